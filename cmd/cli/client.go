@@ -0,0 +1,166 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"github.com/urfave/cli/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/roomapi"
+)
+
+// tokens are minted per call, short lived
+const tokenValidity = 5 * time.Minute
+
+// adminClient talks to the server API of a node: the Twirp services and the HTTP endpoints of the server
+type adminClient struct {
+	url       string
+	apiKey    string
+	apiSecret string
+	http      *http.Client
+}
+
+func newAdminClient(c *cli.Command) (*adminClient, error) {
+	apiKey, apiSecret := c.String("api-key"), c.String("api-secret")
+	if apiKey == "" || apiSecret == "" {
+		return nil, fmt.Errorf("api-key and api-secret are required")
+	}
+	return &adminClient{
+		url:       httpURL(c.String("url")),
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		http:      &http.Client{},
+	}, nil
+}
+
+// httpURL accepts the websocket URL clients connect to as well
+func httpURL(u string) string {
+	u = strings.TrimSuffix(u, "/")
+	switch {
+	case strings.HasPrefix(u, "ws://"):
+		return "http://" + strings.TrimPrefix(u, "ws://")
+	case strings.HasPrefix(u, "wss://"):
+		return "https://" + strings.TrimPrefix(u, "wss://")
+	default:
+		return u
+	}
+}
+
+// token grants what operating a node takes, including admin of the room when given
+func (a *adminClient) token(room string) (string, error) {
+	return auth.NewAccessToken(a.apiKey, a.apiSecret).
+		AddGrant(&auth.VideoGrant{
+			RoomList:   true,
+			RoomCreate: true,
+			RoomRecord: true,
+			RoomAdmin:  room != "",
+			Room:       room,
+		}).
+		SetValidFor(tokenValidity).
+		ToJWT()
+}
+
+func (a *adminClient) twirpContext(ctx context.Context, room string) (context.Context, error) {
+	token, err := a.token(room)
+	if err != nil {
+		return nil, err
+	}
+	header := make(http.Header)
+	header.Set("Authorization", "Bearer "+token)
+	return twirp.WithHTTPRequestHeaders(ctx, header)
+}
+
+func (a *adminClient) roomService() livekit.RoomService {
+	return livekit.NewRoomServiceProtobufClient(a.url, a.http)
+}
+
+func (a *adminClient) egressService() livekit.Egress {
+	return livekit.NewEgressProtobufClient(a.url, a.http)
+}
+
+func (a *adminClient) roomProcessingService() roomapi.RoomService {
+	return roomapi.NewRoomServiceProtobufClient(a.url, a.http)
+}
+
+// request calls an HTTP endpoint of the server and returns the response for the caller to close
+func (a *adminClient) request(ctx context.Context, method string, path string, room string, query url.Values) (*http.Response, error) {
+	token, err := a.token(room)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, a.url+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res, err := a.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode >= http.StatusBadRequest {
+		defer res.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+		return nil, fmt.Errorf("%s %s: %s: %s", method, path, res.Status, strings.TrimSpace(string(body)))
+	}
+	return res, nil
+}
+
+// printJSON prints a response of a twirp API the way call prints those of the HTTP endpoints
+func printJSON(m proto.Message) error {
+	out, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(m)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
+
+// call calls an HTTP endpoint of the server and prints the JSON it responds with
+func (a *adminClient) call(ctx context.Context, method string, path string, room string, query url.Values) error {
+	res, err := a.request(ctx, method, path, room, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if json.Indent(&out, body, "", "  ") != nil {
+		fmt.Println(string(body))
+		return nil
+	}
+	fmt.Println(out.String())
+	return nil
+}
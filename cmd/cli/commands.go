@@ -0,0 +1,414 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/urfave/cli/v3"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/roomapi"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+func listRooms(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, err = client.twirpContext(ctx, "")
+	if err != nil {
+		return err
+	}
+
+	res, err := client.roomService().ListRooms(ctx, &livekit.ListRoomsRequest{})
+	if err != nil {
+		return err
+	}
+
+	table := newTable("Name", "SID", "Participants", "Publishers", "Created")
+	for _, room := range res.Rooms {
+		table.Append([]string{
+			room.Name,
+			room.Sid,
+			strconv.Itoa(int(room.NumParticipants)),
+			strconv.Itoa(int(room.NumPublishers)),
+			formatUnix(room.CreationTime),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func listParticipants(ctx context.Context, c *cli.Command) error {
+	room := c.String("room")
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.roomService().ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: room})
+	if err != nil {
+		return err
+	}
+
+	table := newTable("Identity", "SID", "Kind", "State", "Tracks", "Bypassed Stages", "Joined")
+	for _, p := range res.Participants {
+		tracks := make([]string, 0, len(p.Tracks))
+		for _, track := range p.Tracks {
+			desc := fmt.Sprintf("%s %s", track.Sid, strings.ToLower(track.Type.String()))
+			if track.Muted {
+				desc += " (muted)"
+			}
+			tracks = append(tracks, desc)
+		}
+		table.Append([]string{
+			p.Identity,
+			p.Sid,
+			strings.ToLower(p.Kind.String()),
+			strings.ToLower(p.State.String()),
+			strings.Join(tracks, "\n"),
+			p.Attributes[audio.StageBypassAttribute],
+			formatUnix(p.JoinedAt),
+		})
+	}
+	table.Render()
+	return nil
+}
+
+// setStageBypass excludes a participant from a processing stage, or includes it again,
+// through the stage bypass attribute
+func setStageBypass(ctx context.Context, c *cli.Command) error {
+	room, identity, stage := c.String("room"), c.String("identity"), c.String("stage")
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+
+	rs := client.roomService()
+	p, err := rs.GetParticipant(ctx, &livekit.RoomParticipantIdentity{Room: room, Identity: identity})
+	if err != nil {
+		return err
+	}
+
+	var stages []string
+	for _, s := range strings.Split(p.Attributes[audio.StageBypassAttribute], ",") {
+		if s = strings.TrimSpace(s); s != "" && s != stage {
+			stages = append(stages, s)
+		}
+	}
+	if c.Bool("bypass") {
+		stages = append(stages, stage)
+	}
+	slices.Sort(stages)
+
+	// an empty value removes the attribute
+	_, err = rs.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+		Room:       room,
+		Identity:   identity,
+		Attributes: map[string]string{audio.StageBypassAttribute: strings.Join(stages, ",")},
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s bypasses stages: %s\n", identity, strings.Join(stages, ", "))
+	return nil
+}
+
+func setNoiseFilter(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	room := c.String("room")
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+
+	var res *roomapi.TrackNoiseFilter
+	if c.IsSet("enabled") {
+		res, err = client.roomProcessingService().UpdateTrackNoiseFilter(ctx, &roomapi.UpdateTrackNoiseFilterRequest{
+			Room:     room,
+			Identity: c.String("identity"),
+			TrackSid: c.String("track"),
+			Enabled:  c.Bool("enabled"),
+		})
+	} else {
+		res, err = client.roomProcessingService().GetTrackNoiseFilter(ctx, &roomapi.TrackNoiseFilterRequest{
+			Room:     room,
+			Identity: c.String("identity"),
+			TrackSid: c.String("track"),
+		})
+	}
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func processingBypassStatus(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	room := c.String("room")
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.roomProcessingService().GetProcessingBypass(ctx, &roomapi.ProcessingBypassRequest{Room: room})
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func enableProcessingBypass(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	room := c.String("room")
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+
+	req := &roomapi.EnableProcessingBypassRequest{
+		Room:   room,
+		Reason: c.String("reason"),
+	}
+	if duration := c.Duration("duration"); duration > 0 {
+		req.Duration = durationpb.New(duration)
+	}
+	res, err := client.roomProcessingService().EnableProcessingBypass(ctx, req)
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func disableProcessingBypass(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	room := c.String("room")
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.roomProcessingService().DisableProcessingBypass(ctx, &roomapi.DisableProcessingBypassRequest{
+		Room:   room,
+		Reason: c.String("reason"),
+	})
+	if err != nil {
+		return err
+	}
+	return printJSON(res)
+}
+
+func startEgress(ctx context.Context, c *cli.Command) error {
+	room := c.String("room")
+	req := &livekit.RoomCompositeEgressRequest{
+		RoomName:  room,
+		AudioOnly: c.Bool("audio-only"),
+	}
+	if filepath := c.String("file"); filepath != "" {
+		req.FileOutputs = []*livekit.EncodedFileOutput{{Filepath: filepath}}
+	}
+	if urls := c.StringSlice("stream"); len(urls) != 0 {
+		req.StreamOutputs = []*livekit.StreamOutput{{Protocol: livekit.StreamProtocol_RTMP, Urls: urls}}
+	}
+	if len(req.FileOutputs) == 0 && len(req.StreamOutputs) == 0 {
+		return fmt.Errorf("file or stream output is required")
+	}
+
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, err = client.twirpContext(ctx, room)
+	if err != nil {
+		return err
+	}
+	info, err := client.egressService().StartRoomCompositeEgress(ctx, req)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("started egress %s (%s)\n", info.EgressId, strings.ToLower(info.Status.String()))
+	return nil
+}
+
+func stopEgress(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, err = client.twirpContext(ctx, "")
+	if err != nil {
+		return err
+	}
+	info, err := client.egressService().StopEgress(ctx, &livekit.StopEgressRequest{EgressId: c.String("id")})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("stopping egress %s (%s)\n", info.EgressId, strings.ToLower(info.Status.String()))
+	return nil
+}
+
+func listEgress(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+	ctx, err = client.twirpContext(ctx, c.String("room"))
+	if err != nil {
+		return err
+	}
+	res, err := client.egressService().ListEgress(ctx, &livekit.ListEgressRequest{
+		RoomName: c.String("room"),
+		Active:   !c.Bool("all"),
+	})
+	if err != nil {
+		return err
+	}
+
+	table := newTable("ID", "Room", "Status", "Started", "Error")
+	for _, info := range res.Items {
+		table.Append([]string{
+			info.EgressId,
+			info.RoomName,
+			strings.ToLower(info.Status.String()),
+			formatUnix(info.StartedAt / int64(time.Second)),
+			info.Error,
+		})
+	}
+	table.Render()
+	return nil
+}
+
+func nodeDrain(method string) cli.ActionFunc {
+	return func(ctx context.Context, c *cli.Command) error {
+		client, err := newAdminClient(c)
+		if err != nil {
+			return err
+		}
+		return client.call(ctx, method, "/node/drain", "", url.Values{})
+	}
+}
+
+// tailEvents prints the events of the node as they happen until interrupted
+func tailEvents(ctx context.Context, c *cli.Command) error {
+	client, err := newAdminClient(c)
+	if err != nil {
+		return err
+	}
+
+	room := c.String("room")
+	query := url.Values{}
+	if room != "" {
+		query.Set("room", room)
+	}
+	res, err := client.request(ctx, http.MethodGet, "/events", room, query)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	raw := c.Bool("json")
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if raw {
+			fmt.Println(scanner.Text())
+			continue
+		}
+
+		event := &livekit.WebhookEvent{}
+		if err := protojson.Unmarshal(scanner.Bytes(), event); err != nil {
+			fmt.Fprintln(os.Stderr, "could not parse event:", err)
+			continue
+		}
+		fmt.Println(formatEvent(event))
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+func formatEvent(event *livekit.WebhookEvent) string {
+	fields := []string{
+		time.Unix(event.CreatedAt, 0).Format(time.TimeOnly),
+		event.Event,
+	}
+	if room := event.Room.GetName(); room != "" {
+		fields = append(fields, "room="+room)
+	}
+	if identity := event.Participant.GetIdentity(); identity != "" {
+		fields = append(fields, "participant="+identity)
+	}
+	if track := event.Track.GetSid(); track != "" {
+		fields = append(fields, "track="+track)
+	}
+	if egressID := event.EgressInfo.GetEgressId(); egressID != "" {
+		fields = append(fields, "egress="+egressID, "status="+strings.ToLower(event.EgressInfo.GetStatus().String()))
+	}
+	if ingressID := event.IngressInfo.GetIngressId(); ingressID != "" {
+		fields = append(fields, "ingress="+ingressID)
+	}
+	return strings.Join(fields, " ")
+}
+
+func newTable(header ...string) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader(header)
+	return table
+}
+
+func formatUnix(seconds int64) string {
+	if seconds == 0 {
+		return ""
+	}
+	return time.Unix(seconds, 0).Format(time.RFC3339)
+}
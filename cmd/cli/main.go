@@ -0,0 +1,277 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// agentix-cli lets operators control a running server through its API, e. g. during incidents
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/version"
+)
+
+var (
+	roomFlag = &cli.StringFlag{
+		Name:     "room",
+		Usage:    "name of the room",
+		Required: true,
+	}
+	identityFlag = &cli.StringFlag{
+		Name:     "identity",
+		Usage:    "identity of the participant",
+		Required: true,
+	}
+)
+
+func main() {
+	cmd := &cli.Command{
+		Name:  "agentix-cli",
+		Usage: "control a running server",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "url",
+				Usage:   "URL of the server",
+				Value:   "http://localhost:7880",
+				Sources: cli.EnvVars("LIVEKIT_URL"),
+			},
+			&cli.StringFlag{
+				Name:    "api-key",
+				Usage:   "API key",
+				Sources: cli.EnvVars("LIVEKIT_API_KEY"),
+			},
+			&cli.StringFlag{
+				Name:    "api-secret",
+				Usage:   "API secret",
+				Sources: cli.EnvVars("LIVEKIT_API_SECRET"),
+			},
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "rooms",
+				Usage: "rooms of the server",
+				Commands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "list rooms",
+						Action: listRooms,
+					},
+				},
+			},
+			{
+				Name:  "participants",
+				Usage: "participants of a room",
+				Commands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "list participants with their tracks and bypassed stages",
+						Flags:  []cli.Flag{roomFlag},
+						Action: listParticipants,
+					},
+				},
+			},
+			{
+				Name:  "stages",
+				Usage: "audio processing stages",
+				Commands: []*cli.Command{
+					{
+						Name:  "bypass",
+						Usage: "exclude a participant from a stage, or include it again with --bypass=false",
+						Flags: []cli.Flag{
+							roomFlag,
+							identityFlag,
+							&cli.StringFlag{
+								Name:  "stage",
+								Usage: "processing stage",
+								Value: audio.StageNoiseFilter,
+							},
+							&cli.BoolFlag{
+								Name:  "bypass",
+								Usage: "whether the participant bypasses the stage",
+								Value: true,
+							},
+						},
+						Action: setStageBypass,
+					},
+					{
+						Name:  "noise-filter",
+						Usage: "show the noise filter of a track, or turn it on or off with --enabled",
+						Flags: []cli.Flag{
+							roomFlag,
+							identityFlag,
+							&cli.StringFlag{
+								Name:     "track",
+								Usage:    "SID of the audio track",
+								Required: true,
+							},
+							&cli.BoolFlag{
+								Name:  "enabled",
+								Usage: "whether the track is noise filtered",
+							},
+						},
+						Action: setNoiseFilter,
+					},
+					{
+						Name:  "room-bypass",
+						Usage: "bypass of all processing of a room",
+						Commands: []*cli.Command{
+							{
+								Name:   "status",
+								Usage:  "show whether processing of a room is bypassed",
+								Flags:  []cli.Flag{roomFlag},
+								Action: processingBypassStatus,
+							},
+							{
+								Name:  "enable",
+								Usage: "bypass all processing of a room, in an emergency",
+								Flags: []cli.Flag{
+									roomFlag,
+									&cli.DurationFlag{
+										Name:  "duration",
+										Usage: "processing is re-enabled after, defaults to the configured duration",
+									},
+									&cli.StringFlag{
+										Name:  "reason",
+										Usage: "reason recorded with the bypass",
+									},
+								},
+								Action: enableProcessingBypass,
+							},
+							{
+								Name:  "disable",
+								Usage: "re-enable processing of a room",
+								Flags: []cli.Flag{
+									roomFlag,
+									&cli.StringFlag{
+										Name:  "reason",
+										Usage: "reason recorded with the re-enabling",
+									},
+								},
+								Action: disableProcessingBypass,
+							},
+						},
+					},
+				},
+			},
+			{
+				Name:  "egress",
+				Usage: "recordings and streams of rooms",
+				Commands: []*cli.Command{
+					{
+						Name:  "start",
+						Usage: "start recording or streaming a room",
+						Flags: []cli.Flag{
+							roomFlag,
+							&cli.StringFlag{
+								Name:  "file",
+								Usage: "path of the recording",
+							},
+							&cli.StringSliceFlag{
+								Name:  "stream",
+								Usage: "RTMP URL to stream to, use flag multiple times to stream to several",
+							},
+							&cli.BoolFlag{
+								Name:  "audio-only",
+								Usage: "record audio only",
+							},
+						},
+						Action: startEgress,
+					},
+					{
+						Name:  "stop",
+						Usage: "stop an egress",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:     "id",
+								Usage:    "ID of the egress",
+								Required: true,
+							},
+						},
+						Action: stopEgress,
+					},
+					{
+						Name:  "list",
+						Usage: "list active egresses",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "room",
+								Usage: "name of the room, all rooms when not given",
+							},
+							&cli.BoolFlag{
+								Name:  "all",
+								Usage: "include ended egresses",
+							},
+						},
+						Action: listEgress,
+					},
+				},
+			},
+			{
+				Name:  "node",
+				Usage: "the node the URL points to",
+				Commands: []*cli.Command{
+					{
+						Name:   "status",
+						Usage:  "show the state, rooms and participants of the node",
+						Action: nodeDrain(http.MethodGet),
+					},
+					{
+						Name:   "drain",
+						Usage:  "stop routing new rooms and participants to the node, hosted rooms stay until empty",
+						Action: nodeDrain(http.MethodPost),
+					},
+				},
+			},
+			{
+				Name:  "events",
+				Usage: "events of the node",
+				Commands: []*cli.Command{
+					{
+						Name:  "tail",
+						Usage: "print events as they happen",
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:  "room",
+								Usage: "name of the room, all rooms of the node when not given",
+							},
+							&cli.BoolFlag{
+								Name:  "json",
+								Usage: "print events as JSON",
+							},
+						},
+						Action: tailEvents,
+					},
+				},
+			},
+		},
+		Version: version.Version,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := cmd.Run(ctx, os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		stop()
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+func TestHTTPURL(t *testing.T) {
+	require.Equal(t, "http://localhost:7880", httpURL("ws://localhost:7880/"))
+	require.Equal(t, "https://rtc.example.com", httpURL("wss://rtc.example.com"))
+	require.Equal(t, "https://rtc.example.com", httpURL("https://rtc.example.com"))
+}
+
+func TestFormatEvent(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 0, time.Local)
+	event := &livekit.WebhookEvent{
+		Event:       webhook.EventTrackPublished,
+		CreatedAt:   createdAt.Unix(),
+		Room:        &livekit.Room{Name: "support"},
+		Participant: &livekit.ParticipantInfo{Identity: "caller"},
+		Track:       &livekit.TrackInfo{Sid: "TR_audio"},
+	}
+	require.Equal(t, "10:30:00 track_published room=support participant=caller track=TR_audio", formatEvent(event))
+
+	event = &livekit.WebhookEvent{
+		Event:      webhook.EventEgressEnded,
+		CreatedAt:  createdAt.Unix(),
+		EgressInfo: &livekit.EgressInfo{EgressId: "EG_1", Status: livekit.EgressStatus_EGRESS_COMPLETE},
+	}
+	require.Equal(t, "10:30:00 egress_ended egress=EG_1 status=egress_complete", formatEvent(event))
+}
@@ -16,6 +16,8 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strconv"
@@ -24,17 +26,21 @@ import (
 
 	"github.com/dustin/go-humanize"
 	"github.com/olekukonko/tablewriter"
+	"github.com/pion/interceptor"
 	"github.com/urfave/cli/v3"
 	"gopkg.in/yaml.v3"
 
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/guid"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/replay"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/service"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
 )
 
 func generateKeys(_ context.Context, _ *cli.Command) error {
@@ -256,3 +262,97 @@ func listNodes(_ context.Context, c *cli.Command) error {
 
 	return nil
 }
+
+// replayCaptures replays captured sessions through the audio pipeline of the configuration and prints
+// the digests of its output, identical for every run with the same seed, configuration and build
+func replayCaptures(_ context.Context, c *cli.Command) error {
+	conf, err := getConfig(c)
+	if err != nil {
+		return err
+	}
+	if c.Args().Len() == 0 {
+		return errors.New("no capture given")
+	}
+
+	for _, path := range c.Args().Slice() {
+		res, err := replayCapture(path, c.Int64("seed"), conf)
+		if err != nil {
+			return fmt.Errorf("could not replay %s: %w", path, err)
+		}
+		out, err := json.MarshalIndent(struct {
+			Capture string `json:"capture"`
+			*replay.Result
+		}{path, res}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+	}
+	return nil
+}
+
+func replayCapture(path string, seed int64, conf *config.Config) (*replay.Result, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	noiseFilterConfig := conf.Audio.NoiseFilter
+	// worker pools and shared denoisers carry state across streams and threads, a replay denoises
+	// every packet inline with fresh denoisers
+	noiseFilterConfig.Workers.Enabled = false
+	noiseFilterConfig.Instances.Enabled = false
+
+	var noiseFilter *sfuinterceptor.NoiseFilterFactory
+	var vad *sfuinterceptor.VADFactory
+	defer func() {
+		if noiseFilter != nil {
+			noiseFilter.Close()
+		}
+		if vad != nil {
+			vad.Close()
+		}
+	}()
+
+	return replay.Replay(file, replay.ReplayParams{
+		Seed: seed,
+		Interceptors: func() ([]interceptor.Factory, error) {
+			// in the order of a publisher transport
+			var factories []interceptor.Factory
+			if noiseFilterConfig.Enabled {
+				noiseFilter = sfuinterceptor.NewNoiseFilterFactory(noiseFilterConfig, logger.GetLogger())
+				factories = append(factories, noiseFilter)
+			}
+			if conf.Audio.VAD.Enabled {
+				vad = sfuinterceptor.NewVADFactory(conf.Audio.VAD, logger.GetLogger())
+				factories = append(factories, vad)
+			}
+			return factories, nil
+		},
+	})
+}
+
+func runPreflight(ctx context.Context, c *cli.Command, conf *config.Config) error {
+	report, err := service.RunPreflight(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if path := c.String("preflight-report"); path != "" {
+		if err := os.WriteFile(path, append(out, '\n'), 0644); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(string(out))
+	}
+
+	if !report.Passed {
+		return cli.Exit(fmt.Sprintf("preflight failed: %s", strings.Join(report.Failed(), ", ")), 1)
+	}
+	return nil
+}
@@ -29,12 +29,10 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"github.com/livekit/livekit-server/pkg/rtc"
-	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/protocol/logger"
 
+	"github.com/livekit/livekit-server/pkg/agentix"
 	"github.com/livekit/livekit-server/pkg/config"
-	"github.com/livekit/livekit-server/pkg/routing"
-	"github.com/livekit/livekit-server/pkg/service"
 	"github.com/livekit/livekit-server/version"
 )
 
@@ -96,6 +94,11 @@ var baseFlags = []cli.Flag{
 		Usage:   "tls key file for TURN server",
 		Sources: cli.EnvVars("LIVEKIT_TURN_KEY"),
 	},
+	&cli.StringFlag{
+		Name:    "audio-debug-dump",
+		Usage:   "record the start of every denoised track before and after denoising as WAV files into `dir`",
+		Sources: cli.EnvVars("LIVEKIT_AUDIO_DEBUG_DUMP"),
+	},
 	&cli.StringFlag{
 		Name:  "cpuprofile",
 		Usage: "write CPU profile to `file`",
@@ -104,6 +107,14 @@ var baseFlags = []cli.Flag{
 		Name:  "memprofile",
 		Usage: "write memory profile to `file`",
 	},
+	&cli.BoolFlag{
+		Name:  "preflight",
+		Usage: "run the startup self-test suite against the configuration and exit, non-zero if a check fails",
+	},
+	&cli.StringFlag{
+		Name:  "preflight-report",
+		Usage: "write the preflight report as JSON to `file` instead of stdout",
+	},
 	&cli.BoolFlag{
 		Name:  "dev",
 		Usage: "sets log-level to debug, console formatter, and /debug/pprof. insecure for production",
@@ -172,6 +183,19 @@ func main() {
 					},
 				},
 			},
+			{
+				Name:      "replay",
+				Usage:     "replays captured sessions deterministically through the audio pipeline, printing digests of its output",
+				ArgsUsage: "<capture>...",
+				Action:    replayCaptures,
+				Flags: []cli.Flag{
+					&cli.Int64Flag{
+						Name:  "seed",
+						Usage: "seed of all random choices",
+						Value: 1,
+					},
+				},
+			},
 			{
 				Name:   "list-nodes",
 				Usage:  "list all nodes",
@@ -246,16 +270,14 @@ func getConfig(c *cli.Command) (*config.Config, error) {
 	return conf, nil
 }
 
-func startServer(_ context.Context, c *cli.Command) error {
+func startServer(ctx context.Context, c *cli.Command) error {
 	conf, err := getConfig(c)
 	if err != nil {
 		return err
 	}
 
-	// validate API key length
-	err = conf.ValidateKeys()
-	if err != nil {
-		return err
+	if c.Bool("preflight") {
+		return runPreflight(ctx, c, conf)
 	}
 
 	if cpuProfile := c.String("cpuprofile"); cpuProfile != "" {
@@ -286,16 +308,7 @@ func startServer(_ context.Context, c *cli.Command) error {
 		}
 	}
 
-	currentNode, err := routing.NewLocalNode(conf)
-	if err != nil {
-		return err
-	}
-
-	if err := prometheus.Init(string(currentNode.NodeID()), currentNode.NodeType()); err != nil {
-		return err
-	}
-
-	server, err := service.InitializeServer(conf, currentNode)
+	server, err := agentix.NewServer(conf)
 	if err != nil {
 		return err
 	}
@@ -312,6 +325,10 @@ func startServer(_ context.Context, c *cli.Command) error {
 		}
 	}()
 
+	watchCtx, cancelWatch := context.WithCancel(ctx)
+	defer cancelWatch()
+	go watchConfig(watchCtx, c, server)
+
 	return server.Start()
 }
 
@@ -0,0 +1,83 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/agentix"
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+// configPollInterval is how often the config file is checked for changes
+const configPollInterval = 5 * time.Second
+
+// watchConfig reloads the audio settings of the server when the config file changes, or on SIGHUP,
+// until ctx is done. Other settings take effect with the next restart.
+func watchConfig(ctx context.Context, c *cli.Command, server *agentix.Server) {
+	configFile := c.String("config")
+	lastBody, _ := getConfigString(configFile, c.String("config-body"))
+
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	defer signal.Stop(hupChan)
+
+	var pollChan <-chan time.Time
+	if configFile != "" && c.String("config-body") == "" {
+		ticker := time.NewTicker(configPollInterval)
+		defer ticker.Stop()
+		pollChan = ticker.C
+	}
+
+	for {
+		force := false
+		select {
+		case <-ctx.Done():
+			return
+		case <-hupChan:
+			force = true
+		case <-pollChan:
+		}
+
+		body, err := getConfigString(configFile, c.String("config-body"))
+		if err != nil {
+			logger.Warnw("could not read config file", err, "file", configFile)
+			continue
+		}
+		if body == lastBody && !force {
+			continue
+		}
+		lastBody = body
+
+		conf, err := config.NewConfig(body, !c.Bool("disable-strict-config"), c, baseFlags)
+		if err == nil {
+			err = server.ReloadAudioConfig(conf)
+		}
+		if err != nil {
+			logger.Warnw("could not reload config, keeping the current audio settings", err, "file", configFile)
+			continue
+		}
+		logger.Infow("audio settings reloaded", "file", configFile)
+	}
+}
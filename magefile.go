@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
 	"github.com/magefile/mage/mg"
@@ -35,8 +36,26 @@ import (
 const (
 	goChecksumFile = ".checksumgo"
 	imageName      = "livekit/livekit-server"
+	protocolModule = "github.com/livekit/protocol"
 )
 
+// protos of the services served by the server, clients are generated for these. Twirp services are
+// defined by github.com/livekit/protocol and pkg/roomapi, gRPC services of AgentIX by protos of this
+// repository.
+var clientProtos = []string{
+	"livekit_room.proto",
+	"livekit_agent_dispatch.proto",
+	"livekit_egress.proto",
+	"livekit_ingress.proto",
+	"livekit_sip.proto",
+	"pkg/pcmtap/pcmtap.proto",
+	"pkg/audioinject/audioinject.proto",
+	"pkg/agent/agentworker.proto",
+	"pkg/transcription/whisper.proto",
+	"pkg/transcription/speech.proto",
+	"pkg/roomapi/roomapi.proto",
+}
+
 // Default target to run when none is specified
 // If not set, running mage will list available targets
 var (
@@ -56,7 +75,7 @@ func Deps() error {
 	return installTools(true)
 }
 
-// builds LiveKit server
+// builds LiveKit server and the operator CLI
 func Build() error {
 	mg.Deps(generateWire)
 	if !checksummer.IsChanged() {
@@ -71,6 +90,9 @@ func Build() error {
 	if err := mageutil.RunDir(context.Background(), "cmd/server", "go build -o ../../bin/livekit-server"); err != nil {
 		return err
 	}
+	if err := mageutil.RunDir(context.Background(), "cmd/cli", "go build -o ../../bin/agentix-cli"); err != nil {
+		return err
+	}
 
 	checksummer.WriteChecksum()
 	return nil
@@ -92,7 +114,7 @@ func BuildLinux() error {
 	if len(buildArch) == 0 {
 		buildArch = "amd64"
 	}
-	cmd := mageutil.CommandDir(context.Background(), "cmd/server", "go build -buildvcs=false -o ../../bin/livekit-server-" + buildArch)
+	cmd := mageutil.CommandDir(context.Background(), "cmd/server", "go build -buildvcs=false -o ../../bin/livekit-server-"+buildArch)
 	cmd.Env = []string{
 		"GOOS=linux",
 		"GOARCH=" + buildArch,
@@ -215,6 +237,80 @@ func generateWire() error {
 	return nil
 }
 
+// generates Go, TypeScript and Python clients of the server APIs into clients/
+func Clients() error {
+	mg.Deps(installClientTools)
+
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", protocolModule).Output()
+	if err != nil {
+		return fmt.Errorf("could not locate %s, run go mod download: %w", protocolModule, err)
+	}
+	// protos of this repository import those of the protocol, both are generated from one tree
+	protoDir, err := os.MkdirTemp("", "clients")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(protoDir)
+	if err := os.CopyFS(protoDir, os.DirFS(filepath.Join(strings.TrimSpace(string(out)), "protobufs"))); err != nil {
+		return err
+	}
+	for _, p := range clientProtos {
+		if !strings.HasPrefix(p, "pkg/") {
+			continue
+		}
+		proto, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(protoDir, filepath.Dir(p)), 0755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(protoDir, p), proto, 0644); err != nil {
+			return err
+		}
+	}
+
+	buf, err := mageutil.GetToolPath("buf")
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range []string{"go", "ts", "python"} {
+		if err := os.RemoveAll(filepath.Join("clients", dir)); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("generating clients...")
+	args := []string{"generate", protoDir, "--template", "clients/buf.gen.yaml", "--output", "clients"}
+	for _, p := range clientProtos {
+		args = append(args, "--path", filepath.Join(protoDir, p))
+	}
+	cmd := exec.Command(buf, args...)
+	mageutil.ConnectStd(cmd)
+	return cmd.Run()
+}
+
+func installClientTools() error {
+	if _, err := exec.LookPath("npx"); err != nil {
+		return errors.New("npx is required to generate TypeScript clients")
+	}
+
+	tools := map[string]string{
+		"github.com/bufbuild/buf/cmd/buf":               "latest",
+		"google.golang.org/protobuf/cmd/protoc-gen-go":  "latest",
+		"google.golang.org/grpc/cmd/protoc-gen-go-grpc": "latest",
+		"github.com/twitchtv/twirp/protoc-gen-twirp":    "latest",
+		"github.com/verloop/twirpy/protoc-gen-twirpy":   "latest",
+	}
+	for t, v := range tools {
+		if err := mageutil.InstallTool(t, v, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // implicitly install deps
 func installDeps() error {
 	return installTools(false)
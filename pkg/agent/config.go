@@ -1,5 +1,43 @@
 package agent
 
+// livekit_agent.proto is one of the protobufs of github.com/livekit/protocol
+//go:generate protoc -I . -I $LIVEKIT_PROTOCOL/protobufs --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative agentworker.proto
+
+import "time"
+
 type Config struct {
 	EnableUserDataRecording bool `yaml:"enable_user_data_recording"`
+
+	// agents dispatched into rooms created without agents of their own, every rule matching the
+	// name of the room adds a dispatch
+	DispatchRules []DispatchRule `yaml:"dispatch_rules,omitempty"`
+	// workers not heard from within this time, pings included, are disconnected, 0 disables
+	WorkerTimeout time.Duration `yaml:"worker_timeout,omitempty"`
+	// jobs of disconnected workers are dispatched to another worker
+	JobRetry JobRetryConfig `yaml:"job_retry,omitempty"`
+	// gRPC registration of workers, next to the WebSocket at /agent
+	WorkerGRPC WorkerGRPCConfig `yaml:"worker_grpc,omitempty"`
+}
+
+type JobRetryConfig struct {
+	// times a job is dispatched again, 0 disables retries
+	MaxAttempts int `yaml:"max_attempts,omitempty"`
+	// wait before the first retry, doubling with every further one
+	Backoff time.Duration `yaml:"backoff,omitempty"`
+}
+
+type WorkerGRPCConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	Port    int  `yaml:"port,omitempty"`
+}
+
+var DefaultConfig = Config{
+	WorkerTimeout: time.Minute,
+	JobRetry: JobRetryConfig{
+		MaxAttempts: 2,
+		Backoff:     time.Second,
+	},
+	WorkerGRPC: WorkerGRPCConfig{
+		Port: 7885,
+	},
 }
@@ -0,0 +1,86 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// DispatchRule dispatches an agent into the rooms created with a matching name
+type DispatchRule struct {
+	// glob of room names, as matched by path.Match, e. g. support-*
+	RoomPattern string `yaml:"room_pattern,omitempty"`
+	AgentName   string `yaml:"agent_name,omitempty"`
+	// metadata of the dispatch, passed to the job
+	Metadata string `yaml:"metadata,omitempty"`
+	// capabilities a worker has to advertise to be given jobs of the rule
+	Capabilities []string `yaml:"capabilities,omitempty"`
+}
+
+func (r *DispatchRule) Matches(roomName string) bool {
+	ok, err := path.Match(r.RoomPattern, roomName)
+	return err == nil && ok
+}
+
+// RoomAgentDispatches returns the dispatches of the rules matching the room, nil if none match
+func RoomAgentDispatches(rules []DispatchRule, roomName string) []*livekit.RoomAgentDispatch {
+	var dispatches []*livekit.RoomAgentDispatch
+	for i := range rules {
+		if rules[i].Matches(roomName) {
+			dispatches = append(dispatches, &livekit.RoomAgentDispatch{
+				AgentName: rules[i].AgentName,
+				Metadata:  rules[i].Metadata,
+			})
+		}
+	}
+	return dispatches
+}
+
+// RequiredCapabilities returns the capabilities of the rules for the agent matching the room
+func RequiredCapabilities(rules []DispatchRule, roomName string, agentName string) []string {
+	var capabilities []string
+	for i := range rules {
+		if rules[i].AgentName == agentName && rules[i].Matches(roomName) {
+			capabilities = append(capabilities, rules[i].Capabilities...)
+		}
+	}
+	return capabilities
+}
+
+// ParseCapabilities returns the capabilities of a comma separated list, as advertised by workers
+func ParseCapabilities(s string) []string {
+	var capabilities []string
+	for _, c := range strings.Split(s, ",") {
+		if c = strings.TrimSpace(c); c != "" {
+			capabilities = append(capabilities, c)
+		}
+	}
+	return capabilities
+}
+
+// HasCapabilities returns true if the worker advertised all of required
+func (r *WorkerRegistration) HasCapabilities(required []string) bool {
+	for _, c := range required {
+		if !slices.Contains(r.Capabilities, c) {
+			return false
+		}
+	}
+	return true
+}
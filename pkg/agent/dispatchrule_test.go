@@ -0,0 +1,48 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatchRules(t *testing.T) {
+	rules := []DispatchRule{
+		{RoomPattern: "support-*", AgentName: "support", Metadata: "en", Capabilities: []string{"stt"}},
+		{RoomPattern: "support-vip-*", AgentName: "support", Capabilities: []string{"tts"}},
+		{RoomPattern: "*", AgentName: "recorder"},
+		{RoomPattern: "[", AgentName: "invalid"},
+	}
+
+	dispatches := RoomAgentDispatches(rules, "support-vip-1")
+	require.Len(t, dispatches, 3)
+	require.Equal(t, "support", dispatches[0].AgentName)
+	require.Equal(t, "en", dispatches[0].Metadata)
+	require.Equal(t, "recorder", dispatches[2].AgentName)
+	require.Len(t, RoomAgentDispatches(rules[:2], "sales-1"), 0)
+
+	require.Equal(t, []string{"stt", "tts"}, RequiredCapabilities(rules, "support-vip-1", "support"))
+	require.Equal(t, []string{"stt"}, RequiredCapabilities(rules, "support-1", "support"))
+	require.Empty(t, RequiredCapabilities(rules, "support-1", "recorder"))
+
+	r := WorkerRegistration{Capabilities: ParseCapabilities(" stt, tts,,")}
+	require.Equal(t, []string{"stt", "tts"}, r.Capabilities)
+	require.True(t, r.HasCapabilities(nil))
+	require.True(t, r.HasCapabilities([]string{"tts"}))
+	require.False(t, r.HasCapabilities([]string{"tts", "vision"}))
+}
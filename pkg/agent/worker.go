@@ -149,6 +149,8 @@ type WorkerRegistration struct {
 	JobType     livekit.JobType
 	Permissions *livekit.ParticipantPermission
 	ClientIP    string
+	// advertised by the worker when connecting, see DispatchRule
+	Capabilities []string
 }
 
 func MakeWorkerRegistration() WorkerRegistration {
@@ -0,0 +1,140 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agentix embeds the server in another binary, e. g. one adding proprietary interceptors to the
+// audio pipeline, without forking it:
+//
+//	conf, err := config.NewConfig(yamlConfig, true, nil, nil)
+//	...
+//	server, err := agentix.NewServer(conf,
+//		agentix.WithLogger(l),
+//		agentix.WithInterceptor("watermark", watermarkFactory),
+//	)
+//	...
+//	go server.Start()
+//	defer server.Stop(false)
+//
+// Metrics and the node wide denoiser pool are per process, a process runs a single server.
+package agentix
+
+import (
+	"github.com/pion/interceptor"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/protocol/logger"
+)
+
+type Server = service.LivekitServer
+
+type options struct {
+	logger       logger.Logger
+	node         routing.LocalNode
+	telemetry    []func(telemetry.TelemetryService) telemetry.TelemetryService
+	interceptors []rtc.InterceptorStage
+}
+
+type Option func(o *options)
+
+// WithLogger sets the logger of the server instead of the one configured by the logging section
+func WithLogger(l logger.Logger) Option {
+	return func(o *options) {
+		o.logger = l
+	}
+}
+
+// WithNode runs the server as node instead of a node created from the configuration
+func WithNode(node routing.LocalNode) Option {
+	return func(o *options) {
+		o.node = node
+	}
+}
+
+// WithTelemetry wraps the telemetry service of the server, e. g. to forward events to another sink as well.
+// Wrappers apply in the order they are given, the last one being outermost.
+func WithTelemetry(wrap func(telemetry.TelemetryService) telemetry.TelemetryService) Option {
+	return func(o *options) {
+		o.telemetry = append(o.telemetry, wrap)
+	}
+}
+
+// WithInterceptor adds a receive stage to the buffer of every published track. Interceptors follow the
+// built in audio stages in the order they are given, seeing denoised audio and its voice activity attributes.
+// name labels the interceptor in flight recorder traces.
+func WithInterceptor(name string, factory interceptor.Factory) Option {
+	return func(o *options) {
+		o.interceptors = append(o.interceptors, rtc.InterceptorStage{Name: name, Factory: factory})
+	}
+}
+
+func (o *options) serverOptions() service.ServerOptions {
+	opts := service.ServerOptions{
+		Interceptors: o.interceptors,
+	}
+	if len(o.telemetry) != 0 {
+		wrappers := o.telemetry
+		opts.Telemetry = func(ts telemetry.TelemetryService) telemetry.TelemetryService {
+			for _, wrap := range wrappers {
+				ts = wrap(ts)
+			}
+			return ts
+		}
+	}
+	return opts
+}
+
+// NewServer validates conf and creates a server from it, it is started with Start
+func NewServer(conf *config.Config, opts ...Option) (*Server, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	if o.logger != nil {
+		config.SetLogger(o.logger)
+	}
+
+	if err := conf.ValidateKeys(); err != nil {
+		return nil, err
+	}
+
+	currentNode := o.node
+	if currentNode == nil {
+		var err error
+		if currentNode, err = routing.NewLocalNode(conf); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := prometheus.Init(string(currentNode.NodeID()), currentNode.NodeType()); err != nil {
+		return nil, err
+	}
+
+	if err := service.CheckNativeLibraries(conf); err != nil {
+		return nil, err
+	}
+	if err := service.CheckNoiseFilterModels(conf); err != nil {
+		return nil, err
+	}
+	if err := service.CheckWakeWordModel(conf); err != nil {
+		return nil, err
+	}
+
+	return service.InitializeServer(conf, currentNode, o.serverOptions())
+}
@@ -0,0 +1,70 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentix
+
+import (
+	"testing"
+
+	"github.com/pion/interceptor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/telemetry"
+	"github.com/livekit/livekit-server/pkg/telemetry/telemetryfakes"
+)
+
+type noOpFactory struct{}
+
+func (noOpFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &interceptor.NoOp{}, nil
+}
+
+type namedTelemetry struct {
+	telemetry.TelemetryService
+	name string
+}
+
+func TestServerOptions(t *testing.T) {
+	o := &options{}
+	require.Nil(t, o.serverOptions().Telemetry)
+
+	factory := noOpFactory{}
+	for _, opt := range []Option{
+		WithInterceptor("first", factory),
+		WithInterceptor("second", factory),
+		WithTelemetry(func(ts telemetry.TelemetryService) telemetry.TelemetryService {
+			return &namedTelemetry{TelemetryService: ts, name: "inner"}
+		}),
+		WithTelemetry(func(ts telemetry.TelemetryService) telemetry.TelemetryService {
+			return &namedTelemetry{TelemetryService: ts, name: "outer"}
+		}),
+	} {
+		opt(o)
+	}
+
+	opts := o.serverOptions()
+	require.Len(t, opts.Interceptors, 2)
+	require.Equal(t, "first", opts.Interceptors[0].Name)
+	require.Equal(t, "second", opts.Interceptors[1].Name)
+
+	base := &telemetryfakes.FakeTelemetryService{}
+	outer, ok := opts.Telemetry(base).(*namedTelemetry)
+	require.True(t, ok)
+	require.Equal(t, "outer", outer.name)
+	inner, ok := outer.TelemetryService.(*namedTelemetry)
+	require.True(t, ok)
+	require.Equal(t, "inner", inner.name)
+	require.Same(t, base, inner.TelemetryService)
+}
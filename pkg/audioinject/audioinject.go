@@ -0,0 +1,437 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package audioinject plays audio into rooms as server side tracks, so that agent backends can speak,
+// e. g. stream TTS, without a WebRTC stack of their own.
+package audioinject
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// sample rates PCM can be written at, it is resampled to Opus' 48 kHz
+	MinSampleRate = 8000
+	MaxSampleRate = audio.OpusSampleRate
+
+	// duration of the Opus frames PCM is encoded into
+	frameDuration = 20 * time.Millisecond
+)
+
+// encodings of the audio written to a player, see Format of audioinject.proto
+const (
+	// mono, signed 16 bit little endian samples
+	FormatPCM16 = Format_FORMAT_PCM16
+	// an Opus packet per write, sent as is
+	FormatOpus = Format_FORMAT_OPUS
+)
+
+// PCMFromBytes returns the samples of signed 16 bit little endian PCM, a trailing odd byte is ignored
+func PCMFromBytes(b []byte) []int16 {
+	pcm := make([]int16, len(b)/2)
+	for i := range pcm {
+		pcm[i] = int16(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	return pcm
+}
+
+// FinishReason tells why playback finished
+type FinishReason string
+
+const (
+	// all audio was played after the end of the audio was signaled
+	FinishCompleted FinishReason = "completed"
+	// playback was cancelled, audio still queued was dropped
+	FinishCancelled FinishReason = "cancelled"
+	// the track went away, e. g. with its room
+	FinishClosed FinishReason = "closed"
+)
+
+var (
+	ErrUnsupportedSampleRate = errors.New("unsupported sample rate, must be between 8000 and 48000")
+	ErrUnsupportedFormat     = errors.New("unsupported audio format")
+	ErrInvalidOpusPacket     = errors.New("invalid opus packet")
+	ErrPlayerFinished        = errors.New("audio injection finished")
+	ErrSourceNotAllowed      = errors.New("audio source not allowed")
+)
+
+// Config enables a gRPC service playing audio into rooms as server side tracks that every participant
+// receives, streamed by the caller as PCM or Opus, or read from a URL or file. Audio is paced in real time.
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// port the gRPC service listens on
+	Port int `yaml:"port,omitempty"`
+	// audio queued ahead of playback per track, the caller is held back beyond it
+	BufferDuration time.Duration `yaml:"buffer_duration,omitempty"`
+	// injected tracks of a room at a time
+	MaxTracks int `yaml:"max_tracks,omitempty"`
+	// http and https URLs can be played
+	AllowURLs bool `yaml:"allow_urls,omitempty"`
+	// files below this directory can be played, none when empty
+	FileRoot string `yaml:"file_root,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Port:           7884,
+		BufferDuration: 10 * time.Second,
+		MaxTracks:      4,
+	}
+)
+
+type PlayerParams struct {
+	Config Config
+	// sends a packet of Opus audio of duration, called at the pace of real time
+	Write func(payload []byte, duration time.Duration) error
+	// called once the first audio is sent
+	OnStarted func()
+	// called once when playback finished, with the audio sent until then
+	OnFinished func(reason FinishReason, played time.Duration)
+	// denoises 48 kHz mono PCM in place before it is encoded, optional. Opus packets are decoded to be
+	// denoised and encoded again when set.
+	Denoise func(pcm []int16)
+}
+
+type opusFrame struct {
+	payload  []byte
+	duration time.Duration
+}
+
+// Player paces the audio written to it into a track. PCM is resampled and encoded into 20 ms Opus frames,
+// Opus packets are sent as is unless they are denoised. Writes block while more than the buffer duration
+// is queued.
+type Player struct {
+	params PlayerParams
+
+	lock   sync.Mutex
+	queue  []opusFrame
+	queued time.Duration
+	// no more audio is written, playback completes once the queue ran empty
+	ended    bool
+	reason   FinishReason
+	started  bool
+	played   time.Duration
+	dequeued chan struct{}
+	enqueued chan struct{}
+	// stopped is broken as playback finishes, finished once OnFinished returned
+	stopped  core.Fuse
+	finished core.Fuse
+
+	// PCM encoding, only used by writers
+	writeLock  sync.Mutex
+	encoder    audio.OpusEncoder
+	resampler  *audio.Resampler
+	sampleRate int
+	pcm        []int16
+	payload    []byte
+	decoder    audio.OpusDecoder
+	decoded    []int16
+}
+
+func NewPlayer(params PlayerParams) *Player {
+	if params.Config.BufferDuration <= 0 {
+		params.Config.BufferDuration = DefaultConfig.BufferDuration
+	}
+	p := &Player{
+		params:   params,
+		dequeued: make(chan struct{}, 1),
+		enqueued: make(chan struct{}, 1),
+	}
+	go p.worker()
+	return p
+}
+
+// WriteOpus queues an Opus packet
+func (p *Player) WriteOpus(ctx context.Context, packet []byte) error {
+	toc, ok := audio.ParseOpusTOC(packet)
+	if !ok || toc.Duration() <= 0 {
+		return ErrInvalidOpusPacket
+	}
+	if p.params.Denoise != nil {
+		return p.writeDecoded(ctx, packet)
+	}
+	return p.enqueue(ctx, opusFrame{payload: append([]byte(nil), packet...), duration: toc.Duration()})
+}
+
+// writeDecoded queues the audio of an Opus packet as PCM, which is denoised as it is encoded
+func (p *Player) writeDecoded(ctx context.Context, packet []byte) error {
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+
+	if p.stopped.IsBroken() {
+		return ErrPlayerFinished
+	}
+	if err := p.createEncoderLocked(); err != nil {
+		return err
+	}
+	if p.decoder == nil {
+		decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+		if err != nil {
+			return err
+		}
+		p.decoder = decoder
+		p.decoded = make([]int16, audio.OpusMaxFrameSize)
+	}
+
+	n, err := p.decoder.Decode(packet, p.decoded)
+	if err != nil {
+		return ErrInvalidOpusPacket
+	}
+	p.pcm = append(p.pcm, p.decoded[:n]...)
+	return p.encodeFramesLocked(ctx, false)
+}
+
+// WritePCM queues mono samples at sampleRate, samples short of a full frame are kept for the next write
+func (p *Player) WritePCM(ctx context.Context, pcm []int16, sampleRate int) error {
+	if sampleRate < MinSampleRate || sampleRate > MaxSampleRate {
+		return ErrUnsupportedSampleRate
+	}
+
+	p.writeLock.Lock()
+	defer p.writeLock.Unlock()
+
+	if p.stopped.IsBroken() {
+		return ErrPlayerFinished
+	}
+	if err := p.createEncoderLocked(); err != nil {
+		return err
+	}
+	if p.resampler == nil || p.sampleRate != sampleRate {
+		p.resampler = audio.NewResampler(sampleRate, audio.OpusSampleRate, 1)
+		p.sampleRate = sampleRate
+	}
+
+	p.pcm = p.resampler.Resample(pcm, p.pcm)
+	return p.encodeFramesLocked(ctx, false)
+}
+
+// createEncoderLocked creates the encoder of PCM with the first write. Must be called with the write
+// lock held.
+func (p *Player) createEncoderLocked() error {
+	if p.encoder != nil {
+		return nil
+	}
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return err
+	}
+	p.encoder = audio.DefaultEncoderRegistry.Track(encoder, audio.EncoderPriorityMixed)
+	p.payload = make([]byte, audio.OpusMaxPacketSize)
+	return nil
+}
+
+// encodeFramesLocked encodes and queues the full frames of PCM, and with flush a last frame padded with
+// silence. Must be called with the write lock held.
+func (p *Player) encodeFramesLocked(ctx context.Context, flush bool) error {
+	frameSize := int(int64(audio.OpusSampleRate) * int64(frameDuration) / int64(time.Second))
+	if flush && len(p.pcm)%frameSize != 0 {
+		p.pcm = append(p.pcm, make([]int16, frameSize-len(p.pcm)%frameSize)...)
+	}
+
+	for len(p.pcm) >= frameSize {
+		if p.params.Denoise != nil {
+			p.params.Denoise(p.pcm[:frameSize])
+		}
+		n, err := p.encoder.Encode(p.pcm[:frameSize], p.payload)
+		if err != nil {
+			return err
+		}
+		p.pcm = append(p.pcm[:0], p.pcm[frameSize:]...)
+		if err := p.enqueue(ctx, opusFrame{payload: append([]byte(nil), p.payload[:n]...), duration: frameDuration}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Player) enqueue(ctx context.Context, f opusFrame) error {
+	for {
+		p.lock.Lock()
+		switch {
+		case p.reason != "" || p.ended:
+			p.lock.Unlock()
+			return ErrPlayerFinished
+		case p.queued < p.params.Config.BufferDuration:
+			p.queue = append(p.queue, f)
+			p.queued += f.duration
+			p.lock.Unlock()
+			signal(p.enqueued)
+			return nil
+		}
+		p.lock.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.stopped.Watch():
+			return ErrPlayerFinished
+		case <-p.dequeued:
+		}
+	}
+}
+
+// End signals that no more audio is written, playback completes once the audio queued was played
+func (p *Player) End(ctx context.Context) error {
+	p.writeLock.Lock()
+	var err error
+	if p.encoder != nil && len(p.pcm) > 0 {
+		err = p.encodeFramesLocked(ctx, true)
+	}
+	p.writeLock.Unlock()
+
+	p.lock.Lock()
+	p.ended = true
+	p.lock.Unlock()
+	signal(p.enqueued)
+	return err
+}
+
+// Flush drops the audio queued and PCM short of a frame, playback continues with the audio written next
+func (p *Player) Flush() {
+	p.lock.Lock()
+	p.queue = nil
+	p.queued = 0
+	p.lock.Unlock()
+	signal(p.dequeued)
+
+	p.writeLock.Lock()
+	p.pcm = p.pcm[:0]
+	p.writeLock.Unlock()
+}
+
+// Cancel stops playback at once, audio still queued is dropped
+func (p *Player) Cancel() {
+	p.finish(FinishCancelled)
+}
+
+// Close stops playback because the track went away
+func (p *Player) Close() {
+	p.finish(FinishClosed)
+}
+
+// Done is closed once playback finished and OnFinished returned
+func (p *Player) Done() <-chan struct{} {
+	return p.finished.Watch()
+}
+
+func (p *Player) finish(reason FinishReason) {
+	p.lock.Lock()
+	if p.reason != "" {
+		p.lock.Unlock()
+		return
+	}
+	p.reason = reason
+	p.queue = nil
+	p.queued = 0
+	played := p.played
+	p.lock.Unlock()
+
+	// releases writers waiting for room in the queue before taking the write lock
+	p.stopped.Break()
+
+	p.writeLock.Lock()
+	audio.DefaultEncoderRegistry.Untrack(p.encoder)
+	p.writeLock.Unlock()
+
+	if p.params.OnFinished != nil {
+		p.params.OnFinished(reason, played)
+	}
+	p.finished.Break()
+}
+
+// dequeue returns the next frame to play, false if there is none yet. Completes playback when
+// the audio ended and everything was played.
+func (p *Player) dequeue() (opusFrame, bool) {
+	p.lock.Lock()
+	if len(p.queue) == 0 {
+		complete := p.ended && p.reason == ""
+		p.lock.Unlock()
+		if complete {
+			p.finish(FinishCompleted)
+		}
+		return opusFrame{}, false
+	}
+
+	f := p.queue[0]
+	p.queue = p.queue[1:]
+	p.queued -= f.duration
+	started := p.started
+	p.started = true
+	p.lock.Unlock()
+
+	signal(p.dequeued)
+	if !started && p.params.OnStarted != nil {
+		p.params.OnStarted()
+	}
+	return f, true
+}
+
+// worker sends the queued frames in real time. After running dry, playback restarts with the next frame
+// rather than catching up.
+func (p *Player) worker() {
+	var next time.Time
+	for {
+		f, ok := p.dequeue()
+		if !ok {
+			next = time.Time{}
+			select {
+			case <-p.stopped.Watch():
+				return
+			case <-p.enqueued:
+				continue
+			}
+		}
+
+		// frames late by less than a frame are sent right away and keep the pace, later ones restart it
+		if now := time.Now(); next.IsZero() || now.After(next.Add(frameDuration)) {
+			next = now
+		} else if now.Before(next) {
+			timer := time.NewTimer(next.Sub(now))
+			select {
+			case <-p.stopped.Watch():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		if p.stopped.IsBroken() {
+			return
+		}
+
+		if err := p.params.Write(f.payload, f.duration); err != nil {
+			p.finish(FinishClosed)
+			return
+		}
+		p.lock.Lock()
+		p.played += f.duration
+		p.lock.Unlock()
+		next = next.Add(f.duration)
+	}
+}
+
+func signal(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,338 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audioinject
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+// a 20 ms CELT fullband Opus packet
+var opusPacket = []byte{0xf8, 0x01, 0x02}
+
+type fakeCodec struct{}
+
+func (fakeCodec) NewDecoder(sampleRate int, channels int) (audio.OpusDecoder, error) {
+	return fakeDecoder{}, nil
+}
+
+func (fakeCodec) NewEncoder(sampleRate int, channels int) (audio.OpusEncoder, error) {
+	return fakeEncoder{}, nil
+}
+
+type fakeDecoder struct{}
+
+// Decode returns 20 ms of audio
+func (fakeDecoder) Decode(payload []byte, pcm []int16) (int, error) {
+	return copy(pcm, make([]int16, 960)), nil
+}
+
+type fakeEncoder struct{}
+
+func (fakeEncoder) Encode(pcm []int16, out []byte) (int, error) {
+	return copy(out, opusPacket), nil
+}
+
+type recorder struct {
+	lock     sync.Mutex
+	payloads [][]byte
+	started  bool
+	reason   FinishReason
+	played   time.Duration
+}
+
+func newRecordingPlayer(config Config) (*Player, *recorder) {
+	r := &recorder{}
+	p := NewPlayer(PlayerParams{
+		Config: config,
+		Write: func(payload []byte, duration time.Duration) error {
+			r.lock.Lock()
+			r.payloads = append(r.payloads, payload)
+			r.lock.Unlock()
+			return nil
+		},
+		OnStarted: func() {
+			r.lock.Lock()
+			r.started = true
+			r.lock.Unlock()
+		},
+		OnFinished: func(reason FinishReason, played time.Duration) {
+			r.lock.Lock()
+			r.reason = reason
+			r.played = played
+			r.lock.Unlock()
+		},
+	})
+	return p, r
+}
+
+func (r *recorder) result() (int, bool, FinishReason, time.Duration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return len(r.payloads), r.started, r.reason, r.played
+}
+
+func waitDone(t *testing.T, p *Player) {
+	select {
+	case <-p.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("playback did not finish")
+	}
+}
+
+func TestPlayer(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("opus is paced in real time", func(t *testing.T) {
+		p, r := newRecordingPlayer(DefaultConfig)
+		start := time.Now()
+		for i := 0; i < 5; i++ {
+			require.NoError(t, p.WriteOpus(ctx, opusPacket))
+		}
+		require.ErrorIs(t, p.WriteOpus(ctx, nil), ErrInvalidOpusPacket)
+		require.NoError(t, p.End(ctx))
+		waitDone(t, p)
+
+		// the first frame goes out at once, the last one 80 ms later
+		require.GreaterOrEqual(t, time.Since(start), 80*time.Millisecond)
+		written, started, reason, played := r.result()
+		require.Equal(t, 5, written)
+		require.True(t, started)
+		require.Equal(t, FinishCompleted, reason)
+		require.Equal(t, 100*time.Millisecond, played)
+		require.ErrorIs(t, p.WriteOpus(ctx, opusPacket), ErrPlayerFinished)
+	})
+
+	t.Run("cancel drops queued audio", func(t *testing.T) {
+		p, r := newRecordingPlayer(DefaultConfig)
+		for i := 0; i < 50; i++ {
+			require.NoError(t, p.WriteOpus(ctx, opusPacket))
+		}
+		p.Cancel()
+		waitDone(t, p)
+
+		written, _, reason, played := r.result()
+		require.Less(t, written, 50)
+		require.Equal(t, FinishCancelled, reason)
+		require.Less(t, played, time.Second)
+	})
+
+	t.Run("flush drops queued audio and keeps playing", func(t *testing.T) {
+		p, r := newRecordingPlayer(DefaultConfig)
+		for i := 0; i < 50; i++ {
+			require.NoError(t, p.WriteOpus(ctx, opusPacket))
+		}
+		p.Flush()
+		written, _, _, _ := r.result()
+		require.Less(t, written, 50)
+
+		require.NoError(t, p.WriteOpus(ctx, opusPacket))
+		require.NoError(t, p.End(ctx))
+		waitDone(t, p)
+
+		afterFlush, _, reason, _ := r.result()
+		require.Equal(t, FinishCompleted, reason)
+		require.Less(t, afterFlush, 10)
+		require.Greater(t, afterFlush, written)
+	})
+
+	t.Run("writers are held back by the buffer", func(t *testing.T) {
+		p, _ := newRecordingPlayer(Config{BufferDuration: 40 * time.Millisecond})
+		defer p.Close()
+
+		start := time.Now()
+		for i := 0; i < 6; i++ {
+			require.NoError(t, p.WriteOpus(ctx, opusPacket))
+		}
+		require.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+		timeout, cancel := context.WithTimeout(ctx, time.Millisecond)
+		defer cancel()
+		for i := 0; i < 3; i++ {
+			if err := p.WriteOpus(timeout, opusPacket); err != nil {
+				require.ErrorIs(t, err, context.DeadlineExceeded)
+				return
+			}
+		}
+		t.Fatal("writes were not held back")
+	})
+
+	t.Run("pcm is encoded into 20 ms frames", func(t *testing.T) {
+		audio.RegisterOpusCodec(fakeCodec{})
+		defer audio.RegisterOpusCodec(nil)
+
+		p, r := newRecordingPlayer(DefaultConfig)
+		require.ErrorIs(t, p.WritePCM(ctx, make([]int16, 160), 4000), ErrUnsupportedSampleRate)
+		// 30 ms at 16 kHz, the last 10 ms are padded on end
+		require.NoError(t, p.WritePCM(ctx, make([]int16, 480), 16000))
+		require.NoError(t, p.End(ctx))
+		waitDone(t, p)
+
+		written, _, reason, played := r.result()
+		require.Equal(t, 2, written)
+		require.Equal(t, FinishCompleted, reason)
+		require.Equal(t, 40*time.Millisecond, played)
+	})
+
+	t.Run("pcm and opus are denoised before they are encoded", func(t *testing.T) {
+		audio.RegisterOpusCodec(fakeCodec{})
+		defer audio.RegisterOpusCodec(nil)
+
+		var frames []int
+		p := NewPlayer(PlayerParams{
+			Config: DefaultConfig,
+			Write: func(payload []byte, duration time.Duration) error {
+				return nil
+			},
+			Denoise: func(pcm []int16) {
+				frames = append(frames, len(pcm))
+			},
+		})
+		// 20 ms at 48 kHz, then a 20 ms Opus packet, decoded
+		require.NoError(t, p.WritePCM(ctx, make([]int16, 960), 48000))
+		require.NoError(t, p.WriteOpus(ctx, opusPacket))
+		require.NoError(t, p.End(ctx))
+		waitDone(t, p)
+
+		require.Equal(t, []int{960, 960}, frames)
+	})
+}
+
+// oggPage returns an Ogg page of the packets, without a valid checksum which is not verified
+func oggPage(packets ...[]byte) []byte {
+	var lacing, body []byte
+	for _, packet := range packets {
+		n := len(packet)
+		for ; n >= 255; n -= 255 {
+			lacing = append(lacing, 255)
+		}
+		lacing = append(lacing, byte(n))
+		body = append(body, packet...)
+	}
+
+	header := make([]byte, 27)
+	copy(header, "OggS")
+	binary.LittleEndian.PutUint32(header[14:], 1)
+	header[26] = byte(len(lacing))
+	return append(append(header, lacing...), body...)
+}
+
+func TestPlayFrom(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ogg opus", func(t *testing.T) {
+		long := append(append([]byte(nil), opusPacket...), make([]byte, 300)...)
+		var file []byte
+		file = append(file, oggPage([]byte("OpusHead...."))...)
+		file = append(file, oggPage([]byte("OpusTags...."))...)
+		file = append(file, oggPage(opusPacket, long, opusPacket)...)
+
+		p, r := newRecordingPlayer(DefaultConfig)
+		require.NoError(t, PlayFrom(ctx, p, bytes.NewReader(file)))
+		waitDone(t, p)
+
+		written, _, reason, _ := r.result()
+		require.Equal(t, 3, written)
+		require.Equal(t, FinishCompleted, reason)
+		require.Len(t, r.payloads[1], len(long))
+	})
+
+	t.Run("wav", func(t *testing.T) {
+		audio.RegisterOpusCodec(fakeCodec{})
+		defer audio.RegisterOpusCodec(nil)
+
+		f, err := os.Create(filepath.Join(t.TempDir(), "speech.wav"))
+		require.NoError(t, err)
+		ww, err := audio.NewWAVWriter(f, 16000, 2)
+		require.NoError(t, err)
+		// 60 ms of stereo
+		require.NoError(t, ww.Write(make([]int16, 2*960)))
+		require.NoError(t, ww.Close())
+		_, err = f.Seek(0, 0)
+		require.NoError(t, err)
+
+		p, r := newRecordingPlayer(DefaultConfig)
+		require.NoError(t, PlayFrom(ctx, p, f))
+		waitDone(t, p)
+
+		written, _, reason, _ := r.result()
+		require.Equal(t, 3, written)
+		require.Equal(t, FinishCompleted, reason)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		p, _ := newRecordingPlayer(DefaultConfig)
+		defer p.Close()
+		require.ErrorIs(t, PlayFrom(ctx, p, bytes.NewReader([]byte("ID3 mp3"))), ErrUnsupportedSource)
+	})
+}
+
+func TestOpenSource(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(root, "hello.wav"), []byte("RIFF"), 0o644))
+
+	_, err := OpenSource(ctx, DefaultConfig, "hello.wav")
+	require.ErrorIs(t, err, ErrSourceNotAllowed)
+	_, err = OpenSource(ctx, DefaultConfig, "https://example.com/hello.wav")
+	require.ErrorIs(t, err, ErrSourceNotAllowed)
+
+	config := Config{FileRoot: root}
+	for _, location := range []string{"hello.wav", "/hello.wav", "file:///hello.wav"} {
+		r, err := OpenSource(ctx, config, location)
+		require.NoError(t, err, location)
+		require.NoError(t, r.Close())
+	}
+
+	// paths climbing out of the root stay inside it
+	require.NoError(t, os.WriteFile(filepath.Join(filepath.Dir(root), "secret.wav"), []byte("RIFF"), 0o644))
+	_, err = OpenSource(ctx, config, "../secret.wav")
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMessages(t *testing.T) {
+	req := &PlayRequest{
+		RoomName:   "room",
+		Name:       "tts",
+		Format:     FormatOpus,
+		SampleRate: 48000,
+		Audio:      opusPacket,
+		End:        true,
+	}
+	b, err := proto.Marshal(req)
+	require.NoError(t, err)
+	decoded := &PlayRequest{}
+	require.NoError(t, proto.Unmarshal(b, decoded))
+	require.True(t, proto.Equal(req, decoded))
+
+	event := &PlayEvent{Type: PlayEventFinished, TrackSid: "TR_INJ_1", Reason: string(FinishCancelled), PlayedUs: 1500000}
+	b, err = proto.Marshal(event)
+	require.NoError(t, err)
+	decodedEvent := &PlayEvent{}
+	require.NoError(t, proto.Unmarshal(b, decodedEvent))
+	require.True(t, proto.Equal(event, decodedEvent))
+}
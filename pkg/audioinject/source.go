@@ -0,0 +1,242 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audioinject
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+var ErrUnsupportedSource = errors.New("unsupported audio file, must be 16 bit PCM WAV or Ogg Opus")
+
+// OpenSource opens audio to play from an http or https URL, or from a file below the file root
+// given as a path or file URL relative to it
+func OpenSource(ctx context.Context, config Config, location string) (io.ReadCloser, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		if !config.AllowURLs {
+			return nil, ErrSourceNotAllowed
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if res.StatusCode != http.StatusOK {
+			_ = res.Body.Close()
+			return nil, fmt.Errorf("could not fetch audio: %s", res.Status)
+		}
+		return res.Body, nil
+
+	case "", "file":
+		if config.FileRoot == "" {
+			return nil, ErrSourceNotAllowed
+		}
+		path := u.Path
+		if u.Scheme == "" {
+			path = location
+		}
+		// rooted before cleaning so paths cannot climb out of the file root
+		return os.Open(filepath.Join(config.FileRoot, filepath.Clean("/"+path)))
+	}
+	return nil, ErrSourceNotAllowed
+}
+
+// PlayFrom writes the audio of a 16 bit PCM WAV or Ogg Opus file to the player until the file ends,
+// then ends playback
+func PlayFrom(ctx context.Context, p *Player, r io.Reader) error {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(4)
+	if err != nil {
+		return ErrUnsupportedSource
+	}
+
+	switch {
+	case bytes.Equal(magic, []byte("RIFF")):
+		err = playWAV(ctx, p, br)
+	case bytes.Equal(magic, []byte("OggS")):
+		err = playOgg(ctx, p, br)
+	default:
+		err = ErrUnsupportedSource
+	}
+	if err != nil {
+		return err
+	}
+	return p.End(ctx)
+}
+
+func playWAV(ctx context.Context, p *Player, r io.Reader) error {
+	format, err := audio.ReadWAVHeader(r)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, 2*format.Channels*int(frameDuration.Milliseconds())*format.SampleRate/1000)
+	pcm := make([]int16, 0, len(buf)/2/format.Channels)
+	for {
+		n, err := io.ReadFull(r, buf)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return err
+		}
+
+		// downmixed to mono
+		pcm = pcm[:0]
+		for i := 0; i+2*format.Channels <= n; i += 2 * format.Channels {
+			var sum int32
+			for ch := 0; ch < format.Channels; ch++ {
+				sum += int32(int16(binary.LittleEndian.Uint16(buf[i+2*ch:])))
+			}
+			pcm = append(pcm, int16(sum/int32(format.Channels)))
+		}
+		if err := p.WritePCM(ctx, pcm, format.SampleRate); err != nil {
+			return err
+		}
+		if n < len(buf) {
+			return nil
+		}
+	}
+}
+
+func playOgg(ctx context.Context, p *Player, r io.Reader) error {
+	packets := newOggPacketReader(r)
+	for i := 0; ; i++ {
+		packet, err := packets.next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case i == 0:
+			if !bytes.HasPrefix(packet, []byte("OpusHead")) {
+				return ErrUnsupportedSource
+			}
+		case i == 1:
+			// OpusTags
+		default:
+			if err := p.WriteOpus(ctx, packet); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// --------------------------------------
+
+var errInvalidOgg = errors.New("invalid ogg page")
+
+// oggPacketReader reads the packets of the first logical stream of an Ogg file, RFC 3533
+type oggPacketReader struct {
+	r      io.Reader
+	serial uint32
+	primed bool
+	// segments of the current page not yet read
+	lacing  []byte
+	page    []byte
+	partial []byte
+}
+
+func newOggPacketReader(r io.Reader) *oggPacketReader {
+	return &oggPacketReader{r: r}
+}
+
+func (o *oggPacketReader) next() ([]byte, error) {
+	for {
+		for len(o.lacing) > 0 {
+			size := int(o.lacing[0])
+			o.lacing = o.lacing[1:]
+			if size > len(o.page) {
+				return nil, errInvalidOgg
+			}
+			o.partial = append(o.partial, o.page[:size]...)
+			o.page = o.page[size:]
+			// a segment shorter than 255 bytes ends a packet, others continue it
+			if size < 255 {
+				packet := o.partial
+				o.partial = nil
+				return packet, nil
+			}
+		}
+		if err := o.readPage(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (o *oggPacketReader) readPage() error {
+	for {
+		header := make([]byte, 27)
+		if _, err := io.ReadFull(o.r, header); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return errInvalidOgg
+			}
+			return err
+		}
+		if string(header[0:4]) != "OggS" {
+			return errInvalidOgg
+		}
+
+		lacing := make([]byte, header[26])
+		if _, err := io.ReadFull(o.r, lacing); err != nil {
+			return errInvalidOgg
+		}
+		size := 0
+		for _, l := range lacing {
+			size += int(l)
+		}
+		page := make([]byte, size)
+		if _, err := io.ReadFull(o.r, page); err != nil {
+			return errInvalidOgg
+		}
+
+		serial := binary.LittleEndian.Uint32(header[14:])
+		if !o.primed {
+			o.primed = true
+			o.serial = serial
+		}
+		if serial != o.serial {
+			// other logical streams are skipped
+			continue
+		}
+		o.lacing = lacing
+		o.page = page
+		return nil
+	}
+}
@@ -0,0 +1,48 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package b2bua
+
+import (
+	"time"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// rooms whose name starts with the prefix run in B2BUA mode
+	RoomPrefix string `yaml:"room_prefix,omitempty"`
+	// host:port of the compliance recorder every call is streamed to
+	RecorderAddress string `yaml:"recorder_address,omitempty"`
+	// time the recorder has to accept a call, legs are rejected until it did
+	ConnectTimeout time.Duration `yaml:"connect_timeout,omitempty"`
+	// interval of heartbeats sent to the recorder
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval,omitempty"`
+	// the call fails when the recorder has not answered a heartbeat for this long
+	HeartbeatTimeout time.Duration `yaml:"heartbeat_timeout,omitempty"`
+	// the call fails when a write to the recorder blocks for this long
+	WriteTimeout time.Duration `yaml:"write_timeout,omitempty"`
+	// frames queued for the recorder, the call fails rather than dropping media when the queue is full
+	QueueSize int `yaml:"queue_size,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		ConnectTimeout:    5 * time.Second,
+		HeartbeatInterval: time.Second,
+		HeartbeatTimeout:  3 * time.Second,
+		WriteTimeout:      2 * time.Second,
+		QueueSize:         1000,
+	}
+)
@@ -0,0 +1,449 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package b2bua
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Frames exchanged with the recorder are a one byte type, the big endian uint32 length of the payload and the payload.
+const (
+	// JSON CallInfo, the first frame of a call. The recorder answers with FrameAccept once it is able to capture the call.
+	FrameStart byte = 'S'
+	// JSON LegEvent
+	FrameLeg byte = 'L'
+	// JSON Track, announces the handle media of a track is sent with
+	FrameTrack byte = 'T'
+	// uint16 track handle, int64 arrival time in unix nanoseconds and the RTP packet
+	FrameMedia byte = 'M'
+	// uint64 sequence number, the recorder echoes the frame as is
+	FrameHeartbeat byte = 'H'
+	// JSON End, the last frame of a call
+	FrameEnd byte = 'E'
+
+	// sent by the recorder when it accepted the call
+	FrameAccept byte = 'A'
+	// sent by the recorder when it cannot capture the call, the payload is the reason
+	FrameFault byte = 'F'
+
+	MaxFramePayload = 1 << 20
+
+	frameHeaderSize = 5
+	mediaHeaderSize = 10
+)
+
+var (
+	ErrRecorderNotConfigured = errors.New("no compliance recorder configured")
+	ErrRecorderRejected      = errors.New("compliance recorder rejected the call")
+	ErrRecorderFault         = errors.New("compliance recorder reported a fault")
+	ErrRecorderHeartbeat     = errors.New("compliance recorder missed heartbeats")
+	ErrRecorderBackpressure  = errors.New("compliance recorder is not keeping up")
+	ErrRecorderProtocol      = errors.New("unexpected frame from compliance recorder")
+	ErrRecordingClosed       = errors.New("compliance recording is closed")
+	ErrFrameTooLarge         = errors.New("frame exceeds maximum payload size")
+)
+
+type CallInfo struct {
+	RoomName  string    `json:"room_name"`
+	RoomID    string    `json:"room_id"`
+	StartedAt time.Time `json:"started_at"`
+}
+
+const (
+	LegJoined = "joined"
+	LegLeft   = "left"
+)
+
+type LegEvent struct {
+	Event               string    `json:"event"`
+	Index               int       `json:"index"`
+	ParticipantIdentity string    `json:"participant_identity"`
+	Kind                string    `json:"kind"`
+	SIPCallID           string    `json:"sip_call_id,omitempty"`
+	Time                time.Time `json:"time"`
+}
+
+type Track struct {
+	Handle              uint16 `json:"handle"`
+	ParticipantIdentity string `json:"participant_identity"`
+	TrackID             string `json:"track_id"`
+	MimeType            string `json:"mime_type"`
+	ClockRate           uint32 `json:"clock_rate"`
+}
+
+type End struct {
+	Reason string    `json:"reason"`
+	Time   time.Time `json:"time"`
+}
+
+func WriteFrame(w io.Writer, frameType byte, payload []byte) error {
+	if len(payload) > MaxFramePayload {
+		return ErrFrameTooLarge
+	}
+	if _, err := w.Write(appendFrameHeader(make([]byte, 0, frameHeaderSize), frameType, len(payload))); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+func ReadFrame(r io.Reader) (byte, []byte, error) {
+	var header [frameHeaderSize]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(header[1:])
+	if size > MaxFramePayload {
+		return 0, nil, ErrFrameTooLarge
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[0], payload, nil
+}
+
+// ParseMedia splits the payload of a media frame
+func ParseMedia(payload []byte) (handle uint16, arrival time.Time, packet []byte, err error) {
+	if len(payload) < mediaHeaderSize {
+		return 0, time.Time{}, nil, io.ErrUnexpectedEOF
+	}
+	handle = binary.BigEndian.Uint16(payload)
+	arrival = time.Unix(0, int64(binary.BigEndian.Uint64(payload[2:])))
+	return handle, arrival, payload[mediaHeaderSize:], nil
+}
+
+func appendFrameHeader(b []byte, frameType byte, size int) []byte {
+	b = append(b, frameType)
+	return binary.BigEndian.AppendUint32(b, uint32(size))
+}
+
+func encodeFrame(frameType byte, payload []byte) []byte {
+	return append(appendFrameHeader(make([]byte, 0, frameHeaderSize+len(payload)), frameType, len(payload)), payload...)
+}
+
+func encodeJSONFrame(frameType byte, v any) ([]byte, error) {
+	payload, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return encodeFrame(frameType, payload), nil
+}
+
+// --------------------------------------
+
+// Recording streams the media of one call to the compliance recorder. Capture is guaranteed,
+// i. e. nothing is dropped: a recorder that cannot keep up, misses heartbeats or reports a fault
+// fails the recording and the call has to be ended.
+type Recording struct {
+	config    Config
+	conn      net.Conn
+	onFailure func(err error)
+
+	queue      chan []byte
+	done       chan struct{}
+	ended      chan struct{}
+	stopOnce   sync.Once
+	closing    atomic.Bool
+	lastEcho   atomic.Int64
+	heartbeat  atomic.Uint64
+	nextHandle atomic.Uint32
+
+	lock sync.Mutex
+	err  error
+}
+
+// Dial connects to the recorder and starts the call, returns once the recorder accepted it.
+// onFailure is called once, in its own goroutine, when the recording fails before it is closed.
+func Dial(ctx context.Context, config Config, call CallInfo, onFailure func(err error)) (*Recording, error) {
+	if config.RecorderAddress == "" {
+		return nil, ErrRecorderNotConfigured
+	}
+	if config.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.ConnectTimeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", config.RecorderAddress)
+	if err != nil {
+		return nil, err
+	}
+	if err := start(ctx, conn, call); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	r := &Recording{
+		config:    config,
+		conn:      conn,
+		onFailure: onFailure,
+		queue:     make(chan []byte, max(config.QueueSize, 1)),
+		done:      make(chan struct{}),
+		ended:     make(chan struct{}),
+	}
+	r.lastEcho.Store(time.Now().UnixNano())
+	go r.writeWorker()
+	go r.readWorker()
+	go r.heartbeatWorker()
+	return r, nil
+}
+
+func start(ctx context.Context, conn net.Conn, call CallInfo) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	payload, err := json.Marshal(call)
+	if err != nil {
+		return err
+	}
+	if err := WriteFrame(conn, FrameStart, payload); err != nil {
+		return err
+	}
+
+	frameType, payload, err := ReadFrame(conn)
+	if err != nil {
+		return err
+	}
+	switch frameType {
+	case FrameAccept:
+		return nil
+	case FrameFault:
+		return fmt.Errorf("%w: %s", ErrRecorderRejected, payload)
+	default:
+		return fmt.Errorf("%w: %q", ErrRecorderProtocol, frameType)
+	}
+}
+
+// Err returns the error the recording failed with, nil while it is healthy
+func (r *Recording) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.err
+}
+
+func (r *Recording) AddLeg(leg LegEvent) error {
+	frame, err := encodeJSONFrame(FrameLeg, leg)
+	if err != nil {
+		return err
+	}
+	return r.enqueue(frame)
+}
+
+// AddTrack announces a track, media of the track is written with the returned handle
+func (r *Recording) AddTrack(track Track) (uint16, error) {
+	track.Handle = uint16(r.nextHandle.Add(1))
+	frame, err := encodeJSONFrame(FrameTrack, track)
+	if err != nil {
+		return 0, err
+	}
+	return track.Handle, r.enqueue(frame)
+}
+
+// WriteRTP queues a marshalled RTP packet of a track without blocking
+func (r *Recording) WriteRTP(handle uint16, arrival time.Time, packet []byte) error {
+	frame := appendFrameHeader(make([]byte, 0, frameHeaderSize+mediaHeaderSize+len(packet)), FrameMedia, mediaHeaderSize+len(packet))
+	frame = binary.BigEndian.AppendUint16(frame, handle)
+	frame = binary.BigEndian.AppendUint64(frame, uint64(arrival.UnixNano()))
+	frame = append(frame, packet...)
+	return r.enqueue(frame)
+}
+
+// Close ends the call, media queued so far is delivered before the connection is closed.
+// Returns the error the recording failed with, if any.
+func (r *Recording) Close(reason string) error {
+	if r.closing.Swap(true) {
+		return r.Err()
+	}
+
+	frame, err := encodeJSONFrame(FrameEnd, End{Reason: reason, Time: time.Now()})
+	if err == nil {
+		timer := time.NewTimer(r.config.WriteTimeout)
+		select {
+		case r.queue <- frame:
+			select {
+			case <-r.ended:
+			case <-r.done:
+			case <-timer.C:
+			}
+		case <-r.done:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+
+	r.stop()
+	return r.Err()
+}
+
+func (r *Recording) enqueue(frame []byte) error {
+	if r.closing.Load() {
+		return ErrRecordingClosed
+	}
+	select {
+	case <-r.done:
+		return r.Err()
+	default:
+	}
+
+	select {
+	case r.queue <- frame:
+		return nil
+	default:
+		r.fail(ErrRecorderBackpressure)
+		return ErrRecorderBackpressure
+	}
+}
+
+func (r *Recording) fail(err error) {
+	select {
+	case <-r.done:
+		// connection closed after the recording stopped
+		return
+	case <-r.ended:
+		// recorder hung up after the end of the call
+		return
+	default:
+	}
+
+	r.lock.Lock()
+	failed := r.err == nil
+	if failed {
+		r.err = err
+	}
+	r.lock.Unlock()
+
+	r.stop()
+	if failed && !r.closing.Load() && r.onFailure != nil {
+		go r.onFailure(err)
+	}
+}
+
+func (r *Recording) stop() {
+	r.stopOnce.Do(func() {
+		close(r.done)
+		_ = r.conn.Close()
+	})
+}
+
+func (r *Recording) writeWorker() {
+	w := bufio.NewWriter(r.conn)
+	write := func(frame []byte) bool {
+		if r.config.WriteTimeout > 0 {
+			_ = r.conn.SetWriteDeadline(time.Now().Add(r.config.WriteTimeout))
+		}
+		if _, err := w.Write(frame); err != nil {
+			r.fail(err)
+			return false
+		}
+		return true
+	}
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case frame := <-r.queue:
+			// batch whatever else is queued into one flush
+			for {
+				if !write(frame) {
+					return
+				}
+				if frame[0] == FrameEnd {
+					if err := w.Flush(); err != nil {
+						r.fail(err)
+						return
+					}
+					close(r.ended)
+					return
+				}
+				if len(r.queue) == 0 {
+					break
+				}
+				frame = <-r.queue
+			}
+			if err := w.Flush(); err != nil {
+				r.fail(err)
+				return
+			}
+		}
+	}
+}
+
+func (r *Recording) readWorker() {
+	reader := bufio.NewReader(r.conn)
+	for {
+		frameType, payload, err := ReadFrame(reader)
+		if err != nil {
+			r.fail(err)
+			return
+		}
+
+		switch frameType {
+		case FrameHeartbeat:
+			if len(payload) == 8 && binary.BigEndian.Uint64(payload) <= r.heartbeat.Load() {
+				r.lastEcho.Store(time.Now().UnixNano())
+			}
+		case FrameFault:
+			r.fail(fmt.Errorf("%w: %s", ErrRecorderFault, payload))
+			return
+		default:
+			r.fail(fmt.Errorf("%w: %q", ErrRecorderProtocol, frameType))
+			return
+		}
+	}
+}
+
+func (r *Recording) heartbeatWorker() {
+	if r.config.HeartbeatInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.config.HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			if r.closing.Load() {
+				return
+			}
+			if r.config.HeartbeatTimeout > 0 && time.Since(time.Unix(0, r.lastEcho.Load())) > r.config.HeartbeatTimeout {
+				r.fail(ErrRecorderHeartbeat)
+				return
+			}
+			if err := r.enqueue(encodeFrame(FrameHeartbeat, binary.BigEndian.AppendUint64(nil, r.heartbeat.Add(1)))); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package b2bua
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRecorder struct {
+	listener net.Listener
+	frames   chan byte
+	media    chan []byte
+}
+
+// newFakeRecorder accepts one call, echoing heartbeats when echo is set
+func newFakeRecorder(t *testing.T, accept bool, echo bool) *fakeRecorder {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	f := &fakeRecorder{
+		listener: l,
+		frames:   make(chan byte, 100),
+		media:    make(chan []byte, 100),
+	}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if frameType, _, err := ReadFrame(conn); err != nil || frameType != FrameStart {
+			return
+		}
+		if !accept {
+			_ = WriteFrame(conn, FrameFault, []byte("storage full"))
+			return
+		}
+		_ = WriteFrame(conn, FrameAccept, nil)
+
+		for {
+			frameType, payload, err := ReadFrame(conn)
+			if err != nil {
+				close(f.frames)
+				return
+			}
+			switch frameType {
+			case FrameHeartbeat:
+				if echo {
+					_ = WriteFrame(conn, FrameHeartbeat, payload)
+				}
+				continue
+			case FrameMedia:
+				f.media <- payload
+			}
+			f.frames <- frameType
+		}
+	}()
+	return f
+}
+
+func testConfig(address string) Config {
+	config := DefaultConfig
+	config.Enabled = true
+	config.RecorderAddress = address
+	config.HeartbeatInterval = 10 * time.Millisecond
+	config.HeartbeatTimeout = 50 * time.Millisecond
+	return config
+}
+
+func TestFrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteFrame(&buf, FrameLeg, []byte(`{"index":0}`)))
+
+	frameType, payload, err := ReadFrame(&buf)
+	require.NoError(t, err)
+	require.Equal(t, FrameLeg, frameType)
+	require.Equal(t, `{"index":0}`, string(payload))
+
+	require.ErrorIs(t, WriteFrame(&buf, FrameMedia, make([]byte, MaxFramePayload+1)), ErrFrameTooLarge)
+}
+
+func TestRecording(t *testing.T) {
+	t.Run("streams legs, tracks and media until closed", func(t *testing.T) {
+		recorder := newFakeRecorder(t, true, true)
+		rec, err := Dial(context.Background(), testConfig(recorder.listener.Addr().String()), CallInfo{RoomName: "call"}, func(err error) {
+			t.Errorf("unexpected failure: %v", err)
+		})
+		require.NoError(t, err)
+
+		require.NoError(t, rec.AddLeg(LegEvent{Event: LegJoined, Index: 0, ParticipantIdentity: "caller"}))
+		handle, err := rec.AddTrack(Track{ParticipantIdentity: "caller", TrackID: "TR_1", MimeType: "audio/opus", ClockRate: 48000})
+		require.NoError(t, err)
+		arrival := time.Unix(100, 0)
+		require.NoError(t, rec.WriteRTP(handle, arrival, []byte{0x80, 0x6f}))
+
+		// heartbeats are answered, the recording stays healthy
+		time.Sleep(100 * time.Millisecond)
+		require.NoError(t, rec.Close("hangup"))
+		require.ErrorIs(t, rec.WriteRTP(handle, arrival, []byte{0x80}), ErrRecordingClosed)
+
+		var frames []byte
+		for frameType := range recorder.frames {
+			frames = append(frames, frameType)
+		}
+		require.Equal(t, []byte{FrameLeg, FrameTrack, FrameMedia, FrameEnd}, frames)
+
+		gotHandle, gotArrival, packet, err := ParseMedia(<-recorder.media)
+		require.NoError(t, err)
+		require.Equal(t, handle, gotHandle)
+		require.True(t, arrival.Equal(gotArrival))
+		require.Equal(t, []byte{0x80, 0x6f}, packet)
+	})
+
+	t.Run("rejected call", func(t *testing.T) {
+		recorder := newFakeRecorder(t, false, false)
+		_, err := Dial(context.Background(), testConfig(recorder.listener.Addr().String()), CallInfo{}, nil)
+		require.ErrorIs(t, err, ErrRecorderRejected)
+	})
+
+	t.Run("missed heartbeats fail the recording", func(t *testing.T) {
+		recorder := newFakeRecorder(t, true, false)
+		failed := make(chan error, 1)
+		rec, err := Dial(context.Background(), testConfig(recorder.listener.Addr().String()), CallInfo{}, func(err error) {
+			failed <- err
+		})
+		require.NoError(t, err)
+
+		select {
+		case err := <-failed:
+			require.ErrorIs(t, err, ErrRecorderHeartbeat)
+		case <-time.After(time.Second):
+			t.Fatal("recording did not fail")
+		}
+		require.ErrorIs(t, rec.WriteRTP(1, time.Now(), []byte{0x80}), ErrRecorderHeartbeat)
+		require.ErrorIs(t, rec.Close("hangup"), ErrRecorderHeartbeat)
+	})
+
+	t.Run("unreachable recorder", func(t *testing.T) {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		address := l.Addr().String()
+		require.NoError(t, l.Close())
+
+		_, err = Dial(context.Background(), testConfig(address), CallInfo{}, nil)
+		require.Error(t, err)
+		require.False(t, errors.Is(err, ErrRecorderRejected))
+	})
+
+	t.Run("no recorder configured", func(t *testing.T) {
+		_, err := Dial(context.Background(), testConfig(""), CallInfo{}, nil)
+		require.ErrorIs(t, err, ErrRecorderNotConfigured)
+	})
+}
+
+func TestLegEventJSON(t *testing.T) {
+	data, err := json.Marshal(LegEvent{Event: LegJoined, Index: 1, ParticipantIdentity: "callee", Kind: "SIP", SIPCallID: "SCL_1"})
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"sip_call_id":"SCL_1"`)
+}
@@ -0,0 +1,335 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callflow
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// steps entered without waiting before a flow is taken to loop
+	maxTransitions = 100
+)
+
+type Wait string
+
+const (
+	WaitNone     Wait = ""
+	WaitPrompt   Wait = "prompt"
+	WaitInput    Wait = "input"
+	WaitTransfer Wait = "transfer"
+)
+
+type ActionKind string
+
+const (
+	ActionPrompt   ActionKind = "prompt"
+	ActionHandoff  ActionKind = "handoff"
+	ActionTransfer ActionKind = "transfer"
+	ActionHangup   ActionKind = "hangup"
+)
+
+// Action is performed by the room for the participant running the flow
+type Action struct {
+	Kind     ActionKind
+	Step     string
+	Prompt   *Prompt
+	Handoff  *Handoff
+	Transfer *Transfer
+	// variables of the flow at the time of the action
+	Variables map[string]string
+}
+
+// State is where an execution stands, it is persisted so that a participant reconnecting resumes the flow
+type State struct {
+	Flow string `json:"flow"`
+	Step string `json:"step"`
+	// what the step waits for until Deadline
+	Wait     Wait      `json:"wait,omitempty"`
+	Deadline time.Time `json:"deadline,omitempty"`
+	// retries of the current collect step
+	Attempt int `json:"attempt,omitempty"`
+	// digits collected so far
+	Input     string            `json:"input,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+	Done      bool              `json:"done,omitempty"`
+}
+
+// Execution runs a flow for one participant. Every event returns the actions to perform in order.
+// Not safe for concurrent use.
+type Execution struct {
+	flow  *Flow
+	state State
+}
+
+func NewExecution(name string, flow *Flow) *Execution {
+	return &Execution{
+		flow:  flow,
+		state: State{Flow: name},
+	}
+}
+
+// ResumeExecution continues a flow from a persisted state
+func ResumeExecution(flow *Flow, state State) (*Execution, error) {
+	if _, ok := flow.Steps[state.Step]; !ok && !state.Done {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStep, state.Step)
+	}
+	state.Variables = maps.Clone(state.Variables)
+	return &Execution{
+		flow:  flow,
+		state: state,
+	}, nil
+}
+
+func (e *Execution) State() State {
+	state := e.state
+	state.Variables = maps.Clone(e.state.Variables)
+	return state
+}
+
+func (e *Execution) Done() bool {
+	return e.state.Done
+}
+
+// Start enters the first step of the flow
+func (e *Execution) Start(now time.Time) []Action {
+	return e.enter(e.flow.Start, now, nil)
+}
+
+// OnDigit takes a DTMF digit as input of a collect step
+func (e *Execution) OnDigit(digit string, now time.Time) []Action {
+	step, ok := e.waiting(WaitInput)
+	if !ok || digit == "" {
+		return nil
+	}
+
+	collect := step.Collect
+	if collect.Terminator != "" && digit == collect.Terminator {
+		return e.complete(step, e.state.Input, now)
+	}
+	e.state.Input += digit
+	if len(e.state.Input) >= max(collect.MaxDigits, 1) {
+		return e.complete(step, e.state.Input, now)
+	}
+	e.state.Deadline = now.Add(collectTimeout(collect))
+	return nil
+}
+
+// OnSpeech takes a final transcription of the participant as input of a collect step accepting speech
+func (e *Execution) OnSpeech(text string, now time.Time) []Action {
+	step, ok := e.waiting(WaitInput)
+	if !ok || !step.Collect.Speech || strings.TrimSpace(text) == "" {
+		return nil
+	}
+	return e.complete(step, strings.TrimSpace(text), now)
+}
+
+// OnPromptDone moves on once the prompt of step was played
+func (e *Execution) OnPromptDone(stepName string, now time.Time) []Action {
+	step, ok := e.waiting(WaitPrompt)
+	if !ok || stepName != e.state.Step {
+		return nil
+	}
+	return e.enter(step.Next, now, nil)
+}
+
+// OnTransferResult ends the flow after a successful transfer or continues at the failure step
+func (e *Execution) OnTransferResult(err error, now time.Time) []Action {
+	step, ok := e.waiting(WaitTransfer)
+	if !ok {
+		return nil
+	}
+	if err == nil {
+		e.finish()
+		return nil
+	}
+	return e.enter(step.Transfer.Failed, now, nil)
+}
+
+// OnTimer ends waits that passed their deadline
+func (e *Execution) OnTimer(now time.Time) []Action {
+	if e.state.Done || e.state.Deadline.IsZero() || now.Before(e.state.Deadline) {
+		return nil
+	}
+
+	step := e.flow.Steps[e.state.Step]
+	switch e.state.Wait {
+	case WaitPrompt:
+		return e.enter(step.Next, now, nil)
+	case WaitInput:
+		if e.state.Input != "" {
+			return e.complete(step, e.state.Input, now)
+		}
+		return e.noMatch(step, now)
+	}
+	return nil
+}
+
+func (e *Execution) waiting(wait Wait) (Step, bool) {
+	if e.state.Done || e.state.Wait != wait {
+		return Step{}, false
+	}
+	step, ok := e.flow.Steps[e.state.Step]
+	return step, ok
+}
+
+func (e *Execution) finish() {
+	e.state.Done = true
+	e.state.Wait = WaitNone
+	e.state.Deadline = time.Time{}
+}
+
+// enter runs steps from name until one waits or the flow ends, appending their actions to actions
+func (e *Execution) enter(name string, now time.Time, actions []Action) []Action {
+	for range maxTransitions {
+		step, ok := e.flow.Steps[name]
+		if name == "" || !ok {
+			e.finish()
+			return actions
+		}
+
+		e.state.Step = name
+		e.state.Wait = WaitNone
+		e.state.Deadline = time.Time{}
+		e.state.Attempt = 0
+		e.state.Input = ""
+
+		action := Action{Step: name, Variables: maps.Clone(e.state.Variables)}
+		switch {
+		case step.Prompt != nil:
+			action.Kind, action.Prompt = ActionPrompt, step.Prompt
+			actions = append(actions, action)
+			if step.Prompt.Wait > 0 {
+				e.state.Wait, e.state.Deadline = WaitPrompt, now.Add(step.Prompt.Wait)
+				return actions
+			}
+			name = step.Next
+
+		case step.Collect != nil:
+			return e.collect(step, now, actions)
+
+		case step.Handoff != nil:
+			action.Kind, action.Handoff = ActionHandoff, step.Handoff
+			actions = append(actions, action)
+			name = step.Next
+
+		case step.Transfer != nil:
+			action.Kind, action.Transfer = ActionTransfer, step.Transfer
+			e.state.Wait = WaitTransfer
+			return append(actions, action)
+
+		case step.Hangup:
+			action.Kind = ActionHangup
+			e.finish()
+			return append(actions, action)
+
+		default:
+			name = ""
+		}
+	}
+
+	// steps without waits leading into each other
+	e.finish()
+	return actions
+}
+
+// collect starts waiting for input, prompting for it first
+func (e *Execution) collect(step Step, now time.Time, actions []Action) []Action {
+	e.state.Wait = WaitInput
+	e.state.Deadline = now.Add(collectTimeout(step.Collect))
+	e.state.Input = ""
+	if step.Collect.Prompt != nil {
+		actions = append(actions, Action{
+			Kind:      ActionPrompt,
+			Step:      e.state.Step,
+			Prompt:    step.Collect.Prompt,
+			Variables: maps.Clone(e.state.Variables),
+		})
+	}
+	return actions
+}
+
+func (e *Execution) complete(step Step, input string, now time.Time) []Action {
+	target, ok := step.Next, true
+	if len(step.Collect.Routes) != 0 {
+		target, ok = matchRoute(step.Collect.Routes, input)
+	}
+	if !ok {
+		return e.noMatch(step, now)
+	}
+	if step.Collect.Variable != "" {
+		if e.state.Variables == nil {
+			e.state.Variables = make(map[string]string)
+		}
+		e.state.Variables[step.Collect.Variable] = input
+	}
+	return e.enter(target, now, nil)
+}
+
+func (e *Execution) noMatch(step Step, now time.Time) []Action {
+	if e.state.Attempt < step.Collect.Retries {
+		attempt := e.state.Attempt + 1
+		actions := e.collect(step, now, nil)
+		e.state.Attempt = attempt
+		return actions
+	}
+	return e.enter(step.Collect.Default, now, nil)
+}
+
+func collectTimeout(collect *Collect) time.Duration {
+	if collect.Timeout <= 0 {
+		return DefaultCollectTimeout
+	}
+	return collect.Timeout
+}
+
+// matchRoute returns the step of the route matching input, an exact match wins over a word of speech
+// matching, which wins over `*`
+func matchRoute(routes map[string]string, input string) (string, bool) {
+	if target, ok := routes[input]; ok {
+		return target, true
+	}
+
+	words := " " + normalizeWords(input) + " "
+	best, bestKey := "", ""
+	for key, target := range routes {
+		normalized := normalizeWords(key)
+		if normalized == "" || !strings.Contains(words, " "+normalized+" ") {
+			continue
+		}
+		// the longest, then lowest key wins so that matching does not depend on map order
+		if len(key) > len(bestKey) || (len(key) == len(bestKey) && key < bestKey) {
+			best, bestKey = target, key
+		}
+	}
+	if bestKey != "" {
+		return best, true
+	}
+
+	target, ok := routes["*"]
+	return target, ok
+}
+
+// normalizeWords lowercases text and separates its words by single spaces, dropping punctuation
+func normalizeWords(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package callflow
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testFlow() *Flow {
+	return &Flow{
+		Start: "welcome",
+		Steps: map[string]Step{
+			"welcome": {Prompt: &Prompt{Text: "Welcome"}, Next: "menu"},
+			"menu": {Collect: &Collect{
+				Prompt:   &Prompt{Text: "Press 1 for sales or say support"},
+				Speech:   true,
+				Timeout:  time.Second,
+				Retries:  1,
+				Routes:   map[string]string{"1": "sales", "support": "agent", "tech support": "tech"},
+				Default:  "agent",
+				Variable: "choice",
+			}},
+			"sales": {Transfer: &Transfer{To: "tel:+15550100", Failed: "sorry"}},
+			"agent": {Handoff: &Handoff{AgentName: "support"}},
+			"tech":  {Handoff: &Handoff{AgentName: "tech"}, Next: "bye"},
+			"sorry": {Prompt: &Prompt{Text: "Sorry", Wait: 2 * time.Second}, Next: "bye"},
+			"bye":   {Hangup: true},
+		},
+	}
+}
+
+func actionKinds(actions []Action) []ActionKind {
+	kinds := make([]ActionKind, 0, len(actions))
+	for _, a := range actions {
+		kinds = append(kinds, a.Kind)
+	}
+	return kinds
+}
+
+func TestFlowValidate(t *testing.T) {
+	require.NoError(t, testFlow().Validate())
+
+	f := testFlow()
+	f.Start = "missing"
+	require.ErrorIs(t, f.Validate(), ErrInvalidFlow)
+
+	f = testFlow()
+	f.Steps["menu"].Collect.Routes["2"] = "missing"
+	require.ErrorIs(t, f.Validate(), ErrInvalidFlow)
+
+	f = testFlow()
+	f.Steps["both"] = Step{Prompt: &Prompt{}, Hangup: true}
+	require.ErrorIs(t, f.Validate(), ErrInvalidFlow)
+}
+
+func TestExecutionDTMF(t *testing.T) {
+	now := time.Now()
+	e := NewExecution("support", testFlow())
+
+	actions := e.Start(now)
+	require.Equal(t, []ActionKind{ActionPrompt, ActionPrompt}, actionKinds(actions))
+	require.Equal(t, "Welcome", actions[0].Prompt.Text)
+	require.Equal(t, "menu", e.State().Step)
+	require.Equal(t, WaitInput, e.State().Wait)
+
+	actions = e.OnDigit("1", now)
+	require.Equal(t, []ActionKind{ActionTransfer}, actionKinds(actions))
+	require.Equal(t, "tel:+15550100", actions[0].Transfer.To)
+	require.Equal(t, "1", actions[0].Variables["choice"])
+
+	// a failed transfer continues at the failure step, which waits for its prompt
+	actions = e.OnTransferResult(errors.New("busy"), now)
+	require.Equal(t, []ActionKind{ActionPrompt}, actionKinds(actions))
+	require.Equal(t, WaitPrompt, e.State().Wait)
+	require.Empty(t, e.OnPromptDone("menu", now))
+
+	actions = e.OnPromptDone("sorry", now)
+	require.Equal(t, []ActionKind{ActionHangup}, actionKinds(actions))
+	require.True(t, e.Done())
+}
+
+func TestExecutionSpeechAndRetries(t *testing.T) {
+	now := time.Now()
+	e := NewExecution("support", testFlow())
+	e.Start(now)
+
+	// no input, the prompt is repeated once
+	require.Empty(t, e.OnTimer(now.Add(500*time.Millisecond)))
+	actions := e.OnTimer(now.Add(time.Second))
+	require.Equal(t, []ActionKind{ActionPrompt}, actionKinds(actions))
+	require.Equal(t, 1, e.State().Attempt)
+
+	// unmatched speech after the retries goes to the default step
+	actions = e.OnSpeech("I have a question", now.Add(2*time.Second))
+	require.Equal(t, []ActionKind{ActionHandoff}, actionKinds(actions))
+	require.Equal(t, "support", actions[0].Handoff.AgentName)
+	require.True(t, e.Done())
+
+	// the longest matching phrase wins
+	e = NewExecution("support", testFlow())
+	e.Start(now)
+	actions = e.OnSpeech("I need Tech Support, please.", now)
+	require.Equal(t, []ActionKind{ActionHandoff, ActionHangup}, actionKinds(actions))
+	require.Equal(t, "tech", actions[0].Handoff.AgentName)
+	require.Equal(t, "I need Tech Support, please.", actions[0].Variables["choice"])
+}
+
+func TestExecutionMultipleDigits(t *testing.T) {
+	now := time.Now()
+	flow := &Flow{
+		Start: "account",
+		Steps: map[string]Step{
+			"account": {Collect: &Collect{MaxDigits: 6, Terminator: "#", Variable: "account"}, Next: "agent"},
+			"agent":   {Handoff: &Handoff{AgentName: "billing"}},
+		},
+	}
+	require.NoError(t, flow.Validate())
+
+	e := NewExecution("billing", flow)
+	require.Empty(t, e.Start(now))
+	require.Empty(t, e.OnDigit("4", now))
+	require.Empty(t, e.OnDigit("2", now))
+
+	// resumed from the persisted state
+	e, err := ResumeExecution(flow, e.State())
+	require.NoError(t, err)
+	actions := e.OnDigit("#", now)
+	require.Equal(t, []ActionKind{ActionHandoff}, actionKinds(actions))
+	require.Equal(t, map[string]string{"account": "42"}, actions[0].Variables)
+
+	_, err = ResumeExecution(flow, State{Step: "missing"})
+	require.ErrorIs(t, err, ErrUnknownStep)
+}
+
+func TestExecutionLoop(t *testing.T) {
+	flow := &Flow{
+		Start: "a",
+		Steps: map[string]Step{
+			"a": {Prompt: &Prompt{Text: "a"}, Next: "b"},
+			"b": {Prompt: &Prompt{Text: "b"}, Next: "a"},
+		},
+	}
+	e := NewExecution("loop", flow)
+	require.Len(t, e.Start(time.Now()), maxTransitions)
+	require.True(t, e.Done())
+}
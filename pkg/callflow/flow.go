@@ -0,0 +1,176 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package callflow runs declarative call flows, e. g. simple IVR menus, for participants of agent rooms:
+// prompts, collection of DTMF or spoken input, handoff to an agent and SIP transfers, without an
+// external orchestrator. The package holds the flow definitions and the state machine executing them,
+// the room performs the actions.
+package callflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// participant attribute or key of a JSON object room metadata naming the flow a participant runs,
+	// the attribute wins over the room metadata which applies to SIP participants only
+	FlowKey = "agentix.call_flow"
+	// topic of the data packets carrying prompts to the participant playing them, and their acknowledgements
+	Topic = "agentix.call_flow"
+
+	DefaultCollectTimeout = 5 * time.Second
+)
+
+var (
+	ErrInvalidFlow = errors.New("invalid call flow")
+	ErrUnknownStep = errors.New("unknown call flow step")
+)
+
+// Flow is a graph of steps starting at Start, e. g. in YAML
+//
+//	start: menu
+//	steps:
+//	  menu:
+//	    collect:
+//	      prompt: {text: "Press 1 for sales or say support"}
+//	      speech: true
+//	      routes: {"1": sales, "support": agent}
+//	      default: agent
+//	  sales:
+//	    transfer: {to: "tel:+15550100"}
+//	  agent:
+//	    handoff: {agent_name: support}
+type Flow struct {
+	Start string          `json:"start,omitempty" yaml:"start,omitempty"`
+	Steps map[string]Step `json:"steps,omitempty" yaml:"steps,omitempty"`
+}
+
+// Step performs exactly one action
+type Step struct {
+	// sends a prompt to be played
+	Prompt *Prompt `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	// collects DTMF or spoken input and branches on it
+	Collect *Collect `json:"collect,omitempty" yaml:"collect,omitempty"`
+	// dispatches an agent to the room
+	Handoff *Handoff `json:"handoff,omitempty" yaml:"handoff,omitempty"`
+	// transfers a SIP participant
+	Transfer *Transfer `json:"transfer,omitempty" yaml:"transfer,omitempty"`
+	// removes the participant from the room
+	Hangup bool `json:"hangup,omitempty" yaml:"hangup,omitempty"`
+	// step after a prompt, a handoff or a collect without routes, the flow ends without one
+	Next string `json:"next,omitempty" yaml:"next,omitempty"`
+}
+
+// Prompt is played by a participant of the room, e. g. an agent speaking Text, the server has no
+// speech synthesis. It is sent as data on Topic to all participants.
+type Prompt struct {
+	Text     string `json:"text,omitempty" yaml:"text,omitempty"`
+	AudioURL string `json:"audio_url,omitempty" yaml:"audio_url,omitempty"`
+	// longest time the flow waits for the prompt to be acknowledged as played before moving on,
+	// 0 moves on right away. Prompts of collect steps never wait, input may interrupt them.
+	Wait time.Duration `json:"wait,omitempty" yaml:"wait,omitempty"`
+}
+
+type Collect struct {
+	// played when collection starts and again with each retry
+	Prompt *Prompt `json:"prompt,omitempty" yaml:"prompt,omitempty"`
+	// digits ending the input, defaults to 1
+	MaxDigits int `json:"max_digits,omitempty" yaml:"max_digits,omitempty"`
+	// digit ending the input early, not part of it
+	Terminator string `json:"terminator,omitempty" yaml:"terminator,omitempty"`
+	// final transcriptions of the participant are taken as input
+	Speech bool `json:"speech,omitempty" yaml:"speech,omitempty"`
+	// time to wait for input and between digits, defaults to 5s
+	Timeout time.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// times the prompt is repeated when nothing matched
+	Retries int `json:"retries,omitempty" yaml:"retries,omitempty"`
+	// step per input, digits match exactly, speech when it contains the key as a word, `*` matches any input
+	Routes map[string]string `json:"routes,omitempty" yaml:"routes,omitempty"`
+	// step when nothing matched after the retries, the flow ends without one
+	Default string `json:"default,omitempty" yaml:"default,omitempty"`
+	// variable the matched input is stored in, passed on to handoffs
+	Variable string `json:"variable,omitempty" yaml:"variable,omitempty"`
+}
+
+type Handoff struct {
+	AgentName string `json:"agent_name,omitempty" yaml:"agent_name,omitempty"`
+	// job metadata of the agent, the variables of the flow are added to it as JSON when it is empty
+	Metadata string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+}
+
+type Transfer struct {
+	// SIP URI or tel: number
+	To           string `json:"to,omitempty" yaml:"to,omitempty"`
+	PlayDialtone bool   `json:"play_dialtone,omitempty" yaml:"play_dialtone,omitempty"`
+	// step when the transfer fails, the flow ends without one
+	Failed string `json:"failed,omitempty" yaml:"failed,omitempty"`
+}
+
+func (s *Step) actions() int {
+	n := 0
+	for _, set := range []bool{s.Prompt != nil, s.Collect != nil, s.Handoff != nil, s.Transfer != nil, s.Hangup} {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// Validate checks that every step performs one action and every step referenced exists
+func (f *Flow) Validate() error {
+	if _, ok := f.Steps[f.Start]; !ok {
+		return fmt.Errorf("%w: start step %q does not exist", ErrInvalidFlow, f.Start)
+	}
+
+	names := make([]string, 0, len(f.Steps))
+	for name := range f.Steps {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		step := f.Steps[name]
+		if step.actions() != 1 {
+			return fmt.Errorf("%w: step %q must have exactly one of prompt, collect, handoff, transfer or hangup", ErrInvalidFlow, name)
+		}
+
+		targets := []string{step.Next}
+		switch {
+		case step.Collect != nil:
+			for _, target := range step.Collect.Routes {
+				targets = append(targets, target)
+			}
+			targets = append(targets, step.Collect.Default)
+		case step.Handoff != nil:
+			if step.Handoff.AgentName == "" {
+				return fmt.Errorf("%w: step %q hands off without an agent name", ErrInvalidFlow, name)
+			}
+		case step.Transfer != nil:
+			if step.Transfer.To == "" {
+				return fmt.Errorf("%w: step %q transfers without a destination", ErrInvalidFlow, name)
+			}
+			targets = append(targets, step.Transfer.Failed)
+		}
+		for _, target := range targets {
+			if _, ok := f.Steps[target]; target != "" && !ok {
+				return fmt.Errorf("%w: step %q leads to %q, which does not exist", ErrInvalidFlow, name, target)
+			}
+		}
+	}
+	return nil
+}
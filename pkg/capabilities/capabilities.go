@@ -0,0 +1,79 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities
+
+// names of the optional subsystems a node reports
+const (
+	NoiseFilter = "noise_filter"
+	Opus        = "opus"
+	GPUDSP      = "gpu_dsp"
+	AudioMixing = "audio_mixing"
+	MLExport    = "ml_export"
+	SIP         = "sip"
+	Ingress     = "ingress"
+	Egress      = "egress"
+	STT         = "stt"
+	Placement   = "placement"
+)
+
+// Subsystem describes an optional part of the server. Compiled is false when the build lacks it,
+// e. g. a build without the `opus` tag, Enabled is true when it is compiled and turned on.
+type Subsystem struct {
+	Name     string `json:"name"`
+	Compiled bool   `json:"compiled"`
+	Enabled  bool   `json:"enabled"`
+	// implementation in use, e. g. rnnoise or libopus
+	Backend string `json:"backend,omitempty"`
+	// formats or providers offered, e. g. egress file formats or speech to text providers
+	Options []string `json:"options,omitempty"`
+	// why a compiled subsystem is unavailable
+	Reason string `json:"reason,omitempty"`
+}
+
+// Capabilities lists what a node is able to do, for orchestrators to route rooms
+// to nodes equipped for them
+type Capabilities struct {
+	NodeID     string      `json:"node_id"`
+	Region     string      `json:"region,omitempty"`
+	Version    string      `json:"version"`
+	Ready      bool        `json:"ready"`
+	Subsystems []Subsystem `json:"subsystems"`
+}
+
+func (c *Capabilities) Get(name string) (Subsystem, bool) {
+	for _, s := range c.Subsystems {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Subsystem{}, false
+}
+
+// Supports returns true if the subsystem is compiled and enabled on the node
+func (c *Capabilities) Supports(name string) bool {
+	s, ok := c.Get(name)
+	return ok && s.Compiled && s.Enabled
+}
+
+// SupportsAll returns the first required subsystem the node does not support, empty if it supports all of them
+func (c *Capabilities) SupportsAll(names ...string) string {
+	for _, name := range names {
+		if !c.Supports(name) {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package capabilities
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	c := &Capabilities{
+		NodeID:  "ND_test",
+		Version: "1.0.0",
+		Ready:   true,
+		Subsystems: []Subsystem{
+			{Name: NoiseFilter, Compiled: true, Enabled: true, Backend: "rnnoise"},
+			{Name: Opus, Compiled: false, Reason: "built without the opus tag"},
+			{Name: SIP, Compiled: true, Enabled: false},
+		},
+	}
+
+	require.True(t, c.Supports(NoiseFilter))
+	require.False(t, c.Supports(Opus))
+	require.False(t, c.Supports(SIP))
+	require.False(t, c.Supports(GPUDSP))
+
+	require.Equal(t, "", c.SupportsAll(NoiseFilter))
+	require.Equal(t, Opus, c.SupportsAll(NoiseFilter, Opus, SIP))
+
+	s, ok := c.Get(NoiseFilter)
+	require.True(t, ok)
+	require.Equal(t, "rnnoise", s.Backend)
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	var decoded Capabilities
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, *c, decoded)
+}
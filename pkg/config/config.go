@@ -27,13 +27,39 @@ import (
 	"gopkg.in/yaml.v3"
 
 	"github.com/livekit/livekit-server/pkg/agent"
+	"github.com/livekit/livekit-server/pkg/audioinject"
+	"github.com/livekit/livekit-server/pkg/b2bua"
+	"github.com/livekit/livekit-server/pkg/callflow"
+	"github.com/livekit/livekit-server/pkg/eventexport"
+	"github.com/livekit/livekit-server/pkg/latencyprobe"
+	"github.com/livekit/livekit-server/pkg/metadata"
 	"github.com/livekit/livekit-server/pkg/metric"
+	"github.com/livekit/livekit-server/pkg/mlexport"
+	"github.com/livekit/livekit-server/pkg/nativecheck"
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/preflight"
+	"github.com/livekit/livekit-server/pkg/realtime"
+	"github.com/livekit/livekit-server/pkg/replay"
+	"github.com/livekit/livekit-server/pkg/rtc/moderation"
+	"github.com/livekit/livekit-server/pkg/rtc/reaper"
+	"github.com/livekit/livekit-server/pkg/rtc/talkstats"
+	"github.com/livekit/livekit-server/pkg/rtc/watchdog"
+	"github.com/livekit/livekit-server/pkg/rtmp"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/bwe/remotebwe"
 	"github.com/livekit/livekit-server/pkg/sfu/bwe/sendsidebwe"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+	"github.com/livekit/livekit-server/pkg/sfu/memtrack"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
 	"github.com/livekit/livekit-server/pkg/sfu/streamallocator"
+	"github.com/livekit/livekit-server/pkg/sip"
+	"github.com/livekit/livekit-server/pkg/srt"
+	"github.com/livekit/livekit-server/pkg/supportbundle"
+	"github.com/livekit/livekit-server/pkg/syntheticmonitor"
+	"github.com/livekit/livekit-server/pkg/transcription"
 	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
@@ -83,6 +109,45 @@ type Config struct {
 	Metric metric.MetricConfig `yaml:"metric,omitempty"`
 
 	NodeStats NodeStatsConfig `yaml:"node_stats,omitempty"`
+
+	Placement placement.Config `yaml:"placement,omitempty"`
+
+	NativeCheck nativecheck.Config `yaml:"native_check,omitempty"`
+
+	LatencyProbe latencyprobe.Config `yaml:"latency_probe,omitempty"`
+
+	SyntheticMonitor syntheticmonitor.Config `yaml:"synthetic_monitor,omitempty"`
+
+	// batched export of room, track, quality and processing events to ClickHouse or BigQuery
+	EventExport eventexport.Config `yaml:"event_export,omitempty"`
+
+	// gRPC streaming of the decoded audio of tracks to consumers outside of the server, e. g. STT engines
+	PCMTap pcmtap.Config `yaml:"pcm_tap,omitempty"`
+
+	// gRPC service playing audio into rooms as server side tracks, e. g. TTS of agent backends
+	AudioInject audioinject.Config `yaml:"audio_injection,omitempty"`
+
+	// bridges relaying tracks to realtime speech models, OpenAI Realtime or Gemini Live
+	Realtime realtime.Config `yaml:"realtime_bridge,omitempty"`
+
+	// SIP gateway answering phone calls of SIP trunks, callers join the rooms of the numbers they dialed
+	SIPGateway sip.Config `yaml:"sip_gateway,omitempty"`
+
+	// RTMP and RTMPS ingest of broadcast encoders, streams are published into the rooms of their stream keys
+	RTMPIngest rtmp.Config `yaml:"rtmp_ingest,omitempty"`
+
+	// SRT ingest of broadcast encoders, listening for their calls with the token as stream id and calling those
+	// configured, the programs of their MPEG-TS are published into rooms
+	SRTIngest srt.Config `yaml:"srt_ingest,omitempty"`
+
+	// memory held per DSP stage and instances outliving their streams
+	DSPMemory memtrack.Config `yaml:"dsp_memory,omitempty"`
+
+	// startup self-test suite run with --preflight
+	Preflight preflight.Config `yaml:"preflight,omitempty"`
+
+	// where the values of fields come from, see EffectiveConfig
+	provenance configProvenance
 }
 
 type RTCConfig struct {
@@ -125,6 +190,25 @@ type RTCConfig struct {
 	DatachannelSlowThreshold int `yaml:"datachannel_slow_threshold,omitempty"`
 
 	ForwardStats ForwardStatsConfig `yaml:"forward_stats,omitempty"`
+
+	// sampled per-packet timing through the receive stages of the buffers
+	FlightRecorder sfuinterceptor.FlightRecorderConfig `yaml:"flight_recorder,omitempty"`
+
+	// rollout of instrumentation under validation in the receive stages of the buffers
+	Canary sfuinterceptor.CanaryConfig `yaml:"canary,omitempty"`
+
+	// recording of received RTP and signalling of participants for deterministic replay
+	Capture replay.CaptureConfig `yaml:"capture,omitempty"`
+
+	// ICE consent freshness and dead peer detection
+	ICEConsent ICEConsentConfig `yaml:"ice_consent,omitempty"`
+
+	// sharing of forwarded payloads between subscribers of large rooms
+	FanOut sfu.FanOutConfig `yaml:"fan_out,omitempty"`
+
+	// sockets sharing each UDP mux address with SO_REUSEPORT, each with its own read loop.
+	// 0 or 1 reads with a single socket, sharding is only supported on linux.
+	UDPMuxShards int `yaml:"udp_mux_shards,omitempty"`
 }
 
 type TURNServer struct {
@@ -150,6 +234,54 @@ type CongestionControlConfig struct {
 	SendSideBWE      sendsidebwe.SendSideBWEConfig `yaml:"send_side_bwe,omitempty"`
 }
 
+type ICEConsentConfig struct {
+	// time without consent freshness responses before a connection is disconnected
+	DisconnectedTimeout time.Duration `yaml:"disconnected_timeout,omitempty"`
+	// time between disconnected and failed
+	FailedTimeout time.Duration `yaml:"failed_timeout,omitempty"`
+	// interval of consent freshness checks and keepalives
+	KeepaliveInterval time.Duration `yaml:"keepalive_interval,omitempty"`
+
+	DeadPeer DeadPeerConfig `yaml:"dead_peer,omitempty"`
+}
+
+type DeadPeerAction string
+
+const (
+	// release per-peer processing state, e. g. denoisers, until media resumes
+	DeadPeerActionPause DeadPeerAction = "pause"
+	// handle like a failed transport, the client is asked to resume and the participant is closed if it does not
+	DeadPeerActionClose DeadPeerAction = "close"
+)
+
+// DeadPeerConfig detects peers that stopped sending media and RTCP well before ICE consent expires
+type DeadPeerConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// time without RTP or RTCP from a peer after which it is considered dead
+	Timeout time.Duration  `yaml:"timeout,omitempty"`
+	Action  DeadPeerAction `yaml:"action,omitempty"`
+}
+
+func (c ICEConsentConfig) Validate() error {
+	if c.KeepaliveInterval <= 0 || c.DisconnectedTimeout <= 0 || c.FailedTimeout <= 0 {
+		return errors.New("ice consent timeouts must be positive")
+	}
+	if c.KeepaliveInterval >= c.DisconnectedTimeout {
+		return fmt.Errorf("ice keepalive interval %s must be shorter than disconnected timeout %s", c.KeepaliveInterval, c.DisconnectedTimeout)
+	}
+	if c.DeadPeer.Enabled {
+		if c.DeadPeer.Timeout <= 0 {
+			return errors.New("dead peer timeout must be positive")
+		}
+		switch c.DeadPeer.Action {
+		case DeadPeerActionPause, DeadPeerActionClose:
+		default:
+			return fmt.Errorf("invalid dead peer action %q", c.DeadPeer.Action)
+		}
+	}
+	return nil
+}
+
 type PlayoutDelayConfig struct {
 	Enabled bool `yaml:"enabled,omitempty"`
 	Min     int  `yaml:"min,omitempty"`
@@ -163,6 +295,72 @@ type VideoConfig struct {
 	CodecRegressionThreshold int `yaml:"codec_regression_threshold,omitempty"`
 }
 
+// CaptionsConfig distributes the transcripts of a room as captions to the participants that ask for them
+// with their attributes, in the languages they select
+type CaptionsConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// interim transcripts are sent as well, not only final ones
+	Interim bool `yaml:"interim,omitempty"`
+}
+
+type TrackMirrorConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// only rooms with this name prefix accept mirrored tracks, keeping QA rooms apart from customer rooms
+	RoomPrefix string `yaml:"room_prefix,omitempty"`
+}
+
+// WarmPoolConfig keeps rooms with a connected agent on standby, a call claims one instead of
+// waiting for room creation, agent dispatch and the agent's negotiation
+type WarmPoolConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// rooms kept on standby
+	Size int `yaml:"size,omitempty"`
+	// name prefix of standby rooms
+	RoomPrefix string `yaml:"room_prefix,omitempty"`
+	// agent dispatched into standby rooms, empty starts any agent
+	AgentName string `yaml:"agent_name,omitempty"`
+	// dispatch metadata of the agent
+	AgentMetadata string `yaml:"agent_metadata,omitempty"`
+	// standby rooms the agent has not joined within this time are replaced
+	ReadyTimeout time.Duration `yaml:"ready_timeout,omitempty"`
+	// standby rooms are replaced after this time, keep it below the idle reaper's room timeout
+	MaxAge time.Duration `yaml:"max_age,omitempty"`
+	// a claimed room stays open this long for the caller to join
+	ClaimTimeout time.Duration `yaml:"claim_timeout,omitempty"`
+}
+
+var DefaultWarmPoolConfig = WarmPoolConfig{
+	Size:         2,
+	RoomPrefix:   "warm-",
+	ReadyTimeout: 30 * time.Second,
+	MaxAge:       15 * time.Minute,
+	ClaimTimeout: 30 * time.Second,
+}
+
+// ProcessingBypassConfig bounds the emergency bypass reverting a room to pure forwarding
+type ProcessingBypassConfig struct {
+	// how long processing stays bypassed when no duration is requested
+	DefaultDuration time.Duration `yaml:"default_duration,omitempty"`
+	// processing is re-enabled after this time at the latest
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+	// switches kept in the event trail of a room
+	MaxEvents int `yaml:"max_events,omitempty"`
+}
+
+var DefaultProcessingBypassConfig = ProcessingBypassConfig{
+	DefaultDuration: 15 * time.Minute,
+	MaxDuration:     4 * time.Hour,
+	MaxEvents:       50,
+}
+
+// ConsentConfig makes recording, transcription and analytics of a participant's media subject
+// to the consent flags the participant carries
+type ConsentConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// flags of participants that do not carry any, e. g. [transcription], none if empty
+	Default []string `yaml:"default,omitempty"`
+}
+
 type RoomConfig struct {
 	// enable rooms to be automatically created
 	AutoCreate         bool               `yaml:"auto_create,omitempty"`
@@ -185,6 +383,40 @@ type RoomConfig struct {
 	// deprecated, moved to limits
 	MaxParticipantIdentityLength int                                   `yaml:"max_participant_identity_length,omitempty"`
 	RoomConfigurations           map[string]*livekit.RoomConfiguration `yaml:"room_configurations,omitempty"`
+	// consent based export of audio, transcripts and voice activity for training
+	MLExport mlexport.Config `yaml:"ml_export,omitempty"`
+	// Opus parameters enforced on publishers
+	OpusFmtp audio.OpusFmtpConfig `yaml:"opus_fmtp,omitempty"`
+	// detection and recovery of stuck published tracks
+	TrackWatchdog watchdog.Config `yaml:"track_watchdog,omitempty"`
+	// how room and participant metadata updates are applied
+	Metadata metadata.Config `yaml:"metadata,omitempty"`
+	// slow mode, send bans and validation of data sent by participants
+	DataModeration moderation.Config `yaml:"data_moderation,omitempty"`
+	// in memory capture of room logs for support bundles
+	SupportBundle supportbundle.Config `yaml:"support_bundle,omitempty"`
+	// read-only copies of tracks into QA rooms
+	TrackMirror TrackMirrorConfig `yaml:"track_mirror,omitempty"`
+	// disconnecting idle participants and closing idle rooms
+	IdleReaper reaper.Config `yaml:"idle_reaper,omitempty"`
+	// emergency switch disabling optional processing of a room
+	ProcessingBypass ProcessingBypassConfig `yaml:"processing_bypass,omitempty"`
+	// talk time, interruptions and speech rate per participant
+	TalkAnalytics talkstats.Config `yaml:"talk_analytics,omitempty"`
+	// per participant consent to recording, transcription and analytics
+	Consent ConsentConfig `yaml:"consent,omitempty"`
+	// standby rooms with connected agents claimed by incoming calls
+	WarmPool WarmPoolConfig `yaml:"warm_pool,omitempty"`
+	// named noise filter overrides rooms select through their metadata
+	NoiseFilterPresets map[string]audio.NoiseFilterOverride `yaml:"noise_filter_presets,omitempty"`
+	// named call flows participants run when they select one through their attributes or room metadata
+	CallFlows map[string]callflow.Flow `yaml:"call_flows,omitempty"`
+	// back-to-back user agent rooms bridging two legs, recorded for compliance
+	B2BUA b2bua.Config `yaml:"b2bua,omitempty"`
+	// speech to text of published audio tracks, rooms select provider and language through their metadata
+	Transcription transcription.Config `yaml:"transcription,omitempty"`
+	// captions fanned out to participants on a data topic of their own
+	Captions CaptionsConfig `yaml:"captions,omitempty"`
 }
 
 type CodecSpec struct {
@@ -339,6 +571,19 @@ var DefaultConfig = Config{
 		PacketBufferSizeVideo: 500,
 		PacketBufferSizeAudio: 200,
 		PLIThrottle:           sfu.DefaultPLIThrottleConfig,
+		FlightRecorder:        sfuinterceptor.DefaultFlightRecorderConfig,
+		Canary:                sfuinterceptor.DefaultCanaryConfig,
+		Capture:               replay.DefaultCaptureConfig,
+		FanOut:                sfu.DefaultFanOutConfig,
+		ICEConsent: ICEConsentConfig{
+			DisconnectedTimeout: 10 * time.Second,
+			FailedTimeout:       5 * time.Second,
+			KeepaliveInterval:   2 * time.Second,
+			DeadPeer: DeadPeerConfig{
+				Timeout: 3 * time.Second,
+				Action:  DeadPeerActionPause,
+			},
+		},
 		CongestionControl: CongestionControlConfig{
 			Enabled:                   true,
 			AllowPause:                false,
@@ -377,6 +622,19 @@ var DefaultConfig = Config{
 		CreateRoomTimeout:     10 * time.Second,
 		CreateRoomAttempts:    3,
 		UpdateBatchTargetSize: 128 * 1024,
+		MLExport:              mlexport.DefaultConfig,
+		B2BUA:                 b2bua.DefaultConfig,
+		TrackWatchdog:         watchdog.DefaultConfig,
+		DataModeration:        moderation.DefaultConfig,
+		SupportBundle:         supportbundle.DefaultConfig,
+		TrackMirror: TrackMirrorConfig{
+			RoomPrefix: "qa-",
+		},
+		IdleReaper:       reaper.DefaultConfig,
+		ProcessingBypass: DefaultProcessingBypassConfig,
+		TalkAnalytics:    talkstats.DefaultConfig,
+		WarmPool:         DefaultWarmPoolConfig,
+		Transcription:    transcription.DefaultConfig,
 	},
 	Limit: LimitConfig{
 		MaxMetadataSize:              64000,
@@ -405,11 +663,25 @@ var DefaultConfig = Config{
 		StreamBufferSize: 1000,
 		ConnectAttempts:  3,
 	},
-	PSRPC:     rpc.DefaultPSRPCConfig,
-	Keys:      map[string]string{},
-	Metric:    metric.DefaultMetricConfig,
-	WebHook:   webhook.DefaultWebHookConfig,
-	NodeStats: DefaultNodeStatsConfig,
+	PSRPC:            rpc.DefaultPSRPCConfig,
+	Agents:           agent.DefaultConfig,
+	Keys:             map[string]string{},
+	Metric:           metric.DefaultMetricConfig,
+	WebHook:          webhook.DefaultWebHookConfig,
+	NodeStats:        DefaultNodeStatsConfig,
+	Placement:        placement.DefaultConfig,
+	NativeCheck:      nativecheck.DefaultConfig,
+	LatencyProbe:     latencyprobe.DefaultConfig,
+	SyntheticMonitor: syntheticmonitor.DefaultConfig,
+	EventExport:      eventexport.DefaultConfig,
+	PCMTap:           pcmtap.DefaultConfig,
+	AudioInject:      audioinject.DefaultConfig,
+	Realtime:         realtime.DefaultConfig,
+	SIPGateway:       sip.DefaultConfig,
+	RTMPIngest:       rtmp.DefaultConfig,
+	SRTIngest:        srt.DefaultConfig,
+	DSPMemory:        memtrack.DefaultConfig,
+	Preflight:        preflight.DefaultConfig,
 }
 
 func NewConfig(confString string, strictMode bool, c *cli.Command, baseFlags []cli.Flag) (*Config, error) {
@@ -425,23 +697,60 @@ func NewConfig(confString string, strictMode bool, c *cli.Command, baseFlags []c
 		return nil, err
 	}
 
+	provenance := configProvenance{}
 	if confString != "" {
 		decoder := yaml.NewDecoder(strings.NewReader(confString))
 		decoder.KnownFields(strictMode)
 		if err := decoder.Decode(&conf); err != nil {
 			return nil, fmt.Errorf("could not parse config: %v", err)
 		}
+
+		fileFields, err := flattenYAML([]byte(confString))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse config: %v", err)
+		}
+		for path := range fileFields {
+			provenance[path] = ConfigSourceFile
+		}
 	}
 
 	if c != nil {
+		beforeCLI, err := flattenConfig(&conf)
+		if err != nil {
+			return nil, err
+		}
 		if err := conf.updateFromCLI(c, baseFlags); err != nil {
 			return nil, err
 		}
+		afterCLI, err := flattenConfig(&conf)
+		if err != nil {
+			return nil, err
+		}
+		provenance.recordChanges(beforeCLI, afterCLI, ConfigSourceCLI)
 	}
 
 	if err := conf.RTC.Validate(conf.Development); err != nil {
 		return nil, fmt.Errorf("could not validate RTC config: %v", err)
 	}
+	if err := conf.RTC.ICEConsent.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate RTC config: %v", err)
+	}
+	if err := conf.Room.OpusFmtp.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate room config: %v", err)
+	}
+	if err := conf.Audio.Framing.Validate(); err != nil {
+		return nil, fmt.Errorf("could not validate audio config: %v", err)
+	}
+	if conf.EventExport.Enabled {
+		if err := conf.EventExport.Validate(); err != nil {
+			return nil, fmt.Errorf("could not validate event export config: %v", err)
+		}
+	}
+
+	beforeDerived, err := flattenConfig(&conf)
+	if err != nil {
+		return nil, err
+	}
 
 	// expand env vars in filenames
 	file, err := homedir.Expand(os.ExpandEnv(conf.KeyFile))
@@ -487,6 +796,13 @@ func NewConfig(confString string, strictMode bool, c *cli.Command, baseFlags []c
 		conf.Limit.MaxRoomNameLength = conf.Room.MaxRoomNameLength
 	}
 
+	afterDerived, err := flattenConfig(&conf)
+	if err != nil {
+		return nil, err
+	}
+	provenance.recordChanges(beforeDerived, afterDerived, ConfigSourceDerived)
+	conf.provenance = provenance
+
 	return &conf, nil
 }
 
@@ -735,6 +1051,10 @@ func (conf *Config) updateFromCLI(c *cli.Command, baseFlags []cli.Flag) error {
 	if c.IsSet("turn-key") {
 		conf.TURN.KeyFile = c.String("turn-key")
 	}
+	if c.IsSet("audio-debug-dump") {
+		conf.Audio.NoiseFilter.DebugDump.Enabled = true
+		conf.Audio.NoiseFilter.DebugDump.Directory = c.String("audio-debug-dump")
+	}
 	if c.IsSet("node-ip") {
 		conf.RTC.NodeIP = c.String("node-ip")
 	}
@@ -83,3 +83,35 @@ func TestGeneratedFlags(t *testing.T) {
 func TestYAMLTag(t *testing.T) {
 	require.NoError(t, configtest.CheckYAMLTags(Config{}))
 }
+
+func TestConfig_EffectiveConfig(t *testing.T) {
+	generatedFlags, err := GenerateCLIFlags(nil, false)
+	require.NoError(t, err)
+
+	c := &cli.Command{}
+	c.Name = "test"
+	c.Flags = append(c.Flags, generatedFlags...)
+	c.Set("prometheus.port", "9999")
+
+	const content = `keys:
+  key1: secret1
+room:
+  empty_timeout: 10
+  max_room_name_length: 64`
+	conf, err := NewConfig(content, true, c, nil)
+	require.NoError(t, err)
+
+	fields, err := conf.EffectiveConfig()
+	require.NoError(t, err)
+	effective := make(map[string]EffectiveField, len(fields))
+	for _, field := range fields {
+		effective[field.Path] = field
+	}
+
+	require.Equal(t, ConfigSourceFile, effective["room.empty_timeout"].Source)
+	require.Equal(t, ConfigSourceCLI, effective["prometheus.port"].Source)
+	require.Equal(t, ConfigSourceDerived, effective["limit.max_room_name_length"].Source)
+	require.Equal(t, ConfigSourceDefault, effective["port"].Source)
+	require.Equal(t, ConfigSourceFile, effective["keys.key1"].Source)
+	require.Equal(t, redactedConfigValue, effective["keys.key1"].Value)
+}
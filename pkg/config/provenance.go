@@ -0,0 +1,160 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigSource is where the effective value of a configuration field comes from
+type ConfigSource string
+
+const (
+	ConfigSourceDefault ConfigSource = "default"
+	ConfigSourceFile    ConfigSource = "file"
+	// command line flags, including their environment variables
+	ConfigSourceCLI ConfigSource = "cli"
+	// set by the server from other fields, e. g. TURN relay ports or legacy limits
+	ConfigSourceDerived ConfigSource = "derived"
+)
+
+const redactedConfigValue = "<redacted>"
+
+// EffectiveField is a configuration field with the value the node is running with
+type EffectiveField struct {
+	// dotted yaml path, e. g. audio.noise_filter.enabled
+	Path   string       `json:"path"`
+	Value  interface{}  `json:"value"`
+	Source ConfigSource `json:"source"`
+}
+
+// configProvenance records the source of every field not coming from the defaults
+type configProvenance map[string]ConfigSource
+
+// flattenConfig returns the yaml representation of v keyed by dotted path, empty fields are left out
+func flattenConfig(v interface{}) (map[string]interface{}, error) {
+	marshalled, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return flattenYAML(marshalled)
+}
+
+func flattenYAML(data []byte) (map[string]interface{}, error) {
+	var tree map[string]interface{}
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]interface{})
+	flattenInto(fields, "", tree)
+	return fields, nil
+}
+
+func flattenInto(fields map[string]interface{}, prefix string, node interface{}) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if len(n) == 0 && prefix != "" {
+			break
+		}
+		for key, child := range n {
+			path := key
+			if prefix != "" {
+				path = prefix + "." + key
+			}
+			flattenInto(fields, path, child)
+		}
+		return
+
+	case []interface{}:
+		// lists of structs, e. g. TURN servers, are split up so that their secrets can be redacted
+		if len(n) == 0 {
+			break
+		}
+		if _, ok := n[0].(map[string]interface{}); !ok {
+			break
+		}
+		for i, child := range n {
+			flattenInto(fields, fmt.Sprintf("%s.%d", prefix, i), child)
+		}
+		return
+	}
+	fields[prefix] = node
+}
+
+// recordChanges attributes every field differing between before and after to source
+func (p configProvenance) recordChanges(before, after map[string]interface{}, source ConfigSource) {
+	for path, value := range after {
+		if previous, ok := before[path]; !ok || fmt.Sprint(previous) != fmt.Sprint(value) {
+			p[path] = source
+		}
+	}
+}
+
+func (p configProvenance) source(path string) ConfigSource {
+	for {
+		if source, ok := p[path]; ok {
+			return source
+		}
+		i := strings.LastIndexByte(path, '.')
+		if i < 0 {
+			return ConfigSourceDefault
+		}
+		// a file setting a whole map or list covers all fields below it
+		path = path[:i]
+	}
+}
+
+// EffectiveConfig lists all non-empty fields of the running configuration sorted by path, together with
+// where their value comes from. Keys, passwords, secrets and tokens are redacted.
+func (conf *Config) EffectiveConfig() ([]EffectiveField, error) {
+	fields, err := flattenConfig(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	effective := make([]EffectiveField, 0, len(fields))
+	for path, value := range fields {
+		if isSecretConfigPath(path) {
+			value = redactedConfigValue
+		}
+		effective = append(effective, EffectiveField{
+			Path:   path,
+			Value:  value,
+			Source: conf.provenance.source(path),
+		})
+	}
+	sort.Slice(effective, func(i, j int) bool {
+		return effective[i].Path < effective[j].Path
+	})
+	return effective, nil
+}
+
+func isSecretConfigPath(path string) bool {
+	if path == "keys" || strings.HasPrefix(path, "keys.") {
+		return true
+	}
+	name := path[strings.LastIndexByte(path, '.')+1:]
+	for _, secret := range []string{"password", "secret", "token", "credential"} {
+		if strings.Contains(name, secret) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,260 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	// tokens from the metadata server are refreshed this long before they expire
+	tokenExpiryMargin = time.Minute
+)
+
+var (
+	errTableNotFound = errors.New("table not found")
+)
+
+type bigQueryField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Mode string `json:"mode,omitempty"`
+}
+
+type bigQueryTimePartitioning struct {
+	Type  string `json:"type"`
+	Field string `json:"field,omitempty"`
+}
+
+type bigQuerySchema struct {
+	Fields []bigQueryField `json:"fields"`
+}
+
+type bigQueryTable struct {
+	TableReference struct {
+		ProjectID string `json:"projectId"`
+		DatasetID string `json:"datasetId"`
+		TableID   string `json:"tableId"`
+	} `json:"tableReference"`
+	Schema           bigQuerySchema            `json:"schema"`
+	TimePartitioning *bigQueryTimePartitioning `json:"timePartitioning,omitempty"`
+}
+
+type bigQueryInsertRow struct {
+	// lets BigQuery drop a batch written again after a timeout
+	InsertID string `json:"insertId,omitempty"`
+	JSON     *Row   `json:"json"`
+}
+
+type bigQueryInsertResponse struct {
+	InsertErrors []struct {
+		Index  int `json:"index"`
+		Errors []struct {
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"errors"`
+	} `json:"insertErrors"`
+}
+
+// bigQuerySink writes events through the streaming insert API of BigQuery,
+// into a table partitioned by day
+type bigQuerySink struct {
+	conf   BigQueryConfig
+	table  string
+	client *http.Client
+
+	tokenLock   sync.Mutex
+	token       string
+	tokenExpiry time.Time
+}
+
+func newBigQuerySink(conf BigQueryConfig, table string) *bigQuerySink {
+	if conf.Endpoint == "" {
+		conf.Endpoint = DefaultConfig.BigQuery.Endpoint
+	}
+	return &bigQuerySink{
+		conf:   conf,
+		table:  table,
+		client: &http.Client{},
+	}
+}
+
+func (s *bigQuerySink) EnsureSchema(ctx context.Context) error {
+	var table bigQueryTable
+	err := s.call(ctx, http.MethodGet, s.tablePath(), nil, &table)
+	if errors.Is(err, errTableNotFound) {
+		table.TableReference.ProjectID = s.conf.ProjectID
+		table.TableReference.DatasetID = s.conf.Dataset
+		table.TableReference.TableID = s.table
+		for _, c := range Columns {
+			table.Schema.Fields = append(table.Schema.Fields, bigQueryField{Name: c.Name, Type: c.BigQueryType})
+		}
+		table.TimePartitioning = &bigQueryTimePartitioning{Type: "DAY", Field: "timestamp"}
+		return s.call(ctx, http.MethodPost, s.datasetPath()+"/tables", &table, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	// tables created by older versions get the columns added since, the schema
+	// in a patch replaces the existing one and must include all columns
+	existing := make(map[string]bool, len(table.Schema.Fields))
+	for _, f := range table.Schema.Fields {
+		existing[f.Name] = true
+	}
+	fields := table.Schema.Fields
+	for _, c := range Columns {
+		if !existing[c.Name] {
+			fields = append(fields, bigQueryField{Name: c.Name, Type: c.BigQueryType, Mode: "NULLABLE"})
+		}
+	}
+	if len(fields) == len(table.Schema.Fields) {
+		return nil
+	}
+	return s.call(ctx, http.MethodPatch, s.tablePath(), &struct {
+		Schema bigQuerySchema `json:"schema"`
+	}{Schema: bigQuerySchema{Fields: fields}}, nil)
+}
+
+func (s *bigQuerySink) Write(ctx context.Context, rows []Row) error {
+	insert := struct {
+		Rows []bigQueryInsertRow `json:"rows"`
+	}{
+		Rows: make([]bigQueryInsertRow, 0, len(rows)),
+	}
+	for i := range rows {
+		insert.Rows = append(insert.Rows, bigQueryInsertRow{InsertID: rows[i].ID, JSON: &rows[i]})
+	}
+
+	var res bigQueryInsertResponse
+	if err := s.call(ctx, http.MethodPost, s.tablePath()+"/insertAll", &insert, &res); err != nil {
+		return err
+	}
+	if len(res.InsertErrors) != 0 {
+		first := res.InsertErrors[0]
+		msg := ""
+		if len(first.Errors) != 0 {
+			msg = first.Errors[0].Reason + ": " + first.Errors[0].Message
+		}
+		return fmt.Errorf("bigquery rejected %d rows, row %d: %s", len(res.InsertErrors), first.Index, msg)
+	}
+	return nil
+}
+
+func (s *bigQuerySink) datasetPath() string {
+	return fmt.Sprintf("/projects/%s/datasets/%s", url.PathEscape(s.conf.ProjectID), url.PathEscape(s.conf.Dataset))
+}
+
+func (s *bigQuerySink) tablePath() string {
+	return s.datasetPath() + "/tables/" + url.PathEscape(s.table)
+}
+
+func (s *bigQuerySink) call(ctx context.Context, method string, path string, in any, out any) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(s.conf.Endpoint, "/")+path, body)
+	if err != nil {
+		return err
+	}
+	token, err := s.accessToken(ctx)
+	if err != nil {
+		return fmt.Errorf("could not get access token: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound && method == http.MethodGet:
+		return errTableNotFound
+	case resp.StatusCode/100 != 2:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("bigquery returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	case out != nil:
+		return json.NewDecoder(resp.Body).Decode(out)
+	default:
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+}
+
+func (s *bigQuerySink) accessToken(ctx context.Context) (string, error) {
+	if s.conf.TokenFile != "" {
+		b, err := os.ReadFile(s.conf.TokenFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(b)), nil
+	}
+
+	s.tokenLock.Lock()
+	defer s.tokenLock.Unlock()
+
+	if s.token != "" && time.Now().Before(s.tokenExpiry) {
+		return s.token, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("metadata server returned %s", resp.Status)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", err
+	}
+	s.token = token.AccessToken
+	s.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return s.token, nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventexport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// longest part of an error response included in errors
+	maxErrorBody = 1024
+)
+
+// clickHouseSink writes events through the HTTP interface of ClickHouse. Events are deduplicated
+// by id and timestamp on merges, so that a batch written again after a timeout is not counted twice.
+type clickHouseSink struct {
+	conf   ClickHouseConfig
+	table  string
+	client *http.Client
+}
+
+func newClickHouseSink(conf ClickHouseConfig, table string) *clickHouseSink {
+	return &clickHouseSink{
+		conf:   conf,
+		table:  table,
+		client: &http.Client{},
+	}
+}
+
+func (s *clickHouseSink) EnsureSchema(ctx context.Context) error {
+	columns := make([]string, 0, len(Columns))
+	for _, c := range Columns {
+		columns = append(columns, fmt.Sprintf("`%s` %s", c.Name, c.ClickHouseType))
+	}
+	create := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS `%s` (%s) ENGINE = ReplacingMergeTree PARTITION BY toYYYYMM(timestamp) ORDER BY (kind, timestamp, id)",
+		s.table,
+		strings.Join(columns, ", "),
+	)
+	if s.conf.TTLDays > 0 {
+		create += fmt.Sprintf(" TTL toDateTime(timestamp) + INTERVAL %d DAY", s.conf.TTLDays)
+	}
+	if err := s.exec(ctx, nil, create); err != nil {
+		return err
+	}
+
+	// tables created by older versions get the columns added since
+	alter := make([]string, 0, len(Columns))
+	for _, c := range Columns {
+		alter = append(alter, fmt.Sprintf("ADD COLUMN IF NOT EXISTS `%s` %s", c.Name, c.ClickHouseType))
+	}
+	return s.exec(ctx, nil, fmt.Sprintf("ALTER TABLE `%s` %s", s.table, strings.Join(alter, ", ")))
+}
+
+func (s *clickHouseSink) Write(ctx context.Context, rows []Row) error {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for i := range rows {
+		if err := enc.Encode(&rows[i]); err != nil {
+			return err
+		}
+	}
+	return s.exec(ctx, &body, fmt.Sprintf("INSERT INTO `%s` FORMAT JSONEachRow", s.table))
+}
+
+// exec runs a query, with data sent as the request body
+func (s *clickHouseSink) exec(ctx context.Context, data io.Reader, query string) error {
+	u, err := url.Parse(s.conf.URL)
+	if err != nil {
+		return err
+	}
+	params := u.Query()
+	if s.conf.Database != "" {
+		params.Set("database", s.conf.Database)
+	}
+	// timestamps are encoded as RFC 3339
+	params.Set("date_time_input_format", "best_effort")
+
+	var body io.Reader
+	if data == nil {
+		body = strings.NewReader(query)
+	} else {
+		params.Set("query", query)
+		body = data
+	}
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.String(), body)
+	if err != nil {
+		return err
+	}
+	if s.conf.Username != "" {
+		req.Header.Set("X-ClickHouse-User", s.conf.Username)
+		req.Header.Set("X-ClickHouse-Key", s.conf.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return fmt.Errorf("clickhouse returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventexport
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+type Backend string
+
+const (
+	BackendClickHouse Backend = "clickhouse"
+	BackendBigQuery   Backend = "bigquery"
+)
+
+var (
+	ErrUnknownBackend   = errors.New("unknown event export backend")
+	ErrInvalidTableName = errors.New("invalid event export table name")
+
+	tableNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+)
+
+type ClickHouseConfig struct {
+	// address of the HTTP interface, e. g. http://localhost:8123
+	URL      string `yaml:"url,omitempty"`
+	Database string `yaml:"database,omitempty"`
+	Username string `yaml:"username,omitempty"`
+	Password string `yaml:"password,omitempty"`
+	// days events are kept for, 0 keeps them forever. Only applied when the table is created.
+	TTLDays int `yaml:"ttl_days,omitempty"`
+}
+
+type BigQueryConfig struct {
+	ProjectID string `yaml:"project_id,omitempty"`
+	Dataset   string `yaml:"dataset,omitempty"`
+	// file holding an OAuth2 access token, read for every request so that it can be refreshed
+	// by a sidecar. Tokens are fetched from the GCE metadata server when empty.
+	TokenFile string `yaml:"token_file,omitempty"`
+	// base URL of the BigQuery API
+	Endpoint string `yaml:"endpoint,omitempty"`
+}
+
+type Config struct {
+	Enabled bool    `yaml:"enabled,omitempty"`
+	Backend Backend `yaml:"backend,omitempty"`
+	// table events are written to, created or extended with missing columns on startup
+	Table string `yaml:"table,omitempty"`
+	// number of events written at once
+	BatchSize int `yaml:"batch_size,omitempty"`
+	// longest time an event waits for its batch to fill up
+	FlushInterval time.Duration `yaml:"flush_interval,omitempty"`
+	// events waiting for export, further events are dropped
+	QueueSize int `yaml:"queue_size,omitempty"`
+	// batches that could not be written are stored in this directory and written again later,
+	// they are dropped when empty
+	BackfillDir string `yaml:"backfill_dir,omitempty"`
+	// how often stored batches are written again
+	BackfillInterval time.Duration `yaml:"backfill_interval,omitempty"`
+	// stored batches kept at most, the oldest are dropped
+	MaxBackfillBatches int `yaml:"max_backfill_batches,omitempty"`
+
+	ClickHouse ClickHouseConfig `yaml:"clickhouse,omitempty"`
+	BigQuery   BigQueryConfig   `yaml:"bigquery,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Backend:            BackendClickHouse,
+		Table:              "agentix_events",
+		BatchSize:          500,
+		FlushInterval:      5 * time.Second,
+		QueueSize:          10000,
+		BackfillInterval:   time.Minute,
+		MaxBackfillBatches: 1000,
+		ClickHouse: ClickHouseConfig{
+			URL:      "http://localhost:8123",
+			Database: "default",
+		},
+		BigQuery: BigQueryConfig{
+			Endpoint: "https://bigquery.googleapis.com/bigquery/v2",
+		},
+	}
+)
+
+// Validate checks the settings needed to reach the backend
+func (c *Config) Validate() error {
+	if !tableNameRegexp.MatchString(c.Table) {
+		return fmt.Errorf("%w: %q", ErrInvalidTableName, c.Table)
+	}
+
+	switch c.Backend {
+	case BackendClickHouse:
+		if c.ClickHouse.URL == "" {
+			return errors.New("event export to ClickHouse needs clickhouse.url")
+		}
+	case BackendBigQuery:
+		if c.BigQuery.ProjectID == "" || c.BigQuery.Dataset == "" {
+			return errors.New("event export to BigQuery needs bigquery.project_id and bigquery.dataset")
+		}
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownBackend, c.Backend)
+	}
+	return nil
+}
@@ -0,0 +1,352 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventexport
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	writeTimeout = 30 * time.Second
+
+	backfillPrefix = "events-"
+	backfillSuffix = ".jsonl"
+)
+
+// Sink is a database events are written to
+type Sink interface {
+	// EnsureSchema creates the events table or adds the columns missing from it
+	EnsureSchema(ctx context.Context) error
+	Write(ctx context.Context, rows []Row) error
+}
+
+type ExporterParams struct {
+	Config Config
+	// used instead of the configured backend when set
+	Sink   Sink
+	Logger logger.Logger
+}
+
+// Exporter writes events in batches to ClickHouse or BigQuery. Batches that cannot be written are
+// stored on disk and written again once the database is reachable, so an outage leaves no gaps in
+// long-term dashboards.
+type Exporter struct {
+	params ExporterParams
+	sink   Sink
+
+	queue   chan Row
+	dropped atomic.Uint64
+
+	// only accessed by the worker
+	schemaReady bool
+
+	stopped core.Fuse
+	done    chan struct{}
+}
+
+func NewExporter(params ExporterParams) (*Exporter, error) {
+	conf := &params.Config
+	if conf.Backend == "" {
+		conf.Backend = DefaultConfig.Backend
+	}
+	if conf.Table == "" {
+		conf.Table = DefaultConfig.Table
+	}
+	if conf.BatchSize <= 0 {
+		conf.BatchSize = DefaultConfig.BatchSize
+	}
+	if conf.FlushInterval <= 0 {
+		conf.FlushInterval = DefaultConfig.FlushInterval
+	}
+	if conf.QueueSize < conf.BatchSize {
+		conf.QueueSize = max(DefaultConfig.QueueSize, conf.BatchSize)
+	}
+	if conf.BackfillInterval <= 0 {
+		conf.BackfillInterval = DefaultConfig.BackfillInterval
+	}
+	if conf.MaxBackfillBatches <= 0 {
+		conf.MaxBackfillBatches = DefaultConfig.MaxBackfillBatches
+	}
+	if params.Logger == nil {
+		params.Logger = logger.GetLogger()
+	}
+
+	e := &Exporter{
+		params: params,
+		sink:   params.Sink,
+		queue:  make(chan Row, conf.QueueSize),
+		done:   make(chan struct{}),
+	}
+	if e.sink == nil {
+		if err := conf.Validate(); err != nil {
+			return nil, err
+		}
+		switch conf.Backend {
+		case BackendClickHouse:
+			e.sink = newClickHouseSink(conf.ClickHouse, conf.Table)
+		case BackendBigQuery:
+			e.sink = newBigQuerySink(conf.BigQuery, conf.Table)
+		}
+	}
+	if conf.BackfillDir != "" {
+		if err := os.MkdirAll(conf.BackfillDir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *Exporter) Start() {
+	if e == nil {
+		return
+	}
+	go e.worker()
+}
+
+// Stop writes the events still queued and stops exporting
+func (e *Exporter) Stop() {
+	if e == nil {
+		return
+	}
+	e.stopped.Break()
+	<-e.done
+}
+
+// Record queues an event for export, it is dropped when the queue is full
+func (e *Exporter) Record(row Row) {
+	if e == nil || e.stopped.IsBroken() {
+		return
+	}
+
+	select {
+	case e.queue <- row:
+	default:
+		if e.dropped.Inc()%1000 == 1 {
+			e.params.Logger.Warnw("event export queue full, dropping events", nil, "dropped", e.dropped.Load())
+		}
+	}
+}
+
+// Dropped returns the number of events dropped because the queue was full
+func (e *Exporter) Dropped() uint64 {
+	if e == nil {
+		return 0
+	}
+	return e.dropped.Load()
+}
+
+func (e *Exporter) worker() {
+	defer close(e.done)
+
+	flushTicker := time.NewTicker(e.params.Config.FlushInterval)
+	defer flushTicker.Stop()
+
+	backfillTicker := time.NewTicker(e.params.Config.BackfillInterval)
+	defer backfillTicker.Stop()
+
+	batch := make([]Row, 0, e.params.Config.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		e.export(batch)
+		batch = make([]Row, 0, e.params.Config.BatchSize)
+	}
+
+	e.backfill()
+	for {
+		select {
+		case <-e.stopped.Watch():
+			for {
+				select {
+				case row := <-e.queue:
+					batch = append(batch, row)
+					if len(batch) >= e.params.Config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+
+		case row := <-e.queue:
+			batch = append(batch, row)
+			if len(batch) >= e.params.Config.BatchSize {
+				flush()
+			}
+
+		case <-flushTicker.C:
+			flush()
+
+		case <-backfillTicker.C:
+			e.backfill()
+		}
+	}
+}
+
+func (e *Exporter) export(rows []Row) {
+	if err := e.write(rows); err != nil {
+		e.params.Logger.Warnw("could not export events", err, "count", len(rows), "backend", e.params.Config.Backend)
+		e.store(rows)
+	}
+}
+
+func (e *Exporter) write(rows []Row) error {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if !e.schemaReady {
+		if err := e.sink.EnsureSchema(ctx); err != nil {
+			return fmt.Errorf("could not set up events table: %w", err)
+		}
+		e.schemaReady = true
+	}
+	return e.sink.Write(ctx, rows)
+}
+
+// store keeps a batch that could not be written for backfill
+func (e *Exporter) store(rows []Row) {
+	dir := e.params.Config.BackfillDir
+	if dir == "" {
+		return
+	}
+
+	name := filepath.Join(dir, fmt.Sprintf("%s%020d%s", backfillPrefix, time.Now().UnixNano(), backfillSuffix))
+	if err := writeBatchFile(name, rows); err != nil {
+		e.params.Logger.Errorw("could not store events for backfill", err, "file", name)
+		return
+	}
+
+	files, err := e.backfillFiles()
+	if err != nil {
+		return
+	}
+	for len(files) > e.params.Config.MaxBackfillBatches {
+		e.params.Logger.Warnw("too many events stored for backfill, dropping oldest", nil, "file", files[0])
+		_ = os.Remove(files[0])
+		files = files[1:]
+	}
+}
+
+// backfill writes stored batches, oldest first, until one fails
+func (e *Exporter) backfill() {
+	if e.params.Config.BackfillDir == "" {
+		return
+	}
+
+	files, err := e.backfillFiles()
+	if err != nil {
+		e.params.Logger.Warnw("could not list events stored for backfill", err)
+		return
+	}
+	for _, name := range files {
+		if e.stopped.IsBroken() {
+			return
+		}
+
+		rows, err := readBatchFile(name)
+		if err != nil {
+			e.params.Logger.Warnw("dropping unreadable events stored for backfill", err, "file", name)
+			_ = os.Remove(name)
+			continue
+		}
+		if err := e.write(rows); err != nil {
+			e.params.Logger.Debugw("could not backfill events", "error", err, "file", name)
+			return
+		}
+		_ = os.Remove(name)
+		e.params.Logger.Infow("backfilled events", "count", len(rows), "file", name)
+	}
+}
+
+// backfillFiles returns the stored batches, oldest first
+func (e *Exporter) backfillFiles() ([]string, error) {
+	entries, err := os.ReadDir(e.params.Config.BackfillDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), backfillPrefix) || !strings.HasSuffix(entry.Name(), backfillSuffix) {
+			continue
+		}
+		files = append(files, filepath.Join(e.params.Config.BackfillDir, entry.Name()))
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+func writeBatchFile(name string, rows []Row) error {
+	// written under a temporary name so that backfill never reads a partial batch
+	tmp := name + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for i := range rows {
+		if err = enc.Encode(&rows[i]); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, name)
+}
+
+func readBatchFile(name string) ([]Row, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rows []Row
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var row Row
+		if err := dec.Decode(&row); err != nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
@@ -0,0 +1,273 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventexport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testSink struct {
+	lock    sync.Mutex
+	fail    bool
+	schemas int
+	batches [][]Row
+}
+
+func (s *testSink) EnsureSchema(_ context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fail {
+		return errors.New("unreachable")
+	}
+	s.schemas++
+	return nil
+}
+
+func (s *testSink) Write(_ context.Context, rows []Row) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fail {
+		return errors.New("unreachable")
+	}
+	s.batches = append(s.batches, rows)
+	return nil
+}
+
+func (s *testSink) setFail(fail bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.fail = fail
+}
+
+func (s *testSink) written() []Row {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var rows []Row
+	for _, batch := range s.batches {
+		rows = append(rows, batch...)
+	}
+	return rows
+}
+
+func testRow(id string) Row {
+	return Row{
+		ID:        id,
+		Timestamp: time.Now().UTC().Truncate(time.Millisecond),
+		Kind:      KindRoom,
+		Type:      "room_created",
+		RoomName:  "room",
+	}
+}
+
+func TestExporter(t *testing.T) {
+	t.Run("batches are written when full and on stop", func(t *testing.T) {
+		sink := &testSink{}
+		e, err := NewExporter(ExporterParams{
+			Config: Config{BatchSize: 2, FlushInterval: time.Hour},
+			Sink:   sink,
+		})
+		require.NoError(t, err)
+		e.Start()
+
+		e.Record(testRow("1"))
+		e.Record(testRow("2"))
+		e.Record(testRow("3"))
+		require.Eventually(t, func() bool { return len(sink.written()) == 2 }, time.Second, 10*time.Millisecond)
+
+		e.Stop()
+		require.Len(t, sink.written(), 3)
+		require.Len(t, sink.batches, 2)
+		require.Equal(t, 1, sink.schemas)
+
+		// events recorded after stop are ignored
+		e.Record(testRow("4"))
+		require.Len(t, sink.written(), 3)
+	})
+
+	t.Run("failed batches are backfilled", func(t *testing.T) {
+		dir := t.TempDir()
+		sink := &testSink{fail: true}
+		e, err := NewExporter(ExporterParams{
+			Config: Config{BatchSize: 2, FlushInterval: time.Hour, BackfillDir: dir, BackfillInterval: 20 * time.Millisecond},
+			Sink:   sink,
+		})
+		require.NoError(t, err)
+		e.Start()
+		defer e.Stop()
+
+		rows := []Row{testRow("1"), testRow("2")}
+		for _, row := range rows {
+			e.Record(row)
+		}
+		require.Eventually(t, func() bool {
+			files, _ := filepath.Glob(filepath.Join(dir, "events-*.jsonl"))
+			return len(files) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		sink.setFail(false)
+		require.Eventually(t, func() bool { return len(sink.written()) == 2 }, time.Second, 10*time.Millisecond)
+		require.Equal(t, rows, sink.written())
+
+		files, _ := filepath.Glob(filepath.Join(dir, "events-*"))
+		require.Empty(t, files)
+	})
+
+	t.Run("oldest stored batches are dropped", func(t *testing.T) {
+		dir := t.TempDir()
+		e, err := NewExporter(ExporterParams{
+			Config: Config{BackfillDir: dir, MaxBackfillBatches: 2},
+			Sink:   &testSink{fail: true},
+		})
+		require.NoError(t, err)
+
+		for _, id := range []string{"1", "2", "3"} {
+			e.store([]Row{testRow(id)})
+		}
+		files, err := e.backfillFiles()
+		require.NoError(t, err)
+		require.Len(t, files, 2)
+
+		rows, err := readBatchFile(files[0])
+		require.NoError(t, err)
+		require.Equal(t, "2", rows[0].ID)
+	})
+
+	t.Run("unknown backend", func(t *testing.T) {
+		_, err := NewExporter(ExporterParams{Config: Config{Backend: "parquet"}})
+		require.ErrorIs(t, err, ErrUnknownBackend)
+	})
+}
+
+func TestClickHouseSink(t *testing.T) {
+	var lock sync.Mutex
+	var queries, bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		lock.Lock()
+		queries = append(queries, r.URL.Query().Get("query"))
+		bodies = append(bodies, string(body))
+		lock.Unlock()
+
+		require.Equal(t, "analytics", r.URL.Query().Get("database"))
+		require.Equal(t, "agentix", r.Header.Get("X-ClickHouse-User"))
+		if strings.Contains(string(body), "broken") {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("Code: 62. Syntax error"))
+		}
+	}))
+	defer srv.Close()
+
+	sink := newClickHouseSink(ClickHouseConfig{URL: srv.URL, Database: "analytics", Username: "agentix", TTLDays: 30}, "events")
+	require.NoError(t, sink.EnsureSchema(context.Background()))
+	require.Contains(t, bodies[0], "CREATE TABLE IF NOT EXISTS `events`")
+	require.Contains(t, bodies[0], "`timestamp` DateTime64(3, 'UTC')")
+	require.Contains(t, bodies[0], "INTERVAL 30 DAY")
+	require.Contains(t, bodies[1], "ALTER TABLE `events` ADD COLUMN IF NOT EXISTS `id` String")
+
+	require.NoError(t, sink.Write(context.Background(), []Row{testRow("1"), testRow("2")}))
+	require.Equal(t, "INSERT INTO `events` FORMAT JSONEachRow", queries[2])
+	lines := strings.Split(strings.TrimSpace(bodies[2]), "\n")
+	require.Len(t, lines, 2)
+	var row Row
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &row))
+	require.Equal(t, "2", row.ID)
+
+	err := sink.Write(context.Background(), []Row{{ID: "broken"}})
+	require.ErrorContains(t, err, "Syntax error")
+}
+
+func TestBigQuerySink(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("secret\n"), 0600))
+
+	var lock sync.Mutex
+	var tableExists bool
+	var fields []bigQueryField
+	var inserted []bigQueryInsertRow
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		require.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		const tablePath = "/projects/proj/datasets/ds/tables/events"
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == tablePath:
+			if !tableExists {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			var table bigQueryTable
+			table.Schema.Fields = fields
+			_ = json.NewEncoder(w).Encode(&table)
+
+		case r.Method == http.MethodPost && r.URL.Path == "/projects/proj/datasets/ds/tables":
+			var table bigQueryTable
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&table))
+			require.Equal(t, "events", table.TableReference.TableID)
+			require.Equal(t, "timestamp", table.TimePartitioning.Field)
+			tableExists = true
+			// an older version of the table, without the last column
+			fields = table.Schema.Fields[:len(table.Schema.Fields)-1]
+
+		case r.Method == http.MethodPatch && r.URL.Path == tablePath:
+			var patch bigQueryTable
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&patch))
+			fields = patch.Schema.Fields
+
+		case r.Method == http.MethodPost && r.URL.Path == tablePath+"/insertAll":
+			var insert struct {
+				Rows []bigQueryInsertRow `json:"rows"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&insert))
+			inserted = append(inserted, insert.Rows...)
+			_, _ = w.Write([]byte(`{}`))
+
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	sink := newBigQuerySink(BigQueryConfig{ProjectID: "proj", Dataset: "ds", TokenFile: tokenFile, Endpoint: srv.URL}, "events")
+	require.NoError(t, sink.EnsureSchema(context.Background()))
+	require.Len(t, fields, len(Columns)-1)
+
+	require.NoError(t, sink.EnsureSchema(context.Background()))
+	require.Len(t, fields, len(Columns))
+	require.Equal(t, "payload", fields[len(fields)-1].Name)
+
+	require.NoError(t, sink.Write(context.Background(), []Row{testRow("1"), testRow("2")}))
+	require.Len(t, inserted, 2)
+	require.Equal(t, "2", inserted[1].InsertID)
+	require.Equal(t, KindRoom, inserted[1].JSON.Kind)
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventexport
+
+import (
+	"time"
+)
+
+type Kind string
+
+const (
+	KindRoom        Kind = "room"
+	KindParticipant Kind = "participant"
+	KindTrack       Kind = "track"
+	KindEgress      Kind = "egress"
+	KindIngress     Kind = "ingress"
+	// periodic stats of a track, with its connection quality score
+	KindQuality Kind = "quality"
+	// events of processing added by the server, e. g. processing bypass, idle reaping or talk analytics
+	KindProcessing Kind = "processing"
+	KindOther      Kind = "other"
+)
+
+// Row is a single event as stored in the events table
+type Row struct {
+	ID                  string    `json:"id"`
+	Timestamp           time.Time `json:"timestamp"`
+	Kind                Kind      `json:"kind"`
+	Type                string    `json:"type"`
+	NodeID              string    `json:"node_id"`
+	RoomID              string    `json:"room_id"`
+	RoomName            string    `json:"room_name"`
+	ParticipantID       string    `json:"participant_id"`
+	ParticipantIdentity string    `json:"participant_identity"`
+	TrackID             string    `json:"track_id"`
+	Mime                string    `json:"mime"`
+
+	// quality stats, zero for other kinds
+	Score       float32 `json:"score"`
+	MinScore    float32 `json:"min_score"`
+	Packets     uint64  `json:"packets"`
+	PacketsLost uint64  `json:"packets_lost"`
+	Bytes       uint64  `json:"bytes"`
+	RTT         float64 `json:"rtt"`
+	Jitter      float64 `json:"jitter"`
+
+	// the complete event as JSON
+	Payload string `json:"payload"`
+}
+
+type Column struct {
+	Name           string
+	ClickHouseType string
+	BigQueryType   string
+}
+
+// Columns is the schema of the events table, columns are only ever added to it
+// so that tables created by older versions can be extended
+var Columns = []Column{
+	{Name: "id", ClickHouseType: "String", BigQueryType: "STRING"},
+	{Name: "timestamp", ClickHouseType: "DateTime64(3, 'UTC')", BigQueryType: "TIMESTAMP"},
+	{Name: "kind", ClickHouseType: "LowCardinality(String)", BigQueryType: "STRING"},
+	{Name: "type", ClickHouseType: "LowCardinality(String)", BigQueryType: "STRING"},
+	{Name: "node_id", ClickHouseType: "LowCardinality(String)", BigQueryType: "STRING"},
+	{Name: "room_id", ClickHouseType: "String", BigQueryType: "STRING"},
+	{Name: "room_name", ClickHouseType: "String", BigQueryType: "STRING"},
+	{Name: "participant_id", ClickHouseType: "String", BigQueryType: "STRING"},
+	{Name: "participant_identity", ClickHouseType: "String", BigQueryType: "STRING"},
+	{Name: "track_id", ClickHouseType: "String", BigQueryType: "STRING"},
+	{Name: "mime", ClickHouseType: "LowCardinality(String)", BigQueryType: "STRING"},
+	{Name: "score", ClickHouseType: "Float32", BigQueryType: "FLOAT"},
+	{Name: "min_score", ClickHouseType: "Float32", BigQueryType: "FLOAT"},
+	{Name: "packets", ClickHouseType: "UInt64", BigQueryType: "INTEGER"},
+	{Name: "packets_lost", ClickHouseType: "UInt64", BigQueryType: "INTEGER"},
+	{Name: "bytes", ClickHouseType: "UInt64", BigQueryType: "INTEGER"},
+	{Name: "rtt", ClickHouseType: "Float64", BigQueryType: "FLOAT"},
+	{Name: "jitter", ClickHouseType: "Float64", BigQueryType: "FLOAT"},
+	{Name: "payload", ClickHouseType: "String", BigQueryType: "STRING"},
+}
@@ -0,0 +1,268 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ingest carries the media of broadcast encoders, received over RTMP or SRT, into rooms. H.264 video
+// is repackaged for WebRTC as is, Opus audio is passed through and AAC audio is decoded to be encoded to Opus.
+package ingest
+
+import (
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Protocol is what an encoder publishes with
+type Protocol string
+
+const (
+	ProtocolRTMP Protocol = "rtmp"
+	ProtocolSRT  Protocol = "srt"
+)
+
+var (
+	ErrInvalidAVCConfig      = errors.New("invalid avc decoder configuration record")
+	ErrInvalidNALU           = errors.New("invalid h264 nal unit")
+	ErrInvalidAACConfig      = errors.New("invalid aac audio specific config")
+	ErrAACDecoderUnavailable = errors.New("aac decoder unavailable, none was registered")
+	ErrUnsupportedAudioCodec = errors.New("unsupported audio codec, must be aac or opus")
+	ErrUnsupportedVideoCodec = errors.New("unsupported video codec, must be h264")
+	ErrPublisherReplaced     = errors.New("stream was taken over by another connection of the encoder")
+	ErrPublisherClosed       = errors.New("stream closed")
+)
+
+// Publisher takes the media of an encoder into a room
+type Publisher interface {
+	// WriteH264 sends an access unit of H.264 NAL units in Annex B format in decode order, with its decode
+	// timestamp on the timeline of the stream. B-frames are not reordered, encoders are to be set up without them.
+	WriteH264(au []byte, dts time.Duration) error
+	// WriteOpus sends an Opus packet
+	WriteOpus(packet []byte) error
+	// WritePCM sends interleaved audio decoded from a codec WebRTC does not carry
+	WritePCM(pcm []int16, sampleRate int, channels int) error
+	// Close ends the connection of the encoder, the stream may continue on a reconnect
+	Close()
+}
+
+// --------------------------------------
+
+var annexBStartCode = []byte{0, 0, 0, 1}
+
+const (
+	naluTypeIDR = 5
+	naluTypeSPS = 7
+	naluTypePPS = 8
+)
+
+// AVCConfig is the AVCDecoderConfigurationRecord of an H.264 stream, ISO 14496-15
+type AVCConfig struct {
+	// bytes of the length prefix of NAL units
+	LengthSize int
+	SPS        [][]byte
+	PPS        [][]byte
+}
+
+func ParseAVCConfig(b []byte) (*AVCConfig, error) {
+	if len(b) < 6 || b[0] != 1 {
+		return nil, ErrInvalidAVCConfig
+	}
+
+	c := &AVCConfig{LengthSize: int(b[4]&0x03) + 1}
+	if c.LengthSize == 3 {
+		return nil, ErrInvalidAVCConfig
+	}
+
+	var err error
+	offset := 5
+	if c.SPS, offset, err = parseParameterSets(b, offset, int(b[offset]&0x1f)); err != nil {
+		return nil, err
+	}
+	if offset >= len(b) {
+		return nil, ErrInvalidAVCConfig
+	}
+	if c.PPS, _, err = parseParameterSets(b, offset, int(b[offset])); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func parseParameterSets(b []byte, offset int, count int) ([][]byte, int, error) {
+	offset++
+	sets := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		if offset+2 > len(b) {
+			return nil, 0, ErrInvalidAVCConfig
+		}
+		n := int(binary.BigEndian.Uint16(b[offset:]))
+		offset += 2
+		if offset+n > len(b) {
+			return nil, 0, ErrInvalidAVCConfig
+		}
+		sets = append(sets, append([]byte(nil), b[offset:offset+n]...))
+		offset += n
+	}
+	return sets, offset, nil
+}
+
+// AnnexB appends the length prefixed NAL units of an access unit to out in Annex B format. The parameter sets
+// are put in front of IDR frames that do not carry their own, so that viewers can start decoding at any keyframe.
+func (c *AVCConfig) AnnexB(au []byte, out []byte) ([]byte, error) {
+	start := len(out)
+	hasParameterSets := false
+	for len(au) > 0 {
+		if len(au) < c.LengthSize {
+			return out, ErrInvalidNALU
+		}
+		n := 0
+		for _, b := range au[:c.LengthSize] {
+			n = n<<8 | int(b)
+		}
+		au = au[c.LengthSize:]
+		if n == 0 || n > len(au) {
+			return out, ErrInvalidNALU
+		}
+
+		switch au[0] & 0x1f {
+		case naluTypeSPS, naluTypePPS:
+			hasParameterSets = true
+		case naluTypeIDR:
+			if !hasParameterSets {
+				out = c.appendParameterSets(out)
+				hasParameterSets = true
+			}
+		}
+		out = append(append(out, annexBStartCode...), au[:n]...)
+		au = au[n:]
+	}
+	if len(out) == start {
+		return out, ErrInvalidNALU
+	}
+	return out, nil
+}
+
+func (c *AVCConfig) appendParameterSets(out []byte) []byte {
+	for _, sps := range c.SPS {
+		out = append(append(out, annexBStartCode...), sps...)
+	}
+	for _, pps := range c.PPS {
+		out = append(append(out, annexBStartCode...), pps...)
+	}
+	return out
+}
+
+// --------------------------------------
+
+// sample rates by sampling frequency index, ISO 14496-3
+var aacSampleRates = [...]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350}
+
+// AACConfig is the AudioSpecificConfig of an AAC stream
+type AACConfig struct {
+	ObjectType int
+	SampleRate int
+	Channels   int
+	// the AudioSpecificConfig as received, decoders are set up with it
+	Raw []byte
+}
+
+func ParseAACConfig(b []byte) (AACConfig, error) {
+	if len(b) < 2 {
+		return AACConfig{}, ErrInvalidAACConfig
+	}
+	objectType := int(b[0] >> 3)
+	rateIndex := int(b[0]&0x07)<<1 | int(b[1]>>7)
+	channels := int(b[1] >> 3 & 0x0f)
+	if objectType == 0 || rateIndex >= len(aacSampleRates) || channels == 0 || channels > 2 {
+		return AACConfig{}, ErrInvalidAACConfig
+	}
+	return AACConfig{
+		ObjectType: objectType,
+		SampleRate: aacSampleRates[rateIndex],
+		Channels:   channels,
+		Raw:        append([]byte(nil), b...),
+	}, nil
+}
+
+// AACDecoder decodes raw AAC frames into interleaved 16 bit PCM
+type AACDecoder interface {
+	// Decode returns the number of samples per channel written to pcm
+	Decode(frame []byte, pcm []int16) (int, error)
+	// Format returns the rate and channels of the decoded audio, for HE-AAC those of the SBR and parametric
+	// stereo output rather than of the config
+	Format() (sampleRate int, channels int)
+}
+
+type AACDecoderFactory interface {
+	NewDecoder(config AACConfig) (AACDecoder, error)
+}
+
+var (
+	aacDecoderLock    sync.RWMutex
+	aacDecoderFactory AACDecoderFactory
+)
+
+// RegisterAACDecoder installs the AAC implementation of the build, e. g. one linking a system library.
+// Without one, the AAC audio of encoders is dropped and only their video is published.
+func RegisterAACDecoder(f AACDecoderFactory) {
+	aacDecoderLock.Lock()
+	aacDecoderFactory = f
+	aacDecoderLock.Unlock()
+}
+
+func NewAACDecoder(config AACConfig) (AACDecoder, error) {
+	aacDecoderLock.RLock()
+	f := aacDecoderFactory
+	aacDecoderLock.RUnlock()
+
+	if f == nil {
+		return nil, ErrAACDecoderUnavailable
+	}
+	return f.NewDecoder(config)
+}
+
+// AACMaxFrameSamples is the most samples per channel an AAC frame decodes to, HE-AAC doubles the 1024 of AAC-LC
+const AACMaxFrameSamples = 2048
+
+// AACAudio decodes the AAC frames of a stream for its Publisher
+type AACAudio struct {
+	config  AACConfig
+	decoder AACDecoder
+	pcm     []int16
+}
+
+// NewAACAudio returns ErrAACDecoderUnavailable when no decoder was registered
+func NewAACAudio(config AACConfig) (*AACAudio, error) {
+	decoder, err := NewAACDecoder(config)
+	if err != nil {
+		return nil, err
+	}
+	return &AACAudio{
+		config:  config,
+		decoder: decoder,
+		pcm:     make([]int16, AACMaxFrameSamples*2),
+	}, nil
+}
+
+func (a *AACAudio) Config() AACConfig {
+	return a.config
+}
+
+func (a *AACAudio) Write(p Publisher, frame []byte) error {
+	n, err := a.decoder.Decode(frame, a.pcm)
+	if err != nil || n == 0 {
+		return err
+	}
+	sampleRate, channels := a.decoder.Format()
+	return p.WritePCM(a.pcm[:n*channels], sampleRate, channels)
+}
@@ -0,0 +1,230 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingest
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAVC(t *testing.T) {
+	sps := []byte{0x67, 0x64, 0x00, 0x1f}
+	pps := []byte{0x68, 0xeb}
+	record := []byte{1, 0x64, 0x00, 0x1f, 0xfd, 0xe1, 0, 4}
+	record = append(append(record, sps...), 1, 0, 2)
+	record = append(record, pps...)
+
+	c, err := ParseAVCConfig(record)
+	require.NoError(t, err)
+	require.Equal(t, 2, c.LengthSize)
+	require.Equal(t, [][]byte{sps}, c.SPS)
+	require.Equal(t, [][]byte{pps}, c.PPS)
+
+	// parameter sets are put in front of the idr, not of other frames
+	out, err := c.AnnexB([]byte{0, 2, 0x09, 0xf0, 0, 3, 0x65, 1, 2}, nil)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0, 0, 0, 1, 0x09, 0xf0,
+		0, 0, 0, 1, 0x67, 0x64, 0x00, 0x1f,
+		0, 0, 0, 1, 0x68, 0xeb,
+		0, 0, 0, 1, 0x65, 1, 2,
+	}, out)
+
+	out, err = c.AnnexB([]byte{0, 2, 0x41, 7}, out[:0])
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 1, 0x41, 7}, out)
+
+	_, err = c.AnnexB([]byte{0, 9, 0x41}, nil)
+	require.ErrorIs(t, err, ErrInvalidNALU)
+	_, err = ParseAVCConfig(record[:10])
+	require.ErrorIs(t, err, ErrInvalidAVCConfig)
+}
+
+func TestAAC(t *testing.T) {
+	// AAC-LC, 44.1kHz, stereo
+	config, err := ParseAACConfig([]byte{0x12, 0x10})
+	require.NoError(t, err)
+	require.Equal(t, 2, config.ObjectType)
+	require.Equal(t, 44100, config.SampleRate)
+	require.Equal(t, 2, config.Channels)
+
+	_, err = ParseAACConfig([]byte{0x12})
+	require.ErrorIs(t, err, ErrInvalidAACConfig)
+
+	RegisterAACDecoder(nil)
+	_, err = NewAACAudio(config)
+	require.ErrorIs(t, err, ErrAACDecoderUnavailable)
+
+	RegisterAACDecoder(testAACDecoderFactory{})
+	t.Cleanup(func() { RegisterAACDecoder(nil) })
+	a, err := NewAACAudio(config)
+	require.NoError(t, err)
+
+	p := &testPublisher{}
+	require.NoError(t, a.Write(p, []byte{1, 2, 3}))
+	require.Equal(t, []int16{1, 1, 2, 2, 3, 3}, p.pcm)
+	require.Equal(t, 44100, p.sampleRate)
+	require.Equal(t, 2, p.channels)
+}
+
+func TestTSDemuxer(t *testing.T) {
+	publishers := map[uint16]*testPublisher{}
+	var skipped []uint16
+	d := NewTSDemuxer(TSDemuxerParams{
+		OnProgram: func(program uint16, index int) (Publisher, error) {
+			publishers[program] = &testPublisher{}
+			return publishers[program], nil
+		},
+		OnSkipped: func(program uint16, pid uint16, err error) {
+			skipped = append(skipped, pid)
+		},
+	})
+
+	var ts []byte
+	ts = append(ts, testTSPackets(0, testPSI(0x00, 1, []byte{0, 1, 0xe1, 0x00, 0, 2, 0xe2, 0x00}))...)
+	ts = append(ts, testTSPackets(0x100, testPSI(0x02, 1, []byte{
+		0xe1, 0x01, 0xf0, 0x00,
+		tsStreamTypeH264, 0xe1, 0x01, 0xf0, 0x00,
+		tsStreamTypePrivate, 0xe1, 0x02, 0xf0, 0x06, tsDescriptorRegistration, 4, 'O', 'p', 'u', 's',
+		0x03, 0xe1, 0x03, 0xf0, 0x00,
+	}))...)
+	ts = append(ts, testTSPackets(0x200, testPSI(0x02, 2, []byte{
+		0xe2, 0x01, 0xf0, 0x00,
+		tsStreamTypeH264, 0xe2, 0x01, 0xf0, 0x00,
+	}))...)
+
+	// a frame spanning packets, sent once the next one starts
+	frame := append([]byte{0, 0, 0, 1, 0x65}, make([]byte, 400)...)
+	ts = append(ts, testTSPackets(0x101, testPES(0xe0, 90000, frame, false))...)
+	ts = append(ts, testTSPackets(0x101, testPES(0xe0, 93000, []byte{0, 0, 0, 1, 0x41}, false))...)
+	// two opus packets in an access unit, sent at once as the length is known
+	ts = append(ts, testTSPackets(0x102, testPES(0xc0, 90000, []byte{0x7f, 0xe0, 2, 0xfc, 1, 0x7f, 0xe0, 1, 0xf8}, true))...)
+	ts = append(ts, testTSPackets(0x201, testPES(0xe0, 0, []byte{0, 0, 0, 1, 0x09}, false))...)
+
+	// split anywhere
+	require.NoError(t, d.Write(ts[:100]))
+	require.NoError(t, d.Write(ts[100:]))
+
+	require.Len(t, publishers, 2)
+	require.Equal(t, [][]byte{frame}, publishers[1].video)
+	require.Equal(t, []time.Duration{time.Second}, publishers[1].dts)
+	require.Equal(t, [][]byte{{0xfc, 1}, {0xf8}}, publishers[1].opus)
+	require.Empty(t, publishers[2].video)
+	require.Equal(t, []uint16{0x103}, skipped)
+}
+
+// testPSI returns a section of table id with the table id extension, e. g. the program number of a PMT
+func testPSI(tableID byte, extension uint16, data []byte) []byte {
+	section := []byte{0, tableID, 0xb0, byte(5 + len(data) + 4)}
+	section = binary.BigEndian.AppendUint16(section, extension)
+	section = append(section, 0xc1, 0, 0)
+	section = append(section, data...)
+	// crc, not checked
+	return append(section, 0, 0, 0, 0)
+}
+
+func testPES(streamID byte, dts int64, data []byte, withLength bool) []byte {
+	pes := []byte{0, 0, 1, streamID, 0, 0, 0x80, 0x80, 5}
+	if withLength {
+		binary.BigEndian.PutUint16(pes[4:], uint16(3+5+len(data)))
+	}
+	pes = append(pes,
+		byte(0x21|dts>>29&0x0e), byte(dts>>22), byte(dts>>14|1), byte(dts>>7), byte(dts<<1|1),
+	)
+	return append(pes, data...)
+}
+
+// testTSPackets splits a payload into packets of pid, the last one stuffed through its adaptation field
+func testTSPackets(pid uint16, payload []byte) []byte {
+	var ts []byte
+	for i := 0; len(payload) > 0; i++ {
+		header := []byte{tsSyncByte, byte(pid >> 8), byte(pid), 0x10 | byte(i&0x0f)}
+		if i == 0 {
+			header[1] |= 0x40
+		}
+		n := min(len(payload), TSPacketSize-4)
+		if n < TSPacketSize-4 {
+			header[3] |= 0x20
+			stuffing := TSPacketSize - 4 - n - 1
+			header = append(header, byte(stuffing))
+			if stuffing > 0 {
+				header = append(header, 0x00)
+				for j := 1; j < stuffing; j++ {
+					header = append(header, 0xff)
+				}
+			}
+		}
+		ts = append(append(ts, header...), payload[:n]...)
+		payload = payload[n:]
+	}
+	return ts
+}
+
+type testAACDecoderFactory struct{}
+
+func (testAACDecoderFactory) NewDecoder(config AACConfig) (AACDecoder, error) {
+	return &testAACDecoder{config: config}, nil
+}
+
+// testAACDecoder decodes every byte of a frame to a sample on each channel
+type testAACDecoder struct {
+	config AACConfig
+}
+
+func (d *testAACDecoder) Decode(frame []byte, pcm []int16) (int, error) {
+	for i, b := range frame {
+		for c := 0; c < d.config.Channels; c++ {
+			pcm[i*d.config.Channels+c] = int16(b)
+		}
+	}
+	return len(frame), nil
+}
+
+func (d *testAACDecoder) Format() (int, int) {
+	return d.config.SampleRate, d.config.Channels
+}
+
+type testPublisher struct {
+	video      [][]byte
+	dts        []time.Duration
+	opus       [][]byte
+	pcm        []int16
+	sampleRate int
+	channels   int
+}
+
+func (p *testPublisher) Close() {}
+
+func (p *testPublisher) WriteH264(au []byte, dts time.Duration) error {
+	p.video = append(p.video, append([]byte(nil), au...))
+	p.dts = append(p.dts, dts)
+	return nil
+}
+
+func (p *testPublisher) WriteOpus(packet []byte) error {
+	p.opus = append(p.opus, append([]byte(nil), packet...))
+	return nil
+}
+
+func (p *testPublisher) WritePCM(pcm []int16, sampleRate int, channels int) error {
+	p.pcm = append(p.pcm[:0], pcm...)
+	p.sampleRate = sampleRate
+	p.channels = channels
+	return nil
+}
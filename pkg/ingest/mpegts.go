@@ -0,0 +1,467 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ingest
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	TSPacketSize = 188
+	tsSyncByte   = 0x47
+
+	tsPIDPAT = 0x0000
+
+	// stream types of PMTs, ISO 13818-1
+	tsStreamTypeAAC     = 0x0f
+	tsStreamTypeH264    = 0x1b
+	tsStreamTypePrivate = 0x06
+
+	// registration descriptor naming the codec of private streams
+	tsDescriptorRegistration = 0x05
+
+	// PES above this size are dropped, well above the keyframes of broadcast bitrates
+	maxPESSize = 8 << 20
+)
+
+var (
+	ErrInvalidTS   = errors.New("invalid mpeg-ts packet")
+	ErrInvalidADTS = errors.New("invalid adts frame")
+	ErrInvalidOpus = errors.New("invalid opus access unit in mpeg-ts")
+	// a program has several video or audio streams, the first is published
+	ErrDuplicateStream = errors.New("program has a stream of the kind already")
+)
+
+type TSDemuxerParams struct {
+	// returns the publisher of a program when its PMT is first received, index is the position of the program
+	// in the PAT. An error skips the program.
+	OnProgram func(program uint16, index int) (Publisher, error)
+	// told about elementary streams that are not published, the first video and audio stream of a program are
+	OnSkipped func(program uint16, pid uint16, err error)
+}
+
+// TSDemuxer splits an MPEG-TS stream into its programs, each published by its own publisher with the
+// first H.264 stream of the program as video and the first AAC or Opus stream as audio
+type TSDemuxer struct {
+	params TSDemuxerParams
+
+	partial  []byte
+	pmtPIDs  map[uint16]uint16
+	programs map[uint16]*tsProgram
+	// elementary streams by PID, and those not published
+	streams map[uint16]*tsStream
+	skipped map[uint16]bool
+}
+
+type tsProgram struct {
+	number    uint16
+	publisher Publisher
+	video     uint16
+	audio     uint16
+}
+
+type tsStream struct {
+	program *tsProgram
+	kind    int
+	pes     []byte
+	aac     *AACAudio
+	adts    [2]byte
+}
+
+const (
+	tsKindH264 = iota + 1
+	tsKindAAC
+	tsKindOpus
+)
+
+func NewTSDemuxer(params TSDemuxerParams) *TSDemuxer {
+	return &TSDemuxer{
+		params:   params,
+		pmtPIDs:  make(map[uint16]uint16),
+		programs: make(map[uint16]*tsProgram),
+		streams:  make(map[uint16]*tsStream),
+		skipped:  make(map[uint16]bool),
+	}
+}
+
+// Write takes TS packets, split anywhere. It returns ErrPublisherReplaced or ErrPublisherClosed once a
+// publisher lost its stream, media that cannot be demuxed is dropped.
+func (d *TSDemuxer) Write(b []byte) error {
+	if len(d.partial) > 0 {
+		n := min(TSPacketSize-len(d.partial), len(b))
+		d.partial = append(d.partial, b[:n]...)
+		b = b[n:]
+		if len(d.partial) < TSPacketSize {
+			return nil
+		}
+		err := d.writePacket(d.partial)
+		d.partial = d.partial[:0]
+		if err != nil {
+			return err
+		}
+	}
+
+	for len(b) >= TSPacketSize {
+		if b[0] != tsSyncByte {
+			// resynchronize on the next sync byte
+			b = b[1:]
+			continue
+		}
+		if err := d.writePacket(b[:TSPacketSize]); err != nil {
+			return err
+		}
+		b = b[TSPacketSize:]
+	}
+	d.partial = append(d.partial, b...)
+	return nil
+}
+
+// Close closes the publishers of all programs
+func (d *TSDemuxer) Close() {
+	for _, p := range d.programs {
+		if p.publisher != nil {
+			p.publisher.Close()
+		}
+	}
+	d.programs = make(map[uint16]*tsProgram)
+	d.streams = make(map[uint16]*tsStream)
+}
+
+func (d *TSDemuxer) writePacket(pkt []byte) error {
+	if pkt[0] != tsSyncByte {
+		return nil
+	}
+	pusi := pkt[1]&0x40 != 0
+	pid := binary.BigEndian.Uint16(pkt[1:3]) & 0x1fff
+	adaptation := pkt[3] >> 4 & 0x03
+
+	payload := pkt[4:]
+	if adaptation&0x02 != 0 {
+		if len(payload) == 0 || int(payload[0]) >= len(payload) {
+			return nil
+		}
+		payload = payload[1+int(payload[0]):]
+	}
+	if adaptation&0x01 == 0 {
+		return nil
+	}
+
+	if pid == tsPIDPAT {
+		if pusi {
+			d.parsePAT(payload)
+		}
+		return nil
+	}
+	if program, ok := d.pmtPIDs[pid]; ok {
+		if pusi {
+			d.parsePMT(program, payload)
+		}
+		return nil
+	}
+
+	s := d.streams[pid]
+	if s == nil {
+		return nil
+	}
+	var err error
+	if pusi {
+		if len(s.pes) > 0 {
+			err = d.writePES(s, s.pes)
+		}
+		s.pes = append(s.pes[:0], payload...)
+	} else if len(s.pes) > 0 {
+		if len(s.pes)+len(payload) > maxPESSize {
+			s.pes = s.pes[:0]
+		} else {
+			s.pes = append(s.pes, payload...)
+		}
+	}
+	// PES of known length, as those of audio usually are, are sent once complete instead of on the next one
+	if err == nil && len(s.pes) >= 6 {
+		if length := int(binary.BigEndian.Uint16(s.pes[4:6])); length != 0 && len(s.pes) >= 6+length {
+			err = d.writePES(s, s.pes)
+			s.pes = s.pes[:0]
+		}
+	}
+	return err
+}
+
+// psiSection returns the section of a payload starting it, skipping the pointer field
+func psiSection(payload []byte, tableID byte) []byte {
+	if len(payload) == 0 || int(payload[0])+1 >= len(payload) {
+		return nil
+	}
+	section := payload[1+int(payload[0]):]
+	if len(section) < 8 || section[0] != tableID {
+		return nil
+	}
+	length := int(binary.BigEndian.Uint16(section[1:3]) & 0x0fff)
+	// without the crc
+	if length < 9 || 3+length > len(section) {
+		return nil
+	}
+	return section[:3+length-4]
+}
+
+func (d *TSDemuxer) parsePAT(payload []byte) {
+	section := psiSection(payload, 0x00)
+	if section == nil {
+		return
+	}
+	index := 0
+	for b := section[8:]; len(b) >= 4; b = b[4:] {
+		program := binary.BigEndian.Uint16(b[0:2])
+		pid := binary.BigEndian.Uint16(b[2:4]) & 0x1fff
+		if program == 0 {
+			// network pid
+			continue
+		}
+		if _, ok := d.pmtPIDs[pid]; !ok {
+			d.pmtPIDs[pid] = program
+			if d.programs[program] == nil {
+				d.programs[program] = &tsProgram{number: program}
+				d.openProgram(d.programs[program], index)
+			}
+		}
+		index++
+	}
+}
+
+func (d *TSDemuxer) openProgram(p *tsProgram, index int) {
+	publisher, err := d.params.OnProgram(p.number, index)
+	if err != nil {
+		d.skip(p.number, 0, err)
+		return
+	}
+	p.publisher = publisher
+}
+
+func (d *TSDemuxer) parsePMT(program uint16, payload []byte) {
+	section := psiSection(payload, 0x02)
+	p := d.programs[program]
+	if section == nil || p == nil || p.publisher == nil || binary.BigEndian.Uint16(section[3:5]) != program {
+		return
+	}
+	if len(section) < 12 {
+		return
+	}
+	infoLength := int(binary.BigEndian.Uint16(section[10:12]) & 0x0fff)
+	if 12+infoLength > len(section) {
+		return
+	}
+
+	for b := section[12+infoLength:]; len(b) >= 5; {
+		streamType := b[0]
+		pid := binary.BigEndian.Uint16(b[1:3]) & 0x1fff
+		esLength := int(binary.BigEndian.Uint16(b[3:5]) & 0x0fff)
+		if 5+esLength > len(b) {
+			return
+		}
+		descriptors := b[5 : 5+esLength]
+		b = b[5+esLength:]
+
+		if _, ok := d.streams[pid]; ok || d.skipped[pid] {
+			continue
+		}
+		kind := tsStreamKind(streamType, descriptors)
+		switch {
+		case kind == tsKindH264 && p.video == 0:
+			p.video = pid
+		case (kind == tsKindAAC || kind == tsKindOpus) && p.audio == 0:
+			p.audio = pid
+		case kind == 0:
+			d.skip(program, pid, tsUnsupportedError(streamType))
+			continue
+		default:
+			d.skip(program, pid, ErrDuplicateStream)
+			continue
+		}
+		d.streams[pid] = &tsStream{program: p, kind: kind}
+	}
+}
+
+func tsStreamKind(streamType byte, descriptors []byte) int {
+	switch streamType {
+	case tsStreamTypeH264:
+		return tsKindH264
+	case tsStreamTypeAAC:
+		return tsKindAAC
+	case tsStreamTypePrivate:
+		for len(descriptors) >= 2 {
+			tag, length := descriptors[0], int(descriptors[1])
+			if 2+length > len(descriptors) {
+				break
+			}
+			if tag == tsDescriptorRegistration && length >= 4 && string(descriptors[2:6]) == "Opus" {
+				return tsKindOpus
+			}
+			descriptors = descriptors[2+length:]
+		}
+	}
+	return 0
+}
+
+func tsUnsupportedError(streamType byte) error {
+	switch streamType {
+	case 0x01, 0x02, 0x10, 0x24, 0x42, 0xd1, 0xea:
+		return ErrUnsupportedVideoCodec
+	}
+	return ErrUnsupportedAudioCodec
+}
+
+func (d *TSDemuxer) skip(program uint16, pid uint16, err error) {
+	d.skipped[pid] = true
+	if d.params.OnSkipped != nil {
+		d.params.OnSkipped(program, pid, err)
+	}
+}
+
+// writePES sends the access units of a complete PES packet
+func (d *TSDemuxer) writePES(s *tsStream, pes []byte) error {
+	if len(pes) < 9 || pes[0] != 0 || pes[1] != 0 || pes[2] != 1 {
+		return nil
+	}
+	headerLength := int(pes[8])
+	if 9+headerLength > len(pes) {
+		return nil
+	}
+	var dts time.Duration
+	if flags := pes[7] >> 6; flags&0x02 != 0 && headerLength >= 5 {
+		// the dts when present, the pts otherwise
+		offset := 9
+		if flags == 0x03 && headerLength >= 10 {
+			offset = 14
+		}
+		dts = time.Duration(parseTSTimestamp(pes[offset:])) * time.Second / 90000
+	}
+	data := pes[9+headerLength:]
+	if length := int(binary.BigEndian.Uint16(pes[4:6])); length != 0 && 6+length < len(pes) {
+		data = pes[9+headerLength : 6+length]
+	}
+
+	publisher := s.program.publisher
+	var err error
+	switch s.kind {
+	case tsKindH264:
+		err = publisher.WriteH264(data, dts)
+	case tsKindAAC:
+		err = d.writeADTS(s, data)
+	case tsKindOpus:
+		err = writeTSOpus(publisher, data)
+	}
+	if errors.Is(err, ErrPublisherReplaced) || errors.Is(err, ErrPublisherClosed) {
+		return err
+	}
+	return nil
+}
+
+func parseTSTimestamp(b []byte) int64 {
+	return int64(b[0]>>1&0x07)<<30 | int64(b[1])<<22 | int64(b[2]>>1)<<15 | int64(b[3])<<7 | int64(b[4]>>1)
+}
+
+func (d *TSDemuxer) writeADTS(s *tsStream, data []byte) error {
+	for len(data) > 0 {
+		config, frame, n, err := ParseADTS(data)
+		if err != nil {
+			return err
+		}
+		data = data[n:]
+
+		if s.aac == nil || [2]byte(config.Raw) != s.adts {
+			s.adts = [2]byte(config.Raw)
+			if s.aac, err = NewAACAudio(config); err != nil {
+				if errors.Is(err, ErrAACDecoderUnavailable) {
+					d.skip(s.program.number, s.program.audio, err)
+					s.kind = 0
+				}
+				return err
+			}
+		}
+		if err := s.aac.Write(s.program.publisher, frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseADTS returns the config and raw frame of the ADTS frame b starts with, and the length of the frame
+func ParseADTS(b []byte) (AACConfig, []byte, int, error) {
+	if len(b) < 7 || b[0] != 0xff || b[1]&0xf6 != 0xf0 {
+		return AACConfig{}, nil, 0, ErrInvalidADTS
+	}
+	headerLength := 7
+	if b[1]&0x01 == 0 {
+		// crc follows
+		headerLength = 9
+	}
+	length := int(b[3]&0x03)<<11 | int(b[4])<<3 | int(b[5]>>5)
+	if length < headerLength || length > len(b) {
+		return AACConfig{}, nil, 0, ErrInvalidADTS
+	}
+
+	objectType := int(b[2]>>6) + 1
+	rateIndex := int(b[2] >> 2 & 0x0f)
+	channels := int(b[2]&0x01)<<2 | int(b[3]>>6)
+	asc := []byte{
+		byte(objectType<<3 | rateIndex>>1),
+		byte(rateIndex&0x01<<7 | channels<<3),
+	}
+	config, err := ParseAACConfig(asc)
+	if err != nil {
+		return AACConfig{}, nil, 0, err
+	}
+	return config, b[headerLength:length], length, nil
+}
+
+// writeTSOpus sends the Opus packets of an access unit, each behind the control header of ETSI TS 102 366
+func writeTSOpus(publisher Publisher, data []byte) error {
+	for len(data) > 0 {
+		if len(data) < 2 || data[0] != 0x7f || data[1]&0xe0 != 0xe0 {
+			return ErrInvalidOpus
+		}
+		flags := data[1]
+		data = data[2:]
+
+		size := 0
+		for len(data) > 0 {
+			b := data[0]
+			data = data[1:]
+			size += int(b)
+			if b != 0xff {
+				break
+			}
+		}
+		if flags&0x10 != 0 {
+			data = data[min(2, len(data)):]
+		}
+		if flags&0x08 != 0 {
+			data = data[min(2, len(data)):]
+		}
+		if flags&0x04 != 0 && len(data) > 0 {
+			data = data[min(1+int(data[0]), len(data)):]
+		}
+		if size > len(data) {
+			return ErrInvalidOpus
+		}
+		if err := publisher.WriteOpus(data[:size]); err != nil {
+			return err
+		}
+		data = data[size:]
+	}
+	return nil
+}
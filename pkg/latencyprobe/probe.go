@@ -0,0 +1,334 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latencyprobe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	probeFrameDuration = 20 * time.Millisecond
+	probeBurstFrames   = 5
+	// how long markers still in flight are waited for after the last one was sent
+	probeDrainTime = time.Second
+)
+
+var (
+	ErrProbeRunning = errors.New("latency probe already running")
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how often a probe runs in the background, 0 runs probes on demand only
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// how long a probe publishes
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// time between two tone markers
+	MarkerInterval time.Duration `yaml:"marker_interval,omitempty"`
+	// probe rooms are named with this prefix and a random suffix
+	RoomPrefix string `yaml:"room_prefix,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Interval:       5 * time.Minute,
+		Duration:       10 * time.Second,
+		MarkerInterval: 500 * time.Millisecond,
+		RoomPrefix:     "latency-probe-",
+	}
+)
+
+// Loopback is a test participant that publishes an Opus track and receives the track back
+// as it is forwarded by the server
+type Loopback interface {
+	WriteFrame(payload []byte, duration time.Duration) error
+	// OnFrame sets the handler of the payloads received back, it is called from a single goroutine
+	OnFrame(f func(payload []byte, arrival time.Time))
+	Close()
+}
+
+type Result struct {
+	Room            string    `json:"room"`
+	StartedAt       time.Time `json:"started_at"`
+	MarkersSent     int       `json:"markers_sent"`
+	MarkersReceived int       `json:"markers_received"`
+	MinMs           float64   `json:"min_ms"`
+	MeanMs          float64   `json:"mean_ms"`
+	P50Ms           float64   `json:"p50_ms"`
+	P95Ms           float64   `json:"p95_ms"`
+	MaxMs           float64   `json:"max_ms"`
+	Error           string    `json:"error,omitempty"`
+}
+
+type ProberParams struct {
+	Config Config
+	// Connect joins the test participant to a room of the local server
+	Connect func(ctx context.Context, room string) (Loopback, error)
+	Logger  logger.Logger
+	// called with the result of every probe, e. g. to export metrics
+	OnResult func(res *Result, err error)
+}
+
+// Prober measures end-to-end latency through the server, including audio processing, by publishing
+// a tone with timed markers and detecting the markers in the audio received back.
+type Prober struct {
+	params ProberParams
+
+	lock    sync.Mutex
+	running bool
+	last    *Result
+	stopped core.Fuse
+}
+
+func NewProber(params ProberParams) *Prober {
+	if params.Config.Duration <= 0 {
+		params.Config.Duration = DefaultConfig.Duration
+	}
+	if params.Config.MarkerInterval < (probeBurstFrames+1)*probeFrameDuration {
+		params.Config.MarkerInterval = DefaultConfig.MarkerInterval
+	}
+	if params.Config.RoomPrefix == "" {
+		params.Config.RoomPrefix = DefaultConfig.RoomPrefix
+	}
+	return &Prober{
+		params: params,
+	}
+}
+
+func (p *Prober) Start() {
+	if p == nil {
+		return
+	}
+	if p.params.Config.Interval > 0 {
+		go p.worker()
+	}
+}
+
+func (p *Prober) Stop() {
+	if p == nil {
+		return
+	}
+	p.stopped.Break()
+}
+
+// LastResult returns the result of the most recent probe, nil if none has completed
+func (p *Prober) LastResult() *Result {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.last
+}
+
+// Run runs a probe, only one probe runs at a time
+func (p *Prober) Run(ctx context.Context) (*Result, error) {
+	p.lock.Lock()
+	if p.running {
+		p.lock.Unlock()
+		return nil, ErrProbeRunning
+	}
+	p.running = true
+	p.lock.Unlock()
+
+	res, err := p.run(ctx)
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if p.params.OnResult != nil {
+		p.params.OnResult(res, err)
+	}
+
+	p.lock.Lock()
+	p.running = false
+	p.last = res
+	p.lock.Unlock()
+	return res, err
+}
+
+func (p *Prober) worker() {
+	ticker := time.NewTicker(p.params.Config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), p.params.Config.Duration+time.Minute)
+			res, err := p.Run(ctx)
+			cancel()
+			if err != nil && !errors.Is(err, ErrProbeRunning) {
+				p.params.Logger.Warnw("latency probe failed", err, "room", res.Room)
+			}
+		}
+	}
+}
+
+func (p *Prober) run(ctx context.Context) (*Result, error) {
+	rec := newRecorder()
+	res := &Result{
+		Room:      p.params.Config.RoomPrefix + guid.New(""),
+		StartedAt: time.Now(),
+	}
+
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return res, err
+	}
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return res, err
+	}
+
+	lb, err := p.params.Connect(ctx, res.Room)
+	if err != nil {
+		return res, fmt.Errorf("could not connect test participant: %w", err)
+	}
+	defer lb.Close()
+
+	detector := NewToneDetector(audio.OpusSampleRate)
+	pcm := make([]int16, audio.OpusMaxFrameSize)
+	lb.OnFrame(func(payload []byte, arrival time.Time) {
+		n, err := decoder.Decode(payload, pcm)
+		if err != nil {
+			return
+		}
+		if marker, ok := detector.Process(pcm[:n]); ok {
+			rec.markReceived(marker, arrival)
+		}
+	})
+
+	frameSize := int(audio.OpusSampleRate * probeFrameDuration / time.Second)
+	generator := NewToneGenerator(
+		audio.OpusSampleRate,
+		frameSize,
+		int(p.params.Config.MarkerInterval/probeFrameDuration),
+		probeBurstFrames,
+	)
+	out := make([]byte, audio.OpusMaxPacketSize)
+
+	ticker := time.NewTicker(probeFrameDuration)
+	defer ticker.Stop()
+	deadline := time.After(p.params.Config.Duration)
+publish:
+	for {
+		select {
+		case <-ctx.Done():
+			return rec.result(res), ctx.Err()
+		case <-deadline:
+			break publish
+		case <-ticker.C:
+			frame, marker, start := generator.NextFrame()
+			n, err := encoder.Encode(frame, out)
+			if err != nil {
+				return rec.result(res), err
+			}
+			if start {
+				rec.markSent(marker, time.Now())
+			}
+			if err := lb.WriteFrame(out[:n], probeFrameDuration); err != nil {
+				return rec.result(res), err
+			}
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+	case <-time.After(probeDrainTime):
+	}
+	return rec.result(res), nil
+}
+
+// --------------------------------------
+
+// recorder matches the markers received back to the markers sent
+type recorder struct {
+	lock     sync.Mutex
+	sent     map[int]time.Time
+	received map[int]time.Time
+}
+
+func newRecorder() *recorder {
+	return &recorder{
+		sent:     make(map[int]time.Time),
+		received: make(map[int]time.Time),
+	}
+}
+
+func (r *recorder) markSent(marker int, at time.Time) {
+	r.lock.Lock()
+	r.sent[marker] = at
+	r.lock.Unlock()
+}
+
+func (r *recorder) markReceived(marker int, at time.Time) {
+	r.lock.Lock()
+	if _, ok := r.received[marker]; !ok {
+		r.received[marker] = at
+	}
+	r.lock.Unlock()
+}
+
+func (r *recorder) result(res *Result) *Result {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var latencies []time.Duration
+	for marker, sentAt := range r.sent {
+		if receivedAt, ok := r.received[marker]; ok && !receivedAt.Before(sentAt) {
+			latencies = append(latencies, receivedAt.Sub(sentAt))
+		}
+	}
+	res.MarkersSent = len(r.sent)
+	res.MarkersReceived = len(latencies)
+	if len(latencies) == 0 {
+		return res
+	}
+
+	slices.Sort(latencies)
+	var sum time.Duration
+	for _, l := range latencies {
+		sum += l
+	}
+	res.MinMs = toMs(latencies[0])
+	res.MaxMs = toMs(latencies[len(latencies)-1])
+	res.MeanMs = toMs(sum / time.Duration(len(latencies)))
+	res.P50Ms = toMs(percentile(latencies, 50))
+	res.P95Ms = toMs(percentile(latencies, 95))
+	return res
+}
+
+// percentile of sorted values, nearest rank
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	return sorted[max(rank-1, 0)]
+}
+
+func toMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
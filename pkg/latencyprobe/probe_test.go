@@ -0,0 +1,75 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latencyprobe
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestToneMarkers(t *testing.T) {
+	const (
+		sampleRate = 48000
+		frameSize  = 960
+	)
+	generator := NewToneGenerator(sampleRate, frameSize, 25, probeBurstFrames)
+	detector := NewToneDetector(sampleRate)
+
+	var sent, detected []int
+	for frame := 0; frame < 25*10; frame++ {
+		pcm, marker, start := generator.NextFrame()
+		if start {
+			sent = append(sent, marker)
+		}
+
+		// markers 3 to 5 are lost
+		if frame >= 3*25 && frame < 6*25 {
+			continue
+		}
+		if marker, ok := detector.Process(pcm); ok {
+			require.True(t, start)
+			detected = append(detected, marker)
+		}
+	}
+
+	require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, sent)
+	// sequence numbers are recovered across the lost markers
+	require.Equal(t, []int{0, 1, 2, 6, 7, 8, 9}, detected)
+}
+
+func TestRecorder(t *testing.T) {
+	rec := newRecorder()
+	start := time.Now()
+	for marker := 0; marker < 20; marker++ {
+		sentAt := start.Add(time.Duration(marker) * 500 * time.Millisecond)
+		rec.markSent(marker, sentAt)
+		if marker%10 != 9 {
+			rec.markReceived(marker, sentAt.Add(time.Duration(20+marker)*time.Millisecond))
+		}
+	}
+	// later detections of the same marker are ignored
+	rec.markReceived(0, start.Add(time.Second))
+
+	res := rec.result(&Result{})
+	require.Equal(t, 20, res.MarkersSent)
+	require.Equal(t, 18, res.MarkersReceived)
+	require.Equal(t, 20.0, res.MinMs)
+	require.Equal(t, 38.0, res.MaxMs)
+	require.Equal(t, 28.0, res.P50Ms)
+	require.Equal(t, 38.0, res.P95Ms)
+}
@@ -0,0 +1,143 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package latencyprobe
+
+import (
+	"math"
+)
+
+const (
+	toneAmplitude = 12000
+	// share of a frame's energy at a marker frequency for the frame to count as a marker
+	toneDetectionRatio = 0.6
+	// frame energy below which a frame is taken as silence, per sample
+	toneSilenceEnergy = 1000 * 1000
+)
+
+var (
+	// markers cycle through these frequencies so that a lost marker does not shift the ones after it
+	toneFrequencies = []float64{697, 941, 1336, 1633}
+)
+
+// ToneGenerator produces mono PCM frames of silence with a tone burst, the marker, every interval.
+// Bursts start at a frame boundary.
+type ToneGenerator struct {
+	sampleRate     int
+	frameSize      int
+	intervalFrames int
+	burstFrames    int
+
+	frame  int
+	marker int
+}
+
+func NewToneGenerator(sampleRate int, frameSize int, intervalFrames int, burstFrames int) *ToneGenerator {
+	return &ToneGenerator{
+		sampleRate:     sampleRate,
+		frameSize:      frameSize,
+		intervalFrames: max(intervalFrames, burstFrames+1),
+		burstFrames:    max(burstFrames, 1),
+	}
+}
+
+// NextFrame returns the next frame and, when a marker starts with this frame, its sequence number
+func (g *ToneGenerator) NextFrame() ([]int16, int, bool) {
+	pcm := make([]int16, g.frameSize)
+
+	pos := g.frame % g.intervalFrames
+	marker := g.marker
+	if pos < g.burstFrames {
+		freq := toneFrequencies[marker%len(toneFrequencies)]
+		for i := range pcm {
+			n := pos*g.frameSize + i
+			pcm[i] = int16(toneAmplitude * math.Sin(2*math.Pi*freq*float64(n)/float64(g.sampleRate)))
+		}
+	}
+
+	g.frame++
+	if pos == g.burstFrames-1 {
+		g.marker++
+	}
+	return pcm, marker, pos == 0
+}
+
+// --------------------------------------
+
+// ToneDetector finds the onset of markers in decoded audio, frame by frame
+type ToneDetector struct {
+	sampleRate int
+
+	inTone bool
+	// sequence number of the last marker detected
+	last int
+}
+
+func NewToneDetector(sampleRate int) *ToneDetector {
+	return &ToneDetector{
+		sampleRate: sampleRate,
+		last:       -1,
+	}
+}
+
+// Process returns the sequence number of a marker starting in this frame. Sequence numbers are
+// recovered from the marker frequency, assuming fewer than a full cycle of markers is lost in a row.
+func (d *ToneDetector) Process(pcm []int16) (int, bool) {
+	if len(pcm) == 0 {
+		return 0, false
+	}
+
+	var energy float64
+	for _, s := range pcm {
+		energy += float64(s) * float64(s)
+	}
+	if energy/float64(len(pcm)) < toneSilenceEnergy {
+		d.inTone = false
+		return 0, false
+	}
+
+	index := -1
+	for i, freq := range toneFrequencies {
+		// Goertzel power is N/2 times the energy of a pure tone at the frequency
+		if goertzel(pcm, freq, d.sampleRate)*2/float64(len(pcm)) >= toneDetectionRatio*energy {
+			index = i
+			break
+		}
+	}
+	if index < 0 || d.inTone {
+		d.inTone = index >= 0
+		return 0, false
+	}
+	d.inTone = true
+
+	n := len(toneFrequencies)
+	marker := index
+	if d.last >= 0 {
+		marker = d.last + 1 + ((index-(d.last+1)%n)+n)%n
+	}
+	d.last = marker
+	return marker, true
+}
+
+func goertzel(pcm []int16, freq float64, sampleRate int) float64 {
+	coeff := 2 * math.Cos(2*math.Pi*freq/float64(sampleRate))
+	var s1, s2 float64
+	for _, sample := range pcm {
+		s0 := float64(sample) + coeff*s1 - s2
+		s2 = s1
+		s1 = s0
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
@@ -0,0 +1,159 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+)
+
+const (
+	// VersionsKey holds the version of every key ever set, maintained by the server
+	VersionsKey = "agentix.versions"
+	// IfVersionsKey in an update lists the versions the writer expects,
+	// the update is rejected unless all of them match. It is never stored.
+	IfVersionsKey = "agentix.if_versions"
+)
+
+var (
+	ErrVersionMismatch = errors.New("metadata version mismatch")
+	ErrTooLarge        = errors.New("merged metadata exceeds size limit")
+)
+
+// Config controls how metadata updates of rooms and participants are applied
+type Config struct {
+	// merge JSON object updates per key instead of replacing the whole metadata
+	Merge bool `yaml:"merge,omitempty"`
+}
+
+// Merge applies update onto current.
+//
+// When update is a JSON object, it is applied per top level key, a null value deletes the key.
+// Every key that changes has its version bumped in VersionsKey, versions of deleted keys are kept
+// so that a delete is seen as a change by concurrent writers. A key that was never set has version 0.
+// If update carries IfVersionsKey, ErrVersionMismatch is returned unless all listed versions match,
+// i. e. a compare-and-swap on those keys.
+//
+// Any other update replaces current as is.
+//
+// The result, VersionsKey included, must not exceed maxSize bytes, ErrTooLarge is returned otherwise.
+// 0 does not limit the size.
+func Merge(current, update string, maxSize uint32) (string, error) {
+	merged, err := merge(current, update)
+	if err != nil {
+		return "", err
+	}
+	if maxSize != 0 && uint32(len(merged)) > maxSize {
+		return "", ErrTooLarge
+	}
+	return merged, nil
+}
+
+func merge(current, update string) (string, error) {
+	var patch map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(update), &patch); err != nil || patch == nil {
+		return update, nil
+	}
+
+	var ifVersions map[string]uint64
+	if raw, ok := patch[IfVersionsKey]; ok {
+		if err := json.Unmarshal(raw, &ifVersions); err != nil {
+			return "", err
+		}
+	}
+
+	doc := parseObject(current)
+	versions := make(map[string]uint64)
+	if raw, ok := doc[VersionsKey]; ok {
+		// versions that cannot be parsed restart from scratch
+		_ = json.Unmarshal(raw, &versions)
+		delete(doc, VersionsKey)
+	}
+
+	for key, expected := range ifVersions {
+		if versions[key] != expected {
+			return "", ErrVersionMismatch
+		}
+	}
+
+	for key, value := range patch {
+		if key == VersionsKey || key == IfVersionsKey {
+			continue
+		}
+
+		existing, exists := doc[key]
+		if isNull(value) {
+			if exists {
+				delete(doc, key)
+				versions[key]++
+			}
+			continue
+		}
+		if exists && equalJSON(existing, value) {
+			continue
+		}
+		doc[key] = value
+		versions[key]++
+	}
+
+	if len(versions) != 0 {
+		raw, err := json.Marshal(versions)
+		if err != nil {
+			return "", err
+		}
+		doc[VersionsKey] = raw
+	}
+	if len(doc) == 0 {
+		return "", nil
+	}
+
+	// map keys are sorted on marshal, equal documents compare equal as strings
+	merged, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(merged), nil
+}
+
+// Version returns the version of a key, 0 if it was never set
+func Version(metadata string, key string) uint64 {
+	var versions map[string]uint64
+	if raw, ok := parseObject(metadata)[VersionsKey]; ok {
+		_ = json.Unmarshal(raw, &versions)
+	}
+	return versions[key]
+}
+
+func parseObject(s string) map[string]json.RawMessage {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s), &doc); err != nil || doc == nil {
+		return make(map[string]json.RawMessage)
+	}
+	return doc
+}
+
+func isNull(raw json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(raw), []byte("null"))
+}
+
+func equalJSON(a, b json.RawMessage) bool {
+	var ca, cb bytes.Buffer
+	if json.Compact(&ca, a) != nil || json.Compact(&cb, b) != nil {
+		return bytes.Equal(a, b)
+	}
+	return bytes.Equal(ca.Bytes(), cb.Bytes())
+}
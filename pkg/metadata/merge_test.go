@@ -0,0 +1,124 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metadata
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("non object replaces", func(t *testing.T) {
+		merged, err := Merge(`{"a":1}`, "plain text", 0)
+		require.NoError(t, err)
+		require.Equal(t, "plain text", merged)
+
+		merged, err = Merge("plain text", `{"a":1}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":1,"agentix.versions":{"a":1}}`, merged)
+	})
+
+	t.Run("concurrent writers keep each others keys", func(t *testing.T) {
+		merged, err := Merge("", `{"agent":"thinking"}`, 0)
+		require.NoError(t, err)
+		merged, err = Merge(merged, `{"client":{"hand_raised":true}}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, `{"agent":"thinking","agentix.versions":{"agent":1,"client":1},"client":{"hand_raised":true}}`, merged)
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		merged, err := Merge("", `{"a":1,"b":2}`, 0)
+		require.NoError(t, err)
+
+		// unchanged value does not bump
+		merged, err = Merge(merged, `{"a": 1, "b":3}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), Version(merged, "a"))
+		require.Equal(t, uint64(2), Version(merged, "b"))
+
+		// delete bumps and keeps the version
+		merged, err = Merge(merged, `{"b":null}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, `{"a":1,"agentix.versions":{"a":1,"b":3}}`, merged)
+
+		// deleting a missing key is a no-op
+		merged, err = Merge(merged, `{"c":null}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), Version(merged, "c"))
+
+		// client supplied versions are ignored
+		merged, err = Merge(merged, `{"agentix.versions":{"a":100}}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), Version(merged, "a"))
+	})
+
+	t.Run("compare and swap", func(t *testing.T) {
+		merged, err := Merge("", `{"turn":"agent"}`, 0)
+		require.NoError(t, err)
+
+		// two writers read version 1, first one wins
+		winner, err := Merge(merged, `{"turn":"user","agentix.if_versions":{"turn":1}}`, 0)
+		require.NoError(t, err)
+		require.Equal(t, `{"agentix.versions":{"turn":2},"turn":"user"}`, winner)
+
+		_, err = Merge(winner, `{"turn":"agent","agentix.if_versions":{"turn":1}}`, 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+
+		// 0 expects the key to never have been set
+		_, err = Merge(winner, `{"lock":"agent","agentix.if_versions":{"lock":0}}`, 0)
+		require.NoError(t, err)
+		_, err = Merge(winner, `{"turn":"agent","agentix.if_versions":{"turn":0}}`, 0)
+		require.ErrorIs(t, err, ErrVersionMismatch)
+
+		_, err = Merge(winner, `{"turn":"agent","agentix.if_versions":"bad"}`, 0)
+		require.Error(t, err)
+	})
+
+	t.Run("merged size is limited", func(t *testing.T) {
+		const maxSize = 128
+
+		// every patch fits, the document with its versions outgrows the limit
+		var merged string
+		var err error
+		for i := 0; ; i++ {
+			patch := fmt.Sprintf(`{"key%d":"value%d"}`, i, i)
+			require.Less(t, len(patch), maxSize)
+
+			var next string
+			next, err = Merge(merged, patch, maxSize)
+			if err != nil {
+				break
+			}
+			require.LessOrEqual(t, len(next), maxSize)
+			merged = next
+		}
+		require.ErrorIs(t, err, ErrTooLarge)
+		require.Greater(t, Version(merged, "key0"), uint64(0))
+
+		// shrinking is still possible
+		merged, err = Merge(merged, `{"key0":null}`, maxSize)
+		require.NoError(t, err)
+		require.Less(t, len(merged), maxSize)
+	})
+
+	t.Run("empty result", func(t *testing.T) {
+		merged, err := Merge("", `{}`, 0)
+		require.NoError(t, err)
+		require.Empty(t, merged)
+	})
+}
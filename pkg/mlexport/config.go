@@ -0,0 +1,80 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mlexport
+
+import (
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// participant attribute, only participants that set it to "true" are exported.
+	// Removing it during the session discards everything recorded of the participant.
+	ConsentAttribute = "agentix.ml_consent"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// directory session archives are written to
+	OutputDir string `yaml:"output_dir,omitempty"`
+	// level above which a frame is labeled as voice, in dBFS
+	VADThreshold float64 `yaml:"vad_threshold,omitempty"`
+	// moving archives to cold storage and deleting them by age
+	Lifecycle LifecycleConfig `yaml:"lifecycle,omitempty"`
+	// normalizing the loudness of every track's audio when the archive is written
+	Loudness audio.LoudnessConfig `yaml:"loudness,omitempty"`
+}
+
+// LifecyclePolicy sets the age in days after which an archive moves to cold storage and after which
+// it is deleted, zero keeps it in its tier
+type LifecyclePolicy struct {
+	ColdAfterDays   int `json:"cold_after_days" yaml:"cold_after_days,omitempty"`
+	DeleteAfterDays int `json:"delete_after_days" yaml:"delete_after_days,omitempty"`
+}
+
+// ProjectPolicy is the lifecycle policy of the archives of a project's rooms
+type ProjectPolicy struct {
+	Name string `yaml:"name,omitempty"`
+	// rooms of the project, the longest matching prefix wins
+	RoomPrefix      string `yaml:"room_prefix,omitempty"`
+	LifecyclePolicy `yaml:",inline"`
+}
+
+type LifecycleConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// directory of the cold storage tier, e. g. a mounted archive bucket
+	ColdDir       string        `yaml:"cold_dir,omitempty"`
+	CheckInterval time.Duration `yaml:"check_interval,omitempty"`
+	// policy of rooms not belonging to a project
+	LifecyclePolicy `yaml:",inline"`
+	Projects        []ProjectPolicy `yaml:"projects,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		OutputDir:    "ml_export",
+		VADThreshold: -45,
+		Lifecycle: LifecycleConfig{
+			ColdDir:       "ml_export_cold",
+			CheckInterval: time.Hour,
+			LifecyclePolicy: LifecyclePolicy{
+				ColdAfterDays: 30,
+			},
+		},
+		Loudness: audio.DefaultLoudnessConfig,
+	}
+)
@@ -0,0 +1,326 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mlexport
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// webhook event carrying a DeletionCertificate as the JSON metadata of its room
+	WebhookEventArchiveDeleted = "ml_export_deleted"
+
+	TierHot  = "hot"
+	TierCold = "cold"
+
+	manifestName = "manifest.json"
+)
+
+// DeletionCertificate records the deletion of an archive by its lifecycle policy, for compliance
+type DeletionCertificate struct {
+	Archive   string          `json:"archive"`
+	Tier      string          `json:"tier"`
+	Project   string          `json:"project,omitempty"`
+	RoomName  string          `json:"room_name,omitempty"`
+	RoomID    string          `json:"room_id,omitempty"`
+	StartedAt time.Time       `json:"started_at,omitempty"`
+	EndedAt   time.Time       `json:"ended_at"`
+	Size      int64           `json:"size"`
+	SHA256    string          `json:"sha256"`
+	Policy    LifecyclePolicy `json:"policy"`
+	DeletedAt time.Time       `json:"deleted_at"`
+}
+
+type LifecycleResult struct {
+	Archived int `json:"archived"`
+	Deleted  int `json:"deleted"`
+	Failed   int `json:"failed"`
+}
+
+type LifecycleParams struct {
+	Config Config
+	// called for every archive deleted by its policy, e. g. to send the certificate as a webhook
+	OnDeleted func(cert *DeletionCertificate)
+	// called for every archive that could not be moved or deleted, it is retried on the next check
+	OnError func(path string, err error)
+}
+
+// Lifecycle moves session archives to the cold storage tier and deletes them once they reach the age
+// the policy of their project sets. Archives are aged from the end of their session.
+type Lifecycle struct {
+	params LifecycleParams
+
+	lock     sync.Mutex
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func NewLifecycle(params LifecycleParams) *Lifecycle {
+	if params.Config.Lifecycle.CheckInterval <= 0 {
+		params.Config.Lifecycle.CheckInterval = DefaultConfig.Lifecycle.CheckInterval
+	}
+	if params.Config.OutputDir == "" {
+		params.Config.OutputDir = DefaultConfig.OutputDir
+	}
+	return &Lifecycle{
+		params:  params,
+		stopped: make(chan struct{}),
+	}
+}
+
+func (l *Lifecycle) Start() {
+	if l == nil {
+		return
+	}
+	go l.worker()
+}
+
+func (l *Lifecycle) Stop() {
+	if l == nil {
+		return
+	}
+	l.stopOnce.Do(func() {
+		close(l.stopped)
+	})
+}
+
+func (l *Lifecycle) worker() {
+	ticker := time.NewTicker(l.params.Config.Lifecycle.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		l.Run(time.Now())
+
+		select {
+		case <-l.stopped:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Run applies the policies to all archives as of now, only one run happens at a time
+func (l *Lifecycle) Run(now time.Time) LifecycleResult {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	var res LifecycleResult
+	config := l.params.Config.Lifecycle
+	coldDir := config.ColdDir
+	if coldDir != "" && filepath.Clean(coldDir) == filepath.Clean(l.params.Config.OutputDir) {
+		coldDir = ""
+	}
+
+	for _, path := range l.listArchives(l.params.Config.OutputDir) {
+		info := readArchiveInfo(path)
+		project, policy := config.Policy(info.RoomName)
+		switch {
+		case policy.due(policy.DeleteAfterDays, info.EndedAt, now):
+			l.delete(&res, path, TierHot, project, policy, info, now)
+
+		case coldDir != "" && policy.due(policy.ColdAfterDays, info.EndedAt, now):
+			if err := moveFile(path, coldDir); err != nil {
+				l.failed(&res, path, err)
+				continue
+			}
+			res.Archived++
+		}
+	}
+
+	if coldDir != "" {
+		for _, path := range l.listArchives(coldDir) {
+			info := readArchiveInfo(path)
+			project, policy := config.Policy(info.RoomName)
+			if policy.due(policy.DeleteAfterDays, info.EndedAt, now) {
+				l.delete(&res, path, TierCold, project, policy, info, now)
+			}
+		}
+	}
+	return res
+}
+
+func (l *Lifecycle) delete(res *LifecycleResult, path string, tier string, project string, policy LifecyclePolicy, info Manifest, now time.Time) {
+	size, digest, err := digestFile(path)
+	if err == nil {
+		err = os.Remove(path)
+	}
+	if err != nil {
+		l.failed(res, path, err)
+		return
+	}
+	res.Deleted++
+
+	if l.params.OnDeleted != nil {
+		l.params.OnDeleted(&DeletionCertificate{
+			Archive:   filepath.Base(path),
+			Tier:      tier,
+			Project:   project,
+			RoomName:  info.RoomName,
+			RoomID:    info.RoomID,
+			StartedAt: info.StartedAt,
+			EndedAt:   info.EndedAt,
+			Size:      size,
+			SHA256:    digest,
+			Policy:    policy,
+			DeletedAt: now,
+		})
+	}
+}
+
+func (l *Lifecycle) failed(res *LifecycleResult, path string, err error) {
+	// another node sharing the storage got to the archive first
+	if errors.Is(err, os.ErrNotExist) {
+		return
+	}
+	res.Failed++
+	if l.params.OnError != nil {
+		l.params.OnError(path, err)
+	}
+}
+
+func (l *Lifecycle) listArchives(dir string) []string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) && l.params.OnError != nil {
+			l.params.OnError(dir, err)
+		}
+		return nil
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		// sessions in progress and partial copies are hidden
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".zip" {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+	return paths
+}
+
+// Policy returns the project and lifecycle policy of a room, the project with the longest matching
+// room prefix, else the default policy
+func (c LifecycleConfig) Policy(roomName string) (string, LifecyclePolicy) {
+	var match *ProjectPolicy
+	for i := range c.Projects {
+		p := &c.Projects[i]
+		if !strings.HasPrefix(roomName, p.RoomPrefix) {
+			continue
+		}
+		if match == nil || len(p.RoomPrefix) > len(match.RoomPrefix) {
+			match = p
+		}
+	}
+	if match == nil {
+		return "", c.LifecyclePolicy
+	}
+	return match.Name, match.LifecyclePolicy
+}
+
+func (p LifecyclePolicy) due(days int, endedAt time.Time, now time.Time) bool {
+	return days > 0 && !now.Before(endedAt.Add(time.Duration(days)*24*time.Hour))
+}
+
+// readArchiveInfo returns the manifest of an archive, archives without one are aged from their
+// modification time and get the default policy
+func readArchiveInfo(path string) Manifest {
+	var manifest Manifest
+	if zr, err := zip.OpenReader(path); err == nil {
+		for _, f := range zr.File {
+			if f.Name != manifestName {
+				continue
+			}
+			if r, err := f.Open(); err == nil {
+				_ = json.NewDecoder(r).Decode(&manifest)
+				r.Close()
+			}
+			break
+		}
+		zr.Close()
+	}
+	if manifest.EndedAt.IsZero() {
+		if stat, err := os.Stat(path); err == nil {
+			manifest.EndedAt = stat.ModTime()
+		}
+	}
+	return manifest
+}
+
+func digestFile(path string) (int64, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, file)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// moveFile moves a file into dir, copying it when dir is on another file system
+func moveFile(path string, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	dst := filepath.Join(dir, filepath.Base(path))
+	if err := os.Rename(path, dst); err == nil || errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp, err := os.CreateTemp(dir, ".move-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, src); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), dst); err != nil {
+		return fmt.Errorf("could not move %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
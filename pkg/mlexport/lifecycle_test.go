@@ -0,0 +1,99 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mlexport
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLifecycle(t *testing.T) {
+	dir := t.TempDir()
+	config := DefaultConfig
+	config.OutputDir = filepath.Join(dir, "hot")
+	config.Lifecycle.ColdDir = filepath.Join(dir, "cold")
+	config.Lifecycle.LifecyclePolicy = LifecyclePolicy{ColdAfterDays: 30, DeleteAfterDays: 365}
+	config.Lifecycle.Projects = []ProjectPolicy{
+		{Name: "acme", RoomPrefix: "acme-", LifecyclePolicy: LifecyclePolicy{ColdAfterDays: 7, DeleteAfterDays: 90}},
+		{Name: "acme-legal", RoomPrefix: "acme-legal-", LifecyclePolicy: LifecyclePolicy{ColdAfterDays: 1}},
+	}
+	require.NoError(t, os.MkdirAll(config.OutputDir, 0o755))
+
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	days := func(n int) time.Time { return now.Add(-time.Duration(n) * 24 * time.Hour) }
+	archive := func(name string, roomName string, endedAt time.Time) string {
+		path := filepath.Join(config.OutputDir, name)
+		require.NoError(t, writeArchive(path, &Manifest{
+			RoomName:   roomName,
+			RoomID:     "RM_" + roomName,
+			EndedAt:    endedAt,
+			Transcript: "transcript.json",
+		}, nil, nil))
+		return path
+	}
+
+	archive("support.zip", "support-1", days(10))
+	archive("acme.zip", "acme-1", days(10))
+	archive("legal.zip", "acme-legal-1", days(1000))
+	archive("old.zip", "support-2", days(400))
+	require.NoError(t, os.Mkdir(filepath.Join(config.OutputDir, ".session-1"), 0o755))
+
+	var certificates []*DeletionCertificate
+	lifecycle := NewLifecycle(LifecycleParams{
+		Config: config,
+		OnDeleted: func(cert *DeletionCertificate) {
+			certificates = append(certificates, cert)
+		},
+	})
+
+	t.Run("policies by project", func(t *testing.T) {
+		require.Equal(t, LifecycleResult{Archived: 2, Deleted: 1}, lifecycle.Run(now))
+
+		require.FileExists(t, filepath.Join(config.OutputDir, "support.zip"))
+		require.FileExists(t, filepath.Join(config.Lifecycle.ColdDir, "acme.zip"))
+		// the most specific project keeps its archives in cold storage
+		require.FileExists(t, filepath.Join(config.Lifecycle.ColdDir, "legal.zip"))
+		require.NoFileExists(t, filepath.Join(config.OutputDir, "old.zip"))
+		require.DirExists(t, filepath.Join(config.OutputDir, ".session-1"))
+
+		require.Len(t, certificates, 1)
+		cert := certificates[0]
+		require.Equal(t, "old.zip", cert.Archive)
+		require.Equal(t, TierHot, cert.Tier)
+		require.Empty(t, cert.Project)
+		require.Equal(t, "support-2", cert.RoomName)
+		require.Equal(t, 365, cert.Policy.DeleteAfterDays)
+		require.Len(t, cert.SHA256, 64)
+		require.NotZero(t, cert.Size)
+		require.Equal(t, now, cert.DeletedAt)
+	})
+
+	t.Run("deletes from cold storage", func(t *testing.T) {
+		certificates = nil
+		require.Equal(t, LifecycleResult{Archived: 1, Deleted: 1}, lifecycle.Run(now.Add(90*24*time.Hour)))
+
+		require.FileExists(t, filepath.Join(config.Lifecycle.ColdDir, "support.zip"))
+		require.NoFileExists(t, filepath.Join(config.Lifecycle.ColdDir, "acme.zip"))
+		require.FileExists(t, filepath.Join(config.Lifecycle.ColdDir, "legal.zip"))
+		require.Len(t, certificates, 1)
+		require.Equal(t, TierCold, certificates[0].Tier)
+		require.Equal(t, "acme", certificates[0].Project)
+	})
+}
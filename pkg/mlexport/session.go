@@ -0,0 +1,339 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mlexport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+var (
+	ErrSessionFinished = errors.New("export session already finished")
+
+	unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+)
+
+// TranscriptSegment is a final transcription segment, times in seconds from the start of the session
+type TranscriptSegment struct {
+	ID                  string  `json:"id"`
+	ParticipantIdentity string  `json:"participant_identity"`
+	TrackID             string  `json:"track_id,omitempty"`
+	Text                string  `json:"text"`
+	Language            string  `json:"language,omitempty"`
+	Start               float64 `json:"start"`
+	End                 float64 `json:"end"`
+}
+
+type ManifestTrack struct {
+	ParticipantIdentity string       `json:"participant_identity"`
+	TrackID             string       `json:"track_id"`
+	Audio               string       `json:"audio"`
+	SampleRate          int          `json:"sample_rate"`
+	Duration            float64      `json:"duration"`
+	VAD                 []VADSegment `json:"vad"`
+	// loudness of the audio, when it is normalized
+	Loudness *TrackLoudness `json:"loudness,omitempty"`
+}
+
+type Manifest struct {
+	RoomName   string          `json:"room_name"`
+	RoomID     string          `json:"room_id"`
+	StartedAt  time.Time       `json:"started_at"`
+	EndedAt    time.Time       `json:"ended_at"`
+	Tracks     []ManifestTrack `json:"tracks"`
+	Transcript string          `json:"transcript"`
+}
+
+// Session collects the audio, transcriptions and voice activity of consenting participants
+// of one room session and packages them into a single zip archive when finished.
+type Session struct {
+	config   Config
+	roomName string
+	roomID   string
+	started  time.Time
+	dir      string
+
+	lock       sync.Mutex
+	tracks     map[string]*TrackRecorder
+	finished   []*TrackRecorder
+	transcript []TranscriptSegment
+	done       bool
+}
+
+func NewSession(config Config, roomName string, roomID string) (*Session, error) {
+	if err := os.MkdirAll(config.OutputDir, 0o755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(config.OutputDir, ".session-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		config:   config,
+		roomName: roomName,
+		roomID:   roomID,
+		started:  time.Now(),
+		dir:      dir,
+		tracks:   make(map[string]*TrackRecorder),
+	}, nil
+}
+
+func (s *Session) StartedAt() time.Time {
+	return s.started
+}
+
+// AddTrack starts recording a track, returns the existing recorder if the track is already recorded
+func (s *Session) AddTrack(identity string, trackID string) (*TrackRecorder, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done {
+		return nil, ErrSessionFinished
+	}
+	if t, ok := s.tracks[trackID]; ok {
+		return t, nil
+	}
+
+	name := fmt.Sprintf("%s_%s_%d.wav", sanitizeName(identity), sanitizeName(trackID), len(s.tracks)+len(s.finished))
+	t, err := newTrackRecorder(filepath.Join(s.dir, name), identity, trackID, s.started, s.config.VADThreshold)
+	if err != nil {
+		return nil, err
+	}
+	if s.config.Loudness.Enabled {
+		t.meter = audio.NewLoudnessMeter(SampleRate, 1)
+	}
+	s.tracks[trackID] = t
+	return t, nil
+}
+
+// WriteFrame writes decoded audio of a recorded track, frames of unknown tracks are dropped
+func (s *Session) WriteFrame(trackID string, arrival time.Time, rtpTimestamp uint32, pcm []int16) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, ok := s.tracks[trackID]
+	if !ok {
+		return nil
+	}
+	return t.WriteFrame(arrival, rtpTimestamp, pcm)
+}
+
+// RemoveTrack stops recording a track, what was recorded so far is kept for the archive
+func (s *Session) RemoveTrack(trackID string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	t, ok := s.tracks[trackID]
+	if !ok {
+		return nil
+	}
+	delete(s.tracks, trackID)
+	s.finished = append(s.finished, t)
+	return t.close()
+}
+
+// DiscardParticipant drops all audio and transcriptions of a participant, used when consent is revoked
+func (s *Session) DiscardParticipant(identity string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done {
+		return
+	}
+	for trackID, t := range s.tracks {
+		if t.identity == identity {
+			delete(s.tracks, trackID)
+			_ = t.close()
+			_ = os.Remove(t.path)
+		}
+	}
+	finished := s.finished[:0]
+	for _, t := range s.finished {
+		if t.identity == identity {
+			_ = os.Remove(t.path)
+			continue
+		}
+		finished = append(finished, t)
+	}
+	s.finished = finished
+
+	transcript := s.transcript[:0]
+	for _, seg := range s.transcript {
+		if seg.ParticipantIdentity != identity {
+			transcript = append(transcript, seg)
+		}
+	}
+	s.transcript = transcript
+}
+
+// AddTranscription records a final transcription segment. Segments are keyed by ID,
+// a later segment with the same ID replaces the earlier one.
+func (s *Session) AddTranscription(seg TranscriptSegment) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.done {
+		return
+	}
+	for i := range s.transcript {
+		if seg.ID != "" && s.transcript[i].ID == seg.ID {
+			s.transcript[i] = seg
+			return
+		}
+	}
+	s.transcript = append(s.transcript, seg)
+}
+
+// Finish stops all recordings and writes the session archive, returns the archive path.
+// Nothing is written when no participant consented.
+func (s *Session) Finish() (string, error) {
+	s.lock.Lock()
+	if s.done {
+		s.lock.Unlock()
+		return "", ErrSessionFinished
+	}
+	s.done = true
+	for trackID, t := range s.tracks {
+		delete(s.tracks, trackID)
+		_ = t.close()
+		s.finished = append(s.finished, t)
+	}
+	tracks := s.finished
+	transcript := s.transcript
+	s.lock.Unlock()
+
+	defer os.RemoveAll(s.dir)
+
+	if len(tracks) == 0 {
+		return "", nil
+	}
+
+	sort.Slice(tracks, func(i, j int) bool {
+		if tracks[i].identity != tracks[j].identity {
+			return tracks[i].identity < tracks[j].identity
+		}
+		return tracks[i].path < tracks[j].path
+	})
+	sort.SliceStable(transcript, func(i, j int) bool {
+		return transcript[i].Start < transcript[j].Start
+	})
+
+	manifest := Manifest{
+		RoomName:   s.roomName,
+		RoomID:     s.roomID,
+		StartedAt:  s.started,
+		EndedAt:    time.Now(),
+		Transcript: "transcript.json",
+	}
+	for _, t := range tracks {
+		track := ManifestTrack{
+			ParticipantIdentity: t.identity,
+			TrackID:             t.trackID,
+			Audio:               "audio/" + filepath.Base(t.path),
+			SampleRate:          SampleRate,
+			Duration:            samplesToSeconds(t.wav.Samples()),
+			VAD:                 t.segments,
+		}
+		if t.meter != nil {
+			loudness, err := t.normalize(s.config.Loudness)
+			if err != nil {
+				return "", err
+			}
+			track.Loudness = loudness
+		}
+		manifest.Tracks = append(manifest.Tracks, track)
+	}
+
+	name := fmt.Sprintf("%s_%s_%s.zip", sanitizeName(s.roomName), sanitizeName(s.roomID), s.started.UTC().Format("20060102T150405Z"))
+	path := filepath.Join(s.config.OutputDir, name)
+	if err := writeArchive(path, &manifest, tracks, transcript); err != nil {
+		_ = os.Remove(path)
+		return "", err
+	}
+	return path, nil
+}
+
+func writeArchive(path string, manifest *Manifest, tracks []*TrackRecorder, transcript []TranscriptSegment) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	zw := zip.NewWriter(file)
+	for i, t := range manifest.Tracks {
+		if err := addFile(zw, t.Audio, tracks[i].path); err != nil {
+			return err
+		}
+	}
+	if transcript == nil {
+		transcript = []TranscriptSegment{}
+	}
+	if err := addJSON(zw, manifest.Transcript, transcript); err != nil {
+		return err
+	}
+	if err := addJSON(zw, manifestName, manifest); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+func addFile(zw *zip.Writer, name string, path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func addJSON(zw *zip.Writer, name string, v any) error {
+	dst, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(dst)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func sanitizeName(name string) string {
+	if name == "" {
+		return "_"
+	}
+	return unsafeNameChars.ReplaceAllString(name, "_")
+}
@@ -0,0 +1,204 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mlexport
+
+import (
+	"archive/zip"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func tone(n int, amplitude int16) []int16 {
+	pcm := make([]int16, n)
+	for i := range pcm {
+		if i%2 == 0 {
+			pcm[i] = amplitude
+		} else {
+			pcm[i] = -amplitude
+		}
+	}
+	return pcm
+}
+
+func TestTrackRecorderAlignment(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	rec, err := newTrackRecorder(dir+"/a.wav", "a", "TR_a", start, DefaultConfig.VADThreshold)
+	require.NoError(t, err)
+
+	// first frame arrives 100 ms into the session
+	arrival := start.Add(100 * time.Millisecond)
+	require.NoError(t, rec.WriteFrame(arrival, 1000, tone(960, 10000)))
+	require.Equal(t, int64(4800+960), rec.wav.Samples())
+
+	// DTX gap of 20 frames is padded
+	require.NoError(t, rec.WriteFrame(arrival, 1000+21*960, tone(960, 10000)))
+	require.Equal(t, int64(4800+22*960), rec.wav.Samples())
+
+	// late packet is dropped
+	require.NoError(t, rec.WriteFrame(arrival, 1000+960, tone(960, 10000)))
+	require.Equal(t, int64(4800+22*960), rec.wav.Samples())
+
+	require.NoError(t, rec.close())
+	require.Equal(t, []VADSegment{
+		{Start: 0.1, End: 0.12},
+		{Start: 0.52, End: 0.54},
+	}, rec.segments)
+}
+
+func TestTrackRecorderVADHangover(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	rec, err := newTrackRecorder(dir+"/a.wav", "a", "TR_a", start, DefaultConfig.VADThreshold)
+	require.NoError(t, err)
+
+	ts := uint32(0)
+	write := func(amplitude int16) {
+		require.NoError(t, rec.WriteFrame(start, ts, tone(960, amplitude)))
+		ts += 960
+	}
+	write(10000)
+	// short pause within hangover
+	for i := 0; i < 5; i++ {
+		write(0)
+	}
+	write(10000)
+	require.NoError(t, rec.close())
+	require.Equal(t, []VADSegment{{Start: 0, End: 0.14}}, rec.segments)
+}
+
+func TestSessionArchive(t *testing.T) {
+	config := DefaultConfig
+	config.OutputDir = t.TempDir()
+
+	s, err := NewSession(config, "room/1", "RM_1")
+	require.NoError(t, err)
+
+	_, err = s.AddTrack("alice", "TR_alice")
+	require.NoError(t, err)
+	_, err = s.AddTrack("bob", "TR_bob")
+	require.NoError(t, err)
+
+	require.NoError(t, s.WriteFrame("TR_alice", s.StartedAt(), 0, tone(960, 10000)))
+	require.NoError(t, s.WriteFrame("TR_bob", s.StartedAt(), 0, tone(960, 10000)))
+	s.AddTranscription(TranscriptSegment{ID: "1", ParticipantIdentity: "alice", Text: "hel", Start: 0, End: 0.5})
+	s.AddTranscription(TranscriptSegment{ID: "1", ParticipantIdentity: "alice", Text: "hello", Start: 0, End: 0.5})
+	s.AddTranscription(TranscriptSegment{ID: "2", ParticipantIdentity: "bob", Text: "secret", Start: 1, End: 1.5})
+
+	// bob revokes consent
+	s.DiscardParticipant("bob")
+
+	path, err := s.Finish()
+	require.NoError(t, err)
+	require.NotEmpty(t, path)
+
+	_, err = s.Finish()
+	require.ErrorIs(t, err, ErrSessionFinished)
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	require.Len(t, files, 3)
+
+	var manifest Manifest
+	readJSON(t, files["manifest.json"], &manifest)
+	require.Equal(t, "RM_1", manifest.RoomID)
+	require.Len(t, manifest.Tracks, 1)
+	require.Equal(t, "alice", manifest.Tracks[0].ParticipantIdentity)
+	require.Contains(t, files, manifest.Tracks[0].Audio)
+	require.Len(t, manifest.Tracks[0].VAD, 1)
+
+	var transcript []TranscriptSegment
+	readJSON(t, files[manifest.Transcript], &transcript)
+	require.Len(t, transcript, 1)
+	require.Equal(t, "hello", transcript[0].Text)
+}
+
+func TestSessionLoudness(t *testing.T) {
+	config := DefaultConfig
+	config.OutputDir = t.TempDir()
+	config.Loudness.Enabled = true
+
+	s, err := NewSession(config, "room", "RM_1")
+	require.NoError(t, err)
+
+	_, err = s.AddTrack("alice", "TR_alice")
+	require.NoError(t, err)
+	for i := 0; i < 100; i++ {
+		require.NoError(t, s.WriteFrame("TR_alice", s.StartedAt(), uint32(i*960), tone(960, 3000)))
+	}
+
+	path, err := s.Finish()
+	require.NoError(t, err)
+
+	zr, err := zip.OpenReader(path)
+	require.NoError(t, err)
+	defer zr.Close()
+
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+	var manifest Manifest
+	readJSON(t, files["manifest.json"], &manifest)
+	require.Len(t, manifest.Tracks, 1)
+
+	loudness := manifest.Tracks[0].Loudness
+	require.NotNil(t, loudness)
+	require.Greater(t, loudness.Integrated, config.Loudness.Target)
+	require.Less(t, loudness.Gain, 0.0)
+	require.InDelta(t, config.Loudness.Target, loudness.Normalized, 1e-9)
+
+	// the audio is scaled by the gain
+	r, err := files[manifest.Tracks[0].Audio].Open()
+	require.NoError(t, err)
+	defer r.Close()
+	wav, err := io.ReadAll(r)
+	require.NoError(t, err)
+	sample := int16(binary.LittleEndian.Uint16(wav[44:]))
+	require.InDelta(t, 3000*math.Pow(10, loudness.Gain/20), float64(sample), 1)
+}
+
+func TestSessionWithoutConsent(t *testing.T) {
+	config := DefaultConfig
+	config.OutputDir = t.TempDir()
+
+	s, err := NewSession(config, "room", "RM_1")
+	require.NoError(t, err)
+
+	path, err := s.Finish()
+	require.NoError(t, err)
+	require.Empty(t, path)
+}
+
+func readJSON(t *testing.T, f *zip.File, v any) {
+	require.NotNil(t, f)
+	r, err := f.Open()
+	require.NoError(t, err)
+	defer r.Close()
+	require.NoError(t, json.NewDecoder(r).Decode(v))
+}
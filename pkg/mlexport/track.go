@@ -0,0 +1,207 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mlexport
+
+import (
+	"math"
+	"os"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	SampleRate = audio.OpusSampleRate
+
+	// voice segments closer than this are merged
+	vadHangover = 200 * time.Millisecond
+	// gaps longer than this are treated as a timestamp jump and not padded
+	maxSilenceGap = 10 * time.Minute
+)
+
+// VADSegment is a span of voice activity, in seconds from the start of the session
+type VADSegment struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// TrackRecorder writes the decoded audio of one published track as mono WAV aligned to the
+// start of the session: audio starts at the offset the track was first received at and gaps,
+// e. g. DTX or mute, are filled with silence, so all tracks of a session share one timeline.
+type TrackRecorder struct {
+	identity     string
+	trackID      string
+	sessionStart time.Time
+	vadThreshold float64
+	path         string
+
+	file *os.File
+	wav  *audio.WAVWriter
+
+	started     bool
+	firstTS     uint32
+	startSample int64
+
+	segments   []VADSegment
+	inVoice    bool
+	voiceStart int64
+	voiceEnd   int64
+
+	// loudness of the recorded audio, nil unless it is normalized
+	meter *audio.LoudnessMeter
+}
+
+// TrackLoudness is the loudness of a track's audio as recorded and the gain normalizing it
+type TrackLoudness struct {
+	audio.LoudnessStats
+	// gain applied to the audio, in dB
+	Gain float64 `json:"gain_db"`
+	// integrated loudness of the normalized audio, in LUFS
+	Normalized float64 `json:"normalized_lufs"`
+}
+
+func newTrackRecorder(path string, identity string, trackID string, sessionStart time.Time, vadThreshold float64) (*TrackRecorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	wav, err := audio.NewWAVWriter(file, SampleRate, 1)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &TrackRecorder{
+		identity:     identity,
+		trackID:      trackID,
+		sessionStart: sessionStart,
+		vadThreshold: vadThreshold,
+		path:         path,
+		file:         file,
+		wav:          wav,
+	}, nil
+}
+
+// WriteFrame writes a decoded frame, arrival is used to place the first frame, RTP timestamps the rest
+func (t *TrackRecorder) WriteFrame(arrival time.Time, rtpTimestamp uint32, pcm []int16) error {
+	if !t.started {
+		t.started = true
+		t.firstTS = rtpTimestamp
+		t.startSample = max(0, durationToSamples(arrival.Sub(t.sessionStart)))
+	}
+
+	pos := t.startSample + int64(rtpTimestamp-t.firstTS)
+	written := t.wav.Samples()
+	if pos < written {
+		// late or duplicate
+		return nil
+	}
+	if gap := pos - written; gap > 0 && gap <= durationToSamples(maxSilenceGap) {
+		if err := t.wav.WriteSilence(int(gap)); err != nil {
+			return err
+		}
+	}
+	pos = t.wav.Samples()
+	if err := t.wav.Write(pcm); err != nil {
+		return err
+	}
+	if t.meter != nil {
+		t.meter.Write(pcm)
+	}
+
+	t.labelFrame(pos, pcm)
+	return nil
+}
+
+func (t *TrackRecorder) labelFrame(pos int64, pcm []int16) {
+	end := pos + int64(len(pcm))
+	if levelDBFS(pcm) > t.vadThreshold {
+		if !t.inVoice || pos-t.voiceEnd > durationToSamples(vadHangover) {
+			t.closeSegment()
+			t.inVoice = true
+			t.voiceStart = pos
+		}
+		t.voiceEnd = end
+	}
+}
+
+func (t *TrackRecorder) closeSegment() {
+	if !t.inVoice {
+		return
+	}
+	t.segments = append(t.segments, VADSegment{
+		Start: samplesToSeconds(t.voiceStart),
+		End:   samplesToSeconds(t.voiceEnd),
+	})
+	t.inVoice = false
+}
+
+func (t *TrackRecorder) close() error {
+	t.closeSegment()
+	err := t.wav.Close()
+	if cerr := t.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// normalize scales the closed recording to the target loudness of config, returns its loudness before and after
+func (t *TrackRecorder) normalize(config audio.LoudnessConfig) (*TrackLoudness, error) {
+	stats := t.meter.Stats()
+	loudness := &TrackLoudness{
+		LoudnessStats: stats,
+		Gain:          stats.Gain(config),
+		Normalized:    stats.Integrated,
+	}
+	if loudness.Gain == 0 {
+		return loudness, nil
+	}
+
+	file, err := os.OpenFile(t.path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if err := audio.ScaleWAV(file, math.Pow(10, loudness.Gain/20)); err != nil {
+		return nil, err
+	}
+	loudness.Normalized += loudness.Gain
+	return loudness, file.Close()
+}
+
+func durationToSamples(d time.Duration) int64 {
+	return int64(d) * SampleRate / int64(time.Second)
+}
+
+func samplesToSeconds(samples int64) float64 {
+	return float64(samples) / SampleRate
+}
+
+func levelDBFS(pcm []int16) float64 {
+	if len(pcm) == 0 {
+		return -100
+	}
+	var sum float64
+	for _, s := range pcm {
+		sum += float64(s) * float64(s)
+	}
+	rms := math.Sqrt(sum/float64(len(pcm))) / 32768
+	if rms == 0 {
+		return -100
+	}
+	return 20 * math.Log10(rms)
+}
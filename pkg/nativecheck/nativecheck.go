@@ -0,0 +1,195 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nativecheck
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// check native libraries at startup instead of finding out at the first packet
+	Enabled bool `yaml:"enabled,omitempty"`
+	// refuse to start when a library needed by the configuration fails, otherwise only log
+	Strict bool `yaml:"strict,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Enabled: true,
+		Strict:  true,
+	}
+)
+
+// --------------------------------------
+
+type Stage string
+
+const (
+	// the library is linked into the build and an instance can be created
+	StageLoad Stage = "load"
+	// the loaded library is recent enough
+	StageVersion Stage = "version"
+	// processing a synthetic signal gives sane output
+	StageSelfTest Stage = "self_test"
+)
+
+// Error is a failed check of a native library
+type Error struct {
+	Library string
+	Stage   Stage
+	Err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("native library %s failed %s check: %v", e.Library, e.Stage, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// --------------------------------------
+
+type Library struct {
+	Name string
+	// whether the configuration depends on the library
+	Required bool
+	// Load makes the library ready for use, returns an error if it is not part of the build
+	Load func() error
+	// Version returns the version of the loaded library, nil when the library does not report one
+	Version func() string
+	// oldest supported version, dotted numbers, e. g. "1.3"
+	MinVersion string
+	// SelfTest processes a synthetic signal, nil to skip
+	SelfTest func() error
+}
+
+type Result struct {
+	Library  string        `json:"library"`
+	Required bool          `json:"required"`
+	Version  string        `json:"version,omitempty"`
+	Duration time.Duration `json:"duration"`
+	Err      *Error        `json:"-"`
+}
+
+func (r Result) OK() bool {
+	return r.Err == nil
+}
+
+// Check runs the checks of a library, stopping at the first failed stage
+func Check(lib Library) Result {
+	start := time.Now()
+	result := Result{Library: lib.Name, Required: lib.Required}
+
+	fail := func(stage Stage, err error) Result {
+		result.Err = &Error{Library: lib.Name, Stage: stage, Err: err}
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	if lib.Load != nil {
+		if err := runSafely(lib.Load); err != nil {
+			return fail(StageLoad, err)
+		}
+	}
+
+	if lib.Version != nil {
+		result.Version = lib.Version()
+		if lib.MinVersion != "" && CompareVersions(result.Version, lib.MinVersion) < 0 {
+			return fail(StageVersion, fmt.Errorf("version %q is older than %s", result.Version, lib.MinVersion))
+		}
+	}
+
+	if lib.SelfTest != nil {
+		if err := runSafely(lib.SelfTest); err != nil {
+			return fail(StageSelfTest, err)
+		}
+	}
+
+	result.Duration = time.Since(start)
+	return result
+}
+
+// CheckAll checks all libraries, the returned error joins the failures of required libraries
+func CheckAll(libs []Library) ([]Result, error) {
+	results := make([]Result, 0, len(libs))
+	var errs []error
+	for _, lib := range libs {
+		result := Check(lib)
+		results = append(results, result)
+		if result.Err != nil && result.Required {
+			errs = append(errs, result.Err)
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// a misbehaving native library is reported rather than taking the process down
+func runSafely(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return f()
+}
+
+// CompareVersions compares the first dotted number found in each version string,
+// e. g. "libopus 1.3.1" and "1.4", missing components count as 0
+func CompareVersions(a, b string) int {
+	va, vb := parseVersion(a), parseVersion(b)
+	for i := 0; i < max(len(va), len(vb)); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+func parseVersion(v string) []int {
+	start := strings.IndexAny(v, "0123456789")
+	if start < 0 {
+		return nil
+	}
+	v = v[start:]
+	if end := strings.IndexFunc(v, func(r rune) bool { return r != '.' && (r < '0' || r > '9') }); end >= 0 {
+		v = v[:end]
+	}
+
+	var parts []int
+	for _, p := range strings.Split(strings.Trim(v, "."), ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nativecheck
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	require.Equal(t, 0, CompareVersions("libopus 1.3.1", "1.3.1"))
+	require.Equal(t, 1, CompareVersions("libopus 1.4", "1.3.1"))
+	require.Equal(t, -1, CompareVersions("libopus 1.3", "1.3.1"))
+	require.Equal(t, 0, CompareVersions("1.3.0-beta", "1.3"))
+	require.Equal(t, -1, CompareVersions("unknown", "1.0"))
+}
+
+func TestCheck(t *testing.T) {
+	t.Run("passes", func(t *testing.T) {
+		result := Check(Library{
+			Name:       "lib",
+			Load:       func() error { return nil },
+			Version:    func() string { return "lib 2.0" },
+			MinVersion: "1.3",
+			SelfTest:   func() error { return nil },
+		})
+		require.True(t, result.OK())
+		require.Equal(t, "lib 2.0", result.Version)
+	})
+
+	t.Run("stops at load", func(t *testing.T) {
+		selfTested := false
+		result := Check(Library{
+			Name:     "lib",
+			Load:     func() error { return errors.New("not linked") },
+			SelfTest: func() error { selfTested = true; return nil },
+		})
+		require.False(t, result.OK())
+		require.Equal(t, StageLoad, result.Err.Stage)
+		require.False(t, selfTested)
+	})
+
+	t.Run("too old", func(t *testing.T) {
+		result := Check(Library{
+			Name:       "lib",
+			Version:    func() string { return "lib 1.1" },
+			MinVersion: "1.3",
+		})
+		require.Equal(t, StageVersion, result.Err.Stage)
+	})
+
+	t.Run("recovers self test panic", func(t *testing.T) {
+		result := Check(Library{
+			Name:     "lib",
+			SelfTest: func() error { panic("bad frame") },
+		})
+		require.Equal(t, StageSelfTest, result.Err.Stage)
+		require.ErrorContains(t, result.Err, "bad frame")
+	})
+}
+
+func TestCheckAll(t *testing.T) {
+	failing := func() error { return errors.New("broken") }
+	results, err := CheckAll([]Library{
+		{Name: "optional", Load: failing},
+		{Name: "required", Required: true, Load: failing},
+		{Name: "ok", Required: true},
+	})
+	require.Len(t, results, 3)
+
+	var nativeErr *Error
+	require.ErrorAs(t, err, &nativeErr)
+	require.Equal(t, "required", nativeErr.Library)
+	require.NotContains(t, err.Error(), "optional")
+}
@@ -0,0 +1,40 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcmtap
+
+import (
+	"encoding/binary"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative pcmtap.proto
+
+// NewAudioFrame returns the AudioFrame message of pcmtap.proto carrying a frame
+func NewAudioFrame(f Frame) *AudioFrame {
+	pcm := make([]byte, 2*len(f.PCM))
+	for i, sample := range f.PCM {
+		binary.LittleEndian.PutUint16(pcm[2*i:], uint16(sample))
+	}
+	frame := &AudioFrame{
+		Pcm:           pcm,
+		SampleRate:    uint32(f.SampleRate),
+		TimestampUs:   uint64(f.Timestamp.Microseconds()),
+		DroppedFrames: uint32(f.Dropped),
+	}
+	if !f.CaptureTime.IsZero() {
+		frame.CaptureTimeUs = uint64(f.CaptureTime.UnixMicro())
+	}
+	return frame
+}
@@ -0,0 +1,374 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pcmtap streams the decoded audio of published tracks to consumers outside of the server,
+// e. g. transcription engines, that would otherwise have to join rooms as WebRTC participants.
+package pcmtap
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// sample rates audio is delivered at
+	SampleRate16k = 16000
+	SampleRate48k = audio.OpusSampleRate
+
+	// duration of the frames the ring buffers are sized in, that of typical Opus packets
+	frameDuration = 20 * time.Millisecond
+
+	// gaps in the audio of ASR subscribers longer than this are skipped instead of filled with silence
+	maxASRGap = 2 * time.Second
+)
+
+// profiles selecting how the audio of a track is cut into frames for a subscriber, see Profile of pcmtap.proto
+const (
+	// a frame per packet, at the sample rate subscribed to
+	ProfileRaw = Profile_PROFILE_RAW
+	// frames of exactly 20 ms of 16 kHz audio on a continuous timeline, gaps up to 2 s are filled with silence,
+	// as speech recognition engines expect
+	ProfileASR = Profile_PROFILE_ASR
+)
+
+var (
+	ErrUnsupportedSampleRate = errors.New("unsupported sample rate, must be 16000 or 48000")
+	ErrUnsupportedProfile    = errors.New("unsupported pcm tap profile")
+	ErrTooManySubscribers    = errors.New("too many pcm tap subscribers on track")
+	ErrStreamClosed          = errors.New("pcm tap stream closed")
+)
+
+// Config enables a gRPC service streaming the decoded audio of any track of the rooms of the node after
+// noise filtering, as mono 16 bit PCM at 16 or 48 kHz. Every subscriber has a ring buffer absorbing a
+// consumer falling behind, when it fills the oldest audio is dropped and the gap reported.
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// port the gRPC service listens on
+	Port int `yaml:"port,omitempty"`
+	// audio buffered per subscriber ahead of a slow consumer
+	BufferDuration time.Duration `yaml:"buffer_duration,omitempty"`
+	// consumers of a track at a time
+	MaxSubscribers int `yaml:"max_subscribers,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Port:           7883,
+		BufferDuration: 2 * time.Second,
+		MaxSubscribers: 8,
+	}
+)
+
+// Frame is the audio of a packet of a track
+type Frame struct {
+	// mono 16 bit samples
+	PCM        []int16
+	SampleRate int
+	// position of the frame in the track since the subscription started
+	Timestamp time.Duration
+	// frames dropped ahead of this one because the subscriber fell behind
+	Dropped int
+	// when the server received the start of the frame, increasing by the duration of every frame, and by the
+	// gaps skipped in between
+	CaptureTime time.Time
+}
+
+// --------------------------------------
+
+// Stream fans the decoded audio of a track out to its subscribers. Closing it ends the subscribers
+// once they have read the audio buffered for them.
+type Stream struct {
+	config  Config
+	onEmpty func()
+
+	lock        sync.Mutex
+	subscribers map[*Subscriber]struct{}
+	closed      bool
+}
+
+// NewStream creates the stream of a track, onEmpty is called when its last subscriber goes away
+func NewStream(config Config, onEmpty func()) *Stream {
+	if config.BufferDuration <= 0 {
+		config.BufferDuration = DefaultConfig.BufferDuration
+	}
+	if config.MaxSubscribers <= 0 {
+		config.MaxSubscribers = DefaultConfig.MaxSubscribers
+	}
+	return &Stream{
+		config:      config,
+		onEmpty:     onEmpty,
+		subscribers: make(map[*Subscriber]struct{}),
+	}
+}
+
+// Subscribe adds a subscriber receiving the audio written from now on at sampleRate
+func (s *Stream) Subscribe(sampleRate int) (*Subscriber, error) {
+	return s.SubscribeProfile(ProfileRaw, sampleRate)
+}
+
+// SubscribeProfile adds a subscriber receiving the audio written from now on in frames of the profile.
+// ASR subscribers receive 16 kHz audio, sampleRate has to be 16000 or 0.
+func (s *Stream) SubscribeProfile(profile Profile, sampleRate int) (*Subscriber, error) {
+	if profile != ProfileRaw && profile != ProfileASR {
+		return nil, ErrUnsupportedProfile
+	}
+	if profile == ProfileASR && sampleRate == 0 {
+		sampleRate = SampleRate16k
+	}
+	if sampleRate != SampleRate16k && (sampleRate != SampleRate48k || profile == ProfileASR) {
+		return nil, ErrUnsupportedSampleRate
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return nil, ErrStreamClosed
+	}
+	if len(s.subscribers) >= s.config.MaxSubscribers {
+		return nil, ErrTooManySubscribers
+	}
+
+	sub := &Subscriber{
+		stream:     s,
+		sampleRate: sampleRate,
+		frames:     make([]Frame, max(int(s.config.BufferDuration/frameDuration), 1)),
+		notify:     make(chan struct{}, 1),
+	}
+	if sampleRate != SampleRate48k {
+		sub.resampler = audio.NewResampler(SampleRate48k, sampleRate, 1)
+	}
+	if profile == ProfileASR {
+		sub.chunkSize = int(int64(sampleRate) * int64(frameDuration) / int64(time.Second))
+		sub.pending = make([]int16, 0, sub.chunkSize)
+	}
+	s.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+// Write delivers mono 16 bit PCM at 48 kHz to the subscribers, timestamp being its position in the track
+func (s *Stream) Write(pcm []int16, timestamp time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for sub := range s.subscribers {
+		sub.push(pcm, timestamp)
+	}
+}
+
+func (s *Stream) NumSubscribers() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.subscribers)
+}
+
+func (s *Stream) Close() {
+	s.lock.Lock()
+	subscribers := s.subscribers
+	s.subscribers = make(map[*Subscriber]struct{})
+	s.closed = true
+	s.lock.Unlock()
+
+	for sub := range subscribers {
+		sub.end()
+	}
+}
+
+func (s *Stream) remove(sub *Subscriber) {
+	s.lock.Lock()
+	_, ok := s.subscribers[sub]
+	delete(s.subscribers, sub)
+	empty := ok && len(s.subscribers) == 0 && !s.closed
+	s.lock.Unlock()
+
+	if empty && s.onEmpty != nil {
+		s.onEmpty()
+	}
+}
+
+// --------------------------------------
+
+// Subscriber is a consumer of the audio of a track, reading it from a ring buffer of its own
+type Subscriber struct {
+	stream     *Stream
+	sampleRate int
+	resampler  *audio.Resampler
+	// position in the track and receive time of the first frame
+	origin        time.Duration
+	captureOrigin time.Time
+	received      bool
+
+	// ASR subscribers cut the audio into frames of chunkSize samples, position being the sample
+	// following the pending ones on the timeline of the subscriber
+	chunkSize int
+	pending   []int16
+	position  int64
+
+	lock   sync.Mutex
+	frames []Frame
+	head   int
+	size   int
+	ended  bool
+	notify chan struct{}
+}
+
+func (s *Subscriber) SampleRate() int {
+	return s.sampleRate
+}
+
+// Next returns the next frame, waiting for it, and io.EOF once the stream closed and the buffer is drained
+func (s *Subscriber) Next(ctx context.Context) (Frame, error) {
+	for {
+		s.lock.Lock()
+		if s.size > 0 {
+			f := s.frames[s.head]
+			s.frames[s.head] = Frame{}
+			s.head = (s.head + 1) % len(s.frames)
+			s.size--
+			s.lock.Unlock()
+			return f, nil
+		}
+		ended := s.ended
+		s.lock.Unlock()
+
+		if ended {
+			return Frame{}, io.EOF
+		}
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return Frame{}, ctx.Err()
+		}
+	}
+}
+
+// Close unsubscribes from the stream
+func (s *Subscriber) Close() {
+	s.end()
+	s.stream.remove(s)
+}
+
+// push resamples and buffers the audio of a packet, dropping the oldest frame when the buffer is full.
+// Called with the lock of the stream held.
+func (s *Subscriber) push(pcm []int16, timestamp time.Duration) {
+	if !s.received {
+		s.origin, s.captureOrigin, s.received = timestamp, time.Now(), true
+	}
+	if s.chunkSize != 0 {
+		s.pushChunks(s.resampler.Resample(pcm, make([]int16, 0, s.resampler.OutputSize(len(pcm)))), timestamp-s.origin)
+		return
+	}
+
+	f := Frame{
+		SampleRate:  s.sampleRate,
+		Timestamp:   timestamp - s.origin,
+		CaptureTime: s.captureOrigin.Add(timestamp - s.origin),
+	}
+	if s.resampler != nil {
+		f.PCM = s.resampler.Resample(pcm, make([]int16, 0, s.resampler.OutputSize(len(pcm))))
+	} else {
+		f.PCM = append([]int16(nil), pcm...)
+	}
+	s.buffer(f)
+}
+
+// pushChunks places the audio of an ASR subscriber at its position on the timeline and buffers the chunks
+// completed by it. Audio overlapping what was delivered already is cut, gaps are filled with silence.
+func (s *Subscriber) pushChunks(pcm []int16, position time.Duration) {
+	start := int64(position) * int64(s.sampleRate) / int64(time.Second)
+	switch gap := start - s.position; {
+	case gap > int64(maxASRGap)*int64(s.sampleRate)/int64(time.Second):
+		// complete the pending chunk with silence and skip the rest of the gap
+		if len(s.pending) > 0 {
+			s.position += int64(s.chunkSize - len(s.pending))
+			s.pending = append(s.pending, make([]int16, s.chunkSize-len(s.pending))...)
+			s.flushChunks()
+		}
+		s.position = start
+	case gap > 0:
+		pcm = append(make([]int16, int(gap), int(gap)+len(pcm)), pcm...)
+	case gap < 0:
+		pcm = pcm[min(int(-gap), len(pcm)):]
+	}
+
+	for len(pcm) > 0 {
+		n := min(len(pcm), s.chunkSize-len(s.pending))
+		s.pending = append(s.pending, pcm[:n]...)
+		s.position += int64(n)
+		pcm = pcm[n:]
+		s.flushChunks()
+	}
+}
+
+// flushChunks buffers the pending chunk once it is complete
+func (s *Subscriber) flushChunks() {
+	if len(s.pending) < s.chunkSize {
+		return
+	}
+	timestamp := time.Duration((s.position - int64(len(s.pending))) * int64(time.Second) / int64(s.sampleRate))
+	s.buffer(Frame{
+		PCM:         s.pending,
+		SampleRate:  s.sampleRate,
+		Timestamp:   timestamp,
+		CaptureTime: s.captureOrigin.Add(timestamp),
+	})
+	s.pending = make([]int16, 0, s.chunkSize)
+}
+
+// buffer adds a frame to the ring buffer, dropping the oldest one when it is full
+func (s *Subscriber) buffer(f Frame) {
+	s.lock.Lock()
+	if s.ended {
+		s.lock.Unlock()
+		return
+	}
+	if s.size == len(s.frames) {
+		// the gap is reported with the frame following the dropped one
+		gap := s.frames[s.head].Dropped + 1
+		s.frames[s.head] = Frame{}
+		s.head = (s.head + 1) % len(s.frames)
+		if s.size--; s.size > 0 {
+			s.frames[s.head].Dropped += gap
+		} else {
+			f.Dropped += gap
+		}
+	}
+	s.frames[(s.head+s.size)%len(s.frames)] = f
+	s.size++
+	s.lock.Unlock()
+
+	s.wake()
+}
+
+func (s *Subscriber) end() {
+	s.lock.Lock()
+	s.ended = true
+	s.lock.Unlock()
+
+	s.wake()
+}
+
+func (s *Subscriber) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
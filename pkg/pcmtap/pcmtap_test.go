@@ -0,0 +1,194 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pcmtap
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+// packet returns 20 ms of PCM at 48 kHz filled with value
+func packet(value int16) []int16 {
+	pcm := make([]int16, SampleRate48k/50)
+	for i := range pcm {
+		pcm[i] = value
+	}
+	return pcm
+}
+
+func TestStream(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("subscribers receive resampled audio", func(t *testing.T) {
+		s := NewStream(DefaultConfig, nil)
+		_, err := s.Subscribe(8000)
+		require.ErrorIs(t, err, ErrUnsupportedSampleRate)
+
+		wide, err := s.Subscribe(SampleRate48k)
+		require.NoError(t, err)
+		narrow, err := s.Subscribe(SampleRate16k)
+		require.NoError(t, err)
+
+		for i := 0; i < 5; i++ {
+			s.Write(packet(1000), time.Duration(i)*frameDuration)
+		}
+
+		total := 0
+		for i := 0; i < 5; i++ {
+			f, err := wide.Next(ctx)
+			require.NoError(t, err)
+			require.Equal(t, SampleRate48k, f.SampleRate)
+			require.Equal(t, time.Duration(i)*frameDuration, f.Timestamp)
+			require.Equal(t, packet(1000), f.PCM)
+
+			f, err = narrow.Next(ctx)
+			require.NoError(t, err)
+			require.Equal(t, SampleRate16k, f.SampleRate)
+			total += len(f.PCM)
+		}
+		// 100 ms at 16 kHz
+		require.InDelta(t, 1600, total, 1)
+	})
+
+	t.Run("slow subscriber drops the oldest audio", func(t *testing.T) {
+		s := NewStream(Config{BufferDuration: 3 * frameDuration}, nil)
+		sub, err := s.Subscribe(SampleRate48k)
+		require.NoError(t, err)
+
+		for i := int16(0); i < 5; i++ {
+			s.Write(packet(i), time.Duration(i)*frameDuration)
+		}
+
+		f, err := sub.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, int16(2), f.PCM[0])
+		require.Equal(t, 2, f.Dropped)
+		for i := int16(3); i < 5; i++ {
+			f, err = sub.Next(ctx)
+			require.NoError(t, err)
+			require.Equal(t, i, f.PCM[0])
+			require.Zero(t, f.Dropped)
+		}
+
+		waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err = sub.Next(waitCtx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("closing drains subscribers", func(t *testing.T) {
+		emptied := false
+		s := NewStream(Config{MaxSubscribers: 1}, func() { emptied = true })
+		sub, err := s.Subscribe(SampleRate48k)
+		require.NoError(t, err)
+		_, err = s.Subscribe(SampleRate48k)
+		require.ErrorIs(t, err, ErrTooManySubscribers)
+
+		s.Write(packet(1), 0)
+		s.Close()
+		_, err = sub.Next(ctx)
+		require.NoError(t, err)
+		_, err = sub.Next(ctx)
+		require.ErrorIs(t, err, io.EOF)
+
+		_, err = s.Subscribe(SampleRate48k)
+		require.ErrorIs(t, err, ErrStreamClosed)
+		sub.Close()
+		require.False(t, emptied)
+	})
+
+	t.Run("asr subscribers receive 20 ms chunks on a continuous timeline", func(t *testing.T) {
+		s := NewStream(DefaultConfig, nil)
+		_, err := s.SubscribeProfile(ProfileASR, SampleRate48k)
+		require.ErrorIs(t, err, ErrUnsupportedSampleRate)
+		sub, err := s.SubscribeProfile(ProfileASR, 0)
+		require.NoError(t, err)
+		require.Equal(t, SampleRate16k, sub.SampleRate())
+
+		// 30 ms packets, then a 40 ms gap, an overlapping packet and a gap too long to fill
+		s.Write(make([]int16, 1440), 0)
+		s.Write(make([]int16, 1440), 30*time.Millisecond)
+		s.Write(make([]int16, 960), 100*time.Millisecond)
+		s.Write(make([]int16, 960), 110*time.Millisecond)
+		s.Write(make([]int16, 960), 5*time.Second)
+
+		var frames []Frame
+		for i := 0; i < 7; i++ {
+			f, err := sub.Next(ctx)
+			require.NoError(t, err)
+			require.Equal(t, SampleRate16k, f.SampleRate)
+			require.Len(t, f.PCM, 320)
+			frames = append(frames, f)
+		}
+		// 130 ms are delivered in full, the chunk started before the long gap is completed with silence
+		for i, f := range frames[:7] {
+			require.Equal(t, time.Duration(i)*frameDuration, f.Timestamp)
+			require.Equal(t, frames[0].CaptureTime.Add(f.Timestamp), f.CaptureTime)
+		}
+
+		f, err := sub.Next(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 5*time.Second, f.Timestamp)
+		require.True(t, f.CaptureTime.After(frames[6].CaptureTime))
+	})
+
+	t.Run("last subscriber leaving empties the stream", func(t *testing.T) {
+		emptied := false
+		s := NewStream(DefaultConfig, func() { emptied = true })
+		a, err := s.Subscribe(SampleRate48k)
+		require.NoError(t, err)
+		b, err := s.Subscribe(SampleRate16k)
+		require.NoError(t, err)
+
+		a.Close()
+		require.False(t, emptied)
+		b.Close()
+		require.True(t, emptied)
+		require.Zero(t, s.NumSubscribers())
+	})
+}
+
+func TestMessages(t *testing.T) {
+	req := &SubscribeRequest{RoomName: "room", TrackSid: "TR_audio", SampleRate: SampleRate16k, Profile: ProfileASR}
+	b, err := proto.Marshal(req)
+	require.NoError(t, err)
+	decodedReq := &SubscribeRequest{}
+	require.NoError(t, proto.Unmarshal(b, decodedReq))
+	require.True(t, proto.Equal(req, decodedReq))
+
+	frame := NewAudioFrame(Frame{
+		PCM:         []int16{1, -1, 32767},
+		SampleRate:  SampleRate48k,
+		Timestamp:   1500 * time.Millisecond,
+		Dropped:     3,
+		CaptureTime: time.UnixMicro(1700000000000000),
+	})
+	require.Equal(t, []byte{1, 0, 0xff, 0xff, 0xff, 0x7f}, frame.Pcm)
+	require.Equal(t, uint32(SampleRate48k), frame.SampleRate)
+	require.Equal(t, uint64(1500000), frame.TimestampUs)
+	require.Equal(t, uint32(3), frame.DroppedFrames)
+	require.Equal(t, uint64(1700000000000000), frame.CaptureTimeUs)
+	b, err = proto.Marshal(frame)
+	require.NoError(t, err)
+	decodedFrame := &AudioFrame{}
+	require.NoError(t, proto.Unmarshal(b, decodedFrame))
+	require.True(t, proto.Equal(frame, decodedFrame))
+}
@@ -0,0 +1,37 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import "time"
+
+type Config struct {
+	// shard rooms across NUMA nodes and run their media work on workers pinned to the node's CPUs
+	Enabled bool `yaml:"enabled,omitempty"`
+	// number of pinned workers per NUMA node, 0 to use one worker per CPU of the node
+	WorkersPerNode int `yaml:"workers_per_node,omitempty"`
+	// depth of the task queues of a node, one per priority, tasks spill over to other nodes when it is full
+	QueueSize int `yaml:"queue_size,omitempty"`
+	// interval to sample kernel NUMA allocation counters, 0 to disable
+	StatsInterval time.Duration `yaml:"stats_interval,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Enabled:       false,
+		QueueSize:     1024,
+		StatsInterval: 10 * time.Second,
+	}
+)
@@ -0,0 +1,227 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+// Slot is the NUMA node a room has been placed on. Media work for the room
+// (decode, denoise, fan-out) should be submitted through it so that it runs
+// on CPUs local to the memory its buffers were allocated from.
+type Slot struct {
+	placer *Placer
+	index  int
+}
+
+// NodeID returns the NUMA node of the slot, -1 for a nil slot
+func (s *Slot) NodeID() int {
+	if s == nil {
+		return -1
+	}
+	return s.placer.pools[s.index].NodeID()
+}
+
+// Submit runs the task on the slot's node, spilling over to other nodes when
+// its queue is full. Tasks run inline when placement is disabled or all queues are full.
+func (s *Slot) Submit(task func()) {
+	s.SubmitWithPriority(PriorityInteractive, task)
+}
+
+// SubmitWithPriority is like Submit, except that recording priority tasks are dropped
+// instead of run inline when all queues are full. Returns false if the task was dropped.
+func (s *Slot) SubmitWithPriority(priority Priority, task func()) bool {
+	if s == nil {
+		task()
+		return true
+	}
+
+	return s.placer.submit(s.index, priority, task)
+}
+
+// --------------------------------------------------------
+
+type Placer struct {
+	config   Config
+	logger   logger.Logger
+	topology *Topology
+	pools    []*WorkerPool
+
+	lock      sync.Mutex
+	rooms     map[livekit.RoomName]int
+	roomCount []int
+
+	stop core.Fuse
+}
+
+// NewPlacer returns nil when placement is disabled, all methods are safe to call on a nil Placer
+func NewPlacer(config Config, logger logger.Logger) *Placer {
+	if !config.Enabled {
+		return nil
+	}
+
+	return newPlacer(config, DetectTopology(), logger)
+}
+
+func newPlacer(config Config, topology *Topology, logger logger.Logger) *Placer {
+	p := &Placer{
+		config:    config,
+		logger:    logger,
+		topology:  topology,
+		rooms:     make(map[livekit.RoomName]int),
+		roomCount: make([]int, len(topology.Nodes)),
+	}
+
+	queueSize := config.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultConfig.QueueSize
+	}
+	for _, node := range topology.Nodes {
+		numWorkers := config.WorkersPerNode
+		if numWorkers <= 0 {
+			numWorkers = len(node.CPUs)
+		}
+		p.pools = append(p.pools, newWorkerPool(node, numWorkers, queueSize, logger))
+	}
+
+	logger.Infow("topology aware placement enabled", "numaNodes", len(topology.Nodes), "numCPUs", topology.NumCPUs())
+
+	if config.StatsInterval > 0 {
+		go p.statsWorker()
+	}
+	return p
+}
+
+func (p *Placer) Stop() {
+	if p == nil {
+		return
+	}
+
+	p.stop.Break()
+	for _, pool := range p.pools {
+		pool.Stop()
+	}
+}
+
+// AssignRoom places the room on the NUMA node hosting the fewest rooms.
+// Assigning an already placed room returns its existing slot.
+func (p *Placer) AssignRoom(roomName livekit.RoomName) *Slot {
+	if p == nil {
+		return nil
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if index, ok := p.rooms[roomName]; ok {
+		return &Slot{placer: p, index: index}
+	}
+
+	index := 0
+	for i, count := range p.roomCount {
+		if count < p.roomCount[index] {
+			index = i
+		}
+	}
+	p.rooms[roomName] = index
+	p.roomCount[index]++
+	prometheus.SetNUMARooms(p.pools[index].NodeID(), p.roomCount[index])
+
+	return &Slot{placer: p, index: index}
+}
+
+func (p *Placer) ReleaseRoom(roomName livekit.RoomName) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	index, ok := p.rooms[roomName]
+	if !ok {
+		return
+	}
+	delete(p.rooms, roomName)
+	p.roomCount[index]--
+	prometheus.SetNUMARooms(p.pools[index].NodeID(), p.roomCount[index])
+}
+
+// Backlog returns the fill of the fullest worker queue across NUMA nodes, 0 for a nil Placer.
+// A growing backlog means the node is short of capacity even if the CPU load does not show it yet.
+func (p *Placer) Backlog() float64 {
+	if p == nil {
+		return 0
+	}
+
+	backlog := 0.0
+	for _, pool := range p.pools {
+		backlog = max(backlog, pool.Backlog())
+	}
+	return backlog
+}
+
+func (p *Placer) submit(index int, priority Priority, task func()) bool {
+	if p.pools[index].TrySubmit(priority, task) {
+		prometheus.AddNUMATask(p.pools[index].NodeID(), false)
+		return true
+	}
+
+	for i := 1; i < len(p.pools); i++ {
+		pool := p.pools[(index+i)%len(p.pools)]
+		if pool.TrySubmit(priority, task) {
+			prometheus.AddNUMATask(pool.NodeID(), true)
+			return true
+		}
+	}
+
+	// every queue is backed up, passive work gives way,
+	// everything else applies back pressure to the caller
+	if priority == PriorityRecording {
+		prometheus.AddNUMATaskDropped(p.pools[index].NodeID(), priority.String())
+		return false
+	}
+	task()
+	return true
+}
+
+func (p *Placer) statsWorker() {
+	ticker := time.NewTicker(p.config.StatsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, node := range p.topology.Nodes {
+				stats, err := readNUMAStats(node.ID)
+				if err != nil {
+					continue
+				}
+				prometheus.RecordNUMAStats(node.ID, stats.Hit, stats.Miss, stats.Foreign, stats.LocalNode, stats.OtherNode)
+			}
+
+		case <-p.stop.Watch():
+			return
+		}
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := ParseCPUList("0-3,8,10-11\n")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cpus)
+
+	cpus, err = ParseCPUList("")
+	require.NoError(t, err)
+	require.Empty(t, cpus)
+
+	_, err = ParseCPUList("3-1")
+	require.Error(t, err)
+
+	_, err = ParseCPUList("a-b")
+	require.Error(t, err)
+}
+
+func TestPlacer(t *testing.T) {
+	prometheus.Init("test", livekit.NodeType_SERVER)
+
+	topology := &Topology{
+		Nodes: []NUMANode{
+			{ID: 0, CPUs: []int{0, 1}},
+			{ID: 1, CPUs: []int{2, 3}},
+		},
+	}
+	p := newPlacer(Config{Enabled: true, WorkersPerNode: 1, QueueSize: 1}, topology, logger.GetLogger())
+	defer p.Stop()
+
+	t.Run("rooms are spread across nodes", func(t *testing.T) {
+		s1 := p.AssignRoom("room1")
+		s2 := p.AssignRoom("room2")
+		require.NotEqual(t, s1.NodeID(), s2.NodeID())
+
+		// assignment is sticky
+		require.Equal(t, s1.NodeID(), p.AssignRoom("room1").NodeID())
+
+		// released capacity is reused
+		p.ReleaseRoom("room1")
+		require.Equal(t, s1.NodeID(), p.AssignRoom("room3").NodeID())
+
+		p.ReleaseRoom("room2")
+		p.ReleaseRoom("room3")
+	})
+
+	t.Run("tasks run", func(t *testing.T) {
+		slot := p.AssignRoom("room1")
+		done := make(chan struct{}, 10)
+		for i := 0; i < 10; i++ {
+			slot.Submit(func() { done <- struct{}{} })
+		}
+		for i := 0; i < 10; i++ {
+			<-done
+		}
+	})
+
+	t.Run("tasks run on the node of the room", func(t *testing.T) {
+		slot := p.AssignRoom("room1")
+		other := p.AssignRoom("room2")
+		require.NotEqual(t, slot.NodeID(), other.NodeID())
+
+		// keep the only worker of the room's node busy, the other node is idle
+		started := make(chan struct{})
+		release := make(chan struct{})
+		slot.Submit(func() {
+			close(started)
+			<-release
+		})
+		<-started
+
+		ran := make(chan struct{})
+		slot.Submit(func() { close(ran) })
+		select {
+		case <-ran:
+			t.Fatal("task did not wait for the worker of its node")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		close(release)
+		<-ran
+		p.ReleaseRoom("room1")
+		p.ReleaseRoom("room2")
+	})
+}
+
+func TestPlacerPriority(t *testing.T) {
+	prometheus.Init("test", livekit.NodeType_SERVER)
+
+	topology := &Topology{
+		Nodes: []NUMANode{{ID: 0, CPUs: []int{0}}},
+	}
+	p := newPlacer(Config{Enabled: true, WorkersPerNode: 1, QueueSize: 1}, topology, logger.GetLogger())
+	defer p.Stop()
+	slot := p.AssignRoom("room")
+
+	// keep the only worker busy
+	started := make(chan struct{})
+	release := make(chan struct{})
+	slot.Submit(func() {
+		close(started)
+		<-release
+	})
+	<-started
+
+	var order []Priority
+	done := make(chan struct{}, 2)
+	record := func(priority Priority) func() {
+		return func() {
+			order = append(order, priority)
+			done <- struct{}{}
+		}
+	}
+	require.True(t, slot.SubmitWithPriority(PriorityRecording, record(PriorityRecording)))
+	require.True(t, slot.SubmitWithPriority(PriorityAgentInput, record(PriorityAgentInput)))
+
+	// full queues drop recording work and run everything else inline
+	require.False(t, slot.SubmitWithPriority(PriorityRecording, func() { t.Fatal("dropped task ran") }))
+	ranInline := false
+	require.True(t, slot.SubmitWithPriority(PriorityAgentInput, func() { ranInline = true }))
+	require.True(t, ranInline)
+
+	close(release)
+	<-done
+	<-done
+	require.Equal(t, []Priority{PriorityAgentInput, PriorityRecording}, order)
+}
+
+func TestSerialQueue(t *testing.T) {
+	prometheus.Init("test", livekit.NodeType_SERVER)
+
+	topology := &Topology{
+		Nodes: []NUMANode{{ID: 0, CPUs: []int{0, 1}}},
+	}
+	p := newPlacer(Config{Enabled: true, WorkersPerNode: 2, QueueSize: 16}, topology, logger.GetLogger())
+	defer p.Stop()
+
+	q := NewSerialQueue(p.AssignRoom("room"), func() Priority { return PriorityInteractive }, 100)
+	var order []int
+	done := make(chan struct{})
+	for i := 0; i < 100; i++ {
+		require.True(t, q.Submit(func() {
+			order = append(order, i)
+			if i == 99 {
+				close(done)
+			}
+		}))
+	}
+	<-done
+	for i, v := range order {
+		require.Equal(t, i, v)
+	}
+
+	// runs inline without placement
+	ran := false
+	require.True(t, NewSerialQueue(nil, nil, 1).Submit(func() { ran = true }))
+	require.True(t, ran)
+}
+
+func TestParsePriority(t *testing.T) {
+	for priority := PriorityRecording; priority < numPriorities; priority++ {
+		parsed, err := ParsePriority(priority.String())
+		require.NoError(t, err)
+		require.Equal(t, priority, parsed)
+	}
+
+	_, err := ParsePriority("urgent")
+	require.Error(t, err)
+}
+
+func TestNilPlacer(t *testing.T) {
+	p := NewPlacer(Config{}, logger.GetLogger())
+	require.Nil(t, p)
+
+	slot := p.AssignRoom("room")
+	require.Nil(t, slot)
+	require.Equal(t, -1, slot.NodeID())
+
+	ran := false
+	slot.Submit(func() { ran = true })
+	require.True(t, ran)
+
+	p.ReleaseRoom("room")
+	p.Stop()
+}
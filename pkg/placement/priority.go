@@ -0,0 +1,132 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Priority orders media work when workers fall behind, higher priorities run first
+// and the lowest priority is dropped rather than holding up the caller.
+type Priority int
+
+const (
+	// passive taps such as recordings and exports, dropped first under load
+	PriorityRecording Priority = iota
+	// default for the media of a track
+	PriorityInteractive
+	// audio an agent is listening to, e. g. for transcription, never dropped
+	PriorityAgentInput
+
+	numPriorities
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityRecording:
+		return "recording"
+	case PriorityInteractive:
+		return "interactive"
+	case PriorityAgentInput:
+		return "agent-input"
+	default:
+		return fmt.Sprintf("%d", int(p))
+	}
+}
+
+// ParsePriority parses a priority label as returned by Priority.String
+func ParsePriority(label string) (Priority, error) {
+	for p := PriorityRecording; p < numPriorities; p++ {
+		if p.String() == label {
+			return p, nil
+		}
+	}
+	return PriorityInteractive, fmt.Errorf("invalid priority %q", label)
+}
+
+// --------------------------------------------------------
+
+// SerialQueue runs tasks one at a time in submission order on the workers of a slot,
+// for work that has to stay ordered like decoding the packets of one track.
+// Tasks run inline when the slot is nil.
+type SerialQueue struct {
+	slot      *Slot
+	priority  func() Priority
+	maxQueued int
+
+	lock      sync.Mutex
+	tasks     []func()
+	scheduled bool
+}
+
+// NewSerialQueue returns a queue scheduling its tasks at the priority returned by the given function,
+// which is evaluated every time the queue is scheduled so that priority changes take effect
+func NewSerialQueue(slot *Slot, priority func() Priority, maxQueued int) *SerialQueue {
+	return &SerialQueue{
+		slot:      slot,
+		priority:  priority,
+		maxQueued: max(1, maxQueued),
+	}
+}
+
+// Submit queues the task, returns false if it was dropped because the queue is full
+// or, at recording priority, all workers are backed up
+func (q *SerialQueue) Submit(task func()) bool {
+	if q.slot == nil {
+		task()
+		return true
+	}
+
+	q.lock.Lock()
+	if len(q.tasks) >= q.maxQueued {
+		q.lock.Unlock()
+		return false
+	}
+	q.tasks = append(q.tasks, task)
+	if q.scheduled {
+		q.lock.Unlock()
+		return true
+	}
+	q.scheduled = true
+	q.lock.Unlock()
+
+	if !q.slot.SubmitWithPriority(q.priority(), q.drain) {
+		q.lock.Lock()
+		q.tasks = nil
+		q.scheduled = false
+		q.lock.Unlock()
+		return false
+	}
+	return true
+}
+
+func (q *SerialQueue) drain() {
+	for {
+		q.lock.Lock()
+		if len(q.tasks) == 0 {
+			q.scheduled = false
+			q.lock.Unlock()
+			return
+		}
+		task := q.tasks[0]
+		q.tasks[0] = nil
+		q.tasks = q.tasks[1:]
+		q.lock.Unlock()
+
+		task()
+	}
+}
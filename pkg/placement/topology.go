@@ -0,0 +1,102 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// NUMANode is a memory locality domain and the CPUs attached to it
+type NUMANode struct {
+	ID   int
+	CPUs []int
+}
+
+// NUMAStats holds the kernel page allocation counters of a NUMA node.
+// OtherNode counts pages allocated on this node by a process running on another node,
+// i. e. cross-node memory traffic.
+type NUMAStats struct {
+	Hit       uint64
+	Miss      uint64
+	Foreign   uint64
+	LocalNode uint64
+	OtherNode uint64
+}
+
+type Topology struct {
+	Nodes []NUMANode
+}
+
+// DetectTopology returns the NUMA layout of the host. When the layout cannot be
+// determined, all CPUs are reported as a single node.
+func DetectTopology() *Topology {
+	if t, err := detectTopology(); err == nil && len(t.Nodes) != 0 {
+		return t
+	}
+
+	return singleNodeTopology()
+}
+
+func singleNodeTopology() *Topology {
+	cpus := make([]int, runtime.NumCPU())
+	for i := range cpus {
+		cpus[i] = i
+	}
+	return &Topology{
+		Nodes: []NUMANode{{ID: 0, CPUs: cpus}},
+	}
+}
+
+func (t *Topology) NumCPUs() int {
+	num := 0
+	for _, n := range t.Nodes {
+		num += len(n.CPUs)
+	}
+	return num
+}
+
+// ParseCPUList parses the kernel cpulist format, e. g. "0-3,8,10-11"
+func ParseCPUList(list string) ([]int, error) {
+	list = strings.TrimSpace(list)
+	if list == "" {
+		return nil, nil
+	}
+
+	var cpus []int
+	for _, r := range strings.Split(list, ",") {
+		lo, hi, isRange := strings.Cut(r, "-")
+		start, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpu list %q: %w", list, err)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(hi); err != nil {
+				return nil, fmt.Errorf("invalid cpu list %q: %w", list, err)
+			}
+		}
+		if end < start {
+			return nil, fmt.Errorf("invalid cpu list %q: descending range", list)
+		}
+		for cpu := start; cpu <= end; cpu++ {
+			cpus = append(cpus, cpu)
+		}
+	}
+	return cpus, nil
+}
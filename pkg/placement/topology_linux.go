@@ -0,0 +1,112 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package placement
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+const sysfsNodePath = "/sys/devices/system/node"
+
+func detectTopology() (*Topology, error) {
+	entries, err := os.ReadDir(sysfsNodePath)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Topology{}
+	for _, entry := range entries {
+		id, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), "node"))
+		if err != nil || !strings.HasPrefix(entry.Name(), "node") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(sysfsNodePath, entry.Name(), "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+		cpus, err := ParseCPUList(string(data))
+		if err != nil {
+			return nil, err
+		}
+		if len(cpus) == 0 {
+			// memory only node, nothing can be scheduled on it
+			continue
+		}
+		t.Nodes = append(t.Nodes, NUMANode{ID: id, CPUs: cpus})
+	}
+
+	sort.Slice(t.Nodes, func(i, j int) bool {
+		return t.Nodes[i].ID < t.Nodes[j].ID
+	})
+	return t, nil
+}
+
+func readNUMAStats(nodeID int) (NUMAStats, error) {
+	var stats NUMAStats
+
+	f, err := os.Open(filepath.Join(sysfsNodePath, "node"+strconv.Itoa(nodeID), "numastat"))
+	if err != nil {
+		return stats, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		name, value, ok := strings.Cut(scanner.Text(), " ")
+		if !ok {
+			continue
+		}
+		v, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch name {
+		case "numa_hit":
+			stats.Hit = v
+		case "numa_miss":
+			stats.Miss = v
+		case "numa_foreign":
+			stats.Foreign = v
+		case "local_node":
+			stats.LocalNode = v
+		case "other_node":
+			stats.OtherNode = v
+		}
+	}
+	return stats, scanner.Err()
+}
+
+// setThreadAffinity restricts the calling OS thread to the given CPUs,
+// callers are expected to have locked the goroutine to its thread.
+func setThreadAffinity(cpus []int) error {
+	var set unix.CPUSet
+	set.Zero()
+	for _, cpu := range cpus {
+		set.Set(cpu)
+	}
+	return unix.SchedSetaffinity(0, &set)
+}
@@ -0,0 +1,35 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package placement
+
+import "errors"
+
+var errTopologyUnsupported = errors.New("cpu topology detection is not supported on this platform")
+
+func detectTopology() (*Topology, error) {
+	return nil, errTopologyUnsupported
+}
+
+func readNUMAStats(_ int) (NUMAStats, error) {
+	return NUMAStats{}, errTopologyUnsupported
+}
+
+func setThreadAffinity(_ []int) error {
+	return errTopologyUnsupported
+}
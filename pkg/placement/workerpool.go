@@ -0,0 +1,130 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package placement
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+)
+
+// WorkerPool runs tasks on OS threads pinned to the CPUs of a single NUMA node.
+// Each priority has its own queue, workers always take the highest priority task first.
+type WorkerPool struct {
+	node   NUMANode
+	tasks  [numPriorities]chan func()
+	logger logger.Logger
+
+	wg   sync.WaitGroup
+	stop core.Fuse
+}
+
+func newWorkerPool(node NUMANode, numWorkers int, queueSize int, logger logger.Logger) *WorkerPool {
+	w := &WorkerPool{
+		node:   node,
+		logger: logger.WithValues("numaNode", node.ID),
+	}
+	for i := range w.tasks {
+		w.tasks[i] = make(chan func(), queueSize)
+	}
+
+	w.wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go w.worker()
+	}
+	return w
+}
+
+func (w *WorkerPool) NodeID() int {
+	return w.node.ID
+}
+
+// TrySubmit queues the task without blocking, returns false if the queue of the priority is full or the pool is stopped
+func (w *WorkerPool) TrySubmit(priority Priority, task func()) bool {
+	if w.stop.IsBroken() {
+		return false
+	}
+
+	select {
+	case w.tasks[priority] <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// Backlog returns the fill of the fullest priority queue, 0 when empty and 1 when full
+func (w *WorkerPool) Backlog() float64 {
+	backlog := 0.0
+	for _, tasks := range w.tasks {
+		if cap(tasks) > 0 {
+			backlog = max(backlog, float64(len(tasks))/float64(cap(tasks)))
+		}
+	}
+	return backlog
+}
+
+func (w *WorkerPool) Stop() {
+	w.stop.Break()
+	w.wg.Wait()
+}
+
+func (w *WorkerPool) worker() {
+	defer w.wg.Done()
+
+	// the goroutine exits without unlocking, so the runtime discards the pinned
+	// thread instead of handing it back to the scheduler with a narrowed affinity
+	runtime.LockOSThread()
+	if err := setThreadAffinity(w.node.CPUs); err != nil {
+		w.logger.Debugw("could not pin worker thread", "error", err)
+	}
+
+	for {
+		if task := w.next(); task != nil {
+			task()
+			continue
+		}
+
+		select {
+		case task := <-w.tasks[PriorityAgentInput]:
+			task()
+
+		case task := <-w.tasks[PriorityInteractive]:
+			task()
+
+		case task := <-w.tasks[PriorityRecording]:
+			task()
+
+		case <-w.stop.Watch():
+			return
+		}
+	}
+}
+
+// next returns the queued task of the highest priority, nil when all queues are empty
+func (w *WorkerPool) next() func() {
+	for priority := numPriorities - 1; priority >= 0; priority-- {
+		select {
+		case task := <-w.tasks[priority]:
+			return task
+		default:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// ports reported per address when binding fails
+	maxReportedPorts = 10
+)
+
+// CodecCheck runs an Opus encode and decode loop over frames frames of a tone, reporting the time per frame,
+// and round trips every G.711 code
+func CodecCheck(frames int) Check {
+	return Check{
+		Name: CheckCodec,
+		Run: func(ctx context.Context) (Details, error) {
+			if err := checkG711(); err != nil {
+				return nil, err
+			}
+			if err := audio.OpusSelfTest(); err != nil {
+				return nil, fmt.Errorf("opus: %w", err)
+			}
+
+			encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+			if err != nil {
+				return nil, fmt.Errorf("opus: %w", err)
+			}
+			decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+			if err != nil {
+				return nil, fmt.Errorf("opus: %w", err)
+			}
+
+			pcm := make([]int16, audio.OpusFrameSize)
+			out := make([]int16, audio.OpusMaxFrameSize)
+			payload := make([]byte, audio.OpusMaxPacketSize)
+			var encodeTime, decodeTime time.Duration
+			for frame := 0; frame < frames; frame++ {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+				for i := range pcm {
+					t := float64(frame*audio.OpusFrameSize+i) / audio.OpusSampleRate
+					pcm[i] = int16(8000 * math.Sin(2*math.Pi*440*t))
+				}
+
+				start := time.Now()
+				n, err := encoder.Encode(pcm, payload)
+				encodeTime += time.Since(start)
+				if err != nil {
+					return nil, fmt.Errorf("opus: frame %d: encode: %w", frame, err)
+				}
+
+				start = time.Now()
+				_, err = decoder.Decode(payload[:n], out)
+				decodeTime += time.Since(start)
+				if err != nil {
+					return nil, fmt.Errorf("opus: frame %d: decode: %w", frame, err)
+				}
+			}
+
+			details := Details{
+				"opus_version": audio.OpusVersion(),
+				"frames":       frames,
+			}
+			if frames > 0 {
+				details["encode_us_per_frame"] = encodeTime.Microseconds() / int64(frames)
+				details["decode_us_per_frame"] = decodeTime.Microseconds() / int64(frames)
+			}
+			return details, nil
+		},
+	}
+}
+
+// checkG711 expands and compresses every code, which has to give back the expanded sample
+func checkG711() error {
+	for code := 0; code < 256; code++ {
+		b := byte(code)
+		if sample := audio.DecodeMuLaw(b); audio.DecodeMuLaw(audio.EncodeMuLaw(sample)) != sample {
+			return fmt.Errorf("g711 mu-law: code %#x does not round trip", b)
+		}
+		if sample := audio.DecodeALaw(b); audio.DecodeALaw(audio.EncodeALaw(sample)) != sample {
+			return fmt.Errorf("g711 a-law: code %#x does not round trip", b)
+		}
+	}
+	return nil
+}
+
+// BenchmarkCheck measures how many times faster than real time a single core processes audio. setup returns
+// the function processing a frame of frameDuration, which is called until duration of audio is processed.
+// Fails when the speed is below minRealtimeFactor.
+func BenchmarkCheck(
+	name string,
+	frameDuration time.Duration,
+	duration time.Duration,
+	minRealtimeFactor float64,
+	setup func() (func() error, error),
+) Check {
+	return Check{
+		Name: name,
+		Run: func(ctx context.Context) (Details, error) {
+			process, err := setup()
+			if err != nil {
+				return nil, err
+			}
+
+			frames := max(int(duration/frameDuration), 1)
+			start := time.Now()
+			for frame := 0; frame < frames; frame++ {
+				if frame%100 == 0 {
+					if err := ctx.Err(); err != nil {
+						return nil, err
+					}
+				}
+				if err := process(); err != nil {
+					return nil, fmt.Errorf("frame %d: %w", frame, err)
+				}
+			}
+			elapsed := max(time.Since(start), time.Microsecond)
+
+			factor := float64(time.Duration(frames)*frameDuration) / float64(elapsed)
+			details := Details{
+				"frames":              frames,
+				"us_per_frame":        elapsed.Microseconds() / int64(frames),
+				"realtime_factor":     math.Round(factor*10) / 10,
+				"min_realtime_factor": minRealtimeFactor,
+			}
+			if factor < minRealtimeFactor {
+				return details, fmt.Errorf("processed %.1f times real time, expected at least %.1f", factor, minRealtimeFactor)
+			}
+			return details, nil
+		},
+	}
+}
+
+// UDPPortsCheck binds each of the ports on each of the addresses, an empty address binds all interfaces.
+// Fails with the ports that are in use or not permitted.
+func UDPPortsCheck(addrs []string, ports []int) Check {
+	if len(addrs) == 0 {
+		addrs = []string{""}
+	}
+
+	return Check{
+		Name: CheckUDP,
+		Run: func(ctx context.Context) (Details, error) {
+			var errs []error
+			for _, addr := range addrs {
+				var failed []int
+				var firstErr error
+				for i, port := range ports {
+					if i%100 == 0 {
+						if err := ctx.Err(); err != nil {
+							return nil, err
+						}
+					}
+					conn, err := net.ListenPacket("udp", net.JoinHostPort(addr, strconv.Itoa(port)))
+					if err != nil {
+						failed = append(failed, port)
+						if firstErr == nil {
+							firstErr = err
+						}
+						continue
+					}
+					_ = conn.Close()
+				}
+				if len(failed) != 0 {
+					errs = append(errs, fmt.Errorf("%d of %d ports unavailable on %q, e. g. %v: %w",
+						len(failed), len(ports), addr, failed[:min(len(failed), maxReportedPorts)], firstErr))
+				}
+			}
+
+			details := Details{
+				"addresses": addrs,
+				"ports":     len(ports),
+			}
+			return details, errors.Join(errs...)
+		},
+	}
+}
+
+// StorageCheck writes a file of probeSize bytes to each directory, keyed by what it is used for, reads it back
+// and removes it. Missing directories are created, as the features writing to them do.
+func StorageCheck(dirs map[string]string, probeSize int) Check {
+	return Check{
+		Name: CheckStorage,
+		Run: func(ctx context.Context) (Details, error) {
+			probe := make([]byte, max(probeSize, 1))
+			_, _ = rand.New(rand.NewSource(time.Now().UnixNano())).Read(probe)
+
+			details := Details{}
+			var errs []error
+			for _, use := range sortedKeys(dirs) {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+
+				elapsed, err := probeDir(dirs[use], probe)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s %s: %w", use, dirs[use], err))
+					continue
+				}
+				details[use] = Details{
+					"dir":         dirs[use],
+					"write_ms":    elapsed.Milliseconds(),
+					"write_mib_s": math.Round(float64(len(probe))/(1<<20)/max(elapsed.Seconds(), 1e-6)*10) / 10,
+				}
+			}
+			return details, errors.Join(errs...)
+		},
+	}
+}
+
+// probeDir writes probe to a file in dir and syncs it, returning the time that took, and reads it back
+func probeDir(dir string, probe []byte) (time.Duration, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	f, err := os.CreateTemp(dir, ".preflight-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err = f.Write(probe); err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+	elapsed := time.Since(start)
+
+	read, err := os.ReadFile(f.Name())
+	if err != nil {
+		return 0, err
+	}
+	if !bytes.Equal(read, probe) {
+		return 0, errors.New("file read back differs from the one written")
+	}
+	if err := os.Remove(f.Name()); err != nil {
+		return 0, err
+	}
+	return elapsed, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
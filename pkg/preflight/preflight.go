@@ -0,0 +1,206 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package preflight runs the startup self-test suite of a node: codecs, denoising speed, ports, TURN,
+// Redis and storage are checked before the node joins the cluster, and the outcome is reported in a
+// machine readable form for bootstrap automation.
+package preflight
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	CheckCodec   = "codec"
+	CheckDenoise = "denoise"
+	CheckUDP     = "udp_ports"
+	CheckTURN    = "turn"
+	CheckRedis   = "redis"
+	CheckStorage = "storage"
+)
+
+// Checks are the names of all checks, in the order they run
+var Checks = []string{CheckCodec, CheckDenoise, CheckUDP, CheckTURN, CheckRedis, CheckStorage}
+
+type Config struct {
+	// checks to run, all of them when empty
+	Checks []string `yaml:"checks,omitempty"`
+	// time each check may take
+	Timeout time.Duration `yaml:"timeout,omitempty"`
+	// frames encoded and decoded by the codec check
+	CodecFrames int `yaml:"codec_frames,omitempty"`
+	// audio denoised by the denoise benchmark
+	BenchmarkDuration time.Duration `yaml:"benchmark_duration,omitempty"`
+	// audio a single core has to denoise per second of processing, e. g. 50 is 50 times real time,
+	// a node below cannot carry its share of streams
+	MinRealtimeFactor float64 `yaml:"min_realtime_factor,omitempty"`
+	// size of the file written to each storage directory
+	StorageProbeSize int `yaml:"storage_probe_size,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Timeout:           10 * time.Second,
+		CodecFrames:       500,
+		BenchmarkDuration: 10 * time.Second,
+		MinRealtimeFactor: 20,
+		StorageProbeSize:  1 << 20,
+	}
+)
+
+func (c Config) Validate() error {
+	for _, name := range c.Checks {
+		if !slices.Contains(Checks, name) {
+			return fmt.Errorf("preflight: unknown check %q, expected one of %v", name, Checks)
+		}
+	}
+	return nil
+}
+
+// Enabled returns whether the check runs
+func (c Config) Enabled(name string) bool {
+	return len(c.Checks) == 0 || slices.Contains(c.Checks, name)
+}
+
+// --------------------------------------
+
+// Details are the measurements of a check, e. g. timings, included in the report
+type Details map[string]any
+
+// Check is a single self-test
+type Check struct {
+	Name string
+	// Skip is the reason the check does not apply to the configuration, e. g. Redis is not configured,
+	// empty to run it
+	Skip string
+	Run  func(ctx context.Context) (Details, error)
+}
+
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+type Result struct {
+	Name     string        `json:"name"`
+	Status   Status        `json:"status"`
+	Duration time.Duration `json:"duration_ns"`
+	Details  Details       `json:"details,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	// why the check was skipped
+	Reason string `json:"reason,omitempty"`
+}
+
+// Report is the outcome of the suite, Passed is false if any check failed
+type Report struct {
+	Version   string        `json:"version,omitempty"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration_ns"`
+	Passed    bool          `json:"passed"`
+	Results   []Result      `json:"results"`
+}
+
+// Failed returns the names of the failed checks
+func (r Report) Failed() []string {
+	var failed []string
+	for _, result := range r.Results {
+		if result.Status == StatusFail {
+			failed = append(failed, result.Name)
+		}
+	}
+	return failed
+}
+
+// Run runs the enabled checks one after another, each bounded by the timeout of the config
+func Run(ctx context.Context, conf Config, checks []Check) Report {
+	if conf.Timeout <= 0 {
+		conf.Timeout = DefaultConfig.Timeout
+	}
+
+	report := Report{
+		StartedAt: time.Now(),
+		Passed:    true,
+	}
+	for _, check := range checks {
+		if !conf.Enabled(check.Name) {
+			continue
+		}
+
+		result := runCheck(ctx, check, conf.Timeout)
+		if result.Status == StatusFail {
+			report.Passed = false
+		}
+		report.Results = append(report.Results, result)
+	}
+	report.Duration = time.Since(report.StartedAt)
+	return report
+}
+
+func runCheck(ctx context.Context, check Check, timeout time.Duration) Result {
+	result := Result{Name: check.Name}
+	if check.Skip != "" {
+		result.Status = StatusSkip
+		result.Reason = check.Skip
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type outcome struct {
+		details Details
+		err     error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		details, err := runSafely(ctx, check.Run)
+		done <- outcome{details, err}
+	}()
+
+	var err error
+	select {
+	case o := <-done:
+		result.Details, err = o.details, o.err
+	case <-ctx.Done():
+		// a check stuck in a call that does not take the context is abandoned
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	} else {
+		result.Status = StatusPass
+	}
+	return result
+}
+
+// a misbehaving check, e. g. a native library, fails rather than taking the process down
+func runSafely(ctx context.Context, run func(ctx context.Context) (Details, error)) (details Details, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return run(ctx)
+}
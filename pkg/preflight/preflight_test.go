@@ -0,0 +1,144 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package preflight
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	pass := func(ctx context.Context) (Details, error) { return Details{"ok": true}, nil }
+
+	t.Run("report collects the outcome of every check", func(t *testing.T) {
+		report := Run(context.Background(), DefaultConfig, []Check{
+			{Name: CheckCodec, Run: pass},
+			{Name: CheckRedis, Skip: "redis is not configured"},
+			{Name: CheckStorage, Run: func(ctx context.Context) (Details, error) {
+				return nil, errors.New("read-only file system")
+			}},
+			{Name: CheckTURN, Run: func(ctx context.Context) (Details, error) {
+				panic("boom")
+			}},
+		})
+
+		require.False(t, report.Passed)
+		require.Equal(t, []string{CheckStorage, CheckTURN}, report.Failed())
+		require.Len(t, report.Results, 4)
+		require.Equal(t, StatusPass, report.Results[0].Status)
+		require.Equal(t, Details{"ok": true}, report.Results[0].Details)
+		require.Equal(t, StatusSkip, report.Results[1].Status)
+		require.Equal(t, "redis is not configured", report.Results[1].Reason)
+		require.Equal(t, "read-only file system", report.Results[2].Error)
+		require.Equal(t, "panic: boom", report.Results[3].Error)
+	})
+
+	t.Run("only configured checks run", func(t *testing.T) {
+		conf := DefaultConfig
+		conf.Checks = []string{CheckUDP}
+		require.NoError(t, conf.Validate())
+
+		report := Run(context.Background(), conf, []Check{
+			{Name: CheckCodec, Run: pass},
+			{Name: CheckUDP, Run: pass},
+		})
+		require.True(t, report.Passed)
+		require.Len(t, report.Results, 1)
+		require.Equal(t, CheckUDP, report.Results[0].Name)
+
+		conf.Checks = []string{"disk"}
+		require.Error(t, conf.Validate())
+	})
+
+	t.Run("checks are bounded by the timeout", func(t *testing.T) {
+		conf := DefaultConfig
+		conf.Timeout = 20 * time.Millisecond
+
+		report := Run(context.Background(), conf, []Check{
+			{Name: CheckRedis, Run: func(ctx context.Context) (Details, error) {
+				time.Sleep(time.Second)
+				return nil, nil
+			}},
+		})
+		require.False(t, report.Passed)
+		require.Contains(t, report.Results[0].Error, "timed out")
+		require.Less(t, report.Results[0].Duration, time.Second)
+	})
+}
+
+func TestChecks(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("g711 round trips", func(t *testing.T) {
+		require.NoError(t, checkG711())
+	})
+
+	t.Run("ports in use fail", func(t *testing.T) {
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		require.NoError(t, err)
+		port := conn.LocalAddr().(*net.UDPAddr).Port
+
+		check := UDPPortsCheck([]string{"127.0.0.1"}, []int{port})
+		_, err = check.Run(ctx)
+		require.Error(t, err)
+
+		require.NoError(t, conn.Close())
+		details, err := check.Run(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, details["ports"])
+	})
+
+	t.Run("storage is written and cleaned up", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "ml_export")
+		details, err := StorageCheck(map[string]string{"ml_export": dir}, 4096).Run(ctx)
+		require.NoError(t, err)
+		require.Contains(t, details, "ml_export")
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+
+		file := filepath.Join(t.TempDir(), "file")
+		require.NoError(t, os.WriteFile(file, nil, 0644))
+		_, err = StorageCheck(map[string]string{"capture": file}, 4096).Run(ctx)
+		require.Error(t, err)
+	})
+
+	t.Run("benchmark fails below real time", func(t *testing.T) {
+		setup := func(delay time.Duration) func() (func() error, error) {
+			return func() (func() error, error) {
+				return func() error {
+					time.Sleep(delay)
+					return nil
+				}, nil
+			}
+		}
+
+		details, err := BenchmarkCheck(CheckDenoise, 10*time.Millisecond, 100*time.Millisecond, 2, setup(0)).Run(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 10, details["frames"])
+
+		_, err = BenchmarkCheck(CheckDenoise, time.Millisecond, 10*time.Millisecond, 2, setup(2*time.Millisecond)).Run(ctx)
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,136 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	geminiURL          = "wss://generativelanguage.googleapis.com/ws/google.ai.generativelanguage.v1beta.GenerativeService.BidiGenerateContent"
+	geminiDefaultModel = "gemini-2.0-flash-live-001"
+	geminiDefaultVoice = "Puck"
+	geminiSampleRate   = 16000
+)
+
+// geminiProvider speaks the Gemini Live API, with automatic activity detection of the server
+type geminiProvider struct{}
+
+type geminiPart struct {
+	Text       string `json:"text,omitempty"`
+	InlineData *struct {
+		MimeType string `json:"mimeType"`
+		Data     string `json:"data"`
+	} `json:"inlineData,omitempty"`
+}
+
+type geminiServerMessage struct {
+	ServerContent *struct {
+		ModelTurn *struct {
+			Parts []geminiPart `json:"parts"`
+		} `json:"modelTurn,omitempty"`
+		Interrupted  bool `json:"interrupted,omitempty"`
+		TurnComplete bool `json:"turnComplete,omitempty"`
+	} `json:"serverContent,omitempty"`
+}
+
+func (geminiProvider) url(config Config) string {
+	if config.URL != "" {
+		return config.URL
+	}
+	return geminiURL + "?key=" + url.QueryEscape(config.APIKey)
+}
+
+func (geminiProvider) header(config Config) http.Header {
+	return nil
+}
+
+func (geminiProvider) setup(config Config) []any {
+	model := config.Model
+	if model == "" {
+		model = geminiDefaultModel
+	}
+	if !strings.HasPrefix(model, "models/") {
+		model = "models/" + model
+	}
+	voice := config.Voice
+	if voice == "" {
+		voice = geminiDefaultVoice
+	}
+
+	setup := map[string]any{
+		"model": model,
+		"generationConfig": map[string]any{
+			"responseModalities": []string{"AUDIO"},
+			"speechConfig": map[string]any{
+				"voiceConfig": map[string]any{
+					"prebuiltVoiceConfig": map[string]any{"voiceName": voice},
+				},
+			},
+		},
+	}
+	if config.Instructions != "" {
+		setup["systemInstruction"] = map[string]any{
+			"parts": []geminiPart{{Text: config.Instructions}},
+		}
+	}
+	return []any{map[string]any{"setup": setup}}
+}
+
+func (geminiProvider) inputSampleRate() int {
+	return geminiSampleRate
+}
+
+func (geminiProvider) appendAudio(pcm []byte) any {
+	return map[string]any{
+		"realtimeInput": map[string]any{
+			"audio": map[string]any{
+				"mimeType": "audio/pcm;rate=16000",
+				"data":     base64.StdEncoding.EncodeToString(pcm),
+			},
+		},
+	}
+}
+
+func (geminiProvider) parse(data []byte) (Event, error) {
+	msg := geminiServerMessage{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Event{}, err
+	}
+	content := msg.ServerContent
+	if content == nil {
+		return Event{}, nil
+	}
+
+	event := Event{Interrupted: content.Interrupted, ResponseDone: content.TurnComplete}
+	if content.ModelTurn != nil {
+		for _, part := range content.ModelTurn.Parts {
+			if part.InlineData == nil || !strings.HasPrefix(part.InlineData.MimeType, "audio/pcm") {
+				continue
+			}
+			pcm, err := decodePCM(part.InlineData.Data)
+			if err != nil {
+				return Event{}, err
+			}
+			event.Audio = append(event.Audio, pcm...)
+		}
+	}
+	return event, nil
+}
@@ -0,0 +1,112 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+)
+
+const (
+	openAIURL          = "wss://api.openai.com/v1/realtime"
+	openAIDefaultModel = "gpt-4o-realtime-preview"
+	openAIDefaultVoice = "alloy"
+	openAISampleRate   = 24000
+)
+
+// openAIProvider speaks the OpenAI Realtime API, with turn detection of the server
+type openAIProvider struct{}
+
+type openAIMessage struct {
+	Type    string `json:"type"`
+	Delta   string `json:"delta,omitempty"`
+	Audio   string `json:"audio,omitempty"`
+	Session any    `json:"session,omitempty"`
+	Error   *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (openAIProvider) url(config Config) string {
+	if config.URL != "" {
+		return config.URL
+	}
+	model := config.Model
+	if model == "" {
+		model = openAIDefaultModel
+	}
+	return openAIURL + "?model=" + url.QueryEscape(model)
+}
+
+func (openAIProvider) header(config Config) http.Header {
+	h := http.Header{}
+	if config.APIKey != "" {
+		h.Set("Authorization", "Bearer "+config.APIKey)
+	}
+	h.Set("OpenAI-Beta", "realtime=v1")
+	return h
+}
+
+func (openAIProvider) setup(config Config) []any {
+	voice := config.Voice
+	if voice == "" {
+		voice = openAIDefaultVoice
+	}
+	session := map[string]any{
+		"modalities":          []string{"audio", "text"},
+		"voice":               voice,
+		"input_audio_format":  "pcm16",
+		"output_audio_format": "pcm16",
+		"turn_detection":      map[string]any{"type": "server_vad"},
+	}
+	if config.Instructions != "" {
+		session["instructions"] = config.Instructions
+	}
+	return []any{&openAIMessage{Type: "session.update", Session: session}}
+}
+
+func (openAIProvider) inputSampleRate() int {
+	return openAISampleRate
+}
+
+func (openAIProvider) appendAudio(pcm []byte) any {
+	return &openAIMessage{Type: "input_audio_buffer.append", Audio: base64.StdEncoding.EncodeToString(pcm)}
+}
+
+func (openAIProvider) parse(data []byte) (Event, error) {
+	msg := openAIMessage{}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return Event{}, err
+	}
+
+	switch msg.Type {
+	case "response.audio.delta", "response.output_audio.delta":
+		pcm, err := decodePCM(msg.Delta)
+		return Event{Audio: pcm}, err
+	case "input_audio_buffer.speech_started":
+		return Event{Interrupted: true}, nil
+	case "response.done":
+		return Event{ResponseDone: true}, nil
+	case "error":
+		if msg.Error != nil {
+			return Event{Error: msg.Error.Message}, nil
+		}
+		return Event{Error: "unknown error"}, nil
+	}
+	return Event{}, nil
+}
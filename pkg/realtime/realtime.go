@@ -0,0 +1,285 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package realtime connects to speech to speech model APIs, OpenAI Realtime and Gemini Live, over their
+// WebSocket protocols, so that a track of a room can talk to a model without an agent process.
+package realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+// Provider is the API a session talks to
+type Provider string
+
+const (
+	ProviderOpenAI Provider = "openai"
+	ProviderGemini Provider = "gemini"
+)
+
+// sample rate of the audio models return, for both providers
+const OutputSampleRate = 24000
+
+const writeTimeout = 5 * time.Second
+
+var (
+	ErrUnknownProvider = errors.New("unknown realtime provider, must be openai or gemini")
+	ErrNoAPIKey        = errors.New("realtime provider api key missing")
+	ErrSessionClosed   = errors.New("realtime session closed")
+)
+
+// Config enables bridges between audio tracks and a realtime model: the audio of the track is sent to
+// the model, the audio it answers with is played into the room as a server side track
+type Config struct {
+	Enabled  bool     `yaml:"enabled,omitempty"`
+	Provider Provider `yaml:"provider,omitempty"`
+	// WebSocket URL of the API, defaults to that of the provider
+	URL    string `yaml:"url,omitempty"`
+	APIKey string `yaml:"api_key,omitempty"`
+	// model and voice, default to those of the provider when empty
+	Model string `yaml:"model,omitempty"`
+	Voice string `yaml:"voice,omitempty"`
+	// system prompt of the model
+	Instructions string `yaml:"instructions,omitempty"`
+	// bridges of a room at a time
+	MaxSessions int `yaml:"max_sessions,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Provider:    ProviderOpenAI,
+		MaxSessions: 2,
+	}
+)
+
+// SessionOverrides replaces settings of the config for one session, empty fields keep the config's
+type SessionOverrides struct {
+	Model        string `json:"model,omitempty"`
+	Voice        string `json:"voice,omitempty"`
+	Instructions string `json:"instructions,omitempty"`
+}
+
+func (c Config) WithOverrides(o SessionOverrides) Config {
+	if o.Model != "" {
+		c.Model = o.Model
+	}
+	if o.Voice != "" {
+		c.Voice = o.Voice
+	}
+	if o.Instructions != "" {
+		c.Instructions = o.Instructions
+	}
+	return c
+}
+
+// Event is what a session receives from the model
+type Event struct {
+	// mono 16 bit PCM at OutputSampleRate
+	Audio []int16
+	// the user started speaking, audio of the model still playing is to be dropped
+	Interrupted bool
+	// the model finished a response
+	ResponseDone bool
+	// error reported by the API, the session continues
+	Error string
+}
+
+// provider speaks the protocol of an API
+type provider interface {
+	url(config Config) string
+	header(config Config) http.Header
+	// messages sent once connected
+	setup(config Config) []any
+	inputSampleRate() int
+	// message appending little endian PCM at the input sample rate to the user's audio
+	appendAudio(pcm []byte) any
+	parse(data []byte) (Event, error)
+}
+
+func newProvider(p Provider) (provider, error) {
+	switch p {
+	case ProviderOpenAI, "":
+		return openAIProvider{}, nil
+	case ProviderGemini:
+		return geminiProvider{}, nil
+	}
+	return nil, ErrUnknownProvider
+}
+
+type SessionParams struct {
+	Config Config
+	// called for every event of the model, from a goroutine of the session
+	OnEvent func(event Event)
+	// called once when the connection ended, with the error that ended it, nil when closed
+	OnClose func(err error)
+}
+
+// Session is a connection to a realtime model
+type Session struct {
+	params   SessionParams
+	provider provider
+	conn     *websocket.Conn
+
+	writeLock sync.Mutex
+	resampler *audio.Resampler
+	inputRate int
+	pcm       []int16
+	payload   []byte
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// Dial connects to the API of the provider of the config and sets the session up
+func Dial(ctx context.Context, params SessionParams) (*Session, error) {
+	p, err := newProvider(params.Config.Provider)
+	if err != nil {
+		return nil, err
+	}
+	if params.Config.APIKey == "" && params.Config.URL == "" {
+		return nil, ErrNoAPIKey
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, p.url(params.Config), p.header(params.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{
+		params:   params,
+		provider: p,
+		conn:     conn,
+		closed:   make(chan struct{}),
+	}
+	for _, msg := range p.setup(params.Config) {
+		if err := s.write(msg); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+	}
+	go s.readWorker()
+	return s, nil
+}
+
+// SendAudio appends mono PCM at sampleRate to the audio of the user, resampled to the rate of the provider
+func (s *Session) SendAudio(pcm []int16, sampleRate int) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.resampler == nil || s.inputRate != sampleRate {
+		s.resampler = audio.NewResampler(sampleRate, s.provider.inputSampleRate(), 1)
+		s.inputRate = sampleRate
+	}
+	s.pcm = s.resampler.Resample(pcm, s.pcm[:0])
+	if len(s.pcm) == 0 {
+		return nil
+	}
+	s.payload = PCMToBytes(s.pcm, s.payload[:0])
+	return s.writeLocked(s.provider.appendAudio(s.payload))
+}
+
+func (s *Session) Close() {
+	s.close(nil)
+}
+
+func (s *Session) Done() <-chan struct{} {
+	return s.closed
+}
+
+func (s *Session) write(msg any) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	return s.writeLocked(msg)
+}
+
+func (s *Session) writeLocked(msg any) error {
+	select {
+	case <-s.closed:
+		return ErrSessionClosed
+	default:
+	}
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return s.conn.WriteJSON(msg)
+}
+
+func (s *Session) close(err error) {
+	closed := false
+	s.closeOnce.Do(func() {
+		close(s.closed)
+		_ = s.conn.Close()
+		closed = true
+	})
+	// outside of the once, OnClose may close the session again
+	if closed && s.params.OnClose != nil {
+		s.params.OnClose(err)
+	}
+}
+
+func (s *Session) readWorker() {
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.closed:
+			default:
+				s.close(err)
+			}
+			return
+		}
+
+		event, err := s.provider.parse(data)
+		if err != nil {
+			s.close(err)
+			return
+		}
+		if s.params.OnEvent != nil && (len(event.Audio) != 0 || event.Interrupted || event.ResponseDone || event.Error != "") {
+			s.params.OnEvent(event)
+		}
+	}
+}
+
+// --------------------------------------
+
+// PCMToBytes appends the samples to b as signed 16 bit little endian PCM
+func PCMToBytes(pcm []int16, b []byte) []byte {
+	for _, sample := range pcm {
+		b = binary.LittleEndian.AppendUint16(b, uint16(sample))
+	}
+	return b
+}
+
+// decodePCM returns the samples of base64 encoded signed 16 bit little endian PCM
+func decodePCM(s string) ([]int16, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	pcm := make([]int16, len(b)/2)
+	for i := range pcm {
+		pcm[i] = int16(binary.LittleEndian.Uint16(b[2*i:]))
+	}
+	return pcm, nil
+}
@@ -0,0 +1,123 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package realtime
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAISession(t *testing.T) {
+	received := make(chan map[string]any, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		for i := 0; i < 2; i++ {
+			msg := map[string]any{}
+			require.NoError(t, conn.ReadJSON(&msg))
+			received <- msg
+		}
+		delta := base64.StdEncoding.EncodeToString(PCMToBytes([]int16{1, -2, 3}, nil))
+		require.NoError(t, conn.WriteJSON(map[string]any{"type": "response.audio.delta", "delta": delta}))
+		require.NoError(t, conn.WriteJSON(map[string]any{"type": "input_audio_buffer.speech_started"}))
+		require.NoError(t, conn.WriteJSON(map[string]any{"type": "session.updated"}))
+		require.NoError(t, conn.WriteJSON(map[string]any{"type": "response.done"}))
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	events := make(chan Event, 10)
+	closed := make(chan error, 1)
+	config := DefaultConfig
+	config.URL = "ws" + strings.TrimPrefix(server.URL, "http")
+	config.APIKey = "key"
+	config.Instructions = "be brief"
+	s, err := Dial(context.Background(), SessionParams{
+		Config:  config,
+		OnEvent: func(event Event) { events <- event },
+		OnClose: func(err error) { closed <- err },
+	})
+	require.NoError(t, err)
+
+	setup := <-received
+	require.Equal(t, "session.update", setup["type"])
+	session := setup["session"].(map[string]any)
+	require.Equal(t, "alloy", session["voice"])
+	require.Equal(t, "be brief", session["instructions"])
+
+	// 10ms at 48kHz is 10ms at 24kHz
+	require.NoError(t, s.SendAudio(make([]int16, 480), 48000))
+	appended := <-received
+	require.Equal(t, "input_audio_buffer.append", appended["type"])
+	b, err := base64.StdEncoding.DecodeString(appended["audio"].(string))
+	require.NoError(t, err)
+	require.InDelta(t, 480, len(b), 4)
+
+	require.Equal(t, Event{Audio: []int16{1, -2, 3}}, <-events)
+	require.Equal(t, Event{Interrupted: true}, <-events)
+	require.Equal(t, Event{ResponseDone: true}, <-events)
+
+	s.Close()
+	require.NoError(t, <-closed)
+	require.ErrorIs(t, s.SendAudio(make([]int16, 480), 48000), ErrSessionClosed)
+}
+
+func TestGemini(t *testing.T) {
+	p := geminiProvider{}
+
+	setup, err := json.Marshal(p.setup(Config{Model: "gemini-live", Instructions: "be brief"})[0])
+	require.NoError(t, err)
+	msg := struct {
+		Setup struct {
+			Model             string `json:"model"`
+			SystemInstruction struct {
+				Parts []geminiPart `json:"parts"`
+			} `json:"systemInstruction"`
+		} `json:"setup"`
+	}{}
+	require.NoError(t, json.Unmarshal(setup, &msg))
+	require.Equal(t, "models/gemini-live", msg.Setup.Model)
+	require.Equal(t, "be brief", msg.Setup.SystemInstruction.Parts[0].Text)
+
+	data := base64.StdEncoding.EncodeToString(PCMToBytes([]int16{4, 5}, nil))
+	event, err := p.parse([]byte(`{"serverContent":{"modelTurn":{"parts":[{"text":"hi"},{"inlineData":{"mimeType":"audio/pcm;rate=24000","data":"` + data + `"}}]}}}`))
+	require.NoError(t, err)
+	require.Equal(t, Event{Audio: []int16{4, 5}}, event)
+
+	event, err = p.parse([]byte(`{"serverContent":{"interrupted":true}}`))
+	require.NoError(t, err)
+	require.Equal(t, Event{Interrupted: true}, event)
+
+	event, err = p.parse([]byte(`{"setupComplete":{}}`))
+	require.NoError(t, err)
+	require.Equal(t, Event{}, event)
+
+	_, err = newProvider("other")
+	require.ErrorIs(t, err, ErrUnknownProvider)
+	_, err = Dial(context.Background(), SessionParams{Config: Config{Provider: ProviderGemini}})
+	require.ErrorIs(t, err, ErrNoAPIKey)
+}
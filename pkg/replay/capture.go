@@ -0,0 +1,239 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+)
+
+// A capture starts with the magic and the capture start time, followed by records of a type byte,
+// the offset from the start in nanoseconds, the SSRC, and a length prefixed payload. All integers are big endian.
+const (
+	captureMagic   = "AGXR"
+	captureVersion = 1
+
+	// the stream of an SSRC was bound, the payload is a StreamRecord
+	RecordStream byte = 'S'
+	// an RTP packet of a bound stream as it left SRTP decryption
+	RecordRTP byte = 'R'
+	// the stream of an SSRC was unbound
+	RecordUnbind byte = 'U'
+	// a signal message of the participant, the payload is a SignalRecord
+	RecordSignal byte = 'G'
+
+	maxRecordSize = 1 << 20
+)
+
+var (
+	ErrInvalidCapture = errors.New("invalid capture")
+)
+
+type Record struct {
+	Type    byte
+	Offset  time.Duration
+	SSRC    uint32
+	Payload []byte
+}
+
+type RTPHeaderExtension struct {
+	URI string `json:"uri"`
+	ID  int    `json:"id"`
+}
+
+type RTCPFeedback struct {
+	Type      string `json:"type"`
+	Parameter string `json:"parameter,omitempty"`
+}
+
+// StreamRecord is the part of the stream info of a bound stream receive interceptors depend on
+type StreamRecord struct {
+	ID                  string               `json:"id"`
+	SSRC                uint32               `json:"ssrc"`
+	PayloadType         uint8                `json:"payload_type"`
+	MimeType            string               `json:"mime_type"`
+	ClockRate           uint32               `json:"clock_rate"`
+	Channels            uint16               `json:"channels"`
+	SDPFmtpLine         string               `json:"sdp_fmtp_line,omitempty"`
+	RTPHeaderExtensions []RTPHeaderExtension `json:"rtp_header_extensions,omitempty"`
+	RTCPFeedback        []RTCPFeedback       `json:"rtcp_feedback,omitempty"`
+}
+
+func NewStreamRecord(info *interceptor.StreamInfo) StreamRecord {
+	s := StreamRecord{
+		ID:          info.ID,
+		SSRC:        info.SSRC,
+		PayloadType: info.PayloadType,
+		MimeType:    info.MimeType,
+		ClockRate:   info.ClockRate,
+		Channels:    info.Channels,
+		SDPFmtpLine: info.SDPFmtpLine,
+	}
+	for _, ext := range info.RTPHeaderExtensions {
+		s.RTPHeaderExtensions = append(s.RTPHeaderExtensions, RTPHeaderExtension{URI: ext.URI, ID: ext.ID})
+	}
+	for _, fb := range info.RTCPFeedback {
+		s.RTCPFeedback = append(s.RTCPFeedback, RTCPFeedback{Type: fb.Type, Parameter: fb.Parameter})
+	}
+	return s
+}
+
+func (s StreamRecord) StreamInfo() *interceptor.StreamInfo {
+	info := &interceptor.StreamInfo{
+		ID:          s.ID,
+		Attributes:  interceptor.Attributes{},
+		SSRC:        s.SSRC,
+		PayloadType: s.PayloadType,
+		MimeType:    s.MimeType,
+		ClockRate:   s.ClockRate,
+		Channels:    s.Channels,
+		SDPFmtpLine: s.SDPFmtpLine,
+	}
+	for _, ext := range s.RTPHeaderExtensions {
+		info.RTPHeaderExtensions = append(info.RTPHeaderExtensions, interceptor.RTPHeaderExtension{URI: ext.URI, ID: ext.ID})
+	}
+	for _, fb := range s.RTCPFeedback {
+		info.RTCPFeedback = append(info.RTCPFeedback, interceptor.RTCPFeedback{Type: fb.Type, Parameter: fb.Parameter})
+	}
+	return info
+}
+
+// SignalRecord is a signal message, Message being its protojson encoding
+type SignalRecord struct {
+	Type    string          `json:"type"`
+	Message json.RawMessage `json:"message"`
+}
+
+// --------------------------------------
+
+// CaptureWriter writes a capture, safe for concurrent use
+type CaptureWriter struct {
+	lock  sync.Mutex
+	w     *bufio.Writer
+	start time.Time
+	err   error
+}
+
+func NewCaptureWriter(w io.Writer, start time.Time) (*CaptureWriter, error) {
+	bw := bufio.NewWriter(w)
+	header := make([]byte, 0, len(captureMagic)+1+8)
+	header = append(header, captureMagic...)
+	header = append(header, captureVersion)
+	header = binary.BigEndian.AppendUint64(header, uint64(start.UnixNano()))
+	if _, err := bw.Write(header); err != nil {
+		return nil, err
+	}
+	return &CaptureWriter{
+		w:     bw,
+		start: start,
+	}, nil
+}
+
+// Write adds a record at the time at, the first error is returned by every later call
+func (c *CaptureWriter) Write(recordType byte, at time.Time, ssrc uint32, payload []byte) error {
+	if len(payload) > maxRecordSize {
+		return fmt.Errorf("%w: record of %d bytes", ErrInvalidCapture, len(payload))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	var header [17]byte
+	header[0] = recordType
+	binary.BigEndian.PutUint64(header[1:9], uint64(at.Sub(c.start)))
+	binary.BigEndian.PutUint32(header[9:13], ssrc)
+	binary.BigEndian.PutUint32(header[13:17], uint32(len(payload)))
+	if _, c.err = c.w.Write(header[:]); c.err == nil {
+		_, c.err = c.w.Write(payload)
+	}
+	return c.err
+}
+
+func (c *CaptureWriter) Flush() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.err != nil {
+		return c.err
+	}
+	c.err = c.w.Flush()
+	return c.err
+}
+
+// CaptureReader reads the records of a capture in order
+type CaptureReader struct {
+	r     *bufio.Reader
+	start time.Time
+}
+
+func NewCaptureReader(r io.Reader) (*CaptureReader, error) {
+	br := bufio.NewReader(r)
+	var header [len(captureMagic) + 1 + 8]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCapture, err)
+	}
+	if string(header[:len(captureMagic)]) != captureMagic {
+		return nil, fmt.Errorf("%w: bad magic", ErrInvalidCapture)
+	}
+	if version := header[len(captureMagic)]; version != captureVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrInvalidCapture, version)
+	}
+	return &CaptureReader{
+		r:     br,
+		start: time.Unix(0, int64(binary.BigEndian.Uint64(header[len(captureMagic)+1:]))),
+	}, nil
+}
+
+// Start returns the time the capture started
+func (c *CaptureReader) Start() time.Time {
+	return c.start
+}
+
+// Next returns the next record, io.EOF at the end of the capture
+func (c *CaptureReader) Next() (Record, error) {
+	var header [17]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			err = fmt.Errorf("%w: truncated record", ErrInvalidCapture)
+		}
+		return Record{}, err
+	}
+	size := binary.BigEndian.Uint32(header[13:17])
+	if size > maxRecordSize {
+		return Record{}, fmt.Errorf("%w: record of %d bytes", ErrInvalidCapture, size)
+	}
+	rec := Record{
+		Type:    header[0],
+		Offset:  time.Duration(binary.BigEndian.Uint64(header[1:9])),
+		SSRC:    binary.BigEndian.Uint32(header[9:13]),
+		Payload: make([]byte, size),
+	}
+	if _, err := io.ReadFull(c.r, rec.Payload); err != nil {
+		return Record{}, fmt.Errorf("%w: truncated record", ErrInvalidCapture)
+	}
+	return rec, nil
+}
@@ -0,0 +1,178 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/pion/interceptor"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const captureExtension = ".agxr"
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+type CaptureConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// directory the captures of participants are written to
+	Dir string `yaml:"dir,omitempty"`
+}
+
+var (
+	DefaultCaptureConfig = CaptureConfig{
+		Dir: "replay_captures",
+	}
+)
+
+// Capturer records sessions of participants for replay, nil when capture is disabled
+type Capturer struct {
+	config CaptureConfig
+}
+
+func NewCapturer(config CaptureConfig) *Capturer {
+	if config.Dir == "" {
+		config.Dir = DefaultCaptureConfig.Dir
+	}
+	return &Capturer{
+		config: config,
+	}
+}
+
+// Open starts the capture of a participant, nil if the capturer is nil
+func (c *Capturer) Open(name string) (*CaptureSession, error) {
+	if c == nil {
+		return nil, nil
+	}
+	if err := os.MkdirAll(c.config.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	start := Now()
+	path := filepath.Join(c.config.Dir, fmt.Sprintf("%s_%s%s", start.UTC().Format("20060102T150405.000"), unsafeNameChars.ReplaceAllString(name, "_"), captureExtension))
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := NewCaptureWriter(file, start)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return &CaptureSession{
+		path:   path,
+		file:   file,
+		writer: writer,
+	}, nil
+}
+
+// CaptureSession records the received RTP and the signal messages of a participant to a capture
+// file, the streams as they leave SRTP decryption, ahead of all receive interceptors. Methods are nil-safe.
+type CaptureSession struct {
+	path   string
+	file   *os.File
+	writer *CaptureWriter
+
+	closeOnce sync.Once
+}
+
+func (s *CaptureSession) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Factory returns the receive stage recording the streams, it has to be added first
+func (s *CaptureSession) Factory() interceptor.Factory {
+	if s == nil {
+		return nil
+	}
+	return &captureFactory{session: s}
+}
+
+// Signal records a signal message received from the participant
+func (s *CaptureSession) Signal(msg proto.Message) {
+	if s == nil || msg == nil {
+		return
+	}
+	message, err := protojson.Marshal(msg)
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(SignalRecord{
+		Type:    string(msg.ProtoReflect().Descriptor().FullName()),
+		Message: message,
+	})
+	if err != nil {
+		return
+	}
+	_ = s.writer.Write(RecordSignal, Now(), 0, payload)
+}
+
+func (s *CaptureSession) Close() error {
+	if s == nil {
+		return nil
+	}
+	var err error
+	s.closeOnce.Do(func() {
+		err = s.writer.Flush()
+		if closeErr := s.file.Close(); err == nil {
+			err = closeErr
+		}
+	})
+	return err
+}
+
+// --------------------------------------
+
+type captureFactory struct {
+	session *CaptureSession
+}
+
+func (f *captureFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &captureInterceptor{session: f.session}, nil
+}
+
+type captureInterceptor struct {
+	interceptor.NoOp
+	session *CaptureSession
+}
+
+func (c *captureInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	writer := c.session.writer
+	if payload, err := json.Marshal(NewStreamRecord(info)); err == nil {
+		_ = writer.Write(RecordStream, Now(), info.SSRC, payload)
+	}
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err == nil && n > 0 {
+			_ = writer.Write(RecordRTP, Now(), info.SSRC, b[:n])
+		}
+		return n, a, err
+	})
+}
+
+func (c *captureInterceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	_ = c.session.writer.Write(RecordUnbind, Now(), info.SSRC, nil)
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// Clock is the time source of the media pipeline, the wall clock unless a replay virtualizes it
+type Clock interface {
+	Now() time.Time
+	NewTicker(d time.Duration) Ticker
+}
+
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+var clock atomic.Pointer[clockHolder]
+
+type clockHolder struct {
+	Clock
+}
+
+// SetClock replaces the time source of the node, nil restores the wall clock
+func SetClock(c Clock) {
+	if c == nil {
+		clock.Store(nil)
+		return
+	}
+	clock.Store(&clockHolder{c})
+}
+
+func Now() time.Time {
+	if c := clock.Load(); c != nil {
+		return c.Now()
+	}
+	return time.Now()
+}
+
+func Since(t time.Time) time.Duration {
+	return Now().Sub(t)
+}
+
+func NewTicker(d time.Duration) Ticker {
+	if c := clock.Load(); c != nil {
+		return c.NewTicker(d)
+	}
+	return wallTicker{time.NewTicker(d)}
+}
+
+type wallTicker struct {
+	*time.Ticker
+}
+
+func (t wallTicker) C() <-chan time.Time {
+	return t.Ticker.C
+}
+
+// --------------------------------------
+
+// VirtualClock only moves when it is set. Tickers fire on the way, each tick is handed to its receiver
+// before the clock moves on, so that timers run at the same point of a replay every time.
+type VirtualClock struct {
+	lock    sync.Mutex
+	now     time.Time
+	tickers []*virtualTicker
+}
+
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{
+		now: start,
+	}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.now
+}
+
+func (c *VirtualClock) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	t := &virtualTicker{
+		clock:   c,
+		period:  d,
+		next:    c.now.Add(d),
+		c:       make(chan time.Time),
+		stopped: make(chan struct{}),
+	}
+	c.tickers = append(c.tickers, t)
+	return t
+}
+
+// Set moves the clock forward to now, firing the tickers due up to then in order. The clock never goes back.
+func (c *VirtualClock) Set(now time.Time) {
+	for {
+		c.lock.Lock()
+		sort.SliceStable(c.tickers, func(i, j int) bool {
+			return c.tickers[i].next.Before(c.tickers[j].next)
+		})
+		if len(c.tickers) == 0 || c.tickers[0].next.After(now) {
+			if now.After(c.now) {
+				c.now = now
+			}
+			c.lock.Unlock()
+			return
+		}
+
+		t := c.tickers[0]
+		c.now = t.next
+		t.next = t.next.Add(t.period)
+		tick := c.now
+		c.lock.Unlock()
+
+		select {
+		case t.c <- tick:
+		case <-t.stopped:
+		}
+	}
+}
+
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+func (c *VirtualClock) removeTicker(t *virtualTicker) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, other := range c.tickers {
+		if other == t {
+			c.tickers = append(c.tickers[:i], c.tickers[i+1:]...)
+			return
+		}
+	}
+}
+
+type virtualTicker struct {
+	clock  *VirtualClock
+	period time.Duration
+	// guarded by the lock of the clock
+	next time.Time
+
+	c        chan time.Time
+	stopOnce sync.Once
+	stopped  chan struct{}
+}
+
+func (t *virtualTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *virtualTicker) Stop() {
+	t.stopOnce.Do(func() {
+		close(t.stopped)
+		t.clock.removeTicker(t)
+	})
+}
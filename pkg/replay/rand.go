@@ -0,0 +1,59 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"math/rand"
+	"sync"
+
+	"go.uber.org/atomic"
+)
+
+var source atomic.Pointer[lockedRand]
+
+type lockedRand struct {
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+// Seed makes the random choices of the node, e. g. initial sequence numbers and offer ids, repeat
+// for the same seed
+func Seed(seed int64) {
+	source.Store(&lockedRand{rand: rand.New(rand.NewSource(seed))})
+}
+
+// Unseed restores random choices differing from run to run
+func Unseed() {
+	source.Store(nil)
+}
+
+func Intn(n int) int {
+	if r := source.Load(); r != nil {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		return r.rand.Intn(n)
+	}
+	return rand.Intn(n)
+}
+
+func Uint32() uint32 {
+	if r := source.Load(); r != nil {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		return r.rand.Uint32()
+	}
+	return rand.Uint32()
+}
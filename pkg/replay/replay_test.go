@@ -0,0 +1,136 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualClock(t *testing.T) {
+	start := time.Unix(1000, 0)
+	clock := NewVirtualClock(start)
+	ticker := clock.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	ticks := make(chan time.Time, 10)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case tick := <-ticker.C():
+				ticks <- tick
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	clock.Set(start.Add(250 * time.Millisecond))
+	require.Equal(t, start.Add(250*time.Millisecond), clock.Now())
+	require.Equal(t, start.Add(100*time.Millisecond), <-ticks)
+	require.Equal(t, start.Add(200*time.Millisecond), <-ticks)
+
+	// the clock never goes back
+	clock.Set(start)
+	require.Equal(t, start.Add(250*time.Millisecond), clock.Now())
+}
+
+// stampFactory appends a random byte and the time since the capture start to every packet
+type stampFactory struct {
+	start time.Time
+}
+
+func (f *stampFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &stampInterceptor{start: f.start}, nil
+}
+
+type stampInterceptor struct {
+	interceptor.NoOp
+	start time.Time
+}
+
+func (s *stampInterceptor) BindRemoteStream(_ *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err != nil {
+			return n, a, err
+		}
+		b[n] = byte(Intn(256))
+		binary.BigEndian.PutUint64(b[n+1:], uint64(Since(s.start)))
+		return n + 9, a, nil
+	})
+}
+
+func TestReplay(t *testing.T) {
+	start := time.Unix(1000, 0)
+	var capture bytes.Buffer
+	writer, err := NewCaptureWriter(&capture, start)
+	require.NoError(t, err)
+
+	stream, err := json.Marshal(StreamRecord{ID: "TR_audio", SSRC: 1, PayloadType: 111, MimeType: "audio/opus", ClockRate: 48000, Channels: 2})
+	require.NoError(t, err)
+	require.NoError(t, writer.Write(RecordSignal, start, 0, []byte(`{"type":"livekit.SignalRequest","message":{}}`)))
+	require.NoError(t, writer.Write(RecordStream, start, 1, stream))
+	for i := 0; i < 5; i++ {
+		require.NoError(t, writer.Write(RecordRTP, start.Add(time.Duration(i)*20*time.Millisecond), 1, []byte{0x80, 111, 0, byte(i)}))
+	}
+	require.NoError(t, writer.Write(RecordUnbind, start.Add(100*time.Millisecond), 1, nil))
+	require.NoError(t, writer.Flush())
+
+	run := func(seed int64) (*Result, []time.Duration) {
+		var offsets []time.Duration
+		res, err := Replay(bytes.NewReader(capture.Bytes()), ReplayParams{
+			Seed: seed,
+			Interceptors: func() ([]interceptor.Factory, error) {
+				return []interceptor.Factory{&stampFactory{start: start}}, nil
+			},
+			OnPacket: func(ssrc uint32, packet []byte, _ interceptor.Attributes) {
+				require.Equal(t, uint32(1), ssrc)
+				offsets = append(offsets, time.Duration(binary.BigEndian.Uint64(packet[len(packet)-8:])))
+			},
+		})
+		require.NoError(t, err)
+		return res, offsets
+	}
+
+	res, offsets := run(1)
+	require.Equal(t, 1, res.Signals)
+	require.Len(t, res.Streams, 1)
+	require.Equal(t, 5, res.Streams[0].Packets)
+	require.Equal(t, "audio/opus", res.Streams[0].MimeType)
+	// the clock follows the capture
+	require.Equal(t, []time.Duration{0, 20 * time.Millisecond, 40 * time.Millisecond, 60 * time.Millisecond, 80 * time.Millisecond}, offsets)
+
+	again, _ := run(1)
+	require.Equal(t, res.Digest, again.Digest)
+
+	other, _ := run(2)
+	require.NotEqual(t, res.Digest, other.Digest)
+
+	// the wall clock is back after a replay
+	require.WithinDuration(t, time.Now(), Now(), time.Second)
+
+	_, err = Replay(bytes.NewReader([]byte("nope")), ReplayParams{})
+	require.ErrorIs(t, err, ErrInvalidCapture)
+}
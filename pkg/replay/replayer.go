@@ -0,0 +1,204 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replay
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"sort"
+
+	"github.com/pion/interceptor"
+)
+
+var (
+	ErrUnknownStream = errors.New("packet of an unbound stream")
+
+	errNoPacket = errors.New("no captured packet pending")
+)
+
+type ReplayParams struct {
+	Seed int64
+	// creates the receive interceptors to replay through, in the order of a transport, innermost first.
+	// It is called once the clock is virtual and the randomness seeded.
+	Interceptors func() ([]interceptor.Factory, error)
+	// called with every packet leaving the interceptors
+	OnPacket func(ssrc uint32, packet []byte, a interceptor.Attributes)
+}
+
+type StreamResult struct {
+	SSRC     uint32 `json:"ssrc"`
+	MimeType string `json:"mime_type"`
+	Packets  int    `json:"packets"`
+	// packets an interceptor failed to read
+	Errors int `json:"errors"`
+	// SHA-256 of the packets leaving the interceptors
+	Digest string `json:"digest"`
+}
+
+// Result of a replay, identical for every replay of a capture with the same seed and configuration
+type Result struct {
+	Seed    int64          `json:"seed"`
+	Signals int            `json:"signals"`
+	Streams []StreamResult `json:"streams"`
+	// SHA-256 over the digests of all streams
+	Digest string `json:"digest"`
+}
+
+// Replay runs a capture through receive interceptors deterministically: the node's clock follows the
+// capture instead of the wall clock and its random choices are seeded. Packets are read through the
+// interceptors one at a time in the order they were captured, so that the output is bit identical across runs
+// and machines. Only one replay can run in a process at a time, the clock and randomness are restored after it.
+func Replay(r io.Reader, params ReplayParams) (*Result, error) {
+	capture, err := NewCaptureReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := NewVirtualClock(capture.Start())
+	SetClock(clock)
+	Seed(params.Seed)
+	defer func() {
+		SetClock(nil)
+		Unseed()
+	}()
+
+	ir := &interceptor.Registry{}
+	if params.Interceptors != nil {
+		factories, err := params.Interceptors()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range factories {
+			ir.Add(f)
+		}
+	}
+	chain, err := ir.Build("replay")
+	if err != nil {
+		return nil, err
+	}
+	defer chain.Close()
+
+	res := &Result{Seed: params.Seed}
+	streams := make(map[uint32]*replayStream)
+	var finished []*replayStream
+	unbind := func(s *replayStream) {
+		chain.UnbindRemoteStream(s.info)
+		delete(streams, s.info.SSRC)
+		finished = append(finished, s)
+	}
+
+	buf := make([]byte, maxRecordSize)
+	for {
+		rec, err := capture.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		clock.Set(capture.Start().Add(rec.Offset))
+
+		switch rec.Type {
+		case RecordStream:
+			var stream StreamRecord
+			if err := json.Unmarshal(rec.Payload, &stream); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidCapture, err)
+			}
+			if s, ok := streams[rec.SSRC]; ok {
+				unbind(s)
+			}
+			s := &replayStream{
+				info:   stream.StreamInfo(),
+				digest: sha256.New(),
+			}
+			s.reader = chain.BindRemoteStream(s.info, interceptor.RTPReaderFunc(s.read))
+			streams[rec.SSRC] = s
+
+		case RecordRTP:
+			s, ok := streams[rec.SSRC]
+			if !ok {
+				return nil, fmt.Errorf("%w: %d", ErrUnknownStream, rec.SSRC)
+			}
+			s.pending = rec.Payload
+			n, a, err := s.reader.Read(buf, nil)
+			s.pending = nil
+			if err != nil {
+				s.result.Errors++
+				continue
+			}
+			s.result.Packets++
+			var size [4]byte
+			binary.BigEndian.PutUint32(size[:], uint32(n))
+			s.digest.Write(size[:])
+			s.digest.Write(buf[:n])
+			if params.OnPacket != nil {
+				params.OnPacket(rec.SSRC, buf[:n], a)
+			}
+
+		case RecordUnbind:
+			if s, ok := streams[rec.SSRC]; ok {
+				unbind(s)
+			}
+
+		case RecordSignal:
+			res.Signals++
+		}
+	}
+	for _, s := range streams {
+		unbind(s)
+	}
+
+	sort.SliceStable(finished, func(i, j int) bool {
+		return finished[i].info.SSRC < finished[j].info.SSRC
+	})
+	digest := sha256.New()
+	for _, s := range finished {
+		s.result.SSRC = s.info.SSRC
+		s.result.MimeType = s.info.MimeType
+		s.result.Digest = hex.EncodeToString(s.digest.Sum(nil))
+		digest.Write([]byte(s.result.Digest))
+		res.Streams = append(res.Streams, s.result)
+	}
+	res.Digest = hex.EncodeToString(digest.Sum(nil))
+	return res, nil
+}
+
+type replayStream struct {
+	info    *interceptor.StreamInfo
+	reader  interceptor.RTPReader
+	pending []byte
+	digest  hash.Hash
+	result  StreamResult
+}
+
+// read hands the captured packet to the innermost interceptor, there is at most one per read of the chain
+func (s *replayStream) read(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+	if s.pending == nil {
+		return 0, a, errNoPacket
+	}
+	n := copy(b, s.pending)
+	s.pending = nil
+	if a == nil {
+		a = interceptor.Attributes{}
+	}
+	return n, a, nil
+}
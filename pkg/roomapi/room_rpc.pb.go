@@ -0,0 +1,161 @@
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: room_rpc.proto
+
+package roomapi
+
+import (
+	_ "github.com/livekit/psrpc/protoc-gen-psrpc/options"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var File_room_rpc_proto protoreflect.FileDescriptor
+
+const file_room_rpc_proto_rawDesc = "" +
+	"\n" +
+	"\x0eroom_rpc.proto\x12\fagentix.room\x1a\roptions.proto\x1a\rroomapi.proto2\xf8\r\n" +
+	"\x0eRoomProcessing\x12t\n" +
+	"\x13GetTrackNoiseFilter\x12%.agentix.room.TrackNoiseFilterRequest\x1a\x1e.agentix.room.TrackNoiseFilter\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12}\n" +
+	"\x16UpdateTrackNoiseFilter\x12+.agentix.room.UpdateTrackNoiseFilterRequest\x1a\x1e.agentix.room.TrackNoiseFilter\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12_\n" +
+	"\fGetTrackGain\x12\x1e.agentix.room.TrackGainRequest\x1a\x17.agentix.room.TrackGain\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12h\n" +
+	"\x0fUpdateTrackGain\x12$.agentix.room.UpdateTrackGainRequest\x1a\x17.agentix.room.TrackGain\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12y\n" +
+	"\x13GetProcessingBypass\x12%.agentix.room.ProcessingBypassRequest\x1a#.agentix.room.ProcessingBypassState\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12\x82\x01\n" +
+	"\x16EnableProcessingBypass\x12+.agentix.room.EnableProcessingBypassRequest\x1a#.agentix.room.ProcessingBypassState\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12\x84\x01\n" +
+	"\x17DisableProcessingBypass\x12,.agentix.room.DisableProcessingBypassRequest\x1a#.agentix.room.ProcessingBypassState\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12k\n" +
+	"\x10GetTalkAnalytics\x12\".agentix.room.TalkAnalyticsRequest\x1a\x1b.agentix.room.TalkAnalytics\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12\x7f\n" +
+	"\x12ListAudioSnapshots\x12'.agentix.room.ListAudioSnapshotsRequest\x1a(.agentix.room.ListAudioSnapshotsResponse\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12k\n" +
+	"\x10GetAudioSnapshot\x12\".agentix.room.AudioSnapshotRequest\x1a\x1b.agentix.room.AudioSnapshot\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12o\n" +
+	"\x10StartTrackMirror\x12 .agentix.room.TrackMirrorRequest\x1a!.agentix.room.TrackMirrorResponse\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12n\n" +
+	"\x0fStopTrackMirror\x12 .agentix.room.TrackMirrorRequest\x1a!.agentix.room.TrackMirrorResponse\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12u\n" +
+	"\x13StartRealtimeBridge\x12(.agentix.room.StartRealtimeBridgeRequest\x1a\x1c.agentix.room.RealtimeBridge\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12\x7f\n" +
+	"\x12StopRealtimeBridge\x12'.agentix.room.StopRealtimeBridgeRequest\x1a(.agentix.room.StopRealtimeBridgeResponse\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01\x12k\n" +
+	"\x10GetSupportBundle\x12\".agentix.room.SupportBundleRequest\x1a\x1b.agentix.room.SupportBundle\"\x16\xb2\x89\x01\x12\x10\x01\x1a\x0e\n" +
+	"\x04room\x12\x04room\x18\x01B/Z-github.com/livekit/livekit-server/pkg/roomapib\x06proto3"
+
+var file_room_rpc_proto_goTypes = []any{
+	(*TrackNoiseFilterRequest)(nil),        // 0: agentix.room.TrackNoiseFilterRequest
+	(*UpdateTrackNoiseFilterRequest)(nil),  // 1: agentix.room.UpdateTrackNoiseFilterRequest
+	(*TrackGainRequest)(nil),               // 2: agentix.room.TrackGainRequest
+	(*UpdateTrackGainRequest)(nil),         // 3: agentix.room.UpdateTrackGainRequest
+	(*ProcessingBypassRequest)(nil),        // 4: agentix.room.ProcessingBypassRequest
+	(*EnableProcessingBypassRequest)(nil),  // 5: agentix.room.EnableProcessingBypassRequest
+	(*DisableProcessingBypassRequest)(nil), // 6: agentix.room.DisableProcessingBypassRequest
+	(*TalkAnalyticsRequest)(nil),           // 7: agentix.room.TalkAnalyticsRequest
+	(*ListAudioSnapshotsRequest)(nil),      // 8: agentix.room.ListAudioSnapshotsRequest
+	(*AudioSnapshotRequest)(nil),           // 9: agentix.room.AudioSnapshotRequest
+	(*TrackMirrorRequest)(nil),             // 10: agentix.room.TrackMirrorRequest
+	(*StartRealtimeBridgeRequest)(nil),     // 11: agentix.room.StartRealtimeBridgeRequest
+	(*StopRealtimeBridgeRequest)(nil),      // 12: agentix.room.StopRealtimeBridgeRequest
+	(*SupportBundleRequest)(nil),           // 13: agentix.room.SupportBundleRequest
+	(*TrackNoiseFilter)(nil),               // 14: agentix.room.TrackNoiseFilter
+	(*TrackGain)(nil),                      // 15: agentix.room.TrackGain
+	(*ProcessingBypassState)(nil),          // 16: agentix.room.ProcessingBypassState
+	(*TalkAnalytics)(nil),                  // 17: agentix.room.TalkAnalytics
+	(*ListAudioSnapshotsResponse)(nil),     // 18: agentix.room.ListAudioSnapshotsResponse
+	(*AudioSnapshot)(nil),                  // 19: agentix.room.AudioSnapshot
+	(*TrackMirrorResponse)(nil),            // 20: agentix.room.TrackMirrorResponse
+	(*RealtimeBridge)(nil),                 // 21: agentix.room.RealtimeBridge
+	(*StopRealtimeBridgeResponse)(nil),     // 22: agentix.room.StopRealtimeBridgeResponse
+	(*SupportBundle)(nil),                  // 23: agentix.room.SupportBundle
+}
+var file_room_rpc_proto_depIdxs = []int32{
+	0,  // 0: agentix.room.RoomProcessing.GetTrackNoiseFilter:input_type -> agentix.room.TrackNoiseFilterRequest
+	1,  // 1: agentix.room.RoomProcessing.UpdateTrackNoiseFilter:input_type -> agentix.room.UpdateTrackNoiseFilterRequest
+	2,  // 2: agentix.room.RoomProcessing.GetTrackGain:input_type -> agentix.room.TrackGainRequest
+	3,  // 3: agentix.room.RoomProcessing.UpdateTrackGain:input_type -> agentix.room.UpdateTrackGainRequest
+	4,  // 4: agentix.room.RoomProcessing.GetProcessingBypass:input_type -> agentix.room.ProcessingBypassRequest
+	5,  // 5: agentix.room.RoomProcessing.EnableProcessingBypass:input_type -> agentix.room.EnableProcessingBypassRequest
+	6,  // 6: agentix.room.RoomProcessing.DisableProcessingBypass:input_type -> agentix.room.DisableProcessingBypassRequest
+	7,  // 7: agentix.room.RoomProcessing.GetTalkAnalytics:input_type -> agentix.room.TalkAnalyticsRequest
+	8,  // 8: agentix.room.RoomProcessing.ListAudioSnapshots:input_type -> agentix.room.ListAudioSnapshotsRequest
+	9,  // 9: agentix.room.RoomProcessing.GetAudioSnapshot:input_type -> agentix.room.AudioSnapshotRequest
+	10, // 10: agentix.room.RoomProcessing.StartTrackMirror:input_type -> agentix.room.TrackMirrorRequest
+	10, // 11: agentix.room.RoomProcessing.StopTrackMirror:input_type -> agentix.room.TrackMirrorRequest
+	11, // 12: agentix.room.RoomProcessing.StartRealtimeBridge:input_type -> agentix.room.StartRealtimeBridgeRequest
+	12, // 13: agentix.room.RoomProcessing.StopRealtimeBridge:input_type -> agentix.room.StopRealtimeBridgeRequest
+	13, // 14: agentix.room.RoomProcessing.GetSupportBundle:input_type -> agentix.room.SupportBundleRequest
+	14, // 15: agentix.room.RoomProcessing.GetTrackNoiseFilter:output_type -> agentix.room.TrackNoiseFilter
+	14, // 16: agentix.room.RoomProcessing.UpdateTrackNoiseFilter:output_type -> agentix.room.TrackNoiseFilter
+	15, // 17: agentix.room.RoomProcessing.GetTrackGain:output_type -> agentix.room.TrackGain
+	15, // 18: agentix.room.RoomProcessing.UpdateTrackGain:output_type -> agentix.room.TrackGain
+	16, // 19: agentix.room.RoomProcessing.GetProcessingBypass:output_type -> agentix.room.ProcessingBypassState
+	16, // 20: agentix.room.RoomProcessing.EnableProcessingBypass:output_type -> agentix.room.ProcessingBypassState
+	16, // 21: agentix.room.RoomProcessing.DisableProcessingBypass:output_type -> agentix.room.ProcessingBypassState
+	17, // 22: agentix.room.RoomProcessing.GetTalkAnalytics:output_type -> agentix.room.TalkAnalytics
+	18, // 23: agentix.room.RoomProcessing.ListAudioSnapshots:output_type -> agentix.room.ListAudioSnapshotsResponse
+	19, // 24: agentix.room.RoomProcessing.GetAudioSnapshot:output_type -> agentix.room.AudioSnapshot
+	20, // 25: agentix.room.RoomProcessing.StartTrackMirror:output_type -> agentix.room.TrackMirrorResponse
+	20, // 26: agentix.room.RoomProcessing.StopTrackMirror:output_type -> agentix.room.TrackMirrorResponse
+	21, // 27: agentix.room.RoomProcessing.StartRealtimeBridge:output_type -> agentix.room.RealtimeBridge
+	22, // 28: agentix.room.RoomProcessing.StopRealtimeBridge:output_type -> agentix.room.StopRealtimeBridgeResponse
+	23, // 29: agentix.room.RoomProcessing.GetSupportBundle:output_type -> agentix.room.SupportBundle
+	15, // [15:30] is the sub-list for method output_type
+	0,  // [0:15] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_room_rpc_proto_init() }
+func file_room_rpc_proto_init() {
+	if File_room_rpc_proto != nil {
+		return
+	}
+	file_roomapi_proto_init()
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_room_rpc_proto_rawDesc), len(file_room_rpc_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_room_rpc_proto_goTypes,
+		DependencyIndexes: file_room_rpc_proto_depIdxs,
+	}.Build()
+	File_room_rpc_proto = out.File
+	file_room_rpc_proto_goTypes = nil
+	file_room_rpc_proto_depIdxs = nil
+}
@@ -0,0 +1,1925 @@
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: roomapi.proto
+
+package roomapi
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TrackNoiseFilterRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Room  string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// publisher of the track
+	Identity      string `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid      string `protobuf:"bytes,3,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackNoiseFilterRequest) Reset() {
+	*x = TrackNoiseFilterRequest{}
+	mi := &file_roomapi_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackNoiseFilterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackNoiseFilterRequest) ProtoMessage() {}
+
+func (x *TrackNoiseFilterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackNoiseFilterRequest.ProtoReflect.Descriptor instead.
+func (*TrackNoiseFilterRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TrackNoiseFilterRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *TrackNoiseFilterRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *TrackNoiseFilterRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+type UpdateTrackNoiseFilterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	Identity      string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid      string                 `protobuf:"bytes,3,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrackNoiseFilterRequest) Reset() {
+	*x = UpdateTrackNoiseFilterRequest{}
+	mi := &file_roomapi_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrackNoiseFilterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrackNoiseFilterRequest) ProtoMessage() {}
+
+func (x *UpdateTrackNoiseFilterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrackNoiseFilterRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTrackNoiseFilterRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *UpdateTrackNoiseFilterRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *UpdateTrackNoiseFilterRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *UpdateTrackNoiseFilterRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *UpdateTrackNoiseFilterRequest) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type TrackNoiseFilter struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid      string                 `protobuf:"bytes,2,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	Enabled       bool                   `protobuf:"varint,3,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackNoiseFilter) Reset() {
+	*x = TrackNoiseFilter{}
+	mi := &file_roomapi_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackNoiseFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackNoiseFilter) ProtoMessage() {}
+
+func (x *TrackNoiseFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackNoiseFilter.ProtoReflect.Descriptor instead.
+func (*TrackNoiseFilter) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TrackNoiseFilter) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *TrackNoiseFilter) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *TrackNoiseFilter) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+type TrackGainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	Identity      string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid      string                 `protobuf:"bytes,3,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackGainRequest) Reset() {
+	*x = TrackGainRequest{}
+	mi := &file_roomapi_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackGainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackGainRequest) ProtoMessage() {}
+
+func (x *TrackGainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackGainRequest.ProtoReflect.Descriptor instead.
+func (*TrackGainRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TrackGainRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *TrackGainRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *TrackGainRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+type UpdateTrackGainRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Room     string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	Identity string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid string                 `protobuf:"bytes,3,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	// -60 mutes, up to 20, 0 for none
+	GainDb        float64 `protobuf:"fixed64,4,opt,name=gain_db,json=gainDb,proto3" json:"gain_db,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrackGainRequest) Reset() {
+	*x = UpdateTrackGainRequest{}
+	mi := &file_roomapi_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrackGainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrackGainRequest) ProtoMessage() {}
+
+func (x *UpdateTrackGainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrackGainRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTrackGainRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateTrackGainRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *UpdateTrackGainRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *UpdateTrackGainRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *UpdateTrackGainRequest) GetGainDb() float64 {
+	if x != nil {
+		return x.GainDb
+	}
+	return 0
+}
+
+type TrackGain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Identity      string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid      string                 `protobuf:"bytes,2,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	GainDb        float64                `protobuf:"fixed64,3,opt,name=gain_db,json=gainDb,proto3" json:"gain_db,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackGain) Reset() {
+	*x = TrackGain{}
+	mi := &file_roomapi_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackGain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackGain) ProtoMessage() {}
+
+func (x *TrackGain) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackGain.ProtoReflect.Descriptor instead.
+func (*TrackGain) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TrackGain) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *TrackGain) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *TrackGain) GetGainDb() float64 {
+	if x != nil {
+		return x.GainDb
+	}
+	return 0
+}
+
+type ProcessingBypassRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessingBypassRequest) Reset() {
+	*x = ProcessingBypassRequest{}
+	mi := &file_roomapi_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessingBypassRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessingBypassRequest) ProtoMessage() {}
+
+func (x *ProcessingBypassRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessingBypassRequest.ProtoReflect.Descriptor instead.
+func (*ProcessingBypassRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ProcessingBypassRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+type EnableProcessingBypassRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Room  string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// processing is re-enabled after, the configured default if unset
+	Duration *durationpb.Duration `protobuf:"bytes,2,opt,name=duration,proto3" json:"duration,omitempty"`
+	Reason   string               `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// identity of the token or its API key, set by the server
+	Actor         string `protobuf:"bytes,4,opt,name=actor,proto3" json:"actor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnableProcessingBypassRequest) Reset() {
+	*x = EnableProcessingBypassRequest{}
+	mi := &file_roomapi_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnableProcessingBypassRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnableProcessingBypassRequest) ProtoMessage() {}
+
+func (x *EnableProcessingBypassRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnableProcessingBypassRequest.ProtoReflect.Descriptor instead.
+func (*EnableProcessingBypassRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *EnableProcessingBypassRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *EnableProcessingBypassRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+func (x *EnableProcessingBypassRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *EnableProcessingBypassRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+type DisableProcessingBypassRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Room   string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	Reason string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// identity of the token or its API key, set by the server
+	Actor         string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DisableProcessingBypassRequest) Reset() {
+	*x = DisableProcessingBypassRequest{}
+	mi := &file_roomapi_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DisableProcessingBypassRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DisableProcessingBypassRequest) ProtoMessage() {}
+
+func (x *DisableProcessingBypassRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DisableProcessingBypassRequest.ProtoReflect.Descriptor instead.
+func (*DisableProcessingBypassRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *DisableProcessingBypassRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *DisableProcessingBypassRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *DisableProcessingBypassRequest) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+type ProcessingBypassEvent struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// enable, extend, disable or expire
+	Action string `protobuf:"bytes,1,opt,name=action,proto3" json:"action,omitempty"`
+	// unix time in milliseconds
+	At int64 `protobuf:"varint,2,opt,name=at,proto3" json:"at,omitempty"`
+	// identity of whoever switched the bypass, empty for expiry
+	Actor  string `protobuf:"bytes,3,opt,name=actor,proto3" json:"actor,omitempty"`
+	Reason string `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	// unix time in milliseconds processing is re-enabled automatically, for enable and extend
+	Until         int64 `protobuf:"varint,5,opt,name=until,proto3" json:"until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessingBypassEvent) Reset() {
+	*x = ProcessingBypassEvent{}
+	mi := &file_roomapi_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessingBypassEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessingBypassEvent) ProtoMessage() {}
+
+func (x *ProcessingBypassEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessingBypassEvent.ProtoReflect.Descriptor instead.
+func (*ProcessingBypassEvent) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ProcessingBypassEvent) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *ProcessingBypassEvent) GetAt() int64 {
+	if x != nil {
+		return x.At
+	}
+	return 0
+}
+
+func (x *ProcessingBypassEvent) GetActor() string {
+	if x != nil {
+		return x.Actor
+	}
+	return ""
+}
+
+func (x *ProcessingBypassEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ProcessingBypassEvent) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+type ProcessingBypassState struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Active bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	// unix time in milliseconds processing is re-enabled automatically, while active
+	Until int64 `protobuf:"varint,2,opt,name=until,proto3" json:"until,omitempty"`
+	// most recent last
+	Events        []*ProcessingBypassEvent `protobuf:"bytes,3,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ProcessingBypassState) Reset() {
+	*x = ProcessingBypassState{}
+	mi := &file_roomapi_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProcessingBypassState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProcessingBypassState) ProtoMessage() {}
+
+func (x *ProcessingBypassState) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProcessingBypassState.ProtoReflect.Descriptor instead.
+func (*ProcessingBypassState) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ProcessingBypassState) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *ProcessingBypassState) GetUntil() int64 {
+	if x != nil {
+		return x.Until
+	}
+	return 0
+}
+
+func (x *ProcessingBypassState) GetEvents() []*ProcessingBypassEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type TalkAnalyticsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TalkAnalyticsRequest) Reset() {
+	*x = TalkAnalyticsRequest{}
+	mi := &file_roomapi_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TalkAnalyticsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TalkAnalyticsRequest) ProtoMessage() {}
+
+func (x *TalkAnalyticsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TalkAnalyticsRequest.ProtoReflect.Descriptor instead.
+func (*TalkAnalyticsRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *TalkAnalyticsRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+type ParticipantTalkAnalytics struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Identity   string                 `protobuf:"bytes,1,opt,name=identity,proto3" json:"identity,omitempty"`
+	TalkTimeMs int64                  `protobuf:"varint,2,opt,name=talk_time_ms,json=talkTimeMs,proto3" json:"talk_time_ms,omitempty"`
+	// share of the talk time of all participants, 0 - 1
+	TalkShare     float64 `protobuf:"fixed64,3,opt,name=talk_share,json=talkShare,proto3" json:"talk_share,omitempty"`
+	Turns         uint32  `protobuf:"varint,4,opt,name=turns,proto3" json:"turns,omitempty"`
+	LongestTurnMs int64   `protobuf:"varint,5,opt,name=longest_turn_ms,json=longestTurnMs,proto3" json:"longest_turn_ms,omitempty"`
+	// times this participant interrupted somebody
+	Interruptions uint32 `protobuf:"varint,6,opt,name=interruptions,proto3" json:"interruptions,omitempty"`
+	// times this participant was interrupted
+	Interrupted uint32 `protobuf:"varint,7,opt,name=interrupted,proto3" json:"interrupted,omitempty"`
+	// words of final transcription segments
+	Words uint32 `protobuf:"varint,8,opt,name=words,proto3" json:"words,omitempty"`
+	// speech rate of the transcribed speech, 0 without transcription
+	WordsPerMinute float64 `protobuf:"fixed64,9,opt,name=words_per_minute,json=wordsPerMinute,proto3" json:"words_per_minute,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ParticipantTalkAnalytics) Reset() {
+	*x = ParticipantTalkAnalytics{}
+	mi := &file_roomapi_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParticipantTalkAnalytics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParticipantTalkAnalytics) ProtoMessage() {}
+
+func (x *ParticipantTalkAnalytics) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParticipantTalkAnalytics.ProtoReflect.Descriptor instead.
+func (*ParticipantTalkAnalytics) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ParticipantTalkAnalytics) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *ParticipantTalkAnalytics) GetTalkTimeMs() int64 {
+	if x != nil {
+		return x.TalkTimeMs
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetTalkShare() float64 {
+	if x != nil {
+		return x.TalkShare
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetTurns() uint32 {
+	if x != nil {
+		return x.Turns
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetLongestTurnMs() int64 {
+	if x != nil {
+		return x.LongestTurnMs
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetInterruptions() uint32 {
+	if x != nil {
+		return x.Interruptions
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetInterrupted() uint32 {
+	if x != nil {
+		return x.Interrupted
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetWords() uint32 {
+	if x != nil {
+		return x.Words
+	}
+	return 0
+}
+
+func (x *ParticipantTalkAnalytics) GetWordsPerMinute() float64 {
+	if x != nil {
+		return x.WordsPerMinute
+	}
+	return 0
+}
+
+type TalkAnalytics struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// unix time in milliseconds
+	StartedAt  int64 `protobuf:"varint,1,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	DurationMs int64 `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	TalkTimeMs int64 `protobuf:"varint,3,opt,name=talk_time_ms,json=talkTimeMs,proto3" json:"talk_time_ms,omitempty"`
+	// time more than one participant spoke
+	OverlapMs int64 `protobuf:"varint,4,opt,name=overlap_ms,json=overlapMs,proto3" json:"overlap_ms,omitempty"`
+	// time nobody spoke
+	SilenceMs     int64                       `protobuf:"varint,5,opt,name=silence_ms,json=silenceMs,proto3" json:"silence_ms,omitempty"`
+	Participants  []*ParticipantTalkAnalytics `protobuf:"bytes,6,rep,name=participants,proto3" json:"participants,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TalkAnalytics) Reset() {
+	*x = TalkAnalytics{}
+	mi := &file_roomapi_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TalkAnalytics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TalkAnalytics) ProtoMessage() {}
+
+func (x *TalkAnalytics) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TalkAnalytics.ProtoReflect.Descriptor instead.
+func (*TalkAnalytics) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *TalkAnalytics) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *TalkAnalytics) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *TalkAnalytics) GetTalkTimeMs() int64 {
+	if x != nil {
+		return x.TalkTimeMs
+	}
+	return 0
+}
+
+func (x *TalkAnalytics) GetOverlapMs() int64 {
+	if x != nil {
+		return x.OverlapMs
+	}
+	return 0
+}
+
+func (x *TalkAnalytics) GetSilenceMs() int64 {
+	if x != nil {
+		return x.SilenceMs
+	}
+	return 0
+}
+
+func (x *TalkAnalytics) GetParticipants() []*ParticipantTalkAnalytics {
+	if x != nil {
+		return x.Participants
+	}
+	return nil
+}
+
+type ListAudioSnapshotsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Room  string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// consumer the audio was delivered to, e. g. an agent
+	Identity      string `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAudioSnapshotsRequest) Reset() {
+	*x = ListAudioSnapshotsRequest{}
+	mi := &file_roomapi_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAudioSnapshotsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAudioSnapshotsRequest) ProtoMessage() {}
+
+func (x *ListAudioSnapshotsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAudioSnapshotsRequest.ProtoReflect.Descriptor instead.
+func (*ListAudioSnapshotsRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListAudioSnapshotsRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *ListAudioSnapshotsRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+type AudioSnapshotStream struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	TrackSid          string                 `protobuf:"bytes,1,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	PublisherIdentity string                 `protobuf:"bytes,2,opt,name=publisher_identity,json=publisherIdentity,proto3" json:"publisher_identity,omitempty"`
+	Mixed             bool                   `protobuf:"varint,3,opt,name=mixed,proto3" json:"mixed,omitempty"`
+	// unix time in milliseconds the last packet was delivered
+	LastDelivered int64 `protobuf:"varint,4,opt,name=last_delivered,json=lastDelivered,proto3" json:"last_delivered,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudioSnapshotStream) Reset() {
+	*x = AudioSnapshotStream{}
+	mi := &file_roomapi_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioSnapshotStream) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioSnapshotStream) ProtoMessage() {}
+
+func (x *AudioSnapshotStream) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioSnapshotStream.ProtoReflect.Descriptor instead.
+func (*AudioSnapshotStream) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *AudioSnapshotStream) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *AudioSnapshotStream) GetPublisherIdentity() string {
+	if x != nil {
+		return x.PublisherIdentity
+	}
+	return ""
+}
+
+func (x *AudioSnapshotStream) GetMixed() bool {
+	if x != nil {
+		return x.Mixed
+	}
+	return false
+}
+
+func (x *AudioSnapshotStream) GetLastDelivered() int64 {
+	if x != nil {
+		return x.LastDelivered
+	}
+	return 0
+}
+
+type ListAudioSnapshotsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Streams       []*AudioSnapshotStream `protobuf:"bytes,1,rep,name=streams,proto3" json:"streams,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListAudioSnapshotsResponse) Reset() {
+	*x = ListAudioSnapshotsResponse{}
+	mi := &file_roomapi_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListAudioSnapshotsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAudioSnapshotsResponse) ProtoMessage() {}
+
+func (x *ListAudioSnapshotsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListAudioSnapshotsResponse.ProtoReflect.Descriptor instead.
+func (*ListAudioSnapshotsResponse) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListAudioSnapshotsResponse) GetStreams() []*AudioSnapshotStream {
+	if x != nil {
+		return x.Streams
+	}
+	return nil
+}
+
+type AudioSnapshotRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Room     string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	Identity string                 `protobuf:"bytes,2,opt,name=identity,proto3" json:"identity,omitempty"`
+	TrackSid string                 `protobuf:"bytes,3,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	// audio before now, all retained audio if unset
+	Duration      *durationpb.Duration `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudioSnapshotRequest) Reset() {
+	*x = AudioSnapshotRequest{}
+	mi := &file_roomapi_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioSnapshotRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioSnapshotRequest) ProtoMessage() {}
+
+func (x *AudioSnapshotRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioSnapshotRequest.ProtoReflect.Descriptor instead.
+func (*AudioSnapshotRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *AudioSnapshotRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *AudioSnapshotRequest) GetIdentity() string {
+	if x != nil {
+		return x.Identity
+	}
+	return ""
+}
+
+func (x *AudioSnapshotRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *AudioSnapshotRequest) GetDuration() *durationpb.Duration {
+	if x != nil {
+		return x.Duration
+	}
+	return nil
+}
+
+type AudioSnapshot struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Wav           []byte                 `protobuf:"bytes,1,opt,name=wav,proto3" json:"wav,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AudioSnapshot) Reset() {
+	*x = AudioSnapshot{}
+	mi := &file_roomapi_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioSnapshot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioSnapshot) ProtoMessage() {}
+
+func (x *AudioSnapshot) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioSnapshot.ProtoReflect.Descriptor instead.
+func (*AudioSnapshot) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *AudioSnapshot) GetWav() []byte {
+	if x != nil {
+		return x.Wav
+	}
+	return nil
+}
+
+type TrackMirrorRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// room publishing the track
+	Room     string `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	TrackSid string `protobuf:"bytes,2,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	// QA room receiving the copy
+	MirrorRoom    string `protobuf:"bytes,3,opt,name=mirror_room,json=mirrorRoom,proto3" json:"mirror_room,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackMirrorRequest) Reset() {
+	*x = TrackMirrorRequest{}
+	mi := &file_roomapi_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackMirrorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackMirrorRequest) ProtoMessage() {}
+
+func (x *TrackMirrorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackMirrorRequest.ProtoReflect.Descriptor instead.
+func (*TrackMirrorRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *TrackMirrorRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *TrackMirrorRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *TrackMirrorRequest) GetMirrorRoom() string {
+	if x != nil {
+		return x.MirrorRoom
+	}
+	return ""
+}
+
+type TrackMirrorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackMirrorResponse) Reset() {
+	*x = TrackMirrorResponse{}
+	mi := &file_roomapi_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackMirrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackMirrorResponse) ProtoMessage() {}
+
+func (x *TrackMirrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackMirrorResponse.ProtoReflect.Descriptor instead.
+func (*TrackMirrorResponse) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{20}
+}
+
+type StartRealtimeBridgeRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Room     string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	TrackSid string                 `protobuf:"bytes,2,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	// override settings of the config if set
+	Model         string `protobuf:"bytes,3,opt,name=model,proto3" json:"model,omitempty"`
+	Voice         string `protobuf:"bytes,4,opt,name=voice,proto3" json:"voice,omitempty"`
+	Instructions  string `protobuf:"bytes,5,opt,name=instructions,proto3" json:"instructions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StartRealtimeBridgeRequest) Reset() {
+	*x = StartRealtimeBridgeRequest{}
+	mi := &file_roomapi_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StartRealtimeBridgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StartRealtimeBridgeRequest) ProtoMessage() {}
+
+func (x *StartRealtimeBridgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StartRealtimeBridgeRequest.ProtoReflect.Descriptor instead.
+func (*StartRealtimeBridgeRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *StartRealtimeBridgeRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *StartRealtimeBridgeRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *StartRealtimeBridgeRequest) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *StartRealtimeBridgeRequest) GetVoice() string {
+	if x != nil {
+		return x.Voice
+	}
+	return ""
+}
+
+func (x *StartRealtimeBridgeRequest) GetInstructions() string {
+	if x != nil {
+		return x.Instructions
+	}
+	return ""
+}
+
+type RealtimeBridge struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	TrackSid string                 `protobuf:"bytes,1,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	// server side track playing the audio of the model
+	OutputTrackSid string `protobuf:"bytes,2,opt,name=output_track_sid,json=outputTrackSid,proto3" json:"output_track_sid,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RealtimeBridge) Reset() {
+	*x = RealtimeBridge{}
+	mi := &file_roomapi_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RealtimeBridge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RealtimeBridge) ProtoMessage() {}
+
+func (x *RealtimeBridge) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RealtimeBridge.ProtoReflect.Descriptor instead.
+func (*RealtimeBridge) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *RealtimeBridge) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+func (x *RealtimeBridge) GetOutputTrackSid() string {
+	if x != nil {
+		return x.OutputTrackSid
+	}
+	return ""
+}
+
+type StopRealtimeBridgeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Room          string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	TrackSid      string                 `protobuf:"bytes,2,opt,name=track_sid,json=trackSid,proto3" json:"track_sid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRealtimeBridgeRequest) Reset() {
+	*x = StopRealtimeBridgeRequest{}
+	mi := &file_roomapi_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRealtimeBridgeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRealtimeBridgeRequest) ProtoMessage() {}
+
+func (x *StopRealtimeBridgeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRealtimeBridgeRequest.ProtoReflect.Descriptor instead.
+func (*StopRealtimeBridgeRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *StopRealtimeBridgeRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *StopRealtimeBridgeRequest) GetTrackSid() string {
+	if x != nil {
+		return x.TrackSid
+	}
+	return ""
+}
+
+type StopRealtimeBridgeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopRealtimeBridgeResponse) Reset() {
+	*x = StopRealtimeBridgeResponse{}
+	mi := &file_roomapi_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopRealtimeBridgeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopRealtimeBridgeResponse) ProtoMessage() {}
+
+func (x *StopRealtimeBridgeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopRealtimeBridgeResponse.ProtoReflect.Descriptor instead.
+func (*StopRealtimeBridgeResponse) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{24}
+}
+
+type SupportBundleRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Room  string                 `protobuf:"bytes,1,opt,name=room,proto3" json:"room,omitempty"`
+	// period of logs, the configured default if unset
+	Period        *durationpb.Duration `protobuf:"bytes,2,opt,name=period,proto3" json:"period,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SupportBundleRequest) Reset() {
+	*x = SupportBundleRequest{}
+	mi := &file_roomapi_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupportBundleRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupportBundleRequest) ProtoMessage() {}
+
+func (x *SupportBundleRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupportBundleRequest.ProtoReflect.Descriptor instead.
+func (*SupportBundleRequest) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SupportBundleRequest) GetRoom() string {
+	if x != nil {
+		return x.Room
+	}
+	return ""
+}
+
+func (x *SupportBundleRequest) GetPeriod() *durationpb.Duration {
+	if x != nil {
+		return x.Period
+	}
+	return nil
+}
+
+type SupportBundle struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Zip   []byte                 `protobuf:"bytes,1,opt,name=zip,proto3" json:"zip,omitempty"`
+	// suggested file name
+	FileName      string `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SupportBundle) Reset() {
+	*x = SupportBundle{}
+	mi := &file_roomapi_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SupportBundle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SupportBundle) ProtoMessage() {}
+
+func (x *SupportBundle) ProtoReflect() protoreflect.Message {
+	mi := &file_roomapi_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SupportBundle.ProtoReflect.Descriptor instead.
+func (*SupportBundle) Descriptor() ([]byte, []int) {
+	return file_roomapi_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SupportBundle) GetZip() []byte {
+	if x != nil {
+		return x.Zip
+	}
+	return nil
+}
+
+func (x *SupportBundle) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+var File_roomapi_proto protoreflect.FileDescriptor
+
+const file_roomapi_proto_rawDesc = "" +
+	"\n" +
+	"\rroomapi.proto\x12\fagentix.room\x1a\x1egoogle/protobuf/duration.proto\"f\n" +
+	"\x17TrackNoiseFilterRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x03 \x01(\tR\btrackSid\"\x86\x01\n" +
+	"\x1dUpdateTrackNoiseFilterRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x03 \x01(\tR\btrackSid\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\"e\n" +
+	"\x10TrackNoiseFilter\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x02 \x01(\tR\btrackSid\x12\x18\n" +
+	"\aenabled\x18\x03 \x01(\bR\aenabled\"_\n" +
+	"\x10TrackGainRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x03 \x01(\tR\btrackSid\"~\n" +
+	"\x16UpdateTrackGainRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x03 \x01(\tR\btrackSid\x12\x17\n" +
+	"\again_db\x18\x04 \x01(\x01R\x06gainDb\"]\n" +
+	"\tTrackGain\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x02 \x01(\tR\btrackSid\x12\x17\n" +
+	"\again_db\x18\x03 \x01(\x01R\x06gainDb\"-\n" +
+	"\x17ProcessingBypassRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\"\x98\x01\n" +
+	"\x1dEnableProcessingBypassRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x125\n" +
+	"\bduration\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\bduration\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05actor\x18\x04 \x01(\tR\x05actor\"b\n" +
+	"\x1eDisableProcessingBypassRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\"\x83\x01\n" +
+	"\x15ProcessingBypassEvent\x12\x16\n" +
+	"\x06action\x18\x01 \x01(\tR\x06action\x12\x0e\n" +
+	"\x02at\x18\x02 \x01(\x03R\x02at\x12\x14\n" +
+	"\x05actor\x18\x03 \x01(\tR\x05actor\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x14\n" +
+	"\x05until\x18\x05 \x01(\x03R\x05until\"\x82\x01\n" +
+	"\x15ProcessingBypassState\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x14\n" +
+	"\x05until\x18\x02 \x01(\x03R\x05until\x12;\n" +
+	"\x06events\x18\x03 \x03(\v2#.agentix.room.ProcessingBypassEventR\x06events\"*\n" +
+	"\x14TalkAnalyticsRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\"\xbd\x02\n" +
+	"\x18ParticipantTalkAnalytics\x12\x1a\n" +
+	"\bidentity\x18\x01 \x01(\tR\bidentity\x12 \n" +
+	"\ftalk_time_ms\x18\x02 \x01(\x03R\n" +
+	"talkTimeMs\x12\x1d\n" +
+	"\n" +
+	"talk_share\x18\x03 \x01(\x01R\ttalkShare\x12\x14\n" +
+	"\x05turns\x18\x04 \x01(\rR\x05turns\x12&\n" +
+	"\x0flongest_turn_ms\x18\x05 \x01(\x03R\rlongestTurnMs\x12$\n" +
+	"\rinterruptions\x18\x06 \x01(\rR\rinterruptions\x12 \n" +
+	"\vinterrupted\x18\a \x01(\rR\vinterrupted\x12\x14\n" +
+	"\x05words\x18\b \x01(\rR\x05words\x12(\n" +
+	"\x10words_per_minute\x18\t \x01(\x01R\x0ewordsPerMinute\"\xfb\x01\n" +
+	"\rTalkAnalytics\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x01 \x01(\x03R\tstartedAt\x12\x1f\n" +
+	"\vduration_ms\x18\x02 \x01(\x03R\n" +
+	"durationMs\x12 \n" +
+	"\ftalk_time_ms\x18\x03 \x01(\x03R\n" +
+	"talkTimeMs\x12\x1d\n" +
+	"\n" +
+	"overlap_ms\x18\x04 \x01(\x03R\toverlapMs\x12\x1d\n" +
+	"\n" +
+	"silence_ms\x18\x05 \x01(\x03R\tsilenceMs\x12J\n" +
+	"\fparticipants\x18\x06 \x03(\v2&.agentix.room.ParticipantTalkAnalyticsR\fparticipants\"K\n" +
+	"\x19ListAudioSnapshotsRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\"\x9e\x01\n" +
+	"\x13AudioSnapshotStream\x12\x1b\n" +
+	"\ttrack_sid\x18\x01 \x01(\tR\btrackSid\x12-\n" +
+	"\x12publisher_identity\x18\x02 \x01(\tR\x11publisherIdentity\x12\x14\n" +
+	"\x05mixed\x18\x03 \x01(\bR\x05mixed\x12%\n" +
+	"\x0elast_delivered\x18\x04 \x01(\x03R\rlastDelivered\"Y\n" +
+	"\x1aListAudioSnapshotsResponse\x12;\n" +
+	"\astreams\x18\x01 \x03(\v2!.agentix.room.AudioSnapshotStreamR\astreams\"\x9a\x01\n" +
+	"\x14AudioSnapshotRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1a\n" +
+	"\bidentity\x18\x02 \x01(\tR\bidentity\x12\x1b\n" +
+	"\ttrack_sid\x18\x03 \x01(\tR\btrackSid\x125\n" +
+	"\bduration\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\bduration\"!\n" +
+	"\rAudioSnapshot\x12\x10\n" +
+	"\x03wav\x18\x01 \x01(\fR\x03wav\"f\n" +
+	"\x12TrackMirrorRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1b\n" +
+	"\ttrack_sid\x18\x02 \x01(\tR\btrackSid\x12\x1f\n" +
+	"\vmirror_room\x18\x03 \x01(\tR\n" +
+	"mirrorRoom\"\x15\n" +
+	"\x13TrackMirrorResponse\"\x9d\x01\n" +
+	"\x1aStartRealtimeBridgeRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1b\n" +
+	"\ttrack_sid\x18\x02 \x01(\tR\btrackSid\x12\x14\n" +
+	"\x05model\x18\x03 \x01(\tR\x05model\x12\x14\n" +
+	"\x05voice\x18\x04 \x01(\tR\x05voice\x12\"\n" +
+	"\finstructions\x18\x05 \x01(\tR\finstructions\"W\n" +
+	"\x0eRealtimeBridge\x12\x1b\n" +
+	"\ttrack_sid\x18\x01 \x01(\tR\btrackSid\x12(\n" +
+	"\x10output_track_sid\x18\x02 \x01(\tR\x0eoutputTrackSid\"L\n" +
+	"\x19StopRealtimeBridgeRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x12\x1b\n" +
+	"\ttrack_sid\x18\x02 \x01(\tR\btrackSid\"\x1c\n" +
+	"\x1aStopRealtimeBridgeResponse\"]\n" +
+	"\x14SupportBundleRequest\x12\x12\n" +
+	"\x04room\x18\x01 \x01(\tR\x04room\x121\n" +
+	"\x06period\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\x06period\">\n" +
+	"\rSupportBundle\x12\x10\n" +
+	"\x03zip\x18\x01 \x01(\fR\x03zip\x12\x1b\n" +
+	"\tfile_name\x18\x02 \x01(\tR\bfileName2\x8b\v\n" +
+	"\vRoomService\x12\\\n" +
+	"\x13GetTrackNoiseFilter\x12%.agentix.room.TrackNoiseFilterRequest\x1a\x1e.agentix.room.TrackNoiseFilter\x12e\n" +
+	"\x16UpdateTrackNoiseFilter\x12+.agentix.room.UpdateTrackNoiseFilterRequest\x1a\x1e.agentix.room.TrackNoiseFilter\x12G\n" +
+	"\fGetTrackGain\x12\x1e.agentix.room.TrackGainRequest\x1a\x17.agentix.room.TrackGain\x12P\n" +
+	"\x0fUpdateTrackGain\x12$.agentix.room.UpdateTrackGainRequest\x1a\x17.agentix.room.TrackGain\x12a\n" +
+	"\x13GetProcessingBypass\x12%.agentix.room.ProcessingBypassRequest\x1a#.agentix.room.ProcessingBypassState\x12j\n" +
+	"\x16EnableProcessingBypass\x12+.agentix.room.EnableProcessingBypassRequest\x1a#.agentix.room.ProcessingBypassState\x12l\n" +
+	"\x17DisableProcessingBypass\x12,.agentix.room.DisableProcessingBypassRequest\x1a#.agentix.room.ProcessingBypassState\x12S\n" +
+	"\x10GetTalkAnalytics\x12\".agentix.room.TalkAnalyticsRequest\x1a\x1b.agentix.room.TalkAnalytics\x12g\n" +
+	"\x12ListAudioSnapshots\x12'.agentix.room.ListAudioSnapshotsRequest\x1a(.agentix.room.ListAudioSnapshotsResponse\x12S\n" +
+	"\x10GetAudioSnapshot\x12\".agentix.room.AudioSnapshotRequest\x1a\x1b.agentix.room.AudioSnapshot\x12W\n" +
+	"\x10StartTrackMirror\x12 .agentix.room.TrackMirrorRequest\x1a!.agentix.room.TrackMirrorResponse\x12V\n" +
+	"\x0fStopTrackMirror\x12 .agentix.room.TrackMirrorRequest\x1a!.agentix.room.TrackMirrorResponse\x12]\n" +
+	"\x13StartRealtimeBridge\x12(.agentix.room.StartRealtimeBridgeRequest\x1a\x1c.agentix.room.RealtimeBridge\x12g\n" +
+	"\x12StopRealtimeBridge\x12'.agentix.room.StopRealtimeBridgeRequest\x1a(.agentix.room.StopRealtimeBridgeResponse\x12S\n" +
+	"\x10GetSupportBundle\x12\".agentix.room.SupportBundleRequest\x1a\x1b.agentix.room.SupportBundleB/Z-github.com/livekit/livekit-server/pkg/roomapib\x06proto3"
+
+var (
+	file_roomapi_proto_rawDescOnce sync.Once
+	file_roomapi_proto_rawDescData []byte
+)
+
+func file_roomapi_proto_rawDescGZIP() []byte {
+	file_roomapi_proto_rawDescOnce.Do(func() {
+		file_roomapi_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_roomapi_proto_rawDesc), len(file_roomapi_proto_rawDesc)))
+	})
+	return file_roomapi_proto_rawDescData
+}
+
+var file_roomapi_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_roomapi_proto_goTypes = []any{
+	(*TrackNoiseFilterRequest)(nil),        // 0: agentix.room.TrackNoiseFilterRequest
+	(*UpdateTrackNoiseFilterRequest)(nil),  // 1: agentix.room.UpdateTrackNoiseFilterRequest
+	(*TrackNoiseFilter)(nil),               // 2: agentix.room.TrackNoiseFilter
+	(*TrackGainRequest)(nil),               // 3: agentix.room.TrackGainRequest
+	(*UpdateTrackGainRequest)(nil),         // 4: agentix.room.UpdateTrackGainRequest
+	(*TrackGain)(nil),                      // 5: agentix.room.TrackGain
+	(*ProcessingBypassRequest)(nil),        // 6: agentix.room.ProcessingBypassRequest
+	(*EnableProcessingBypassRequest)(nil),  // 7: agentix.room.EnableProcessingBypassRequest
+	(*DisableProcessingBypassRequest)(nil), // 8: agentix.room.DisableProcessingBypassRequest
+	(*ProcessingBypassEvent)(nil),          // 9: agentix.room.ProcessingBypassEvent
+	(*ProcessingBypassState)(nil),          // 10: agentix.room.ProcessingBypassState
+	(*TalkAnalyticsRequest)(nil),           // 11: agentix.room.TalkAnalyticsRequest
+	(*ParticipantTalkAnalytics)(nil),       // 12: agentix.room.ParticipantTalkAnalytics
+	(*TalkAnalytics)(nil),                  // 13: agentix.room.TalkAnalytics
+	(*ListAudioSnapshotsRequest)(nil),      // 14: agentix.room.ListAudioSnapshotsRequest
+	(*AudioSnapshotStream)(nil),            // 15: agentix.room.AudioSnapshotStream
+	(*ListAudioSnapshotsResponse)(nil),     // 16: agentix.room.ListAudioSnapshotsResponse
+	(*AudioSnapshotRequest)(nil),           // 17: agentix.room.AudioSnapshotRequest
+	(*AudioSnapshot)(nil),                  // 18: agentix.room.AudioSnapshot
+	(*TrackMirrorRequest)(nil),             // 19: agentix.room.TrackMirrorRequest
+	(*TrackMirrorResponse)(nil),            // 20: agentix.room.TrackMirrorResponse
+	(*StartRealtimeBridgeRequest)(nil),     // 21: agentix.room.StartRealtimeBridgeRequest
+	(*RealtimeBridge)(nil),                 // 22: agentix.room.RealtimeBridge
+	(*StopRealtimeBridgeRequest)(nil),      // 23: agentix.room.StopRealtimeBridgeRequest
+	(*StopRealtimeBridgeResponse)(nil),     // 24: agentix.room.StopRealtimeBridgeResponse
+	(*SupportBundleRequest)(nil),           // 25: agentix.room.SupportBundleRequest
+	(*SupportBundle)(nil),                  // 26: agentix.room.SupportBundle
+	(*durationpb.Duration)(nil),            // 27: google.protobuf.Duration
+}
+var file_roomapi_proto_depIdxs = []int32{
+	27, // 0: agentix.room.EnableProcessingBypassRequest.duration:type_name -> google.protobuf.Duration
+	9,  // 1: agentix.room.ProcessingBypassState.events:type_name -> agentix.room.ProcessingBypassEvent
+	12, // 2: agentix.room.TalkAnalytics.participants:type_name -> agentix.room.ParticipantTalkAnalytics
+	15, // 3: agentix.room.ListAudioSnapshotsResponse.streams:type_name -> agentix.room.AudioSnapshotStream
+	27, // 4: agentix.room.AudioSnapshotRequest.duration:type_name -> google.protobuf.Duration
+	27, // 5: agentix.room.SupportBundleRequest.period:type_name -> google.protobuf.Duration
+	0,  // 6: agentix.room.RoomService.GetTrackNoiseFilter:input_type -> agentix.room.TrackNoiseFilterRequest
+	1,  // 7: agentix.room.RoomService.UpdateTrackNoiseFilter:input_type -> agentix.room.UpdateTrackNoiseFilterRequest
+	3,  // 8: agentix.room.RoomService.GetTrackGain:input_type -> agentix.room.TrackGainRequest
+	4,  // 9: agentix.room.RoomService.UpdateTrackGain:input_type -> agentix.room.UpdateTrackGainRequest
+	6,  // 10: agentix.room.RoomService.GetProcessingBypass:input_type -> agentix.room.ProcessingBypassRequest
+	7,  // 11: agentix.room.RoomService.EnableProcessingBypass:input_type -> agentix.room.EnableProcessingBypassRequest
+	8,  // 12: agentix.room.RoomService.DisableProcessingBypass:input_type -> agentix.room.DisableProcessingBypassRequest
+	11, // 13: agentix.room.RoomService.GetTalkAnalytics:input_type -> agentix.room.TalkAnalyticsRequest
+	14, // 14: agentix.room.RoomService.ListAudioSnapshots:input_type -> agentix.room.ListAudioSnapshotsRequest
+	17, // 15: agentix.room.RoomService.GetAudioSnapshot:input_type -> agentix.room.AudioSnapshotRequest
+	19, // 16: agentix.room.RoomService.StartTrackMirror:input_type -> agentix.room.TrackMirrorRequest
+	19, // 17: agentix.room.RoomService.StopTrackMirror:input_type -> agentix.room.TrackMirrorRequest
+	21, // 18: agentix.room.RoomService.StartRealtimeBridge:input_type -> agentix.room.StartRealtimeBridgeRequest
+	23, // 19: agentix.room.RoomService.StopRealtimeBridge:input_type -> agentix.room.StopRealtimeBridgeRequest
+	25, // 20: agentix.room.RoomService.GetSupportBundle:input_type -> agentix.room.SupportBundleRequest
+	2,  // 21: agentix.room.RoomService.GetTrackNoiseFilter:output_type -> agentix.room.TrackNoiseFilter
+	2,  // 22: agentix.room.RoomService.UpdateTrackNoiseFilter:output_type -> agentix.room.TrackNoiseFilter
+	5,  // 23: agentix.room.RoomService.GetTrackGain:output_type -> agentix.room.TrackGain
+	5,  // 24: agentix.room.RoomService.UpdateTrackGain:output_type -> agentix.room.TrackGain
+	10, // 25: agentix.room.RoomService.GetProcessingBypass:output_type -> agentix.room.ProcessingBypassState
+	10, // 26: agentix.room.RoomService.EnableProcessingBypass:output_type -> agentix.room.ProcessingBypassState
+	10, // 27: agentix.room.RoomService.DisableProcessingBypass:output_type -> agentix.room.ProcessingBypassState
+	13, // 28: agentix.room.RoomService.GetTalkAnalytics:output_type -> agentix.room.TalkAnalytics
+	16, // 29: agentix.room.RoomService.ListAudioSnapshots:output_type -> agentix.room.ListAudioSnapshotsResponse
+	18, // 30: agentix.room.RoomService.GetAudioSnapshot:output_type -> agentix.room.AudioSnapshot
+	20, // 31: agentix.room.RoomService.StartTrackMirror:output_type -> agentix.room.TrackMirrorResponse
+	20, // 32: agentix.room.RoomService.StopTrackMirror:output_type -> agentix.room.TrackMirrorResponse
+	22, // 33: agentix.room.RoomService.StartRealtimeBridge:output_type -> agentix.room.RealtimeBridge
+	24, // 34: agentix.room.RoomService.StopRealtimeBridge:output_type -> agentix.room.StopRealtimeBridgeResponse
+	26, // 35: agentix.room.RoomService.GetSupportBundle:output_type -> agentix.room.SupportBundle
+	21, // [21:36] is the sub-list for method output_type
+	6,  // [6:21] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_roomapi_proto_init() }
+func file_roomapi_proto_init() {
+	if File_roomapi_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_roomapi_proto_rawDesc), len(file_roomapi_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_roomapi_proto_goTypes,
+		DependencyIndexes: file_roomapi_proto_depIdxs,
+		MessageInfos:      file_roomapi_proto_msgTypes,
+	}.Build()
+	File_roomapi_proto = out.File
+	file_roomapi_proto_goTypes = nil
+	file_roomapi_proto_depIdxs = nil
+}
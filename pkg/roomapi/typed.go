@@ -0,0 +1,36 @@
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package roomapi
+
+import (
+	"github.com/livekit/protocol/rpc"
+	"github.com/livekit/psrpc"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --twirp_out=. --twirp_opt=paths=source_relative roomapi.proto
+//go:generate protoc -I . -I $PSRPC/protoc-gen-psrpc/options --go_out=. --go_opt=paths=source_relative --psrpc_out=. --psrpc_opt=paths=source_relative room_rpc.proto
+//go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
+
+//counterfeiter:generate . TypedRoomProcessingClient
+type TypedRoomProcessingClient = RoomProcessingClient[rpc.RoomTopic]
+type TypedRoomProcessingServer = RoomProcessingServer[rpc.RoomTopic]
+
+func NewTypedRoomProcessingClient(params rpc.ClientParams) (TypedRoomProcessingClient, error) {
+	return NewRoomProcessingClient[rpc.RoomTopic](params.Args())
+}
+
+func NewTypedRoomProcessingServer(svc RoomProcessingServerImpl, bus psrpc.MessageBus, opts ...psrpc.ServerOption) (TypedRoomProcessingServer, error) {
+	return NewRoomProcessingServer[rpc.RoomTopic](svc, bus, opts...)
+}
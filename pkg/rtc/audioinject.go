@@ -0,0 +1,334 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/audioinject"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+)
+
+const (
+	// stream ID of injected tracks is the prefix followed by the track ID
+	AudioInjectionStreamIDPrefix = "agentix_inject_"
+
+	// topic of the data packets telling participants that injected audio started or finished playing
+	AudioInjectionTopic = "agentix.audio_injection"
+
+	AudioInjectionEventStarted  = "started"
+	AudioInjectionEventFinished = "finished"
+
+	audioInjectionTrackPrefix = "TR_INJ_"
+)
+
+var (
+	ErrAudioInjectionDisabled = errors.New("audio injection is disabled")
+	ErrTooManyAudioInjections = errors.New("too many injected tracks in the room")
+	ErrAudioInjectionNotFound = errors.New("injected track not found")
+)
+
+// AudioInjectionEvent tells participants about playback of an injected track
+type AudioInjectionEvent struct {
+	Event   string          `json:"event"`
+	TrackID livekit.TrackID `json:"track_id"`
+	Name    string          `json:"name,omitempty"`
+	// why playback finished, for finished
+	Reason audioinject.FinishReason `json:"reason,omitempty"`
+	// audio played, for finished
+	PlayedMs int64 `json:"played_ms,omitempty"`
+}
+
+type AudioInjectionsParams struct {
+	Config audioinject.Config
+	// denoises the audio of each injected track once as it is encoded, rather than per participant
+	NoiseFilter audio.NoiseFilterConfig
+	Logger      logger.Logger
+	OnEvent     func(event *AudioInjectionEvent)
+}
+
+// AudioInjections plays audio into the room as server side tracks every participant receives,
+// i. e. they are not published by any participant. Tracks are removed once their playback finished.
+type AudioInjections struct {
+	params AudioInjectionsParams
+
+	lock       sync.Mutex
+	injections map[livekit.TrackID]*audioInjection
+	viewers    map[livekit.ParticipantID]types.LocalParticipant
+	stopped    core.Fuse
+}
+
+func NewAudioInjections(params AudioInjectionsParams) *AudioInjections {
+	return &AudioInjections{
+		params:     params,
+		injections: make(map[livekit.TrackID]*audioInjection),
+		viewers:    make(map[livekit.ParticipantID]types.LocalParticipant),
+	}
+}
+
+// Start adds an injected track named name to all current and future participants and returns the player
+// of its audio. onFinished is called once playback finished, after the track was removed.
+func (a *AudioInjections) Start(
+	name string,
+	onStarted func(),
+	onFinished func(reason audioinject.FinishReason, played time.Duration),
+) (livekit.TrackID, *audioinject.Player, error) {
+	if a == nil {
+		return "", nil, ErrAudioInjectionDisabled
+	}
+
+	trackID := livekit.TrackID(guid.New(audioInjectionTrackPrefix))
+	trackLocal, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{
+			MimeType:  webrtc.MimeTypeOpus,
+			ClockRate: audio.OpusSampleRate,
+			Channels:  2,
+		},
+		string(trackID),
+		AudioInjectionStreamIDPrefix+string(trackID),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+
+	injection := &audioInjection{
+		trackID:    trackID,
+		name:       name,
+		trackLocal: trackLocal,
+		senders:    make(map[livekit.ParticipantID]*webrtc.RTPSender),
+	}
+
+	a.lock.Lock()
+	if a.stopped.IsBroken() {
+		a.lock.Unlock()
+		return "", nil, ErrAudioInjectionDisabled
+	}
+	if a.params.Config.MaxTracks > 0 && len(a.injections) >= a.params.Config.MaxTracks {
+		a.lock.Unlock()
+		return "", nil, ErrTooManyAudioInjections
+	}
+	var denoise func(pcm []int16)
+	var denoiser *sfuinterceptor.SourceNoiseFilter
+	if a.params.NoiseFilter.Enabled {
+		denoiser = sfuinterceptor.NewSourceNoiseFilter(a.params.NoiseFilter, a.params.Logger.WithValues("trackID", trackID))
+		denoise = func(pcm []int16) {
+			denoiser.Process(pcm)
+		}
+	}
+	// created under the lock, so that the injection is never found without its player
+	injection.player = audioinject.NewPlayer(audioinject.PlayerParams{
+		Config: a.params.Config,
+		Write: func(payload []byte, duration time.Duration) error {
+			return trackLocal.WriteSample(media.Sample{Data: payload, Duration: duration})
+		},
+		OnStarted: func() {
+			a.params.OnEvent(&AudioInjectionEvent{
+				Event:   AudioInjectionEventStarted,
+				TrackID: trackID,
+				Name:    name,
+			})
+			if onStarted != nil {
+				onStarted()
+			}
+		},
+		OnFinished: func(reason audioinject.FinishReason, played time.Duration) {
+			// writers no longer encode once playback finished
+			denoiser.Close()
+			a.remove(injection)
+			a.params.Logger.Infow("audio injection finished", "trackID", trackID, "name", name, "reason", reason, "played", played)
+			a.params.OnEvent(&AudioInjectionEvent{
+				Event:    AudioInjectionEventFinished,
+				TrackID:  trackID,
+				Name:     name,
+				Reason:   reason,
+				PlayedMs: played.Milliseconds(),
+			})
+			if onFinished != nil {
+				onFinished(reason, played)
+			}
+		},
+		Denoise: denoise,
+	})
+	a.injections[trackID] = injection
+	viewers := make([]types.LocalParticipant, 0, len(a.viewers))
+	for _, p := range a.viewers {
+		viewers = append(viewers, p)
+	}
+	a.lock.Unlock()
+
+	for _, p := range viewers {
+		a.addSender(injection, p)
+	}
+
+	a.params.Logger.Infow("injecting audio", "trackID", trackID, "name", name)
+	return trackID, injection.player, nil
+}
+
+// Cancel stops playback of an injected track at once
+func (a *AudioInjections) Cancel(trackID livekit.TrackID) error {
+	if a == nil {
+		return ErrAudioInjectionDisabled
+	}
+
+	a.lock.Lock()
+	injection, ok := a.injections[trackID]
+	a.lock.Unlock()
+
+	if !ok {
+		return ErrAudioInjectionNotFound
+	}
+	injection.player.Cancel()
+	return nil
+}
+
+func (a *AudioInjections) AddViewer(p types.LocalParticipant) {
+	if a == nil {
+		return
+	}
+
+	a.lock.Lock()
+	if a.stopped.IsBroken() {
+		a.lock.Unlock()
+		return
+	}
+	if _, ok := a.viewers[p.ID()]; ok {
+		a.lock.Unlock()
+		return
+	}
+	a.viewers[p.ID()] = p
+	injections := make([]*audioInjection, 0, len(a.injections))
+	for _, injection := range a.injections {
+		injections = append(injections, injection)
+	}
+	a.lock.Unlock()
+
+	for _, injection := range injections {
+		a.addSender(injection, p)
+	}
+}
+
+func (a *AudioInjections) RemoveViewer(p types.LocalParticipant) {
+	if a == nil {
+		return
+	}
+
+	a.lock.Lock()
+	delete(a.viewers, p.ID())
+	injections := make([]*audioInjection, 0, len(a.injections))
+	for _, injection := range a.injections {
+		injections = append(injections, injection)
+	}
+	a.lock.Unlock()
+
+	for _, injection := range injections {
+		injection.removeSender(p)
+	}
+}
+
+func (a *AudioInjections) Close() {
+	if a == nil {
+		return
+	}
+
+	a.lock.Lock()
+	a.stopped.Break()
+	injections := make([]*audioInjection, 0, len(a.injections))
+	for _, injection := range a.injections {
+		injections = append(injections, injection)
+	}
+	a.lock.Unlock()
+
+	for _, injection := range injections {
+		injection.player.Close()
+	}
+}
+
+func (a *AudioInjections) addSender(injection *audioInjection, p types.LocalParticipant) {
+	if err := injection.addSender(p); err != nil {
+		p.GetLogger().Warnw("could not add injected track", err, "trackID", injection.trackID)
+	}
+}
+
+// remove takes the track of a finished injection away from all participants
+func (a *AudioInjections) remove(injection *audioInjection) {
+	a.lock.Lock()
+	delete(a.injections, injection.trackID)
+	viewers := make([]types.LocalParticipant, 0, len(a.viewers))
+	for _, p := range a.viewers {
+		viewers = append(viewers, p)
+	}
+	a.lock.Unlock()
+
+	for _, p := range viewers {
+		injection.removeSender(p)
+	}
+}
+
+// --------------------------------------
+
+type audioInjection struct {
+	trackID    livekit.TrackID
+	name       string
+	trackLocal *webrtc.TrackLocalStaticSample
+	player     *audioinject.Player
+
+	lock    sync.Mutex
+	senders map[livekit.ParticipantID]*webrtc.RTPSender
+}
+
+func (i *audioInjection) addSender(p types.LocalParticipant) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	if _, ok := i.senders[p.ID()]; ok {
+		return nil
+	}
+	sender, _, err := p.AddTrackLocal(i.trackLocal, types.AddTrackParams{})
+	if err != nil {
+		return err
+	}
+	i.senders[p.ID()] = sender
+	p.Negotiate(false)
+	return nil
+}
+
+func (i *audioInjection) removeSender(p types.LocalParticipant) {
+	i.lock.Lock()
+	sender, ok := i.senders[p.ID()]
+	delete(i.senders, p.ID())
+	i.lock.Unlock()
+
+	if !ok || p.IsClosed() {
+		return
+	}
+	if err := p.RemoveTrackLocal(sender); err != nil {
+		p.GetLogger().Warnw("could not remove injected track", err, "trackID", i.trackID)
+		return
+	}
+	p.Negotiate(false)
+}
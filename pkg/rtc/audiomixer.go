@@ -0,0 +1,925 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+)
+
+const (
+	// participant attribute, set to "true" to receive one server mixed audio track instead of a track per publisher,
+	// or to AudioMixRoom for the room mix
+	AudioMixAttribute = "agentix.audio_mix"
+	// value of AudioMixAttribute receiving the room mix, the audio of everybody including the participant itself.
+	// Without the room mix enabled the participant receives the mix of everybody else.
+	AudioMixRoom = "room"
+	// participant attribute of a publisher, the gain in dB its audio is mixed at, from audio.MinMixGainDB,
+	// which mutes it, to audio.MaxMixGainDB
+	AudioMixGainAttribute = "agentix.audio_mix_gain"
+	// participant attribute of a mixed audio subscriber, a JSON object of publisher identities to the channel
+	// of a stereo mix ("left", "right" or "center"), key "*" for everybody else. Without it the mix is mono.
+	AudioMixChannelsAttribute = "agentix.audio_mix_channels"
+
+	AudioMixTrackID  = "TR_AUDIO_MIX"
+	AudioMixStreamID = "agentix_audio_mix"
+	RoomMixTrackID   = "TR_ROOM_MIX"
+	RoomMixStreamID  = "agentix_room_mix"
+
+	audioMixSubscriberPrefix = "MIX_"
+)
+
+var ErrRoomMixDisabled = errors.New("room mix is not enabled")
+
+// WantsAudioMix returns true if the participant opted in to mixed audio, the attribute may change at any time
+func WantsAudioMix(p types.LocalParticipant) bool {
+	value := p.GetAttributes()[AudioMixAttribute]
+	return value == "true" || value == AudioMixRoom
+}
+
+// audioMixGain returns the gain in dB the audio of the publisher is mixed at
+func audioMixGain(p types.LocalParticipant) float64 {
+	value, ok := p.GetAttributes()[AudioMixGainAttribute]
+	if !ok {
+		return 0
+	}
+
+	gain, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		p.GetLogger().Warnw("invalid audio mix gain, mixing unchanged", err, "gain", value)
+		return 0
+	}
+	return gain
+}
+
+// audioMixChannelMap returns the stereo channels the participant asked publishers to be mixed into,
+// nil for a mono mix
+func audioMixChannelMap(p types.LocalParticipant) audio.ChannelMap {
+	channels, err := audio.ParseChannelMap(p.GetAttributes()[AudioMixChannelsAttribute])
+	if err != nil {
+		p.GetLogger().Warnw("invalid audio mix channels, mixing mono", err)
+		return nil
+	}
+	return channels
+}
+
+// --------------------------------------
+
+type AudioMixerParams struct {
+	Config  audio.MixerConfig
+	Framing audio.FramingConfig
+	Logger  logger.Logger
+	// workers decoding, encoding and sending the mixes run on, decoding runs on the forwarding path and
+	// the mixes are encoded by the mix worker when nil
+	Placement     func() *placement.Slot
+	TrackPriority func(track types.MediaTrack) placement.Priority
+	// sources are denoised with it once as they are decoded, for all listeners, unless IsDenoised reports
+	// that the noise filter of their publisher denoises them already
+	NoiseFilter audio.NoiseFilterConfig
+	IsDenoised  func(track types.MediaTrack) bool
+	// sees every encoded frame sent to a listener, the payload is only valid for the duration of the call
+	OnMixedAudio func(p types.LocalParticipant, payload []byte, duration time.Duration)
+	// buffering of consumers of the room mix through the PCM tap service
+	PCMTap pcmtap.Config
+	// publishers without recording consent are left out of the room mix
+	HasConsent func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool
+	// audio synthesized for lost packets instead of the mixer padding them with silence
+	Concealment audio.ConcealmentConfig
+	// packets are reordered and paced before they are decoded
+	JitterBuffer audio.JitterBufferConfig
+	// sends the listeners of a mix who is in it, see audio.MixerConfig.Activity
+	OnActivity func(destinations []livekit.ParticipantIdentity, event *MixActivityEvent)
+}
+
+// AudioMixer decodes every published audio track of a room and sends each opted in
+// subscriber a single Opus track with the mix of everybody but itself. With the room mix enabled,
+// subscribers may instead share one track with the mix of everybody, encoded once.
+// Video and data are unaffected and keep being forwarded SFU style.
+type AudioMixer struct {
+	params AudioMixerParams
+	mixer  *audio.Mixer
+	// nil unless the room mix is enabled
+	roomMix *audioRoomMix
+
+	lock      sync.RWMutex
+	taps      map[livekit.TrackID]*audioMixTap
+	listeners map[livekit.ParticipantID]*audioMixListener
+
+	numListeners atomic.Int32
+	stopped      core.Fuse
+}
+
+func NewAudioMixer(params AudioMixerParams) (*AudioMixer, error) {
+	m := &AudioMixer{
+		params:    params,
+		mixer:     audio.NewMixer(params.Framing.MixerParams(params.Config)),
+		taps:      make(map[livekit.TrackID]*audioMixTap),
+		listeners: make(map[livekit.ParticipantID]*audioMixListener),
+	}
+	if params.Config.RoomMix.Enabled {
+		roomMix, err := newAudioRoomMix(params)
+		if err != nil {
+			return nil, err
+		}
+		m.roomMix = roomMix
+	}
+
+	go m.mixWorker()
+	return m, nil
+}
+
+func (m *AudioMixer) Stop() {
+	if m == nil {
+		return
+	}
+
+	m.stopped.Break()
+
+	m.lock.Lock()
+	taps := m.taps
+	m.taps = make(map[livekit.TrackID]*audioMixTap)
+	for _, l := range m.listeners {
+		l.releaseEncoder()
+	}
+	m.lock.Unlock()
+
+	m.roomMix.close()
+	for _, tap := range taps {
+		tap.close()
+	}
+}
+
+// WantsMix returns true if the participant is to receive mixed audio, because it opted in or,
+// with the room mix given to recorders, it is a recorder
+func (m *AudioMixer) WantsMix(p types.LocalParticipant) bool {
+	if m == nil {
+		return false
+	}
+	return WantsAudioMix(p) || m.wantsRoomMix(p)
+}
+
+// wantsRoomMix returns true if the participant is to receive the room mix rather than a mix of its own
+func (m *AudioMixer) wantsRoomMix(p types.LocalParticipant) bool {
+	if m.roomMix == nil {
+		return false
+	}
+	if m.params.Config.RoomMix.Recorders && p.IsRecorder() {
+		return true
+	}
+	return p.GetAttributes()[AudioMixAttribute] == AudioMixRoom
+}
+
+func (m *AudioMixer) IsListener(participantID livekit.ParticipantID) bool {
+	if m == nil {
+		return false
+	}
+
+	m.lock.RLock()
+	_, ok := m.listeners[participantID]
+	m.lock.RUnlock()
+
+	return ok || m.roomMix.isListener(participantID)
+}
+
+// SubscribeRoomMix returns a consumer of the PCM of the room mix at sampleRate, in frames of the profile
+func (m *AudioMixer) SubscribeRoomMix(profile pcmtap.Profile, sampleRate int) (*pcmtap.Subscriber, error) {
+	if m == nil || m.roomMix == nil {
+		return nil, ErrRoomMixDisabled
+	}
+	return m.roomMix.stream.SubscribeProfile(profile, sampleRate)
+}
+
+// AddTrack starts mixing a published audio track, other track types are ignored
+func (m *AudioMixer) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if m == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		m.params.Logger.Debugw("no opus receiver, not mixing track", "trackID", track.ID())
+		return
+	}
+
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		m.params.Logger.Warnw("could not create decoder, not mixing track", err, "trackID", track.ID())
+		return
+	}
+
+	tap := &audioMixTap{
+		mixer:             m,
+		publisherID:       track.PublisherID(),
+		publisherIdentity: track.PublisherIdentity(),
+		decoder:           decoder,
+		concealer:         audio.NewLossConcealer(m.params.Concealment),
+		pcm:               make([]int16, audio.OpusMaxFrameSize),
+	}
+	if m.params.NoiseFilter.Enabled {
+		tap.denoiser = sfuinterceptor.NewSourceNoiseFilter(m.params.NoiseFilter, m.params.Logger.WithValues("trackID", track.ID()))
+		tap.isDenoised = func() bool {
+			return m.params.IsDenoised != nil && m.params.IsDenoised(track)
+		}
+	}
+	tap.receiverTap = newReceiverTap(audioMixSubscriberPrefix, track.ID(), receiver, tap.onPacket)
+	tap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(1))
+	tap.bufferJitter(m.params.JitterBuffer, audio.OpusSampleRate)
+	if m.params.Placement != nil {
+		tap.schedule(m.params.Placement(), func() placement.Priority { return m.params.TrackPriority(track) })
+	}
+
+	m.lock.Lock()
+	if m.stopped.IsBroken() {
+		m.lock.Unlock()
+		return
+	}
+	if _, ok := m.taps[track.ID()]; ok {
+		m.lock.Unlock()
+		return
+	}
+	m.taps[track.ID()] = tap
+	m.lock.Unlock()
+
+	m.mixer.AddSource(string(track.ID()))
+	m.mixer.SetGain(string(track.ID()), audioMixGain(publisher))
+	tap.recordable.Store(m.isRecordable(publisher))
+	if err := tap.start(); err != nil {
+		m.params.Logger.Warnw("could not tap receiver", err, "trackID", track.ID())
+		m.RemoveTrack(track.ID())
+	}
+}
+
+func (m *AudioMixer) RemoveTrack(trackID livekit.TrackID) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	tap, ok := m.taps[trackID]
+	delete(m.taps, trackID)
+	m.lock.Unlock()
+
+	if ok {
+		tap.close()
+	}
+}
+
+// UpdatePublisher applies the mix gain and recording consent of the participant to its published tracks
+func (m *AudioMixer) UpdatePublisher(p types.LocalParticipant) {
+	if m == nil {
+		return
+	}
+
+	gain := audioMixGain(p)
+	recordable := m.isRecordable(p)
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for trackID, tap := range m.taps {
+		if tap.publisherID != p.ID() {
+			continue
+		}
+		m.mixer.SetGain(string(trackID), gain)
+		tap.recordable.Store(recordable)
+	}
+}
+
+func (m *AudioMixer) isRecordable(p types.LocalParticipant) bool {
+	return m.params.HasConsent == nil || m.params.HasConsent(p.Identity(), ConsentRecording)
+}
+
+// AddListener adds the mixed audio track to the subscriber peer connection of the participant,
+// the room mix if the participant wants it
+func (m *AudioMixer) AddListener(p types.LocalParticipant) error {
+	if m == nil {
+		return nil
+	}
+	if m.wantsRoomMix(p) {
+		if m.stopped.IsBroken() {
+			return nil
+		}
+		return m.roomMix.addListener(p)
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.stopped.IsBroken() {
+		return nil
+	}
+	if _, ok := m.listeners[p.ID()]; ok {
+		return nil
+	}
+
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return err
+	}
+
+	trackLocal, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{
+			MimeType:  webrtc.MimeTypeOpus,
+			ClockRate: audio.OpusSampleRate,
+			Channels:  2,
+		},
+		AudioMixTrackID,
+		AudioMixStreamID,
+	)
+	if err != nil {
+		return err
+	}
+
+	sender, _, err := p.AddTrackLocal(trackLocal, types.AddTrackParams{})
+	if err != nil {
+		return err
+	}
+
+	listener := &audioMixListener{
+		participant:     p,
+		onWrite:         m.params.OnMixedAudio,
+		trackLocal:      trackLocal,
+		sender:          sender,
+		encoder:         audio.DefaultEncoderRegistry.Track(encoder, audio.EncoderPriorityMixed),
+		encoderChannels: 1,
+		duration:        m.params.Framing.Duration(),
+		pcm:             make([]int16, 2*m.params.Framing.FrameSize()),
+		payload:         make([]byte, audio.OpusMaxPacketSize),
+		activity:        newMixActivityTracker(m.params),
+	}
+	listener.setChannelMap(audioMixChannelMap(p))
+	m.listeners[p.ID()] = listener
+	m.numListeners.Store(int32(len(m.listeners)))
+
+	p.Negotiate(false)
+	p.GetLogger().Infow("receiving mixed audio")
+	return nil
+}
+
+// UpdateListener switches the participant between its own mix and the room mix when its wish changed,
+// and applies the stereo channels it asked for
+func (m *AudioMixer) UpdateListener(p types.LocalParticipant) error {
+	if m == nil {
+		return nil
+	}
+
+	if m.wantsRoomMix(p) != m.roomMix.isListener(p.ID()) {
+		m.RemoveListener(p)
+		return m.AddListener(p)
+	}
+	m.UpdateChannelMap(p)
+	return nil
+}
+
+// UpdateChannelMap applies the stereo channels the listener asked for, see AudioMixChannelsAttribute
+func (m *AudioMixer) UpdateChannelMap(p types.LocalParticipant) {
+	if m == nil {
+		return
+	}
+
+	m.lock.RLock()
+	listener, ok := m.listeners[p.ID()]
+	m.lock.RUnlock()
+
+	if ok {
+		listener.setChannelMap(audioMixChannelMap(p))
+	}
+}
+
+func (m *AudioMixer) RemoveListener(p types.LocalParticipant) {
+	if m == nil {
+		return
+	}
+	if m.roomMix.removeListener(p) {
+		return
+	}
+
+	m.lock.Lock()
+	listener, ok := m.listeners[p.ID()]
+	delete(m.listeners, p.ID())
+	m.numListeners.Store(int32(len(m.listeners)))
+	m.lock.Unlock()
+
+	if !ok {
+		return
+	}
+	listener.releaseEncoder()
+	if p.IsClosed() {
+		return
+	}
+
+	if err := p.RemoveTrackLocal(listener.sender); err != nil {
+		p.GetLogger().Warnw("could not remove mixed audio track", err)
+		return
+	}
+	p.Negotiate(false)
+	p.GetLogger().Infow("stopped receiving mixed audio")
+}
+
+func (m *AudioMixer) hasListeners() bool {
+	return m.numListeners.Load() > 0 || m.roomMix.isActive()
+}
+
+func (m *AudioMixer) mixWorker() {
+	ticker := time.NewTicker(m.params.Framing.Duration())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			// always tick so that sources do not accumulate stale audio
+			frame := m.mixer.Tick()
+			if !m.hasListeners() {
+				continue
+			}
+
+			m.lock.RLock()
+			listeners := make([]*audioMixListener, 0, len(m.listeners))
+			for _, l := range m.listeners {
+				listeners = append(listeners, l)
+			}
+			publishers := make(map[string]audioMixPublisher, len(m.taps))
+			for trackID, tap := range m.taps {
+				publishers[string(trackID)] = audioMixPublisher{
+					id:         tap.publisherID,
+					identity:   tap.publisherIdentity,
+					recordable: tap.recordable.Load(),
+				}
+			}
+			m.lock.RUnlock()
+
+			// shared by the activity of all mixes, nil unless activity is sent
+			var energies map[string]float64
+			if m.params.Config.Activity.Enabled && m.params.OnActivity != nil {
+				energies = frame.Energies()
+			}
+
+			tasks := make([]func(), 0, len(listeners)+1)
+			for _, l := range listeners {
+				tasks = append(tasks, func() {
+					l.write(frame, publishers)
+					l.observeActivity(energies, publishers, m.params.OnActivity)
+				})
+			}
+			tasks = append(tasks, func() {
+				if m.roomMix.write(frame, publishers) {
+					m.roomMix.observeActivity(energies, publishers, m.params.OnActivity)
+				}
+			})
+			m.fanOut(tasks)
+		}
+	}
+}
+
+// fanOut runs the encoding and sending of the mixes of a frame on the workers of the room's slot and waits
+// for them, so that the frames of each mix go out in order
+func (m *AudioMixer) fanOut(tasks []func()) {
+	var slot *placement.Slot
+	if m.params.Placement != nil {
+		slot = m.params.Placement()
+	}
+
+	done := make(chan struct{}, len(tasks))
+	for _, task := range tasks {
+		slot.Submit(func() {
+			task()
+			done <- struct{}{}
+		})
+	}
+	for range tasks {
+		select {
+		case <-done:
+		case <-m.stopped.Watch():
+			// a stopped placer drops the tasks it still queued
+			return
+		}
+	}
+}
+
+// newMixActivityTracker returns nil unless the activity of mixes is sent to their listeners
+func newMixActivityTracker(params AudioMixerParams) *audio.MixActivityTracker {
+	if !params.Config.Activity.Enabled || params.OnActivity == nil {
+		return nil
+	}
+	return audio.NewMixActivityTracker(params.Config.Activity, params.Framing.Duration())
+}
+
+// --------------------------------------
+
+type audioMixPublisher struct {
+	id       livekit.ParticipantID
+	identity livekit.ParticipantIdentity
+	// consented to recording, part of the room mix
+	recordable bool
+}
+
+type audioMixListener struct {
+	participant types.LocalParticipant
+	trackLocal  *webrtc.TrackLocalStaticSample
+	sender      *webrtc.RTPSender
+	onWrite     func(p types.LocalParticipant, payload []byte, duration time.Duration)
+
+	lock sync.Mutex
+	// nil for a mono mix
+	channelMap audio.ChannelMap
+	// mono or stereo following the channel map, nil once released
+	encoder         audio.OpusEncoder
+	encoderChannels int
+
+	// frames are encoded at the pipeline frame duration, Opus supports both 10 and 20 ms natively
+	duration time.Duration
+	// room for a stereo frame
+	pcm     []int16
+	payload []byte
+
+	// only used by the mixing of one frame at a time, nil unless activity is sent
+	activity *audio.MixActivityTracker
+}
+
+func (l *audioMixListener) setChannelMap(channelMap audio.ChannelMap) {
+	l.lock.Lock()
+	changed := !maps.Equal(channelMap, l.channelMap)
+	l.channelMap = channelMap
+	l.lock.Unlock()
+
+	if changed {
+		l.participant.GetLogger().Infow("audio mix channels changed", "channels", channelMap)
+	}
+}
+
+func (l *audioMixListener) releaseEncoder() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	audio.DefaultEncoderRegistry.Untrack(l.encoder)
+	l.encoder = nil
+}
+
+// ensureEncoderLocked switches the encoder to the number of channels of the mix.
+// Returns false if there is no encoder to write with. Must be called with the lock held.
+func (l *audioMixListener) ensureEncoderLocked(channels int) bool {
+	if l.encoder == nil {
+		return false
+	}
+	if l.encoderChannels == channels {
+		return true
+	}
+
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, channels)
+	if err != nil {
+		l.participant.GetLogger().Warnw("could not create audio mix encoder", err, "channels", channels)
+		return false
+	}
+	audio.DefaultEncoderRegistry.Untrack(l.encoder)
+	l.encoder = audio.DefaultEncoderRegistry.Track(encoder, audio.EncoderPriorityMixed)
+	l.encoderChannels = channels
+	return true
+}
+
+func (l *audioMixListener) write(frame *audio.MixedFrame, publishers map[string]audioMixPublisher) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	participantID := l.participant.ID()
+	exclude := func(id string) bool {
+		return publishers[id].id == participantID
+	}
+
+	var pcm []int16
+	if l.channelMap != nil {
+		if !l.ensureEncoderLocked(2) {
+			return
+		}
+		pcm = l.pcm
+		frame.MixStereo(exclude, func(id string) audio.MixChannel {
+			return l.channelMap.Channel(string(publishers[id].identity))
+		}, pcm)
+	} else {
+		if !l.ensureEncoderLocked(1) {
+			return
+		}
+		pcm = l.pcm[:len(l.pcm)/2]
+		frame.MixExcluding(exclude, pcm)
+	}
+
+	n, err := l.encoder.Encode(pcm, l.payload)
+	if err != nil {
+		l.participant.GetLogger().Debugw("could not encode mixed audio", "error", err)
+		return
+	}
+
+	_ = l.trackLocal.WriteSample(media.Sample{Data: l.payload[:n], Duration: l.duration})
+	if l.onWrite != nil {
+		l.onWrite(l.participant, l.payload[:n], l.duration)
+	}
+}
+
+// observeActivity accounts the frame to the activity of the mix of the listener,
+// and sends the listener the activity once a report is complete
+func (l *audioMixListener) observeActivity(
+	energies map[string]float64,
+	publishers map[string]audioMixPublisher,
+	onActivity func(destinations []livekit.ParticipantIdentity, event *MixActivityEvent),
+) {
+	if l.activity == nil {
+		return
+	}
+
+	participantID := l.participant.ID()
+	report := l.activity.Observe(energies, func(id string) bool {
+		return publishers[id].id != participantID
+	})
+	if report == nil {
+		return
+	}
+	onActivity(
+		[]livekit.ParticipantIdentity{l.participant.Identity()},
+		newMixActivityEvent(AudioMixTrackID, report, publishers, time.Now()),
+	)
+}
+
+// --------------------------------------
+
+// audioRoomMix is the mix of everybody who consented to recording, encoded once and shared by
+// its listeners. Its PCM is also served to consumers of the PCM tap service.
+type audioRoomMix struct {
+	logger     logger.Logger
+	trackLocal *webrtc.TrackLocalStaticSample
+	stream     *pcmtap.Stream
+
+	lock       sync.Mutex
+	senders    map[livekit.ParticipantID]*webrtc.RTPSender
+	identities map[livekit.ParticipantID]livekit.ParticipantIdentity
+	// nil once closed
+	encoder audio.OpusEncoder
+
+	numSenders atomic.Int32
+	duration   time.Duration
+	timestamp  time.Duration
+	pcm        []int16
+	payload    []byte
+
+	// only used by the mixing of one frame at a time, nil unless activity is sent
+	activity *audio.MixActivityTracker
+}
+
+func newAudioRoomMix(params AudioMixerParams) (*audioRoomMix, error) {
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	trackLocal, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{
+			MimeType:  webrtc.MimeTypeOpus,
+			ClockRate: audio.OpusSampleRate,
+			Channels:  2,
+		},
+		RoomMixTrackID,
+		RoomMixStreamID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &audioRoomMix{
+		logger:     params.Logger,
+		trackLocal: trackLocal,
+		stream:     pcmtap.NewStream(params.PCMTap, nil),
+		senders:    make(map[livekit.ParticipantID]*webrtc.RTPSender),
+		identities: make(map[livekit.ParticipantID]livekit.ParticipantIdentity),
+		encoder:    audio.DefaultEncoderRegistry.Track(encoder, audio.EncoderPriorityMixed),
+		duration:   params.Framing.Duration(),
+		pcm:        make([]int16, params.Framing.FrameSize()),
+		payload:    make([]byte, audio.OpusMaxPacketSize),
+		activity:   newMixActivityTracker(params),
+	}, nil
+}
+
+func (r *audioRoomMix) close() {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	audio.DefaultEncoderRegistry.Untrack(r.encoder)
+	r.encoder = nil
+	r.lock.Unlock()
+
+	r.stream.Close()
+}
+
+func (r *audioRoomMix) isListener(participantID livekit.ParticipantID) bool {
+	if r == nil {
+		return false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, ok := r.senders[participantID]
+	return ok
+}
+
+// isActive returns true if anybody receives the room mix, over WebRTC or the PCM tap service
+func (r *audioRoomMix) isActive() bool {
+	if r == nil {
+		return false
+	}
+	return r.numSenders.Load() > 0 || r.stream.NumSubscribers() > 0
+}
+
+func (r *audioRoomMix) addListener(p types.LocalParticipant) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if _, ok := r.senders[p.ID()]; ok {
+		return nil
+	}
+
+	sender, _, err := p.AddTrackLocal(r.trackLocal, types.AddTrackParams{})
+	if err != nil {
+		return err
+	}
+	r.senders[p.ID()] = sender
+	r.identities[p.ID()] = p.Identity()
+	r.numSenders.Store(int32(len(r.senders)))
+
+	p.Negotiate(false)
+	p.GetLogger().Infow("receiving room mix")
+	return nil
+}
+
+// removeListener returns false if the participant was not a listener of the room mix
+func (r *audioRoomMix) removeListener(p types.LocalParticipant) bool {
+	if r == nil {
+		return false
+	}
+
+	r.lock.Lock()
+	sender, ok := r.senders[p.ID()]
+	delete(r.senders, p.ID())
+	delete(r.identities, p.ID())
+	r.numSenders.Store(int32(len(r.senders)))
+	r.lock.Unlock()
+
+	if !ok {
+		return false
+	}
+	if p.IsClosed() {
+		return true
+	}
+
+	if err := p.RemoveTrackLocal(sender); err != nil {
+		p.GetLogger().Warnw("could not remove room mix track", err)
+		return true
+	}
+	p.Negotiate(false)
+	p.GetLogger().Infow("stopped receiving room mix")
+	return true
+}
+
+// write returns true if the frame was written, i. e. the timeline of the room mix advanced
+func (r *audioRoomMix) write(frame *audio.MixedFrame, publishers map[string]audioMixPublisher) bool {
+	if !r.isActive() {
+		return false
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.encoder == nil {
+		return false
+	}
+
+	frame.MixExcluding(func(id string) bool {
+		return !publishers[id].recordable
+	}, r.pcm)
+	r.stream.Write(r.pcm, r.timestamp)
+	r.timestamp += r.duration
+
+	if len(r.senders) == 0 {
+		return true
+	}
+	n, err := r.encoder.Encode(r.pcm, r.payload)
+	if err != nil {
+		r.logger.Debugw("could not encode room mix", "error", err)
+		return true
+	}
+	_ = r.trackLocal.WriteSample(media.Sample{Data: r.payload[:n], Duration: r.duration})
+	return true
+}
+
+// observeActivity accounts a written frame to the activity of the room mix, frames are observed
+// as they are written so that the activity shares the timeline of the PCM tap stream
+func (r *audioRoomMix) observeActivity(
+	energies map[string]float64,
+	publishers map[string]audioMixPublisher,
+	onActivity func(destinations []livekit.ParticipantIdentity, event *MixActivityEvent),
+) {
+	if r.activity == nil {
+		return
+	}
+
+	report := r.activity.Observe(energies, func(id string) bool {
+		return publishers[id].recordable
+	})
+	if report == nil {
+		return
+	}
+
+	r.lock.Lock()
+	destinations := slices.Collect(maps.Values(r.identities))
+	r.lock.Unlock()
+
+	if len(destinations) != 0 {
+		onActivity(destinations, newMixActivityEvent(RoomMixTrackID, report, publishers, time.Now()))
+	}
+}
+
+// --------------------------------------
+
+// audioMixTap decodes the packets of a published track into the mixer
+type audioMixTap struct {
+	*receiverTap
+
+	mixer             *AudioMixer
+	publisherID       livekit.ParticipantID
+	publisherIdentity livekit.ParticipantIdentity
+	recordable        atomic.Bool
+	decoder           audio.OpusDecoder
+	// nil unless concealment is enabled
+	concealer *audio.LossConcealer
+	pcm       []int16
+	// nil unless sources are denoised
+	denoiser   *sfuinterceptor.SourceNoiseFilter
+	isDenoised func() bool
+}
+
+func (t *audioMixTap) close() {
+	t.stop()
+	t.mixer.mixer.RemoveSource(string(t.trackID))
+	t.denoiser.Close()
+}
+
+func (t *audioMixTap) onPacket(p *buffer.ExtPacket) {
+	if !t.mixer.hasListeners() {
+		// not decoded, the gap to the next packet decoded is no loss
+		t.concealer.Reset()
+		return
+	}
+
+	if n := concealLoss(t.concealer, t.decoder, 1, "mixer", p, t.pcm); n > 0 {
+		t.push(t.pcm[:n])
+	}
+	// packets may carry any Opus frame duration, the mixer queue repacketizes
+	// the decoded samples into frames of the pipeline frame duration
+	n, err := t.decoder.Decode(p.Packet.Payload, t.pcm)
+	if err != nil {
+		// a corrupt packet leaves a gap, the mixer pads it with silence
+		return
+	}
+	t.push(t.pcm[:n])
+}
+
+func (t *audioMixTap) push(pcm []int16) {
+	if t.denoiser != nil && !t.isDenoised() {
+		t.denoiser.Process(pcm)
+	}
+	t.mixer.mixer.Push(string(t.trackID), pcm)
+}
@@ -0,0 +1,49 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+func TestAudioMixAttributes(t *testing.T) {
+	p := &typesfakes.FakeLocalParticipant{}
+	require.False(t, WantsAudioMix(p))
+	require.Zero(t, audioMixGain(p))
+	require.Nil(t, audioMixChannelMap(p))
+
+	// attributes set after joining take effect
+	p.GetAttributesReturns(map[string]string{
+		AudioMixAttribute:         "true",
+		AudioMixGainAttribute:     "-6",
+		AudioMixChannelsAttribute: `{"interpreter":"left","*":"right"}`,
+	})
+	require.True(t, WantsAudioMix(p))
+	require.Equal(t, -6.0, audioMixGain(p))
+	require.Equal(t, audio.ChannelMap{"interpreter": audio.MixChannelLeft, "*": audio.MixChannelRight}, audioMixChannelMap(p))
+
+	p.GetAttributesReturns(map[string]string{AudioMixAttribute: AudioMixRoom})
+	require.True(t, WantsAudioMix(p))
+	require.True(t, (&AudioMixer{roomMix: &audioRoomMix{}}).wantsRoomMix(p))
+
+	p.GetAttributesReturns(nil)
+	require.False(t, WantsAudioMix(p))
+}
@@ -0,0 +1,340 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/rtp"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+)
+
+const (
+	audioSnapshotSyncInterval = time.Second
+	audioSnapshotRateWindow   = time.Minute
+)
+
+var (
+	ErrAudioSnapshotNotFound    = errors.New("no audio retained for consumer track")
+	ErrAudioSnapshotRateLimited = errors.New("audio snapshot rate limit exceeded")
+)
+
+type AudioSnapshotStream struct {
+	TrackID           livekit.TrackID             `json:"track_id"`
+	PublisherIdentity livekit.ParticipantIdentity `json:"publisher_identity,omitempty"`
+	Mixed             bool                        `json:"mixed,omitempty"`
+	// unix time in milliseconds the last packet was delivered
+	LastDeliveredMs int64 `json:"last_delivered_ms"`
+}
+
+type AudioSnapshotsParams struct {
+	Config          audio.SnapshotConfig
+	Logger          logger.Logger
+	GetParticipants func() []types.LocalParticipant
+	// audio of publishers without recording consent is not retained,
+	// nor is mixed audio while any of them publishes audio
+	HasConsent func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool
+}
+
+type audioSnapshotKey struct {
+	consumer livekit.ParticipantIdentity
+	trackID  livekit.TrackID
+}
+
+type audioSnapshotStream struct {
+	publisher livekit.ParticipantIdentity
+	downTrack *sfu.DownTrack
+	buffer    *audio.SnapshotBuffer
+
+	// mixed audio carries no RTP timestamps, they are derived from the frame durations
+	mixed     bool
+	timestamp uint32
+}
+
+// AudioSnapshots retains the last seconds of the Opus audio delivered to the consumers of a room,
+// i. e. the audio tracks agents, or all subscribers, are sent and the mixed audio of mix listeners,
+// so that what a consumer heard can be reconstructed, e. g. to debug "the agent misheard me" reports.
+// Subscriptions negotiated as RED are not covered.
+type AudioSnapshots struct {
+	params AudioSnapshotsParams
+
+	lock     sync.Mutex
+	streams  map[audioSnapshotKey]*audioSnapshotStream
+	requests []time.Time
+	stopped  core.Fuse
+
+	// false while an audio publisher did not consent to recording
+	mixedConsent atomic.Bool
+}
+
+func NewAudioSnapshots(params AudioSnapshotsParams) *AudioSnapshots {
+	s := &AudioSnapshots{
+		params:  params,
+		streams: make(map[audioSnapshotKey]*audioSnapshotStream),
+	}
+	s.mixedConsent.Store(params.HasConsent == nil)
+	go s.worker()
+	return s
+}
+
+func (s *AudioSnapshots) Stop() {
+	if s == nil {
+		return
+	}
+
+	s.stopped.Break()
+
+	s.lock.Lock()
+	streams := s.streams
+	s.streams = make(map[audioSnapshotKey]*audioSnapshotStream)
+	s.lock.Unlock()
+
+	for _, stream := range streams {
+		if stream.downTrack != nil {
+			stream.downTrack.SetPacketObserver(nil)
+		}
+	}
+}
+
+func (s *AudioSnapshots) isConsumer(p types.LocalParticipant) bool {
+	return s.params.Config.AllSubscribers || p.IsAgent()
+}
+
+func (s *AudioSnapshots) hasConsent(publisher livekit.ParticipantIdentity) bool {
+	return s.params.HasConsent == nil || s.params.HasConsent(publisher, ConsentRecording)
+}
+
+// AddMixedAudio retains a frame of the mixed audio sent to a listener
+func (s *AudioSnapshots) AddMixedAudio(p types.LocalParticipant, payload []byte, duration time.Duration) {
+	if s == nil || !s.isConsumer(p) || !s.mixedConsent.Load() {
+		return
+	}
+
+	key := audioSnapshotKey{consumer: p.Identity(), trackID: AudioMixTrackID}
+	s.lock.Lock()
+	stream, ok := s.streams[key]
+	if !ok {
+		if s.stopped.IsBroken() {
+			s.lock.Unlock()
+			return
+		}
+		stream = &audioSnapshotStream{
+			buffer: audio.NewSnapshotBuffer(s.params.Config.Retention),
+			mixed:  true,
+		}
+		s.streams[key] = stream
+	}
+	timestamp := stream.timestamp
+	stream.timestamp += uint32(duration * audio.OpusSampleRate / time.Second)
+	s.lock.Unlock()
+
+	stream.buffer.Push(time.Now(), timestamp, payload)
+}
+
+func (s *AudioSnapshots) worker() {
+	ticker := time.NewTicker(audioSnapshotSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			s.sync()
+		}
+	}
+}
+
+// sync starts observing new audio subscriptions of the consumers and forgets streams
+// that have not delivered anything within the retention period
+func (s *AudioSnapshots) sync() {
+	type subscription struct {
+		key       audioSnapshotKey
+		publisher livekit.ParticipantIdentity
+		downTrack *sfu.DownTrack
+	}
+	var subscriptions []subscription
+	participants := s.params.GetParticipants()
+	mixedConsent := true
+	for _, p := range participants {
+		if s.hasConsent(p.Identity()) {
+			continue
+		}
+		for _, track := range p.GetPublishedTracks() {
+			if track.Kind() == livekit.TrackType_AUDIO {
+				mixedConsent = false
+			}
+		}
+	}
+	s.mixedConsent.Store(mixedConsent)
+
+	for _, p := range participants {
+		if !s.isConsumer(p) {
+			continue
+		}
+		for _, st := range p.GetSubscribedTracks() {
+			dt := st.DownTrack()
+			if dt == nil || st.MediaTrack().Kind() != livekit.TrackType_AUDIO || dt.Mime() != mime.MimeTypeOpus {
+				continue
+			}
+			if !s.hasConsent(st.PublisherIdentity()) {
+				continue
+			}
+			subscriptions = append(subscriptions, subscription{
+				key:       audioSnapshotKey{consumer: p.Identity(), trackID: st.ID()},
+				publisher: st.PublisherIdentity(),
+				downTrack: dt,
+			})
+		}
+	}
+
+	now := time.Now()
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stopped.IsBroken() {
+		return
+	}
+	for _, sub := range subscriptions {
+		stream, ok := s.streams[sub.key]
+		if ok && stream.downTrack == sub.downTrack {
+			continue
+		}
+		if !ok {
+			stream = &audioSnapshotStream{
+				buffer: audio.NewSnapshotBuffer(s.params.Config.Retention),
+			}
+			s.streams[sub.key] = stream
+		}
+		// a resubscription keeps the history of the previous down track
+		stream.publisher = sub.publisher
+		stream.downTrack = sub.downTrack
+		buffer := stream.buffer
+		sub.downTrack.SetPacketObserver(func(hdr *rtp.Header, payload []byte) {
+			buffer.Push(time.Now(), hdr.Timestamp, payload)
+		})
+	}
+
+	for key, stream := range s.streams {
+		// consent withdrawn, or the mix now carries audio of a publisher that did not consent
+		if (stream.mixed && !mixedConsent) || (!stream.mixed && !s.hasConsent(stream.publisher)) {
+			if stream.downTrack != nil {
+				stream.downTrack.SetPacketObserver(nil)
+			}
+			delete(s.streams, key)
+			continue
+		}
+		if last := stream.buffer.LastArrival(); now.Sub(last) <= s.params.Config.Retention {
+			continue
+		}
+		if slices.ContainsFunc(subscriptions, func(sub subscription) bool { return sub.key == key }) {
+			continue
+		}
+		delete(s.streams, key)
+	}
+}
+
+// Streams returns the audio streams retained for the consumer
+func (s *AudioSnapshots) Streams(consumer livekit.ParticipantIdentity) []AudioSnapshotStream {
+	if s == nil {
+		return nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	streams := make([]AudioSnapshotStream, 0)
+	for key, stream := range s.streams {
+		if key.consumer != consumer {
+			continue
+		}
+		streams = append(streams, AudioSnapshotStream{
+			TrackID:           key.trackID,
+			PublisherIdentity: stream.publisher,
+			Mixed:             stream.mixed,
+			LastDeliveredMs:   stream.buffer.LastArrival().UnixMilli(),
+		})
+	}
+	slices.SortFunc(streams, func(a, b AudioSnapshotStream) int {
+		return cmp.Compare(a.TrackID, b.TrackID)
+	})
+	return streams
+}
+
+// Snapshot reconstructs the audio of a track delivered to the consumer within the duration before now as WAV.
+// The duration is capped to the retention period, requests are rate limited per room.
+func (s *AudioSnapshots) Snapshot(consumer livekit.ParticipantIdentity, trackID livekit.TrackID, duration time.Duration) ([]byte, error) {
+	if s == nil {
+		return nil, ErrAudioSnapshotNotFound
+	}
+
+	now := time.Now()
+	s.lock.Lock()
+	stream, ok := s.streams[audioSnapshotKey{consumer: consumer, trackID: trackID}]
+	if !ok {
+		s.lock.Unlock()
+		return nil, ErrAudioSnapshotNotFound
+	}
+	s.requests = slices.DeleteFunc(s.requests, func(at time.Time) bool {
+		return now.Sub(at) >= audioSnapshotRateWindow
+	})
+	if limit := s.params.Config.RequestsPerMinute; limit > 0 && len(s.requests) >= limit {
+		s.lock.Unlock()
+		return nil, ErrAudioSnapshotRateLimited
+	}
+	s.requests = append(s.requests, now)
+	s.lock.Unlock()
+
+	if duration <= 0 || duration > s.params.Config.Retention {
+		duration = s.params.Config.Retention
+	}
+	packets := stream.buffer.Packets(now, duration)
+	if len(packets) == 0 {
+		return nil, ErrAudioSnapshotNotFound
+	}
+
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+	wav, err := audio.SnapshotWAV(packets, decoder)
+	if err != nil {
+		return nil, err
+	}
+
+	s.params.Logger.Infow(
+		"audio snapshot taken",
+		"consumer", consumer,
+		"trackID", trackID,
+		"duration", duration,
+		"packets", len(packets),
+	)
+	return wav, nil
+}
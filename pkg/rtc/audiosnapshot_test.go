@@ -0,0 +1,74 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+func TestAudioSnapshots(t *testing.T) {
+	agent := &typesfakes.FakeLocalParticipant{}
+	agent.IdentityReturns("agent")
+	agent.IsAgentReturns(true)
+	user := &typesfakes.FakeLocalParticipant{}
+	user.IdentityReturns("user")
+
+	s := NewAudioSnapshots(AudioSnapshotsParams{
+		Config: audio.SnapshotConfig{
+			Enabled:           true,
+			Retention:         time.Second,
+			RequestsPerMinute: 2,
+		},
+		Logger: logger.GetLogger(),
+		GetParticipants: func() []types.LocalParticipant {
+			return []types.LocalParticipant{agent, user}
+		},
+	})
+	defer s.Stop()
+
+	for i := 0; i < 5; i++ {
+		s.AddMixedAudio(agent, []byte{0x78, byte(i)}, 20*time.Millisecond)
+		// only agents are covered by default
+		s.AddMixedAudio(user, []byte{0x78, byte(i)}, 20*time.Millisecond)
+	}
+
+	streams := s.Streams("agent")
+	require.Len(t, streams, 1)
+	require.Equal(t, livekit.TrackID(AudioMixTrackID), streams[0].TrackID)
+	require.True(t, streams[0].Mixed)
+	require.Empty(t, s.Streams("user"))
+
+	_, err := s.Snapshot("user", AudioMixTrackID, time.Second)
+	require.ErrorIs(t, err, ErrAudioSnapshotNotFound)
+
+	// decoding depends on the opus build tag, the requests count against the limit either way
+	for i := 0; i < 2; i++ {
+		_, err = s.Snapshot("agent", AudioMixTrackID, time.Second)
+		require.NotErrorIs(t, err, ErrAudioSnapshotRateLimited)
+	}
+	_, err = s.Snapshot("agent", AudioMixTrackID, time.Second)
+	require.ErrorIs(t, err, ErrAudioSnapshotRateLimited)
+}
@@ -0,0 +1,329 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/b2bua"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	b2buaSubscriberPrefix = "B2B_"
+	b2buaMaxLegs          = 2
+
+	WebhookEventB2BUARecordingFailed = "b2bua_recording_failed"
+)
+
+var (
+	ErrB2BUALegsExceeded     = errors.New("B2BUA room already has two legs")
+	ErrB2BUARecordingFailed  = errors.New("compliance recording of the call failed")
+	ErrB2BUARecordingStopped = errors.New("compliance recording of the call has stopped")
+)
+
+// IsB2BUARoom returns true if the room runs as a back-to-back user agent
+func IsB2BUARoom(config b2bua.Config, roomName livekit.RoomName) bool {
+	return config.Enabled && config.RoomPrefix != "" && strings.HasPrefix(string(roomName), config.RoomPrefix)
+}
+
+type B2BUAParams struct {
+	Config   b2bua.Config
+	RoomName livekit.RoomName
+	RoomID   livekit.RoomID
+	Logger   logger.Logger
+	// called once when the recording fails, the call has to be ended
+	OnFailure func(err error)
+}
+
+// B2BUA bridges exactly two legs, SIP or WebRTC participants, and streams the audio of the call to
+// a compliance recorder. Recording is a precondition of the call: legs are only admitted once the
+// recorder accepted the call, and a recording failure ends the call.
+type B2BUA struct {
+	params B2BUAParams
+	ready  chan struct{}
+
+	lock      sync.Mutex
+	recording *b2bua.Recording
+	err       error
+	legs      map[livekit.ParticipantIdentity]int
+	nextLeg   int
+	taps      map[livekit.TrackID]*b2buaTap
+	stopped   bool
+}
+
+func NewB2BUA(params B2BUAParams) *B2BUA {
+	b := &B2BUA{
+		params: params,
+		ready:  make(chan struct{}),
+		legs:   make(map[livekit.ParticipantIdentity]int),
+		taps:   make(map[livekit.TrackID]*b2buaTap),
+	}
+	go b.connect()
+	return b
+}
+
+func (b *B2BUA) connect() {
+	recording, err := b2bua.Dial(context.Background(), b.params.Config, b2bua.CallInfo{
+		RoomName:  string(b.params.RoomName),
+		RoomID:    string(b.params.RoomID),
+		StartedAt: time.Now(),
+	}, b.fail)
+
+	b.lock.Lock()
+	stopped := b.stopped
+	if !stopped {
+		b.recording, b.err = recording, err
+	}
+	b.lock.Unlock()
+	close(b.ready)
+
+	switch {
+	case stopped:
+		if recording != nil {
+			_ = recording.Close("room closed")
+		}
+	case err != nil:
+		b.fail(err)
+	default:
+		b.params.Logger.Infow("compliance recording started", "recorder", b.params.Config.RecorderAddress)
+	}
+}
+
+func (b *B2BUA) fail(err error) {
+	b.lock.Lock()
+	if b.err == nil {
+		b.err = err
+	}
+	b.lock.Unlock()
+
+	b.params.Logger.Errorw("compliance recording failed", err, "recorder", b.params.Config.RecorderAddress)
+	if b.params.OnFailure != nil {
+		b.params.OnFailure(err)
+	}
+}
+
+// WaitReady blocks until the recorder accepted the call, returns an error if the call cannot be recorded
+func (b *B2BUA) WaitReady() error {
+	if b == nil {
+		return nil
+	}
+
+	<-b.ready
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.checkLocked()
+}
+
+func (b *B2BUA) checkLocked() error {
+	switch {
+	case b.err != nil:
+		return fmt.Errorf("%w: %w", ErrB2BUARecordingFailed, b.err)
+	case b.stopped:
+		return ErrB2BUARecordingStopped
+	default:
+		return nil
+	}
+}
+
+// AddLeg admits a participant as one of the two legs of the call, agents and recorders are not legs
+func (b *B2BUA) AddLeg(p types.LocalParticipant) error {
+	if b == nil || p.IsDependent() {
+		return nil
+	}
+
+	b.lock.Lock()
+	if err := b.checkLocked(); err != nil {
+		b.lock.Unlock()
+		return err
+	}
+	if _, ok := b.legs[p.Identity()]; ok {
+		b.lock.Unlock()
+		return nil
+	}
+	if len(b.legs) >= b2buaMaxLegs {
+		b.lock.Unlock()
+		return ErrB2BUALegsExceeded
+	}
+	index := b.nextLeg
+	b.nextLeg++
+	b.legs[p.Identity()] = index
+	recording := b.recording
+	b.lock.Unlock()
+
+	return recording.AddLeg(b.legEvent(b2bua.LegJoined, index, p))
+}
+
+// RemoveLeg frees the slot of a leg that left the call
+func (b *B2BUA) RemoveLeg(p types.LocalParticipant) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	index, ok := b.legs[p.Identity()]
+	delete(b.legs, p.Identity())
+	recording := b.recording
+	stopped := b.stopped || b.err != nil
+	b.lock.Unlock()
+
+	if ok && !stopped {
+		_ = recording.AddLeg(b.legEvent(b2bua.LegLeft, index, p))
+	}
+}
+
+func (b *B2BUA) legEvent(event string, index int, p types.LocalParticipant) b2bua.LegEvent {
+	return b2bua.LegEvent{
+		Event:               event,
+		Index:               index,
+		ParticipantIdentity: string(p.Identity()),
+		Kind:                p.Kind().String(),
+		SIPCallID:           p.ToProto().Attributes[livekit.AttrSIPCallID],
+		Time:                time.Now(),
+	}
+}
+
+// AddTrack records an audio track of any participant of the call. It has to be called before
+// the track is forwarded, as nothing may be heard by a leg that was not recorded.
+func (b *B2BUA) AddTrack(p types.LocalParticipant, track types.MediaTrack) error {
+	if b == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return nil
+	}
+
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		return nil
+	}
+
+	b.lock.Lock()
+	if err := b.checkLocked(); err != nil {
+		b.lock.Unlock()
+		return err
+	}
+	if _, ok := b.taps[track.ID()]; ok {
+		b.lock.Unlock()
+		return nil
+	}
+	recording := b.recording
+	b.lock.Unlock()
+
+	handle, err := recording.AddTrack(b2bua.Track{
+		ParticipantIdentity: string(p.Identity()),
+		TrackID:             string(track.ID()),
+		MimeType:            receiver.Mime().String(),
+		ClockRate:           receiver.Codec().ClockRate,
+	})
+	if err != nil {
+		return err
+	}
+
+	tap := &b2buaTap{
+		recording: recording,
+		handle:    handle,
+	}
+	tap.receiverTap = newReceiverTap(b2buaSubscriberPrefix, track.ID(), receiver, tap.onPacket)
+
+	b.lock.Lock()
+	if err := b.checkLocked(); err != nil {
+		b.lock.Unlock()
+		return err
+	}
+	b.taps[track.ID()] = tap
+	b.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		b.RemoveTrack(track.ID())
+		return err
+	}
+	return nil
+}
+
+func (b *B2BUA) RemoveTrack(trackID livekit.TrackID) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	tap, ok := b.taps[trackID]
+	delete(b.taps, trackID)
+	b.lock.Unlock()
+
+	if ok {
+		tap.stop()
+	}
+}
+
+// Stop ends the recording of the call
+func (b *B2BUA) Stop() {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	if b.stopped {
+		b.lock.Unlock()
+		return
+	}
+	b.stopped = true
+	taps := b.taps
+	b.taps = make(map[livekit.TrackID]*b2buaTap)
+	recording := b.recording
+	b.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.stop()
+	}
+	if recording != nil {
+		go func() {
+			if err := recording.Close("room closed"); err != nil {
+				b.params.Logger.Warnw("compliance recording ended with error", err)
+			} else {
+				b.params.Logger.Infow("compliance recording finished")
+			}
+		}()
+	}
+}
+
+// --------------------------------------
+
+// b2buaTap writes the packets of a track to the recording on the forwarding path,
+// the recording queues them without blocking and fails rather than dropping any
+type b2buaTap struct {
+	*receiverTap
+
+	recording *b2bua.Recording
+	handle    uint16
+}
+
+func (t *b2buaTap) onPacket(p *buffer.ExtPacket) {
+	packet, err := p.Packet.Marshal()
+	if err != nil {
+		return
+	}
+	// failures are reported through the failure callback of the recording
+	_ = t.recording.WriteRTP(t.handle, time.Unix(0, p.Arrival), packet)
+}
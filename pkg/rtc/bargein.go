@@ -0,0 +1,136 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// topic of the data packets telling an agent that somebody started speaking while it played audio
+	BargeInTopic = "agentix.barge_in"
+
+	// webhook event sent when an agent is interrupted, with the agent as participant and its track
+	WebhookEventAgentInterrupted = "agent_interrupted"
+)
+
+// BargeInEvent tells an agent to stop talking, somebody started speaking over it
+type BargeInEvent struct {
+	// who started speaking
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	// the agent interrupted
+	AgentIdentity livekit.ParticipantIdentity `json:"agent_identity"`
+	AgentTrackID  livekit.TrackID             `json:"agent_track_id"`
+	// unix time in milliseconds the speech started
+	SpeechStartMs int64 `json:"speech_start_ms"`
+	// time from the speech starting to it being detected
+	LatencyMs int64 `json:"latency_ms"`
+	// the track of the agent was muted, the agent unmutes it to talk again
+	Paused bool `json:"paused"`
+}
+
+type BargeInControllerParams struct {
+	Config    audio.BargeInConfig
+	OnBargeIn func(agent types.LocalParticipant, event *BargeInEvent)
+}
+
+// BargeInController interrupts agents playing audio when somebody else starts speaking, following the
+// speaking transitions of voice activity detection of both
+type BargeInController struct {
+	params BargeInControllerParams
+
+	lock     sync.Mutex
+	detector *audio.BargeInDetector
+	agents   map[livekit.TrackID]types.LocalParticipant
+}
+
+func NewBargeInController(params BargeInControllerParams) *BargeInController {
+	return &BargeInController{
+		params:   params,
+		detector: audio.NewBargeInDetector(params.Config),
+		agents:   make(map[livekit.TrackID]types.LocalParticipant),
+	}
+}
+
+// ObserveSpeaking follows the speech of agents, and interrupts them when anybody else starts speaking
+func (c *BargeInController) ObserveSpeaking(p types.LocalParticipant, trackID livekit.TrackID, transition *audio.SpeakingTransition) {
+	if c == nil {
+		return
+	}
+
+	if p.IsAgent() {
+		if !c.params.Config.IsAgent(string(p.Identity())) {
+			return
+		}
+
+		c.lock.Lock()
+		c.agents[trackID] = p
+		if transition.Speaking {
+			c.detector.AgentSpeaking(string(trackID), transition.SpeechStart, true)
+		} else {
+			c.detector.AgentSpeaking(string(trackID), transition.SpeechEnd, false)
+		}
+		c.lock.Unlock()
+		return
+	}
+	if !transition.Speaking {
+		return
+	}
+
+	now := time.Now()
+	type interrupt struct {
+		agent   types.LocalParticipant
+		trackID livekit.TrackID
+	}
+	var interrupts []interrupt
+	c.lock.Lock()
+	for _, agentTrackID := range c.detector.SpeechStarted(now) {
+		if agent := c.agents[livekit.TrackID(agentTrackID)]; agent != nil {
+			interrupts = append(interrupts, interrupt{agent: agent, trackID: livekit.TrackID(agentTrackID)})
+		}
+	}
+	c.lock.Unlock()
+
+	for _, i := range interrupts {
+		c.params.OnBargeIn(i.agent, &BargeInEvent{
+			ParticipantIdentity: p.Identity(),
+			TrackID:             trackID,
+			AgentIdentity:       i.agent.Identity(),
+			AgentTrackID:        i.trackID,
+			SpeechStartMs:       transition.SpeechStart.UnixMilli(),
+			LatencyMs:           now.Sub(transition.SpeechStart).Milliseconds(),
+			Paused:              c.params.Config.AutoPause,
+		})
+	}
+}
+
+func (c *BargeInController) RemoveTrack(trackID livekit.TrackID) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	delete(c.agents, trackID)
+	c.detector.RemoveAgent(string(trackID))
+	c.lock.Unlock()
+}
@@ -0,0 +1,399 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/callflow"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// state of a flow is kept this long after its last change, for participants reconnecting
+	CallFlowStateTTL = 24 * time.Hour
+
+	callFlowTickInterval = 100 * time.Millisecond
+	callFlowQueueSize    = 256
+	callFlowStoreTimeout = 2 * time.Second
+	// SIP transfers wait for the target to answer
+	callFlowTransferTimeout = 30 * time.Second
+)
+
+var ErrCallFlowStateNotFound = errors.New("call flow state does not exist")
+
+// CallFlowStore persists the state of running call flows
+type CallFlowStore interface {
+	StoreCallFlowState(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity, state *callflow.State, ttl time.Duration) error
+	LoadCallFlowState(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*callflow.State, error)
+	DeleteCallFlowState(ctx context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error
+}
+
+// CallFlowMessage is the JSON payload of data packets on callflow.Topic. The server sends prompts,
+// the participant playing them answers with prompt_done once a prompt was played.
+type CallFlowMessage struct {
+	Type                string            `json:"type"`
+	ParticipantIdentity string            `json:"participant_identity"`
+	Step                string            `json:"step"`
+	Text                string            `json:"text,omitempty"`
+	AudioURL            string            `json:"audio_url,omitempty"`
+	Variables           map[string]string `json:"variables,omitempty"`
+}
+
+const (
+	CallFlowMessagePrompt     = "prompt"
+	CallFlowMessagePromptDone = "prompt_done"
+)
+
+type CallFlowRunnerParams struct {
+	Flows    map[string]*callflow.Flow
+	RoomName livekit.RoomName
+	Logger   logger.Logger
+	// optional, flows are not resumed without it
+	Store    CallFlowStore
+	SendData func(dp *livekit.DataPacket)
+	Dispatch func(agentName string, metadata string) error
+	// optional, transfers fail without it
+	Transfer func(ctx context.Context, sipCallID string, transfer *callflow.Transfer) error
+	Hangup   func(identity livekit.ParticipantIdentity)
+}
+
+// CallFlowRunner executes the call flows of the participants of a room on a single goroutine,
+// so that actions are performed in order and outside of the locks of the room
+type CallFlowRunner struct {
+	params CallFlowRunnerParams
+
+	queue   chan func()
+	stopped core.Fuse
+
+	// accessed on the worker only
+	executions map[livekit.ParticipantIdentity]*callFlowExecution
+}
+
+type callFlowExecution struct {
+	*callflow.Execution
+	identity  livekit.ParticipantIdentity
+	sipCallID string
+	// state last persisted, nothing is written when an event changed nothing
+	stored callflow.State
+}
+
+func NewCallFlowRunner(params CallFlowRunnerParams) *CallFlowRunner {
+	c := &CallFlowRunner{
+		params:     params,
+		queue:      make(chan func(), callFlowQueueSize),
+		executions: make(map[livekit.ParticipantIdentity]*callFlowExecution),
+	}
+	go c.worker()
+	return c
+}
+
+// CallFlowName returns the flow a participant runs, named by its attribute or, for SIP participants,
+// by the room metadata
+func CallFlowName(p types.LocalParticipant, roomMetadata string) string {
+	if grants := p.ClaimGrants(); grants != nil {
+		if name := grants.Attributes[callflow.FlowKey]; name != "" {
+			return name
+		}
+	}
+	if p.Kind() != livekit.ParticipantInfo_SIP || roomMetadata == "" {
+		return ""
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(roomMetadata), &doc); err != nil {
+		return ""
+	}
+	var name string
+	if raw, ok := doc[callflow.FlowKey]; !ok || json.Unmarshal(raw, &name) != nil {
+		return ""
+	}
+	return name
+}
+
+// Start runs the flow the participant selects, resuming a flow it ran before reconnecting
+func (c *CallFlowRunner) Start(p types.LocalParticipant, roomMetadata string) {
+	if c == nil {
+		return
+	}
+	name := CallFlowName(p, roomMetadata)
+	if name == "" {
+		return
+	}
+	flow, ok := c.params.Flows[name]
+	if !ok {
+		c.params.Logger.Warnw("unknown call flow", nil, "participant", p.Identity(), "flow", name)
+		return
+	}
+
+	identity := p.Identity()
+	sipCallID := p.ToProto().Attributes[livekit.AttrSIPCallID]
+	c.enqueue(func() {
+		if _, ok := c.executions[identity]; ok {
+			return
+		}
+
+		e := &callFlowExecution{identity: identity, sipCallID: sipCallID}
+		if state := c.load(identity); state != nil && state.Flow == name && !state.Done {
+			resumed, err := callflow.ResumeExecution(flow, *state)
+			if err == nil {
+				e.Execution, e.stored = resumed, *state
+				c.executions[identity] = e
+				c.params.Logger.Infow("resuming call flow", "participant", identity, "flow", name, "step", state.Step)
+				return
+			}
+			c.params.Logger.Warnw("could not resume call flow", err, "participant", identity, "flow", name)
+		}
+
+		e.Execution = callflow.NewExecution(name, flow)
+		c.executions[identity] = e
+		c.params.Logger.Infow("starting call flow", "participant", identity, "flow", name)
+		c.perform(e, e.Start(time.Now()))
+	})
+}
+
+// ParticipantLeft stops the flow of a participant, a flow that has not ended can be resumed
+func (c *CallFlowRunner) ParticipantLeft(identity livekit.ParticipantIdentity) {
+	if c == nil {
+		return
+	}
+	c.enqueue(func() {
+		delete(c.executions, identity)
+	})
+}
+
+// OnDigit passes a DTMF digit of a participant to its flow
+func (c *CallFlowRunner) OnDigit(identity livekit.ParticipantIdentity, digit string) {
+	if c == nil {
+		return
+	}
+	c.onEvent(identity, func(e *callFlowExecution, now time.Time) []callflow.Action {
+		return e.OnDigit(digit, now)
+	})
+}
+
+// OnDataPacket passes SIP DTMF, final transcriptions and prompt acknowledgements to the flows they concern
+func (c *CallFlowRunner) OnDataPacket(source types.LocalParticipant, dp *livekit.DataPacket) {
+	if c == nil {
+		return
+	}
+
+	switch {
+	case dp.GetSipDtmf() != nil && source != nil:
+		c.OnDigit(source.Identity(), dp.GetSipDtmf().Digit)
+
+	case dp.GetTranscription() != nil:
+		transcription := dp.GetTranscription()
+		var text []string
+		for _, segment := range transcription.Segments {
+			if segment.Final && segment.Text != "" {
+				text = append(text, segment.Text)
+			}
+		}
+		if len(text) == 0 {
+			return
+		}
+		speech := strings.Join(text, " ")
+		c.onEvent(livekit.ParticipantIdentity(transcription.TranscribedParticipantIdentity), func(e *callFlowExecution, now time.Time) []callflow.Action {
+			return e.OnSpeech(speech, now)
+		})
+
+	case dp.GetUser().GetTopic() == callflow.Topic:
+		var msg CallFlowMessage
+		if err := json.Unmarshal(dp.GetUser().Payload, &msg); err != nil || msg.Type != CallFlowMessagePromptDone {
+			return
+		}
+		c.onEvent(livekit.ParticipantIdentity(msg.ParticipantIdentity), func(e *callFlowExecution, now time.Time) []callflow.Action {
+			return e.OnPromptDone(msg.Step, now)
+		})
+	}
+}
+
+func (c *CallFlowRunner) Stop() {
+	if c == nil {
+		return
+	}
+	c.stopped.Break()
+}
+
+func (c *CallFlowRunner) onEvent(identity livekit.ParticipantIdentity, event func(e *callFlowExecution, now time.Time) []callflow.Action) {
+	c.enqueue(func() {
+		if e, ok := c.executions[identity]; ok {
+			c.perform(e, event(e, time.Now()))
+		}
+	})
+}
+
+func (c *CallFlowRunner) enqueue(op func()) {
+	select {
+	case c.queue <- op:
+	case <-c.stopped.Watch():
+	default:
+		c.params.Logger.Warnw("call flow queue full, dropping event", nil)
+	}
+}
+
+func (c *CallFlowRunner) worker() {
+	ticker := time.NewTicker(callFlowTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopped.Watch():
+			return
+		case op := <-c.queue:
+			op()
+		case now := <-ticker.C:
+			for _, e := range c.executions {
+				c.perform(e, e.OnTimer(now))
+			}
+		}
+	}
+}
+
+// perform carries out the actions of an execution and persists its state. Runs on the worker.
+func (c *CallFlowRunner) perform(e *callFlowExecution, actions []callflow.Action) {
+	for _, action := range actions {
+		switch action.Kind {
+		case callflow.ActionPrompt:
+			c.sendPrompt(e, action)
+
+		case callflow.ActionHandoff:
+			metadata := action.Handoff.Metadata
+			if metadata == "" && len(action.Variables) != 0 {
+				if data, err := json.Marshal(action.Variables); err == nil {
+					metadata = string(data)
+				}
+			}
+			if err := c.params.Dispatch(action.Handoff.AgentName, metadata); err != nil {
+				c.params.Logger.Warnw("call flow handoff failed", err, "participant", e.identity, "step", action.Step, "agentName", action.Handoff.AgentName)
+			}
+
+		case callflow.ActionTransfer:
+			c.transfer(e, action.Transfer)
+
+		case callflow.ActionHangup:
+			c.params.Hangup(e.identity)
+		}
+	}
+
+	state := e.State()
+	if state.Done {
+		delete(c.executions, e.identity)
+		c.params.Logger.Infow("call flow ended", "participant", e.identity, "flow", state.Flow, "step", state.Step)
+	}
+	c.store(e, state)
+}
+
+func (c *CallFlowRunner) sendPrompt(e *callFlowExecution, action callflow.Action) {
+	payload, err := json.Marshal(&CallFlowMessage{
+		Type:                CallFlowMessagePrompt,
+		ParticipantIdentity: string(e.identity),
+		Step:                action.Step,
+		Text:                action.Prompt.Text,
+		AudioURL:            action.Prompt.AudioURL,
+		Variables:           action.Variables,
+	})
+	if err != nil {
+		c.params.Logger.Errorw("could not marshal call flow prompt", err)
+		return
+	}
+	c.params.SendData(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(callflow.Topic),
+			},
+		},
+	})
+}
+
+// transfer hands the call to the SIP bridge, the result is passed back to the flow on the worker
+func (c *CallFlowRunner) transfer(e *callFlowExecution, transfer *callflow.Transfer) {
+	identity, sipCallID := e.identity, e.sipCallID
+	go func() {
+		err := errors.New("transfers are not available")
+		switch {
+		case sipCallID == "":
+			err = errors.New("participant has no SIP call")
+		case c.params.Transfer != nil:
+			ctx, cancel := context.WithTimeout(context.Background(), callFlowTransferTimeout)
+			err = c.params.Transfer(ctx, sipCallID, transfer)
+			cancel()
+		}
+		if err != nil {
+			c.params.Logger.Warnw("call flow transfer failed", err, "participant", identity, "transferTo", transfer.To)
+		}
+
+		c.onEvent(identity, func(e *callFlowExecution, now time.Time) []callflow.Action {
+			return e.OnTransferResult(err, now)
+		})
+	}()
+}
+
+func (c *CallFlowRunner) load(identity livekit.ParticipantIdentity) *callflow.State {
+	if c.params.Store == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), callFlowStoreTimeout)
+	defer cancel()
+
+	state, err := c.params.Store.LoadCallFlowState(ctx, c.params.RoomName, identity)
+	if err != nil {
+		if !errors.Is(err, ErrCallFlowStateNotFound) {
+			c.params.Logger.Warnw("could not load call flow state", err, "participant", identity)
+		}
+		return nil
+	}
+	return state
+}
+
+func (c *CallFlowRunner) store(e *callFlowExecution, state callflow.State) {
+	if c.params.Store == nil || callFlowStateEqual(e.stored, state) {
+		return
+	}
+	e.stored = state
+
+	ctx, cancel := context.WithTimeout(context.Background(), callFlowStoreTimeout)
+	defer cancel()
+
+	var err error
+	if state.Done {
+		err = c.params.Store.DeleteCallFlowState(ctx, c.params.RoomName, e.identity)
+	} else {
+		err = c.params.Store.StoreCallFlowState(ctx, c.params.RoomName, e.identity, &state, CallFlowStateTTL)
+	}
+	if err != nil {
+		c.params.Logger.Warnw("could not persist call flow state", err, "participant", e.identity)
+	}
+}
+
+func callFlowStateEqual(a, b callflow.State) bool {
+	return a.Flow == b.Flow && a.Step == b.Step && a.Wait == b.Wait && a.Deadline.Equal(b.Deadline) &&
+		a.Attempt == b.Attempt && a.Input == b.Input && a.Done == b.Done && maps.Equal(a.Variables, b.Variables)
+}
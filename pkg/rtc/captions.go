@@ -0,0 +1,179 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// topic of the reliable data packets carrying captions, a JSON Caption each
+	CaptionsTopic = "agentix.captions"
+	// participant attribute subscribing to captions, CaptionsAllLanguages or a comma separated list of
+	// language codes, e. g. "en,de-AT", a code without region matching every region of the language
+	CaptionsAttribute    = "agentix.captions"
+	CaptionsAllLanguages = "*"
+)
+
+// Caption is a transcript segment of a speaker, interim captions of an utterance are replaced by the
+// following ones with the same segment ID until one is final
+type Caption struct {
+	SpeakerIdentity livekit.ParticipantIdentity `json:"speaker_identity"`
+	SpeakerName     string                      `json:"speaker_name,omitempty"`
+	TrackID         livekit.TrackID             `json:"track_id,omitempty"`
+	SegmentID       string                      `json:"segment_id"`
+	Text            string                      `json:"text"`
+	// language of the text, empty when the transcriber does not tell, sent to every subscriber then
+	Language  string `json:"language,omitempty"`
+	StartTime uint64 `json:"start_time"`
+	EndTime   uint64 `json:"end_time"`
+	Final     bool   `json:"final"`
+}
+
+type CaptionsParams struct {
+	Config          config.CaptionsConfig
+	Logger          logger.Logger
+	GetParticipants func() []types.LocalParticipant
+	Send            func(dp *livekit.DataPacket)
+}
+
+// Captions fans the transcripts of a room, from the server's transcription as well as from agents, out to
+// the participants subscribed with CaptionsAttribute, each getting the languages it selected
+type Captions struct {
+	params CaptionsParams
+}
+
+func NewCaptions(params CaptionsParams) *Captions {
+	return &Captions{
+		params: params,
+	}
+}
+
+func (c *Captions) AddTranscription(transcription *livekit.Transcription) {
+	if c == nil {
+		return
+	}
+
+	speaker := livekit.ParticipantIdentity(transcription.TranscribedParticipantIdentity)
+	var speakerName string
+	var subscribers []types.LocalParticipant
+	var languages [][]string
+	for _, p := range c.params.GetParticipants() {
+		if p.Identity() == speaker {
+			speakerName = p.ToProto().Name
+		}
+		if l, ok := CaptionLanguages(p); ok {
+			subscribers = append(subscribers, p)
+			languages = append(languages, l)
+		}
+	}
+	if len(subscribers) == 0 {
+		return
+	}
+
+	for _, segment := range transcription.Segments {
+		if !segment.Final && !c.params.Config.Interim {
+			continue
+		}
+
+		var destinations []string
+		for i, p := range subscribers {
+			if captionLanguageMatches(languages[i], segment.Language) {
+				destinations = append(destinations, string(p.Identity()))
+			}
+		}
+		if len(destinations) == 0 {
+			continue
+		}
+
+		payload, err := json.Marshal(&Caption{
+			SpeakerIdentity: speaker,
+			SpeakerName:     speakerName,
+			TrackID:         livekit.TrackID(transcription.TrackId),
+			SegmentID:       segment.Id,
+			Text:            segment.Text,
+			Language:        segment.Language,
+			StartTime:       segment.StartTime,
+			EndTime:         segment.EndTime,
+			Final:           segment.Final,
+		})
+		if err != nil {
+			c.params.Logger.Errorw("could not marshal caption", err)
+			return
+		}
+		c.params.Send(&livekit.DataPacket{
+			Kind:                  livekit.DataPacket_RELIABLE,
+			DestinationIdentities: destinations,
+			Value: &livekit.DataPacket_User{
+				User: &livekit.UserPacket{
+					Payload: payload,
+					Topic:   proto.String(CaptionsTopic),
+				},
+			},
+		})
+	}
+}
+
+// CaptionLanguages returns the languages a participant subscribed to captions in, nil for all of them,
+// and false if it did not subscribe
+func CaptionLanguages(p types.LocalParticipant) ([]string, bool) {
+	grants := p.ClaimGrants()
+	if grants == nil {
+		return nil, false
+	}
+	value := strings.TrimSpace(grants.Attributes[CaptionsAttribute])
+	if value == "" {
+		return nil, false
+	}
+
+	var languages []string
+	for _, language := range strings.Split(value, ",") {
+		language = strings.TrimSpace(language)
+		if language == CaptionsAllLanguages {
+			return nil, true
+		}
+		if language != "" {
+			languages = append(languages, language)
+		}
+	}
+	return languages, len(languages) != 0
+}
+
+// captionLanguageMatches returns true if a caption in language is for a subscriber of languages
+func captionLanguageMatches(languages []string, language string) bool {
+	if len(languages) == 0 || language == "" {
+		return true
+	}
+	for _, l := range languages {
+		if strings.EqualFold(l, language) {
+			return true
+		}
+		// a language without region matches all of its regions
+		if base, _, ok := strings.Cut(language, "-"); ok && strings.EqualFold(l, base) {
+			return true
+		}
+	}
+	return false
+}
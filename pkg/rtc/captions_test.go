@@ -0,0 +1,88 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestCaptions(t *testing.T) {
+	participant := func(identity livekit.ParticipantIdentity, captions string) *typesfakes.FakeLocalParticipant {
+		p := &typesfakes.FakeLocalParticipant{}
+		p.IdentityReturns(identity)
+		p.ToProtoReturns(&livekit.ParticipantInfo{Identity: string(identity), Name: string(identity) + " name"})
+		p.ClaimGrantsReturns(&auth.ClaimGrants{Attributes: map[string]string{CaptionsAttribute: captions}})
+		return p
+	}
+	participants := []types.LocalParticipant{
+		participant("speaker", ""),
+		participant("all", "*"),
+		participant("english", "en"),
+		participant("german", "de-AT, de-DE"),
+	}
+
+	var sent []*livekit.DataPacket
+	c := NewCaptions(CaptionsParams{
+		Config:          config.CaptionsConfig{Enabled: true},
+		Logger:          logger.GetLogger(),
+		GetParticipants: func() []types.LocalParticipant { return participants },
+		Send:            func(dp *livekit.DataPacket) { sent = append(sent, dp) },
+	})
+
+	c.AddTranscription(&livekit.Transcription{
+		TranscribedParticipantIdentity: "speaker",
+		TrackId:                        "TR_speaker",
+		Segments: []*livekit.TranscriptionSegment{
+			{Id: "s1", Text: "hello", Language: "en-US", Final: true, StartTime: 10, EndTime: 20},
+			{Id: "s2", Text: "hallo", Language: "de-DE"},
+			{Id: "s3", Text: "servus", Language: "de-DE", Final: true},
+			{Id: "s4", Text: "ok", Final: true},
+		},
+	})
+
+	require.Len(t, sent, 3)
+	require.Equal(t, []string{"all", "english"}, sent[0].DestinationIdentities)
+	require.Equal(t, CaptionsTopic, sent[0].GetUser().GetTopic())
+	caption := Caption{}
+	require.NoError(t, json.Unmarshal(sent[0].GetUser().Payload, &caption))
+	require.Equal(t, Caption{
+		SpeakerIdentity: "speaker",
+		SpeakerName:     "speaker name",
+		TrackID:         "TR_speaker",
+		SegmentID:       "s1",
+		Text:            "hello",
+		Language:        "en-US",
+		StartTime:       10,
+		EndTime:         20,
+		Final:           true,
+	}, caption)
+
+	// interim captions are not sent without interim enabled
+	require.Equal(t, []string{"all", "german"}, sent[1].DestinationIdentities)
+	// captions without a language are for everybody subscribed
+	require.Equal(t, []string{"all", "english", "german"}, sent[2].DestinationIdentities)
+}
@@ -15,13 +15,25 @@
 package rtc
 
 import (
+	"time"
+
+	"github.com/pion/interceptor"
 	"github.com/pion/sdp/v3"
 	"github.com/pion/webrtc/v4"
 
+	"github.com/livekit/livekit-server/pkg/audioinject"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/realtime"
+	"github.com/livekit/livekit-server/pkg/replay"
+	"github.com/livekit/livekit-server/pkg/rtmp"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
+	"github.com/livekit/livekit-server/pkg/sip"
+	"github.com/livekit/livekit-server/pkg/srt"
 	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
+	"github.com/livekit/protocol/logger"
 )
 
 const (
@@ -32,10 +44,29 @@ const (
 type WebRTCConfig struct {
 	rtcconfig.WebRTCConfig
 
-	BufferFactory *buffer.Factory
-	Receiver      ReceiverConfig
-	Publisher     DirectionConfig
-	Subscriber    DirectionConfig
+	BufferFactory  *buffer.Factory
+	Receiver       ReceiverConfig
+	Publisher      DirectionConfig
+	Subscriber     DirectionConfig
+	FlightRecorder *sfuinterceptor.FlightRecorder
+	Canary         *sfuinterceptor.Canary
+	Capture        *replay.Capturer
+	ICEConsent     config.ICEConsentConfig
+	PCMTap         pcmtap.Config
+	AudioInject    audioinject.Config
+	Realtime       realtime.Config
+	SIPGateway     sip.Config
+	RTMPIngest     rtmp.Config
+	SRTIngest      srt.Config
+	// receive stages of a binary embedding the server, behind the built in audio stages
+	Interceptors []InterceptorStage
+}
+
+// InterceptorStage is a receive side interceptor added by a binary embedding the server,
+// Name labels it in flight recorder traces
+type InterceptorStage struct {
+	Name    string
+	Factory interceptor.Factory
 }
 
 type ReceiverConfig struct {
@@ -61,7 +92,18 @@ type DirectionConfig struct {
 func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 	rtcConf := conf.RTC
 
-	webRTCConfig, err := rtcconfig.NewWebRTCConfig(&rtcConf.RTCConfig, conf.Development)
+	baseConf := rtcConf.RTCConfig
+	shardUDPMux := rtcConf.UDPMuxShards > 1 && udpMuxShardable(&baseConf)
+	if shardUDPMux && !reusePortSupported {
+		logger.Warnw("udp mux sharding unavailable, reading with a single socket", ErrUDPMuxShardingUnsupported)
+		shardUDPMux = false
+	}
+	if shardUDPMux {
+		// the sharded mux binds the ports itself
+		baseConf.UDPPort = rtcconfig.PortRange{}
+	}
+
+	webRTCConfig, err := rtcconfig.NewWebRTCConfig(&baseConf, conf.Development)
 	if err != nil {
 		return nil, err
 	}
@@ -69,6 +111,15 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 	// we don't want to use active TCP on a server, clients should be dialing
 	webRTCConfig.SettingEngine.DisableActiveTCP(true)
 
+	if shardUDPMux {
+		udpMux, err := newShardedUDPMux(&rtcConf.RTCConfig, rtcConf.UDPMuxShards, logger.GetLogger())
+		if err != nil {
+			return nil, err
+		}
+		webRTCConfig.UDPMux = udpMux
+		webRTCConfig.SettingEngine.SetICEUDPMux(udpMux)
+	}
+
 	if rtcConf.PacketBufferSize == 0 {
 		rtcConf.PacketBufferSize = 500
 	}
@@ -79,17 +130,55 @@ func NewWebRTCConfig(conf *config.Config) (*WebRTCConfig, error) {
 		rtcConf.PacketBufferSizeAudio = rtcConf.PacketBufferSize
 	}
 
+	var flightRecorder *sfuinterceptor.FlightRecorder
+	if rtcConf.FlightRecorder.Enabled {
+		flightRecorder = sfuinterceptor.NewFlightRecorder(rtcConf.FlightRecorder, logger.GetLogger())
+	}
+	var canary *sfuinterceptor.Canary
+	if rtcConf.Canary.Enabled {
+		canary = sfuinterceptor.NewCanary(rtcConf.Canary, logger.GetLogger())
+	}
+	var capturer *replay.Capturer
+	if rtcConf.Capture.Enabled {
+		capturer = replay.NewCapturer(rtcConf.Capture)
+	}
+
 	return &WebRTCConfig{
 		WebRTCConfig: *webRTCConfig,
 		Receiver: ReceiverConfig{
 			PacketBufferSizeVideo: rtcConf.PacketBufferSizeVideo,
 			PacketBufferSizeAudio: rtcConf.PacketBufferSizeAudio,
 		},
-		Publisher:  getPublisherConfig(false),
-		Subscriber: getSubscriberConfig(rtcConf.CongestionControl.UseSendSideBWEInterceptor || rtcConf.CongestionControl.UseSendSideBWE),
+		Publisher:      getPublisherConfig(false),
+		Subscriber:     getSubscriberConfig(rtcConf.CongestionControl.UseSendSideBWEInterceptor || rtcConf.CongestionControl.UseSendSideBWE),
+		FlightRecorder: flightRecorder,
+		Canary:         canary,
+		Capture:        capturer,
+		ICEConsent:     rtcConf.ICEConsent,
+		PCMTap:         conf.PCMTap,
+		AudioInject:    conf.AudioInject,
+		Realtime:       conf.Realtime,
+		SIPGateway:     conf.SIPGateway,
+		RTMPIngest:     conf.RTMPIngest,
+		SRTIngest:      conf.SRTIngest,
 	}, nil
 }
 
+// iceTimeouts returns the configured ICE consent timeouts, falling back to the defaults for unset values
+func (c *WebRTCConfig) iceTimeouts() (disconnected time.Duration, failed time.Duration, keepalive time.Duration) {
+	disconnected, failed, keepalive = iceDisconnectedTimeout, iceFailedTimeout, iceKeepaliveInterval
+	if c.ICEConsent.DisconnectedTimeout > 0 {
+		disconnected = c.ICEConsent.DisconnectedTimeout
+	}
+	if c.ICEConsent.FailedTimeout > 0 {
+		failed = c.ICEConsent.FailedTimeout
+	}
+	if c.ICEConsent.KeepaliveInterval > 0 {
+		keepalive = c.ICEConsent.KeepaliveInterval
+	}
+	return
+}
+
 func (c *WebRTCConfig) UpdatePublisherConfig(consolidated bool) {
 	c.Publisher = getPublisherConfig(consolidated)
 }
@@ -0,0 +1,142 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// participant attribute carrying the consent flags, e. g. "recording,transcription"
+	ConsentAttribute = "agentix.consent"
+)
+
+var (
+	ErrInvalidConsent = errors.New("invalid consent flags")
+)
+
+// ConsentFlags is the bitmask of what a participant agreed its media to be used for
+type ConsentFlags uint8
+
+const (
+	ConsentRecording ConsentFlags = 1 << iota
+	ConsentTranscription
+	ConsentAnalytics
+
+	ConsentNone ConsentFlags = 0
+	ConsentAll               = ConsentRecording | ConsentTranscription | ConsentAnalytics
+)
+
+type consentFlagName struct {
+	flag ConsentFlags
+	name string
+}
+
+var consentFlagNames = []consentFlagName{
+	{ConsentRecording, "recording"},
+	{ConsentTranscription, "transcription"},
+	{ConsentAnalytics, "analytics"},
+}
+
+// Has returns true if all of flags are set
+func (f ConsentFlags) Has(flags ConsentFlags) bool {
+	return f&flags == flags
+}
+
+func (f ConsentFlags) String() string {
+	var names []string
+	for _, n := range consentFlagNames {
+		if f.Has(n.flag) {
+			names = append(names, n.name)
+		}
+	}
+	if len(names) == 0 {
+		return "none"
+	}
+	return strings.Join(names, ",")
+}
+
+// ParseConsentFlags parses a comma separated list of recording, transcription and analytics,
+// "all", "none", or the bitmask as a number
+func ParseConsentFlags(s string) (ConsentFlags, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(s, 10, 8); err == nil {
+		if ConsentFlags(n)&^ConsentAll != 0 {
+			return ConsentNone, fmt.Errorf("%w: %q", ErrInvalidConsent, s)
+		}
+		return ConsentFlags(n), nil
+	}
+
+	var flags ConsentFlags
+	for _, name := range strings.Split(s, ",") {
+		switch name = strings.ToLower(strings.TrimSpace(name)); name {
+		case "", "none":
+			continue
+		case "all":
+			flags |= ConsentAll
+			continue
+		}
+
+		i := slices.IndexFunc(consentFlagNames, func(n consentFlagName) bool { return n.name == name })
+		if i < 0 {
+			return ConsentNone, fmt.Errorf("%w: %q", ErrInvalidConsent, name)
+		}
+		flags |= consentFlagNames[i].flag
+	}
+	return flags, nil
+}
+
+// DefaultConsent returns the flags of participants that do not carry any,
+// everything is consented to while consent is not enforced
+func DefaultConsent(conf config.ConsentConfig) ConsentFlags {
+	if !conf.Enabled {
+		return ConsentAll
+	}
+
+	flags, err := ParseConsentFlags(strings.Join(conf.Default, ","))
+	if err != nil {
+		return ConsentNone
+	}
+	return flags
+}
+
+// ParticipantConsent returns the consent flags of the participant. Invalid flags consent to nothing.
+func ParticipantConsent(p types.LocalParticipant, conf config.ConsentConfig) ConsentFlags {
+	if !conf.Enabled {
+		return ConsentAll
+	}
+
+	grants := p.ClaimGrants()
+	if grants == nil {
+		return DefaultConsent(conf)
+	}
+	value, ok := grants.Attributes[ConsentAttribute]
+	if !ok {
+		return DefaultConsent(conf)
+	}
+	flags, err := ParseConsentFlags(value)
+	if err != nil {
+		return ConsentNone
+	}
+	return flags
+}
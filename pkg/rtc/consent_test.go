@@ -0,0 +1,204 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/realtime"
+	"github.com/livekit/livekit-server/pkg/rtc/talkstats"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+func withConsent(p *typesfakes.FakeLocalParticipant, consent string) {
+	p.ClaimGrantsReturns(&auth.ClaimGrants{Attributes: map[string]string{ConsentAttribute: consent}})
+}
+
+func TestParseConsentFlags(t *testing.T) {
+	for value, expected := range map[string]ConsentFlags{
+		"":                          ConsentNone,
+		"none":                      ConsentNone,
+		"all":                       ConsentAll,
+		"recording":                 ConsentRecording,
+		" Transcription, analytics": ConsentTranscription | ConsentAnalytics,
+		"5":                         ConsentRecording | ConsentAnalytics,
+	} {
+		flags, err := ParseConsentFlags(value)
+		require.NoError(t, err, value)
+		require.Equal(t, expected, flags, value)
+	}
+
+	for _, value := range []string{"recording,marketing", "8"} {
+		_, err := ParseConsentFlags(value)
+		require.ErrorIs(t, err, ErrInvalidConsent, value)
+	}
+
+	require.Equal(t, "recording,analytics", (ConsentRecording | ConsentAnalytics).String())
+	require.Equal(t, "none", ConsentNone.String())
+}
+
+func TestParticipantConsent(t *testing.T) {
+	p := &typesfakes.FakeLocalParticipant{}
+
+	// not enforced
+	require.Equal(t, ConsentAll, ParticipantConsent(p, config.ConsentConfig{}))
+
+	conf := config.ConsentConfig{Enabled: true, Default: []string{"transcription"}}
+	require.Equal(t, ConsentTranscription, ParticipantConsent(p, conf))
+
+	withConsent(p, "recording,analytics")
+	require.Equal(t, ConsentRecording|ConsentAnalytics, ParticipantConsent(p, conf))
+
+	// invalid flags consent to nothing
+	withConsent(p, "recording,everything")
+	require.Equal(t, ConsentNone, ParticipantConsent(p, conf))
+}
+
+func TestTalkAnalytics_Consent(t *testing.T) {
+	consenting := &typesfakes.FakeLocalParticipant{}
+	consenting.IdentityReturns("consenting")
+	consenting.GetAudioLevelReturns(0.5, true)
+	other := &typesfakes.FakeLocalParticipant{}
+	other.IdentityReturns("other")
+	other.GetAudioLevelReturns(0.5, true)
+
+	a := NewTalkAnalytics(TalkAnalyticsParams{
+		Config: talkstats.DefaultConfig,
+		HasConsent: func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool {
+			return identity == "consenting"
+		},
+	})
+	defer a.Stop()
+
+	a.Observe([]types.LocalParticipant{consenting, other})
+	a.AddTranscription(&livekit.Transcription{
+		TranscribedParticipantIdentity: "other",
+		Segments:                       []*livekit.TranscriptionSegment{{Id: "s1", Text: "hello there", Final: true}},
+	})
+
+	stats := a.Stats()
+	require.Len(t, stats.Participants, 1)
+	require.Equal(t, "consenting", stats.Participants[0].ParticipantIdentity)
+
+	a.ForgetParticipant("consenting")
+	require.Empty(t, a.Stats().Participants)
+}
+
+func TestRoom_Consent(t *testing.T) {
+	t.Run("transcriptions of participants without consent are dropped", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 2, consent: config.ConsentConfig{Enabled: true}})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		participants := rm.GetParticipants()
+		agent := participants[0].(*typesfakes.FakeLocalParticipant)
+		user := participants[1].(*typesfakes.FakeLocalParticipant)
+
+		packet := &livekit.DataPacket{
+			Kind: livekit.DataPacket_RELIABLE,
+			Value: &livekit.DataPacket_Transcription{
+				Transcription: &livekit.Transcription{
+					TranscribedParticipantIdentity: string(user.Identity()),
+					Segments:                       []*livekit.TranscriptionSegment{{Id: "s1", Text: "hello", Final: true}},
+				},
+			},
+		}
+		agent.OnDataPacketArgsForCall(0)(agent, packet.Kind, packet)
+		require.Zero(t, user.SendDataMessageCallCount())
+
+		withConsent(user, "transcription")
+		agent.OnDataPacketArgsForCall(0)(agent, packet.Kind, packet)
+		require.Equal(t, 1, user.SendDataMessageCallCount())
+	})
+
+	t.Run("mic quality of consenting publishers reaches the publisher, agents and admins", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 4, consent: config.ConsentConfig{Enabled: true}})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		participants := rm.GetParticipants()
+		pub := participants[0].(*typesfakes.FakeLocalParticipant)
+		agent := participants[1].(*typesfakes.FakeLocalParticipant)
+		agent.IsAgentReturns(true)
+		admin := participants[2].(*typesfakes.FakeLocalParticipant)
+		admin.ClaimGrantsReturns(&auth.ClaimGrants{Video: &auth.VideoGrant{RoomAdmin: true}})
+		other := participants[3].(*typesfakes.FakeLocalParticipant)
+
+		event := &MicQualityEvent{
+			ParticipantIdentity: pub.Identity(),
+			TrackID:             "TR_audio",
+			MicQualityReport:    &audio.MicQualityReport{Issues: []audio.MicQualityIssue{audio.MicQualityIssueClipping}},
+		}
+		rm.onMicQualityReport(event)
+		for _, p := range participants {
+			require.Zero(t, p.(*typesfakes.FakeLocalParticipant).SendDataMessageCallCount())
+		}
+
+		withConsent(pub, "analytics")
+		rm.onMicQualityReport(event)
+		for _, p := range []*typesfakes.FakeLocalParticipant{pub, agent, admin} {
+			require.Equal(t, 1, p.SendDataMessageCallCount(), p.Identity())
+		}
+		require.Zero(t, other.SendDataMessageCallCount())
+	})
+
+	t.Run("recorders get tracks of consenting publishers only", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1, consent: config.ConsentConfig{Enabled: true}})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		pub := rm.GetParticipants()[0].(*typesfakes.FakeLocalParticipant)
+		pub.HasPermissionReturns(true)
+
+		track := &typesfakes.FakeMediaTrack{}
+		track.IDReturns("TR_audio")
+		track.IsOpenReturns(true)
+		rm.trackManager.AddTrack(track, pub.Identity(), pub.ID())
+
+		recorder := &typesfakes.FakeLocalParticipant{}
+		recorder.IdentityReturns("recorder")
+		recorder.IsRecorderReturns(true)
+		subscriber := &typesfakes.FakeLocalParticipant{}
+		subscriber.IdentityReturns("subscriber")
+
+		require.False(t, rm.ResolveMediaTrackForSubscriber(recorder, "TR_audio").HasPermission)
+		// forwarding to other participants is not subject to consent
+		require.True(t, rm.ResolveMediaTrackForSubscriber(subscriber, "TR_audio").HasPermission)
+
+		withConsent(pub, "recording")
+		require.True(t, rm.ResolveMediaTrackForSubscriber(recorder, "TR_audio").HasPermission)
+	})
+
+	t.Run("tracks of publishers without consent are not bridged", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1, consent: config.ConsentConfig{Enabled: true}})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		pub := rm.GetParticipants()[0].(*typesfakes.FakeLocalParticipant)
+
+		track := &typesfakes.FakeMediaTrack{}
+		track.IDReturns("TR_audio")
+		pub.GetPublishedTrackReturns(track)
+
+		_, err := rm.StartRealtimeBridge("TR_audio", realtime.SessionOverrides{})
+		require.ErrorIs(t, err, ErrRealtimeBridgeNoConsent)
+
+		// gets past consent to the bridges, which are not enabled in this room
+		withConsent(pub, "transcription")
+		_, err = rm.StartRealtimeBridge("TR_audio", realtime.SessionOverrides{})
+		require.ErrorIs(t, err, ErrRealtimeBridgeDisabled)
+	})
+}
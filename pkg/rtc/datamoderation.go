@@ -0,0 +1,207 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/moderation"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// topic of the data packets notifying about moderation violations
+	DataModerationTopic = "agentix.moderation"
+
+	// a participant is notified about violations at most this often
+	dataModerationNotifyInterval = time.Second
+)
+
+type DataModerationEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	Violation           moderation.Violation        `json:"violation"`
+	Type                string                      `json:"type"`
+	Size                int                         `json:"size"`
+	RetryAfterMs        int64                       `json:"retry_after_ms,omitempty"`
+}
+
+type DataModeratorParams struct {
+	Config      moderation.Config
+	Logger      logger.Logger
+	OnViolation func(event *DataModerationEvent)
+}
+
+// DataModerator enforces slow mode, send bans and message validation on data
+// sent by participants before it is forwarded to the room.
+type DataModerator struct {
+	params    DataModeratorParams
+	moderator *moderation.Moderator
+
+	lock         sync.Mutex
+	lastNotified map[livekit.ParticipantIdentity]time.Time
+}
+
+func NewDataModerator(params DataModeratorParams) *DataModerator {
+	return &DataModerator{
+		params:       params,
+		moderator:    moderation.NewModerator(params.Config),
+		lastNotified: make(map[livekit.ParticipantIdentity]time.Time),
+	}
+}
+
+func (d *DataModerator) SyncRoomMetadata(metadata string) {
+	if d == nil {
+		return
+	}
+
+	d.moderator.SyncRoomMetadata(metadata)
+}
+
+// IsExempt returns true for participants with a role that is not moderated
+func (d *DataModerator) IsExempt(p types.Participant) bool {
+	if d == nil {
+		return false
+	}
+
+	return d.moderator.IsExempt(participantRole(p))
+}
+
+func (d *DataModerator) AllowDataPacket(source types.LocalParticipant, dp *livekit.DataPacket) bool {
+	if d == nil || source == nil {
+		return true
+	}
+
+	msgType, counted := dataPacketType(dp)
+	return d.allow(source, moderation.Message{
+		Type:    msgType,
+		Size:    proto.Size(dp),
+		Counted: counted,
+	})
+}
+
+func (d *DataModerator) AllowDataMessage(source types.LocalParticipant, data []byte) bool {
+	if d == nil || source == nil {
+		return true
+	}
+
+	return d.allow(source, moderation.Message{
+		Type:    "unlabeled",
+		Size:    len(data),
+		Counted: true,
+	})
+}
+
+func (d *DataModerator) RemoveParticipant(identity livekit.ParticipantIdentity) {
+	if d == nil {
+		return
+	}
+
+	d.moderator.RemoveParticipant(string(identity))
+
+	d.lock.Lock()
+	delete(d.lastNotified, identity)
+	d.lock.Unlock()
+}
+
+func (d *DataModerator) allow(source types.LocalParticipant, msg moderation.Message) bool {
+	var ban string
+	if grants := source.ClaimGrants(); grants != nil {
+		ban = grants.Attributes[moderation.BanAttribute]
+	}
+
+	now := time.Now()
+	res := d.moderator.Check(moderation.Sender{
+		Identity: string(source.Identity()),
+		Role:     participantRole(source),
+		Ban:      ban,
+	}, msg, now)
+	if res.Violation == moderation.ViolationNone {
+		return true
+	}
+
+	d.params.Logger.Debugw(
+		"dropping data from participant",
+		"participant", source.Identity(),
+		"violation", res.Violation,
+		"type", msg.Type,
+		"size", msg.Size,
+	)
+
+	d.lock.Lock()
+	notify := now.Sub(d.lastNotified[source.Identity()]) >= dataModerationNotifyInterval
+	if notify {
+		d.lastNotified[source.Identity()] = now
+	}
+	d.lock.Unlock()
+
+	if notify && d.params.OnViolation != nil {
+		d.params.OnViolation(&DataModerationEvent{
+			ParticipantIdentity: source.Identity(),
+			Violation:           res.Violation,
+			Type:                msg.Type,
+			Size:                msg.Size,
+			RetryAfterMs:        res.RetryAfter.Milliseconds(),
+		})
+	}
+	return false
+}
+
+func participantRole(p types.Participant) string {
+	if lp, ok := p.(types.LocalParticipant); ok {
+		if grants := lp.ClaimGrants(); grants != nil {
+			if role := grants.Attributes[moderation.RoleAttribute]; role != "" {
+				return role
+			}
+		}
+	}
+	if p.IsAgent() {
+		return moderation.RoleAgent
+	}
+	return ""
+}
+
+// dataPacketType names the type of a data packet for moderation and whether it is
+// a message on its own, continuations of a stream do not count towards slow mode
+func dataPacketType(dp *livekit.DataPacket) (string, bool) {
+	switch dp.Value.(type) {
+	case *livekit.DataPacket_User:
+		return "user", true
+	case *livekit.DataPacket_ChatMessage:
+		return "chat_message", true
+	case *livekit.DataPacket_Transcription:
+		return "transcription", true
+	case *livekit.DataPacket_SipDtmf:
+		return "sip_dtmf", true
+	case *livekit.DataPacket_RpcRequest:
+		return "rpc", true
+	case *livekit.DataPacket_RpcResponse, *livekit.DataPacket_RpcAck:
+		return "rpc", false
+	case *livekit.DataPacket_StreamHeader:
+		return "stream", true
+	case *livekit.DataPacket_StreamChunk, *livekit.DataPacket_StreamTrailer:
+		return "stream", false
+	case *livekit.DataPacket_EncryptedPacket:
+		return "encrypted", true
+	default:
+		return "other", true
+	}
+}
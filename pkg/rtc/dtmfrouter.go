@@ -0,0 +1,328 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// topic of the data packets carrying start and end of in-band DTMF digits
+	DTMFTopic = "agentix.dtmf"
+
+	dtmfSubscriberPrefix     = "DTM_"
+	dtmfToneSubscriberPrefix = "DTT_"
+)
+
+// DTMFSource is how a digit was sent
+type DTMFSource string
+
+const (
+	DTMFSourceTelephoneEvent DTMFSource = "telephone_event"
+	DTMFSourceTone           DTMFSource = "tone"
+)
+
+type DTMFEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	Code                uint32                      `json:"code"`
+	Digit               string                      `json:"digit"`
+	Phase               audio.TelephoneEventPhase   `json:"phase"`
+	Source              DTMFSource                  `json:"source"`
+	DurationMs          int64                       `json:"duration_ms,omitempty"`
+	// the end was inferred after the end packets of the event were lost
+	EndLost bool `json:"end_lost,omitempty"`
+}
+
+type DTMFRouterParams struct {
+	Config  audio.TelephoneEventConfig
+	Logger  logger.Logger
+	OnEvent func(event *DTMFEvent)
+	// workers tone detection runs on, detection runs on the forwarding path when nil
+	Placement     func() *placement.Slot
+	TrackPriority func(track types.MediaTrack) placement.Priority
+}
+
+// DTMFRouter follows the RFC 4733 telephone events sent along published audio tracks, and optionally
+// the DTMF tones in their audio, and reports every digit once when it starts and once when it ends.
+// Tones of a track are ignored once it has sent telephone events, gateways may send digits both ways.
+type DTMFRouter struct {
+	params DTMFRouterParams
+
+	lock    sync.Mutex
+	taps    map[livekit.TrackID]*dtmfTap
+	stopped core.Fuse
+}
+
+func NewDTMFRouter(params DTMFRouterParams) *DTMFRouter {
+	if params.Config.EndTimeout <= 0 {
+		params.Config.EndTimeout = audio.DefaultTelephoneEventConfig.EndTimeout
+	}
+
+	d := &DTMFRouter{
+		params: params,
+		taps:   make(map[livekit.TrackID]*dtmfTap),
+	}
+	go d.expireWorker()
+	return d
+}
+
+func (d *DTMFRouter) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if d == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	receivers := track.Receivers()
+	if len(receivers) == 0 {
+		return
+	}
+
+	// telephone events are negotiated at the clock rate of the audio codec they accompany
+	tap := &dtmfTap{
+		publisher: publisher,
+		track:     track,
+		tracker:   audio.NewTelephoneEventTracker(receivers[0].Codec().ClockRate, d.params.Config.EndTimeout),
+	}
+	tap.receiverTap = newReceiverTap(dtmfSubscriberPrefix, track.ID(), receivers[0], func(_ *buffer.ExtPacket) {})
+	tap.handleTelephoneEvents(func(p *buffer.ExtPacket) {
+		d.onTelephoneEvent(tap, p)
+	})
+	if d.params.Config.Tones.Enabled {
+		d.tapTones(tap)
+	}
+
+	d.lock.Lock()
+	if _, ok := d.taps[track.ID()]; ok {
+		d.lock.Unlock()
+		return
+	}
+	d.taps[track.ID()] = tap
+	d.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		d.params.Logger.Warnw("could not tap receiver for dtmf", err, "trackID", track.ID())
+		d.RemoveTrack(track.ID())
+		return
+	}
+	if tap.toneTap != nil {
+		if err := tap.toneTap.start(); err != nil {
+			d.params.Logger.Warnw("could not tap receiver for dtmf tones", err, "trackID", track.ID())
+		}
+	}
+}
+
+// tapTones decodes the Opus audio of the track of tap to detect the tones of digits in it
+func (d *DTMFRouter) tapTones(tap *dtmfTap) {
+	receiver := opusReceiver(tap.track)
+	if receiver == nil {
+		return
+	}
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		d.params.Logger.Debugw("dtmf tone detection disabled", "error", err, "trackID", tap.track.ID())
+		return
+	}
+
+	tap.decoder = decoder
+	tap.pcm = make([]int16, audio.OpusMaxFrameSize)
+	tap.tones = audio.NewDTMFToneDetector(d.params.Config.Tones, audio.OpusSampleRate)
+	tap.toneTap = newReceiverTap(dtmfToneSubscriberPrefix, tap.track.ID(), receiver, func(p *buffer.ExtPacket) {
+		d.onAudio(tap, p)
+	})
+	tap.toneTap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(1))
+	if d.params.Placement != nil {
+		tap.toneTap.schedule(d.params.Placement(), func() placement.Priority { return d.params.TrackPriority(tap.track) })
+	}
+}
+
+func (d *DTMFRouter) RemoveTrack(trackID livekit.TrackID) {
+	if d == nil {
+		return
+	}
+
+	d.lock.Lock()
+	tap, ok := d.taps[trackID]
+	delete(d.taps, trackID)
+	d.lock.Unlock()
+
+	if ok {
+		d.close(tap)
+	}
+}
+
+func (d *DTMFRouter) Stop() {
+	if d == nil {
+		return
+	}
+
+	d.stopped.Break()
+
+	d.lock.Lock()
+	taps := d.taps
+	d.taps = make(map[livekit.TrackID]*dtmfTap)
+	d.lock.Unlock()
+
+	for _, tap := range taps {
+		d.close(tap)
+	}
+}
+
+// close ends a digit that is still held down when its track goes away
+func (d *DTMFRouter) close(tap *dtmfTap) {
+	tap.stop()
+	if tap.toneTap != nil {
+		tap.toneTap.stop()
+	}
+
+	tap.lock.Lock()
+	update := tap.tracker.Flush()
+	var toneUpdate *audio.TelephoneEventUpdate
+	if tap.tones != nil {
+		toneUpdate = tap.tones.Flush()
+	}
+	tap.lock.Unlock()
+
+	if update != nil {
+		d.emit(tap, *update, DTMFSourceTelephoneEvent)
+	}
+	if toneUpdate != nil {
+		d.emit(tap, *toneUpdate, DTMFSourceTone)
+	}
+}
+
+func (d *DTMFRouter) onTelephoneEvent(tap *dtmfTap, p *buffer.ExtPacket) {
+	ev, err := audio.ParseTelephoneEvent(p.Packet.Payload)
+	if err != nil {
+		d.params.Logger.Debugw("invalid telephone event", err, "trackID", tap.track.ID())
+		return
+	}
+
+	tap.telephoneEvents.Store(true)
+
+	tap.lock.Lock()
+	updates := tap.tracker.Push(ev, p.Packet.Timestamp, time.Now())
+	tap.lock.Unlock()
+
+	for _, update := range updates {
+		d.emit(tap, update, DTMFSourceTelephoneEvent)
+	}
+}
+
+func (d *DTMFRouter) onAudio(tap *dtmfTap, p *buffer.ExtPacket) {
+	tap.lock.Lock()
+	var updates []audio.TelephoneEventUpdate
+	if tap.telephoneEvents.Load() {
+		// a digit detected before the first telephone event still ends
+		if update := tap.tones.Flush(); update != nil {
+			updates = append(updates, *update)
+		}
+	} else if n, err := tap.decoder.Decode(p.Packet.Payload, tap.pcm); err == nil {
+		updates = tap.tones.Push(tap.pcm[:n])
+	}
+	tap.lock.Unlock()
+
+	for _, update := range updates {
+		d.emit(tap, update, DTMFSourceTone)
+	}
+}
+
+func (d *DTMFRouter) expireWorker() {
+	ticker := time.NewTicker(d.params.Config.EndTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stopped.Watch():
+			return
+
+		case now := <-ticker.C:
+			d.lock.Lock()
+			taps := make([]*dtmfTap, 0, len(d.taps))
+			for _, tap := range d.taps {
+				taps = append(taps, tap)
+			}
+			d.lock.Unlock()
+
+			for _, tap := range taps {
+				tap.lock.Lock()
+				update := tap.tracker.Expire(now)
+				tap.lock.Unlock()
+
+				if update != nil {
+					d.emit(tap, *update, DTMFSourceTelephoneEvent)
+				}
+			}
+		}
+	}
+}
+
+func (d *DTMFRouter) emit(tap *dtmfTap, update audio.TelephoneEventUpdate, source DTMFSource) {
+	d.params.Logger.Debugw(
+		"dtmf",
+		"participant", tap.publisher.Identity(),
+		"trackID", tap.track.ID(),
+		"digit", update.Digit,
+		"phase", update.Phase,
+		"source", source,
+		"duration", update.Duration,
+		"endLost", update.EndLost,
+	)
+
+	if d.params.OnEvent != nil {
+		d.params.OnEvent(&DTMFEvent{
+			ParticipantIdentity: tap.publisher.Identity(),
+			TrackID:             tap.track.ID(),
+			Code:                uint32(update.Event),
+			Digit:               update.Digit,
+			Phase:               update.Phase,
+			Source:              source,
+			DurationMs:          update.Duration.Milliseconds(),
+			EndLost:             update.EndLost,
+		})
+	}
+}
+
+// --------------------------------------
+
+type dtmfTap struct {
+	*receiverTap
+
+	publisher types.LocalParticipant
+	track     types.MediaTrack
+
+	lock    sync.Mutex
+	tracker *audio.TelephoneEventTracker
+	// set once the track has sent a telephone event
+	telephoneEvents atomic.Bool
+
+	// nil unless tones are detected, the tap receives the decoded audio of the track
+	toneTap *receiverTap
+	tones   *audio.DTMFToneDetector
+	decoder audio.OpusDecoder
+	pcm     []int16
+}
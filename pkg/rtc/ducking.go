@@ -0,0 +1,173 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// the ramps advance a packet at a time
+	duckingSampleInterval = 20 * time.Millisecond
+)
+
+type DuckingControllerParams struct {
+	Config audio.DuckingConfig
+}
+
+type duckedTrack struct {
+	publisher types.LocalParticipant
+	// gain in dB last set on the track
+	gainDB float64
+}
+
+// DuckingController attenuates the audio tracks of everybody else while an agent speaks, following the
+// voice activity of the agents' tracks. The attenuation is applied in the noise filter of the publishers
+// on top of the gain operators set, so it reaches every subscriber of the tracks.
+type DuckingController struct {
+	params DuckingControllerParams
+	ducker *audio.Ducker
+
+	lock    sync.Mutex
+	agents  map[livekit.TrackID]types.MediaTrack
+	ducked  map[livekit.TrackID]*duckedTrack
+	stopped core.Fuse
+}
+
+func NewDuckingController(params DuckingControllerParams) *DuckingController {
+	c := &DuckingController{
+		params: params,
+		ducker: audio.NewDucker(params.Config),
+		agents: make(map[livekit.TrackID]types.MediaTrack),
+		ducked: make(map[livekit.TrackID]*duckedTrack),
+	}
+	go c.worker()
+	return c
+}
+
+// AddTrack follows the audio tracks of the designated agents and ducks the audio tracks of everybody else
+func (c *DuckingController) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if c == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	switch {
+	case publisher.IsAgent() && c.params.Config.IsAgent(string(publisher.Identity())):
+		c.agents[track.ID()] = track
+	case publisher.IsAgent():
+		// agents not designated are neither followed nor ducked
+	default:
+		if _, ok := c.ducked[track.ID()]; !ok {
+			c.ducked[track.ID()] = &duckedTrack{publisher: publisher}
+		}
+	}
+}
+
+func (c *DuckingController) RemoveTrack(trackID livekit.TrackID) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	delete(c.agents, trackID)
+	t := c.ducked[trackID]
+	delete(c.ducked, trackID)
+	c.lock.Unlock()
+
+	if t != nil && t.gainDB != 0 {
+		setTrackDucking(t.publisher, trackID, 0)
+	}
+}
+
+func (c *DuckingController) Stop() {
+	if c == nil {
+		return
+	}
+
+	c.stopped.Break()
+
+	c.lock.Lock()
+	ducked := c.ducked
+	c.ducked = make(map[livekit.TrackID]*duckedTrack)
+	c.agents = make(map[livekit.TrackID]types.MediaTrack)
+	c.lock.Unlock()
+
+	for trackID, t := range ducked {
+		if t.gainDB != 0 {
+			setTrackDucking(t.publisher, trackID, 0)
+		}
+	}
+}
+
+func (c *DuckingController) worker() {
+	ticker := time.NewTicker(duckingSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopped.Watch():
+			return
+
+		case now := <-ticker.C:
+			c.lock.Lock()
+			speaking := false
+			for _, track := range c.agents {
+				if _, isSpeaking := track.GetAudioLevel(); isSpeaking && !track.IsMuted() {
+					speaking = true
+					break
+				}
+			}
+			gainDB := c.ducker.Observe(now, speaking)
+
+			type change struct {
+				trackID   livekit.TrackID
+				publisher types.LocalParticipant
+			}
+			var changes []change
+			for trackID, t := range c.ducked {
+				if t.gainDB != gainDB {
+					t.gainDB = gainDB
+					changes = append(changes, change{trackID: trackID, publisher: t.publisher})
+				}
+			}
+			c.lock.Unlock()
+
+			for _, ch := range changes {
+				setTrackDucking(ch.publisher, ch.trackID, gainDB)
+			}
+		}
+	}
+}
+
+func setTrackDucking(publisher types.LocalParticipant, trackID livekit.TrackID, gainDB float64) {
+	if p, ok := publisher.(interface {
+		SetTrackDucking(trackID livekit.TrackID, gainDB float64) error
+	}); ok {
+		// the track may be gone already, without a noise filter there is no stage to duck in
+		_ = p.SetTrackDucking(trackID, gainDB)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	echoReferenceSubscriberPrefix = "AEC_"
+)
+
+type EchoCancellationParams struct {
+	Config audio.EchoCancellationConfig
+	Logger logger.Logger
+	// workers decoding runs on, decoding runs on the forwarding path when nil
+	Placement     func() *placement.Slot
+	TrackPriority func(track types.MediaTrack) placement.Priority
+}
+
+// EchoCancellation keeps agents that both speak and listen from hearing and transcribing themselves.
+// The audio agents publish is decoded into the echo reference of the room, the noise filter of every
+// other publisher cancels it from the microphone audio ahead of denoising.
+type EchoCancellation struct {
+	params    EchoCancellationParams
+	reference *audio.EchoReference
+
+	lock sync.Mutex
+	taps map[livekit.TrackID]*echoReferenceTap
+	// publishers of the tracks the reference is cancelled from
+	cancelled map[livekit.TrackID]types.LocalParticipant
+	stopped   core.Fuse
+}
+
+func NewEchoCancellation(params EchoCancellationParams) *EchoCancellation {
+	return &EchoCancellation{
+		params:    params,
+		reference: audio.NewEchoReference(params.Config),
+		taps:      make(map[livekit.TrackID]*echoReferenceTap),
+		cancelled: make(map[livekit.TrackID]types.LocalParticipant),
+	}
+}
+
+// AddTrack feeds the audio tracks of agents into the echo reference and cancels it from the audio tracks
+// of everybody else, other track types are ignored
+func (e *EchoCancellation) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if e == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	if publisher.IsAgent() {
+		e.addReference(track)
+	} else {
+		e.addCancelled(publisher, track)
+	}
+}
+
+func (e *EchoCancellation) addReference(track types.MediaTrack) {
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		e.params.Logger.Debugw("no opus receiver, not cancelling echo of track", "trackID", track.ID())
+		return
+	}
+
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		e.params.Logger.Warnw("could not create decoder, not cancelling echo of track", err, "trackID", track.ID())
+		return
+	}
+
+	tap := &echoReferenceTap{
+		reference: e.reference,
+		decoder:   decoder,
+		pcm:       make([]int16, audio.OpusMaxFrameSize),
+	}
+	tap.receiverTap = newReceiverTap(echoReferenceSubscriberPrefix, track.ID(), receiver, tap.onPacket)
+	tap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(1))
+	if e.params.Placement != nil {
+		tap.schedule(e.params.Placement(), func() placement.Priority { return e.params.TrackPriority(track) })
+	}
+
+	e.lock.Lock()
+	if e.stopped.IsBroken() {
+		e.lock.Unlock()
+		return
+	}
+	if _, ok := e.taps[track.ID()]; ok {
+		e.lock.Unlock()
+		return
+	}
+	e.taps[track.ID()] = tap
+	e.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		e.params.Logger.Warnw("could not tap receiver", err, "trackID", track.ID())
+		e.RemoveTrack(track.ID())
+	}
+}
+
+func (e *EchoCancellation) addCancelled(publisher types.LocalParticipant, track types.MediaTrack) {
+	p, ok := publisher.(interface {
+		SetTrackEchoReference(trackID livekit.TrackID, reference *audio.EchoReference) error
+	})
+	if !ok {
+		return
+	}
+
+	e.lock.Lock()
+	if e.stopped.IsBroken() {
+		e.lock.Unlock()
+		return
+	}
+	if _, ok := e.cancelled[track.ID()]; ok {
+		e.lock.Unlock()
+		return
+	}
+	e.cancelled[track.ID()] = publisher
+	e.lock.Unlock()
+
+	if err := p.SetTrackEchoReference(track.ID(), e.reference); err != nil {
+		// without a noise filter there is no stage to cancel in
+		e.params.Logger.Debugw("not cancelling echo from track", "error", err, "trackID", track.ID())
+	}
+}
+
+func (e *EchoCancellation) RemoveTrack(trackID livekit.TrackID) {
+	if e == nil {
+		return
+	}
+
+	e.lock.Lock()
+	tap := e.taps[trackID]
+	delete(e.taps, trackID)
+	publisher := e.cancelled[trackID]
+	delete(e.cancelled, trackID)
+	e.lock.Unlock()
+
+	if tap != nil {
+		tap.close()
+	}
+	if publisher != nil {
+		clearEchoReference(publisher, trackID)
+	}
+}
+
+func (e *EchoCancellation) Stop() {
+	if e == nil {
+		return
+	}
+
+	e.stopped.Break()
+
+	e.lock.Lock()
+	taps := e.taps
+	e.taps = make(map[livekit.TrackID]*echoReferenceTap)
+	cancelled := e.cancelled
+	e.cancelled = make(map[livekit.TrackID]types.LocalParticipant)
+	e.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.close()
+	}
+	for trackID, publisher := range cancelled {
+		clearEchoReference(publisher, trackID)
+	}
+}
+
+func clearEchoReference(publisher types.LocalParticipant, trackID livekit.TrackID) {
+	if p, ok := publisher.(interface {
+		SetTrackEchoReference(trackID livekit.TrackID, reference *audio.EchoReference) error
+	}); ok {
+		// the track may be gone already
+		_ = p.SetTrackEchoReference(trackID, nil)
+	}
+}
+
+// --------------------------------------
+
+// echoReferenceTap decodes the packets of an agent's track into the echo reference
+type echoReferenceTap struct {
+	*receiverTap
+
+	reference *audio.EchoReference
+	decoder   audio.OpusDecoder
+	pcm       []int16
+}
+
+func (t *echoReferenceTap) close() {
+	t.stop()
+	t.reference.RemoveSource(string(t.trackID))
+}
+
+func (t *echoReferenceTap) onPacket(p *buffer.ExtPacket) {
+	n, err := t.decoder.Decode(p.Packet.Payload, t.pcm)
+	if err != nil {
+		// a corrupt packet leaves a gap, the reference is silent there
+		return
+	}
+
+	// the reference follows the time the audio reached the server, workers may process it later
+	received := time.Now()
+	if p.Arrival != 0 {
+		received = time.Unix(0, p.Arrival)
+	}
+	t.reference.Write(string(t.trackID), t.pcm[:n], received)
+}
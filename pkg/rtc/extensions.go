@@ -0,0 +1,119 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+const (
+	// participant attribute with the highest version of the AgentIX extensions a client speaks, set in the
+	// token or the join request. At join the server replaces it with the negotiated version and sets
+	// ExtensionFeaturesAttribute. Clients without it get no extension attributes, as before negotiation existed.
+	ExtensionProtocolAttribute = "agentix.protocol"
+	// participant attribute set at join with the extensions available to the participant, comma separated
+	ExtensionFeaturesAttribute = "agentix.features"
+
+	// highest version of the extensions this server speaks
+	CurrentExtensionProtocol = 1
+)
+
+// ExtensionFeature is a capability flag of the extensions, it names a topic, attribute or API a client can rely on
+type ExtensionFeature string
+
+const (
+	// server processing controls
+	ExtensionProcessingBypass ExtensionFeature = "processing_bypass"
+	ExtensionStageBypass      ExtensionFeature = "stage_bypass"
+	ExtensionNoiseProfile     ExtensionFeature = "noise_profile"
+	ExtensionAudioMix         ExtensionFeature = "audio_mix"
+	ExtensionConsent          ExtensionFeature = "consent"
+
+	// track attributes
+	ExtensionTrackPriority ExtensionFeature = "track_priority"
+
+	// topic data
+	ExtensionSTTGate        ExtensionFeature = "stt_gate"
+	ExtensionIdle           ExtensionFeature = "idle"
+	ExtensionDTMF           ExtensionFeature = "dtmf"
+	ExtensionTalkAnalytics  ExtensionFeature = "talk_analytics"
+	ExtensionMicQuality     ExtensionFeature = "mic_quality"
+	ExtensionTrackHealth    ExtensionFeature = "track_health"
+	ExtensionDataModeration ExtensionFeature = "data_moderation"
+	ExtensionStatsRPC       ExtensionFeature = "stats_rpc"
+)
+
+type extensionFeature struct {
+	feature ExtensionFeature
+	// first protocol version the feature is part of
+	since   int
+	enabled func(conf *config.Config) bool
+}
+
+func alwaysEnabled(*config.Config) bool { return true }
+
+var extensionFeatures = []extensionFeature{
+	{ExtensionProcessingBypass, 1, alwaysEnabled},
+	{ExtensionStageBypass, 1, func(conf *config.Config) bool { return conf.Audio.NoiseFilter.Enabled }},
+	{ExtensionNoiseProfile, 1, func(conf *config.Config) bool { return conf.Audio.NoiseProfile.Enabled }},
+	{ExtensionAudioMix, 1, func(conf *config.Config) bool { return conf.Audio.Mixing.Enabled }},
+	{ExtensionConsent, 1, func(conf *config.Config) bool { return conf.Room.Consent.Enabled }},
+	{ExtensionTrackPriority, 1, func(conf *config.Config) bool { return conf.Placement.Enabled }},
+	{ExtensionSTTGate, 1, func(conf *config.Config) bool { return conf.Audio.STTGate.Enabled }},
+	{ExtensionIdle, 1, func(conf *config.Config) bool { return conf.Room.IdleReaper.Enabled }},
+	{ExtensionDTMF, 1, func(conf *config.Config) bool { return conf.Audio.TelephoneEvents.Enabled }},
+	{ExtensionTalkAnalytics, 1, func(conf *config.Config) bool { return conf.Room.TalkAnalytics.Enabled }},
+	{ExtensionMicQuality, 1, func(conf *config.Config) bool { return conf.Audio.MicQuality.Enabled }},
+	{ExtensionTrackHealth, 1, func(conf *config.Config) bool { return conf.Room.TrackWatchdog.Enabled }},
+	{ExtensionDataModeration, 1, func(conf *config.Config) bool { return conf.Room.DataModeration.Enabled }},
+	{ExtensionStatsRPC, 1, alwaysEnabled},
+}
+
+// ExtensionFeatures returns the features of a protocol version that are turned on in the configuration
+func ExtensionFeatures(conf *config.Config, version int) []ExtensionFeature {
+	var features []ExtensionFeature
+	for _, f := range extensionFeatures {
+		if f.since <= version && f.enabled(conf) {
+			features = append(features, f.feature)
+		}
+	}
+	return features
+}
+
+// NegotiateExtensions returns the attributes to set on a joining participant given its attributes,
+// nil when the client did not ask for the extensions
+func NegotiateExtensions(conf *config.Config, attributes map[string]string) map[string]string {
+	requested, err := strconv.Atoi(strings.TrimSpace(attributes[ExtensionProtocolAttribute]))
+	if err != nil || requested < 1 {
+		return nil
+	}
+
+	version := min(requested, CurrentExtensionProtocol)
+	features := make([]string, 0, len(extensionFeatures))
+	for _, f := range ExtensionFeatures(conf, version) {
+		features = append(features, string(f))
+	}
+	slices.Sort(features)
+
+	return map[string]string{
+		ExtensionProtocolAttribute: strconv.Itoa(version),
+		ExtensionFeaturesAttribute: strings.Join(features, ","),
+	}
+}
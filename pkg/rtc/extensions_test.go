@@ -0,0 +1,55 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+func TestNegotiateExtensions(t *testing.T) {
+	conf := &config.Config{}
+	conf.Audio.STTGate.Enabled = true
+	conf.Room.Consent.Enabled = true
+
+	t.Run("old clients are left alone", func(t *testing.T) {
+		require.Nil(t, NegotiateExtensions(conf, nil))
+		require.Nil(t, NegotiateExtensions(conf, map[string]string{"kind": "agent"}))
+		require.Nil(t, NegotiateExtensions(conf, map[string]string{ExtensionProtocolAttribute: "v2"}))
+		require.Nil(t, NegotiateExtensions(conf, map[string]string{ExtensionProtocolAttribute: "0"}))
+	})
+
+	t.Run("features of the enabled subsystems", func(t *testing.T) {
+		attrs := NegotiateExtensions(conf, map[string]string{ExtensionProtocolAttribute: "1"})
+		require.Equal(t, map[string]string{
+			ExtensionProtocolAttribute: "1",
+			ExtensionFeaturesAttribute: "consent,processing_bypass,stats_rpc,stt_gate",
+		}, attrs)
+	})
+
+	t.Run("newer clients get the server version", func(t *testing.T) {
+		attrs := NegotiateExtensions(conf, map[string]string{ExtensionProtocolAttribute: "99"})
+		require.Equal(t, "1", attrs[ExtensionProtocolAttribute])
+	})
+
+	t.Run("features are part of a version", func(t *testing.T) {
+		require.Empty(t, ExtensionFeatures(conf, 0))
+		require.Contains(t, ExtensionFeatures(conf, CurrentExtensionProtocol), ExtensionSTTGate)
+	})
+}
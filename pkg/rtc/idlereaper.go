@@ -0,0 +1,208 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/reaper"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+)
+
+const (
+	// topic of the data packets warning about idle participants and rooms
+	IdleTopic = "agentix.idle"
+
+	// webhook events sent ahead of and when the idle reaper acts
+	WebhookEventParticipantIdleWarning      = "participant_idle_warning"
+	WebhookEventParticipantIdleDisconnected = "participant_idle_disconnected"
+	WebhookEventRoomIdleWarning             = "room_idle_warning"
+	WebhookEventRoomIdleClosed              = "room_idle_closed"
+)
+
+// IdleEvent is about a participant when ParticipantIdentity is set, about the room otherwise
+type IdleEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity,omitempty"`
+	Action              reaper.Action               `json:"action"`
+	IdleForMs           int64                       `json:"idle_for_ms"`
+	// time left until the participant is disconnected or the room is closed, for warnings
+	RemainingMs int64 `json:"remaining_ms,omitempty"`
+}
+
+type IdleReaperParams struct {
+	Config          reaper.Config
+	Logger          logger.Logger
+	GetParticipants func() []types.LocalParticipant
+	// p is nil for room events
+	OnEvent func(p types.LocalParticipant, event *IdleEvent)
+}
+
+// IdleReaper disconnects participants that neither send media nor data for a while and closes
+// rooms nobody is active in, warning ahead of both. Agents and recorders do not keep a room alive
+// and are not disconnected.
+type IdleReaper struct {
+	params IdleReaperParams
+
+	lock         sync.Mutex
+	participants map[livekit.ParticipantIdentity]*reaper.Tracker
+	room         *reaper.Tracker
+
+	stopped core.Fuse
+}
+
+func NewIdleReaper(params IdleReaperParams) *IdleReaper {
+	if params.Config.CheckInterval <= 0 {
+		params.Config.CheckInterval = reaper.DefaultConfig.CheckInterval
+	}
+
+	r := &IdleReaper{
+		params:       params,
+		participants: make(map[livekit.ParticipantIdentity]*reaper.Tracker),
+		room:         reaper.NewTracker(params.Config.RoomTimeout, params.Config.WarningPeriod, time.Now()),
+	}
+	go r.checkWorker()
+	return r
+}
+
+func (r *IdleReaper) Stop() {
+	if r == nil {
+		return
+	}
+
+	r.stopped.Break()
+}
+
+// Touch records data activity of a participant
+func (r *IdleReaper) Touch(p types.LocalParticipant) {
+	if r == nil || p == nil || p.IsDependent() {
+		return
+	}
+
+	r.touch(p, time.Now())
+}
+
+func (r *IdleReaper) RemoveParticipant(identity livekit.ParticipantIdentity) {
+	if r == nil {
+		return
+	}
+
+	r.lock.Lock()
+	delete(r.participants, identity)
+	r.lock.Unlock()
+}
+
+func (r *IdleReaper) touch(p types.LocalParticipant, at time.Time) {
+	if r.trackerFor(p, at).Touch(at) {
+		r.params.Logger.Infow("idle participant became active", "participant", p.Identity())
+	}
+	if r.room.Touch(at) {
+		r.params.Logger.Infow("idle room became active")
+	}
+}
+
+func (r *IdleReaper) trackerFor(p types.LocalParticipant, now time.Time) *reaper.Tracker {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	tracker, ok := r.participants[p.Identity()]
+	if !ok {
+		tracker = reaper.NewTracker(r.params.Config.ParticipantTimeout, r.params.Config.WarningPeriod, now)
+		r.participants[p.Identity()] = tracker
+	}
+	return tracker
+}
+
+func (r *IdleReaper) checkWorker() {
+	ticker := time.NewTicker(r.params.Config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			r.check(time.Now())
+		}
+	}
+}
+
+func (r *IdleReaper) check(now time.Time) {
+	for _, p := range r.params.GetParticipants() {
+		if p.IsDependent() || p.IsDisconnected() {
+			continue
+		}
+
+		if at := lastMediaAt(p); !at.IsZero() {
+			r.touch(p, at)
+		}
+		if r.params.Config.IsExempt(participantRole(p)) {
+			continue
+		}
+
+		tracker := r.trackerFor(p, now)
+		if action, idle := tracker.Check(now); action != reaper.ActionNone {
+			r.params.Logger.Infow("participant idle", "participant", p.Identity(), "action", action, "idleFor", idle)
+			r.notify(p, &IdleEvent{
+				ParticipantIdentity: p.Identity(),
+				Action:              action,
+				IdleForMs:           idle.Milliseconds(),
+				RemainingMs:         tracker.Remaining(now).Milliseconds(),
+			})
+		}
+	}
+
+	if action, idle := r.room.Check(now); action != reaper.ActionNone {
+		r.params.Logger.Infow("room idle", "action", action, "idleFor", idle)
+		r.notify(nil, &IdleEvent{
+			Action:      action,
+			IdleForMs:   idle.Milliseconds(),
+			RemainingMs: r.room.Remaining(now).Milliseconds(),
+		})
+	}
+}
+
+func (r *IdleReaper) notify(p types.LocalParticipant, event *IdleEvent) {
+	if r.params.OnEvent != nil && !r.stopped.IsBroken() {
+		r.params.OnEvent(p, event)
+	}
+}
+
+// lastMediaAt returns the time of the last RTCP sender report of the unmuted published tracks
+// of a participant, publishers stop sending reports when they stop sending media
+func lastMediaAt(p types.LocalParticipant) time.Time {
+	var last time.Time
+	for _, track := range p.GetPublishedTracks() {
+		if track.IsMuted() {
+			continue
+		}
+		for _, receiver := range track.Receivers() {
+			if wr, ok := receiver.(*sfu.WebRTCReceiver); ok {
+				if at := wr.GetLastSenderReportTime(); at.After(last) {
+					last = at
+				}
+			}
+		}
+	}
+	return last
+}
@@ -0,0 +1,536 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/audioinject"
+	"github.com/livekit/livekit-server/pkg/ingest"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// stream ID of the tracks of an ingested stream is the prefix followed by the identity it publishes as
+	IngestStreamIDPrefix = "agentix_ingest_"
+
+	// topic of the data packets telling participants that a stream of an encoder started, reconnected or stopped
+	IngestTopic = "agentix.ingest"
+
+	IngestEventStarted     = "started"
+	IngestEventReconnected = "reconnected"
+	IngestEventStopped     = "stopped"
+
+	ingestVideoTrackPrefix = "TR_ING_"
+
+	// audio of an encoder queued ahead of playback, it sends in real time
+	ingestAudioBuffer = time.Second
+	// duration of video frames whose successor does not tell, e. g. across a reconnect
+	ingestDefaultFrameDuration = time.Second / 30
+)
+
+var (
+	ErrIngestDisabled = errors.New("stream ingest is disabled")
+)
+
+// IngestEvent tells participants about the stream of an encoder
+type IngestEvent struct {
+	Event               string                      `json:"event"`
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	Name                string                      `json:"name,omitempty"`
+	Protocol            ingest.Protocol             `json:"protocol"`
+	// server side tracks of the stream
+	VideoTrackID livekit.TrackID `json:"video_track_id"`
+	AudioTrackID livekit.TrackID `json:"audio_track_id"`
+}
+
+type IngestsParams struct {
+	Logger  logger.Logger
+	OnEvent func(event *IngestEvent)
+}
+
+// IngestParams describes the stream of an encoder connecting
+type IngestParams struct {
+	// who the stream publishes as, from the token of the encoder
+	Identity livekit.ParticipantIdentity
+	Name     string
+	Protocol ingest.Protocol
+	// the tracks of the stream stay published this long after its encoder disconnected, for it to reconnect
+	ReconnectTimeout time.Duration
+}
+
+// Ingests publishes the streams of broadcast encoders as server side tracks every participant receives, an
+// H.264 video track and an Opus audio track per stream. An encoder reconnecting with the same identity within
+// the reconnect timeout continues on the tracks of its stream, and one connecting while its previous
+// connection is still up takes the stream over. Encoders cannot be asked for keyframes, participants see
+// video from the next keyframe the encoder sends.
+type Ingests struct {
+	params IngestsParams
+	audio  *AudioInjections
+
+	// serializes Start, so that an identity gets a single stream
+	startLock sync.Mutex
+
+	lock    sync.Mutex
+	streams map[livekit.ParticipantIdentity]*ingestStream
+	viewers map[livekit.ParticipantID]types.LocalParticipant
+	stopped core.Fuse
+}
+
+func NewIngests(params IngestsParams) *Ingests {
+	return &Ingests{
+		params: params,
+		audio: NewAudioInjections(AudioInjectionsParams{
+			Config: audioinject.Config{
+				BufferDuration: ingestAudioBuffer,
+			},
+			Logger:  params.Logger,
+			OnEvent: func(event *AudioInjectionEvent) {},
+		}),
+		streams: make(map[livekit.ParticipantIdentity]*ingestStream),
+		viewers: make(map[livekit.ParticipantID]types.LocalParticipant),
+	}
+}
+
+// Start returns the publisher of an encoder connecting, continuing the stream of its identity if there is one
+func (i *Ingests) Start(params IngestParams) (ingest.Publisher, error) {
+	if i == nil {
+		return nil, ErrIngestDisabled
+	}
+
+	i.startLock.Lock()
+	defer i.startLock.Unlock()
+
+	i.lock.Lock()
+	if i.stopped.IsBroken() {
+		i.lock.Unlock()
+		return nil, ErrIngestDisabled
+	}
+	s := i.streams[params.Identity]
+	i.lock.Unlock()
+
+	if s != nil {
+		if publisher, ok := s.attach(params); ok {
+			i.params.Logger.Infow("ingest reconnected", "identity", params.Identity, "protocol", params.Protocol)
+			i.params.OnEvent(s.event(IngestEventReconnected))
+			return publisher, nil
+		}
+		// stopped meanwhile, a new stream replaces it
+	}
+
+	s, err := i.newStream(params)
+	if err != nil {
+		return nil, err
+	}
+
+	i.lock.Lock()
+	if i.stopped.IsBroken() {
+		i.lock.Unlock()
+		s.close()
+		return nil, ErrIngestDisabled
+	}
+	i.streams[params.Identity] = s
+	viewers := make([]types.LocalParticipant, 0, len(i.viewers))
+	for _, p := range i.viewers {
+		viewers = append(viewers, p)
+	}
+	i.lock.Unlock()
+
+	for _, p := range viewers {
+		i.addSender(s, p)
+	}
+
+	publisher, _ := s.attach(params)
+	i.params.Logger.Infow(
+		"ingest started",
+		"identity", params.Identity,
+		"protocol", params.Protocol,
+		"videoTrackID", s.videoTrackID,
+		"audioTrackID", s.audioTrackID,
+	)
+	i.params.OnEvent(s.event(IngestEventStarted))
+	return publisher, nil
+}
+
+func (i *Ingests) AddViewer(p types.LocalParticipant) {
+	if i == nil {
+		return
+	}
+
+	i.lock.Lock()
+	if i.stopped.IsBroken() {
+		i.lock.Unlock()
+		return
+	}
+	if _, ok := i.viewers[p.ID()]; ok {
+		i.lock.Unlock()
+		return
+	}
+	i.viewers[p.ID()] = p
+	streams := i.streamsLocked()
+	i.lock.Unlock()
+
+	for _, s := range streams {
+		i.addSender(s, p)
+	}
+	i.audio.AddViewer(p)
+}
+
+func (i *Ingests) RemoveViewer(p types.LocalParticipant) {
+	if i == nil {
+		return
+	}
+
+	i.lock.Lock()
+	delete(i.viewers, p.ID())
+	streams := i.streamsLocked()
+	i.lock.Unlock()
+
+	for _, s := range streams {
+		s.removeSender(p)
+	}
+	i.audio.RemoveViewer(p)
+}
+
+// Close stops all streams, their encoders are disconnected on their next write
+func (i *Ingests) Close() {
+	if i == nil {
+		return
+	}
+
+	i.lock.Lock()
+	i.stopped.Break()
+	streams := i.streamsLocked()
+	i.lock.Unlock()
+
+	for _, s := range streams {
+		i.stop(s)
+	}
+	i.audio.Close()
+}
+
+func (i *Ingests) newStream(params IngestParams) (*ingestStream, error) {
+	videoTrackID := livekit.TrackID(guid.New(ingestVideoTrackPrefix))
+	video, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{
+			MimeType:  webrtc.MimeTypeH264,
+			ClockRate: 90000,
+			// matched against the H.264 of participants, the profile of the encoder is sent as is
+			SDPFmtpLine: "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42e01f",
+		},
+		string(videoTrackID),
+		IngestStreamIDPrefix+string(params.Identity),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &ingestStream{
+		i:            i,
+		identity:     params.Identity,
+		name:         params.Name,
+		videoTrackID: videoTrackID,
+		video:        video,
+		ctx:          ctx,
+		cancel:       cancel,
+		senders:      make(map[livekit.ParticipantID]*webrtc.RTPSender),
+	}
+
+	s.audioTrackID, s.player, err = i.audio.Start(
+		string(params.Identity),
+		nil,
+		func(reason audioinject.FinishReason, played time.Duration) {
+			if reason == audioinject.FinishClosed {
+				i.stop(s)
+			}
+		},
+	)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (i *Ingests) addSender(s *ingestStream, p types.LocalParticipant) {
+	if err := s.addSender(p); err != nil {
+		p.GetLogger().Warnw("could not add ingest track", err, "trackID", s.videoTrackID)
+	}
+}
+
+// stop removes the tracks of a stream
+func (i *Ingests) stop(s *ingestStream) {
+	if !s.close() {
+		return
+	}
+
+	i.lock.Lock()
+	if i.streams[s.identity] == s {
+		delete(i.streams, s.identity)
+	}
+	viewers := make([]types.LocalParticipant, 0, len(i.viewers))
+	for _, p := range i.viewers {
+		viewers = append(viewers, p)
+	}
+	i.lock.Unlock()
+
+	for _, p := range viewers {
+		s.removeSender(p)
+	}
+	i.params.Logger.Infow("ingest stopped", "identity", s.identity)
+	i.params.OnEvent(s.event(IngestEventStopped))
+}
+
+func (i *Ingests) streamsLocked() []*ingestStream {
+	streams := make([]*ingestStream, 0, len(i.streams))
+	for _, s := range i.streams {
+		streams = append(streams, s)
+	}
+	return streams
+}
+
+// --------------------------------------
+
+// ingestStream is the stream of an identity, across the connections of its encoder
+type ingestStream struct {
+	i            *Ingests
+	identity     livekit.ParticipantIdentity
+	name         string
+	videoTrackID livekit.TrackID
+	video        *webrtc.TrackLocalStaticSample
+	audioTrackID livekit.TrackID
+	player       *audioinject.Player
+	ctx          context.Context
+	cancel       context.CancelFunc
+
+	lock     sync.Mutex
+	protocol ingest.Protocol
+	// the connection whose writes are taken, earlier ones were replaced
+	generation int
+	attached   bool
+	reconnect  *time.Timer
+	lastDTS    time.Duration
+	hasDTS     bool
+	senders    map[livekit.ParticipantID]*webrtc.RTPSender
+	stopped    bool
+
+	// downmix of PCM, only used by the writer
+	writeLock sync.Mutex
+	mono      []int16
+}
+
+// attach hands the stream to a new connection of its encoder, returns false if the stream stopped
+func (s *ingestStream) attach(params IngestParams) (*ingestPublisher, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stopped {
+		return nil, false
+	}
+	if s.reconnect != nil {
+		s.reconnect.Stop()
+		s.reconnect = nil
+	}
+	s.generation++
+	s.attached = true
+	s.protocol = params.Protocol
+	// the timeline of the new connection starts over
+	s.hasDTS = false
+	return &ingestPublisher{s: s, generation: s.generation, reconnectTimeout: params.ReconnectTimeout}, true
+}
+
+// detach waits for the encoder to reconnect once the connection of generation closed
+func (s *ingestStream) detach(generation int, reconnectTimeout time.Duration) {
+	s.lock.Lock()
+	if s.stopped || s.generation != generation || !s.attached {
+		s.lock.Unlock()
+		return
+	}
+	s.attached = false
+	if reconnectTimeout > 0 {
+		s.reconnect = time.AfterFunc(reconnectTimeout, func() {
+			s.lock.Lock()
+			expired := !s.attached && s.generation == generation
+			s.lock.Unlock()
+			if expired {
+				s.i.stop(s)
+			}
+		})
+	}
+	s.lock.Unlock()
+
+	if reconnectTimeout <= 0 {
+		s.i.stop(s)
+	}
+}
+
+// checkLocked returns the error ending the connection of generation, if it no longer has the stream
+func (s *ingestStream) checkLocked(generation int) error {
+	switch {
+	case s.stopped:
+		return ingest.ErrPublisherClosed
+	case s.generation != generation:
+		return ingest.ErrPublisherReplaced
+	}
+	return nil
+}
+
+// close releases the tracks, returns false if already closed
+func (s *ingestStream) close() bool {
+	s.lock.Lock()
+	if s.stopped {
+		s.lock.Unlock()
+		return false
+	}
+	s.stopped = true
+	if s.reconnect != nil {
+		s.reconnect.Stop()
+		s.reconnect = nil
+	}
+	s.lock.Unlock()
+
+	s.cancel()
+	s.player.Cancel()
+	return true
+}
+
+func (s *ingestStream) addSender(p types.LocalParticipant) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, ok := s.senders[p.ID()]; ok || s.stopped {
+		return nil
+	}
+	sender, _, err := p.AddTrackLocal(s.video, types.AddTrackParams{})
+	if err != nil {
+		return err
+	}
+	s.senders[p.ID()] = sender
+	p.Negotiate(false)
+	return nil
+}
+
+func (s *ingestStream) removeSender(p types.LocalParticipant) {
+	s.lock.Lock()
+	sender, ok := s.senders[p.ID()]
+	delete(s.senders, p.ID())
+	s.lock.Unlock()
+
+	if !ok || p.IsClosed() {
+		return
+	}
+	if err := p.RemoveTrackLocal(sender); err != nil {
+		p.GetLogger().Warnw("could not remove ingest track", err, "trackID", s.videoTrackID)
+		return
+	}
+	p.Negotiate(false)
+}
+
+func (s *ingestStream) event(event string) *IngestEvent {
+	s.lock.Lock()
+	protocol := s.protocol
+	s.lock.Unlock()
+
+	return &IngestEvent{
+		Event:               event,
+		ParticipantIdentity: s.identity,
+		Name:                s.name,
+		Protocol:            protocol,
+		VideoTrackID:        s.videoTrackID,
+		AudioTrackID:        s.audioTrackID,
+	}
+}
+
+// --------------------------------------
+
+// ingestPublisher is a connection of the encoder of a stream
+type ingestPublisher struct {
+	s                *ingestStream
+	generation       int
+	reconnectTimeout time.Duration
+}
+
+func (p *ingestPublisher) WriteH264(au []byte, dts time.Duration) error {
+	s := p.s
+	s.lock.Lock()
+	if err := s.checkLocked(p.generation); err != nil {
+		s.lock.Unlock()
+		return err
+	}
+	// a frame lasts until the next one, so the duration is taken from the one before
+	duration := dts - s.lastDTS
+	if !s.hasDTS || duration <= 0 || duration > time.Second {
+		duration = ingestDefaultFrameDuration
+	}
+	s.lastDTS = dts
+	s.hasDTS = true
+	s.lock.Unlock()
+
+	return s.video.WriteSample(media.Sample{Data: au, Duration: duration})
+}
+
+func (p *ingestPublisher) WriteOpus(packet []byte) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+	return p.s.player.WriteOpus(p.s.ctx, packet)
+}
+
+func (p *ingestPublisher) WritePCM(pcm []int16, sampleRate int, channels int) error {
+	if err := p.check(); err != nil {
+		return err
+	}
+
+	s := p.s
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if channels > 1 {
+		s.mono = s.mono[:0]
+		for j := 0; j+channels <= len(pcm); j += channels {
+			sum := 0
+			for _, sample := range pcm[j : j+channels] {
+				sum += int(sample)
+			}
+			s.mono = append(s.mono, int16(sum/channels))
+		}
+		pcm = s.mono
+	}
+	return s.player.WritePCM(s.ctx, pcm, sampleRate)
+}
+
+func (p *ingestPublisher) Close() {
+	p.s.detach(p.generation, p.reconnectTimeout)
+}
+
+func (p *ingestPublisher) check() error {
+	p.s.lock.Lock()
+	defer p.s.lock.Unlock()
+
+	return p.s.checkLocked(p.generation)
+}
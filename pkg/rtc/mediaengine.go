@@ -61,6 +61,23 @@ var (
 		PayloadType: 8,
 	}
 
+	// RFC 4733 telephone events (in-band DTMF), at the clock rate of Opus and of G.711
+	TelephoneEventCodecParameters = webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:  mime.MimeTypeTelephoneEvent.String(),
+			ClockRate: 48000,
+		},
+		PayloadType: 110,
+	}
+
+	TelephoneEvent8kCodecParameters = webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{
+			MimeType:  mime.MimeTypeTelephoneEvent.String(),
+			ClockRate: 8000,
+		},
+		PayloadType: 126,
+	}
+
 	videoRTXCodecParameters = webrtc.RTPCodecParameters{
 		RTPCodecCapability: webrtc.RTPCodecCapability{
 			MimeType:  mime.MimeTypeRTX.String(),
@@ -178,6 +195,16 @@ func registerCodecs(me *webrtc.MediaEngine, codecs []*livekit.Codec, rtcpFeedbac
 		}
 	}
 
+	for _, codec := range []webrtc.RTPCodecParameters{TelephoneEventCodecParameters, TelephoneEvent8kCodecParameters} {
+		if !IsCodecEnabled(codecs, codec.RTPCodecCapability) {
+			continue
+		}
+
+		if err := me.RegisterCodec(codec, webrtc.RTPCodecTypeAudio); err != nil {
+			return err
+		}
+	}
+
 	// video codecs
 	rtxEnabled := IsCodecEnabled(codecs, videoRTXCodecParameters.RTPCodecCapability)
 	for _, codec := range videoCodecsParameters {
@@ -17,6 +17,7 @@ package rtc
 import (
 	"context"
 	"math"
+	"slices"
 	"sync"
 	"time"
 
@@ -32,6 +33,7 @@ import (
 	"github.com/livekit/livekit-server/pkg/rtc/dynacast"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
@@ -71,6 +73,23 @@ type MediaTrack struct {
 		trackID livekit.TrackID,
 		codecs []*livekit.SubscribedAudioCodec,
 	) error
+
+	goodbyeReceived atomic.Bool
+	onGoodbye       []func()
+
+	// SSRCs of the received streams
+	ssrcs               []uint32
+	noiseFilterDisabled atomic.Bool
+	// audio cancelled from the received streams, see ParticipantImpl.SetTrackEchoReference
+	echoReference atomic.Pointer[audio.EchoReference]
+	// gain in dB the received streams are scaled by, see ParticipantImpl.SetTrackGain
+	gainDB atomic.Float64
+	// attenuation in dB on top of the gain while an agent speaks, see DuckingController
+	duckingDB atomic.Float64
+
+	// subscribers needing denoised packets of the original size, see NoiseFilterCompatibility
+	noiseFilterCompatSubscribers     map[livekit.ParticipantID]struct{}
+	onNoiseFilterCompatibilityChange func(trackID livekit.TrackID, ssrcs []uint32, compatible bool)
 }
 
 type MediaTrackParams struct {
@@ -82,6 +101,7 @@ type MediaTrackParams struct {
 	ReceiverConfig           ReceiverConfig
 	SubscriberConfig         DirectionConfig
 	PLIThrottleConfig        sfu.PLIThrottleConfig
+	FanOutConfig             sfu.FanOutConfig
 	AudioConfig              sfu.AudioConfig
 	VideoConfig              config.VideoConfig
 	Telemetry                telemetry.TelemetryService
@@ -93,6 +113,10 @@ type MediaTrackParams struct {
 	OnTrackEverSubscribed    func(livekit.TrackID)
 	ShouldRegressCodec       func() bool
 	PreferVideoSizeFromMedia bool
+	// processing of the packets of the track ahead of forwarding
+	ReceiveStages *ReceiveStages
+	// invoked for every stream of the track the publisher ends with an RTCP BYE
+	OnStreamGoodbye func(ssrc uint32, reason string)
 }
 
 func NewMediaTrack(params MediaTrackParams, ti *livekit.TrackInfo) *MediaTrack {
@@ -213,6 +237,145 @@ func (t *MediaTrack) OnSubscribedAudioCodecChange(
 	t.lock.Unlock()
 }
 
+// AddOnGoodbye adds a callback invoked once when the publisher ends an audio track with an RTCP BYE,
+// ahead of the track being unpublished
+func (t *MediaTrack) AddOnGoodbye(f func()) {
+	t.lock.Lock()
+	t.onGoodbye = append(t.onGoodbye, f)
+	t.lock.Unlock()
+}
+
+// SSRCs returns the SSRCs of the streams received for the track
+func (t *MediaTrack) SSRCs() []uint32 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return slices.Clone(t.ssrcs)
+}
+
+// SetNoiseFilterEnabled records whether the publisher's audio is to be noise filtered,
+// the filter itself is switched by the publisher's transport
+func (t *MediaTrack) SetNoiseFilterEnabled(enabled bool) {
+	t.noiseFilterDisabled.Store(!enabled)
+}
+
+func (t *MediaTrack) IsNoiseFilterEnabled() bool {
+	return !t.noiseFilterDisabled.Load()
+}
+
+// SetEchoReference records the echo reference cancelled from the publisher's audio, nil for none,
+// cancellation itself is switched by the publisher's transport
+func (t *MediaTrack) SetEchoReference(reference *audio.EchoReference) {
+	t.echoReference.Store(reference)
+}
+
+func (t *MediaTrack) EchoReference() *audio.EchoReference {
+	return t.echoReference.Load()
+}
+
+// SetGain records the gain in dB the publisher's audio is scaled by for everyone, 0 for none,
+// scaling itself is switched by the publisher's transport
+func (t *MediaTrack) SetGain(gainDB float64) {
+	t.gainDB.Store(gainDB)
+}
+
+func (t *MediaTrack) Gain() float64 {
+	return t.gainDB.Load()
+}
+
+// SetDucking records the attenuation in dB applied on top of the gain while an agent speaks, 0 for none
+func (t *MediaTrack) SetDucking(gainDB float64) {
+	t.duckingDB.Store(gainDB)
+}
+
+// StreamGain returns the gain in dB the received streams are scaled by, of the gain and the ducking
+func (t *MediaTrack) StreamGain() float64 {
+	return min(max(t.gainDB.Load()+t.duckingDB.Load(), audio.MinTrackGainDB), audio.MaxTrackGainDB)
+}
+
+// OnNoiseFilterCompatibilityChange sets the callback invoked when the first subscriber needing noise filter
+// compatibility mode subscribes or the last one unsubscribes
+func (t *MediaTrack) OnNoiseFilterCompatibilityChange(f func(trackID livekit.TrackID, ssrcs []uint32, compatible bool)) {
+	t.lock.Lock()
+	t.onNoiseFilterCompatibilityChange = f
+	t.lock.Unlock()
+}
+
+// IsNoiseFilterCompatible returns true while a subscriber needs noise filter compatibility mode
+func (t *MediaTrack) IsNoiseFilterCompatible() bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return len(t.noiseFilterCompatSubscribers) != 0
+}
+
+func (t *MediaTrack) AddSubscriber(sub types.LocalParticipant) (types.SubscribedTrack, error) {
+	subTrack, err := t.MediaTrackReceiver.AddSubscriber(sub)
+	if err == nil && t.Kind() == livekit.TrackType_AUDIO {
+		if c, ok := sub.(interface{ NeedsNoiseFilterCompatibility() bool }); ok && c.NeedsNoiseFilterCompatibility() {
+			t.setNoiseFilterCompatSubscriber(sub.ID(), true)
+		}
+	}
+	return subTrack, err
+}
+
+func (t *MediaTrack) RemoveSubscriber(subscriberID livekit.ParticipantID, isExpectedToResume bool) {
+	t.MediaTrackReceiver.RemoveSubscriber(subscriberID, isExpectedToResume)
+	t.setNoiseFilterCompatSubscriber(subscriberID, false)
+}
+
+func (t *MediaTrack) setNoiseFilterCompatSubscriber(subscriberID livekit.ParticipantID, compatible bool) {
+	t.lock.RLock()
+	var gone []livekit.ParticipantID
+	for id := range t.noiseFilterCompatSubscribers {
+		if id != subscriberID {
+			gone = append(gone, id)
+		}
+	}
+	t.lock.RUnlock()
+	// subscribers dropped without going through RemoveSubscriber, e. g. on a codec change
+	gone = slices.DeleteFunc(gone, t.MediaTrackReceiver.IsSubscriber)
+
+	t.lock.Lock()
+	wasCompatible := len(t.noiseFilterCompatSubscribers) != 0
+	if compatible {
+		if t.noiseFilterCompatSubscribers == nil {
+			t.noiseFilterCompatSubscribers = make(map[livekit.ParticipantID]struct{})
+		}
+		t.noiseFilterCompatSubscribers[subscriberID] = struct{}{}
+	} else {
+		delete(t.noiseFilterCompatSubscribers, subscriberID)
+	}
+	for _, id := range gone {
+		delete(t.noiseFilterCompatSubscribers, id)
+	}
+	isCompatible := len(t.noiseFilterCompatSubscribers) != 0
+	ssrcs := slices.Clone(t.ssrcs)
+	onChange := t.onNoiseFilterCompatibilityChange
+	t.lock.Unlock()
+
+	if isCompatible != wasCompatible {
+		t.params.Logger.Debugw("noise filter compatibility mode changed", "compatible", isCompatible, "subscriberID", subscriberID)
+		if onChange != nil {
+			onChange(t.ID(), ssrcs, isCompatible)
+		}
+	}
+}
+
+func (t *MediaTrack) handleGoodbye(reason string) {
+	if t.Kind() != livekit.TrackType_AUDIO || t.goodbyeReceived.Swap(true) {
+		return
+	}
+
+	t.params.Logger.Infow("publisher ended track with RTCP BYE", "reason", reason)
+	t.lock.RLock()
+	onGoodbye := slices.Clone(t.onGoodbye)
+	t.lock.RUnlock()
+	for _, f := range onGoodbye {
+		f()
+	}
+}
+
 func (t *MediaTrack) NotifySubscriberNodeMaxQuality(nodeID livekit.NodeID, qualities []types.SubscribedCodecQuality) {
 	if t.dynacastManager != nil {
 		t.dynacastManager.NotifySubscriberNodeMaxQuality(nodeID, qualities)
@@ -292,8 +455,16 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track sfu.TrackRe
 		return newCodec, false
 	}
 
+	t.lock.Lock()
+	if !slices.Contains(t.ssrcs, ssrc) {
+		t.ssrcs = append(t.ssrcs, ssrc)
+	}
+	t.lock.Unlock()
+
 	var lastRR uint32
 	rtcpReader.OnPacket(func(bytes []byte) {
+		t.params.ReceiveStages.WriteRTCP(bytes)
+
 		pkts, err := rtcp.Unmarshal(bytes)
 		if err != nil {
 			t.params.Logger.Errorw("could not unmarshal RTCP", err)
@@ -307,6 +478,13 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track sfu.TrackRe
 				if pkt.SSRC == uint32(track.SSRC()) {
 					buff.SetSenderReportData(pkt.RTPTime, pkt.NTPTime, pkt.PacketCount, pkt.OctetCount)
 				}
+			case *rtcp.Goodbye:
+				if slices.Contains(pkt.Sources, ssrc) {
+					if t.params.OnStreamGoodbye != nil {
+						t.params.OnStreamGoodbye(ssrc, pkt.Reason)
+					}
+					t.handleGoodbye(pkt.Reason)
+				}
 			case *rtcp.ExtendedReport:
 			rttFromXR:
 				for _, report := range pkt.Reports {
@@ -403,6 +581,7 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track sfu.TrackRe
 			sfu.WithAudioConfig(t.params.AudioConfig),
 			sfu.WithLoadBalanceThreshold(20),
 			sfu.WithForwardStats(t.params.ForwardStats),
+			sfu.WithFanOutConfig(t.params.FanOutConfig),
 		)
 		newWR.OnCloseHandler(func() {
 			t.MediaTrackReceiver.SetClosing(false)
@@ -530,7 +709,12 @@ func (t *MediaTrack) AddReceiver(receiver *webrtc.RTPReceiver, track sfu.TrackRe
 	if layer >= 0 && len(layers) > int(layer) {
 		bitrates = int(layers[layer].GetBitrate())
 	}
-	if err := buff.Bind(receiver.GetParameters(), track.Codec().RTPCodecCapability, bitrates); err != nil {
+	parameters := receiver.GetParameters()
+	// stages process audio, video is buffered as received
+	if t.Kind() == livekit.TrackType_AUDIO && !t.params.ReceiveStages.IsEmpty() {
+		buff.SetStages(t.params.ReceiveStages, receiveStreamInfo(track, parameters))
+	}
+	if err := buff.Bind(parameters, track.Codec().RTPCodecCapability, bitrates); err != nil {
 		t.params.Logger.Warnw(
 			"binding buffer failed", err,
 			"rid", track.RID(),
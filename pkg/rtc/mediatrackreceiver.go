@@ -581,7 +581,7 @@ func (t *MediaTrackReceiver) AddSubscriber(sub types.LocalParticipant) (types.Su
 		StreamId:       streamId,
 		UpstreamCodecs: potentialCodecs,
 		Logger:         tLogger,
-		DisableRed:     !IsRedEnabled(t.TrackInfo()) || !t.params.AudioConfig.ActiveREDEncoding,
+		DisableRed:     !IsRedEnabled(t.TrackInfo()) || !(t.params.AudioConfig.ActiveREDEncoding || t.params.AudioConfig.REDGeneration.Enabled),
 		IsEncrypted:    t.IsEncrypted(),
 	})
 	subID := sub.ID()
@@ -1000,6 +1000,8 @@ func (t *MediaTrackReceiver) GetAudioLevel() (float64, bool) {
 
 func (t *MediaTrackReceiver) onDownTrackCreated(downTrack *sfu.DownTrack) {
 	if t.Kind() == livekit.TrackType_AUDIO {
+		downTrack.SetLossResilience(t.params.AudioConfig.LossResilience)
+		downTrack.SetREDGeneration(t.params.AudioConfig.REDGeneration)
 		downTrack.AddReceiverReportListener(func(dt *sfu.DownTrack, rr *rtcp.ReceiverReport) {
 			if t.onMediaLossFeedback != nil {
 				t.onMediaLossFeedback(dt, rr)
@@ -0,0 +1,170 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// topic of the data packets carrying microphone quality advisories
+	MicQualityTopic = "agentix.mic_quality"
+
+	micQualitySubscriberPrefix = "MICQ_"
+)
+
+type MicQualityEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	*audio.MicQualityReport
+}
+
+type MicQualityMonitorParams struct {
+	Config   audio.MicQualityConfig
+	Logger   logger.Logger
+	OnReport func(event *MicQualityEvent)
+	// workers analysis runs on, analysis runs on the forwarding path when nil
+	Placement     func() *placement.Slot
+	TrackPriority func(track types.MediaTrack) placement.Priority
+}
+
+// MicQualityMonitor analyzes every published audio track of a room and reports
+// when a track starts or stops showing signs of a poor microphone.
+type MicQualityMonitor struct {
+	params MicQualityMonitorParams
+
+	lock sync.Mutex
+	taps map[livekit.TrackID]*micQualityTap
+}
+
+func NewMicQualityMonitor(params MicQualityMonitorParams) *MicQualityMonitor {
+	return &MicQualityMonitor{
+		params: params,
+		taps:   make(map[livekit.TrackID]*micQualityTap),
+	}
+}
+
+func (m *MicQualityMonitor) AddTrack(track types.MediaTrack) {
+	if m == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		return
+	}
+
+	tap := &micQualityTap{
+		monitor:  m,
+		identity: track.PublisherIdentity(),
+		analyzer: audio.NewMicQualityAnalyzer(m.params.Config),
+	}
+	// without a decoder clipping is not detected, bandwidth and bitrate come from the packets
+	if decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1); err == nil {
+		tap.decoder = decoder
+		tap.pcm = make([]int16, audio.OpusMaxFrameSize)
+	}
+	tap.receiverTap = newReceiverTap(micQualitySubscriberPrefix, track.ID(), receiver, tap.onPacket)
+	if tap.decoder != nil {
+		tap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(1))
+	}
+	if m.params.Placement != nil {
+		tap.schedule(m.params.Placement(), func() placement.Priority { return m.params.TrackPriority(track) })
+	}
+
+	m.lock.Lock()
+	if _, ok := m.taps[track.ID()]; ok {
+		m.lock.Unlock()
+		return
+	}
+	m.taps[track.ID()] = tap
+	m.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		m.params.Logger.Warnw("could not tap receiver for mic quality", err, "trackID", track.ID())
+		m.RemoveTrack(track.ID())
+	}
+}
+
+func (m *MicQualityMonitor) RemoveTrack(trackID livekit.TrackID) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	tap, ok := m.taps[trackID]
+	delete(m.taps, trackID)
+	m.lock.Unlock()
+
+	if ok {
+		tap.stop()
+	}
+}
+
+func (m *MicQualityMonitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	taps := m.taps
+	m.taps = make(map[livekit.TrackID]*micQualityTap)
+	m.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.stop()
+	}
+}
+
+// --------------------------------------
+
+type micQualityTap struct {
+	*receiverTap
+
+	monitor  *MicQualityMonitor
+	identity livekit.ParticipantIdentity
+	analyzer *audio.MicQualityAnalyzer
+	decoder  audio.OpusDecoder
+	pcm      []int16
+}
+
+func (t *micQualityTap) onPacket(p *buffer.ExtPacket) {
+	if t.decoder != nil {
+		if n, err := t.decoder.Decode(p.Packet.Payload, t.pcm); err == nil {
+			t.analyzer.ObservePCM(t.pcm[:n])
+		}
+	}
+
+	report := t.analyzer.ObservePacket(p.Packet.Payload)
+	if report == nil || t.monitor.params.OnReport == nil {
+		return
+	}
+
+	// do not hold up forwarding
+	go t.monitor.params.OnReport(&MicQualityEvent{
+		ParticipantIdentity: t.identity,
+		TrackID:             t.trackID,
+		MicQualityReport:    report,
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// topic of the data packets sent to the listeners of a mix with the level and voice activity
+	// of every publisher in it
+	MixActivityTopic = "agentix.mix_activity"
+)
+
+// MixActivityEvent attributes a span of a mix to its publishers
+type MixActivityEvent struct {
+	// the mix, AudioMixTrackID or RoomMixTrackID
+	TrackID livekit.TrackID `json:"track_id"`
+	// start of the span on the timeline of the mix, the first frame the listener received is at 0.
+	// For the room mix the timeline is the one of the frame timestamps of the PCM tap service.
+	MixTimeMs  int64 `json:"mix_time_ms"`
+	DurationMs int64 `json:"duration_ms"`
+	// wall clock time the span started at, in unix milliseconds
+	Timestamp int64 `json:"timestamp"`
+	// loudest first
+	Contributors []MixContributor `json:"contributors"`
+}
+
+type MixContributor struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	// dBFS of the mean energy of the publisher in the mix, after its mix gain
+	Level float64 `json:"level"`
+	// share of the span the publisher was active in, 0 to 1
+	Activity float64 `json:"activity"`
+}
+
+func newMixActivityEvent(
+	trackID livekit.TrackID,
+	report *audio.MixActivityReport,
+	publishers map[string]audioMixPublisher,
+	now time.Time,
+) *MixActivityEvent {
+	event := &MixActivityEvent{
+		TrackID:      trackID,
+		MixTimeMs:    report.Start.Milliseconds(),
+		DurationMs:   report.Duration.Milliseconds(),
+		Timestamp:    now.Add(-report.Duration).UnixMilli(),
+		Contributors: make([]MixContributor, 0, len(report.Contributors)),
+	}
+	for _, c := range report.Contributors {
+		event.Contributors = append(event.Contributors, MixContributor{
+			// publishers of tracks unpublished since are left unnamed
+			ParticipantIdentity: publishers[c.ID].identity,
+			TrackID:             livekit.TrackID(c.ID),
+			Level:               c.Level,
+			Activity:            c.Activity,
+		})
+	}
+	return event
+}
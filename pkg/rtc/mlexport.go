@@ -0,0 +1,281 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/mlexport"
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	mlExportSubscriberPrefix = "MLX_"
+)
+
+// HasMLExportConsent returns true if the participant agreed to their audio being exported for training
+func HasMLExportConsent(p types.LocalParticipant) bool {
+	return p.GetAttributes()[mlexport.ConsentAttribute] == "true"
+}
+
+type MLExporterParams struct {
+	Config   mlexport.Config
+	RoomName livekit.RoomName
+	RoomID   livekit.RoomID
+	Logger   logger.Logger
+	// workers decoding runs on at recording priority, decoding runs on the forwarding path when nil
+	Placement func() *placement.Slot
+	// consent to recording is required on top of the export consent, and to transcription for transcripts
+	HasConsent func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool
+}
+
+// MLExporter records the audio and transcriptions of consenting participants of a room
+// and writes them as one archive when the room closes.
+type MLExporter struct {
+	params  MLExporterParams
+	session *mlexport.Session
+
+	lock      sync.Mutex
+	consented map[livekit.ParticipantIdentity]bool
+	taps      map[livekit.TrackID]*mlExportTap
+	stopped   bool
+}
+
+func NewMLExporter(params MLExporterParams) (*MLExporter, error) {
+	session, err := mlexport.NewSession(params.Config, string(params.RoomName), string(params.RoomID))
+	if err != nil {
+		return nil, err
+	}
+
+	return &MLExporter{
+		params:    params,
+		session:   session,
+		consented: make(map[livekit.ParticipantIdentity]bool),
+		taps:      make(map[livekit.TrackID]*mlExportTap),
+	}, nil
+}
+
+// SyncConsent follows the consent attribute of a participant, revoking consent
+// discards everything recorded of the participant
+func (e *MLExporter) SyncConsent(p types.LocalParticipant) {
+	if e == nil {
+		return
+	}
+
+	consent := HasMLExportConsent(p) && e.hasConsent(p.Identity(), ConsentRecording)
+
+	e.lock.Lock()
+	if e.stopped || consent == e.consented[p.Identity()] {
+		e.lock.Unlock()
+		return
+	}
+	if consent {
+		e.consented[p.Identity()] = true
+		e.lock.Unlock()
+
+		for _, track := range p.GetPublishedTracks() {
+			e.AddTrack(track)
+		}
+		return
+	}
+
+	delete(e.consented, p.Identity())
+	var taps []*mlExportTap
+	for trackID, tap := range e.taps {
+		if tap.identity == p.Identity() {
+			delete(e.taps, trackID)
+			taps = append(taps, tap)
+		}
+	}
+	e.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.stop()
+	}
+	e.session.DiscardParticipant(string(p.Identity()))
+	e.params.Logger.Infow("ml export consent revoked, discarded recording", "participant", p.Identity())
+}
+
+func (e *MLExporter) AddTrack(track types.MediaTrack) {
+	if e == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		return
+	}
+
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		e.params.Logger.Warnw("could not create decoder for ml export", err, "trackID", track.ID())
+		return
+	}
+
+	e.lock.Lock()
+	if e.stopped || !e.consented[track.PublisherIdentity()] {
+		e.lock.Unlock()
+		return
+	}
+	if _, ok := e.taps[track.ID()]; ok {
+		e.lock.Unlock()
+		return
+	}
+	if _, err := e.session.AddTrack(string(track.PublisherIdentity()), string(track.ID())); err != nil {
+		e.lock.Unlock()
+		e.params.Logger.Warnw("could not record track for ml export", err, "trackID", track.ID())
+		return
+	}
+	tap := &mlExportTap{
+		exporter: e,
+		identity: track.PublisherIdentity(),
+		decoder:  decoder,
+		pcm:      make([]int16, audio.OpusMaxFrameSize),
+	}
+	tap.receiverTap = newReceiverTap(mlExportSubscriberPrefix, track.ID(), receiver, tap.onPacket)
+	tap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(1))
+	if e.params.Placement != nil {
+		tap.schedule(e.params.Placement(), func() placement.Priority { return placement.PriorityRecording })
+	}
+	e.taps[track.ID()] = tap
+	e.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		e.params.Logger.Warnw("could not tap receiver for ml export", err, "trackID", track.ID())
+		e.RemoveTrack(track.ID())
+	}
+}
+
+func (e *MLExporter) RemoveTrack(trackID livekit.TrackID) {
+	if e == nil {
+		return
+	}
+
+	e.lock.Lock()
+	tap, ok := e.taps[trackID]
+	delete(e.taps, trackID)
+	e.lock.Unlock()
+
+	if ok {
+		tap.stop()
+		if err := e.session.RemoveTrack(string(trackID)); err != nil {
+			e.params.Logger.Warnw("could not finish ml export track", err, "trackID", trackID)
+		}
+	}
+}
+
+// AddTranscription records the final segments of a transcription of a consenting participant.
+// Segments are placed on the session timeline by the time they are received.
+func (e *MLExporter) AddTranscription(transcription *livekit.Transcription) {
+	if e == nil || transcription == nil {
+		return
+	}
+
+	identity := livekit.ParticipantIdentity(transcription.TranscribedParticipantIdentity)
+	e.lock.Lock()
+	consented := !e.stopped && e.consented[identity]
+	e.lock.Unlock()
+	if !consented || !e.hasConsent(identity, ConsentTranscription) {
+		return
+	}
+
+	received := time.Since(e.session.StartedAt()).Seconds()
+	for _, seg := range transcription.Segments {
+		if !seg.Final {
+			continue
+		}
+		// segment times are in milliseconds of the transcribed stream, only their difference is usable here
+		start := received
+		if seg.EndTime > seg.StartTime {
+			start = max(0, received-float64(seg.EndTime-seg.StartTime)/1000)
+		}
+		e.session.AddTranscription(mlexport.TranscriptSegment{
+			ID:                  seg.Id,
+			ParticipantIdentity: string(identity),
+			TrackID:             transcription.TrackId,
+			Text:                seg.Text,
+			Language:            seg.Language,
+			Start:               start,
+			End:                 received,
+		})
+	}
+}
+
+func (e *MLExporter) hasConsent(identity livekit.ParticipantIdentity, flags ConsentFlags) bool {
+	return e.params.HasConsent == nil || e.params.HasConsent(identity, flags)
+}
+
+// Stop stops recording and writes the session archive in the background
+func (e *MLExporter) Stop() {
+	if e == nil {
+		return
+	}
+
+	e.lock.Lock()
+	if e.stopped {
+		e.lock.Unlock()
+		return
+	}
+	e.stopped = true
+	taps := e.taps
+	e.taps = make(map[livekit.TrackID]*mlExportTap)
+	e.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.stop()
+	}
+
+	go func() {
+		path, err := e.session.Finish()
+		if err != nil {
+			e.params.Logger.Errorw("could not write ml export", err)
+			return
+		}
+		if path != "" {
+			e.params.Logger.Infow("wrote ml export", "path", path)
+		}
+	}()
+}
+
+// --------------------------------------
+
+type mlExportTap struct {
+	*receiverTap
+
+	exporter *MLExporter
+	identity livekit.ParticipantIdentity
+	decoder  audio.OpusDecoder
+	pcm      []int16
+}
+
+func (t *mlExportTap) onPacket(p *buffer.ExtPacket) {
+	n, err := t.decoder.Decode(p.Packet.Payload, t.pcm)
+	if err != nil {
+		return
+	}
+
+	if err := t.exporter.session.WriteFrame(string(t.trackID), time.Unix(0, p.Arrival), p.Packet.Timestamp, t.pcm[:n]); err != nil {
+		t.exporter.params.Logger.Warnw("could not write ml export audio", err, "trackID", t.trackID)
+		t.exporter.RemoveTrack(t.trackID)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// participant attribute with the role of a participant, agents without a role are "agent"
+	RoleAttribute = "agentix.role"
+	// participant attribute banning a participant from sending data,
+	// "true" or an RFC 3339 time the ban expires at
+	BanAttribute = "agentix.data_ban"
+	// room metadata key overriding the slow mode interval, a duration string or seconds
+	SlowModeKey = "agentix.slow_mode"
+
+	RoleAgent = "agent"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// minimum interval between messages of a participant, 0 disables slow mode
+	SlowMode time.Duration `yaml:"slow_mode,omitempty"`
+	// maximum size of a data packet in bytes, 0 for no limit
+	MaxMessageSize int `yaml:"max_message_size,omitempty"`
+	// message types participants are allowed to send, all types when empty
+	AllowedTypes []string `yaml:"allowed_types,omitempty"`
+	// roles that are not moderated and receive violation events
+	ExemptRoles []string `yaml:"exempt_roles,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		MaxMessageSize: 15 * 1024,
+		ExemptRoles:    []string{RoleAgent, "moderator"},
+	}
+)
+
+// --------------------------------------
+
+type Violation int
+
+const (
+	ViolationNone Violation = iota
+	ViolationBanned
+	ViolationTypeNotAllowed
+	ViolationTooLarge
+	ViolationSlowMode
+)
+
+func (v Violation) String() string {
+	switch v {
+	case ViolationNone:
+		return "none"
+	case ViolationBanned:
+		return "banned"
+	case ViolationTypeNotAllowed:
+		return "type_not_allowed"
+	case ViolationTooLarge:
+		return "too_large"
+	case ViolationSlowMode:
+		return "slow_mode"
+	default:
+		return "unknown"
+	}
+}
+
+func (v Violation) MarshalText() ([]byte, error) {
+	return []byte(v.String()), nil
+}
+
+// --------------------------------------
+
+type Message struct {
+	Type string
+	Size int
+	// counts towards slow mode, false for continuations like stream chunks
+	Counted bool
+}
+
+type Sender struct {
+	Identity string
+	Role     string
+	// value of BanAttribute
+	Ban string
+}
+
+type Result struct {
+	Violation Violation
+	// when the message would have been accepted in slow mode
+	RetryAfter time.Duration
+}
+
+// Moderator decides whether data sent by a participant is forwarded
+type Moderator struct {
+	config       Config
+	allowedTypes map[string]struct{}
+	exemptRoles  map[string]struct{}
+
+	lock     sync.Mutex
+	slowMode time.Duration
+	lastSent map[string]time.Time
+}
+
+func NewModerator(config Config) *Moderator {
+	m := &Moderator{
+		config:       config,
+		allowedTypes: make(map[string]struct{}, len(config.AllowedTypes)),
+		exemptRoles:  make(map[string]struct{}, len(config.ExemptRoles)),
+		slowMode:     config.SlowMode,
+		lastSent:     make(map[string]time.Time),
+	}
+	for _, t := range config.AllowedTypes {
+		m.allowedTypes[t] = struct{}{}
+	}
+	for _, r := range config.ExemptRoles {
+		m.exemptRoles[r] = struct{}{}
+	}
+	return m
+}
+
+func (m *Moderator) IsExempt(role string) bool {
+	_, ok := m.exemptRoles[role]
+	return role != "" && ok
+}
+
+// SyncRoomMetadata applies the slow mode override of the room metadata,
+// the configured interval is restored when the override is removed
+func (m *Moderator) SyncRoomMetadata(metadata string) {
+	slowMode, ok := SlowModeFromMetadata(metadata)
+	if !ok {
+		slowMode = m.config.SlowMode
+	}
+
+	m.lock.Lock()
+	m.slowMode = slowMode
+	m.lock.Unlock()
+}
+
+func (m *Moderator) SlowMode() time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.slowMode
+}
+
+func (m *Moderator) Check(sender Sender, msg Message, now time.Time) Result {
+	if m.IsExempt(sender.Role) {
+		return Result{}
+	}
+	if IsBanned(sender.Ban, now) {
+		return Result{Violation: ViolationBanned}
+	}
+	if len(m.allowedTypes) != 0 {
+		if _, ok := m.allowedTypes[msg.Type]; !ok {
+			return Result{Violation: ViolationTypeNotAllowed}
+		}
+	}
+	if m.config.MaxMessageSize > 0 && msg.Size > m.config.MaxMessageSize {
+		return Result{Violation: ViolationTooLarge}
+	}
+	if !msg.Counted {
+		return Result{}
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.slowMode > 0 {
+		if last, ok := m.lastSent[sender.Identity]; ok {
+			if wait := last.Add(m.slowMode).Sub(now); wait > 0 {
+				return Result{Violation: ViolationSlowMode, RetryAfter: wait}
+			}
+		}
+	}
+	m.lastSent[sender.Identity] = now
+	return Result{}
+}
+
+func (m *Moderator) RemoveParticipant(identity string) {
+	m.lock.Lock()
+	delete(m.lastSent, identity)
+	m.lock.Unlock()
+}
+
+// --------------------------------------
+
+func IsBanned(ban string, now time.Time) bool {
+	if ban == "" {
+		return false
+	}
+	if banned, err := strconv.ParseBool(ban); err == nil {
+		return banned
+	}
+	if until, err := time.Parse(time.RFC3339, ban); err == nil {
+		return now.Before(until)
+	}
+	// an unparseable ban is not lifted by accident
+	return true
+}
+
+// SlowModeFromMetadata returns the slow mode interval set in a JSON object room metadata
+func SlowModeFromMetadata(metadata string) (time.Duration, bool) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &doc); err != nil {
+		return 0, false
+	}
+	raw, ok := doc[SlowModeKey]
+	if !ok {
+		return 0, false
+	}
+
+	var seconds float64
+	if err := json.Unmarshal(raw, &seconds); err == nil && seconds >= 0 {
+		return time.Duration(seconds * float64(time.Second)), true
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerator(t *testing.T) {
+	now := time.Now()
+	chat := Message{Type: "chat_message", Size: 10, Counted: true}
+	user := Sender{Identity: "user"}
+
+	t.Run("slow mode", func(t *testing.T) {
+		m := NewModerator(Config{SlowMode: 5 * time.Second})
+		require.Equal(t, ViolationNone, m.Check(user, chat, now).Violation)
+
+		res := m.Check(user, chat, now.Add(2*time.Second))
+		require.Equal(t, ViolationSlowMode, res.Violation)
+		require.Equal(t, 3*time.Second, res.RetryAfter)
+
+		// other participants and continuations are not limited
+		require.Equal(t, ViolationNone, m.Check(Sender{Identity: "other"}, chat, now.Add(2*time.Second)).Violation)
+		require.Equal(t, ViolationNone, m.Check(user, Message{Type: "stream"}, now.Add(2*time.Second)).Violation)
+
+		// rejected messages do not extend the interval
+		require.Equal(t, ViolationNone, m.Check(user, chat, now.Add(5*time.Second)).Violation)
+
+		m.RemoveParticipant("user")
+		require.Equal(t, ViolationNone, m.Check(user, chat, now.Add(6*time.Second)).Violation)
+	})
+
+	t.Run("room metadata override", func(t *testing.T) {
+		m := NewModerator(Config{SlowMode: time.Second})
+		m.SyncRoomMetadata(`{"agentix.slow_mode":"30s"}`)
+		require.Equal(t, 30*time.Second, m.SlowMode())
+		m.SyncRoomMetadata(`{"agentix.slow_mode":0}`)
+		require.Equal(t, time.Duration(0), m.SlowMode())
+		m.SyncRoomMetadata("not json")
+		require.Equal(t, time.Second, m.SlowMode())
+	})
+
+	t.Run("validation", func(t *testing.T) {
+		m := NewModerator(Config{MaxMessageSize: 100, AllowedTypes: []string{"chat_message"}})
+		require.Equal(t, ViolationTypeNotAllowed, m.Check(user, Message{Type: "user", Size: 10}, now).Violation)
+		require.Equal(t, ViolationTooLarge, m.Check(user, Message{Type: "chat_message", Size: 101}, now).Violation)
+		require.Equal(t, ViolationNone, m.Check(user, chat, now).Violation)
+	})
+
+	t.Run("bans and exempt roles", func(t *testing.T) {
+		m := NewModerator(DefaultConfig)
+		require.Equal(t, ViolationBanned, m.Check(Sender{Identity: "a", Ban: "true"}, chat, now).Violation)
+		require.Equal(t, ViolationNone, m.Check(Sender{Identity: "a", Ban: "false"}, chat, now).Violation)
+
+		until := now.Add(time.Minute).Format(time.RFC3339)
+		require.Equal(t, ViolationBanned, m.Check(Sender{Identity: "b", Ban: until}, chat, now).Violation)
+		require.Equal(t, ViolationNone, m.Check(Sender{Identity: "b", Ban: until}, chat, now.Add(2*time.Minute)).Violation)
+
+		require.Equal(t, ViolationNone, m.Check(Sender{Identity: "c", Role: "moderator", Ban: "true"}, Message{Size: 1 << 20}, now).Violation)
+		require.False(t, m.IsExempt(""))
+	})
+}
@@ -0,0 +1,69 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/clientconfiguration"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// participant attribute of a subscriber asking for denoised packets of the original size, "true" to opt in,
+	// "false" to opt out even when its client is matched
+	NoiseFilterCompatibilityAttribute = "agentix.noise_filter_compat"
+)
+
+// NoiseFilterCompatibility decides which subscribers need denoised audio in compatibility mode,
+// either matched by their client info or opted in with NoiseFilterCompatibilityAttribute.
+// A nil NoiseFilterCompatibility only honours the attribute.
+type NoiseFilterCompatibility struct {
+	clients []clientconfiguration.Match
+}
+
+func NewNoiseFilterCompatibility(config audio.NoiseFilterCompatibilityConfig) (*NoiseFilterCompatibility, error) {
+	c := &NoiseFilterCompatibility{}
+	for _, expr := range config.Clients {
+		match, err := clientconfiguration.NewScriptMatch(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid noise filter compatibility client %q: %w", expr, err)
+		}
+		c.clients = append(c.clients, match)
+	}
+	return c, nil
+}
+
+// IsNeeded returns true if a subscriber with clientInfo and attributes needs compatibility mode
+func (c *NoiseFilterCompatibility) IsNeeded(clientInfo *livekit.ClientInfo, attributes map[string]string) bool {
+	if v, ok := attributes[NoiseFilterCompatibilityAttribute]; ok {
+		if needed, err := strconv.ParseBool(v); err == nil {
+			return needed
+		}
+	}
+	if c == nil || clientInfo == nil {
+		return false
+	}
+	for _, match := range c.clients {
+		if ok, err := match.Match(clientInfo); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
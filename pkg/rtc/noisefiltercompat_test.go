@@ -0,0 +1,77 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+func TestNoiseFilterCompatibility(t *testing.T) {
+	c, err := NewNoiseFilterCompatibility(audio.NoiseFilterCompatibilityConfig{
+		Clients: []string{
+			`c.sdk == "unity" && !(c.version >= "2.0.0")`,
+			`c.sdk == "android" && !(c.version >= "1.5.0")`,
+			`c.sdk == "js" && c.browser == "firefox"`,
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name     string
+		client   *livekit.ClientInfo
+		expected bool
+	}{
+		{"js chrome", &livekit.ClientInfo{Sdk: livekit.ClientInfo_JS, Version: "2.5.7", Browser: "Chrome"}, false},
+		{"js firefox", &livekit.ClientInfo{Sdk: livekit.ClientInfo_JS, Version: "2.5.7", Browser: "Firefox"}, true},
+		{"android old", &livekit.ClientInfo{Sdk: livekit.ClientInfo_ANDROID, Version: "1.4.2"}, true},
+		{"android current", &livekit.ClientInfo{Sdk: livekit.ClientInfo_ANDROID, Version: "2.10.0"}, false},
+		{"swift", &livekit.ClientInfo{Sdk: livekit.ClientInfo_SWIFT, Version: "1.1.0"}, false},
+		{"unity old", &livekit.ClientInfo{Sdk: livekit.ClientInfo_UNITY, Version: "1.0.3"}, true},
+		{"unity current", &livekit.ClientInfo{Sdk: livekit.ClientInfo_UNITY, Version: "2.0.0"}, false},
+		{"flutter", &livekit.ClientInfo{Sdk: livekit.ClientInfo_FLUTTER, Version: "1.5.0"}, false},
+		{"unknown", nil, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, c.IsNeeded(tc.client, nil))
+		})
+	}
+
+	t.Run("attribute", func(t *testing.T) {
+		chrome := &livekit.ClientInfo{Sdk: livekit.ClientInfo_JS, Version: "2.5.7", Browser: "Chrome"}
+		require.True(t, c.IsNeeded(chrome, map[string]string{NoiseFilterCompatibilityAttribute: "true"}))
+
+		unity := &livekit.ClientInfo{Sdk: livekit.ClientInfo_UNITY, Version: "1.0.3"}
+		require.False(t, c.IsNeeded(unity, map[string]string{NoiseFilterCompatibilityAttribute: "false"}))
+		// not a flag, the client decides
+		require.True(t, c.IsNeeded(unity, map[string]string{NoiseFilterCompatibilityAttribute: "sometimes"}))
+
+		var none *NoiseFilterCompatibility
+		require.True(t, none.IsNeeded(nil, map[string]string{NoiseFilterCompatibilityAttribute: "1"}))
+		require.False(t, none.IsNeeded(unity, nil))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewNoiseFilterCompatibility(audio.NoiseFilterCompatibilityConfig{Clients: []string{`c.sdk ==`}})
+		require.Error(t, err)
+	})
+}
@@ -50,13 +50,16 @@ import (
 	"github.com/livekit/psrpc"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metadata"
 	"github.com/livekit/livekit-server/pkg/metric"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc/moderation"
 	"github.com/livekit/livekit-server/pkg/rtc/signalling"
 	"github.com/livekit/livekit-server/pkg/rtc/supervisor"
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
@@ -170,6 +173,7 @@ type ParticipantParams struct {
 	Telemetry               telemetry.TelemetryService
 	Trailer                 []byte
 	PLIThrottleConfig       sfu.PLIThrottleConfig
+	FanOutConfig            sfu.FanOutConfig
 	CongestionControlConfig config.CongestionControlConfig
 	// codecs that are enabled for this room
 	PublishEnabledCodecs           []*livekit.Codec
@@ -216,6 +220,10 @@ type ParticipantParams struct {
 	Country                        string
 	PreferVideoSizeFromMedia       bool
 	UseSinglePeerConnection        bool
+	NoiseProfile                   *audio.NoiseProfile
+	NoiseFilterCompatibility       *NoiseFilterCompatibility
+	OpusFmtp                       audio.OpusFmtpConfig
+	MetadataConfig                 metadata.Config
 }
 
 type ParticipantImpl struct {
@@ -231,6 +239,9 @@ type ParticipantImpl struct {
 	isClosed    atomic.Bool
 	closeReason atomic.Value // types.ParticipantCloseReason
 
+	// language detected in transcriptions of the participant's speech
+	detectedLanguage atomic.String
+
 	state        atomic.Value // livekit.ParticipantInfo_State
 	disconnected chan struct{}
 
@@ -307,6 +318,8 @@ type ParticipantImpl struct {
 	onSyncState                    func(types.LocalParticipant, *livekit.SyncState) error
 	onSimulateScenario             func(types.LocalParticipant, *livekit.SimulateScenario) error
 	onLeave                        func(types.LocalParticipant, types.ParticipantCloseReason)
+	onSpeakingChange               func(types.LocalParticipant, livekit.TrackID, *audio.SpeakingTransition)
+	onUtterance                    func(types.LocalParticipant, livekit.TrackID, *audio.UtteranceEvent)
 
 	migrateState                atomic.Value // types.MigrateState
 	migratedTracksPublishedFuse core.Fuse
@@ -587,6 +600,14 @@ func (p *ParticipantImpl) checkMetadataLimits(
 	return nil
 }
 
+// hasReservedAttribute returns true if attributes include ones that grant or revoke moderation privileges
+func hasReservedAttribute(attrs map[string]string) bool {
+	_, hasRole := attrs[moderation.RoleAttribute]
+	_, hasBan := attrs[moderation.BanAttribute]
+	_, hasBypass := attrs[audio.StageBypassAttribute]
+	return hasRole || hasBan || hasBypass
+}
+
 func (p *ParticipantImpl) UpdateMetadata(update *livekit.UpdateParticipantMetadata, fromAdmin bool) error {
 	lgr := p.params.Logger.WithUnlikelyValues(
 		"update", logger.Proto(update),
@@ -618,6 +639,13 @@ func (p *ParticipantImpl) UpdateMetadata(update *livekit.UpdateParticipantMetada
 		return sendRequestResponse()
 	}
 
+	if !fromAdmin && hasReservedAttribute(update.Attributes) {
+		requestResponse.Reason = livekit.RequestResponse_NOT_ALLOWED
+		requestResponse.Message = "cannot update reserved attributes"
+		err = signalling.ErrUpdateReservedAttribute
+		return sendRequestResponse()
+	}
+
 	if err = p.checkMetadataLimits(update.Name, update.Metadata, update.Attributes); err != nil {
 		switch err {
 		case signalling.ErrNameExceedsLimits:
@@ -635,10 +663,24 @@ func (p *ParticipantImpl) UpdateMetadata(update *livekit.UpdateParticipantMetada
 		return sendRequestResponse()
 	}
 
+	mergeMetadata := update.Metadata != "" && p.params.MetadataConfig.Merge
+	if mergeMetadata {
+		// merged first so that a failed compare-and-swap does not partially apply the update
+		if err = p.mergeMetadata(update.Metadata); err != nil {
+			if errors.Is(err, metadata.ErrTooLarge) {
+				requestResponse.Reason = livekit.RequestResponse_LIMIT_EXCEEDED
+				requestResponse.Message = "exceeds metadata size limit"
+			} else {
+				requestResponse.Reason = livekit.RequestResponse_NOT_ALLOWED
+				requestResponse.Message = err.Error()
+			}
+			return sendRequestResponse()
+		}
+	}
 	if update.Name != "" {
 		p.SetName(update.Name)
 	}
-	if update.Metadata != "" {
+	if update.Metadata != "" && !mergeMetadata {
 		p.SetMetadata(update.Metadata)
 	}
 	if update.Attributes != nil {
@@ -675,17 +717,31 @@ func (p *ParticipantImpl) SetName(name string) {
 
 // SetMetadata attaches metadata to the participant
 func (p *ParticipantImpl) SetMetadata(metadata string) {
+	_ = p.updateMetadata(func(string) (string, error) {
+		return metadata, nil
+	})
+}
+
+// mergeMetadata applies update onto the current metadata per key, see metadata.Merge
+func (p *ParticipantImpl) mergeMetadata(update string) error {
+	return p.updateMetadata(func(current string) (string, error) {
+		return metadata.Merge(current, update, p.params.LimitConfig.MaxMetadataSize)
+	})
+}
+
+func (p *ParticipantImpl) updateMetadata(apply func(current string) (string, error)) error {
 	p.lock.Lock()
 	grants := p.grants.Load()
-	if grants.Metadata == metadata {
+	md, err := apply(grants.Metadata)
+	if err != nil || grants.Metadata == md {
 		p.lock.Unlock()
-		return
+		return err
 	}
 
 	grants = grants.Clone()
-	grants.Metadata = metadata
+	grants.Metadata = md
 	p.grants.Store(grants)
-	p.requireBroadcast = p.requireBroadcast || metadata != ""
+	p.requireBroadcast = p.requireBroadcast || md != ""
 	p.dirty.Store(true)
 
 	onParticipantUpdate := p.onParticipantUpdate
@@ -698,6 +754,7 @@ func (p *ParticipantImpl) SetMetadata(metadata string) {
 	if onClaimsChanged != nil {
 		onClaimsChanged(p)
 	}
+	return nil
 }
 
 func (p *ParticipantImpl) SetAttributes(attrs map[string]string) {
@@ -729,6 +786,9 @@ func (p *ParticipantImpl) SetAttributes(attrs map[string]string) {
 	onClaimsChanged := p.onClaimsChanged
 	p.lock.Unlock()
 
+	p.TransportManager.SyncStageBypass(p.Identity(), grants.Attributes)
+	p.syncNoiseFilterLanguage()
+
 	if onParticipantUpdate != nil {
 		onParticipantUpdate(p)
 	}
@@ -737,10 +797,137 @@ func (p *ParticipantImpl) SetAttributes(attrs map[string]string) {
 	}
 }
 
+// SetTrackNoiseFilter switches noise filtering of a published audio track at runtime,
+// the denoiser is detached or attached with the next packet without renegotiation
+func (p *ParticipantImpl) SetTrackNoiseFilter(trackID livekit.TrackID, enabled bool) error {
+	if !p.TransportManager.HasNoiseFilter() {
+		return ErrNoiseFilterUnavailable
+	}
+	mt, ok := p.GetPublishedTrack(trackID).(*MediaTrack)
+	if !ok || mt.Kind() != livekit.TrackType_AUDIO {
+		return ErrTrackNotFound
+	}
+
+	mt.SetNoiseFilterEnabled(enabled)
+	for _, ssrc := range mt.SSRCs() {
+		p.TransportManager.SetStreamNoiseFilter(ssrc, enabled)
+	}
+	p.pubLogger.Infow("noise filter switched", "trackID", trackID, "enabled", enabled)
+	return nil
+}
+
+// SetDetectedLanguage tunes the noise filter of the participant's audio for the language detected in its speech,
+// the language attribute takes precedence
+func (p *ParticipantImpl) SetDetectedLanguage(language string) {
+	if p.detectedLanguage.Swap(language) != language {
+		p.syncNoiseFilterLanguage()
+	}
+}
+
+// noiseFilterLanguage returns the language the participant speaks, the attribute before the detected language
+func (p *ParticipantImpl) noiseFilterLanguage() string {
+	if language := p.grants.Load().Attributes[audio.NoiseFilterLanguageAttribute]; language != "" {
+		return language
+	}
+	return p.detectedLanguage.Load()
+}
+
+func (p *ParticipantImpl) syncNoiseFilterLanguage() {
+	if tm := p.TransportManager; tm != nil {
+		tm.SetNoiseFilterLanguage(p.noiseFilterLanguage())
+	}
+}
+
+// UpdateAudioConfig applies a reloaded audio configuration to the noise filter of the participant's published audio,
+// it has no effect if the noise filter was disabled when the participant joined
+func (p *ParticipantImpl) UpdateAudioConfig(config sfu.AudioConfig) {
+	if tm := p.TransportManager; tm != nil {
+		tm.UpdateAudioConfig(config)
+	}
+}
+
+// NeedsNoiseFilterCompatibility returns true if denoised audio sent to the participant
+// has to keep the size of the packets it replaces
+func (p *ParticipantImpl) NeedsNoiseFilterCompatibility() bool {
+	return p.params.NoiseFilterCompatibility.IsNeeded(p.params.ClientInfo.ClientInfo, p.grants.Load().Attributes)
+}
+
+func (p *ParticipantImpl) onNoiseFilterCompatibilityChange(trackID livekit.TrackID, ssrcs []uint32, compatible bool) {
+	for _, ssrc := range ssrcs {
+		p.TransportManager.SetStreamNoiseFilterCompatible(ssrc, compatible)
+	}
+	p.pubLogger.Infow("noise filter compatibility mode switched", "trackID", trackID, "compatible", compatible)
+}
+
+// SetTrackEchoReference cancels the audio of reference, the audio agents play into the room, from a published
+// audio track ahead of noise filtering. nil stops cancellation.
+func (p *ParticipantImpl) SetTrackEchoReference(trackID livekit.TrackID, reference *audio.EchoReference) error {
+	if !p.TransportManager.HasNoiseFilter() {
+		return ErrNoiseFilterUnavailable
+	}
+	mt, ok := p.GetPublishedTrack(trackID).(*MediaTrack)
+	if !ok || mt.Kind() != livekit.TrackType_AUDIO {
+		return ErrTrackNotFound
+	}
+
+	mt.SetEchoReference(reference)
+	for _, ssrc := range mt.SSRCs() {
+		p.TransportManager.SetStreamEchoReference(ssrc, reference)
+	}
+	return nil
+}
+
+// SetTrackGain scales a published audio track by gainDB for all its subscribers, clamped to the range of
+// audio.MinTrackGainDB, which mutes, to audio.MaxTrackGainDB. 0 stops scaling.
+func (p *ParticipantImpl) SetTrackGain(trackID livekit.TrackID, gainDB float64) error {
+	if !p.TransportManager.HasNoiseFilter() {
+		return ErrNoiseFilterUnavailable
+	}
+	mt, ok := p.GetPublishedTrack(trackID).(*MediaTrack)
+	if !ok || mt.Kind() != livekit.TrackType_AUDIO {
+		return ErrTrackNotFound
+	}
+
+	gainDB = min(max(gainDB, audio.MinTrackGainDB), audio.MaxTrackGainDB)
+	mt.SetGain(gainDB)
+	p.syncTrackGain(mt)
+	p.pubLogger.Infow("track gain set", "trackID", trackID, "gainDB", gainDB)
+	return nil
+}
+
+// SetTrackDucking attenuates a published audio track by gainDB on top of its gain, 0 stops attenuating
+func (p *ParticipantImpl) SetTrackDucking(trackID livekit.TrackID, gainDB float64) error {
+	if !p.TransportManager.HasNoiseFilter() {
+		return ErrNoiseFilterUnavailable
+	}
+	mt, ok := p.GetPublishedTrack(trackID).(*MediaTrack)
+	if !ok || mt.Kind() != livekit.TrackType_AUDIO {
+		return ErrTrackNotFound
+	}
+
+	mt.SetDucking(gainDB)
+	p.syncTrackGain(mt)
+	return nil
+}
+
+func (p *ParticipantImpl) syncTrackGain(mt *MediaTrack) {
+	gainDB := mt.StreamGain()
+	for _, ssrc := range mt.SSRCs() {
+		p.TransportManager.SetStreamGain(ssrc, gainDB)
+	}
+}
+
 func (p *ParticipantImpl) ClaimGrants() *auth.ClaimGrants {
 	return p.grants.Load()
 }
 
+func (p *ParticipantImpl) GetAttributes() map[string]string {
+	if grants := p.grants.Load(); grants != nil {
+		return grants.Attributes
+	}
+	return nil
+}
+
 func (p *ParticipantImpl) SetPermission(permission *livekit.ParticipantPermission) bool {
 	if permission == nil {
 		return false
@@ -1008,6 +1195,45 @@ func (p *ParticipantImpl) getOnMetrics() func(types.Participant, *livekit.DataPa
 	return p.onMetrics
 }
 
+// OnSpeakingChange sets the callback invoked when a published audio track starts or stops carrying speech,
+// following voice activity detection
+func (p *ParticipantImpl) OnSpeakingChange(callback func(types.LocalParticipant, livekit.TrackID, *audio.SpeakingTransition)) {
+	p.lock.Lock()
+	p.onSpeakingChange = callback
+	p.lock.Unlock()
+}
+
+func (p *ParticipantImpl) getOnSpeakingChange() func(types.LocalParticipant, livekit.TrackID, *audio.SpeakingTransition) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.onSpeakingChange
+}
+
+func (p *ParticipantImpl) handleSpeakingChange(trackID livekit.TrackID, transition *audio.SpeakingTransition) {
+	if onSpeakingChange := p.getOnSpeakingChange(); onSpeakingChange != nil {
+		onSpeakingChange(p, trackID, transition)
+	}
+}
+
+// OnUtterance sets the callback invoked when an utterance of a published audio track starts or ends
+func (p *ParticipantImpl) OnUtterance(callback func(types.LocalParticipant, livekit.TrackID, *audio.UtteranceEvent)) {
+	p.lock.Lock()
+	p.onUtterance = callback
+	p.lock.Unlock()
+}
+
+func (p *ParticipantImpl) getOnUtterance() func(types.LocalParticipant, livekit.TrackID, *audio.UtteranceEvent) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.onUtterance
+}
+
+func (p *ParticipantImpl) handleUtterance(trackID livekit.TrackID, event *audio.UtteranceEvent) {
+	if onUtterance := p.getOnUtterance(); onUtterance != nil {
+		onUtterance(p, trackID, event)
+	}
+}
+
 func (p *ParticipantImpl) OnUpdateSubscriptions(callback func(types.LocalParticipant, []livekit.TrackID, []*livekit.ParticipantTracks, bool)) {
 	p.lock.Lock()
 	p.onUpdateSubscriptions = callback
@@ -1826,6 +2052,16 @@ func (p *ParticipantImpl) IsPublisher() bool {
 	return p.isPublisher.Load()
 }
 
+// isPublishingMedia returns true if the participant publishes a track that is not muted
+func (p *ParticipantImpl) isPublishingMedia() bool {
+	for _, t := range p.GetPublishedTracks() {
+		if !t.IsMuted() {
+			return true
+		}
+	}
+	return false
+}
+
 func (p *ParticipantImpl) CanPublish() bool {
 	return p.grants.Load().Video.GetCanPublish()
 }
@@ -2053,6 +2289,11 @@ func (p *ParticipantImpl) setupTransportManager() error {
 		pth = PrimaryTransportHandler{pth, p}
 	}
 
+	capture, err := p.params.Config.Capture.Open(fmt.Sprintf("%s_%s", p.params.Identity, p.params.SID))
+	if err != nil {
+		p.params.Logger.Warnw("could not start capture", err)
+	}
+
 	params := TransportManagerParams{
 		// primary connection does not change, canSubscribe can change if permission was updated
 		// after the participant has joined
@@ -2080,6 +2321,10 @@ func (p *ParticipantImpl) setupTransportManager() error {
 		DataChannelStats:             p.dataChannelStats,
 		UseOneShotSignallingMode:     p.params.UseOneShotSignallingMode,
 		FireOnTrackBySdp:             p.params.FireOnTrackBySdp,
+		AudioConfig:                  &p.params.AudioConfig,
+		NoiseProfile:                 p.params.NoiseProfile,
+		Capture:                      capture,
+		IsPublishingMedia:            p.isPublishingMedia,
 	}
 	if p.params.SyncStreams && p.params.PlayoutDelay.GetEnabled() && p.params.ClientInfo.isFirefox() {
 		// we will disable playout delay for Firefox if the user is expecting
@@ -2088,8 +2333,13 @@ func (p *ParticipantImpl) setupTransportManager() error {
 	}
 	tm, err := NewTransportManager(params)
 	if err != nil {
+		_ = capture.Close()
 		return err
 	}
+	tm.SyncStageBypass(p.params.Identity, p.grants.Load().Attributes)
+	tm.SetNoiseFilterLanguage(p.noiseFilterLanguage())
+	tm.OnSpeakingChange(p.handleSpeakingChange)
+	tm.OnUtterance(p.handleUtterance)
 
 	tm.OnICEConfigChanged(func(iceConfig *livekit.ICEConfig) {
 		p.lock.Lock()
@@ -2519,6 +2769,10 @@ func (p *ParticipantImpl) handleReceivedDataMessage(kind livekit.DataPacket_Kind
 		if payload.RpcRequest == nil {
 			return
 		}
+		if payload.RpcRequest.Method == StatsRPCMethod {
+			go p.answerStatsRPC(payload.RpcRequest, dp.DestinationIdentities)
+			return
+		}
 		p.pubLogger.Infow(
 			"received RPC request",
 			"method", payload.RpcRequest.Method,
@@ -2697,6 +2951,17 @@ func (p *ParticipantImpl) setupDisconnectTimer() {
 	p.lock.Unlock()
 }
 
+// RestartTransport makes the client resume its session over fresh transports,
+// used to recover media that stopped flowing without the transport failing
+func (p *ParticipantImpl) RestartTransport() {
+	if p.IsClosed() || p.IsDisconnected() {
+		return
+	}
+
+	p.params.Logger.Infow("restarting transport")
+	p.onAnyTransportFailed()
+}
+
 func (p *ParticipantImpl) onAnyTransportFailed() {
 	if p.params.UseOneShotSignallingMode {
 		// as there is no way to notify participant, close the participant on transport failure
@@ -3292,6 +3557,27 @@ func (p *ParticipantImpl) mediaTrackReceived(track sfu.TrackRemote, rtpReceiver
 	p.pendingTracksLock.Unlock()
 
 	_, isReceiverAdded := mt.AddReceiver(rtpReceiver, track, mid)
+	if isReceiverAdded && !mt.IsNoiseFilterEnabled() {
+		// a stream received after the filter was switched off, e. g. on a codec change
+		p.TransportManager.SetStreamNoiseFilter(uint32(track.SSRC()), false)
+	}
+	if isReceiverAdded && mt.IsNoiseFilterCompatible() {
+		p.TransportManager.SetStreamNoiseFilterCompatible(uint32(track.SSRC()), true)
+	}
+	if reference := mt.EchoReference(); isReceiverAdded && reference != nil {
+		p.TransportManager.SetStreamEchoReference(uint32(track.SSRC()), reference)
+	}
+	if gainDB := mt.StreamGain(); isReceiverAdded && gainDB != 0 {
+		p.TransportManager.SetStreamGain(uint32(track.SSRC()), gainDB)
+	}
+	if isReceiverAdded && mt.Kind() == livekit.TrackType_AUDIO {
+		// phone audio reaches the server narrowband, whichever codec the SIP gateway publishes it with
+		if p.Kind() == livekit.ParticipantInfo_SIP {
+			p.TransportManager.SetStreamBandwidthExtension(uint32(track.SSRC()), true)
+		}
+		p.TransportManager.SetNoiseFilterStreamTrack(uint32(track.SSRC()), livekit.RoomName(p.grants.Load().Video.Room), mt.ID())
+		p.TransportManager.SetVADStreamTrack(uint32(track.SSRC()), mt.ID())
+	}
 
 	if newTrack {
 		go func() {
@@ -3401,6 +3687,7 @@ func (p *ParticipantImpl) addMediaTrack(signalCid string, ti *livekit.TrackInfo)
 		Reporter:              p.params.Reporter.WithTrack(ti.Sid),
 		SubscriberConfig:      p.params.Config.Subscriber,
 		PLIThrottleConfig:     p.params.PLIThrottleConfig,
+		FanOutConfig:          p.params.FanOutConfig,
 		SimTracks:             p.params.SimTracks,
 		OnRTCP:                p.postRtcp,
 		ForwardStats:          p.params.ForwardStats,
@@ -3409,10 +3696,15 @@ func (p *ParticipantImpl) addMediaTrack(signalCid string, ti *livekit.TrackInfo)
 			return p.helper().ShouldRegressCodec()
 		},
 		PreferVideoSizeFromMedia: p.params.PreferVideoSizeFromMedia,
+		ReceiveStages:            p.TransportManager.ReceiveStages(),
+		OnStreamGoodbye: func(ssrc uint32, reason string) {
+			p.TransportManager.EndNoiseFilterStream(ssrc, reason)
+		},
 	}, ti)
 
 	mt.OnSubscribedMaxQualityChange(p.onSubscribedMaxQualityChange)
 	mt.OnSubscribedAudioCodecChange(p.onSubscribedAudioCodecChange)
+	mt.OnNoiseFilterCompatibilityChange(p.onNoiseFilterCompatibilityChange)
 
 	// add to published and clean up pending
 	if p.supervisor != nil {
@@ -4194,6 +4486,7 @@ func (p *ParticipantImpl) HandleLeaveRequest(reason types.ParticipantCloseReason
 }
 
 func (p *ParticipantImpl) HandleSignalMessage(msg proto.Message) error {
+	p.TransportManager.CaptureSignal(msg)
 	return p.signalHandler.HandleMessage(msg)
 }
 
@@ -15,7 +15,6 @@
 package rtc
 
 import (
-	"fmt"
 	"slices"
 	"strconv"
 	"strings"
@@ -24,6 +23,7 @@ import (
 	"github.com/pion/webrtc/v4"
 
 	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
@@ -317,7 +317,8 @@ func (p *ParticipantImpl) setCodecPreferencesOpusRedForPublisher(
 	}
 }
 
-// configure publisher answer for audio track's dtx and stereo settings
+// configure publisher answer for audio track's dtx and stereo settings,
+// parameters enforced for the room take precedence over the track settings
 func (p *ParticipantImpl) configurePublisherAnswer(answer webrtc.SessionDescription) webrtc.SessionDescription {
 	offer := p.TransportManager.LastPublisherOffer()
 	parsedOffer, err := offer.Unmarshal()
@@ -366,10 +367,15 @@ func (p *ParticipantImpl) configurePublisherAnswer(answer webrtc.SessionDescript
 				}
 			}
 
-			if ti == nil || (ti.DisableDtx && !slices.Contains(ti.AudioFeatures, livekit.AudioTrackFeature_TF_STEREO)) {
+			if ti == nil {
+				continue
+			}
+			stereo := slices.Contains(ti.AudioFeatures, livekit.AudioTrackFeature_TF_STEREO)
+			if !p.params.OpusFmtp.Enabled && ti.DisableDtx && !stereo {
 				// no need to configure
 				continue
 			}
+			fmtpParams := p.params.OpusFmtp.Resolve(stereo, !ti.DisableDtx)
 
 			opusPT, err := parsed.GetPayloadTypeForCodec(sdp.Codec{Name: mime.MimeTypeCodecOpus.String()})
 			if err != nil {
@@ -378,13 +384,8 @@ func (p *ParticipantImpl) configurePublisherAnswer(answer webrtc.SessionDescript
 			}
 
 			for i, attr := range m.Attributes {
-				if strings.HasPrefix(attr.String(), fmt.Sprintf("fmtp:%d", opusPT)) {
-					if !ti.DisableDtx {
-						attr.Value += ";usedtx=1"
-					}
-					if slices.Contains(ti.AudioFeatures, livekit.AudioTrackFeature_TF_STEREO) {
-						attr.Value += ";stereo=1;maxaveragebitrate=510000"
-					}
+				if pt, fmtp, ok := strings.Cut(attr.Value, " "); ok && attr.Key == "fmtp" && pt == strconv.Itoa(int(opusPT)) {
+					attr.Value = pt + " " + audio.RewriteOpusFmtp(fmtp, fmtpParams)
 					m.Attributes[i] = attr
 				}
 			}
@@ -0,0 +1,239 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	pcmTapSubscriberPrefix = "PCM_"
+)
+
+var (
+	ErrPCMTapDisabled     = errors.New("pcm tap is not enabled")
+	ErrPCMTapNoAudioTrack = errors.New("no published audio track with this sid")
+	ErrPCMTapNotOpus      = errors.New("track is not Opus audio")
+	ErrPCMTapNoConsent    = errors.New("publisher has not consented to transcription")
+)
+
+type PCMTapsParams struct {
+	Config pcmtap.Config
+	Logger logger.Logger
+	// audio synthesized for lost packets instead of leaving holes
+	Concealment audio.ConcealmentConfig
+	// packets are reordered and paced before they are decoded
+	JitterBuffer audio.JitterBufferConfig
+	// workers decoding runs on, decoding runs on the forwarding path when nil
+	Placement     func() *placement.Slot
+	TrackPriority func(track types.MediaTrack) placement.Priority
+	// the audio of a publisher leaves the server only with consent to transcription
+	HasConsent func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool
+}
+
+// PCMTaps decodes the audio tracks of a room that consumers of the PCM tap service subscribe to.
+// A track is tapped from when its first consumer subscribes until its last one leaves, so that tracks
+// nobody listens to are not decoded.
+type PCMTaps struct {
+	params PCMTapsParams
+
+	lock sync.Mutex
+	taps map[livekit.TrackID]*pcmTrackTap
+}
+
+func NewPCMTaps(params PCMTapsParams) *PCMTaps {
+	return &PCMTaps{
+		params: params,
+		taps:   make(map[livekit.TrackID]*pcmTrackTap),
+	}
+}
+
+// Subscribe returns a consumer of the decoded audio of the track at sampleRate, in frames of the profile
+func (p *PCMTaps) Subscribe(track types.MediaTrack, profile pcmtap.Profile, sampleRate int) (*pcmtap.Subscriber, error) {
+	if p == nil {
+		return nil, ErrPCMTapDisabled
+	}
+	if track.Kind() != livekit.TrackType_AUDIO {
+		return nil, ErrPCMTapNoAudioTrack
+	}
+	if !p.params.HasConsent(track.PublisherIdentity(), ConsentTranscription) {
+		return nil, ErrPCMTapNoConsent
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if tap, ok := p.taps[track.ID()]; ok {
+		return tap.stream.SubscribeProfile(profile, sampleRate)
+	}
+
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		return nil, ErrPCMTapNotOpus
+	}
+	// stereo tracks are decoded as such and downmixed, the stream carries mono
+	channels := audio.OpusChannels(receiver.Codec().SDPFmtpLine)
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, channels)
+	if err != nil {
+		return nil, err
+	}
+
+	tap := &pcmTrackTap{
+		decoder:   decoder,
+		channels:  channels,
+		concealer: audio.NewLossConcealer(p.params.Concealment),
+		pcm:       make([]int16, audio.OpusMaxFrameSize*channels),
+	}
+	tap.stream = pcmtap.NewStream(p.params.Config, func() {
+		p.removeIdle(track.ID(), tap)
+	})
+	sub, err := tap.stream.SubscribeProfile(profile, sampleRate)
+	if err != nil {
+		return nil, err
+	}
+
+	tap.receiverTap = newReceiverTap(pcmTapSubscriberPrefix, track.ID(), receiver, tap.onPacket)
+	tap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(channels))
+	tap.bufferJitter(p.params.JitterBuffer, audio.OpusSampleRate)
+	if p.params.Placement != nil {
+		tap.schedule(p.params.Placement(), func() placement.Priority { return p.params.TrackPriority(track) })
+	}
+	if err := tap.start(); err != nil {
+		p.params.Logger.Warnw("could not tap receiver for pcm", err, "trackID", track.ID())
+		tap.stream.Close()
+		return nil, err
+	}
+	p.taps[track.ID()] = tap
+	p.params.Logger.Debugw("pcm tap started", "trackID", track.ID())
+	return sub, nil
+}
+
+// RemoveTrack ends the consumers of the track once they have read the audio buffered for them
+func (p *PCMTaps) RemoveTrack(trackID livekit.TrackID) {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	tap, ok := p.taps[trackID]
+	delete(p.taps, trackID)
+	p.lock.Unlock()
+
+	if ok {
+		tap.close()
+	}
+}
+
+func (p *PCMTaps) Stop() {
+	if p == nil {
+		return
+	}
+
+	p.lock.Lock()
+	taps := p.taps
+	p.taps = make(map[livekit.TrackID]*pcmTrackTap)
+	p.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.close()
+	}
+}
+
+// removeIdle stops tapping the track once its last consumer left, unless another one subscribed meanwhile
+func (p *PCMTaps) removeIdle(trackID livekit.TrackID, tap *pcmTrackTap) {
+	p.lock.Lock()
+	if p.taps[trackID] != tap || tap.stream.NumSubscribers() != 0 {
+		p.lock.Unlock()
+		return
+	}
+	delete(p.taps, trackID)
+	p.lock.Unlock()
+
+	tap.close()
+	p.params.Logger.Debugw("pcm tap stopped", "trackID", trackID)
+}
+
+// --------------------------------------
+
+type pcmTrackTap struct {
+	*receiverTap
+
+	stream   *pcmtap.Stream
+	decoder  audio.OpusDecoder
+	channels int
+	// nil unless concealment is enabled
+	concealer *audio.LossConcealer
+	pcm       []int16
+	// extended RTP timestamp of the first packet, positions in the track are counted from it
+	firstTimestamp uint64
+	started        bool
+}
+
+func (t *pcmTrackTap) onPacket(p *buffer.ExtPacket) {
+	if n := concealLoss(t.concealer, t.decoder, t.channels, "pcm_tap", p, t.pcm); n > 0 && t.started {
+		// the concealment ends where the packet starts
+		t.stream.Write(downmix(t.pcm, n, t.channels), t.position(p.ExtTimestamp-uint64(n)))
+	}
+
+	n, err := t.decoder.Decode(p.Packet.Payload, t.pcm)
+	if err != nil || n == 0 {
+		return
+	}
+	if !t.started {
+		t.firstTimestamp, t.started = p.ExtTimestamp, true
+	}
+	t.stream.Write(downmix(t.pcm, n, t.channels), t.position(p.ExtTimestamp))
+}
+
+// position returns the position of an extended RTP timestamp in the track
+func (t *pcmTrackTap) position(extTimestamp uint64) time.Duration {
+	// the RTP clock of Opus runs at 48 kHz
+	if extTimestamp <= t.firstTimestamp {
+		return 0
+	}
+	return time.Duration(extTimestamp-t.firstTimestamp) * time.Second / audio.OpusSampleRate
+}
+
+// downmix averages the channels of the first samples per channel of interleaved pcm into its first samples
+func downmix(pcm []int16, samples int, channels int) []int16 {
+	if channels == 1 {
+		return pcm[:samples]
+	}
+	for i := 0; i < samples; i++ {
+		sum := 0
+		for _, sample := range pcm[i*channels : (i+1)*channels] {
+			sum += int(sample)
+		}
+		pcm[i] = int16(sum / channels)
+	}
+	return pcm[:samples]
+}
+
+func (t *pcmTrackTap) close() {
+	t.stop()
+	t.stream.Close()
+}
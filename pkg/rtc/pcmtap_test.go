@@ -0,0 +1,61 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
+)
+
+func TestPCMTaps_Subscribe(t *testing.T) {
+	consented := map[livekit.ParticipantIdentity]bool{"consented": true}
+	taps := NewPCMTaps(PCMTapsParams{
+		HasConsent: func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool {
+			return flags == ConsentTranscription && consented[identity]
+		},
+	})
+
+	track := &typesfakes.FakeMediaTrack{}
+	track.KindReturns(livekit.TrackType_AUDIO)
+	track.IDReturns("TR_audio")
+
+	t.Run("requires consent to transcription", func(t *testing.T) {
+		track.PublisherIdentityReturns("other")
+		_, err := taps.Subscribe(track, pcmtap.Profile_PROFILE_RAW, 16000)
+		require.ErrorIs(t, err, ErrPCMTapNoConsent)
+	})
+
+	t.Run("decodes with consent", func(t *testing.T) {
+		track.PublisherIdentityReturns("consented")
+		// the fake track has no receivers, so it gets past consent to the codec check
+		_, err := taps.Subscribe(track, pcmtap.Profile_PROFILE_RAW, 16000)
+		require.ErrorIs(t, err, ErrPCMTapNotOpus)
+	})
+}
+
+func TestDownmix(t *testing.T) {
+	pcm := []int16{100, 300, -200, -400, 7, 8}
+	require.Equal(t, []int16{200, -300, 7}, downmix(pcm, 3, 2))
+
+	mono := []int16{1, 2, 3, 4}
+	require.Equal(t, []int16{1, 2}, downmix(mono, 2, 1))
+}
@@ -0,0 +1,255 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+const (
+	// topic of the data packets announcing that optional processing of the room was switched off or on again
+	ProcessingBypassTopic = "agentix.processing_bypass"
+
+	WebhookEventProcessingBypassEnabled  = "processing_bypass_enabled"
+	WebhookEventProcessingBypassDisabled = "processing_bypass_disabled"
+)
+
+type ProcessingBypassAction string
+
+const (
+	ProcessingBypassActionEnable  ProcessingBypassAction = "enable"
+	ProcessingBypassActionExtend  ProcessingBypassAction = "extend"
+	ProcessingBypassActionDisable ProcessingBypassAction = "disable"
+	// the bypass timer ran out and processing was re-enabled
+	ProcessingBypassActionExpire ProcessingBypassAction = "expire"
+)
+
+type ProcessingBypassEvent struct {
+	Action ProcessingBypassAction `json:"action"`
+	At     time.Time              `json:"at"`
+	// identity of whoever switched the bypass, empty for expiry
+	Actor  string `json:"actor,omitempty"`
+	Reason string `json:"reason,omitempty"`
+	// when processing is re-enabled automatically, for enable and extend
+	Until *time.Time `json:"until,omitempty"`
+}
+
+type ProcessingBypassState struct {
+	Active bool       `json:"active"`
+	Until  *time.Time `json:"until,omitempty"`
+	// most recent last
+	Events []ProcessingBypassEvent `json:"events"`
+}
+
+type ProcessingBypassParams struct {
+	Config config.ProcessingBypassConfig
+	Logger logger.Logger
+	// called with active true when processing is to be bypassed, false when it is re-enabled
+	OnChange func(active bool, event ProcessingBypassEvent)
+}
+
+// ProcessingBypass is the emergency switch of a room reverting it to pure forwarding, i. e. without
+// noise filtering or audio mixing. A bypass always ends, processing is re-enabled when its timer runs out.
+type ProcessingBypass struct {
+	params ProcessingBypassParams
+
+	lock   sync.Mutex
+	active bool
+	until  time.Time
+	timer  *time.Timer
+	events []ProcessingBypassEvent
+}
+
+func NewProcessingBypass(params ProcessingBypassParams) *ProcessingBypass {
+	if params.Config.DefaultDuration <= 0 {
+		params.Config.DefaultDuration = config.DefaultProcessingBypassConfig.DefaultDuration
+	}
+	if params.Config.MaxDuration < params.Config.DefaultDuration {
+		params.Config.MaxDuration = params.Config.DefaultDuration
+	}
+	if params.Config.MaxEvents <= 0 {
+		params.Config.MaxEvents = config.DefaultProcessingBypassConfig.MaxEvents
+	}
+	return &ProcessingBypass{
+		params: params,
+	}
+}
+
+// Enable bypasses processing for duration, the configured default when 0, capped at the configured maximum.
+// Enabling an active bypass moves its end.
+func (b *ProcessingBypass) Enable(duration time.Duration, actor string, reason string) ProcessingBypassState {
+	if b == nil {
+		return ProcessingBypassState{}
+	}
+
+	if duration <= 0 {
+		duration = b.params.Config.DefaultDuration
+	}
+	if duration > b.params.Config.MaxDuration {
+		duration = b.params.Config.MaxDuration
+	}
+
+	b.lock.Lock()
+	wasActive := b.active
+	b.active = true
+	b.until = time.Now().Add(duration)
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	until := b.until
+	b.timer = time.AfterFunc(duration, func() {
+		b.expire(until)
+	})
+
+	action := ProcessingBypassActionEnable
+	if wasActive {
+		action = ProcessingBypassActionExtend
+	}
+	event := b.recordLocked(action, actor, reason)
+	state := b.stateLocked()
+	b.lock.Unlock()
+
+	b.params.Logger.Infow(
+		"processing bypass enabled",
+		"actor", actor,
+		"reason", reason,
+		"until", until,
+		"extended", wasActive,
+	)
+	if !wasActive && b.params.OnChange != nil {
+		b.params.OnChange(true, event)
+	}
+	return state
+}
+
+// Disable re-enables processing ahead of the timer, returns false if no bypass was active
+func (b *ProcessingBypass) Disable(actor string, reason string) bool {
+	if b == nil {
+		return false
+	}
+
+	b.lock.Lock()
+	if !b.active {
+		b.lock.Unlock()
+		return false
+	}
+	event := b.deactivateLocked(ProcessingBypassActionDisable, actor, reason)
+	b.lock.Unlock()
+
+	b.params.Logger.Infow("processing bypass disabled", "actor", actor, "reason", reason)
+	if b.params.OnChange != nil {
+		b.params.OnChange(false, event)
+	}
+	return true
+}
+
+func (b *ProcessingBypass) IsActive() bool {
+	if b == nil {
+		return false
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.active
+}
+
+// State returns whether processing is bypassed and the trail of switches
+func (b *ProcessingBypass) State() ProcessingBypassState {
+	if b == nil {
+		return ProcessingBypassState{}
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return b.stateLocked()
+}
+
+func (b *ProcessingBypass) Stop() {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	b.lock.Unlock()
+}
+
+func (b *ProcessingBypass) expire(until time.Time) {
+	b.lock.Lock()
+	if !b.active || !b.until.Equal(until) {
+		// disabled or extended meanwhile
+		b.lock.Unlock()
+		return
+	}
+	event := b.deactivateLocked(ProcessingBypassActionExpire, "", "")
+	b.lock.Unlock()
+
+	b.params.Logger.Infow("processing bypass expired, re-enabling processing")
+	if b.params.OnChange != nil {
+		b.params.OnChange(false, event)
+	}
+}
+
+func (b *ProcessingBypass) deactivateLocked(action ProcessingBypassAction, actor string, reason string) ProcessingBypassEvent {
+	b.active = false
+	b.until = time.Time{}
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	return b.recordLocked(action, actor, reason)
+}
+
+func (b *ProcessingBypass) recordLocked(action ProcessingBypassAction, actor string, reason string) ProcessingBypassEvent {
+	event := ProcessingBypassEvent{
+		Action: action,
+		At:     time.Now(),
+		Actor:  actor,
+		Reason: reason,
+	}
+	if b.active {
+		until := b.until
+		event.Until = &until
+	}
+
+	b.events = append(b.events, event)
+	if excess := len(b.events) - b.params.Config.MaxEvents; excess > 0 {
+		b.events = append(b.events[:0], b.events[excess:]...)
+	}
+	return event
+}
+
+func (b *ProcessingBypass) stateLocked() ProcessingBypassState {
+	state := ProcessingBypassState{
+		Active: b.active,
+		Events: append([]ProcessingBypassEvent{}, b.events...),
+	}
+	if b.active {
+		until := b.until
+		state.Until = &until
+	}
+	return state
+}
@@ -0,0 +1,114 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+)
+
+type bypassChanges struct {
+	lock   sync.Mutex
+	active []bool
+}
+
+func (c *bypassChanges) onChange(active bool, _ ProcessingBypassEvent) {
+	c.lock.Lock()
+	c.active = append(c.active, active)
+	c.lock.Unlock()
+}
+
+func (c *bypassChanges) get() []bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]bool{}, c.active...)
+}
+
+func TestProcessingBypass(t *testing.T) {
+	t.Run("expires", func(t *testing.T) {
+		changes := &bypassChanges{}
+		b := NewProcessingBypass(ProcessingBypassParams{
+			Config:   config.ProcessingBypassConfig{DefaultDuration: 100 * time.Millisecond, MaxDuration: time.Second},
+			Logger:   logger.GetLogger(),
+			OnChange: changes.onChange,
+		})
+		defer b.Stop()
+
+		state := b.Enable(0, "admin", "denoiser crashing")
+		require.True(t, state.Active)
+		require.NotNil(t, state.Until)
+		require.True(t, b.IsActive())
+
+		// extending does not switch again
+		b.Enable(200*time.Millisecond, "admin", "")
+		require.Equal(t, []bool{true}, changes.get())
+
+		require.Eventually(t, func() bool { return !b.IsActive() }, time.Second, 10*time.Millisecond)
+		require.Equal(t, []bool{true, false}, changes.get())
+
+		state = b.State()
+		require.False(t, state.Active)
+		require.Nil(t, state.Until)
+		actions := make([]ProcessingBypassAction, 0, len(state.Events))
+		for _, e := range state.Events {
+			actions = append(actions, e.Action)
+		}
+		require.Equal(t, []ProcessingBypassAction{
+			ProcessingBypassActionEnable,
+			ProcessingBypassActionExtend,
+			ProcessingBypassActionExpire,
+		}, actions)
+		require.Equal(t, "denoiser crashing", state.Events[0].Reason)
+		require.Equal(t, "admin", state.Events[0].Actor)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		changes := &bypassChanges{}
+		b := NewProcessingBypass(ProcessingBypassParams{
+			Config:   config.ProcessingBypassConfig{DefaultDuration: 50 * time.Millisecond, MaxEvents: 2},
+			Logger:   logger.GetLogger(),
+			OnChange: changes.onChange,
+		})
+		defer b.Stop()
+
+		require.False(t, b.Disable("admin", ""))
+
+		// capped at the maximum duration
+		state := b.Enable(time.Hour, "admin", "")
+		require.WithinDuration(t, time.Now().Add(50*time.Millisecond), *state.Until, 20*time.Millisecond)
+
+		require.True(t, b.Disable("admin", "resolved"))
+		require.False(t, b.IsActive())
+
+		// the timer of the disabled bypass does not fire
+		time.Sleep(100 * time.Millisecond)
+		require.Equal(t, []bool{true, false}, changes.get())
+
+		b.Enable(0, "admin", "")
+		state = b.State()
+		require.Len(t, state.Events, 2)
+		require.Equal(t, ProcessingBypassActionDisable, state.Events[0].Action)
+		require.Equal(t, "resolved", state.Events[0].Reason)
+		require.Equal(t, ProcessingBypassActionEnable, state.Events[1].Action)
+	})
+}
@@ -0,0 +1,390 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/audioinject"
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/realtime"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// topic of the data packets telling participants that a bridge started or stopped
+	RealtimeBridgeTopic = "agentix.realtime_bridge"
+
+	RealtimeBridgeEventStarted = "started"
+	RealtimeBridgeEventStopped = "stopped"
+
+	// audio of the model queued ahead of playback, models answer faster than real time
+	realtimeBridgeBuffer = 2 * time.Minute
+)
+
+var (
+	ErrRealtimeBridgeDisabled  = errors.New("realtime bridge is disabled")
+	ErrTooManyRealtimeBridges  = errors.New("too many realtime bridges in the room")
+	ErrRealtimeBridgeExists    = errors.New("track is already bridged")
+	ErrRealtimeBridgeNotFound  = errors.New("realtime bridge not found")
+	ErrRealtimeBridgeStopped   = errors.New("realtime bridge stopped")
+	ErrRealtimeBridgeNoConsent = errors.New("publisher has not consented to transcription")
+)
+
+// RealtimeBridgeEvent tells participants about a bridge
+type RealtimeBridgeEvent struct {
+	Event string `json:"event"`
+	// track relayed to the model
+	TrackID livekit.TrackID `json:"track_id"`
+	// server side track playing the audio of the model
+	OutputTrackID livekit.TrackID `json:"output_track_id,omitempty"`
+	// why the bridge stopped, for stopped
+	Error string `json:"error,omitempty"`
+}
+
+type RealtimeBridgesParams struct {
+	Config       realtime.Config
+	Logger       logger.Logger
+	SubscribePCM func(trackID livekit.TrackID, profile pcmtap.Profile, sampleRate int) (*pcmtap.Subscriber, error)
+	OnEvent      func(event *RealtimeBridgeEvent)
+}
+
+// RealtimeBridges relays the audio of tracks to sessions of a realtime speech model and plays what the model
+// answers into the room as a server side track, acting as a voice agent participant. Audio of the model still
+// playing is dropped when the model reports that the user interrupted it.
+type RealtimeBridges struct {
+	params     RealtimeBridgesParams
+	injections *AudioInjections
+
+	lock    sync.Mutex
+	bridges map[livekit.TrackID]*realtimeBridge
+	stopped core.Fuse
+}
+
+func NewRealtimeBridges(params RealtimeBridgesParams) *RealtimeBridges {
+	return &RealtimeBridges{
+		params: params,
+		injections: NewAudioInjections(AudioInjectionsParams{
+			Config: audioinject.Config{
+				BufferDuration: realtimeBridgeBuffer,
+				MaxTracks:      params.Config.MaxSessions,
+			},
+			Logger:  params.Logger,
+			OnEvent: func(event *AudioInjectionEvent) {},
+		}),
+		bridges: make(map[livekit.TrackID]*realtimeBridge),
+	}
+}
+
+// Start connects a track to a new session of the model, overrides replacing settings of the config
+func (b *RealtimeBridges) Start(trackID livekit.TrackID, overrides realtime.SessionOverrides) (livekit.TrackID, error) {
+	if b == nil {
+		return "", ErrRealtimeBridgeDisabled
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bridge := &realtimeBridge{trackID: trackID, cancel: cancel}
+
+	// reserved while connecting, so that a track is bridged once
+	b.lock.Lock()
+	switch {
+	case b.stopped.IsBroken():
+		b.lock.Unlock()
+		cancel()
+		return "", ErrRealtimeBridgeDisabled
+	case b.bridges[trackID] != nil:
+		b.lock.Unlock()
+		cancel()
+		return "", ErrRealtimeBridgeExists
+	case b.params.Config.MaxSessions > 0 && len(b.bridges) >= b.params.Config.MaxSessions:
+		b.lock.Unlock()
+		cancel()
+		return "", ErrTooManyRealtimeBridges
+	}
+	b.bridges[trackID] = bridge
+	b.lock.Unlock()
+
+	if err := b.connect(ctx, bridge, overrides); err != nil {
+		b.params.Logger.Warnw("could not start realtime bridge", err, "trackID", trackID)
+		b.stop(bridge, nil)
+		return "", err
+	}
+	if !bridge.start() {
+		return "", ErrRealtimeBridgeStopped
+	}
+
+	b.params.Logger.Infow("realtime bridge started", "trackID", trackID, "outputTrackID", bridge.outputTrackID)
+	b.params.OnEvent(&RealtimeBridgeEvent{
+		Event:         RealtimeBridgeEventStarted,
+		TrackID:       trackID,
+		OutputTrackID: bridge.outputTrackID,
+	})
+	go b.relay(ctx, bridge)
+	return bridge.outputTrackID, nil
+}
+
+// Stop disconnects a track from its session
+func (b *RealtimeBridges) Stop(trackID livekit.TrackID) error {
+	if b == nil {
+		return ErrRealtimeBridgeDisabled
+	}
+
+	b.lock.Lock()
+	bridge, ok := b.bridges[trackID]
+	b.lock.Unlock()
+
+	if !ok {
+		return ErrRealtimeBridgeNotFound
+	}
+	b.stop(bridge, nil)
+	return nil
+}
+
+// RemoveTrack stops the bridge of a track whose publisher withdrew consent, if any
+func (b *RealtimeBridges) RemoveTrack(trackID livekit.TrackID) {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	bridge, ok := b.bridges[trackID]
+	b.lock.Unlock()
+
+	if ok {
+		b.stop(bridge, ErrRealtimeBridgeNoConsent)
+	}
+}
+
+func (b *RealtimeBridges) AddViewer(p types.LocalParticipant) {
+	if b == nil {
+		return
+	}
+	b.injections.AddViewer(p)
+}
+
+func (b *RealtimeBridges) RemoveViewer(p types.LocalParticipant) {
+	if b == nil {
+		return
+	}
+	b.injections.RemoveViewer(p)
+}
+
+func (b *RealtimeBridges) Close() {
+	if b == nil {
+		return
+	}
+
+	b.lock.Lock()
+	b.stopped.Break()
+	bridges := make([]*realtimeBridge, 0, len(b.bridges))
+	for _, bridge := range b.bridges {
+		bridges = append(bridges, bridge)
+	}
+	b.lock.Unlock()
+
+	for _, bridge := range bridges {
+		b.stop(bridge, nil)
+	}
+	b.injections.Close()
+}
+
+// connect subscribes to the audio of the track, adds the output track and dials the model
+func (b *RealtimeBridges) connect(ctx context.Context, bridge *realtimeBridge, overrides realtime.SessionOverrides) error {
+	subscriber, err := b.params.SubscribePCM(bridge.trackID, pcmtap.ProfileRaw, audio.OpusSampleRate)
+	if err != nil {
+		return err
+	}
+	if !bridge.setSubscriber(subscriber) {
+		subscriber.Close()
+		return ErrRealtimeBridgeStopped
+	}
+
+	outputTrackID, player, err := b.injections.Start(
+		"realtime:"+string(bridge.trackID),
+		nil,
+		func(reason audioinject.FinishReason, played time.Duration) {
+			if reason == audioinject.FinishClosed {
+				b.stop(bridge, nil)
+			}
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if !bridge.setPlayer(outputTrackID, player) {
+		player.Cancel()
+		return ErrRealtimeBridgeStopped
+	}
+
+	session, err := realtime.Dial(ctx, realtime.SessionParams{
+		Config: b.params.Config.WithOverrides(overrides),
+		OnEvent: func(event realtime.Event) {
+			b.onSessionEvent(ctx, bridge, player, event)
+		},
+		OnClose: func(err error) {
+			b.stop(bridge, err)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if !bridge.setSession(session) {
+		session.Close()
+		return ErrRealtimeBridgeStopped
+	}
+	return nil
+}
+
+func (b *RealtimeBridges) onSessionEvent(ctx context.Context, bridge *realtimeBridge, player *audioinject.Player, event realtime.Event) {
+	if event.Interrupted {
+		player.Flush()
+	}
+	if len(event.Audio) != 0 {
+		if err := player.WritePCM(ctx, event.Audio, realtime.OutputSampleRate); err != nil && ctx.Err() == nil {
+			b.params.Logger.Warnw("could not play realtime model audio", err, "trackID", bridge.trackID)
+		}
+	}
+	if event.Error != "" {
+		b.params.Logger.Infow("realtime model error", "trackID", bridge.trackID, "error", event.Error)
+	}
+}
+
+// relay sends the audio of the track to the model until either goes away
+func (b *RealtimeBridges) relay(ctx context.Context, bridge *realtimeBridge) {
+	subscriber, session := bridge.subscriber, bridge.session
+	for {
+		frame, err := subscriber.Next(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				b.stop(bridge, nil)
+			}
+			return
+		}
+		if err := session.SendAudio(frame.PCM, frame.SampleRate); err != nil {
+			b.stop(bridge, err)
+			return
+		}
+	}
+}
+
+func (b *RealtimeBridges) stop(bridge *realtimeBridge, err error) {
+	stopped, started := bridge.stop()
+	if !stopped {
+		return
+	}
+
+	b.lock.Lock()
+	if b.bridges[bridge.trackID] == bridge {
+		delete(b.bridges, bridge.trackID)
+	}
+	b.lock.Unlock()
+
+	if !started {
+		return
+	}
+	event := &RealtimeBridgeEvent{
+		Event:   RealtimeBridgeEventStopped,
+		TrackID: bridge.trackID,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	b.params.Logger.Infow("realtime bridge stopped", "trackID", bridge.trackID, "error", err)
+	b.params.OnEvent(event)
+}
+
+// --------------------------------------
+
+type realtimeBridge struct {
+	trackID livekit.TrackID
+	cancel  context.CancelFunc
+
+	lock          sync.Mutex
+	outputTrackID livekit.TrackID
+	subscriber    *pcmtap.Subscriber
+	player        *audioinject.Player
+	session       *realtime.Session
+	started       bool
+	stopped       bool
+}
+
+// setSubscriber, setPlayer and setSession return false if the bridge stopped while connecting
+func (r *realtimeBridge) setSubscriber(subscriber *pcmtap.Subscriber) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.subscriber = subscriber
+	return !r.stopped
+}
+
+func (r *realtimeBridge) setPlayer(outputTrackID livekit.TrackID, player *audioinject.Player) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.outputTrackID = outputTrackID
+	r.player = player
+	return !r.stopped
+}
+
+func (r *realtimeBridge) setSession(session *realtime.Session) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.session = session
+	return !r.stopped
+}
+
+// start marks the bridge connected, returns false if it stopped while connecting
+func (r *realtimeBridge) start() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.started = !r.stopped
+	return r.started
+}
+
+// stop releases what was set up so far, returns false if already stopped, and whether the bridge was connected
+func (r *realtimeBridge) stop() (bool, bool) {
+	r.lock.Lock()
+	if r.stopped {
+		r.lock.Unlock()
+		return false, false
+	}
+	r.stopped = true
+	subscriber, player, session, started := r.subscriber, r.player, r.session, r.started
+	r.lock.Unlock()
+
+	r.cancel()
+	if subscriber != nil {
+		subscriber.Close()
+	}
+	if session != nil {
+		session.Close()
+	}
+	if player != nil {
+		player.Cancel()
+	}
+	return true, started
+}
@@ -0,0 +1,156 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reaper
+
+import (
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how often participants and rooms are checked
+	CheckInterval time.Duration `yaml:"check_interval,omitempty"`
+	// participants without media or data for this long are disconnected, 0 disables
+	ParticipantTimeout time.Duration `yaml:"participant_timeout,omitempty"`
+	// rooms without media or data from any participant for this long are closed, 0 disables
+	RoomTimeout time.Duration `yaml:"room_timeout,omitempty"`
+	// how long before disconnecting or closing a warning is sent, 0 disables warnings
+	WarningPeriod time.Duration `yaml:"warning_period,omitempty"`
+	// participants with these roles (attribute `agentix.role`) are never disconnected
+	ExemptRoles []string `yaml:"exempt_roles,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		CheckInterval:      10 * time.Second,
+		ParticipantTimeout: 10 * time.Minute,
+		RoomTimeout:        30 * time.Minute,
+		WarningPeriod:      time.Minute,
+	}
+)
+
+func (c Config) IsExempt(role string) bool {
+	if role == "" {
+		return false
+	}
+	for _, r := range c.ExemptRoles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// --------------------------------------
+
+type Action int
+
+const (
+	ActionNone Action = iota
+	// the participant or room is about to be reaped unless there is activity
+	ActionWarn
+	// disconnect the participant or close the room
+	ActionReap
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionNone:
+		return "none"
+	case ActionWarn:
+		return "warn"
+	case ActionReap:
+		return "reap"
+	default:
+		return "unknown"
+	}
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+// --------------------------------------
+
+// Tracker follows the activity of a participant or a room. Each action is returned once,
+// activity after a warning arms the warning again.
+type Tracker struct {
+	timeout       time.Duration
+	warningPeriod time.Duration
+
+	lock         sync.Mutex
+	lastActivity time.Time
+	warned       bool
+	reaped       bool
+}
+
+func NewTracker(timeout time.Duration, warningPeriod time.Duration, now time.Time) *Tracker {
+	return &Tracker{
+		timeout:       timeout,
+		warningPeriod: min(warningPeriod, timeout),
+		lastActivity:  now,
+	}
+}
+
+// Touch records activity at the given time, returns true if a pending warning was cleared
+func (t *Tracker) Touch(at time.Time) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if at.After(t.lastActivity) {
+		t.lastActivity = at
+	}
+	warned := t.warned
+	t.warned = false
+	return warned
+}
+
+func (t *Tracker) LastActivity() time.Time {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.lastActivity
+}
+
+// Check returns the action due at now and how long the tracked entity has been idle
+func (t *Tracker) Check(now time.Time) (Action, time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	idle := now.Sub(t.lastActivity)
+	if t.timeout <= 0 || t.reaped {
+		return ActionNone, idle
+	}
+
+	if idle >= t.timeout {
+		t.reaped = true
+		return ActionReap, idle
+	}
+	if !t.warned && t.warningPeriod > 0 && idle >= t.timeout-t.warningPeriod {
+		t.warned = true
+		return ActionWarn, idle
+	}
+	return ActionNone, idle
+}
+
+// Remaining returns the time left until the tracked entity is reaped
+func (t *Tracker) Remaining(now time.Time) time.Duration {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return max(0, t.timeout-now.Sub(t.lastActivity))
+}
@@ -0,0 +1,95 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reaper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	start := time.Now()
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	t.Run("warns then reaps", func(t *testing.T) {
+		tr := NewTracker(10*time.Minute, time.Minute, start)
+
+		action, _ := tr.Check(at(8 * time.Minute))
+		require.Equal(t, ActionNone, action)
+
+		action, idle := tr.Check(at(9 * time.Minute))
+		require.Equal(t, ActionWarn, action)
+		require.Equal(t, 9*time.Minute, idle)
+		require.Equal(t, time.Minute, tr.Remaining(at(9*time.Minute)))
+
+		// warned once
+		action, _ = tr.Check(at(9*time.Minute + 30*time.Second))
+		require.Equal(t, ActionNone, action)
+
+		action, _ = tr.Check(at(10 * time.Minute))
+		require.Equal(t, ActionReap, action)
+
+		// reaped once
+		action, _ = tr.Check(at(20 * time.Minute))
+		require.Equal(t, ActionNone, action)
+	})
+
+	t.Run("activity clears warning", func(t *testing.T) {
+		tr := NewTracker(10*time.Minute, time.Minute, start)
+
+		action, _ := tr.Check(at(9 * time.Minute))
+		require.Equal(t, ActionWarn, action)
+		require.True(t, tr.Touch(at(9*time.Minute+10*time.Second)))
+		require.False(t, tr.Touch(at(9*time.Minute+20*time.Second)))
+
+		action, _ = tr.Check(at(10 * time.Minute))
+		require.Equal(t, ActionNone, action)
+
+		// warns again after another idle period
+		action, _ = tr.Check(at(18*time.Minute + 20*time.Second))
+		require.Equal(t, ActionWarn, action)
+	})
+
+	t.Run("activity does not go back in time", func(t *testing.T) {
+		tr := NewTracker(10*time.Minute, time.Minute, start)
+		tr.Touch(at(5 * time.Minute))
+		tr.Touch(at(2 * time.Minute))
+		require.Equal(t, at(5*time.Minute), tr.LastActivity())
+	})
+
+	t.Run("without warning period", func(t *testing.T) {
+		tr := NewTracker(10*time.Minute, 0, start)
+		action, _ := tr.Check(at(9*time.Minute + 59*time.Second))
+		require.Equal(t, ActionNone, action)
+		action, _ = tr.Check(at(10 * time.Minute))
+		require.Equal(t, ActionReap, action)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tr := NewTracker(0, time.Minute, start)
+		action, _ := tr.Check(at(time.Hour))
+		require.Equal(t, ActionNone, action)
+	})
+}
+
+func TestConfigIsExempt(t *testing.T) {
+	conf := Config{ExemptRoles: []string{"agent"}}
+	require.True(t, conf.IsExempt("agent"))
+	require.False(t, conf.IsExempt("viewer"))
+	require.False(t, conf.IsExempt(""))
+}
@@ -0,0 +1,297 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/memtrack"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+const (
+	// packets a scheduled tap queues before dropping, 1 s of 20 ms audio
+	receiverTapMaxQueued = 50
+)
+
+// opusReceiver returns the receiver delivering plain Opus packets of an audio track,
+// for RED publications that is the primary encoding extracted from RED
+func opusReceiver(track types.MediaTrack) sfu.TrackReceiver {
+	for _, r := range track.Receivers() {
+		switch r.Mime() {
+		case mime.MimeTypeOpus:
+			return r
+		case mime.MimeTypeRED:
+			return r.GetPrimaryReceiverForRed()
+		}
+	}
+	return nil
+}
+
+// number of receiver taps attached across all rooms, every processing stage of a track holds one
+var liveReceiverTaps atomic.Int64
+
+// --------------------------------------
+
+// receiverTap is attached to a publisher's receiver like a down track to observe the received packets
+// without forwarding them anywhere. The prefix keeps the subscriber ID of taps of different features apart.
+type receiverTap struct {
+	prefix   string
+	trackID  livekit.TrackID
+	receiver sfu.TrackReceiver
+	onPacket func(p *buffer.ExtPacket)
+	queue    *placement.SerialQueue
+
+	onTelephoneEvent func(p *buffer.ExtPacket)
+
+	// memory held by the feature for the tap, see accountMemory
+	goBytes     int
+	nativeBytes int
+	mem         atomic.Pointer[memtrack.Instance]
+
+	// packets are reordered and paced ahead of onPacket, see bufferJitter
+	jitterLock  sync.Mutex
+	jitter      *audio.JitterBuffer[*buffer.ExtPacket]
+	jitterTimer *time.Timer
+	jitterStats audio.JitterBufferStats
+
+	started atomic.Bool
+	closed  atomic.Bool
+}
+
+// concealLoss writes audio synthesized for the packets lost ahead of p into pcm, interleaving channels, with the
+// decoder of a tap, before p is decoded, and returns its samples per channel. stage labels the concealment in the metrics.
+func concealLoss(concealer *audio.LossConcealer, decoder audio.OpusDecoder, channels int, stage string, p *buffer.ExtPacket, pcm []int16) int {
+	lost := concealer.Observe(p.Packet.SequenceNumber, p.Packet.Timestamp, p.Packet.Payload)
+	if lost == 0 {
+		return 0
+	}
+
+	// what was synthesized before an error is still valid
+	result, _ := concealer.Conceal(decoder, channels, lost, p.Packet.Payload, pcm)
+	plc, fec := result.Durations()
+	prometheus.RecordConcealment(stage, plc, fec)
+	return result.Samples()
+}
+
+func newReceiverTap(prefix string, trackID livekit.TrackID, receiver sfu.TrackReceiver, onPacket func(p *buffer.ExtPacket)) *receiverTap {
+	return &receiverTap{
+		prefix:   prefix,
+		trackID:  trackID,
+		receiver: receiver,
+		onPacket: onPacket,
+	}
+}
+
+// schedule moves packet processing off the forwarding path onto the workers of the slot.
+// Packets are processed in order at the given priority and may be dropped when workers fall behind.
+// Has to be called before start, a nil slot keeps processing inline.
+func (t *receiverTap) schedule(slot *placement.Slot, priority func() placement.Priority) {
+	if slot == nil {
+		return
+	}
+	t.queue = placement.NewSerialQueue(slot, priority, receiverTapMaxQueued)
+}
+
+// handleTelephoneEvents receives the RFC 4733 telephone events of the track, which never reach onPacket.
+// Events are handled inline and the packet must not be retained. Has to be called before start.
+func (t *receiverTap) handleTelephoneEvents(onTelephoneEvent func(p *buffer.ExtPacket)) {
+	t.onTelephoneEvent = onTelephoneEvent
+}
+
+// accountMemory records the memory the feature holds for the tap, e. g. a decoder and its buffers,
+// in the DSP memory of the stage of the tap while it is started. Has to be called before start.
+func (t *receiverTap) accountMemory(goBytes int, nativeBytes int) {
+	t.goBytes, t.nativeBytes = goBytes, nativeBytes
+}
+
+// bufferJitter holds packets in a jitter buffer until their playout time, so that onPacket gets them in order
+// and evenly paced. Late packets are dropped. Has to be called before start, a disabled config keeps no buffer.
+func (t *receiverTap) bufferJitter(config audio.JitterBufferConfig, clockRate int) {
+	t.jitter = audio.NewJitterBuffer[*buffer.ExtPacket](config, clockRate)
+}
+
+// stage names the tap in the metrics
+func (t *receiverTap) stage() string {
+	return "tap_" + strings.ToLower(strings.TrimSuffix(t.prefix, "_"))
+}
+
+func (t *receiverTap) start() error {
+	if err := t.receiver.AddDownTrack(t); err != nil {
+		return err
+	}
+	if !t.started.Swap(true) {
+		liveReceiverTaps.Inc()
+		mem := memtrack.DefaultRegistry.StageScope(t.stage()).Track(string(t.trackID))
+		mem.Set(t.goBytes, t.nativeBytes)
+		t.mem.Store(mem)
+	}
+	return nil
+}
+
+func (t *receiverTap) stop() {
+	t.closed.Store(true)
+	t.receiver.DeleteDownTrack(t.SubscriberID())
+	if t.started.Swap(false) {
+		liveReceiverTaps.Dec()
+		t.mem.Swap(nil).Release()
+	}
+
+	if t.jitter != nil {
+		t.jitterLock.Lock()
+		if t.jitterTimer != nil {
+			t.jitterTimer.Stop()
+		}
+		t.jitter.Flush()
+		t.jitterLock.Unlock()
+	}
+}
+
+func (t *receiverTap) WriteRTP(p *buffer.ExtPacket, _ int32) error {
+	if t.closed.Load() || p.Packet == nil || len(p.Packet.Payload) == 0 {
+		return nil
+	}
+
+	if t.jitter != nil {
+		t.pushJitter(clonePacket(p))
+		return nil
+	}
+	if t.queue == nil {
+		t.onPacket(p)
+		return nil
+	}
+	t.submit(clonePacket(p))
+	return nil
+}
+
+// clonePacket copies a packet to hold on to it, the payload buffer is reused once forwarding returns
+func clonePacket(p *buffer.ExtPacket) *buffer.ExtPacket {
+	ep := *p
+	pkt := *p.Packet
+	pkt.Payload = slices.Clone(p.Packet.Payload)
+	ep.Packet = &pkt
+	return &ep
+}
+
+func (t *receiverTap) submit(p *buffer.ExtPacket) {
+	t.queue.Submit(func() {
+		if !t.closed.Load() {
+			t.onPacket(p)
+		}
+	})
+}
+
+func (t *receiverTap) pushJitter(p *buffer.ExtPacket) {
+	t.jitterLock.Lock()
+	defer t.jitterLock.Unlock()
+
+	// taps are written as packets are forwarded, right after they arrived
+	t.jitter.Push(p.Packet.SequenceNumber, p.Packet.Timestamp, time.Now(), p)
+	t.releaseJitterLocked()
+}
+
+// releaseJitterLocked hands the due packets of the jitter buffer on and sets the timer for the next one.
+// Must be called with the jitter lock held.
+func (t *receiverTap) releaseJitterLocked() {
+	if t.closed.Load() {
+		return
+	}
+
+	for {
+		p, ok := t.jitter.Pop(time.Now())
+		if !ok {
+			break
+		}
+		if t.queue == nil {
+			t.onPacket(p)
+		} else {
+			t.submit(p)
+		}
+	}
+
+	stats := t.jitter.Stats()
+	prometheus.RecordJitterBuffer(t.stage(), stats.Reordered-t.jitterStats.Reordered, stats.Late-t.jitterStats.Late, stats.Dropped-t.jitterStats.Dropped)
+	t.jitterStats = stats
+
+	deadline, ok := t.jitter.NextDeadline()
+	if !ok {
+		return
+	}
+	if t.jitterTimer == nil {
+		t.jitterTimer = time.AfterFunc(time.Until(deadline), func() {
+			t.jitterLock.Lock()
+			defer t.jitterLock.Unlock()
+			t.releaseJitterLocked()
+		})
+	} else {
+		t.jitterTimer.Reset(time.Until(deadline))
+	}
+}
+
+func (t *receiverTap) WriteTelephoneEvent(p *buffer.ExtPacket) {
+	if t.closed.Load() || t.onTelephoneEvent == nil || p.Packet == nil {
+		return
+	}
+
+	t.onTelephoneEvent(p)
+}
+
+func (t *receiverTap) ID() string {
+	return t.prefix + string(t.trackID)
+}
+
+func (t *receiverTap) SubscriberID() livekit.ParticipantID {
+	return livekit.ParticipantID(t.prefix + string(t.trackID))
+}
+
+// Close is called by the receiver when the track ends, the feature is expected to stop the tap shortly
+func (t *receiverTap) Close() {
+	t.closed.Store(true)
+	t.mem.Load().EndStream()
+}
+
+func (t *receiverTap) IsClosed() bool {
+	return t.closed.Load()
+}
+
+func (t *receiverTap) UpTrackLayersChange()                           {}
+func (t *receiverTap) UpTrackBitrateAvailabilityChange()              {}
+func (t *receiverTap) UpTrackMaxPublishedLayerChange(_ int32)         {}
+func (t *receiverTap) UpTrackMaxTemporalLayerSeenChange(_ int32)      {}
+func (t *receiverTap) UpTrackBitrateReport(_ []int32, _ sfu.Bitrates) {}
+func (t *receiverTap) Resync()                                        {}
+func (t *receiverTap) SetReceiver(_ sfu.TrackReceiver)                {}
+func (t *receiverTap) HandleRTCPSenderReportData(
+	_ webrtc.PayloadType,
+	_ int32,
+	_ *livekit.RTCPSenderReportState,
+) error {
+	return nil
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/webrtc/v4"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu"
+)
+
+const receiveStagesRTCPQueueSize = 64
+
+// ReceiveStages process the media a participant publishes, e. g. the noise filter. The buffers of its tracks
+// run every packet through them before buffering it for forwarding, the RTCP the participant sends on its
+// tracks is read through them as well. Stages are interceptors, in the order they were added.
+type ReceiveStages struct {
+	logger logger.Logger
+
+	lock      sync.Mutex
+	factories []interceptor.Factory
+	chain     *interceptor.Chain
+	rtcp      chan []byte
+	closed    bool
+}
+
+func NewReceiveStages(logger logger.Logger) *ReceiveStages {
+	return &ReceiveStages{
+		logger: logger,
+	}
+}
+
+// Add appends a stage, stages have to be added before the first stream is bound
+func (s *ReceiveStages) Add(factory interceptor.Factory) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.factories = append(s.factories, factory)
+}
+
+// IsEmpty returns true if there is no stage to run, packets are then buffered as received
+func (s *ReceiveStages) IsEmpty() bool {
+	if s == nil {
+		return true
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return len(s.factories) == 0
+}
+
+// chainLocked creates the interceptors of the stages with the first stream. Must be called with the lock held.
+func (s *ReceiveStages) chainLocked() *interceptor.Chain {
+	if s.closed {
+		return nil
+	}
+	if s.chain != nil {
+		return s.chain
+	}
+
+	interceptors := make([]interceptor.Interceptor, 0, len(s.factories))
+	for _, factory := range s.factories {
+		i, err := factory.NewInterceptor("")
+		if err != nil {
+			s.logger.Warnw("could not create receive stage", err)
+			continue
+		}
+		interceptors = append(interceptors, i)
+	}
+	s.chain = interceptor.NewChain(interceptors)
+
+	s.rtcp = make(chan []byte, receiveStagesRTCPQueueSize)
+	go s.readRTCP(s.chain.BindRTCPReader(&receiveStagesRTCPFeed{packets: s.rtcp}))
+	return s.chain
+}
+
+// BindRemoteStream implements buffer.Stages
+func (s *ReceiveStages) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	s.lock.Lock()
+	chain := s.chainLocked()
+	s.lock.Unlock()
+
+	if chain == nil {
+		return reader
+	}
+	return chain.BindRemoteStream(info, reader)
+}
+
+// UnbindRemoteStream implements buffer.Stages
+func (s *ReceiveStages) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	s.lock.Lock()
+	chain := s.chain
+	s.lock.Unlock()
+
+	if chain != nil {
+		chain.UnbindRemoteStream(info)
+	}
+}
+
+// WriteRTCP has the stages read an RTCP compound packet the participant sent, it is dropped if they fell behind
+func (s *ReceiveStages) WriteRTCP(packet []byte) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.factories) == 0 || s.chainLocked() == nil {
+		return
+	}
+	select {
+	case s.rtcp <- append([]byte(nil), packet...):
+	default:
+		s.logger.Debugw("receive stages behind, dropping RTCP")
+	}
+}
+
+func (s *ReceiveStages) readRTCP(reader interceptor.RTCPReader) {
+	buf := make([]byte, 1500)
+	for {
+		if _, _, err := reader.Read(buf, nil); errors.Is(err, io.EOF) {
+			return
+		}
+	}
+}
+
+func (s *ReceiveStages) Close() {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	if s.chain != nil {
+		close(s.rtcp)
+		if err := s.chain.Close(); err != nil {
+			s.logger.Warnw("could not close receive stages", err)
+		}
+	}
+}
+
+// receiveStreamInfo describes a published stream to the stages, as the publisher peer connection
+// describes it to its interceptors
+func receiveStreamInfo(track sfu.TrackRemote, parameters webrtc.RTPParameters) *interceptor.StreamInfo {
+	codec := track.Codec()
+	info := &interceptor.StreamInfo{
+		ID:           track.ID(),
+		Attributes:   interceptor.Attributes{},
+		SSRC:         uint32(track.SSRC()),
+		PayloadType:  uint8(codec.PayloadType),
+		MimeType:     codec.MimeType,
+		ClockRate:    codec.ClockRate,
+		Channels:     codec.Channels,
+		SDPFmtpLine:  codec.SDPFmtpLine,
+		RTCPFeedback: make([]interceptor.RTCPFeedback, 0, len(codec.RTCPFeedback)),
+	}
+	for _, ext := range parameters.HeaderExtensions {
+		info.RTPHeaderExtensions = append(info.RTPHeaderExtensions, interceptor.RTPHeaderExtension{URI: ext.URI, ID: ext.ID})
+	}
+	for _, fb := range codec.RTCPFeedback {
+		info.RTCPFeedback = append(info.RTCPFeedback, interceptor.RTCPFeedback{Type: fb.Type, Parameter: fb.Parameter})
+	}
+	return info
+}
+
+// --------------------------------------
+
+type receiveStagesRTCPFeed struct {
+	packets chan []byte
+}
+
+func (f *receiveStagesRTCPFeed) Read(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+	packet, ok := <-f.packets
+	if !ok {
+		return 0, a, io.EOF
+	}
+	if len(b) < len(packet) {
+		return 0, a, io.ErrShortBuffer
+	}
+	return copy(b, packet), a, nil
+}
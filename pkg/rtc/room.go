@@ -16,6 +16,7 @@ package rtc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"math"
 	"slices"
@@ -34,15 +35,27 @@ import (
 	"github.com/livekit/protocol/rpc"
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/guid"
+	"github.com/livekit/protocol/webhook"
 	"github.com/livekit/psrpc"
 
 	"github.com/livekit/livekit-server/pkg/agent"
+	"github.com/livekit/livekit-server/pkg/audioinject"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/ingest"
+	"github.com/livekit/livekit-server/pkg/metadata"
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/realtime"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc/reaper"
+	"github.com/livekit/livekit-server/pkg/rtc/talkstats"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/connectionquality"
+	"github.com/livekit/livekit-server/pkg/sip"
+	"github.com/livekit/livekit-server/pkg/supportbundle"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	sutils "github.com/livekit/livekit-server/pkg/utils"
@@ -105,14 +118,40 @@ type Room struct {
 	protoProxy *utils.ProtoProxy[*livekit.Room]
 	logger     logger.Logger
 
-	config          WebRTCConfig
-	roomConfig      config.RoomConfig
-	audioConfig     *sfu.AudioConfig
-	serverInfo      *livekit.ServerInfo
-	telemetry       telemetry.TelemetryService
-	egressLauncher  EgressLauncher
-	trackManager    *RoomTrackManager
-	agentDispatches map[string]*agentDispatch
+	config           WebRTCConfig
+	roomConfig       config.RoomConfig
+	audioConfig      *sfu.AudioConfig
+	serverInfo       *livekit.ServerInfo
+	telemetry        telemetry.TelemetryService
+	egressLauncher   EgressLauncher
+	trackManager     *RoomTrackManager
+	agentDispatches  map[string]*agentDispatch
+	audioMixer       *AudioMixer
+	micQuality       *MicQualityMonitor
+	wakeWord         *WakeWordMonitor
+	mlExporter       *MLExporter
+	trackWatchdog    *TrackWatchdog
+	dataModerator    *DataModerator
+	logRing          *supportbundle.LogRing
+	trackMirrors     *TrackMirrors
+	audioInjections  *AudioInjections
+	realtimeBridges  *RealtimeBridges
+	sipCalls         *SIPCalls
+	ingests          *Ingests
+	transcriptions   *Transcriptions
+	captions         *Captions
+	idleReaper       *IdleReaper
+	dtmfRouter       *DTMFRouter
+	callFlows        *CallFlowRunner
+	b2bua            *B2BUA
+	sttGate          *STTGateController
+	echoCancellation *EchoCancellation
+	ducking          *DuckingController
+	bargeIn          *BargeInController
+	pcmTaps          *PCMTaps
+	talkAnalytics    *TalkAnalytics
+	processingBypass *ProcessingBypass
+	audioSnapshots   *AudioSnapshots
 
 	// agents
 	agentClient agent.Client
@@ -123,6 +162,7 @@ type Room struct {
 	participantOpts           map[livekit.ParticipantIdentity]*ParticipantOptions
 	participantRequestSources map[livekit.ParticipantIdentity]routing.MessageSource
 	hasPublished              map[livekit.ParticipantIdentity]bool
+	consents                  map[livekit.ParticipantIdentity]ConsentFlags
 	agentParticpants          map[livekit.ParticipantIdentity]*agentJob
 	bufferFactory             *buffer.FactoryOfBufferFactory
 
@@ -145,6 +185,8 @@ type Room struct {
 	userPacketDeduper *UserPacketDeduper
 
 	dataMessageCache *utils.TimeSizeCache[types.DataMessageCache]
+
+	placement *placement.Slot
 }
 
 type ParticipantOptions struct {
@@ -267,6 +309,7 @@ func NewRoom(
 		participantOpts:                      make(map[livekit.ParticipantIdentity]*ParticipantOptions),
 		participantRequestSources:            make(map[livekit.ParticipantIdentity]routing.MessageSource),
 		hasPublished:                         make(map[livekit.ParticipantIdentity]bool),
+		consents:                             make(map[livekit.ParticipantIdentity]ConsentFlags),
 		agentParticpants:                     make(map[livekit.ParticipantIdentity]*agentJob),
 		bufferFactory:                        buffer.NewFactoryOfBufferFactory(config.Receiver.PacketBufferSizeVideo, config.Receiver.PacketBufferSizeAudio),
 		batchedUpdates:                       make(map[livekit.ParticipantIdentity]*ParticipantUpdate),
@@ -280,6 +323,10 @@ func NewRoom(
 			MaxSize: dataMessageCacheSize,
 		}),
 	}
+	if roomConfig.SupportBundle.Enabled {
+		r.logRing = supportbundle.NewLogRing(roomConfig.SupportBundle)
+		r.logger = newCaptureLogger(r.logger, r.logRing, nil)
+	}
 
 	if r.protoRoom.EmptyTimeout == 0 {
 		r.protoRoom.EmptyTimeout = roomConfig.EmptyTimeout
@@ -294,6 +341,239 @@ func NewRoom(
 	}
 	r.protoProxy = utils.NewProtoProxy(roomUpdateInterval, r.updateProto)
 
+	// created ahead of the mixer, which hands it the mixed audio
+	if audioConfig != nil && audioConfig.Snapshots.Enabled {
+		r.audioSnapshots = NewAudioSnapshots(AudioSnapshotsParams{
+			Config:          audioConfig.Snapshots,
+			Logger:          r.logger,
+			GetParticipants: r.GetParticipants,
+			HasConsent:      r.HasConsent,
+		})
+	}
+	if audioConfig != nil && audioConfig.Mixing.Enabled {
+		if audio.IsOpusCodecAvailable() {
+			audioMixer, err := NewAudioMixer(AudioMixerParams{
+				Config:        audioConfig.Mixing,
+				Framing:       audioConfig.Framing,
+				Logger:        r.logger,
+				Placement:     r.Placement,
+				TrackPriority: r.trackPriority,
+				NoiseFilter:   audioConfig.EgressNoiseFilter,
+				IsDenoised:    r.isTrackDenoised,
+				OnMixedAudio:  r.audioSnapshots.AddMixedAudio,
+				PCMTap:        config.PCMTap,
+				HasConsent:    r.HasConsent,
+				Concealment:   audioConfig.Concealment,
+				JitterBuffer:  audioConfig.JitterBuffer,
+				OnActivity:    r.onMixActivity,
+			})
+			if err != nil {
+				r.logger.Warnw("audio mixing disabled", err)
+			} else {
+				r.audioMixer = audioMixer
+			}
+		} else {
+			r.logger.Warnw("audio mixing disabled", audio.ErrOpusCodecUnavailable)
+		}
+	}
+	if audioConfig != nil && audioConfig.MicQuality.Enabled {
+		r.micQuality = NewMicQualityMonitor(MicQualityMonitorParams{
+			Config:        audioConfig.MicQuality,
+			Logger:        r.logger,
+			OnReport:      r.onMicQualityReport,
+			Placement:     r.Placement,
+			TrackPriority: r.trackPriority,
+		})
+	}
+	if audioConfig != nil && audioConfig.WakeWord.Enabled {
+		if audio.IsOpusCodecAvailable() {
+			r.wakeWord = NewWakeWordMonitor(WakeWordMonitorParams{
+				Config:        audioConfig.WakeWord,
+				Logger:        r.logger,
+				OnDetection:   r.onWakeWordDetected,
+				Placement:     r.Placement,
+				TrackPriority: r.trackPriority,
+			})
+		} else {
+			r.logger.Warnw("wake word detection disabled", audio.ErrOpusCodecUnavailable)
+		}
+	}
+	if audioConfig != nil && audioConfig.TelephoneEvents.Enabled {
+		r.dtmfRouter = NewDTMFRouter(DTMFRouterParams{
+			Config:        audioConfig.TelephoneEvents,
+			Logger:        r.logger,
+			OnEvent:       r.onDTMFEvent,
+			Placement:     r.Placement,
+			TrackPriority: r.trackPriority,
+		})
+	}
+	if config.PCMTap.Enabled {
+		var concealment audio.ConcealmentConfig
+		var jitterBuffer audio.JitterBufferConfig
+		if audioConfig != nil {
+			concealment = audioConfig.Concealment
+			jitterBuffer = audioConfig.JitterBuffer
+		}
+		r.pcmTaps = NewPCMTaps(PCMTapsParams{
+			Config:        config.PCMTap,
+			Logger:        r.logger,
+			Concealment:   concealment,
+			JitterBuffer:  jitterBuffer,
+			Placement:     r.Placement,
+			TrackPriority: r.trackPriority,
+			HasConsent:    r.HasConsent,
+		})
+	}
+	if roomConfig.TalkAnalytics.Enabled {
+		r.talkAnalytics = NewTalkAnalytics(TalkAnalyticsParams{
+			Config:     roomConfig.TalkAnalytics,
+			OnReport:   r.onTalkAnalyticsReport,
+			HasConsent: r.HasConsent,
+		})
+	}
+	r.processingBypass = NewProcessingBypass(ProcessingBypassParams{
+		Config:   roomConfig.ProcessingBypass,
+		Logger:   r.logger,
+		OnChange: r.onProcessingBypassChanged,
+	})
+	if audioConfig != nil && audioConfig.STTGate.Enabled {
+		r.sttGate = NewSTTGateController(STTGateControllerParams{
+			Config:          audioConfig.STTGate,
+			GetParticipants: r.GetParticipants,
+			OnEvent:         r.onSTTGateEvent,
+		})
+	}
+	// attenuated by the noise filter of the publishers
+	if audioConfig != nil && audioConfig.NoiseFilter.Enabled && audioConfig.Ducking.Enabled {
+		r.ducking = NewDuckingController(DuckingControllerParams{
+			Config: audioConfig.Ducking,
+		})
+	}
+	// follows the speaking transitions of voice activity detection
+	if audioConfig != nil && audioConfig.VAD.Enabled && audioConfig.BargeIn.Enabled {
+		r.bargeIn = NewBargeInController(BargeInControllerParams{
+			Config:    audioConfig.BargeIn,
+			OnBargeIn: r.onBargeIn,
+		})
+	}
+	// cancelled by the noise filter of the publishers
+	if audioConfig != nil && audioConfig.NoiseFilter.Enabled && audioConfig.NoiseFilter.EchoCancellation.Enabled {
+		if audio.IsOpusCodecAvailable() {
+			r.echoCancellation = NewEchoCancellation(EchoCancellationParams{
+				Config:        audioConfig.NoiseFilter.EchoCancellation,
+				Logger:        r.logger,
+				Placement:     r.Placement,
+				TrackPriority: r.trackPriority,
+			})
+		} else {
+			r.logger.Warnw("echo cancellation disabled", audio.ErrOpusCodecUnavailable)
+		}
+	}
+	if roomConfig.TrackWatchdog.Enabled {
+		r.trackWatchdog = NewTrackWatchdog(TrackWatchdogParams{
+			Config:  roomConfig.TrackWatchdog,
+			Logger:  r.logger,
+			OnEvent: r.onTrackHealthEvent,
+		})
+	}
+	if roomConfig.DataModeration.Enabled {
+		r.dataModerator = NewDataModerator(DataModeratorParams{
+			Config:      roomConfig.DataModeration,
+			Logger:      r.logger,
+			OnViolation: r.onDataModerationViolation,
+		})
+		r.dataModerator.SyncRoomMetadata(room.Metadata)
+	}
+	if roomConfig.IdleReaper.Enabled {
+		r.idleReaper = NewIdleReaper(IdleReaperParams{
+			Config:          roomConfig.IdleReaper,
+			Logger:          r.logger,
+			GetParticipants: r.GetParticipants,
+			OnEvent:         r.onIdleEvent,
+		})
+	}
+	if roomConfig.TrackMirror.Enabled && roomConfig.TrackMirror.RoomPrefix != "" && strings.HasPrefix(room.Name, roomConfig.TrackMirror.RoomPrefix) {
+		r.trackMirrors = NewTrackMirrors(TrackMirrorsParams{
+			Logger: r.logger,
+		})
+	}
+	if config.AudioInject.Enabled {
+		injectionParams := AudioInjectionsParams{
+			Config:  config.AudioInject,
+			Logger:  r.logger,
+			OnEvent: r.onAudioInjectionEvent,
+		}
+		if audioConfig != nil {
+			injectionParams.NoiseFilter = audioConfig.EgressNoiseFilter
+		}
+		r.audioInjections = NewAudioInjections(injectionParams)
+	}
+	if config.Realtime.Enabled {
+		r.realtimeBridges = NewRealtimeBridges(RealtimeBridgesParams{
+			Config:       config.Realtime,
+			Logger:       r.logger,
+			SubscribePCM: r.SubscribePCM,
+			OnEvent:      r.onRealtimeBridgeEvent,
+		})
+	}
+	if config.SIPGateway.Enabled {
+		r.sipCalls = NewSIPCalls(SIPCallsParams{
+			Logger:       r.logger,
+			SubscribePCM: r.SubscribePCM,
+			OnEvent:      r.onSIPCallEvent,
+			OnDTMF:       r.onDTMFEvent,
+		})
+	}
+	if config.RTMPIngest.Enabled || config.SRTIngest.Enabled {
+		r.ingests = NewIngests(IngestsParams{
+			Logger:  r.logger,
+			OnEvent: r.onIngestEvent,
+		})
+	}
+	if roomConfig.Transcription.Enabled {
+		r.transcriptions = NewTranscriptions(TranscriptionsParams{
+			Config:       roomConfig.Transcription,
+			Logger:       r.logger,
+			SubscribePCM: r.SubscribePCM,
+			OnResult:     r.onTranscriptionResult,
+		}, room.Metadata)
+	}
+	if roomConfig.Captions.Enabled {
+		r.captions = NewCaptions(CaptionsParams{
+			Config:          roomConfig.Captions,
+			Logger:          r.logger,
+			GetParticipants: r.GetParticipants,
+			Send: func(dp *livekit.DataPacket) {
+				r.SendDataPacket(dp, livekit.DataPacket_RELIABLE)
+			},
+		})
+	}
+	if IsB2BUARoom(roomConfig.B2BUA, livekit.RoomName(room.Name)) {
+		r.b2bua = NewB2BUA(B2BUAParams{
+			Config:    roomConfig.B2BUA,
+			RoomName:  livekit.RoomName(room.Name),
+			RoomID:    livekit.RoomID(room.Sid),
+			Logger:    r.logger,
+			OnFailure: r.onB2BUAFailure,
+		})
+	}
+	if roomConfig.MLExport.Enabled {
+		if !audio.IsOpusCodecAvailable() {
+			r.logger.Warnw("ml export disabled", audio.ErrOpusCodecUnavailable)
+		} else if exporter, err := NewMLExporter(MLExporterParams{
+			Config:     roomConfig.MLExport,
+			RoomName:   livekit.RoomName(room.Name),
+			RoomID:     livekit.RoomID(room.Sid),
+			Logger:     r.logger,
+			Placement:  r.Placement,
+			HasConsent: r.HasConsent,
+		}); err != nil {
+			r.logger.Errorw("could not start ml export", err)
+		} else {
+			r.mlExporter = exporter
+		}
+	}
+
 	r.createAgentDispatchesFromRoomAgent()
 
 	r.launchRoomAgents(maps.Values(r.agentDispatches))
@@ -306,6 +586,73 @@ func NewRoom(
 	return r
 }
 
+// SetPlacement records the NUMA node the room's media work is scheduled on
+func (r *Room) SetPlacement(slot *placement.Slot) {
+	if slot == nil {
+		return
+	}
+
+	r.lock.Lock()
+	r.placement = slot
+	r.lock.Unlock()
+
+	r.logger.Infow("room placed", "numaNode", slot.NodeID())
+}
+
+// SetCallFlows runs the call flows of params for the participants of the room that select one,
+// the room provides the actions. Must be called before participants join.
+func (r *Room) SetCallFlows(params CallFlowRunnerParams) {
+	if len(params.Flows) == 0 {
+		return
+	}
+
+	params.RoomName = r.Name()
+	params.Logger = r.logger
+	params.SendData = func(dp *livekit.DataPacket) {
+		r.SendDataPacket(dp, livekit.DataPacket_RELIABLE)
+	}
+	params.Dispatch = func(agentName string, metadata string) error {
+		_, err := r.AddAgentDispatch(&livekit.AgentDispatch{
+			Id:        guid.New(guid.AgentDispatchPrefix),
+			AgentName: agentName,
+			Metadata:  metadata,
+			Room:      string(r.Name()),
+		})
+		return err
+	}
+	params.Hangup = func(identity livekit.ParticipantIdentity) {
+		r.RemoveParticipant(identity, "", types.ParticipantCloseReasonServiceRequestRemoveParticipant)
+	}
+	r.callFlows = NewCallFlowRunner(params)
+}
+
+// Placement returns the NUMA slot of the room, nil when topology aware placement is disabled
+func (r *Room) Placement() *placement.Slot {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return r.placement
+}
+
+// trackPriority returns the processing priority of a published track, as labeled by its publisher
+func (r *Room) trackPriority(track types.MediaTrack) placement.Priority {
+	publisher := r.GetParticipant(track.PublisherIdentity())
+	if publisher == nil {
+		return placement.PriorityInteractive
+	}
+	return TrackPriority(publisher, track)
+}
+
+// isTrackDenoised returns true if the track is forwarded denoised by the noise filter of its publisher
+func (r *Room) isTrackDenoised(track types.MediaTrack) bool {
+	publisher, ok := r.GetParticipant(track.PublisherIdentity()).(interface{ HasNoiseFilter() bool })
+	if !ok || !publisher.HasNoiseFilter() {
+		return false
+	}
+	nf, ok := track.(interface{ IsNoiseFilterEnabled() bool })
+	return ok && nf.IsNoiseFilterEnabled()
+}
+
 func (r *Room) Logger() logger.Logger {
 	return r.logger
 }
@@ -434,6 +781,13 @@ func (r *Room) Join(
 	opts *ParticipantOptions,
 	iceServers []*livekit.ICEServer,
 ) error {
+	if !participant.IsDependent() {
+		// legs of a B2BUA call are admitted only once the call is recorded
+		if err := r.b2bua.WaitReady(); err != nil {
+			return err
+		}
+	}
+
 	r.lock.Lock()
 	defer r.lock.Unlock()
 
@@ -455,6 +809,9 @@ func (r *Room) Join(
 			return ErrMaxParticipantsExceeded
 		}
 	}
+	if err := r.b2bua.AddLeg(participant); err != nil {
+		return err
+	}
 
 	if r.FirstJoinedAt() == 0 && !participant.IsDependent() {
 		r.joinedAt.Store(time.Now().Unix())
@@ -560,8 +917,19 @@ func (r *Room) Join(
 	participant.OnSyncState(r.onSyncState)
 	participant.OnSimulateScenario(r.onSimulateScenario)
 	participant.OnLeave(r.onLeave)
+	if sp, ok := participant.(interface {
+		OnSpeakingChange(func(types.LocalParticipant, livekit.TrackID, *audio.SpeakingTransition))
+	}); ok {
+		sp.OnSpeakingChange(r.onSpeakingChange)
+	}
+	if up, ok := participant.(interface {
+		OnUtterance(func(types.LocalParticipant, livekit.TrackID, *audio.UtteranceEvent))
+	}); ok {
+		up.OnUtterance(r.onUtterance)
+	}
 
 	r.launchTargetAgents(maps.Values(r.agentDispatches), participant, livekit.JobType_JT_PARTICIPANT)
+	r.callFlows.Start(participant, r.protoRoom.Metadata)
 
 	r.logger.Debugw(
 		"new participant joined",
@@ -580,6 +948,9 @@ func (r *Room) Join(
 	}
 
 	r.participants[participant.Identity()] = participant
+	if r.processingBypass.IsActive() {
+		setProcessingBypass(participant, true)
+	}
 	r.participantOpts[participant.Identity()] = opts
 	r.participantRequestSources[participant.Identity()] = requestSource
 
@@ -794,6 +1165,10 @@ func (r *Room) ResolveMediaTrackForSubscriber(sub types.LocalParticipant, trackI
 	// when publisher is not found, we will assume it doesn't have permission to access
 	if pub != nil {
 		res.HasPermission = IsParticipantExemptFromTrackPermissionsRestrictions(sub) || pub.HasPermission(trackID, sub.Identity())
+		// recorders do not get the tracks of publishers that did not consent to recording
+		if res.HasPermission && sub.IsRecorder() && !ParticipantConsent(pub, r.roomConfig.Consent).Has(ConsentRecording) {
+			res.HasPermission = false
+		}
 	}
 
 	return res
@@ -863,6 +1238,28 @@ func (r *Room) Close(reason types.ParticipantCloseReason) {
 	}
 
 	r.protoProxy.Stop()
+	r.audioMixer.Stop()
+	r.micQuality.Stop()
+	r.wakeWord.Stop()
+	r.mlExporter.Stop()
+	r.trackWatchdog.Stop()
+	r.dtmfRouter.Stop()
+	r.pcmTaps.Stop()
+	r.callFlows.Stop()
+	r.b2bua.Stop()
+	r.sttGate.Stop()
+	r.echoCancellation.Stop()
+	r.ducking.Stop()
+	r.processingBypass.Stop()
+	r.talkAnalytics.Stop()
+	r.audioSnapshots.Stop()
+	r.trackMirrors.Close()
+	r.audioInjections.Close()
+	r.realtimeBridges.Close()
+	r.sipCalls.Close()
+	r.ingests.Close()
+	r.transcriptions.Stop()
+	r.idleReaper.Stop()
 
 	if r.onClose != nil {
 		r.onClose()
@@ -885,9 +1282,31 @@ func (r *Room) SetMetadata(metadata string) <-chan struct{} {
 	r.lock.Lock()
 	r.protoRoom.Metadata = metadata
 	r.lock.Unlock()
+	r.dataModerator.SyncRoomMetadata(metadata)
+	r.transcriptions.SyncRoomMetadata(metadata)
 	return r.protoProxy.MarkDirty(true)
 }
 
+// UpdateMetadata applies a metadata update, merged per key when enabled in the room config.
+// A merged result larger than maxSize bytes is rejected with metadata.ErrTooLarge, 0 does not limit it.
+func (r *Room) UpdateMetadata(update string, maxSize uint32) (<-chan struct{}, error) {
+	if !r.roomConfig.Metadata.Merge {
+		return r.SetMetadata(update), nil
+	}
+
+	r.lock.Lock()
+	merged, err := metadata.Merge(r.protoRoom.Metadata, update, maxSize)
+	if err != nil {
+		r.lock.Unlock()
+		return nil, err
+	}
+	r.protoRoom.Metadata = merged
+	r.lock.Unlock()
+	r.dataModerator.SyncRoomMetadata(merged)
+	r.transcriptions.SyncRoomMetadata(merged)
+	return r.protoProxy.MarkDirty(true), nil
+}
+
 func (r *Room) sendRoomUpdate() {
 	roomInfo := r.ToProto()
 	// Send update to participants
@@ -1101,6 +1520,13 @@ func (r *Room) createJoinResponseLocked(
 
 // a ParticipantImpl in the room added a new track, subscribe other participants to it
 func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.MediaTrack) {
+	// nothing is forwarded in a B2BUA call that is not recorded
+	if err := r.b2bua.AddTrack(participant, track); err != nil {
+		participant.GetLogger().Errorw("could not record track of B2BUA call", err, "trackID", track.ID())
+		r.onB2BUAFailure(err)
+		return
+	}
+
 	// publish participant update, since track state is changed
 	r.broadcastParticipantState(participant, broadcastOptions{skipSource: true})
 
@@ -1118,6 +1544,10 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 		if !r.autoSubscribe(existingParticipant) {
 			continue
 		}
+		if track.Kind() == livekit.TrackType_AUDIO && r.audioMixer.IsListener(existingParticipant.ID()) {
+			// receives the track as part of the mixed audio
+			continue
+		}
 
 		existingParticipant.GetLogger().Debugw(
 			"subscribing to new track",
@@ -1135,6 +1565,26 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 	}
 
 	r.trackManager.AddTrack(track, participant.Identity(), participant.ID())
+	r.audioMixer.AddTrack(participant, track)
+	r.micQuality.AddTrack(track)
+	r.wakeWord.AddTrack(track)
+	r.mlExporter.SyncConsent(participant)
+	r.mlExporter.AddTrack(track)
+	r.trackWatchdog.AddTrack(participant, track)
+	r.dtmfRouter.AddTrack(participant, track)
+	r.echoCancellation.AddTrack(participant, track)
+	r.ducking.AddTrack(participant, track)
+	r.syncConsent(participant)
+	if r.HasConsent(participant.Identity(), ConsentTranscription) {
+		r.sttGate.AddTrack(participant, track)
+		r.transcriptions.AddTrack(participant, track)
+	}
+	if t, ok := track.(interface{ AddOnGoodbye(func()) }); ok {
+		// the publisher ended the stream, release processing right away rather than when the track is unpublished
+		t.AddOnGoodbye(func() {
+			r.removeTrackProcessing(track.ID())
+		})
+	}
 
 	// launch jobs
 	r.lock.Lock()
@@ -1146,7 +1596,7 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 		r.lock.RLock()
 		r.launchTargetAgents(maps.Values(r.agentDispatches), participant, livekit.JobType_JT_PUBLISHER)
 		r.lock.RUnlock()
-		if r.internal != nil && r.internal.ParticipantEgress != nil {
+		if r.internal != nil && r.internal.ParticipantEgress != nil && r.hasEgressConsent(participant) {
 			go func() {
 				if err := StartParticipantEgress(
 					context.Background(),
@@ -1162,7 +1612,7 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 			}()
 		}
 	}
-	if participant.Kind() != livekit.ParticipantInfo_EGRESS && r.internal != nil && r.internal.TrackEgress != nil {
+	if participant.Kind() != livekit.ParticipantInfo_EGRESS && r.internal != nil && r.internal.TrackEgress != nil && r.hasEgressConsent(participant) {
 		go func() {
 			if err := StartTrackEgress(
 				context.Background(),
@@ -1179,6 +1629,23 @@ func (r *Room) onTrackPublished(participant types.LocalParticipant, track types.
 	}
 }
 
+// removeTrackProcessing detaches every processing stage from the track, safe to call more than once
+func (r *Room) removeTrackProcessing(trackID livekit.TrackID) {
+	r.audioMixer.RemoveTrack(trackID)
+	r.micQuality.RemoveTrack(trackID)
+	r.wakeWord.RemoveTrack(trackID)
+	r.mlExporter.RemoveTrack(trackID)
+	r.b2bua.RemoveTrack(trackID)
+	r.trackWatchdog.RemoveTrack(trackID)
+	r.dtmfRouter.RemoveTrack(trackID)
+	r.sttGate.RemoveTrack(trackID)
+	r.transcriptions.RemoveTrack(trackID)
+	r.echoCancellation.RemoveTrack(trackID)
+	r.ducking.RemoveTrack(trackID)
+	r.bargeIn.RemoveTrack(trackID)
+	r.pcmTaps.RemoveTrack(trackID)
+}
+
 func (r *Room) onTrackUpdated(p types.LocalParticipant, _ types.MediaTrack) {
 	// send track updates to everyone, especially if track was updated by admin
 	r.broadcastParticipantState(p, broadcastOptions{})
@@ -1189,6 +1656,7 @@ func (r *Room) onTrackUpdated(p types.LocalParticipant, _ types.MediaTrack) {
 
 func (r *Room) onTrackUnpublished(p types.LocalParticipant, track types.MediaTrack) {
 	r.trackManager.RemoveTrack(track)
+	r.removeTrackProcessing(track.ID())
 	if !p.IsClosed() {
 		r.broadcastParticipantState(p, broadcastOptions{skipSource: true})
 	}
@@ -1204,9 +1672,635 @@ func (r *Room) onParticipantUpdate(p types.LocalParticipant) {
 	if r.onParticipantChanged != nil {
 		r.onParticipantChanged(p)
 	}
+
+	// attributes set at runtime switch mixed audio on or off and change its channels and gain
+	if r.audioMixer != nil && p.State() == livekit.ParticipantInfo_ACTIVE {
+		r.syncAudioMix(p)
+	}
+	r.audioMixer.UpdatePublisher(p)
+	r.syncConsent(p)
+	r.mlExporter.SyncConsent(p)
+}
+
+// Consent returns the consent flags of a participant, the default flags if it is not in the room
+func (r *Room) Consent(identity livekit.ParticipantIdentity) ConsentFlags {
+	if p := r.GetParticipant(identity); p != nil {
+		return ParticipantConsent(p, r.roomConfig.Consent)
+	}
+	return DefaultConsent(r.roomConfig.Consent)
+}
+
+// HasConsent returns true if the participant consented to all of flags,
+// every processing of a participant's media beyond forwarding has to check it first
+func (r *Room) HasConsent(identity livekit.ParticipantIdentity, flags ConsentFlags) bool {
+	return r.Consent(identity).Has(flags)
+}
+
+func (r *Room) hasEgressConsent(p types.LocalParticipant) bool {
+	if ParticipantConsent(p, r.roomConfig.Consent).Has(ConsentRecording) {
+		return true
+	}
+	p.GetLogger().Infow("not launching egress, no recording consent")
+	return false
+}
+
+// syncConsent applies a change of the participant's consent to the processing of its published tracks
+func (r *Room) syncConsent(p types.LocalParticipant) {
+	if !r.roomConfig.Consent.Enabled {
+		return
+	}
+
+	consent := ParticipantConsent(p, r.roomConfig.Consent)
+	r.lock.Lock()
+	prev, ok := r.consents[p.Identity()]
+	r.consents[p.Identity()] = consent
+	r.lock.Unlock()
+	if !ok || prev == consent {
+		return
+	}
+
+	p.GetLogger().Infow("consent changed", "consent", consent, "previous", prev)
+	if !consent.Has(ConsentAnalytics) {
+		r.talkAnalytics.ForgetParticipant(p.Identity())
+	}
+	for _, track := range p.GetPublishedTracks() {
+		if consent.Has(ConsentTranscription) {
+			r.sttGate.AddTrack(p, track)
+			r.transcriptions.AddTrack(p, track)
+		} else {
+			r.sttGate.RemoveTrack(track.ID())
+			r.transcriptions.RemoveTrack(track.ID())
+			r.pcmTaps.RemoveTrack(track.ID())
+			r.realtimeBridges.RemoveTrack(track.ID())
+		}
+		// resolves the subscriptions of recorders again
+		r.trackManager.NotifyTrackChanged(track.ID())
+	}
+}
+
+// syncAudioMix switches the participant between mixed audio and per publisher audio tracks
+// following the opt-in attribute
+func (r *Room) syncAudioMix(p types.LocalParticipant) {
+	wantsMix := r.audioMixer.WantsMix(p) && !r.processingBypass.IsActive()
+	if wantsMix == r.audioMixer.IsListener(p.ID()) {
+		if wantsMix {
+			if err := r.audioMixer.UpdateListener(p); err != nil {
+				p.GetLogger().Warnw("could not switch mixed audio", err)
+			}
+		}
+		return
+	}
+
+	if !wantsMix {
+		r.audioMixer.RemoveListener(p)
+
+		r.lock.RLock()
+		shouldSubscribe := r.autoSubscribe(p)
+		r.lock.RUnlock()
+		if !shouldSubscribe {
+			return
+		}
+		for _, op := range r.GetParticipants() {
+			if op.ID() == p.ID() {
+				continue
+			}
+			for _, track := range op.GetPublishedTracks() {
+				if track.Kind() == livekit.TrackType_AUDIO {
+					p.SubscribeToTrack(track.ID(), false)
+				}
+			}
+		}
+		return
+	}
+
+	if err := r.audioMixer.AddListener(p); err != nil {
+		p.GetLogger().Warnw("could not enable mixed audio", err)
+		return
+	}
+	for _, st := range p.GetSubscribedTracks() {
+		if st.MediaTrack().Kind() == livekit.TrackType_AUDIO {
+			p.UnsubscribeFromTrack(st.ID())
+		}
+	}
+}
+
+// EnableProcessingBypass reverts the room to pure forwarding for duration, see ProcessingBypass
+func (r *Room) EnableProcessingBypass(duration time.Duration, actor string, reason string) ProcessingBypassState {
+	return r.processingBypass.Enable(duration, actor, reason)
+}
+
+// DisableProcessingBypass re-enables processing, returns false if it was not bypassed
+func (r *Room) DisableProcessingBypass(actor string, reason string) bool {
+	return r.processingBypass.Disable(actor, reason)
+}
+
+func (r *Room) ProcessingBypassState() ProcessingBypassState {
+	return r.processingBypass.State()
+}
+
+func setProcessingBypass(p types.LocalParticipant, bypass bool) {
+	if b, ok := p.(interface{ SetProcessingBypass(bypass bool) }); ok {
+		b.SetProcessingBypass(bypass)
+	}
+}
+
+// onProcessingBypassChanged switches noise filtering and audio mixing of all participants
+// and lets everybody in the room know
+func (r *Room) onProcessingBypassChanged(active bool, event ProcessingBypassEvent) {
+	for _, p := range r.GetParticipants() {
+		setProcessingBypass(p, active)
+		if r.audioMixer != nil && p.State() == livekit.ParticipantInfo_ACTIVE {
+			r.syncAudioMix(p)
+		}
+	}
+
+	if active {
+		r.notifyWebhook(WebhookEventProcessingBypassEnabled, nil)
+	} else {
+		r.notifyWebhook(WebhookEventProcessingBypassDisabled, nil)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal processing bypass event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(ProcessingBypassTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onMicQualityReport lets the publisher, agents and room admins know about microphone issues of the publisher,
+// the diagnostics are analytics of the publisher and need its consent
+func (r *Room) onMicQualityReport(event *MicQualityEvent) {
+	if !r.HasConsent(event.ParticipantIdentity, ConsentAnalytics) {
+		return
+	}
+
+	r.logger.Infow(
+		"mic quality changed",
+		"participant", event.ParticipantIdentity,
+		"trackID", event.TrackID,
+		"issues", event.Issues,
+		"clippingRatio", event.ClippingRatio,
+		"narrowbandRatio", event.NarrowbandRatio,
+		"bitrate", event.Bitrate,
+	)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal mic quality event", err)
+		return
+	}
+
+	destIdentities := []string{string(event.ParticipantIdentity)}
+	for _, p := range r.GetParticipants() {
+		if p.Identity() != event.ParticipantIdentity && (p.IsAgent() || isRoomAdmin(p)) {
+			destIdentities = append(destIdentities, string(p.Identity()))
+		}
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                  livekit.DataPacket_RELIABLE,
+		DestinationIdentities: destIdentities,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(MicQualityTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onMixActivity tells the listeners of a mix who is speaking in it. Sent lossy, a lost report
+// is superseded by the next one a fraction of a second later.
+func (r *Room) onMixActivity(destinations []livekit.ParticipantIdentity, event *MixActivityEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal mix activity event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                  livekit.DataPacket_LOSSY,
+		DestinationIdentities: livekit.IDsAsStrings(destinations),
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(MixActivityTopic),
+			},
+		},
+	}, livekit.DataPacket_LOSSY)
+}
+
+// onWakeWordDetected lets clients and agents know, through data and a webhook, that a publisher said a wake word
+func (r *Room) onWakeWordDetected(event *WakeWordEvent) {
+	r.logger.Infow(
+		"wake word detected",
+		"participant", event.ParticipantIdentity,
+		"trackID", event.TrackID,
+		"phrase", event.Phrase,
+		"score", event.Score,
+	)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal wake word event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(WakeWordTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+
+	var participant *livekit.ParticipantInfo
+	var track *livekit.TrackInfo
+	if p := r.GetParticipant(event.ParticipantIdentity); p != nil {
+		participant = p.ToProto()
+		if t := p.GetPublishedTrack(event.TrackID); t != nil {
+			track = t.ToProto()
+		}
+	}
+	r.telemetry.WakeWordDetected(context.Background(), r.ToProto(), participant, track)
+}
+
+// onTrackHealthEvent lets clients and agents know about stuck tracks and recovery attempts
+func (r *Room) onTrackHealthEvent(event *TrackHealthEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal track health event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(TrackHealthTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onDTMFEvent delivers in-band DTMF as data, on its own topic with start and end of each digit,
+// and as SIP DTMF once a digit has ended, the way IVR logic listening for SIP participants receives digits
+func (r *Room) onDTMFEvent(event *DTMFEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal dtmf event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: string(event.ParticipantIdentity),
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				ParticipantIdentity: string(event.ParticipantIdentity),
+				Payload:             payload,
+				Topic:               proto.String(DTMFTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+
+	if event.Phase != audio.TelephoneEventPhaseEnd || event.Digit == "" {
+		return
+	}
+	r.callFlows.OnDigit(event.ParticipantIdentity, event.Digit)
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: string(event.ParticipantIdentity),
+		Value: &livekit.DataPacket_SipDtmf{
+			SipDtmf: &livekit.SipDTMF{
+				Code:  event.Code,
+				Digit: event.Digit,
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onSTTGateEvent tells the agents of the room to pause or resume their STT streams of a track
+func (r *Room) onSTTGateEvent(event *STTGateEvent, agents []types.LocalParticipant) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal stt gate event", err)
+		return
+	}
+
+	destIdentities := make([]string, 0, len(agents))
+	for _, p := range agents {
+		destIdentities = append(destIdentities, string(p.Identity()))
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                  livekit.DataPacket_RELIABLE,
+		DestinationIdentities: destIdentities,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(STTGateTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onSpeakingChange tells everybody in the room about a participant starting or stopping to speak
+func (r *Room) onSpeakingChange(p types.LocalParticipant, trackID livekit.TrackID, transition *audio.SpeakingTransition) {
+	// ahead of the event, agents are to stop talking as early as possible
+	r.bargeIn.ObserveSpeaking(p, trackID, transition)
+
+	payload, err := json.Marshal(newSpeakingEvent(p.Identity(), trackID, transition))
+	if err != nil {
+		r.logger.Errorw("could not marshal speaking event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: string(p.Identity()),
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				ParticipantIdentity: string(p.Identity()),
+				Payload:             payload,
+				Topic:               proto.String(SpeakingTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onBargeIn tells an agent, through data and a webhook, that somebody started speaking over it,
+// pausing its track first if configured to
+func (r *Room) onBargeIn(agent types.LocalParticipant, event *BargeInEvent) {
+	agent.GetLogger().Infow(
+		"agent interrupted",
+		"by", event.ParticipantIdentity,
+		"trackID", event.AgentTrackID,
+		"latency", time.Duration(event.LatencyMs)*time.Millisecond,
+		"paused", event.Paused,
+	)
+	if event.Paused {
+		agent.SetTrackMuted(&livekit.MuteTrackRequest{
+			Sid:   string(event.AgentTrackID),
+			Muted: true,
+		}, true)
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal barge-in event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                  livekit.DataPacket_RELIABLE,
+		DestinationIdentities: []string{string(agent.Identity())},
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(BargeInTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+
+	notifier, ok := r.telemetry.(interface {
+		NotifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption)
+	})
+	if !ok {
+		return
+	}
+	webhookEvent := &livekit.WebhookEvent{
+		Event:       WebhookEventAgentInterrupted,
+		Room:        r.ToProto(),
+		Participant: agent.ToProto(),
+	}
+	if track := agent.GetPublishedTrack(event.AgentTrackID); track != nil {
+		webhookEvent.Track = track.ToProto()
+	}
+	notifier.NotifyEvent(context.Background(), webhookEvent)
+}
+
+// onUtterance tells everybody in the room, agents in particular, about an utterance of a participant
+// starting or ending
+func (r *Room) onUtterance(p types.LocalParticipant, trackID livekit.TrackID, utterance *audio.UtteranceEvent) {
+	payload, err := json.Marshal(newUtteranceEvent(p.Identity(), trackID, utterance))
+	if err != nil {
+		r.logger.Errorw("could not marshal utterance event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: string(p.Identity()),
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				ParticipantIdentity: string(p.Identity()),
+				Payload:             payload,
+				Topic:               proto.String(UtteranceTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onDataModerationViolation lets the sender and moderators know about dropped data
+func (r *Room) onDataModerationViolation(event *DataModerationEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal data moderation event", err)
+		return
+	}
+
+	destIdentities := []string{string(event.ParticipantIdentity)}
+	for _, p := range r.GetParticipants() {
+		if p.Identity() != event.ParticipantIdentity && r.dataModerator.IsExempt(p) {
+			destIdentities = append(destIdentities, string(p.Identity()))
+		}
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind:                  livekit.DataPacket_RELIABLE,
+		DestinationIdentities: destIdentities,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(DataModerationTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// onIdleEvent warns idle participants and rooms, and disconnects or closes them once the warning period has passed
+func (r *Room) onIdleEvent(p types.LocalParticipant, event *IdleEvent) {
+	var webhookEvent string
+	switch {
+	case p != nil && event.Action == reaper.ActionWarn:
+		webhookEvent = WebhookEventParticipantIdleWarning
+	case p != nil:
+		webhookEvent = WebhookEventParticipantIdleDisconnected
+	case event.Action == reaper.ActionWarn:
+		webhookEvent = WebhookEventRoomIdleWarning
+	default:
+		webhookEvent = WebhookEventRoomIdleClosed
+	}
+	r.notifyWebhook(webhookEvent, p)
+
+	if event.Action != reaper.ActionReap {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			r.logger.Errorw("could not marshal idle event", err)
+			return
+		}
+		dp := &livekit.DataPacket{
+			Kind: livekit.DataPacket_RELIABLE,
+			Value: &livekit.DataPacket_User{
+				User: &livekit.UserPacket{
+					Payload: payload,
+					Topic:   proto.String(IdleTopic),
+				},
+			},
+		}
+		if p != nil {
+			dp.DestinationIdentities = []string{string(p.Identity())}
+		}
+		r.SendDataPacket(dp, livekit.DataPacket_RELIABLE)
+		return
+	}
+
+	if p != nil {
+		p.GetLogger().Infow("removing idle participant", "idleFor", time.Duration(event.IdleForMs)*time.Millisecond)
+		r.RemoveParticipant(p.Identity(), p.ID(), types.ParticipantCloseReasonIdle)
+		return
+	}
+	r.logger.Infow("closing idle room", "reason", "no activity", "idleFor", time.Duration(event.IdleForMs)*time.Millisecond)
+	r.Close(types.ParticipantCloseReasonRoomClosed)
+}
+
+// onB2BUAFailure ends a B2BUA call whose recording failed, the call must not continue unrecorded
+func (r *Room) onB2BUAFailure(err error) {
+	if r.IsClosed() {
+		return
+	}
+	r.logger.Warnw("ending B2BUA call, recording failed", err)
+	r.notifyWebhook(WebhookEventB2BUARecordingFailed, nil)
+	go r.Close(types.ParticipantCloseReasonRoomClosed)
+}
+
+// TalkAnalytics returns talk time, turns, interruptions and speech rate of the participants
+// of the session so far, nil when talk analytics are disabled
+// AudioSnapshotStreams returns the audio streams retained for a consumer, nil if snapshots are disabled
+func (r *Room) AudioSnapshotStreams(consumer livekit.ParticipantIdentity) []AudioSnapshotStream {
+	return r.audioSnapshots.Streams(consumer)
+}
+
+// AudioSnapshot returns the audio of a track delivered to a consumer within the duration before now as WAV
+func (r *Room) AudioSnapshot(consumer livekit.ParticipantIdentity, trackID livekit.TrackID, duration time.Duration) ([]byte, error) {
+	return r.audioSnapshots.Snapshot(consumer, trackID, duration)
+}
+
+// SubscribePCM returns a consumer of the decoded audio of a published audio track at sampleRate, in frames of
+// the profile, or of the room mix for RoomMixTrackID
+func (r *Room) SubscribePCM(trackID livekit.TrackID, profile pcmtap.Profile, sampleRate int) (*pcmtap.Subscriber, error) {
+	if trackID == RoomMixTrackID {
+		if r.pcmTaps == nil {
+			return nil, ErrPCMTapDisabled
+		}
+		return r.audioMixer.SubscribeRoomMix(profile, sampleRate)
+	}
+	for _, p := range r.GetParticipants() {
+		if track := p.GetPublishedTrack(trackID); track != nil {
+			return r.pcmTaps.Subscribe(track, profile, sampleRate)
+		}
+	}
+	return nil, ErrPCMTapNoAudioTrack
+}
+
+func (r *Room) TalkAnalytics() *talkstats.SessionStats {
+	return r.talkAnalytics.Stats()
+}
+
+func (r *Room) onTalkAnalyticsReport(stats *talkstats.SessionStats) {
+	if len(stats.Participants) == 0 {
+		return
+	}
+	payload, err := json.Marshal(stats)
+	if err != nil {
+		r.logger.Errorw("could not marshal talk analytics", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(TalkAnalyticsTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// notifyTalkAnalytics sends the talk analytics of a leaving participant as a webhook event
+func (r *Room) notifyTalkAnalytics(p types.LocalParticipant, stats *talkstats.ParticipantStats) {
+	notifier, ok := r.telemetry.(interface {
+		NotifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption)
+	})
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(stats)
+	if err != nil {
+		r.logger.Errorw("could not marshal talk analytics", err)
+		return
+	}
+	// the attribute is only added to the copy in the event, not to the participant
+	pi := p.ToProto()
+	attributes := make(map[string]string, len(pi.Attributes)+1)
+	for k, v := range pi.Attributes {
+		attributes[k] = v
+	}
+	attributes[TalkAnalyticsAttribute] = string(payload)
+	pi.Attributes = attributes
+
+	notifier.NotifyEvent(context.Background(), &livekit.WebhookEvent{
+		Event:       WebhookEventParticipantTalkAnalytics,
+		Room:        r.ToProto(),
+		Participant: pi,
+	})
+}
+
+// notifyWebhook sends webhook events the telemetry service has no dedicated method for
+func (r *Room) notifyWebhook(event string, p types.LocalParticipant) {
+	notifier, ok := r.telemetry.(interface {
+		NotifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption)
+	})
+	if !ok {
+		return
+	}
+
+	webhookEvent := &livekit.WebhookEvent{
+		Event: event,
+		Room:  r.ToProto(),
+	}
+	if p != nil {
+		webhookEvent.Participant = p.ToProto()
+	}
+	notifier.NotifyEvent(context.Background(), webhookEvent)
 }
 
 func (r *Room) onDataPacket(source types.LocalParticipant, kind livekit.DataPacket_Kind, dp *livekit.DataPacket) {
+	if !r.dataModerator.AllowDataPacket(source, dp) {
+		return
+	}
+	if transcription := dp.GetTranscription(); transcription != nil {
+		transcribed := livekit.ParticipantIdentity(transcription.TranscribedParticipantIdentity)
+		if !r.HasConsent(transcribed, ConsentTranscription) {
+			r.logger.Debugw("dropping transcription without consent", "transcribed", transcribed, "trackID", transcription.TrackId)
+			return
+		}
+	}
+	r.idleReaper.Touch(source)
 	if kind == livekit.DataPacket_RELIABLE && source != nil && dp.GetSequence() > 0 {
 		data, err := proto.Marshal(dp)
 		if err != nil {
@@ -1220,10 +2314,105 @@ func (r *Room) onDataPacket(source types.LocalParticipant, kind livekit.DataPack
 			DestIdentities: livekit.StringsAsIDs[livekit.ParticipantIdentity](dp.DestinationIdentities),
 		}, len(data))
 	}
+	if transcription := dp.GetTranscription(); transcription != nil {
+		r.mlExporter.AddTranscription(transcription)
+		r.talkAnalytics.AddTranscription(transcription)
+		r.captions.AddTranscription(transcription)
+		r.syncDetectedLanguage(transcription)
+	}
+	r.callFlows.OnDataPacket(source, dp)
+	if dtmf := dp.GetSipDtmf(); dtmf != nil {
+		r.sipCalls.SendDTMF(dp.DestinationIdentities, dtmf.Digit)
+	}
 	BroadcastDataPacketForRoom(r, source, kind, dp, r.logger)
 }
 
+// syncDetectedLanguage tunes the noise filter of the transcribed participant for the language
+// the transcriber detected, taken from the last final segment naming one
+func (r *Room) syncDetectedLanguage(transcription *livekit.Transcription) {
+	var language string
+	for _, seg := range transcription.Segments {
+		if seg.Final && seg.Language != "" {
+			language = seg.Language
+		}
+	}
+	if language == "" {
+		return
+	}
+
+	p, ok := r.GetParticipant(livekit.ParticipantIdentity(transcription.TranscribedParticipantIdentity)).(interface {
+		SetDetectedLanguage(language string)
+	})
+	if ok {
+		p.SetDetectedLanguage(language)
+	}
+}
+
+// onTranscriptionResult sends a transcript of a track to the room as a transcription packet, and final ones
+// as a webhook event
+func (r *Room) onTranscriptionResult(result *TranscriptionResult) {
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_Transcription{
+			Transcription: &livekit.Transcription{
+				TranscribedParticipantIdentity: string(result.ParticipantIdentity),
+				TrackId:                        string(result.TrackID),
+				Segments: []*livekit.TranscriptionSegment{{
+					Id:        result.SegmentID,
+					Text:      result.Text,
+					StartTime: uint64(result.StartMs),
+					EndTime:   uint64(result.EndMs),
+					Final:     result.Final,
+					Language:  result.Language,
+				}},
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+
+	if !result.Final {
+		return
+	}
+	notifier, ok := r.telemetry.(interface {
+		NotifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption)
+	})
+	if !ok {
+		return
+	}
+	p := r.GetParticipant(result.ParticipantIdentity)
+	if p == nil {
+		return
+	}
+	payload, err := json.Marshal(result)
+	if err != nil {
+		r.logger.Errorw("could not marshal transcription result", err)
+		return
+	}
+
+	// the attribute is only added to the copy in the event, not to the participant
+	pi := p.ToProto()
+	attributes := make(map[string]string, len(pi.Attributes)+1)
+	for k, v := range pi.Attributes {
+		attributes[k] = v
+	}
+	attributes[TranscriptAttribute] = string(payload)
+	pi.Attributes = attributes
+
+	webhookEvent := &livekit.WebhookEvent{
+		Event:       WebhookEventTranscriptionFinal,
+		Room:        r.ToProto(),
+		Participant: pi,
+	}
+	if track := p.GetPublishedTrack(result.TrackID); track != nil {
+		webhookEvent.Track = track.ToProto()
+	}
+	notifier.NotifyEvent(context.Background(), webhookEvent)
+}
+
 func (r *Room) onDataMessage(source types.LocalParticipant, data []byte) {
+	if !r.dataModerator.AllowDataMessage(source, data) {
+		return
+	}
+	r.idleReaper.Touch(source)
 	BroadcastDataMessageForRoom(r, source, data, r.logger)
 }
 
@@ -1294,6 +2483,7 @@ func (r *Room) RemoveParticipant(
 	delete(r.participantOpts, identity)
 	delete(r.participantRequestSources, identity)
 	delete(r.hasPublished, identity)
+	delete(r.consents, identity)
 	delete(r.agentParticpants, identity)
 	if !p.Hidden() {
 		r.protoRoom.NumParticipants--
@@ -1333,6 +2523,14 @@ func (r *Room) RemoveParticipant(
 	for _, t := range p.GetPublishedTracks() {
 		p.RemovePublishedTrack(t, false)
 		r.trackManager.RemoveTrack(t)
+		r.removeTrackProcessing(t.ID())
+	}
+	r.dataModerator.RemoveParticipant(identity)
+	r.idleReaper.RemoveParticipant(identity)
+	r.callFlows.ParticipantLeft(identity)
+	r.b2bua.RemoveLeg(p)
+	if stats := r.talkAnalytics.RemoveParticipant(identity); stats != nil {
+		r.notifyTalkAnalytics(p, stats)
 	}
 
 	if agentJob != nil {
@@ -1361,9 +2559,25 @@ func (r *Room) RemoveParticipant(
 	p.OnSyncState(nil)
 	p.OnSimulateScenario(nil)
 	p.OnLeave(nil)
+	if sp, ok := p.(interface {
+		OnSpeakingChange(func(types.LocalParticipant, livekit.TrackID, *audio.SpeakingTransition))
+	}); ok {
+		sp.OnSpeakingChange(nil)
+	}
+	if up, ok := p.(interface {
+		OnUtterance(func(types.LocalParticipant, livekit.TrackID, *audio.UtteranceEvent))
+	}); ok {
+		up.OnUtterance(nil)
+	}
 
 	// close participant as well
 	_ = p.Close(true, reason, false)
+	r.audioMixer.RemoveListener(p)
+	r.trackMirrors.RemoveViewer(p)
+	r.audioInjections.RemoveViewer(p)
+	r.realtimeBridges.RemoveViewer(p)
+	r.sipCalls.RemoveViewer(p)
+	r.ingests.RemoveViewer(p)
 
 	r.leftAt.Store(time.Now().Unix())
 
@@ -1376,12 +2590,22 @@ func (r *Room) RemoveParticipant(
 }
 
 func (r *Room) subscribeToExistingTracks(p types.LocalParticipant, isSync bool) {
+	r.trackMirrors.AddViewer(p)
+	r.audioInjections.AddViewer(p)
+	r.realtimeBridges.AddViewer(p)
+	r.sipCalls.AddViewer(p)
+	r.ingests.AddViewer(p)
+	if r.audioMixer != nil {
+		r.syncAudioMix(p)
+	}
+
 	r.lock.RLock()
 	shouldSubscribe := r.autoSubscribe(p)
 	r.lock.RUnlock()
 	if !shouldSubscribe {
 		return
 	}
+	mixedAudio := r.audioMixer.IsListener(p.ID())
 
 	var trackIDs []livekit.TrackID
 	for _, op := range r.GetParticipants() {
@@ -1392,6 +2616,9 @@ func (r *Room) subscribeToExistingTracks(p types.LocalParticipant, isSync bool)
 
 		// subscribe to all
 		for _, track := range op.GetPublishedTracks() {
+			if mixedAudio && track.Kind() == livekit.TrackType_AUDIO {
+				continue
+			}
 			trackIDs = append(trackIDs, track.ID())
 			p.SubscribeToTrack(track.ID(), isSync)
 		}
@@ -1510,6 +2737,7 @@ func (r *Room) audioUpdateWorker() {
 		}
 
 		activeSpeakers := r.GetActiveSpeakers()
+		r.talkAnalytics.Observe(r.GetParticipants())
 		changedSpeakers := make([]*livekit.SpeakerInfo, 0, len(activeSpeakers))
 		nextActiveMap := make(map[livekit.ParticipantID]*livekit.SpeakerInfo, len(activeSpeakers))
 		for _, speaker := range activeSpeakers {
@@ -1720,6 +2948,167 @@ func (r *Room) DebugInfo() map[string]interface{} {
 	return info
 }
 
+// MirrorTrack sends a read-only copy of a track of another room to every participant,
+// only QA rooms accept mirrored tracks
+func (r *Room) MirrorTrack(sourceRoom livekit.RoomName, track types.MediaTrack) error {
+	return r.trackMirrors.Start(sourceRoom, track)
+}
+
+// StopMirror ends mirroring a track, returns false if it is not mirrored into this room
+func (r *Room) StopMirror(trackID livekit.TrackID) bool {
+	return r.trackMirrors.Stop(trackID)
+}
+
+// InjectAudio adds a server side track named name playing the audio written to the returned player
+// to every participant, the track is removed once playback finished
+func (r *Room) InjectAudio(
+	name string,
+	onStarted func(),
+	onFinished func(reason audioinject.FinishReason, played time.Duration),
+) (livekit.TrackID, *audioinject.Player, error) {
+	return r.audioInjections.Start(name, onStarted, onFinished)
+}
+
+// CancelAudioInjection stops playback of an injected track at once
+func (r *Room) CancelAudioInjection(trackID livekit.TrackID) error {
+	return r.audioInjections.Cancel(trackID)
+}
+
+func (r *Room) onAudioInjectionEvent(event *AudioInjectionEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal audio injection event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(AudioInjectionTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// StartRealtimeBridge relays the audio of a track to a new session of the realtime model of the config and
+// plays its answers as a server side track, returns the ID of that track
+func (r *Room) StartRealtimeBridge(trackID livekit.TrackID, overrides realtime.SessionOverrides) (livekit.TrackID, error) {
+	for _, p := range r.GetParticipants() {
+		if p.GetPublishedTrack(trackID) != nil && !r.HasConsent(p.Identity(), ConsentTranscription) {
+			return "", ErrRealtimeBridgeNoConsent
+		}
+	}
+	return r.realtimeBridges.Start(trackID, overrides)
+}
+
+// StopRealtimeBridge ends the session of a bridged track
+func (r *Room) StopRealtimeBridge(trackID livekit.TrackID) error {
+	return r.realtimeBridges.Stop(trackID)
+}
+
+func (r *Room) onRealtimeBridgeEvent(event *RealtimeBridgeEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal realtime bridge event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(RealtimeBridgeTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// JoinSIPCall adds a phone call of the SIP gateway to the room, returns the handler of the media of the call
+func (r *Room) JoinSIPCall(call *sip.Call) (sip.CallHandler, error) {
+	return r.sipCalls.Join(call)
+}
+
+func (r *Room) onSIPCallEvent(event *SIPCallEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal sip call event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(SIPCallTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// StartIngest publishes the stream of an encoder connecting to the room, returns where its media goes
+func (r *Room) StartIngest(params IngestParams) (ingest.Publisher, error) {
+	return r.ingests.Start(params)
+}
+
+func (r *Room) onIngestEvent(event *IngestEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		r.logger.Errorw("could not marshal ingest event", err)
+		return
+	}
+	r.SendDataPacket(&livekit.DataPacket{
+		Kind: livekit.DataPacket_RELIABLE,
+		Value: &livekit.DataPacket_User{
+			User: &livekit.UserPacket{
+				Payload: payload,
+				Topic:   proto.String(IngestTopic),
+			},
+		},
+	}, livekit.DataPacket_RELIABLE)
+}
+
+// CaptureLogs returns a logger that also keeps its entries for support bundles of the room
+func (r *Room) CaptureLogs(l logger.Logger) logger.Logger {
+	return newCaptureLogger(l, r.logRing, nil)
+}
+
+// SupportBundle collects the logs of the last period, the pipeline state and the ICE candidates
+// of the room, nil when support bundles are not enabled
+func (r *Room) SupportBundle(period time.Duration) *supportbundle.Bundle {
+	if r.logRing == nil {
+		return nil
+	}
+
+	now := time.Now()
+	since := now.Add(-period)
+
+	candidates := make(map[string]map[string]interface{})
+	for _, p := range r.GetParticipants() {
+		fields := connectionDetailsFields(p.GetICEConnectionInfo())
+		details := make(map[string]interface{}, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			details[fmt.Sprint(fields[i])] = fields[i+1]
+		}
+		candidates[string(p.Identity())] = details
+	}
+
+	return &supportbundle.Bundle{
+		Manifest: supportbundle.Manifest{
+			RoomName:  string(r.Name()),
+			RoomID:    string(r.ID()),
+			CreatedAt: now,
+			LogsSince: since,
+		},
+		Logs: r.logRing.Since(since, now),
+		Stats: map[string]interface{}{
+			"room":  r.ToProto(),
+			"debug": r.DebugInfo(),
+		},
+		ICECandidates: candidates,
+	}
+}
+
 func (r *Room) createAgentDispatch(dispatch *livekit.AgentDispatch) (*agentDispatch, error) {
 	dispatch.State = &livekit.AgentDispatchState{
 		CreatedAt: time.Now().UnixNano(),
@@ -2095,3 +3484,8 @@ func toParticipants(lps []types.LocalParticipant) []types.Participant {
 	}
 	return participants
 }
+
+func isRoomAdmin(p types.LocalParticipant) bool {
+	grants := p.ClaimGrants()
+	return grants != nil && grants.Video != nil && grants.Video.RoomAdmin
+}
@@ -30,10 +30,12 @@ import (
 	"github.com/livekit/livekit-server/version"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metadata"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/rtc/types/typesfakes"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/livekit-server/pkg/telemetry/telemetryfakes"
@@ -798,11 +800,133 @@ func TestRoomUpdate(t *testing.T) {
 	})
 }
 
+func TestRoomProcessingTeardown(t *testing.T) {
+	newAudioTrack := func(trackID livekit.TrackID, receiver sfu.TrackReceiver) *typesfakes.FakeMediaTrack {
+		track := &typesfakes.FakeMediaTrack{}
+		track.IDReturns(trackID)
+		track.KindReturns(livekit.TrackType_AUDIO)
+		track.PublisherIdentityReturns("p0")
+		track.ReceiversReturns([]sfu.TrackReceiver{receiver})
+		return track
+	}
+
+	t.Run("track removal detaches taps", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1, micQuality: true})
+		defer rm.Close(types.ParticipantCloseReasonNone)
+		baseline := liveReceiverTaps.Load()
+
+		receiver := newTapTestReceiver()
+		rm.micQuality.AddTrack(newAudioTrack("TR_a", receiver))
+		require.Equal(t, baseline+1, liveReceiverTaps.Load())
+		require.Len(t, receiver.downTracks, 1)
+
+		rm.removeTrackProcessing("TR_a")
+		rm.removeTrackProcessing("TR_a")
+		require.Equal(t, baseline, liveReceiverTaps.Load())
+		require.Empty(t, receiver.downTracks)
+	})
+
+	t.Run("room close leaves no taps", func(t *testing.T) {
+		rm := newRoomWithParticipants(t, testRoomOpts{num: 1, micQuality: true})
+		baseline := liveReceiverTaps.Load()
+
+		receivers := []*tapTestReceiver{newTapTestReceiver(), newTapTestReceiver()}
+		for i, receiver := range receivers {
+			rm.micQuality.AddTrack(newAudioTrack(livekit.TrackID(fmt.Sprintf("TR_%d", i)), receiver))
+		}
+		require.Equal(t, baseline+int64(len(receivers)), liveReceiverTaps.Load())
+
+		rm.Close(types.ParticipantCloseReasonNone)
+		require.Equal(t, baseline, liveReceiverTaps.Load())
+		for _, receiver := range receivers {
+			require.Empty(t, receiver.downTracks)
+		}
+	})
+}
+
+func TestRoomUpdateMetadata(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 1})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+	rm.roomConfig.Metadata.Merge = true
+
+	const maxSize = 128
+	var err error
+	for i := 0; err == nil; i++ {
+		var done <-chan struct{}
+		if done, err = rm.UpdateMetadata(fmt.Sprintf(`{"key%d":"value%d"}`, i, i), maxSize); err == nil {
+			<-done
+		}
+		require.LessOrEqual(t, len(rm.ToProto().Metadata), maxSize)
+	}
+	require.ErrorIs(t, err, metadata.ErrTooLarge)
+	require.NotEmpty(t, rm.ToProto().Metadata)
+}
+
+func TestRoomWakeWord(t *testing.T) {
+	rm := newRoomWithParticipants(t, testRoomOpts{num: 2})
+	defer rm.Close(types.ParticipantCloseReasonNone)
+	telemetryService := &telemetryfakes.FakeTelemetryService{}
+	rm.telemetry = telemetryService
+	participants := rm.GetParticipants()
+	speaker := participants[0].(*typesfakes.FakeLocalParticipant)
+	listener := participants[1].(*typesfakes.FakeLocalParticipant)
+
+	rm.onWakeWordDetected(&WakeWordEvent{
+		ParticipantIdentity: speaker.Identity(),
+		TrackID:             "TR_audio",
+		Phrase:              "hey agent",
+		Score:               0.9,
+	})
+
+	// a server event, the speaker is only named in the payload
+	require.Equal(t, 1, listener.SendDataMessageCallCount())
+	_, data, _, _ := listener.SendDataMessageArgsForCall(0)
+	dp := &livekit.DataPacket{}
+	require.NoError(t, proto.Unmarshal(data, dp))
+	require.Empty(t, dp.ParticipantIdentity)
+	require.Empty(t, dp.GetUser().ParticipantIdentity)
+	require.Equal(t, WakeWordTopic, dp.GetUser().GetTopic())
+	require.Contains(t, string(dp.GetUser().Payload), `"participant_identity":"`+string(speaker.Identity())+`"`)
+
+	require.Equal(t, 1, telemetryService.WakeWordDetectedCallCount())
+	_, room, participant, _ := telemetryService.WakeWordDetectedArgsForCall(0)
+	require.Equal(t, "room", room.Name)
+	require.Equal(t, string(speaker.Identity()), participant.Identity)
+}
+
+// tapTestReceiver is an Opus receiver that only keeps track of the attached down tracks
+type tapTestReceiver struct {
+	sfu.TrackReceiver
+
+	downTracks map[livekit.ParticipantID]sfu.TrackSender
+}
+
+func newTapTestReceiver() *tapTestReceiver {
+	return &tapTestReceiver{
+		downTracks: make(map[livekit.ParticipantID]sfu.TrackSender),
+	}
+}
+
+func (r *tapTestReceiver) Mime() mime.MimeType {
+	return mime.MimeTypeOpus
+}
+
+func (r *tapTestReceiver) AddDownTrack(track sfu.TrackSender) error {
+	r.downTracks[track.SubscriberID()] = track
+	return nil
+}
+
+func (r *tapTestReceiver) DeleteDownTrack(participantID livekit.ParticipantID) {
+	delete(r.downTracks, participantID)
+}
+
 type testRoomOpts struct {
 	num                  int
 	numHidden            int
 	protocol             types.ProtocolVersion
 	audioSmoothIntervals uint32
+	micQuality           bool
+	consent              config.ConsentConfig
 }
 
 func newRoomWithParticipants(t *testing.T, opts testRoomOpts) *Room {
@@ -819,12 +943,14 @@ func newRoomWithParticipants(t *testing.T, opts testRoomOpts) *Room {
 		config.RoomConfig{
 			EmptyTimeout:     5 * 60,
 			DepartureTimeout: 1,
+			Consent:          opts.consent,
 		},
 		&sfu.AudioConfig{
 			AudioLevelConfig: audio.AudioLevelConfig{
 				UpdateInterval:  audioUpdateInterval,
 				SmoothIntervals: opts.audioSmoothIntervals,
 			},
+			MicQuality: audio.MicQualityConfig{Enabled: opts.micQuality},
 		},
 		&livekit.ServerInfo{
 			Edition:  livekit.ServerInfo_Standard,
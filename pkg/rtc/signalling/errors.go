@@ -24,4 +24,5 @@ var (
 	ErrMetadataExceedsLimits       = errors.New("metadata size exceeds limits")
 	ErrAttributesExceedsLimits     = errors.New("attributes size exceeds limits")
 	ErrUpdateOwnMetadataNotAllowed = errors.New("update own metadata not allowed")
+	ErrUpdateReservedAttribute     = errors.New("reserved attributes can only be updated by the server")
 )
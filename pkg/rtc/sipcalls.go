@@ -0,0 +1,389 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/audioinject"
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sip"
+)
+
+const (
+	// topic of the data packets telling participants that a phone caller joined or left
+	SIPCallTopic = "agentix.sip_call"
+
+	SIPCallEventJoined = "joined"
+	SIPCallEventLeft   = "left"
+
+	// identity of a phone caller is the prefix followed by the number it called from
+	SIPCallerIdentityPrefix = "sip_"
+
+	// audio of the caller queued ahead of playback, the trunk sends in real time
+	sipCallBuffer = time.Second
+)
+
+var (
+	ErrSIPGatewayDisabled = errors.New("sip gateway is disabled")
+)
+
+// SIPCallEvent tells participants about a phone caller
+type SIPCallEvent struct {
+	Event               string                      `json:"event"`
+	CallID              string                      `json:"call_id"`
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	Name                string                      `json:"name,omitempty"`
+	// number of the caller and number it dialed
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Codec string `json:"codec"`
+	// server side track playing the audio of the caller
+	TrackID livekit.TrackID `json:"track_id,omitempty"`
+	// why the call ended, for left
+	Reason sip.EndReason `json:"reason,omitempty"`
+}
+
+type SIPCallsParams struct {
+	Logger       logger.Logger
+	SubscribePCM func(trackID livekit.TrackID, profile pcmtap.Profile, sampleRate int) (*pcmtap.Subscriber, error)
+	OnEvent      func(event *SIPCallEvent)
+	// digits the callers send, as those of published tracks
+	OnDTMF func(event *DTMFEvent)
+}
+
+// SIPCalls puts phone calls of the SIP gateway into the room. The audio of a caller is played as a server side
+// track every participant receives, the caller hears the room mix, i. e. the participants publishing audio but
+// not other callers. Digits of the caller are delivered like in-band DTMF of a participant, and SIP DTMF
+// packets sent to a caller are played to it as telephone events.
+type SIPCalls struct {
+	params     SIPCallsParams
+	injections *AudioInjections
+
+	lock    sync.Mutex
+	calls   map[livekit.ParticipantIdentity]*sipCall
+	stopped core.Fuse
+}
+
+func NewSIPCalls(params SIPCallsParams) *SIPCalls {
+	return &SIPCalls{
+		params: params,
+		injections: NewAudioInjections(AudioInjectionsParams{
+			Config: audioinject.Config{
+				BufferDuration: sipCallBuffer,
+			},
+			Logger:  params.Logger,
+			OnEvent: func(event *AudioInjectionEvent) {},
+		}),
+		calls: make(map[livekit.ParticipantIdentity]*sipCall),
+	}
+}
+
+// Join adds a caller to the room, returns the handler of the media of the call
+func (s *SIPCalls) Join(call *sip.Call) (sip.CallHandler, error) {
+	if s == nil {
+		return nil, ErrSIPGatewayDisabled
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := &sipCall{
+		s:        s,
+		call:     call,
+		identity: livekit.ParticipantIdentity(SIPCallerIdentityPrefix + call.From),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+
+	// reserved while joining, a number calls into a room once at a time
+	s.lock.Lock()
+	switch {
+	case s.stopped.IsBroken():
+		s.lock.Unlock()
+		cancel()
+		return nil, ErrSIPGatewayDisabled
+	case s.calls[c.identity] != nil:
+		s.lock.Unlock()
+		cancel()
+		return nil, sip.ErrBusy
+	}
+	s.calls[c.identity] = c
+	s.lock.Unlock()
+
+	if err := s.connect(c); err != nil {
+		s.params.Logger.Warnw("could not join sip call", err, "callID", call.ID, "identity", c.identity)
+		s.stop(c, "")
+		return nil, err
+	}
+
+	s.params.Logger.Infow("sip caller joined", "callID", call.ID, "identity", c.identity, "trackID", c.trackID, "codec", call.Format.Name)
+	s.params.OnEvent(c.event(SIPCallEventJoined, ""))
+	go s.relay(c)
+	return c, nil
+}
+
+// SendDTMF plays a digit to the callers of identities
+func (s *SIPCalls) SendDTMF(identities []string, digit string) {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	var calls []*sipCall
+	for _, identity := range identities {
+		if c, ok := s.calls[livekit.ParticipantIdentity(identity)]; ok {
+			calls = append(calls, c)
+		}
+	}
+	s.lock.Unlock()
+
+	for _, c := range calls {
+		go func(c *sipCall) {
+			if err := c.call.SendDTMF(digit); err != nil {
+				s.params.Logger.Infow("could not send dtmf to sip caller", "error", err, "identity", c.identity, "digit", digit)
+			}
+		}(c)
+	}
+}
+
+func (s *SIPCalls) AddViewer(p types.LocalParticipant) {
+	if s == nil {
+		return
+	}
+	s.injections.AddViewer(p)
+}
+
+func (s *SIPCalls) RemoveViewer(p types.LocalParticipant) {
+	if s == nil {
+		return
+	}
+	s.injections.RemoveViewer(p)
+}
+
+// Close hangs up all calls
+func (s *SIPCalls) Close() {
+	if s == nil {
+		return
+	}
+
+	s.lock.Lock()
+	s.stopped.Break()
+	calls := make([]*sipCall, 0, len(s.calls))
+	for _, c := range s.calls {
+		calls = append(calls, c)
+	}
+	s.lock.Unlock()
+
+	for _, c := range calls {
+		s.stop(c, sip.EndReasonClosed)
+	}
+	s.injections.Close()
+}
+
+// connect subscribes to the room mix and adds the track of the caller
+func (s *SIPCalls) connect(c *sipCall) error {
+	// the room mix is tapped at 16 kHz or 48 kHz, narrowband calls are resampled from 16 kHz
+	sampleRate := pcmtap.SampleRate48k
+	if c.call.SampleRate() <= pcmtap.SampleRate16k {
+		sampleRate = pcmtap.SampleRate16k
+	}
+	subscriber, err := s.params.SubscribePCM(RoomMixTrackID, pcmtap.ProfileRaw, sampleRate)
+	if err != nil {
+		return err
+	}
+	if !c.setSubscriber(subscriber) {
+		subscriber.Close()
+		return sip.ErrCallEnded
+	}
+
+	trackID, player, err := s.injections.Start(
+		string(c.identity),
+		nil,
+		func(reason audioinject.FinishReason, played time.Duration) {
+			if reason == audioinject.FinishClosed {
+				s.stop(c, sip.EndReasonClosed)
+			}
+		},
+	)
+	if err != nil {
+		return err
+	}
+	if !c.setPlayer(trackID, player) {
+		player.Cancel()
+		return sip.ErrCallEnded
+	}
+	return nil
+}
+
+// relay sends the room mix to the caller until either goes away
+func (s *SIPCalls) relay(c *sipCall) {
+	for {
+		frame, err := c.subscriber.Next(c.ctx)
+		if err != nil {
+			if c.ctx.Err() == nil {
+				s.stop(c, sip.EndReasonClosed)
+			}
+			return
+		}
+		if err := c.call.WriteAudio(frame.PCM, frame.SampleRate); err != nil {
+			if !errors.Is(err, sip.ErrCallEnded) {
+				s.params.Logger.Warnw("could not send audio to sip caller", err, "callID", c.call.ID)
+			}
+			s.stop(c, sip.EndReasonClosed)
+			return
+		}
+	}
+}
+
+// stop releases the call and hangs it up, reason is empty when it never joined, the gateway rejects it then
+func (s *SIPCalls) stop(c *sipCall, reason sip.EndReason) {
+	if !c.stop() {
+		return
+	}
+
+	s.lock.Lock()
+	if s.calls[c.identity] == c {
+		delete(s.calls, c.identity)
+	}
+	s.lock.Unlock()
+
+	if reason == "" {
+		return
+	}
+	c.call.Hangup()
+	s.params.Logger.Infow("sip caller left", "callID", c.call.ID, "identity", c.identity, "reason", reason)
+	s.params.OnEvent(c.event(SIPCallEventLeft, reason))
+}
+
+// --------------------------------------
+
+type sipCall struct {
+	s        *SIPCalls
+	call     *sip.Call
+	identity livekit.ParticipantIdentity
+	ctx      context.Context
+	cancel   context.CancelFunc
+
+	lock       sync.Mutex
+	trackID    livekit.TrackID
+	subscriber *pcmtap.Subscriber
+	player     *audioinject.Player
+	stopped    bool
+}
+
+// setSubscriber and setPlayer return false if the call ended while joining
+func (c *sipCall) setSubscriber(subscriber *pcmtap.Subscriber) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.subscriber = subscriber
+	return !c.stopped
+}
+
+func (c *sipCall) setPlayer(trackID livekit.TrackID, player *audioinject.Player) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.trackID = trackID
+	c.player = player
+	return !c.stopped
+}
+
+// stop releases what was set up so far, returns false if already stopped
+func (c *sipCall) stop() bool {
+	c.lock.Lock()
+	if c.stopped {
+		c.lock.Unlock()
+		return false
+	}
+	c.stopped = true
+	subscriber, player := c.subscriber, c.player
+	c.lock.Unlock()
+
+	c.cancel()
+	if subscriber != nil {
+		subscriber.Close()
+	}
+	if player != nil {
+		player.Cancel()
+	}
+	return true
+}
+
+func (c *sipCall) event(event string, reason sip.EndReason) *SIPCallEvent {
+	c.lock.Lock()
+	trackID := c.trackID
+	c.lock.Unlock()
+
+	return &SIPCallEvent{
+		Event:               event,
+		CallID:              c.call.ID,
+		ParticipantIdentity: c.identity,
+		Name:                c.call.FromName,
+		From:                c.call.From,
+		To:                  c.call.To,
+		Codec:               c.call.Format.Name,
+		TrackID:             trackID,
+		Reason:              reason,
+	}
+}
+
+// OnAudio plays the audio of the caller into the room
+func (c *sipCall) OnAudio(pcm []int16, sampleRate int) {
+	c.lock.Lock()
+	player := c.player
+	c.lock.Unlock()
+
+	if player == nil {
+		return
+	}
+	if err := player.WritePCM(c.ctx, pcm, sampleRate); err != nil && c.ctx.Err() == nil {
+		c.s.params.Logger.Debugw("could not play sip caller audio", "error", err, "callID", c.call.ID)
+	}
+}
+
+func (c *sipCall) OnDTMF(update audio.TelephoneEventUpdate) {
+	c.lock.Lock()
+	trackID := c.trackID
+	c.lock.Unlock()
+
+	event := &DTMFEvent{
+		ParticipantIdentity: c.identity,
+		TrackID:             trackID,
+		Code:                uint32(update.Event),
+		Digit:               update.Digit,
+		Phase:               update.Phase,
+		Source:              DTMFSourceTelephoneEvent,
+		EndLost:             update.EndLost,
+	}
+	if update.Phase == audio.TelephoneEventPhaseEnd {
+		event.DurationMs = update.Duration.Milliseconds()
+	}
+	c.s.params.OnDTMF(event)
+}
+
+func (c *sipCall) OnEnd(reason sip.EndReason) {
+	c.s.stop(c, reason)
+}
@@ -0,0 +1,56 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// topic of the data packets reporting participants starting and stopping to speak
+	SpeakingTopic = "agentix.speaking"
+
+	SpeakingEventParticipantSpeaking        = "ParticipantSpeaking"
+	SpeakingEventParticipantStoppedSpeaking = "ParticipantStoppedSpeaking"
+)
+
+// SpeakingEvent reports a published audio track starting or stopping to carry speech, following voice
+// activity detection on the server rather than audio levels reported by the client
+type SpeakingEvent struct {
+	Event               string                      `json:"event"`
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	// unix time in milliseconds the speech started
+	SpeechStartMs int64 `json:"speech_start_ms"`
+	// unix time in milliseconds the speech was last heard, for stopped speaking
+	SpeechEndMs int64 `json:"speech_end_ms,omitempty"`
+}
+
+func newSpeakingEvent(identity livekit.ParticipantIdentity, trackID livekit.TrackID, transition *audio.SpeakingTransition) *SpeakingEvent {
+	event := &SpeakingEvent{
+		Event:               SpeakingEventParticipantSpeaking,
+		ParticipantIdentity: identity,
+		TrackID:             trackID,
+		SpeechStartMs:       transition.SpeechStart.UnixMilli(),
+	}
+	if !transition.Speaking {
+		event.Event = SpeakingEventParticipantStoppedSpeaking
+		event.SpeechEndMs = transition.SpeechEnd.UnixMilli()
+	}
+	return event
+}
@@ -0,0 +1,290 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// StatsRPCMethod is answered by the server instead of being forwarded. Clients and agents call it
+	// with RPC on any destination identity to get the server's view of their connection as a list of
+	// RTCStats-shaped objects, named from the caller's point of view like the browser's getStats().
+	// The payload may be {"tracks": ["TR_..."]} to limit the report to some tracks.
+	StatsRPCMethod = "agentix.get_stats"
+)
+
+type statsRPCRequest struct {
+	Tracks []livekit.TrackID `json:"tracks,omitempty"`
+}
+
+type statsRPCResponse struct {
+	Stats []map[string]interface{} `json:"stats"`
+}
+
+// answerStatsRPC acknowledges and answers a StatsRPCMethod request of the participant
+func (p *ParticipantImpl) answerStatsRPC(req *livekit.RpcRequest, destinationIdentities []string) {
+	// respond in the name of the identity that was called
+	responder := ""
+	if len(destinationIdentities) != 0 {
+		responder = destinationIdentities[0]
+	}
+	p.sendRPCPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: responder,
+		Value: &livekit.DataPacket_RpcAck{
+			RpcAck: &livekit.RpcAck{RequestId: req.Id},
+		},
+	})
+
+	var filter statsRPCRequest
+	if req.Payload != "" {
+		if err := json.Unmarshal([]byte(req.Payload), &filter); err != nil {
+			p.sendRPCError(responder, req.Id, utils.DataChannelRpcErrorFromBuiltInCodes(utils.DataChannelRpcApplicationError, "invalid payload"))
+			return
+		}
+	}
+
+	payload, err := json.Marshal(statsRPCResponse{Stats: p.rtcStats(filter.Tracks)})
+	if err != nil {
+		p.sendRPCError(responder, req.Id, utils.DataChannelRpcErrorFromBuiltInCodes(utils.DataChannelRpcApplicationError, err.Error()))
+		return
+	}
+	if len(payload) > utils.DataChannelRpcMaxPayloadBytes {
+		p.sendRPCError(responder, req.Id, utils.DataChannelRpcErrorFromBuiltInCodes(utils.DataChannelRpcResponsePayloadTooLarge, "request fewer tracks"))
+		return
+	}
+
+	p.sendRPCPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: responder,
+		Value: &livekit.DataPacket_RpcResponse{
+			RpcResponse: &livekit.RpcResponse{
+				RequestId: req.Id,
+				Value:     &livekit.RpcResponse_Payload{Payload: string(payload)},
+			},
+		},
+	})
+}
+
+func (p *ParticipantImpl) sendRPCError(responder string, requestID string, rpcErr *utils.DataChannelRpcError) {
+	p.sendRPCPacket(&livekit.DataPacket{
+		Kind:                livekit.DataPacket_RELIABLE,
+		ParticipantIdentity: responder,
+		Value: &livekit.DataPacket_RpcResponse{
+			RpcResponse: &livekit.RpcResponse{
+				RequestId: requestID,
+				Value: &livekit.RpcResponse_Error{
+					Error: &livekit.RpcError{
+						Code:    uint32(rpcErr.Code),
+						Message: rpcErr.Message,
+						Data:    rpcErr.Data,
+					},
+				},
+			},
+		},
+	})
+}
+
+func (p *ParticipantImpl) sendRPCPacket(dp *livekit.DataPacket) {
+	data, err := proto.Marshal(dp)
+	if err != nil {
+		p.params.Logger.Warnw("could not marshal stats rpc packet", err)
+		return
+	}
+	if err := p.SendDataMessage(livekit.DataPacket_RELIABLE, data, "", 0); err != nil {
+		p.params.Logger.Debugw("could not send stats rpc packet", "error", err)
+	}
+}
+
+// rtcStats reports the transports and tracks of the participant, limited to tracks if given
+func (p *ParticipantImpl) rtcStats(tracks []livekit.TrackID) []map[string]interface{} {
+	timestamp := float64(time.Now().UnixNano()) / 1e6
+	wanted := func(trackID livekit.TrackID) bool {
+		if len(tracks) == 0 {
+			return true
+		}
+		for _, t := range tracks {
+			if t == trackID {
+				return true
+			}
+		}
+		return false
+	}
+
+	var stats []map[string]interface{}
+	if len(tracks) == 0 {
+		p.lock.RLock()
+		rtt := p.lastRTT
+		p.lock.RUnlock()
+		stats = append(stats, transportRTCStats(p.GetICEConnectionInfo(), rtt, timestamp)...)
+	}
+
+	// what the participant sends is received by the server
+	for _, track := range p.GetPublishedTracks() {
+		lmt, ok := track.(types.LocalMediaTrack)
+		if !ok || !wanted(track.ID()) {
+			continue
+		}
+		rtpStats := lmt.GetTrackStats()
+		if rtpStats == nil {
+			continue
+		}
+		id := fmt.Sprintf("OT_%s", track.ID())
+		outbound := map[string]interface{}{
+			"id":              id,
+			"type":            "outbound-rtp",
+			"timestamp":       timestamp,
+			"kind":            kindString(track.Kind()),
+			"trackIdentifier": string(track.ID()),
+			"packetsSent":     rtpStats.Packets,
+			"bytesSent":       rtpStats.Bytes,
+			"nackCount":       rtpStats.Nacks,
+			"pliCount":        rtpStats.Plis,
+			"remoteId":        "RI" + id,
+		}
+		if mt, ok := track.(*MediaTrack); ok {
+			if ssrcs := mt.SSRCs(); len(ssrcs) != 0 {
+				outbound["ssrc"] = ssrcs[0]
+			}
+		}
+		stats = append(stats, outbound, map[string]interface{}{
+			"id":            "RI" + id,
+			"type":          "remote-inbound-rtp",
+			"timestamp":     timestamp,
+			"kind":          kindString(track.Kind()),
+			"localId":       id,
+			"packetsLost":   rtpStats.PacketsLost,
+			"fractionLost":  rtpStats.PacketLossPercentage / 100,
+			"jitter":        rtpStats.JitterCurrent / 1e6,
+			"roundTripTime": float64(rtpStats.RttCurrent) / 1e3,
+		})
+	}
+
+	// what the participant receives is sent by the server
+	for _, subTrack := range p.GetSubscribedTracks() {
+		dt := subTrack.DownTrack()
+		if dt == nil || !wanted(subTrack.ID()) {
+			continue
+		}
+		rtpStats := dt.GetTrackStats()
+		if rtpStats == nil {
+			continue
+		}
+		stats = append(stats, map[string]interface{}{
+			"id":              fmt.Sprintf("IT_%s", subTrack.ID()),
+			"type":            "inbound-rtp",
+			"timestamp":       timestamp,
+			"kind":            kindString(subTrack.MediaTrack().Kind()),
+			"trackIdentifier": string(subTrack.ID()),
+			"ssrc":            dt.SSRC(),
+			"mimeType":        dt.Mime().String(),
+			"packetsReceived": rtpStats.Packets,
+			"bytesReceived":   rtpStats.Bytes,
+			"packetsLost":     rtpStats.PacketsLost,
+			"jitter":          rtpStats.JitterCurrent / 1e6,
+			"nackCount":       rtpStats.Nacks,
+			"pliCount":        rtpStats.Plis,
+		})
+	}
+	return stats
+}
+
+// transportRTCStats reports a transport and the selected candidates per peer connection
+func transportRTCStats(infos []*types.ICEConnectionInfo, rtt uint32, timestamp float64) []map[string]interface{} {
+	var stats []map[string]interface{}
+	for _, info := range infos {
+		name := "subscriber"
+		if info.Transport == livekit.SignalTarget_PUBLISHER {
+			name = "publisher"
+		}
+		transportID := "T_" + name
+		pairID := "CP_" + name
+
+		pair := map[string]interface{}{
+			"id":          pairID,
+			"type":        "candidate-pair",
+			"timestamp":   timestamp,
+			"transportId": transportID,
+			"state":       "succeeded",
+		}
+		if rtt != 0 {
+			pair["currentRoundTripTime"] = float64(rtt) / 1e3
+		}
+		for _, c := range info.Local {
+			if c.SelectedOrder == 0 || c.Local == nil {
+				continue
+			}
+			// the server's local candidate is the participant's remote one
+			id := "RC_" + name
+			pair["remoteCandidateId"] = id
+			stats = append(stats, map[string]interface{}{
+				"id":            id,
+				"type":          "remote-candidate",
+				"timestamp":     timestamp,
+				"transportId":   transportID,
+				"address":       c.Local.Address,
+				"port":          c.Local.Port,
+				"protocol":      c.Local.Protocol.String(),
+				"candidateType": c.Local.Typ.String(),
+			})
+			break
+		}
+		for _, c := range info.Remote {
+			if c.SelectedOrder == 0 || c.Remote == nil {
+				continue
+			}
+			id := "LC_" + name
+			pair["localCandidateId"] = id
+			stats = append(stats, map[string]interface{}{
+				"id":            id,
+				"type":          "local-candidate",
+				"timestamp":     timestamp,
+				"transportId":   transportID,
+				"address":       MaybeTruncateIP(c.Remote.Address()),
+				"port":          c.Remote.Port(),
+				"protocol":      c.Remote.NetworkType().NetworkShort(),
+				"candidateType": c.Remote.Type().String(),
+			})
+			break
+		}
+
+		stats = append(stats, map[string]interface{}{
+			"id":                      transportID,
+			"type":                    "transport",
+			"timestamp":               timestamp,
+			"selectedCandidatePairId": pairID,
+			"iceConnectionType":       info.Type.String(),
+		}, pair)
+	}
+	return stats
+}
+
+func kindString(kind livekit.TrackType) string {
+	if kind == livekit.TrackType_VIDEO {
+		return "video"
+	}
+	return "audio"
+}
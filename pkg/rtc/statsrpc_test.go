@@ -0,0 +1,59 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+func TestTransportRTCStats(t *testing.T) {
+	infos := []*types.ICEConnectionInfo{
+		{
+			Transport: livekit.SignalTarget_PUBLISHER,
+			Type:      types.ICEConnectionTypeUDP,
+			Local: []*types.ICECandidateExtended{
+				{Local: &webrtc.ICECandidate{Address: "10.0.0.1", Port: 7882, Protocol: webrtc.ICEProtocolUDP, Typ: webrtc.ICECandidateTypeHost}},
+				{Local: &webrtc.ICECandidate{Address: "1.2.3.4", Port: 7882, Protocol: webrtc.ICEProtocolUDP, Typ: webrtc.ICECandidateTypeSrflx}, SelectedOrder: 1},
+			},
+		},
+	}
+
+	stats := transportRTCStats(infos, 40, 1000)
+	byID := make(map[string]map[string]interface{}, len(stats))
+	for _, s := range stats {
+		byID[s["id"].(string)] = s
+	}
+
+	require.Equal(t, "transport", byID["T_publisher"]["type"])
+	require.Equal(t, "udp", byID["T_publisher"]["iceConnectionType"])
+	require.Equal(t, "CP_publisher", byID["T_publisher"]["selectedCandidatePairId"])
+
+	pair := byID["CP_publisher"]
+	require.Equal(t, 0.04, pair["currentRoundTripTime"])
+	require.Equal(t, "RC_publisher", pair["remoteCandidateId"])
+	require.NotContains(t, pair, "localCandidateId")
+
+	// the selected server candidate is the participant's remote candidate
+	require.Equal(t, "1.2.3.4", byID["RC_publisher"]["address"])
+	require.Equal(t, "srflx", byID["RC_publisher"]["candidateType"])
+}
@@ -0,0 +1,205 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+const (
+	// topic of the data packets telling agents to pause and resume the STT streams of a track
+	STTGateTopic = "agentix.stt_gate"
+
+	sttGateSampleInterval = 100 * time.Millisecond
+)
+
+type STTGateEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	State               audio.STTGateState          `json:"state"`
+	// on resume, audio of this length before speech_start_ms is to be sent to the STT provider first
+	PreRollMs int64 `json:"pre_roll_ms,omitempty"`
+	// unix time in milliseconds the resuming speech started
+	SpeechStartMs int64 `json:"speech_start_ms,omitempty"`
+	PausedForMs   int64 `json:"paused_for_ms,omitempty"`
+}
+
+type STTGateControllerParams struct {
+	Config          audio.STTGateConfig
+	GetParticipants func() []types.LocalParticipant
+	// agents is who the event is for, the agents in the room
+	OnEvent func(event *STTGateEvent, agents []types.LocalParticipant)
+}
+
+type sttGateTrack struct {
+	publisher livekit.ParticipantIdentity
+	track     types.MediaTrack
+	gate      *audio.STTGate
+}
+
+// STTGateController follows the voice activity of the audio published by non-agent participants
+// and tells the agents of the room to pause their STT provider streams of a track during extended
+// silence, and to resume with pre-roll audio at the speech onset. Media keeps flowing to the agents,
+// only the provider streams are paused.
+type STTGateController struct {
+	params STTGateControllerParams
+
+	lock    sync.Mutex
+	tracks  map[livekit.TrackID]*sttGateTrack
+	stopped core.Fuse
+}
+
+func NewSTTGateController(params STTGateControllerParams) *STTGateController {
+	c := &STTGateController{
+		params: params,
+		tracks: make(map[livekit.TrackID]*sttGateTrack),
+	}
+	go c.worker()
+	return c
+}
+
+func (c *STTGateController) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if c == nil || track.Kind() != livekit.TrackType_AUDIO || publisher.IsAgent() {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if _, ok := c.tracks[track.ID()]; !ok {
+		c.tracks[track.ID()] = &sttGateTrack{
+			publisher: publisher.Identity(),
+			track:     track,
+			gate:      audio.NewSTTGate(c.params.Config, time.Now()),
+		}
+	}
+}
+
+func (c *STTGateController) RemoveTrack(trackID livekit.TrackID) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	t, ok := c.tracks[trackID]
+	delete(c.tracks, trackID)
+	c.lock.Unlock()
+
+	if ok {
+		c.close(t)
+	}
+}
+
+func (c *STTGateController) Stop() {
+	if c == nil {
+		return
+	}
+
+	c.stopped.Break()
+
+	c.lock.Lock()
+	tracks := c.tracks
+	c.tracks = make(map[livekit.TrackID]*sttGateTrack)
+	c.lock.Unlock()
+
+	for _, t := range tracks {
+		c.close(t)
+	}
+}
+
+func (c *STTGateController) close(t *sttGateTrack) {
+	if t.gate.State() != audio.STTGateStatePaused {
+		return
+	}
+	saved := t.gate.Close(time.Now())
+	prometheus.RecordSTTGateResume(saved*time.Duration(len(c.agents())), true)
+}
+
+func (c *STTGateController) agents() []types.LocalParticipant {
+	var agents []types.LocalParticipant
+	for _, p := range c.params.GetParticipants() {
+		if p.IsAgent() {
+			agents = append(agents, p)
+		}
+	}
+	return agents
+}
+
+func (c *STTGateController) worker() {
+	ticker := time.NewTicker(sttGateSampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopped.Watch():
+			return
+
+		case now := <-ticker.C:
+			type transition struct {
+				event *STTGateEvent
+				saved time.Duration
+			}
+			var transitions []transition
+
+			c.lock.Lock()
+			for _, t := range c.tracks {
+				_, speaking := t.track.GetAudioLevel()
+				tr := t.gate.Observe(now, speaking && !t.track.IsMuted())
+				if tr == nil {
+					continue
+				}
+
+				event := &STTGateEvent{
+					ParticipantIdentity: t.publisher,
+					TrackID:             t.track.ID(),
+					State:               tr.State,
+				}
+				if tr.State == audio.STTGateStateOpen {
+					event.PreRollMs = c.params.Config.PreRoll.Milliseconds()
+					event.SpeechStartMs = tr.SpeechStart.UnixMilli()
+					event.PausedForMs = tr.PausedFor.Milliseconds()
+				}
+				transitions = append(transitions, transition{event: event, saved: tr.Saved})
+			}
+			c.lock.Unlock()
+
+			if len(transitions) == 0 {
+				continue
+			}
+			agents := c.agents()
+			for _, tr := range transitions {
+				if tr.event.State == audio.STTGateStatePaused {
+					prometheus.RecordSTTGatePause()
+				} else {
+					// every agent runs its own provider stream
+					prometheus.RecordSTTGateResume(tr.saved*time.Duration(len(agents)), false)
+				}
+				if len(agents) != 0 {
+					c.params.OnEvent(tr.event, agents)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/supportbundle"
+)
+
+// captureLogger logs through the wrapped logger and keeps a copy of every entry
+// in the room's log ring for support bundles, sampling does not apply to the copy
+type captureLogger struct {
+	logger.Logger
+	// logs with the caller of the capture logger
+	base   logger.Logger
+	ring   *supportbundle.LogRing
+	values []interface{}
+}
+
+func newCaptureLogger(l logger.Logger, ring *supportbundle.LogRing, values []interface{}) logger.Logger {
+	if ring == nil {
+		return l
+	}
+	return &captureLogger{
+		Logger: l,
+		base:   l.WithCallDepth(1),
+		ring:   ring,
+		values: values,
+	}
+}
+
+func (c *captureLogger) Debugw(msg string, keysAndValues ...interface{}) {
+	c.base.Debugw(msg, keysAndValues...)
+	c.capture(supportbundle.LevelDebug, msg, nil, keysAndValues)
+}
+
+func (c *captureLogger) Infow(msg string, keysAndValues ...interface{}) {
+	c.base.Infow(msg, keysAndValues...)
+	c.capture(supportbundle.LevelInfo, msg, nil, keysAndValues)
+}
+
+func (c *captureLogger) Warnw(msg string, err error, keysAndValues ...interface{}) {
+	c.base.Warnw(msg, err, keysAndValues...)
+	c.capture(supportbundle.LevelWarn, msg, err, keysAndValues)
+}
+
+func (c *captureLogger) Errorw(msg string, err error, keysAndValues ...interface{}) {
+	c.base.Errorw(msg, err, keysAndValues...)
+	c.capture(supportbundle.LevelError, msg, err, keysAndValues)
+}
+
+func (c *captureLogger) WithValues(keysAndValues ...interface{}) logger.Logger {
+	values := make([]interface{}, 0, len(c.values)+len(keysAndValues))
+	values = append(values, c.values...)
+	values = append(values, keysAndValues...)
+	return newCaptureLogger(c.Logger.WithValues(keysAndValues...), c.ring, values)
+}
+
+func (c *captureLogger) WithName(name string) logger.Logger {
+	return newCaptureLogger(c.Logger.WithName(name), c.ring, c.values)
+}
+
+func (c *captureLogger) WithComponent(component string) logger.Logger {
+	return newCaptureLogger(c.Logger.WithComponent(component), c.ring, c.values)
+}
+
+func (c *captureLogger) WithCallDepth(depth int) logger.Logger {
+	return newCaptureLogger(c.Logger.WithCallDepth(depth), c.ring, c.values)
+}
+
+func (c *captureLogger) WithItemSampler() logger.Logger {
+	return newCaptureLogger(c.Logger.WithItemSampler(), c.ring, c.values)
+}
+
+func (c *captureLogger) WithoutSampler() logger.Logger {
+	return newCaptureLogger(c.Logger.WithoutSampler(), c.ring, c.values)
+}
+
+func (c *captureLogger) capture(level supportbundle.Level, msg string, err error, keysAndValues []interface{}) {
+	if !c.ring.Enabled(level) {
+		return
+	}
+
+	entry := supportbundle.LogEntry{
+		Time:    time.Now(),
+		Level:   level,
+		Message: msg,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	if n := len(c.values) + len(keysAndValues); n != 0 {
+		entry.Fields = make(map[string]string, n/2)
+		addLogFields(entry.Fields, c.values)
+		addLogFields(entry.Fields, keysAndValues)
+	}
+	c.ring.Add(entry)
+}
+
+func addLogFields(fields map[string]string, keysAndValues []interface{}) {
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		fields[fmt.Sprint(keysAndValues[i])] = fmt.Sprint(keysAndValues[i+1])
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/rtc/talkstats"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// topic of the data packets carrying the running talk analytics of the session
+	TalkAnalyticsTopic = "agentix.talk_analytics"
+
+	// webhook event sent when a participant leaves, its talk analytics are set as JSON
+	// in the TalkAnalyticsAttribute attribute of the participant in the event
+	WebhookEventParticipantTalkAnalytics = "participant_talk_analytics"
+	TalkAnalyticsAttribute               = "agentix.talk_analytics"
+)
+
+type TalkAnalyticsParams struct {
+	Config   talkstats.Config
+	OnReport func(stats *talkstats.SessionStats)
+	// participants without analytics consent are left out
+	HasConsent func(identity livekit.ParticipantIdentity, flags ConsentFlags) bool
+}
+
+// TalkAnalytics follows who speaks when in a room, from the voice activity of the published audio
+// and the final segments of transcriptions, for coaching and meeting analytics.
+type TalkAnalytics struct {
+	params  TalkAnalyticsParams
+	tracker *talkstats.Tracker
+
+	stopped core.Fuse
+}
+
+func NewTalkAnalytics(params TalkAnalyticsParams) *TalkAnalytics {
+	a := &TalkAnalytics{
+		params:  params,
+		tracker: talkstats.NewTracker(params.Config, time.Now()),
+	}
+	if params.Config.ReportInterval > 0 && params.OnReport != nil {
+		go a.reportWorker()
+	}
+	return a
+}
+
+// Observe samples the voice activity of the participants, called at the audio level update interval
+func (a *TalkAnalytics) Observe(participants []types.LocalParticipant) {
+	if a == nil {
+		return
+	}
+
+	var active []string
+	for _, p := range participants {
+		if !a.hasConsent(p.Identity()) {
+			continue
+		}
+		if _, speaking := p.GetAudioLevel(); speaking {
+			active = append(active, string(p.Identity()))
+		}
+	}
+	a.tracker.Observe(time.Now(), active)
+}
+
+func (a *TalkAnalytics) AddTranscription(transcription *livekit.Transcription) {
+	if a == nil || transcription.TranscribedParticipantIdentity == "" {
+		return
+	}
+	if !a.hasConsent(livekit.ParticipantIdentity(transcription.TranscribedParticipantIdentity)) {
+		return
+	}
+
+	for _, seg := range transcription.Segments {
+		if !seg.Final {
+			continue
+		}
+		var speech time.Duration
+		if seg.EndTime > seg.StartTime {
+			speech = time.Duration(seg.EndTime-seg.StartTime) * time.Millisecond
+		}
+		a.tracker.AddTranscription(transcription.TranscribedParticipantIdentity, seg.Id, seg.Text, speech)
+	}
+}
+
+func (a *TalkAnalytics) hasConsent(identity livekit.ParticipantIdentity) bool {
+	return a.params.HasConsent == nil || a.params.HasConsent(identity, ConsentAnalytics)
+}
+
+// RemoveParticipant returns the stats of a leaving participant, nil if it never spoke
+func (a *TalkAnalytics) RemoveParticipant(identity livekit.ParticipantIdentity) *talkstats.ParticipantStats {
+	if a == nil {
+		return nil
+	}
+	return a.tracker.Leave(string(identity))
+}
+
+// ForgetParticipant drops the stats of a participant that withdrew its consent to analytics
+func (a *TalkAnalytics) ForgetParticipant(identity livekit.ParticipantIdentity) {
+	if a == nil {
+		return
+	}
+	a.tracker.Forget(string(identity))
+}
+
+// Stats returns the stats of the session so far, nil when talk analytics are disabled
+func (a *TalkAnalytics) Stats() *talkstats.SessionStats {
+	if a == nil {
+		return nil
+	}
+	return a.tracker.Stats()
+}
+
+func (a *TalkAnalytics) Stop() {
+	if a == nil {
+		return
+	}
+	a.stopped.Break()
+}
+
+func (a *TalkAnalytics) reportWorker() {
+	ticker := time.NewTicker(a.params.Config.ReportInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			a.params.OnReport(a.tracker.Stats())
+		}
+	}
+}
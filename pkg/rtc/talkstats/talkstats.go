@@ -0,0 +1,306 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package talkstats
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// silence shorter than this does not end a turn
+	TurnHangover time.Duration `yaml:"turn_hangover,omitempty"`
+	// starting to speak while somebody else holds the turn counts as an interruption once
+	// both spoke at the same time for this long, shorter overlaps are backchannel, e. g. "mm-hm"
+	InterruptionOverlap time.Duration `yaml:"interruption_overlap,omitempty"`
+	// how often running stats are sent to the room, 0 disables
+	ReportInterval time.Duration `yaml:"report_interval,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		TurnHangover:        700 * time.Millisecond,
+		InterruptionOverlap: time.Second,
+		ReportInterval:      30 * time.Second,
+	}
+)
+
+type ParticipantStats struct {
+	ParticipantIdentity string `json:"participant_identity"`
+	TalkTimeMs          int64  `json:"talk_time_ms"`
+	// share of the talk time of all participants, 0 - 1
+	TalkShare     float64 `json:"talk_share"`
+	Turns         int     `json:"turns"`
+	LongestTurnMs int64   `json:"longest_turn_ms"`
+	// times this participant interrupted somebody
+	Interruptions int `json:"interruptions"`
+	// times this participant was interrupted
+	Interrupted int `json:"interrupted"`
+	// words of final transcription segments
+	Words int `json:"words,omitempty"`
+	// speech rate of the transcribed speech, 0 without transcription
+	WordsPerMinute float64 `json:"words_per_minute,omitempty"`
+}
+
+type SessionStats struct {
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+	TalkTimeMs int64     `json:"talk_time_ms"`
+	// time more than one participant spoke
+	OverlapMs int64 `json:"overlap_ms"`
+	// time nobody spoke
+	SilenceMs    int64              `json:"silence_ms"`
+	Participants []ParticipantStats `json:"participants"`
+}
+
+// --------------------------------------
+
+type speaker struct {
+	stats      ParticipantStats
+	active     bool
+	inTurn     bool
+	turnStart  time.Time
+	lastActive time.Time
+	// overlap with the speakers holding the turn when this turn started, an interruption
+	// of a speaker is counted when its overlap reaches the configured minimum
+	pending map[string]time.Duration
+	// speech duration covered by transcription
+	transcribed time.Duration
+}
+
+func (s *speaker) endTurn() {
+	if turn := s.lastActive.Sub(s.turnStart).Milliseconds(); turn > s.stats.LongestTurnMs {
+		s.stats.LongestTurnMs = turn
+	}
+	s.inTurn = false
+	s.pending = nil
+}
+
+func (s *speaker) snapshot(totalTalkTime int64) ParticipantStats {
+	stats := s.stats
+	if totalTalkTime > 0 {
+		stats.TalkShare = float64(stats.TalkTimeMs) / float64(totalTalkTime)
+	}
+	if s.inTurn {
+		if turn := s.lastActive.Sub(s.turnStart).Milliseconds(); turn > stats.LongestTurnMs {
+			stats.LongestTurnMs = turn
+		}
+	}
+
+	speech := s.transcribed
+	if speech <= 0 {
+		speech = time.Duration(stats.TalkTimeMs) * time.Millisecond
+	}
+	if stats.Words > 0 && speech > 0 {
+		stats.WordsPerMinute = float64(stats.Words) / speech.Minutes()
+	}
+	return stats
+}
+
+// Tracker derives talk time, turns and interruptions of the participants of a session
+// from periodic voice activity samples, and speech rate from transcription.
+type Tracker struct {
+	config Config
+
+	lock      sync.Mutex
+	started   time.Time
+	last      time.Time
+	speakers  map[string]*speaker
+	overlap   time.Duration
+	silence   time.Duration
+	segmentID map[string]struct{}
+}
+
+func NewTracker(config Config, now time.Time) *Tracker {
+	if config.TurnHangover <= 0 {
+		config.TurnHangover = DefaultConfig.TurnHangover
+	}
+	if config.InterruptionOverlap <= 0 {
+		config.InterruptionOverlap = DefaultConfig.InterruptionOverlap
+	}
+	return &Tracker{
+		config:    config,
+		started:   now,
+		last:      now,
+		speakers:  make(map[string]*speaker),
+		segmentID: make(map[string]struct{}),
+	}
+}
+
+// Observe records the participants with voice activity at now,
+// the time since the previous observation is attributed to them
+func (t *Tracker) Observe(now time.Time, active []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	elapsed := now.Sub(t.last)
+	if elapsed < 0 {
+		return
+	}
+	t.last = now
+
+	switch {
+	case len(active) == 0:
+		t.silence += elapsed
+	case len(active) > 1:
+		t.overlap += elapsed
+	}
+
+	for _, s := range t.speakers {
+		s.active = false
+	}
+	for _, identity := range active {
+		s := t.getSpeakerLocked(identity)
+		s.active = true
+		s.lastActive = now
+		s.stats.TalkTimeMs += elapsed.Milliseconds()
+	}
+
+	for identity, s := range t.speakers {
+		switch {
+		case s.active && !s.inTurn:
+			s.inTurn = true
+			s.turnStart = now
+			s.stats.Turns++
+			for other, os := range t.speakers {
+				if other != identity && os.inTurn && os.turnStart.Before(now) {
+					if s.pending == nil {
+						s.pending = make(map[string]time.Duration)
+					}
+					s.pending[other] = 0
+				}
+			}
+
+		case !s.active && s.inTurn && now.Sub(s.lastActive) >= t.config.TurnHangover:
+			s.endTurn()
+		}
+	}
+
+	for _, s := range t.speakers {
+		if !s.active || len(s.pending) == 0 {
+			continue
+		}
+		for other, overlap := range s.pending {
+			os := t.speakers[other]
+			if !os.inTurn {
+				delete(s.pending, other)
+				continue
+			}
+			if !os.active {
+				continue
+			}
+			if overlap += elapsed; overlap < t.config.InterruptionOverlap {
+				s.pending[other] = overlap
+				continue
+			}
+			delete(s.pending, other)
+			s.stats.Interruptions++
+			os.stats.Interrupted++
+		}
+	}
+}
+
+// AddTranscription records a final transcription segment, segments are counted once per ID.
+// speech is the duration of the segment, 0 if not known.
+func (t *Tracker) AddTranscription(identity string, segmentID string, text string, speech time.Duration) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if segmentID != "" {
+		if _, ok := t.segmentID[segmentID]; ok {
+			return
+		}
+		t.segmentID[segmentID] = struct{}{}
+	}
+
+	s := t.getSpeakerLocked(identity)
+	s.stats.Words += len(strings.Fields(text))
+	if speech > 0 {
+		s.transcribed += speech
+	}
+}
+
+// Forget drops the stats of a participant, e. g. when it withdraws its consent to analytics
+func (t *Tracker) Forget(identity string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.speakers, identity)
+	for _, s := range t.speakers {
+		delete(s.pending, identity)
+	}
+}
+
+// Leave ends the turn of a participant and returns its stats, nil if it never spoke
+func (t *Tracker) Leave(identity string) *ParticipantStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	s, ok := t.speakers[identity]
+	if !ok {
+		return nil
+	}
+	s.active = false
+	if s.inTurn {
+		s.endTurn()
+	}
+	stats := s.snapshot(t.talkTimeLocked())
+	return &stats
+}
+
+func (t *Tracker) Stats() *SessionStats {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	talkTime := t.talkTimeLocked()
+	stats := &SessionStats{
+		StartedAt:    t.started,
+		DurationMs:   t.last.Sub(t.started).Milliseconds(),
+		TalkTimeMs:   talkTime,
+		OverlapMs:    t.overlap.Milliseconds(),
+		SilenceMs:    t.silence.Milliseconds(),
+		Participants: make([]ParticipantStats, 0, len(t.speakers)),
+	}
+	for _, s := range t.speakers {
+		stats.Participants = append(stats.Participants, s.snapshot(talkTime))
+	}
+	sort.Slice(stats.Participants, func(i, j int) bool {
+		return stats.Participants[i].ParticipantIdentity < stats.Participants[j].ParticipantIdentity
+	})
+	return stats
+}
+
+func (t *Tracker) talkTimeLocked() int64 {
+	var total int64
+	for _, s := range t.speakers {
+		total += s.stats.TalkTimeMs
+	}
+	return total
+}
+
+func (t *Tracker) getSpeakerLocked(identity string) *speaker {
+	s, ok := t.speakers[identity]
+	if !ok {
+		s = &speaker{
+			stats: ParticipantStats{ParticipantIdentity: identity},
+		}
+		t.speakers[identity] = s
+	}
+	return s
+}
@@ -0,0 +1,92 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package talkstats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type span struct {
+	identity   string
+	start, end time.Duration
+}
+
+func TestTracker(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	tracker := NewTracker(DefaultConfig, start)
+
+	spans := []span{
+		{"alice", 0, 3 * time.Second},
+		// talks over alice for a second
+		{"bob", 2 * time.Second, 5 * time.Second},
+		// backchannel while bob talks
+		{"carol", 4 * time.Second, 4300 * time.Millisecond},
+		// after bob ended his turn
+		{"alice", 6 * time.Second, 7 * time.Second},
+	}
+	const tick = 100 * time.Millisecond
+	for at := tick; at <= 8*time.Second; at += tick {
+		var active []string
+		for _, s := range spans {
+			if at > s.start && at <= s.end {
+				active = append(active, s.identity)
+			}
+		}
+		tracker.Observe(start.Add(at), active)
+	}
+
+	tracker.AddTranscription("alice", "seg1", "one two three four five", 2*time.Second)
+	tracker.AddTranscription("alice", "seg1", "one two three four five", 2*time.Second)
+	tracker.AddTranscription("alice", "seg2", "six seven eight nine ten", 2*time.Second)
+
+	stats := tracker.Stats()
+	require.Equal(t, int64(8000), stats.DurationMs)
+	require.Equal(t, int64(7300), stats.TalkTimeMs)
+	require.Equal(t, int64(1300), stats.OverlapMs)
+	require.Equal(t, int64(2000), stats.SilenceMs)
+	require.Len(t, stats.Participants, 3)
+
+	alice := stats.Participants[0]
+	require.Equal(t, "alice", alice.ParticipantIdentity)
+	require.Equal(t, int64(4000), alice.TalkTimeMs)
+	require.InDelta(t, 4000.0/7300.0, alice.TalkShare, 1e-9)
+	require.Equal(t, 2, alice.Turns)
+	require.Equal(t, int64(2900), alice.LongestTurnMs)
+	require.Equal(t, 0, alice.Interruptions)
+	require.Equal(t, 1, alice.Interrupted)
+	require.Equal(t, 10, alice.Words)
+	require.InDelta(t, 150.0, alice.WordsPerMinute, 1e-9)
+
+	bob := stats.Participants[1]
+	require.Equal(t, int64(3000), bob.TalkTimeMs)
+	require.Equal(t, 1, bob.Turns)
+	require.Equal(t, 1, bob.Interruptions)
+	require.Equal(t, 0, bob.Interrupted)
+	require.Zero(t, bob.WordsPerMinute)
+
+	carol := stats.Participants[2]
+	require.Equal(t, int64(300), carol.TalkTimeMs)
+	require.Equal(t, 1, carol.Turns)
+	require.Equal(t, 0, carol.Interruptions)
+
+	left := tracker.Leave("alice")
+	require.NotNil(t, left)
+	require.Equal(t, int64(4000), left.TalkTimeMs)
+	require.Nil(t, tracker.Leave("dave"))
+}
@@ -0,0 +1,332 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/webrtc/v4"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// stream ID of mirrored tracks is the prefix followed by the source room and track ID
+	TrackMirrorStreamIDPrefix = "agentix_mirror_"
+
+	trackMirrorSubscriberPrefix = "MIR_"
+	trackMirrorCheckInterval    = time.Second
+)
+
+var (
+	ErrNotMirrorRoom         = errors.New("room does not accept mirrored tracks")
+	ErrTrackAlreadyMirrored  = errors.New("track is already mirrored into the room")
+	ErrTrackMirrorNoReceiver = errors.New("track has no receiver to mirror")
+)
+
+type TrackMirrorsParams struct {
+	Logger logger.Logger
+}
+
+// TrackMirrors forwards read-only copies of tracks of other rooms to every participant of a QA room.
+// Mirrored tracks are sent like a server side track, i. e. they are not published by any participant
+// of the QA room, and nothing flows back to the source room.
+type TrackMirrors struct {
+	params TrackMirrorsParams
+
+	lock    sync.Mutex
+	mirrors map[livekit.TrackID]*trackMirror
+	viewers map[livekit.ParticipantID]types.LocalParticipant
+	stopped core.Fuse
+}
+
+func NewTrackMirrors(params TrackMirrorsParams) *TrackMirrors {
+	m := &TrackMirrors{
+		params:  params,
+		mirrors: make(map[livekit.TrackID]*trackMirror),
+		viewers: make(map[livekit.ParticipantID]types.LocalParticipant),
+	}
+	go m.checkWorker()
+	return m
+}
+
+// Start mirrors a track of the source room to all current and future participants
+func (m *TrackMirrors) Start(sourceRoom livekit.RoomName, track types.MediaTrack) error {
+	if m == nil {
+		return ErrNotMirrorRoom
+	}
+
+	var receiver sfu.TrackReceiver
+	if track.Kind() == livekit.TrackType_AUDIO {
+		receiver = opusReceiver(track)
+	} else if receivers := track.Receivers(); len(receivers) != 0 {
+		receiver = receivers[0]
+	}
+	if receiver == nil {
+		return ErrTrackMirrorNoReceiver
+	}
+
+	trackLocal, err := webrtc.NewTrackLocalStaticRTP(
+		receiver.Codec().RTPCodecCapability,
+		string(track.ID()),
+		TrackMirrorStreamIDPrefix+string(sourceRoom)+"_"+string(track.ID()),
+	)
+	if err != nil {
+		return err
+	}
+
+	mirror := &trackMirror{
+		sourceRoom: sourceRoom,
+		track:      track,
+		trackLocal: trackLocal,
+		senders:    make(map[livekit.ParticipantID]*webrtc.RTPSender),
+	}
+	mirror.receiverTap = newReceiverTap(trackMirrorSubscriberPrefix, track.ID(), receiver, mirror.onPacket)
+
+	m.lock.Lock()
+	if m.stopped.IsBroken() {
+		m.lock.Unlock()
+		return ErrNotMirrorRoom
+	}
+	if _, ok := m.mirrors[track.ID()]; ok {
+		m.lock.Unlock()
+		return ErrTrackAlreadyMirrored
+	}
+	m.mirrors[track.ID()] = mirror
+	viewers := make([]types.LocalParticipant, 0, len(m.viewers))
+	for _, p := range m.viewers {
+		viewers = append(viewers, p)
+	}
+	m.lock.Unlock()
+
+	if err := mirror.start(); err != nil {
+		m.lock.Lock()
+		delete(m.mirrors, track.ID())
+		m.lock.Unlock()
+		return err
+	}
+	for _, p := range viewers {
+		m.addSender(mirror, p)
+	}
+
+	m.params.Logger.Infow(
+		"mirroring track",
+		"sourceRoom", sourceRoom,
+		"trackID", track.ID(),
+		"kind", track.Kind(),
+	)
+	return nil
+}
+
+// Stop ends a mirror, returns false if the track is not mirrored
+func (m *TrackMirrors) Stop(trackID livekit.TrackID) bool {
+	if m == nil {
+		return false
+	}
+
+	m.lock.Lock()
+	mirror, ok := m.mirrors[trackID]
+	delete(m.mirrors, trackID)
+	m.lock.Unlock()
+
+	if !ok {
+		return false
+	}
+	m.close(mirror)
+	return true
+}
+
+func (m *TrackMirrors) AddViewer(p types.LocalParticipant) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	if m.stopped.IsBroken() {
+		m.lock.Unlock()
+		return
+	}
+	if _, ok := m.viewers[p.ID()]; ok {
+		m.lock.Unlock()
+		return
+	}
+	m.viewers[p.ID()] = p
+	mirrors := make([]*trackMirror, 0, len(m.mirrors))
+	for _, mirror := range m.mirrors {
+		mirrors = append(mirrors, mirror)
+	}
+	m.lock.Unlock()
+
+	for _, mirror := range mirrors {
+		m.addSender(mirror, p)
+	}
+}
+
+func (m *TrackMirrors) RemoveViewer(p types.LocalParticipant) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	delete(m.viewers, p.ID())
+	mirrors := make([]*trackMirror, 0, len(m.mirrors))
+	for _, mirror := range m.mirrors {
+		mirrors = append(mirrors, mirror)
+	}
+	m.lock.Unlock()
+
+	for _, mirror := range mirrors {
+		mirror.removeSender(p)
+	}
+}
+
+func (m *TrackMirrors) Close() {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	m.stopped.Break()
+	mirrors := m.mirrors
+	m.mirrors = make(map[livekit.TrackID]*trackMirror)
+	m.viewers = make(map[livekit.ParticipantID]types.LocalParticipant)
+	m.lock.Unlock()
+
+	for _, mirror := range mirrors {
+		mirror.stop()
+	}
+}
+
+func (m *TrackMirrors) addSender(mirror *trackMirror, p types.LocalParticipant) {
+	if err := mirror.addSender(p); err != nil {
+		p.GetLogger().Warnw("could not add mirrored track", err, "sourceRoom", mirror.sourceRoom, "trackID", mirror.track.ID())
+	}
+}
+
+func (m *TrackMirrors) close(mirror *trackMirror) {
+	mirror.stop()
+
+	m.lock.Lock()
+	viewers := make([]types.LocalParticipant, 0, len(m.viewers))
+	for _, p := range m.viewers {
+		viewers = append(viewers, p)
+	}
+	m.lock.Unlock()
+
+	for _, p := range viewers {
+		mirror.removeSender(p)
+	}
+	m.params.Logger.Infow("stopped mirroring track", "sourceRoom", mirror.sourceRoom, "trackID", mirror.track.ID())
+}
+
+// checkWorker ends mirrors of tracks that were unpublished in the source room
+func (m *TrackMirrors) checkWorker() {
+	ticker := time.NewTicker(trackMirrorCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			m.lock.Lock()
+			var ended []*trackMirror
+			for trackID, mirror := range m.mirrors {
+				if mirror.IsClosed() {
+					delete(m.mirrors, trackID)
+					ended = append(ended, mirror)
+				}
+			}
+			m.lock.Unlock()
+
+			for _, mirror := range ended {
+				m.close(mirror)
+			}
+		}
+	}
+}
+
+// --------------------------------------
+
+type trackMirror struct {
+	*receiverTap
+
+	sourceRoom livekit.RoomName
+	track      types.MediaTrack
+	trackLocal *webrtc.TrackLocalStaticRTP
+
+	lock    sync.Mutex
+	senders map[livekit.ParticipantID]*webrtc.RTPSender
+}
+
+func (t *trackMirror) addSender(p types.LocalParticipant) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if _, ok := t.senders[p.ID()]; ok {
+		return nil
+	}
+	sender, _, err := p.AddTrackLocal(t.trackLocal, types.AddTrackParams{})
+	if err != nil {
+		return err
+	}
+	t.senders[p.ID()] = sender
+	p.Negotiate(false)
+
+	if t.track.Kind() == livekit.TrackType_VIDEO {
+		// a new viewer cannot decode until the next keyframe
+		t.receiver.SendPLI(0, true)
+	}
+	return nil
+}
+
+func (t *trackMirror) removeSender(p types.LocalParticipant) {
+	t.lock.Lock()
+	sender, ok := t.senders[p.ID()]
+	delete(t.senders, p.ID())
+	t.lock.Unlock()
+
+	if !ok || p.IsClosed() {
+		return
+	}
+	if err := p.RemoveTrackLocal(sender); err != nil {
+		p.GetLogger().Warnw("could not remove mirrored track", err, "trackID", t.track.ID())
+		return
+	}
+	p.Negotiate(false)
+}
+
+func (t *trackMirror) onPacket(p *buffer.ExtPacket) {
+	// simulcast publications are mirrored at the lowest layer
+	if t.track.Kind() == livekit.TrackType_VIDEO && p.Spatial > 0 {
+		return
+	}
+
+	// header extension IDs are negotiated per peer connection, the publisher's do not apply to viewers
+	pkt := *p.Packet
+	pkt.Header.Extension = false
+	pkt.Header.Extensions = nil
+	_ = t.trackLocal.WriteRTP(&pkt)
+}
@@ -0,0 +1,50 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const (
+	// participant attribute prefix labeling the processing priority of a published track by track name,
+	// e. g. `agentix.priority.microphone` set to "agent-input" for audio an agent transcribes
+	TrackPriorityAttributePrefix = "agentix.priority."
+)
+
+// TrackPriority returns the processing priority a participant labeled its track with,
+// interactive when unlabeled or the label is invalid
+func TrackPriority(p types.Participant, track types.MediaTrack) placement.Priority {
+	lp, ok := p.(types.LocalParticipant)
+	if !ok {
+		return placement.PriorityInteractive
+	}
+	grants := lp.ClaimGrants()
+	if grants == nil {
+		return placement.PriorityInteractive
+	}
+
+	label, ok := grants.Attributes[TrackPriorityAttributePrefix+track.Name()]
+	if !ok {
+		return placement.PriorityInteractive
+	}
+	priority, err := placement.ParsePriority(label)
+	if err != nil {
+		return placement.PriorityInteractive
+	}
+	return priority
+}
@@ -0,0 +1,250 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/rtc/watchdog"
+	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// topic of the data packets carrying track health diagnostics
+	TrackHealthTopic = "agentix.track_health"
+
+	trackWatchdogSubscriberPrefix = "WDG_"
+)
+
+type TrackHealthEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	Kind                string                      `json:"kind"`
+	Stall               watchdog.Stall              `json:"stall"`
+	Action              watchdog.Action             `json:"action"`
+	Attempt             int                         `json:"attempt"`
+	StalledForMs        int64                       `json:"stalled_for_ms"`
+	Recovered           bool                        `json:"recovered"`
+}
+
+type TrackWatchdogParams struct {
+	Config  watchdog.Config
+	Logger  logger.Logger
+	OnEvent func(event *TrackHealthEvent)
+}
+
+// TrackWatchdog looks for published tracks that are stuck while their publisher is still connected
+// and works through recovery actions until media flows again.
+type TrackWatchdog struct {
+	params TrackWatchdogParams
+
+	lock    sync.Mutex
+	taps    map[livekit.TrackID]*trackWatchdogTap
+	stopped core.Fuse
+}
+
+func NewTrackWatchdog(params TrackWatchdogParams) *TrackWatchdog {
+	if params.Config.CheckInterval <= 0 {
+		params.Config.CheckInterval = watchdog.DefaultConfig.CheckInterval
+	}
+	if params.Config.StallTimeout <= 0 {
+		params.Config.StallTimeout = watchdog.DefaultConfig.StallTimeout
+	}
+	if params.Config.RecoveryInterval <= 0 {
+		params.Config.RecoveryInterval = watchdog.DefaultConfig.RecoveryInterval
+	}
+
+	w := &TrackWatchdog{
+		params: params,
+		taps:   make(map[livekit.TrackID]*trackWatchdogTap),
+	}
+	go w.checkWorker()
+	return w
+}
+
+func (w *TrackWatchdog) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if w == nil {
+		return
+	}
+
+	receivers := track.Receivers()
+	if len(receivers) == 0 {
+		return
+	}
+
+	tap := &trackWatchdogTap{
+		publisher: publisher,
+		track:     track,
+		tracker:   watchdog.NewTracker(w.params.Config, track.Kind() == livekit.TrackType_VIDEO, time.Now()),
+	}
+	tap.receiverTap = newReceiverTap(trackWatchdogSubscriberPrefix, track.ID(), receivers[0], tap.onPacket)
+
+	w.lock.Lock()
+	if _, ok := w.taps[track.ID()]; ok {
+		w.lock.Unlock()
+		return
+	}
+	w.taps[track.ID()] = tap
+	w.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		w.params.Logger.Warnw("could not tap receiver for track watchdog", err, "trackID", track.ID())
+		w.RemoveTrack(track.ID())
+	}
+}
+
+func (w *TrackWatchdog) RemoveTrack(trackID livekit.TrackID) {
+	if w == nil {
+		return
+	}
+
+	w.lock.Lock()
+	tap, ok := w.taps[trackID]
+	delete(w.taps, trackID)
+	w.lock.Unlock()
+
+	if ok {
+		tap.stop()
+	}
+}
+
+func (w *TrackWatchdog) Stop() {
+	if w == nil {
+		return
+	}
+
+	w.stopped.Break()
+
+	w.lock.Lock()
+	taps := w.taps
+	w.taps = make(map[livekit.TrackID]*trackWatchdogTap)
+	w.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.stop()
+	}
+}
+
+func (w *TrackWatchdog) checkWorker() {
+	ticker := time.NewTicker(w.params.Config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			w.lock.Lock()
+			taps := make([]*trackWatchdogTap, 0, len(w.taps))
+			for _, tap := range w.taps {
+				taps = append(taps, tap)
+			}
+			w.lock.Unlock()
+
+			for _, tap := range taps {
+				w.check(tap)
+			}
+		}
+	}
+}
+
+func (w *TrackWatchdog) check(tap *trackWatchdogTap) {
+	var lastSenderReportAt time.Time
+	for _, r := range tap.track.Receivers() {
+		if wr, ok := r.(*sfu.WebRTCReceiver); ok {
+			if at := wr.GetLastSenderReportTime(); at.After(lastSenderReportAt) {
+				lastSenderReportAt = at
+			}
+		}
+	}
+
+	// publishers stop sending video layers nobody subscribes to
+	paused := tap.track.IsMuted() || (tap.track.Kind() == livekit.TrackType_VIDEO && len(tap.track.GetAllSubscribers()) == 0)
+	event := tap.tracker.Check(time.Now(), lastSenderReportAt, paused)
+	if event == nil {
+		return
+	}
+
+	w.params.Logger.Infow(
+		"track health changed",
+		"participant", tap.publisher.Identity(),
+		"trackID", tap.track.ID(),
+		"stall", event.Stall,
+		"action", event.Action,
+		"attempt", event.Attempt,
+		"stalledFor", event.StalledFor,
+		"recovered", event.Recovered,
+	)
+	w.recover(tap, event.Action)
+
+	if w.params.OnEvent != nil {
+		w.params.OnEvent(&TrackHealthEvent{
+			ParticipantIdentity: tap.publisher.Identity(),
+			TrackID:             tap.track.ID(),
+			Kind:                tap.track.Kind().String(),
+			Stall:               event.Stall,
+			Action:              event.Action,
+			Attempt:             event.Attempt,
+			StalledForMs:        event.StalledFor.Milliseconds(),
+			Recovered:           event.Recovered,
+		})
+	}
+}
+
+func (w *TrackWatchdog) recover(tap *trackWatchdogTap, action watchdog.Action) {
+	switch action {
+	case watchdog.ActionKeyframeRequest:
+		for _, r := range tap.track.Receivers() {
+			for layer := int32(0); layer <= buffer.DefaultMaxLayerSpatial; layer++ {
+				r.SendPLI(layer, true)
+			}
+		}
+
+	case watchdog.ActionResubscribe:
+		// subscription manager subscribes again with fresh down tracks
+		for _, subscriberID := range tap.track.GetAllSubscribers() {
+			tap.track.RemoveSubscriber(subscriberID, false)
+		}
+
+	case watchdog.ActionTransportRestart:
+		if restarter, ok := tap.publisher.(interface{ RestartTransport() }); ok {
+			restarter.RestartTransport()
+		}
+	}
+}
+
+// --------------------------------------
+
+type trackWatchdogTap struct {
+	*receiverTap
+
+	publisher types.LocalParticipant
+	track     types.MediaTrack
+	tracker   *watchdog.Tracker
+}
+
+func (t *trackWatchdogTap) onPacket(p *buffer.ExtPacket) {
+	t.tracker.ObservePacket(time.Unix(0, p.Arrival), p.Packet.Timestamp)
+}
@@ -0,0 +1,307 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/transcription"
+)
+
+const (
+	WebhookEventTranscriptionFinal = "transcription_final"
+
+	// participant attribute carrying the final transcript in transcription_final webhook events
+	TranscriptAttribute = "agentix.transcript"
+
+	transcriptionSegmentPrefix = "SG_"
+
+	// wait before starting a stream again after the provider could not be reached
+	transcriptionRetryInterval = 2 * time.Second
+)
+
+// TranscriptionResult is the result of a track, with the segment it belongs to, interim results
+// of an utterance sharing the segment ID with its final one
+type TranscriptionResult struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	SegmentID           string                      `json:"segment_id"`
+	Text                string                      `json:"text"`
+	Final               bool                        `json:"final"`
+	Language            string                      `json:"language,omitempty"`
+	// position of the utterance in the audio transcribed since the track was published
+	StartMs int64 `json:"start_ms"`
+	EndMs   int64 `json:"end_ms"`
+}
+
+type TranscriptionsParams struct {
+	Config       transcription.Config
+	Logger       logger.Logger
+	SubscribePCM func(trackID livekit.TrackID, profile pcmtap.Profile, sampleRate int) (*pcmtap.Subscriber, error)
+	OnResult     func(result *TranscriptionResult)
+}
+
+// Transcriptions runs speech to text of the published audio tracks of a room with the provider of the room's
+// settings, the config overridden by the room metadata. Streams ended by the provider are started again as
+// long as the track is published.
+type Transcriptions struct {
+	params TranscriptionsParams
+
+	lock     sync.Mutex
+	settings transcription.Settings
+	tracks   map[livekit.TrackID]*transcribedTrack
+	stopped  core.Fuse
+}
+
+type transcribedTrack struct {
+	publisher livekit.ParticipantIdentity
+	trackID   livekit.TrackID
+	// set while the track is transcribed
+	cancel context.CancelFunc
+}
+
+func NewTranscriptions(params TranscriptionsParams, roomMetadata string) *Transcriptions {
+	return &Transcriptions{
+		params:   params,
+		settings: params.Config.RoomSettings(roomMetadata),
+		tracks:   make(map[livekit.TrackID]*transcribedTrack),
+	}
+}
+
+func (t *Transcriptions) AddTrack(publisher types.LocalParticipant, track types.MediaTrack) {
+	if t == nil || track.Kind() != livekit.TrackType_AUDIO || publisher.IsAgent() {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.stopped.IsBroken() || t.tracks[track.ID()] != nil {
+		return
+	}
+	tt := &transcribedTrack{
+		publisher: publisher.Identity(),
+		trackID:   track.ID(),
+	}
+	t.tracks[track.ID()] = tt
+	t.startLocked(tt)
+}
+
+func (t *Transcriptions) RemoveTrack(trackID livekit.TrackID) {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if tt, ok := t.tracks[trackID]; ok {
+		delete(t.tracks, trackID)
+		tt.stop()
+	}
+}
+
+// SyncRoomMetadata applies the settings of the room metadata, restarting the streams when they changed
+func (t *Transcriptions) SyncRoomMetadata(metadata string) {
+	if t == nil {
+		return
+	}
+
+	settings := t.params.Config.RoomSettings(metadata)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if settings == t.settings || t.stopped.IsBroken() {
+		return
+	}
+	t.params.Logger.Infow("transcription settings changed", "settings", settings, "previous", t.settings)
+	t.settings = settings
+	for _, tt := range t.tracks {
+		tt.stop()
+	}
+	for _, tt := range t.tracks {
+		t.startLocked(tt)
+	}
+}
+
+func (t *Transcriptions) Stop() {
+	if t == nil {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.stopped.Break()
+	for _, tt := range t.tracks {
+		tt.stop()
+	}
+	t.tracks = make(map[livekit.TrackID]*transcribedTrack)
+}
+
+// startLocked starts transcribing a track if the room is transcribed and streams are left.
+// Must be called with the lock held.
+func (t *Transcriptions) startLocked(tt *transcribedTrack) {
+	if !t.settings.Enabled {
+		return
+	}
+	if maxStreams := t.params.Config.MaxStreams; maxStreams > 0 {
+		running := 0
+		for _, other := range t.tracks {
+			if other.cancel != nil {
+				running++
+			}
+		}
+		if running >= maxStreams {
+			t.params.Logger.Infow("not transcribing track, too many streams", "trackID", tt.trackID, "maxStreams", maxStreams)
+			return
+		}
+	}
+
+	provider, err := transcription.NewProvider(t.params.Config, t.settings.Provider)
+	if err != nil {
+		t.params.Logger.Warnw("could not transcribe track", err, "trackID", tt.trackID, "provider", t.settings.Provider)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tt.cancel = cancel
+	go t.run(ctx, tt, provider, t.settings)
+}
+
+// run transcribes the track until it is unpublished or transcription stops
+func (t *Transcriptions) run(ctx context.Context, tt *transcribedTrack, provider transcription.TranscriptionProvider, settings transcription.Settings) {
+	logger := t.params.Logger.WithValues("trackID", tt.trackID, "provider", provider.Name())
+
+	subscriber, err := t.params.SubscribePCM(tt.trackID, pcmtap.ProfileASR, transcription.SampleRate)
+	if err != nil {
+		logger.Warnw("could not transcribe track", err)
+		return
+	}
+	defer subscriber.Close()
+
+	params := transcription.StreamParams{
+		Language:       settings.Language,
+		InterimResults: settings.InterimResults,
+	}
+	for ctx.Err() == nil {
+		startedAt := time.Now()
+		stream, err := provider.NewStream(ctx, params)
+		if err == nil {
+			if !t.stream(ctx, tt, subscriber, stream, logger) {
+				return
+			}
+		} else if ctx.Err() == nil {
+			logger.Warnw("could not start transcription stream", err)
+		}
+
+		// streams failing right away, e. g. rejected by the provider, are not started again at once
+		if wait := transcriptionRetryInterval - time.Since(startedAt); wait > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+// stream writes the audio of the track to the stream until either ends, it returns false once the track ended
+func (t *Transcriptions) stream(
+	ctx context.Context,
+	tt *transcribedTrack,
+	subscriber *pcmtap.Subscriber,
+	stream transcription.Stream,
+	logger logger.Logger,
+) bool {
+	defer stream.Close()
+
+	// results are on the timeline of the stream, which starts with the next frame of the track
+	var offset time.Duration
+	started := make(chan struct{})
+	ended := make(chan struct{})
+	go func() {
+		defer close(ended)
+
+		<-started
+		segmentID := guid.New(transcriptionSegmentPrefix)
+		for {
+			result, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					logger.Debugw("transcription stream ended", "error", err)
+				}
+				return
+			}
+			t.params.OnResult(&TranscriptionResult{
+				ParticipantIdentity: tt.publisher,
+				TrackID:             tt.trackID,
+				SegmentID:           segmentID,
+				Text:                result.Text,
+				Final:               result.Final,
+				Language:            result.Language,
+				StartMs:             (offset + result.Start).Milliseconds(),
+				EndMs:               (offset + result.End).Milliseconds(),
+			})
+			if result.Final {
+				segmentID = guid.New(transcriptionSegmentPrefix)
+			}
+		}
+	}()
+
+	first := true
+	for {
+		frame, err := subscriber.Next(ctx)
+		if err != nil {
+			if first {
+				close(started)
+			}
+			return false
+		}
+		if first {
+			offset = frame.Timestamp
+			first = false
+			close(started)
+		}
+
+		select {
+		case <-ended:
+			return true
+		default:
+		}
+		if err := stream.WriteAudio(frame.PCM); err != nil {
+			logger.Debugw("could not write to transcription stream", "error", err)
+			return true
+		}
+	}
+}
+
+func (tt *transcribedTrack) stop() {
+	if tt.cancel != nil {
+		tt.cancel()
+		tt.cancel = nil
+	}
+}
@@ -17,7 +17,6 @@ package rtc
 import (
 	"fmt"
 	"io"
-	"math/rand"
 	"net"
 	"strconv"
 	"strings"
@@ -39,9 +38,9 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/replay"
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
-	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/bwe"
 	"github.com/livekit/livekit-server/pkg/sfu/bwe/remotebwe"
@@ -301,7 +300,6 @@ type TransportParams struct {
 	FireOnTrackBySdp             bool
 	DataChannelMaxBufferedAmount uint64
 	DatachannelSlowThreshold     int
-	AudioConfig                  *sfu.AudioConfig // Add audio config for noise filtering
 
 	// for development test
 	DatachannelMaxReceiverBufferSize int
@@ -377,7 +375,8 @@ func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimat
 		se.SetLite(false)
 	}
 	se.SetDTLSRetransmissionInterval(dtlsRetransmissionInterval)
-	se.SetICETimeouts(iceDisconnectedTimeout, iceFailedTimeout, iceKeepaliveInterval)
+	disconnectedTimeout, failedTimeout, keepaliveInterval := params.Config.iceTimeouts()
+	se.SetICETimeouts(disconnectedTimeout, failedTimeout, keepaliveInterval)
 
 	// if client don't support prflx over relay, we should not expose private address to it, use single external ip as host candidate
 	if !params.ClientInfo.SupportsPrflxOverRelay() && len(params.Config.NAT1To1IPs) > 0 {
@@ -415,6 +414,7 @@ func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimat
 	}
 
 	ir := &interceptor.Registry{}
+
 	if params.IsSendSide {
 		if params.CongestionControlConfig.UseSendSideBWEInterceptor && !params.CongestionControlConfig.UseSendSideBWE {
 			params.Logger.Infow("using send side BWE - interceptor")
@@ -487,19 +487,6 @@ func newPeerConnection(params TransportParams, onBandwidthEstimator func(estimat
 		params.Config.BufferFactory.SetRTXPair(repair, base)
 	}, params.Logger))
 
-	// Add noise filter interceptor for audio processing (AgentIX enhancement)
-	if params.AudioConfig != nil && params.AudioConfig.NoiseFilter.Enabled {
-		noiseFilterFactory := sfuinterceptor.NewNoiseFilterFactory(
-			params.AudioConfig.NoiseFilter,
-			params.Logger,
-		)
-		ir.Add(noiseFilterFactory)
-		params.Logger.Infow("noise filter interceptor registered",
-			"enabled", params.AudioConfig.NoiseFilter.Enabled,
-			"threshold", params.AudioConfig.NoiseFilter.Threshold,
-			"aggressive", params.AudioConfig.NoiseFilter.Aggressive)
-	}
-
 	api := webrtc.NewAPI(
 		webrtc.WithMediaEngine(me),
 		webrtc.WithSettingEngine(se),
@@ -527,7 +514,7 @@ func NewPCTransport(params TransportParams) (*PCTransport, error) {
 		connectionDetails:        types.NewICEConnectionDetails(params.Transport, params.Logger),
 		lastNegotiate:            time.Now(),
 	}
-	t.localOfferId.Store(uint32(rand.Intn(1<<8) + 1))
+	t.localOfferId.Store(uint32(replay.Intn(1<<8) + 1))
 
 	bwe, err := t.createPeerConnection()
 	if err != nil {
@@ -633,7 +620,8 @@ func (t *PCTransport) setICEStartedAt(at time.Time) {
 		t.iceStartedAt = at
 
 		// checklist of ice agent will be cleared on ice failed, get stats before that
-		t.mayFailedICEStatsTimer = time.AfterFunc(iceFailedTimeoutTotal-time.Second, t.logMayFailedICEStats)
+		disconnectedTimeout, failedTimeout, _ := t.params.Config.iceTimeouts()
+		t.mayFailedICEStatsTimer = time.AfterFunc(disconnectedTimeout+failedTimeout-time.Second, t.logMayFailedICEStats)
 
 		// set failure timer for tcp ice connection based on signaling RTT
 		if t.preferTCP.Load() {
@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/frostbyte73/core"
 	"github.com/pion/rtcp"
 	"github.com/pion/sctp"
 	"github.com/pion/sdp/v3"
@@ -35,10 +36,13 @@ import (
 	"github.com/livekit/protocol/utils"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/replay"
 	"github.com/livekit/livekit-server/pkg/rtc/transport"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/datachannel"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
 	"github.com/livekit/livekit-server/pkg/sfu/pacer"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 )
@@ -96,6 +100,13 @@ type TransportManagerParams struct {
 	DataChannelStats             *telemetry.BytesTrackStats
 	UseOneShotSignallingMode     bool
 	FireOnTrackBySdp             bool
+	AudioConfig                  *sfu.AudioConfig
+	// noise profile learned in previous sessions of the participant
+	NoiseProfile *audio.NoiseProfile
+	// capture of the participant for replay, closed with the transport manager
+	Capture *replay.CaptureSession
+	// returns true if the participant publishes an unmuted track, only then a peer is expected to send media
+	IsPublishingMedia func() bool
 }
 
 type TransportManager struct {
@@ -117,6 +128,11 @@ type TransportManager struct {
 	iceConfig                    *livekit.ICEConfig
 
 	mediaLossProxy       *MediaLossProxy
+	receiveStages        *ReceiveStages
+	noiseFilter          *sfuinterceptor.NoiseFilterFactory
+	vad                  *sfuinterceptor.VADFactory
+	activityMonitor      *sfuinterceptor.ActivityMonitor
+	closed               core.Fuse
 	udpLossUnstableCount uint32
 	signalingRTT, udpRTT uint32
 
@@ -137,6 +153,56 @@ func NewTransportManager(params TransportManagerParams) (*TransportManager, erro
 	t.mediaLossProxy.OnMediaLossUpdate(t.onMediaLossUpdate)
 
 	lgr := LoggerWithPCTarget(params.Logger, livekit.SignalTarget_PUBLISHER)
+	// processing of published media runs as the buffers receive it, interceptors of the
+	// publisher peer connection only see the packets read ahead of binding a buffer
+	t.receiveStages = NewReceiveStages(lgr)
+	if capture := params.Capture.Factory(); capture != nil {
+		// streams are captured as they arrive at the buffers, ahead of every stage a replay runs
+		t.receiveStages.Add(capture)
+	}
+	flightRecorder := params.Config.FlightRecorder
+	if flightRecorder != nil {
+		// origin probe has to be ahead of the processing stages to time every one of them
+		t.receiveStages.Add(flightRecorder.OriginProbe())
+	}
+	addStageProbe := func(stage string) {
+		if flightRecorder != nil {
+			t.receiveStages.Add(flightRecorder.StageProbe(stage))
+		}
+	}
+	if params.AudioConfig != nil && params.AudioConfig.NoiseFilter.Enabled {
+		t.noiseFilter = sfuinterceptor.NewNoiseFilterFactory(params.AudioConfig.NoiseFilter, lgr)
+		t.noiseFilter.SetProfile(params.NoiseProfile)
+		t.noiseFilter.SetConcealment(params.AudioConfig.Concealment)
+		t.receiveStages.Add(t.noiseFilter)
+		addStageProbe("noise_filter")
+		config := t.noiseFilter.GetConfig()
+		lgr.Infow("noise filter registered",
+			"enabled", config.Enabled,
+			"threshold", config.Threshold,
+			"aggressiveness", config.Level())
+	}
+	if params.AudioConfig != nil && params.AudioConfig.VAD.Enabled {
+		t.vad = sfuinterceptor.NewVADFactory(params.AudioConfig.VAD, lgr)
+		// behind the noise filter, reusing the voice activity it attaches to denoised packets
+		t.receiveStages.Add(t.vad)
+		addStageProbe("vad")
+	}
+	// stages of an embedding binary, seeing denoised audio with its voice activity
+	for _, stage := range params.Config.Interceptors {
+		t.receiveStages.Add(stage.Factory)
+		addStageProbe(stage.Name)
+	}
+	// canary slot, last so that it observes what the probed stages deliver
+	if params.Config.Canary != nil {
+		t.receiveStages.Add(params.Config.Canary)
+		addStageProbe("canary")
+	}
+	if params.Config.ICEConsent.DeadPeer.Enabled {
+		// sees every packet and RTCP the peer sends on its tracks
+		t.activityMonitor = sfuinterceptor.NewActivityMonitor()
+		t.receiveStages.Add(t.activityMonitor)
+	}
 	publisher, err := NewPCTransport(TransportParams{
 		ProtocolVersion:              params.ProtocolVersion,
 		Config:                       params.Config,
@@ -191,17 +257,236 @@ func NewTransportManager(params TransportManagerParams) (*TransportManager, erro
 		}
 	}
 
+	if t.activityMonitor != nil {
+		go t.deadPeerWorker()
+	}
+
 	t.signalSourceValid.Store(true)
 	return t, nil
 }
 
+// ReceiveStages returns the stages processing the packets of published tracks
+func (t *TransportManager) ReceiveStages() *ReceiveStages {
+	if t == nil {
+		return nil
+	}
+	return t.receiveStages
+}
+
+// LearnedNoiseProfile returns the noise profile learned by the publisher noise filter, nil when filtering is disabled
+func (t *TransportManager) LearnedNoiseProfile() *audio.NoiseProfile {
+	if t.noiseFilter == nil {
+		return nil
+	}
+	return t.noiseFilter.LearnedProfile()
+}
+
+// SetProcessingBypass passes published audio through unfiltered while bypass is set
+func (t *TransportManager) SetProcessingBypass(bypass bool) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetBypass(bypass)
+	}
+}
+
+func (t *TransportManager) HasNoiseFilter() bool {
+	return t.noiseFilter != nil
+}
+
+// SetStreamNoiseFilter switches noise filtering of a received stream
+func (t *TransportManager) SetStreamNoiseFilter(ssrc uint32, enabled bool) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetStreamEnabled(ssrc, enabled)
+	}
+}
+
+// SetStreamNoiseFilterCompatible switches noise filter compatibility mode of a received stream
+func (t *TransportManager) SetStreamNoiseFilterCompatible(ssrc uint32, compatible bool) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetStreamCompatible(ssrc, compatible)
+	}
+}
+
+// SetStreamEchoReference cancels the audio of reference from a received stream ahead of noise filtering, nil stops it
+func (t *TransportManager) SetStreamEchoReference(ssrc uint32, reference *audio.EchoReference) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetStreamEchoReference(ssrc, reference)
+	}
+}
+
+// SetStreamGain scales the audio of a received stream by gainDB, 0 stops scaling
+func (t *TransportManager) SetStreamGain(ssrc uint32, gainDB float64) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetStreamGain(ssrc, gainDB)
+	}
+}
+
+// SetStreamBandwidthExtension switches the reconstruction of the upper band of a received narrowband stream
+func (t *TransportManager) SetStreamBandwidthExtension(ssrc uint32, enabled bool) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetStreamBandwidthExtension(ssrc, enabled)
+	}
+}
+
+// SetNoiseFilterStreamTrack labels the noise filter metrics of a received stream with its room and track
+func (t *TransportManager) SetNoiseFilterStreamTrack(ssrc uint32, room livekit.RoomName, trackID livekit.TrackID) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetStreamTrack(ssrc, room, trackID)
+	}
+}
+
+// EndNoiseFilterStream tears down the noise filter of a received stream the publisher ended with an RTCP BYE
+func (t *TransportManager) EndNoiseFilterStream(ssrc uint32, reason string) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.EndStream(ssrc, reason)
+	}
+}
+
+// OnSpeakingChange sets the callback invoked when a published audio track starts or stops carrying speech,
+// never invoked without voice activity detection
+func (t *TransportManager) OnSpeakingChange(f func(trackID livekit.TrackID, transition *audio.SpeakingTransition)) {
+	if t.vad != nil {
+		t.vad.OnSpeakingChange(f)
+	}
+}
+
+// OnUtterance sets the callback invoked when an utterance of a published audio track starts or ends,
+// never invoked without voice activity detection
+func (t *TransportManager) OnUtterance(f func(trackID livekit.TrackID, event *audio.UtteranceEvent)) {
+	if t.vad != nil {
+		t.vad.OnUtterance(f)
+	}
+}
+
+// SetVADStreamTrack sets the track of a received stream for its speaking events
+func (t *TransportManager) SetVADStreamTrack(ssrc uint32, trackID livekit.TrackID) {
+	if t.vad != nil {
+		t.vad.SetStreamTrack(ssrc, trackID)
+	}
+}
+
+// SetNoiseFilterLanguage tunes the noise filter of published audio for speech of the language
+func (t *TransportManager) SetNoiseFilterLanguage(language string) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.SetLanguage(language)
+	}
+}
+
+// UpdateAudioConfig applies a reloaded audio configuration to the noise filter of published audio,
+// streams bound afterwards also conceal their losses following it
+func (t *TransportManager) UpdateAudioConfig(config sfu.AudioConfig) {
+	if t.noiseFilter != nil {
+		t.noiseFilter.UpdateConfig(config.NoiseFilter)
+		t.noiseFilter.SetConcealment(config.Concealment)
+	}
+}
+
+// SyncStageBypass excludes the participant from processing stages following the stage bypass configuration
+// and its attributes
+func (t *TransportManager) SyncStageBypass(identity livekit.ParticipantIdentity, attributes map[string]string) {
+	if t.noiseFilter != nil && t.params.AudioConfig != nil {
+		t.noiseFilter.SetExcluded(t.params.AudioConfig.StageBypass.IsBypassed(audio.StageNoiseFilter, string(identity), attributes))
+	}
+}
+
 func (t *TransportManager) Close() {
+	t.closed.Break()
 	if t.publisher != nil {
 		t.publisher.Close()
 	}
 	if t.subscriber != nil {
 		t.subscriber.Close()
 	}
+	t.receiveStages.Close()
+	// interceptors are not guaranteed to see every stream unbound, free the remaining denoisers here
+	if t.noiseFilter != nil {
+		t.noiseFilter.Close()
+	}
+	if t.vad != nil {
+		t.vad.Close()
+	}
+	if err := t.params.Capture.Close(); err != nil {
+		t.params.Logger.Warnw("could not close capture", err, "path", t.params.Capture.Path())
+	}
+}
+
+// CaptureSignal records a signal message of the participant in its capture, if it is captured
+func (t *TransportManager) CaptureSignal(msg proto.Message) {
+	t.params.Capture.Signal(msg)
+}
+
+// deadPeerWorker notices a peer that stopped sending RTP and RTCP well before ICE consent expires
+func (t *TransportManager) deadPeerWorker() {
+	conf := t.params.Config.ICEConsent.DeadPeer
+	ticker := time.NewTicker(max(conf.Timeout/4, 100*time.Millisecond))
+	defer ticker.Stop()
+
+	detector := &deadPeerDetector{timeout: conf.Timeout}
+	for {
+		select {
+		case <-t.closed.Watch():
+			return
+
+		case now := <-ticker.C:
+			publishing := t.params.IsPublishingMedia == nil || t.params.IsPublishingMedia()
+			switch idle, state := detector.update(now, t.activityMonitor.LastActivity(), publishing); state {
+			case deadPeerResumed:
+				t.params.Logger.Infow("peer resumed sending media")
+
+			case deadPeerDied:
+				t.params.Logger.Infow("peer stopped sending media", "idle", idle, "action", conf.Action)
+				if t.noiseFilter != nil {
+					t.noiseFilter.Release()
+				}
+				if conf.Action == config.DeadPeerActionClose && t.params.PublisherHandler != nil {
+					t.params.PublisherHandler.OnFailed(false, t.publisher.GetICEConnectionInfo())
+				}
+			}
+		}
+	}
+}
+
+type deadPeerChange int
+
+const (
+	deadPeerUnchanged deadPeerChange = iota
+	deadPeerDied
+	deadPeerResumed
+)
+
+// deadPeerDetector decides whether a peer stopped sending media from the time it last sent anything,
+// a peer with all of its tracks muted is not expected to send
+type deadPeerDetector struct {
+	timeout  time.Duration
+	dead     bool
+	exemptAt time.Time
+}
+
+func (d *deadPeerDetector) update(now time.Time, lastActivity time.Time, publishing bool) (time.Duration, deadPeerChange) {
+	if !publishing {
+		d.exemptAt = now
+		return 0, deadPeerUnchanged
+	}
+	if lastActivity.IsZero() {
+		return 0, deadPeerUnchanged
+	}
+	// a peer unmuting gets the timeout to resume sending
+	if lastActivity.Before(d.exemptAt) {
+		lastActivity = d.exemptAt
+	}
+
+	idle := now.Sub(lastActivity)
+	if idle < d.timeout {
+		if d.dead {
+			d.dead = false
+			return idle, deadPeerResumed
+		}
+		return idle, deadPeerUnchanged
+	}
+	if d.dead {
+		return idle, deadPeerUnchanged
+	}
+	d.dead = true
+	return idle, deadPeerDied
 }
 
 func (t *TransportManager) SubscriberClose() {
@@ -0,0 +1,112 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtcp"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+)
+
+func TestDeadPeerDetector(t *testing.T) {
+	monitor := sfuinterceptor.NewActivityMonitor()
+	stages := NewReceiveStages(logger.GetLogger())
+	stages.Add(monitor)
+	defer stages.Close()
+
+	pcmu := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "audio/PCMU", ClockRate: 8000},
+		PayloadType:        0,
+	}
+	buff := buffer.NewBuffer(1234, 100, 100)
+	buff.SetStages(stages, &interceptor.StreamInfo{SSRC: 1234, PayloadType: 0, MimeType: pcmu.MimeType, ClockRate: pcmu.ClockRate})
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{pcmu},
+	}, pcmu.RTPCodecCapability, 0))
+	defer buff.Close()
+
+	detector := &deadPeerDetector{timeout: 50 * time.Millisecond}
+	_, change := detector.update(time.Now(), monitor.LastActivity(), true)
+	require.Equal(t, deadPeerUnchanged, change)
+
+	// a publisher sending media through its buffers is live for longer than the timeout
+	buf := make([]byte, 1500)
+	for sn := uint16(1); sn <= 10; sn++ {
+		raw, err := (&rtp.Packet{
+			Header:  rtp.Header{Version: 2, SequenceNumber: sn, Timestamp: uint32(sn) * 160, SSRC: 1234},
+			Payload: make([]byte, 160),
+		}).Marshal()
+		require.NoError(t, err)
+		_, err = buff.Write(raw)
+		require.NoError(t, err)
+		_, err = buff.ReadExtended(buf)
+		require.NoError(t, err)
+
+		_, change := detector.update(time.Now(), monitor.LastActivity(), true)
+		require.Equal(t, deadPeerUnchanged, change)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// so is one sending RTCP only
+	for i := 0; i < 10; i++ {
+		lastActivity := monitor.LastActivity()
+		sr, err := (&rtcp.SenderReport{SSRC: 1234}).Marshal()
+		require.NoError(t, err)
+		stages.WriteRTCP(sr)
+		require.Eventually(t, func() bool {
+			return monitor.LastActivity().After(lastActivity)
+		}, time.Second, time.Millisecond)
+
+		_, change := detector.update(time.Now(), monitor.LastActivity(), true)
+		require.Equal(t, deadPeerUnchanged, change)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// a peer with its tracks muted is not expected to send
+	time.Sleep(60 * time.Millisecond)
+	_, change = detector.update(time.Now(), monitor.LastActivity(), false)
+	require.Equal(t, deadPeerUnchanged, change)
+	// and gets the timeout to resume once unmuted
+	_, change = detector.update(time.Now(), monitor.LastActivity(), true)
+	require.Equal(t, deadPeerUnchanged, change)
+
+	time.Sleep(60 * time.Millisecond)
+	idle, change := detector.update(time.Now(), monitor.LastActivity(), true)
+	require.Equal(t, deadPeerDied, change)
+	require.GreaterOrEqual(t, idle, 50*time.Millisecond)
+	_, change = detector.update(time.Now(), monitor.LastActivity(), true)
+	require.Equal(t, deadPeerUnchanged, change)
+
+	sr, err := (&rtcp.SenderReport{SSRC: 1234}).Marshal()
+	require.NoError(t, err)
+	lastActivity := monitor.LastActivity()
+	stages.WriteRTCP(sr)
+	require.Eventually(t, func() bool {
+		return monitor.LastActivity().After(lastActivity)
+	}, time.Second, time.Millisecond)
+	_, change = detector.update(time.Now(), monitor.LastActivity(), true)
+	require.Equal(t, deadPeerResumed, change)
+}
@@ -116,6 +116,7 @@ const (
 	ParticipantCloseReasonUserUnavailable
 	ParticipantCloseReasonUserRejected
 	ParticipantCloseReasonMoveFailed
+	ParticipantCloseReasonIdle
 )
 
 func (p ParticipantCloseReason) String() string {
@@ -176,6 +177,8 @@ func (p ParticipantCloseReason) String() string {
 		return "USER_REJECTED"
 	case ParticipantCloseReasonMoveFailed:
 		return "MOVE_FAILED"
+	case ParticipantCloseReasonIdle:
+		return "IDLE"
 	default:
 		return fmt.Sprintf("%d", int(p))
 	}
@@ -196,7 +199,7 @@ func (p ParticipantCloseReason) ToDisconnectReason() livekit.DisconnectReason {
 		return livekit.DisconnectReason_DUPLICATE_IDENTITY
 	case ParticipantCloseReasonMigrationRequested, ParticipantCloseReasonMigrationComplete, ParticipantCloseReasonSimulateMigration:
 		return livekit.DisconnectReason_MIGRATION
-	case ParticipantCloseReasonServiceRequestRemoveParticipant:
+	case ParticipantCloseReasonServiceRequestRemoveParticipant, ParticipantCloseReasonIdle:
 		return livekit.DisconnectReason_PARTICIPANT_REMOVED
 	case ParticipantCloseReasonServiceRequestDeleteRoom:
 		return livekit.DisconnectReason_ROOM_DELETED
@@ -404,6 +407,8 @@ type LocalParticipant interface {
 
 	// permissions
 	ClaimGrants() *auth.ClaimGrants
+	// attributes as last set, by the token or at runtime
+	GetAttributes() map[string]string
 	SetPermission(permission *livekit.ParticipantPermission) bool
 	CanPublish() bool
 	CanPublishSource(source livekit.TrackSource) bool
@@ -215,6 +215,16 @@ type FakeLocalParticipant struct {
 		result2 uint32
 		result3 error
 	}
+	GetAttributesStub        func() map[string]string
+	getAttributesMutex       sync.RWMutex
+	getAttributesArgsForCall []struct {
+	}
+	getAttributesReturns struct {
+		result1 map[string]string
+	}
+	getAttributesReturnsOnCall map[int]struct {
+		result1 map[string]string
+	}
 	GetAudioLevelStub        func() (float64, bool)
 	getAudioLevelMutex       sync.RWMutex
 	getAudioLevelArgsForCall []struct {
@@ -2438,6 +2448,59 @@ func (fake *FakeLocalParticipant) GetAnswerReturnsOnCall(i int, result1 webrtc.S
 	}{result1, result2, result3}
 }
 
+func (fake *FakeLocalParticipant) GetAttributes() map[string]string {
+	fake.getAttributesMutex.Lock()
+	ret, specificReturn := fake.getAttributesReturnsOnCall[len(fake.getAttributesArgsForCall)]
+	fake.getAttributesArgsForCall = append(fake.getAttributesArgsForCall, struct {
+	}{})
+	stub := fake.GetAttributesStub
+	fakeReturns := fake.getAttributesReturns
+	fake.recordInvocation("GetAttributes", []interface{}{})
+	fake.getAttributesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLocalParticipant) GetAttributesCallCount() int {
+	fake.getAttributesMutex.RLock()
+	defer fake.getAttributesMutex.RUnlock()
+	return len(fake.getAttributesArgsForCall)
+}
+
+func (fake *FakeLocalParticipant) GetAttributesCalls(stub func() map[string]string) {
+	fake.getAttributesMutex.Lock()
+	defer fake.getAttributesMutex.Unlock()
+	fake.GetAttributesStub = stub
+}
+
+func (fake *FakeLocalParticipant) GetAttributesReturns(result1 map[string]string) {
+	fake.getAttributesMutex.Lock()
+	defer fake.getAttributesMutex.Unlock()
+	fake.GetAttributesStub = nil
+	fake.getAttributesReturns = struct {
+		result1 map[string]string
+	}{result1}
+}
+
+func (fake *FakeLocalParticipant) GetAttributesReturnsOnCall(i int, result1 map[string]string) {
+	fake.getAttributesMutex.Lock()
+	defer fake.getAttributesMutex.Unlock()
+	fake.GetAttributesStub = nil
+	if fake.getAttributesReturnsOnCall == nil {
+		fake.getAttributesReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+		})
+	}
+	fake.getAttributesReturnsOnCall[i] = struct {
+		result1 map[string]string
+	}{result1}
+}
+
 func (fake *FakeLocalParticipant) GetAudioLevel() (float64, bool) {
 	fake.getAudioLevelMutex.Lock()
 	ret, specificReturn := fake.getAudioLevelReturnsOnCall[len(fake.getAudioLevelArgsForCall)]
@@ -0,0 +1,458 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/pion/ice/v4"
+	"github.com/pion/stun/v3"
+	"github.com/pion/transport/v3/deadline"
+	tudp "github.com/pion/transport/v3/udp"
+
+	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
+	"github.com/livekit/mediatransportutil/pkg/transport"
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	// large enough for any datagram
+	shardReceiveMTU = 8192
+	// packets of a connection waiting to be read, later ones are dropped like by a full socket buffer
+	shardConnQueueSize = 512
+	// socket buffers, same as the unsharded mux
+	shardSocketBufferSize = 16_777_216
+	// standalone STUN port of use_stun_port_as_ice
+	stunPort = 3478
+)
+
+var ErrUDPMuxShardingUnsupported = errors.New("udp mux sharding requires SO_REUSEPORT, only supported on linux")
+
+var shardPacketPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, shardReceiveMTU)
+		return &b
+	},
+}
+
+// udpMuxShardable returns whether conf reads ICE over UDP through a mux on udp_port, the only mode that can be sharded
+func udpMuxShardable(conf *rtcconfig.RTCConfig) bool {
+	return !conf.ForceTCP && (conf.ICEPortRangeStart == 0 || conf.ICEPortRangeEnd == 0) && conf.UDPPort.Valid()
+}
+
+// newShardedUDPMux binds the UDP mux ports of conf like the unsharded mux, but with shards sockets per address,
+// bound with SO_REUSEPORT. The kernel hashes the 5-tuple of every packet to pick the socket, each socket has its
+// own read loop, so input is spread over more cores while the ports and the firewall rules stay the same.
+func newShardedUDPMux(conf *rtcconfig.RTCConfig, shards int, l logger.Logger) (ice.UDPMux, error) {
+	if !reusePortSupported {
+		return nil, ErrUDPMuxShardingUnsupported
+	}
+
+	ips, err := shardListenIPs(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	availablePorts := conf.UDPPort.ToSlice()
+	ports := make([]int, 0, len(availablePorts))
+	for i := 0; i < runtime.NumCPU() && i < len(availablePorts); i++ {
+		ports = append(ports, availablePorts[i])
+	}
+
+	var muxes, standalonePortMuxes []ice.UDPMux
+	closeAll := func() {
+		for _, m := range append(muxes, standalonePortMuxes...) {
+			_ = m.Close()
+		}
+	}
+	for _, ip := range ips {
+		for _, port := range ports {
+			m, err := listenShardedUDPMux(&net.UDPAddr{IP: ip, Port: port}, shards, conf.BatchIO, l)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			muxes = append(muxes, m)
+		}
+		if conf.UseStunPortAsICE {
+			m, err := listenShardedUDPMux(&net.UDPAddr{IP: ip, Port: stunPort}, shards, conf.BatchIO, l)
+			if err != nil {
+				closeAll()
+				return nil, err
+			}
+			standalonePortMuxes = append(standalonePortMuxes, m)
+		}
+	}
+
+	l.Infow("sharded udp mux", "ips", len(ips), "ports", len(ports), "shards", shards)
+	return transport.NewMultiPortsUDPMux(muxes, standalonePortMuxes), nil
+}
+
+// shardListenIPs returns the local addresses the unsharded mux listens on
+func shardListenIPs(conf *rtcconfig.RTCConfig) ([]net.IP, error) {
+	var ifFilter func(string) bool
+	if len(conf.Interfaces.Includes) != 0 || len(conf.Interfaces.Excludes) != 0 {
+		ifFilter = rtcconfig.InterfaceFilterFromConf(conf.Interfaces)
+	}
+	var ipFilter func(net.IP) bool
+	if len(conf.IPs.Includes) != 0 || len(conf.IPs.Excludes) != 0 {
+		filter, err := rtcconfig.IPFilterFromConf(conf.IPs)
+		if err != nil {
+			return nil, err
+		}
+		ipFilter = filter
+	}
+
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	var ips []net.IP
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+		if iface.Flags&net.FlagLoopback != 0 && !conf.EnableLoopbackCandidate {
+			continue
+		}
+		if ifFilter != nil && !ifFilter(iface.Name) {
+			continue
+		}
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+		for _, addr := range addrs {
+			var ip net.IP
+			switch addr := addr.(type) {
+			case *net.IPNet:
+				ip = addr.IP
+			case *net.IPAddr:
+				ip = addr.IP
+			}
+			if ip == nil || (ip.IsLoopback() && !conf.EnableLoopbackCandidate) {
+				continue
+			}
+			// same as ICE gathering, https://tools.ietf.org/html/rfc8445#section-5.1.1.1
+			if ip.To4() == nil && (ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip[0] == 0xfe && ip[1]&0xc0 == 0xc0) {
+				continue
+			}
+			if ipFilter != nil && !ipFilter(ip) {
+				continue
+			}
+			ips = append(ips, ip)
+		}
+	}
+	return ips, nil
+}
+
+// --------------------------------------
+
+// shardedUDPMux reads one local address with several sockets bound with SO_REUSEPORT, each with its own read loop.
+// A connection is pinned to the socket the first STUN packet for its ufrag arrived on and answers through it.
+// Packets of a remote address already known to a connection are delivered to it whichever socket they arrive on.
+type shardedUDPMux struct {
+	logger  logger.Logger
+	sockets []net.PacketConn
+
+	lock    sync.RWMutex
+	conns   map[string]*shardedConn
+	remotes map[string]*shardedConn
+	closed  bool
+}
+
+func listenShardedUDPMux(addr *net.UDPAddr, shards int, batchIO rtcconfig.BatchIOConfig, l logger.Logger) (*shardedUDPMux, error) {
+	network := "udp4"
+	if addr.IP.To4() == nil {
+		network = "udp6"
+	}
+
+	m := &shardedUDPMux{
+		logger:  l,
+		conns:   make(map[string]*shardedConn),
+		remotes: make(map[string]*shardedConn),
+	}
+	lc := net.ListenConfig{Control: reusePortControl}
+	for range shards {
+		bindAddr := addr.String()
+		if len(m.sockets) != 0 {
+			// same port if the first socket picked one
+			bindAddr = m.sockets[0].LocalAddr().String()
+		}
+		conn, err := lc.ListenPacket(context.Background(), network, bindAddr)
+		if err != nil {
+			for _, socket := range m.sockets {
+				_ = socket.Close()
+			}
+			return nil, err
+		}
+		if udpConn, ok := conn.(*net.UDPConn); ok {
+			_ = udpConn.SetReadBuffer(shardSocketBufferSize)
+			_ = udpConn.SetWriteBuffer(shardSocketBufferSize)
+		}
+		if batchIO.BatchSize > 0 {
+			conn = tudp.NewBatchConn(conn, batchIO.BatchSize, batchIO.MaxFlushInterval)
+		}
+		m.sockets = append(m.sockets, conn)
+	}
+
+	for shard := range m.sockets {
+		go m.readLoop(shard)
+	}
+	return m, nil
+}
+
+func (m *shardedUDPMux) Close() error {
+	m.lock.Lock()
+	if m.closed {
+		m.lock.Unlock()
+		return nil
+	}
+	m.closed = true
+	conns := make([]*shardedConn, 0, len(m.conns))
+	for _, c := range m.conns {
+		conns = append(conns, c)
+	}
+	m.lock.Unlock()
+
+	for _, c := range conns {
+		_ = c.Close()
+	}
+	var errs []error
+	for _, socket := range m.sockets {
+		errs = append(errs, socket.Close())
+	}
+	return errors.Join(errs...)
+}
+
+func (m *shardedUDPMux) GetConn(ufrag string, _ net.Addr) (net.PacketConn, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.closed {
+		return nil, io.ErrClosedPipe
+	}
+	if c, ok := m.conns[ufrag]; ok {
+		return c, nil
+	}
+
+	c := newShardedConn(m, ufrag)
+	m.conns[ufrag] = c
+	return c, nil
+}
+
+func (m *shardedUDPMux) RemoveConnByUfrag(ufrag string) {
+	m.lock.RLock()
+	c := m.conns[ufrag]
+	m.lock.RUnlock()
+
+	if c != nil {
+		_ = c.Close()
+	}
+}
+
+func (m *shardedUDPMux) GetListenAddresses() []net.Addr {
+	// all sockets are bound to the same address
+	return []net.Addr{m.sockets[0].LocalAddr()}
+}
+
+func (m *shardedUDPMux) readLoop(shard int) {
+	socket := m.sockets[shard]
+	buf := make([]byte, shardReceiveMTU)
+	for {
+		n, addr, err := socket.ReadFrom(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			m.logger.Debugw("failed to read udp mux socket", err, "shard", shard)
+			continue
+		}
+
+		m.lock.RLock()
+		c := m.remotes[addr.String()]
+		m.lock.RUnlock()
+		if c == nil {
+			if c = m.connForSTUN(buf[:n], addr, shard); c == nil {
+				continue
+			}
+		}
+		c.deliver(buf[:n], addr)
+	}
+}
+
+// connForSTUN looks up the connection of a STUN packet from an unknown remote address by the ufrag in its
+// username, pins the connection to shard if it is the first one for it and routes the address to it
+func (m *shardedUDPMux) connForSTUN(b []byte, addr net.Addr, shard int) *shardedConn {
+	if !stun.IsMessage(b) {
+		return nil
+	}
+	msg := &stun.Message{Raw: b}
+	if err := msg.Decode(); err != nil {
+		return nil
+	}
+	username, err := msg.Get(stun.AttrUsername)
+	if err != nil {
+		return nil
+	}
+	ufrag, _, _ := strings.Cut(string(username), ":")
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	c := m.conns[ufrag]
+	if c == nil {
+		return nil
+	}
+	c.shard.CompareAndSwap(-1, int32(shard))
+	m.remotes[addr.String()] = c
+	c.remotes = append(c.remotes, addr.String())
+	return c
+}
+
+func (m *shardedUDPMux) removeConn(c *shardedConn) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.conns[c.ufrag] == c {
+		delete(m.conns, c.ufrag)
+	}
+	for _, remote := range c.remotes {
+		if m.remotes[remote] == c {
+			delete(m.remotes, remote)
+		}
+	}
+	c.remotes = nil
+}
+
+// --------------------------------------
+
+type shardedPacket struct {
+	buf  *[]byte
+	n    int
+	addr net.Addr
+}
+
+// shardedConn is the connection of one ICE agent on a shardedUDPMux
+type shardedConn struct {
+	mux   *shardedUDPMux
+	ufrag string
+	// socket the first STUN packet arrived on, -1 before
+	shard atomic.Int32
+	// remote addresses routed to this connection, guarded by the lock of the mux
+	remotes []string
+
+	packets      chan shardedPacket
+	readDeadline *deadline.Deadline
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newShardedConn(m *shardedUDPMux, ufrag string) *shardedConn {
+	c := &shardedConn{
+		mux:          m,
+		ufrag:        ufrag,
+		packets:      make(chan shardedPacket, shardConnQueueSize),
+		readDeadline: deadline.New(),
+		closed:       make(chan struct{}),
+	}
+	c.shard.Store(-1)
+	return c
+}
+
+func (c *shardedConn) deliver(b []byte, addr net.Addr) {
+	buf := shardPacketPool.Get().(*[]byte)
+	n := copy(*buf, b)
+	select {
+	case c.packets <- shardedPacket{buf: buf, n: n, addr: addr}:
+	default:
+		shardPacketPool.Put(buf)
+	}
+}
+
+func (c *shardedConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	select {
+	case <-c.closed:
+		return 0, nil, io.EOF
+	default:
+	}
+
+	select {
+	case p := <-c.packets:
+		n := copy(b, (*p.buf)[:p.n])
+		shardPacketPool.Put(p.buf)
+		if n < p.n {
+			return n, p.addr, io.ErrShortBuffer
+		}
+		return n, p.addr, nil
+
+	case <-c.readDeadline.Done():
+		return 0, nil, os.ErrDeadlineExceeded
+
+	case <-c.closed:
+		return 0, nil, io.EOF
+	}
+}
+
+func (c *shardedConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	select {
+	case <-c.closed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+
+	shard := c.shard.Load()
+	if shard < 0 {
+		shard = 0
+	}
+	return c.mux.sockets[shard].WriteTo(b, addr)
+}
+
+func (c *shardedConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		c.mux.removeConn(c)
+	})
+	return nil
+}
+
+func (c *shardedConn) LocalAddr() net.Addr {
+	return c.mux.sockets[0].LocalAddr()
+}
+
+func (c *shardedConn) SetDeadline(t time.Time) error {
+	return c.SetReadDeadline(t)
+}
+
+func (c *shardedConn) SetReadDeadline(t time.Time) error {
+	c.readDeadline.Set(t)
+	return nil
+}
+
+func (c *shardedConn) SetWriteDeadline(time.Time) error {
+	// the socket is shared with the other connections, like the unsharded mux
+	return nil
+}
@@ -0,0 +1,28 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package rtc
+
+import "syscall"
+
+// other platforms either lack SO_REUSEPORT or do not balance unicast flows across the sockets
+const reusePortSupported = false
+
+func reusePortControl(_, _ string, _ syscall.RawConn) error {
+	return ErrUDPMuxShardingUnsupported
+}
@@ -0,0 +1,118 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pion/stun/v3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
+	"github.com/livekit/protocol/logger"
+)
+
+func TestShardedUDPMux(t *testing.T) {
+	if !reusePortSupported {
+		t.Skip("SO_REUSEPORT unavailable")
+	}
+
+	m, err := listenShardedUDPMux(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)}, 3, rtcconfig.BatchIOConfig{}, logger.GetLogger())
+	require.NoError(t, err)
+	defer m.Close()
+
+	// same port, read by three sockets
+	require.Len(t, m.sockets, 3)
+	local := m.GetListenAddresses()[0]
+	for _, socket := range m.sockets {
+		require.Equal(t, local.String(), socket.LocalAddr().String())
+	}
+
+	conn, err := m.GetConn("ufrag", local)
+	require.NoError(t, err)
+	c := conn.(*shardedConn)
+	require.Equal(t, int32(-1), c.shard.Load())
+
+	remote, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer remote.Close()
+
+	// unknown remote addresses are dropped until a STUN packet names the ufrag
+	_, err = remote.WriteTo([]byte{1}, local)
+	require.NoError(t, err)
+
+	request := stun.MustBuild(stun.TransactionID, stun.BindingRequest, stun.NewUsername("ufrag:remote"))
+	_, err = remote.WriteTo(request.Raw, local)
+	require.NoError(t, err)
+
+	buf := make([]byte, 1500)
+	require.NoError(t, c.SetReadDeadline(time.Now().Add(time.Second)))
+	n, addr, err := c.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, request.Raw, buf[:n])
+	require.Equal(t, remote.LocalAddr().String(), addr.String())
+	shard := c.shard.Load()
+	require.GreaterOrEqual(t, shard, int32(0))
+
+	// later packets of the address are routed without STUN
+	_, err = remote.WriteTo([]byte{2}, local)
+	require.NoError(t, err)
+	n, _, err = c.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2}, buf[:n])
+
+	// answers go out through the pinned socket
+	_, err = c.WriteTo([]byte{3}, remote.LocalAddr())
+	require.NoError(t, err)
+	require.NoError(t, remote.SetReadDeadline(time.Now().Add(time.Second)))
+	n, addr, err = remote.ReadFrom(buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{3}, buf[:n])
+	require.Equal(t, local.String(), addr.String())
+
+	// a new deadline wakes a blocked read
+	require.NoError(t, c.SetReadDeadline(time.Time{}))
+	readErr := make(chan error, 1)
+	go func() {
+		_, _, err := c.ReadFrom(buf)
+		readErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, c.SetReadDeadline(time.Now()))
+	select {
+	case err := <-readErr:
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	case <-time.After(time.Second):
+		t.Fatal("blocked read not woken by deadline")
+	}
+
+	// closing removes the ufrag and its addresses
+	m.RemoveConnByUfrag("ufrag")
+	_, _, err = c.ReadFrom(buf)
+	require.ErrorIs(t, err, io.EOF)
+	m.lock.RLock()
+	require.Empty(t, m.conns)
+	require.Empty(t, m.remotes)
+	m.lock.RUnlock()
+
+	require.NoError(t, m.Close())
+	_, err = m.GetConn("ufrag", local)
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+}
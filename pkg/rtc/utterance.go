@@ -0,0 +1,68 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// topic of the data packets reporting utterances of participants starting and ending
+	UtteranceTopic = "agentix.utterance"
+
+	UtteranceEventStarted = "UtteranceStarted"
+	UtteranceEventEnded   = "UtteranceEnded"
+)
+
+// UtteranceEvent reports an utterance of a published audio track starting or ending, for agents deciding
+// when to respond. Ranges are trimmed to speech and given as RTP timestamps of the published stream.
+type UtteranceEvent struct {
+	Event               string                      `json:"event"`
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	// counts the utterances of the track, from 1, started and ended of an utterance share it
+	Sequence uint32 `json:"sequence"`
+	// RTP timestamp of the first sample and, for ended, one past the last sample of the utterance
+	StartRTPTimestamp uint32 `json:"start_rtp_timestamp"`
+	EndRTPTimestamp   uint32 `json:"end_rtp_timestamp,omitempty"`
+	ClockRate         uint32 `json:"clock_rate"`
+	// unix time in milliseconds the speech of the utterance started, and for ended was last heard
+	StartMs int64 `json:"start_ms"`
+	EndMs   int64 `json:"end_ms,omitempty"`
+	// for ended, "silence", "max_duration" or "stream_ended"
+	Reason audio.UtteranceEndReason `json:"reason,omitempty"`
+}
+
+func newUtteranceEvent(identity livekit.ParticipantIdentity, trackID livekit.TrackID, utterance *audio.UtteranceEvent) *UtteranceEvent {
+	event := &UtteranceEvent{
+		Event:               UtteranceEventStarted,
+		ParticipantIdentity: identity,
+		TrackID:             trackID,
+		Sequence:            utterance.Sequence,
+		StartRTPTimestamp:   utterance.StartTimestamp,
+		ClockRate:           utterance.ClockRate,
+		StartMs:             utterance.StartTime.UnixMilli(),
+	}
+	if !utterance.Started {
+		event.Event = UtteranceEventEnded
+		event.EndRTPTimestamp = utterance.EndTimestamp
+		event.EndMs = utterance.EndTime.UnixMilli()
+		event.Reason = utterance.Reason
+	}
+	return event
+}
@@ -0,0 +1,207 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtc
+
+import (
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// topic of the data packets carrying wake words spotted in published audio
+	WakeWordTopic = "agentix.wake_word"
+
+	wakeWordSubscriberPrefix = "WKW_"
+
+	// 120 ms at 16 kHz, the longest frame an Opus packet can carry
+	wakeWordMaxFrameSize = audio.WakeWordSampleRate * 120 / 1000
+)
+
+type WakeWordEvent struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	Phrase              string                      `json:"phrase"`
+	Score               float64                     `json:"score"`
+}
+
+type WakeWordMonitorParams struct {
+	Config      audio.WakeWordConfig
+	Logger      logger.Logger
+	OnDetection func(event *WakeWordEvent)
+	// workers detection runs on, detection runs on the forwarding path when nil
+	Placement     func() *placement.Slot
+	TrackPriority func(track types.MediaTrack) placement.Priority
+}
+
+// WakeWordMonitor decodes every published audio track of a room and reports the configured phrases
+// the wake word model spots in it.
+type WakeWordMonitor struct {
+	params WakeWordMonitorParams
+
+	lock sync.Mutex
+	taps map[livekit.TrackID]*wakeWordTap
+}
+
+func NewWakeWordMonitor(params WakeWordMonitorParams) *WakeWordMonitor {
+	return &WakeWordMonitor{
+		params: params,
+		taps:   make(map[livekit.TrackID]*wakeWordTap),
+	}
+}
+
+func (m *WakeWordMonitor) AddTrack(track types.MediaTrack) {
+	if m == nil || track.Kind() != livekit.TrackType_AUDIO {
+		return
+	}
+
+	receiver := opusReceiver(track)
+	if receiver == nil {
+		return
+	}
+
+	decoder, err := audio.NewOpusDecoder(audio.WakeWordSampleRate, 1)
+	if err != nil {
+		m.params.Logger.Warnw("could not create decoder for wake word detection", err, "trackID", track.ID())
+		return
+	}
+	detector, err := audio.NewWakeWordDetector(m.params.Config)
+	if err != nil {
+		m.params.Logger.Warnw("could not create wake word detector", err, "trackID", track.ID())
+		return
+	}
+
+	tap := &wakeWordTap{
+		monitor:  m,
+		identity: track.PublisherIdentity(),
+		spotter:  audio.NewWakeWordSpotter(m.params.Config, detector),
+		decoder:  decoder,
+		pcm:      make([]int16, wakeWordMaxFrameSize),
+	}
+	tap.receiverTap = newReceiverTap(wakeWordSubscriberPrefix, track.ID(), receiver, tap.onPacket)
+	tap.accountMemory(cap(tap.pcm)*2, audio.OpusDecoderNativeBytes(1))
+	if m.params.Placement != nil {
+		tap.schedule(m.params.Placement(), func() placement.Priority { return m.params.TrackPriority(track) })
+	}
+
+	m.lock.Lock()
+	if _, ok := m.taps[track.ID()]; ok {
+		m.lock.Unlock()
+		tap.spotter.Close()
+		return
+	}
+	m.taps[track.ID()] = tap
+	m.lock.Unlock()
+
+	if err := tap.start(); err != nil {
+		m.params.Logger.Warnw("could not tap receiver for wake word detection", err, "trackID", track.ID())
+		m.RemoveTrack(track.ID())
+	}
+}
+
+func (m *WakeWordMonitor) RemoveTrack(trackID livekit.TrackID) {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	tap, ok := m.taps[trackID]
+	delete(m.taps, trackID)
+	m.lock.Unlock()
+
+	if ok {
+		tap.close()
+	}
+}
+
+func (m *WakeWordMonitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	taps := m.taps
+	m.taps = make(map[livekit.TrackID]*wakeWordTap)
+	m.lock.Unlock()
+
+	for _, tap := range taps {
+		tap.close()
+	}
+}
+
+// --------------------------------------
+
+type wakeWordTap struct {
+	*receiverTap
+
+	monitor  *WakeWordMonitor
+	identity livekit.ParticipantIdentity
+	decoder  audio.OpusDecoder
+	pcm      []int16
+
+	// guards the spotter against packets still being processed while the tap closes
+	lock    sync.Mutex
+	spotter *audio.WakeWordSpotter
+	closed  bool
+}
+
+func (t *wakeWordTap) onPacket(p *buffer.ExtPacket) {
+	n, err := t.decoder.Decode(p.Packet.Payload, t.pcm)
+	if err != nil {
+		return
+	}
+
+	t.lock.Lock()
+	if t.closed {
+		t.lock.Unlock()
+		return
+	}
+	detections := t.spotter.Process(time.Now(), t.pcm[:n])
+	t.lock.Unlock()
+
+	if len(detections) == 0 || t.monitor.params.OnDetection == nil {
+		return
+	}
+	// do not hold up forwarding
+	go func() {
+		for _, d := range detections {
+			t.monitor.params.OnDetection(&WakeWordEvent{
+				ParticipantIdentity: t.identity,
+				TrackID:             t.trackID,
+				Phrase:              d.Phrase,
+				Score:               d.Score,
+			})
+		}
+	}()
+}
+
+func (t *wakeWordTap) close() {
+	t.stop()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if !t.closed {
+		t.closed = true
+		t.spotter.Close()
+	}
+}
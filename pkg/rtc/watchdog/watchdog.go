@@ -0,0 +1,234 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchdog
+
+import (
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how often tracks are checked
+	CheckInterval time.Duration `yaml:"check_interval,omitempty"`
+	// how long a track has to be stuck before recovery starts
+	StallTimeout time.Duration `yaml:"stall_timeout,omitempty"`
+	// time given to a recovery action before escalating to the next one
+	RecoveryInterval time.Duration `yaml:"recovery_interval,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		CheckInterval:    time.Second,
+		StallTimeout:     3 * time.Second,
+		RecoveryInterval: 5 * time.Second,
+	}
+)
+
+// --------------------------------------
+
+type Stall int
+
+const (
+	StallNone Stall = iota
+	// RTP keeps arriving but the media timestamp does not advance, e. g. a hung encoder
+	StallFrozenTimestamps
+	// the publisher keeps sending RTCP sender reports but no RTP
+	StallNoRTP
+)
+
+func (s Stall) String() string {
+	switch s {
+	case StallNone:
+		return "none"
+	case StallFrozenTimestamps:
+		return "frozen_timestamps"
+	case StallNoRTP:
+		return "no_rtp"
+	default:
+		return "unknown"
+	}
+}
+
+func (s Stall) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionKeyframeRequest
+	ActionResubscribe
+	ActionTransportRestart
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionNone:
+		return "none"
+	case ActionKeyframeRequest:
+		return "keyframe_request"
+	case ActionResubscribe:
+		return "resubscribe"
+	case ActionTransportRestart:
+		return "transport_restart"
+	default:
+		return "unknown"
+	}
+}
+
+func (a Action) MarshalText() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+var (
+	// cheapest first, a keyframe is of no use for audio
+	videoRecovery = []Action{ActionKeyframeRequest, ActionResubscribe, ActionTransportRestart}
+	audioRecovery = []Action{ActionResubscribe, ActionTransportRestart}
+)
+
+// Event is a recovery action to take for a stuck track, or the report that the track recovered
+type Event struct {
+	Stall      Stall
+	Action     Action
+	Attempt    int
+	StalledFor time.Duration
+	Recovered  bool
+}
+
+// --------------------------------------
+
+// Tracker follows the media of one published track and escalates through recovery actions
+// while the track is stuck
+type Tracker struct {
+	config   Config
+	recovery []Action
+
+	lock         sync.Mutex
+	activeSince  time.Time
+	paused       bool
+	havePacket   bool
+	lastPacketAt time.Time
+	lastTS       uint32
+	lastTSAt     time.Time
+
+	stall        Stall
+	stalledAt    time.Time
+	attempt      int
+	lastActionAt time.Time
+}
+
+func NewTracker(config Config, isVideo bool, now time.Time) *Tracker {
+	t := &Tracker{
+		config:      config,
+		recovery:    audioRecovery,
+		activeSince: now,
+	}
+	if isVideo {
+		t.recovery = videoRecovery
+	}
+	return t
+}
+
+func (t *Tracker) ObservePacket(at time.Time, ts uint32) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if !t.havePacket || ts != t.lastTS {
+		t.lastTS = ts
+		t.lastTSAt = at
+	}
+	t.havePacket = true
+	t.lastPacketAt = at
+}
+
+// Check evaluates the track, lastSenderReportAt is the time of the last RTCP sender report of the track.
+// Returns the next recovery action when the track is stuck, nil if there is nothing to do.
+func (t *Tracker) Check(now time.Time, lastSenderReportAt time.Time, paused bool) *Event {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if paused || t.paused {
+		// a muted or paused track is expected to stop, give it time to restart when resumed
+		t.paused = paused
+		t.activeSince = now
+		t.resetLocked()
+		return nil
+	}
+
+	stall := t.detectLocked(now, lastSenderReportAt)
+	if stall == StallNone {
+		if t.stall == StallNone {
+			return nil
+		}
+		event := &Event{
+			Stall:      t.stall,
+			Attempt:    t.attempt,
+			StalledFor: now.Sub(t.stalledAt),
+			Recovered:  true,
+		}
+		t.resetLocked()
+		return event
+	}
+
+	if stall != t.stall {
+		t.stall = stall
+		t.stalledAt = now
+		t.attempt = 0
+		t.lastActionAt = time.Time{}
+	}
+	if t.attempt >= len(t.recovery) || (!t.lastActionAt.IsZero() && now.Sub(t.lastActionAt) < t.config.RecoveryInterval) {
+		return nil
+	}
+
+	event := &Event{
+		Stall:      stall,
+		Action:     t.recovery[t.attempt],
+		Attempt:    t.attempt + 1,
+		StalledFor: now.Sub(t.stalledAt),
+	}
+	t.attempt++
+	t.lastActionAt = now
+	return event
+}
+
+func (t *Tracker) detectLocked(now time.Time, lastSenderReportAt time.Time) Stall {
+	if now.Sub(t.activeSince) < t.config.StallTimeout {
+		return StallNone
+	}
+
+	flowing := t.havePacket && now.Sub(t.lastPacketAt) < t.config.StallTimeout
+	if flowing {
+		if now.Sub(t.lastTSAt) >= t.config.StallTimeout {
+			return StallFrozenTimestamps
+		}
+		return StallNone
+	}
+
+	// without RTCP either, the publisher is gone, which is up to transport failure detection
+	if !lastSenderReportAt.IsZero() && now.Sub(lastSenderReportAt) < t.config.StallTimeout {
+		return StallNoRTP
+	}
+	return StallNone
+}
+
+func (t *Tracker) resetLocked() {
+	t.stall = StallNone
+	t.stalledAt = time.Time{}
+	t.attempt = 0
+	t.lastActionAt = time.Time{}
+}
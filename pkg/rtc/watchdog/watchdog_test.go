@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watchdog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackerFrozenTimestamps(t *testing.T) {
+	start := time.Now()
+	tr := NewTracker(DefaultConfig, true, start)
+
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	// healthy
+	for i := 0; i < 40; i++ {
+		tr.ObservePacket(at(time.Duration(i)*100*time.Millisecond), uint32(i*9000))
+	}
+	require.Nil(t, tr.Check(at(4*time.Second), time.Time{}, false))
+
+	// packets keep coming with the same timestamp
+	frozenUntil := func(d time.Duration) {
+		for i := 40; i <= int(d/(100*time.Millisecond)); i++ {
+			tr.ObservePacket(at(time.Duration(i)*100*time.Millisecond), 39*9000)
+		}
+	}
+	frozenUntil(12 * time.Second)
+	event := tr.Check(at(12*time.Second), time.Time{}, false)
+	require.NotNil(t, event)
+	require.Equal(t, StallFrozenTimestamps, event.Stall)
+	require.Equal(t, ActionKeyframeRequest, event.Action)
+	require.Equal(t, 1, event.Attempt)
+
+	// waits before escalating
+	frozenUntil(13 * time.Second)
+	require.Nil(t, tr.Check(at(13*time.Second), time.Time{}, false))
+	frozenUntil(17 * time.Second)
+	event = tr.Check(at(17*time.Second), time.Time{}, false)
+	require.NotNil(t, event)
+	require.Equal(t, ActionResubscribe, event.Action)
+	frozenUntil(22 * time.Second)
+	event = tr.Check(at(22*time.Second), time.Time{}, false)
+	require.NotNil(t, event)
+	require.Equal(t, ActionTransportRestart, event.Action)
+	require.Equal(t, 3, event.Attempt)
+
+	// out of actions
+	frozenUntil(30 * time.Second)
+	require.Nil(t, tr.Check(at(30*time.Second), time.Time{}, false))
+
+	// recovers
+	tr.ObservePacket(at(31*time.Second), 1_000_000)
+	event = tr.Check(at(31*time.Second), time.Time{}, false)
+	require.NotNil(t, event)
+	require.True(t, event.Recovered)
+	require.Equal(t, StallFrozenTimestamps, event.Stall)
+	require.Nil(t, tr.Check(at(32*time.Second), time.Time{}, false))
+}
+
+func TestTrackerNoRTP(t *testing.T) {
+	start := time.Now()
+	tr := NewTracker(DefaultConfig, false, start)
+
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tr.ObservePacket(at(time.Second), 960)
+
+	// neither RTP nor RTCP, not a track problem
+	require.Nil(t, tr.Check(at(10*time.Second), time.Time{}, false))
+
+	// RTCP without RTP
+	event := tr.Check(at(11*time.Second), at(10*time.Second), false)
+	require.NotNil(t, event)
+	require.Equal(t, StallNoRTP, event.Stall)
+	require.Equal(t, ActionResubscribe, event.Action)
+}
+
+func TestTrackerPaused(t *testing.T) {
+	start := time.Now()
+	tr := NewTracker(DefaultConfig, false, start)
+
+	at := func(d time.Duration) time.Time { return start.Add(d) }
+
+	tr.ObservePacket(at(time.Second), 960)
+	require.Nil(t, tr.Check(at(10*time.Second), at(9*time.Second), true))
+
+	// resumed, given time to resume
+	require.Nil(t, tr.Check(at(20*time.Second), at(19*time.Second), false))
+	require.Nil(t, tr.Check(at(22*time.Second), at(21*time.Second), false))
+	require.NotNil(t, tr.Check(at(23*time.Second), at(22*time.Second), false))
+}
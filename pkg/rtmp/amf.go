@@ -0,0 +1,217 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+)
+
+// AMF0 type markers
+const (
+	amfNumber      = 0x00
+	amfBoolean     = 0x01
+	amfString      = 0x02
+	amfObject      = 0x03
+	amfNull        = 0x05
+	amfUndefined   = 0x06
+	amfECMAArray   = 0x08
+	amfObjectEnd   = 0x09
+	amfStrictArray = 0x0a
+	amfDate        = 0x0b
+	amfLongString  = 0x0c
+)
+
+var (
+	ErrInvalidAMF = errors.New("invalid amf0 value")
+)
+
+// amfObjectValue is an AMF0 object or ECMA array
+type amfObjectValue map[string]any
+
+// decodeAMF returns the values of an AMF0 message as float64, bool, string, amfObjectValue, []any and nil
+func decodeAMF(b []byte) ([]any, error) {
+	var values []any
+	for len(b) > 0 {
+		v, n, err := decodeAMFValue(b, 0)
+		if err != nil {
+			return values, err
+		}
+		values = append(values, v)
+		b = b[n:]
+	}
+	return values, nil
+}
+
+func decodeAMFValue(b []byte, depth int) (any, int, error) {
+	if len(b) == 0 || depth > 16 {
+		return nil, 0, ErrInvalidAMF
+	}
+
+	switch b[0] {
+	case amfNumber:
+		if len(b) < 9 {
+			return nil, 0, ErrInvalidAMF
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b[1:])), 9, nil
+
+	case amfBoolean:
+		if len(b) < 2 {
+			return nil, 0, ErrInvalidAMF
+		}
+		return b[1] != 0, 2, nil
+
+	case amfString:
+		s, n, err := decodeAMFString(b[1:], 2)
+		return s, n + 1, err
+
+	case amfLongString:
+		s, n, err := decodeAMFString(b[1:], 4)
+		return s, n + 1, err
+
+	case amfNull, amfUndefined:
+		return nil, 1, nil
+
+	case amfObject:
+		o, n, err := decodeAMFProperties(b[1:], depth)
+		return o, n + 1, err
+
+	case amfECMAArray:
+		// the count is a hint, the properties end like those of an object
+		if len(b) < 5 {
+			return nil, 0, ErrInvalidAMF
+		}
+		o, n, err := decodeAMFProperties(b[5:], depth)
+		return o, n + 5, err
+
+	case amfStrictArray:
+		if len(b) < 5 {
+			return nil, 0, ErrInvalidAMF
+		}
+		count := int(binary.BigEndian.Uint32(b[1:]))
+		offset := 5
+		var a []any
+		for i := 0; i < count; i++ {
+			v, n, err := decodeAMFValue(b[offset:], depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a = append(a, v)
+			offset += n
+		}
+		return a, offset, nil
+
+	case amfDate:
+		if len(b) < 11 {
+			return nil, 0, ErrInvalidAMF
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(b[1:])), 11, nil
+	}
+	return nil, 0, ErrInvalidAMF
+}
+
+func decodeAMFString(b []byte, lengthSize int) (string, int, error) {
+	if len(b) < lengthSize {
+		return "", 0, ErrInvalidAMF
+	}
+	var n int
+	if lengthSize == 2 {
+		n = int(binary.BigEndian.Uint16(b))
+	} else {
+		n = int(binary.BigEndian.Uint32(b))
+	}
+	if n > len(b)-lengthSize {
+		return "", 0, ErrInvalidAMF
+	}
+	return string(b[lengthSize : lengthSize+n]), lengthSize + n, nil
+}
+
+func decodeAMFProperties(b []byte, depth int) (amfObjectValue, int, error) {
+	o := amfObjectValue{}
+	offset := 0
+	for {
+		key, n, err := decodeAMFString(b[offset:], 2)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset += n
+		if key == "" {
+			if offset >= len(b) || b[offset] != amfObjectEnd {
+				return nil, 0, ErrInvalidAMF
+			}
+			return o, offset + 1, nil
+		}
+
+		v, n, err := decodeAMFValue(b[offset:], depth+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		o[key] = v
+		offset += n
+	}
+}
+
+// appendAMF appends values of the types decodeAMF returns, ints are sent as numbers
+func appendAMF(b []byte, values ...any) []byte {
+	for _, v := range values {
+		switch v := v.(type) {
+		case float64:
+			b = append(b, amfNumber)
+			b = binary.BigEndian.AppendUint64(b, math.Float64bits(v))
+		case int:
+			b = appendAMF(b, float64(v))
+		case bool:
+			b = append(b, amfBoolean)
+			if v {
+				b = append(b, 1)
+			} else {
+				b = append(b, 0)
+			}
+		case string:
+			if len(v) > math.MaxUint16 {
+				b = append(b, amfLongString)
+				b = binary.BigEndian.AppendUint32(b, uint32(len(v)))
+			} else {
+				b = append(b, amfString)
+				b = binary.BigEndian.AppendUint16(b, uint16(len(v)))
+			}
+			b = append(b, v...)
+		case amfObjectValue:
+			b = append(b, amfObject)
+			// sorted for stable messages
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				b = binary.BigEndian.AppendUint16(b, uint16(len(key)))
+				b = append(b, key...)
+				b = appendAMF(b, v[key])
+			}
+			b = append(b, 0, 0, amfObjectEnd)
+		case []any:
+			b = append(b, amfStrictArray)
+			b = binary.BigEndian.AppendUint32(b, uint32(len(v)))
+			b = appendAMF(b, v...)
+		default:
+			b = append(b, amfNull)
+		}
+	}
+	return b
+}
@@ -0,0 +1,269 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtmp
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// message types
+const (
+	msgSetChunkSize     = 1
+	msgAbort            = 2
+	msgAcknowledgement  = 3
+	msgUserControl      = 4
+	msgWindowAckSize    = 5
+	msgSetPeerBandwidth = 6
+	msgAudio            = 8
+	msgVideo            = 9
+	msgDataAMF3         = 15
+	msgCommandAMF3      = 17
+	msgDataAMF0         = 18
+	msgCommandAMF0      = 20
+)
+
+const (
+	defaultChunkSize = 128
+	maxChunkSize     = 1 << 24
+	// largest message accepted, well above the keyframes of broadcast bitrates
+	maxMessageSize = 8 << 20
+
+	extendedTimestamp = 0xffffff
+)
+
+var (
+	ErrInvalidChunk    = errors.New("invalid rtmp chunk")
+	ErrMessageTooLarge = errors.New("rtmp message too large")
+)
+
+type message struct {
+	Type      uint8
+	StreamID  uint32
+	Timestamp uint32
+	Payload   []byte
+}
+
+// chunkStream is the state of a chunk stream id, the headers of later chunks are deltas to it
+type chunkStream struct {
+	timestamp      uint32
+	timestampDelta uint32
+	extended       bool
+	length         int
+	typ            uint8
+	streamID       uint32
+	// payload of the message being received, nil between messages
+	payload []byte
+}
+
+type chunkReader struct {
+	r         *bufio.Reader
+	chunkSize int
+	streams   map[uint32]*chunkStream
+	header    [11]byte
+	// bytes read, for acknowledgements
+	read uint64
+}
+
+func newChunkReader(r *bufio.Reader) *chunkReader {
+	return &chunkReader{
+		r:         r,
+		chunkSize: defaultChunkSize,
+		streams:   make(map[uint32]*chunkStream),
+	}
+}
+
+// readMessage reads chunks until a message is complete
+func (c *chunkReader) readMessage() (*message, error) {
+	for {
+		msg, err := c.readChunk()
+		if err != nil || msg != nil {
+			return msg, err
+		}
+	}
+}
+
+func (c *chunkReader) readChunk() (*message, error) {
+	b, err := c.readByte()
+	if err != nil {
+		return nil, err
+	}
+	format := b >> 6
+	csid := uint32(b & 0x3f)
+	switch csid {
+	case 0:
+		if _, err := c.readFull(c.header[:1]); err != nil {
+			return nil, err
+		}
+		csid = 64 + uint32(c.header[0])
+	case 1:
+		if _, err := c.readFull(c.header[:2]); err != nil {
+			return nil, err
+		}
+		csid = 64 + uint32(c.header[0]) + uint32(c.header[1])<<8
+	}
+
+	cs := c.streams[csid]
+	if cs == nil {
+		if format != 0 {
+			return nil, ErrInvalidChunk
+		}
+		cs = &chunkStream{}
+		c.streams[csid] = cs
+	}
+
+	headerSize := [4]int{11, 7, 3, 0}[format]
+	h := c.header[:headerSize]
+	if _, err := c.readFull(h); err != nil {
+		return nil, err
+	}
+	if format <= 2 {
+		ts := uint32(h[0])<<16 | uint32(h[1])<<8 | uint32(h[2])
+		cs.extended = ts == extendedTimestamp
+		if format == 0 {
+			cs.timestamp = ts
+			cs.timestampDelta = 0
+		} else {
+			cs.timestampDelta = ts
+		}
+	}
+	if format <= 1 {
+		cs.length = int(h[3])<<16 | int(h[4])<<8 | int(h[5])
+		cs.typ = h[6]
+		if cs.length > maxMessageSize {
+			return nil, ErrMessageTooLarge
+		}
+	}
+	if format == 0 {
+		cs.streamID = binary.LittleEndian.Uint32(h[7:11])
+	}
+	if cs.extended {
+		// also repeated by type 3 chunks continuing a message with an extended timestamp
+		var ext [4]byte
+		if _, err := c.readFull(ext[:]); err != nil {
+			return nil, err
+		}
+		if format == 0 {
+			cs.timestamp = binary.BigEndian.Uint32(ext[:])
+		} else if format != 3 {
+			cs.timestampDelta = binary.BigEndian.Uint32(ext[:])
+		}
+	}
+
+	if cs.payload == nil {
+		// a new message, deltas apply to the timestamp of the previous one
+		if format != 0 {
+			cs.timestamp += cs.timestampDelta
+		}
+		cs.payload = make([]byte, 0, cs.length)
+	}
+
+	n := min(c.chunkSize, cs.length-len(cs.payload))
+	start := len(cs.payload)
+	cs.payload = cs.payload[:start+n]
+	if _, err := c.readFull(cs.payload[start:]); err != nil {
+		return nil, err
+	}
+	if len(cs.payload) < cs.length {
+		return nil, nil
+	}
+
+	msg := &message{
+		Type:      cs.typ,
+		StreamID:  cs.streamID,
+		Timestamp: cs.timestamp,
+		Payload:   cs.payload,
+	}
+	cs.payload = nil
+	return msg, nil
+}
+
+func (c *chunkReader) readByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.read++
+	}
+	return b, err
+}
+
+func (c *chunkReader) readFull(b []byte) (int, error) {
+	n, err := io.ReadFull(c.r, b)
+	c.read += uint64(n)
+	return n, err
+}
+
+// --------------------------------------
+
+// chunk stream ids of the messages the server sends
+const (
+	csidControl = 2
+	csidCommand = 3
+)
+
+type chunkWriter struct {
+	w         *bufio.Writer
+	chunkSize int
+}
+
+func newChunkWriter(w *bufio.Writer) *chunkWriter {
+	return &chunkWriter{
+		w:         w,
+		chunkSize: defaultChunkSize,
+	}
+}
+
+// writeMessage sends a message in chunks of the chunk size, with a type 0 header followed by type 3 ones
+func (c *chunkWriter) writeMessage(csid uint32, msg *message) error {
+	var h [12]byte
+	h[0] = byte(csid)
+	ts := min(msg.Timestamp, extendedTimestamp)
+	h[1], h[2], h[3] = byte(ts>>16), byte(ts>>8), byte(ts)
+	n := len(msg.Payload)
+	h[4], h[5], h[6] = byte(n>>16), byte(n>>8), byte(n)
+	h[7] = msg.Type
+	binary.LittleEndian.PutUint32(h[8:], msg.StreamID)
+	if _, err := c.w.Write(h[:]); err != nil {
+		return err
+	}
+	if ts == extendedTimestamp {
+		if err := binary.Write(c.w, binary.BigEndian, msg.Timestamp); err != nil {
+			return err
+		}
+	}
+
+	payload := msg.Payload
+	for {
+		n := min(c.chunkSize, len(payload))
+		if _, err := c.w.Write(payload[:n]); err != nil {
+			return err
+		}
+		payload = payload[n:]
+		if len(payload) == 0 {
+			break
+		}
+		if err := c.w.WriteByte(3<<6 | byte(csid)); err != nil {
+			return err
+		}
+		if ts == extendedTimestamp {
+			if err := binary.Write(c.w, binary.BigEndian, msg.Timestamp); err != nil {
+				return err
+			}
+		}
+	}
+	return c.w.Flush()
+}
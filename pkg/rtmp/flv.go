@@ -0,0 +1,181 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtmp
+
+import (
+	"errors"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+)
+
+// FLV codec ids of audio and video messages
+const (
+	soundFormatAAC      = 10
+	soundFormatExHeader = 9
+	videoCodecAVC       = 7
+
+	// packet types of AVC and AAC, and of enhanced RTMP, with the same values for sequence starts and frames
+	packetTypeSequenceStart = 0
+	packetTypeCodedFrames   = 1
+	// enhanced RTMP video frames without a composition time
+	packetTypeCodedFramesX = 3
+)
+
+var (
+	fourCCAVC  = [4]byte{'a', 'v', 'c', '1'}
+	fourCCOpus = [4]byte{'O', 'p', 'u', 's'}
+)
+
+var (
+	ErrInvalidMedia = errors.New("invalid rtmp media message")
+)
+
+// mediaReceiver unpacks the FLV tags of audio and video messages for a publisher. Audio is AAC, decoded when
+// a decoder is registered, or Opus of enhanced RTMP, passed through. Video is H.264, legacy or enhanced.
+type mediaReceiver struct {
+	publisher ingest.Publisher
+
+	avc *ingest.AVCConfig
+	au  []byte
+	aac *ingest.AACAudio
+	// audio codec of the stream, once announced
+	audioCodec string
+}
+
+// writeVideo returns ErrUnsupportedVideoCodec once for streams of other codecs, the video is dropped
+func (m *mediaReceiver) writeVideo(msg *message) error {
+	b := msg.Payload
+	if len(b) < 2 {
+		return ErrInvalidMedia
+	}
+
+	// the composition time is skipped, frames are sent in decode order and B-frames are not reordered
+	var packetType byte
+	if b[0]&0x80 != 0 {
+		// enhanced RTMP, the packet type in place of the codec id and a four cc
+		if len(b) < 5 {
+			return ErrInvalidMedia
+		}
+		if [4]byte(b[1:5]) != fourCCAVC {
+			return m.unsupportedVideo()
+		}
+		packetType = b[0] & 0x0f
+		b = b[5:]
+		if packetType == packetTypeCodedFrames {
+			if len(b) < 3 {
+				return ErrInvalidMedia
+			}
+			b = b[3:]
+		} else if packetType == packetTypeCodedFramesX {
+			packetType = packetTypeCodedFrames
+		}
+	} else {
+		if b[0]&0x0f != videoCodecAVC {
+			return m.unsupportedVideo()
+		}
+		if len(b) < 5 {
+			return ErrInvalidMedia
+		}
+		packetType = b[1]
+		b = b[5:]
+	}
+
+	switch packetType {
+	case packetTypeSequenceStart:
+		avc, err := ingest.ParseAVCConfig(b)
+		if err != nil {
+			return err
+		}
+		m.avc = avc
+		return nil
+
+	case packetTypeCodedFrames:
+		if m.avc == nil {
+			// frames before the sequence header cannot be decoded
+			return nil
+		}
+		var err error
+		if m.au, err = m.avc.AnnexB(b, m.au[:0]); err != nil {
+			return err
+		}
+		return m.publisher.WriteH264(m.au, time.Duration(msg.Timestamp)*time.Millisecond)
+	}
+	return nil
+}
+
+func (m *mediaReceiver) unsupportedVideo() error {
+	if m.avc == nil {
+		// reported once, the config stays empty as a marker
+		m.avc = &ingest.AVCConfig{}
+		return ingest.ErrUnsupportedVideoCodec
+	}
+	return nil
+}
+
+// writeAudio returns ErrUnsupportedAudioCodec or ErrAACDecoderUnavailable once when the audio is dropped
+func (m *mediaReceiver) writeAudio(msg *message) error {
+	b := msg.Payload
+	if len(b) < 2 {
+		return ErrInvalidMedia
+	}
+
+	switch b[0] >> 4 {
+	case soundFormatAAC:
+		switch b[1] {
+		case packetTypeSequenceStart:
+			config, err := ingest.ParseAACConfig(b[2:])
+			if err != nil {
+				return err
+			}
+			m.aac, err = ingest.NewAACAudio(config)
+			return m.setAudioCodec("aac", err)
+		case packetTypeCodedFrames:
+			if m.aac == nil {
+				return nil
+			}
+			return m.aac.Write(m.publisher, b[2:])
+		}
+
+	case soundFormatExHeader:
+		if len(b) < 5 || [4]byte(b[1:5]) != fourCCOpus {
+			return m.setAudioCodec("unsupported", ingest.ErrUnsupportedAudioCodec)
+		}
+		switch b[0] & 0x0f {
+		case packetTypeSequenceStart:
+			// the OpusHead carries nothing the track needs
+			return m.setAudioCodec("opus", nil)
+		case packetTypeCodedFrames:
+			if len(b) == 5 {
+				return nil
+			}
+			return m.publisher.WriteOpus(b[5:])
+		}
+
+	default:
+		return m.setAudioCodec("unsupported", ingest.ErrUnsupportedAudioCodec)
+	}
+	return nil
+}
+
+// setAudioCodec returns err only when the codec changed, so that dropped audio is reported once
+func (m *mediaReceiver) setAudioCodec(codec string, err error) error {
+	if m.audioCodec == codec {
+		return nil
+	}
+	m.audioCodec = codec
+	return err
+}
@@ -0,0 +1,520 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package rtmp receives streams of broadcast encoders, OBS, ffmpeg and hardware encoders, over RTMP and RTMPS.
+// It speaks the publishing side of the protocol only: the simple handshake, chunk streams, AMF0 commands and
+// FLV audio and video, H.264 with AAC or the Opus of enhanced RTMP. Streams are not played back to clients.
+package rtmp
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+)
+
+const (
+	rtmpVersion   = 3
+	handshakeSize = 1536
+
+	handshakeTimeout = 10 * time.Second
+	// encoders send media continuously, a connection silent this long is dead
+	readTimeout  = 30 * time.Second
+	writeTimeout = 5 * time.Second
+
+	// sizes the server announces once connected
+	serverChunkSize = 4096
+	serverWindowAck = 2500000
+	// message stream of the publish, the only one created per connection
+	publishStreamID = 1
+)
+
+var (
+	ErrInvalidHandshake = errors.New("invalid rtmp handshake")
+	ErrInvalidCommand   = errors.New("invalid rtmp command")
+	ErrTooManyStreams   = errors.New("too many rtmp streams")
+	ErrUnpublished      = errors.New("stream unpublished")
+	ErrTLSConfig        = errors.New("rtmps needs both cert_file and key_file")
+)
+
+// Config enables a listener encoders publish streams into rooms with
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// TCP port RTMP is received on
+	Port int `yaml:"port,omitempty"`
+	// certificate and key in PEM files, the port speaks RTMPS instead of RTMP when set
+	CertFile string `yaml:"cert_file,omitempty"`
+	KeyFile  string `yaml:"key_file,omitempty"`
+	// the tracks of a stream whose encoder disconnected stay published this long for it to reconnect
+	ReconnectTimeout time.Duration `yaml:"reconnect_timeout,omitempty"`
+	// streams of the node at a time
+	MaxStreams int `yaml:"max_streams,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Port:             1935,
+		ReconnectTimeout: 10 * time.Second,
+		MaxStreams:       20,
+	}
+)
+
+type ServerParams struct {
+	Config Config
+	Logger logger.Logger
+	// authorizes a stream and returns where its media goes, an error rejects it. app is the path of the URL the
+	// encoder connected to and streamKey the name it published, what encoders call the stream key. Called from
+	// the goroutine of the connection.
+	OnPublish func(app string, streamKey string) (ingest.Publisher, error)
+}
+
+// Server accepts the connections of encoders
+type Server struct {
+	params    ServerParams
+	tlsConfig *tls.Config
+	listener  net.Listener
+
+	lock    sync.Mutex
+	conns   map[*conn]struct{}
+	streams int
+	stopped core.Fuse
+}
+
+func NewServer(params ServerParams) (*Server, error) {
+	s := &Server{
+		params: params,
+		conns:  make(map[*conn]struct{}),
+	}
+	if params.Config.CertFile != "" || params.Config.KeyFile != "" {
+		if params.Config.CertFile == "" || params.Config.KeyFile == "" {
+			return nil, ErrTLSConfig
+		}
+		cert, err := tls.LoadX509KeyPair(params.Config.CertFile, params.Config.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		s.tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}
+	}
+	return s, nil
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", s.params.Config.Port))
+	if err != nil {
+		return err
+	}
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+	s.listener = listener
+
+	s.params.Logger.Infow("rtmp ingest listening", "port", listener.Addr().(*net.TCPAddr).Port, "tls", s.tlsConfig != nil)
+	go s.acceptWorker()
+	return nil
+}
+
+// Stop disconnects all encoders
+func (s *Server) Stop() {
+	s.lock.Lock()
+	s.stopped.Break()
+	conns := make([]*conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+	s.lock.Unlock()
+
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	for _, c := range conns {
+		_ = c.netConn.Close()
+	}
+}
+
+// Addr returns the address RTMP is received on
+func (s *Server) Addr() net.Addr {
+	return s.listener.Addr()
+}
+
+// NumStreams returns the streams being published
+func (s *Server) NumStreams() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.streams
+}
+
+func (s *Server) acceptWorker() {
+	for {
+		netConn, err := s.listener.Accept()
+		if err != nil {
+			if !s.stopped.IsBroken() {
+				s.params.Logger.Errorw("rtmp accept failed", err)
+			}
+			return
+		}
+
+		c := newConn(s, netConn)
+		s.lock.Lock()
+		if s.stopped.IsBroken() {
+			s.lock.Unlock()
+			_ = netConn.Close()
+			return
+		}
+		s.conns[c] = struct{}{}
+		s.lock.Unlock()
+
+		go c.run()
+	}
+}
+
+func (s *Server) addStream() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.params.Config.MaxStreams > 0 && s.streams >= s.params.Config.MaxStreams {
+		return ErrTooManyStreams
+	}
+	s.streams++
+	return nil
+}
+
+func (s *Server) removeStream() {
+	s.lock.Lock()
+	s.streams--
+	s.lock.Unlock()
+}
+
+func (s *Server) removeConn(c *conn) {
+	s.lock.Lock()
+	delete(s.conns, c)
+	s.lock.Unlock()
+}
+
+// --------------------------------------
+
+// conn is the connection of an encoder, publishing at most one stream
+type conn struct {
+	server  *Server
+	netConn net.Conn
+	logger  logger.Logger
+	bw      *bufio.Writer
+	r       *chunkReader
+	w       *chunkWriter
+
+	windowAckSize uint64
+	acked         uint64
+
+	app        string
+	streamKey  string
+	publishing bool
+	media      mediaReceiver
+}
+
+func newConn(s *Server, netConn net.Conn) *conn {
+	bw := bufio.NewWriter(netConn)
+	return &conn{
+		server:  s,
+		netConn: netConn,
+		logger:  s.params.Logger.WithValues("remote", netConn.RemoteAddr().String()),
+		bw:      bw,
+		r:       newChunkReader(bufio.NewReaderSize(netConn, 64*1024)),
+		w:       newChunkWriter(bw),
+	}
+}
+
+func (c *conn) run() {
+	err := c.serve()
+	if c.publishing {
+		c.media.publisher.Close()
+		c.server.removeStream()
+	}
+	c.server.removeConn(c)
+	_ = c.netConn.Close()
+
+	switch {
+	case c.publishing:
+		c.logger.Infow("rtmp stream ended", "app", c.app, "reason", err)
+	case err != nil && !errors.Is(err, io.EOF):
+		c.logger.Debugw("rtmp connection failed", "error", err)
+	}
+}
+
+func (c *conn) serve() error {
+	_ = c.netConn.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := c.handshake(); err != nil {
+		return err
+	}
+	_ = c.netConn.SetDeadline(time.Time{})
+
+	for {
+		_ = c.netConn.SetReadDeadline(time.Now().Add(readTimeout))
+		msg, err := c.r.readMessage()
+		if err != nil {
+			return err
+		}
+		if err := c.acknowledge(); err != nil {
+			return err
+		}
+		if err := c.handleMessage(msg); err != nil {
+			return err
+		}
+	}
+}
+
+// handshake is the simple one of the specification, encoders do not validate the digests of the complex one
+func (c *conn) handshake() error {
+	c0c1 := make([]byte, 1+handshakeSize)
+	if _, err := c.r.readFull(c0c1); err != nil {
+		return err
+	}
+	if c0c1[0] != rtmpVersion {
+		return ErrInvalidHandshake
+	}
+
+	s0s1s2 := make([]byte, 1+2*handshakeSize)
+	s0s1s2[0] = rtmpVersion
+	// time and zero followed by random bytes
+	if _, err := rand.Read(s0s1s2[9 : 1+handshakeSize]); err != nil {
+		return err
+	}
+	copy(s0s1s2[1+handshakeSize:], c0c1[1:])
+	if _, err := c.bw.Write(s0s1s2); err != nil {
+		return err
+	}
+	if err := c.bw.Flush(); err != nil {
+		return err
+	}
+
+	c2 := make([]byte, handshakeSize)
+	_, err := c.r.readFull(c2)
+	return err
+}
+
+func (c *conn) acknowledge() error {
+	if c.windowAckSize == 0 || c.r.read-c.acked < c.windowAckSize {
+		return nil
+	}
+	c.acked = c.r.read
+	return c.writeControl(msgAcknowledgement, uint32(c.r.read))
+}
+
+func (c *conn) handleMessage(msg *message) error {
+	switch msg.Type {
+	case msgSetChunkSize:
+		if len(msg.Payload) < 4 {
+			return ErrInvalidChunk
+		}
+		size := int(binary.BigEndian.Uint32(msg.Payload) & 0x7fffffff)
+		if size == 0 || size > maxChunkSize {
+			return ErrInvalidChunk
+		}
+		c.r.chunkSize = size
+
+	case msgAbort:
+		if len(msg.Payload) >= 4 {
+			if cs := c.r.streams[binary.BigEndian.Uint32(msg.Payload)]; cs != nil {
+				cs.payload = nil
+			}
+		}
+
+	case msgWindowAckSize:
+		if len(msg.Payload) >= 4 {
+			c.windowAckSize = uint64(binary.BigEndian.Uint32(msg.Payload))
+		}
+
+	case msgCommandAMF3:
+		// AMF0 values behind an AMF3 marker
+		if len(msg.Payload) == 0 {
+			return ErrInvalidCommand
+		}
+		return c.handleCommand(msg.StreamID, msg.Payload[1:])
+
+	case msgCommandAMF0:
+		return c.handleCommand(msg.StreamID, msg.Payload)
+
+	case msgDataAMF0:
+		if values, err := decodeAMF(msg.Payload); err == nil && len(values) >= 3 && values[0] == "@setDataFrame" {
+			c.logger.Debugw("rtmp stream metadata", "metadata", values[2])
+		}
+
+	case msgAudio:
+		if c.publishing {
+			return c.mediaError(c.media.writeAudio(msg))
+		}
+
+	case msgVideo:
+		if c.publishing {
+			return c.mediaError(c.media.writeVideo(msg))
+		}
+	}
+	return nil
+}
+
+// mediaError ends the connection when its stream ended, other errors drop the message
+func (c *conn) mediaError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, ingest.ErrPublisherReplaced), errors.Is(err, ingest.ErrPublisherClosed):
+		return err
+	case errors.Is(err, ingest.ErrUnsupportedVideoCodec), errors.Is(err, ingest.ErrUnsupportedAudioCodec),
+		errors.Is(err, ingest.ErrAACDecoderUnavailable):
+		c.logger.Warnw("dropping rtmp media", err, "app", c.app)
+	default:
+		c.logger.Debugw("invalid rtmp media", "error", err)
+	}
+	return nil
+}
+
+func (c *conn) handleCommand(streamID uint32, payload []byte) error {
+	values, err := decodeAMF(payload)
+	if err != nil {
+		return err
+	}
+	if len(values) < 2 {
+		return ErrInvalidCommand
+	}
+	name, _ := values[0].(string)
+	txn, _ := values[1].(float64)
+
+	switch name {
+	case "connect":
+		if len(values) < 3 {
+			return ErrInvalidCommand
+		}
+		obj, _ := values[2].(amfObjectValue)
+		c.app, _ = obj["app"].(string)
+		return c.connect(txn)
+
+	case "releaseStream", "FCPublish":
+		// answered for encoders that wait for it, the stream is created by publish
+		return c.writeCommand(0, "_result", txn, nil)
+
+	case "createStream":
+		return c.writeCommand(0, "_result", txn, nil, publishStreamID)
+
+	case "publish":
+		if len(values) < 4 || c.publishing {
+			return ErrInvalidCommand
+		}
+		streamKey, _ := values[3].(string)
+		return c.publish(streamID, streamKey)
+
+	case "FCUnpublish", "deleteStream", "closeStream":
+		if c.publishing {
+			return ErrUnpublished
+		}
+	}
+	return nil
+}
+
+func (c *conn) connect(txn float64) error {
+	if err := c.writeControl(msgWindowAckSize, serverWindowAck); err != nil {
+		return err
+	}
+	// dynamic limit type
+	if err := c.writeMessage(csidControl, &message{
+		Type:    msgSetPeerBandwidth,
+		Payload: append(binary.BigEndian.AppendUint32(nil, serverWindowAck), 2),
+	}); err != nil {
+		return err
+	}
+	if err := c.writeControl(msgSetChunkSize, serverChunkSize); err != nil {
+		return err
+	}
+	c.w.chunkSize = serverChunkSize
+
+	return c.writeCommand(0, "_result", txn,
+		amfObjectValue{
+			"fmsVer":       "FMS/3,0,1,123",
+			"capabilities": 31,
+		},
+		amfObjectValue{
+			"level":          "status",
+			"code":           "NetConnection.Connect.Success",
+			"description":    "Connection succeeded.",
+			"objectEncoding": 0,
+		},
+	)
+}
+
+func (c *conn) publish(streamID uint32, streamKey string) error {
+	if err := c.server.addStream(); err != nil {
+		_ = c.writeStatus(streamID, "error", "NetStream.Publish.Rejected", "Too many streams.")
+		return err
+	}
+	publisher, err := c.server.params.OnPublish(c.app, streamKey)
+	if err != nil {
+		c.server.removeStream()
+		_ = c.writeStatus(streamID, "error", "NetStream.Publish.BadName", "Stream key rejected.")
+		return err
+	}
+	c.streamKey = streamKey
+	c.publishing = true
+	c.media.publisher = publisher
+
+	// user control stream begin
+	begin := binary.BigEndian.AppendUint16(nil, 0)
+	begin = binary.BigEndian.AppendUint32(begin, streamID)
+	if err := c.writeMessage(csidControl, &message{Type: msgUserControl, Payload: begin}); err != nil {
+		return err
+	}
+	c.logger.Infow("rtmp stream started", "app", c.app)
+	return c.writeStatus(streamID, "status", "NetStream.Publish.Start", "Publishing.")
+}
+
+func (c *conn) writeStatus(streamID uint32, level string, code string, description string) error {
+	return c.writeCommand(streamID, "onStatus", 0, nil, amfObjectValue{
+		"level":       level,
+		"code":        code,
+		"description": description,
+	})
+}
+
+func (c *conn) writeCommand(streamID uint32, name string, txn float64, values ...any) error {
+	return c.writeMessage(csidCommand, &message{
+		Type:     msgCommandAMF0,
+		StreamID: streamID,
+		Payload:  appendAMF(appendAMF(nil, name, txn), values...),
+	})
+}
+
+func (c *conn) writeControl(typ uint8, value uint32) error {
+	return c.writeMessage(csidControl, &message{
+		Type:    typ,
+		Payload: binary.BigEndian.AppendUint32(nil, value),
+	})
+}
+
+func (c *conn) writeMessage(csid uint32, msg *message) error {
+	_ = c.netConn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return c.w.writeMessage(csid, msg)
+}
@@ -0,0 +1,269 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtmp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+)
+
+func TestAMF(t *testing.T) {
+	values := []any{
+		"connect",
+		1.0,
+		amfObjectValue{"app": "live", "flashVer": "FMLE/3.0", "nested": amfObjectValue{"n": 2.0}},
+		nil,
+		true,
+		[]any{"a", 3.0},
+	}
+	decoded, err := decodeAMF(appendAMF(nil, values...))
+	require.NoError(t, err)
+	require.Equal(t, values, decoded)
+
+	_, err = decodeAMF([]byte{amfString, 0, 5, 'a'})
+	require.ErrorIs(t, err, ErrInvalidAMF)
+}
+
+func TestChunks(t *testing.T) {
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	w := newChunkWriter(bw)
+	w.chunkSize = 100
+
+	payload := bytes.Repeat([]byte{1, 2, 3}, 150)
+	msgs := []*message{
+		{Type: msgVideo, StreamID: 1, Timestamp: 40, Payload: payload},
+		{Type: msgAudio, StreamID: 1, Timestamp: 0x1000000, Payload: []byte{0xaf, 1, 9}},
+		{Type: msgVideo, StreamID: 1, Timestamp: 0x1000010, Payload: payload},
+	}
+	for i, msg := range msgs {
+		require.NoError(t, w.writeMessage(uint32(4+i%2), msg))
+	}
+
+	r := newChunkReader(bufio.NewReader(&buf))
+	r.chunkSize = 100
+	for _, msg := range msgs {
+		read, err := r.readMessage()
+		require.NoError(t, err)
+		require.Equal(t, msg, read)
+	}
+	_, err := r.readMessage()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestPublish(t *testing.T) {
+	publisher := newTestPublisher()
+	var app, key string
+	s := newTestServer(t, func(a, k string) (ingest.Publisher, error) {
+		app, key = a, k
+		return publisher, nil
+	})
+
+	c := dialTestClient(t, s)
+	c.publish(t, "stream-key")
+	require.Equal(t, "live", app)
+	require.Equal(t, "stream-key", key)
+	require.Equal(t, 1, s.NumStreams())
+
+	sps := []byte{0x67, 0x42, 0xc0, 0x1f}
+	pps := []byte{0x68, 0xce, 0x3c, 0x80}
+	avcConfig := []byte{1, 0x42, 0xc0, 0x1f, 0xff, 0xe1, 0, 4}
+	avcConfig = append(append(avcConfig, sps...), 1, 0, 4)
+	avcConfig = append(avcConfig, pps...)
+	c.send(t, msgVideo, 0, append([]byte{0x17, 0, 0, 0, 0}, avcConfig...))
+
+	idr := []byte{0x65, 0x88, 0x84}
+	c.send(t, msgVideo, 40, append([]byte{0x17, 1, 0, 0, 0, 0, 0, 0, 3}, idr...))
+	// enhanced rtmp opus
+	c.send(t, msgAudio, 0, []byte{0x90, 'O', 'p', 'u', 's', 'O', 'p', 'u', 's', 'H', 'e', 'a', 'd'})
+	c.send(t, msgAudio, 40, []byte{0x91, 'O', 'p', 'u', 's', 0xfc, 0xff, 0xfe})
+
+	c.send(t, msgCommandAMF0, 0, appendAMF(nil, "deleteStream", 4.0, nil, 1))
+	select {
+	case <-publisher.closed:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "publisher not closed")
+	}
+
+	publisher.lock.Lock()
+	defer publisher.lock.Unlock()
+	expected := append([]byte{0, 0, 0, 1}, sps...)
+	expected = append(append(expected, 0, 0, 0, 1), pps...)
+	expected = append(append(expected, 0, 0, 0, 1), idr...)
+	require.Equal(t, [][]byte{expected}, publisher.video)
+	require.Equal(t, []time.Duration{40 * time.Millisecond}, publisher.dts)
+	require.Equal(t, [][]byte{{0xfc, 0xff, 0xfe}}, publisher.opus)
+	require.Eventually(t, func() bool { return s.NumStreams() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPublishRejected(t *testing.T) {
+	s := newTestServer(t, func(app, key string) (ingest.Publisher, error) {
+		return nil, errors.New("invalid stream key")
+	})
+
+	c := dialTestClient(t, s)
+	c.sendCommand(t, "connect", 1, amfObjectValue{"app": "live"})
+	c.expectCommand(t, "_result")
+	c.sendCommand(t, "createStream", 2, nil)
+	c.expectCommand(t, "_result")
+	c.send(t, msgCommandAMF0, 0, appendAMF(nil, "publish", 3.0, nil, "bad-key", "live"))
+	values := c.expectCommand(t, "onStatus")
+	require.Equal(t, "NetStream.Publish.BadName", values[3].(amfObjectValue)["code"])
+
+	_, err := c.r.readMessage()
+	require.Error(t, err)
+	require.Equal(t, 0, s.NumStreams())
+}
+
+func newTestServer(t *testing.T, onPublish func(app, key string) (ingest.Publisher, error)) *Server {
+	s, err := NewServer(ServerParams{
+		Config:    Config{Enabled: true},
+		Logger:    logger.GetLogger(),
+		OnPublish: onPublish,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+	t.Cleanup(s.Stop)
+	return s
+}
+
+type testClient struct {
+	conn net.Conn
+	r    *chunkReader
+	w    *chunkWriter
+}
+
+func dialTestClient(t *testing.T, s *Server) *testClient {
+	conn, err := net.Dial("tcp", s.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+
+	c0c1 := make([]byte, 1+handshakeSize)
+	c0c1[0] = rtmpVersion
+	c0c1[100] = 42
+	_, err = conn.Write(c0c1)
+	require.NoError(t, err)
+
+	br := bufio.NewReader(conn)
+	s0s1s2 := make([]byte, 1+2*handshakeSize)
+	_, err = io.ReadFull(br, s0s1s2)
+	require.NoError(t, err)
+	require.Equal(t, byte(rtmpVersion), s0s1s2[0])
+	require.Equal(t, c0c1[1:], s0s1s2[1+handshakeSize:])
+
+	_, err = conn.Write(s0s1s2[1 : 1+handshakeSize])
+	require.NoError(t, err)
+
+	return &testClient{
+		conn: conn,
+		r:    newChunkReader(br),
+		w:    newChunkWriter(bufio.NewWriter(conn)),
+	}
+}
+
+func (c *testClient) publish(t *testing.T, streamKey string) {
+	c.sendCommand(t, "connect", 1, amfObjectValue{"app": "live", "type": "nonprivate"})
+	c.expectCommand(t, "_result")
+	c.sendCommand(t, "releaseStream", 2, nil, streamKey)
+	c.expectCommand(t, "_result")
+	c.sendCommand(t, "createStream", 3, nil)
+	values := c.expectCommand(t, "_result")
+	require.Equal(t, float64(publishStreamID), values[3])
+
+	c.send(t, msgCommandAMF0, 0, appendAMF(nil, "publish", 4.0, nil, streamKey, "live"))
+	values = c.expectCommand(t, "onStatus")
+	require.Equal(t, "NetStream.Publish.Start", values[3].(amfObjectValue)["code"])
+}
+
+func (c *testClient) sendCommand(t *testing.T, name string, txn float64, values ...any) {
+	c.send(t, msgCommandAMF0, 0, appendAMF(appendAMF(nil, name, txn), values...))
+}
+
+func (c *testClient) send(t *testing.T, typ uint8, timestamp uint32, payload []byte) {
+	require.NoError(t, c.w.writeMessage(csidCommand, &message{
+		Type:      typ,
+		StreamID:  publishStreamID,
+		Timestamp: timestamp,
+		Payload:   payload,
+	}))
+}
+
+// expectCommand skips control messages until a command, applying chunk size changes
+func (c *testClient) expectCommand(t *testing.T, name string) []any {
+	for {
+		msg, err := c.r.readMessage()
+		require.NoError(t, err)
+		switch msg.Type {
+		case msgSetChunkSize:
+			c.r.chunkSize = int(msg.Payload[3]) | int(msg.Payload[2])<<8
+		case msgCommandAMF0:
+			values, err := decodeAMF(msg.Payload)
+			require.NoError(t, err)
+			require.Equal(t, name, values[0])
+			return values
+		}
+	}
+}
+
+type testPublisher struct {
+	lock   sync.Mutex
+	video  [][]byte
+	dts    []time.Duration
+	opus   [][]byte
+	closed chan struct{}
+}
+
+func newTestPublisher() *testPublisher {
+	return &testPublisher{closed: make(chan struct{})}
+}
+
+func (p *testPublisher) WriteH264(au []byte, dts time.Duration) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.video = append(p.video, append([]byte(nil), au...))
+	p.dts = append(p.dts, dts)
+	return nil
+}
+
+func (p *testPublisher) WriteOpus(packet []byte) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.opus = append(p.opus, append([]byte(nil), packet...))
+	return nil
+}
+
+func (p *testPublisher) WritePCM(pcm []int16, sampleRate int, channels int) error {
+	return nil
+}
+
+func (p *testPublisher) Close() {
+	close(p.closed)
+}
@@ -0,0 +1,202 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/agent"
+)
+
+// AgentWorkerServer serves the agentix.agent.AgentWorker gRPC service of pkg/agent/agentworker.proto,
+// registering workers over a stream of the messages the WebSocket at /agent carries
+type AgentWorkerServer struct {
+	agent.UnimplementedAgentWorkerServer
+
+	config      agent.WorkerGRPCConfig
+	keyProvider auth.KeyProvider
+	handler     *AgentHandler
+	server      *grpc.Server
+	logger      logger.Logger
+}
+
+func newAgentWorkerServer(conf agent.WorkerGRPCConfig, keyProvider auth.KeyProvider, handler *AgentHandler) *AgentWorkerServer {
+	a := &AgentWorkerServer{
+		config:      conf,
+		keyProvider: keyProvider,
+		handler:     handler,
+		server:      grpc.NewServer(),
+		logger:      logger.GetLogger().WithComponent("agents"),
+	}
+	agent.RegisterAgentWorkerServer(a.server, a)
+	return a
+}
+
+func (a *AgentWorkerServer) Start() error {
+	if a == nil {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.config.Port))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := a.server.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			a.logger.Errorw("agent worker server failed", err)
+		}
+	}()
+	a.logger.Infow("agent worker registration listening", "port", a.config.Port)
+	return nil
+}
+
+func (a *AgentWorkerServer) Stop() {
+	if a == nil {
+		return
+	}
+	a.server.Stop()
+}
+
+func (a *AgentWorkerServer) Connect(stream agent.AgentWorker_ConnectServer) error {
+	ctx, err := withGRPCGrants(stream.Context(), a.keyProvider)
+	if err != nil {
+		return err
+	}
+	if claims := GetGrants(ctx); claims == nil || claims.Video == nil || !claims.Video.Agent {
+		return status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+
+	registration := agent.MakeWorkerRegistration()
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			registration.ClientIP = host
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("capabilities"); len(values) != 0 {
+		registration.Capabilities = agent.ParseCapabilities(strings.Join(values, ","))
+	}
+
+	conn := newGRPCSignalConn(stream)
+	a.handler.HandleConnection(ctx, conn, registration)
+	_ = conn.Close()
+
+	// the stream must not be written once the handler returned
+	conn.writeLock.Lock()
+	conn.writeLock.Unlock()
+	return nil
+}
+
+// --------------------------------------
+
+type grpcReadTimeoutError struct{}
+
+func (grpcReadTimeoutError) Error() string   { return "read timeout" }
+func (grpcReadTimeoutError) Timeout() bool   { return true }
+func (grpcReadTimeoutError) Temporary() bool { return true }
+
+var errGRPCSignalConnClosed = errors.New("use of closed network connection")
+
+// grpcSignalConn is the agent.SignalConn of a gRPC stream. Reads run on a goroutine of their own
+// to apply the read deadline, which gRPC streams lack.
+type grpcSignalConn struct {
+	stream agent.AgentWorker_ConnectServer
+	closed core.Fuse
+
+	writeLock sync.Mutex
+
+	lock     sync.Mutex
+	deadline time.Time
+}
+
+type grpcWorkerMessage struct {
+	msg *livekit.WorkerMessage
+	err error
+}
+
+func newGRPCSignalConn(stream agent.AgentWorker_ConnectServer) *grpcSignalConn {
+	return &grpcSignalConn{stream: stream}
+}
+
+func (c *grpcSignalConn) WriteServerMessage(msg *livekit.ServerMessage) (int, error) {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if c.closed.IsBroken() {
+		return 0, errGRPCSignalConnClosed
+	}
+	return proto.Size(msg), c.stream.Send(msg)
+}
+
+func (c *grpcSignalConn) ReadWorkerMessage() (*livekit.WorkerMessage, int, error) {
+	read := make(chan grpcWorkerMessage, 1)
+	go func() {
+		msg, err := c.stream.Recv()
+		read <- grpcWorkerMessage{msg: msg, err: err}
+	}()
+
+	c.lock.Lock()
+	deadline := c.deadline
+	c.lock.Unlock()
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		timer := time.NewTimer(time.Until(deadline))
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	// the connection is not read again after an error, the read left running ends with the stream
+	select {
+	case r := <-read:
+		if r.err != nil {
+			return nil, 0, r.err
+		}
+		return r.msg, proto.Size(r.msg), nil
+	case <-timeout:
+		return nil, 0, grpcReadTimeoutError{}
+	case <-c.closed.Watch():
+		return nil, 0, errGRPCSignalConnClosed
+	}
+}
+
+func (c *grpcSignalConn) SetReadDeadline(deadline time.Time) error {
+	c.lock.Lock()
+	c.deadline = deadline
+	c.lock.Unlock()
+	return nil
+}
+
+func (c *grpcSignalConn) Close() error {
+	c.closed.Break()
+	return nil
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"math/rand"
+	"net"
 	"net/http"
 	"slices"
 	"sort"
@@ -89,6 +90,7 @@ func (u AgentSocketUpgrader) Upgrade(
 	if pv, err := strconv.Atoi(r.FormValue("protocol")); err == nil {
 		registration.Protocol = agent.WorkerProtocolVersion(pv)
 	}
+	registration.Capabilities = agent.ParseCapabilities(r.FormValue("capabilities"))
 
 	return conn, registration, true
 }
@@ -96,7 +98,10 @@ func (u AgentSocketUpgrader) Upgrade(
 func DispatchAgentWorkerSignal(c agent.SignalConn, h agent.WorkerSignalHandler, l logger.Logger) bool {
 	req, _, err := c.ReadWorkerMessage()
 	if err != nil {
-		if IsWebSocketCloseError(err) {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			l.Infow("worker unresponsive, disconnecting")
+		} else if IsWebSocketCloseError(err) {
 			l.Debugw("worker closed WS connection", "wsError", err)
 		} else {
 			l.Errorw("error reading from websocket", err)
@@ -136,12 +141,14 @@ type AgentService struct {
 
 type AgentHandler struct {
 	agentServer rpc.AgentInternalServer
+	config      agent.Config
 	mu          sync.Mutex
 	logger      logger.Logger
 
 	serverInfo  *livekit.ServerInfo
 	workers     map[string]*agent.Worker
 	jobToWorker map[livekit.JobID]*agent.Worker
+	jobRetries  map[livekit.JobID]*jobRetry
 	keyProvider auth.KeyProvider
 
 	namespaceWorkers    map[workerKey][]*agent.Worker
@@ -162,6 +169,12 @@ type workerKey struct {
 	jobType   livekit.JobType
 }
 
+// jobRetry is a job of a lost worker, dispatched again after a backoff
+type jobRetry struct {
+	attempt int
+	cancel  context.CancelFunc
+}
+
 func NewAgentService(
 	conf *config.Config,
 	currentNode routing.LocalNode,
@@ -191,6 +204,7 @@ func NewAgentService(
 		agent.RoomAgentTopic,
 		agent.PublisherAgentTopic,
 		agent.ParticipantAgentTopic,
+		conf.Agents,
 	)
 	return s, nil
 }
@@ -210,12 +224,15 @@ func NewAgentHandler(
 	roomTopic string,
 	publisherTopic string,
 	participantTopic string,
+	config agent.Config,
 ) *AgentHandler {
 	return &AgentHandler{
 		agentServer:      agentServer,
+		config:           config,
 		logger:           logger.WithComponent("agents"),
 		workers:          make(map[string]*agent.Worker),
 		jobToWorker:      make(map[livekit.JobID]*agent.Worker),
+		jobRetries:       make(map[livekit.JobID]*jobRetry),
 		namespaceWorkers: make(map[workerKey][]*agent.Worker),
 		serverInfo:       serverInfo,
 		keyProvider:      keyProvider,
@@ -239,6 +256,12 @@ func (h *AgentHandler) HandleConnection(ctx context.Context, conn agent.SignalCo
 
 	handlerWorker := &agentHandlerWorker{h, worker}
 	for ok := true; ok; {
+		// workers that stop sending, pings included, are considered lost
+		if h.config.WorkerTimeout > 0 {
+			if err := conn.SetReadDeadline(time.Now().Add(h.config.WorkerTimeout)); err != nil {
+				break
+			}
+		}
 		ok = DispatchAgentWorkerSignal(conn, handlerWorker, worker.Logger())
 	}
 
@@ -300,6 +323,7 @@ func (h *AgentHandler) registerWorker(w *agent.Worker) {
 		"jobType", w.JobType,
 		"agentName", w.AgentName,
 		"workerID", w.ID,
+		"capabilities", w.Capabilities,
 	)
 	if created {
 		err := h.agentServer.PublishWorkerRegistered(context.Background(), agent.DefaultHandlerNamespace, &emptypb.Empty{})
@@ -362,7 +386,11 @@ func (h *AgentHandler) deregisterWorker(w *agent.Worker) {
 	}
 
 	jobs := w.RunningJobs()
-	for jobID := range jobs {
+	for jobID, job := range jobs {
+		if h.retryJobLocked(job) {
+			delete(h.jobToWorker, jobID)
+			continue
+		}
 		h.deregisterJob(jobID)
 	}
 }
@@ -371,10 +399,64 @@ func (h *AgentHandler) deregisterJob(jobID livekit.JobID) {
 	h.agentServer.DeregisterJobTerminateTopic(string(jobID))
 
 	delete(h.jobToWorker, jobID)
+	if retry := h.jobRetries[jobID]; retry != nil {
+		retry.cancel()
+		delete(h.jobRetries, jobID)
+	}
 
 	// TODO update dispatch state
 }
 
+// retryJobLocked dispatches a job of a lost worker again after the backoff, unless it ran out of attempts.
+// The terminate topic of the job stays registered meanwhile, so that the room can still end it.
+func (h *AgentHandler) retryJobLocked(job *livekit.Job) bool {
+	jobID := livekit.JobID(job.Id)
+	attempt := 1
+	if previous := h.jobRetries[jobID]; previous != nil {
+		attempt = previous.attempt + 1
+	}
+	if attempt > h.config.JobRetry.MaxAttempts || job.Room == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	retry := &jobRetry{attempt: attempt, cancel: cancel}
+	h.jobRetries[jobID] = retry
+	go h.retryJob(ctx, job, retry)
+	return true
+}
+
+func (h *AgentHandler) retryJob(ctx context.Context, job *livekit.Job, retry *jobRetry) {
+	logger := h.logger.WithUnlikelyValues("jobID", job.Id, "agentName", job.AgentName, "room", job.Room.Name, "attempt", retry.attempt)
+
+	timer := time.NewTimer(h.config.JobRetry.Backoff << (retry.attempt - 1))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	state, err := h.assignJob(ctx, job, logger)
+	if err == nil && state.GetStatus() == livekit.JobStatus_JS_RUNNING {
+		logger.Infow("job dispatched again after losing its worker")
+		return
+	}
+	if ctx.Err() == nil {
+		logger.Warnw("could not dispatch job again", err)
+	}
+
+	h.mu.Lock()
+	current := h.jobRetries[livekit.JobID(job.Id)] == retry
+	if current {
+		delete(h.jobRetries, livekit.JobID(job.Id))
+	}
+	h.mu.Unlock()
+	if current {
+		h.agentServer.DeregisterJobTerminateTopic(job.Id)
+	}
+}
+
 func (h *AgentHandler) JobRequest(ctx context.Context, job *livekit.Job) (*rpc.JobRequestResponse, error) {
 	logger := h.logger.WithUnlikelyValues(
 		"jobID", job.Id,
@@ -389,12 +471,30 @@ func (h *AgentHandler) JobRequest(ctx context.Context, job *livekit.Job) (*rpc.J
 		logger = logger.WithValues("participant", job.Participant.Identity)
 	}
 
+	state, err := h.assignJob(ctx, job, logger)
+	if err != nil {
+		return nil, err
+	}
+	if state.GetStatus() == livekit.JobStatus_JS_RUNNING {
+		if err := h.agentServer.RegisterJobTerminateTopic(job.Id); err != nil {
+			logger.Errorw("failed to register JobTerminate handler", err)
+		}
+	}
+	return &rpc.JobRequestResponse{
+		State: state,
+	}, nil
+}
+
+// assignJob offers the job to the workers of its agent with the capabilities it needs, weighted by load,
+// until one accepts it
+func (h *AgentHandler) assignJob(ctx context.Context, job *livekit.Job, logger logger.UnlikelyLogger) (*livekit.JobState, error) {
 	key := workerKey{job.AgentName, job.Namespace, job.Type}
+	capabilities := agent.RequiredCapabilities(h.config.DispatchRules, job.Room.GetName(), job.AgentName)
 	attempted := make(map[*agent.Worker]struct{})
 	for {
-		selected, err := h.selectWorkerWeightedByLoad(key, attempted)
+		selected, err := h.selectWorkerWeightedByLoad(key, attempted, capabilities)
 		if err != nil {
-			logger.Warnw("no worker available to handle job", err)
+			logger.Warnw("no worker available to handle job", err, "capabilities", capabilities)
 			return nil, psrpc.NewError(psrpc.ResourceExhausted, err)
 		}
 
@@ -408,16 +508,9 @@ func (h *AgentHandler) JobRequest(ctx context.Context, job *livekit.Job) (*rpc.J
 			h.mu.Lock()
 			h.jobToWorker[livekit.JobID(job.Id)] = selected
 			h.mu.Unlock()
-
-			err = h.agentServer.RegisterJobTerminateTopic(job.Id)
-			if err != nil {
-				logger.Errorw("failed to register JobTerminate handler", err)
-			}
-			fallthrough
+			return state, nil
 		case livekit.JobStatus_JS_SUCCESS:
-			return &rpc.JobRequestResponse{
-				State: state,
-			}, nil
+			return state, nil
 		default:
 			retry := utils.ErrorIsOneOf(err, agent.ErrWorkerNotAvailable, agent.ErrWorkerClosed)
 			logger.Warnw("failed to assign job to worker", err, "retry", retry)
@@ -432,11 +525,15 @@ func (h *AgentHandler) JobRequestAffinity(ctx context.Context, job *livekit.Job)
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	capabilities := agent.RequiredCapabilities(h.config.DispatchRules, job.Room.GetName(), job.AgentName)
 	var affinity float32
 	for _, w := range h.workers {
 		if w.AgentName != job.AgentName || w.Namespace != job.Namespace || w.JobType != job.Type {
 			continue
 		}
+		if !w.HasCapabilities(capabilities) {
+			continue
+		}
 
 		if w.Status() == livekit.WorkerStatus_WS_AVAILABLE {
 			affinity += max(0, 1-w.Load())
@@ -449,6 +546,21 @@ func (h *AgentHandler) JobRequestAffinity(ctx context.Context, job *livekit.Job)
 func (h *AgentHandler) JobTerminate(ctx context.Context, req *rpc.JobTerminateRequest) (*rpc.JobTerminateResponse, error) {
 	h.mu.Lock()
 	w := h.jobToWorker[livekit.JobID(req.JobId)]
+	retry := h.jobRetries[livekit.JobID(req.JobId)]
+	if w == nil && retry != nil {
+		// the job lost its worker and waits to be dispatched again
+		h.deregisterJob(livekit.JobID(req.JobId))
+		h.mu.Unlock()
+
+		now := time.Now().UnixNano()
+		return &rpc.JobTerminateResponse{
+			State: &livekit.JobState{
+				Status:    livekit.JobStatus_JS_SUCCESS,
+				UpdatedAt: now,
+				EndedAt:   now,
+			},
+		}, nil
+	}
 	h.mu.Unlock()
 
 	if w == nil {
@@ -496,7 +608,7 @@ func (h *AgentHandler) DrainConnections(interval time.Duration) {
 	}
 }
 
-func (h *AgentHandler) selectWorkerWeightedByLoad(key workerKey, ignore map[*agent.Worker]struct{}) (*agent.Worker, error) {
+func (h *AgentHandler) selectWorkerWeightedByLoad(key workerKey, ignore map[*agent.Worker]struct{}, capabilities []string) (*agent.Worker, error) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -508,7 +620,7 @@ func (h *AgentHandler) selectWorkerWeightedByLoad(key workerKey, ignore map[*age
 	normalizedLoads := make(map[*agent.Worker]float32)
 	var availableSum float32
 	for _, w := range workers {
-		if _, ok := ignore[w]; !ok && w.Status() == livekit.WorkerStatus_WS_AVAILABLE {
+		if _, ok := ignore[w]; !ok && w.Status() == livekit.WorkerStatus_WS_AVAILABLE && w.HasCapabilities(capabilities) {
 			normalizedLoads[w] = max(0, 1-w.Load())
 			availableSum += normalizedLoads[w]
 		}
@@ -519,12 +631,15 @@ func (h *AgentHandler) selectWorkerWeightedByLoad(key workerKey, ignore map[*age
 	}
 
 	currentSum := rand.Float32() * availableSum
+	var last *agent.Worker
 	for w, load := range normalizedLoads {
 		if currentSum -= load; currentSum <= 0 {
 			return w, nil
 		}
+		last = w
 	}
-	return workers[0], nil
+	// rounding left some of the sum, any worker considered will do
+	return last, nil
 }
 
 var _ agent.WorkerSignalHandler = (*agentHandlerWorker)(nil)
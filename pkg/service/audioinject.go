@@ -0,0 +1,277 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/audioinject"
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+// AudioInjectServer serves the agentix.audioinject.AudioInject gRPC service of pkg/audioinject/audioinject.proto.
+// Playback lives as long as its Play call, audio still queued when the caller goes away is dropped.
+type AudioInjectServer struct {
+	audioinject.UnimplementedAudioInjectServer
+
+	config      audioinject.Config
+	keyProvider auth.KeyProvider
+	roomManager *RoomManager
+	server      *grpc.Server
+	logger      logger.Logger
+}
+
+func newAudioInjectServer(conf audioinject.Config, keyProvider auth.KeyProvider, roomManager *RoomManager) *AudioInjectServer {
+	a := &AudioInjectServer{
+		config:      conf,
+		keyProvider: keyProvider,
+		roomManager: roomManager,
+		server:      grpc.NewServer(),
+		logger:      logger.GetLogger().WithComponent("audio_injection"),
+	}
+	audioinject.RegisterAudioInjectServer(a.server, a)
+	return a
+}
+
+func (a *AudioInjectServer) Start() error {
+	if a == nil {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", a.config.Port))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := a.server.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			a.logger.Errorw("audio injection server failed", err)
+		}
+	}()
+	a.logger.Infow("audio injection listening", "port", a.config.Port)
+	return nil
+}
+
+func (a *AudioInjectServer) Stop() {
+	if a == nil {
+		return
+	}
+	// playback ends with the rooms, which close before the server stops
+	a.server.Stop()
+}
+
+func (a *AudioInjectServer) Play(stream grpc.BidiStreamingServer[audioinject.PlayRequest, audioinject.PlayEvent]) error {
+	ctx := stream.Context()
+	req, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	roomName := livekit.RoomName(req.RoomName)
+	if err := a.authorize(ctx, roomName); err != nil {
+		return err
+	}
+	room := a.roomManager.GetRoom(ctx, roomName)
+	if room == nil {
+		return status.Error(codes.NotFound, "room is not hosted on this node")
+	}
+
+	var source io.ReadCloser
+	if req.Url != "" {
+		if source, err = audioinject.OpenSource(ctx, a.config, req.Url); err != nil {
+			return audioInjectStatus(err)
+		}
+		defer source.Close()
+	} else if err := validatePlayFormat(req); err != nil {
+		return audioInjectStatus(err)
+	}
+
+	// every event is sent at most once
+	events := make(chan *audioinject.PlayEvent, 2)
+	trackID, player, err := room.InjectAudio(
+		req.Name,
+		func() {
+			events <- &audioinject.PlayEvent{Type: audioinject.PlayEventStarted}
+		},
+		func(reason audioinject.FinishReason, played time.Duration) {
+			events <- &audioinject.PlayEvent{
+				Type:     audioinject.PlayEventFinished,
+				Reason:   string(reason),
+				PlayedUs: uint64(played.Microseconds()),
+			}
+		},
+	)
+	if err != nil {
+		return audioInjectStatus(err)
+	}
+	defer player.Cancel()
+
+	a.logger.Debugw("audio injection started", "room", roomName, "trackID", trackID, "name", req.Name, "format", req.Format, "url", req.Url != "")
+	if err := stream.Send(&audioinject.PlayEvent{Type: audioinject.PlayEventPublished, TrackSid: string(trackID)}); err != nil {
+		return err
+	}
+
+	// the first failure of reading or writing audio ends playback and the call
+	failed := make(chan error, 2)
+	fail := func(err error) {
+		if err != nil && !errors.Is(err, audioinject.ErrPlayerFinished) && !errors.Is(err, context.Canceled) {
+			failed <- err
+			player.Cancel()
+		}
+	}
+	if source != nil {
+		go func() {
+			fail(audioinject.PlayFrom(ctx, player, source))
+		}()
+	}
+	go func() {
+		fail(a.receive(ctx, stream, player, req, source != nil))
+	}()
+
+	for {
+		select {
+		case event := <-events:
+			event.TrackSid = string(trackID)
+			if err := stream.Send(event); err != nil {
+				return err
+			}
+			if event.Type != audioinject.PlayEventFinished {
+				continue
+			}
+			select {
+			case err := <-failed:
+				return audioInjectStatus(err)
+			default:
+				return nil
+			}
+
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// receive writes the audio of the requests following the first to the player, starting with the first
+// itself, and cancels playback when asked to, until the call ends
+func (a *AudioInjectServer) receive(ctx context.Context, stream grpc.BidiStreamingServer[audioinject.PlayRequest, audioinject.PlayEvent], player *audioinject.Player, req *audioinject.PlayRequest, fromSource bool) error {
+	format, sampleRate := req.Format, int(req.SampleRate)
+	ended := fromSource
+	for {
+		switch {
+		case req.Cancel:
+			player.Cancel()
+			return nil
+		case len(req.Audio) != 0 && fromSource:
+			return status.Error(codes.InvalidArgument, "audio cannot be streamed while playing a url")
+		case len(req.Audio) != 0 && format == audioinject.FormatOpus:
+			if err := player.WriteOpus(ctx, req.Audio); err != nil {
+				return err
+			}
+		case len(req.Audio) != 0:
+			if err := player.WritePCM(ctx, audioinject.PCMFromBytes(req.Audio), sampleRate); err != nil {
+				return err
+			}
+		}
+		if req.End && !ended {
+			ended = true
+			if err := player.End(ctx); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if req, err = stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) && !ended {
+				// the caller closing its side ends the audio
+				return player.End(ctx)
+			}
+			return nil
+		}
+	}
+}
+
+func (a *AudioInjectServer) Cancel(ctx context.Context, req *audioinject.CancelRequest) (*audioinject.CancelResponse, error) {
+	roomName := livekit.RoomName(req.RoomName)
+	if err := a.authorize(ctx, roomName); err != nil {
+		return nil, err
+	}
+	room := a.roomManager.GetRoom(ctx, roomName)
+	if room == nil {
+		return nil, status.Error(codes.NotFound, "room is not hosted on this node")
+	}
+	if err := room.CancelAudioInjection(livekit.TrackID(req.TrackSid)); err != nil {
+		return nil, audioInjectStatus(err)
+	}
+	return &audioinject.CancelResponse{}, nil
+}
+
+// authorize verifies the access token of the call, which needs to grant administration of the room
+func (a *AudioInjectServer) authorize(ctx context.Context, roomName livekit.RoomName) error {
+	ctx, err := withGRPCGrants(ctx, a.keyProvider)
+	if err != nil {
+		return err
+	}
+	if EnsureAdminPermission(ctx, roomName) != nil {
+		return status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	return nil
+}
+
+func validatePlayFormat(req *audioinject.PlayRequest) error {
+	switch req.Format {
+	case audioinject.FormatOpus:
+		return nil
+	case audioinject.FormatPCM16:
+		if req.SampleRate < audioinject.MinSampleRate || req.SampleRate > audioinject.MaxSampleRate {
+			return audioinject.ErrUnsupportedSampleRate
+		}
+		return nil
+	}
+	return audioinject.ErrUnsupportedFormat
+}
+
+func audioInjectStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	switch {
+	case errors.Is(err, audioinject.ErrUnsupportedSampleRate), errors.Is(err, audioinject.ErrUnsupportedFormat),
+		errors.Is(err, audioinject.ErrInvalidOpusPacket), errors.Is(err, audioinject.ErrUnsupportedSource):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, audioinject.ErrSourceNotAllowed):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, rtc.ErrAudioInjectionNotFound), errors.Is(err, os.ErrNotExist):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, rtc.ErrAudioInjectionDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, rtc.ErrTooManyAudioInjections):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
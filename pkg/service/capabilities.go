@@ -0,0 +1,141 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/capabilities"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/version"
+)
+
+// capabilitiesHandler reports readiness and which optional subsystems are compiled and enabled on this node
+func (s *LivekitServer) capabilitiesHandler(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.Capabilities())
+}
+
+func (s *LivekitServer) Capabilities() *capabilities.Capabilities {
+	ready, _ := s.isReady()
+	opusCompiled := audio.IsOpusCodecAvailable()
+	opusReason := ""
+	if !opusCompiled {
+		opusReason = audio.ErrOpusCodecUnavailable.Error()
+	}
+
+	return &capabilities.Capabilities{
+		NodeID:  string(s.currentNode.NodeID()),
+		Region:  s.config.Region,
+		Version: version.Version,
+		Ready:   ready,
+		Subsystems: []capabilities.Subsystem{
+			{
+				Name:     capabilities.NoiseFilter,
+				Compiled: true,
+				Enabled:  s.config.Audio.NoiseFilter.Enabled,
+				Backend:  "rnnoise",
+			},
+			{
+				Name:     capabilities.Opus,
+				Compiled: opusCompiled,
+				Enabled:  opusCompiled,
+				Backend:  "libopus",
+				Reason:   opusReason,
+			},
+			{
+				Name:   capabilities.GPUDSP,
+				Reason: "no GPU audio processing backend in this build",
+			},
+			{
+				Name:     capabilities.AudioMixing,
+				Compiled: opusCompiled,
+				Enabled:  opusCompiled && s.config.Audio.Mixing.Enabled,
+				Reason:   opusReason,
+			},
+			{
+				Name:     capabilities.MLExport,
+				Compiled: opusCompiled,
+				Enabled:  opusCompiled && s.config.Room.MLExport.Enabled,
+				Reason:   opusReason,
+			},
+			connectedSubsystem(capabilities.SIP, s.sipService != nil && s.sipService.psrpcClient != nil, nil),
+			connectedSubsystem(capabilities.Ingress, s.ingressService != nil && s.ingressService.psrpcClient != nil, nil),
+			connectedSubsystem(capabilities.Egress, s.egressService != nil && s.egressService.client != nil, egressFormats()),
+			{
+				Name:   capabilities.STT,
+				Reason: "speech to text is provided by agents",
+			},
+			{
+				Name:     capabilities.Placement,
+				Compiled: true,
+				Enabled:  s.config.Placement.Enabled,
+			},
+		},
+	}
+}
+
+// isReady returns true if the node has reported its stats recently
+func (s *LivekitServer) isReady() (bool, time.Time) {
+	var updatedAt time.Time
+	if s.Node().Stats != nil {
+		updatedAt = time.Unix(s.Node().Stats.UpdatedAt, 0)
+	}
+	return time.Since(updatedAt) <= 4*time.Second, updatedAt
+}
+
+// connectedSubsystem describes a subsystem run by separate workers reached over the message bus
+func connectedSubsystem(name string, connected bool, options []string) capabilities.Subsystem {
+	subsystem := capabilities.Subsystem{
+		Name:     name,
+		Compiled: true,
+		Enabled:  connected,
+		Options:  options,
+	}
+	if !connected {
+		subsystem.Reason = "not connected (redis required)"
+	}
+	return subsystem
+}
+
+// egressFormats returns the file formats and stream protocols egress requests can ask for
+func egressFormats() []string {
+	var formats []string
+	for value, name := range livekit.EncodedFileType_name {
+		if value != int32(livekit.EncodedFileType_DEFAULT_FILETYPE) {
+			formats = append(formats, strings.ToLower(name))
+		}
+	}
+	for value, name := range livekit.StreamProtocol_name {
+		if value != int32(livekit.StreamProtocol_DEFAULT_PROTOCOL) {
+			formats = append(formats, strings.ToLower(name))
+		}
+	}
+	formats = append(formats, "hls")
+	slices.Sort(formats)
+	return formats
+}
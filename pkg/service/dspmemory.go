@@ -0,0 +1,154 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/memtrack"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+// dspMemoryMonitor exports the memory held per DSP stage and reports instances that outlive their stream
+type dspMemoryMonitor struct {
+	config   memtrack.Config
+	registry *memtrack.Registry
+	logger   logger.Logger
+
+	lock sync.Mutex
+	// stages seen so far, exported as zero once their last instance is released
+	stages map[string]struct{}
+	leaks  []memtrack.Leak
+
+	stop core.Fuse
+}
+
+func newDSPMemoryMonitor(conf memtrack.Config) *dspMemoryMonitor {
+	if !conf.Enabled {
+		return nil
+	}
+	if conf.LeakGrace <= 0 {
+		conf.LeakGrace = memtrack.DefaultConfig.LeakGrace
+	}
+	if conf.CheckInterval <= 0 {
+		conf.CheckInterval = memtrack.DefaultConfig.CheckInterval
+	}
+
+	return &dspMemoryMonitor{
+		config:   conf,
+		registry: memtrack.DefaultRegistry,
+		logger:   logger.GetLogger().WithComponent("dsp_memory"),
+		stages:   make(map[string]struct{}),
+	}
+}
+
+func (m *dspMemoryMonitor) Start() {
+	if m == nil {
+		return
+	}
+
+	go m.worker()
+}
+
+func (m *dspMemoryMonitor) Stop() {
+	if m == nil {
+		return
+	}
+
+	m.stop.Break()
+}
+
+func (m *dspMemoryMonitor) worker() {
+	ticker := time.NewTicker(m.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.check()
+
+		case <-m.stop.Watch():
+			return
+		}
+	}
+}
+
+func (m *dspMemoryMonitor) check() {
+	leaks := m.registry.CheckLeaks(time.Now(), m.config.LeakGrace)
+	for _, leak := range leaks {
+		if !leak.New {
+			continue
+		}
+		prometheus.RecordDSPLeak(leak.Stage)
+		m.logger.Warnw(
+			"dsp instance outlived its stream", nil,
+			"stage", leak.Stage,
+			"stream", leak.Stream,
+			"age", leak.Age,
+			"sinceEnd", leak.SinceEnd,
+			"goBytes", leak.GoBytes,
+			"nativeBytes", leak.NativeBytes,
+		)
+	}
+
+	usage := m.registry.Usage()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.leaks = leaks
+	for stage := range usage {
+		m.stages[stage] = struct{}{}
+	}
+	for stage := range m.stages {
+		u := usage[stage]
+		prometheus.RecordDSPMemory(stage, u.Instances, u.GoBytes, u.NativeBytes, u.Leaked)
+	}
+}
+
+// dspMemoryReport is the memory held per stage and the instances found leaked by the last check
+type dspMemoryReport struct {
+	Stages map[string]memtrack.Usage `json:"stages"`
+	Leaks  []memtrack.Leak           `json:"leaks"`
+}
+
+func (m *dspMemoryMonitor) report() dspMemoryReport {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return dspMemoryReport{
+		Stages: m.registry.Usage(),
+		Leaks:  m.leaks,
+	}
+}
+
+// debugDSPMemory reports the memory held per DSP stage and the instances that outlived their stream
+func (s *LivekitServer) debugDSPMemory(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.dspMemory.report())
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+type effectiveConfigResponse struct {
+	NodeID string                  `json:"nodeId"`
+	Fields []config.EffectiveField `json:"fields"`
+	Room   *roomConfigOverrides    `json:"room,omitempty"`
+}
+
+// roomConfigOverrides are the runtime changes of a room to the node configuration
+type roomConfigOverrides struct {
+	Name             livekit.RoomName          `json:"name"`
+	ProcessingBypass rtc.ProcessingBypassState `json:"processingBypass"`
+	// noise filter configuration selected by the room metadata, applies to participants joining afterwards
+	NoiseFilter  *audio.NoiseFilterConfig     `json:"noiseFilter,omitempty"`
+	Participants []participantConfigOverrides `json:"participants,omitempty"`
+}
+
+type participantConfigOverrides struct {
+	Identity livekit.ParticipantIdentity `json:"identity"`
+	// excluded by the stage bypass rules or the agentix.bypass attribute
+	NoiseFilterExcluded bool `json:"noiseFilterExcluded,omitempty"`
+	// tracks with noise filtering switched off through UpdateTrackNoiseFilter
+	NoiseFilterDisabledTracks []livekit.TrackID `json:"noiseFilterDisabledTracks,omitempty"`
+}
+
+// effectiveConfig renders the configuration this node is running with, each field annotated with
+// whether it comes from the defaults, the config file, command line flags and environment or is derived
+// by the server. With the room query parameter the runtime overrides of that room are added, which
+// requires the roomAdmin grant for it.
+func (s *LivekitServer) effectiveConfig(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	fields, err := s.config.EffectiveConfig()
+	if err != nil {
+		HandleError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	res := effectiveConfigResponse{
+		NodeID: string(s.currentNode.NodeID()),
+		Fields: fields,
+	}
+
+	if roomName := livekit.RoomName(r.URL.Query().Get("room")); roomName != "" {
+		if err := EnsureAdminPermission(r.Context(), roomName); err != nil {
+			HandleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+		room := s.roomManager.GetRoom(r.Context(), roomName)
+		if room == nil {
+			HandleError(w, r, http.StatusNotFound, ErrRoomNotFound)
+			return
+		}
+		res.Room = s.roomConfigOverrides(room)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+func (s *LivekitServer) roomConfigOverrides(room *rtc.Room) *roomConfigOverrides {
+	overrides := &roomConfigOverrides{
+		Name:             room.Name(),
+		ProcessingBypass: room.ProcessingBypassState(),
+	}
+	if noiseFilter, ok, _ := audio.RoomNoiseFilterConfig(s.config.Audio.NoiseFilter, s.config.Room.NoiseFilterPresets, room.ToProto().Metadata); ok {
+		overrides.NoiseFilter = &noiseFilter
+	}
+	if !s.config.Audio.NoiseFilter.Enabled && overrides.NoiseFilter == nil {
+		return overrides
+	}
+
+	for _, p := range room.GetParticipants() {
+		po := participantConfigOverrides{
+			Identity:            p.Identity(),
+			NoiseFilterExcluded: s.config.Audio.StageBypass.IsBypassed(audio.StageNoiseFilter, string(p.Identity()), p.ClaimGrants().Attributes),
+		}
+		for _, track := range p.GetPublishedTracks() {
+			if nf, ok := track.(interface{ IsNoiseFilterEnabled() bool }); ok && track.Kind() == livekit.TrackType_AUDIO && !nf.IsNoiseFilterEnabled() {
+				po.NoiseFilterDisabledTracks = append(po.NoiseFilterDisabledTracks, track.ID())
+			}
+		}
+		if po.NoiseFilterExcluded || len(po.NoiseFilterDisabledTracks) != 0 {
+			overrides.Participants = append(overrides.Participants, po)
+		}
+	}
+	return overrides
+}
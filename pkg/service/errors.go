@@ -28,6 +28,7 @@ var (
 	ErrIngressNonReusable               = psrpc.NewErrorf(psrpc.InvalidArgument, "ingress is not reusable and cannot be modified")
 	ErrNameExceedsLimits                = psrpc.NewErrorf(psrpc.InvalidArgument, "name length exceeds limits")
 	ErrMetadataExceedsLimits            = psrpc.NewErrorf(psrpc.InvalidArgument, "metadata size exceeds limits")
+	ErrMetadataVersionMismatch          = psrpc.NewErrorf(psrpc.FailedPrecondition, "metadata version mismatch")
 	ErrAttributeExceedsLimits           = psrpc.NewErrorf(psrpc.InvalidArgument, "attribute size exceeds limits")
 	ErrNoRoomName                       = psrpc.NewErrorf(psrpc.InvalidArgument, "no room name")
 	ErrRoomNameExceedsLimits            = psrpc.NewErrorf(psrpc.InvalidArgument, "room name length exceeds limits")
@@ -49,4 +50,19 @@ var (
 	ErrNoConnectRequest                 = psrpc.NewErrorf(psrpc.InvalidArgument, "no connect request")
 	ErrNoConnectResponse                = psrpc.NewErrorf(psrpc.InvalidArgument, "no connect response")
 	ErrDestinationIdentityRequired      = psrpc.NewErrorf(psrpc.InvalidArgument, "destination identity is required")
+	ErrNoiseProfileNotFound             = psrpc.NewErrorf(psrpc.NotFound, "noise profile does not exist")
+	ErrTrackMirrorNotEnabled            = psrpc.NewErrorf(psrpc.FailedPrecondition, "track mirroring not enabled")
+	ErrMirrorRoomNotFound               = psrpc.NewErrorf(psrpc.NotFound, "mirror room is not hosted on this node")
+	ErrNotMirrorRoom                    = psrpc.NewErrorf(psrpc.InvalidArgument, "room does not accept mirrored tracks")
+	ErrTrackAlreadyMirrored             = psrpc.NewErrorf(psrpc.AlreadyExists, "track is already mirrored into the room")
+	ErrNoiseFilterNotEnabled            = psrpc.NewErrorf(psrpc.FailedPrecondition, "noise filter not enabled")
+	ErrNoiseFilterUnavailable           = psrpc.NewErrorf(psrpc.FailedPrecondition, "noise filter is not available for the track")
+	ErrTalkAnalyticsNotEnabled          = psrpc.NewErrorf(psrpc.FailedPrecondition, "talk analytics not enabled")
+	ErrAudioSnapshotsNotEnabled         = psrpc.NewErrorf(psrpc.FailedPrecondition, "audio snapshots not enabled")
+	ErrRealtimeBridgeNotEnabled         = psrpc.NewErrorf(psrpc.FailedPrecondition, "realtime bridge not enabled")
+	ErrSupportBundleNotEnabled          = psrpc.NewErrorf(psrpc.FailedPrecondition, "support bundles not enabled")
+	ErrProcessingNotBypassed            = psrpc.NewErrorf(psrpc.FailedPrecondition, "processing of the room is not bypassed")
+	ErrWarmPoolNotEnabled               = psrpc.NewErrorf(psrpc.FailedPrecondition, "warm room pool not enabled")
+	ErrNoWarmRoom                       = psrpc.NewErrorf(psrpc.Unavailable, "no warm room ready")
+	ErrEventFeedUnavailable             = psrpc.NewErrorf(psrpc.Unimplemented, "event feed not available")
 )
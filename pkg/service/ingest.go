@@ -0,0 +1,117 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+// time the room of a stream may take to be created
+const ingestRoomTimeout = 10 * time.Second
+
+// ingestStarter publishes the streams of broadcast encoders into rooms. An encoder is authorized by an access
+// token in place of its stream key, with permission to join and publish in the room, which is created on
+// this node if it does not exist. The room stays open while the encoder is connected.
+type ingestStarter struct {
+	keyProvider auth.KeyProvider
+	roomManager *RoomManager
+	logger      logger.Logger
+}
+
+func (s *ingestStarter) start(token string, protocol ingest.Protocol, reconnectTimeout time.Duration) (ingest.Publisher, error) {
+	claims, err := s.authorize(token)
+	if err != nil {
+		return nil, err
+	}
+	return s.publish(ingestRoomRequest(claims), rtc.IngestParams{
+		Identity:         livekit.ParticipantIdentity(claims.Identity),
+		Name:             claims.Name,
+		Protocol:         protocol,
+		ReconnectTimeout: reconnectTimeout,
+	})
+}
+
+// authorize verifies the token of an encoder, returns its grants
+func (s *ingestStarter) authorize(token string) (*auth.ClaimGrants, error) {
+	v, err := auth.ParseAPIToken(token)
+	if err != nil {
+		return nil, ErrInvalidAuthorizationToken
+	}
+	secret := s.keyProvider.GetSecret(v.APIKey())
+	if secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+	claims, err := v.Verify(secret)
+	if err != nil {
+		return nil, ErrInvalidAuthorizationToken
+	}
+	if claims.Video == nil || !claims.Video.RoomJoin || claims.Video.Room == "" || !claims.Video.GetCanPublish() {
+		return nil, ErrPermissionDenied
+	}
+	if claims.Identity == "" {
+		return nil, ErrIdentityEmpty
+	}
+	return claims, nil
+}
+
+// publish starts a stream in the room, creating it if needed
+func (s *ingestStarter) publish(createRoom *livekit.CreateRoomRequest, params rtc.IngestParams) (ingest.Publisher, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), ingestRoomTimeout)
+	defer cancel()
+
+	room, err := s.roomManager.getOrCreateRoom(ctx, createRoom)
+	if err != nil {
+		s.logger.Warnw("could not create room of ingest", err, "room", createRoom.Name, "protocol", params.Protocol)
+		return nil, err
+	}
+
+	publisher, err := room.StartIngest(params)
+	if err != nil {
+		room.Release()
+		return nil, err
+	}
+	return &roomIngestPublisher{Publisher: publisher, room: room}, nil
+}
+
+func ingestRoomRequest(claims *auth.ClaimGrants) *livekit.CreateRoomRequest {
+	createRoom := &livekit.CreateRoomRequest{
+		Name:       claims.Video.Room,
+		RoomPreset: claims.RoomPreset,
+	}
+	SetRoomConfiguration(createRoom, claims.GetRoomConfiguration())
+	return createRoom
+}
+
+// roomIngestPublisher holds the room of a stream until its encoder disconnects
+type roomIngestPublisher struct {
+	ingest.Publisher
+	room    *rtc.Room
+	release sync.Once
+}
+
+func (p *roomIngestPublisher) Close() {
+	p.Publisher.Close()
+	p.release.Do(p.room.Release)
+}
@@ -19,6 +19,8 @@ import (
 	"time"
 
 	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate
@@ -56,6 +58,13 @@ type OSSServiceStore interface {
 	HasParticipant(context.Context, livekit.RoomName, livekit.ParticipantIdentity) (bool, error)
 }
 
+// persists learned noise profiles by participant identity
+type NoiseProfileStore interface {
+	StoreNoiseProfile(ctx context.Context, identity livekit.ParticipantIdentity, profile *audio.NoiseProfile, ttl time.Duration) error
+	LoadNoiseProfile(ctx context.Context, identity livekit.ParticipantIdentity) (*audio.NoiseProfile, error)
+	DeleteNoiseProfile(ctx context.Context, identity livekit.ParticipantIdentity) error
+}
+
 //counterfeiter:generate . EgressStore
 type EgressStore interface {
 	StoreEgress(ctx context.Context, info *livekit.EgressInfo) error
@@ -0,0 +1,235 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/latencyprobe"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	testclient "github.com/livekit/livekit-server/test/client"
+)
+
+const (
+	latencyProbePublisherIdentity  = "latency-probe-publisher"
+	latencyProbeSubscriberIdentity = "latency-probe-subscriber"
+)
+
+var ErrNoAPIKey = errors.New("no API key configured")
+
+func newLatencyProber(s *LivekitServer) *latencyprobe.Prober {
+	return latencyprobe.NewProber(latencyprobe.ProberParams{
+		Config:  s.config.LatencyProbe,
+		Connect: s.connectLatencyProbe,
+		Logger:  logger.GetLogger().WithComponent("latency_probe"),
+		OnResult: func(res *latencyprobe.Result, err error) {
+			prometheus.RecordLatencyProbe(
+				res.MarkersSent,
+				res.MarkersReceived,
+				time.Duration(res.P50Ms*float64(time.Millisecond)),
+				time.Duration(res.P95Ms*float64(time.Millisecond)),
+				err != nil,
+			)
+		},
+	})
+}
+
+// latencyProbe returns the result of the last latency probe, POST runs a probe and waits for its result
+func (s *LivekitServer) latencyProbe(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	var res *latencyprobe.Result
+	switch r.Method {
+	case http.MethodGet:
+		if res = s.latencyProber.LastResult(); res == nil {
+			HandleError(w, r, http.StatusNotFound, errors.New("no latency probe has completed"))
+			return
+		}
+
+	case http.MethodPost:
+		var err error
+		if res, err = s.latencyProber.Run(r.Context()); errors.Is(err, latencyprobe.ErrProbeRunning) {
+			HandleError(w, r, http.StatusConflict, err)
+			return
+		}
+
+	default:
+		HandleError(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(res)
+}
+
+// connectLatencyProbe joins a publishing and a subscribing test participant to a room of this node,
+// over the same signalling and media path as any client
+func (s *LivekitServer) connectLatencyProbe(ctx context.Context, room string) (latencyprobe.Loopback, error) {
+	apiKey, apiSecret, err := s.localAPIKey()
+	if err != nil {
+		return nil, err
+	}
+	host := s.localHost()
+
+	l := &latencyProbeLoopback{
+		onClose: func() {
+			// do not leave the room behind until the departure timeout
+			_, _ = s.roomManager.DeleteRoom(context.Background(), &livekit.DeleteRoomRequest{Room: room})
+		},
+	}
+
+	if l.subscriber, err = joinLocalParticipant(host, apiKey, apiSecret, room, latencyProbeSubscriberIdentity, true, nil); err != nil {
+		l.Close()
+		return nil, err
+	}
+	l.subscriber.OnRTPReceived = l.onRTP
+
+	if l.publisher, err = joinLocalParticipant(host, apiKey, apiSecret, room, latencyProbePublisherIdentity, false, nil); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if l.track, err = webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: mime.MimeTypeOpus.String(), ClockRate: 48000, Channels: 2},
+		"latency-probe",
+		"latency-probe",
+	); err != nil {
+		l.Close()
+		return nil, err
+	}
+	if _, err = l.publisher.AddTrack(l.track, "", testclient.AddTrackNoWriter()); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		l.Close()
+		return nil, err
+	}
+	return l, nil
+}
+
+// localAPIKey returns the first API key of the configuration, which built-in participants join with
+func (s *LivekitServer) localAPIKey() (string, string, error) {
+	if len(s.config.Keys) == 0 {
+		return "", "", ErrNoAPIKey
+	}
+	keys := make([]string, 0, len(s.config.Keys))
+	for key := range s.config.Keys {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys[0], s.config.Keys[keys[0]], nil
+}
+
+func (s *LivekitServer) localHost() string {
+	return fmt.Sprintf("ws://127.0.0.1:%d", s.config.Port)
+}
+
+// joinLocalParticipant joins a built-in participant to a room of this node and waits until it is connected
+func joinLocalParticipant(host, apiKey, apiSecret, room, identity string, subscribe bool, attributes map[string]string) (*testclient.RTCClient, error) {
+	token, err := auth.NewAccessToken(apiKey, apiSecret).
+		SetIdentity(identity).
+		SetAttributes(attributes).
+		SetVideoGrant(&auth.VideoGrant{
+			RoomJoin:     true,
+			Room:         room,
+			CanSubscribe: &subscribe,
+		}).
+		ToJWT()
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &testclient.Options{AutoSubscribe: subscribe}
+	conn, err := testclient.NewWebSocketConn(host, token, opts)
+	if err != nil {
+		return nil, err
+	}
+	c, err := testclient.NewRTCClient(conn, false, opts)
+	if err != nil {
+		return nil, err
+	}
+	go c.Run()
+
+	if err := c.WaitUntilConnected(); err != nil {
+		c.Stop()
+		return nil, err
+	}
+	return c, nil
+}
+
+// --------------------------------------
+
+type latencyProbeLoopback struct {
+	publisher  *testclient.RTCClient
+	subscriber *testclient.RTCClient
+	track      *webrtc.TrackLocalStaticSample
+	onClose    func()
+
+	lock    sync.Mutex
+	onFrame func(payload []byte, arrival time.Time)
+}
+
+func (l *latencyProbeLoopback) WriteFrame(payload []byte, duration time.Duration) error {
+	return l.track.WriteSample(media.Sample{Data: payload, Duration: duration})
+}
+
+func (l *latencyProbeLoopback) OnFrame(f func(payload []byte, arrival time.Time)) {
+	l.lock.Lock()
+	l.onFrame = f
+	l.lock.Unlock()
+}
+
+func (l *latencyProbeLoopback) onRTP(_ *webrtc.TrackRemote, pkt *rtp.Packet) {
+	arrival := time.Now()
+
+	l.lock.Lock()
+	onFrame := l.onFrame
+	l.lock.Unlock()
+
+	if onFrame != nil && len(pkt.Payload) != 0 {
+		onFrame(pkt.Payload, arrival)
+	}
+}
+
+func (l *latencyProbeLoopback) Close() {
+	if l.publisher != nil {
+		l.publisher.Stop()
+	}
+	if l.subscriber != nil {
+		l.subscriber.Stop()
+	}
+	l.onClose()
+}
@@ -0,0 +1,220 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"slices"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/interceptor"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+type shedTrack struct {
+	room     livekit.RoomName
+	identity livekit.ParticipantIdentity
+	trackID  livekit.TrackID
+}
+
+type sheddingCandidate struct {
+	shedTrack
+	subscribed bool
+	priority   placement.Priority
+	level      float64
+}
+
+// noiseFilterLoadShedder turns noise filtering off on the least important tracks of the node while it is
+// overloaded, i. e. unsubscribed tracks before subscribed ones, lower processing priorities first and quieter
+// tracks first, and back on in reverse order once the load recovered
+type noiseFilterLoadShedder struct {
+	config  audio.LoadSheddingConfig
+	shedder *audio.LoadShedder
+	rooms   func() []*rtc.Room
+	logger  logger.Logger
+
+	// in the order they were shed
+	shed []shedTrack
+
+	stop core.Fuse
+}
+
+func newNoiseFilterLoadShedder(conf audio.LoadSheddingConfig, rooms func() []*rtc.Room) *noiseFilterLoadShedder {
+	if !conf.Enabled {
+		return nil
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = audio.DefaultLoadSheddingConfig.Interval
+	}
+
+	s := &noiseFilterLoadShedder{
+		config:  conf,
+		shedder: audio.NewLoadShedder(conf),
+		rooms:   rooms,
+		logger:  logger.GetLogger().WithComponent("noise_filter_load_shedding"),
+	}
+	go s.worker()
+	return s
+}
+
+func (s *noiseFilterLoadShedder) Stop() {
+	if s == nil {
+		return
+	}
+
+	s.stop.Break()
+}
+
+func (s *noiseFilterLoadShedder) worker() {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.rebalance()
+
+		case <-s.stop.Watch():
+			return
+		}
+	}
+}
+
+func (s *noiseFilterLoadShedder) rebalance() {
+	cpuLoad, ok := prometheus.GetCPULoad()
+	if !ok {
+		return
+	}
+	frameLatency := interceptor.TakeFrameLatency()
+
+	candidates := s.candidates()
+	n := s.shedder.Observe(cpuLoad, frameLatency, len(candidates), len(s.shed))
+	switch {
+	case n > 0:
+		slices.SortFunc(candidates, compareSheddingCandidates)
+		var shed []shedTrack
+		var trackIDs []livekit.TrackID
+		for _, c := range candidates[:n] {
+			if s.setNoiseFilter(c.shedTrack, false) {
+				shed = append(shed, c.shedTrack)
+				trackIDs = append(trackIDs, c.trackID)
+			}
+		}
+		s.shed = append(s.shed, shed...)
+		prometheus.RecordNoiseFilterShedding("shed", len(shed), len(s.shed))
+		s.logger.Infow(
+			"node overloaded, noise filtering turned off",
+			"cpuLoad", cpuLoad,
+			"frameLatency", frameLatency,
+			"trackIDs", trackIDs,
+			"numShed", len(s.shed),
+			"numFiltered", len(candidates)-len(shed),
+		)
+
+	case n < 0:
+		// the most important tracks were shed last and are restored first
+		restored := s.shed[len(s.shed)+n:]
+		s.shed = s.shed[:len(s.shed)+n]
+		trackIDs := make([]livekit.TrackID, 0, len(restored))
+		for _, t := range restored {
+			// tracks that are gone are dropped all the same
+			s.setNoiseFilter(t, true)
+			trackIDs = append(trackIDs, t.trackID)
+		}
+		prometheus.RecordNoiseFilterShedding("restored", len(restored), len(s.shed))
+		s.logger.Infow(
+			"node load recovered, noise filtering turned back on",
+			"cpuLoad", cpuLoad,
+			"frameLatency", frameLatency,
+			"trackIDs", trackIDs,
+			"numShed", len(s.shed),
+		)
+	}
+}
+
+// candidates returns the audio tracks of the node that are noise filtered
+func (s *noiseFilterLoadShedder) candidates() []sheddingCandidate {
+	var candidates []sheddingCandidate
+	for _, room := range s.rooms() {
+		for _, p := range room.GetParticipants() {
+			for _, track := range p.GetPublishedTracks() {
+				if track.Kind() != livekit.TrackType_AUDIO {
+					continue
+				}
+				if t, ok := track.(interface{ IsNoiseFilterEnabled() bool }); !ok || !t.IsNoiseFilterEnabled() {
+					continue
+				}
+				level, _ := track.GetAudioLevel()
+				candidates = append(candidates, sheddingCandidate{
+					shedTrack: shedTrack{
+						room:     room.Name(),
+						identity: p.Identity(),
+						trackID:  track.ID(),
+					},
+					subscribed: track.GetNumSubscribers() > 0,
+					priority:   rtc.TrackPriority(p, track),
+					level:      level,
+				})
+			}
+		}
+	}
+	return candidates
+}
+
+// compareSheddingCandidates orders the least important tracks first
+func compareSheddingCandidates(a, b sheddingCandidate) int {
+	switch {
+	case a.subscribed != b.subscribed:
+		if !a.subscribed {
+			return -1
+		}
+		return 1
+	case a.priority != b.priority:
+		return int(a.priority - b.priority)
+	case a.level < b.level:
+		return -1
+	case a.level > b.level:
+		return 1
+	}
+	return 0
+}
+
+func (s *noiseFilterLoadShedder) setNoiseFilter(t shedTrack, enabled bool) bool {
+	for _, room := range s.rooms() {
+		if room.Name() != t.room {
+			continue
+		}
+		p, ok := room.GetParticipant(t.identity).(interface {
+			SetTrackNoiseFilter(trackID livekit.TrackID, enabled bool) error
+		})
+		if !ok {
+			return false
+		}
+		if err := p.SetTrackNoiseFilter(t.trackID, enabled); err != nil {
+			s.logger.Debugw("could not switch noise filter", "error", err, "room", t.room, "trackID", t.trackID)
+			return false
+		}
+		return true
+	}
+	return false
+}
@@ -16,6 +16,7 @@ package service
 
 import (
 	"context"
+	"maps"
 	"sync"
 	"time"
 
@@ -23,8 +24,27 @@ import (
 
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/utils"
+
+	"github.com/livekit/livekit-server/pkg/callflow"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 )
 
+type localCallFlowKey struct {
+	roomName livekit.RoomName
+	identity livekit.ParticipantIdentity
+}
+
+type localCallFlowState struct {
+	state     callflow.State
+	expiresAt time.Time
+}
+
+type localNoiseProfile struct {
+	profile   audio.NoiseProfile
+	expiresAt time.Time
+}
+
 // encapsulates CRUD operations for room settings
 type LocalStore struct {
 	// map of roomName => room
@@ -36,6 +56,9 @@ type LocalStore struct {
 	agentDispatches map[livekit.RoomName]map[string]*livekit.AgentDispatch
 	agentJobs       map[livekit.RoomName]map[string]*livekit.Job
 
+	noiseProfiles map[livekit.ParticipantIdentity]localNoiseProfile
+	callFlows     map[localCallFlowKey]localCallFlowState
+
 	lock       sync.RWMutex
 	globalLock sync.Mutex
 }
@@ -47,6 +70,8 @@ func NewLocalStore() *LocalStore {
 		participants:    make(map[livekit.RoomName]map[livekit.ParticipantIdentity]*livekit.ParticipantInfo),
 		agentDispatches: make(map[livekit.RoomName]map[string]*livekit.AgentDispatch),
 		agentJobs:       make(map[livekit.RoomName]map[string]*livekit.Job),
+		noiseProfiles:   make(map[livekit.ParticipantIdentity]localNoiseProfile),
+		callFlows:       make(map[localCallFlowKey]localCallFlowState),
 		lock:            sync.RWMutex{},
 	}
 }
@@ -284,3 +309,80 @@ func (s *LocalStore) DeleteAgentJob(ctx context.Context, job *livekit.Job) error
 
 	return nil
 }
+
+func (s *LocalStore) StoreNoiseProfile(_ context.Context, identity livekit.ParticipantIdentity, profile *audio.NoiseProfile, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	p := localNoiseProfile{profile: *profile}
+	if ttl > 0 {
+		p.expiresAt = time.Now().Add(ttl)
+	}
+	s.noiseProfiles[identity] = p
+	return nil
+}
+
+func (s *LocalStore) LoadNoiseProfile(_ context.Context, identity livekit.ParticipantIdentity) (*audio.NoiseProfile, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	p, ok := s.noiseProfiles[identity]
+	if !ok {
+		return nil, ErrNoiseProfileNotFound
+	}
+	if !p.expiresAt.IsZero() && time.Now().After(p.expiresAt) {
+		delete(s.noiseProfiles, identity)
+		return nil, ErrNoiseProfileNotFound
+	}
+
+	profile := p.profile
+	return &profile, nil
+}
+
+func (s *LocalStore) DeleteNoiseProfile(_ context.Context, identity livekit.ParticipantIdentity) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.noiseProfiles, identity)
+	return nil
+}
+
+func (s *LocalStore) StoreCallFlowState(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity, state *callflow.State, ttl time.Duration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	st := localCallFlowState{state: *state}
+	st.state.Variables = maps.Clone(state.Variables)
+	if ttl > 0 {
+		st.expiresAt = time.Now().Add(ttl)
+	}
+	s.callFlows[localCallFlowKey{roomName, identity}] = st
+	return nil
+}
+
+func (s *LocalStore) LoadCallFlowState(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*callflow.State, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := localCallFlowKey{roomName, identity}
+	st, ok := s.callFlows[key]
+	if !ok {
+		return nil, rtc.ErrCallFlowStateNotFound
+	}
+	if !st.expiresAt.IsZero() && time.Now().After(st.expiresAt) {
+		delete(s.callFlows, key)
+		return nil, rtc.ErrCallFlowStateNotFound
+	}
+
+	state := st.state
+	state.Variables = maps.Clone(st.state.Variables)
+	return &state, nil
+}
+
+func (s *LocalStore) DeleteCallFlowState(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.callFlows, localCallFlowKey{roomName, identity})
+	return nil
+}
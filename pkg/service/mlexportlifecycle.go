@@ -0,0 +1,67 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-server/pkg/mlexport"
+)
+
+// newMLExportLifecycle moves ML export archives to cold storage and deletes them by their project's
+// policy, every deletion is certified with a webhook event
+func newMLExportLifecycle(s *LivekitServer) *mlexport.Lifecycle {
+	lifecycleLogger := logger.GetLogger().WithComponent("ml_export_lifecycle")
+	return mlexport.NewLifecycle(mlexport.LifecycleParams{
+		Config: s.config.Room.MLExport,
+		OnDeleted: func(cert *mlexport.DeletionCertificate) {
+			lifecycleLogger.Infow("deleted ml export", "archive", cert.Archive, "tier", cert.Tier, "project", cert.Project, "sha256", cert.SHA256)
+			s.notifyArchiveDeleted(cert)
+		},
+		OnError: func(path string, err error) {
+			lifecycleLogger.Warnw("could not apply ml export lifecycle", err, "path", path)
+		},
+	})
+}
+
+// notifyArchiveDeleted sends the deletion certificate as the JSON metadata of the archive's room
+func (s *LivekitServer) notifyArchiveDeleted(cert *mlexport.DeletionCertificate) {
+	notifier, ok := s.roomManager.telemetry.(interface {
+		NotifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption)
+	})
+	if !ok {
+		return
+	}
+
+	payload, err := json.Marshal(cert)
+	if err != nil {
+		logger.Errorw("could not marshal deletion certificate", err)
+		return
+	}
+	notifier.NotifyEvent(context.Background(), &livekit.WebhookEvent{
+		Event: mlexport.WebhookEventArchiveDeleted,
+		Room: &livekit.Room{
+			Sid:      cert.RoomID,
+			Name:     cert.RoomName,
+			Metadata: string(payload),
+		},
+	})
+}
@@ -0,0 +1,106 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/nativecheck"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+)
+
+const minOpusVersion = "1.3"
+
+// CheckNativeLibraries loads and self tests the native libraries at startup. A library is required
+// when the configuration enables a feature depending on it, a failing required library
+// fails startup in strict mode.
+func CheckNativeLibraries(conf *config.Config) error {
+	if !conf.NativeCheck.Enabled {
+		return nil
+	}
+
+	results, err := nativecheck.CheckAll([]nativecheck.Library{
+		{
+			Name:     "rnnoise",
+			Required: conf.Audio.NoiseFilter.Enabled,
+			Load:     sfuinterceptor.RNNoiseLoad,
+			SelfTest: sfuinterceptor.RNNoiseSelfTest,
+		},
+		{
+			Name:     "opus",
+			Required: conf.Audio.Mixing.Enabled || conf.Room.MLExport.Enabled || conf.Audio.WakeWord.Enabled,
+			Load: func() error {
+				if !audio.IsOpusCodecAvailable() {
+					return audio.ErrOpusCodecUnavailable
+				}
+				return nil
+			},
+			Version:    audio.OpusVersion,
+			MinVersion: minOpusVersion,
+			SelfTest:   audio.OpusSelfTest,
+		},
+	})
+
+	for _, result := range results {
+		switch {
+		case result.OK():
+			logger.Infow("native library ready", "library", result.Library, "version", result.Version, "duration", result.Duration)
+		case result.Required:
+			logger.Errorw("native library check failed", result.Err, "library", result.Library, "stage", result.Err.Stage, "version", result.Version)
+		default:
+			logger.Infow("optional native library unavailable", "library", result.Library, "stage", result.Err.Stage, "error", result.Err.Err)
+		}
+	}
+
+	if err != nil && conf.NativeCheck.Strict {
+		return err
+	}
+	return nil
+}
+
+// CheckNoiseFilterModels validates the RNNoise models of the configuration at startup: presets and languages
+// must select named models, and when the noise filter is enabled every model file must load.
+func CheckNoiseFilterModels(conf *config.Config) error {
+	noiseFilter := conf.Audio.NoiseFilter
+	if err := audio.ValidateNoiseFilterModels(noiseFilter, conf.Room.NoiseFilterPresets); err != nil {
+		return err
+	}
+	if !noiseFilter.Enabled && len(conf.Room.NoiseFilterPresets) == 0 {
+		return nil
+	}
+	if err := sfuinterceptor.LoadRNNoiseModels(noiseFilter); err != nil {
+		return err
+	}
+	logger.Infow("noise filter models loaded", "modelPath", noiseFilter.ModelPath, "models", len(noiseFilter.Models))
+	return nil
+}
+
+// CheckWakeWordModel creates a detector of the configured wake word model at startup, the model has to be registered
+// with audio.RegisterWakeWordModel ahead of it
+func CheckWakeWordModel(conf *config.Config) error {
+	if !conf.Audio.WakeWord.Enabled {
+		return nil
+	}
+	detector, err := audio.NewWakeWordDetector(conf.Audio.WakeWord)
+	if err != nil {
+		return err
+	}
+	detector.Close()
+	logger.Infow("wake word model loaded", "model", conf.Audio.WakeWord.Model, "phrases", conf.Audio.WakeWord.Phrases)
+	return nil
+}
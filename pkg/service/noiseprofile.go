@@ -0,0 +1,106 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// participant attribute, set to "off" to opt out of noise profile persistence,
+	// a stored profile is deleted when the participant leaves opted out
+	NoiseProfileAttribute = "agentix.noise_profile"
+
+	noiseProfileStoreTimeout = 2 * time.Second
+)
+
+// noiseProfiles loads the noise profile of a participant when a session starts
+// and stores what was learned when it ends
+type noiseProfiles struct {
+	config audio.NoiseProfileConfig
+	store  NoiseProfileStore
+}
+
+func newNoiseProfiles(conf audio.NoiseProfileConfig, roomStore ObjectStore) *noiseProfiles {
+	if !conf.Enabled {
+		return nil
+	}
+
+	store, ok := roomStore.(NoiseProfileStore)
+	if !ok {
+		logger.Warnw("noise profile persistence not supported by room store", nil)
+		return nil
+	}
+
+	return &noiseProfiles{
+		config: conf,
+		store:  store,
+	}
+}
+
+func noiseProfileOptedOut(grants *auth.ClaimGrants) bool {
+	return grants != nil && grants.Attributes[NoiseProfileAttribute] == "off"
+}
+
+func (n *noiseProfiles) Load(identity livekit.ParticipantIdentity, grants *auth.ClaimGrants) *audio.NoiseProfile {
+	if n == nil || identity == "" || noiseProfileOptedOut(grants) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), noiseProfileStoreTimeout)
+	defer cancel()
+
+	profile, err := n.store.LoadNoiseProfile(ctx, identity)
+	if err != nil {
+		if !errors.Is(err, ErrNoiseProfileNotFound) {
+			logger.Warnw("could not load noise profile", err, "participant", identity)
+		}
+		return nil
+	}
+	return profile
+}
+
+func (n *noiseProfiles) Save(p types.LocalParticipant, profile *audio.NoiseProfile) {
+	if n == nil || p.Identity() == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), noiseProfileStoreTimeout)
+	defer cancel()
+
+	if noiseProfileOptedOut(p.ClaimGrants()) {
+		if err := n.store.DeleteNoiseProfile(ctx, p.Identity()); err != nil {
+			p.GetLogger().Warnw("could not delete noise profile", err)
+		}
+		return
+	}
+
+	if profile == nil {
+		return
+	}
+	if err := n.store.StoreNoiseProfile(ctx, p.Identity(), profile, n.config.TTL); err != nil {
+		p.GetLogger().Warnw("could not store noise profile", err)
+	}
+}
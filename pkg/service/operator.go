@@ -0,0 +1,126 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+// nodeDrainState is what the node reports to operators about a drain
+type nodeDrainState struct {
+	NodeID       string `json:"node_id"`
+	State        string `json:"state"`
+	Rooms        int    `json:"rooms"`
+	Participants int    `json:"participants"`
+}
+
+// nodeDrain reports the state of the node, and with POST stops new rooms and participants from being
+// routed to the node. Hosted rooms stay until their participants leave.
+func (s *LivekitServer) nodeDrain(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		if err := EnsureListPermission(r.Context()); err != nil {
+			HandleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+
+	case http.MethodPost:
+		if err := EnsureCreatePermission(r.Context()); err != nil {
+			HandleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+		logger.Infow("draining node on request", "nodeID", s.currentNode.NodeID(), "apiKey", GetAPIKey(r.Context()))
+		s.router.Drain()
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	state := nodeDrainState{
+		NodeID: string(s.currentNode.NodeID()),
+		State:  s.currentNode.Clone().State.String(),
+	}
+	for _, room := range s.roomManager.localRooms() {
+		state.Rooms++
+		state.Participants += len(room.GetParticipants())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(state)
+}
+
+// tailEvents streams the webhook events raised on this node as newline delimited JSON until the client
+// goes away, of the room given by the room query parameter or of all rooms
+func (s *LivekitServer) tailEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	roomName := livekit.RoomName(r.URL.Query().Get("room"))
+	if roomName != "" {
+		if err := EnsureAdminPermission(r.Context(), roomName); err != nil {
+			HandleError(w, r, http.StatusUnauthorized, err)
+			return
+		}
+	} else if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	feed, ok := s.roomManager.telemetry.(interface {
+		SubscribeEvents(roomName livekit.RoomName) (<-chan *livekit.WebhookEvent, func())
+	})
+	flusher, canFlush := w.(http.Flusher)
+	if !ok || !canFlush {
+		HandleError(w, r, http.StatusNotImplemented, ErrEventFeedUnavailable)
+		return
+	}
+
+	events, stop := feed.SubscribeEvents(roomName)
+	defer stop()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case <-s.closedChan:
+			return
+
+		case event := <-events:
+			line, err := protojson.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
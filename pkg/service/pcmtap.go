@@ -0,0 +1,180 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/rtc"
+)
+
+// PCMTapServer serves the agentix.pcmtap.PCMTap gRPC service of pkg/pcmtap/pcmtap.proto. Every call
+// streams from the ring buffer of its subscriber, sending blocks under HTTP/2 flow control while the
+// consumer falls behind, which lets the buffer fill and drop the oldest audio.
+type PCMTapServer struct {
+	pcmtap.UnimplementedPCMTapServer
+
+	config      pcmtap.Config
+	keyProvider auth.KeyProvider
+	roomManager *RoomManager
+	server      *grpc.Server
+	logger      logger.Logger
+}
+
+func newPCMTapServer(conf pcmtap.Config, keyProvider auth.KeyProvider, roomManager *RoomManager) *PCMTapServer {
+	p := &PCMTapServer{
+		config:      conf,
+		keyProvider: keyProvider,
+		roomManager: roomManager,
+		server:      grpc.NewServer(),
+		logger:      logger.GetLogger().WithComponent("pcm_tap"),
+	}
+	pcmtap.RegisterPCMTapServer(p.server, p)
+	return p
+}
+
+func (p *PCMTapServer) Start() error {
+	if p == nil {
+		return nil
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf(":%d", p.config.Port))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := p.server.Serve(l); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			p.logger.Errorw("pcm tap server failed", err)
+		}
+	}()
+	p.logger.Infow("pcm tap listening", "port", p.config.Port)
+	return nil
+}
+
+func (p *PCMTapServer) Stop() {
+	if p == nil {
+		return
+	}
+	// streams end with the rooms, which close before the server stops
+	p.server.Stop()
+}
+
+func (p *PCMTapServer) Subscribe(req *pcmtap.SubscribeRequest, stream grpc.ServerStreamingServer[pcmtap.AudioFrame]) error {
+	ctx := stream.Context()
+	roomName := livekit.RoomName(req.RoomName)
+	if err := p.authorize(ctx, roomName); err != nil {
+		return err
+	}
+
+	room := p.roomManager.GetRoom(ctx, roomName)
+	if room == nil {
+		return status.Error(codes.NotFound, "room is not hosted on this node")
+	}
+
+	sampleRate := int(req.SampleRate)
+	if sampleRate == 0 && req.Profile == pcmtap.ProfileRaw {
+		sampleRate = pcmtap.SampleRate48k
+	}
+	sub, err := room.SubscribePCM(livekit.TrackID(req.TrackSid), req.Profile, sampleRate)
+	if err != nil {
+		return pcmTapStatus(err)
+	}
+	defer sub.Close()
+
+	p.logger.Debugw("pcm tap subscribed", "room", roomName, "trackID", req.TrackSid, "sampleRate", sub.SampleRate(), "profile", req.Profile)
+	for {
+		f, err := sub.Next(ctx)
+		switch {
+		case errors.Is(err, io.EOF):
+			return nil
+		case err != nil:
+			return status.FromContextError(err).Err()
+		}
+		if err := stream.Send(pcmtap.NewAudioFrame(f)); err != nil {
+			return err
+		}
+	}
+}
+
+// authorize verifies the access token of the call, which needs to grant recording, or administration of the room
+func (p *PCMTapServer) authorize(ctx context.Context, roomName livekit.RoomName) error {
+	ctx, err := withGRPCGrants(ctx, p.keyProvider)
+	if err != nil {
+		return err
+	}
+	if EnsureRecordPermission(ctx) != nil && EnsureAdminPermission(ctx, roomName) != nil {
+		return status.Error(codes.PermissionDenied, ErrPermissionDenied.Error())
+	}
+	return nil
+}
+
+// withGRPCGrants verifies the access token in the authorization metadata of a gRPC call and returns
+// the context with its grants
+func withGRPCGrants(ctx context.Context, keyProvider auth.KeyProvider) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 || !strings.HasPrefix(values[0], bearerPrefix) {
+		return nil, status.Error(codes.Unauthenticated, ErrMissingAuthorization.Error())
+	}
+
+	v, err := auth.ParseAPIToken(values[0][len(bearerPrefix):])
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, ErrInvalidAuthorizationToken.Error())
+	}
+	secret := keyProvider.GetSecret(v.APIKey())
+	if secret == "" {
+		return nil, status.Error(codes.Unauthenticated, ErrInvalidAPIKey.Error())
+	}
+	grants, err := v.Verify(secret)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, ErrInvalidAuthorizationToken.Error())
+	}
+	return WithGrants(ctx, grants, v.APIKey()), nil
+}
+
+func pcmTapStatus(err error) error {
+	switch {
+	case errors.Is(err, pcmtap.ErrUnsupportedSampleRate), errors.Is(err, pcmtap.ErrUnsupportedProfile):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, rtc.ErrPCMTapNoAudioTrack):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, rtc.ErrPCMTapNoConsent):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, rtc.ErrPCMTapNotOpus), errors.Is(err, rtc.ErrPCMTapDisabled),
+		errors.Is(err, rtc.ErrRoomMixDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, pcmtap.ErrTooManySubscribers):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, pcmtap.ErrStreamClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,237 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pion/turn/v4"
+
+	redisLiveKit "github.com/livekit/protocol/redis"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/preflight"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
+	"github.com/livekit/livekit-server/version"
+)
+
+const preflightRedisKeyPrefix = "preflight:"
+
+// RunPreflight runs the startup self-test suite against the configuration of the node, without starting it.
+// Checks of features the configuration does not use are skipped.
+func RunPreflight(ctx context.Context, conf *config.Config) (preflight.Report, error) {
+	pc := conf.Preflight
+	if err := pc.Validate(); err != nil {
+		return preflight.Report{}, err
+	}
+
+	denoise := preflight.BenchmarkCheck(
+		preflight.CheckDenoise,
+		sfuinterceptor.RNNoiseFrameDuration,
+		pc.BenchmarkDuration,
+		pc.MinRealtimeFactor,
+		sfuinterceptor.RNNoiseBenchmark,
+	)
+	if !conf.Audio.NoiseFilter.Enabled {
+		denoise.Skip = "noise filter is disabled"
+	}
+
+	report := preflight.Run(ctx, pc, []preflight.Check{
+		preflight.CodecCheck(pc.CodecFrames),
+		denoise,
+		preflight.UDPPortsCheck(conf.BindAddresses, preflightUDPPorts(conf)),
+		preflightTURNCheck(conf.RTC.TURNServers),
+		preflightRedisCheck(conf),
+		preflightStorageCheck(conf),
+	})
+	report.Version = version.Version
+	return report, nil
+}
+
+// preflightUDPPorts returns the UDP ports the node listens on for ICE and TURN
+func preflightUDPPorts(conf *config.Config) []int {
+	var ports []int
+	if !conf.RTC.ForceTCP {
+		if conf.RTC.UDPPort.Valid() {
+			for port := conf.RTC.UDPPort.Start; port <= max(conf.RTC.UDPPort.End, conf.RTC.UDPPort.Start); port++ {
+				ports = append(ports, port)
+			}
+		} else if conf.RTC.ICEPortRangeStart != 0 {
+			for port := conf.RTC.ICEPortRangeStart; port <= conf.RTC.ICEPortRangeEnd; port++ {
+				ports = append(ports, int(port))
+			}
+		}
+	}
+	if conf.TURN.Enabled && conf.TURN.UDPPort > 0 {
+		ports = append(ports, conf.TURN.UDPPort)
+	}
+	return ports
+}
+
+// preflightTURNCheck allocates a relay on each of the TURN servers handed to clients
+func preflightTURNCheck(servers []config.TURNServer) preflight.Check {
+	check := preflight.Check{
+		Name: preflight.CheckTURN,
+		Run: func(ctx context.Context) (preflight.Details, error) {
+			details := preflight.Details{}
+			var errs []error
+			for _, server := range servers {
+				addr := net.JoinHostPort(server.Host, strconv.Itoa(server.Port))
+				start := time.Now()
+				relayed, err := allocateTURNRelay(ctx, server, addr)
+				if err != nil {
+					errs = append(errs, fmt.Errorf("%s %s: %w", server.Protocol, addr, err))
+					continue
+				}
+				details[server.Protocol+"://"+addr] = preflight.Details{
+					"relayed_address": relayed,
+					"allocation_ms":   time.Since(start).Milliseconds(),
+				}
+			}
+			return details, errors.Join(errs...)
+		},
+	}
+	if len(servers) == 0 {
+		check.Skip = "no turn servers configured"
+	}
+	return check
+}
+
+func allocateTURNRelay(ctx context.Context, server config.TURNServer, addr string) (string, error) {
+	var conn net.PacketConn
+	switch server.Protocol {
+	case "tcp", "tls":
+		dialer := &net.Dialer{}
+		var c net.Conn
+		var err error
+		if server.Protocol == "tls" {
+			c, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: server.Host}}).DialContext(ctx, "tcp", addr)
+		} else {
+			c, err = dialer.DialContext(ctx, "tcp", addr)
+		}
+		if err != nil {
+			return "", err
+		}
+		conn = turn.NewSTUNConn(c)
+
+	default:
+		c, err := net.ListenPacket("udp4", "0.0.0.0:0")
+		if err != nil {
+			return "", err
+		}
+		conn = c
+	}
+	defer conn.Close()
+
+	client, err := turn.NewClient(&turn.ClientConfig{
+		STUNServerAddr: addr,
+		TURNServerAddr: addr,
+		Conn:           conn,
+		Username:       server.Username,
+		Password:       server.Credential,
+	})
+	if err != nil {
+		return "", err
+	}
+	defer client.Close()
+	if err := client.Listen(); err != nil {
+		return "", err
+	}
+
+	relay, err := client.Allocate()
+	if err != nil {
+		return "", err
+	}
+	defer relay.Close()
+	return relay.LocalAddr().String(), nil
+}
+
+// preflightRedisCheck writes, reads and deletes a key, reporting the round trip times
+func preflightRedisCheck(conf *config.Config) preflight.Check {
+	check := preflight.Check{
+		Name: preflight.CheckRedis,
+		Run: func(ctx context.Context) (preflight.Details, error) {
+			start := time.Now()
+			rc, err := redisLiveKit.GetRedisClient(&conf.Redis)
+			if err != nil {
+				return nil, err
+			}
+			defer rc.Close()
+			connected := time.Since(start)
+
+			key := preflightRedisKeyPrefix + guid.New("PF_")
+			value := strconv.FormatInt(time.Now().UnixNano(), 10)
+
+			start = time.Now()
+			if err := rc.Set(ctx, key, value, time.Minute).Err(); err != nil {
+				return nil, fmt.Errorf("set: %w", err)
+			}
+			read, err := rc.Get(ctx, key).Result()
+			if err != nil {
+				return nil, fmt.Errorf("get: %w", err)
+			}
+			if err := rc.Del(ctx, key).Err(); err != nil {
+				return nil, fmt.Errorf("del: %w", err)
+			}
+			if read != value {
+				return nil, fmt.Errorf("read %q, expected %q", read, value)
+			}
+
+			return preflight.Details{
+				"connect_ms": connected.Milliseconds(),
+				// set, get and delete
+				"round_trip_us": time.Since(start).Microseconds() / 3,
+			}, nil
+		},
+	}
+	if !conf.Redis.IsConfigured() {
+		check.Skip = "redis is not configured"
+	}
+	return check
+}
+
+// preflightStorageCheck probes the directories the enabled features write to
+func preflightStorageCheck(conf *config.Config) preflight.Check {
+	dirs := make(map[string]string)
+	if mlExport := conf.Room.MLExport; mlExport.Enabled {
+		dirs["ml_export"] = mlExport.OutputDir
+		if mlExport.Lifecycle.Enabled {
+			dirs["ml_export_cold"] = mlExport.Lifecycle.ColdDir
+		}
+	}
+	if conf.EventExport.Enabled && conf.EventExport.BackfillDir != "" {
+		dirs["event_export_backfill"] = conf.EventExport.BackfillDir
+	}
+	if conf.RTC.Capture.Enabled {
+		dirs["capture"] = conf.RTC.Capture.Dir
+	}
+	if dump := conf.Audio.NoiseFilter.DebugDump; dump.Enabled && dump.Directory != "" {
+		dirs["audio_debug_dump"] = dump.Directory
+	}
+
+	check := preflight.StorageCheck(dirs, conf.Preflight.StorageProbeSize)
+	if len(dirs) == 0 {
+		check.Skip = "no storage configured"
+	}
+	return check
+}
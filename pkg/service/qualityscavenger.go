@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/placement"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+)
+
+// qualityScavenger spends idle CPU of the node on the quality of re-encoded streams, backing off
+// before the node selector stops placing rooms here or the placement queues back up
+type qualityScavenger struct {
+	config       audio.QualityScavengingConfig
+	registry     *audio.EncoderRegistry
+	placer       *placement.Placer
+	cpuLoadLimit float64
+	logger       logger.Logger
+
+	stop core.Fuse
+}
+
+func newQualityScavenger(conf audio.QualityScavengingConfig, cpuLoadLimit float32, placer *placement.Placer) *qualityScavenger {
+	if !conf.Enabled {
+		return nil
+	}
+	if conf.Interval <= 0 {
+		conf.Interval = audio.DefaultQualityScavengingConfig.Interval
+	}
+
+	s := &qualityScavenger{
+		config:       conf,
+		registry:     audio.DefaultEncoderRegistry,
+		placer:       placer,
+		cpuLoadLimit: float64(cpuLoadLimit),
+		logger:       logger.GetLogger().WithComponent("quality_scavenger"),
+	}
+	s.registry.Enable(conf)
+	go s.worker()
+	return s
+}
+
+func (s *qualityScavenger) Stop() {
+	if s == nil {
+		return
+	}
+
+	s.stop.Break()
+}
+
+func (s *qualityScavenger) worker() {
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.rebalance()
+
+		case <-s.stop.Watch():
+			return
+		}
+	}
+}
+
+func (s *qualityScavenger) rebalance() {
+	cpuLoad, ok := prometheus.GetCPULoad()
+	if !ok {
+		return
+	}
+
+	backlog := s.placer.Backlog()
+	upgraded, downgraded := s.registry.Rebalance(cpuLoad, backlog, s.cpuLoadLimit)
+	if upgraded == 0 && downgraded == 0 {
+		return
+	}
+
+	numUpgraded, numStreams := s.registry.Counts()
+	s.logger.Debugw(
+		"re-encoding quality rebalanced",
+		"cpuLoad", cpuLoad,
+		"placementBacklog", backlog,
+		"upgraded", upgraded,
+		"downgraded", downgraded,
+		"numUpgraded", numUpgraded,
+		"numStreams", numStreams,
+	)
+}
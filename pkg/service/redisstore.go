@@ -16,6 +16,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"slices"
 	"strconv"
@@ -34,6 +35,9 @@ import (
 	"github.com/livekit/protocol/utils/guid"
 	"github.com/livekit/psrpc"
 
+	"github.com/livekit/livekit-server/pkg/callflow"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/version"
 )
 
@@ -65,6 +69,12 @@ const (
 	AgentDispatchPrefix = "agent_dispatch:"
 	AgentJobPrefix      = "agent_job:"
 
+	// NoiseProfilePrefix is a key per participant identity containing the JSON encoded noise profile
+	NoiseProfilePrefix = "noise_profile:"
+
+	// CallFlowPrefix is a key per room and participant identity containing the JSON encoded state of its call flow
+	CallFlowPrefix = "call_flow:"
+
 	maxRetries = 5
 )
 
@@ -345,6 +355,34 @@ func (s *RedisStore) DeleteParticipant(_ context.Context, roomName livekit.RoomN
 	return s.rc.HDel(s.ctx, key, string(identity)).Err()
 }
 
+func (s *RedisStore) StoreNoiseProfile(_ context.Context, identity livekit.ParticipantIdentity, profile *audio.NoiseProfile, ttl time.Duration) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Set(s.ctx, NoiseProfilePrefix+string(identity), data, ttl).Err()
+}
+
+func (s *RedisStore) LoadNoiseProfile(_ context.Context, identity livekit.ParticipantIdentity) (*audio.NoiseProfile, error) {
+	data, err := s.rc.Get(s.ctx, NoiseProfilePrefix+string(identity)).Bytes()
+	if err == redis.Nil {
+		return nil, ErrNoiseProfileNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	profile := &audio.NoiseProfile{}
+	if err := json.Unmarshal(data, profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *RedisStore) DeleteNoiseProfile(_ context.Context, identity livekit.ParticipantIdentity) error {
+	return s.rc.Del(s.ctx, NoiseProfilePrefix+string(identity)).Err()
+}
+
 func (s *RedisStore) StoreEgress(_ context.Context, info *livekit.EgressInfo) error {
 	data, err := proto.Marshal(info)
 	if err != nil {
@@ -1081,3 +1119,35 @@ func sortPage[T any, P protoEntity[T]](items []P, page *livekit.Pagination) []P
 	}
 	return items
 }
+
+func callFlowKey(roomName livekit.RoomName, identity livekit.ParticipantIdentity) string {
+	return CallFlowPrefix + string(roomName) + ":" + string(identity)
+}
+
+func (s *RedisStore) StoreCallFlowState(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity, state *callflow.State, ttl time.Duration) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	return s.rc.Set(s.ctx, callFlowKey(roomName, identity), data, ttl).Err()
+}
+
+func (s *RedisStore) LoadCallFlowState(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) (*callflow.State, error) {
+	data, err := s.rc.Get(s.ctx, callFlowKey(roomName, identity)).Bytes()
+	if err == redis.Nil {
+		return nil, rtc.ErrCallFlowStateNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	state := &callflow.State{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+func (s *RedisStore) DeleteCallFlowState(_ context.Context, roomName livekit.RoomName, identity livekit.ParticipantIdentity) error {
+	return s.rc.Del(s.ctx, callFlowKey(roomName, identity)).Err()
+}
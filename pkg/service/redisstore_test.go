@@ -30,6 +30,7 @@ import (
 	"github.com/livekit/protocol/utils/guid"
 
 	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 )
 
 func redisStoreDocker(t testing.TB) *service.RedisStore {
@@ -115,6 +116,41 @@ func TestParticipantPersistence(t *testing.T) {
 	require.Equal(t, err, service.ErrParticipantNotFound)
 }
 
+func TestNoiseProfileStore(t *testing.T) {
+	ctx := context.Background()
+
+	stores := map[string]service.NoiseProfileStore{
+		"redis": redisStore(t),
+		"local": service.NewLocalStore(),
+	}
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			identity := livekit.ParticipantIdentity("noise_profile_test")
+			_ = store.DeleteNoiseProfile(ctx, identity)
+
+			_, err := store.LoadNoiseProfile(ctx, identity)
+			require.ErrorIs(t, err, service.ErrNoiseProfileNotFound)
+
+			profile := &audio.NoiseProfile{
+				NoiseFloor:     -42,
+				Threshold:      0.6,
+				Aggressiveness: 2,
+				Frames:         1000,
+				UpdatedAt:      time.Unix(1700000000, 0).UTC(),
+			}
+			require.NoError(t, store.StoreNoiseProfile(ctx, identity, profile, time.Minute))
+
+			actual, err := store.LoadNoiseProfile(ctx, identity)
+			require.NoError(t, err)
+			require.Equal(t, profile, actual)
+
+			require.NoError(t, store.DeleteNoiseProfile(ctx, identity))
+			_, err = store.LoadNoiseProfile(ctx, identity)
+			require.ErrorIs(t, err, service.ErrNoiseProfileNotFound)
+		})
+	}
+}
+
 func TestRoomLock(t *testing.T) {
 	ctx := context.Background()
 	rs := redisStore(t)
@@ -25,6 +25,7 @@ import (
 	"github.com/livekit/protocol/utils/guid"
 	"github.com/livekit/psrpc"
 
+	"github.com/livekit/livekit-server/pkg/agent"
 	"github.com/livekit/livekit-server/pkg/config"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/routing/selector"
@@ -113,6 +114,12 @@ func (r *StandardRoomAllocator) CreateRoom(ctx context.Context, req *livekit.Cre
 	if req.Agents != nil {
 		internal.AgentDispatches = req.Agents
 	}
+	if created && len(internal.AgentDispatches) == 0 {
+		// rooms created without agents get those of the dispatch rules matching their name
+		if dispatches := agent.RoomAgentDispatches(r.config.Agents.DispatchRules, req.Name); dispatches != nil {
+			internal.AgentDispatches = dispatches
+		}
+	}
 	if req.MinPlayoutDelay > 0 || req.MaxPlayoutDelay > 0 {
 		internal.PlayoutDelay = &livekit.PlayoutDelay{
 			Enabled: true,
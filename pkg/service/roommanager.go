@@ -25,7 +25,9 @@ import (
 	"golang.org/x/exp/maps"
 
 	"github.com/livekit/livekit-server/pkg/agent"
+	"github.com/livekit/livekit-server/pkg/placement"
 	"github.com/livekit/livekit-server/pkg/sfu"
+	sfuinterceptor "github.com/livekit/livekit-server/pkg/sfu/interceptor"
 	sutils "github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/mediatransportutil/pkg/rtcconfig"
 	"github.com/livekit/protocol/auth"
@@ -39,11 +41,15 @@ import (
 	"github.com/livekit/psrpc"
 	"github.com/livekit/psrpc/pkg/middleware"
 
+	"github.com/livekit/livekit-server/pkg/callflow"
 	"github.com/livekit/livekit-server/pkg/clientconfiguration"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/metadata"
+	"github.com/livekit/livekit-server/pkg/roomapi"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/rtc/types"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	"github.com/livekit/livekit-server/version"
@@ -81,10 +87,12 @@ type RoomManager struct {
 	versionGenerator  utils.TimedVersionGenerator
 	turnAuthHandler   *TURNAuthHandler
 	bus               psrpc.MessageBus
+	sipClient         rpc.SIPClient
 
 	rooms map[livekit.RoomName]*rtc.Room
 
 	roomServers                  utils.MultitonService[rpc.RoomTopic]
+	roomProcessingServers        utils.MultitonService[rpc.RoomTopic]
 	agentDispatchServers         utils.MultitonService[rpc.RoomTopic]
 	participantServers           utils.MultitonService[rpc.ParticipantTopic]
 	httpSignalParticipantServers utils.MultitonService[rpc.ParticipantTopic]
@@ -94,6 +102,23 @@ type RoomManager struct {
 
 	forwardStats *sfu.ForwardStats
 
+	placer *placement.Placer
+
+	noiseProfiles *noiseProfiles
+
+	noiseFilterCompat *rtc.NoiseFilterCompatibility
+
+	callFlows map[string]*callflow.Flow
+
+	qualityScavenger *qualityScavenger
+	loadShedder      *noiseFilterLoadShedder
+	warmPool         *warmRoomPool
+
+	// audio configuration of participants and rooms created from now on, replaced by UpdateAudioConfig
+	audioLock          sync.RWMutex
+	audioConfig        sfu.AudioConfig
+	noiseFilterPresets map[string]audio.NoiseFilterOverride
+
 	rpc.UnimplementedParticipantServer
 	rpc.UnimplementedRoomServer
 	rpc.UnimplementedRoomManagerServer
@@ -113,11 +138,27 @@ func NewLocalRoomManager(
 	turnAuthHandler *TURNAuthHandler,
 	bus psrpc.MessageBus,
 	forwardStats *sfu.ForwardStats,
+	interceptors []rtc.InterceptorStage,
+	sipClient rpc.SIPClient,
 ) (*RoomManager, error) {
 	rtcConf, err := rtc.NewWebRTCConfig(conf)
 	if err != nil {
 		return nil, err
 	}
+	rtcConf.Interceptors = interceptors
+
+	noiseFilterCompat, err := rtc.NewNoiseFilterCompatibility(conf.Audio.NoiseFilter.Compatibility)
+	if err != nil {
+		return nil, err
+	}
+
+	callFlows := make(map[string]*callflow.Flow, len(conf.Room.CallFlows))
+	for name, flow := range conf.Room.CallFlows {
+		if err := flow.Validate(); err != nil {
+			return nil, fmt.Errorf("call flow %q: %w", name, err)
+		}
+		callFlows[name] = &flow
+	}
 
 	r := &RoomManager{
 		config:            conf,
@@ -134,7 +175,15 @@ func NewLocalRoomManager(
 		versionGenerator:  versionGenerator,
 		turnAuthHandler:   turnAuthHandler,
 		bus:               bus,
+		sipClient:         sipClient,
 		forwardStats:      forwardStats,
+		placer:            placement.NewPlacer(conf.Placement, logger.GetLogger()),
+		noiseProfiles:     newNoiseProfiles(conf.Audio.NoiseProfile, roomStore),
+		noiseFilterCompat: noiseFilterCompat,
+		callFlows:         callFlows,
+
+		audioConfig:        conf.Audio,
+		noiseFilterPresets: conf.Room.NoiseFilterPresets,
 
 		rooms: make(map[livekit.RoomName]*rtc.Room),
 
@@ -170,6 +219,11 @@ func NewLocalRoomManager(
 		return nil, err
 	}
 
+	r.qualityScavenger = newQualityScavenger(conf.Audio.QualityScavenging, conf.NodeSelector.CPULoadLimit, r.placer)
+	r.loadShedder = newNoiseFilterLoadShedder(conf.Audio.LoadShedding, r.localRooms)
+	sfuinterceptor.PrewarmDenoisers(conf.Audio.NoiseFilter.Instances)
+	r.warmPool = newWarmRoomPool(conf.Room.WarmPool, r)
+
 	return r, nil
 }
 
@@ -208,6 +262,13 @@ func (r *RoomManager) deleteRoom(ctx context.Context, roomName livekit.RoomName)
 	return err
 }
 
+func (r *RoomManager) localRooms() []*rtc.Room {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	return maps.Values(r.rooms)
+}
+
 func (r *RoomManager) CloseIdleRooms() {
 	r.lock.RLock()
 	rooms := maps.Values(r.rooms)
@@ -218,6 +279,47 @@ func (r *RoomManager) CloseIdleRooms() {
 	}
 }
 
+// roomAudioConfig returns the audio configuration of a room, with the noise filter configuration
+// the room selects through its metadata
+func (r *RoomManager) roomAudioConfig(metadata string, lgr logger.Logger) sfu.AudioConfig {
+	r.audioLock.RLock()
+	audioConfig, presets := r.audioConfig, r.noiseFilterPresets
+	r.audioLock.RUnlock()
+
+	noiseFilter, ok, err := audio.RoomNoiseFilterConfig(audioConfig.NoiseFilter, presets, metadata)
+	if err != nil {
+		lgr.Warnw("ignoring noise filter override of room", err)
+	}
+	if ok {
+		audioConfig.NoiseFilter = noiseFilter
+	}
+	return audioConfig
+}
+
+// UpdateAudioConfig replaces the audio configuration, e. g. after the config file changed. Rooms and participants
+// created afterwards use it, participants already in a room switch their noise filter to it, with the noise filter
+// override of their room applied.
+func (r *RoomManager) UpdateAudioConfig(audioConfig sfu.AudioConfig, noiseFilterPresets map[string]audio.NoiseFilterOverride) {
+	r.audioLock.Lock()
+	r.audioConfig = audioConfig
+	r.noiseFilterPresets = noiseFilterPresets
+	r.audioLock.Unlock()
+
+	r.lock.RLock()
+	rooms := maps.Values(r.rooms)
+	r.lock.RUnlock()
+
+	for _, room := range rooms {
+		roomAudioConfig := r.roomAudioConfig(room.ToProto().Metadata, room.Logger())
+		for _, p := range room.GetParticipants() {
+			if lp, ok := p.(interface{ UpdateAudioConfig(config sfu.AudioConfig) }); ok {
+				lp.UpdateAudioConfig(roomAudioConfig)
+			}
+		}
+	}
+	logger.Infow("audio configuration updated", "rooms", len(rooms), "noiseFilter", audioConfig.NoiseFilter.Enabled)
+}
+
 func (r *RoomManager) HasParticipants() bool {
 	r.lock.RLock()
 	defer r.lock.RUnlock()
@@ -231,6 +333,8 @@ func (r *RoomManager) HasParticipants() bool {
 }
 
 func (r *RoomManager) Stop() {
+	r.warmPool.Stop()
+
 	// disconnect all clients
 	r.lock.RLock()
 	rooms := maps.Values(r.rooms)
@@ -243,11 +347,16 @@ func (r *RoomManager) Stop() {
 	r.roomManagerServer.Kill()
 	r.whipServer.Kill()
 	r.roomServers.Kill()
+	r.roomProcessingServers.Kill()
 	r.agentDispatchServers.Kill()
 	r.participantServers.Kill()
 	r.httpSignalParticipantServers.Kill()
 	r.whipParticipantServers.Kill()
 
+	r.qualityScavenger.Stop()
+	r.loadShedder.Stop()
+	r.placer.Stop()
+
 	if r.rtcConfig != nil {
 		if r.rtcConfig.UDPMux != nil {
 			_ = r.rtcConfig.UDPMux.Close()
@@ -400,7 +509,7 @@ func (r *RoomManager) StartSession(
 
 	sid := livekit.ParticipantID(guid.New(utils.ParticipantPrefix))
 	pLogger := rtc.LoggerWithParticipant(
-		rtc.LoggerWithRoom(logger.GetLogger(), room.Name(), room.ID()),
+		room.CaptureLogs(rtc.LoggerWithRoom(logger.GetLogger(), room.Name(), room.ID())),
 		pi.Identity,
 		sid,
 		false,
@@ -415,6 +524,11 @@ func (r *RoomManager) StartSession(
 
 	clientConf := r.clientConfManager.GetConfiguration(pi.Client)
 
+	if attrs := rtc.NegotiateExtensions(r.config, pi.Grants.Attributes); attrs != nil {
+		pi.Grants = pi.Grants.Clone()
+		maps.Copy(pi.Grants.Attributes, attrs)
+	}
+
 	pv := types.ProtocolVersion(pi.Client.Protocol)
 	rtcConf := *r.rtcConfig
 	rtcConf.SetBufferFactory(room.GetBufferFactory())
@@ -458,7 +572,7 @@ func (r *RoomManager) StartSession(
 		SID:                     sid,
 		Config:                  &rtcConf,
 		Sink:                    responseSink,
-		AudioConfig:             r.config.Audio,
+		AudioConfig:             r.roomAudioConfig(room.ToProto().Metadata, room.Logger()),
 		VideoConfig:             r.config.Video,
 		LimitConfig:             r.config.Limit,
 		ProtocolVersion:         pv,
@@ -466,6 +580,7 @@ func (r *RoomManager) StartSession(
 		Telemetry:               r.telemetry,
 		Trailer:                 room.Trailer(),
 		PLIThrottleConfig:       r.config.RTC.PLIThrottle,
+		FanOutConfig:            r.config.RTC.FanOut,
 		CongestionControlConfig: r.config.RTC.CongestionControl,
 		PublishEnabledCodecs:    protoRoom.EnabledCodecs,
 		SubscribeEnabledCodecs:  protoRoom.EnabledCodecs,
@@ -499,6 +614,10 @@ func (r *RoomManager) StartSession(
 		DatachannelSlowThreshold:     r.config.RTC.DatachannelSlowThreshold,
 		FireOnTrackBySdp:             true,
 		UseSinglePeerConnection:      pi.UseSinglePeerConnection,
+		NoiseProfile:                 r.noiseProfiles.Load(pi.Identity, pi.Grants),
+		NoiseFilterCompatibility:     r.noiseFilterCompat,
+		OpusFmtp:                     r.config.Room.OpusFmtp,
+		MetadataConfig:               r.config.Room.Metadata,
 	})
 	if err != nil {
 		return err
@@ -567,6 +686,10 @@ func (r *RoomManager) StartSession(
 		proto := room.ToProto()
 		persistRoomForParticipantCount(proto)
 		r.telemetry.ParticipantLeft(ctx, proto, p.ToProto(), true, participant.TelemetryGuard())
+
+		if pImpl, ok := p.(*rtc.ParticipantImpl); ok {
+			r.noiseProfiles.Save(p, pImpl.LearnedNoiseProfile())
+		}
 	})
 	participant.OnClaimsChanged(func(participant types.LocalParticipant) {
 		pLogger.Debugw("refreshing client token after claims change")
@@ -622,7 +745,8 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, createRoom *livekit.C
 	}
 
 	// construct ice servers
-	newRoom := rtc.NewRoom(ri, internal, *r.rtcConfig, r.config.Room, &r.config.Audio, r.serverInfo, r.telemetry, r.agentClient, r.agentStore, r.egressLauncher)
+	roomAudioConfig := r.roomAudioConfig(ri.Metadata, logger.GetLogger().WithValues("room", roomName))
+	newRoom := rtc.NewRoom(ri, internal, *r.rtcConfig, r.config.Room, &roomAudioConfig, r.serverInfo, r.telemetry, r.agentClient, r.agentStore, r.egressLauncher)
 
 	roomTopic := rpc.FormatRoomTopic(roomName)
 	roomServer := must.Get(rpc.NewTypedRoomServer(r, r.bus))
@@ -632,18 +756,32 @@ func (r *RoomManager) getOrCreateRoom(ctx context.Context, createRoom *livekit.C
 		r.lock.Unlock()
 		return nil, err
 	}
+	roomProcessingServer := must.Get(roomapi.NewTypedRoomProcessingServer(r, r.bus))
+	killRoomProcessingServer := r.roomProcessingServers.Replace(roomTopic, roomProcessingServer)
+	if err := roomProcessingServer.RegisterAllRoomTopics(roomTopic); err != nil {
+		killRoomServer()
+		killRoomProcessingServer()
+		r.lock.Unlock()
+		return nil, err
+	}
 	agentDispatchServer := must.Get(rpc.NewTypedAgentDispatchInternalServer(r, r.bus))
 	killDispServer := r.agentDispatchServers.Replace(roomTopic, agentDispatchServer)
 	if err := agentDispatchServer.RegisterAllRoomTopics(roomTopic); err != nil {
 		killRoomServer()
+		killRoomProcessingServer()
 		killDispServer()
 		r.lock.Unlock()
 		return nil, err
 	}
 
+	newRoom.SetPlacement(r.placer.AssignRoom(roomName))
+	r.setCallFlows(newRoom)
+
 	newRoom.OnClose(func() {
 		killRoomServer()
+		killRoomProcessingServer()
 		killDispServer()
+		r.placer.ReleaseRoom(roomName)
 
 		roomInfo := newRoom.ToProto()
 		r.telemetry.RoomEnded(ctx, roomInfo)
@@ -802,6 +940,9 @@ func (r *RoomManager) UpdateParticipant(ctx context.Context, req *livekit.Update
 		Metadata:   req.Metadata,
 		Attributes: req.Attributes,
 	}, true); err != nil {
+		if errors.Is(err, metadata.ErrVersionMismatch) {
+			return nil, ErrMetadataVersionMismatch
+		}
 		return nil, err
 	}
 
@@ -912,12 +1053,79 @@ func (r *RoomManager) UpdateRoomMetadata(ctx context.Context, req *livekit.Updat
 	}
 
 	room.Logger().Debugw("updating room")
-	done := room.SetMetadata(req.Metadata)
+	done, err := room.UpdateMetadata(req.Metadata, r.config.Limit.MaxMetadataSize)
+	switch {
+	case errors.Is(err, metadata.ErrVersionMismatch):
+		return nil, ErrMetadataVersionMismatch
+	case errors.Is(err, metadata.ErrTooLarge):
+		return nil, ErrMetadataExceedsLimits
+	}
+	if err != nil {
+		return nil, psrpc.NewError(psrpc.InvalidArgument, err)
+	}
 	// wait till the update is applied
 	<-done
 	return room.ToProto(), nil
 }
 
+// ClaimWarmRoom hands out a standby room whose agent is already connected, metadata is applied to the room
+func (r *RoomManager) ClaimWarmRoom(_ context.Context, metadata string) (*livekit.Room, error) {
+	room, err := r.warmPool.Claim(metadata)
+	if err != nil {
+		return nil, err
+	}
+	return room.ToProto(), nil
+}
+
+// MirrorTrack sends a read-only copy of a published track into a QA room, both rooms have to be hosted on this node
+func (r *RoomManager) MirrorTrack(ctx context.Context, sourceRoom livekit.RoomName, trackID livekit.TrackID, mirrorRoom livekit.RoomName) error {
+	if !r.config.Room.TrackMirror.Enabled {
+		return ErrTrackMirrorNotEnabled
+	}
+	if sourceRoom == mirrorRoom {
+		return ErrDestinationSameAsSourceRoom
+	}
+
+	source := r.GetRoom(ctx, sourceRoom)
+	if source == nil {
+		return ErrRoomNotFound
+	}
+	target := r.GetRoom(ctx, mirrorRoom)
+	if target == nil {
+		return ErrMirrorRoomNotFound
+	}
+
+	var track types.MediaTrack
+	for _, p := range source.GetParticipants() {
+		if track = p.GetPublishedTrack(trackID); track != nil {
+			break
+		}
+	}
+	if track == nil {
+		return ErrTrackNotFound
+	}
+
+	switch err := target.MirrorTrack(sourceRoom, track); {
+	case errors.Is(err, rtc.ErrNotMirrorRoom):
+		return ErrNotMirrorRoom
+	case errors.Is(err, rtc.ErrTrackAlreadyMirrored):
+		return ErrTrackAlreadyMirrored
+	default:
+		return err
+	}
+}
+
+func (r *RoomManager) StopMirror(ctx context.Context, trackID livekit.TrackID, mirrorRoom livekit.RoomName) error {
+	target := r.GetRoom(ctx, mirrorRoom)
+	if target == nil {
+		return ErrMirrorRoomNotFound
+	}
+	if !target.StopMirror(trackID) {
+		return ErrTrackNotFound
+	}
+	return nil
+}
+
 func (r *RoomManager) ListDispatch(ctx context.Context, req *livekit.ListAgentDispatchRequest) (*livekit.ListAgentDispatchResponse, error) {
 	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
 	if room == nil {
@@ -1122,3 +1330,32 @@ func (h *roomManagerParticipantHelper) ShouldRegressCodec() bool {
 func (h *roomManagerParticipantHelper) GetCachedReliableDataMessage(seqs map[livekit.ParticipantID]uint32) []*types.DataMessageCache {
 	return h.room.GetCachedReliableDataMessage(seqs)
 }
+
+func (r *RoomManager) setCallFlows(room *rtc.Room) {
+	if len(r.callFlows) == 0 {
+		return
+	}
+
+	params := rtc.CallFlowRunnerParams{
+		Flows: r.callFlows,
+	}
+	if store, ok := r.roomStore.(rtc.CallFlowStore); ok {
+		params.Store = store
+	}
+	if r.sipClient != nil {
+		params.Transfer = func(ctx context.Context, sipCallID string, transfer *callflow.Transfer) error {
+			// same default as TransferSIPParticipant, covering the transfer target answering
+			timeout := 30 * time.Second
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			_, err := r.sipClient.TransferSIPParticipant(ctx, sipCallID, &rpc.InternalTransferSIPParticipantRequest{
+				SipCallId:    sipCallID,
+				TransferTo:   transfer.To,
+				PlayDialtone: transfer.PlayDialtone,
+			}, psrpc.WithRequestTimeout(timeout))
+			return err
+		}
+	}
+	room.SetCallFlows(params)
+}
@@ -0,0 +1,585 @@
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"gopkg.in/yaml.v3"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/psrpc"
+
+	"github.com/livekit/livekit-server/pkg/pcmtap"
+	"github.com/livekit/livekit-server/pkg/realtime"
+	"github.com/livekit/livekit-server/pkg/roomapi"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/talkstats"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/version"
+)
+
+const defaultSupportBundlePeriod = 5 * time.Minute
+
+// GetTrackNoiseFilter and the following methods of RoomService implement roomapi.RoomService, they check
+// the request and route it to the node hosting the room like the calls of livekit.RoomService
+
+func (s *RoomService) GetTrackNoiseFilter(ctx context.Context, req *roomapi.TrackNoiseFilterRequest) (*roomapi.TrackNoiseFilter, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid)
+	if err := ensureTrackRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.GetTrackNoiseFilter(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) UpdateTrackNoiseFilter(ctx context.Context, req *roomapi.UpdateTrackNoiseFilterRequest) (*roomapi.TrackNoiseFilter, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid, "enabled", req.Enabled)
+	if err := ensureTrackRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.UpdateTrackNoiseFilter(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) GetTrackGain(ctx context.Context, req *roomapi.TrackGainRequest) (*roomapi.TrackGain, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid)
+	if err := ensureTrackRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.GetTrackGain(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) UpdateTrackGain(ctx context.Context, req *roomapi.UpdateTrackGainRequest) (*roomapi.TrackGain, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid, "gainDB", req.GainDb)
+	if req.GainDb < audio.MinTrackGainDB || req.GainDb > audio.MaxTrackGainDB {
+		return nil, twirp.InvalidArgumentError("gain_db", fmt.Sprintf("allowed are %d to %d", audio.MinTrackGainDB, audio.MaxTrackGainDB))
+	}
+	if err := ensureTrackRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.UpdateTrackGain(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+type trackReq interface {
+	participantReq
+	GetTrackSid() string
+}
+
+func ensureTrackRequest(ctx context.Context, req trackReq) error {
+	if req.GetRoom() == "" {
+		return ErrNoRoomName
+	}
+	if req.GetIdentity() == "" {
+		return ErrIdentityEmpty
+	}
+	if req.GetTrackSid() == "" {
+		return twirp.RequiredArgumentError("track_sid")
+	}
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(req.GetRoom())); err != nil {
+		return twirpAuthError(err)
+	}
+	return nil
+}
+
+func (s *RoomService) GetProcessingBypass(ctx context.Context, req *roomapi.ProcessingBypassRequest) (*roomapi.ProcessingBypassState, error) {
+	AppendLogFields(ctx, "room", req.Room)
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.GetProcessingBypass(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) EnableProcessingBypass(ctx context.Context, req *roomapi.EnableProcessingBypassRequest) (*roomapi.ProcessingBypassState, error) {
+	AppendLogFields(ctx, "room", req.Room, "duration", req.Duration.AsDuration(), "reason", req.Reason)
+	if req.Duration != nil && req.Duration.AsDuration() <= 0 {
+		return nil, twirp.InvalidArgumentError("duration", "must be positive")
+	}
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	req.Actor = requestActor(ctx)
+	return s.roomProcessingClient.EnableProcessingBypass(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) DisableProcessingBypass(ctx context.Context, req *roomapi.DisableProcessingBypassRequest) (*roomapi.ProcessingBypassState, error) {
+	AppendLogFields(ctx, "room", req.Room, "reason", req.Reason)
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	req.Actor = requestActor(ctx)
+	return s.roomProcessingClient.DisableProcessingBypass(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// requestActor identifies the caller in the processing bypass trail by the identity of its token, or its API key
+func requestActor(ctx context.Context) string {
+	if grants := GetGrants(ctx); grants != nil && grants.Identity != "" {
+		return grants.Identity
+	}
+	return GetAPIKey(ctx)
+}
+
+func ensureRoomRequest(ctx context.Context, room string) error {
+	if room == "" {
+		return ErrNoRoomName
+	}
+	if err := EnsureAdminPermission(ctx, livekit.RoomName(room)); err != nil {
+		return twirpAuthError(err)
+	}
+	return nil
+}
+
+func (s *RoomService) GetTalkAnalytics(ctx context.Context, req *roomapi.TalkAnalyticsRequest) (*roomapi.TalkAnalytics, error) {
+	AppendLogFields(ctx, "room", req.Room)
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.GetTalkAnalytics(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) ListAudioSnapshots(ctx context.Context, req *roomapi.ListAudioSnapshotsRequest) (*roomapi.ListAudioSnapshotsResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity)
+	if req.Identity == "" {
+		return nil, ErrIdentityEmpty
+	}
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.ListAudioSnapshots(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) GetAudioSnapshot(ctx context.Context, req *roomapi.AudioSnapshotRequest) (*roomapi.AudioSnapshot, error) {
+	AppendLogFields(ctx, "room", req.Room, "participant", req.Identity, "trackID", req.TrackSid, "duration", req.Duration.AsDuration())
+	if req.Duration != nil && req.Duration.AsDuration() <= 0 {
+		return nil, twirp.InvalidArgumentError("duration", "must be positive")
+	}
+	if err := ensureTrackRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.GetAudioSnapshot(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) StartTrackMirror(ctx context.Context, req *roomapi.TrackMirrorRequest) (*roomapi.TrackMirrorResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "trackID", req.TrackSid, "mirrorRoom", req.MirrorRoom)
+	if err := ensureTrackMirrorRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.StartTrackMirror(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// StopTrackMirror is served by the node hosting the mirror room, the source room may be gone by now
+func (s *RoomService) StopTrackMirror(ctx context.Context, req *roomapi.TrackMirrorRequest) (*roomapi.TrackMirrorResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "trackID", req.TrackSid, "mirrorRoom", req.MirrorRoom)
+	if err := ensureTrackMirrorRequest(ctx, req); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.StopTrackMirror(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.MirrorRoom)), req)
+}
+
+func ensureTrackMirrorRequest(ctx context.Context, req *roomapi.TrackMirrorRequest) error {
+	if req.TrackSid == "" {
+		return twirp.RequiredArgumentError("track_sid")
+	}
+	if req.MirrorRoom == "" {
+		return twirp.RequiredArgumentError("mirror_room")
+	}
+	return ensureRoomRequest(ctx, req.Room)
+}
+
+func (s *RoomService) StartRealtimeBridge(ctx context.Context, req *roomapi.StartRealtimeBridgeRequest) (*roomapi.RealtimeBridge, error) {
+	AppendLogFields(ctx, "room", req.Room, "trackID", req.TrackSid, "model", req.Model)
+	if req.TrackSid == "" {
+		return nil, twirp.RequiredArgumentError("track_sid")
+	}
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.StartRealtimeBridge(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) StopRealtimeBridge(ctx context.Context, req *roomapi.StopRealtimeBridgeRequest) (*roomapi.StopRealtimeBridgeResponse, error) {
+	AppendLogFields(ctx, "room", req.Room, "trackID", req.TrackSid)
+	if req.TrackSid == "" {
+		return nil, twirp.RequiredArgumentError("track_sid")
+	}
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.StopRealtimeBridge(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+func (s *RoomService) GetSupportBundle(ctx context.Context, req *roomapi.SupportBundleRequest) (*roomapi.SupportBundle, error) {
+	AppendLogFields(ctx, "room", req.Room, "period", req.Period.AsDuration())
+	if req.Period != nil && req.Period.AsDuration() <= 0 {
+		return nil, twirp.InvalidArgumentError("period", "must be positive")
+	}
+	if err := ensureRoomRequest(ctx, req.Room); err != nil {
+		return nil, err
+	}
+	return s.roomProcessingClient.GetSupportBundle(ctx, s.topicFormatter.RoomTopic(ctx, livekit.RoomName(req.Room)), req)
+}
+
+// --------------------------------------
+
+// GetTrackNoiseFilter and the following methods of RoomManager implement roomapi.RoomProcessingServerImpl,
+// they serve the calls of roomapi.RoomService on the node hosting the room
+func (r *RoomManager) GetTrackNoiseFilter(ctx context.Context, req *roomapi.TrackNoiseFilterRequest) (*roomapi.TrackNoiseFilter, error) {
+	if !r.config.Audio.NoiseFilter.Enabled {
+		return nil, ErrNoiseFilterNotEnabled
+	}
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	track, ok := participant.GetPublishedTrack(livekit.TrackID(req.TrackSid)).(interface{ IsNoiseFilterEnabled() bool })
+	if !ok {
+		return nil, ErrTrackNotFound
+	}
+	return &roomapi.TrackNoiseFilter{
+		Identity: string(participant.Identity()),
+		TrackSid: req.TrackSid,
+		Enabled:  track.IsNoiseFilterEnabled(),
+	}, nil
+}
+
+func (r *RoomManager) UpdateTrackNoiseFilter(ctx context.Context, req *roomapi.UpdateTrackNoiseFilterRequest) (*roomapi.TrackNoiseFilter, error) {
+	if !r.config.Audio.NoiseFilter.Enabled {
+		return nil, ErrNoiseFilterNotEnabled
+	}
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lp, ok := participant.(interface {
+		SetTrackNoiseFilter(trackID livekit.TrackID, enabled bool) error
+	})
+	if !ok {
+		return nil, ErrNoiseFilterUnavailable
+	}
+
+	participant.GetLogger().Debugw("setting track noise filter", "trackID", req.TrackSid, "enabled", req.Enabled)
+	if err := lp.SetTrackNoiseFilter(livekit.TrackID(req.TrackSid), req.Enabled); err != nil {
+		return nil, trackProcessingError(err)
+	}
+	return r.GetTrackNoiseFilter(ctx, &roomapi.TrackNoiseFilterRequest{
+		Room:     req.Room,
+		Identity: req.Identity,
+		TrackSid: req.TrackSid,
+	})
+}
+
+func (r *RoomManager) GetTrackGain(ctx context.Context, req *roomapi.TrackGainRequest) (*roomapi.TrackGain, error) {
+	if !r.config.Audio.NoiseFilter.Enabled {
+		return nil, ErrNoiseFilterNotEnabled
+	}
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	track, ok := participant.GetPublishedTrack(livekit.TrackID(req.TrackSid)).(interface{ Gain() float64 })
+	if !ok {
+		return nil, ErrTrackNotFound
+	}
+	return &roomapi.TrackGain{
+		Identity: string(participant.Identity()),
+		TrackSid: req.TrackSid,
+		GainDb:   track.Gain(),
+	}, nil
+}
+
+func (r *RoomManager) UpdateTrackGain(ctx context.Context, req *roomapi.UpdateTrackGainRequest) (*roomapi.TrackGain, error) {
+	if !r.config.Audio.NoiseFilter.Enabled {
+		return nil, ErrNoiseFilterNotEnabled
+	}
+	_, participant, err := r.roomAndParticipantForReq(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	lp, ok := participant.(interface {
+		SetTrackGain(trackID livekit.TrackID, gainDB float64) error
+	})
+	if !ok {
+		return nil, ErrNoiseFilterUnavailable
+	}
+
+	participant.GetLogger().Debugw("setting track gain", "trackID", req.TrackSid, "gainDB", req.GainDb)
+	if err := lp.SetTrackGain(livekit.TrackID(req.TrackSid), req.GainDb); err != nil {
+		return nil, trackProcessingError(err)
+	}
+	return r.GetTrackGain(ctx, &roomapi.TrackGainRequest{
+		Room:     req.Room,
+		Identity: req.Identity,
+		TrackSid: req.TrackSid,
+	})
+}
+
+func trackProcessingError(err error) error {
+	switch {
+	case errors.Is(err, rtc.ErrNoiseFilterUnavailable):
+		return ErrNoiseFilterUnavailable
+	case errors.Is(err, rtc.ErrTrackNotFound):
+		return ErrTrackNotFound
+	default:
+		return err
+	}
+}
+
+func (r *RoomManager) GetProcessingBypass(ctx context.Context, req *roomapi.ProcessingBypassRequest) (*roomapi.ProcessingBypassState, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	return processingBypassStateToProto(room.ProcessingBypassState()), nil
+}
+
+func (r *RoomManager) EnableProcessingBypass(ctx context.Context, req *roomapi.EnableProcessingBypassRequest) (*roomapi.ProcessingBypassState, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	return processingBypassStateToProto(room.EnableProcessingBypass(req.Duration.AsDuration(), req.Actor, req.Reason)), nil
+}
+
+func (r *RoomManager) DisableProcessingBypass(ctx context.Context, req *roomapi.DisableProcessingBypassRequest) (*roomapi.ProcessingBypassState, error) {
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	if !room.DisableProcessingBypass(req.Actor, req.Reason) {
+		return nil, ErrProcessingNotBypassed
+	}
+	return processingBypassStateToProto(room.ProcessingBypassState()), nil
+}
+
+func processingBypassStateToProto(state rtc.ProcessingBypassState) *roomapi.ProcessingBypassState {
+	pb := &roomapi.ProcessingBypassState{
+		Active: state.Active,
+		Events: make([]*roomapi.ProcessingBypassEvent, 0, len(state.Events)),
+	}
+	if state.Until != nil {
+		pb.Until = state.Until.UnixMilli()
+	}
+	for _, event := range state.Events {
+		e := &roomapi.ProcessingBypassEvent{
+			Action: string(event.Action),
+			At:     event.At.UnixMilli(),
+			Actor:  event.Actor,
+			Reason: event.Reason,
+		}
+		if event.Until != nil {
+			e.Until = event.Until.UnixMilli()
+		}
+		pb.Events = append(pb.Events, e)
+	}
+	return pb
+}
+
+func (r *RoomManager) GetTalkAnalytics(ctx context.Context, req *roomapi.TalkAnalyticsRequest) (*roomapi.TalkAnalytics, error) {
+	if !r.config.Room.TalkAnalytics.Enabled {
+		return nil, ErrTalkAnalyticsNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+	stats := room.TalkAnalytics()
+	if stats == nil {
+		return nil, ErrTalkAnalyticsNotEnabled
+	}
+	return talkAnalyticsToProto(stats), nil
+}
+
+func talkAnalyticsToProto(stats *talkstats.SessionStats) *roomapi.TalkAnalytics {
+	pb := &roomapi.TalkAnalytics{
+		StartedAt:    stats.StartedAt.UnixMilli(),
+		DurationMs:   stats.DurationMs,
+		TalkTimeMs:   stats.TalkTimeMs,
+		OverlapMs:    stats.OverlapMs,
+		SilenceMs:    stats.SilenceMs,
+		Participants: make([]*roomapi.ParticipantTalkAnalytics, 0, len(stats.Participants)),
+	}
+	for _, p := range stats.Participants {
+		pb.Participants = append(pb.Participants, &roomapi.ParticipantTalkAnalytics{
+			Identity:       p.ParticipantIdentity,
+			TalkTimeMs:     p.TalkTimeMs,
+			TalkShare:      p.TalkShare,
+			Turns:          uint32(p.Turns),
+			LongestTurnMs:  p.LongestTurnMs,
+			Interruptions:  uint32(p.Interruptions),
+			Interrupted:    uint32(p.Interrupted),
+			Words:          uint32(p.Words),
+			WordsPerMinute: p.WordsPerMinute,
+		})
+	}
+	return pb
+}
+
+func (r *RoomManager) ListAudioSnapshots(ctx context.Context, req *roomapi.ListAudioSnapshotsRequest) (*roomapi.ListAudioSnapshotsResponse, error) {
+	if !r.config.Audio.Snapshots.Enabled {
+		return nil, ErrAudioSnapshotsNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	streams := room.AudioSnapshotStreams(livekit.ParticipantIdentity(req.Identity))
+	res := &roomapi.ListAudioSnapshotsResponse{
+		Streams: make([]*roomapi.AudioSnapshotStream, 0, len(streams)),
+	}
+	for _, stream := range streams {
+		res.Streams = append(res.Streams, &roomapi.AudioSnapshotStream{
+			TrackSid:          string(stream.TrackID),
+			PublisherIdentity: string(stream.PublisherIdentity),
+			Mixed:             stream.Mixed,
+			LastDelivered:     stream.LastDeliveredMs,
+		})
+	}
+	return res, nil
+}
+
+func (r *RoomManager) GetAudioSnapshot(ctx context.Context, req *roomapi.AudioSnapshotRequest) (*roomapi.AudioSnapshot, error) {
+	if !r.config.Audio.Snapshots.Enabled {
+		return nil, ErrAudioSnapshotsNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	wav, err := room.AudioSnapshot(livekit.ParticipantIdentity(req.Identity), livekit.TrackID(req.TrackSid), req.Duration.AsDuration())
+	switch {
+	case errors.Is(err, rtc.ErrAudioSnapshotNotFound):
+		return nil, psrpc.NewError(psrpc.NotFound, err)
+	case errors.Is(err, rtc.ErrAudioSnapshotRateLimited):
+		return nil, psrpc.NewError(psrpc.ResourceExhausted, err)
+	case err != nil:
+		return nil, err
+	}
+	return &roomapi.AudioSnapshot{Wav: wav}, nil
+}
+
+func (r *RoomManager) StartTrackMirror(ctx context.Context, req *roomapi.TrackMirrorRequest) (*roomapi.TrackMirrorResponse, error) {
+	if err := r.MirrorTrack(ctx, livekit.RoomName(req.Room), livekit.TrackID(req.TrackSid), livekit.RoomName(req.MirrorRoom)); err != nil {
+		return nil, err
+	}
+	return &roomapi.TrackMirrorResponse{}, nil
+}
+
+func (r *RoomManager) StopTrackMirror(ctx context.Context, req *roomapi.TrackMirrorRequest) (*roomapi.TrackMirrorResponse, error) {
+	if err := r.StopMirror(ctx, livekit.TrackID(req.TrackSid), livekit.RoomName(req.MirrorRoom)); err != nil {
+		return nil, err
+	}
+	return &roomapi.TrackMirrorResponse{}, nil
+}
+
+func (r *RoomManager) StartRealtimeBridge(ctx context.Context, req *roomapi.StartRealtimeBridgeRequest) (*roomapi.RealtimeBridge, error) {
+	if !r.config.Realtime.Enabled {
+		return nil, ErrRealtimeBridgeNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	outputTrackID, err := room.StartRealtimeBridge(livekit.TrackID(req.TrackSid), realtime.SessionOverrides{
+		Model:        req.Model,
+		Voice:        req.Voice,
+		Instructions: req.Instructions,
+	})
+	if err != nil {
+		return nil, realtimeBridgeError(err)
+	}
+	return &roomapi.RealtimeBridge{
+		TrackSid:       req.TrackSid,
+		OutputTrackSid: string(outputTrackID),
+	}, nil
+}
+
+func (r *RoomManager) StopRealtimeBridge(ctx context.Context, req *roomapi.StopRealtimeBridgeRequest) (*roomapi.StopRealtimeBridgeResponse, error) {
+	if !r.config.Realtime.Enabled {
+		return nil, ErrRealtimeBridgeNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	if err := room.StopRealtimeBridge(livekit.TrackID(req.TrackSid)); err != nil {
+		return nil, realtimeBridgeError(err)
+	}
+	return &roomapi.StopRealtimeBridgeResponse{}, nil
+}
+
+func realtimeBridgeError(err error) error {
+	switch {
+	case errors.Is(err, rtc.ErrRealtimeBridgeNotFound), errors.Is(err, rtc.ErrPCMTapNoAudioTrack):
+		return psrpc.NewError(psrpc.NotFound, err)
+	case errors.Is(err, rtc.ErrRealtimeBridgeExists):
+		return psrpc.NewError(psrpc.AlreadyExists, err)
+	case errors.Is(err, rtc.ErrRealtimeBridgeNoConsent), errors.Is(err, rtc.ErrPCMTapNoConsent):
+		return psrpc.NewError(psrpc.PermissionDenied, err)
+	case errors.Is(err, rtc.ErrTooManyRealtimeBridges), errors.Is(err, pcmtap.ErrTooManySubscribers):
+		return psrpc.NewError(psrpc.ResourceExhausted, err)
+	case errors.Is(err, rtc.ErrRealtimeBridgeDisabled), errors.Is(err, rtc.ErrPCMTapDisabled):
+		return psrpc.NewError(psrpc.FailedPrecondition, err)
+	}
+	// the model could not be reached
+	return psrpc.NewError(psrpc.Unavailable, err)
+}
+
+func (r *RoomManager) GetSupportBundle(ctx context.Context, req *roomapi.SupportBundleRequest) (*roomapi.SupportBundle, error) {
+	if !r.config.Room.SupportBundle.Enabled {
+		return nil, ErrSupportBundleNotEnabled
+	}
+	room := r.GetRoom(ctx, livekit.RoomName(req.Room))
+	if room == nil {
+		return nil, ErrRoomNotFound
+	}
+
+	period := defaultSupportBundlePeriod
+	if req.Period != nil {
+		period = req.Period.AsDuration()
+	}
+	bundle := room.SupportBundle(period)
+	if bundle == nil {
+		return nil, ErrSupportBundleNotEnabled
+	}
+
+	bundle.Manifest.ServerVersion = version.Version
+	bundle.Manifest.NodeID = string(r.currentNode.NodeID())
+	// secrets like keys and TURN credentials are left out on purpose
+	snapshot, err := yaml.Marshal(map[string]interface{}{
+		"room":  r.config.Room,
+		"audio": r.config.Audio,
+		"limit": r.config.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bundle.Config = snapshot
+
+	var buf bytes.Buffer
+	if err := bundle.WriteZip(&buf); err != nil {
+		return nil, err
+	}
+	return &roomapi.SupportBundle{
+		Zip:      buf.Bytes(),
+		FileName: fmt.Sprintf("support-%s-%d.zip", req.Room, time.Now().Unix()),
+	}, nil
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/twitchtv/twirp"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/roomapi"
 	"github.com/livekit/livekit-server/pkg/routing"
 	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/protocol/egress"
@@ -41,6 +42,8 @@ type RoomService struct {
 	topicFormatter    rpc.TopicFormatter
 	roomClient        rpc.TypedRoomClient
 	participantClient rpc.TypedParticipantClient
+	// routes the calls of roomapi.RoomService
+	roomProcessingClient roomapi.TypedRoomProcessingClient
 
 	rpc.UnimplementedRoomServer
 	rpc.UnimplementedParticipantServer
@@ -56,6 +59,7 @@ func NewRoomService(
 	topicFormatter rpc.TopicFormatter,
 	roomClient rpc.TypedRoomClient,
 	participantClient rpc.TypedParticipantClient,
+	roomProcessingClient roomapi.TypedRoomProcessingClient,
 ) (svc *RoomService, err error) {
 	svc = &RoomService{
 		limitConf:         limitConf,
@@ -67,6 +71,8 @@ func NewRoomService(
 		topicFormatter:    topicFormatter,
 		roomClient:        roomClient,
 		participantClient: participantClient,
+
+		roomProcessingClient: roomProcessingClient,
 	}
 	return
 }
@@ -27,6 +27,8 @@ import (
 	"github.com/livekit/protocol/rpc/rpcfakes"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/roomapi"
+	"github.com/livekit/livekit-server/pkg/roomapi/roomapifakes"
 	"github.com/livekit/livekit-server/pkg/routing/routingfakes"
 	"github.com/livekit/livekit-server/pkg/service"
 	"github.com/livekit/livekit-server/pkg/service/servicefakes"
@@ -104,10 +106,84 @@ func TestMetaDataLimits(t *testing.T) {
 	}
 }
 
+func TestRoomProcessing(t *testing.T) {
+	adminCtx := func(room string) context.Context {
+		grant := &auth.ClaimGrants{
+			Identity: "operator",
+			Video:    &auth.VideoGrant{RoomAdmin: true, Room: room},
+		}
+		return service.WithGrants(context.Background(), grant, "APIKey")
+	}
+
+	t.Run("missing permissions", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		_, err := svc.GetTalkAnalytics(adminCtx("otherroom"), &roomapi.TalkAnalyticsRequest{Room: "testroom"})
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.Unauthenticated, terr.Code())
+		require.Zero(t, svc.roomProcessingClient.GetTalkAnalyticsCallCount())
+	})
+
+	t.Run("gain out of range", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		_, err := svc.UpdateTrackGain(adminCtx("testroom"), &roomapi.UpdateTrackGainRequest{
+			Room:     "testroom",
+			Identity: "publisher",
+			TrackSid: "TR_audio",
+			GainDb:   30,
+		})
+		terr, ok := err.(twirp.Error)
+		require.True(t, ok)
+		require.Equal(t, twirp.InvalidArgument, terr.Code())
+		require.Zero(t, svc.roomProcessingClient.UpdateTrackGainCallCount())
+	})
+
+	t.Run("routed to the room", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		svc.roomProcessingClient.UpdateTrackNoiseFilterReturns(&roomapi.TrackNoiseFilter{Enabled: true}, nil)
+		res, err := svc.UpdateTrackNoiseFilter(adminCtx("testroom"), &roomapi.UpdateTrackNoiseFilterRequest{
+			Room:     "testroom",
+			Identity: "publisher",
+			TrackSid: "TR_audio",
+			Enabled:  true,
+		})
+		require.NoError(t, err)
+		require.True(t, res.Enabled)
+		_, topic, req, _ := svc.roomProcessingClient.UpdateTrackNoiseFilterArgsForCall(0)
+		require.Equal(t, rpc.RoomTopic("testroom"), topic)
+		require.Equal(t, "TR_audio", req.TrackSid)
+	})
+
+	t.Run("bypass actor from token", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		_, err := svc.EnableProcessingBypass(adminCtx("testroom"), &roomapi.EnableProcessingBypassRequest{
+			Room:   "testroom",
+			Reason: "incident",
+			Actor:  "spoofed",
+		})
+		require.NoError(t, err)
+		_, _, req, _ := svc.roomProcessingClient.EnableProcessingBypassArgsForCall(0)
+		require.Equal(t, "operator", req.Actor)
+	})
+
+	t.Run("mirror stopped on the mirror room", func(t *testing.T) {
+		svc := newTestRoomService(config.LimitConfig{})
+		_, err := svc.StopTrackMirror(adminCtx("testroom"), &roomapi.TrackMirrorRequest{
+			Room:       "testroom",
+			TrackSid:   "TR_audio",
+			MirrorRoom: "qaroom",
+		})
+		require.NoError(t, err)
+		_, topic, _, _ := svc.roomProcessingClient.StopTrackMirrorArgsForCall(0)
+		require.Equal(t, rpc.RoomTopic("qaroom"), topic)
+	})
+}
+
 func newTestRoomService(limitConf config.LimitConfig) *TestRoomService {
 	router := &routingfakes.FakeRouter{}
 	allocator := &servicefakes.FakeRoomAllocator{}
 	store := &servicefakes.FakeServiceStore{}
+	roomProcessingClient := &roomapifakes.FakeTypedRoomProcessingClient{}
 	svc, err := service.NewRoomService(
 		limitConf,
 		config.APIConfig{ExecutionTimeout: 2},
@@ -118,6 +194,7 @@ func newTestRoomService(limitConf config.LimitConfig) *TestRoomService {
 		rpc.NewTopicFormatter(),
 		&rpcfakes.FakeTypedRoomClient{},
 		&rpcfakes.FakeTypedParticipantClient{},
+		roomProcessingClient,
 	)
 	if err != nil {
 		panic(err)
@@ -127,6 +204,8 @@ func newTestRoomService(limitConf config.LimitConfig) *TestRoomService {
 		router:      router,
 		allocator:   allocator,
 		store:       store,
+
+		roomProcessingClient: roomProcessingClient,
 	}
 }
 
@@ -135,4 +214,6 @@ type TestRoomService struct {
 	router    *routingfakes.FakeRouter
 	allocator *servicefakes.FakeRoomAllocator
 	store     *servicefakes.FakeServiceStore
+
+	roomProcessingClient *roomapifakes.FakeTypedRoomProcessingClient
 }
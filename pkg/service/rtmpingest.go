@@ -0,0 +1,79 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+	"github.com/livekit/livekit-server/pkg/rtmp"
+)
+
+// RTMPIngestServer accepts RTMP and RTMPS streams of encoders on this node, the stream key is an access token
+type RTMPIngestServer struct {
+	config  rtmp.Config
+	starter *ingestStarter
+	server  *rtmp.Server
+	logger  logger.Logger
+}
+
+func newRTMPIngestServer(conf rtmp.Config, keyProvider auth.KeyProvider, roomManager *RoomManager) (*RTMPIngestServer, error) {
+	s := &RTMPIngestServer{
+		config: conf,
+		logger: logger.GetLogger().WithComponent("rtmp_ingest"),
+	}
+	s.starter = &ingestStarter{
+		keyProvider: keyProvider,
+		roomManager: roomManager,
+		logger:      s.logger,
+	}
+	server, err := rtmp.NewServer(rtmp.ServerParams{
+		Config:    conf,
+		Logger:    s.logger,
+		OnPublish: s.onPublish,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.server = server
+	return s, nil
+}
+
+func (s *RTMPIngestServer) Start() error {
+	if s == nil {
+		return nil
+	}
+	return s.server.Start()
+}
+
+// Stop disconnects all encoders
+func (s *RTMPIngestServer) Stop() {
+	if s == nil {
+		return
+	}
+	s.server.Stop()
+}
+
+// onPublish takes the stream key as token, whatever the app of the URL
+func (s *RTMPIngestServer) onPublish(app string, streamKey string) (ingest.Publisher, error) {
+	publisher, err := s.starter.start(streamKey, ingest.ProtocolRTMP, s.config.ReconnectTimeout)
+	if err != nil {
+		s.logger.Infow("rejected rtmp stream", "app", app, "error", err)
+		return nil, err
+	}
+	return publisher, nil
+}
@@ -39,32 +39,61 @@ import (
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/utils/xtwirp"
+	"github.com/livekit/psrpc"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/latencyprobe"
+	"github.com/livekit/livekit-server/pkg/mlexport"
+	"github.com/livekit/livekit-server/pkg/roomapi"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/syntheticmonitor"
+	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/livekit-server/version"
 )
 
+// ServerOptions customizes a server embedded in another binary, the zero value builds the server of livekit-server
+type ServerOptions struct {
+	// wraps the telemetry service, e. g. to forward events to another sink as well
+	Telemetry func(telemetry.TelemetryService) telemetry.TelemetryService
+	// receive side interceptors added to every publisher peer connection
+	Interceptors []rtc.InterceptorStage
+}
+
 type LivekitServer struct {
-	config       *config.Config
-	ioService    *IOInfoService
-	rtcService   *RTCService
-	whipService  *WHIPService
-	agentService *AgentService
-	httpServer   *http.Server
-	promServer   *http.Server
-	router       routing.Router
-	roomManager  *RoomManager
-	signalServer *SignalServer
-	turnServer   *turn.Server
-	currentNode  routing.LocalNode
-	running      atomic.Bool
-	doneChan     chan struct{}
-	closedChan   chan struct{}
+	config            *config.Config
+	egressService     *EgressService
+	ingressService    *IngressService
+	sipService        *SIPService
+	ioService         *IOInfoService
+	rtcService        *RTCService
+	whipService       *WHIPService
+	agentService      *AgentService
+	httpServer        *http.Server
+	promServer        *http.Server
+	router            routing.Router
+	roomManager       *RoomManager
+	signalServer      *SignalServer
+	turnServer        *turn.Server
+	currentNode       routing.LocalNode
+	latencyProber     *latencyprobe.Prober
+	syntheticMonitors *syntheticmonitor.Monitors
+	mlExport          *mlexport.Lifecycle
+	pcmTap            *PCMTapServer
+	audioInject       *AudioInjectServer
+	sipGateway        *SIPGatewayServer
+	rtmpIngest        *RTMPIngestServer
+	srtIngest         *SRTIngestServer
+	agentWorker       *AgentWorkerServer
+	dspMemory         *dspMemoryMonitor
+	running           atomic.Bool
+	doneChan          chan struct{}
+	closedChan        chan struct{}
 }
 
 func NewLivekitServer(conf *config.Config,
 	roomService livekit.RoomService,
+	roomProcessingService roomapi.RoomService,
 	agentDispatchService *AgentDispatchService,
 	egressService *EgressService,
 	ingressService *IngressService,
@@ -81,14 +110,17 @@ func NewLivekitServer(conf *config.Config,
 	currentNode routing.LocalNode,
 ) (s *LivekitServer, err error) {
 	s = &LivekitServer{
-		config:       conf,
-		ioService:    ioService,
-		rtcService:   rtcService,
-		whipService:  whipService,
-		agentService: agentService,
-		router:       router,
-		roomManager:  roomManager,
-		signalServer: signalServer,
+		config:         conf,
+		egressService:  egressService,
+		ingressService: ingressService,
+		sipService:     sipService,
+		ioService:      ioService,
+		rtcService:     rtcService,
+		whipService:    whipService,
+		agentService:   agentService,
+		router:         router,
+		roomManager:    roomManager,
+		signalServer:   signalServer,
 		// turn server starts automatically
 		turnServer:  turnServer,
 		currentNode: currentNode,
@@ -125,6 +157,7 @@ func NewLivekitServer(conf *config.Config,
 		serverOptions = append(serverOptions, opt)
 	}
 	roomServer := livekit.NewRoomServiceServer(roomService, serverOptions...)
+	roomProcessingServer := roomapi.NewRoomServiceServer(roomProcessingService, serverOptions...)
 	agentDispatchServer := livekit.NewAgentDispatchServiceServer(agentDispatchService, serverOptions...)
 	egressServer := livekit.NewEgressServer(egressService, serverOptions...)
 	ingressServer := livekit.NewIngressServer(ingressService, serverOptions...)
@@ -137,8 +170,61 @@ func NewLivekitServer(conf *config.Config,
 		mux.HandleFunc("/debug/goroutine", s.debugGoroutines)
 		mux.HandleFunc("/debug/rooms", s.debugInfo)
 	}
+	if roomManager.rtcConfig != nil && roomManager.rtcConfig.FlightRecorder != nil {
+		mux.HandleFunc("/debug/flight_recorder", s.debugFlightRecorder)
+	}
+	if roomManager.rtcConfig != nil && roomManager.rtcConfig.Canary != nil {
+		mux.HandleFunc("/debug/canary", s.debugCanary)
+	}
+	if conf.Room.WarmPool.Enabled {
+		mux.HandleFunc("/warm_room", s.claimWarmRoom)
+	}
+	if conf.LatencyProbe.Enabled {
+		s.latencyProber = newLatencyProber(s)
+		mux.HandleFunc("/debug/latency_probe", s.latencyProbe)
+	}
+	if conf.SyntheticMonitor.Enabled {
+		s.syntheticMonitors = newSyntheticMonitors(s)
+		mux.Handle("/synthetic_monitor", NewSyntheticMonitorService(s.syntheticMonitors))
+	}
+	if conf.Room.MLExport.Lifecycle.Enabled {
+		s.mlExport = newMLExportLifecycle(s)
+	}
+	if conf.PCMTap.Enabled && keyProvider != nil {
+		s.pcmTap = newPCMTapServer(conf.PCMTap, keyProvider, roomManager)
+	}
+	if conf.AudioInject.Enabled && keyProvider != nil {
+		s.audioInject = newAudioInjectServer(conf.AudioInject, keyProvider, roomManager)
+	}
+	if conf.SIPGateway.Enabled {
+		if s.sipGateway, err = newSIPGatewayServer(conf.SIPGateway, currentNode.NodeIP(), roomManager); err != nil {
+			return nil, err
+		}
+	}
+	if conf.RTMPIngest.Enabled && keyProvider != nil {
+		if s.rtmpIngest, err = newRTMPIngestServer(conf.RTMPIngest, keyProvider, roomManager); err != nil {
+			return nil, err
+		}
+	}
+	if conf.SRTIngest.Enabled && keyProvider != nil {
+		if s.srtIngest, err = newSRTIngestServer(conf.SRTIngest, keyProvider, roomManager); err != nil {
+			return nil, err
+		}
+	}
+	if conf.Agents.WorkerGRPC.Enabled && keyProvider != nil && agentService != nil {
+		s.agentWorker = newAgentWorkerServer(conf.Agents.WorkerGRPC, keyProvider, agentService.AgentHandler)
+	}
+	if conf.DSPMemory.Enabled {
+		s.dspMemory = newDSPMemoryMonitor(conf.DSPMemory)
+		mux.HandleFunc("/debug/dsp_memory", s.debugDSPMemory)
+	}
+	mux.HandleFunc("/capabilities", s.capabilitiesHandler)
+	mux.HandleFunc("/debug/config", s.effectiveConfig)
+	mux.HandleFunc("/node/drain", s.nodeDrain)
+	mux.HandleFunc("/events", s.tailEvents)
 
 	xtwirp.RegisterServer(mux, roomServer)
+	xtwirp.RegisterServer(mux, roomProcessingServer)
 	xtwirp.RegisterServer(mux, agentDispatchServer)
 	xtwirp.RegisterServer(mux, egressServer)
 	xtwirp.RegisterServer(mux, ingressServer)
@@ -273,6 +359,24 @@ func (s *LivekitServer) Start() error {
 	if err := s.signalServer.Start(); err != nil {
 		return err
 	}
+	if err := s.pcmTap.Start(); err != nil {
+		return err
+	}
+	if err := s.audioInject.Start(); err != nil {
+		return err
+	}
+	if err := s.sipGateway.Start(); err != nil {
+		return err
+	}
+	if err := s.rtmpIngest.Start(); err != nil {
+		return err
+	}
+	if err := s.srtIngest.Start(); err != nil {
+		return err
+	}
+	if err := s.agentWorker.Start(); err != nil {
+		return err
+	}
 
 	httpGroup := &errgroup.Group{}
 	for _, ln := range listeners {
@@ -294,6 +398,9 @@ func (s *LivekitServer) Start() error {
 	time.Sleep(100 * time.Millisecond)
 
 	s.running.Store(true)
+	s.latencyProber.Start()
+	s.mlExport.Start()
+	s.dspMemory.Start()
 
 	<-s.doneChan
 
@@ -301,19 +408,44 @@ func (s *LivekitServer) Start() error {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
 	defer cancel()
 	_ = s.httpServer.Shutdown(ctx)
+	s.latencyProber.Stop()
+	s.syntheticMonitors.Close()
+	s.mlExport.Stop()
+	s.dspMemory.Stop()
 
 	if s.turnServer != nil {
 		_ = s.turnServer.Close()
 	}
 
+	// callers are hung up and encoders disconnected before their rooms close
+	s.sipGateway.Stop()
+	s.rtmpIngest.Stop()
+	s.srtIngest.Stop()
 	s.roomManager.Stop()
+	s.pcmTap.Stop()
+	s.audioInject.Stop()
+	s.agentWorker.Stop()
 	s.signalServer.Stop()
 	s.ioService.Stop()
+	if closer, ok := s.roomManager.telemetry.(interface{ Close() }); ok {
+		closer.Close()
+	}
 
 	close(s.closedChan)
 	return nil
 }
 
+// ReloadAudioConfig applies the audio settings of conf, a configuration parsed again after the config file changed,
+// without restarting the server. The noise filter models of conf are loaded ahead of applying it, on errors the
+// current settings are kept.
+func (s *LivekitServer) ReloadAudioConfig(conf *config.Config) error {
+	if err := CheckNoiseFilterModels(conf); err != nil {
+		return err
+	}
+	s.roomManager.UpdateAudioConfig(conf.Audio, conf.Room.NoiseFilterPresets)
+	return nil
+}
+
 func (s *LivekitServer) Stop(force bool) {
 	// wait for all participants to exit
 	s.router.Drain()
@@ -362,6 +494,89 @@ func (s *LivekitServer) debugInfo(w http.ResponseWriter, _ *http.Request) {
 	}
 }
 
+// debugFlightRecorder dumps the sampled packet traces of all tracks on this node,
+// or of a single track when the ssrc query parameter is given
+func (s *LivekitServer) debugFlightRecorder(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	recorder := s.roomManager.rtcConfig.FlightRecorder
+
+	var dump interface{}
+	if ssrcParam := r.URL.Query().Get("ssrc"); ssrcParam != "" {
+		ssrc, err := strconv.ParseUint(ssrcParam, 10, 32)
+		if err != nil {
+			HandleError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		track, ok := recorder.DumpTrack(uint32(ssrc))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		dump = track
+	} else {
+		dump = recorder.Dump()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(dump)
+}
+
+// debugCanary reports the rollout state of the canary interceptor stage
+func (s *LivekitServer) debugCanary(w http.ResponseWriter, r *http.Request) {
+	if err := EnsureListPermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(s.roomManager.rtcConfig.Canary.Status())
+}
+
+type warmRoomClaim struct {
+	Room livekit.RoomName `json:"room"`
+	Sid  livekit.RoomID   `json:"sid"`
+}
+
+// claimWarmRoom hands out a standby room with a connected agent (POST), the optional metadata is applied to
+// the room before the caller joins. It requires a token with the roomCreate grant.
+func (s *LivekitServer) claimWarmRoom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := EnsureCreatePermission(r.Context()); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	metadata := r.URL.Query().Get("metadata")
+	if maxMetadataSize := int(s.config.Limit.MaxMetadataSize); maxMetadataSize > 0 && len(metadata) > maxMetadataSize {
+		HandleError(w, r, http.StatusBadRequest, ErrMetadataExceedsLimits)
+		return
+	}
+
+	room, err := s.roomManager.ClaimWarmRoom(r.Context(), metadata)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var psrpcErr psrpc.Error
+		if errors.As(err, &psrpcErr) {
+			status = psrpcErr.ToHttp()
+		}
+		HandleError(w, r, status, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(warmRoomClaim{
+		Room: livekit.RoomName(room.Name),
+		Sid:  livekit.RoomID(room.Sid),
+	})
+}
+
 func (s *LivekitServer) defaultHandler(w http.ResponseWriter, r *http.Request) {
 	if r.URL.Path == "/" {
 		s.healthCheck(w, r)
@@ -371,11 +586,7 @@ func (s *LivekitServer) defaultHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *LivekitServer) healthCheck(w http.ResponseWriter, _ *http.Request) {
-	var updatedAt time.Time
-	if s.Node().Stats != nil {
-		updatedAt = time.Unix(s.Node().Stats.UpdatedAt, 0)
-	}
-	if time.Since(updatedAt) > 4*time.Second {
+	if ready, updatedAt := s.isReady(); !ready {
 		w.WriteHeader(http.StatusNotAcceptable)
 		_, _ = w.Write([]byte(fmt.Sprintf("Not Ready\nNode Updated At %s", updatedAt)))
 		return
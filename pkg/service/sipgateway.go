@@ -0,0 +1,103 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sip"
+)
+
+// time the room of a call may take to be created
+const sipRoomTimeout = 10 * time.Second
+
+// SIPGatewayServer answers the phone calls SIP trunks send to this node, a caller joins the room named with the
+// room prefix followed by the number it dialed, created on this node if it does not exist. The room stays open
+// while the call lasts.
+type SIPGatewayServer struct {
+	config      sip.Config
+	roomManager *RoomManager
+	server      *sip.Server
+	logger      logger.Logger
+}
+
+func newSIPGatewayServer(conf sip.Config, nodeIP string, roomManager *RoomManager) (*SIPGatewayServer, error) {
+	if conf.ExternalIP == "" {
+		conf.ExternalIP = nodeIP
+	}
+
+	g := &SIPGatewayServer{
+		config:      conf,
+		roomManager: roomManager,
+		logger:      logger.GetLogger().WithComponent("sip_gateway"),
+	}
+	server, err := sip.NewServer(sip.ServerParams{
+		Config:   conf,
+		Logger:   g.logger,
+		OnInvite: g.onInvite,
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.server = server
+	return g, nil
+}
+
+func (g *SIPGatewayServer) Start() error {
+	if g == nil {
+		return nil
+	}
+	return g.server.Start()
+}
+
+// Stop hangs up all calls
+func (g *SIPGatewayServer) Stop() {
+	if g == nil {
+		return
+	}
+	g.server.Stop()
+}
+
+func (g *SIPGatewayServer) onInvite(call *sip.Call) (sip.CallHandler, error) {
+	if call.To == "" {
+		return nil, sip.ErrNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sipRoomTimeout)
+	defer cancel()
+
+	roomName := livekit.RoomName(g.config.RoomPrefix + call.To)
+	room, err := g.roomManager.getOrCreateRoom(ctx, &livekit.CreateRoomRequest{Name: string(roomName)})
+	if err != nil {
+		g.logger.Warnw("could not create room of sip call", err, "room", roomName, "callID", call.ID)
+		return nil, sip.ErrServiceUnavailable
+	}
+
+	handler, err := room.JoinSIPCall(call)
+	if err != nil {
+		room.Release()
+		return nil, err
+	}
+	go func() {
+		<-call.Done()
+		room.Release()
+	}()
+	return handler, nil
+}
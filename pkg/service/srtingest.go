@@ -0,0 +1,146 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/srt"
+)
+
+// prefix of stream ids in the access control syntax of SRT, #!::key=value,...
+const srtStreamIDPrefix = "#!::"
+
+var errSRTMode = errors.New("srt streams can only be published")
+
+// SRTIngestServer receives SRT streams of encoders on this node. Those calling are authorized by an access token
+// as stream id, or as the session of a stream id in the access control syntax, #!::s=<token>,m=publish. Each
+// program of the MPEG-TS of a stream publishes as a participant, the first as the identity of the token, the
+// others with their program number appended.
+type SRTIngestServer struct {
+	config  srt.Config
+	starter *ingestStarter
+	server  *srt.Server
+	logger  logger.Logger
+}
+
+func newSRTIngestServer(conf srt.Config, keyProvider auth.KeyProvider, roomManager *RoomManager) (*SRTIngestServer, error) {
+	s := &SRTIngestServer{
+		config: conf,
+		logger: logger.GetLogger().WithComponent("srt_ingest"),
+	}
+	s.starter = &ingestStarter{
+		keyProvider: keyProvider,
+		roomManager: roomManager,
+		logger:      s.logger,
+	}
+	server, err := srt.NewServer(srt.ServerParams{
+		Config:    conf,
+		Logger:    s.logger,
+		OnPublish: s.onPublish,
+		OnCall:    s.onCall,
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.server = server
+	return s, nil
+}
+
+func (s *SRTIngestServer) Start() error {
+	if s == nil {
+		return nil
+	}
+	return s.server.Start()
+}
+
+// Stop disconnects all encoders
+func (s *SRTIngestServer) Stop() {
+	if s == nil {
+		return
+	}
+	s.server.Stop()
+}
+
+// onPublish authorizes the stream, its rooms are joined once its programs are known. The stream id is not
+// logged, it holds the token.
+func (s *SRTIngestServer) onPublish(streamID string) (srt.PublishFunc, error) {
+	token, err := srtStreamToken(streamID)
+	if err != nil {
+		return nil, err
+	}
+	claims, err := s.starter.authorize(token)
+	if err != nil {
+		if errors.Is(err, ErrPermissionDenied) {
+			return nil, &srt.RejectError{Reason: srt.RejectForbidden, Err: err}
+		}
+		return nil, &srt.RejectError{Reason: srt.RejectUnauthorized, Err: err}
+	}
+	return s.publishFunc(ingestRoomRequest(claims), livekit.ParticipantIdentity(claims.Identity), claims.Name), nil
+}
+
+// onCall publishes the stream of a listener as the identity it is configured with, no token involved
+func (s *SRTIngestServer) onCall(caller srt.CallerConfig) (srt.PublishFunc, error) {
+	createRoom := &livekit.CreateRoomRequest{Name: caller.Room}
+	return s.publishFunc(createRoom, livekit.ParticipantIdentity(caller.Identity), caller.Name), nil
+}
+
+func (s *SRTIngestServer) publishFunc(createRoom *livekit.CreateRoomRequest, identity livekit.ParticipantIdentity, name string) srt.PublishFunc {
+	return func(program uint16, index int) (ingest.Publisher, error) {
+		params := rtc.IngestParams{
+			Identity:         identity,
+			Name:             name,
+			Protocol:         ingest.ProtocolSRT,
+			ReconnectTimeout: s.config.ReconnectTimeout,
+		}
+		if index > 0 {
+			params.Identity = livekit.ParticipantIdentity(fmt.Sprintf("%s_%d", identity, program))
+			if name != "" {
+				params.Name = fmt.Sprintf("%s %d", name, program)
+			}
+		}
+		return s.starter.publish(createRoom, params)
+	}
+}
+
+// srtStreamToken returns the token of a stream id, the whole id or the session of the access control syntax
+func srtStreamToken(streamID string) (string, error) {
+	if !strings.HasPrefix(streamID, srtStreamIDPrefix) {
+		return streamID, nil
+	}
+
+	var token string
+	for _, pair := range strings.Split(strings.TrimPrefix(streamID, srtStreamIDPrefix), ",") {
+		key, value, _ := strings.Cut(pair, "=")
+		switch key {
+		case "s":
+			token = value
+		case "m":
+			if value != "publish" {
+				return "", &srt.RejectError{Reason: srt.RejectBadMode, Err: errSRTMode}
+			}
+		}
+	}
+	return token, nil
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/livekit-server/pkg/syntheticmonitor"
+	testclient "github.com/livekit/livekit-server/test/client"
+)
+
+// SyntheticMonitorService joins a synthetic monitor to a room (POST /synthetic_monitor?room=), returns what
+// the monitor with the identity it joined with measured (GET with &identity=) or removes it (DELETE with
+// &identity=). It requires a token with the roomAdmin grant for the room.
+type SyntheticMonitorService struct {
+	monitors *syntheticmonitor.Monitors
+}
+
+func NewSyntheticMonitorService(monitors *syntheticmonitor.Monitors) *SyntheticMonitorService {
+	return &SyntheticMonitorService{
+		monitors: monitors,
+	}
+}
+
+type syntheticMonitorState struct {
+	Identity livekit.ParticipantIdentity `json:"identity"`
+}
+
+func (s *SyntheticMonitorService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	room := livekit.RoomName(query.Get("room"))
+	identity := livekit.ParticipantIdentity(query.Get("identity"))
+	if room == "" || (identity == "" && r.Method != http.MethodPost) {
+		HandleError(w, r, http.StatusBadRequest, errors.New("room and identity are required"))
+		return
+	}
+	if err := EnsureAdminPermission(r.Context(), room); err != nil {
+		HandleError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		identity, err := s.monitors.Start(r.Context(), room)
+		if err != nil {
+			HandleError(w, r, syntheticMonitorStatus(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(syntheticMonitorState{Identity: identity})
+
+	case http.MethodGet:
+		report, err := s.monitors.Report(room, identity)
+		if err != nil {
+			HandleError(w, r, syntheticMonitorStatus(err), err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(report)
+
+	case http.MethodDelete:
+		if err := s.monitors.Stop(room, identity); err != nil {
+			HandleError(w, r, syntheticMonitorStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+
+	default:
+		HandleError(w, r, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func syntheticMonitorStatus(err error) int {
+	switch {
+	case errors.Is(err, syntheticmonitor.ErrMonitorNotFound), errors.Is(err, ErrRoomNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, syntheticmonitor.ErrTooManyMonitors):
+		return http.StatusTooManyRequests
+	case errors.Is(err, syntheticmonitor.ErrMonitorsClosed):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusInternalServerError
+}
+
+// --------------------------------------
+
+func newSyntheticMonitors(s *LivekitServer) *syntheticmonitor.Monitors {
+	return syntheticmonitor.NewMonitors(syntheticmonitor.MonitorsParams{
+		Config:  s.config.SyntheticMonitor,
+		Logger:  logger.GetLogger().WithComponent("synthetic_monitor"),
+		Connect: s.connectSyntheticMonitor,
+		Measure: s.measureSyntheticMonitor,
+	})
+}
+
+// connectSyntheticMonitor joins a synthetic participant to an existing room of this node, over the same
+// signalling and media path as any client. It publishes an Opus track and subscribes to every track.
+func (s *LivekitServer) connectSyntheticMonitor(
+	ctx context.Context,
+	room livekit.RoomName,
+	identity livekit.ParticipantIdentity,
+) (syntheticmonitor.Participant, error) {
+	// monitors do not create rooms
+	if s.roomManager.GetRoom(ctx, room) == nil {
+		return nil, ErrRoomNotFound
+	}
+	apiKey, apiSecret, err := s.localAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := joinLocalParticipant(
+		s.localHost(),
+		apiKey,
+		apiSecret,
+		string(room),
+		string(identity),
+		true,
+		map[string]string{syntheticmonitor.Attribute: "true"},
+	)
+	if err != nil {
+		return nil, err
+	}
+	track, err := webrtc.NewTrackLocalStaticSample(
+		webrtc.RTPCodecCapability{MimeType: mime.MimeTypeOpus.String(), ClockRate: 48000, Channels: 2},
+		"synthetic-monitor",
+		"synthetic-monitor",
+	)
+	if err == nil {
+		_, err = client.AddTrack(track, "", testclient.AddTrackNoWriter())
+	}
+	if err != nil {
+		client.Stop()
+		return nil, err
+	}
+	return &syntheticMonitorParticipant{client: client, track: track}, nil
+}
+
+// measureSyntheticMonitor returns the quality of the down tracks sending the tracks of the room to a monitor
+func (s *LivekitServer) measureSyntheticMonitor(
+	room livekit.RoomName,
+	identity livekit.ParticipantIdentity,
+) ([]*syntheticmonitor.TrackQuality, bool) {
+	r := s.roomManager.GetRoom(context.Background(), room)
+	if r == nil {
+		return nil, false
+	}
+	p := r.GetParticipant(identity)
+	if p == nil {
+		return nil, false
+	}
+
+	tracks := make([]*syntheticmonitor.TrackQuality, 0)
+	for _, st := range p.GetSubscribedTracks() {
+		dt := st.DownTrack()
+		if dt == nil {
+			continue
+		}
+		score, quality := dt.GetConnectionScoreAndQuality()
+		q := &syntheticmonitor.TrackQuality{
+			ParticipantIdentity: st.PublisherIdentity(),
+			TrackID:             st.ID(),
+			Kind:                dt.Kind().String(),
+			Score:               score,
+			Quality:             quality.String(),
+		}
+		if rtpStats := dt.GetTrackStats(); rtpStats != nil {
+			q.Packets = rtpStats.Packets
+			q.PacketsLost = rtpStats.PacketsLost
+			q.PacketLoss = rtpStats.PacketLossPercentage
+			q.JitterMs = rtpStats.JitterCurrent / 1e3
+			q.RttMs = rtpStats.RttCurrent
+			q.Bitrate = rtpStats.Bitrate
+		}
+		tracks = append(tracks, q)
+	}
+	return tracks, true
+}
+
+// --------------------------------------
+
+type syntheticMonitorParticipant struct {
+	client *testclient.RTCClient
+	track  *webrtc.TrackLocalStaticSample
+}
+
+func (p *syntheticMonitorParticipant) WriteFrame(payload []byte, duration time.Duration) error {
+	return p.track.WriteSample(media.Sample{Data: payload, Duration: duration})
+}
+
+func (p *syntheticMonitorParticipant) Close() {
+	p.client.Stop()
+}
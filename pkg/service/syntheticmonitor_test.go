@@ -0,0 +1,146 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/service"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/syntheticmonitor"
+)
+
+func TestSyntheticMonitorService(t *testing.T) {
+	t.Run("requires room admin", func(t *testing.T) {
+		svc := newTestSyntheticMonitorService(t, 1)
+
+		for name, ctx := range map[string]context.Context{
+			"no grants":  context.Background(),
+			"not admin":  service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{Room: "testroom"}}, ""),
+			"other room": service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomAdmin: true, Room: "otherroom"}}, ""),
+		} {
+			rec := serveSyntheticMonitor(ctx, svc, http.MethodPost, "room=testroom")
+			require.Equal(t, http.StatusUnauthorized, rec.Code, name)
+		}
+	})
+
+	t.Run("start, report and stop", func(t *testing.T) {
+		svc := newTestSyntheticMonitorService(t, 1)
+		ctx := syntheticMonitorAdmin("testroom")
+
+		rec := serveSyntheticMonitor(ctx, svc, http.MethodPost, "room=testroom")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var state struct {
+			Identity string `json:"identity"`
+		}
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&state))
+		require.NotEmpty(t, state.Identity)
+
+		rec = serveSyntheticMonitor(ctx, svc, http.MethodGet, "room=testroom&identity="+state.Identity)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var report syntheticmonitor.Report
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&report))
+		require.Equal(t, livekit.ParticipantIdentity(state.Identity), report.Identity)
+		require.True(t, report.Connected)
+		require.Len(t, report.Tracks, 1)
+
+		rec = serveSyntheticMonitor(ctx, svc, http.MethodDelete, "room=testroom&identity="+state.Identity)
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		rec = serveSyntheticMonitor(ctx, svc, http.MethodGet, "room=testroom&identity="+state.Identity)
+		require.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("limits monitors", func(t *testing.T) {
+		svc := newTestSyntheticMonitorService(t, 1)
+		ctx := syntheticMonitorAdmin("testroom")
+
+		rec := serveSyntheticMonitor(ctx, svc, http.MethodPost, "room=testroom")
+		require.Equal(t, http.StatusOK, rec.Code)
+		rec = serveSyntheticMonitor(ctx, svc, http.MethodPost, "room=testroom")
+		require.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("requires identity", func(t *testing.T) {
+		svc := newTestSyntheticMonitorService(t, 1)
+
+		rec := serveSyntheticMonitor(syntheticMonitorAdmin("testroom"), svc, http.MethodGet, "room=testroom")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+}
+
+func newTestSyntheticMonitorService(t *testing.T, maxMonitors int) *service.SyntheticMonitorService {
+	if !audio.IsOpusCodecAvailable() {
+		t.Skip("opus codec unavailable")
+	}
+
+	var lock sync.Mutex
+	connected := make(map[livekit.ParticipantIdentity]bool)
+	monitors := syntheticmonitor.NewMonitors(syntheticmonitor.MonitorsParams{
+		Config: syntheticmonitor.Config{
+			Enabled:      true,
+			MaxMonitors:  maxMonitors,
+			ToneInterval: time.Second,
+			ToneBurst:    100 * time.Millisecond,
+		},
+		Logger: logger.GetLogger(),
+		Connect: func(_ context.Context, _ livekit.RoomName, identity livekit.ParticipantIdentity) (syntheticmonitor.Participant, error) {
+			lock.Lock()
+			defer lock.Unlock()
+			connected[identity] = true
+			return &testSyntheticMonitorParticipant{}, nil
+		},
+		Measure: func(_ livekit.RoomName, identity livekit.ParticipantIdentity) ([]*syntheticmonitor.TrackQuality, bool) {
+			lock.Lock()
+			defer lock.Unlock()
+			if !connected[identity] {
+				return nil, false
+			}
+			return []*syntheticmonitor.TrackQuality{{ParticipantIdentity: "publisher", TrackID: "TR_audio", Kind: "audio", Score: 5}}, true
+		},
+	})
+	t.Cleanup(monitors.Close)
+	return service.NewSyntheticMonitorService(monitors)
+}
+
+func syntheticMonitorAdmin(room string) context.Context {
+	return service.WithGrants(context.Background(), &auth.ClaimGrants{Video: &auth.VideoGrant{RoomAdmin: true, Room: room}}, "")
+}
+
+func serveSyntheticMonitor(ctx context.Context, svc http.Handler, method string, query string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, "/synthetic_monitor?"+query, nil).WithContext(ctx)
+	rec := httptest.NewRecorder()
+	svc.ServeHTTP(rec, req)
+	return rec
+}
+
+type testSyntheticMonitorParticipant struct{}
+
+func (p *testSyntheticMonitorParticipant) WriteFrame(_ []byte, _ time.Duration) error { return nil }
+
+func (p *testSyntheticMonitorParticipant) Close() {}
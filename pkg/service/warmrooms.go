@@ -0,0 +1,225 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/rtc"
+	"github.com/livekit/livekit-server/pkg/rtc/types"
+)
+
+const warmRoomCheckInterval = time.Second
+
+type warmRoom struct {
+	room      *rtc.Room
+	createdAt time.Time
+	ready     bool
+}
+
+// warmRoomPool keeps rooms on standby with the agent dispatched at creation, a claim hands out the
+// oldest room whose agent has joined and completed its negotiation, so the caller only has to connect
+type warmRoomPool struct {
+	config  config.WarmPoolConfig
+	manager *RoomManager
+	logger  logger.Logger
+
+	lock  sync.Mutex
+	rooms []*warmRoom
+
+	// serializes room creation of the worker and claims, keeping the pool at its size
+	replenishLock sync.Mutex
+
+	stop core.Fuse
+}
+
+func newWarmRoomPool(conf config.WarmPoolConfig, manager *RoomManager) *warmRoomPool {
+	if !conf.Enabled || conf.Size <= 0 {
+		return nil
+	}
+	if conf.ReadyTimeout <= 0 {
+		conf.ReadyTimeout = config.DefaultWarmPoolConfig.ReadyTimeout
+	}
+	if conf.MaxAge <= 0 {
+		conf.MaxAge = config.DefaultWarmPoolConfig.MaxAge
+	}
+	if conf.ClaimTimeout <= 0 {
+		conf.ClaimTimeout = config.DefaultWarmPoolConfig.ClaimTimeout
+	}
+
+	p := &warmRoomPool{
+		config:  conf,
+		manager: manager,
+		logger:  logger.GetLogger().WithComponent("warm_pool"),
+	}
+	go p.worker()
+	return p
+}
+
+func (p *warmRoomPool) Stop() {
+	if p == nil {
+		return
+	}
+
+	p.stop.Break()
+
+	p.lock.Lock()
+	rooms := p.rooms
+	p.rooms = nil
+	p.lock.Unlock()
+
+	for _, wr := range rooms {
+		wr.room.Release()
+	}
+}
+
+// Claim takes the oldest ready room out of the pool, applies metadata when given, which reaches the
+// agent as a room metadata change, and keeps the room open for the claim timeout for the caller to join
+func (p *warmRoomPool) Claim(metadata string) (*rtc.Room, error) {
+	if p == nil {
+		return nil, ErrWarmPoolNotEnabled
+	}
+
+	p.lock.Lock()
+	var claimed *warmRoom
+	for i, wr := range p.rooms {
+		if wr.ready && !wr.room.IsClosed() {
+			claimed = wr
+			p.rooms = append(p.rooms[:i:i], p.rooms[i+1:]...)
+			break
+		}
+	}
+	p.lock.Unlock()
+
+	if claimed == nil {
+		return nil, ErrNoWarmRoom
+	}
+
+	room := claimed.room
+	if metadata != "" {
+		<-room.SetMetadata(metadata)
+	}
+	time.AfterFunc(p.config.ClaimTimeout, room.Release)
+
+	p.logger.Infow(
+		"warm room claimed",
+		"room", room.Name(),
+		"standbyTime", time.Since(claimed.createdAt),
+	)
+	go p.replenish()
+	return room, nil
+}
+
+func (p *warmRoomPool) worker() {
+	// rooms are created from the first tick on, giving the server time to accept agent connections
+	ticker := time.NewTicker(warmRoomCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.check()
+			p.replenish()
+
+		case <-p.stop.Watch():
+			return
+		}
+	}
+}
+
+// check marks rooms ready once an agent is active and retires rooms that closed, did not get an agent in time or
+// stood by for too long, agent sessions are not kept open indefinitely
+func (p *warmRoomPool) check() {
+	now := time.Now()
+
+	var retired []*warmRoom
+	p.lock.Lock()
+	rooms := p.rooms[:0]
+	for _, wr := range p.rooms {
+		if !wr.ready {
+			wr.ready = hasActiveAgent(wr.room)
+		}
+
+		age := now.Sub(wr.createdAt)
+		if wr.room.IsClosed() || age > p.config.MaxAge || (!wr.ready && age > p.config.ReadyTimeout) {
+			retired = append(retired, wr)
+			continue
+		}
+		rooms = append(rooms, wr)
+	}
+	p.rooms = rooms
+	p.lock.Unlock()
+
+	for _, wr := range retired {
+		if !wr.room.IsClosed() {
+			p.logger.Infow("retiring warm room", "room", wr.room.Name(), "ready", wr.ready, "age", now.Sub(wr.createdAt))
+			wr.room.Release()
+			wr.room.Close(types.ParticipantCloseReasonRoomClosed)
+		}
+	}
+}
+
+func (p *warmRoomPool) replenish() {
+	p.replenishLock.Lock()
+	defer p.replenishLock.Unlock()
+
+	p.lock.Lock()
+	missing := p.config.Size - len(p.rooms)
+	p.lock.Unlock()
+
+	for i := 0; i < missing; i++ {
+		if p.stop.IsBroken() {
+			return
+		}
+
+		room, err := p.manager.getOrCreateRoom(context.Background(), &livekit.CreateRoomRequest{
+			Name: p.config.RoomPrefix + guid.New(""),
+			Agents: []*livekit.RoomAgentDispatch{
+				{
+					AgentName: p.config.AgentName,
+					Metadata:  p.config.AgentMetadata,
+				},
+			},
+		})
+		if err != nil {
+			p.logger.Warnw("could not create warm room", err)
+			return
+		}
+
+		// the hold taken by room creation keeps the room open while it is on standby
+		p.lock.Lock()
+		p.rooms = append(p.rooms, &warmRoom{room: room, createdAt: time.Now()})
+		p.lock.Unlock()
+	}
+}
+
+func hasActiveAgent(room *rtc.Room) bool {
+	for _, p := range room.GetParticipants() {
+		if p.IsAgent() && p.State() == livekit.ParticipantInfo_ACTIVE {
+			return true
+		}
+	}
+	return false
+}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/url"
 	"strings"
@@ -205,7 +206,7 @@ func (s *WHIPService) validateCreate(r *http.Request) (*createRequest, int, erro
 }
 
 func (s *WHIPService) handleCreate(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get("Content-type") != "application/sdp" {
+	if !hasContentType(r, "application/sdp") {
 		s.handleError("Create", w, r, http.StatusBadRequest, fmt.Errorf("unsupported content-type: %s", r.Header.Get("Content-type")))
 		return
 	}
@@ -423,7 +424,7 @@ func (s *WHIPService) iceRestart(
 }
 
 func (s *WHIPService) handleParticipantPatch(w http.ResponseWriter, r *http.Request) {
-	if r.Header.Get("Content-type") != "application/trickle-ice-sdpfrag" {
+	if !hasContentType(r, "application/trickle-ice-sdpfrag") {
 		s.handleError("Patch", w, r, http.StatusBadRequest, fmt.Errorf("unsupported content-type: %s", r.Header.Get("Content-type")))
 		return
 	}
@@ -465,6 +466,7 @@ func (s *WHIPService) handleParticipantPatch(w http.ResponseWriter, r *http.Requ
 	sdpFragmentBytes, err := ioutil.ReadAll(r.Body)
 	if err != nil {
 		s.handleError("Patch", w, r, http.StatusBadRequest, fmt.Errorf("body does not have SDP fragment: %s", err))
+		return
 	}
 	sdpFragment := string(sdpFragmentBytes)
 
@@ -521,6 +523,9 @@ func (s *WHIPService) handleParticipantDelete(w http.ResponseWriter, r *http.Req
 }
 
 func (s *WHIPService) handleError(method string, w http.ResponseWriter, r *http.Request, status int, err error) {
+	if err == nil {
+		err = errors.New(http.StatusText(status))
+	}
 	sutils.GetLogger(r.Context()).Warnw(
 		fmt.Sprintf("API WHIP.%s", method), err,
 		"status", status,
@@ -532,3 +537,10 @@ func (s *WHIPService) handleError(method string, w http.ResponseWriter, r *http.
 		Error: err.Error(),
 	})
 }
+
+// hasContentType returns true if the media type of the request is mediaType, encoders may add parameters
+// like a charset and vary in case
+func hasContentType(r *http.Request, mediaType string) bool {
+	mt, _, err := mime.ParseMediaType(r.Header.Get("Content-type"))
+	return err == nil && mt == mediaType
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+
+	"github.com/livekit/livekit-server/pkg/service"
+)
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("connection reset")
+}
+
+func serveWHIP(ctx context.Context, method string, path string, header map[string]string, body io.Reader) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	(&service.WHIPService{}).SetupRoutes(mux)
+
+	req := httptest.NewRequest(method, path, body).WithContext(ctx)
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeWHIPError returns the error of the response, which must be its only content
+func decodeWHIPError(t *testing.T, rec *httptest.ResponseRecorder) string {
+	var res struct {
+		Error string `json:"error"`
+	}
+	decoder := json.NewDecoder(rec.Body)
+	require.NoError(t, decoder.Decode(&res))
+	require.ErrorIs(t, decoder.Decode(&res), io.EOF)
+	return res.Error
+}
+
+func contentType(value string) map[string]string {
+	return map[string]string{"Content-Type": value}
+}
+
+func TestWHIPContentType(t *testing.T) {
+	t.Run("offer with parameters", func(t *testing.T) {
+		// passes the content type check and fails authorization
+		rec := serveWHIP(context.Background(), http.MethodPost, "/whip/v1", contentType("application/sdp; charset=utf-8"), strings.NewReader("v=0"))
+		require.Equal(t, http.StatusUnauthorized, rec.Code)
+
+		rec = serveWHIP(context.Background(), http.MethodPost, "/whip/v1", contentType("text/plain"), strings.NewReader("v=0"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, decodeWHIPError(t, rec), "unsupported content-type")
+	})
+
+	t.Run("sdp fragment with parameters", func(t *testing.T) {
+		rec := serveWHIP(context.Background(), http.MethodPatch, "/whip/v1/PA_test", contentType("application/trickle-ice-sdpfrag; charset=utf-8"), strings.NewReader("a=end-of-candidates"))
+		require.Equal(t, http.StatusPreconditionRequired, rec.Code)
+
+		rec = serveWHIP(context.Background(), http.MethodPatch, "/whip/v1/PA_test", contentType("application/sdp"), strings.NewReader("a=end-of-candidates"))
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		require.Contains(t, decodeWHIPError(t, rec), "unsupported content-type")
+	})
+}
+
+func TestWHIPPatchBodyReadFails(t *testing.T) {
+	ctx := service.WithGrants(context.Background(), &auth.ClaimGrants{
+		Identity: "publisher",
+		Video:    &auth.VideoGrant{RoomJoin: true, Room: "testroom"},
+	}, "")
+	rec := serveWHIP(ctx, http.MethodPatch, "/whip/v1/PA_test", map[string]string{
+		"Content-Type": "application/trickle-ice-sdpfrag; charset=utf-8",
+		"If-Match":     "*",
+	}, failingReader{})
+
+	// answered once, the ICE restart is not attempted
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	require.Contains(t, decodeWHIPError(t, rec), "body does not have SDP fragment")
+}
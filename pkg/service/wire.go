@@ -29,7 +29,9 @@ import (
 
 	"github.com/livekit/livekit-server/pkg/agent"
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/roomapi"
 	"github.com/livekit/livekit-server/pkg/routing"
+	"github.com/livekit/livekit-server/pkg/rtc"
 	"github.com/livekit/livekit-server/pkg/sfu"
 	"github.com/livekit/livekit-server/pkg/telemetry"
 	"github.com/livekit/protocol/auth"
@@ -42,7 +44,7 @@ import (
 	"github.com/livekit/psrpc"
 )
 
-func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*LivekitServer, error) {
+func InitializeServer(conf *config.Config, currentNode routing.LocalNode, opts ServerOptions) (*LivekitServer, error) {
 	wire.Build(
 		getNodeID,
 		createRedisClient,
@@ -57,8 +59,9 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 		config.DefaultAPIConfig,
 		wire.Bind(new(routing.MessageRouter), new(routing.Router)),
 		wire.Bind(new(livekit.RoomService), new(*RoomService)),
+		wire.Bind(new(roomapi.RoomService), new(*RoomService)),
 		telemetry.NewAnalyticsService,
-		telemetry.NewTelemetryService,
+		createTelemetryService,
 		getMessageBus,
 		NewIOInfoService,
 		wire.Bind(new(IOClient), new(*IOInfoService)),
@@ -94,8 +97,10 @@ func InitializeServer(conf *config.Config, currentNode routing.LocalNode) (*Live
 		rpc.NewTopicFormatter,
 		rpc.NewTypedRoomClient,
 		rpc.NewTypedParticipantClient,
+		roomapi.NewTypedRoomProcessingClient,
 		rpc.NewTypedWHIPParticipantClient,
 		rpc.NewTypedAgentDispatchInternalClient,
+		getInterceptorStages,
 		NewLocalRoomManager,
 		NewTURNAuthHandler,
 		getTURNAuthHandlerFunc,
@@ -274,3 +279,15 @@ func getNodeStatsConfig(config *config.Config) config.NodeStatsConfig {
 func getAgentConfig(config *config.Config) agent.Config {
 	return config.Agents
 }
+
+func createTelemetryService(opts ServerOptions, notifier webhook.QueuedNotifier, analytics telemetry.AnalyticsService) telemetry.TelemetryService {
+	ts := telemetry.NewTelemetryService(notifier, analytics)
+	if opts.Telemetry != nil {
+		ts = opts.Telemetry(ts)
+	}
+	return ts
+}
+
+func getInterceptorStages(opts ServerOptions) []rtc.InterceptorStage {
+	return opts.Interceptors
+}
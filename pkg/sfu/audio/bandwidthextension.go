@@ -0,0 +1,196 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	// samples of the 10 ms frames at 48 kHz a bandwidth extender processes
+	BandwidthExtensionFrameSize = 480
+
+	BandwidthExtensionModelSpectral = "spectral"
+)
+
+var ErrUnknownBandwidthExtensionModel = errors.New("unknown bandwidth extension model")
+
+// BandwidthExtensionConfig controls the reconstruction of the band above telephone bandwidth for audio that
+// was narrowband at its source, e. g. PSTN callers reaching the server through a SIP gateway, so that
+// subscribers and speech to text get wideband speech
+type BandwidthExtensionConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// model reconstructing the upper band, the built in one is `spectral`, others are added with
+	// RegisterBandwidthExtensionModel
+	Model string `json:"model" yaml:"model,omitempty"`
+	// weights of models that load them, unused by `spectral`
+	ModelPath string `json:"model_path" yaml:"model_path,omitempty"`
+	// level of the reconstructed band relative to the top of the telephone band, in dB
+	Gain float64 `json:"gain" yaml:"gain,omitempty"`
+}
+
+var (
+	DefaultBandwidthExtensionConfig = BandwidthExtensionConfig{
+		Model: BandwidthExtensionModelSpectral,
+		Gain:  -6,
+	}
+)
+
+// BandwidthExtender reconstructs the upper band of narrowband speech in 48 kHz mono audio. Not safe for concurrent use.
+type BandwidthExtender interface {
+	// Extend processes a frame of BandwidthExtensionFrameSize normalized samples in place. Frames that already
+	// carry the upper band are left as they are.
+	Extend(frame []float32)
+}
+
+type BandwidthExtensionModel func(config BandwidthExtensionConfig) (BandwidthExtender, error)
+
+// --------------------------------------
+
+var (
+	bandwidthExtensionLock   sync.RWMutex
+	bandwidthExtensionModels = map[string]BandwidthExtensionModel{
+		BandwidthExtensionModelSpectral: newSpectralBandwidthExtender,
+	}
+)
+
+// RegisterBandwidthExtensionModel makes a model available to the `model` setting, replacing one of the same name
+func RegisterBandwidthExtensionModel(name string, model BandwidthExtensionModel) {
+	bandwidthExtensionLock.Lock()
+	bandwidthExtensionModels[name] = model
+	bandwidthExtensionLock.Unlock()
+}
+
+// NewBandwidthExtender creates an extender of the configured model, the built in one if none is configured
+func NewBandwidthExtender(config BandwidthExtensionConfig) (BandwidthExtender, error) {
+	name := config.Model
+	if name == "" {
+		name = BandwidthExtensionModelSpectral
+	}
+
+	bandwidthExtensionLock.RLock()
+	model := bandwidthExtensionModels[name]
+	bandwidthExtensionLock.RUnlock()
+
+	if model == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownBandwidthExtensionModel, name)
+	}
+	return model(config)
+}
+
+// --------------------------------------
+
+const (
+	// edge of the telephone band, 300 Hz to 3.4 kHz, the reconstruction is derived from the top of it
+	telephoneBandSourceLow  = 2000
+	telephoneBandSourceHigh = 3400
+	// band that is reconstructed
+	extensionBandLow  = 4000
+	extensionBandHigh = 8000
+	// energy above this frequency marks audio that is wideband already
+	widebandProbeCutoff = 5000
+	// audio with this much energy above the probe cutoff relative to all of it is treated as wideband, -40 dB
+	widebandEnergyRatio = 1e-4
+	// frames the gain of the reconstructed band takes to settle when audio turns from narrowband to wideband
+	extensionGainFrames = 10
+)
+
+// spectralBandwidthExtender generates harmonics of the top of the telephone band by full wave rectification and
+// shapes them into the band above it, at a level following the band they are generated from. It adds no delay.
+type spectralBandwidthExtender struct {
+	gain float32
+
+	source  [2]biquad
+	shaping [4]biquad
+	probe   [4]biquad
+
+	generated []float32
+	// gain applied at the end of the previous frame, ramped towards the gain of the next one
+	current float32
+}
+
+func newSpectralBandwidthExtender(config BandwidthExtensionConfig) (BandwidthExtender, error) {
+	const rate = OpusSampleRate
+	return &spectralBandwidthExtender{
+		gain: float32(math.Pow(10, config.Gain/20)),
+		source: [2]biquad{
+			newHighpassBiquad(telephoneBandSourceLow, rate, 0.7071),
+			newLowpassBiquad(telephoneBandSourceHigh, rate, 0.7071),
+		},
+		// fourth order band pass, steep enough to keep the rectified signal out of the telephone band
+		shaping: [4]biquad{
+			newHighpassBiquad(extensionBandLow, rate, 0.5412),
+			newHighpassBiquad(extensionBandLow, rate, 1.3066),
+			newLowpassBiquad(extensionBandHigh, rate, 0.5412),
+			newLowpassBiquad(extensionBandHigh, rate, 1.3066),
+		},
+		// eighth order, the top of the telephone band must not register
+		probe: [4]biquad{
+			newHighpassBiquad(widebandProbeCutoff, rate, 0.5098),
+			newHighpassBiquad(widebandProbeCutoff, rate, 0.6013),
+			newHighpassBiquad(widebandProbeCutoff, rate, 0.9000),
+			newHighpassBiquad(widebandProbeCutoff, rate, 2.5629),
+		},
+		generated: make([]float32, BandwidthExtensionFrameSize),
+	}, nil
+}
+
+func (e *spectralBandwidthExtender) Extend(frame []float32) {
+	var energy, sourceEnergy, generatedEnergy, upperEnergy float64
+	for i, x := range frame {
+		energy += float64(x * x)
+
+		s := e.source[1].process(e.source[0].process(x))
+		sourceEnergy += float64(s * s)
+
+		g := s
+		if g < 0 {
+			g = -g
+		}
+		for j := range e.shaping {
+			g = e.shaping[j].process(g)
+		}
+		e.generated[i] = g
+		generatedEnergy += float64(g * g)
+
+		u := x
+		for j := range e.probe {
+			u = e.probe[j].process(u)
+		}
+		upperEnergy += float64(u * u)
+	}
+
+	var target float32
+	if generatedEnergy > 0 && upperEnergy < energy*widebandEnergyRatio {
+		target = e.gain * float32(math.Sqrt(sourceEnergy/generatedEnergy))
+	}
+	if target > e.current {
+		// onsets are followed right away, only drops are smoothed over frames
+		e.current = target
+	}
+	next := e.current + (target-e.current)/extensionGainFrames
+
+	step := (next - e.current) / float32(len(frame))
+	gain := e.current
+	for i := range frame {
+		gain += step
+		frame[i] = min(max(frame[i]+gain*e.generated[i], -1), 1)
+	}
+	e.current = next
+}
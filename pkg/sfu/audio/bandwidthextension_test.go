@@ -0,0 +1,118 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// harmonicFrames returns one second of 10 ms frames of a 200 Hz voice with harmonics up to maxFrequency
+func harmonicFrames(maxFrequency float64) [][]float32 {
+	frames := make([][]float32, OpusSampleRate/BandwidthExtensionFrameSize)
+	for f := range frames {
+		frames[f] = make([]float32, BandwidthExtensionFrameSize)
+		for i := range frames[f] {
+			t := float64(f*BandwidthExtensionFrameSize+i) / OpusSampleRate
+			var sample float64
+			for h := 200.0; h <= maxFrequency; h += 200 {
+				sample += 0.3 / (h / 200) * math.Sin(2*math.Pi*h*t)
+			}
+			frames[f][i] = float32(sample)
+		}
+	}
+	return frames
+}
+
+// bandEnergy returns the energy of the frames between low and high Hz, skipping the first ones while filters settle
+func bandEnergy(frames [][]float32, low, high int) float64 {
+	var samples []complex64
+	for _, frame := range frames[10:] {
+		for _, x := range frame {
+			samples = append(samples, complex(x, 0))
+		}
+	}
+	spectrum := make([]complex64, len(samples))
+	newFFT(len(samples)).transform(samples, spectrum)
+
+	var energy float64
+	for bin := low * len(samples) / OpusSampleRate; bin < high*len(samples)/OpusSampleRate; bin++ {
+		energy += float64(real(spectrum[bin])*real(spectrum[bin]) + imag(spectrum[bin])*imag(spectrum[bin]))
+	}
+	return energy
+}
+
+func TestBandwidthExtender(t *testing.T) {
+	t.Run("reconstructs upper band of narrowband audio", func(t *testing.T) {
+		e, err := NewBandwidthExtender(DefaultBandwidthExtensionConfig)
+		require.NoError(t, err)
+
+		frames := harmonicFrames(3400)
+		before := bandEnergy(frames, 4000, 8000)
+		telephoneBand := bandEnergy(frames, 300, 3400)
+		for _, frame := range frames {
+			e.Extend(frame)
+		}
+
+		after := bandEnergy(frames, 4000, 8000)
+		require.Greater(t, after, 1000*before+1)
+		// well below the telephone band, at about the configured gain relative to its top
+		require.Less(t, after, telephoneBand/10)
+		// the telephone band is left as it was
+		require.InEpsilon(t, telephoneBand, bandEnergy(frames, 300, 3400), 0.05)
+	})
+
+	t.Run("leaves wideband audio alone", func(t *testing.T) {
+		e, err := NewBandwidthExtender(DefaultBandwidthExtensionConfig)
+		require.NoError(t, err)
+
+		frames := harmonicFrames(7000)
+		original := harmonicFrames(7000)
+		for _, frame := range frames {
+			e.Extend(frame)
+		}
+		for f := range frames {
+			for i := range frames[f] {
+				require.InDelta(t, original[f][i], frames[f][i], 1e-6)
+			}
+		}
+	})
+
+	t.Run("silence stays silent", func(t *testing.T) {
+		e, err := NewBandwidthExtender(DefaultBandwidthExtensionConfig)
+		require.NoError(t, err)
+
+		frame := make([]float32, BandwidthExtensionFrameSize)
+		e.Extend(frame)
+		require.Equal(t, make([]float32, BandwidthExtensionFrameSize), frame)
+	})
+
+	t.Run("models", func(t *testing.T) {
+		_, err := NewBandwidthExtender(BandwidthExtensionConfig{Model: "unknown"})
+		require.ErrorIs(t, err, ErrUnknownBandwidthExtensionModel)
+
+		var created BandwidthExtensionConfig
+		RegisterBandwidthExtensionModel("test", func(config BandwidthExtensionConfig) (BandwidthExtender, error) {
+			created = config
+			return newSpectralBandwidthExtender(config)
+		})
+		_, err = NewBandwidthExtender(BandwidthExtensionConfig{Model: "test", ModelPath: "weights.onnx"})
+		require.NoError(t, err)
+		require.Equal(t, "weights.onnx", created.ModelPath)
+	})
+}
@@ -0,0 +1,102 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"slices"
+	"time"
+)
+
+// BargeInConfig controls detecting participants speaking up while an agent plays audio, e. g. TTS,
+// so that the agent can stop talking. Follows voice activity detection, speech is noticed once it
+// lasted the VAD min speech.
+type BargeInConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// an agent still counts as playing this long after its speech stopped, bridges the pauses between sentences
+	Hold time.Duration `yaml:"hold,omitempty"`
+	// an agent is interrupted at most once within this time
+	Cooldown time.Duration `yaml:"cooldown,omitempty"`
+	// mutes the track of the agent when it is interrupted, the agent unmutes it to talk again
+	AutoPause bool `yaml:"auto_pause,omitempty"`
+	// identities of the agents that can be interrupted, all agents when empty
+	Agents []string `yaml:"agents,omitempty"`
+}
+
+var (
+	DefaultBargeInConfig = BargeInConfig{
+		Hold:     300 * time.Millisecond,
+		Cooldown: time.Second,
+	}
+)
+
+func (c BargeInConfig) IsAgent(identity string) bool {
+	return len(c.Agents) == 0 || slices.Contains(c.Agents, identity)
+}
+
+type bargeInAgent struct {
+	speaking      bool
+	lastSpeech    time.Time
+	lastInterrupt time.Time
+}
+
+// BargeInDetector follows whether agent tracks play audio and picks the ones interrupted when somebody
+// else starts speaking. Not safe for concurrent use.
+type BargeInDetector struct {
+	config BargeInConfig
+	agents map[string]*bargeInAgent
+}
+
+func NewBargeInDetector(config BargeInConfig) *BargeInDetector {
+	return &BargeInDetector{
+		config: config,
+		agents: make(map[string]*bargeInAgent),
+	}
+}
+
+// AgentSpeaking records an agent track starting or, at now, stopping to play speech
+func (d *BargeInDetector) AgentSpeaking(id string, now time.Time, speaking bool) {
+	a, ok := d.agents[id]
+	if !ok {
+		a = &bargeInAgent{}
+		d.agents[id] = a
+	}
+	a.speaking = speaking
+	if !speaking {
+		a.lastSpeech = now
+	}
+}
+
+func (d *BargeInDetector) RemoveAgent(id string) {
+	delete(d.agents, id)
+}
+
+// SpeechStarted returns the agent tracks playing speech when somebody else started speaking at now,
+// sorted. Each is not returned again within the cooldown.
+func (d *BargeInDetector) SpeechStarted(now time.Time) []string {
+	var interrupted []string
+	for id, a := range d.agents {
+		if !a.speaking && now.Sub(a.lastSpeech) > d.config.Hold {
+			continue
+		}
+		if !a.lastInterrupt.IsZero() && now.Sub(a.lastInterrupt) < d.config.Cooldown {
+			continue
+		}
+		a.lastInterrupt = now
+		interrupted = append(interrupted, id)
+	}
+	slices.Sort(interrupted)
+	return interrupted
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBargeInDetector(t *testing.T) {
+	config := BargeInConfig{Hold: 300 * time.Millisecond, Cooldown: time.Second}
+	now := time.Unix(1000, 0)
+
+	t.Run("interrupts playing agents", func(t *testing.T) {
+		d := NewBargeInDetector(config)
+		require.Empty(t, d.SpeechStarted(now))
+
+		d.AgentSpeaking("TR_AGENT", now, true)
+		d.AgentSpeaking("TR_QUIET", now, false)
+		require.Equal(t, []string{"TR_AGENT"}, d.SpeechStarted(now.Add(time.Second)))
+		// not again within the cooldown
+		require.Empty(t, d.SpeechStarted(now.Add(1500*time.Millisecond)))
+		require.Equal(t, []string{"TR_AGENT"}, d.SpeechStarted(now.Add(2*time.Second)))
+
+		d.RemoveAgent("TR_AGENT")
+		require.Empty(t, d.SpeechStarted(now.Add(4*time.Second)))
+	})
+
+	t.Run("holds across pauses", func(t *testing.T) {
+		d := NewBargeInDetector(config)
+		d.AgentSpeaking("TR_AGENT", now, true)
+		d.AgentSpeaking("TR_AGENT", now.Add(time.Second), false)
+
+		require.Equal(t, []string{"TR_AGENT"}, d.SpeechStarted(now.Add(1200*time.Millisecond)))
+
+		d.AgentSpeaking("TR_AGENT", now.Add(3*time.Second), true)
+		d.AgentSpeaking("TR_AGENT", now.Add(4*time.Second), false)
+		require.Empty(t, d.SpeechStarted(now.Add(4500*time.Millisecond)))
+	})
+
+	t.Run("designated agents", func(t *testing.T) {
+		require.True(t, BargeInConfig{}.IsAgent("agent"))
+		require.True(t, BargeInConfig{Agents: []string{"agent"}}.IsAgent("agent"))
+		require.False(t, BargeInConfig{Agents: []string{"agent"}}.IsAgent("other"))
+	})
+}
@@ -0,0 +1,71 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type MixChannel string
+
+const (
+	// both channels, at the level of a mono mix
+	MixChannelCenter MixChannel = "center"
+	MixChannelLeft   MixChannel = "left"
+	MixChannelRight  MixChannel = "right"
+
+	// key of a ChannelMap entry applying to all sources not mapped by name
+	ChannelMapDefault = "*"
+)
+
+// ChannelMap assigns mixed sources to the channels of a stereo mix, e. g. an interpreter
+// to the left and the floor to the right. Unmapped sources are mixed into both channels.
+type ChannelMap map[string]MixChannel
+
+// ParseChannelMap parses a JSON object of source names to channels, an empty string is no mapping
+func ParseChannelMap(s string) (ChannelMap, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var m ChannelMap
+	if err := json.Unmarshal([]byte(s), &m); err != nil {
+		return nil, err
+	}
+	for name, channel := range m {
+		switch channel {
+		case MixChannelCenter, MixChannelLeft, MixChannelRight:
+		default:
+			return nil, fmt.Errorf("invalid channel %q for %q", channel, name)
+		}
+	}
+	if len(m) == 0 {
+		return nil, nil
+	}
+	return m, nil
+}
+
+// Channel returns the channel of a source
+func (m ChannelMap) Channel(name string) MixChannel {
+	if channel, ok := m[name]; ok {
+		return channel
+	}
+	if channel, ok := m[ChannelMapDefault]; ok {
+		return channel
+	}
+	return MixChannelCenter
+}
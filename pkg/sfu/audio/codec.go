@@ -0,0 +1,173 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	OpusSampleRate = 48000
+	// samples per channel in a 20 ms frame at 48 kHz
+	OpusFrameSize = 960
+	// largest Opus packet recommended by RFC 6716
+	OpusMaxPacketSize = 1275
+	// 120 ms at 48 kHz, the longest frame an Opus packet can carry
+	OpusMaxFrameSize = 5760
+)
+
+// OpusDecoderNativeBytes approximates the state libopus allocates for a decoder, as reported by
+// opus_decoder_get_size. It is used to account memory the Go runtime does not see.
+func OpusDecoderNativeBytes(channels int) int {
+	if channels > 1 {
+		return 26 << 10
+	}
+	return 18 << 10
+}
+
+// OpusEncoderNativeBytes approximates the state libopus allocates for an encoder, as reported by opus_encoder_get_size
+func OpusEncoderNativeBytes(channels int) int {
+	if channels > 1 {
+		return 41 << 10
+	}
+	return 31 << 10
+}
+
+var ErrOpusCodecUnavailable = errors.New("opus codec unavailable, build with the opus tag")
+
+// OpusDecoder decodes Opus packets into interleaved 16 bit PCM.
+type OpusDecoder interface {
+	// Decode returns the number of samples per channel written to pcm
+	Decode(payload []byte, pcm []int16) (int, error)
+}
+
+// OpusEncoder encodes interleaved 16 bit PCM frames into Opus packets.
+type OpusEncoder interface {
+	// Encode returns the number of bytes written to out
+	Encode(pcm []int16, out []byte) (int, error)
+}
+
+type OpusCodecFactory interface {
+	NewDecoder(sampleRate int, channels int) (OpusDecoder, error)
+	NewEncoder(sampleRate int, channels int) (OpusEncoder, error)
+}
+
+// --------------------------------------
+
+var (
+	opusCodecLock    sync.RWMutex
+	opusCodecFactory OpusCodecFactory
+)
+
+// RegisterOpusCodec installs the Opus implementation used by the server side audio processing.
+// The libopus backed implementation registers itself when built with the `opus` tag.
+func RegisterOpusCodec(f OpusCodecFactory) {
+	opusCodecLock.Lock()
+	opusCodecFactory = f
+	opusCodecLock.Unlock()
+}
+
+func IsOpusCodecAvailable() bool {
+	opusCodecLock.RLock()
+	defer opusCodecLock.RUnlock()
+
+	return opusCodecFactory != nil
+}
+
+func NewOpusDecoder(sampleRate int, channels int) (OpusDecoder, error) {
+	opusCodecLock.RLock()
+	f := opusCodecFactory
+	opusCodecLock.RUnlock()
+
+	if f == nil {
+		return nil, ErrOpusCodecUnavailable
+	}
+	return f.NewDecoder(sampleRate, channels)
+}
+
+func NewOpusEncoder(sampleRate int, channels int) (OpusEncoder, error) {
+	opusCodecLock.RLock()
+	f := opusCodecFactory
+	opusCodecLock.RUnlock()
+
+	if f == nil {
+		return nil, ErrOpusCodecUnavailable
+	}
+	return f.NewEncoder(sampleRate, channels)
+}
+
+// OpusVersion returns the version reported by the registered Opus implementation, empty when it reports none
+func OpusVersion() string {
+	opusCodecLock.RLock()
+	f := opusCodecFactory
+	opusCodecLock.RUnlock()
+
+	if v, ok := f.(interface{ Version() string }); ok {
+		return v.Version()
+	}
+	return ""
+}
+
+// OpusSelfTest encodes and decodes a tone and checks that it survives the round trip
+func OpusSelfTest() error {
+	encoder, err := NewOpusEncoder(OpusSampleRate, 1)
+	if err != nil {
+		return err
+	}
+	decoder, err := NewOpusDecoder(OpusSampleRate, 1)
+	if err != nil {
+		return err
+	}
+
+	pcm := make([]int16, OpusFrameSize)
+	out := make([]int16, OpusMaxFrameSize)
+	payload := make([]byte, OpusMaxPacketSize)
+	var energy float64
+	// the first frames are spent on encoder look-ahead
+	for frame := 0; frame < 5; frame++ {
+		for i := range pcm {
+			t := float64(frame*OpusFrameSize+i) / OpusSampleRate
+			pcm[i] = int16(8000 * math.Sin(2*math.Pi*440*t))
+		}
+
+		n, err := encoder.Encode(pcm, payload)
+		if err != nil {
+			return fmt.Errorf("encode: %w", err)
+		}
+		if n == 0 {
+			return errors.New("encoder produced an empty packet")
+		}
+
+		samples, err := decoder.Decode(payload[:n], out)
+		if err != nil {
+			return fmt.Errorf("decode: %w", err)
+		}
+		if samples != OpusFrameSize {
+			return fmt.Errorf("decoded %d samples, expected %d", samples, OpusFrameSize)
+		}
+		for _, sample := range out[:samples] {
+			energy += float64(sample) * float64(sample)
+		}
+	}
+
+	if energy == 0 {
+		return errors.New("decoded audio is silent")
+	}
+	return nil
+}
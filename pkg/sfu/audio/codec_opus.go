@@ -0,0 +1,41 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build opus
+// +build opus
+
+package audio
+
+import (
+	"gopkg.in/hraban/opus.v2"
+)
+
+func init() {
+	RegisterOpusCodec(libopusFactory{})
+}
+
+type libopusFactory struct{}
+
+func (libopusFactory) NewDecoder(sampleRate int, channels int) (OpusDecoder, error) {
+	return opus.NewDecoder(sampleRate, channels)
+}
+
+func (libopusFactory) NewEncoder(sampleRate int, channels int) (OpusEncoder, error) {
+	return opus.NewEncoder(sampleRate, channels, opus.AppVoIP)
+}
+
+func (libopusFactory) Version() string {
+	return opus.Version()
+}
@@ -0,0 +1,168 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+)
+
+const (
+	// order of the LPC model of the noise spectrum, as in RFC 3389
+	comfortNoiseOrder = 10
+	// weight of the newest frame in the spectral estimate, the envelope follows slowly changing noise
+	comfortNoiseSmoothing = 0.2
+	// added to the energy before the LPC analysis, keeps the synthesis filter stable for tonal noise
+	comfortNoiseWhiteNoiseCorrection = 1e-4
+)
+
+// ComfortNoiseConfig controls the comfort noise that replaces frames suppressed by the noise filter
+type ComfortNoiseConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// highest level of the comfort noise in dBFS, it also stays below the noise it replaces
+	// by the attenuation of the suppression level
+	Level float64 `json:"level" yaml:"level,omitempty"`
+}
+
+var (
+	DefaultComfortNoiseConfig = ComfortNoiseConfig{
+		Enabled: true,
+		Level:   -50,
+	}
+)
+
+// ComfortNoise generates noise with the spectral envelope of the noise it replaces (comfort noise generation),
+// so that suppressed frames do not sound like hard gating. The envelope is a 10th order LPC model learned from
+// the replaced frames, white noise shaped by it is played at a reduced level. Not safe for concurrent use.
+type ComfortNoise struct {
+	maxRMS float64
+	gain   float64
+
+	// autocorrelation of the noise per sample, smoothed over frames
+	autocorr [comfortNoiseOrder + 1]float64
+	primed   bool
+
+	lpc     [comfortNoiseOrder]float64
+	history [comfortNoiseOrder]float64
+	// excitation scale of the previous frame, the level is ramped from it
+	scale float64
+	seed  uint32
+}
+
+// NewComfortNoise creates a generator for the frames suppressed at noiseGain, see NoiseSuppression
+func NewComfortNoise(config ComfortNoiseConfig, noiseGain float32) *ComfortNoise {
+	level := config.Level
+	if level >= 0 {
+		level = DefaultComfortNoiseConfig.Level
+	}
+	return &ComfortNoise{
+		maxRMS: math.Pow(10, level/20),
+		gain:   float64(noiseGain),
+		seed:   0x9e3779b9,
+	}
+}
+
+// Fill replaces a frame of noise, normalized samples of a single channel, in place by comfort noise
+func (c *ComfortNoise) Fill(frame []float32) {
+	if len(frame) == 0 {
+		return
+	}
+
+	c.analyze(frame)
+	residual := levinsonDurbin(&c.autocorr, &c.lpc)
+
+	var scale float64
+	if noiseRMS := math.Sqrt(c.autocorr[0]); noiseRMS > 0 && residual > 0 {
+		// white noise of unit variance through the synthesis filter has the energy of the analyzed noise
+		// when scaled by the prediction error, it is scaled further down to the target level
+		scale = math.Sqrt(residual) * min(c.maxRMS, noiseRMS*c.gain) / noiseRMS
+	}
+
+	prev := c.scale
+	n := float64(len(frame))
+	for i := range frame {
+		s := prev + (scale-prev)*float64(i+1)/n
+		y := c.excitation() * s
+		for k, a := range c.lpc {
+			y -= a * c.history[k]
+		}
+		copy(c.history[1:], c.history[:comfortNoiseOrder-1])
+		c.history[0] = y
+		frame[i] = float32(min(max(y, -1), 1))
+	}
+	c.scale = scale
+}
+
+// Pause is called for frames passed as speech, comfort noise fades in again after them
+func (c *ComfortNoise) Pause() {
+	c.scale = 0
+	c.history = [comfortNoiseOrder]float64{}
+}
+
+func (c *ComfortNoise) analyze(frame []float32) {
+	n := float64(len(frame))
+	for k := range c.autocorr {
+		var sum float64
+		for i := k; i < len(frame); i++ {
+			sum += float64(frame[i]) * float64(frame[i-k])
+		}
+		sum /= n
+
+		if c.primed {
+			c.autocorr[k] += comfortNoiseSmoothing * (sum - c.autocorr[k])
+		} else {
+			c.autocorr[k] = sum
+		}
+	}
+	c.primed = true
+}
+
+// excitation returns uniform white noise of unit variance
+func (c *ComfortNoise) excitation() float64 {
+	// xorshift32
+	c.seed ^= c.seed << 13
+	c.seed ^= c.seed >> 17
+	c.seed ^= c.seed << 5
+	return (float64(c.seed)/math.MaxUint32*2 - 1) * math.Sqrt(3)
+}
+
+// levinsonDurbin computes the coefficients a of the LPC model A(z) = 1 + sum a[k] z^-(k+1) of the autocorrelation r
+// and returns the energy of the prediction error. Stops at the last stable order.
+func levinsonDurbin(r *[comfortNoiseOrder + 1]float64, a *[comfortNoiseOrder]float64) float64 {
+	*a = [comfortNoiseOrder]float64{}
+	energy := r[0] * (1 + comfortNoiseWhiteNoiseCorrection)
+	if energy <= 0 {
+		return 0
+	}
+
+	for i := 0; i < comfortNoiseOrder; i++ {
+		acc := r[i+1]
+		for j := 0; j < i; j++ {
+			acc += a[j] * r[i-j]
+		}
+		k := -acc / energy
+		if k >= 1 || k <= -1 {
+			break
+		}
+
+		prev := *a
+		for j := 0; j < i; j++ {
+			a[j] = prev[j] + k*prev[i-1-j]
+		}
+		a[i] = k
+		energy *= 1 - k*k
+	}
+	return energy
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func frameRMS(frame []float32) float64 {
+	var sum float64
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(frame)))
+}
+
+// lag1 returns the normalized autocorrelation at lag 1, positive for noise with mostly low frequencies
+func lag1(frame []float32) float64 {
+	var r0, r1 float64
+	for i := range frame {
+		r0 += float64(frame[i]) * float64(frame[i])
+		if i > 0 {
+			r1 += float64(frame[i]) * float64(frame[i-1])
+		}
+	}
+	return r1 / r0
+}
+
+func TestComfortNoise(t *testing.T) {
+	const frameSize = 480
+	rng := rand.New(rand.NewSource(1))
+
+	// rumble, white noise through a one pole lowpass at about -30 dBFS
+	var state float64
+	noiseFrame := func() []float32 {
+		frame := make([]float32, frameSize)
+		for i := range frame {
+			state = 0.95*state + 0.05*rng.NormFloat64()
+			frame[i] = float32(state * 0.1)
+		}
+		return frame
+	}
+
+	// level of the frames, averaged once the spectral estimate has settled
+	fill := func(cn *ComfortNoise) (noiseRMS float64, comfortRMS float64, comfortLag1 float64) {
+		const frames, settled = 200, 50
+		for i := range frames {
+			frame := noiseFrame()
+			if i >= settled {
+				noiseRMS += frameRMS(frame) * frameRMS(frame)
+			}
+			cn.Fill(frame)
+			if i >= settled {
+				comfortRMS += frameRMS(frame) * frameRMS(frame)
+				comfortLag1 += lag1(frame)
+			}
+		}
+		n := float64(frames - settled)
+		return math.Sqrt(noiseRMS / n), math.Sqrt(comfortRMS / n), comfortLag1 / n
+	}
+
+	t.Run("shaped and attenuated", func(t *testing.T) {
+		noiseRMS, comfortRMS, comfortLag1 := fill(NewComfortNoise(ComfortNoiseConfig{Enabled: true, Level: -20}, 0.1))
+
+		// -20 dB of the noise, below the configured level
+		require.InDelta(t, noiseRMS*0.1, comfortRMS, noiseRMS*0.03)
+		// keeps the low frequency character of the noise instead of being white
+		require.Greater(t, comfortLag1, 0.8)
+	})
+
+	t.Run("capped at level", func(t *testing.T) {
+		_, comfortRMS, _ := fill(NewComfortNoise(ComfortNoiseConfig{Enabled: true, Level: -60}, 1))
+		require.InDelta(t, 0.001, comfortRMS, 0.0003)
+	})
+
+	t.Run("fades in after speech", func(t *testing.T) {
+		cn := NewComfortNoise(DefaultComfortNoiseConfig, 0.1)
+		for range 10 {
+			cn.Fill(noiseFrame())
+		}
+		cn.Pause()
+
+		frame := noiseFrame()
+		cn.Fill(frame)
+		require.Less(t, frameRMS(frame[:frameSize/4]), frameRMS(frame[3*frameSize/4:]))
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		cn := NewComfortNoise(DefaultComfortNoiseConfig, 0.1)
+		frame := make([]float32, frameSize)
+		cn.Fill(frame)
+		require.Zero(t, frameRMS(frame))
+	})
+}
@@ -0,0 +1,184 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"time"
+)
+
+// ConcealmentConfig controls synthesizing audio for packets lost from Opus streams that are decoded on the
+// server, so that consumers of the decoded audio get no holes where the packets were lost
+type ConcealmentConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// longest loss that is concealed, the rest of a longer one stays a hole
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+}
+
+var (
+	DefaultConcealmentConfig = ConcealmentConfig{
+		MaxDuration: 100 * time.Millisecond,
+	}
+)
+
+// OpusConcealingDecoder is implemented by Opus decoders that can synthesize the audio of lost packets
+type OpusConcealingDecoder interface {
+	// DecodePLC fills pcm, sized to the duration of the lost audio, with packet loss concealment
+	DecodePLC(pcm []int16) error
+}
+
+// OpusFECDecoder is implemented by Opus decoders that can recover a lost frame from the in-band FEC of the next packet
+type OpusFECDecoder interface {
+	// DecodeFEC fills pcm, sized to the duration of the lost frame, from the FEC carried by payload
+	DecodeFEC(payload []byte, pcm []int16) error
+}
+
+var ErrOpusConcealmentUnsupported = errors.New("opus decoder does not support packet loss concealment")
+
+const (
+	// concealment is synthesized in multiples of 2.5 ms, the shortest Opus frame
+	concealmentGranularity = OpusSampleRate / 400
+	// and in chunks of up to 20 ms
+	concealmentChunk = OpusFrameSize
+)
+
+// ConcealmentResult is the audio synthesized for a loss, in samples per channel
+type ConcealmentResult struct {
+	// synthesized by packet loss concealment
+	PLC int
+	// recovered from the in-band FEC of the packet after the loss
+	FEC int
+}
+
+func (r ConcealmentResult) Samples() int {
+	return r.PLC + r.FEC
+}
+
+// Durations returns how much audio was synthesized by each method
+func (r ConcealmentResult) Durations() (plc time.Duration, fec time.Duration) {
+	return time.Duration(r.PLC) * time.Second / OpusSampleRate, time.Duration(r.FEC) * time.Second / OpusSampleRate
+}
+
+// LossConcealer detects packets lost from an Opus stream by their sequence numbers and synthesizes audio in
+// their place with the decoder of the stream. Gaps of the timestamps without lost packets, e. g. DTX, are left
+// alone. Not safe for concurrent use.
+type LossConcealer struct {
+	maxSamples int
+
+	started bool
+	lastSN  uint16
+	// RTP timestamp the audio of the last packet ends at
+	nextTS uint32
+}
+
+// NewLossConcealer returns a concealer for a stream, nil when concealment is disabled
+func NewLossConcealer(config ConcealmentConfig) *LossConcealer {
+	if !config.Enabled {
+		return nil
+	}
+	if config.MaxDuration <= 0 {
+		config.MaxDuration = DefaultConcealmentConfig.MaxDuration
+	}
+	return &LossConcealer{
+		maxSamples: int(config.MaxDuration * OpusSampleRate / time.Second),
+	}
+}
+
+// Reset forgets the stream, e. g. after packets were not decoded on purpose, the next packet lost nothing
+func (c *LossConcealer) Reset() {
+	if c == nil {
+		return
+	}
+	c.started = false
+}
+
+// Observe takes every packet of the stream in the order it is decoded, before it is decoded, and returns the
+// samples per channel lost ahead of it. Duplicate and reordered packets lost nothing.
+func (c *LossConcealer) Observe(sn uint16, ts uint32, payload []byte) int {
+	if c == nil {
+		return 0
+	}
+
+	var duration uint32
+	if toc, ok := ParseOpusTOC(payload); ok {
+		duration = uint32(toc.Duration() * OpusSampleRate / time.Second)
+	}
+	if !c.started {
+		c.started = true
+		c.lastSN, c.nextTS = sn, ts+duration
+		return 0
+	}
+
+	diff := sn - c.lastSN
+	if diff == 0 || diff >= 1<<15 {
+		return 0
+	}
+
+	lost := 0
+	if gap := int32(ts - c.nextTS); diff > 1 && gap > 0 {
+		lost = int(gap)
+	}
+	c.lastSN, c.nextTS = sn, ts+duration
+	return lost
+}
+
+// Conceal synthesizes up to lost samples per channel into pcm with the decoder, ahead of decoding payload,
+// the packet after the loss. The last lost frame is recovered from the in-band FEC of payload when the decoder
+// supports it. The concealment is capped to the configured duration and to the size of pcm.
+func (c *LossConcealer) Conceal(decoder OpusDecoder, channels int, lost int, payload []byte, pcm []int16) (ConcealmentResult, error) {
+	var result ConcealmentResult
+	if c == nil || lost <= 0 {
+		return result, nil
+	}
+	plc, ok := decoder.(OpusConcealingDecoder)
+	if !ok {
+		return result, ErrOpusConcealmentUnsupported
+	}
+
+	samples := min(lost, c.maxSamples, len(pcm)/channels)
+	samples -= samples % concealmentGranularity
+
+	// the FEC of a packet carries the frame before it, at the frame duration of the packet
+	fecSamples := 0
+	fec, canFEC := decoder.(OpusFECDecoder)
+	if toc, ok := ParseOpusTOC(payload); ok && canFEC && !IsOpusDTX(payload) {
+		if frame := int(toc.FrameDuration * OpusSampleRate / time.Second); frame <= samples {
+			fecSamples = frame
+		}
+	}
+
+	concealPLC := func(from int, to int) error {
+		for offset := from; offset < to; offset += concealmentChunk {
+			end := min(offset+concealmentChunk, to)
+			if err := plc.DecodePLC(pcm[offset*channels : end*channels]); err != nil {
+				return err
+			}
+			result.PLC += end - offset
+		}
+		return nil
+	}
+	if err := concealPLC(0, samples-fecSamples); err != nil {
+		return result, err
+	}
+	if fecSamples == 0 {
+		return result, nil
+	}
+	if err := fec.DecodeFEC(payload, pcm[(samples-fecSamples)*channels:samples*channels]); err != nil {
+		return result, concealPLC(samples-fecSamples, samples)
+	}
+	result.FEC = fecSamples
+	return result, nil
+}
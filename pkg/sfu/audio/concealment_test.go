@@ -0,0 +1,134 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type concealingDecoder struct {
+	plcCalls int
+	fecErr   error
+}
+
+func (d *concealingDecoder) Decode(payload []byte, pcm []int16) (int, error) {
+	return 0, nil
+}
+
+func (d *concealingDecoder) DecodePLC(pcm []int16) error {
+	d.plcCalls++
+	for i := range pcm {
+		pcm[i] = 1
+	}
+	return nil
+}
+
+type fecDecoder struct {
+	concealingDecoder
+}
+
+func (d *fecDecoder) DecodeFEC(payload []byte, pcm []int16) error {
+	if d.fecErr != nil {
+		return d.fecErr
+	}
+	for i := range pcm {
+		pcm[i] = 2
+	}
+	return nil
+}
+
+func TestLossConcealer(t *testing.T) {
+	// SILK wideband 20 ms, one frame
+	payload := []byte{0x48, 0x01, 0x02, 0x03, 0x04}
+	config := ConcealmentConfig{Enabled: true, MaxDuration: 100 * time.Millisecond}
+
+	t.Run("disabled", func(t *testing.T) {
+		c := NewLossConcealer(ConcealmentConfig{})
+		require.Nil(t, c)
+		require.Zero(t, c.Observe(1, 0, payload))
+		result, err := c.Conceal(&concealingDecoder{}, 1, 960, payload, make([]int16, 960))
+		require.NoError(t, err)
+		require.Zero(t, result.Samples())
+	})
+
+	t.Run("detects lost packets", func(t *testing.T) {
+		c := NewLossConcealer(config)
+		require.Zero(t, c.Observe(1, 0, payload))
+		require.Zero(t, c.Observe(2, 960, payload))
+		// two packets lost
+		require.Equal(t, 2*960, c.Observe(5, 4*960, payload))
+		// reordered and duplicate packets
+		require.Zero(t, c.Observe(4, 3*960, payload))
+		require.Zero(t, c.Observe(5, 4*960, payload))
+	})
+
+	t.Run("leaves DTX gaps alone", func(t *testing.T) {
+		c := NewLossConcealer(config)
+		require.Zero(t, c.Observe(1, 0, payload))
+		require.Zero(t, c.Observe(2, 960, []byte{0x48}))
+		require.Zero(t, c.Observe(3, 20*960, payload))
+	})
+
+	t.Run("conceals with PLC", func(t *testing.T) {
+		c := NewLossConcealer(config)
+		d := &concealingDecoder{}
+		pcm := make([]int16, 2*OpusMaxFrameSize)
+		result, err := c.Conceal(d, 2, 2*960, payload, pcm)
+		require.NoError(t, err)
+		require.Equal(t, ConcealmentResult{PLC: 2 * 960}, result)
+		require.Equal(t, 2, d.plcCalls)
+		require.Equal(t, int16(1), pcm[2*2*960-1])
+		require.Zero(t, pcm[2*2*960])
+	})
+
+	t.Run("recovers the last frame from FEC", func(t *testing.T) {
+		c := NewLossConcealer(config)
+		d := &fecDecoder{}
+		pcm := make([]int16, OpusMaxFrameSize)
+		result, err := c.Conceal(d, 1, 3*960, payload, pcm)
+		require.NoError(t, err)
+		require.Equal(t, ConcealmentResult{PLC: 2 * 960, FEC: 960}, result)
+		require.Equal(t, int16(1), pcm[2*960-1])
+		require.Equal(t, int16(2), pcm[2*960])
+
+		d.fecErr = errors.New("corrupt")
+		result, err = c.Conceal(d, 1, 960, payload, pcm)
+		require.NoError(t, err)
+		require.Equal(t, ConcealmentResult{PLC: 960}, result)
+	})
+
+	t.Run("caps long losses", func(t *testing.T) {
+		c := NewLossConcealer(config)
+		result, err := c.Conceal(&concealingDecoder{}, 1, 48000, payload, make([]int16, OpusMaxFrameSize))
+		require.NoError(t, err)
+		require.Equal(t, 4800, result.Samples())
+
+		// multiples of 2.5 ms
+		result, err = c.Conceal(&concealingDecoder{}, 1, 1000, payload, make([]int16, OpusMaxFrameSize))
+		require.NoError(t, err)
+		require.Equal(t, 960, result.Samples())
+	})
+
+	t.Run("decoder without concealment", func(t *testing.T) {
+		c := NewLossConcealer(config)
+		_, err := c.Conceal(struct{ OpusDecoder }{}, 1, 960, payload, make([]int16, 960))
+		require.ErrorIs(t, err, ErrOpusConcealmentUnsupported)
+	})
+}
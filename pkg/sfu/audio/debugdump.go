@@ -0,0 +1,266 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	debugDumpPreSuffix  = "-pre.wav"
+	debugDumpPostSuffix = "-post.wav"
+)
+
+// DebugDumpConfig records the start of every denoised track before and after denoising as WAV files, so that
+// the quality of the filter can be checked without subscribing to the track. Meant for debugging, the files
+// hold the audio of participants.
+type DebugDumpConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// directory the files are written to, created if missing. Defaults to agentix-audio-dumps in the
+	// temporary directory.
+	Directory string `json:"directory" yaml:"directory,omitempty"`
+	// audio recorded per track, a track closed earlier is written with what was recorded
+	Duration time.Duration `json:"duration" yaml:"duration,omitempty"`
+	// total size of the files in the directory, the oldest recordings are removed beyond it
+	MaxSizeMB int `json:"max_size_mb" yaml:"max_size_mb,omitempty"`
+}
+
+var (
+	DefaultDebugDumpConfig = DebugDumpConfig{
+		Duration:  10 * time.Second,
+		MaxSizeMB: 200,
+	}
+)
+
+func (c DebugDumpConfig) directory() string {
+	if c.Directory == "" {
+		return filepath.Join(os.TempDir(), "agentix-audio-dumps")
+	}
+	return c.Directory
+}
+
+// --------------------------------------
+
+// DebugDumper writes the recordings of DebugDumpConfig and keeps the directory below the size cap
+type DebugDumper struct {
+	config DebugDumpConfig
+	// called with errors of writing recordings, which happens in the background
+	onError func(err error)
+
+	// serializes writing and rotation
+	lock sync.Mutex
+}
+
+func NewDebugDumper(config DebugDumpConfig, onError func(err error)) *DebugDumper {
+	if config.Duration <= 0 {
+		config.Duration = DefaultDebugDumpConfig.Duration
+	}
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &DebugDumper{
+		config:  config,
+		onError: onError,
+	}
+}
+
+// NewRecording starts a recording of interleaved PCM at sampleRate, name identifies it in the file names
+func (d *DebugDumper) NewRecording(name string, sampleRate int, channels int) *DebugRecording {
+	return &DebugRecording{
+		dumper:     d,
+		name:       fmt.Sprintf("%s_%s", time.Now().UTC().Format("20060102T150405.000"), sanitizeDumpName(name)),
+		sampleRate: sampleRate,
+		channels:   channels,
+		maxSamples: int(d.config.Duration.Seconds()*float64(sampleRate)) * channels,
+	}
+}
+
+func (d *DebugDumper) save(name string, sampleRate int, channels int, pre []int16, post []int16) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	dir := d.config.directory()
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	if err := writeWAVFile(filepath.Join(dir, name+debugDumpPreSuffix), sampleRate, channels, pre); err != nil {
+		return err
+	}
+	if err := writeWAVFile(filepath.Join(dir, name+debugDumpPostSuffix), sampleRate, channels, post); err != nil {
+		return err
+	}
+	return d.rotateLocked(dir)
+}
+
+// rotateLocked removes the oldest recordings, both of their files, until the directory is below the size cap.
+// Must be called with the lock held.
+func (d *DebugDumper) rotateLocked(dir string) error {
+	if d.config.MaxSizeMB <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	type dumpRecording struct {
+		files []string
+		size  int64
+	}
+	recordings := make(map[string]*dumpRecording)
+	var total int64
+	for _, entry := range entries {
+		name := entry.Name()
+		recording, ok := strings.CutSuffix(name, debugDumpPreSuffix)
+		if !ok {
+			recording, ok = strings.CutSuffix(name, debugDumpPostSuffix)
+		}
+		if entry.IsDir() || !ok {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if recordings[recording] == nil {
+			recordings[recording] = &dumpRecording{}
+		}
+		recordings[recording].files = append(recordings[recording].files, name)
+		recordings[recording].size += info.Size()
+		total += info.Size()
+	}
+
+	// names start with the time the recording started
+	names := make([]string, 0, len(recordings))
+	for name := range recordings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	maxSize := int64(d.config.MaxSizeMB) << 20
+	for _, name := range names {
+		if total <= maxSize {
+			break
+		}
+		for _, file := range recordings[name].files {
+			if err := os.Remove(filepath.Join(dir, file)); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+		total -= recordings[name].size
+	}
+	return nil
+}
+
+func writeWAVFile(path string, sampleRate int, channels int, pcm []int16) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	wav, err := NewWAVWriter(f, sampleRate, channels)
+	if err == nil {
+		err = wav.Write(pcm)
+	}
+	if err == nil {
+		err = wav.Close()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// sanitizeDumpName keeps names of rooms and tracks safe to use in a file name
+func sanitizeDumpName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// --------------------------------------
+
+// DebugRecording collects the PCM of a track before and after denoising in memory, the files are written in
+// the background once the configured duration is recorded or the recording is closed
+type DebugRecording struct {
+	dumper     *DebugDumper
+	name       string
+	sampleRate int
+	channels   int
+	maxSamples int
+
+	lock sync.Mutex
+	pre  []int16
+	post []int16
+	done bool
+}
+
+// Pre records PCM before denoising
+func (r *DebugRecording) Pre(pcm []int16) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.done {
+		r.pre = append(r.pre, pcm[:min(len(pcm), r.maxSamples-len(r.pre))]...)
+	}
+}
+
+// Post records the PCM of Pre after denoising, returns true once the recording is complete
+func (r *DebugRecording) Post(pcm []int16) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.done {
+		return true
+	}
+	r.post = append(r.post, pcm[:min(len(pcm), r.maxSamples-len(r.post))]...)
+	if len(r.post) < r.maxSamples {
+		return false
+	}
+	r.finishLocked()
+	return true
+}
+
+// Close writes what was recorded so far, if anything
+func (r *DebugRecording) Close() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.done && len(r.post) != 0 {
+		r.finishLocked()
+	}
+	r.done = true
+}
+
+func (r *DebugRecording) finishLocked() {
+	r.done = true
+	pre, post := r.pre, r.post
+	r.pre, r.post = nil, nil
+	go func() {
+		if err := r.dumper.save(r.name, r.sampleRate, r.channels, pre, post); err != nil {
+			r.dumper.onError(err)
+		}
+	}()
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDebugRecording(t *testing.T) {
+	dir := t.TempDir()
+	dumper := NewDebugDumper(DebugDumpConfig{Enabled: true, Directory: dir, Duration: 20 * time.Millisecond}, nil)
+	recording := dumper.NewRecording("room/1_TR_abc", OpusSampleRate, 1)
+
+	frame := make([]int16, 480)
+	recording.Pre(frame)
+	require.False(t, recording.Post(frame))
+	recording.Pre(frame)
+	require.True(t, recording.Post(frame))
+	// further audio is not recorded
+	recording.Pre(frame)
+	require.True(t, recording.Post(frame))
+
+	var files []string
+	require.Eventually(t, func() bool {
+		files, _ = filepath.Glob(filepath.Join(dir, "*.wav"))
+		return len(files) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Contains(t, files[0], "room_1_TR_abc-post.wav")
+	require.Contains(t, files[1], "room_1_TR_abc-pre.wav")
+
+	for _, file := range files {
+		info, err := os.Stat(file)
+		require.NoError(t, err)
+		require.EqualValues(t, wavHeaderSize+960*2, info.Size())
+	}
+}
+
+func TestDebugRecordingClose(t *testing.T) {
+	dir := t.TempDir()
+	dumper := NewDebugDumper(DebugDumpConfig{Enabled: true, Directory: dir}, nil)
+
+	// nothing recorded, nothing written
+	dumper.NewRecording("empty", OpusSampleRate, 1).Close()
+
+	recording := dumper.NewRecording("short", OpusSampleRate, 2)
+	recording.Pre(make([]int16, 960))
+	recording.Post(make([]int16, 960))
+	recording.Close()
+
+	require.Eventually(t, func() bool {
+		files, _ := filepath.Glob(filepath.Join(dir, "*short-p*.wav"))
+		return len(files) == 2
+	}, time.Second, 10*time.Millisecond)
+	files, _ := filepath.Glob(filepath.Join(dir, "*empty*"))
+	require.Empty(t, files)
+}
+
+func TestDebugDumperRotation(t *testing.T) {
+	dir := t.TempDir()
+	dumper := NewDebugDumper(DebugDumpConfig{Enabled: true, Directory: dir, MaxSizeMB: 1}, nil)
+
+	half := make([]byte, 300<<10)
+	for _, name := range []string{"1_a-pre.wav", "1_a-post.wav", "2_b-pre.wav", "2_b-post.wav", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), half, 0o600))
+	}
+	require.NoError(t, dumper.rotateLocked(dir))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	// the oldest recording is removed, files not written by the dumper are kept
+	require.ElementsMatch(t, []string{"2_b-post.wav", "2_b-pre.wav", "notes.txt"}, names)
+}
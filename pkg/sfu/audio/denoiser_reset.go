@@ -0,0 +1,119 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+// DenoiserResetConfig controls periodic resets of denoiser state, which can drift over hours long calls.
+// A due reset waits for a pause in speech so that the state is rebuilt while there is nothing to distort.
+type DenoiserResetConfig struct {
+	// how long a denoiser runs before its state is reset, 0 disables resets
+	Interval time.Duration `json:"interval" yaml:"interval,omitempty"`
+	// continuous silence needed before a due reset happens
+	MinSilence time.Duration `json:"min_silence" yaml:"min_silence,omitempty"`
+	// longest a due reset waits for silence before it is forced
+	MaxDelay time.Duration `json:"max_delay" yaml:"max_delay,omitempty"`
+}
+
+var (
+	DefaultDenoiserResetConfig = DenoiserResetConfig{
+		Interval:   30 * time.Minute,
+		MinSilence: 500 * time.Millisecond,
+		MaxDelay:   5 * time.Minute,
+	}
+)
+
+// DenoiserStats describes a denoiser instance over its lifetime
+type DenoiserStats struct {
+	Frames       uint64 `json:"frames"`
+	Resets       int    `json:"resets"`
+	ForcedResets int    `json:"forced_resets"`
+	// memory held on the Go side for the instance, i. e. frame buffers, the native state is fixed size
+	MemoryBytes     int `json:"memory_bytes"`
+	PeakMemoryBytes int `json:"peak_memory_bytes"`
+}
+
+// DenoiserResetScheduler follows the frames of a denoiser and decides when its state is reset.
+// Not safe for concurrent use.
+type DenoiserResetScheduler struct {
+	config        DenoiserResetConfig
+	frameDuration time.Duration
+
+	sinceReset time.Duration
+	silence    time.Duration
+	stats      DenoiserStats
+}
+
+func NewDenoiserResetScheduler(config DenoiserResetConfig, frameDuration time.Duration) *DenoiserResetScheduler {
+	return &DenoiserResetScheduler{
+		config:        config,
+		frameDuration: frameDuration,
+	}
+}
+
+// ObserveFrame accounts one processed frame, voiced as detected by voice activity detection.
+// Returns true when the state should be reset before the next frame.
+func (s *DenoiserResetScheduler) ObserveFrame(voiced bool) bool {
+	s.stats.Frames++
+	return s.observe(s.frameDuration, voiced)
+}
+
+// ObserveSilence accounts a pause in which no frames were processed, e. g. the publisher was in DTX.
+// Returns true when the state should be reset before the next frame, which the pause is the best time for.
+func (s *DenoiserResetScheduler) ObserveSilence(duration time.Duration) bool {
+	return s.observe(duration, false)
+}
+
+func (s *DenoiserResetScheduler) observe(duration time.Duration, voiced bool) bool {
+	if s.config.Interval <= 0 {
+		return false
+	}
+
+	s.sinceReset += duration
+	if voiced {
+		s.silence = 0
+	} else {
+		s.silence += duration
+	}
+	if s.sinceReset < s.config.Interval {
+		return false
+	}
+
+	quiet := s.silence >= s.config.MinSilence
+	if !quiet && s.sinceReset < s.config.Interval+s.config.MaxDelay {
+		return false
+	}
+
+	s.stats.Resets++
+	if !quiet {
+		s.stats.ForcedResets++
+	}
+	s.sinceReset = 0
+	s.silence = 0
+	return true
+}
+
+// ObserveMemory records the memory currently held for the instance
+func (s *DenoiserResetScheduler) ObserveMemory(bytes int) {
+	s.stats.MemoryBytes = bytes
+	s.stats.PeakMemoryBytes = max(s.stats.PeakMemoryBytes, bytes)
+}
+
+func (s *DenoiserResetScheduler) Stats() DenoiserStats {
+	return s.stats
+}
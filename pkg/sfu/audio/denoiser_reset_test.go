@@ -0,0 +1,118 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDenoiserFrame = 10 * time.Millisecond
+	testCallDuration  = 8 * time.Hour
+)
+
+// runSyntheticCall feeds the frames of an 8 hour call, voiced reports whether a frame at a time carries speech.
+// Returns the call times at which the denoiser was reset, i. e. the end of the frame that triggered it.
+func runSyntheticCall(s *DenoiserResetScheduler, voiced func(at time.Duration) bool) []time.Duration {
+	var resets []time.Duration
+	for at := time.Duration(0); at < testCallDuration; at += testDenoiserFrame {
+		// pending frame buffer, up to one frame waiting for the rest of its samples
+		s.ObserveMemory(int(at/testDenoiserFrame%2) * 960)
+		if s.ObserveFrame(voiced(at)) {
+			resets = append(resets, at+testDenoiserFrame)
+		}
+	}
+	return resets
+}
+
+func TestDenoiserResetScheduler(t *testing.T) {
+	config := DefaultDenoiserResetConfig
+
+	t.Run("resets in pauses of a conversation", func(t *testing.T) {
+		s := NewDenoiserResetScheduler(config, testDenoiserFrame)
+		// 7 s of speech, 1 s pause
+		conversation := func(at time.Duration) bool { return at%(8*time.Second) < 7*time.Second }
+		resets := runSyntheticCall(s, conversation)
+
+		require.Len(t, resets, int(testCallDuration/config.Interval))
+		last := time.Duration(0)
+		for _, at := range resets {
+			// the pause has lasted long enough
+			require.False(t, conversation(at-testDenoiserFrame))
+			require.False(t, conversation(at-config.MinSilence))
+			require.GreaterOrEqual(t, at-last, config.Interval)
+			require.Less(t, at-last, config.Interval+8*time.Second)
+			last = at
+		}
+
+		stats := s.Stats()
+		require.Equal(t, uint64(testCallDuration/testDenoiserFrame), stats.Frames)
+		require.Equal(t, len(resets), stats.Resets)
+		require.Zero(t, stats.ForcedResets)
+		require.Equal(t, 960, stats.PeakMemoryBytes)
+	})
+
+	t.Run("forces reset without pauses", func(t *testing.T) {
+		s := NewDenoiserResetScheduler(config, testDenoiserFrame)
+		resets := runSyntheticCall(s, func(time.Duration) bool { return true })
+
+		require.Len(t, resets, int(testCallDuration/(config.Interval+config.MaxDelay)))
+		require.Equal(t, len(resets), s.Stats().ForcedResets)
+	})
+
+	t.Run("pauses too short to reset", func(t *testing.T) {
+		s := NewDenoiserResetScheduler(config, testDenoiserFrame)
+		// 200 ms gaps between words only
+		resets := runSyntheticCall(s, func(at time.Duration) bool { return at%(2*time.Second) < 1800*time.Millisecond })
+
+		require.NotEmpty(t, resets)
+		require.Equal(t, len(resets), s.Stats().ForcedResets)
+	})
+
+	t.Run("silent call", func(t *testing.T) {
+		s := NewDenoiserResetScheduler(config, testDenoiserFrame)
+		resets := runSyntheticCall(s, func(time.Duration) bool { return false })
+
+		require.Len(t, resets, int(testCallDuration/config.Interval))
+		require.Zero(t, s.Stats().ForcedResets)
+	})
+
+	t.Run("resets in DTX pauses", func(t *testing.T) {
+		s := NewDenoiserResetScheduler(config, testDenoiserFrame)
+		var resets int
+		// 7 s of speech, then the publisher stops sending for 1 s
+		for at := time.Duration(0); at < testCallDuration; at += 8 * time.Second {
+			for frame := time.Duration(0); frame < 7*time.Second; frame += testDenoiserFrame {
+				require.False(t, s.ObserveFrame(true))
+			}
+			if s.ObserveSilence(time.Second) {
+				resets++
+			}
+		}
+
+		require.Equal(t, int(testCallDuration/config.Interval), resets)
+		require.Zero(t, s.Stats().ForcedResets)
+		require.Equal(t, uint64(testCallDuration/(8*time.Second)*(7*time.Second/testDenoiserFrame)), s.Stats().Frames)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		s := NewDenoiserResetScheduler(DenoiserResetConfig{}, testDenoiserFrame)
+		require.Empty(t, runSyntheticCall(s, func(time.Duration) bool { return false }))
+	})
+}
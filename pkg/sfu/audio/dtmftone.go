@@ -0,0 +1,245 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// tones are detected in blocks of 25 ms, long enough to tell the rows of the keypad apart
+	dtmfToneBlockDuration = 25 * time.Millisecond
+	// mean square of a block below -36 dBFS carries no tone
+	dtmfToneMinLevel = 2.5e-4
+	// share of the energy of a block the two tones of a digit have to carry, speech and music spread theirs
+	dtmfToneMinPurity = 0.7
+	// the tone of a group has to be 8 dB stronger than the other tones of the group, and the two tones
+	// of a digit within 8 dB of each other (twist)
+	dtmfToneMinGroupRatio = 6.3
+	dtmfToneMaxTwist      = 6.3
+	// blocks without the tone that end a digit, shorter dropouts are bridged
+	dtmfToneEndBlocks = 2
+)
+
+var (
+	dtmfRowFrequencies    = [4]float64{697, 770, 852, 941}
+	dtmfColumnFrequencies = [4]float64{1209, 1336, 1477, 1633}
+	// RFC 4733 event codes by row and column of the keypad
+	dtmfToneEvents = [4][4]uint8{
+		{1, 2, 3, 12},
+		{4, 5, 6, 13},
+		{7, 8, 9, 14},
+		{10, 0, 11, 15},
+	}
+)
+
+// DTMFToneConfig controls the detection of DTMF digits sent as tones in the audio itself,
+// for gateways and phones that do not send RFC 4733 telephone events
+type DTMFToneConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// shortest tone taken as a digit
+	MinDuration time.Duration `yaml:"min_duration,omitempty"`
+}
+
+var (
+	DefaultDTMFToneConfig = DTMFToneConfig{
+		MinDuration: 40 * time.Millisecond,
+	}
+)
+
+// DTMFToneDetector finds DTMF digits in mono PCM with the Goertzel algorithm and reports them the way
+// TelephoneEventTracker reports telephone events, once when a digit starts and once when it ends.
+// Not safe for concurrent use.
+type DTMFToneDetector struct {
+	minBlocks int
+	rows      [4]float64
+	columns   [4]float64
+
+	block []float64
+	fill  int
+
+	// digit whose tone is held, event -1 while none is
+	event  int
+	volume uint8
+	blocks int
+	missed int
+	// digit detected in the last blocks, not yet held for the minimum duration
+	candidate       int
+	candidateBlocks int
+}
+
+// NewDTMFToneDetector creates a detector for PCM at sampleRate, settings not configured taken from the defaults
+func NewDTMFToneDetector(config DTMFToneConfig, sampleRate int) *DTMFToneDetector {
+	if config.MinDuration <= 0 {
+		config.MinDuration = DefaultDTMFToneConfig.MinDuration
+	}
+
+	d := &DTMFToneDetector{
+		minBlocks: max(int((config.MinDuration+dtmfToneBlockDuration-1)/dtmfToneBlockDuration), 1),
+		block:     make([]float64, sampleRate*int(dtmfToneBlockDuration/time.Millisecond)/1000),
+		event:     -1,
+		candidate: -1,
+	}
+	for i := range d.rows {
+		d.rows[i] = 2 * math.Cos(2*math.Pi*dtmfRowFrequencies[i]/float64(sampleRate))
+		d.columns[i] = 2 * math.Cos(2*math.Pi*dtmfColumnFrequencies[i]/float64(sampleRate))
+	}
+	return d
+}
+
+// Push analyzes the samples of a packet and returns the resulting phase changes
+func (d *DTMFToneDetector) Push(pcm []int16) []TelephoneEventUpdate {
+	var updates []TelephoneEventUpdate
+	for _, sample := range pcm {
+		d.block[d.fill] = float64(sample) / 32768
+		if d.fill++; d.fill < len(d.block) {
+			continue
+		}
+		d.fill = 0
+
+		event, volume := d.detect()
+		updates = d.observe(event, volume, updates)
+	}
+	return updates
+}
+
+// Flush ends the digit being held, e. g. when the stream goes away
+func (d *DTMFToneDetector) Flush() *TelephoneEventUpdate {
+	d.fill = 0
+	d.candidate, d.candidateBlocks = -1, 0
+	if d.event < 0 {
+		return nil
+	}
+
+	update := d.end()
+	return &update
+}
+
+func (d *DTMFToneDetector) IsActive() bool {
+	return d.event >= 0
+}
+
+func (d *DTMFToneDetector) observe(event int, volume uint8, updates []TelephoneEventUpdate) []TelephoneEventUpdate {
+	if d.event >= 0 {
+		switch {
+		case event == d.event:
+			// blocks the tone dropped out in count towards the digit
+			d.blocks += d.missed + 1
+			d.missed = 0
+			return updates
+
+		case event < 0:
+			if d.missed++; d.missed < dtmfToneEndBlocks {
+				return updates
+			}
+			return append(updates, d.end())
+
+		default:
+			// another digit without a pause in between
+			updates = append(updates, d.end())
+		}
+	}
+
+	if event < 0 || event != d.candidate {
+		d.candidate, d.candidateBlocks = event, 0
+	}
+	if event < 0 {
+		return updates
+	}
+	if d.candidateBlocks++; d.candidateBlocks < d.minBlocks {
+		return updates
+	}
+
+	d.event, d.volume, d.blocks, d.missed = event, volume, d.candidateBlocks, 0
+	d.candidate, d.candidateBlocks = -1, 0
+	return append(updates, d.update(TelephoneEventPhaseStart))
+}
+
+func (d *DTMFToneDetector) end() TelephoneEventUpdate {
+	update := d.update(TelephoneEventPhaseEnd)
+	update.Duration = time.Duration(d.blocks) * dtmfToneBlockDuration
+	d.event, d.blocks, d.missed = -1, 0, 0
+	return update
+}
+
+func (d *DTMFToneDetector) update(phase TelephoneEventPhase) TelephoneEventUpdate {
+	return TelephoneEventUpdate{
+		Event:  uint8(d.event),
+		Digit:  DTMFDigit(uint8(d.event)),
+		Phase:  phase,
+		Volume: d.volume,
+	}
+}
+
+// detect returns the event code of the digit in the block, -1 if there is none, and the level of its tones
+// below full scale in dB, the volume of a telephone event
+func (d *DTMFToneDetector) detect() (int, uint8) {
+	var energy float64
+	for _, x := range d.block {
+		energy += x * x
+	}
+	n := float64(len(d.block))
+	if energy/n < dtmfToneMinLevel {
+		return -1, 0
+	}
+
+	row, rowPower, rowRatio := d.strongest(&d.rows)
+	column, columnPower, columnRatio := d.strongest(&d.columns)
+	if rowRatio < dtmfToneMinGroupRatio || columnRatio < dtmfToneMinGroupRatio {
+		return -1, 0
+	}
+	if rowPower > dtmfToneMaxTwist*columnPower || columnPower > dtmfToneMaxTwist*rowPower {
+		return -1, 0
+	}
+
+	// a sinusoid of amplitude a has a power of (a n / 2)^2 and an energy of a^2 n / 2
+	if 2*(rowPower+columnPower)/(n*energy) < dtmfToneMinPurity {
+		return -1, 0
+	}
+
+	level := 2 * (rowPower + columnPower) / (n * n)
+	volume := uint8(min(max(-10*math.Log10(level), 0), 63))
+	return int(dtmfToneEvents[row][column]), volume
+}
+
+// strongest returns the index and power of the strongest of the tones of a group and how much stronger
+// it is than the second strongest
+func (d *DTMFToneDetector) strongest(coeffs *[4]float64) (int, float64, float64) {
+	best, bestPower, secondPower := 0, 0.0, 0.0
+	for i, coeff := range coeffs {
+		power := goertzel(d.block, coeff)
+		switch {
+		case power > bestPower:
+			best, bestPower, secondPower = i, power, bestPower
+		case power > secondPower:
+			secondPower = power
+		}
+	}
+	if secondPower == 0 {
+		return best, bestPower, math.Inf(1)
+	}
+	return best, bestPower, bestPower / secondPower
+}
+
+// goertzel returns the power of the block at the frequency of coeff, 2 cos(2 pi f / sampleRate)
+func goertzel(block []float64, coeff float64) float64 {
+	var s1, s2 float64
+	for _, x := range block {
+		s1, s2 = x+coeff*s1-s2, s1
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
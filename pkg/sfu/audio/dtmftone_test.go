@@ -0,0 +1,120 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// dtmfTone appends duration of the tones of a digit at 48 kHz, the digit at -10 dBFS and each tone 3 dB below
+func dtmfTone(pcm []int16, row, column int, duration time.Duration) []int16 {
+	n := int(duration * OpusSampleRate / time.Second)
+	amplitude := 32768 * math.Pow(10, -10.0/20)
+	for i := 0; i < n; i++ {
+		t := float64(len(pcm)) / OpusSampleRate
+		s := math.Sin(2*math.Pi*dtmfRowFrequencies[row]*t) + math.Sin(2*math.Pi*dtmfColumnFrequencies[column]*t)
+		pcm = append(pcm, int16(amplitude*s))
+	}
+	return pcm
+}
+
+func dtmfPause(pcm []int16, duration time.Duration) []int16 {
+	return append(pcm, make([]int16, int(duration*OpusSampleRate/time.Second))...)
+}
+
+// pushPackets runs pcm through the detector in 20 ms packets
+func pushPackets(d *DTMFToneDetector, pcm []int16) []TelephoneEventUpdate {
+	var updates []TelephoneEventUpdate
+	for len(pcm) > 0 {
+		n := min(len(pcm), OpusSampleRate/50)
+		updates = append(updates, d.Push(pcm[:n])...)
+		pcm = pcm[n:]
+	}
+	return updates
+}
+
+func TestDTMFToneDetector(t *testing.T) {
+	t.Run("digits start and end once", func(t *testing.T) {
+		d := NewDTMFToneDetector(DTMFToneConfig{Enabled: true}, OpusSampleRate)
+
+		var pcm []int16
+		pcm = dtmfPause(pcm, 30*time.Millisecond)
+		pcm = dtmfTone(pcm, 0, 0, 100*time.Millisecond)
+		pcm = dtmfPause(pcm, 80*time.Millisecond)
+		pcm = dtmfTone(pcm, 3, 2, 200*time.Millisecond)
+		pcm = dtmfPause(pcm, 80*time.Millisecond)
+		pcm = dtmfTone(pcm, 3, 3, 60*time.Millisecond)
+		pcm = dtmfPause(pcm, 80*time.Millisecond)
+
+		updates := pushPackets(d, pcm)
+		require.Len(t, updates, 6)
+		for i, digit := range []string{"1", "#", "D"} {
+			start, end := updates[2*i], updates[2*i+1]
+			require.Equal(t, digit, start.Digit)
+			require.Equal(t, TelephoneEventPhaseStart, start.Phase)
+			require.Equal(t, digit, end.Digit)
+			require.Equal(t, TelephoneEventPhaseEnd, end.Phase)
+			require.False(t, end.EndLost)
+			require.InDelta(t, 10, int(start.Volume), 2)
+		}
+		require.Equal(t, uint8(11), updates[2].Event)
+		require.InDelta(t, 200*time.Millisecond, updates[3].Duration, float64(2*dtmfToneBlockDuration))
+		require.False(t, d.IsActive())
+	})
+
+	t.Run("short tones are ignored", func(t *testing.T) {
+		d := NewDTMFToneDetector(DTMFToneConfig{Enabled: true}, OpusSampleRate)
+
+		pcm := dtmfTone(nil, 1, 1, 30*time.Millisecond)
+		pcm = dtmfPause(pcm, 100*time.Millisecond)
+		require.Empty(t, pushPackets(d, pcm))
+	})
+
+	t.Run("held digit is flushed", func(t *testing.T) {
+		d := NewDTMFToneDetector(DTMFToneConfig{Enabled: true}, OpusSampleRate)
+
+		updates := pushPackets(d, dtmfTone(nil, 2, 1, 100*time.Millisecond))
+		require.Len(t, updates, 1)
+		require.Equal(t, "8", updates[0].Digit)
+		require.True(t, d.IsActive())
+
+		update := d.Flush()
+		require.NotNil(t, update)
+		require.Equal(t, TelephoneEventPhaseEnd, update.Phase)
+		require.Nil(t, d.Flush())
+	})
+
+	t.Run("speech and noise are not digits", func(t *testing.T) {
+		d := NewDTMFToneDetector(DTMFToneConfig{Enabled: true}, OpusSampleRate)
+		rng := rand.New(rand.NewSource(1))
+
+		var pcm []int16
+		for _, frame := range speechSignal(500, rng) {
+			for _, s := range frame {
+				pcm = append(pcm, int16(s*32767))
+			}
+		}
+		for i := 0; i < OpusSampleRate; i++ {
+			pcm = append(pcm, int16(rng.NormFloat64()*3000))
+		}
+		require.Empty(t, pushPackets(d, pcm))
+	})
+}
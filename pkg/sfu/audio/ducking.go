@@ -0,0 +1,125 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// DuckingConfig controls attenuating the audio of the other participants while an agent speaks,
+// so that listeners understand the agent over background voices and noise
+type DuckingConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// attenuation in dB of the other tracks while the agent speaks
+	Attenuation float64 `yaml:"attenuation,omitempty"`
+	// ramp down to the attenuation once the agent starts speaking
+	Attack time.Duration `yaml:"attack,omitempty"`
+	// attenuation kept after the agent stopped, bridges the pauses between words
+	Hold time.Duration `yaml:"hold,omitempty"`
+	// ramp back up after the hold
+	Release time.Duration `yaml:"release,omitempty"`
+	// identities of the agents others are ducked for, all agents when empty
+	Agents []string `yaml:"agents,omitempty"`
+}
+
+var (
+	DefaultDuckingConfig = DuckingConfig{
+		Attenuation: 10,
+		Attack:      100 * time.Millisecond,
+		Hold:        300 * time.Millisecond,
+		Release:     500 * time.Millisecond,
+	}
+)
+
+const (
+	// the gain moves in steps of this many dB, finer steps are not audible
+	duckingStepDB = 0.5
+)
+
+// Ducker follows whether an agent speaks and ramps the gain of the other tracks between 0 dB and the
+// attenuation. Not safe for concurrent use.
+type Ducker struct {
+	config DuckingConfig
+
+	gainDB     float64
+	lastSpeech time.Time
+	last       time.Time
+}
+
+func NewDucker(config DuckingConfig) *Ducker {
+	if config.Attenuation <= 0 {
+		config.Attenuation = DefaultDuckingConfig.Attenuation
+	}
+	config.Attenuation = min(config.Attenuation, -MinTrackGainDB)
+	return &Ducker{
+		config: config,
+	}
+}
+
+// Observe advances the ramp to now, speaking telling whether the agent speaks, and returns the gain
+// in dB of the other tracks, 0 when they are not ducked
+func (d *Ducker) Observe(now time.Time, speaking bool) float64 {
+	elapsed := time.Duration(0)
+	if !d.last.IsZero() {
+		elapsed = now.Sub(d.last)
+	}
+	d.last = now
+	if speaking {
+		d.lastSpeech = now
+	}
+
+	target, ramp := 0.0, d.config.Release
+	if !d.lastSpeech.IsZero() {
+		if sinceHold := now.Sub(d.lastSpeech.Add(d.config.Hold)); speaking || sinceHold < 0 {
+			target, ramp = -d.config.Attenuation, d.config.Attack
+		} else {
+			// the release starts once the hold is over
+			elapsed = min(elapsed, sinceHold)
+		}
+	}
+
+	switch {
+	case ramp <= 0:
+		d.gainDB = target
+	case d.gainDB > target:
+		d.gainDB = max(d.gainDB-d.config.Attenuation*elapsed.Seconds()/ramp.Seconds(), target)
+	case d.gainDB < target:
+		d.gainDB = min(d.gainDB+d.config.Attenuation*elapsed.Seconds()/ramp.Seconds(), target)
+	}
+	return d.Gain()
+}
+
+// Gain returns the gain in dB of the other tracks, rounded to the steps it moves in
+func (d *Ducker) Gain() float64 {
+	if d.gainDB == 0 || d.gainDB == -d.config.Attenuation {
+		return d.gainDB
+	}
+	return math.Round(d.gainDB/duckingStepDB) * duckingStepDB
+}
+
+// IsAgent returns true if others are ducked while the agent with identity speaks
+func (c DuckingConfig) IsAgent(identity string) bool {
+	if len(c.Agents) == 0 {
+		return true
+	}
+	for _, agent := range c.Agents {
+		if agent == identity {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDucker(t *testing.T) {
+	d := NewDucker(DuckingConfig{
+		Attenuation: 10,
+		Attack:      100 * time.Millisecond,
+		Hold:        200 * time.Millisecond,
+		Release:     400 * time.Millisecond,
+	})
+	now := time.Now()
+	require.Zero(t, d.Observe(now, false))
+
+	// ramps down over the attack
+	now = now.Add(20 * time.Millisecond)
+	require.Equal(t, -2.0, d.Observe(now, true))
+	now = now.Add(100 * time.Millisecond)
+	require.Equal(t, -10.0, d.Observe(now, true))
+
+	// held through a pause
+	now = now.Add(150 * time.Millisecond)
+	require.Equal(t, -10.0, d.Observe(now, false))
+
+	// ramps up over the release once the hold is over
+	now = now.Add(150 * time.Millisecond)
+	require.Equal(t, -7.5, d.Observe(now, false))
+	now = now.Add(200 * time.Millisecond)
+	require.Equal(t, -2.5, d.Observe(now, false))
+	now = now.Add(200 * time.Millisecond)
+	require.Zero(t, d.Observe(now, false))
+}
+
+func TestDuckingConfigIsAgent(t *testing.T) {
+	require.True(t, DuckingConfig{}.IsAgent("agent"))
+	require.True(t, DuckingConfig{Agents: []string{"tts"}}.IsAgent("tts"))
+	require.False(t, DuckingConfig{Agents: []string{"tts"}}.IsAgent("agent"))
+}
@@ -0,0 +1,501 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// echo cancellation works on 10 ms blocks at 48 kHz, the frame size of the noise filter
+	echoBlockSize     = 480
+	echoBlockDuration = 10 * time.Millisecond
+	// blocks of energy envelope the delay is estimated over
+	echoDelayHistory = 150
+	// blocks between delay estimates
+	echoDelayInterval = 10
+	// correlation of the envelopes an estimate needs, the higher one accepts it without confirmation
+	echoDelayMinCorrelation       = 0.5
+	echoDelayConfidentCorrelation = 0.8
+	// blocks of reference ahead of the estimated delay covered by the filter, absorbs jitter and estimation error
+	echoDelayMargin = 2
+	// a stream whose timeline position drifts further than this from the wall clock is placed again
+	echoReanchorThreshold = 6 * echoBlockSize
+	// step size of the adaptive filter
+	echoStepSize = 0.5
+	// blocks below this energy, -60 dBFS, neither drive adaptation nor count as reference activity
+	echoSilentBlockEnergy = echoBlockSize * 1e-6
+)
+
+// EchoCancellationConfig controls the cancellation of the audio agents play from the microphone streams
+// of the participants hearing them
+type EchoCancellationConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// length of the echo path covered after the bulk delay, room reverberation and device processing
+	Tail time.Duration `json:"tail" yaml:"tail,omitempty"`
+	// longest delay between the reference leaving the server and its echo coming back
+	MaxDelay time.Duration `json:"max_delay" yaml:"max_delay,omitempty"`
+	// highest attenuation of the echo left after cancellation in dB, 0 leaves it
+	Suppression float64 `json:"suppression" yaml:"suppression,omitempty"`
+}
+
+var (
+	DefaultEchoCancellationConfig = EchoCancellationConfig{
+		Tail:        120 * time.Millisecond,
+		MaxDelay:    time.Second,
+		Suppression: 12,
+	}
+)
+
+func (c EchoCancellationConfig) withDefaults() EchoCancellationConfig {
+	if c.Tail <= 0 {
+		c.Tail = DefaultEchoCancellationConfig.Tail
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultEchoCancellationConfig.MaxDelay
+	}
+	return c
+}
+
+func (c EchoCancellationConfig) delayBlocks() int {
+	return int(c.MaxDelay / echoBlockDuration)
+}
+
+func (c EchoCancellationConfig) partitions() int {
+	return int((c.Tail+echoBlockDuration-1)/echoBlockDuration) + echoDelayMargin
+}
+
+// --------------------------------------
+
+// EchoReference is the far end of echo cancellation, the audio agents play into a room. Samples are laid out
+// on a timeline following the wall clock at 48 kHz, so that echo cancellers find what was playing when their
+// microphone picked it up. Audio of several sources is summed. Safe for concurrent use.
+type EchoReference struct {
+	lock  sync.RWMutex
+	epoch time.Time
+	// timeline position p is held at p % len(samples), the energy of block b at b % len(energies)
+	samples  []float32
+	energies []float32
+	// the samples cover the positions [end-len(samples), end)
+	end int64
+	// position of the next sample of each source
+	sources map[string]int64
+}
+
+func NewEchoReference(config EchoCancellationConfig) *EchoReference {
+	config = config.withDefaults()
+	blocks := echoDelayHistory + config.delayBlocks() + config.partitions() + 2
+	return &EchoReference{
+		epoch:    time.Now(),
+		samples:  make([]float32, blocks*echoBlockSize),
+		energies: make([]float32, blocks),
+		sources:  make(map[string]int64),
+	}
+}
+
+func (e *EchoReference) position(now time.Time) int64 {
+	return int64(now.Sub(e.epoch).Seconds() * OpusSampleRate)
+}
+
+// Write adds mono 48 kHz audio of source received at now. Audio of a source continues where its previous
+// audio ended, unless that drifted too far from now, e. g. after a pause in transmission.
+func (e *EchoReference) Write(source string, pcm []int16, now time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	expected := e.position(now)
+	pos, ok := e.sources[source]
+	if !ok || abs64(pos-expected) > echoReanchorThreshold {
+		pos = expected
+	}
+	end := pos + int64(len(pcm))
+	e.sources[source] = end
+
+	size := int64(len(e.samples))
+	if end <= e.end-size {
+		return
+	}
+	from := pos
+	if end > e.end {
+		// newly covered positions start out silent
+		for p := max(e.end, end-size); p < end; p++ {
+			e.samples[p%size] = 0
+		}
+		from = min(from, max(e.end, end-size))
+		e.end = end
+	}
+	for i, sample := range pcm {
+		if p := pos + int64(i); p >= e.end-size && p >= 0 {
+			e.samples[p%size] += float32(sample) / 32768
+		}
+	}
+
+	for b := max(from, 0) / echoBlockSize; b*echoBlockSize < end; b++ {
+		var energy float32
+		for p := b * echoBlockSize; p < (b+1)*echoBlockSize; p++ {
+			if p >= e.end-size && p < e.end {
+				s := e.samples[p%size]
+				energy += s * s
+			}
+		}
+		e.energies[b%int64(len(e.energies))] = energy
+	}
+}
+
+// RemoveSource forgets where the audio of source ended, its next audio is placed at the time it is received
+func (e *EchoReference) RemoveSource(source string) {
+	e.lock.Lock()
+	delete(e.sources, source)
+	e.lock.Unlock()
+}
+
+// read copies the samples from position pos on into out, silence where the timeline holds none
+func (e *EchoReference) read(pos int64, out []float32) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	size := int64(len(e.samples))
+	for i := range out {
+		if p := pos + int64(i); p >= 0 && p < e.end && p >= e.end-size {
+			out[i] = e.samples[p%size]
+		} else {
+			out[i] = 0
+		}
+	}
+}
+
+// readEnergies copies the energies of the blocks from block on into out, 0 for blocks not held
+func (e *EchoReference) readEnergies(block int64, out []float32) {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+
+	size := int64(len(e.samples))
+	for i := range out {
+		b := block + int64(i)
+		if b >= 0 && (b+1)*echoBlockSize <= e.end && b*echoBlockSize >= e.end-size {
+			out[i] = e.energies[b%int64(len(e.energies))]
+		} else {
+			out[i] = 0
+		}
+	}
+}
+
+// --------------------------------------
+
+// EchoCanceller removes the echo of an EchoReference from a microphone stream. The bulk delay of the echo,
+// network round trip and device buffering, is found by correlating the energy envelopes of both. The echo
+// path after it is modelled by a partitioned block frequency domain adaptive filter, echo left after
+// cancellation is attenuated while the estimated echo dominates the microphone. Not safe for concurrent use.
+type EchoCanceller struct {
+	config    EchoCancellationConfig
+	reference *EchoReference
+	minGain   float32
+
+	// timeline position of the next frame
+	pos      int64
+	anchored bool
+
+	// log energies of the latest frames by block, nearCount of them are valid
+	nearEnergies  []float64
+	nearCount     int
+	farEnergies   []float32
+	sinceEstimate int
+	candidate     int
+	// bulk delay in samples, negative until estimated
+	delay int64
+
+	fft        *fft
+	far        []float32
+	farSpectra [][]complex64
+	weights    [][]complex64
+	power      []float32
+	constrain  int
+	buf        []complex64
+	spectrum   []complex64
+	echo       []float32
+	// smoothed energies of the microphone and of what is left after cancellation
+	nearPower  float32
+	errorPower float32
+	gain       float32
+}
+
+func NewEchoCanceller(config EchoCancellationConfig, reference *EchoReference) *EchoCanceller {
+	config = config.withDefaults()
+	partitions := config.partitions()
+	c := &EchoCanceller{
+		config:       config,
+		reference:    reference,
+		minGain:      float32(math.Pow(10, -max(config.Suppression, 0)/20)),
+		nearEnergies: make([]float64, echoDelayHistory),
+		farEnergies:  make([]float32, echoDelayHistory+config.delayBlocks()),
+		candidate:    -1,
+		delay:        -1,
+		fft:          newFFT(2 * echoBlockSize),
+		far:          make([]float32, 2*echoBlockSize),
+		farSpectra:   make([][]complex64, partitions),
+		weights:      make([][]complex64, partitions),
+		power:        make([]float32, 2*echoBlockSize),
+		buf:          make([]complex64, 2*echoBlockSize),
+		spectrum:     make([]complex64, 2*echoBlockSize),
+		echo:         make([]float32, echoBlockSize),
+		gain:         1,
+	}
+	for p := range partitions {
+		c.farSpectra[p] = make([]complex64, 2*echoBlockSize)
+		c.weights[p] = make([]complex64, 2*echoBlockSize)
+	}
+	return c
+}
+
+// Delay returns the estimated bulk delay of the echo, false until it is known
+func (c *EchoCanceller) Delay() (time.Duration, bool) {
+	if c.delay < 0 {
+		return 0, false
+	}
+	return time.Duration(c.delay) * time.Second / OpusSampleRate, true
+}
+
+// Process cancels the echo from a 10 ms frame of normalized mono samples received at now, in place.
+// Frames of other sizes are left as they are.
+func (c *EchoCanceller) Process(frame []float32, now time.Time) {
+	if len(frame) != echoBlockSize {
+		return
+	}
+
+	if expected := c.reference.position(now); !c.anchored || abs64(c.pos-expected) > echoReanchorThreshold {
+		// the stream paused or drifted, the filter keeps its model of the echo path
+		c.pos = expected
+		c.anchored = true
+		c.nearCount = 0
+		c.resetHistory()
+	}
+
+	c.observeNear(frame)
+	if c.delay >= 0 {
+		c.cancel(frame)
+	}
+	c.pos += echoBlockSize
+}
+
+func (c *EchoCanceller) observeNear(frame []float32) {
+	var energy float32
+	for _, s := range frame {
+		energy += s * s
+	}
+	block := c.pos / echoBlockSize
+	c.nearEnergies[block%echoDelayHistory] = math.Log10(float64(energy) + 1e-10)
+	c.nearCount = min(c.nearCount+1, echoDelayHistory)
+
+	c.sinceEstimate++
+	if c.nearCount == echoDelayHistory && c.sinceEstimate >= echoDelayInterval {
+		c.sinceEstimate = 0
+		c.estimateDelay(block)
+	}
+}
+
+// estimateDelay correlates the energy envelope of the latest frames, up to block, with that of the reference
+// at every delay up to the maximum. Needs the reference to be active for part of the history at that delay.
+func (c *EchoCanceller) estimateDelay(block int64) {
+	lags := c.config.delayBlocks()
+	first := block - echoDelayHistory + 1
+	c.reference.readEnergies(first-int64(lags), c.farEnergies)
+
+	far := make([]float64, len(c.farEnergies))
+	for i, energy := range c.farEnergies {
+		far[i] = math.Log10(float64(energy) + 1e-10)
+	}
+
+	near := make([]float64, echoDelayHistory)
+	for h := range near {
+		near[h] = c.nearEnergies[(first+int64(h))%echoDelayHistory]
+	}
+
+	bestLag, bestCorrelation := -1, 0.0
+	for lag := 0; lag <= lags; lag++ {
+		// the reference block lag blocks before each frame
+		if correlation := pearson(near, far[lags-lag:lags-lag+echoDelayHistory]); correlation > bestCorrelation {
+			bestLag, bestCorrelation = lag, correlation
+		}
+	}
+	if bestCorrelation < echoDelayMinCorrelation {
+		return
+	}
+	active := 0
+	for _, energy := range c.farEnergies[lags-bestLag : lags-bestLag+echoDelayHistory] {
+		if energy > echoSilentBlockEnergy {
+			active++
+		}
+	}
+	if active < echoDelayHistory/10 {
+		return
+	}
+	if bestLag == c.candidate || bestCorrelation >= echoDelayConfidentCorrelation {
+		if delay := int64(max(bestLag-echoDelayMargin, 0)) * echoBlockSize; delay != c.delay {
+			c.delay = delay
+			c.resetFilter()
+		}
+	}
+	c.candidate = bestLag
+}
+
+// cancel subtracts the echo estimated by the adaptive filter from frame and adapts the filter to the error
+func (c *EchoCanceller) cancel(frame []float32) {
+	n := echoBlockSize
+	partitions := len(c.weights)
+
+	// overlap-save, the previous and the current block of the reference
+	copy(c.far[:n], c.far[n:])
+	c.reference.read(c.pos-c.delay, c.far[n:])
+	var farEnergy float32
+	for i, s := range c.far {
+		c.buf[i] = complex(s, 0)
+		if i >= n {
+			farEnergy += s * s
+		}
+	}
+	newest := c.farSpectra[partitions-1]
+	copy(c.farSpectra[1:], c.farSpectra[:partitions-1])
+	c.farSpectra[0] = newest
+	c.fft.transform(c.buf, c.farSpectra[0])
+
+	// estimated echo
+	for k := range c.spectrum {
+		var sum complex64
+		for p := range partitions {
+			sum += c.weights[p][k] * c.farSpectra[p][k]
+		}
+		c.spectrum[k] = sum
+	}
+	c.fft.inverse(c.spectrum, c.buf)
+	var nearEnergy, echoEnergy, errorEnergy float32
+	for i := range n {
+		c.echo[i] = real(c.buf[n+i])
+		nearEnergy += frame[i] * frame[i]
+		echoEnergy += c.echo[i] * c.echo[i]
+		errorEnergy += (frame[i] - c.echo[i]) * (frame[i] - c.echo[i])
+	}
+	c.nearPower = 0.95*c.nearPower + 0.05*nearEnergy
+	c.errorPower = 0.95*c.errorPower + 0.05*errorEnergy
+	if c.errorPower > 2*c.nearPower+echoSilentBlockEnergy {
+		// cancellation adds more than it removes, e. g. after the echo path changed abruptly
+		c.resetFilter()
+		return
+	}
+
+	for i := range n {
+		c.buf[i] = 0
+		c.buf[n+i] = complex(frame[i]-c.echo[i], 0)
+	}
+	c.fft.transform(c.buf, c.spectrum)
+
+	if farEnergy > echoSilentBlockEnergy {
+		c.adapt()
+	}
+
+	gain := float32(1)
+	if c.minGain < 1 {
+		target := max(1-min(echoEnergy/(nearEnergy+1e-10), 1), c.minGain)
+		if target < c.gain {
+			c.gain = target
+		} else {
+			c.gain += 0.2 * (target - c.gain)
+		}
+		gain = c.gain
+	}
+	for i := range n {
+		frame[i] = min(max((frame[i]-c.echo[i])*gain, -1), 1)
+	}
+}
+
+// adapt moves the filter along the normalized gradient of the error spectrum, constraining one partition
+// per block to a causal response of a block
+func (c *EchoCanceller) adapt() {
+	n := echoBlockSize
+	partitions := float32(len(c.weights))
+	regularization := float32(2*n) * 1e-6
+	for k, x := range c.farSpectra[0] {
+		c.power[k] = 0.9*c.power[k] + 0.1*partitions*(real(x)*real(x)+imag(x)*imag(x))
+
+		// large errors, mostly local speech, are clipped to keep double talk from throwing the filter off
+		e := c.spectrum[k]
+		magnitude := float32(math.Hypot(float64(real(e)), float64(imag(e))))
+		if limit := 2 * float32(math.Sqrt(float64(c.power[k]/partitions))); magnitude > limit {
+			e *= complex(limit/magnitude, 0)
+		}
+		step := e * complex(echoStepSize/(c.power[k]+regularization), 0)
+		for p := range c.weights {
+			x := c.farSpectra[p][k]
+			c.weights[p][k] += complex(real(x), -imag(x)) * step
+		}
+	}
+
+	w := c.weights[c.constrain]
+	c.fft.inverse(w, c.buf)
+	for i := n; i < 2*n; i++ {
+		c.buf[i] = 0
+	}
+	c.fft.transform(c.buf, w)
+	c.constrain = (c.constrain + 1) % len(c.weights)
+}
+
+func (c *EchoCanceller) resetHistory() {
+	clear(c.far)
+	for _, spectrum := range c.farSpectra {
+		clear(spectrum)
+	}
+}
+
+func (c *EchoCanceller) resetFilter() {
+	c.resetHistory()
+	for _, w := range c.weights {
+		clear(w)
+	}
+	clear(c.power)
+	c.nearPower, c.errorPower = 0, 0
+	c.gain = 1
+}
+
+// pearson returns the correlation coefficient of a and b, 0 if either is constant
+func pearson(a []float64, b []float64) float64 {
+	var meanA, meanB float64
+	for i := range a {
+		meanA += a[i]
+		meanB += b[i]
+	}
+	meanA /= float64(len(a))
+	meanB /= float64(len(b))
+
+	var cov, varA, varB float64
+	for i := range a {
+		da, db := a[i]-meanA, b[i]-meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+	if varA == 0 || varB == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varA*varB)
+}
+
+func abs64(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
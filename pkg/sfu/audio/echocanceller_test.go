@@ -0,0 +1,204 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFFT(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for _, n := range []int{16, 960} {
+		in := make([]complex64, n)
+		for i := range in {
+			in[i] = complex(float32(rng.NormFloat64()), float32(rng.NormFloat64()))
+		}
+
+		f := newFFT(n)
+		out := make([]complex64, n)
+		f.transform(in, out)
+		for k := range n {
+			var expected complex128
+			for i := range n {
+				expected += complex128(in[i]) * cmplx.Exp(complex(0, -2*math.Pi*float64(i*k)/float64(n)))
+			}
+			require.InDelta(t, real(expected), real(out[k]), 1e-2, "n %d bin %d", n, k)
+			require.InDelta(t, imag(expected), imag(out[k]), 1e-2, "n %d bin %d", n, k)
+		}
+
+		back := make([]complex64, n)
+		f.inverse(out, back)
+		for i := range n {
+			require.InDelta(t, real(in[i]), real(back[i]), 1e-4)
+			require.InDelta(t, imag(in[i]), imag(back[i]), 1e-4)
+		}
+	}
+}
+
+func TestEchoReference(t *testing.T) {
+	ref := NewEchoReference(DefaultEchoCancellationConfig)
+	now := ref.epoch.Add(time.Second)
+	pos := ref.position(now)
+
+	block := make([]int16, echoBlockSize)
+	for i := range block {
+		block[i] = 16384
+	}
+	ref.Write("a", block, now)
+	// continues where the previous audio ended though received early
+	ref.Write("a", block, now.Add(5*time.Millisecond))
+	// mixed with the audio of another source
+	ref.Write("b", block[:10], now)
+
+	out := make([]float32, 2*echoBlockSize+10)
+	ref.read(pos-10, out)
+	require.Zero(t, out[0])
+	require.Equal(t, float32(1), out[10])
+	require.Equal(t, float32(1), out[19])
+	require.Equal(t, float32(0.5), out[20])
+	require.Equal(t, float32(0.5), out[2*echoBlockSize+9])
+
+	// a source resuming long after its audio ended is placed at the time it is received
+	later := now.Add(time.Second)
+	ref.Write("a", block, later)
+	ref.read(ref.position(later), out[:1])
+	require.Equal(t, float32(0.5), out[0])
+	ref.read(pos+2*echoBlockSize, out[:1])
+	require.Zero(t, out[0])
+}
+
+// speechLike returns frames of noise switched on and off at random like syllables, so that its energy envelope varies
+func speechLike(rng *rand.Rand, level float64) func() []float32 {
+	var on bool
+	var state float64
+	return func() []float32 {
+		out := make([]float32, echoBlockSize)
+		if rng.Intn(8) == 0 {
+			on = !on
+		}
+		if !on {
+			return out
+		}
+		for i := range out {
+			state = 0.7*state + 0.3*rng.NormFloat64()
+			out[i] = float32(state * level)
+		}
+		return out
+	}
+}
+
+func TestEchoCanceller(t *testing.T) {
+	const (
+		delayFrames = 20
+		frames      = 1000
+	)
+	rng := rand.New(rand.NewSource(1))
+
+	// decaying echo path
+	path := make([]float64, 600)
+	for i := range path {
+		path[i] = 0.3 * rng.NormFloat64() * math.Exp(-float64(i)/100)
+	}
+
+	ref := NewEchoReference(DefaultEchoCancellationConfig)
+	canceller := NewEchoCanceller(DefaultEchoCancellationConfig, ref)
+	start := ref.epoch.Add(time.Second)
+
+	agent, local := speechLike(rng, 0.1), speechLike(rng, 0.05)
+	var played []float32
+	var echoEnergy, outEnergy, nearEnergy, nearOutEnergy float64
+	for f := range frames {
+		now := start.Add(time.Duration(f) * echoBlockDuration)
+		far := make([]float32, echoBlockSize)
+		if f < 800 {
+			far = agent()
+		}
+		pcm := make([]int16, echoBlockSize)
+		for i, s := range far {
+			pcm[i] = int16(s * 32767)
+		}
+		ref.Write("agent", pcm, now)
+		played = append(played, far...)
+
+		// the echo of the audio played delayFrames ago, along with local speech late on
+		frame := make([]float32, echoBlockSize)
+		for i := range frame {
+			n := len(played) - delayFrames*echoBlockSize - echoBlockSize + i
+			var echo float64
+			for j := 0; j < len(path) && n-j >= 0; j++ {
+				echo += path[j] * float64(played[n-j])
+			}
+			frame[i] = float32(echo + 1e-4*rng.NormFloat64())
+		}
+		var near []float32
+		if f >= 700 {
+			near = local()
+			for i := range frame {
+				frame[i] += near[i]
+			}
+		}
+
+		before := frame
+		frame = append([]float32(nil), before...)
+		canceller.Process(frame, now)
+
+		switch {
+		case f >= 500 && f < 700:
+			for i := range frame {
+				echoEnergy += float64(before[i]) * float64(before[i])
+				outEnergy += float64(frame[i]) * float64(frame[i])
+			}
+		case f >= 800:
+			for i := range frame {
+				nearEnergy += float64(near[i]) * float64(near[i])
+				nearOutEnergy += float64(frame[i]) * float64(frame[i])
+			}
+		}
+	}
+
+	delay, ok := canceller.Delay()
+	require.True(t, ok)
+	require.Equal(t, (delayFrames-echoDelayMargin)*echoBlockDuration, delay)
+
+	erle := 10 * math.Log10(echoEnergy/outEnergy)
+	require.Greater(t, erle, 10.0)
+
+	// local speech passes once the agent is silent
+	require.InDelta(t, 0, 10*math.Log10(nearOutEnergy/nearEnergy), 1)
+}
+
+func TestEchoCanceller_NoReference(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	ref := NewEchoReference(DefaultEchoCancellationConfig)
+	canceller := NewEchoCanceller(DefaultEchoCancellationConfig, ref)
+	start := ref.epoch.Add(time.Second)
+
+	local := speechLike(rng, 0.1)
+	for f := range 300 {
+		frame := local()
+		before := append([]float32(nil), frame...)
+		canceller.Process(frame, start.Add(time.Duration(f)*echoBlockDuration))
+		require.Equal(t, before, frame)
+	}
+	_, ok := canceller.Delay()
+	require.False(t, ok)
+}
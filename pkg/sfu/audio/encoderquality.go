@@ -0,0 +1,364 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// EncoderQuality are the libopus settings of a re-encoded stream
+type EncoderQuality struct {
+	// 0 to 10, higher is better quality at the same bitrate for more CPU
+	Complexity int `yaml:"complexity,omitempty"`
+	// in-band forward error correction, lets receivers recover a lost frame from the next packet
+	InBandFEC bool `yaml:"in_band_fec,omitempty"`
+	// expected packet loss in percent, the encoder spends more of the bitrate on FEC the higher it is
+	PacketLossPerc int `yaml:"packet_loss_perc,omitempty"`
+}
+
+// QualityScavengingConfig controls spending idle CPU on re-encoded streams. While the node is idle,
+// streams are moved to the upgraded quality a few at a time, under load they are moved back to the base quality.
+type QualityScavengingConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how often the CPU load is sampled and streams are moved
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// streams are upgraded while the CPU load is below this
+	IdleCPUThreshold float64 `yaml:"idle_cpu_threshold,omitempty"`
+	// streams are downgraded while the CPU load is above this, or above the cpu_load_limit of the node selector if that is lower
+	BusyCPUThreshold float64 `yaml:"busy_cpu_threshold,omitempty"`
+	// fill of the placement worker queues above which streams are downgraded regardless of the CPU load
+	MaxPlacementBacklog float64 `yaml:"max_placement_backlog,omitempty"`
+	// streams moved per interval
+	Step int `yaml:"step,omitempty"`
+	// upper bound of upgraded streams, 0 for no bound
+	MaxUpgraded int `yaml:"max_upgraded,omitempty"`
+	// settings of streams when the node is busy, applied when a stream starts
+	Base EncoderQuality `yaml:"base,omitempty"`
+	// settings of upgraded streams
+	Upgraded EncoderQuality `yaml:"upgraded,omitempty"`
+}
+
+var (
+	DefaultQualityScavengingConfig = QualityScavengingConfig{
+		Interval:            5 * time.Second,
+		IdleCPUThreshold:    0.5,
+		BusyCPUThreshold:    0.75,
+		MaxPlacementBacklog: 0.25,
+		Step:                8,
+		Base: EncoderQuality{
+			Complexity: 5,
+		},
+		Upgraded: EncoderQuality{
+			Complexity:     10,
+			InBandFEC:      true,
+			PacketLossPerc: 10,
+		},
+	}
+)
+
+// OpusEncoderTuner is implemented by Opus encoders that can change their settings while encoding
+type OpusEncoderTuner interface {
+	SetComplexity(complexity int) error
+	SetInBandFEC(fec bool) error
+	SetPacketLossPerc(lossPerc int) error
+}
+
+// OpusDTXController is implemented by Opus encoders that can switch discontinuous transmission
+type OpusDTXController interface {
+	SetDTX(dtx bool) error
+}
+
+// OpusBitrateController is implemented by Opus encoders that can be held to a target bitrate
+type OpusBitrateController interface {
+	SetBitrate(bitrate int) error
+	SetBitrateToAuto() error
+}
+
+const (
+	// bitrate range accepted by libopus, in bits per second
+	opusMinBitrate = 6000
+	opusMaxBitrate = 510000
+)
+
+var (
+	ErrOpusBitrateUnsupported = errors.New("opus encoder does not support setting the bitrate")
+	ErrOpusSettingUnsupported = errors.New("opus encoder does not support the setting")
+)
+
+// SetOpusBitrate holds the encoder to bitrate bits per second, clamped to the range of libopus.
+// A bitrate of 0 lets the encoder choose again.
+func SetOpusBitrate(encoder OpusEncoder, bitrate int) error {
+	if tuned, ok := encoder.(*TunedOpusEncoder); ok {
+		encoder = tuned.OpusEncoder
+	}
+	c, ok := encoder.(OpusBitrateController)
+	if !ok {
+		return ErrOpusBitrateUnsupported
+	}
+	if bitrate <= 0 {
+		return c.SetBitrateToAuto()
+	}
+	return c.SetBitrate(min(max(bitrate, opusMinBitrate), opusMaxBitrate))
+}
+
+// PreservedFECPacketLossPerc is the expected packet loss an encoder preserving in-band FEC is set to at least,
+// libopus adds no FEC at 0
+const PreservedFECPacketLossPerc = 10
+
+// PreserveOpusSettings applies the settings of the original encoder of a stream to the encoder re-encoding it.
+// Quality changes of an EncoderRegistry keep them, in-band FEC is never turned off again.
+func PreserveOpusSettings(encoder OpusEncoder, settings OpusEncoderSettings) error {
+	if tuned, ok := encoder.(*TunedOpusEncoder); ok {
+		tuned.preserved.Store(&settings)
+		encoder = tuned.OpusEncoder
+	}
+
+	if settings.DTX {
+		c, ok := encoder.(OpusDTXController)
+		if !ok {
+			return ErrOpusSettingUnsupported
+		}
+		if err := c.SetDTX(true); err != nil {
+			return err
+		}
+	}
+	if settings.InBandFEC {
+		tuner, ok := encoder.(OpusEncoderTuner)
+		if !ok {
+			return ErrOpusSettingUnsupported
+		}
+		if err := tuner.SetInBandFEC(true); err != nil {
+			return err
+		}
+		return tuner.SetPacketLossPerc(PreservedFECPacketLossPerc)
+	}
+	return nil
+}
+
+// preserving returns the quality keeping the settings of the original encoder
+func (q EncoderQuality) preserving(settings *OpusEncoderSettings) EncoderQuality {
+	if settings != nil && settings.InBandFEC {
+		q.InBandFEC = true
+		q.PacketLossPerc = max(q.PacketLossPerc, PreservedFECPacketLossPerc)
+	}
+	return q
+}
+
+func applyEncoderQuality(encoder OpusEncoder, q EncoderQuality) error {
+	tuner, ok := encoder.(OpusEncoderTuner)
+	if !ok {
+		return nil
+	}
+	if err := tuner.SetComplexity(q.Complexity); err != nil {
+		return err
+	}
+	if err := tuner.SetInBandFEC(q.InBandFEC); err != nil {
+		return err
+	}
+	return tuner.SetPacketLossPerc(q.PacketLossPerc)
+}
+
+// EncoderPriority orders re-encoded streams, higher priority streams are upgraded first and downgraded last
+type EncoderPriority int
+
+const (
+	// denoised publisher audio, heard by every subscriber of the track
+	EncoderPriorityDenoised EncoderPriority = iota
+	// server side mixes, usually what agents listen to
+	EncoderPriorityMixed
+)
+
+// --------------------------------------
+
+// TunedOpusEncoder is an encoder tracked by an EncoderRegistry. Quality changes are applied
+// with the next Encode, libopus encoders must not be used from several goroutines.
+type TunedOpusEncoder struct {
+	OpusEncoder
+
+	priority EncoderPriority
+	pending  atomic.Pointer[EncoderQuality]
+	// settings of the original encoder, nil when not re-encoding a stream
+	preserved atomic.Pointer[OpusEncoderSettings]
+
+	// guarded by the lock of the registry
+	upgraded bool
+}
+
+func (e *TunedOpusEncoder) Encode(pcm []int16, out []byte) (int, error) {
+	if q := e.pending.Swap(nil); q != nil {
+		// the settings only tune the encoder, it keeps working with the previous ones
+		_ = applyEncoderQuality(e.OpusEncoder, q.preserving(e.preserved.Load()))
+	}
+	return e.OpusEncoder.Encode(pcm, out)
+}
+
+func (e *TunedOpusEncoder) set(q EncoderQuality, upgraded bool) {
+	e.upgraded = upgraded
+	e.pending.Store(&q)
+}
+
+// --------------------------------------
+
+// EncoderRegistry keeps the re-encoded streams of the node whose quality follows the idle CPU
+type EncoderRegistry struct {
+	lock     sync.Mutex
+	config   QualityScavengingConfig
+	encoders []*TunedOpusEncoder
+}
+
+// DefaultEncoderRegistry is shared by all re-encoding sites, streams are only tracked once it is enabled
+var DefaultEncoderRegistry = &EncoderRegistry{}
+
+// Enable starts tracking encoders created afterwards
+func (r *EncoderRegistry) Enable(config QualityScavengingConfig) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.config = config
+}
+
+// Track wraps the encoder to follow the quality of the registry and applies the base quality.
+// The encoder is returned as is when quality scavenging is disabled.
+func (r *EncoderRegistry) Track(encoder OpusEncoder, priority EncoderPriority) OpusEncoder {
+	if encoder == nil {
+		return nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.config.Enabled {
+		return encoder
+	}
+
+	e := &TunedOpusEncoder{
+		OpusEncoder: encoder,
+		priority:    priority,
+	}
+	e.set(r.config.Base, false)
+	r.encoders = append(r.encoders, e)
+	return e
+}
+
+// Untrack stops tracking an encoder returned by Track, no-op for any other encoder
+func (r *EncoderRegistry) Untrack(encoder OpusEncoder) {
+	e, ok := encoder.(*TunedOpusEncoder)
+	if !ok {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for i, tracked := range r.encoders {
+		if tracked == e {
+			r.encoders = append(r.encoders[:i], r.encoders[i+1:]...)
+			return
+		}
+	}
+}
+
+// Counts returns the number of upgraded and of all tracked streams
+func (r *EncoderRegistry) Counts() (upgraded int, total int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	for _, e := range r.encoders {
+		if e.upgraded {
+			upgraded++
+		}
+	}
+	return upgraded, len(r.encoders)
+}
+
+// Rebalance moves up to a step of streams for the sampled CPU load and placement backlog.
+// cpuLoadLimit is the load above which the node stops taking new rooms, 0 if there is none,
+// streams are downgraded before the node reaches it. Returns the number of streams moved up and down.
+func (r *EncoderRegistry) Rebalance(cpuLoad float64, backlog float64, cpuLoadLimit float64) (upgraded int, downgraded int) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if !r.config.Enabled {
+		return 0, 0
+	}
+
+	busy := r.config.BusyCPUThreshold
+	if cpuLoadLimit > 0 && cpuLoadLimit < busy {
+		busy = cpuLoadLimit
+	}
+	step := r.config.Step
+	if step <= 0 {
+		step = 1
+	}
+
+	switch {
+	case cpuLoad > busy || (r.config.MaxPlacementBacklog > 0 && backlog > r.config.MaxPlacementBacklog):
+		// lowest priority first, the most recently upgraded of a priority first
+		for _, e := range r.byPriorityLocked(false) {
+			if downgraded == step {
+				break
+			}
+			if e.upgraded {
+				e.set(r.config.Base, false)
+				downgraded++
+			}
+		}
+
+	case cpuLoad < r.config.IdleCPUThreshold:
+		count := 0
+		for _, e := range r.encoders {
+			if e.upgraded {
+				count++
+			}
+		}
+		for _, e := range r.byPriorityLocked(true) {
+			if upgraded == step || (r.config.MaxUpgraded > 0 && count == r.config.MaxUpgraded) {
+				break
+			}
+			if !e.upgraded {
+				e.set(r.config.Upgraded, true)
+				upgraded++
+				count++
+			}
+		}
+	}
+	return upgraded, downgraded
+}
+
+// byPriorityLocked returns the tracked encoders by priority, oldest first within a priority
+// when descending, newest first otherwise. Must be called with the lock held.
+func (r *EncoderRegistry) byPriorityLocked(descending bool) []*TunedOpusEncoder {
+	encoders := make([]*TunedOpusEncoder, len(r.encoders))
+	if descending {
+		copy(encoders, r.encoders)
+	} else {
+		for i, e := range r.encoders {
+			encoders[len(encoders)-1-i] = e
+		}
+	}
+	sort.SliceStable(encoders, func(i, j int) bool {
+		if descending {
+			return encoders[i].priority > encoders[j].priority
+		}
+		return encoders[i].priority < encoders[j].priority
+	})
+	return encoders
+}
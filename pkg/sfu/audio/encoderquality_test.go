@@ -0,0 +1,209 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tunableEncoder struct {
+	quality EncoderQuality
+	dtx     bool
+}
+
+func (e *tunableEncoder) Encode(pcm []int16, out []byte) (int, error) {
+	return 0, nil
+}
+
+func (e *tunableEncoder) SetComplexity(complexity int) error {
+	e.quality.Complexity = complexity
+	return nil
+}
+
+func (e *tunableEncoder) SetInBandFEC(fec bool) error {
+	e.quality.InBandFEC = fec
+	return nil
+}
+
+func (e *tunableEncoder) SetPacketLossPerc(lossPerc int) error {
+	e.quality.PacketLossPerc = lossPerc
+	return nil
+}
+
+func (e *tunableEncoder) SetDTX(dtx bool) error {
+	e.dtx = dtx
+	return nil
+}
+
+func TestEncoderRegistry(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		r := &EncoderRegistry{}
+		enc := &tunableEncoder{}
+		require.Same(t, enc, r.Track(enc, EncoderPriorityMixed))
+
+		up, down := r.Rebalance(0, 0, 0)
+		require.Zero(t, up)
+		require.Zero(t, down)
+	})
+
+	t.Run("rebalance", func(t *testing.T) {
+		conf := DefaultQualityScavengingConfig
+		conf.Enabled = true
+		conf.Step = 2
+		conf.MaxUpgraded = 3
+
+		r := &EncoderRegistry{}
+		r.Enable(conf)
+
+		denoised := []*tunableEncoder{{}, {}}
+		mixed := []*tunableEncoder{{}, {}}
+		var tracked []OpusEncoder
+		for i := range denoised {
+			tracked = append(tracked, r.Track(denoised[i], EncoderPriorityDenoised))
+			tracked = append(tracked, r.Track(mixed[i], EncoderPriorityMixed))
+		}
+		encode := func() {
+			for _, enc := range tracked {
+				_, err := enc.Encode(nil, nil)
+				require.NoError(t, err)
+			}
+		}
+		encode()
+		for _, enc := range append(denoised, mixed...) {
+			require.Equal(t, conf.Base, enc.quality)
+		}
+
+		// settings between the thresholds are kept
+		up, down := r.Rebalance(0.6, 0, 0)
+		require.Zero(t, up)
+		require.Zero(t, down)
+
+		// mixes are upgraded first, a step at a time
+		up, down = r.Rebalance(0.2, 0, 0)
+		require.Equal(t, 2, up)
+		require.Zero(t, down)
+		encode()
+		require.Equal(t, conf.Upgraded, mixed[0].quality)
+		require.Equal(t, conf.Upgraded, mixed[1].quality)
+		require.Equal(t, conf.Base, denoised[0].quality)
+
+		// up to the bound
+		up, _ = r.Rebalance(0.2, 0, 0)
+		require.Equal(t, 1, up)
+		encode()
+		require.Equal(t, conf.Upgraded, denoised[0].quality)
+		require.Equal(t, conf.Base, denoised[1].quality)
+		numUpgraded, numStreams := r.Counts()
+		require.Equal(t, 3, numUpgraded)
+		require.Equal(t, 4, numStreams)
+
+		// below the busy threshold, but above the load limit of the node selector
+		_, down = r.Rebalance(0.7, 0, 0.65)
+		require.Equal(t, 2, down)
+		encode()
+		require.Equal(t, conf.Base, denoised[0].quality)
+		require.Equal(t, conf.Base, mixed[1].quality)
+		require.Equal(t, conf.Upgraded, mixed[0].quality)
+
+		// placement queues backing up
+		_, down = r.Rebalance(0.2, 0.5, 0)
+		require.Equal(t, 1, down)
+		encode()
+		require.Equal(t, conf.Base, mixed[0].quality)
+
+		for _, enc := range tracked {
+			r.Untrack(enc)
+		}
+		_, numStreams = r.Counts()
+		require.Zero(t, numStreams)
+	})
+
+	t.Run("preserves the settings of the original encoder", func(t *testing.T) {
+		conf := DefaultQualityScavengingConfig
+		conf.Enabled = true
+
+		r := &EncoderRegistry{}
+		r.Enable(conf)
+
+		enc := &tunableEncoder{}
+		tracked := r.Track(enc, EncoderPriorityDenoised)
+		require.NoError(t, PreserveOpusSettings(tracked, OpusEncoderSettings{InBandFEC: true, DTX: true}))
+		require.True(t, enc.dtx)
+
+		// the base quality has no FEC, it is kept
+		_, err := tracked.Encode(nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, conf.Base.Complexity, enc.quality.Complexity)
+		require.True(t, enc.quality.InBandFEC)
+		require.Equal(t, PreservedFECPacketLossPerc, enc.quality.PacketLossPerc)
+
+		r.Rebalance(0.2, 0, 0)
+		_, err = tracked.Encode(nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, conf.Upgraded, enc.quality)
+
+		r.Rebalance(0.9, 0, 0)
+		_, err = tracked.Encode(nil, nil)
+		require.NoError(t, err)
+		require.True(t, enc.quality.InBandFEC)
+		r.Untrack(tracked)
+	})
+
+	t.Run("untracked encoders", func(t *testing.T) {
+		enc := &tunableEncoder{}
+		require.NoError(t, PreserveOpusSettings(enc, OpusEncoderSettings{InBandFEC: true}))
+		require.True(t, enc.quality.InBandFEC)
+		require.False(t, enc.dtx)
+
+		require.ErrorIs(t, PreserveOpusSettings(struct{ OpusEncoder }{}, OpusEncoderSettings{DTX: true}), ErrOpusSettingUnsupported)
+	})
+}
+
+type bitrateEncoder struct {
+	tunableEncoder
+	bitrate int
+}
+
+func (e *bitrateEncoder) SetBitrate(bitrate int) error {
+	e.bitrate = bitrate
+	return nil
+}
+
+func (e *bitrateEncoder) SetBitrateToAuto() error {
+	e.bitrate = 0
+	return nil
+}
+
+func TestSetOpusBitrate(t *testing.T) {
+	e := &bitrateEncoder{}
+	tuned := &TunedOpusEncoder{OpusEncoder: e}
+
+	require.NoError(t, SetOpusBitrate(tuned, 24000))
+	require.Equal(t, 24000, e.bitrate)
+
+	// clamped to what libopus accepts
+	require.NoError(t, SetOpusBitrate(e, 800))
+	require.Equal(t, opusMinBitrate, e.bitrate)
+	require.NoError(t, SetOpusBitrate(e, 1_000_000))
+	require.Equal(t, opusMaxBitrate, e.bitrate)
+
+	require.NoError(t, SetOpusBitrate(tuned, 0))
+	require.Zero(t, e.bitrate)
+
+	require.ErrorIs(t, SetOpusBitrate(&tunableEncoder{}, 24000), ErrOpusBitrateUnsupported)
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// fft is a mixed radix complex FFT for any size, fast for sizes with small prime factors such as
+// the 960 points of two 10 ms frames at 48 kHz. The forward transform is unnormalized, the inverse
+// one scales by 1/n. Not safe for concurrent use.
+type fft struct {
+	n       int
+	factors []int
+	// exp(-2πik/n) for k < n
+	twiddles  []complex64
+	scratch   []complex64
+	butterfly []complex64
+}
+
+func newFFT(n int) *fft {
+	f := &fft{
+		n:        n,
+		twiddles: make([]complex64, n),
+		scratch:  make([]complex64, n),
+	}
+	largest := 1
+	for rest := n; rest > 1; {
+		p := 2
+		for rest%p != 0 {
+			p++
+		}
+		f.factors = append(f.factors, p)
+		largest = max(largest, p)
+		rest /= p
+	}
+	f.butterfly = make([]complex64, largest)
+	for k := range f.twiddles {
+		f.twiddles[k] = complex64(cmplx.Exp(complex(0, -2*math.Pi*float64(k)/float64(n))))
+	}
+	return f
+}
+
+// transform writes the transform of in to out, both of length n
+func (f *fft) transform(in []complex64, out []complex64) {
+	f.recurse(in, 1, out, f.n, 0, false)
+}
+
+// inverse writes the inverse transform of in to out, both of length n
+func (f *fft) inverse(in []complex64, out []complex64) {
+	f.recurse(in, 1, out, f.n, 0, true)
+	scale := complex(1/float32(f.n), 0)
+	for i := range out {
+		out[i] *= scale
+	}
+}
+
+// recurse transforms the n samples of in taken every stride into out, decimating in time by the
+// factor at index level. Sub-transforms land in out, the butterflies combine them in place.
+func (f *fft) recurse(in []complex64, stride int, out []complex64, n int, level int, inverse bool) {
+	if n == 1 {
+		out[0] = in[0]
+		return
+	}
+
+	p := f.factors[level]
+	m := n / p
+	for q := 0; q < p; q++ {
+		f.recurse(in[q*stride:], stride*p, out[q*m:(q+1)*m], m, level+1, inverse)
+	}
+
+	// twiddles of this size are every step-th of the full size
+	step := f.n / n
+	sums := f.scratch[:n]
+	for k := 0; k < m; k++ {
+		for q := 0; q < p; q++ {
+			f.butterfly[q] = out[q*m+k]
+		}
+		for r := 0; r < p; r++ {
+			j := k + r*m
+			var sum complex64
+			for q := 0; q < p; q++ {
+				w := f.twiddles[(q*j*step)%f.n]
+				if inverse {
+					w = complex(real(w), -imag(w))
+				}
+				sum += f.butterfly[q] * w
+			}
+			sums[j] = sum
+		}
+	}
+	copy(out[:n], sums)
+}
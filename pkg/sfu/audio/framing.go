@@ -0,0 +1,98 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"fmt"
+	"time"
+)
+
+// FramingConfig sets the frame duration all server side audio processing runs at.
+// Publishers may send Opus packets of any duration, decoded audio is repacketized into
+// frames of this duration where it enters the pipeline and encoded at this duration
+// where it leaves, stages in between only ever see whole frames.
+// 10 ms lowers latency, 20 ms lowers CPU.
+type FramingConfig struct {
+	FrameDuration time.Duration `yaml:"frame_duration,omitempty"`
+}
+
+const (
+	// frame duration configs like jitter_frames are expressed in, kept for backwards compatibility
+	legacyFrameDuration = 20 * time.Millisecond
+)
+
+var (
+	DefaultFramingConfig = FramingConfig{
+		FrameDuration: 20 * time.Millisecond,
+	}
+)
+
+func (c FramingConfig) Validate() error {
+	switch c.FrameDuration {
+	case 0, 10 * time.Millisecond, 20 * time.Millisecond:
+		return nil
+	default:
+		return fmt.Errorf("invalid audio frame duration %s, must be 10ms or 20ms", c.FrameDuration)
+	}
+}
+
+// Duration returns the frame duration, the default when unset
+func (c FramingConfig) Duration() time.Duration {
+	if c.FrameDuration <= 0 {
+		return DefaultFramingConfig.FrameDuration
+	}
+	return c.FrameDuration
+}
+
+// FrameSize returns the samples per channel in a frame at 48 kHz
+func (c FramingConfig) FrameSize() int {
+	return int(int64(OpusSampleRate) * int64(c.Duration()) / int64(time.Second))
+}
+
+// Frames returns the number of frames needed to cover d, at least one
+func (c FramingConfig) Frames(d time.Duration) int {
+	frame := c.Duration()
+	return max(1, int((d+frame-1)/frame))
+}
+
+// MixerParams returns the mixer parameters for this framing. Buffering is kept
+// at the same duration as with 20 ms frames, so changing the frame duration does
+// not change how much jitter is absorbed.
+func (c FramingConfig) MixerParams(config MixerConfig) MixerParams {
+	jitterFrames := DefaultMixerParams.JitterFrames
+	if config.JitterFrames > 0 {
+		jitterFrames = config.JitterFrames
+	}
+
+	params := MixerParams{
+		FrameSize:       c.FrameSize(),
+		JitterFrames:    c.Frames(time.Duration(jitterFrames) * legacyFrameDuration),
+		MaxQueuedFrames: c.Frames(time.Duration(DefaultMixerParams.MaxQueuedFrames) * legacyFrameDuration),
+	}
+	if config.CatchUp.Enabled {
+		maxBacklogFrames := DefaultCatchUpConfig.MaxBacklogFrames
+		if config.CatchUp.MaxBacklogFrames > 0 {
+			maxBacklogFrames = config.CatchUp.MaxBacklogFrames
+		}
+		params.CatchUpMaxSpeed = DefaultCatchUpConfig.MaxSpeed
+		if config.CatchUp.MaxSpeed > 1 {
+			// segments stop overlapping beyond double speed
+			params.CatchUpMaxSpeed = min(config.CatchUp.MaxSpeed, 2)
+		}
+		params.MaxQueuedFrames = max(params.MaxQueuedFrames, c.Frames(time.Duration(maxBacklogFrames)*legacyFrameDuration))
+	}
+	return params
+}
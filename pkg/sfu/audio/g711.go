@@ -0,0 +1,97 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+// G.711 companding of 16 bit PCM, ITU-T G.711. Both laws carry 8 kHz mono audio, one byte per sample.
+
+const (
+	G711SampleRate = 8000
+
+	muLawBias = 0x84
+	muLawClip = 32635
+)
+
+var aLawSegmentEnd = [8]int32{0x1f, 0x3f, 0x7f, 0xff, 0x1ff, 0x3ff, 0x7ff, 0xfff}
+
+func EncodeMuLaw(sample int16) byte {
+	x := int32(sample)
+	sign := byte(0)
+	if x < 0 {
+		x = -x
+		sign = 0x80
+	}
+	x = min(x, muLawClip) + muLawBias
+
+	exponent := byte(7)
+	for mask := int32(0x4000); x&mask == 0 && exponent > 0; mask >>= 1 {
+		exponent--
+	}
+	mantissa := byte(x>>(exponent+3)) & 0x0f
+	return ^(sign | exponent<<4 | mantissa)
+}
+
+func DecodeMuLaw(b byte) int16 {
+	u := ^b
+	exponent := (u >> 4) & 0x07
+	x := ((int32(u&0x0f) << 3) + muLawBias) << exponent
+	x -= muLawBias
+	if u&0x80 != 0 {
+		return int16(-x)
+	}
+	return int16(x)
+}
+
+func EncodeALaw(sample int16) byte {
+	x := int32(sample) >> 3
+	mask := byte(0xd5)
+	if x < 0 {
+		mask = 0x55
+		x = -x - 1
+	}
+
+	segment := 0
+	for segment < len(aLawSegmentEnd) && x > aLawSegmentEnd[segment] {
+		segment++
+	}
+	if segment == len(aLawSegmentEnd) {
+		return 0x7f ^ mask
+	}
+
+	a := byte(segment << 4)
+	if segment < 2 {
+		a |= byte(x>>1) & 0x0f
+	} else {
+		a |= byte(x>>segment) & 0x0f
+	}
+	return a ^ mask
+}
+
+func DecodeALaw(b byte) int16 {
+	a := b ^ 0x55
+	x := int32(a&0x0f) << 4
+	switch segment := (a >> 4) & 0x07; segment {
+	case 0:
+		x += 8
+	case 1:
+		x += 0x108
+	default:
+		x = (x + 0x108) << (segment - 1)
+	}
+	if a&0x80 != 0 {
+		return int16(x)
+	}
+	return int16(-x)
+}
@@ -0,0 +1,44 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+)
+
+const (
+	// range of the gain of a track set by operators, attenuating further is muting it
+	MinTrackGainDB = -60
+	MaxTrackGainDB = 20
+)
+
+// GainFactor returns the linear factor of a gain in dB clamped to maxDB, 0 at or below minDB
+func GainFactor(gainDB float64, minDB float64, maxDB float64) float64 {
+	if gainDB <= minDB {
+		return 0
+	}
+	return math.Pow(10, min(gainDB, maxDB)/20)
+}
+
+// ApplyGain scales pcm in place by factor, clipping what exceeds the range of int16
+func ApplyGain(pcm []int16, factor float64) {
+	if factor == 1 {
+		return
+	}
+	for i, sample := range pcm {
+		pcm[i] = clipInt16(int32(math.Round(float64(sample) * factor)))
+	}
+}
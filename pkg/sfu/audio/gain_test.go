@@ -0,0 +1,39 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGainFactor(t *testing.T) {
+	require.Equal(t, 1.0, GainFactor(0, MinTrackGainDB, MaxTrackGainDB))
+	require.InDelta(t, 0.5, GainFactor(-6.0206, MinTrackGainDB, MaxTrackGainDB), 1e-4)
+	require.Zero(t, GainFactor(MinTrackGainDB, MinTrackGainDB, MaxTrackGainDB))
+	require.Equal(t, 10.0, GainFactor(40, MinTrackGainDB, MaxTrackGainDB))
+}
+
+func TestApplyGain(t *testing.T) {
+	pcm := []int16{100, -100, 20000, -20000}
+	ApplyGain(pcm, 2)
+	require.Equal(t, []int16{200, -200, math.MaxInt16, math.MinInt16}, pcm)
+
+	ApplyGain(pcm, 0)
+	require.Equal(t, []int16{0, 0, 0, 0}, pcm)
+}
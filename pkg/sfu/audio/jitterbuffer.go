@@ -0,0 +1,295 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+// JitterBufferConfig controls buffering the packets of a stream ahead of the consumers decoding it on the server,
+// e. g. the mixer and PCM taps, so that they get the packets in order and paced by their RTP timestamps
+type JitterBufferConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// delay packets are held for on a steady network
+	TargetDelay time.Duration `yaml:"target_delay,omitempty"`
+	// the delay grows with the measured jitter up to this
+	MaxDelay time.Duration `yaml:"max_delay,omitempty"`
+	// packets held at most, the oldest is dropped when the buffer is full
+	MaxPackets int `yaml:"max_packets,omitempty"`
+}
+
+var (
+	DefaultJitterBufferConfig = JitterBufferConfig{
+		TargetDelay: 40 * time.Millisecond,
+		MaxDelay:    200 * time.Millisecond,
+		MaxPackets:  50,
+	}
+)
+
+const (
+	// the delay is this multiple of the interarrival jitter
+	jitterBufferJitterFactor = 4
+	// the fastest transit of a window is the reference for the playout times, windows renew so that
+	// the reference follows drifting clocks
+	jitterBufferTransitWindow = 10 * time.Second
+)
+
+// JitterBufferResult is what happened to a packet pushed into a JitterBuffer
+type JitterBufferResult int
+
+const (
+	// buffered in order
+	JitterBufferAccepted JitterBufferResult = iota
+	// buffered ahead of packets that arrived before it
+	JitterBufferReordered
+	// dropped, packets after it were released already
+	JitterBufferLate
+	// dropped, the packet is buffered already
+	JitterBufferDuplicate
+)
+
+func (r JitterBufferResult) String() string {
+	switch r {
+	case JitterBufferAccepted:
+		return "accepted"
+	case JitterBufferReordered:
+		return "reordered"
+	case JitterBufferLate:
+		return "late"
+	case JitterBufferDuplicate:
+		return "duplicate"
+	default:
+		return "unknown"
+	}
+}
+
+// JitterBufferStats are the counts of a JitterBuffer since it was created
+type JitterBufferStats struct {
+	Packets   uint64
+	Reordered uint64
+	Late      uint64
+	// duplicates and packets dropped as the buffer was full
+	Dropped uint64
+	// current delay and interarrival jitter
+	Delay  time.Duration
+	Jitter time.Duration
+}
+
+type jitterBufferPacket[T any] struct {
+	sn   uint16
+	ts   int64
+	item T
+}
+
+// JitterBuffer holds the packets of a stream until their playout time, the RTP timestamp of a packet relative to
+// the fastest transit of the recent packets plus a delay adapting to the interarrival jitter, and releases them
+// in sequence order. A lost packet does not hold up the ones after it, they are released at their own playout
+// time and the lost packet is dropped as late if it arrives after all. Not safe for concurrent use.
+type JitterBuffer[T any] struct {
+	config    JitterBufferConfig
+	clockRate int
+
+	packets []jitterBufferPacket[T]
+
+	released bool
+	lastSN   uint16
+
+	// highest RTP timestamp, extended to 64 bits
+	hasTS bool
+	extTS int64
+
+	// transit times are arrival since start minus the extended RTP timestamp, in clock units
+	hasTransit    bool
+	lastTransit   float64
+	jitter        float64
+	minTransit    float64
+	prevMin       float64
+	windowStart   time.Time
+	hasPrevWindow bool
+	start         time.Time
+
+	stats JitterBufferStats
+}
+
+// NewJitterBuffer returns a buffer for a stream of the clock rate, nil when buffering is disabled
+func NewJitterBuffer[T any](config JitterBufferConfig, clockRate int) *JitterBuffer[T] {
+	if !config.Enabled || clockRate <= 0 {
+		return nil
+	}
+	if config.TargetDelay <= 0 {
+		config.TargetDelay = DefaultJitterBufferConfig.TargetDelay
+	}
+	if config.MaxDelay < config.TargetDelay {
+		config.MaxDelay = max(DefaultJitterBufferConfig.MaxDelay, config.TargetDelay)
+	}
+	if config.MaxPackets <= 0 {
+		config.MaxPackets = DefaultJitterBufferConfig.MaxPackets
+	}
+	return &JitterBuffer[T]{
+		config:    config,
+		clockRate: clockRate,
+	}
+}
+
+// Push buffers a packet that arrived at arrival. When the buffer is full its oldest packet is dropped.
+func (b *JitterBuffer[T]) Push(sn uint16, ts uint32, arrival time.Time, item T) JitterBufferResult {
+	b.stats.Packets++
+	if b.released && !snAfter(sn, b.lastSN) {
+		b.stats.Late++
+		return JitterBufferLate
+	}
+
+	// insert in sequence order, usually at the end
+	i := len(b.packets)
+	for i > 0 && snAfter(b.packets[i-1].sn, sn) {
+		i--
+	}
+	if i > 0 && b.packets[i-1].sn == sn {
+		b.stats.Dropped++
+		return JitterBufferDuplicate
+	}
+	b.packets = append(b.packets, jitterBufferPacket[T]{})
+	copy(b.packets[i+1:], b.packets[i:])
+	ext := b.unwrap(ts)
+	b.packets[i] = jitterBufferPacket[T]{sn: sn, ts: ext, item: item}
+
+	b.observeTransit(ext, arrival, i == len(b.packets)-1)
+	result := JitterBufferAccepted
+	if i < len(b.packets)-1 {
+		b.stats.Reordered++
+		result = JitterBufferReordered
+	}
+	for len(b.packets) > b.config.MaxPackets {
+		b.stats.Dropped++
+		b.removeHead()
+	}
+	return result
+}
+
+// unwrap extends an RTP timestamp to 64 bits next to the highest one
+func (b *JitterBuffer[T]) unwrap(ts uint32) int64 {
+	if !b.hasTS {
+		b.hasTS, b.extTS = true, int64(ts)
+		return b.extTS
+	}
+	ext := b.extTS + int64(int32(ts-uint32(b.extTS)))
+	b.extTS = max(b.extTS, ext)
+	return ext
+}
+
+func (b *JitterBuffer[T]) observeTransit(ts int64, arrival time.Time, inOrder bool) {
+	if b.start.IsZero() {
+		b.start = arrival
+		b.windowStart = arrival
+	}
+	transit := arrival.Sub(b.start).Seconds()*float64(b.clockRate) - float64(ts)
+	if !b.hasTransit {
+		b.hasTransit = true
+		b.lastTransit, b.minTransit = transit, transit
+		return
+	}
+
+	// RFC 3550 interarrival jitter, of packets in order
+	if inOrder {
+		d := transit - b.lastTransit
+		if d < 0 {
+			d = -d
+		}
+		b.jitter += (d - b.jitter) / 16
+		b.lastTransit = transit
+	}
+
+	if arrival.Sub(b.windowStart) >= jitterBufferTransitWindow {
+		b.prevMin, b.hasPrevWindow = b.minTransit, true
+		b.minTransit = transit
+		b.windowStart = arrival
+	} else if transit < b.minTransit {
+		b.minTransit = transit
+	}
+}
+
+// delay returns the delay adapted to the jitter
+func (b *JitterBuffer[T]) delay() time.Duration {
+	jitter := time.Duration(b.jitter * float64(time.Second) / float64(b.clockRate))
+	return min(max(b.config.TargetDelay, jitter*jitterBufferJitterFactor), b.config.MaxDelay)
+}
+
+// playout returns the time a packet is due
+func (b *JitterBuffer[T]) playout(ts int64) time.Time {
+	reference := b.minTransit
+	if b.hasPrevWindow && b.prevMin < reference {
+		reference = b.prevMin
+	}
+	at := (float64(ts) + reference) / float64(b.clockRate)
+	return b.start.Add(time.Duration(at*float64(time.Second)) + b.delay())
+}
+
+// Pop returns the next packet if it is due at now
+func (b *JitterBuffer[T]) Pop(now time.Time) (T, bool) {
+	var item T
+	if len(b.packets) == 0 {
+		return item, false
+	}
+
+	head := b.packets[0]
+	if now.Before(b.playout(head.ts)) {
+		return item, false
+	}
+	b.removeHead()
+	return head.item, true
+}
+
+func (b *JitterBuffer[T]) removeHead() {
+	b.released, b.lastSN = true, b.packets[0].sn
+	b.packets[0] = jitterBufferPacket[T]{}
+	b.packets = b.packets[1:]
+}
+
+// NextDeadline returns when the next packet is due, false when the buffer is empty
+func (b *JitterBuffer[T]) NextDeadline() (time.Time, bool) {
+	if len(b.packets) == 0 {
+		return time.Time{}, false
+	}
+	return b.playout(b.packets[0].ts), true
+}
+
+// Flush removes and returns the buffered packets in sequence order
+func (b *JitterBuffer[T]) Flush() []T {
+	items := make([]T, 0, len(b.packets))
+	for i := range b.packets {
+		items = append(items, b.packets[i].item)
+		b.released, b.lastSN = true, b.packets[i].sn
+	}
+	clear(b.packets)
+	b.packets = b.packets[:0]
+	return items
+}
+
+func (b *JitterBuffer[T]) Len() int {
+	return len(b.packets)
+}
+
+func (b *JitterBuffer[T]) Stats() JitterBufferStats {
+	stats := b.stats
+	stats.Delay = b.delay()
+	stats.Jitter = time.Duration(b.jitter * float64(time.Second) / float64(b.clockRate))
+	return stats
+}
+
+// snAfter returns whether sequence number a is after b
+func snAfter(a, b uint16) bool {
+	return a != b && a-b < 1<<15
+}
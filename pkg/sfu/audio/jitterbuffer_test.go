@@ -0,0 +1,120 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestJitterBuffer() *JitterBuffer[uint16] {
+	return NewJitterBuffer[uint16](JitterBufferConfig{
+		Enabled:     true,
+		TargetDelay: 40 * time.Millisecond,
+		MaxDelay:    200 * time.Millisecond,
+		MaxPackets:  10,
+	}, OpusSampleRate)
+}
+
+func popAll(b *JitterBuffer[uint16], now time.Time) []uint16 {
+	var sns []uint16
+	for {
+		sn, ok := b.Pop(now)
+		if !ok {
+			return sns
+		}
+		sns = append(sns, sn)
+	}
+}
+
+func TestJitterBufferDisabled(t *testing.T) {
+	require.Nil(t, NewJitterBuffer[uint16](JitterBufferConfig{}, OpusSampleRate))
+}
+
+func TestJitterBufferReorders(t *testing.T) {
+	b := newTestJitterBuffer()
+	start := time.Now()
+
+	require.Equal(t, JitterBufferAccepted, b.Push(1, 0, start, 1))
+	require.Equal(t, JitterBufferAccepted, b.Push(3, 1920, start.Add(40*time.Millisecond), 3))
+	require.Equal(t, JitterBufferReordered, b.Push(2, 960, start.Add(45*time.Millisecond), 2))
+	require.Equal(t, JitterBufferDuplicate, b.Push(2, 960, start.Add(46*time.Millisecond), 2))
+
+	// nothing is due before the target delay
+	require.Empty(t, popAll(b, start.Add(30*time.Millisecond)))
+	deadline, ok := b.NextDeadline()
+	require.True(t, ok)
+	require.True(t, deadline.After(start.Add(30*time.Millisecond)))
+
+	require.Equal(t, []uint16{1, 2, 3}, popAll(b, start.Add(time.Second)))
+
+	stats := b.Stats()
+	require.EqualValues(t, 4, stats.Packets)
+	require.EqualValues(t, 1, stats.Reordered)
+	require.EqualValues(t, 1, stats.Dropped)
+	require.Zero(t, stats.Late)
+}
+
+func TestJitterBufferSkipsLost(t *testing.T) {
+	b := newTestJitterBuffer()
+	start := time.Now()
+
+	b.Push(1, 0, start, 1)
+	b.Push(3, 1920, start.Add(40*time.Millisecond), 3)
+
+	// packet 2 does not hold up 3 past its playout time
+	require.Equal(t, []uint16{1, 3}, popAll(b, start.Add(100*time.Millisecond)))
+
+	// and is dropped when it arrives after all
+	require.Equal(t, JitterBufferLate, b.Push(2, 960, start.Add(110*time.Millisecond), 2))
+	require.EqualValues(t, 1, b.Stats().Late)
+	require.Zero(t, b.Len())
+}
+
+func TestJitterBufferOverflow(t *testing.T) {
+	b := newTestJitterBuffer()
+	start := time.Now()
+
+	for sn := uint16(0); sn < 12; sn++ {
+		b.Push(sn, uint32(sn)*960, start, sn)
+	}
+	require.Equal(t, 10, b.Len())
+	require.EqualValues(t, 2, b.Stats().Dropped)
+	require.Equal(t, []uint16{2, 3, 4, 5, 6, 7, 8, 9, 10, 11}, b.Flush())
+}
+
+func TestJitterBufferAdaptsDelay(t *testing.T) {
+	b := newTestJitterBuffer()
+	start := time.Now()
+
+	sn, ts := uint16(65530), uint32(1<<32-2*960)
+	for i := 0; i < 200; i++ {
+		arrival := start.Add(time.Duration(i) * 20 * time.Millisecond)
+		if i%2 == 1 {
+			arrival = arrival.Add(30 * time.Millisecond)
+		}
+		b.Push(sn, ts, arrival, sn)
+		popAll(b, arrival)
+		sn, ts = sn+1, ts+960
+	}
+
+	stats := b.Stats()
+	require.Greater(t, stats.Delay, 40*time.Millisecond)
+	require.LessOrEqual(t, stats.Delay, 200*time.Millisecond)
+	require.Zero(t, stats.Late)
+}
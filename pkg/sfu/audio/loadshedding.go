@@ -0,0 +1,96 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+// LoadSheddingConfig turns noise filtering off on the least important tracks of the node while it is overloaded,
+// a step at a time, and back on a step at a time once the load recovered
+type LoadSheddingConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how often the load is sampled and tracks are shed or restored
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// tracks are shed while the CPU load is above this
+	HighCPULoad float64 `yaml:"high_cpu_load,omitempty"`
+	// shed tracks are restored while the CPU load is below this
+	LowCPULoad float64 `yaml:"low_cpu_load,omitempty"`
+	// tracks are shed while denoising a frame takes longer than this on average, 0 ignores the latency
+	HighFrameLatency time.Duration `yaml:"high_frame_latency,omitempty"`
+	// shed tracks are restored while denoising a frame takes less than this on average
+	LowFrameLatency time.Duration `yaml:"low_frame_latency,omitempty"`
+	// share of the tracks shed or restored per interval, at least one track
+	Step float64 `yaml:"step,omitempty"`
+	// intervals the load has to stay low before a step is restored
+	RecoveryIntervals int `yaml:"recovery_intervals,omitempty"`
+}
+
+var (
+	DefaultLoadSheddingConfig = LoadSheddingConfig{
+		Interval:          2 * time.Second,
+		HighCPULoad:       0.9,
+		LowCPULoad:        0.7,
+		HighFrameLatency:  2 * time.Millisecond,
+		LowFrameLatency:   time.Millisecond,
+		Step:              0.1,
+		RecoveryIntervals: 5,
+	}
+)
+
+// LoadShedder decides per interval how many tracks are shed or restored. Between the high and the low
+// thresholds nothing changes, so that the node does not flap around a single threshold. Not safe for concurrent use.
+type LoadShedder struct {
+	config LoadSheddingConfig
+	calm   int
+}
+
+func NewLoadShedder(config LoadSheddingConfig) *LoadShedder {
+	if config.Step <= 0 {
+		config.Step = DefaultLoadSheddingConfig.Step
+	}
+	return &LoadShedder{config: config}
+}
+
+// Observe takes the load of an interval, the number of tracks still filtered and the number of shed ones.
+// Returns how many tracks to shed, or with a negative count how many to restore.
+func (s *LoadShedder) Observe(cpuLoad float64, frameLatency time.Duration, filtered int, shed int) int {
+	latencyAware := s.config.HighFrameLatency > 0
+	if cpuLoad > s.config.HighCPULoad || (latencyAware && frameLatency > s.config.HighFrameLatency) {
+		s.calm = 0
+		if filtered == 0 {
+			return 0
+		}
+		return min(s.step(filtered+shed), filtered)
+	}
+
+	if cpuLoad >= s.config.LowCPULoad || (latencyAware && frameLatency >= s.config.LowFrameLatency) || shed == 0 {
+		s.calm = 0
+		return 0
+	}
+
+	s.calm++
+	if s.calm < s.config.RecoveryIntervals {
+		return 0
+	}
+	s.calm = 0
+	return -min(s.step(filtered+shed), shed)
+}
+
+func (s *LoadShedder) step(tracks int) int {
+	return max(int(math.Ceil(float64(tracks)*s.config.Step)), 1)
+}
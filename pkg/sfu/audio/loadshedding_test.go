@@ -0,0 +1,80 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShedder(t *testing.T) {
+	config := DefaultLoadSheddingConfig
+
+	t.Run("sheds a step per interval while overloaded", func(t *testing.T) {
+		s := NewLoadShedder(config)
+		filtered, shed := 25, 0
+		for i := 0; i < 3; i++ {
+			n := s.Observe(0.95, 0, filtered, shed)
+			require.Equal(t, 3, n)
+			filtered, shed = filtered-n, shed+n
+		}
+
+		// frame latency alone overloads the node
+		require.Equal(t, 3, s.Observe(0.5, 3*time.Millisecond, filtered, shed))
+		// never more than is filtered
+		require.Equal(t, 1, s.Observe(0.95, 0, 1, 24))
+		require.Zero(t, s.Observe(0.95, 0, 0, 25))
+	})
+
+	t.Run("restores after the load stayed low", func(t *testing.T) {
+		s := NewLoadShedder(config)
+		for i := 1; i < config.RecoveryIntervals; i++ {
+			require.Zero(t, s.Observe(0.5, 500*time.Microsecond, 20, 5))
+		}
+		require.Equal(t, -3, s.Observe(0.5, 500*time.Microsecond, 20, 5))
+
+		// the count starts over after a step
+		require.Zero(t, s.Observe(0.5, 0, 23, 2))
+		for i := 2; i < config.RecoveryIntervals; i++ {
+			require.Zero(t, s.Observe(0.5, 0, 23, 2))
+		}
+		require.Equal(t, -2, s.Observe(0.5, 0, 23, 2))
+	})
+
+	t.Run("holds between the thresholds", func(t *testing.T) {
+		s := NewLoadShedder(config)
+		for i := 0; i < 2*config.RecoveryIntervals; i++ {
+			require.Zero(t, s.Observe(0.8, 0, 20, 5))
+			require.Zero(t, s.Observe(0.5, 1500*time.Microsecond, 20, 5))
+		}
+
+		// a spike resets the recovery
+		for i := 1; i < config.RecoveryIntervals; i++ {
+			require.Zero(t, s.Observe(0.5, 0, 20, 5))
+		}
+		require.Equal(t, 3, s.Observe(0.95, 0, 20, 5))
+		require.Zero(t, s.Observe(0.5, 0, 17, 8))
+	})
+
+	t.Run("ignores the latency when not configured", func(t *testing.T) {
+		config := config
+		config.HighFrameLatency = 0
+		s := NewLoadShedder(config)
+		require.Zero(t, s.Observe(0.5, time.Second, 20, 0))
+	})
+}
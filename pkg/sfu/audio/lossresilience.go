@@ -0,0 +1,203 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+// LossResilienceMode is how a subscriber recovers from lost audio packets
+type LossResilienceMode int
+
+const (
+	// lost packets are retransmitted on NACK
+	LossResilienceModeNACK LossResilienceMode = iota
+	// lost packets are concealed with the Opus in-band FEC of the next packet, no retransmission
+	LossResilienceModeFEC
+	// every packet carries the previous ones redundantly (RFC 2198), no retransmission
+	LossResilienceModeRED
+)
+
+func (m LossResilienceMode) String() string {
+	switch m {
+	case LossResilienceModeNACK:
+		return "nack"
+	case LossResilienceModeFEC:
+		return "fec"
+	case LossResilienceModeRED:
+		return "red"
+	default:
+		return "unknown"
+	}
+}
+
+// LossResilienceConfig picks retransmission or forward error correction per audio subscriber. A retransmitted
+// packet arrives one round trip late, which is fine on short paths and useless on long ones
+type LossResilienceConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how often the mode is re-evaluated
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// retransmission is given up for forward error correction above this round trip time
+	HighRTT time.Duration `yaml:"high_rtt,omitempty"`
+	// retransmission is used again below this round trip time
+	LowRTT time.Duration `yaml:"low_rtt,omitempty"`
+	// RED is preferred over in-band FEC above this packet loss, as FEC only recovers isolated losses
+	REDLoss float64 `yaml:"red_loss,omitempty"`
+	// RED is preferred over in-band FEC above this average number of packets lost in a row
+	REDBurst float64 `yaml:"red_burst,omitempty"`
+	// evaluations in a row a new mode has to be chosen before switching to it
+	Hysteresis int `yaml:"hysteresis,omitempty"`
+}
+
+var (
+	DefaultLossResilienceConfig = LossResilienceConfig{
+		Interval:   5 * time.Second,
+		HighRTT:    150 * time.Millisecond,
+		LowRTT:     100 * time.Millisecond,
+		REDLoss:    0.05,
+		REDBurst:   1.5,
+		Hysteresis: 2,
+	}
+)
+
+// LossResilienceStats is the state of a LossResilienceSelector as of its last evaluation
+type LossResilienceStats struct {
+	Mode     LossResilienceMode
+	RTT      time.Duration
+	Loss     float64
+	Burst    float64
+	Switches int
+}
+
+// LossResilienceSelector chooses the LossResilienceMode of a subscriber from the round trip time and the
+// loss pattern it reports. FEC and RED are only chosen when negotiated with the subscriber.
+// Not safe for concurrent use.
+type LossResilienceSelector struct {
+	config       LossResilienceConfig
+	fecAvailable bool
+	redAvailable bool
+
+	mode             LossResilienceMode
+	pending          LossResilienceMode
+	pendingIntervals int
+	lastEvaluation   time.Time
+
+	rtt    time.Duration
+	loss   float64
+	lost   int
+	bursts int
+
+	stats LossResilienceStats
+}
+
+func NewLossResilienceSelector(config LossResilienceConfig, fecAvailable bool, redAvailable bool, now time.Time) *LossResilienceSelector {
+	if config.Interval <= 0 {
+		config.Interval = DefaultLossResilienceConfig.Interval
+	}
+	if config.LowRTT > config.HighRTT {
+		config.LowRTT = config.HighRTT
+	}
+	return &LossResilienceSelector{
+		config:         config,
+		fecAvailable:   fecAvailable,
+		redAvailable:   redAvailable,
+		lastEvaluation: now,
+	}
+}
+
+// ObserveReceiverReport takes the round trip time in milliseconds, 0 when it could not be measured,
+// and the fraction lost of a receiver report
+func (s *LossResilienceSelector) ObserveReceiverReport(rttMs uint32, fractionLost uint8) {
+	if rttMs != 0 {
+		s.rtt = time.Duration(rttMs) * time.Millisecond
+	}
+	s.loss = max(s.loss, float64(fractionLost)/256)
+}
+
+// ObserveNACKs takes the sequence numbers of a NACK, in order, to learn how bursty the losses are
+func (s *LossResilienceSelector) ObserveNACKs(sequenceNumbers []uint16) {
+	for i, sn := range sequenceNumbers {
+		if i == 0 || sn != sequenceNumbers[i-1]+1 {
+			s.bursts++
+		}
+		s.lost++
+	}
+}
+
+// Evaluate picks the mode once per interval from what was observed since the last evaluation.
+// Returns the mode and whether it changed.
+func (s *LossResilienceSelector) Evaluate(now time.Time) (LossResilienceMode, bool) {
+	if now.Sub(s.lastEvaluation) < s.config.Interval {
+		return s.mode, false
+	}
+	s.lastEvaluation = now
+
+	burst := 0.0
+	if s.bursts != 0 {
+		burst = float64(s.lost) / float64(s.bursts)
+	}
+	desired := s.desired(s.loss, burst)
+	s.stats.RTT = s.rtt
+	s.stats.Loss = s.loss
+	s.stats.Burst = burst
+	s.loss, s.lost, s.bursts = 0, 0, 0
+
+	if desired == s.mode {
+		s.pendingIntervals = 0
+		return s.mode, false
+	}
+	if desired != s.pending {
+		s.pending = desired
+		s.pendingIntervals = 0
+	}
+	s.pendingIntervals++
+	if s.pendingIntervals < s.config.Hysteresis {
+		return s.mode, false
+	}
+
+	s.mode = desired
+	s.pendingIntervals = 0
+	s.stats.Switches++
+	return s.mode, true
+}
+
+func (s *LossResilienceSelector) desired(loss float64, burst float64) LossResilienceMode {
+	if s.rtt == 0 {
+		return s.mode
+	}
+
+	longPath := s.rtt > s.config.HighRTT || (s.mode != LossResilienceModeNACK && s.rtt >= s.config.LowRTT)
+	switch {
+	case !longPath:
+		return LossResilienceModeNACK
+	case s.redAvailable && (!s.fecAvailable || loss > s.config.REDLoss || burst > s.config.REDBurst):
+		return LossResilienceModeRED
+	case s.fecAvailable:
+		return LossResilienceModeFEC
+	default:
+		return LossResilienceModeNACK
+	}
+}
+
+func (s *LossResilienceSelector) Mode() LossResilienceMode {
+	return s.mode
+}
+
+func (s *LossResilienceSelector) Stats() LossResilienceStats {
+	stats := s.stats
+	stats.Mode = s.mode
+	return stats
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLossResilienceSelector(t *testing.T) {
+	config := DefaultLossResilienceConfig
+	start := time.Now()
+
+	// runs one interval per report and returns the mode after it
+	run := func(s *LossResilienceSelector, intervals int, rttMs uint32, fractionLost uint8, nacks []uint16) LossResilienceMode {
+		for i := 0; i < intervals; i++ {
+			s.ObserveReceiverReport(rttMs, fractionLost)
+			if nacks != nil {
+				s.ObserveNACKs(nacks)
+			}
+			start = start.Add(config.Interval)
+			s.Evaluate(start)
+		}
+		return s.Mode()
+	}
+
+	t.Run("retransmits on short paths", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, true, true, start)
+		require.Equal(t, LossResilienceModeNACK, run(s, 5, 40, 20, nil))
+		require.Zero(t, s.Stats().Switches)
+	})
+
+	t.Run("uses FEC on long paths with isolated losses", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, true, true, start)
+		// one interval is not enough to switch
+		require.Equal(t, LossResilienceModeNACK, run(s, 1, 250, 5, []uint16{10, 20, 30}))
+		require.Equal(t, LossResilienceModeFEC, run(s, 1, 250, 5, []uint16{10, 20, 30}))
+
+		stats := s.Stats()
+		require.Equal(t, LossResilienceModeFEC, stats.Mode)
+		require.Equal(t, 250*time.Millisecond, stats.RTT)
+		require.Equal(t, 1.0, stats.Burst)
+		require.Equal(t, 1, stats.Switches)
+	})
+
+	t.Run("uses RED on long paths with bursty losses", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, true, true, start)
+		require.Equal(t, LossResilienceModeRED, run(s, 2, 250, 5, []uint16{10, 11, 12, 30, 31}))
+
+		s = NewLossResilienceSelector(config, true, true, start)
+		require.Equal(t, LossResilienceModeRED, run(s, 2, 250, 40, nil))
+	})
+
+	t.Run("only uses what is negotiated", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, false, true, start)
+		require.Equal(t, LossResilienceModeRED, run(s, 2, 250, 0, nil))
+
+		s = NewLossResilienceSelector(config, true, false, start)
+		require.Equal(t, LossResilienceModeFEC, run(s, 2, 250, 40, nil))
+
+		s = NewLossResilienceSelector(config, false, false, start)
+		require.Equal(t, LossResilienceModeNACK, run(s, 2, 250, 40, nil))
+	})
+
+	t.Run("returns to NACK below the low RTT only", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, true, false, start)
+		require.Equal(t, LossResilienceModeFEC, run(s, 2, 250, 0, nil))
+		require.Equal(t, LossResilienceModeFEC, run(s, 5, 120, 0, nil))
+		require.Equal(t, LossResilienceModeNACK, run(s, 2, 60, 0, nil))
+	})
+
+	t.Run("keeps the mode without RTT", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, true, false, start)
+		require.Equal(t, LossResilienceModeNACK, run(s, 5, 0, 40, nil))
+	})
+
+	t.Run("evaluates once per interval", func(t *testing.T) {
+		s := NewLossResilienceSelector(config, true, false, start)
+		for i := 0; i < 10; i++ {
+			s.ObserveReceiverReport(250, 0)
+			mode, changed := s.Evaluate(start.Add(time.Second))
+			require.Equal(t, LossResilienceModeNACK, mode)
+			require.False(t, changed)
+		}
+	})
+}
@@ -0,0 +1,252 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"slices"
+)
+
+const (
+	// loudness of silence, reported when no block passes the absolute gate
+	MinLoudness = -70.0
+
+	// EBU R128 block durations in 100 ms steps, the hop of both block kinds
+	loudnessStepsPerSecond = 10
+	momentaryBlockSteps    = 4
+	shortTermBlockSteps    = 30
+	// gates of ITU-R BS.1770-4 and EBU Tech 3342
+	loudnessAbsoluteGate      = -70.0
+	loudnessRelativeGate      = -10.0
+	loudnessRangeRelativeGate = -20.0
+)
+
+// LoudnessConfig controls normalizing recorded audio to a target integrated loudness following EBU R128
+type LoudnessConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// integrated loudness recordings are normalized to, in LUFS
+	Target float64 `yaml:"target,omitempty"`
+	// level the sample peak of a recording is kept below, limiting the gain, in dBFS
+	MaxPeak float64 `yaml:"max_peak,omitempty"`
+	// largest gain applied, keeps recordings of mostly silence from amplifying their noise
+	MaxGain float64 `yaml:"max_gain,omitempty"`
+}
+
+var (
+	DefaultLoudnessConfig = LoudnessConfig{
+		Target:  -23,
+		MaxPeak: -1,
+		MaxGain: 20,
+	}
+)
+
+// LoudnessStats is the loudness of audio as measured following EBU R128
+type LoudnessStats struct {
+	// integrated loudness, in LUFS
+	Integrated float64 `json:"integrated_lufs"`
+	// loudness range, in LU
+	Range float64 `json:"range_lu"`
+	// sample peak, in dBFS
+	Peak float64 `json:"peak_dbfs"`
+}
+
+// Gain returns the gain in dB bringing the integrated loudness to the target of config, limited by its peak and
+// gain limits. Audio that is silent is not changed.
+func (s LoudnessStats) Gain(config LoudnessConfig) float64 {
+	if s.Integrated <= MinLoudness {
+		return 0
+	}
+	gain := config.Target - s.Integrated
+	if config.MaxGain > 0 {
+		gain = min(gain, config.MaxGain)
+	}
+	return min(gain, config.MaxPeak-s.Peak)
+}
+
+// --------------------------------------
+
+// kWeighting is the K-weighting filter of ITU-R BS.1770-4, a high shelf modeling the head followed by
+// the RLB high pass, designed for any sample rate
+type kWeighting struct {
+	b [2][3]float64
+	a [2][2]float64
+	z [2][2]float64
+}
+
+func newKWeighting(sampleRate int) kWeighting {
+	var k kWeighting
+
+	// high shelf, +4 dB above about 1.5 kHz
+	const shelfFrequency = 1681.974450955533
+	const shelfGain = 3.999843853973347
+	const shelfQ = 0.7071752369554196
+	kk := math.Tan(math.Pi * shelfFrequency / float64(sampleRate))
+	vh := math.Pow(10, shelfGain/20)
+	vb := math.Pow(vh, 0.4996667741545416)
+	a0 := 1 + kk/shelfQ + kk*kk
+	k.b[0] = [3]float64{(vh + vb*kk/shelfQ + kk*kk) / a0, 2 * (kk*kk - vh) / a0, (vh - vb*kk/shelfQ + kk*kk) / a0}
+	k.a[0] = [2]float64{2 * (kk*kk - 1) / a0, (1 - kk/shelfQ + kk*kk) / a0}
+
+	// high pass at about 38 Hz
+	const highpassFrequency = 38.13547087602444
+	const highpassQ = 0.5003270373238773
+	kk = math.Tan(math.Pi * highpassFrequency / float64(sampleRate))
+	a0 = 1 + kk/highpassQ + kk*kk
+	k.b[1] = [3]float64{1, -2, 1}
+	k.a[1] = [2]float64{2 * (kk*kk - 1) / a0, (1 - kk/highpassQ + kk*kk) / a0}
+	return k
+}
+
+func (k *kWeighting) process(x float64) float64 {
+	for i := range k.b {
+		y := k.b[i][0]*x + k.z[i][0]
+		k.z[i][0] = k.b[i][1]*x - k.a[i][0]*y + k.z[i][1]
+		k.z[i][1] = k.b[i][2]*x - k.a[i][1]*y
+		x = y
+	}
+	return x
+}
+
+// LoudnessMeter measures the integrated loudness, loudness range and sample peak of 16 bit PCM
+// following EBU R128. Not safe for concurrent use.
+type LoudnessMeter struct {
+	channels []kWeighting
+	stepSize int
+
+	// K-weighted energy of the current step, summed over channels, and its samples per channel
+	energy  float64
+	samples int
+	// mean K-weighted energy of every completed 100 ms step
+	steps []float64
+	peak  int32
+}
+
+func NewLoudnessMeter(sampleRate int, channels int) *LoudnessMeter {
+	channels = max(channels, 1)
+	m := &LoudnessMeter{
+		channels: make([]kWeighting, channels),
+		stepSize: max(sampleRate/loudnessStepsPerSecond, 1),
+	}
+	for i := range m.channels {
+		m.channels[i] = newKWeighting(sampleRate)
+	}
+	return m
+}
+
+// Write measures interleaved samples
+func (m *LoudnessMeter) Write(pcm []int16) {
+	channels := len(m.channels)
+	for i := 0; i+channels <= len(pcm); i += channels {
+		for c := range m.channels {
+			sample := int32(pcm[i+c])
+			m.peak = max(m.peak, sample, -sample)
+			y := m.channels[c].process(float64(sample) / 32768)
+			m.energy += y * y
+		}
+		m.samples++
+		if m.samples == m.stepSize {
+			m.steps = append(m.steps, m.energy/float64(m.stepSize))
+			m.energy = 0
+			m.samples = 0
+		}
+	}
+}
+
+// Stats returns the loudness of the audio measured so far, a partial trailing 100 ms step is not included
+func (m *LoudnessMeter) Stats() LoudnessStats {
+	peak := MinLoudness
+	if m.peak > 0 {
+		peak = 20 * math.Log10(float64(m.peak)/32768)
+	}
+	return LoudnessStats{
+		Integrated: m.integrated(),
+		Range:      m.loudnessRange(),
+		Peak:       peak,
+	}
+}
+
+func (m *LoudnessMeter) integrated() float64 {
+	blocks := m.blocks(momentaryBlockSteps)
+	gated := gateBlocks(blocks, loudnessAbsoluteGate)
+	if len(gated) == 0 {
+		return MinLoudness
+	}
+	gated = gateBlocks(gated, energyToLoudness(meanEnergy(gated))+loudnessRelativeGate)
+	if len(gated) == 0 {
+		return MinLoudness
+	}
+	return max(energyToLoudness(meanEnergy(gated)), MinLoudness)
+}
+
+func (m *LoudnessMeter) loudnessRange() float64 {
+	blocks := gateBlocks(m.blocks(shortTermBlockSteps), loudnessAbsoluteGate)
+	if len(blocks) == 0 {
+		return 0
+	}
+	blocks = gateBlocks(blocks, energyToLoudness(meanEnergy(blocks))+loudnessRangeRelativeGate)
+	if len(blocks) == 0 {
+		return 0
+	}
+	slices.Sort(blocks)
+	low := blocks[int(math.Round(0.1*float64(len(blocks)-1)))]
+	high := blocks[int(math.Round(0.95*float64(len(blocks)-1)))]
+	return energyToLoudness(high) - energyToLoudness(low)
+}
+
+// blocks returns the mean energy of every block of the given number of steps, with a hop of one step
+func (m *LoudnessMeter) blocks(size int) []float64 {
+	if len(m.steps) < size {
+		return nil
+	}
+	blocks := make([]float64, 0, len(m.steps)-size+1)
+	var sum float64
+	for i, step := range m.steps {
+		sum += step
+		if i >= size {
+			sum -= m.steps[i-size]
+		}
+		if i >= size-1 {
+			blocks = append(blocks, max(sum, 0)/float64(size))
+		}
+	}
+	return blocks
+}
+
+// gateBlocks returns the block energies above the gate in LUFS
+func gateBlocks(blocks []float64, gate float64) []float64 {
+	var gated []float64
+	for _, energy := range blocks {
+		if energyToLoudness(energy) > gate {
+			gated = append(gated, energy)
+		}
+	}
+	return gated
+}
+
+func energyToLoudness(energy float64) float64 {
+	if energy <= 0 {
+		return math.Inf(-1)
+	}
+	return -0.691 + 10*math.Log10(energy)
+}
+
+func meanEnergy(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
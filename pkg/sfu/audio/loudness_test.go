@@ -0,0 +1,125 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// sine returns seconds of a mono sine at 48 kHz with the peak amplitude, 0.0-1.0
+func sine(frequency float64, amplitude float64, seconds float64) []int16 {
+	pcm := make([]int16, int(seconds*OpusSampleRate))
+	for i := range pcm {
+		pcm[i] = int16(math.Round(amplitude * 32767 * math.Sin(2*math.Pi*frequency*float64(i)/OpusSampleRate)))
+	}
+	return pcm
+}
+
+func TestLoudnessMeter(t *testing.T) {
+	t.Run("sine", func(t *testing.T) {
+		// a 997 Hz sine at full scale measures -3.01 LUFS
+		m := NewLoudnessMeter(OpusSampleRate, 1)
+		m.Write(sine(997, 0.5, 5))
+		stats := m.Stats()
+		require.InDelta(t, -9.03, stats.Integrated, 0.1)
+		require.InDelta(t, 0, stats.Range, 0.1)
+		require.InDelta(t, -6.02, stats.Peak, 0.01)
+	})
+
+	t.Run("stereo", func(t *testing.T) {
+		// channels add up, two channels of the same sine measure 3 LU louder
+		mono := sine(997, 0.5, 5)
+		stereo := make([]int16, 2*len(mono))
+		for i, s := range mono {
+			stereo[2*i], stereo[2*i+1] = s, s
+		}
+		m := NewLoudnessMeter(OpusSampleRate, 2)
+		m.Write(stereo)
+		require.InDelta(t, -6.02, m.Stats().Integrated, 0.1)
+	})
+
+	t.Run("gated", func(t *testing.T) {
+		// silence is gated
+		m := NewLoudnessMeter(OpusSampleRate, 1)
+		m.Write(sine(997, 0.5, 10))
+		m.Write(make([]int16, 10*OpusSampleRate))
+		m.Write(sine(997, 0.2, 10))
+		require.InDelta(t, -11.4, m.Stats().Integrated, 0.2)
+
+		// loud and quiet passages make up the range
+		m = NewLoudnessMeter(OpusSampleRate, 1)
+		m.Write(sine(997, 0.5, 10))
+		m.Write(sine(997, 0.2, 10))
+		require.InDelta(t, 7.96, m.Stats().Range, 0.2)
+
+		// passages more than 10 LU below the rest are gated
+		m = NewLoudnessMeter(OpusSampleRate, 1)
+		m.Write(sine(997, 0.5, 10))
+		m.Write(sine(997, 0.05, 10))
+		require.InDelta(t, -9.03, m.Stats().Integrated, 0.1)
+	})
+
+	t.Run("silence", func(t *testing.T) {
+		m := NewLoudnessMeter(OpusSampleRate, 1)
+		m.Write(make([]int16, OpusSampleRate))
+		stats := m.Stats()
+		require.Equal(t, MinLoudness, stats.Integrated)
+		require.Equal(t, MinLoudness, stats.Peak)
+		require.Zero(t, stats.Gain(DefaultLoudnessConfig))
+	})
+}
+
+func TestLoudnessStats_Gain(t *testing.T) {
+	config := DefaultLoudnessConfig
+	// to the target
+	require.InDelta(t, -7, LoudnessStats{Integrated: -16, Peak: -3}.Gain(config), 1e-9)
+	require.InDelta(t, 5, LoudnessStats{Integrated: -28, Peak: -10}.Gain(config), 1e-9)
+	// limited by the peak
+	require.InDelta(t, 3, LoudnessStats{Integrated: -28, Peak: -4}.Gain(config), 1e-9)
+	// limited by the largest gain
+	require.InDelta(t, 20, LoudnessStats{Integrated: -60, Peak: -40}.Gain(config), 1e-9)
+}
+
+func TestScaleWAV(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "scaled.wav"))
+	require.NoError(t, err)
+	defer f.Close()
+
+	w, err := NewWAVWriter(f, OpusSampleRate, 1)
+	require.NoError(t, err)
+	// more than one read of samples
+	pcm := sine(440, 0.5, 1)
+	require.NoError(t, w.Write(pcm))
+	require.NoError(t, w.Close())
+
+	require.NoError(t, ScaleWAV(f, 0.5))
+
+	_, err = f.Seek(0, io.SeekStart)
+	require.NoError(t, err)
+	data, err := io.ReadAll(f)
+	require.NoError(t, err)
+	require.Len(t, data, wavHeaderSize+2*len(pcm))
+	for i, s := range pcm {
+		scaled := int16(uint16(data[wavHeaderSize+2*i]) | uint16(data[wavHeaderSize+2*i+1])<<8)
+		require.InDelta(t, float64(s)/2, float64(scaled), 0.5)
+	}
+}
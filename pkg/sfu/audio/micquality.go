@@ -0,0 +1,166 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	// samples at or above this magnitude are counted as clipped
+	clippingLevel = 32000
+	// Opus packets of this size or smaller are DTX / comfort noise
+	opusDTXPacketSize = 2
+)
+
+type MicQualityConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// amount of voiced audio evaluated at a time
+	Window time.Duration `yaml:"window,omitempty"`
+	// fraction of clipped samples above which the microphone is flagged as clipping
+	ClippingRatio float64 `yaml:"clipping_ratio,omitempty"`
+	// fraction of voiced frames coded narrow/medium band above which the track is flagged as telephone band
+	NarrowbandRatio float64 `yaml:"narrowband_ratio,omitempty"`
+	// average bitrate of voiced audio, in bps, below which the track is flagged as heavily compressed
+	MinBitrate int `yaml:"min_bitrate,omitempty"`
+}
+
+var (
+	DefaultMicQualityConfig = MicQualityConfig{
+		Window:          10 * time.Second,
+		ClippingRatio:   0.001,
+		NarrowbandRatio: 0.8,
+		MinBitrate:      12000,
+	}
+)
+
+type MicQualityIssue string
+
+const (
+	MicQualityIssueClipping   MicQualityIssue = "clipping"
+	MicQualityIssueNarrowband MicQualityIssue = "narrowband"
+	MicQualityIssueLowBitrate MicQualityIssue = "low_bitrate"
+)
+
+type MicQualityReport struct {
+	Issues          []MicQualityIssue `json:"issues"`
+	ClippingRatio   float64           `json:"clipping_ratio"`
+	NarrowbandRatio float64           `json:"narrowband_ratio"`
+	Bitrate         int               `json:"bitrate"`
+}
+
+// --------------------------------------
+
+// MicQualityAnalyzer looks at the Opus packets, and optionally the decoded PCM, of a published track
+// for signs of a poor microphone or capture path. Only voiced audio is evaluated, DTX periods are skipped.
+type MicQualityAnalyzer struct {
+	config MicQualityConfig
+
+	lock             sync.Mutex
+	voicedDuration   time.Duration
+	voicedBytes      int
+	voicedFrames     int
+	narrowbandFrames int
+	samples          int
+	clippedSamples   int
+	lastIssues       []MicQualityIssue
+}
+
+func NewMicQualityAnalyzer(config MicQualityConfig) *MicQualityAnalyzer {
+	if config.Window <= 0 {
+		config.Window = DefaultMicQualityConfig.Window
+	}
+	return &MicQualityAnalyzer{config: config}
+}
+
+// ObservePCM counts clipped samples of decoded audio, call before ObservePacket for the same packet
+func (m *MicQualityAnalyzer) ObservePCM(pcm []int16) {
+	clipped := 0
+	for _, s := range pcm {
+		if s >= clippingLevel || s <= -clippingLevel {
+			clipped++
+		}
+	}
+
+	m.lock.Lock()
+	m.samples += len(pcm)
+	m.clippedSamples += clipped
+	m.lock.Unlock()
+}
+
+// ObservePacket accounts an Opus packet. At the end of each window it returns a report
+// if the set of detected issues changed since the previous window, nil otherwise.
+func (m *MicQualityAnalyzer) ObservePacket(payload []byte) *MicQualityReport {
+	if IsOpusDTX(payload) {
+		return nil
+	}
+
+	toc, ok := ParseOpusTOC(payload)
+	if !ok {
+		return nil
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.voicedDuration += toc.Duration()
+	m.voicedBytes += len(payload)
+	m.voicedFrames += toc.Frames
+	if toc.Bandwidth <= OpusBandwidthMediumband {
+		m.narrowbandFrames += toc.Frames
+	}
+
+	if m.voicedDuration < m.config.Window {
+		return nil
+	}
+
+	report := m.evaluateLocked()
+	if slices.Equal(report.Issues, m.lastIssues) {
+		return nil
+	}
+	m.lastIssues = report.Issues
+	return report
+}
+
+func (m *MicQualityAnalyzer) evaluateLocked() *MicQualityReport {
+	report := &MicQualityReport{
+		NarrowbandRatio: float64(m.narrowbandFrames) / float64(m.voicedFrames),
+		Bitrate:         int(float64(m.voicedBytes*8) / m.voicedDuration.Seconds()),
+	}
+	if m.samples > 0 {
+		report.ClippingRatio = float64(m.clippedSamples) / float64(m.samples)
+	}
+
+	if m.samples > 0 && m.config.ClippingRatio > 0 && report.ClippingRatio > m.config.ClippingRatio {
+		report.Issues = append(report.Issues, MicQualityIssueClipping)
+	}
+	if m.config.NarrowbandRatio > 0 && report.NarrowbandRatio > m.config.NarrowbandRatio {
+		report.Issues = append(report.Issues, MicQualityIssueNarrowband)
+	}
+	if m.config.MinBitrate > 0 && report.Bitrate < m.config.MinBitrate {
+		report.Issues = append(report.Issues, MicQualityIssueLowBitrate)
+	}
+
+	m.voicedDuration = 0
+	m.voicedBytes = 0
+	m.voicedFrames = 0
+	m.narrowbandFrames = 0
+	m.samples = 0
+	m.clippedSamples = 0
+	return report
+}
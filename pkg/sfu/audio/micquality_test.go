@@ -0,0 +1,110 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// opusPacket returns a single frame packet of the given TOC config and size
+func opusPacket(config uint8, size int) []byte {
+	payload := make([]byte, size)
+	payload[0] = config << 3
+	return payload
+}
+
+func TestParseOpusTOC(t *testing.T) {
+	toc, ok := ParseOpusTOC(opusPacket(1, 10))
+	require.True(t, ok)
+	require.Equal(t, OpusBandwidthNarrowband, toc.Bandwidth)
+	require.Equal(t, 20*time.Millisecond, toc.Duration())
+
+	// hybrid fullband 20 ms, two frames
+	toc, ok = ParseOpusTOC([]byte{15<<3 | 0x1, 0, 0})
+	require.True(t, ok)
+	require.Equal(t, OpusBandwidthFullband, toc.Bandwidth)
+	require.Equal(t, 40*time.Millisecond, toc.Duration())
+
+	// CELT wideband 2.5 ms, arbitrary number of frames
+	toc, ok = ParseOpusTOC([]byte{20<<3 | 0x4 | 0x3, 4, 0})
+	require.True(t, ok)
+	require.Equal(t, OpusBandwidthWideband, toc.Bandwidth)
+	require.True(t, toc.Stereo)
+	require.Equal(t, 10*time.Millisecond, toc.Duration())
+
+	_, ok = ParseOpusTOC([]byte{0x3})
+	require.False(t, ok)
+	_, ok = ParseOpusTOC(nil)
+	require.False(t, ok)
+}
+
+func TestIsOpusDTX(t *testing.T) {
+	require.True(t, IsOpusDTX(nil))
+	require.True(t, IsOpusDTX([]byte{1 << 3}))
+	require.True(t, IsOpusDTX([]byte{15<<3 | 0x3, 1}))
+	require.False(t, IsOpusDTX(opusPacket(1, 10)))
+}
+
+func TestMicQualityAnalyzer(t *testing.T) {
+	config := DefaultMicQualityConfig
+	config.Window = time.Second
+
+	t.Run("good microphone", func(t *testing.T) {
+		m := NewMicQualityAnalyzer(config)
+		for i := 0; i < 50; i++ {
+			m.ObservePCM(make([]int16, 960))
+			// fullband hybrid 20 ms at 32 kbps
+			require.Nil(t, m.ObservePacket(opusPacket(15, 80)))
+		}
+	})
+
+	t.Run("telephone band and clipping", func(t *testing.T) {
+		m := NewMicQualityAnalyzer(config)
+		clipped := make([]int16, 960)
+		for i := range clipped {
+			clipped[i] = 32767
+		}
+
+		var report *MicQualityReport
+		for i := 0; i < 50; i++ {
+			m.ObservePCM(clipped)
+			// DTX packets are skipped
+			require.Nil(t, m.ObservePacket([]byte{1 << 3, 0}))
+			report = m.ObservePacket(opusPacket(1, 40))
+		}
+		require.NotNil(t, report)
+		require.Equal(t, []MicQualityIssue{MicQualityIssueClipping, MicQualityIssueNarrowband}, report.Issues)
+		require.Equal(t, 16000, report.Bitrate)
+		require.Equal(t, 1.0, report.NarrowbandRatio)
+
+		// same issues in the next window are not reported again
+		for i := 0; i < 50; i++ {
+			m.ObservePCM(clipped)
+			require.Nil(t, m.ObservePacket(opusPacket(1, 40)))
+		}
+
+		// recovery is reported
+		for i := 0; i < 50; i++ {
+			m.ObservePCM(make([]int16, 960))
+			report = m.ObservePacket(opusPacket(15, 80))
+		}
+		require.NotNil(t, report)
+		require.Empty(t, report.Issues)
+	})
+}
@@ -0,0 +1,169 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// MixActivityConfig controls the activity sidecar of mixed audio, the level and voice activity of every
+// publisher contributing to a mix, so that consumers of the mix can attribute speech to participants
+// like with the CSRC list and audio levels (RFC 6465) of an RTP mixer
+type MixActivityConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// mix time covered by one report
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// dBFS a contribution needs to be active, i. e. for its publisher to count as speaking in the mix
+	MinLevel float64 `yaml:"min_level,omitempty"`
+}
+
+var (
+	DefaultMixActivityConfig = MixActivityConfig{
+		Interval: 200 * time.Millisecond,
+		MinLevel: -50,
+	}
+)
+
+// ContributorActivity is the contribution of one source to the mix over a report
+type ContributorActivity struct {
+	ID string
+	// dBFS of the mean energy of the contribution, after its mix gain
+	Level float64
+	// share of the frames of the report the contribution was active in, 0 to 1
+	Activity float64
+}
+
+// MixActivityReport covers Duration of the mix starting at Start, on the timeline of the mix
+type MixActivityReport struct {
+	Start    time.Duration
+	Duration time.Duration
+	// sources that contributed to at least one frame, loudest first
+	Contributors []ContributorActivity
+}
+
+// Energies returns the mean energy of every contribution to the frame, normalized to full scale
+func (f *MixedFrame) Energies() map[string]float64 {
+	energies := make(map[string]float64, len(f.contributions))
+	for id, frame := range f.contributions {
+		energies[id] = meanSquare(frame)
+	}
+	return energies
+}
+
+func meanSquare(pcm []int16) float64 {
+	if len(pcm) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range pcm {
+		v := float64(s) / 32768
+		sum += v * v
+	}
+	return sum / float64(len(pcm))
+}
+
+type contributorStats struct {
+	energy       float64
+	activeFrames int
+}
+
+// MixActivityTracker accumulates the contributions to the frames of one mix into reports of Interval.
+// Not safe for concurrent use.
+type MixActivityTracker struct {
+	config        MixActivityConfig
+	frameDuration time.Duration
+	minEnergy     float64
+
+	// mix time of the next frame
+	timestamp   time.Duration
+	reportStart time.Duration
+	frames      int
+	stats       map[string]*contributorStats
+}
+
+func NewMixActivityTracker(config MixActivityConfig, frameDuration time.Duration) *MixActivityTracker {
+	if config.Interval < frameDuration {
+		config.Interval = frameDuration
+	}
+	return &MixActivityTracker{
+		config:        config,
+		frameDuration: frameDuration,
+		minEnergy:     math.Pow(10, config.MinLevel/10),
+		stats:         make(map[string]*contributorStats),
+	}
+}
+
+// Observe accounts one mixed frame with the energies of its contributions, see MixedFrame.Energies,
+// of which only those included are part of this mix. Returns a report once Interval of mix time is covered.
+func (t *MixActivityTracker) Observe(energies map[string]float64, include func(id string) bool) *MixActivityReport {
+	for id, energy := range energies {
+		if include != nil && !include(id) {
+			continue
+		}
+
+		s, ok := t.stats[id]
+		if !ok {
+			s = &contributorStats{}
+			t.stats[id] = s
+		}
+		s.energy += energy
+		if energy >= t.minEnergy {
+			s.activeFrames++
+		}
+	}
+	t.frames++
+	t.timestamp += t.frameDuration
+
+	if t.timestamp-t.reportStart < t.config.Interval {
+		return nil
+	}
+	return t.report()
+}
+
+func (t *MixActivityTracker) report() *MixActivityReport {
+	report := &MixActivityReport{
+		Start:        t.reportStart,
+		Duration:     t.timestamp - t.reportStart,
+		Contributors: make([]ContributorActivity, 0, len(t.stats)),
+	}
+	for id, s := range t.stats {
+		level := float64(minDBFS)
+		if energy := s.energy / float64(t.frames); energy > 0 {
+			level = max(10*math.Log10(energy), minDBFS)
+		}
+		report.Contributors = append(report.Contributors, ContributorActivity{
+			ID:       id,
+			Level:    level,
+			Activity: float64(s.activeFrames) / float64(t.frames),
+		})
+	}
+	slices.SortFunc(report.Contributors, func(a, b ContributorActivity) int {
+		if c := cmp.Compare(b.Level, a.Level); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	t.reportStart = t.timestamp
+	t.frames = 0
+	clear(t.stats)
+	return report
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixActivityTracker(t *testing.T) {
+	config := MixActivityConfig{Interval: 60 * time.Millisecond, MinLevel: -50}
+
+	t.Run("reports per contributor", func(t *testing.T) {
+		m := NewMixer(MixerParams{FrameSize: 4, JitterFrames: 1, MaxQueuedFrames: 4})
+		m.AddSource("loud")
+		m.AddSource("quiet")
+		m.AddSource("excluded")
+
+		tracker := NewMixActivityTracker(config, 20*time.Millisecond)
+		include := func(id string) bool { return id != "excluded" }
+
+		var reports []*MixActivityReport
+		for i := 0; i < 6; i++ {
+			// half scale, about -6 dBFS, in every frame
+			m.Push("loud", constantFrame(4, 16384))
+			m.Push("excluded", constantFrame(4, 16384))
+			// below the activity level in the first report, about -40 dBFS in the last frame
+			if i == 5 {
+				m.Push("quiet", constantFrame(4, 328))
+			} else if i < 3 {
+				m.Push("quiet", constantFrame(4, 10))
+			}
+			if report := tracker.Observe(m.Tick().Energies(), include); report != nil {
+				reports = append(reports, report)
+			}
+		}
+		require.Len(t, reports, 2)
+
+		first := reports[0]
+		require.Equal(t, time.Duration(0), first.Start)
+		require.Equal(t, 60*time.Millisecond, first.Duration)
+		require.Len(t, first.Contributors, 2)
+		require.Equal(t, "loud", first.Contributors[0].ID)
+		require.InDelta(t, -6.02, first.Contributors[0].Level, 0.01)
+		require.Equal(t, 1.0, first.Contributors[0].Activity)
+		require.Equal(t, "quiet", first.Contributors[1].ID)
+		require.Zero(t, first.Contributors[1].Activity)
+
+		second := reports[1]
+		require.Equal(t, 60*time.Millisecond, second.Start)
+		require.Len(t, second.Contributors, 2)
+		require.Equal(t, "quiet", second.Contributors[1].ID)
+		require.InDelta(t, 1.0/3, second.Contributors[1].Activity, 1e-9)
+		// energy averaged over the report, one active frame out of three
+		require.InDelta(t, -44.77, second.Contributors[1].Level, 0.01)
+	})
+
+	t.Run("silent contributors", func(t *testing.T) {
+		tracker := NewMixActivityTracker(MixActivityConfig{MinLevel: -50}, 20*time.Millisecond)
+		report := tracker.Observe(map[string]float64{"a": 0}, nil)
+		require.NotNil(t, report)
+		require.Equal(t, []ContributorActivity{{ID: "a", Level: minDBFS}}, report.Contributors)
+	})
+}
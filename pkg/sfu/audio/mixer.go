@@ -0,0 +1,316 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"sync"
+)
+
+// MixerConfig controls server side audio mixing, subscribers opt in to receive
+// one mixed audio track instead of a track per publisher.
+type MixerConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// 20 ms frames buffered per publisher before it is mixed in
+	JitterFrames int `yaml:"jitter_frames,omitempty"`
+	// plays audio queued up after decoding stalled faster instead of dropping it
+	CatchUp CatchUpConfig `yaml:"catch_up,omitempty"`
+	// one mix of everybody, encoded once and shared by all its subscribers
+	RoomMix RoomMixConfig `yaml:"room_mix,omitempty"`
+	// level and voice activity of every publisher in a mix, sent alongside it
+	Activity MixActivityConfig `yaml:"activity,omitempty"`
+}
+
+// RoomMixConfig controls the room mix, a single track of the audio of all publishers of a room
+// for consumers like agents and recorders that would otherwise subscribe to every publisher
+type RoomMixConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// recorders, e. g. egress, receive the room mix instead of a track per publisher
+	Recorders bool `yaml:"recorders,omitempty"`
+}
+
+var (
+	DefaultMixerConfig = MixerConfig{
+		JitterFrames: 2,
+		CatchUp:      DefaultCatchUpConfig,
+		Activity:     DefaultMixActivityConfig,
+	}
+)
+
+// --------------------------------------
+
+type MixerParams struct {
+	// samples per mixed frame
+	FrameSize int
+	// frames a source has to buffer before it contributes to the mix, absorbs network jitter
+	JitterFrames int
+	// frames a source can buffer, oldest samples are dropped beyond this
+	MaxQueuedFrames int
+	// speed a source buffering more than a frame beyond its jitter frames is played at to catch up,
+	// no catching up if not above 1
+	CatchUpMaxSpeed float64
+}
+
+var (
+	DefaultMixerParams = MixerParams{
+		FrameSize:       OpusFrameSize,
+		JitterFrames:    2,
+		MaxQueuedFrames: 10,
+	}
+)
+
+const (
+	// range of the gain of a source, attenuating further is muting it
+	MinMixGainDB = -60
+	MaxMixGainDB = 12
+)
+
+type mixerSource struct {
+	queue  []int16
+	primed bool
+	// applied to the contributions of the source to all mixes
+	gain float64
+	// nil if not catching up
+	catchUp *wsola
+}
+
+// Mixer sums PCM from any number of sources, one frame per Tick.
+// Each Tick keeps the per-source contributions so that N-1 mixes,
+// i. e. everybody except the listener's own sources, can be derived without re-summing.
+type Mixer struct {
+	params MixerParams
+
+	lock    sync.Mutex
+	sources map[string]*mixerSource
+}
+
+func NewMixer(params MixerParams) *Mixer {
+	if params.FrameSize <= 0 {
+		params.FrameSize = DefaultMixerParams.FrameSize
+	}
+	if params.MaxQueuedFrames < params.JitterFrames+1 {
+		params.MaxQueuedFrames = params.JitterFrames + 1
+	}
+	return &Mixer{
+		params:  params,
+		sources: make(map[string]*mixerSource),
+	}
+}
+
+func (m *Mixer) AddSource(id string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.sources[id]; !ok {
+		s := &mixerSource{gain: 1}
+		if m.params.CatchUpMaxSpeed > 1 {
+			s.catchUp = newWSOLA(m.params.FrameSize)
+		}
+		m.sources[id] = s
+	}
+}
+
+func (m *Mixer) RemoveSource(id string) {
+	m.lock.Lock()
+	delete(m.sources, id)
+	m.lock.Unlock()
+}
+
+// SetGain sets the gain of a source in dB, clamped to MinMixGainDB and MaxMixGainDB.
+// Sources at MinMixGainDB are muted.
+func (m *Mixer) SetGain(id string, gainDB float64) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s, ok := m.sources[id]
+	if !ok {
+		return
+	}
+	s.gain = GainFactor(gainDB, MinMixGainDB, MaxMixGainDB)
+}
+
+func (m *Mixer) NumSources() int {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return len(m.sources)
+}
+
+// Push queues samples of a source, samples of unknown sources are dropped
+func (m *Mixer) Push(id string, pcm []int16) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	s, ok := m.sources[id]
+	if !ok {
+		return
+	}
+
+	s.queue = append(s.queue, pcm...)
+	if excess := len(s.queue) - m.params.MaxQueuedFrames*m.params.FrameSize; excess > 0 {
+		s.queue = append(s.queue[:0], s.queue[excess:]...)
+		if s.catchUp != nil {
+			s.catchUp.reset()
+		}
+	}
+	if !s.primed && len(s.queue) >= m.params.JitterFrames*m.params.FrameSize {
+		s.primed = true
+	}
+}
+
+// Tick takes one frame from every primed source. A source that runs dry
+// is padded with silence and has to re-buffer before it contributes again.
+// The returned frame is never nil.
+func (m *Mixer) Tick() *MixedFrame {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	f := &MixedFrame{
+		total:         make([]int32, m.params.FrameSize),
+		contributions: make(map[string][]int16),
+	}
+	for id, s := range m.sources {
+		if !s.primed {
+			continue
+		}
+		if len(s.queue) == 0 {
+			s.primed = false
+			continue
+		}
+
+		frame := make([]int16, m.params.FrameSize)
+		if !m.catchUpLocked(s, frame) {
+			n := copy(frame, s.queue)
+			s.queue = append(s.queue[:0], s.queue[n:]...)
+			if n < m.params.FrameSize {
+				s.primed = false
+			}
+		}
+
+		ApplyGain(frame, s.gain)
+
+		for i, sample := range frame {
+			f.total[i] += int32(sample)
+		}
+		f.contributions[id] = frame
+	}
+	return f
+}
+
+// catchUpLocked fills frame with time-compressed audio while the source is more than a frame behind
+// its jitter buffering, until it is back to at most one frame behind. Returns false if the frame is
+// to be copied from the queue as is. Must be called with the lock held.
+func (m *Mixer) catchUpLocked(s *mixerSource, frame []int16) bool {
+	if s.catchUp == nil {
+		return false
+	}
+
+	target := (m.params.JitterFrames + 1) * m.params.FrameSize
+	if !s.catchUp.active && len(s.queue) <= target+m.params.FrameSize {
+		return false
+	}
+
+	consumed := s.catchUp.compress(s.queue, m.params.CatchUpMaxSpeed, frame)
+	if consumed == 0 {
+		s.catchUp.reset()
+		return false
+	}
+	s.queue = append(s.queue[:0], s.queue[consumed:]...)
+	if len(s.queue) <= target {
+		s.catchUp.reset()
+	}
+	return true
+}
+
+// --------------------------------------
+
+type MixedFrame struct {
+	total         []int32
+	contributions map[string][]int16
+}
+
+// Contributes returns true if the source is part of this frame
+func (f *MixedFrame) Contributes(id string) bool {
+	_, ok := f.contributions[id]
+	return ok
+}
+
+func (f *MixedFrame) NumContributors() int {
+	return len(f.contributions)
+}
+
+// MixExcluding writes the mix of all contributions except the excluded sources into out
+func (f *MixedFrame) MixExcluding(exclude func(id string) bool, out []int16) {
+	sum := make([]int32, len(f.total))
+	copy(sum, f.total)
+	if exclude != nil {
+		for id, frame := range f.contributions {
+			if !exclude(id) {
+				continue
+			}
+			for i, sample := range frame {
+				sum[i] -= int32(sample)
+			}
+		}
+	}
+
+	for i := 0; i < len(out) && i < len(sum); i++ {
+		out[i] = clipInt16(sum[i])
+	}
+}
+
+// MixStereo writes the interleaved stereo mix of all contributions except the excluded sources into out,
+// each source on the channel returned for it
+func (f *MixedFrame) MixStereo(exclude func(id string) bool, channel func(id string) MixChannel, out []int16) {
+	left := make([]int32, len(f.total))
+	right := make([]int32, len(f.total))
+	for id, frame := range f.contributions {
+		if exclude != nil && exclude(id) {
+			continue
+		}
+
+		switch channel(id) {
+		case MixChannelLeft:
+			for i, sample := range frame {
+				left[i] += int32(sample)
+			}
+		case MixChannelRight:
+			for i, sample := range frame {
+				right[i] += int32(sample)
+			}
+		default:
+			for i, sample := range frame {
+				left[i] += int32(sample)
+				right[i] += int32(sample)
+			}
+		}
+	}
+
+	for i := 0; i < len(out)/2 && i < len(left); i++ {
+		out[2*i] = clipInt16(left[i])
+		out[2*i+1] = clipInt16(right[i])
+	}
+}
+
+func clipInt16(v int32) int16 {
+	if v > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if v < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(v)
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func constantFrame(size int, value int16) []int16 {
+	frame := make([]int16, size)
+	for i := range frame {
+		frame[i] = value
+	}
+	return frame
+}
+
+func TestMixer(t *testing.T) {
+	params := MixerParams{FrameSize: 4, JitterFrames: 1, MaxQueuedFrames: 2}
+
+	t.Run("mixes primed sources", func(t *testing.T) {
+		m := NewMixer(params)
+		m.AddSource("a")
+		m.AddSource("b")
+		m.AddSource("c")
+		m.Push("a", constantFrame(4, 100))
+		m.Push("b", constantFrame(4, 200))
+		m.Push("unknown", constantFrame(4, 1000))
+
+		f := m.Tick()
+		require.Equal(t, 2, f.NumContributors())
+		require.True(t, f.Contributes("a"))
+		require.False(t, f.Contributes("c"))
+
+		out := make([]int16, 4)
+		f.MixExcluding(nil, out)
+		require.Equal(t, constantFrame(4, 300), out)
+
+		f.MixExcluding(func(id string) bool { return id == "a" }, out)
+		require.Equal(t, constantFrame(4, 200), out)
+	})
+
+	t.Run("clips", func(t *testing.T) {
+		m := NewMixer(params)
+		m.AddSource("a")
+		m.AddSource("b")
+		m.Push("a", constantFrame(4, math.MaxInt16))
+		m.Push("b", constantFrame(4, math.MaxInt16))
+
+		out := make([]int16, 4)
+		m.Tick().MixExcluding(nil, out)
+		require.Equal(t, constantFrame(4, math.MaxInt16), out)
+	})
+
+	t.Run("re-buffers after underrun", func(t *testing.T) {
+		m := NewMixer(MixerParams{FrameSize: 4, JitterFrames: 2, MaxQueuedFrames: 4})
+		m.AddSource("a")
+		m.Push("a", constantFrame(4, 10))
+		require.Equal(t, 0, m.Tick().NumContributors())
+
+		m.Push("a", constantFrame(4, 10))
+		require.Equal(t, 1, m.Tick().NumContributors())
+		require.Equal(t, 1, m.Tick().NumContributors())
+		// ran dry, needs two frames again
+		require.Equal(t, 0, m.Tick().NumContributors())
+	})
+
+	t.Run("stereo", func(t *testing.T) {
+		m := NewMixer(params)
+		for _, id := range []string{"interpreter", "floor", "other", "listener"} {
+			m.AddSource(id)
+		}
+		m.Push("interpreter", constantFrame(4, 100))
+		m.Push("floor", constantFrame(4, 200))
+		m.Push("other", constantFrame(4, 10))
+		m.Push("listener", constantFrame(4, 1000))
+
+		channels := ChannelMap{"interpreter": MixChannelLeft, "floor": MixChannelRight}
+		out := make([]int16, 8)
+		m.Tick().MixStereo(func(id string) bool { return id == "listener" }, channels.Channel, out)
+		require.Equal(t, []int16{110, 210, 110, 210, 110, 210, 110, 210}, out)
+	})
+
+	t.Run("applies the gain of sources", func(t *testing.T) {
+		m := NewMixer(params)
+		for _, id := range []string{"agent", "caller", "muted"} {
+			m.AddSource(id)
+			m.Push(id, constantFrame(4, 1000))
+		}
+		m.SetGain("agent", -6)
+		m.SetGain("caller", 6)
+		m.SetGain("muted", MinMixGainDB)
+		m.SetGain("unknown", 6)
+
+		out := make([]int16, 4)
+		f := m.Tick()
+		f.MixExcluding(func(id string) bool { return id != "agent" }, out)
+		require.Equal(t, constantFrame(4, 501), out)
+		f.MixExcluding(func(id string) bool { return id != "caller" }, out)
+		require.Equal(t, constantFrame(4, 1995), out)
+		f.MixExcluding(nil, out)
+		require.Equal(t, constantFrame(4, 2496), out)
+	})
+
+	t.Run("drops oldest beyond queue limit", func(t *testing.T) {
+		m := NewMixer(params)
+		m.AddSource("a")
+		m.Push("a", constantFrame(4, 1))
+		m.Push("a", constantFrame(4, 2))
+		m.Push("a", constantFrame(4, 3))
+
+		out := make([]int16, 4)
+		m.Tick().MixExcluding(nil, out)
+		require.Equal(t, constantFrame(4, 2), out)
+	})
+}
+
+func TestParseChannelMap(t *testing.T) {
+	m, err := ParseChannelMap(`{"interpreter": "left", "*": "right"}`)
+	require.NoError(t, err)
+	require.Equal(t, MixChannelLeft, m.Channel("interpreter"))
+	require.Equal(t, MixChannelRight, m.Channel("floor"))
+
+	m, err = ParseChannelMap(`{"interpreter": "left"}`)
+	require.NoError(t, err)
+	require.Equal(t, MixChannelCenter, m.Channel("floor"))
+
+	m, err = ParseChannelMap("")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	m, err = ParseChannelMap("{}")
+	require.NoError(t, err)
+	require.Nil(t, m)
+
+	_, err = ParseChannelMap(`{"interpreter": "up"}`)
+	require.Error(t, err)
+
+	_, err = ParseChannelMap("left")
+	require.Error(t, err)
+}
+
+func TestFramingMixerParams(t *testing.T) {
+	t.Run("defaults to 20ms", func(t *testing.T) {
+		framing := FramingConfig{}
+		require.NoError(t, framing.Validate())
+		require.Equal(t, OpusFrameSize, framing.FrameSize())
+		require.Equal(t, DefaultMixerParams, framing.MixerParams(MixerConfig{}))
+	})
+
+	t.Run("10ms keeps buffered duration", func(t *testing.T) {
+		framing := FramingConfig{FrameDuration: 10 * time.Millisecond}
+		require.NoError(t, framing.Validate())
+		require.Equal(t, 480, framing.FrameSize())
+		require.Equal(t, MixerParams{
+			FrameSize:       480,
+			JitterFrames:    6,
+			MaxQueuedFrames: 20,
+		}, framing.MixerParams(MixerConfig{JitterFrames: 3}))
+	})
+
+	t.Run("rounds up to whole frames", func(t *testing.T) {
+		framing := FramingConfig{FrameDuration: 20 * time.Millisecond}
+		require.Equal(t, 1, framing.Frames(0))
+		require.Equal(t, 2, framing.Frames(25*time.Millisecond))
+	})
+
+	t.Run("rejects other durations", func(t *testing.T) {
+		require.Error(t, FramingConfig{FrameDuration: 40 * time.Millisecond}.Validate())
+		require.Error(t, FramingConfig{FrameDuration: 5 * time.Millisecond}.Validate())
+	})
+}
@@ -0,0 +1,263 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// frames the music score is computed over, 1 s of 10 ms frames
+	musicWindowFrames = 100
+	// mean square of a frame below -60 dBFS, silence is no evidence of anything but pauses
+	musicSilenceLevel = 1e-6
+	// frames below this fraction of the mean energy of the window are low energy frames, and
+	// the share of low energy frames at which a stream counts as not continuous at all
+	musicLowEnergyLevel = 0.5
+	musicMaxLowEnergy   = 0.5
+	// spectral flatness in dB at which a frame counts as fully tonal
+	musicTonalFlatnessDB = -40
+	// weights of continuity, tonality and spectral stability in the score
+	musicContinuityWeight = 0.4
+	musicTonalityWeight   = 0.3
+	musicStabilityWeight  = 0.3
+)
+
+// MusicDetectionConfig bypasses noise suppression while a stream carries music, which RNNoise takes apart
+// as noise. Music is told from speech by a score of three features over the last second: music is continuous
+// where speech pauses between syllables and words, it is tonal, and its spectrum holds from frame to frame
+// where the pitch of speech glides and noise varies at random. The decision has hysteresis, the score has to
+// stay past a threshold for a delay before it switches, so that it does not flap.
+type MusicDetectionConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// music score from 0 to 1 at which suppression is bypassed
+	EnterThreshold float32 `json:"enter_threshold" yaml:"enter_threshold,omitempty"`
+	// score below which suppression resumes, below the enter threshold
+	ExitThreshold float32 `json:"exit_threshold" yaml:"exit_threshold,omitempty"`
+	// time the score has to stay at or above the enter threshold before suppression is bypassed
+	EnterDelay time.Duration `json:"enter_delay" yaml:"enter_delay,omitempty"`
+	// time the score has to stay below the exit threshold before suppression resumes
+	ExitDelay time.Duration `json:"exit_delay" yaml:"exit_delay,omitempty"`
+}
+
+var (
+	DefaultMusicDetectionConfig = MusicDetectionConfig{
+		EnterThreshold: 0.65,
+		ExitThreshold:  0.45,
+		EnterDelay:     time.Second,
+		ExitDelay:      2 * time.Second,
+	}
+)
+
+type musicFrame struct {
+	energy    float32
+	tonality  float32
+	stability float32
+	silent    bool
+}
+
+// MusicDetector classifies the frames of a single channel as music or speech, see MusicDetectionConfig.
+// Not safe for concurrent use.
+type MusicDetector struct {
+	enterThreshold float32
+	exitThreshold  float32
+	enterFrames    int
+	exitFrames     int
+
+	fft     *fft
+	window  []float32
+	in, out []complex64
+	// log power spectra of this and the previous frame, prevValid unless the previous frame was silent
+	logPower     []float64
+	prevLogPower []float64
+	prevValid    bool
+
+	frames [musicWindowFrames]musicFrame
+	next   int
+	count  int
+
+	score float32
+	music bool
+	// consecutive frames the score has been past the threshold of the other state
+	pending int
+}
+
+// NewMusicDetector creates a detector for frames of frameDuration, settings not configured taken from the defaults
+func NewMusicDetector(config MusicDetectionConfig, frameDuration time.Duration) *MusicDetector {
+	if config.EnterThreshold <= 0 || config.EnterThreshold > 1 {
+		config.EnterThreshold = DefaultMusicDetectionConfig.EnterThreshold
+	}
+	if config.ExitThreshold <= 0 || config.ExitThreshold > config.EnterThreshold {
+		config.ExitThreshold = min(DefaultMusicDetectionConfig.ExitThreshold, config.EnterThreshold)
+	}
+	if config.EnterDelay <= 0 {
+		config.EnterDelay = DefaultMusicDetectionConfig.EnterDelay
+	}
+	if config.ExitDelay <= 0 {
+		config.ExitDelay = DefaultMusicDetectionConfig.ExitDelay
+	}
+	return &MusicDetector{
+		enterThreshold: config.EnterThreshold,
+		exitThreshold:  config.ExitThreshold,
+		enterFrames:    max(int(config.EnterDelay/frameDuration), 1),
+		exitFrames:     max(int(config.ExitDelay/frameDuration), 1),
+	}
+}
+
+// Observe analyzes a frame of normalized samples and returns whether the stream is music
+func (d *MusicDetector) Observe(frame []float32) bool {
+	if len(frame) < 4 {
+		return d.music
+	}
+	if d.fft == nil || d.fft.n != len(frame) {
+		d.init(len(frame))
+	}
+
+	d.frames[d.next] = d.analyze(frame)
+	d.next = (d.next + 1) % musicWindowFrames
+	d.count = min(d.count+1, musicWindowFrames)
+	d.score = d.windowScore()
+
+	switch {
+	case !d.music && d.score >= d.enterThreshold:
+		if d.pending++; d.pending >= d.enterFrames {
+			d.music, d.pending = true, 0
+		}
+	case d.music && d.score < d.exitThreshold:
+		if d.pending++; d.pending >= d.exitFrames {
+			d.music, d.pending = false, 0
+		}
+	default:
+		d.pending = 0
+	}
+	return d.music
+}
+
+func (d *MusicDetector) IsMusic() bool {
+	return d.music
+}
+
+// Score returns the music score of the last second, 0 until a second has been observed
+func (d *MusicDetector) Score() float32 {
+	return d.score
+}
+
+func (d *MusicDetector) init(n int) {
+	d.fft = newFFT(n)
+	d.window = make([]float32, n)
+	for i := range d.window {
+		d.window[i] = float32(0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n-1)))
+	}
+	d.in = make([]complex64, n)
+	d.out = make([]complex64, n)
+	d.logPower = make([]float64, n/2)
+	d.prevLogPower = make([]float64, n/2)
+}
+
+func (d *MusicDetector) analyze(frame []float32) musicFrame {
+	var energy float32
+	for i, s := range frame {
+		energy += s * s
+		d.in[i] = complex(s*d.window[i], 0)
+	}
+	f := musicFrame{energy: energy / float32(len(frame))}
+	d.logPower, d.prevLogPower = d.prevLogPower, d.logPower
+	prevValid := d.prevValid
+	d.prevValid = false
+	if f.energy < musicSilenceLevel {
+		f.silent = true
+		return f
+	}
+
+	d.fft.transform(d.in, d.out)
+	bins := len(d.logPower)
+	var sum, logSum float64
+	for k := 1; k < bins; k++ {
+		c := d.out[k]
+		p := float64(real(c)*real(c) + imag(c)*imag(c))
+		sum += p
+		d.logPower[k] = math.Log(p + 1e-12)
+		logSum += d.logPower[k]
+	}
+	if sum <= 0 {
+		f.silent = true
+		return f
+	}
+	d.prevValid = true
+
+	// tonality coefficient, the spectral flatness, the ratio of the geometric to the arithmetic mean
+	// of the power, in dB relative to that of a fully tonal frame
+	logMean := logSum / float64(bins-1)
+	flatnessDB := 10 * (logMean - math.Log(sum/float64(bins-1))) / math.Ln10
+	f.tonality = float32(min(max(flatnessDB/musicTonalFlatnessDB, 0), 1))
+
+	// correlation of the log spectra of consecutive frames
+	if prevValid {
+		var prevMean float64
+		for k := 1; k < bins; k++ {
+			prevMean += d.prevLogPower[k]
+		}
+		prevMean /= float64(bins - 1)
+
+		var cov, variance, prevVariance float64
+		for k := 1; k < bins; k++ {
+			a, b := d.logPower[k]-logMean, d.prevLogPower[k]-prevMean
+			cov += a * b
+			variance += a * a
+			prevVariance += b * b
+		}
+		if variance > 0 && prevVariance > 0 {
+			f.stability = float32(max(cov/math.Sqrt(variance*prevVariance), 0))
+		}
+	}
+	return f
+}
+
+func (d *MusicDetector) windowScore() float32 {
+	if d.count < musicWindowFrames {
+		return 0
+	}
+
+	var energy float32
+	for _, f := range d.frames {
+		energy += f.energy
+	}
+	energy /= musicWindowFrames
+	if energy < musicSilenceLevel {
+		return 0
+	}
+
+	var lowEnergy, voiced int
+	var tonality, stability float32
+	for _, f := range d.frames {
+		if f.energy < musicLowEnergyLevel*energy {
+			lowEnergy++
+		}
+		if !f.silent {
+			voiced++
+			tonality += f.tonality
+			stability += f.stability
+		}
+	}
+	if voiced == 0 {
+		return 0
+	}
+	continuity := 1 - min(float32(lowEnergy)/musicWindowFrames/musicMaxLowEnergy, 1)
+	return musicContinuityWeight*continuity +
+		musicTonalityWeight*tonality/float32(voiced) +
+		musicStabilityWeight*stability/float32(voiced)
+}
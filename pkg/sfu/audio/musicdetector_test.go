@@ -0,0 +1,163 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const musicTestFrameSize = 480
+
+// musicSignal is a sequence of sustained chords with harmonics, one chord every half second
+func musicSignal(frames int) [][]float32 {
+	chords := [][]float64{{261.63, 329.63, 392.0}, {220.0, 261.63, 329.63}, {174.61, 220.0, 261.63}, {196.0, 246.94, 293.66}}
+	out := make([][]float32, frames)
+	for f := range out {
+		frame := make([]float32, musicTestFrameSize)
+		for i := range frame {
+			n := f*musicTestFrameSize + i
+			t := float64(n) / OpusSampleRate
+			chord := chords[(n/(OpusSampleRate/2))%len(chords)]
+			var s float64
+			for _, note := range chord {
+				for h := 1; h <= 4; h++ {
+					s += math.Sin(2*math.Pi*note*float64(h)*t) / float64(h)
+				}
+			}
+			frame[i] = float32(0.08 * s)
+		}
+		out[f] = frame
+	}
+	return out
+}
+
+// speechSignal is voiced syllables with a gliding pitch, separated by short pauses
+func speechSignal(frames int, rng *rand.Rand) [][]float32 {
+	out := make([][]float32, frames)
+	var phase float64
+	syllable, position, length, pause := 0, 0, 0, 0
+	for f := range out {
+		frame := make([]float32, musicTestFrameSize)
+		for i := range frame {
+			if position >= length+pause {
+				syllable++
+				position = 0
+				length = (12 + rng.Intn(14)) * musicTestFrameSize
+				pause = (4 + rng.Intn(12)) * musicTestFrameSize
+			}
+			if position < length {
+				progress := float64(position) / float64(length)
+				f0 := 110 + 60*math.Sin(math.Pi*progress) + 20*float64(syllable%3)
+				phase += 2 * math.Pi * f0 / OpusSampleRate
+				var s float64
+				for h := 1; h <= 20; h++ {
+					// formant-like emphasis around 500 Hz and 1500 Hz
+					freq := f0 * float64(h)
+					gain := math.Exp(-math.Pow((freq-500)/300, 2)) + 0.5*math.Exp(-math.Pow((freq-1500)/400, 2)) + 0.05
+					s += gain * math.Sin(phase*float64(h))
+				}
+				frame[i] = float32(0.1 * math.Sin(math.Pi*progress) * s)
+			}
+			frame[i] += float32(0.002 * rng.NormFloat64())
+			position++
+		}
+		out[f] = frame
+	}
+	return out
+}
+
+func noiseSignal(frames int, rng *rand.Rand) [][]float32 {
+	out := make([][]float32, frames)
+	for f := range out {
+		frame := make([]float32, musicTestFrameSize)
+		for i := range frame {
+			frame[i] = float32(0.05 * rng.NormFloat64())
+		}
+		out[f] = frame
+	}
+	return out
+}
+
+func TestMusicDetector(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	observe := func(d *MusicDetector, frames [][]float32) (int, float32, float32) {
+		detected := -1
+		lowest, highest := float32(1), float32(0)
+		for i, frame := range frames {
+			if d.Observe(frame) && detected < 0 {
+				detected = i
+			}
+			if i >= musicWindowFrames {
+				lowest, highest = min(lowest, d.Score()), max(highest, d.Score())
+			}
+		}
+		return detected, lowest, highest
+	}
+
+	t.Run("music bypasses after the enter delay", func(t *testing.T) {
+		d := NewMusicDetector(DefaultMusicDetectionConfig, 10*time.Millisecond)
+		detected, lowest, _ := observe(d, musicSignal(500))
+		t.Logf("music lowest score %.2f", lowest)
+		require.GreaterOrEqual(t, lowest, DefaultMusicDetectionConfig.EnterThreshold)
+		// the window is full at the 100th frame, which starts the 100 frames of enter delay
+		require.Equal(t, 2*musicWindowFrames-2, detected)
+		require.True(t, d.IsMusic())
+	})
+
+	t.Run("speech and noise are not music", func(t *testing.T) {
+		d := NewMusicDetector(DefaultMusicDetectionConfig, 10*time.Millisecond)
+		detected, _, highest := observe(d, speechSignal(1000, rng))
+		t.Logf("speech highest score %.2f", highest)
+		require.Equal(t, -1, detected)
+		require.Less(t, highest, DefaultMusicDetectionConfig.EnterThreshold)
+
+		d = NewMusicDetector(DefaultMusicDetectionConfig, 10*time.Millisecond)
+		detected, _, highest = observe(d, noiseSignal(1000, rng))
+		t.Logf("noise highest score %.2f", highest)
+		require.Equal(t, -1, detected)
+		require.Less(t, highest, DefaultMusicDetectionConfig.EnterThreshold)
+
+		d = NewMusicDetector(DefaultMusicDetectionConfig, 10*time.Millisecond)
+		detected, _, _ = observe(d, make([][]float32, 500))
+		require.Equal(t, -1, detected)
+	})
+
+	t.Run("speech resumes suppression after the exit delay", func(t *testing.T) {
+		d := NewMusicDetector(DefaultMusicDetectionConfig, 10*time.Millisecond)
+		observe(d, musicSignal(300))
+		require.True(t, d.IsMusic())
+
+		// a short burst of speech does not flap the decision
+		observe(d, speechSignal(100, rng))
+		require.True(t, d.IsMusic())
+
+		frames := speechSignal(600, rng)
+		resumed := -1
+		for i, frame := range frames {
+			if !d.Observe(frame) {
+				resumed = i
+				break
+			}
+		}
+		require.Greater(t, resumed, 100)
+		require.False(t, d.IsMusic())
+	})
+}
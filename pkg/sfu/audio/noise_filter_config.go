@@ -15,18 +15,155 @@
 
 package audio
 
+import (
+	"maps"
+)
+
 // NoiseFilterConfig holds configuration for noise suppression
 type NoiseFilterConfig struct {
-	Enabled    bool    `json:"enabled" yaml:"enabled"`
-	Threshold  float32 `json:"threshold" yaml:"threshold"` // VAD threshold (0.0-1.0)
-	Aggressive bool    `json:"aggressive" yaml:"aggressive"` // More aggressive noise suppression
+	Enabled   bool    `json:"enabled" yaml:"enabled"`
+	Threshold float32 `json:"threshold" yaml:"threshold,omitempty"` // VAD threshold (0.0-1.0)
+	// RNNoise model file, the built in model if empty
+	ModelPath string `json:"model_path" yaml:"model_path,omitempty"`
+	// alternative RNNoise model files by name, e. g. for headsets or speakerphones, selected with the model
+	// setting of presets, room overrides and languages
+	Models map[string]string `json:"models" yaml:"models,omitempty"`
+	// settings for speech of a language, keyed by BCP 47 tag, e. g. "vi" or "pt-br"
+	Languages map[string]NoiseFilterLanguageConfig `json:"languages" yaml:"languages,omitempty"`
+	// suppression level from 0 to MaxAggressiveness, see Suppression
+	Aggressiveness int `json:"aggressiveness" yaml:"aggressiveness,omitempty"`
+	// Deprecated: use Aggressiveness, true is taken as level 2
+	Aggressive bool `json:"aggressive" yaml:"aggressive,omitempty"`
+	// noise frames are replaced by comfort noise instead of being attenuated
+	ComfortNoise ComfortNoiseConfig `json:"comfort_noise" yaml:"comfort_noise,omitempty"`
+	// envelope smoothing the transitions between speech and noise frames
+	NoiseGate NoiseGateConfig `json:"noise_gate" yaml:"noise_gate,omitempty"`
+	// suppression bypassed while the stream carries music
+	MusicDetection MusicDetectionConfig `json:"music_detection" yaml:"music_detection,omitempty"`
+	// periodic reset of denoiser state on long calls
+	Reset DenoiserResetConfig `json:"reset" yaml:"reset,omitempty"`
+	// denoising on dedicated goroutines instead of the RTP read path
+	Workers DenoiserWorkersConfig `json:"workers" yaml:"workers,omitempty"`
+	// pre-initialized denoiser instances streams check out instead of creating their own
+	Instances DenoiserInstancesConfig `json:"instances" yaml:"instances,omitempty"`
+	// subscribers that need denoised packets to keep the size of the original ones
+	Compatibility NoiseFilterCompatibilityConfig `json:"compatibility" yaml:"compatibility,omitempty"`
+	// cancellation of the audio of agents from the microphones of participants hearing them, ahead of denoising
+	EchoCancellation EchoCancellationConfig `json:"echo_cancellation" yaml:"echo_cancellation,omitempty"`
+	// reconstruction of the upper band of narrowband audio, e. g. of phone participants, after denoising
+	BandwidthExtension BandwidthExtensionConfig `json:"bandwidth_extension" yaml:"bandwidth_extension,omitempty"`
+	// recording of audio before and after denoising to WAV files, for debugging
+	DebugDump DebugDumpConfig `json:"debug_dump" yaml:"debug_dump,omitempty"`
+}
+
+// NoiseFilterCompatibilityConfig selects subscribers that break when re-encoding changes the size of packets,
+// e. g. clients sizing jitter buffers from the first packets. While such a subscriber is on a track, its packets
+// are re-encoded at the bitrate of the original ones and never exceed their size. Subscribers can also opt in
+// with a participant attribute.
+type NoiseFilterCompatibilityConfig struct {
+	// expressions matching the client info, e. g. `c.sdk == "unity" && c.version < "2.0.0"`
+	Clients []string `json:"clients" yaml:"clients,omitempty"`
+}
+
+// DenoiserWorkersConfig moves denoising onto a node wide pool of goroutines. Packets of a stream are
+// processed in order, when the pool is saturated packets pass through unfiltered.
+type DenoiserWorkersConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// goroutines denoising, 0 for one per CPU
+	Workers int `json:"workers" yaml:"workers,omitempty"`
+	// packets of a stream read ahead of its consumer, reading pauses when the queue is full
+	StreamQueueSize int `json:"stream_queue_size" yaml:"stream_queue_size,omitempty"`
+	// packets waiting for a worker across all streams, further packets pass through unfiltered
+	MaxPending int `json:"max_pending" yaml:"max_pending,omitempty"`
+}
+
+// DenoiserInstancesConfig keeps a node wide pool of initialized denoisers, so streams do not
+// create them on their first packet. Instances are returned when a stream closes and are
+// cleared of the audio they analyzed before another stream gets them.
+type DenoiserInstancesConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// instances kept ready, created at startup and replenished in the background
+	Size int `json:"size" yaml:"size,omitempty"`
 }
 
+var (
+	DefaultDenoiserWorkersConfig = DenoiserWorkersConfig{
+		StreamQueueSize: 25,
+		MaxPending:      2000,
+	}
+
+	DefaultDenoiserInstancesConfig = DenoiserInstancesConfig{
+		Size: 32,
+	}
+)
+
 // DefaultNoiseFilterConfig returns the default noise filter configuration
 func DefaultNoiseFilterConfig() NoiseFilterConfig {
 	return NoiseFilterConfig{
-		Enabled:    false, // Disabled by default for compatibility
-		Threshold:  0.5,   // Moderate VAD threshold
-		Aggressive: false,
+		Enabled:            false, // Disabled by default for compatibility
+		Threshold:          0.5,   // Moderate VAD threshold
+		ComfortNoise:       DefaultComfortNoiseConfig,
+		NoiseGate:          DefaultNoiseGateConfig,
+		MusicDetection:     DefaultMusicDetectionConfig,
+		Reset:              DefaultDenoiserResetConfig,
+		Workers:            DefaultDenoiserWorkersConfig,
+		Instances:          DefaultDenoiserInstancesConfig,
+		Languages:          maps.Clone(DefaultNoiseFilterLanguages),
+		EchoCancellation:   DefaultEchoCancellationConfig,
+		BandwidthExtension: DefaultBandwidthExtensionConfig,
+		DebugDump:          DefaultDebugDumpConfig,
+	}
+}
+
+const (
+	MaxAggressiveness = 3
+	// level the deprecated Aggressive flag stands for
+	aggressiveLevel = 2
+	// VAD threshold raised by aggressiveness is capped so that speech still gets through
+	maxSuppressionThreshold = 0.9
+)
+
+// NoiseSuppression is how hard the noise filter suppresses at a level of aggressiveness
+type NoiseSuppression struct {
+	// VAD threshold of the denoiser, frames below it are treated as noise
+	Threshold float32
+	// gain applied to noise frames, or to the level of the comfort noise replacing them
+	NoiseGain float32
+	// speech frames are denoised a second time by another denoiser instance
+	DoublePass bool
+}
+
+var noiseSuppressionLevels = [MaxAggressiveness + 1]struct {
+	thresholdOffset float32
+	noiseGain       float32
+	doublePass      bool
+}{
+	{thresholdOffset: 0, noiseGain: 0.1},                        // -20 dB
+	{thresholdOffset: 0.05, noiseGain: 0.03},                    // -30 dB
+	{thresholdOffset: 0.1, noiseGain: 0.01},                     // -40 dB
+	{thresholdOffset: 0.15, noiseGain: 0.001, doublePass: true}, // -60 dB
+}
+
+// Level returns the aggressiveness clamped to the supported levels, taking the deprecated Aggressive flag into account
+func (c NoiseFilterConfig) Level() int {
+	level := min(max(c.Aggressiveness, 0), MaxAggressiveness)
+	if c.Aggressive {
+		level = max(level, aggressiveLevel)
+	}
+	return level
+}
+
+// Suppression returns the suppression settings of the configured level, higher levels raise the
+// VAD threshold, attenuate noise frames deeper and finally denoise speech twice
+func (c NoiseFilterConfig) Suppression() NoiseSuppression {
+	level := noiseSuppressionLevels[c.Level()]
+	threshold := c.Threshold
+	if level.thresholdOffset > 0 {
+		threshold = max(threshold, min(threshold+level.thresholdOffset, maxSuppressionThreshold))
+	}
+	return NoiseSuppression{
+		Threshold:  threshold,
+		NoiseGain:  level.noiseGain,
+		DoublePass: level.doublePass,
 	}
-}
\ No newline at end of file
+}
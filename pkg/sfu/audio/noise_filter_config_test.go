@@ -0,0 +1,62 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoiseFilterConfig_Suppression(t *testing.T) {
+	config := DefaultNoiseFilterConfig()
+
+	t.Run("levels", func(t *testing.T) {
+		prev := config.Suppression()
+		require.Equal(t, NoiseSuppression{Threshold: 0.5, NoiseGain: 0.1}, prev)
+
+		for level := 1; level <= MaxAggressiveness; level++ {
+			config.Aggressiveness = level
+			s := config.Suppression()
+			require.Greater(t, s.Threshold, prev.Threshold)
+			require.Less(t, s.NoiseGain, prev.NoiseGain)
+			require.Equal(t, level == MaxAggressiveness, s.DoublePass)
+			prev = s
+		}
+	})
+
+	t.Run("clamped", func(t *testing.T) {
+		config.Aggressiveness = 7
+		require.Equal(t, MaxAggressiveness, config.Level())
+		config.Aggressiveness = -1
+		require.Zero(t, config.Level())
+	})
+
+	t.Run("deprecated flag", func(t *testing.T) {
+		config.Aggressiveness = 0
+		config.Aggressive = true
+		require.Equal(t, 2, config.Level())
+		config.Aggressiveness = 3
+		require.Equal(t, 3, config.Level())
+	})
+
+	t.Run("threshold capped", func(t *testing.T) {
+		c := NoiseFilterConfig{Threshold: 0.85, Aggressiveness: MaxAggressiveness}
+		require.Equal(t, float32(0.9), c.Suppression().Threshold)
+		c.Threshold = 0.95
+		require.Equal(t, float32(0.95), c.Suppression().Threshold)
+	})
+}
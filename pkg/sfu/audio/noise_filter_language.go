@@ -0,0 +1,113 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"strings"
+)
+
+const (
+	// participant attribute with the BCP 47 tag of the language the participant speaks, e. g. "vi" or "zh-TW".
+	// Takes precedence over the language detected in the participant's transcriptions.
+	NoiseFilterLanguageAttribute = "agentix.language"
+
+	// language label of metrics of streams whose language is not known
+	NoiseFilterLanguageUnknown = "unknown"
+	// language label of metrics of streams whose language tag is not a plain language subtag
+	NoiseFilterLanguageOther = "other"
+)
+
+// NoiseFilterLanguageConfig tunes the noise filter for speech of a language
+type NoiseFilterLanguageConfig struct {
+	NoiseFilterOverride `yaml:",inline"`
+	// aggressiveness is lowered to this level, tonal languages lose pitch contours to strong suppression
+	MaxAggressiveness *int `json:"max_aggressiveness,omitempty" yaml:"max_aggressiveness,omitempty"`
+}
+
+func (l NoiseFilterLanguageConfig) Apply(config NoiseFilterConfig) NoiseFilterConfig {
+	config = l.NoiseFilterOverride.Apply(config)
+	if l.MaxAggressiveness != nil {
+		if limit := max(*l.MaxAggressiveness, 0); config.Level() > limit {
+			config.Aggressiveness = limit
+			config.Aggressive = false
+		}
+	}
+	return config
+}
+
+var (
+	tonalMaxAggressiveness = 1
+
+	// tonal languages keep at most level 1 by default
+	DefaultNoiseFilterLanguages = map[string]NoiseFilterLanguageConfig{
+		"zh":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"yue": {MaxAggressiveness: &tonalMaxAggressiveness},
+		"vi":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"th":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"lo":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"my":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"pa":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"yo":  {MaxAggressiveness: &tonalMaxAggressiveness},
+		"ig":  {MaxAggressiveness: &tonalMaxAggressiveness},
+	}
+)
+
+// NormalizeLanguage lowercases a BCP 47 tag and separates its subtags with hyphens, e. g. "zh_TW" becomes "zh-tw"
+func NormalizeLanguage(tag string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+}
+
+// LanguageConfig returns the settings of the most specific entry matching the language tag, the full tag
+// before the language and region, before the language alone. Returns false if no entry matches.
+func (c NoiseFilterConfig) LanguageConfig(tag string) (NoiseFilterLanguageConfig, bool) {
+	subtags := strings.Split(NormalizeLanguage(tag), "-")
+	if subtags[0] == "" {
+		return NoiseFilterLanguageConfig{}, false
+	}
+
+	for n := len(subtags); n > 0; n-- {
+		if l, ok := c.Languages[strings.Join(subtags[:n], "-")]; ok {
+			return l, true
+		}
+	}
+	return NoiseFilterLanguageConfig{}, false
+}
+
+// ForLanguage returns the configuration tuned for speech of the language, config itself if no entry matches
+func (c NoiseFilterConfig) ForLanguage(tag string) NoiseFilterConfig {
+	l, ok := c.LanguageConfig(tag)
+	if !ok {
+		return c
+	}
+	return l.Apply(c)
+}
+
+// LanguageLabel returns the language subtag of a tag for labeling metrics, keeping their cardinality bounded
+func LanguageLabel(tag string) string {
+	language, _, _ := strings.Cut(NormalizeLanguage(tag), "-")
+	if language == "" {
+		return NoiseFilterLanguageUnknown
+	}
+	if len(language) < 2 || len(language) > 3 {
+		return NoiseFilterLanguageOther
+	}
+	for _, c := range language {
+		if c < 'a' || c > 'z' {
+			return NoiseFilterLanguageOther
+		}
+	}
+	return language
+}
@@ -0,0 +1,79 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNoiseFilterForLanguage(t *testing.T) {
+	threshold := float32(0.3)
+	config := DefaultNoiseFilterConfig()
+	config.Aggressiveness = 3
+	pt, ptBR := "pt", "pt-br"
+	config.Models = map[string]string{pt: "pt.rnnn", ptBR: "pt-br.rnnn"}
+	config.Languages["pt"] = NoiseFilterLanguageConfig{NoiseFilterOverride: NoiseFilterOverride{Model: &pt}}
+	config.Languages["pt-br"] = NoiseFilterLanguageConfig{
+		NoiseFilterOverride: NoiseFilterOverride{Threshold: &threshold, Model: &ptBR},
+	}
+
+	t.Run("tonal languages cap aggressiveness", func(t *testing.T) {
+		tuned := config.ForLanguage("zh-Hant-TW")
+		require.Equal(t, 1, tuned.Level())
+		require.False(t, tuned.Suppression().DoublePass)
+
+		config := config
+		config.Aggressiveness = 0
+		require.Equal(t, 0, config.ForLanguage("vi").Level())
+	})
+
+	t.Run("deprecated flag is capped too", func(t *testing.T) {
+		config := config
+		config.Aggressiveness = 0
+		config.Aggressive = true
+		require.Equal(t, 1, config.ForLanguage("th").Level())
+	})
+
+	t.Run("most specific entry wins", func(t *testing.T) {
+		tuned := config.ForLanguage("pt_BR")
+		require.Equal(t, "pt-br.rnnn", tuned.ModelPath)
+		require.Equal(t, threshold, tuned.Threshold)
+
+		tuned = config.ForLanguage("pt-PT")
+		require.Equal(t, "pt.rnnn", tuned.ModelPath)
+		require.Equal(t, config.Threshold, tuned.Threshold)
+	})
+
+	t.Run("unknown languages keep the configuration", func(t *testing.T) {
+		require.Equal(t, config.Level(), config.ForLanguage("en-US").Level())
+		require.Equal(t, config.Level(), config.ForLanguage("").Level())
+	})
+
+	t.Run("defaults are not shared", func(t *testing.T) {
+		_, ok := DefaultNoiseFilterConfig().Languages["pt"]
+		require.False(t, ok)
+	})
+}
+
+func TestLanguageLabel(t *testing.T) {
+	require.Equal(t, "zh", LanguageLabel("zh-TW"))
+	require.Equal(t, "yue", LanguageLabel("YUE"))
+	require.Equal(t, NoiseFilterLanguageUnknown, LanguageLabel(""))
+	require.Equal(t, NoiseFilterLanguageOther, LanguageLabel("klingon"))
+	require.Equal(t, NoiseFilterLanguageOther, LanguageLabel("x1"))
+}
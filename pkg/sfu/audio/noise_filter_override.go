@@ -0,0 +1,145 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// NoiseFilterRoomKey is the key of a JSON object room metadata selecting the noise filter configuration
+// of the room, the name of a preset or an object of overrides that may name a preset to start from, e. g.
+// {"agentix.noise_filter": "podcast"} or {"agentix.noise_filter": {"preset": "podcast", "threshold": 0.7}}
+const NoiseFilterRoomKey = "agentix.noise_filter"
+
+var (
+	ErrUnknownNoiseFilterPreset = errors.New("unknown noise filter preset")
+	ErrUnknownNoiseFilterModel  = errors.New("unknown noise filter model")
+)
+
+// NoiseFilterOverride changes the noise filter settings a room may tune, unset fields keep the node
+// configuration. Node wide settings like workers are not overridable.
+type NoiseFilterOverride struct {
+	// preset applied before the other fields, only in room metadata
+	Preset             string   `json:"preset,omitempty" yaml:"-"`
+	Enabled            *bool    `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	Threshold          *float32 `json:"threshold,omitempty" yaml:"threshold,omitempty"`
+	Aggressiveness     *int     `json:"aggressiveness,omitempty" yaml:"aggressiveness,omitempty"`
+	ComfortNoise       *bool    `json:"comfort_noise,omitempty" yaml:"comfort_noise,omitempty"`
+	NoiseGate          *bool    `json:"noise_gate,omitempty" yaml:"noise_gate,omitempty"`
+	MusicDetection     *bool    `json:"music_detection,omitempty" yaml:"music_detection,omitempty"`
+	EchoCancellation   *bool    `json:"echo_cancellation,omitempty" yaml:"echo_cancellation,omitempty"`
+	BandwidthExtension *bool    `json:"bandwidth_extension,omitempty" yaml:"bandwidth_extension,omitempty"`
+	// name of an entry of the configured models, empty for the built in model
+	Model *string `json:"model,omitempty" yaml:"model,omitempty"`
+}
+
+func (o NoiseFilterOverride) Apply(config NoiseFilterConfig) NoiseFilterConfig {
+	if o.Enabled != nil {
+		config.Enabled = *o.Enabled
+	}
+	if o.Threshold != nil {
+		config.Threshold = min(max(*o.Threshold, 0), 1)
+	}
+	if o.Aggressiveness != nil {
+		config.Aggressiveness = *o.Aggressiveness
+		// an explicit level wins over the deprecated flag
+		config.Aggressive = false
+	}
+	if o.ComfortNoise != nil {
+		config.ComfortNoise.Enabled = *o.ComfortNoise
+	}
+	if o.NoiseGate != nil {
+		config.NoiseGate.Enabled = *o.NoiseGate
+	}
+	if o.MusicDetection != nil {
+		config.MusicDetection.Enabled = *o.MusicDetection
+	}
+	if o.EchoCancellation != nil {
+		config.EchoCancellation.Enabled = *o.EchoCancellation
+	}
+	if o.BandwidthExtension != nil {
+		config.BandwidthExtension.Enabled = *o.BandwidthExtension
+	}
+	if o.Model != nil {
+		// unknown names are rejected by ValidateModel, the built in model is the fallback
+		config.ModelPath = config.Models[*o.Model]
+	}
+	return config
+}
+
+// ValidateModel returns an error if the override selects a model the configuration does not name
+func (o NoiseFilterOverride) ValidateModel(config NoiseFilterConfig) error {
+	if o.Model == nil || *o.Model == "" {
+		return nil
+	}
+	if _, ok := config.Models[*o.Model]; !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownNoiseFilterModel, *o.Model)
+	}
+	return nil
+}
+
+// ValidateNoiseFilterModels returns an error if a preset or language selects a model the configuration does not name
+func ValidateNoiseFilterModels(config NoiseFilterConfig, presets map[string]NoiseFilterOverride) error {
+	for name, preset := range presets {
+		if err := preset.ValidateModel(config); err != nil {
+			return fmt.Errorf("noise filter preset %q: %w", name, err)
+		}
+	}
+	for language, l := range config.Languages {
+		if err := l.ValidateModel(config); err != nil {
+			return fmt.Errorf("noise filter language %q: %w", language, err)
+		}
+	}
+	return nil
+}
+
+// RoomNoiseFilterConfig returns the noise filter configuration of a room, config overridden by the preset or
+// overrides the room metadata selects under NoiseFilterRoomKey. Returns false when the room does not override it.
+func RoomNoiseFilterConfig(config NoiseFilterConfig, presets map[string]NoiseFilterOverride, metadata string) (NoiseFilterConfig, bool, error) {
+	if metadata == "" {
+		return config, false, nil
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &doc); err != nil {
+		// metadata is not required to be JSON
+		return config, false, nil
+	}
+	raw, ok := doc[NoiseFilterRoomKey]
+	if !ok {
+		return config, false, nil
+	}
+
+	var override NoiseFilterOverride
+	if err := json.Unmarshal(raw, &override.Preset); err != nil {
+		if err := json.Unmarshal(raw, &override); err != nil {
+			return config, false, fmt.Errorf("invalid %s: %w", NoiseFilterRoomKey, err)
+		}
+	}
+	if err := override.ValidateModel(config); err != nil {
+		return config, false, err
+	}
+
+	if override.Preset != "" {
+		preset, ok := presets[override.Preset]
+		if !ok {
+			return config, false, fmt.Errorf("%w: %q", ErrUnknownNoiseFilterPreset, override.Preset)
+		}
+		config = preset.Apply(config)
+	}
+	return override.Apply(config), true, nil
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoomNoiseFilterConfig(t *testing.T) {
+	base := DefaultNoiseFilterConfig()
+	base.Enabled = true
+	base.Aggressive = true
+
+	disabled := false
+	aggressiveness := MaxAggressiveness
+	presets := map[string]NoiseFilterOverride{
+		"music":   {Enabled: &disabled},
+		"podcast": {Aggressiveness: &aggressiveness},
+	}
+
+	t.Run("keeps the node configuration", func(t *testing.T) {
+		for _, metadata := range []string{"", "plain text", `{"topic":"support"}`} {
+			config, ok, err := RoomNoiseFilterConfig(base, presets, metadata)
+			require.NoError(t, err)
+			require.False(t, ok)
+			require.Equal(t, base, config)
+		}
+	})
+
+	t.Run("applies a preset", func(t *testing.T) {
+		config, ok, err := RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":"music"}`)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.False(t, config.Enabled)
+
+		config, ok, err = RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":"podcast"}`)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.True(t, config.Enabled)
+		require.Equal(t, MaxAggressiveness, config.Level())
+		require.Equal(t, base.Workers, config.Workers)
+	})
+
+	t.Run("applies overrides on top of a preset", func(t *testing.T) {
+		config, ok, err := RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":{"preset":"podcast","threshold":0.7,"comfort_noise":true}}`)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, MaxAggressiveness, config.Level())
+		require.Equal(t, float32(0.7), config.Threshold)
+		require.True(t, config.ComfortNoise.Enabled)
+
+		// an explicit level replaces the deprecated flag
+		config, _, err = RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":{"aggressiveness":0}}`)
+		require.NoError(t, err)
+		require.Zero(t, config.Level())
+	})
+
+	t.Run("selects a named model", func(t *testing.T) {
+		base := base
+		base.ModelPath = "default.rnnn"
+		base.Models = map[string]string{"headset": "headset.rnnn"}
+
+		config, _, err := RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":{"model":"headset"}}`)
+		require.NoError(t, err)
+		require.Equal(t, "headset.rnnn", config.ModelPath)
+
+		config, _, err = RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":{"model":""}}`)
+		require.NoError(t, err)
+		require.Empty(t, config.ModelPath)
+
+		config, ok, err := RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":{"model":"speakerphone"}}`)
+		require.ErrorIs(t, err, ErrUnknownNoiseFilterModel)
+		require.False(t, ok)
+		require.Equal(t, base, config)
+
+		speakerphone := "speakerphone"
+		err = ValidateNoiseFilterModels(base, map[string]NoiseFilterOverride{"conference": {Model: &speakerphone}})
+		require.ErrorIs(t, err, ErrUnknownNoiseFilterModel)
+		require.NoError(t, ValidateNoiseFilterModels(base, presets))
+	})
+
+	t.Run("rejects unknown presets and invalid overrides", func(t *testing.T) {
+		config, ok, err := RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":"karaoke"}`)
+		require.ErrorIs(t, err, ErrUnknownNoiseFilterPreset)
+		require.False(t, ok)
+		require.Equal(t, base, config)
+
+		_, ok, err = RoomNoiseFilterConfig(base, presets, `{"agentix.noise_filter":{"threshold":"high"}}`)
+		require.Error(t, err)
+		require.False(t, ok)
+	})
+}
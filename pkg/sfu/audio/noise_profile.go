@@ -0,0 +1,168 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	// noise frames (10 ms each) needed before a learned profile replaces the prior one
+	minNoiseProfileFrames = 500
+	// smoothing of the noise floor, roughly a 1 second window of noise frames
+	noiseFloorAlpha = 0.01
+	// floor of the dBFS scale, used for digital silence
+	minDBFS = -100
+)
+
+// NoiseProfileConfig controls persistence of learned noise profiles across sessions
+type NoiseProfileConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how long a profile is kept after the last session of the participant
+	TTL time.Duration `yaml:"ttl,omitempty"`
+}
+
+var (
+	DefaultNoiseProfileConfig = NoiseProfileConfig{
+		TTL: 30 * 24 * time.Hour,
+	}
+)
+
+// NoiseProfile is what the noise filter learned about a participant's environment,
+// it is stored by identity and used to tune suppression from the first packet of the next session.
+type NoiseProfile struct {
+	// smoothed level of non-speech frames, in dBFS
+	NoiseFloor float32 `json:"noise_floor"`
+	// fraction of frames classified as speech
+	SpeechRatio float32 `json:"speech_ratio"`
+	// tuned suppression settings
+	Threshold      float32 `json:"threshold"`
+	Aggressiveness int     `json:"aggressiveness,omitempty"`
+	// Deprecated: set by profiles stored before Aggressiveness, true is taken as level 2
+	Aggressive bool `json:"aggressive,omitempty"`
+
+	Frames    uint64    `json:"frames"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Apply returns the config with the tuned settings of the profile, a nil profile leaves it unchanged
+func (p *NoiseProfile) Apply(config NoiseFilterConfig) NoiseFilterConfig {
+	if p == nil {
+		return config
+	}
+
+	config.Threshold = p.Threshold
+	config.Aggressiveness = max(config.Level(), NoiseFilterConfig{Aggressiveness: p.Aggressiveness, Aggressive: p.Aggressive}.Level())
+	config.Aggressive = false
+	return config
+}
+
+// --------------------------------------
+
+// NoiseProfileEstimator learns a NoiseProfile from the frames passing through the noise filter
+type NoiseProfileEstimator struct {
+	base  NoiseFilterConfig
+	prior *NoiseProfile
+
+	lock         sync.Mutex
+	noiseFloor   float64
+	noiseFrames  uint64
+	speechFrames uint64
+}
+
+func NewNoiseProfileEstimator(base NoiseFilterConfig, prior *NoiseProfile) *NoiseProfileEstimator {
+	e := &NoiseProfileEstimator{
+		base:       base,
+		prior:      prior,
+		noiseFloor: minDBFS,
+	}
+	if prior != nil {
+		e.noiseFloor = float64(prior.NoiseFloor)
+	}
+	return e
+}
+
+// Observe takes a frame of samples normalized to [-1, 1] and the speech decision of the filter
+func (e *NoiseProfileEstimator) Observe(samples []float32, speech bool) {
+	if len(samples) == 0 {
+		return
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if speech {
+		e.speechFrames++
+		return
+	}
+
+	level := rmsDBFS(samples)
+	if e.noiseFrames == 0 && e.prior == nil {
+		e.noiseFloor = level
+	} else {
+		e.noiseFloor += noiseFloorAlpha * (level - e.noiseFloor)
+	}
+	e.noiseFrames++
+}
+
+// Profile returns the learned profile, or the prior one until enough noise has been observed
+func (e *NoiseProfileEstimator) Profile() *NoiseProfile {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.noiseFrames < minNoiseProfileFrames {
+		return e.prior
+	}
+
+	p := &NoiseProfile{
+		NoiseFloor:  float32(e.noiseFloor),
+		SpeechRatio: float32(e.speechFrames) / float32(e.speechFrames+e.noiseFrames),
+		Threshold:   e.base.Threshold,
+		Frames:      e.speechFrames + e.noiseFrames,
+		UpdatedAt:   time.Now(),
+	}
+	switch {
+	case e.noiseFloor >= -35:
+		// loud environment, e. g. street or open office
+		p.Threshold = min(e.base.Threshold+0.2, 0.9)
+		p.Aggressiveness = aggressiveLevel
+	case e.noiseFloor >= -50:
+		p.Threshold = min(e.base.Threshold+0.1, 0.9)
+	}
+	return p
+}
+
+// NoiseFrames returns the number of non-speech frames observed
+func (e *NoiseProfileEstimator) NoiseFrames() uint64 {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return e.noiseFrames
+}
+
+func rmsDBFS(samples []float32) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	rms := math.Sqrt(sum / float64(len(samples)))
+	if rms == 0 {
+		return minDBFS
+	}
+	return max(20*math.Log10(rms), minDBFS)
+}
@@ -0,0 +1,87 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func noiseFrame(amplitude float32) []float32 {
+	frame := make([]float32, 480)
+	for i := range frame {
+		if i%2 == 0 {
+			frame[i] = amplitude
+		} else {
+			frame[i] = -amplitude
+		}
+	}
+	return frame
+}
+
+func TestNoiseProfileEstimator(t *testing.T) {
+	base := NoiseFilterConfig{Enabled: true, Threshold: 0.5}
+
+	t.Run("returns prior until enough noise is observed", func(t *testing.T) {
+		prior := &NoiseProfile{NoiseFloor: -40, Threshold: 0.6}
+		e := NewNoiseProfileEstimator(base, prior)
+		for i := 0; i < minNoiseProfileFrames-1; i++ {
+			e.Observe(noiseFrame(0.001), false)
+		}
+		require.Equal(t, prior, e.Profile())
+
+		e = NewNoiseProfileEstimator(base, nil)
+		require.Nil(t, e.Profile())
+	})
+
+	t.Run("quiet environment keeps base settings", func(t *testing.T) {
+		e := NewNoiseProfileEstimator(base, nil)
+		for i := 0; i < minNoiseProfileFrames; i++ {
+			e.Observe(noiseFrame(0.001), false) // -60 dBFS
+			e.Observe(noiseFrame(0.5), true)
+		}
+
+		p := e.Profile()
+		require.NotNil(t, p)
+		require.InDelta(t, -60, p.NoiseFloor, 0.5)
+		require.InDelta(t, 0.5, p.SpeechRatio, 0.01)
+		require.Equal(t, float32(0.5), p.Threshold)
+		require.Zero(t, p.Aggressiveness)
+		require.Equal(t, uint64(2*minNoiseProfileFrames), p.Frames)
+	})
+
+	t.Run("loud environment tunes suppression", func(t *testing.T) {
+		e := NewNoiseProfileEstimator(base, nil)
+		for i := 0; i < minNoiseProfileFrames; i++ {
+			e.Observe(noiseFrame(0.05), false) // -26 dBFS
+		}
+
+		p := e.Profile()
+		require.Equal(t, 2, p.Aggressiveness)
+		require.InDelta(t, 0.7, p.Threshold, 0.001)
+
+		config := p.Apply(base)
+		require.True(t, config.Enabled)
+		require.Equal(t, 2, config.Level())
+		require.InDelta(t, 0.7, config.Threshold, 0.001)
+	})
+
+	t.Run("nil profile does not change config", func(t *testing.T) {
+		var p *NoiseProfile
+		require.Equal(t, base, p.Apply(base))
+	})
+}
@@ -0,0 +1,161 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// close threshold below the open threshold when none is configured, keeps the gate from chattering
+	// on probabilities around a single threshold
+	noiseGateHysteresis = 0.15
+	// lowest floor of the gate, a floor of 0 would make the ramp in dB endless
+	noiseGateMinFloorDB = -80
+)
+
+// NoiseGateConfig shapes the transitions between speech and noise frames of the noise filter. Instead of
+// switching each frame between the denoised speech and attenuated noise, the gain follows an envelope:
+// it rises over the attack once the speech probability reaches the open threshold, stays open for the hold
+// after it falls below the close threshold and falls to the suppression level over the release.
+type NoiseGateConfig struct {
+	Enabled bool `json:"enabled" yaml:"enabled,omitempty"`
+	// speech probability opening the gate, 0 for the VAD threshold of the suppression level
+	OpenThreshold float32 `json:"open_threshold" yaml:"open_threshold,omitempty"`
+	// speech probability below which the open gate starts to close, 0 for somewhat below the open threshold
+	CloseThreshold float32 `json:"close_threshold" yaml:"close_threshold,omitempty"`
+	// time to open from the suppression level to unity gain
+	Attack time.Duration `json:"attack" yaml:"attack,omitempty"`
+	// time the gate stays open after the probability fell below the close threshold
+	Hold time.Duration `json:"hold" yaml:"hold,omitempty"`
+	// time to close from unity gain to the suppression level
+	Release time.Duration `json:"release" yaml:"release,omitempty"`
+}
+
+var (
+	DefaultNoiseGateConfig = NoiseGateConfig{
+		Attack:  5 * time.Millisecond,
+		Hold:    150 * time.Millisecond,
+		Release: 100 * time.Millisecond,
+	}
+)
+
+// Thresholds returns the open and close thresholds of the gate, those not configured derived from
+// vadThreshold, the VAD threshold of the suppression level
+func (c NoiseGateConfig) Thresholds(vadThreshold float32) (float32, float32) {
+	open := c.OpenThreshold
+	if open <= 0 || open > 1 {
+		open = vadThreshold
+	}
+	closeAt := c.CloseThreshold
+	if closeAt <= 0 || closeAt > open {
+		closeAt = max(open-noiseGateHysteresis, 0)
+	}
+	return open, closeAt
+}
+
+// NoiseGate applies the envelope of NoiseGateConfig to the frames of a single channel. The gain is ramped
+// linearly in dB between the floor and unity, per sample so that transitions within a frame are smooth.
+// Not safe for concurrent use.
+type NoiseGate struct {
+	openThreshold  float32
+	closeThreshold float32
+	floorDB        float64
+	// change of the gain per sample in dB
+	attackStep  float64
+	releaseStep float64
+	holdSamples int
+
+	open bool
+	// samples the gate stays open for before it releases
+	holding int
+	gainDB  float64
+}
+
+// NewNoiseGate creates a closed gate for audio at sampleRate. vadThreshold is the VAD threshold and floorGain
+// the gain of noise frames of the suppression level, see NoiseSuppression.
+func NewNoiseGate(config NoiseGateConfig, vadThreshold float32, floorGain float32, sampleRate int) *NoiseGate {
+	floorDB := float64(noiseGateMinFloorDB)
+	if floorGain > 0 {
+		floorDB = min(max(20*math.Log10(float64(floorGain)), noiseGateMinFloorDB), 0)
+	}
+
+	g := &NoiseGate{
+		floorDB:     floorDB,
+		attackStep:  rampStep(floorDB, config.Attack, sampleRate),
+		releaseStep: rampStep(floorDB, config.Release, sampleRate),
+		holdSamples: int(config.Hold * time.Duration(sampleRate) / time.Second),
+		gainDB:      floorDB,
+	}
+	g.openThreshold, g.closeThreshold = config.Thresholds(vadThreshold)
+	return g
+}
+
+// rampStep returns the change in dB per sample covering the range from floorDB to unity in duration
+func rampStep(floorDB float64, duration time.Duration, sampleRate int) float64 {
+	samples := duration.Seconds() * float64(sampleRate)
+	if samples < 1 {
+		return -floorDB
+	}
+	return -floorDB / samples
+}
+
+// Process applies the gate to a frame of normalized samples in place, probability being the speech probability
+// of the frame. background, when not nil, is mixed in as the gate closes, e. g. comfort noise replacing the
+// attenuated noise. It must be at least as long as frame. Returns whether the gate was open during the frame.
+func (g *NoiseGate) Process(frame []float32, background []float32, probability float32) bool {
+	switch {
+	case probability >= g.openThreshold:
+		g.open = true
+		g.holding = g.holdSamples
+	case g.open && probability >= g.closeThreshold:
+		// between the thresholds an open gate stays open
+		g.holding = g.holdSamples
+	}
+
+	wasOpen := g.open
+	for i, sample := range frame {
+		if g.open {
+			g.gainDB = min(g.gainDB+g.attackStep, 0)
+			if probability < g.closeThreshold {
+				g.holding--
+				g.open = g.holding > 0
+			}
+		} else {
+			g.gainDB = max(g.gainDB-g.releaseStep, g.floorDB)
+		}
+
+		gain := float32(g.Gain())
+		if background != nil {
+			sample = sample*gain + background[i]*(1-gain)
+		} else {
+			sample *= gain
+		}
+		frame[i] = min(max(sample, -1), 1)
+	}
+	return wasOpen || g.gainDB > g.floorDB
+}
+
+// IsOpen returns true while the gate is open or holding, the gain may still be ramping
+func (g *NoiseGate) IsOpen() bool {
+	return g.open
+}
+
+// Gain returns the current linear gain of the gate
+func (g *NoiseGate) Gain() float64 {
+	return math.Pow(10, g.gainDB/20)
+}
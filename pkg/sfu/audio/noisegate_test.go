@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func gateFrame(value float32) []float32 {
+	frame := make([]float32, 480)
+	for i := range frame {
+		frame[i] = value
+	}
+	return frame
+}
+
+func TestNoiseGateThresholds(t *testing.T) {
+	open, closeAt := NoiseGateConfig{}.Thresholds(0.5)
+	require.Equal(t, float32(0.5), open)
+	require.InDelta(t, 0.35, closeAt, 1e-6)
+
+	open, closeAt = NoiseGateConfig{OpenThreshold: 0.7, CloseThreshold: 0.4}.Thresholds(0.5)
+	require.Equal(t, float32(0.7), open)
+	require.Equal(t, float32(0.4), closeAt)
+
+	// a close threshold above the open one is ignored
+	_, closeAt = NoiseGateConfig{OpenThreshold: 0.3, CloseThreshold: 0.6}.Thresholds(0.5)
+	require.InDelta(t, 0.15, closeAt, 1e-6)
+}
+
+func TestNoiseGateEnvelope(t *testing.T) {
+	config := NoiseGateConfig{
+		Enabled: true,
+		Attack:  5 * time.Millisecond,
+		Hold:    20 * time.Millisecond,
+		Release: 20 * time.Millisecond,
+	}
+	gate := NewNoiseGate(config, 0.5, 0.01, 48000)
+	require.InDelta(t, 0.01, gate.Gain(), 1e-6)
+
+	// noise stays at the floor
+	frame := gateFrame(0.5)
+	require.False(t, gate.Process(frame, nil, 0.1))
+	require.InDelta(t, 0.005, frame[len(frame)-1], 1e-6)
+
+	// speech opens over the attack, halfway through the frame it is at unity
+	frame = gateFrame(0.5)
+	require.True(t, gate.Process(frame, nil, 0.9))
+	require.Less(t, frame[0], float32(0.01))
+	require.Less(t, frame[100], frame[200])
+	require.InDelta(t, 0.5, frame[240], 1e-6)
+	require.InDelta(t, 1, gate.Gain(), 1e-6)
+
+	// between the thresholds the gate stays open
+	frame = gateFrame(0.5)
+	gate.Process(frame, nil, 0.4)
+	require.True(t, gate.IsOpen())
+	require.InDelta(t, 0.5, frame[len(frame)-1], 1e-6)
+
+	// below the close threshold it holds for 20 ms before releasing over 20 ms
+	for range 2 {
+		frame = gateFrame(0.5)
+		gate.Process(frame, nil, 0.1)
+		require.InDelta(t, 0.5, frame[len(frame)-1], 1e-6)
+	}
+	require.False(t, gate.IsOpen())
+
+	frame = gateFrame(0.5)
+	require.True(t, gate.Process(frame, nil, 0.1))
+	require.Less(t, frame[len(frame)-1], frame[0])
+	require.Greater(t, frame[len(frame)-1], float32(0.005))
+
+	frame = gateFrame(0.5)
+	gate.Process(frame, nil, 0.1)
+	require.InDelta(t, 0.005, frame[len(frame)-1], 1e-6)
+	require.False(t, gate.Process(gateFrame(0.5), nil, 0.1))
+}
+
+func TestNoiseGateBackground(t *testing.T) {
+	gate := NewNoiseGate(NoiseGateConfig{Enabled: true}, 0.5, 0.001, 48000)
+
+	// closed, the background replaces nearly all of the frame
+	frame := gateFrame(0.5)
+	gate.Process(frame, gateFrame(0.1), 0)
+	require.InDelta(t, 0.1, frame[0], 0.001)
+
+	// open without attack, the frame passes as is
+	frame = gateFrame(0.5)
+	gate.Process(frame, gateFrame(0.1), 1)
+	require.InDelta(t, 0.5, frame[0], 1e-6)
+}
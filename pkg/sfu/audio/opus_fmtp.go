@@ -0,0 +1,193 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OpusFmtpMode decides an Opus parameter of the negotiated SDP
+type OpusFmtpMode string
+
+const (
+	// follow what the publisher requested for the track
+	OpusFmtpModeTrack    OpusFmtpMode = ""
+	OpusFmtpModeEnabled  OpusFmtpMode = "enabled"
+	OpusFmtpModeDisabled OpusFmtpMode = "disabled"
+)
+
+func (m OpusFmtpMode) resolve(track bool) bool {
+	switch m {
+	case OpusFmtpModeEnabled:
+		return true
+	case OpusFmtpModeDisabled:
+		return false
+	default:
+		return track
+	}
+}
+
+// OpusFmtpConfig enforces Opus parameters on publishers of a room, RFC 7587 section 6.1.
+// The parameters are set on the answer to publishers, which is what their encoders follow,
+// so server side processing can rely on them regardless of client settings.
+type OpusFmtpConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// highest sample rate publishers should encode for, 0 leaves it to the encoder
+	MaxPlaybackRate uint32       `yaml:"max_playback_rate,omitempty"`
+	Stereo          OpusFmtpMode `yaml:"stereo,omitempty"`
+	DTX             OpusFmtpMode `yaml:"dtx,omitempty"`
+}
+
+func (c OpusFmtpConfig) Validate() error {
+	for name, mode := range map[string]OpusFmtpMode{"stereo": c.Stereo, "dtx": c.DTX} {
+		switch mode {
+		case OpusFmtpModeTrack, OpusFmtpModeEnabled, OpusFmtpModeDisabled:
+		default:
+			return fmt.Errorf("invalid opus fmtp %s mode %q", name, mode)
+		}
+	}
+	if c.MaxPlaybackRate != 0 && (c.MaxPlaybackRate < 8000 || c.MaxPlaybackRate > OpusSampleRate) {
+		return fmt.Errorf("invalid opus fmtp max playback rate %d", c.MaxPlaybackRate)
+	}
+	return nil
+}
+
+// OpusFmtpParams are the parameters requested from an Opus publisher
+type OpusFmtpParams struct {
+	MaxPlaybackRate uint32
+	Stereo          bool
+	DTX             bool
+}
+
+// Resolve returns the parameters for a track given what the publisher asked for,
+// without enforcement the track settings are used as is
+func (c OpusFmtpConfig) Resolve(trackStereo bool, trackDTX bool) OpusFmtpParams {
+	if !c.Enabled {
+		return OpusFmtpParams{
+			Stereo: trackStereo,
+			DTX:    trackDTX,
+		}
+	}
+
+	return OpusFmtpParams{
+		MaxPlaybackRate: c.MaxPlaybackRate,
+		Stereo:          c.Stereo.resolve(trackStereo),
+		DTX:             c.DTX.resolve(trackDTX),
+	}
+}
+
+// RewriteOpusFmtp sets the parameters on an Opus fmtp line, other parameters are kept in place
+func RewriteOpusFmtp(fmtp string, params OpusFmtpParams) string {
+	type param struct {
+		key   string
+		value string
+	}
+	var fmtpParams []param
+	for _, kv := range strings.Split(fmtp, ";") {
+		kv = strings.TrimSpace(kv)
+		if kv == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(kv, "=")
+		fmtpParams = append(fmtpParams, param{key: strings.ToLower(key), value: value})
+	}
+
+	set := func(key string, value string) {
+		for i := range fmtpParams {
+			if fmtpParams[i].key == key {
+				fmtpParams[i].value = value
+				return
+			}
+		}
+		fmtpParams = append(fmtpParams, param{key: key, value: value})
+	}
+	remove := func(key string) {
+		for i := range fmtpParams {
+			if fmtpParams[i].key == key {
+				fmtpParams = append(fmtpParams[:i], fmtpParams[i+1:]...)
+				return
+			}
+		}
+	}
+
+	if params.DTX {
+		set("usedtx", "1")
+	} else {
+		remove("usedtx")
+	}
+	if params.Stereo {
+		set("stereo", "1")
+		set("maxaveragebitrate", "510000")
+	} else {
+		remove("stereo")
+		remove("maxaveragebitrate")
+	}
+	if params.MaxPlaybackRate != 0 {
+		set("maxplaybackrate", strconv.FormatUint(uint64(params.MaxPlaybackRate), 10))
+	}
+
+	parts := make([]string, 0, len(fmtpParams))
+	for _, p := range fmtpParams {
+		if p.value == "" {
+			parts = append(parts, p.key)
+		} else {
+			parts = append(parts, p.key+"="+p.value)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// OpusEncoderSettings are the settings of the encoder of an Opus stream that its receivers rely on,
+// a stream re-encoded on the server keeps them
+type OpusEncoderSettings struct {
+	// in-band forward error correction, RFC 7587 useinbandfec
+	InBandFEC bool
+	// discontinuous transmission during silence, RFC 7587 usedtx
+	DTX bool
+}
+
+// OpusEncoderSettingsFromFmtp returns the encoder settings an Opus fmtp line signals
+func OpusEncoderSettingsFromFmtp(fmtp string) OpusEncoderSettings {
+	var settings OpusEncoderSettings
+	for _, kv := range strings.Split(fmtp, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(kv), "=")
+		enabled := strings.TrimSpace(value) == "1"
+		switch strings.ToLower(key) {
+		case "useinbandfec":
+			settings.InBandFEC = enabled
+		case "usedtx":
+			settings.DTX = enabled
+		}
+	}
+	return settings
+}
+
+// OpusChannels returns the number of channels to decode an Opus stream with, 2 when its fmtp line signals
+// stereo and 1 otherwise. The rtpmap of Opus always has two channels, RFC 7587 section 7.
+func OpusChannels(fmtp string) int {
+	for _, kv := range strings.Split(fmtp, ";") {
+		key, value, _ := strings.Cut(strings.TrimSpace(kv), "=")
+		switch strings.ToLower(key) {
+		case "stereo", "sprop-stereo":
+			if strings.TrimSpace(value) == "1" {
+				return 2
+			}
+		}
+	}
+	return 1
+}
@@ -0,0 +1,72 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteOpusFmtp(t *testing.T) {
+	t.Run("track settings", func(t *testing.T) {
+		params := OpusFmtpConfig{}.Resolve(true, true)
+		require.Equal(t, "minptime=10;useinbandfec=1;usedtx=1;stereo=1;maxaveragebitrate=510000", RewriteOpusFmtp("minptime=10;useinbandfec=1", params))
+	})
+
+	t.Run("enforced", func(t *testing.T) {
+		config := OpusFmtpConfig{
+			Enabled:         true,
+			MaxPlaybackRate: 16000,
+			Stereo:          OpusFmtpModeDisabled,
+			DTX:             OpusFmtpModeEnabled,
+		}
+		params := config.Resolve(true, false)
+		require.Equal(t, OpusFmtpParams{MaxPlaybackRate: 16000, DTX: true}, params)
+		require.Equal(
+			t,
+			"minptime=10;useinbandfec=1;maxplaybackrate=16000;usedtx=1",
+			RewriteOpusFmtp("minptime=10;stereo=1;useinbandfec=1;maxplaybackrate=48000;maxaveragebitrate=510000", params),
+		)
+	})
+
+	t.Run("follow track when mode unset", func(t *testing.T) {
+		config := OpusFmtpConfig{Enabled: true, DTX: OpusFmtpModeDisabled}
+		require.Equal(t, "minptime=10;stereo=1;maxaveragebitrate=510000", RewriteOpusFmtp("minptime=10;usedtx=1", config.Resolve(true, true)))
+	})
+}
+
+func TestOpusFmtpConfigValidate(t *testing.T) {
+	require.NoError(t, OpusFmtpConfig{}.Validate())
+	require.NoError(t, OpusFmtpConfig{Enabled: true, MaxPlaybackRate: 24000, Stereo: OpusFmtpModeEnabled}.Validate())
+	require.Error(t, OpusFmtpConfig{DTX: "on"}.Validate())
+	require.Error(t, OpusFmtpConfig{MaxPlaybackRate: 96000}.Validate())
+}
+
+func TestOpusChannels(t *testing.T) {
+	require.Equal(t, 1, OpusChannels(""))
+	require.Equal(t, 1, OpusChannels("minptime=10;useinbandfec=1"))
+	require.Equal(t, 1, OpusChannels("minptime=10;stereo=0"))
+	require.Equal(t, 2, OpusChannels("minptime=10;stereo=1"))
+	require.Equal(t, 2, OpusChannels("minptime=10; sprop-stereo=1;useinbandfec=1"))
+}
+
+func TestOpusEncoderSettingsFromFmtp(t *testing.T) {
+	require.Equal(t, OpusEncoderSettings{}, OpusEncoderSettingsFromFmtp(""))
+	require.Equal(t, OpusEncoderSettings{InBandFEC: true}, OpusEncoderSettingsFromFmtp("minptime=10;useinbandfec=1"))
+	require.Equal(t, OpusEncoderSettings{InBandFEC: true, DTX: true}, OpusEncoderSettingsFromFmtp("useinbandfec=1; usedtx=1"))
+	require.Equal(t, OpusEncoderSettings{DTX: true}, OpusEncoderSettingsFromFmtp("useinbandfec=0;usedtx=1"))
+}
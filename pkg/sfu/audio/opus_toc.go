@@ -0,0 +1,114 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+// ComfortNoisePayloadType is the static RTP payload type of RFC 3389 comfort noise, which G.711 endpoints
+// send instead of audio during silence
+const ComfortNoisePayloadType = 13
+
+type OpusBandwidth int
+
+const (
+	OpusBandwidthNarrowband    OpusBandwidth = iota // 4 kHz
+	OpusBandwidthMediumband                         // 6 kHz
+	OpusBandwidthWideband                           // 8 kHz
+	OpusBandwidthSuperwideband                      // 12 kHz
+	OpusBandwidthFullband                           // 20 kHz
+)
+
+func (b OpusBandwidth) String() string {
+	switch b {
+	case OpusBandwidthNarrowband:
+		return "narrowband"
+	case OpusBandwidthMediumband:
+		return "mediumband"
+	case OpusBandwidthWideband:
+		return "wideband"
+	case OpusBandwidthSuperwideband:
+		return "superwideband"
+	case OpusBandwidthFullband:
+		return "fullband"
+	default:
+		return "unknown"
+	}
+}
+
+// OpusTOC is the table of contents byte of an Opus packet, RFC 6716 section 3.1
+type OpusTOC struct {
+	Config        uint8
+	Bandwidth     OpusBandwidth
+	FrameDuration time.Duration
+	Stereo        bool
+	Frames        int
+}
+
+func (t OpusTOC) Duration() time.Duration {
+	return t.FrameDuration * time.Duration(t.Frames)
+}
+
+func ParseOpusTOC(payload []byte) (OpusTOC, bool) {
+	if len(payload) == 0 {
+		return OpusTOC{}, false
+	}
+
+	toc := OpusTOC{
+		Config: payload[0] >> 3,
+		Stereo: payload[0]&0x4 != 0,
+	}
+
+	switch c := toc.Config; {
+	case c < 12:
+		// SILK only
+		toc.Bandwidth = OpusBandwidth(c / 4)
+		toc.FrameDuration = []time.Duration{10, 20, 40, 60}[c%4] * time.Millisecond
+	case c < 16:
+		// hybrid
+		toc.Bandwidth = OpusBandwidthSuperwideband + OpusBandwidth((c-12)/2)
+		toc.FrameDuration = []time.Duration{10, 20}[c%2] * time.Millisecond
+	default:
+		// CELT only, no mediumband
+		toc.Bandwidth = []OpusBandwidth{
+			OpusBandwidthNarrowband,
+			OpusBandwidthWideband,
+			OpusBandwidthSuperwideband,
+			OpusBandwidthFullband,
+		}[(c-16)/4]
+		toc.FrameDuration = []time.Duration{2500, 5000, 10000, 20000}[c%4] * time.Microsecond
+	}
+
+	switch payload[0] & 0x3 {
+	case 0:
+		toc.Frames = 1
+	case 1, 2:
+		toc.Frames = 2
+	case 3:
+		if len(payload) < 2 {
+			return OpusTOC{}, false
+		}
+		toc.Frames = int(payload[1] & 0x3f)
+	}
+	return toc, toc.Frames != 0
+}
+
+// IsOpusDTX returns whether payload is an Opus DTX frame without audio. In DTX an encoder sends a packet of at
+// most two bytes every 400 ms during silence and nothing in between.
+func IsOpusDTX(payload []byte) bool {
+	return len(payload) <= opusDTXPacketSize
+}
@@ -0,0 +1,47 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+// Conversions between int16 PCM and the normalized float32 samples of the DSP stages. They run for every frame of
+// every decoded track, amd64 with AVX2 and arm64 convert with SIMD, see pcmconv_amd64.s and pcmconv_arm64.s.
+// The purego build tag keeps the portable loops.
+
+// Int16ToFloat32 converts src to samples normalized to [-1, 1) in dst, which has to hold len(src) samples
+func Int16ToFloat32(dst []float32, src []int16) {
+	dst = dst[:len(src)]
+	n := int16ToFloat32SIMD(dst, src)
+	int16ToFloat32Generic(dst[n:], src[n:])
+}
+
+// Float32ToInt16 converts the normalized samples of src to dst, which has to hold len(src) samples.
+// Samples are clipped to the range of int16 and rounded towards zero.
+func Float32ToInt16(dst []int16, src []float32) {
+	dst = dst[:len(src)]
+	n := float32ToInt16SIMD(dst, src)
+	float32ToInt16Generic(dst[n:], src[n:])
+}
+
+func int16ToFloat32Generic(dst []float32, src []int16) {
+	for i, sample := range src {
+		dst[i] = float32(sample) / 32768.0
+	}
+}
+
+func float32ToInt16Generic(dst []int16, src []float32) {
+	for i, sample := range src {
+		dst[i] = int16(min(max(sample*32768.0, -32768), 32767))
+	}
+}
@@ -0,0 +1,53 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !purego
+
+package audio
+
+import (
+	"golang.org/x/sys/cpu"
+)
+
+// samples converted per iteration of the AVX2 loops
+const pcmConvBlock = 8
+
+var hasAVX2 = cpu.X86.HasAVX2
+
+//go:noescape
+func int16ToFloat32AVX2(dst *float32, src *int16, n int)
+
+//go:noescape
+func float32ToInt16AVX2(dst *int16, src *float32, n int)
+
+// int16ToFloat32SIMD converts the whole blocks of src and returns the samples converted
+func int16ToFloat32SIMD(dst []float32, src []int16) int {
+	n := len(src) - len(src)%pcmConvBlock
+	if !hasAVX2 || n == 0 {
+		return 0
+	}
+	int16ToFloat32AVX2(&dst[0], &src[0], n)
+	return n
+}
+
+// float32ToInt16SIMD converts the whole blocks of src and returns the samples converted
+func float32ToInt16SIMD(dst []int16, src []float32) int {
+	n := len(src) - len(src)%pcmConvBlock
+	if !hasAVX2 || n == 0 {
+		return 0
+	}
+	float32ToInt16AVX2(&dst[0], &src[0], n)
+	return n
+}
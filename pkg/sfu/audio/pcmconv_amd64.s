@@ -0,0 +1,82 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !purego
+
+#include "textflag.h"
+
+// 2^-15 and 2^15 as float32
+#define SCALE_DOWN $0x38000000
+#define SCALE_UP $0x47000000
+// -32768 and 32767 as float32
+#define MIN_INT16 $0xc7000000
+#define MAX_INT16 $0x46fffe00
+
+// func int16ToFloat32AVX2(dst *float32, src *int16, n int)
+// n is a multiple of 8
+TEXT ·int16ToFloat32AVX2(SB), NOSPLIT, $0-24
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+
+	MOVL         SCALE_DOWN, AX
+	MOVL         AX, X1
+	VPBROADCASTD X1, Y1
+
+loop:
+	VPMOVSXWD (SI), Y0
+	VCVTDQ2PS Y0, Y0
+	VMULPS    Y1, Y0, Y0
+	VMOVUPS   Y0, (DI)
+	ADDQ      $16, SI
+	ADDQ      $32, DI
+	SUBQ      $8, CX
+	JNZ       loop
+
+	VZEROUPPER
+	RET
+
+// func float32ToInt16AVX2(dst *int16, src *float32, n int)
+// n is a multiple of 8
+TEXT ·float32ToInt16AVX2(SB), NOSPLIT, $0-24
+	MOVQ dst+0(FP), DI
+	MOVQ src+8(FP), SI
+	MOVQ n+16(FP), CX
+
+	MOVL         SCALE_UP, AX
+	MOVL         AX, X1
+	VPBROADCASTD X1, Y1
+	MOVL         MIN_INT16, AX
+	MOVL         AX, X2
+	VPBROADCASTD X2, Y2
+	MOVL         MAX_INT16, AX
+	MOVL         AX, X3
+	VPBROADCASTD X3, Y3
+
+loop:
+	VMULPS       (SI), Y1, Y0
+	VMAXPS       Y2, Y0, Y0
+	VMINPS       Y3, Y0, Y0
+	VCVTTPS2DQ   Y0, Y0
+	VEXTRACTI128 $1, Y0, X4
+	VPACKSSDW    X4, X0, X0
+	VMOVDQU      X0, (DI)
+	ADDQ         $32, SI
+	ADDQ         $16, DI
+	SUBQ         $8, CX
+	JNZ          loop
+
+	VZEROUPPER
+	RET
@@ -0,0 +1,56 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !purego
+
+#include "textflag.h"
+
+// Instructions without a mnemonic in the assembler of older toolchains are encoded as words.
+
+// func int16ToFloat32NEON(dst *float32, src *int16, n int)
+// n is a multiple of 8
+TEXT ·int16ToFloat32NEON(SB), NOSPLIT, $0-24
+	MOVD dst+0(FP), R0
+	MOVD src+8(FP), R1
+	MOVD n+16(FP), R2
+
+loop:
+	VLD1.P 16(R1), [V0.H8]
+	WORD   $0x0f10a401     // SXTL   V1.4S, V0.4H
+	WORD   $0x4f10a402     // SXTL2  V2.4S, V0.8H
+	WORD   $0x4f31e421     // SCVTF  V1.4S, V1.4S, #15
+	WORD   $0x4f31e442     // SCVTF  V2.4S, V2.4S, #15
+	VST1.P [V1.S4, V2.S4], 32(R0)
+	SUBS   $8, R2, R2
+	BNE    loop
+	RET
+
+// func float32ToInt16NEON(dst *int16, src *float32, n int)
+// n is a multiple of 8, the conversion saturates like the clipping of the portable loop
+TEXT ·float32ToInt16NEON(SB), NOSPLIT, $0-24
+	MOVD dst+0(FP), R0
+	MOVD src+8(FP), R1
+	MOVD n+16(FP), R2
+
+loop:
+	VLD1.P 32(R1), [V0.S4, V1.S4]
+	WORD   $0x4f31fc00     // FCVTZS V0.4S, V0.4S, #15
+	WORD   $0x4f31fc21     // FCVTZS V1.4S, V1.4S, #15
+	WORD   $0x0e614802     // SQXTN  V2.4H, V0.4S
+	WORD   $0x4e614822     // SQXTN2 V2.8H, V1.4S
+	VST1.P [V2.H8], 16(R0)
+	SUBS   $8, R2, R2
+	BNE    loop
+	RET
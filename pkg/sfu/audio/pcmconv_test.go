@@ -0,0 +1,102 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// tracks converted per benchmark iteration, a loaded node
+	pcmConvBenchTracks = 500
+	// 10 ms at 48 kHz, the frames of the denoiser
+	pcmConvBenchFrameSize = 480
+)
+
+func TestInt16ToFloat32(t *testing.T) {
+	// lengths not a multiple of the SIMD block take the portable loop for the tail
+	for _, n := range []int{0, 3, 8, 480, 483} {
+		src := make([]int16, n)
+		for i := range src {
+			src[i] = int16(rand.IntN(1 << 16))
+		}
+		if n > 2 {
+			src[0], src[1] = math.MinInt16, math.MaxInt16
+		}
+
+		expected := make([]float32, n)
+		int16ToFloat32Generic(expected, src)
+		actual := make([]float32, n)
+		Int16ToFloat32(actual, src)
+		require.Equal(t, expected, actual, "length %d", n)
+	}
+}
+
+func TestFloat32ToInt16(t *testing.T) {
+	for _, n := range []int{0, 5, 8, 480, 485} {
+		src := make([]float32, n)
+		for i := range src {
+			src[i] = rand.Float32()*2.4 - 1.2
+		}
+		if n > 4 {
+			src[0], src[1], src[2], src[3] = 1, -1, 1e10, -1e10
+		}
+
+		expected := make([]int16, n)
+		float32ToInt16Generic(expected, src)
+		actual := make([]int16, n)
+		Float32ToInt16(actual, src)
+		require.Equal(t, expected, actual, "length %d", n)
+	}
+}
+
+func newPCMConvBenchFrames() ([][]int16, []float32) {
+	frames := make([][]int16, pcmConvBenchTracks)
+	for i := range frames {
+		frames[i] = make([]int16, pcmConvBenchFrameSize)
+		for j := range frames[i] {
+			frames[i][j] = int16(rand.IntN(1 << 16))
+		}
+	}
+	return frames, make([]float32, pcmConvBenchFrameSize)
+}
+
+// a 10 ms frame of every track, both ways, as the noise filter converts it for the denoiser
+func BenchmarkPCMConversion(b *testing.B) {
+	frames, samples := newPCMConvBenchFrames()
+	b.SetBytes(int64(pcmConvBenchTracks * pcmConvBenchFrameSize * 2))
+	for b.Loop() {
+		for _, frame := range frames {
+			Int16ToFloat32(samples, frame)
+			Float32ToInt16(frame, samples)
+		}
+	}
+}
+
+func BenchmarkPCMConversionGeneric(b *testing.B) {
+	frames, samples := newPCMConvBenchFrames()
+	b.SetBytes(int64(pcmConvBenchTracks * pcmConvBenchFrameSize * 2))
+	for b.Loop() {
+		for _, frame := range frames {
+			int16ToFloat32Generic(samples, frame)
+			float32ToInt16Generic(frame, samples)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+const (
+	// redundant packets a RED packet may carry, their timestamp offsets have to fit into 14 bits
+	MaxREDDistance = 8
+)
+
+// REDGenerationConfig wraps the Opus packets of Opus only tracks in RED (RFC 2198) for subscribers that
+// negotiated it, only while they report losses. Other subscribers get plain Opus.
+type REDGenerationConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// previous packets carried redundantly by each RED packet, 1 to MaxREDDistance
+	Distance int `yaml:"distance,omitempty"`
+	// RED is sent above this packet loss
+	LossThreshold float64 `yaml:"loss_threshold,omitempty"`
+	// plain Opus is sent again below this packet loss
+	ClearLoss float64 `yaml:"clear_loss,omitempty"`
+	// how often the loss is evaluated
+	Interval time.Duration `yaml:"interval,omitempty"`
+}
+
+var (
+	DefaultREDGenerationConfig = REDGenerationConfig{
+		Distance:      2,
+		LossThreshold: 0.03,
+		ClearLoss:     0.01,
+		Interval:      5 * time.Second,
+	}
+)
+
+// REDDistance returns the configured distance clamped to 1 to MaxREDDistance, the default when unset
+func (c REDGenerationConfig) REDDistance() int {
+	if c.Distance <= 0 {
+		return DefaultREDGenerationConfig.Distance
+	}
+	return min(c.Distance, MaxREDDistance)
+}
+
+// REDGate decides from the receiver reports of a subscriber whether it is sent RED.
+// Not safe for concurrent use.
+type REDGate struct {
+	config REDGenerationConfig
+
+	active         bool
+	loss           float64
+	lastLoss       float64
+	lastEvaluation time.Time
+}
+
+func NewREDGate(config REDGenerationConfig, now time.Time) *REDGate {
+	if config.Interval <= 0 {
+		config.Interval = DefaultREDGenerationConfig.Interval
+	}
+	if config.ClearLoss > config.LossThreshold {
+		config.ClearLoss = config.LossThreshold
+	}
+	return &REDGate{
+		config:         config,
+		lastEvaluation: now,
+	}
+}
+
+// ObserveReceiverReport takes the fraction lost of a receiver report
+func (g *REDGate) ObserveReceiverReport(fractionLost uint8) {
+	g.loss = max(g.loss, float64(fractionLost)/256)
+}
+
+// Evaluate switches RED once per interval from the worst loss reported since the last evaluation.
+// Returns whether RED is sent and whether that changed.
+func (g *REDGate) Evaluate(now time.Time) (bool, bool) {
+	if now.Sub(g.lastEvaluation) < g.config.Interval {
+		return g.active, false
+	}
+	g.lastEvaluation = now
+
+	loss := g.loss
+	g.lastLoss, g.loss = loss, 0
+
+	switch {
+	case !g.active && loss > g.config.LossThreshold:
+		g.active = true
+	case g.active && loss < g.config.ClearLoss:
+		g.active = false
+	default:
+		return g.active, false
+	}
+	return g.active, true
+}
+
+func (g *REDGate) Active() bool {
+	return g.active
+}
+
+// Loss returns the worst loss of the last evaluated interval
+func (g *REDGate) Loss() float64 {
+	return g.lastLoss
+}
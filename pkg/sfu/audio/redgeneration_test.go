@@ -0,0 +1,79 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestREDGate(t *testing.T) {
+	config := DefaultREDGenerationConfig
+	start := time.Now()
+
+	// runs one interval per report and returns whether RED is sent after it
+	run := func(g *REDGate, intervals int, fractionLost uint8) bool {
+		for i := 0; i < intervals; i++ {
+			g.ObserveReceiverReport(fractionLost)
+			start = start.Add(config.Interval)
+			g.Evaluate(start)
+		}
+		return g.Active()
+	}
+
+	t.Run("starts with plain packets", func(t *testing.T) {
+		g := NewREDGate(config, start)
+		require.False(t, g.Active())
+		require.False(t, run(g, 3, 0))
+	})
+
+	t.Run("sends RED above the loss threshold", func(t *testing.T) {
+		g := NewREDGate(config, start)
+		// 5% loss
+		require.True(t, run(g, 1, 13))
+		require.InDelta(t, 0.05, g.Loss(), 0.01)
+	})
+
+	t.Run("keeps RED between the thresholds", func(t *testing.T) {
+		g := NewREDGate(config, start)
+		require.True(t, run(g, 1, 13))
+		// 2% loss, below the threshold but above the clear loss
+		require.True(t, run(g, 3, 5))
+		require.False(t, run(g, 1, 0))
+	})
+
+	t.Run("evaluates the worst loss of an interval", func(t *testing.T) {
+		g := NewREDGate(config, start)
+		g.ObserveReceiverReport(13)
+		g.ObserveReceiverReport(0)
+
+		active, changed := g.Evaluate(start.Add(config.Interval / 2))
+		require.False(t, active)
+		require.False(t, changed)
+
+		active, changed = g.Evaluate(start.Add(config.Interval))
+		require.True(t, active)
+		require.True(t, changed)
+	})
+
+	t.Run("clamps the distance", func(t *testing.T) {
+		require.Equal(t, 2, REDGenerationConfig{}.REDDistance())
+		require.Equal(t, 3, REDGenerationConfig{Distance: 3}.REDDistance())
+		require.Equal(t, MaxREDDistance, REDGenerationConfig{Distance: 20}.REDDistance())
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+)
+
+// Resampler converts interleaved 16 bit PCM between sample rates by linear interpolation, low pass
+// filtered at the lower of the two Nyquist frequencies against aliasing when downsampling and imaging
+// when upsampling. State is kept across calls so that chunk boundaries are seamless and the number of
+// output samples over time is exact, e. g. every 160 samples at 8 kHz become 960 at 48 kHz and back.
+// Not safe for concurrent use.
+type Resampler struct {
+	fromRate int
+	toRate   int
+	channels int
+
+	// position of the next output sample past the previous input sample, in 1/toRate of an input sample
+	pos   int
+	prev  []float32
+	frame []float32
+	// per channel, on the input when downsampling and on the output when upsampling
+	lowpass [][2]biquad
+}
+
+func NewResampler(fromRate int, toRate int, channels int) *Resampler {
+	channels = max(channels, 1)
+	r := &Resampler{
+		fromRate: fromRate,
+		toRate:   toRate,
+		channels: channels,
+		prev:     make([]float32, channels),
+		frame:    make([]float32, channels),
+	}
+	if fromRate != toRate {
+		// two cascaded sections, 4th order Butterworth, a little below the lower Nyquist frequency
+		cutoff := 0.45 * float64(min(fromRate, toRate))
+		filterRate := float64(max(fromRate, toRate))
+		r.lowpass = make([][2]biquad, channels)
+		for ch := range r.lowpass {
+			r.lowpass[ch][0] = newLowpassBiquad(cutoff, filterRate, 0.5412)
+			r.lowpass[ch][1] = newLowpassBiquad(cutoff, filterRate, 1.3066)
+		}
+	}
+	return r
+}
+
+// OutputSize returns the most samples per channel Resample produces for n samples per channel
+func (r *Resampler) OutputSize(n int) int {
+	return (n*r.toRate+r.fromRate-1)/r.fromRate + 1
+}
+
+// Resample appends the resampled pcm to out and returns it
+func (r *Resampler) Resample(pcm []int16, out []int16) []int16 {
+	if r.fromRate == r.toRate {
+		return append(out, pcm...)
+	}
+
+	downsampling := r.toRate < r.fromRate
+	numFrames := len(pcm) / r.channels
+	for i := 0; i < numFrames; i++ {
+		for ch := range r.frame {
+			sample := float32(pcm[i*r.channels+ch])
+			if downsampling {
+				sample = r.filter(ch, sample)
+			}
+			r.frame[ch] = sample
+		}
+
+		// interpolate outputs falling between the previous and this input sample
+		for ; r.pos < r.toRate; r.pos += r.fromRate {
+			t := float32(r.pos) / float32(r.toRate)
+			for ch, prev := range r.prev {
+				sample := prev + (r.frame[ch]-prev)*t
+				if !downsampling {
+					sample = r.filter(ch, sample)
+				}
+				out = append(out, clipInt16(int32(math.Round(float64(sample)))))
+			}
+		}
+		r.pos -= r.toRate
+		copy(r.prev, r.frame)
+	}
+	return out
+}
+
+func (r *Resampler) filter(ch int, sample float32) float32 {
+	return r.lowpass[ch][1].process(r.lowpass[ch][0].process(sample))
+}
@@ -0,0 +1,111 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sineInt16(rate int, freq float64, amplitude float64, n int, offset int) []int16 {
+	pcm := make([]int16, n)
+	for i := range pcm {
+		pcm[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(offset+i)/float64(rate)))
+	}
+	return pcm
+}
+
+func rmsInt16(pcm []int16) float64 {
+	var sum float64
+	for _, s := range pcm {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(pcm)))
+}
+
+func TestResampler(t *testing.T) {
+	t.Run("exact sizes per chunk", func(t *testing.T) {
+		up := NewResampler(G711SampleRate, OpusSampleRate, 1)
+		down := NewResampler(OpusSampleRate, G711SampleRate, 1)
+		var wide, narrow []int16
+		for i := 0; i < 50; i++ {
+			wide = up.Resample(sineInt16(G711SampleRate, 440, 8000, 160, i*160), wide[:0])
+			require.Len(t, wide, 960)
+			require.LessOrEqual(t, len(wide), up.OutputSize(160))
+
+			narrow = down.Resample(wide, narrow[:0])
+			require.Len(t, narrow, 160)
+		}
+
+		wb := NewResampler(16000, OpusSampleRate, 2)
+		require.Len(t, wb.Resample(make([]int16, 2*320), nil), 2*960)
+	})
+
+	t.Run("fractional ratio", func(t *testing.T) {
+		r := NewResampler(44100, OpusSampleRate, 1)
+		total := 0
+		for i := 0; i < 100; i++ {
+			n := len(r.Resample(make([]int16, 441), nil))
+			require.InDelta(t, 480, n, 1)
+			total += n
+		}
+		require.Equal(t, 48000, total)
+	})
+
+	t.Run("tone survives the round trip", func(t *testing.T) {
+		up := NewResampler(G711SampleRate, OpusSampleRate, 1)
+		down := NewResampler(OpusSampleRate, G711SampleRate, 1)
+		in := sineInt16(G711SampleRate, 1000, 10000, 8000, 0)
+		out := down.Resample(up.Resample(in, nil), nil)
+		require.Len(t, out, len(in))
+
+		// skip the filters settling
+		require.InEpsilon(t, rmsInt16(in[800:]), rmsInt16(out[800:]), 0.1)
+	})
+
+	t.Run("out of band content is removed when downsampling", func(t *testing.T) {
+		down := NewResampler(OpusSampleRate, G711SampleRate, 1)
+		out := down.Resample(sineInt16(OpusSampleRate, 10000, 10000, 48000, 0), nil)
+		require.Less(t, rmsInt16(out[800:]), 300.0)
+	})
+}
+
+func TestG711(t *testing.T) {
+	for _, law := range []struct {
+		name   string
+		encode func(int16) byte
+		decode func(byte) int16
+	}{
+		{"mu-law", EncodeMuLaw, DecodeMuLaw},
+		{"a-law", EncodeALaw, DecodeALaw},
+	} {
+		t.Run(law.name, func(t *testing.T) {
+			for _, sample := range []int16{0, 1, -1, 100, -100, 1000, -1000, 12345, -12345, math.MaxInt16, math.MinInt16} {
+				decoded := law.decode(law.encode(sample))
+				// logarithmic quantization, the error grows with the magnitude
+				require.InDelta(t, float64(sample), float64(decoded), 8+math.Abs(float64(sample))/16, "sample %d", sample)
+			}
+
+			// every code word is stable
+			for b := 0; b < 256; b++ {
+				decoded := law.decode(byte(b))
+				require.Equal(t, decoded, law.decode(law.encode(decoded)), "code %#x", b)
+			}
+		})
+	}
+}
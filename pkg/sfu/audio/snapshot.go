@@ -0,0 +1,205 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// gaps longer than this are treated as a timestamp jump and not padded
+	maxSnapshotSilenceGap = time.Minute
+)
+
+var ErrSnapshotEmpty = errors.New("no audio retained")
+
+// SnapshotConfig keeps a short history of the Opus audio delivered to consumers, i. e. subscribers and
+// mixed audio listeners, so that what a consumer heard can be reconstructed when debugging misrecognitions
+type SnapshotConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// audio retained per consumer track, also the longest snapshot
+	Retention time.Duration `yaml:"retention,omitempty"`
+	// retain the audio delivered to all subscribers, by default only agents are covered
+	AllSubscribers bool `yaml:"all_subscribers,omitempty"`
+	// snapshots served per room and minute, 0 for no limit
+	RequestsPerMinute int `yaml:"requests_per_minute,omitempty"`
+}
+
+var (
+	DefaultSnapshotConfig = SnapshotConfig{
+		Retention:         30 * time.Second,
+		RequestsPerMinute: 6,
+	}
+)
+
+// --------------------------------------
+
+type SnapshotPacket struct {
+	Arrival   time.Time
+	Timestamp uint32
+	Payload   []byte
+}
+
+// SnapshotBuffer retains the Opus packets of one stream received within the retention period
+type SnapshotBuffer struct {
+	retention time.Duration
+
+	lock    sync.Mutex
+	packets []SnapshotPacket
+}
+
+func NewSnapshotBuffer(retention time.Duration) *SnapshotBuffer {
+	return &SnapshotBuffer{
+		retention: retention,
+	}
+}
+
+// Push retains a copy of the payload and drops packets that fell out of the retention period
+func (b *SnapshotBuffer) Push(arrival time.Time, timestamp uint32, payload []byte) {
+	p := SnapshotPacket{
+		Arrival:   arrival,
+		Timestamp: timestamp,
+		Payload:   append([]byte(nil), payload...),
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.packets = append(b.packets, p)
+	b.pruneLocked(arrival)
+}
+
+func (b *SnapshotBuffer) pruneLocked(now time.Time) {
+	expired := 0
+	for expired < len(b.packets) && now.Sub(b.packets[expired].Arrival) > b.retention {
+		expired++
+	}
+	if expired != 0 {
+		b.packets = append(b.packets[:0], b.packets[expired:]...)
+	}
+}
+
+// Packets returns the packets received within the duration before now
+func (b *SnapshotBuffer) Packets(now time.Time, duration time.Duration) []SnapshotPacket {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.pruneLocked(now)
+	for i, p := range b.packets {
+		if now.Sub(p.Arrival) <= duration {
+			return append([]SnapshotPacket(nil), b.packets[i:]...)
+		}
+	}
+	return nil
+}
+
+// LastArrival returns when the most recent packet was received, zero if nothing is retained
+func (b *SnapshotBuffer) LastArrival() time.Time {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.packets) == 0 {
+		return time.Time{}
+	}
+	return b.packets[len(b.packets)-1].Arrival
+}
+
+// --------------------------------------
+
+// SnapshotWAV decodes the packets into a 48 kHz mono WAV file. Packets are placed by RTP timestamp,
+// gaps, e. g. DTX or loss, are filled with silence so that the audio keeps the timing it was delivered with.
+// Packets that cannot be decoded are replaced by silence.
+func SnapshotWAV(packets []SnapshotPacket, decoder OpusDecoder) ([]byte, error) {
+	if len(packets) == 0 {
+		return nil, ErrSnapshotEmpty
+	}
+
+	out := &memWriteSeeker{}
+	wav, err := NewWAVWriter(out, OpusSampleRate, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	pcm := make([]int16, OpusMaxFrameSize)
+	firstTS := packets[0].Timestamp
+	for _, p := range packets {
+		pos := int64(int32(p.Timestamp - firstTS))
+		written := wav.Samples()
+		if pos < written {
+			// late or duplicate
+			continue
+		}
+		if gap := pos - written; gap > 0 && gap <= int64(maxSnapshotSilenceGap)*OpusSampleRate/int64(time.Second) {
+			if err := wav.WriteSilence(int(gap)); err != nil {
+				return nil, err
+			}
+		}
+
+		samples, err := decoder.Decode(p.Payload, pcm)
+		if err != nil {
+			samples = 0
+			if toc, ok := ParseOpusTOC(p.Payload); ok {
+				samples = int(toc.Duration() * OpusSampleRate / time.Second)
+				clear(pcm[:min(samples, len(pcm))])
+			}
+		}
+		if err := wav.Write(pcm[:min(samples, len(pcm))]); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := wav.Close(); err != nil {
+		return nil, err
+	}
+	return out.buf, nil
+}
+
+// memWriteSeeker is an in memory io.WriteSeeker, the WAV header is rewritten on close
+type memWriteSeeker struct {
+	buf []byte
+	pos int
+}
+
+func (m *memWriteSeeker) Write(p []byte) (int, error) {
+	if end := m.pos + len(p); end > len(m.buf) {
+		m.buf = append(m.buf, make([]byte, end-len(m.buf))...)
+	}
+	n := copy(m.buf[m.pos:], p)
+	m.pos += n
+	return n, nil
+}
+
+func (m *memWriteSeeker) Seek(offset int64, whence int) (int64, error) {
+	var pos int64
+	switch whence {
+	case io.SeekStart:
+		pos = offset
+	case io.SeekCurrent:
+		pos = int64(m.pos) + offset
+	case io.SeekEnd:
+		pos = int64(len(m.buf)) + offset
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if pos < 0 {
+		return 0, errors.New("negative position")
+	}
+	m.pos = int(pos)
+	return pos, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// constantDecoder decodes every payload to 20 ms of its first byte, payloads starting with 0xff fail
+type constantDecoder struct{}
+
+func (constantDecoder) Decode(payload []byte, pcm []int16) (int, error) {
+	if payload[0] == 0xff {
+		return 0, errors.New("corrupt")
+	}
+	for i := 0; i < OpusFrameSize; i++ {
+		pcm[i] = int16(payload[0])
+	}
+	return OpusFrameSize, nil
+}
+
+func TestSnapshotBuffer(t *testing.T) {
+	start := time.Now()
+	b := NewSnapshotBuffer(time.Second)
+	require.True(t, b.LastArrival().IsZero())
+
+	for i := 0; i < 100; i++ {
+		b.Push(start.Add(time.Duration(i)*20*time.Millisecond), uint32(i*OpusFrameSize), []byte{byte(i)})
+	}
+	now := start.Add(99 * 20 * time.Millisecond)
+	require.Equal(t, now, b.LastArrival())
+
+	// only the retention period is kept
+	packets := b.Packets(now, time.Minute)
+	require.Len(t, packets, 51)
+	require.Equal(t, byte(49), packets[0].Payload[0])
+
+	packets = b.Packets(now, 100*time.Millisecond)
+	require.Len(t, packets, 6)
+	require.Equal(t, byte(94), packets[0].Payload[0])
+
+	require.Empty(t, b.Packets(now.Add(time.Hour), time.Minute))
+}
+
+func TestSnapshotWAV(t *testing.T) {
+	_, err := SnapshotWAV(nil, constantDecoder{})
+	require.ErrorIs(t, err, ErrSnapshotEmpty)
+
+	packets := []SnapshotPacket{
+		{Timestamp: 1000, Payload: []byte{1}},
+		// DTX gap of one frame
+		{Timestamp: 1000 + 2*OpusFrameSize, Payload: []byte{2}},
+		// duplicate
+		{Timestamp: 1000 + 2*OpusFrameSize, Payload: []byte{3}},
+		// corrupt, replaced by silence
+		{Timestamp: 1000 + 3*OpusFrameSize, Payload: []byte{0xff}},
+		{Timestamp: 1000 + 4*OpusFrameSize, Payload: []byte{4}},
+	}
+	wav, err := SnapshotWAV(packets, constantDecoder{})
+	require.NoError(t, err)
+	require.Equal(t, "RIFF", string(wav[0:4]))
+	require.Equal(t, uint32(OpusSampleRate), binary.LittleEndian.Uint32(wav[24:]))
+
+	data := wav[wavHeaderSize:]
+	require.Equal(t, 5*OpusFrameSize*2, len(data))
+	require.Equal(t, uint32(len(data)), binary.LittleEndian.Uint32(wav[40:]))
+	sample := func(frame int) int16 {
+		return int16(binary.LittleEndian.Uint16(data[frame*OpusFrameSize*2:]))
+	}
+	require.Equal(t, []int16{1, 0, 2, 0, 4}, []int16{sample(0), sample(1), sample(2), sample(3), sample(4)})
+}
@@ -0,0 +1,74 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"slices"
+	"strings"
+)
+
+const (
+	// processing stages participants can be excluded from
+	StageNoiseFilter = "noise_filter"
+
+	// participant attribute listing stages to exclude the participant from, comma separated,
+	// e. g. "noise_filter". Reserved, only settable through the server API.
+	StageBypassAttribute = "agentix.bypass"
+)
+
+// BypassRule selects participants excluded from a processing stage
+type BypassRule struct {
+	Identities []string `yaml:"identities,omitempty"`
+	// attribute values, a participant matching any of them is excluded, e. g. kind: music-bot
+	Attributes map[string]string `yaml:"attributes,omitempty"`
+}
+
+func (r BypassRule) Matches(identity string, attributes map[string]string) bool {
+	if slices.Contains(r.Identities, identity) {
+		return true
+	}
+	for k, v := range r.Attributes {
+		if value, ok := attributes[k]; ok && value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// StageBypassConfig excludes participants from processing stages. Excluded participants
+// keep contributing to voice activity and audio levels.
+type StageBypassConfig struct {
+	NoiseFilter BypassRule `yaml:"noise_filter,omitempty"`
+}
+
+// IsBypassed returns true if a participant is excluded from stage, by configuration or by the
+// StageBypassAttribute attribute
+func (c StageBypassConfig) IsBypassed(stage string, identity string, attributes map[string]string) bool {
+	if stages, ok := attributes[StageBypassAttribute]; ok {
+		for _, s := range strings.Split(stages, ",") {
+			if strings.TrimSpace(s) == stage {
+				return true
+			}
+		}
+	}
+
+	switch stage {
+	case StageNoiseFilter:
+		return c.NoiseFilter.Matches(identity, attributes)
+	default:
+		return false
+	}
+}
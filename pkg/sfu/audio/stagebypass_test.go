@@ -0,0 +1,41 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStageBypass(t *testing.T) {
+	conf := StageBypassConfig{
+		NoiseFilter: BypassRule{
+			Identities: []string{"jukebox"},
+			Attributes: map[string]string{"kind": "music-bot"},
+		},
+	}
+
+	require.True(t, conf.IsBypassed(StageNoiseFilter, "jukebox", nil))
+	require.True(t, conf.IsBypassed(StageNoiseFilter, "bot-1", map[string]string{"kind": "music-bot"}))
+	require.False(t, conf.IsBypassed(StageNoiseFilter, "alice", map[string]string{"kind": "caller"}))
+	require.False(t, conf.IsBypassed(StageNoiseFilter, "alice", nil))
+
+	// set through the API
+	require.True(t, conf.IsBypassed(StageNoiseFilter, "alice", map[string]string{StageBypassAttribute: "agc, noise_filter"}))
+	require.False(t, conf.IsBypassed(StageNoiseFilter, "alice", map[string]string{StageBypassAttribute: "agc"}))
+	require.False(t, conf.IsBypassed("agc", "jukebox", nil))
+}
@@ -0,0 +1,146 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+// STTGateConfig controls pausing the STT provider streams of agents while a track is silent,
+// the server keeps following voice activity and tells agents when speech resumes.
+type STTGateConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// silence after which the STT streams of a track are paused
+	SilenceTimeout time.Duration `yaml:"silence_timeout,omitempty"`
+	// audio before the speech onset agents replay to the STT provider when resuming
+	PreRoll time.Duration `yaml:"pre_roll,omitempty"`
+}
+
+var (
+	DefaultSTTGateConfig = STTGateConfig{
+		SilenceTimeout: 3 * time.Second,
+		PreRoll:        500 * time.Millisecond,
+	}
+)
+
+type STTGateState int
+
+const (
+	STTGateStateOpen STTGateState = iota
+	STTGateStatePaused
+)
+
+func (s STTGateState) String() string {
+	switch s {
+	case STTGateStateOpen:
+		return "open"
+	case STTGateStatePaused:
+		return "paused"
+	default:
+		return "unknown"
+	}
+}
+
+func (s STTGateState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+type STTGateTransition struct {
+	State STTGateState
+	// when the speech resuming a paused stream started, agents replay PreRoll of audio before it
+	SpeechStart time.Time
+	// how long the stream was paused, for transitions to open
+	PausedFor time.Duration
+	// provider stream time saved by the pause, the pause less the replayed pre-roll
+	Saved time.Duration
+}
+
+// STTGate decides from periodic voice activity samples of a track when its STT streams can be paused
+// and when they have to resume. A track starts open and pauses after SilenceTimeout without speech.
+type STTGate struct {
+	config STTGateConfig
+
+	state       STTGateState
+	lastSpeech  time.Time
+	speechStart time.Time
+	pausedAt    time.Time
+	saved       time.Duration
+}
+
+func NewSTTGate(config STTGateConfig, now time.Time) *STTGate {
+	if config.SilenceTimeout <= 0 {
+		config.SilenceTimeout = DefaultSTTGateConfig.SilenceTimeout
+	}
+	if config.PreRoll < 0 {
+		config.PreRoll = 0
+	}
+	return &STTGate{
+		config:     config,
+		lastSpeech: now,
+	}
+}
+
+// Observe records whether the track carries speech at now, returns the state change if any
+func (g *STTGate) Observe(now time.Time, speaking bool) *STTGateTransition {
+	if !speaking {
+		g.speechStart = time.Time{}
+		if g.state == STTGateStateOpen && now.Sub(g.lastSpeech) >= g.config.SilenceTimeout {
+			g.state = STTGateStatePaused
+			g.pausedAt = now
+			return &STTGateTransition{State: STTGateStatePaused}
+		}
+		return nil
+	}
+
+	if g.speechStart.IsZero() {
+		g.speechStart = now
+	}
+	g.lastSpeech = now
+	if g.state != STTGateStatePaused {
+		return nil
+	}
+
+	g.state = STTGateStateOpen
+	t := &STTGateTransition{
+		State:       STTGateStateOpen,
+		SpeechStart: g.speechStart,
+		PausedFor:   now.Sub(g.pausedAt),
+		Saved:       max(0, now.Sub(g.pausedAt)-g.config.PreRoll),
+	}
+	g.saved += t.Saved
+	return t
+}
+
+// Close ends the gate, returns the time saved by a pause still running
+func (g *STTGate) Close(now time.Time) time.Duration {
+	if g.state != STTGateStatePaused {
+		return 0
+	}
+
+	g.state = STTGateStateOpen
+	saved := now.Sub(g.pausedAt)
+	g.saved += saved
+	return saved
+}
+
+func (g *STTGate) State() STTGateState {
+	return g.state
+}
+
+// Saved returns the provider stream time saved over the lifetime of the gate
+func (g *STTGate) Saved() time.Duration {
+	return g.saved
+}
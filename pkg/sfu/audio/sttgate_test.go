@@ -0,0 +1,69 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSTTGate(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	gate := NewSTTGate(STTGateConfig{SilenceTimeout: 2 * time.Second, PreRoll: 500 * time.Millisecond}, start)
+
+	// speech 0 - 1s, silence until 10s, speech 10 - 11s, then silence until the track closes at 12s
+	const tick = 100 * time.Millisecond
+	var transitions []*STTGateTransition
+	var at []time.Duration
+	for d := tick; d <= 12*time.Second; d += tick {
+		speaking := d <= time.Second || (d > 10*time.Second && d <= 11*time.Second)
+		if tr := gate.Observe(start.Add(d), speaking); tr != nil {
+			transitions = append(transitions, tr)
+			at = append(at, d)
+		}
+	}
+
+	require.Len(t, transitions, 2)
+	require.Equal(t, STTGateStatePaused, transitions[0].State)
+	require.Equal(t, 3*time.Second, at[0])
+
+	require.Equal(t, STTGateStateOpen, transitions[1].State)
+	require.Equal(t, 10100*time.Millisecond, at[1])
+	require.Equal(t, start.Add(10100*time.Millisecond), transitions[1].SpeechStart)
+	require.Equal(t, 7100*time.Millisecond, transitions[1].PausedFor)
+	require.Equal(t, 6600*time.Millisecond, transitions[1].Saved)
+
+	// not yet paused again, 11s + 2s of silence
+	require.Equal(t, STTGateStateOpen, gate.State())
+	require.Zero(t, gate.Close(start.Add(12*time.Second)))
+	require.Equal(t, 6600*time.Millisecond, gate.Saved())
+}
+
+func TestSTTGateClosePaused(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	gate := NewSTTGate(DefaultSTTGateConfig, start)
+
+	require.Nil(t, gate.Observe(start.Add(time.Second), false))
+	tr := gate.Observe(start.Add(DefaultSTTGateConfig.SilenceTimeout), false)
+	require.NotNil(t, tr)
+	require.Equal(t, STTGateStatePaused, tr.State)
+
+	// nothing to replay when the track goes away while paused
+	require.Equal(t, 5*time.Second, gate.Close(start.Add(DefaultSTTGateConfig.SilenceTimeout+5*time.Second)))
+	require.Equal(t, STTGateStateOpen, gate.State())
+}
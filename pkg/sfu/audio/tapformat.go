@@ -0,0 +1,371 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+)
+
+type SampleEncoding string
+
+const (
+	SampleEncodingS16LE SampleEncoding = "s16le"
+	SampleEncodingF32LE SampleEncoding = "f32le"
+)
+
+var (
+	ErrUnsupportedTapFormat = errors.New("unsupported audio tap format")
+
+	// sample rates a tap can be converted to, ascending
+	TapSampleRates = []int{8000, 16000, 24000, OpusSampleRate}
+
+	DefaultTapFormat = TapFormat{
+		Encoding:   SampleEncodingS16LE,
+		SampleRate: OpusSampleRate,
+		Channels:   1,
+	}
+)
+
+// TapFormat is the PCM format a consumer of decoded track audio receives, interleaved when stereo
+type TapFormat struct {
+	Encoding   SampleEncoding `json:"encoding"`
+	SampleRate int            `json:"sample_rate"`
+	Channels   int            `json:"channels"`
+}
+
+func (f TapFormat) String() string {
+	return fmt.Sprintf("%s/%d/%d", f.Encoding, f.SampleRate, f.Channels)
+}
+
+func (f TapFormat) BytesPerSample() int {
+	if f.Encoding == SampleEncodingF32LE {
+		return 4
+	}
+	return 2
+}
+
+// NegotiateTapFormat returns the format the server delivers for a requested one. Unset fields take
+// the default, a sample rate that is not supported is raised to the next supported one.
+func NegotiateTapFormat(requested TapFormat) (TapFormat, error) {
+	f := DefaultTapFormat
+	if requested.Encoding != "" {
+		switch encoding := SampleEncoding(strings.ToLower(string(requested.Encoding))); encoding {
+		case SampleEncodingS16LE, SampleEncodingF32LE:
+			f.Encoding = encoding
+		default:
+			return TapFormat{}, fmt.Errorf("%w: encoding %q", ErrUnsupportedTapFormat, requested.Encoding)
+		}
+	}
+
+	switch {
+	case requested.Channels == 0:
+	case requested.Channels == 1 || requested.Channels == 2:
+		f.Channels = requested.Channels
+	default:
+		return TapFormat{}, fmt.Errorf("%w: %d channels", ErrUnsupportedTapFormat, requested.Channels)
+	}
+
+	switch {
+	case requested.SampleRate == 0:
+	case requested.SampleRate < 0 || requested.SampleRate > TapSampleRates[len(TapSampleRates)-1]:
+		return TapFormat{}, fmt.Errorf("%w: sample rate %d", ErrUnsupportedTapFormat, requested.SampleRate)
+	default:
+		for _, rate := range TapSampleRates {
+			if rate >= requested.SampleRate {
+				f.SampleRate = rate
+				break
+			}
+		}
+	}
+	return f, nil
+}
+
+// --------------------------------------
+
+// TapConverter converts interleaved 16 bit PCM of a source into a tap format: down or up mixes channels,
+// resamples and encodes. State is kept across calls so that chunk boundaries are seamless.
+// Not safe for concurrent use.
+type TapConverter struct {
+	sourceChannels int
+	target         TapFormat
+
+	// source samples per output sample
+	step float64
+	// position of the next output sample, relative to the last sample of the previous chunk
+	pos     float64
+	history []float32
+	primed  bool
+	// anti-aliasing when downsampling, per channel
+	lowpass [][2]biquad
+
+	frame []float32
+}
+
+func NewTapConverter(sourceRate int, sourceChannels int, target TapFormat) *TapConverter {
+	sourceChannels = max(sourceChannels, 1)
+	c := &TapConverter{
+		sourceChannels: sourceChannels,
+		target:         target,
+		step:           float64(sourceRate) / float64(target.SampleRate),
+		history:        make([]float32, target.Channels),
+		frame:          make([]float32, target.Channels),
+	}
+	if target.SampleRate < sourceRate {
+		// two cascaded sections, 4th order Butterworth, a little below the new Nyquist frequency
+		cutoff := 0.45 * float64(target.SampleRate)
+		c.lowpass = make([][2]biquad, target.Channels)
+		for ch := range c.lowpass {
+			c.lowpass[ch][0] = newLowpassBiquad(cutoff, float64(sourceRate), 0.5412)
+			c.lowpass[ch][1] = newLowpassBiquad(cutoff, float64(sourceRate), 1.3066)
+		}
+	}
+	return c
+}
+
+func (c *TapConverter) Format() TapFormat {
+	return c.target
+}
+
+// Convert returns the encoded samples for the given source samples, can be empty for short inputs
+func (c *TapConverter) Convert(pcm []int16) []byte {
+	numFrames := len(pcm) / c.sourceChannels
+	out := make([]byte, 0, int(float64(numFrames)/c.step+1)*c.target.Channels*c.target.BytesPerSample())
+
+	for i := 0; i < numFrames; i++ {
+		c.mix(pcm[i*c.sourceChannels : (i+1)*c.sourceChannels])
+		if !c.primed {
+			copy(c.history, c.frame)
+			c.primed = true
+			continue
+		}
+
+		// interpolate outputs falling between the previous and this source sample
+		for ; c.pos < 1; c.pos += c.step {
+			for ch, prev := range c.history {
+				sample := prev + (c.frame[ch]-prev)*float32(c.pos)
+				out = c.encode(out, sample)
+			}
+		}
+		c.pos--
+		copy(c.history, c.frame)
+	}
+	return out
+}
+
+// mix maps a source frame onto the target channels, filtered when downsampling
+func (c *TapConverter) mix(in []int16) {
+	switch {
+	case c.sourceChannels == c.target.Channels:
+		for ch, s := range in {
+			c.frame[ch] = float32(s)
+		}
+	case c.target.Channels == 1:
+		var sum float32
+		for _, s := range in {
+			sum += float32(s)
+		}
+		c.frame[0] = sum / float32(len(in))
+	default:
+		for ch := range c.frame {
+			c.frame[ch] = float32(in[0])
+		}
+	}
+
+	for ch := range c.lowpass {
+		c.frame[ch] = c.lowpass[ch][1].process(c.lowpass[ch][0].process(c.frame[ch]))
+	}
+}
+
+func (c *TapConverter) encode(out []byte, sample float32) []byte {
+	if c.target.Encoding == SampleEncodingF32LE {
+		return binary.LittleEndian.AppendUint32(out, math.Float32bits(sample/32768))
+	}
+	return binary.LittleEndian.AppendUint16(out, uint16(clipInt16(int32(math.Round(float64(sample))))))
+}
+
+// --------------------------------------
+
+type biquad struct {
+	b0, b1, b2, a1, a2 float32
+	z1, z2             float32
+}
+
+func newLowpassBiquad(cutoff float64, sampleRate float64, q float64) biquad {
+	w := 2 * math.Pi * cutoff / sampleRate
+	alpha := math.Sin(w) / (2 * q)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: float32((1 - cos) / 2 / a0),
+		b1: float32((1 - cos) / a0),
+		b2: float32((1 - cos) / 2 / a0),
+		a1: float32(-2 * cos / a0),
+		a2: float32((1 - alpha) / a0),
+	}
+}
+
+func newHighpassBiquad(cutoff float64, sampleRate float64, q float64) biquad {
+	w := 2 * math.Pi * cutoff / sampleRate
+	alpha := math.Sin(w) / (2 * q)
+	cos := math.Cos(w)
+	a0 := 1 + alpha
+	return biquad{
+		b0: float32((1 + cos) / 2 / a0),
+		b1: float32(-(1 + cos) / a0),
+		b2: float32((1 + cos) / 2 / a0),
+		a1: float32(-2 * cos / a0),
+		a2: float32((1 - alpha) / a0),
+	}
+}
+
+func (b *biquad) process(x float32) float32 {
+	y := b.b0*x + b.z1
+	b.z1 = b.b1*x - b.a1*y + b.z2
+	b.z2 = b.b2*x - b.a2*y
+	return y
+}
+
+// --------------------------------------
+
+// TapFanout delivers the decoded audio of one source to any number of consumers, each in the format
+// it negotiated. Audio is converted once per distinct format and the result is shared by all
+// consumers of that format, consumers must not modify it.
+type TapFanout struct {
+	sourceRate     int
+	sourceChannels int
+
+	lock      sync.Mutex
+	groups    map[TapFormat]*tapFormatGroup
+	consumers map[string]TapFormat
+}
+
+type tapFormatGroup struct {
+	converter *TapConverter
+	consumers map[string]func(data []byte)
+}
+
+func NewTapFanout(sourceRate int, sourceChannels int) *TapFanout {
+	return &TapFanout{
+		sourceRate:     sourceRate,
+		sourceChannels: sourceChannels,
+		groups:         make(map[TapFormat]*tapFormatGroup),
+		consumers:      make(map[string]TapFormat),
+	}
+}
+
+// Subscribe adds a consumer, or changes the format of an existing one. Returns the negotiated format.
+func (f *TapFanout) Subscribe(id string, requested TapFormat, onData func(data []byte)) (TapFormat, error) {
+	format, err := NegotiateTapFormat(requested)
+	if err != nil {
+		return TapFormat{}, err
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.removeLocked(id)
+
+	g, ok := f.groups[format]
+	if !ok {
+		g = &tapFormatGroup{
+			converter: NewTapConverter(f.sourceRate, f.sourceChannels, format),
+			consumers: make(map[string]func(data []byte)),
+		}
+		f.groups[format] = g
+	}
+	g.consumers[id] = onData
+	f.consumers[id] = format
+	return format, nil
+}
+
+func (f *TapFanout) Unsubscribe(id string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.removeLocked(id)
+}
+
+func (f *TapFanout) removeLocked(id string) {
+	format, ok := f.consumers[id]
+	if !ok {
+		return
+	}
+	delete(f.consumers, id)
+
+	if g := f.groups[format]; g != nil {
+		delete(g.consumers, id)
+		if len(g.consumers) == 0 {
+			delete(f.groups, format)
+		}
+	}
+}
+
+func (f *TapFanout) NumConsumers() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	return len(f.consumers)
+}
+
+// Formats returns the formats currently converted to, one conversion each
+func (f *TapFanout) Formats() []TapFormat {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	formats := make([]TapFormat, 0, len(f.groups))
+	for format := range f.groups {
+		formats = append(formats, format)
+	}
+	slices.SortFunc(formats, func(a, b TapFormat) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return formats
+}
+
+// Push converts interleaved source samples for every format and hands them to the consumers
+func (f *TapFanout) Push(pcm []int16) {
+	type delivery struct {
+		data      []byte
+		consumers []func(data []byte)
+	}
+
+	f.lock.Lock()
+	deliveries := make([]delivery, 0, len(f.groups))
+	for _, g := range f.groups {
+		data := g.converter.Convert(pcm)
+		if len(data) == 0 {
+			continue
+		}
+		d := delivery{data: data}
+		for _, onData := range g.consumers {
+			d.consumers = append(d.consumers, onData)
+		}
+		deliveries = append(deliveries, d)
+	}
+	f.lock.Unlock()
+
+	for _, d := range deliveries {
+		for _, onData := range d.consumers {
+			onData(d.data)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sinePCM(freq float64, sampleRate int, numSamples int, amplitude float64) []int16 {
+	pcm := make([]int16, numSamples)
+	for i := range pcm {
+		pcm[i] = int16(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate)))
+	}
+	return pcm
+}
+
+func decodeS16LE(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return samples
+}
+
+func rms(samples []int16) float64 {
+	var sum float64
+	for _, s := range samples {
+		sum += float64(s) * float64(s)
+	}
+	return math.Sqrt(sum / float64(len(samples)))
+}
+
+func TestNegotiateTapFormat(t *testing.T) {
+	f, err := NegotiateTapFormat(TapFormat{})
+	require.NoError(t, err)
+	require.Equal(t, DefaultTapFormat, f)
+
+	f, err = NegotiateTapFormat(TapFormat{Encoding: "F32LE", SampleRate: 16000, Channels: 2})
+	require.NoError(t, err)
+	require.Equal(t, TapFormat{Encoding: SampleEncodingF32LE, SampleRate: 16000, Channels: 2}, f)
+
+	// raised to the next supported rate
+	f, err = NegotiateTapFormat(TapFormat{SampleRate: 22050})
+	require.NoError(t, err)
+	require.Equal(t, 24000, f.SampleRate)
+
+	for _, requested := range []TapFormat{
+		{Encoding: "mulaw"},
+		{Channels: 6},
+		{SampleRate: 96000},
+		{SampleRate: -1},
+	} {
+		_, err = NegotiateTapFormat(requested)
+		require.ErrorIs(t, err, ErrUnsupportedTapFormat, requested)
+	}
+}
+
+func TestTapConverter(t *testing.T) {
+	t.Run("passthrough", func(t *testing.T) {
+		c := NewTapConverter(OpusSampleRate, 1, DefaultTapFormat)
+		pcm := sinePCM(440, OpusSampleRate, OpusFrameSize, 10000)
+
+		out := decodeS16LE(c.Convert(pcm))
+		// the first sample primes the converter, the following ones lag by one sample
+		require.Equal(t, pcm[:len(pcm)-1], out)
+	})
+
+	t.Run("resample across chunks", func(t *testing.T) {
+		c := NewTapConverter(OpusSampleRate, 1, TapFormat{Encoding: SampleEncodingS16LE, SampleRate: 16000, Channels: 1})
+		pcm := sinePCM(440, OpusSampleRate, OpusSampleRate, 10000)
+
+		var out []int16
+		for i := 0; i < len(pcm); i += OpusFrameSize {
+			out = append(out, decodeS16LE(c.Convert(pcm[i:i+OpusFrameSize]))...)
+		}
+		require.InDelta(t, 16000, len(out), 1)
+		// a tone in the pass band keeps its level
+		require.InDelta(t, 10000/math.Sqrt2, rms(out[1000:]), 300)
+	})
+
+	t.Run("anti-aliasing", func(t *testing.T) {
+		c := NewTapConverter(OpusSampleRate, 1, TapFormat{Encoding: SampleEncodingS16LE, SampleRate: 8000, Channels: 1})
+		// above the Nyquist frequency of the target rate
+		out := decodeS16LE(c.Convert(sinePCM(12000, OpusSampleRate, OpusSampleRate, 10000)))
+		require.Less(t, rms(out[1000:]), 500.0)
+	})
+
+	t.Run("downmix and f32", func(t *testing.T) {
+		c := NewTapConverter(OpusSampleRate, 2, TapFormat{Encoding: SampleEncodingF32LE, SampleRate: OpusSampleRate, Channels: 1})
+		out := c.Convert([]int16{16384, 0, -32768, -32768, 0, 0})
+		require.Len(t, out, 2*4)
+		require.Equal(t, float32(0.25), math.Float32frombits(binary.LittleEndian.Uint32(out)))
+		require.Equal(t, float32(-1), math.Float32frombits(binary.LittleEndian.Uint32(out[4:])))
+	})
+
+	t.Run("upmix", func(t *testing.T) {
+		c := NewTapConverter(OpusSampleRate, 1, TapFormat{Encoding: SampleEncodingS16LE, SampleRate: OpusSampleRate, Channels: 2})
+		require.Equal(t, []int16{100, 100}, decodeS16LE(c.Convert([]int16{100, 200})))
+	})
+}
+
+func TestTapFanout(t *testing.T) {
+	f := NewTapFanout(OpusSampleRate, 1)
+
+	received := make(map[string][][]byte)
+	onData := func(id string) func(data []byte) {
+		return func(data []byte) {
+			received[id] = append(received[id], data)
+		}
+	}
+
+	asr := TapFormat{SampleRate: 16000}
+	format, err := f.Subscribe("a", asr, onData("a"))
+	require.NoError(t, err)
+	require.Equal(t, TapFormat{Encoding: SampleEncodingS16LE, SampleRate: 16000, Channels: 1}, format)
+	_, err = f.Subscribe("b", asr, onData("b"))
+	require.NoError(t, err)
+	_, err = f.Subscribe("c", TapFormat{Encoding: SampleEncodingF32LE}, onData("c"))
+	require.NoError(t, err)
+	_, err = f.Subscribe("d", TapFormat{Channels: 3}, onData("d"))
+	require.Error(t, err)
+
+	require.Equal(t, 3, f.NumConsumers())
+	require.Len(t, f.Formats(), 2)
+
+	f.Push(sinePCM(440, OpusSampleRate, OpusFrameSize, 10000))
+	// converted once, shared by the consumers of the same format
+	require.Len(t, received["a"], 1)
+	require.Len(t, received["b"], 1)
+	require.Same(t, &received["a"][0][0], &received["b"][0][0])
+	require.Len(t, received["c"], 1)
+	require.Equal(t, (OpusFrameSize-1)*4, len(received["c"][0]))
+
+	// changing format moves the consumer
+	_, err = f.Subscribe("b", TapFormat{Encoding: SampleEncodingF32LE}, onData("b"))
+	require.NoError(t, err)
+	require.Len(t, f.Formats(), 2)
+
+	f.Unsubscribe("a")
+	require.Equal(t, []TapFormat{{Encoding: SampleEncodingF32LE, SampleRate: OpusSampleRate, Channels: 1}}, f.Formats())
+
+	f.Unsubscribe("b")
+	f.Unsubscribe("c")
+	require.Zero(t, f.NumConsumers())
+	require.Empty(t, f.Formats())
+}
@@ -0,0 +1,218 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+const (
+	telephoneEventPayloadSize = 4
+)
+
+var (
+	ErrInvalidTelephoneEvent = errors.New("invalid telephone event payload")
+)
+
+// TelephoneEventConfig controls handling of RFC 4733 telephone events (in-band DTMF) sent by publishers.
+// Telephone events are never forwarded or processed as audio, they are delivered as data instead.
+type TelephoneEventConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// an event whose end packets were all lost is ended after no packet was received for this long
+	EndTimeout time.Duration `yaml:"end_timeout,omitempty"`
+	// DTMF tones in the audio of tracks that send no telephone events
+	Tones DTMFToneConfig `yaml:"tones,omitempty"`
+}
+
+var (
+	DefaultTelephoneEventConfig = TelephoneEventConfig{
+		EndTimeout: 500 * time.Millisecond,
+		Tones:      DefaultDTMFToneConfig,
+	}
+)
+
+// TelephoneEvent is the payload of an RFC 4733 telephone-event packet
+type TelephoneEvent struct {
+	Event  uint8
+	End    bool
+	Volume uint8
+	// in RTP timestamp units since the start of the event (segment)
+	Duration uint16
+}
+
+func ParseTelephoneEvent(payload []byte) (TelephoneEvent, error) {
+	// senders may append further events for redundancy, the first one is the current event
+	if len(payload) < telephoneEventPayloadSize {
+		return TelephoneEvent{}, ErrInvalidTelephoneEvent
+	}
+
+	return TelephoneEvent{
+		Event:    payload[0],
+		End:      payload[1]&0x80 != 0,
+		Volume:   payload[1] & 0x3f,
+		Duration: binary.BigEndian.Uint16(payload[2:4]),
+	}, nil
+}
+
+// DTMFDigit returns the DTMF digit of an event code, empty for events that are not digits
+func DTMFDigit(event uint8) string {
+	switch {
+	case event <= 9:
+		return string(rune('0' + event))
+	case event == 10:
+		return "*"
+	case event == 11:
+		return "#"
+	case event <= 15:
+		return string(rune('A' + event - 12))
+	}
+	return ""
+}
+
+// --------------------------------------
+
+type TelephoneEventPhase string
+
+const (
+	TelephoneEventPhaseStart TelephoneEventPhase = "start"
+	TelephoneEventPhaseEnd   TelephoneEventPhase = "end"
+)
+
+type TelephoneEventUpdate struct {
+	Event    uint8
+	Digit    string
+	Phase    TelephoneEventPhase
+	Volume   uint8
+	Duration time.Duration
+	// the end was inferred, none of the end packets of the event arrived
+	EndLost bool
+}
+
+// TelephoneEventTracker turns the packets of a telephone event stream into exactly one start
+// and one end per event. RFC 4733 senders repeat packets of an event with the same RTP timestamp
+// and retransmit the end packet, events that are longer than the duration field can hold
+// are split into segments with consecutive timestamps. Not safe for concurrent use.
+type TelephoneEventTracker struct {
+	clockRate  uint32
+	endTimeout time.Duration
+
+	active       bool
+	current      TelephoneEvent
+	segmentStart uint32
+	// duration of the segments before the current one, in RTP timestamp units
+	elapsed      uint64
+	lastPacketAt time.Time
+
+	ended          bool
+	endedTimestamp uint32
+}
+
+func NewTelephoneEventTracker(clockRate uint32, endTimeout time.Duration) *TelephoneEventTracker {
+	if endTimeout <= 0 {
+		endTimeout = DefaultTelephoneEventConfig.EndTimeout
+	}
+	return &TelephoneEventTracker{
+		clockRate:  clockRate,
+		endTimeout: endTimeout,
+	}
+}
+
+// Push accounts a received telephone event packet and returns the resulting phase changes
+func (t *TelephoneEventTracker) Push(ev TelephoneEvent, rtpTimestamp uint32, at time.Time) []TelephoneEventUpdate {
+	var updates []TelephoneEventUpdate
+
+	switch {
+	case t.active && rtpTimestamp == t.segmentStart:
+		// repeated packet of the current segment
+
+	case t.active && ev.Event == t.current.Event && rtpTimestamp == t.segmentStart+uint32(t.current.Duration):
+		// next segment of a long event
+		t.elapsed += uint64(t.current.Duration)
+		t.segmentStart = rtpTimestamp
+		t.current.Duration = 0
+
+	case !t.active && t.ended && rtpTimestamp == t.endedTimestamp:
+		// retransmitted end of an event that has already ended
+		return nil
+
+	default:
+		if t.active {
+			updates = append(updates, t.end(true))
+		}
+		t.active = true
+		t.current = ev
+		t.segmentStart = rtpTimestamp
+		t.elapsed = 0
+		updates = append(updates, t.update(TelephoneEventPhaseStart))
+	}
+
+	t.lastPacketAt = at
+	t.current.Duration = max(t.current.Duration, ev.Duration)
+	if ev.End {
+		updates = append(updates, t.end(false))
+	}
+	return updates
+}
+
+// Expire ends the current event when its end packets did not arrive in time
+func (t *TelephoneEventTracker) Expire(at time.Time) *TelephoneEventUpdate {
+	if !t.active || at.Sub(t.lastPacketAt) < t.endTimeout {
+		return nil
+	}
+
+	update := t.end(true)
+	return &update
+}
+
+// Flush ends the current event, e. g. when the stream goes away
+func (t *TelephoneEventTracker) Flush() *TelephoneEventUpdate {
+	if !t.active {
+		return nil
+	}
+
+	update := t.end(true)
+	return &update
+}
+
+func (t *TelephoneEventTracker) IsActive() bool {
+	return t.active
+}
+
+func (t *TelephoneEventTracker) end(lost bool) TelephoneEventUpdate {
+	update := t.update(TelephoneEventPhaseEnd)
+	update.EndLost = lost
+
+	t.active = false
+	t.ended = true
+	t.endedTimestamp = t.segmentStart
+	return update
+}
+
+func (t *TelephoneEventTracker) update(phase TelephoneEventPhase) TelephoneEventUpdate {
+	update := TelephoneEventUpdate{
+		Event:  t.current.Event,
+		Digit:  DTMFDigit(t.current.Event),
+		Phase:  phase,
+		Volume: t.current.Volume,
+	}
+	if phase == TelephoneEventPhaseEnd && t.clockRate != 0 {
+		samples := t.elapsed + uint64(t.current.Duration)
+		update.Duration = time.Duration(samples) * time.Second / time.Duration(t.clockRate)
+	}
+	return update
+}
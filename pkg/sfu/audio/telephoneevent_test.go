@@ -0,0 +1,126 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func telephoneEventPayload(event uint8, end bool, volume uint8, duration uint16) []byte {
+	payload := make([]byte, telephoneEventPayloadSize)
+	payload[0] = event
+	payload[1] = volume & 0x3f
+	if end {
+		payload[1] |= 0x80
+	}
+	binary.BigEndian.PutUint16(payload[2:], duration)
+	return payload
+}
+
+func TestParseTelephoneEvent(t *testing.T) {
+	ev, err := ParseTelephoneEvent(telephoneEventPayload(11, true, 10, 1600))
+	require.NoError(t, err)
+	require.Equal(t, TelephoneEvent{Event: 11, End: true, Volume: 10, Duration: 1600}, ev)
+	require.Equal(t, "#", DTMFDigit(ev.Event))
+
+	_, err = ParseTelephoneEvent([]byte{1, 2})
+	require.ErrorIs(t, err, ErrInvalidTelephoneEvent)
+
+	require.Equal(t, "0", DTMFDigit(0))
+	require.Equal(t, "9", DTMFDigit(9))
+	require.Equal(t, "*", DTMFDigit(10))
+	require.Equal(t, "D", DTMFDigit(15))
+	require.Empty(t, DTMFDigit(16))
+}
+
+func TestTelephoneEventTracker(t *testing.T) {
+	now := time.Now()
+	const clockRate = 8000
+
+	t.Run("start and end once", func(t *testing.T) {
+		tr := NewTelephoneEventTracker(clockRate, time.Second)
+
+		updates := tr.Push(TelephoneEvent{Event: 5, Volume: 10, Duration: 160}, 1000, now)
+		require.Len(t, updates, 1)
+		require.Equal(t, TelephoneEventPhaseStart, updates[0].Phase)
+		require.Equal(t, "5", updates[0].Digit)
+
+		require.Empty(t, tr.Push(TelephoneEvent{Event: 5, Volume: 10, Duration: 320}, 1000, now))
+
+		updates = tr.Push(TelephoneEvent{Event: 5, Volume: 10, End: true, Duration: 800}, 1000, now)
+		require.Len(t, updates, 1)
+		require.Equal(t, TelephoneEventPhaseEnd, updates[0].Phase)
+		require.Equal(t, 100*time.Millisecond, updates[0].Duration)
+		require.False(t, updates[0].EndLost)
+
+		// end packet is retransmitted
+		require.Empty(t, tr.Push(TelephoneEvent{Event: 5, Volume: 10, End: true, Duration: 800}, 1000, now))
+		require.Empty(t, tr.Push(TelephoneEvent{Event: 5, Volume: 10, End: true, Duration: 800}, 1000, now))
+		require.False(t, tr.IsActive())
+	})
+
+	t.Run("lost end", func(t *testing.T) {
+		tr := NewTelephoneEventTracker(clockRate, time.Second)
+		tr.Push(TelephoneEvent{Event: 1, Duration: 160}, 1000, now)
+
+		// next digit ends the previous one
+		updates := tr.Push(TelephoneEvent{Event: 2, Duration: 160}, 3000, now)
+		require.Len(t, updates, 2)
+		require.Equal(t, TelephoneEventPhaseEnd, updates[0].Phase)
+		require.Equal(t, "1", updates[0].Digit)
+		require.True(t, updates[0].EndLost)
+		require.Equal(t, TelephoneEventPhaseStart, updates[1].Phase)
+		require.Equal(t, "2", updates[1].Digit)
+
+		// or the timeout does
+		require.Nil(t, tr.Expire(now.Add(500*time.Millisecond)))
+		update := tr.Expire(now.Add(time.Second))
+		require.NotNil(t, update)
+		require.Equal(t, "2", update.Digit)
+		require.True(t, update.EndLost)
+		require.Nil(t, tr.Flush())
+	})
+
+	t.Run("lost start", func(t *testing.T) {
+		tr := NewTelephoneEventTracker(clockRate, time.Second)
+		updates := tr.Push(TelephoneEvent{Event: 10, End: true, Duration: 400}, 1000, now)
+		require.Len(t, updates, 2)
+		require.Equal(t, TelephoneEventPhaseStart, updates[0].Phase)
+		require.Equal(t, TelephoneEventPhaseEnd, updates[1].Phase)
+		require.Equal(t, 50*time.Millisecond, updates[1].Duration)
+	})
+
+	t.Run("same digit twice", func(t *testing.T) {
+		tr := NewTelephoneEventTracker(clockRate, time.Second)
+		require.Len(t, tr.Push(TelephoneEvent{Event: 3, End: true, Duration: 400}, 1000, now), 2)
+		require.Len(t, tr.Push(TelephoneEvent{Event: 3, End: true, Duration: 400}, 2000, now), 2)
+	})
+
+	t.Run("long event", func(t *testing.T) {
+		tr := NewTelephoneEventTracker(clockRate, time.Second)
+		require.Len(t, tr.Push(TelephoneEvent{Event: 0, Duration: 0xffff}, 1000, now), 1)
+		// next segment continues the event
+		require.Empty(t, tr.Push(TelephoneEvent{Event: 0, Duration: 800}, 1000+0xffff, now))
+
+		updates := tr.Push(TelephoneEvent{Event: 0, End: true, Duration: 8000}, 1000+0xffff, now)
+		require.Len(t, updates, 1)
+		require.Equal(t, time.Duration(0xffff+8000)*time.Second/clockRate, updates[0].Duration)
+	})
+}
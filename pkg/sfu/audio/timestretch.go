@@ -0,0 +1,139 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+)
+
+// CatchUpConfig controls catching up with audio that queued up while processing stalled. Instead of
+// dropping the oldest audio, the backlog is played faster, time-compressed with WSOLA so that pitch is
+// kept and no words are lost.
+type CatchUpConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// upper bound of the playback speed while catching up, 1.25 plays 25% faster
+	MaxSpeed float64 `yaml:"max_speed,omitempty"`
+	// 20 ms frames queued per source before the oldest audio is dropped anyway
+	MaxBacklogFrames int `yaml:"max_backlog_frames,omitempty"`
+}
+
+var (
+	DefaultCatchUpConfig = CatchUpConfig{
+		MaxSpeed:         1.25,
+		MaxBacklogFrames: 50,
+	}
+)
+
+const (
+	// WSOLA segments overlap by half, 5 ms at 48 kHz
+	wsolaMaxHop = 240
+)
+
+// wsola time-compresses a queue of samples with waveform similarity overlap-add. Every output hop
+// overlaps the tail of the previous segment with a segment taken from around the nominal position
+// of the requested speed, shifted to where it continues the tail's waveform best.
+//
+// While active, the first hop of the queue is the tail of the last segment, it is already emitted
+// faded out. Copying the queue as is continues seamlessly, as the faded in copy completes the tail.
+// Not safe for concurrent use.
+type wsola struct {
+	hop       int
+	tolerance int
+	window    []float32
+
+	active bool
+	// samples consumed beyond the nominal speed, corrected by the following segments
+	drift float64
+}
+
+// newWSOLA returns a compressor producing frames of frameSize samples, nil if frames are too short
+func newWSOLA(frameSize int) *wsola {
+	if frameSize < 4 {
+		return nil
+	}
+
+	hop := frameSize / ((frameSize + wsolaMaxHop - 1) / wsolaMaxHop)
+	for frameSize%hop != 0 {
+		hop--
+	}
+	// periodic Hann, the halves of overlapping segments sum up to one
+	window := make([]float32, 2*hop)
+	for i := range window {
+		window[i] = float32(0.5 - 0.5*math.Cos(math.Pi*float64(i)/float64(hop)))
+	}
+	return &wsola{
+		hop:       hop,
+		tolerance: hop,
+		window:    window,
+	}
+}
+
+// required returns the number of queued samples needed to compress len(out) samples at speed
+func (w *wsola) required(outSize int, speed float64) int {
+	return int(math.Ceil(float64(outSize)*speed+math.Abs(w.drift))) + w.tolerance + 2*w.hop
+}
+
+// compress writes len(out) samples, taken from queue at the given speed, into out and returns the number
+// of samples consumed from the front of queue. Returns 0 without touching out if queue is too short.
+func (w *wsola) compress(queue []int16, speed float64, out []int16) int {
+	if len(out)%w.hop != 0 || len(queue) < w.required(len(out), speed) {
+		return 0
+	}
+
+	w.active = true
+	base := 0
+	for o := 0; o < len(out); o += w.hop {
+		template := queue[base : base+w.hop]
+
+		nominal := base + int(math.Round(float64(w.hop)*(speed-1)-w.drift))
+		lo := max(base, nominal-w.tolerance)
+		hi := min(max(base, nominal+w.tolerance), len(queue)-2*w.hop)
+		c := w.bestMatch(queue, template, lo, max(lo, hi))
+
+		for i := 0; i < w.hop; i++ {
+			sample := float32(template[i])*w.window[w.hop+i] + float32(queue[c+i])*w.window[i]
+			out[o+i] = clipInt16(int32(math.Round(float64(sample))))
+		}
+
+		w.drift += float64(c-base+w.hop) - float64(w.hop)*speed
+		base = c + w.hop
+	}
+	return base
+}
+
+// bestMatch returns the start of the segment in queue[lo:hi+hop] that correlates best with template
+func (w *wsola) bestMatch(queue []int16, template []int16, lo int, hi int) int {
+	best, bestScore := lo, math.Inf(-1)
+	for c := lo; c <= hi; c++ {
+		var corr, energy float64
+		for i, t := range template {
+			s := float64(queue[c+i])
+			corr += float64(t) * s
+			energy += s * s
+		}
+		score := corr / math.Sqrt(energy+1)
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return best
+}
+
+// reset ends catching up, the queue continues to be copied as is
+func (w *wsola) reset() {
+	w.active = false
+	w.drift = 0
+}
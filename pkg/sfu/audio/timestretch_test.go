@@ -0,0 +1,98 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sineSamples(n int, freq float64, offset int) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*freq*float64(offset+i)/OpusSampleRate))
+	}
+	return samples
+}
+
+func zeroCrossings(samples []int16) int {
+	crossings := 0
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1] < 0) != (samples[i] < 0) {
+			crossings++
+		}
+	}
+	return crossings
+}
+
+func TestWSOLA(t *testing.T) {
+	require.Nil(t, newWSOLA(2))
+
+	w := newWSOLA(OpusFrameSize)
+	require.Equal(t, 0, OpusFrameSize%w.hop)
+	for i := 0; i < w.hop; i++ {
+		require.InDelta(t, 1, w.window[i]+w.window[w.hop+i], 1e-6)
+	}
+
+	// too short to compress
+	out := make([]int16, OpusFrameSize)
+	require.Zero(t, w.compress(make([]int16, OpusFrameSize), 1.25, out))
+	require.False(t, w.active)
+
+	queue := sineSamples(20*OpusFrameSize, 200, 0)
+	var compressed []int16
+	consumed := 0
+	for i := 0; i < 10; i++ {
+		n := w.compress(queue[consumed:], 1.25, out)
+		require.NotZero(t, n)
+		consumed += n
+		compressed = append(compressed, out...)
+	}
+
+	// the backlog shrinks at about the requested speed
+	require.InDelta(t, 1.25, float64(consumed)/float64(len(compressed)), 0.05)
+	// and the pitch is kept
+	require.InDelta(t, zeroCrossings(queue[:len(compressed)]), zeroCrossings(compressed), 4)
+}
+
+func TestMixer_CatchUp(t *testing.T) {
+	params := FramingConfig{FrameDuration: 20 * time.Millisecond}.MixerParams(MixerConfig{
+		JitterFrames: 2,
+		CatchUp:      CatchUpConfig{Enabled: true, MaxSpeed: 1.5},
+	})
+	require.Equal(t, 1.5, params.CatchUpMaxSpeed)
+	require.Equal(t, DefaultCatchUpConfig.MaxBacklogFrames, params.MaxQueuedFrames)
+
+	m := NewMixer(params)
+	m.AddSource("a")
+
+	// a second of audio arrives at once after a stall, none of it is dropped
+	m.Push("a", sineSamples(50*OpusFrameSize, 200, 0))
+	require.Equal(t, 50*OpusFrameSize, len(m.sources["a"].queue))
+
+	ticks := 0
+	for len(m.sources["a"].queue) > (params.JitterFrames+1)*params.FrameSize {
+		require.Equal(t, 1, m.Tick().NumContributors())
+		ticks++
+	}
+	// catching up at 1.5x takes about two thirds of the frames forwarding as is would take
+	require.Less(t, ticks, 50-params.JitterFrames-1)
+	require.Greater(t, ticks, 30)
+	require.False(t, m.sources["a"].catchUp.active)
+}
@@ -0,0 +1,214 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"time"
+)
+
+// UtteranceConfig controls end of utterance detection on published audio, for conversational agents
+// deciding when to respond. Builds on voice activity detection, speech starts an utterance once it
+// lasted the VAD min speech.
+type UtteranceConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// silence after speech that ends an utterance, longer than the VAD hangover so that
+	// an utterance spans the pauses of a speaker within a turn
+	Hangover time.Duration `yaml:"hangover,omitempty"`
+	// utterances are cut at this length, speech going on starts the next utterance right away
+	MaxDuration time.Duration `yaml:"max_duration,omitempty"`
+}
+
+var (
+	DefaultUtteranceConfig = UtteranceConfig{
+		Hangover:    800 * time.Millisecond,
+		MaxDuration: 30 * time.Second,
+	}
+)
+
+type UtteranceEndReason string
+
+const (
+	UtteranceEndSilence     UtteranceEndReason = "silence"
+	UtteranceEndMaxDuration UtteranceEndReason = "max_duration"
+	UtteranceEndStreamEnded UtteranceEndReason = "stream_ended"
+)
+
+// UtteranceEvent reports an utterance starting or ending. Its range is trimmed to speech, silence
+// before its first and after its last packet classified as speech is left out.
+type UtteranceEvent struct {
+	Started bool
+	// counts the utterances of the stream, from 1
+	Sequence uint32
+	// RTP timestamp of the first sample of the utterance and, once ended, the timestamp one past its last sample
+	StartTimestamp uint32
+	EndTimestamp   uint32
+	ClockRate      uint32
+	// arrival of the first and last speech
+	StartTime time.Time
+	EndTime   time.Time
+	// why the utterance ended, empty for started
+	Reason UtteranceEndReason
+}
+
+// UtteranceDetector turns the voice activity of the packets of a stream into utterances on the RTP timeline
+// of the stream. Not safe for concurrent use.
+type UtteranceDetector struct {
+	clockRate      uint32
+	minSpeechTicks int64
+	hangoverTicks  int64
+	maxTicks       int64
+	hangover       time.Duration
+
+	// RTP timestamps wrap around, the extended timestamp of the last packet counts on from the first packet
+	primed   bool
+	lastTS   uint32
+	extended int64
+	// run of speech packets, the utterance starts with it once it lasted min speech
+	inRun    bool
+	runStart int64
+	runTime  time.Time
+
+	speaking  bool
+	sequence  uint32
+	start     int64
+	startTime time.Time
+	// end of the last speech of the utterance
+	speechEnd     int64
+	speechEndTime time.Time
+}
+
+func NewUtteranceDetector(config UtteranceConfig, minSpeech time.Duration, clockRate uint32) *UtteranceDetector {
+	if config.Hangover <= 0 {
+		config.Hangover = DefaultUtteranceConfig.Hangover
+	}
+	d := &UtteranceDetector{
+		clockRate: clockRate,
+		hangover:  config.Hangover,
+	}
+	d.minSpeechTicks = d.ticks(max(minSpeech, 0))
+	d.hangoverTicks = d.ticks(config.Hangover)
+	if config.MaxDuration > 0 {
+		d.maxTicks = d.ticks(config.MaxDuration)
+	}
+	return d
+}
+
+func (d *UtteranceDetector) ticks(duration time.Duration) int64 {
+	return int64(duration) * int64(d.clockRate) / int64(time.Second)
+}
+
+func (d *UtteranceDetector) extend(timestamp uint32) int64 {
+	if !d.primed {
+		d.primed = true
+		d.lastTS = timestamp
+		return d.extended
+	}
+	d.extended += int64(int32(timestamp - d.lastTS))
+	d.lastTS = timestamp
+	return d.extended
+}
+
+// Observe records whether the packet with timestamp, carrying duration of audio and arriving at now, was speech.
+// Returns the utterances started and ended by it, an utterance cut at its max duration ends and
+// the next one starts with the same packet.
+func (d *UtteranceDetector) Observe(now time.Time, timestamp uint32, duration time.Duration, isSpeech bool) []*UtteranceEvent {
+	if d == nil {
+		return nil
+	}
+
+	ts := d.extend(timestamp)
+	if !isSpeech {
+		d.inRun = false
+		if d.speaking && ts-d.speechEnd >= d.hangoverTicks {
+			return []*UtteranceEvent{d.end(UtteranceEndSilence)}
+		}
+		return nil
+	}
+
+	if !d.inRun {
+		d.inRun = true
+		d.runStart = ts
+		d.runTime = now
+	}
+	end := ts + d.ticks(duration)
+
+	var events []*UtteranceEvent
+	if !d.speaking {
+		if end-d.runStart < d.minSpeechTicks {
+			return nil
+		}
+		events = append(events, d.begin(d.runStart, d.runTime))
+	}
+	d.speechEnd = end
+	d.speechEndTime = now
+
+	if d.maxTicks > 0 && end-d.start >= d.maxTicks {
+		events = append(events, d.end(UtteranceEndMaxDuration), d.begin(end, now))
+	}
+	return events
+}
+
+// Expire ends the utterance going on if no speech arrived for the hangover, for streams that stopped
+// sending packets, e. g. when muted
+func (d *UtteranceDetector) Expire(now time.Time) *UtteranceEvent {
+	if d == nil || !d.speaking || now.Sub(d.speechEndTime) < d.hangover {
+		return nil
+	}
+	d.inRun = false
+	return d.end(UtteranceEndSilence)
+}
+
+// Close ends detection, returns the end of the utterance going on if any
+func (d *UtteranceDetector) Close() *UtteranceEvent {
+	if d == nil || !d.speaking {
+		return nil
+	}
+	return d.end(UtteranceEndStreamEnded)
+}
+
+func (d *UtteranceDetector) begin(start int64, startTime time.Time) *UtteranceEvent {
+	d.speaking = true
+	d.sequence++
+	d.start = start
+	d.startTime = startTime
+	d.speechEnd = start
+	d.speechEndTime = startTime
+	return &UtteranceEvent{
+		Started:        true,
+		Sequence:       d.sequence,
+		StartTimestamp: d.timestamp(start),
+		ClockRate:      d.clockRate,
+		StartTime:      startTime,
+	}
+}
+
+func (d *UtteranceDetector) end(reason UtteranceEndReason) *UtteranceEvent {
+	d.speaking = false
+	return &UtteranceEvent{
+		Sequence:       d.sequence,
+		StartTimestamp: d.timestamp(d.start),
+		EndTimestamp:   d.timestamp(d.speechEnd),
+		ClockRate:      d.clockRate,
+		StartTime:      d.startTime,
+		EndTime:        d.speechEndTime,
+		Reason:         reason,
+	}
+}
+
+// timestamp returns the RTP timestamp of an extended timestamp
+func (d *UtteranceDetector) timestamp(extended int64) uint32 {
+	return d.lastTS + uint32(extended-d.extended)
+}
@@ -0,0 +1,125 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUtteranceDetector(t *testing.T) {
+	const frame = 20 * time.Millisecond
+	config := UtteranceConfig{Hangover: 100 * time.Millisecond, MaxDuration: time.Second}
+	start := time.Unix(1000, 0)
+
+	// observes frames of speech or silence from timestamp on, returns the events and the timestamp after
+	observe := func(d *UtteranceDetector, timestamp uint32, speech []bool) ([]*UtteranceEvent, uint32) {
+		var events []*UtteranceEvent
+		for _, isSpeech := range speech {
+			now := start.Add(time.Duration(timestamp) * time.Second / 48000)
+			events = append(events, d.Observe(now, timestamp, frame, isSpeech)...)
+			timestamp += 960
+		}
+		return events, timestamp
+	}
+	frames := func(n int, isSpeech bool) []bool {
+		speech := make([]bool, n)
+		for i := range speech {
+			speech[i] = isSpeech
+		}
+		return speech
+	}
+
+	t.Run("trims silence", func(t *testing.T) {
+		d := NewUtteranceDetector(config, 40*time.Millisecond, 48000)
+
+		// a click does not start an utterance
+		events, ts := observe(d, 0, []bool{false, true, false, false})
+		require.Empty(t, events)
+
+		events, ts = observe(d, ts, frames(3, true))
+		require.Len(t, events, 1)
+		require.True(t, events[0].Started)
+		require.Equal(t, uint32(1), events[0].Sequence)
+		require.Equal(t, uint32(4*960), events[0].StartTimestamp)
+
+		// pauses shorter than the hangover do not end it
+		events, ts = observe(d, ts, append(frames(4, false), true))
+		require.Empty(t, events)
+
+		events, _ = observe(d, ts, frames(6, false))
+		require.Len(t, events, 1)
+		ended := events[0]
+		require.False(t, ended.Started)
+		require.Equal(t, UtteranceEndSilence, ended.Reason)
+		require.Equal(t, uint32(4*960), ended.StartTimestamp)
+		// one past the last speech frame
+		require.Equal(t, uint32(12*960), ended.EndTimestamp)
+		require.Equal(t, uint32(48000), ended.ClockRate)
+	})
+
+	t.Run("cuts at max duration", func(t *testing.T) {
+		d := NewUtteranceDetector(config, 0, 48000)
+
+		events, _ := observe(d, 0, frames(60, true))
+		require.Len(t, events, 3)
+		require.True(t, events[0].Started)
+		require.Equal(t, UtteranceEndMaxDuration, events[1].Reason)
+		require.Equal(t, uint32(48000), events[1].EndTimestamp)
+		require.True(t, events[2].Started)
+		require.Equal(t, uint32(2), events[2].Sequence)
+		require.Equal(t, uint32(48000), events[2].StartTimestamp)
+	})
+
+	t.Run("wraps around", func(t *testing.T) {
+		d := NewUtteranceDetector(config, 0, 48000)
+
+		events, ts := observe(d, 0xffffffff-959, frames(2, true))
+		require.Len(t, events, 1)
+		require.Equal(t, uint32(0xffffffff-959), events[0].StartTimestamp)
+
+		events, _ = observe(d, ts, frames(6, false))
+		require.Len(t, events, 1)
+		require.Equal(t, uint32(960), events[0].EndTimestamp)
+	})
+
+	t.Run("expires and closes", func(t *testing.T) {
+		d := NewUtteranceDetector(config, 0, 48000)
+		events, _ := observe(d, 0, frames(1, true))
+		require.Len(t, events, 1)
+
+		require.Nil(t, d.Expire(start.Add(50*time.Millisecond)))
+		ended := d.Expire(start.Add(200 * time.Millisecond))
+		require.NotNil(t, ended)
+		require.Equal(t, UtteranceEndSilence, ended.Reason)
+		require.Nil(t, d.Close())
+
+		observe(d, 960*20, frames(1, true))
+		ended = d.Close()
+		require.NotNil(t, ended)
+		require.Equal(t, UtteranceEndStreamEnded, ended.Reason)
+		require.Equal(t, uint32(2), ended.Sequence)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		var d *UtteranceDetector
+		require.Nil(t, d.Observe(start, 0, frame, true))
+		require.Nil(t, d.Expire(start))
+		require.Nil(t, d.Close())
+	})
+}
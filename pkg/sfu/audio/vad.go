@@ -0,0 +1,169 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// time constants the noise floor follows the level with, quickly down into pauses, slowly up so that
+	// speech barely lifts it
+	vadFloorFall = 50 * time.Millisecond
+	vadFloorRise = 5 * time.Second
+)
+
+// VADConfig controls voice activity detection on published audio independent of noise suppression,
+// participants starting and stopping to speak are reported as events
+type VADConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// dB above the noise floor a frame needs to count as speech
+	Margin float64 `yaml:"margin,omitempty"`
+	// dBFS below which frames never count as speech
+	MinLevel float64 `yaml:"min_level,omitempty"`
+	// speech needed before a participant counts as speaking, keeps clicks and pops from starting speech
+	MinSpeech time.Duration `yaml:"min_speech,omitempty"`
+	// silence after speech before a participant stops speaking, bridges the pauses between words
+	Hangover time.Duration `yaml:"hangover,omitempty"`
+	// utterance started and ended events for agent turn-taking
+	Utterance UtteranceConfig `yaml:"utterance,omitempty"`
+}
+
+var (
+	DefaultVADConfig = VADConfig{
+		Margin:    10,
+		MinLevel:  -50,
+		MinSpeech: 60 * time.Millisecond,
+		Hangover:  600 * time.Millisecond,
+		Utterance: DefaultUtteranceConfig,
+	}
+)
+
+// EnergyVAD classifies frames as speech by their level relative to a tracked noise floor.
+// Much cheaper than the RNNoise VAD of the noise filter, for streams that are not denoised.
+// Not safe for concurrent use.
+type EnergyVAD struct {
+	config VADConfig
+	floor  float64
+	primed bool
+}
+
+func NewEnergyVAD(config VADConfig) *EnergyVAD {
+	return &EnergyVAD{config: config}
+}
+
+// IsSpeech returns true if a frame of pcm at sampleRate is speech, and follows the noise floor with it
+func (v *EnergyVAD) IsSpeech(pcm []int16, sampleRate int) bool {
+	if len(pcm) == 0 || sampleRate <= 0 {
+		return false
+	}
+
+	var sum float64
+	for _, s := range pcm {
+		sum += float64(s) * float64(s)
+	}
+	level := float64(minDBFS)
+	if rms := math.Sqrt(sum/float64(len(pcm))) / 32768; rms > 0 {
+		level = max(20*math.Log10(rms), minDBFS)
+	}
+
+	isSpeech := v.primed && level >= v.config.MinLevel && level >= v.floor+v.config.Margin
+
+	duration := time.Duration(len(pcm)) * time.Second / time.Duration(sampleRate)
+	switch {
+	case !v.primed:
+		v.floor = level
+		v.primed = true
+	case level < v.floor:
+		v.floor += (level - v.floor) * min(float64(duration)/float64(vadFloorFall), 1)
+	default:
+		v.floor += (level - v.floor) * min(float64(duration)/float64(vadFloorRise), 1)
+	}
+	return isSpeech
+}
+
+// --------------------------------------
+
+type SpeakingTransition struct {
+	Speaking bool
+	// when the speech started, for transitions to speaking the onset, for transitions to stopped the
+	// start of the speech that ended
+	SpeechStart time.Time
+	// when the last speech was heard, for transitions to stopped
+	SpeechEnd time.Time
+}
+
+// SpeakingDetector turns per frame voice activity into speaking and stopped speaking transitions.
+// Speaking starts after MinSpeech of continuous speech and stops after Hangover without speech.
+// Not safe for concurrent use.
+type SpeakingDetector struct {
+	config VADConfig
+
+	speaking bool
+	// start of the current run of speech frames, zero outside speech
+	runStart    time.Time
+	speechStart time.Time
+	lastSpeech  time.Time
+}
+
+func NewSpeakingDetector(config VADConfig) *SpeakingDetector {
+	if config.MinSpeech < 0 {
+		config.MinSpeech = 0
+	}
+	if config.Hangover <= 0 {
+		config.Hangover = DefaultVADConfig.Hangover
+	}
+	return &SpeakingDetector{config: config}
+}
+
+// Observe records whether the frame ending at now was speech, returns the transition if any
+func (d *SpeakingDetector) Observe(now time.Time, isSpeech bool) *SpeakingTransition {
+	if !isSpeech {
+		d.runStart = time.Time{}
+		if d.speaking && now.Sub(d.lastSpeech) >= d.config.Hangover {
+			d.speaking = false
+			return &SpeakingTransition{SpeechStart: d.speechStart, SpeechEnd: d.lastSpeech}
+		}
+		return nil
+	}
+
+	if d.runStart.IsZero() {
+		d.runStart = now
+	}
+	d.lastSpeech = now
+	if d.speaking || now.Sub(d.runStart) < d.config.MinSpeech {
+		return nil
+	}
+
+	d.speaking = true
+	d.speechStart = d.runStart
+	return &SpeakingTransition{Speaking: true, SpeechStart: d.speechStart}
+}
+
+// Close ends detection, returns the transition to stopped if the participant was speaking
+func (d *SpeakingDetector) Close() *SpeakingTransition {
+	if !d.speaking {
+		return nil
+	}
+
+	d.speaking = false
+	return &SpeakingTransition{SpeechStart: d.speechStart, SpeechEnd: d.lastSpeech}
+}
+
+func (d *SpeakingDetector) IsSpeaking() bool {
+	return d.speaking
+}
@@ -0,0 +1,107 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnergyVAD(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	frame := func(amplitude float64) []int16 {
+		pcm := make([]int16, 480)
+		for i := range pcm {
+			pcm[i] = int16(rng.NormFloat64() * amplitude)
+		}
+		return pcm
+	}
+
+	vad := NewEnergyVAD(DefaultVADConfig)
+	// background noise around -45 dBFS never counts as speech
+	for range 100 {
+		require.False(t, vad.IsSpeech(frame(180), 48000))
+	}
+	// speech around -20 dBFS does, also after seconds of it
+	for range 300 {
+		require.True(t, vad.IsSpeech(frame(3300), 48000))
+	}
+	// the floor falls back quickly in the pause
+	for range 10 {
+		vad.IsSpeech(frame(180), 48000)
+	}
+	require.False(t, vad.IsSpeech(frame(180), 48000))
+	require.True(t, vad.IsSpeech(frame(3300), 48000))
+
+	// quiet frames stay below the minimum level whatever the floor
+	vad = NewEnergyVAD(DefaultVADConfig)
+	vad.IsSpeech(make([]int16, 480), 48000)
+	require.False(t, vad.IsSpeech(frame(50), 48000))
+}
+
+func TestSpeakingDetector(t *testing.T) {
+	config := DefaultVADConfig
+	config.MinSpeech = 30 * time.Millisecond
+	config.Hangover = 100 * time.Millisecond
+	d := NewSpeakingDetector(config)
+
+	now := time.Now()
+	step := func(isSpeech bool) *SpeakingTransition {
+		now = now.Add(10 * time.Millisecond)
+		return d.Observe(now, isSpeech)
+	}
+
+	// a click does not start speech
+	require.Nil(t, step(true))
+	require.Nil(t, step(false))
+
+	onset := now.Add(10 * time.Millisecond)
+	require.Nil(t, step(true))
+	require.Nil(t, step(true))
+	require.Nil(t, step(true))
+	tr := step(true)
+	require.NotNil(t, tr)
+	require.True(t, tr.Speaking)
+	require.Equal(t, onset, tr.SpeechStart)
+	require.True(t, d.IsSpeaking())
+
+	// a pause shorter than the hangover is bridged
+	for range 5 {
+		require.Nil(t, step(false))
+	}
+	require.Nil(t, step(true))
+	end := now
+
+	for range 9 {
+		require.Nil(t, step(false))
+	}
+	tr = step(false)
+	require.NotNil(t, tr)
+	require.False(t, tr.Speaking)
+	require.Equal(t, onset, tr.SpeechStart)
+	require.Equal(t, end, tr.SpeechEnd)
+	require.Nil(t, d.Close())
+
+	for range 4 {
+		step(true)
+	}
+	tr = d.Close()
+	require.NotNil(t, tr)
+	require.False(t, tr.Speaking)
+}
@@ -0,0 +1,157 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// rate of the mono audio wake word detectors process
+	WakeWordSampleRate = 16000
+)
+
+var ErrUnknownWakeWordModel = errors.New("unknown wake word model")
+
+// WakeWordConfig controls spotting configured phrases in published audio, so agents can be summoned hands-free.
+// Detection is done by a model registered with RegisterWakeWordModel, there is no built in one.
+type WakeWordConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// model spotting the phrases, registered with RegisterWakeWordModel
+	Model string `yaml:"model,omitempty"`
+	// weights of models that load them
+	ModelPath string `yaml:"model_path,omitempty"`
+	// phrases that are reported, e. g. "hey agent", matched regardless of case
+	Phrases []string `yaml:"phrases,omitempty"`
+	// minimum score, 0.0-1.0, of a detection to be reported
+	Threshold float64 `yaml:"threshold,omitempty"`
+	// time after a detection the same phrase is not reported again on a track
+	Cooldown time.Duration `yaml:"cooldown,omitempty"`
+}
+
+var (
+	DefaultWakeWordConfig = WakeWordConfig{
+		Threshold: 0.5,
+		Cooldown:  2 * time.Second,
+	}
+)
+
+type WakeWordDetection struct {
+	Phrase string
+	// confidence of the model, 0.0-1.0
+	Score float64
+}
+
+// WakeWordDetector spots phrases in mono audio at WakeWordSampleRate. Not safe for concurrent use.
+type WakeWordDetector interface {
+	// Process consumes the next samples of the stream, returns the phrases that ended within them
+	Process(pcm []int16) []WakeWordDetection
+	// Close frees the resources of the detector
+	Close()
+}
+
+type WakeWordModel func(config WakeWordConfig) (WakeWordDetector, error)
+
+// --------------------------------------
+
+var (
+	wakeWordLock   sync.RWMutex
+	wakeWordModels = map[string]WakeWordModel{}
+)
+
+// RegisterWakeWordModel makes a model available to the `model` setting, replacing one of the same name
+func RegisterWakeWordModel(name string, model WakeWordModel) {
+	wakeWordLock.Lock()
+	wakeWordModels[name] = model
+	wakeWordLock.Unlock()
+}
+
+// NewWakeWordDetector creates a detector of the configured model
+func NewWakeWordDetector(config WakeWordConfig) (WakeWordDetector, error) {
+	wakeWordLock.RLock()
+	model := wakeWordModels[config.Model]
+	wakeWordLock.RUnlock()
+
+	if model == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownWakeWordModel, config.Model)
+	}
+	return model(config)
+}
+
+// --------------------------------------
+
+// WakeWordSpotter reports the detections of a detector that are configured phrases scoring at least the threshold,
+// each phrase at most once per cooldown. Not safe for concurrent use.
+type WakeWordSpotter struct {
+	config   WakeWordConfig
+	detector WakeWordDetector
+	phrases  map[string]string
+	last     map[string]time.Time
+}
+
+func NewWakeWordSpotter(config WakeWordConfig, detector WakeWordDetector) *WakeWordSpotter {
+	if config.Threshold <= 0 || config.Threshold > 1 {
+		config.Threshold = DefaultWakeWordConfig.Threshold
+	}
+	if config.Cooldown < 0 {
+		config.Cooldown = 0
+	}
+
+	phrases := make(map[string]string, len(config.Phrases))
+	for _, phrase := range config.Phrases {
+		phrases[normalizeWakeWord(phrase)] = phrase
+	}
+	return &WakeWordSpotter{
+		config:   config,
+		detector: detector,
+		phrases:  phrases,
+		last:     make(map[string]time.Time),
+	}
+}
+
+// Process runs the detector on the samples received at now, returns the detections to report,
+// with the phrases as they are configured
+func (s *WakeWordSpotter) Process(now time.Time, pcm []int16) []WakeWordDetection {
+	var reported []WakeWordDetection
+	for _, d := range s.detector.Process(pcm) {
+		if d.Score < s.config.Threshold {
+			continue
+		}
+		key := normalizeWakeWord(d.Phrase)
+		phrase, ok := s.phrases[key]
+		if !ok {
+			continue
+		}
+		if last, ok := s.last[key]; ok && now.Sub(last) < s.config.Cooldown {
+			continue
+		}
+		s.last[key] = now
+		reported = append(reported, WakeWordDetection{Phrase: phrase, Score: d.Score})
+	}
+	return reported
+}
+
+func (s *WakeWordSpotter) Close() {
+	s.detector.Close()
+}
+
+func normalizeWakeWord(phrase string) string {
+	return strings.Join(strings.Fields(strings.ToLower(phrase)), " ")
+}
@@ -0,0 +1,88 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedWakeWordDetector returns the detections queued for each call of Process
+type scriptedWakeWordDetector struct {
+	detections [][]WakeWordDetection
+	closed     bool
+}
+
+func (d *scriptedWakeWordDetector) Process(pcm []int16) []WakeWordDetection {
+	if len(d.detections) == 0 {
+		return nil
+	}
+	next := d.detections[0]
+	d.detections = d.detections[1:]
+	return next
+}
+
+func (d *scriptedWakeWordDetector) Close() {
+	d.closed = true
+}
+
+func TestWakeWordDetector(t *testing.T) {
+	_, err := NewWakeWordDetector(WakeWordConfig{Model: "missing"})
+	require.ErrorIs(t, err, ErrUnknownWakeWordModel)
+
+	var created WakeWordConfig
+	RegisterWakeWordModel("test", func(config WakeWordConfig) (WakeWordDetector, error) {
+		created = config
+		return &scriptedWakeWordDetector{}, nil
+	})
+	_, err = NewWakeWordDetector(WakeWordConfig{Model: "test", ModelPath: "hey_agent.onnx"})
+	require.NoError(t, err)
+	require.Equal(t, "hey_agent.onnx", created.ModelPath)
+}
+
+func TestWakeWordSpotter(t *testing.T) {
+	detector := &scriptedWakeWordDetector{
+		detections: [][]WakeWordDetection{
+			{{Phrase: "hey  AGENT", Score: 0.9}},
+			// below the threshold
+			{{Phrase: "hey agent", Score: 0.3}},
+			// not configured
+			{{Phrase: "hello", Score: 0.9}},
+			// within the cooldown
+			{{Phrase: "hey agent", Score: 0.8}},
+			{{Phrase: "hey agent", Score: 0.8}, {Phrase: "Stop", Score: 0.7}},
+		},
+	}
+	config := DefaultWakeWordConfig
+	config.Phrases = []string{"Hey Agent", "stop"}
+	spotter := NewWakeWordSpotter(config, detector)
+
+	now := time.Now()
+	pcm := make([]int16, WakeWordSampleRate/50)
+	require.Equal(t, []WakeWordDetection{{Phrase: "Hey Agent", Score: 0.9}}, spotter.Process(now, pcm))
+	require.Empty(t, spotter.Process(now.Add(20*time.Millisecond), pcm))
+	require.Empty(t, spotter.Process(now.Add(40*time.Millisecond), pcm))
+	require.Empty(t, spotter.Process(now.Add(time.Second), pcm))
+	require.Equal(t, []WakeWordDetection{
+		{Phrase: "Hey Agent", Score: 0.8},
+		{Phrase: "stop", Score: 0.7},
+	}, spotter.Process(now.Add(3*time.Second), pcm))
+
+	spotter.Close()
+	require.True(t, detector.closed)
+}
@@ -0,0 +1,217 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+const wavHeaderSize = 44
+
+var (
+	ErrWAVTooLarge    = errors.New("wav data exceeds 4 GiB")
+	ErrWAVUnsupported = errors.New("not a 16 bit PCM wav file")
+)
+
+// WAVWriter writes 16 bit PCM as a RIFF/WAVE file. The sizes in the header
+// are filled in on Close, which needs the underlying writer to be seekable.
+type WAVWriter struct {
+	w          io.WriteSeeker
+	sampleRate int
+	channels   int
+	dataBytes  int64
+	buf        []byte
+}
+
+func NewWAVWriter(w io.WriteSeeker, sampleRate int, channels int) (*WAVWriter, error) {
+	ww := &WAVWriter{
+		w:          w,
+		sampleRate: sampleRate,
+		channels:   channels,
+	}
+	if _, err := w.Write(ww.header()); err != nil {
+		return nil, err
+	}
+	return ww, nil
+}
+
+// Write appends interleaved samples
+func (ww *WAVWriter) Write(pcm []int16) error {
+	if ww.dataBytes+int64(len(pcm)*2) > 0xffffffff-wavHeaderSize {
+		return ErrWAVTooLarge
+	}
+
+	if cap(ww.buf) < len(pcm)*2 {
+		ww.buf = make([]byte, len(pcm)*2)
+	}
+	buf := ww.buf[:len(pcm)*2]
+	for i, s := range pcm {
+		binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+	}
+
+	n, err := ww.w.Write(buf)
+	ww.dataBytes += int64(n)
+	return err
+}
+
+// WriteSilence appends the given number of zero samples per channel
+func (ww *WAVWriter) WriteSilence(samples int) error {
+	silence := make([]int16, min(samples*ww.channels, 4800))
+	for remaining := samples * ww.channels; remaining > 0; remaining -= len(silence) {
+		if err := ww.Write(silence[:min(remaining, len(silence))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Samples returns the number of samples per channel written so far
+func (ww *WAVWriter) Samples() int64 {
+	return ww.dataBytes / int64(2*ww.channels)
+}
+
+// Close finalizes the header, it does not close the underlying writer
+func (ww *WAVWriter) Close() error {
+	if _, err := ww.w.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := ww.w.Write(ww.header()); err != nil {
+		return err
+	}
+	_, err := ww.w.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (ww *WAVWriter) header() []byte {
+	h := make([]byte, wavHeaderSize)
+	copy(h[0:], "RIFF")
+	binary.LittleEndian.PutUint32(h[4:], uint32(wavHeaderSize-8+ww.dataBytes))
+	copy(h[8:], "WAVE")
+	copy(h[12:], "fmt ")
+	binary.LittleEndian.PutUint32(h[16:], 16) // PCM fmt chunk size
+	binary.LittleEndian.PutUint16(h[20:], 1)  // PCM
+	binary.LittleEndian.PutUint16(h[22:], uint16(ww.channels))
+	binary.LittleEndian.PutUint32(h[24:], uint32(ww.sampleRate))
+	binary.LittleEndian.PutUint32(h[28:], uint32(ww.sampleRate*ww.channels*2))
+	binary.LittleEndian.PutUint16(h[32:], uint16(ww.channels*2))
+	binary.LittleEndian.PutUint16(h[34:], 16)
+	copy(h[36:], "data")
+	binary.LittleEndian.PutUint32(h[40:], uint32(ww.dataBytes))
+	return h
+}
+
+// ScaleWAV scales the samples of a file written by WAVWriter in place by factor, clipping what exceeds the
+// range of int16
+func ScaleWAV(f io.ReadWriteSeeker, factor float64) error {
+	if factor == 1 {
+		return nil
+	}
+
+	buf := make([]byte, 64<<10)
+	pcm := make([]int16, len(buf)/2)
+	for offset := int64(wavHeaderSize); ; {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		n, err := io.ReadFull(f, buf)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			return err
+		}
+		n -= n % 2
+
+		samples := pcm[:n/2]
+		for i := range samples {
+			samples[i] = int16(binary.LittleEndian.Uint16(buf[i*2:]))
+		}
+		ApplyGain(samples, factor)
+		for i, s := range samples {
+			binary.LittleEndian.PutUint16(buf[i*2:], uint16(s))
+		}
+
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+		if _, err := f.Write(buf[:n]); err != nil {
+			return err
+		}
+		offset += int64(n)
+		if n < len(buf) {
+			return nil
+		}
+	}
+}
+
+// WAVFormat is the format of the samples of a WAV file
+type WAVFormat struct {
+	SampleRate int
+	Channels   int
+}
+
+// ReadWAVHeader reads the chunks of a WAV file up to its samples, which r is positioned at on return.
+// Only 16 bit PCM is supported.
+func ReadWAVHeader(r io.Reader) (WAVFormat, error) {
+	riff := make([]byte, 12)
+	if _, err := io.ReadFull(r, riff); err != nil {
+		return WAVFormat{}, err
+	}
+	if string(riff[0:4]) != "RIFF" || string(riff[8:12]) != "WAVE" {
+		return WAVFormat{}, ErrWAVUnsupported
+	}
+
+	var format WAVFormat
+	chunk := make([]byte, 8)
+	for {
+		if _, err := io.ReadFull(r, chunk); err != nil {
+			return WAVFormat{}, err
+		}
+		size := int64(binary.LittleEndian.Uint32(chunk[4:]))
+
+		switch string(chunk[0:4]) {
+		case "fmt ":
+			if size < 16 {
+				return WAVFormat{}, ErrWAVUnsupported
+			}
+			fmtChunk := make([]byte, size+size%2)
+			if _, err := io.ReadFull(r, fmtChunk); err != nil {
+				return WAVFormat{}, err
+			}
+			// PCM, or WAVE_FORMAT_EXTENSIBLE of 16 bit samples
+			tag := binary.LittleEndian.Uint16(fmtChunk[0:])
+			if (tag != 1 && tag != 0xfffe) || binary.LittleEndian.Uint16(fmtChunk[14:]) != 16 {
+				return WAVFormat{}, ErrWAVUnsupported
+			}
+			format.Channels = int(binary.LittleEndian.Uint16(fmtChunk[2:]))
+			format.SampleRate = int(binary.LittleEndian.Uint32(fmtChunk[4:]))
+
+		case "data":
+			if format.SampleRate == 0 || format.Channels == 0 {
+				return WAVFormat{}, ErrWAVUnsupported
+			}
+			return format, nil
+
+		default:
+			// chunks are padded to an even size
+			if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+				return WAVFormat{}, err
+			}
+		}
+	}
+}
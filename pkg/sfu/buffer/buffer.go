@@ -19,11 +19,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gammazero/deque"
+	"github.com/pion/interceptor"
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
@@ -37,6 +39,7 @@ import (
 	dd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/dependencydescriptor"
 	"github.com/livekit/livekit-server/pkg/sfu/rtpstats"
 	"github.com/livekit/livekit-server/pkg/sfu/utils"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 	sutils "github.com/livekit/livekit-server/pkg/utils"
 	"github.com/livekit/mediatransportutil/pkg/bucket"
 	"github.com/livekit/mediatransportutil/pkg/nack"
@@ -60,6 +63,8 @@ var (
 type pendingPacket struct {
 	arrivalTime int64
 	packet      []byte
+	// repaired from an RTX stream, only queued for stages
+	isRTX bool
 }
 
 type ExtPacket struct {
@@ -74,6 +79,8 @@ type ExtPacket struct {
 	DependencyDescriptor *ExtDependencyDescriptor
 	AbsCaptureTimeExt    *act.AbsCaptureTime
 	IsOutOfOrder         bool
+	// RFC 4733 telephone event (in-band DTMF) sent on the stream of an audio track
+	IsTelephoneEvent bool
 }
 
 // VideoSize represents video resolution
@@ -107,6 +114,8 @@ type Buffer struct {
 	rtxPayloadType uint8
 	mime           mime.MimeType
 
+	telephoneEventPayloadTypes []uint8
+
 	snRangeMap *utils.RangeMap[uint64, uint64]
 
 	latestTSForAudioLevelInitialized bool
@@ -161,6 +170,12 @@ type Buffer struct {
 	absCaptureTimeExtID uint8
 
 	keyFrameSeederGeneration atomic.Int32
+
+	// processing of the packets ahead of buffering them, the feed is set once bound
+	stages         Stages
+	stageInfo      *interceptor.StreamInfo
+	stageFeed      *stageFeed
+	stageDropCount atomic.Uint32
 }
 
 // NewBuffer constructs a new Buffer
@@ -254,6 +269,12 @@ func (b *Buffer) Bind(params webrtc.RTPParameters, codec webrtc.RTPCodecCapabili
 		b.payloadType = uint8(params.Codecs[0].PayloadType)
 	}
 
+	for _, codec := range params.Codecs {
+		if mime.IsMimeTypeStringTelephoneEvent(codec.MimeType) {
+			b.telephoneEventPayloadTypes = append(b.telephoneEventPayloadTypes, uint8(codec.PayloadType))
+		}
+	}
+
 	// find RTX payload type
 	for _, codec := range params.Codecs {
 		if mime.IsMimeTypeStringRTX(codec.MimeType) && strings.Contains(codec.SDPFmtpLine, fmt.Sprintf("apt=%d", b.payloadType)) {
@@ -322,8 +343,12 @@ func (b *Buffer) Bind(params webrtc.RTPParameters, codec webrtc.RTPCodecCapabili
 		}
 	}
 
-	for _, pp := range b.pPackets {
-		b.calc(pp.packet, nil, pp.arrivalTime, false)
+	if b.stages != nil && b.codecType == webrtc.RTPCodecTypeAudio {
+		b.bindStagesLocked()
+	} else {
+		for _, pp := range b.pPackets {
+			b.calc(pp.packet, nil, pp.arrivalTime, false)
+		}
 	}
 	b.pPackets = nil
 	b.bound = true
@@ -430,12 +455,96 @@ func (b *Buffer) Write(pkt []byte) (n int, err error) {
 		return
 	}
 
+	if b.stageFeed != nil {
+		packet := make([]byte, len(pkt))
+		copy(packet, pkt)
+		b.pushStagesLocked(pendingPacket{packet: packet, arrivalTime: now}, rtpPacket.SequenceNumber)
+		b.Unlock()
+		return
+	}
+
 	b.calc(pkt, &rtpPacket, now, false)
 	b.readCond.Broadcast()
 	b.Unlock()
 	return
 }
 
+// SetStages has the packets of the stream processed by the stages from Bind on, before they are buffered,
+// including those repaired from an RTX stream. Stages process audio, they are ignored on other streams.
+// Has no effect once bound.
+func (b *Buffer) SetStages(stages Stages, info *interceptor.StreamInfo) {
+	b.Lock()
+	defer b.Unlock()
+
+	if b.bound {
+		return
+	}
+	b.stages = stages
+	b.stageInfo = info
+}
+
+// bindStagesLocked binds the stream to its stages, the packets received ahead of Bind are processed first.
+// Must be called with the lock held.
+func (b *Buffer) bindStagesLocked() {
+	b.stageFeed = newStageFeed(max(b.maxVideoPkts, b.maxAudioPkts))
+	for _, pp := range b.pPackets {
+		b.stageFeed.push(pp)
+	}
+	go b.readStages(b.stages.BindRemoteStream(b.stageInfo, b.stageFeed))
+}
+
+// pushStagesLocked queues a packet for the stages. When they fell behind, the packet is dropped rather than
+// stalling the transport all streams of the publisher are read from, the gap is then recovered as a loss.
+// Must be called with the lock held.
+func (b *Buffer) pushStagesLocked(pp pendingPacket, sn uint16) {
+	if b.stageFeed.push(pp) {
+		return
+	}
+	prometheus.RecordReceiveStageDrop()
+	if count := b.stageDropCount.Inc(); (count-1)%100 == 0 {
+		b.logger.Warnw("stages behind, dropping packet", nil, "sn", sn, "rtx", pp.isRTX, "count", count)
+	}
+}
+
+// StageDropCount returns the number of packets dropped as the stages fell behind
+func (b *Buffer) StageDropCount() uint32 {
+	return b.stageDropCount.Load()
+}
+
+// readStages buffers the packets as the stages deliver them, until the buffer is closed
+func (b *Buffer) readStages(reader interceptor.RTPReader) {
+	buf := make([]byte, bucket.MaxPktSize)
+	for {
+		n, a, err := reader.Read(buf, nil)
+		if err != nil {
+			if errors.Is(err, io.EOF) || b.closed.Load() {
+				return
+			}
+			b.logger.Debugw("could not read processed packet", "error", err)
+			continue
+		}
+
+		var rtpPacket rtp.Packet
+		if err := rtpPacket.Unmarshal(buf[:n]); err != nil {
+			b.logger.Debugw("could not unmarshal processed packet", "error", err)
+			continue
+		}
+		arrivalTime, ok := StageArrivalTime(a)
+		if !ok {
+			arrivalTime = mono.UnixNano()
+		}
+
+		b.Lock()
+		if b.closed.Load() {
+			b.Unlock()
+			return
+		}
+		b.calc(buf[:n], &rtpPacket, arrivalTime, StageIsRTX(a))
+		b.readCond.Broadcast()
+		b.Unlock()
+	}
+}
+
 func (b *Buffer) SetPrimaryBufferForRTX(primaryBuffer *Buffer) {
 	b.Lock()
 	b.primaryBufferForRTX = primaryBuffer
@@ -487,6 +596,13 @@ func (b *Buffer) writeRTX(rtxPkt *rtp.Packet, arrivalTime int64) {
 		return
 	}
 
+	if b.stageFeed != nil {
+		packet := make([]byte, n)
+		copy(packet, b.rtxPktBuf[:n])
+		b.pushStagesLocked(pendingPacket{packet: packet, arrivalTime: arrivalTime, isRTX: true}, repairedPkt.SequenceNumber)
+		return
+	}
+
 	b.calc(b.rtxPktBuf[:n], &repairedPkt, arrivalTime, true)
 	b.readCond.Broadcast()
 }
@@ -540,9 +656,15 @@ func (b *Buffer) Close() error {
 
 		b.RLock()
 		rtpStats := b.rtpStats
+		stages, stageInfo, stageFeed := b.stages, b.stageInfo, b.stageFeed
 		b.readCond.Broadcast()
 		b.RUnlock()
 
+		if stageFeed != nil {
+			stageFeed.close()
+			stages.UnbindRemoteStream(stageInfo)
+		}
+
 		if rtpStats != nil {
 			rtpStats.Stop()
 			b.logger.Debugw("rtp stats",
@@ -903,7 +1025,8 @@ func (b *Buffer) getExtPacket(rtpPacket *rtp.Packet, arrivalTime int64, flowStat
 			Spatial:  InvalidLayerSpatial,
 			Temporal: InvalidLayerTemporal,
 		},
-		IsOutOfOrder: flowState.IsOutOfOrder,
+		IsOutOfOrder:     flowState.IsOutOfOrder,
+		IsTelephoneEvent: slices.Contains(b.telephoneEventPayloadTypes, rtpPacket.PayloadType),
 	}
 
 	if len(rtpPacket.Payload) == 0 {
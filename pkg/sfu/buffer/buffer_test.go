@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/pion/interceptor"
 	"github.com/pion/rtcp"
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v4"
@@ -433,3 +434,165 @@ func BenchmarkMemcpu(b *testing.B) {
 		copy(buf2, buf)
 	}
 }
+
+type invertingStages struct {
+	unbound chan uint32
+}
+
+// BindRemoteStream inverts the payload of every packet
+func (s *invertingStages) BindRemoteStream(_ *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err != nil {
+			return n, a, err
+		}
+		var packet rtp.Packet
+		if err := packet.Unmarshal(b[:n]); err != nil {
+			return n, a, err
+		}
+		for i := range packet.Payload {
+			packet.Payload[i] ^= 0xff
+		}
+		return n, a, nil
+	})
+}
+
+func (s *invertingStages) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	s.unbound <- info.SSRC
+}
+
+func TestStages(t *testing.T) {
+	buff := NewBuffer(123, 100, 100)
+	stages := &invertingStages{unbound: make(chan uint32, 1)}
+	buff.SetStages(stages, &interceptor.StreamInfo{SSRC: 123, MimeType: opusCodec.MimeType, ClockRate: opusCodec.ClockRate})
+
+	payload := []byte{0x01, 0x02, 0x03, 0x04}
+	write := func(sn uint16) {
+		packet := rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    111,
+				SequenceNumber: sn,
+				Timestamp:      uint32(sn) * 960,
+				SSRC:           123,
+			},
+			Payload: payload,
+		}
+		buf, err := packet.Marshal()
+		require.NoError(t, err)
+		_, err = buff.Write(buf)
+		require.NoError(t, err)
+	}
+
+	// received ahead of Bind, processed once bound
+	write(1)
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{opusCodec},
+	}, opusCodec.RTPCodecCapability, 0))
+	write(2)
+
+	buf := make([]byte, 1500)
+	var esn uint64
+	for _, sn := range []uint16{1, 2} {
+		ep, err := buff.ReadExtended(buf)
+		require.NoError(t, err)
+		require.Equal(t, sn, ep.Packet.SequenceNumber)
+		require.Equal(t, []byte{0xfe, 0xfd, 0xfc, 0xfb}, ep.Packet.Payload)
+		esn = ep.ExtSequenceNumber
+	}
+	// the buffered packet is the processed one, e. g. for retransmissions
+	n, err := buff.GetPacket(buf, esn)
+	require.NoError(t, err)
+	var packet rtp.Packet
+	require.NoError(t, packet.Unmarshal(buf[:n]))
+	require.Equal(t, []byte{0xfe, 0xfd, 0xfc, 0xfb}, packet.Payload)
+
+	require.NoError(t, buff.Close())
+	select {
+	case ssrc := <-stages.unbound:
+		require.Equal(t, uint32(123), ssrc)
+	case <-time.After(time.Second):
+		t.Fatalf("stream not unbound")
+	}
+}
+
+func TestStages_RTX(t *testing.T) {
+	buff := NewBuffer(123, 100, 100)
+	stages := &invertingStages{unbound: make(chan uint32, 1)}
+	buff.SetStages(stages, &interceptor.StreamInfo{SSRC: 123, MimeType: opusCodec.MimeType, ClockRate: opusCodec.ClockRate})
+	rtxCodec := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeRTX, ClockRate: 48000, SDPFmtpLine: "apt=111"},
+		PayloadType:        112,
+	}
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{opusCodec, rtxCodec},
+	}, opusCodec.RTPCodecCapability, 0))
+	rtxBuff := NewBuffer(456, 100, 100)
+	rtxBuff.SetPrimaryBufferForRTX(buff)
+
+	payload := []byte{0x01, 0x02, 0x03, 0x04}
+	write := func(b *Buffer, ssrc uint32, pt uint8, sn uint16, ts uint32, payload []byte) {
+		packet := rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    pt,
+				SequenceNumber: sn,
+				Timestamp:      ts,
+				SSRC:           ssrc,
+			},
+			Payload: payload,
+		}
+		buf, err := packet.Marshal()
+		require.NoError(t, err)
+		_, err = b.Write(buf)
+		require.NoError(t, err)
+	}
+
+	write(buff, 123, 111, 1, 960, payload)
+	write(buff, 123, 111, 3, 3*960, payload)
+	// retransmission of the lost packet 2, the original sequence number leads the payload
+	write(rtxBuff, 456, 112, 1, 2*960, append([]byte{0x00, 0x02}, payload...))
+
+	buf := make([]byte, 1500)
+	for _, sn := range []uint16{1, 3, 2} {
+		ep, err := buff.ReadExtended(buf)
+		require.NoError(t, err)
+		require.Equal(t, sn, ep.Packet.SequenceNumber)
+		// repaired packets are processed as well
+		require.Equal(t, []byte{0xfe, 0xfd, 0xfc, 0xfb}, ep.Packet.Payload)
+	}
+	require.Zero(t, buff.StageDropCount())
+	require.NoError(t, buff.Close())
+}
+
+func TestStages_Video(t *testing.T) {
+	buff := NewBuffer(123, 100, 100)
+	stages := &invertingStages{unbound: make(chan uint32, 1)}
+	buff.SetStages(stages, &interceptor.StreamInfo{SSRC: 123, MimeType: vp8Codec.MimeType, ClockRate: vp8Codec.ClockRate})
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{vp8Codec},
+	}, vp8Codec.RTPCodecCapability, 0))
+
+	packet := rtp.Packet{
+		Header: rtp.Header{
+			Version:        2,
+			PayloadType:    uint8(vp8Codec.PayloadType),
+			SequenceNumber: 1,
+			Timestamp:      3000,
+			SSRC:           123,
+		},
+		Payload: []byte{0x10, 0x00, 0x00, 0x9d},
+	}
+	raw, err := packet.Marshal()
+	require.NoError(t, err)
+	_, err = buff.Write(raw)
+	require.NoError(t, err)
+
+	// stages process audio only, video is buffered as received
+	ep, err := buff.ReadExtended(make([]byte, 1500))
+	require.NoError(t, err)
+	require.Equal(t, packet.Payload, ep.Packet.Payload)
+
+	require.NoError(t, buff.Close())
+	require.Empty(t, stages.unbound)
+}
@@ -0,0 +1,94 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package buffer
+
+import (
+	"io"
+	"sync"
+
+	"github.com/pion/interceptor"
+)
+
+// Stages process the packets of a stream before it buffers them, e. g. the media processing of the
+// publisher. Interceptors of a peer connection only see the packets read ahead of Bind, stages see them all.
+type Stages interface {
+	BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader
+	UnbindRemoteStream(info *interceptor.StreamInfo)
+}
+
+type stageArrivalTimeKey struct{}
+
+type stageRTXKey struct{}
+
+// StageArrivalTime returns the time the packet read by a stage arrived at the buffer
+func StageArrivalTime(a interceptor.Attributes) (int64, bool) {
+	arrivalTime, ok := a.Get(stageArrivalTimeKey{}).(int64)
+	return arrivalTime, ok
+}
+
+// StageIsRTX returns true if the packet read by a stage was repaired from an RTX stream
+func StageIsRTX(a interceptor.Attributes) bool {
+	isRTX, _ := a.Get(stageRTXKey{}).(bool)
+	return isRTX
+}
+
+// stageFeed is the reader the stages of a stream start with, it returns the packets written to the buffer
+type stageFeed struct {
+	packets   chan pendingPacket
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newStageFeed(size int) *stageFeed {
+	return &stageFeed{
+		packets: make(chan pendingPacket, max(size, 1)),
+		done:    make(chan struct{}),
+	}
+}
+
+// push queues a packet for the stages, returns false if they fell behind and the packet was dropped
+func (f *stageFeed) push(packet pendingPacket) bool {
+	select {
+	case f.packets <- packet:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *stageFeed) Read(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+	select {
+	case p := <-f.packets:
+		if len(b) < len(p.packet) {
+			return 0, a, io.ErrShortBuffer
+		}
+		if a == nil {
+			a = make(interceptor.Attributes)
+		}
+		a.Set(stageArrivalTimeKey{}, p.arrivalTime)
+		a.Set(stageRTXKey{}, p.isRTX)
+		return copy(b, p.packet), a, nil
+
+	case <-f.done:
+		return 0, a, io.EOF
+	}
+}
+
+func (f *stageFeed) close() {
+	f.closeOnce.Do(func() {
+		close(f.done)
+	})
+}
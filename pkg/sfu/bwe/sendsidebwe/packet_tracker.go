@@ -15,9 +15,9 @@
 package sendsidebwe
 
 import (
-	"math/rand"
 	"sync"
 
+	"github.com/livekit/livekit-server/pkg/replay"
 	"github.com/livekit/livekit-server/pkg/sfu/ccutils"
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/utils/mono"
@@ -49,7 +49,7 @@ type packetTracker struct {
 func newPacketTracker(params packetTrackerParams) *packetTracker {
 	return &packetTracker{
 		params:         params,
-		sequenceNumber: uint64(rand.Intn(1<<14)) + uint64(1<<15), // a random number in third quartile of sequence number space
+		sequenceNumber: uint64(replay.Intn(1<<14)) + uint64(1<<15), // a random number in third quartile of sequence number space
 	}
 }
 
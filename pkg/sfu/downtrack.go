@@ -20,7 +20,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"math/rand"
 	"strings"
 	"sync"
 	"time"
@@ -37,6 +36,8 @@ import (
 	"github.com/livekit/protocol/logger"
 	"github.com/livekit/protocol/utils/mono"
 
+	"github.com/livekit/livekit-server/pkg/replay"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/bwe"
 	"github.com/livekit/livekit-server/pkg/sfu/ccutils"
@@ -48,6 +49,7 @@ import (
 	pd "github.com/livekit/livekit-server/pkg/sfu/rtpextension/playoutdelay"
 	"github.com/livekit/livekit-server/pkg/sfu/rtpstats"
 	"github.com/livekit/livekit-server/pkg/sfu/utils"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
 )
 
 // TrackSender defines an interface send media to remote peer
@@ -72,6 +74,12 @@ type TrackSender interface {
 	SetReceiver(TrackReceiver)
 }
 
+// TelephoneEventWriter is implemented by track senders that consume the RFC 4733 telephone events
+// of an audio track. Telephone events are not media, senders without it never see them.
+type TelephoneEventWriter interface {
+	WriteTelephoneEvent(p *buffer.ExtPacket)
+}
+
 // -------------------------------------------------------------------
 
 const (
@@ -197,6 +205,9 @@ type DownTrackStreamAllocatorListener interface {
 	// get the BWE type in use
 	BWEType() bwe.BWEType
 
+	// committed estimate of the channel capacity in bps, 0 when unknown
+	ChannelCapacity() int64
+
 	// check if subscription mute can be applied
 	IsSubscribeMutable(dt *DownTrack) bool
 }
@@ -242,6 +253,10 @@ var _ TrackSender = (*DownTrack)(nil)
 
 type ReceiverReportListener func(dt *DownTrack, report *rtcp.ReceiverReport)
 
+// PacketObserver sees the packets forwarded to the subscriber after translation,
+// the payload is only valid for the duration of the call
+type PacketObserver func(hdr *rtp.Header, payload []byte)
+
 type DownTrackParams struct {
 	Codecs                         []webrtc.RTPCodecParameters
 	IsEncrypted                    bool
@@ -307,6 +322,7 @@ type DownTrack struct {
 
 	listenerLock            sync.RWMutex
 	receiverReportListeners []ReceiverReportListener
+	packetObserver          atomic.Pointer[PacketObserver]
 
 	bindLock            sync.Mutex
 	bindState           atomic.Value
@@ -342,6 +358,20 @@ type DownTrack struct {
 
 	playoutDelay *PlayoutDelayController
 
+	// audio only, chosen once bound when enabled
+	lossResilienceLock     sync.Mutex
+	lossResilienceConfig   audio.LossResilienceConfig
+	lossResilienceSelector *audio.LossResilienceSelector
+	lossResilient          atomic.Bool
+	lossResilienceMode     atomic.Int32
+
+	// audio only, RED subscribers are sent RED while they report losses when enabled
+	redGenerationLock   sync.Mutex
+	redGenerationConfig audio.REDGenerationConfig
+	redGate             *audio.REDGate
+	redGated            atomic.Bool
+	redActive           atomic.Bool
+
 	pacer pacer.Pacer
 
 	maxLayerNotifierChMu     sync.RWMutex
@@ -439,7 +469,7 @@ func NewDownTrack(params DownTrackParams) (*DownTrack, error) {
 	}
 
 	d.params.Receiver.AddOnReady(d.handleReceiverReady)
-	d.rtxSequenceNumber.Store(uint64(rand.Intn(1<<14)) + uint64(1<<15)) // a random number in third quartile of sequence number space
+	d.rtxSequenceNumber.Store(uint64(replay.Intn(1<<14)) + uint64(1<<15)) // a random number in third quartile of sequence number space
 	d.params.Logger.Debugw("downtrack created", "upstreamCodecs", d.upstreamCodecs)
 
 	return d, nil
@@ -551,6 +581,11 @@ func (d *DownTrack) Bind(t webrtc.TrackLocalContext) (webrtc.RTPCodecParameters,
 			isFECEnabled = strings.Contains(strings.ToLower(matchedUpstreamCodec.SDPFmtpLine), "fec")
 		}
 
+		if d.kind == webrtc.RTPCodecTypeAudio {
+			d.startLossResilience(isFECEnabled)
+			d.startREDGeneration()
+		}
+
 		logFields := []interface{}{
 			"codecs", d.upstreamCodecs,
 			"matchCodec", codec,
@@ -745,6 +780,15 @@ func (d *DownTrack) getStreamAllocatorListener() DownTrackStreamAllocatorListene
 	return d.streamAllocatorListener
 }
 
+// fanOutTier is the tier of the channel capacity estimated by the stream allocator, 0 without estimate
+func (d *DownTrack) fanOutTier(config FanOutConfig) int {
+	var channelCapacity int64
+	if sal := d.getStreamAllocatorListener(); sal != nil {
+		channelCapacity = sal.ChannelCapacity()
+	}
+	return config.Tier(channelCapacity)
+}
+
 func (d *DownTrack) SetProbeClusterId(probeClusterId ccutils.ProbeClusterId) {
 	d.probeClusterId.Store(uint32(probeClusterId))
 }
@@ -985,6 +1029,15 @@ func (d *DownTrack) maxLayerNotifierWorker() {
 
 // WriteRTP writes an RTP Packet to the DownTrack
 func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
+	return d.writeRTP(extPkt, layer, nil)
+}
+
+// writeRTPFanOut writes like WriteRTP, with the payload shared with other subscribers of the same tier
+func (d *DownTrack) writeRTPFanOut(extPkt *buffer.ExtPacket, layer int32, fanOut *fanOutPacket) error {
+	return d.writeRTP(extPkt, layer, fanOut)
+}
+
+func (d *DownTrack) writeRTP(extPkt *buffer.ExtPacket, layer int32, fanOut *fanOutPacket) error {
 	if !d.writable.Load() {
 		return nil
 	}
@@ -997,26 +1050,49 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		return err
 	}
 
-	poolEntity := PacketFactory.Get().(*[]byte)
-	payload := *poolEntity
-	copy(payload, tp.codecBytes)
-	n := copy(payload[len(tp.codecBytes):], extPkt.Packet.Payload[tp.incomingHeaderSize:])
-	if n != len(extPkt.Packet.Payload[tp.incomingHeaderSize:]) {
-		d.params.Logger.Errorw(
-			"payload overflow", nil,
-			"want", len(extPkt.Packet.Payload[tp.incomingHeaderSize:]),
-			"have", n,
-		)
-		PacketFactory.Put(poolEntity)
-		return ErrPayloadOverflow
+	incomingPayload := extPkt.Packet.Payload[tp.incomingHeaderSize:]
+	payloadType := d.getTranslatedPayloadType(extPkt.Packet.PayloadType)
+	if primary, ok := d.stripRedundancy(extPkt.Packet.PayloadType, incomingPayload); ok {
+		// the primary encoding differs from what other subscribers get, not shared
+		incomingPayload, payloadType, fanOut = primary, d.primaryPT, nil
+	}
+
+	var (
+		poolEntity *[]byte
+		payload    []byte
+		shared     *sharedPayload
+	)
+	if fanOut != nil {
+		payload, shared, err = fanOut.payload(d.fanOutTier(fanOut.config), tp.codecBytes, incomingPayload)
+		if err != nil {
+			d.params.Logger.Errorw(
+				"payload overflow", err,
+				"want", len(tp.codecBytes)+len(incomingPayload),
+			)
+			return err
+		}
+	} else {
+		poolEntity = PacketFactory.Get().(*[]byte)
+		payload = *poolEntity
+		copy(payload, tp.codecBytes)
+		n := copy(payload[len(tp.codecBytes):], incomingPayload)
+		if n != len(incomingPayload) {
+			d.params.Logger.Errorw(
+				"payload overflow", nil,
+				"want", len(incomingPayload),
+				"have", n,
+			)
+			PacketFactory.Put(poolEntity)
+			return ErrPayloadOverflow
+		}
+		payload = payload[:len(tp.codecBytes)+n]
 	}
-	payload = payload[:len(tp.codecBytes)+n]
 
 	// translate RTP header
 	hdr := &rtp.Header{
 		Version:        extPkt.Packet.Version,
 		Padding:        extPkt.Packet.Padding,
-		PayloadType:    d.getTranslatedPayloadType(extPkt.Packet.PayloadType),
+		PayloadType:    payloadType,
 		SequenceNumber: uint16(tp.rtp.extSequenceNumber),
 		Timestamp:      uint32(tp.rtp.extTimestamp),
 		SSRC:           d.ssrc,
@@ -1083,6 +1159,10 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		)
 	}
 
+	if observer := d.packetObserver.Load(); observer != nil {
+		(*observer)(hdr, payload)
+	}
+
 	headerSize := hdr.MarshalSize()
 	d.rtpStats.Update(
 		extPkt.Arrival,
@@ -1094,7 +1174,7 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		0,
 		extPkt.IsOutOfOrder,
 	)
-	d.pacer.Enqueue(&pacer.Packet{
+	pkt := &pacer.Packet{
 		Header:             hdr,
 		HeaderSize:         headerSize,
 		Payload:            payload,
@@ -1102,9 +1182,14 @@ func (d *DownTrack) WriteRTP(extPkt *buffer.ExtPacket, layer int32) error {
 		AbsSendTimeExtID:   uint8(d.absSendTimeExtID),
 		TransportWideExtID: uint8(d.transportWideExtID),
 		WriteStream:        d.writeStream,
-		Pool:               PacketFactory,
-		PoolEntity:         poolEntity,
-	})
+	}
+	if shared != nil {
+		pkt.PayloadRef = shared
+	} else {
+		pkt.Pool = PacketFactory
+		pkt.PoolEntity = poolEntity
+	}
+	d.pacer.Enqueue(pkt)
 
 	if extPkt.KeyFrame {
 		d.isNACKThrottled.Store(false)
@@ -1537,6 +1622,15 @@ func (d *DownTrack) AddReceiverReportListener(listener ReceiverReportListener) {
 	d.receiverReportListeners = append(d.receiverReportListeners, listener)
 }
 
+// SetPacketObserver sets the observer of forwarded packets, nil removes it
+func (d *DownTrack) SetPacketObserver(observer PacketObserver) {
+	if observer == nil {
+		d.packetObserver.Store(nil)
+		return
+	}
+	d.packetObserver.Store(&observer)
+}
+
 func (d *DownTrack) IsDeficient() bool {
 	return d.forwarder.IsDeficient()
 }
@@ -1902,6 +1996,8 @@ func (d *DownTrack) handleRTCP(bytes []byte) {
 				if isRttChanged {
 					rttToReport = rtt
 				}
+				d.observeLossResilienceReport(rtt, r.FractionLost)
+				d.observeREDGenerationReport(r.FractionLost)
 
 				if d.playoutDelay != nil {
 					d.playoutDelay.OnSeqAcked(uint16(r.LastSequenceNumber))
@@ -1937,7 +2033,9 @@ func (d *DownTrack) handleRTCP(bytes []byte) {
 					numNACKs += uint32(len(packetList))
 					nacks = append(nacks, packetList...)
 				}
-				go d.retransmitPackets(nacks)
+				if d.observeLossResilienceNACKs(nacks) {
+					go d.retransmitPackets(nacks)
+				}
 			}
 
 		case *rtcp.TransportLayerCC:
@@ -2317,6 +2415,148 @@ func (d *DownTrack) getTranslatedPayloadType(srcPT uint8) uint8 {
 	return uint8(d.payloadType.Load())
 }
 
+// SetLossResilience enables choosing between retransmission and forward error correction for an audio
+// subscriber. Takes effect on bind, once it is known what the subscriber negotiated.
+func (d *DownTrack) SetLossResilience(config audio.LossResilienceConfig) {
+	d.lossResilienceLock.Lock()
+	defer d.lossResilienceLock.Unlock()
+
+	d.lossResilienceConfig = config
+}
+
+// GetLossResilienceMode returns the mode chosen for the subscriber, false when not choosing
+func (d *DownTrack) GetLossResilienceMode() (audio.LossResilienceMode, bool) {
+	if !d.lossResilient.Load() {
+		return audio.LossResilienceModeNACK, false
+	}
+	return audio.LossResilienceMode(d.lossResilienceMode.Load()), true
+}
+
+func (d *DownTrack) startLossResilience(fecAvailable bool) {
+	d.lossResilienceLock.Lock()
+	defer d.lossResilienceLock.Unlock()
+
+	// nothing to choose from without FEC or RED
+	if !d.lossResilienceConfig.Enabled || (!fecAvailable && !d.isRED) || d.lossResilienceSelector != nil {
+		return
+	}
+
+	d.lossResilienceSelector = audio.NewLossResilienceSelector(d.lossResilienceConfig, fecAvailable, d.isRED, time.Now())
+	d.lossResilienceMode.Store(int32(d.lossResilienceSelector.Mode()))
+	d.lossResilient.Store(true)
+}
+
+func (d *DownTrack) observeLossResilienceReport(rtt uint32, fractionLost uint8) {
+	d.lossResilienceLock.Lock()
+	if d.lossResilienceSelector == nil {
+		d.lossResilienceLock.Unlock()
+		return
+	}
+	d.lossResilienceSelector.ObserveReceiverReport(rtt, fractionLost)
+	mode, changed := d.lossResilienceSelector.Evaluate(time.Now())
+	stats := d.lossResilienceSelector.Stats()
+	d.lossResilienceLock.Unlock()
+
+	if !changed {
+		return
+	}
+	d.lossResilienceMode.Store(int32(mode))
+	d.params.Logger.Infow(
+		"loss resilience mode changed",
+		"mode", mode,
+		"rtt", stats.RTT,
+		"loss", stats.Loss,
+		"burst", stats.Burst,
+	)
+	prometheus.RecordLossResilienceSwitch(mode.String())
+}
+
+// observeLossResilienceNACKs returns whether the NACKed packets should be retransmitted,
+// they would arrive too late to be played out when forward error correction was chosen
+func (d *DownTrack) observeLossResilienceNACKs(nacks []uint16) bool {
+	if !d.lossResilient.Load() {
+		return true
+	}
+
+	d.lossResilienceLock.Lock()
+	d.lossResilienceSelector.ObserveNACKs(nacks)
+	d.lossResilienceLock.Unlock()
+
+	mode, _ := d.GetLossResilienceMode()
+	return mode == audio.LossResilienceModeNACK
+}
+
+// SetREDGeneration enables sending RED to an audio subscriber only while it reports losses.
+// Takes effect on bind, once it is known whether the subscriber negotiated RED.
+func (d *DownTrack) SetREDGeneration(config audio.REDGenerationConfig) {
+	d.redGenerationLock.Lock()
+	defer d.redGenerationLock.Unlock()
+
+	d.redGenerationConfig = config
+}
+
+// WantsRED returns whether the subscriber is sent RED rather than its primary encoding.
+// The loss resilience mode takes precedence over RED generation when both are enabled.
+func (d *DownTrack) WantsRED() bool {
+	if !d.isRED {
+		return false
+	}
+	if mode, ok := d.GetLossResilienceMode(); ok {
+		return mode == audio.LossResilienceModeRED
+	}
+	if d.redGated.Load() {
+		return d.redActive.Load()
+	}
+	return true
+}
+
+func (d *DownTrack) startREDGeneration() {
+	d.redGenerationLock.Lock()
+	defer d.redGenerationLock.Unlock()
+
+	if !d.redGenerationConfig.Enabled || !d.isRED || d.redGate != nil {
+		return
+	}
+
+	d.redGate = audio.NewREDGate(d.redGenerationConfig, time.Now())
+	d.redGated.Store(true)
+}
+
+func (d *DownTrack) observeREDGenerationReport(fractionLost uint8) {
+	d.redGenerationLock.Lock()
+	if d.redGate == nil {
+		d.redGenerationLock.Unlock()
+		return
+	}
+	d.redGate.ObserveReceiverReport(fractionLost)
+	active, changed := d.redGate.Evaluate(time.Now())
+	loss := d.redGate.Loss()
+	d.redGenerationLock.Unlock()
+
+	if !changed {
+		return
+	}
+	d.redActive.Store(active)
+	d.params.Logger.Infow("red generation changed", "active", active, "loss", loss)
+	prometheus.RecordREDGenerationSwitch(active)
+}
+
+// stripRedundancy returns the primary encoding of a RED packet when the subscriber is not sent RED
+func (d *DownTrack) stripRedundancy(payloadType uint8, payload []byte) ([]byte, bool) {
+	if !d.isRED || d.primaryPT == 0 || payloadType == d.upstreamPrimaryPT {
+		return nil, false
+	}
+	if d.WantsRED() {
+		return nil, false
+	}
+
+	primary, err := extractPrimaryEncodingForRED(payload)
+	if err != nil {
+		return nil, false
+	}
+	return primary, true
+}
+
 func (d *DownTrack) DebugInfo() map[string]interface{} {
 	stats := map[string]interface{}{
 		"LastPli": d.rtpStats.LastPli(),
@@ -2330,6 +2570,28 @@ func (d *DownTrack) DebugInfo() map[string]interface{} {
 		stats["PacketCount"] = senderReport.PacketCount
 	}
 
+	d.lossResilienceLock.Lock()
+	if d.lossResilienceSelector != nil {
+		lrStats := d.lossResilienceSelector.Stats()
+		stats["LossResilience"] = map[string]interface{}{
+			"Mode":     lrStats.Mode.String(),
+			"RTT":      lrStats.RTT.String(),
+			"Loss":     lrStats.Loss,
+			"Burst":    lrStats.Burst,
+			"Switches": lrStats.Switches,
+		}
+	}
+	d.lossResilienceLock.Unlock()
+
+	d.redGenerationLock.Lock()
+	if d.redGate != nil {
+		stats["REDGeneration"] = map[string]interface{}{
+			"Active": d.redGate.Active(),
+			"Loss":   d.redGate.Loss(),
+		}
+	}
+	d.redGenerationLock.Unlock()
+
 	return map[string]interface{}{
 		"SubscriberID":        d.params.SubID,
 		"TrackID":             d.id,
@@ -0,0 +1,138 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"bytes"
+	"sync"
+
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+// FanOutConfig enables sharing the payload of forwarded packets between the subscribers of a track, meant
+// for rooms with thousands of subscribers. Subscribers for which forwarding produces the same payload from
+// an incoming packet, and whose estimated channel capacity falls into the same tier, get one immutable
+// copy of it written to their transports instead of a copy each. Headers stay per subscriber.
+type FanOutConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// number of subscribers of a track from which payloads are shared
+	MinSubscribers int `yaml:"min_subscribers,omitempty"`
+	// upper bounds of the channel capacity tiers in bps, subscribers without estimate are in the lowest tier
+	TierBoundariesBps []int64 `yaml:"tier_boundaries_bps,omitempty"`
+}
+
+var DefaultFanOutConfig = FanOutConfig{
+	MinSubscribers:    500,
+	TierBoundariesBps: []int64{500_000, 1_500_000, 4_000_000},
+}
+
+// Tier returns the index of the tier of channelCapacity
+func (c FanOutConfig) Tier(channelCapacity int64) int {
+	for i, boundary := range c.TierBoundariesBps {
+		if channelCapacity < boundary {
+			return i
+		}
+	}
+	return len(c.TierBoundariesBps)
+}
+
+// ------------------------------------------------
+
+// sharedPayload is a pooled payload buffer returned to the pool when its last reference is released
+type sharedPayload struct {
+	entity *[]byte
+	refs   atomic.Int32
+}
+
+func (s *sharedPayload) Release() {
+	if s.refs.Dec() == 0 {
+		PacketFactory.Put(s.entity)
+	}
+}
+
+type fanOutEntry struct {
+	tier          int
+	numCodecBytes int
+	payload       []byte
+	ref           *sharedPayload
+}
+
+// fanOutPacket holds the payloads of one incoming packet while it is broadcast to the down tracks
+type fanOutPacket struct {
+	config FanOutConfig
+
+	lock    sync.Mutex
+	entries []fanOutEntry
+}
+
+func newFanOutPacket(config FanOutConfig) *fanOutPacket {
+	return &fanOutPacket{
+		config: config,
+	}
+}
+
+// payload returns the payload made of codecBytes and body for a subscriber of tier, it is shared with
+// all subscribers asking for the same payload in the same tier. The returned reference has to be released.
+func (f *fanOutPacket) payload(tier int, codecBytes []byte, body []byte) ([]byte, *sharedPayload, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, e := range f.entries {
+		if e.tier == tier && e.numCodecBytes == len(codecBytes) && bytes.Equal(e.payload[:e.numCodecBytes], codecBytes) {
+			e.ref.refs.Inc()
+			return e.payload, e.ref, nil
+		}
+	}
+
+	entity := PacketFactory.Get().(*[]byte)
+	buf := *entity
+	copy(buf, codecBytes)
+	if n := copy(buf[len(codecBytes):], body); n != len(body) {
+		PacketFactory.Put(entity)
+		return nil, nil, ErrPayloadOverflow
+	}
+
+	ref := &sharedPayload{entity: entity}
+	// one reference is held by the fan out packet until the broadcast is done
+	ref.refs.Store(2)
+	e := fanOutEntry{
+		tier:          tier,
+		numCodecBytes: len(codecBytes),
+		payload:       buf[:len(codecBytes)+len(body)],
+		ref:           ref,
+	}
+	f.entries = append(f.entries, e)
+	return e.payload, ref, nil
+}
+
+// release drops the references of the broadcast, the fan out packet can be reused afterwards
+func (f *fanOutPacket) release() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	for _, e := range f.entries {
+		e.ref.Release()
+	}
+	clear(f.entries)
+	f.entries = f.entries[:0]
+}
+
+// fanOutWriter is implemented by track senders able to write shared payloads
+type fanOutWriter interface {
+	writeRTPFanOut(extPkt *buffer.ExtPacket, layer int32, fanOut *fanOutPacket) error
+}
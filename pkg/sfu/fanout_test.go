@@ -0,0 +1,68 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sfu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFanOutConfig_Tier(t *testing.T) {
+	config := FanOutConfig{TierBoundariesBps: []int64{500_000, 1_500_000}}
+	require.Equal(t, 0, config.Tier(0))
+	require.Equal(t, 0, config.Tier(499_999))
+	require.Equal(t, 1, config.Tier(500_000))
+	require.Equal(t, 2, config.Tier(10_000_000))
+}
+
+func TestFanOutPacket(t *testing.T) {
+	fanOut := newFanOutPacket(DefaultFanOutConfig)
+	body := []byte{1, 2, 3, 4}
+
+	p1, ref1, err := fanOut.payload(0, []byte{0xaa}, body)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xaa, 1, 2, 3, 4}, p1)
+
+	// same tier and codec bytes share the payload
+	p2, ref2, err := fanOut.payload(0, []byte{0xaa}, body)
+	require.NoError(t, err)
+	require.Same(t, ref1, ref2)
+	require.Equal(t, &p1[0], &p2[0])
+
+	// a different tier or different codec bytes get their own copy
+	_, ref3, err := fanOut.payload(1, []byte{0xaa}, body)
+	require.NoError(t, err)
+	require.NotSame(t, ref1, ref3)
+	p4, ref4, err := fanOut.payload(0, []byte{0xbb, 0xcc}, body)
+	require.NoError(t, err)
+	require.NotSame(t, ref1, ref4)
+	require.Equal(t, []byte{0xbb, 0xcc, 1, 2, 3, 4}, p4)
+
+	require.Equal(t, int32(3), ref1.refs.Load())
+	fanOut.release()
+	require.Equal(t, int32(2), ref1.refs.Load())
+	require.Empty(t, fanOut.entries)
+
+	ref1.Release()
+	ref2.Release()
+	require.Equal(t, int32(0), ref1.refs.Load())
+	ref3.Release()
+	ref4.Release()
+
+	_, _, err = fanOut.payload(0, nil, make([]byte, 2000))
+	require.ErrorIs(t, err, ErrPayloadOverflow)
+}
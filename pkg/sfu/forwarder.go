@@ -18,7 +18,6 @@ import (
 	"errors"
 	"fmt"
 	"math"
-	"math/rand"
 	"sync"
 	"time"
 
@@ -32,6 +31,7 @@ import (
 	"github.com/livekit/protocol/utils"
 	"github.com/livekit/protocol/utils/mono"
 
+	"github.com/livekit/livekit-server/pkg/replay"
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
 	"github.com/livekit/livekit-server/pkg/sfu/codecmunger"
 	"github.com/livekit/livekit-server/pkg/sfu/mime"
@@ -2164,8 +2164,8 @@ func (f *Forwarder) maybeStart() {
 	f.started = true
 	f.preStartTime = time.Now()
 
-	sequenceNumber := uint16(rand.Intn(1<<14)) + uint16(1<<15) // a random number in third quartile of sequence number space
-	timestamp := uint32(rand.Intn(1<<30)) + uint32(1<<31)      // a random number in third quartile of timestamp space
+	sequenceNumber := uint16(replay.Intn(1<<14)) + uint16(1<<15) // a random number in third quartile of sequence number space
+	timestamp := uint32(replay.Intn(1<<30)) + uint32(1<<31)      // a random number in third quartile of timestamp space
 	extPkt := &buffer.ExtPacket{
 		Packet: &rtp.Packet{
 			Header: rtp.Header{
@@ -0,0 +1,75 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"time"
+
+	"github.com/pion/interceptor"
+	"go.uber.org/atomic"
+)
+
+// ActivityMonitor records when RTP or RTCP was last received from the remote peer,
+// run as a receive stage of the tracks it publishes
+type ActivityMonitor struct {
+	lastActivity atomic.Int64
+}
+
+func NewActivityMonitor() *ActivityMonitor {
+	return &ActivityMonitor{}
+}
+
+// LastActivity returns the time anything was last received, zero if nothing was received yet
+func (m *ActivityMonitor) LastActivity() time.Time {
+	at := m.lastActivity.Load()
+	if at == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, at)
+}
+
+func (m *ActivityMonitor) touch() {
+	m.lastActivity.Store(time.Now().UnixNano())
+}
+
+func (m *ActivityMonitor) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &activityMonitorInterceptor{monitor: m}, nil
+}
+
+type activityMonitorInterceptor struct {
+	interceptor.NoOp
+	monitor *ActivityMonitor
+}
+
+func (i *activityMonitorInterceptor) BindRTCPReader(reader interceptor.RTCPReader) interceptor.RTCPReader {
+	return interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err == nil && n > 0 {
+			i.monitor.touch()
+		}
+		return n, a, err
+	})
+}
+
+func (i *activityMonitorInterceptor) BindRemoteStream(_ *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err == nil && n > 0 {
+			i.monitor.touch()
+		}
+		return n, a, err
+	})
+}
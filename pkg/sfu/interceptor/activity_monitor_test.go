@@ -0,0 +1,45 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pion/interceptor"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityMonitor(t *testing.T) {
+	m := NewActivityMonitor()
+	require.True(t, m.LastActivity().IsZero())
+
+	i, err := m.NewInterceptor("")
+	require.NoError(t, err)
+
+	failing := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return 0, a, errors.New("closed")
+	})
+	_, _, _ = i.BindRemoteStream(&interceptor.StreamInfo{}, failing).Read(make([]byte, 1500), nil)
+	require.True(t, m.LastActivity().IsZero())
+
+	rtcp := interceptor.RTCPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return 28, a, nil
+	})
+	_, _, err = i.BindRTCPReader(rtcp).Read(make([]byte, 1500), nil)
+	require.NoError(t, err)
+	require.False(t, m.LastActivity().IsZero())
+}
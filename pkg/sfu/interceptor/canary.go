@@ -0,0 +1,251 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/logger"
+)
+
+// CanaryStage is logic under rollout running in the canary slot of the receive stages.
+// It only observes packets, they are forwarded unchanged whatever the stage does.
+// The payload must not be modified or retained after ObserveRTP returns.
+type CanaryStage interface {
+	Name() string
+	ObserveRTP(info *interceptor.StreamInfo, header *rtp.Header, payload []byte) error
+}
+
+// CanaryConfig controls the rollout of the registered canary stage
+type CanaryConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// percentage of streams the canary stage runs on
+	RolloutPercent float64 `yaml:"rollout_percent,omitempty"`
+	// fraction of observed packets the stage may fail on before it is disabled
+	MaxErrorRate float64 `yaml:"max_error_rate,omitempty"`
+	// mean time the stage may spend on a packet before it is disabled
+	MaxLatency time.Duration `yaml:"max_latency,omitempty"`
+	// packets observed before the budgets are evaluated
+	EvaluationWindow uint64 `yaml:"evaluation_window,omitempty"`
+}
+
+var (
+	DefaultCanaryConfig = CanaryConfig{
+		RolloutPercent:   1,
+		MaxErrorRate:     0.001,
+		MaxLatency:       50 * time.Microsecond,
+		EvaluationWindow: 10000,
+	}
+)
+
+var (
+	canaryStageLock sync.RWMutex
+	canaryStage     CanaryStage
+)
+
+// RegisterCanaryStage installs the stage run in the canary slot, builds under validation register it from init.
+func RegisterCanaryStage(stage CanaryStage) {
+	canaryStageLock.Lock()
+	canaryStage = stage
+	canaryStageLock.Unlock()
+}
+
+func registeredCanaryStage() CanaryStage {
+	canaryStageLock.RLock()
+	defer canaryStageLock.RUnlock()
+
+	return canaryStage
+}
+
+type CanaryStatus struct {
+	Stage          string        `json:"stage"`
+	Active         bool          `json:"active"`
+	DisabledReason string        `json:"disabled_reason,omitempty"`
+	Packets        uint64        `json:"packets"`
+	Errors         uint64        `json:"errors"`
+	MeanLatency    time.Duration `json:"mean_latency_ns"`
+}
+
+// --------------------------------------------------------
+
+// Canary is the canary slot of the receive stages. It is a no-op unless a stage is registered,
+// which then runs on a percentage of streams and is disabled for good when it exceeds its error or latency budget.
+type Canary struct {
+	config CanaryConfig
+	stage  CanaryStage
+	logger logger.Logger
+
+	disabled atomic.Bool
+
+	lock           sync.Mutex
+	disabledReason string
+	packets        uint64
+	errors         uint64
+	latency        time.Duration
+	// totals of the current evaluation window
+	windowPackets uint64
+	windowErrors  uint64
+	windowLatency time.Duration
+}
+
+func NewCanary(config CanaryConfig, logger logger.Logger) *Canary {
+	if config.EvaluationWindow == 0 {
+		config.EvaluationWindow = DefaultCanaryConfig.EvaluationWindow
+	}
+	c := &Canary{
+		config: config,
+		stage:  registeredCanaryStage(),
+		logger: logger,
+	}
+	if c.stage == nil {
+		c.disabled.Store(true)
+	} else {
+		logger.Infow("canary stage registered", "stage", c.stage.Name(), "rolloutPercent", config.RolloutPercent)
+	}
+	return c
+}
+
+func (c *Canary) Status() CanaryStatus {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s := CanaryStatus{
+		Active:         !c.disabled.Load(),
+		DisabledReason: c.disabledReason,
+		Packets:        c.packets,
+		Errors:         c.errors,
+	}
+	if c.stage != nil {
+		s.Stage = c.stage.Name()
+	}
+	if c.packets != 0 {
+		s.MeanLatency = c.latency / time.Duration(c.packets)
+	}
+	return s
+}
+
+// inRollout places a stream in or out of the rollout, consistently for the same SSRC
+func (c *Canary) inRollout(ssrc uint32) bool {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], ssrc)
+	h := fnv.New32a()
+	_, _ = h.Write(b[:])
+	return float64(h.Sum32()%10000) < c.config.RolloutPercent*100
+}
+
+func (c *Canary) observe(info *interceptor.StreamInfo, b []byte) {
+	if c.disabled.Load() {
+		return
+	}
+
+	header := rtp.Header{}
+	headerSize, err := header.Unmarshal(b)
+	if err != nil {
+		return
+	}
+
+	start := time.Now()
+	err = c.runStage(info, &header, b[headerSize:])
+	c.record(time.Since(start), err)
+}
+
+func (c *Canary) runStage(info *interceptor.StreamInfo, header *rtp.Header, payload []byte) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("canary stage panicked: %v", r)
+			c.disable(err.Error())
+		}
+	}()
+
+	return c.stage.ObserveRTP(info, header, payload)
+}
+
+func (c *Canary) record(elapsed time.Duration, err error) {
+	c.lock.Lock()
+	c.packets++
+	c.latency += elapsed
+	c.windowPackets++
+	c.windowLatency += elapsed
+	if err != nil {
+		c.errors++
+		c.windowErrors++
+	}
+	if c.windowPackets < c.config.EvaluationWindow {
+		c.lock.Unlock()
+		return
+	}
+
+	var reason string
+	errorRate := float64(c.windowErrors) / float64(c.windowPackets)
+	meanLatency := c.windowLatency / time.Duration(c.windowPackets)
+	switch {
+	case errorRate > c.config.MaxErrorRate:
+		reason = fmt.Sprintf("error rate %.4f exceeds budget %.4f", errorRate, c.config.MaxErrorRate)
+	case c.config.MaxLatency > 0 && meanLatency > c.config.MaxLatency:
+		reason = fmt.Sprintf("mean latency %s exceeds budget %s", meanLatency, c.config.MaxLatency)
+	}
+	c.windowPackets, c.windowErrors, c.windowLatency = 0, 0, 0
+	c.lock.Unlock()
+
+	if reason != "" {
+		c.disable(reason)
+	}
+}
+
+func (c *Canary) disable(reason string) {
+	if c.disabled.Swap(true) {
+		return
+	}
+
+	c.lock.Lock()
+	c.disabledReason = reason
+	c.lock.Unlock()
+
+	c.logger.Warnw("canary stage disabled", nil, "stage", c.stage.Name(), "reason", reason)
+}
+
+// NewInterceptor implements interceptor.Factory
+func (c *Canary) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &canaryInterceptor{canary: c}, nil
+}
+
+type canaryInterceptor struct {
+	interceptor.NoOp
+
+	canary *Canary
+}
+
+func (i *canaryInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	if i.canary.disabled.Load() || !i.canary.inRollout(info.SSRC) {
+		return reader
+	}
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err == nil {
+			i.canary.observe(info, b[:n])
+		}
+		return n, a, err
+	})
+}
@@ -0,0 +1,150 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+type testCanaryStage struct {
+	err     error
+	delay   time.Duration
+	packets int
+}
+
+func (s *testCanaryStage) Name() string { return "test" }
+
+func (s *testCanaryStage) ObserveRTP(_ *interceptor.StreamInfo, _ *rtp.Header, _ []byte) error {
+	s.packets++
+	time.Sleep(s.delay)
+	return s.err
+}
+
+func readCanary(t *testing.T, c *Canary, packets int) {
+	i, err := c.NewInterceptor("")
+	require.NoError(t, err)
+
+	pkt, err := (&rtp.Packet{Header: rtp.Header{Version: 2, SSRC: 1}, Payload: []byte{1, 2, 3}}).Marshal()
+	require.NoError(t, err)
+	reader := i.BindRemoteStream(&interceptor.StreamInfo{SSRC: 1}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, pkt), a, nil
+	}))
+	for j := 0; j < packets; j++ {
+		n, _, err := reader.Read(make([]byte, 1500), nil)
+		require.NoError(t, err)
+		require.Equal(t, len(pkt), n)
+	}
+}
+
+func TestCanary(t *testing.T) {
+	t.Cleanup(func() { RegisterCanaryStage(nil) })
+
+	t.Run("no stage", func(t *testing.T) {
+		c := NewCanary(DefaultCanaryConfig, logger.GetLogger())
+		readCanary(t, c, 10)
+		require.False(t, c.Status().Active)
+	})
+
+	t.Run("rollout", func(t *testing.T) {
+		stage := &testCanaryStage{}
+		RegisterCanaryStage(stage)
+
+		c := NewCanary(CanaryConfig{RolloutPercent: 0, EvaluationWindow: 10}, logger.GetLogger())
+		readCanary(t, c, 10)
+		require.Zero(t, stage.packets)
+
+		c = NewCanary(CanaryConfig{RolloutPercent: 100, MaxErrorRate: 0.5, EvaluationWindow: 10}, logger.GetLogger())
+		readCanary(t, c, 20)
+		require.Equal(t, 20, stage.packets)
+		require.True(t, c.Status().Active)
+	})
+
+	t.Run("error budget", func(t *testing.T) {
+		stage := &testCanaryStage{err: errors.New("failed")}
+		RegisterCanaryStage(stage)
+
+		c := NewCanary(CanaryConfig{RolloutPercent: 100, MaxErrorRate: 0.1, EvaluationWindow: 10}, logger.GetLogger())
+		readCanary(t, c, 20)
+		require.Equal(t, 10, stage.packets)
+
+		status := c.Status()
+		require.False(t, status.Active)
+		require.Contains(t, status.DisabledReason, "error rate")
+		require.Equal(t, uint64(10), status.Errors)
+	})
+
+	t.Run("latency budget", func(t *testing.T) {
+		stage := &testCanaryStage{delay: time.Millisecond}
+		RegisterCanaryStage(stage)
+
+		c := NewCanary(CanaryConfig{RolloutPercent: 100, MaxErrorRate: 1, MaxLatency: 100 * time.Microsecond, EvaluationWindow: 5}, logger.GetLogger())
+		readCanary(t, c, 10)
+		require.Equal(t, 5, stage.packets)
+		require.Contains(t, c.Status().DisabledReason, "latency")
+	})
+}
+
+func TestCanary_BufferStages(t *testing.T) {
+	t.Cleanup(func() { RegisterCanaryStage(nil) })
+
+	stage := &testCanaryStage{}
+	RegisterCanaryStage(stage)
+	c := NewCanary(CanaryConfig{RolloutPercent: 100, MaxErrorRate: 0.5, EvaluationWindow: 10}, logger.GetLogger())
+	i, err := c.NewInterceptor("")
+	require.NoError(t, err)
+
+	pcmu := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "audio/PCMU", ClockRate: 8000},
+		PayloadType:        0,
+	}
+	buff := buffer.NewBuffer(1, 100, 100)
+	buff.SetStages(i, &interceptor.StreamInfo{SSRC: 1, PayloadType: 0, MimeType: pcmu.MimeType, ClockRate: pcmu.ClockRate})
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{pcmu},
+	}, pcmu.RTPCodecCapability, 0))
+	defer buff.Close()
+
+	// every packet forwarded by the buffer is observed, unchanged
+	buf := make([]byte, 1500)
+	for sn := uint16(1); sn <= 20; sn++ {
+		payload := []byte{1, 2, 3}
+		raw, err := (&rtp.Packet{Header: rtp.Header{Version: 2, SequenceNumber: sn, Timestamp: uint32(sn) * 160, SSRC: 1}, Payload: payload}).Marshal()
+		require.NoError(t, err)
+		_, err = buff.Write(raw)
+		require.NoError(t, err)
+
+		ep, err := buff.ReadExtended(buf)
+		require.NoError(t, err)
+		require.Equal(t, sn, ep.Packet.SequenceNumber)
+		require.Equal(t, payload, ep.Packet.Payload)
+	}
+	require.Equal(t, 20, stage.packets)
+
+	status := c.Status()
+	require.True(t, status.Active)
+	require.Equal(t, uint64(20), status.Packets)
+}
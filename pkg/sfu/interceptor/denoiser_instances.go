@@ -0,0 +1,199 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"sync"
+
+	"github.com/zhangzhao-gg/go-rnnoise/rnnoise"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+var (
+	denoiserInstancesOnce   sync.Once
+	sharedDenoiserInstances atomic.Pointer[denoiserInstances]
+)
+
+// DenoiserInstancesStats describes the node wide pool of initialized denoisers
+type DenoiserInstancesStats struct {
+	Idle int `json:"idle"`
+	// instances checked out from the pool, and created on demand as the pool was empty
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+}
+
+// GetDenoiserInstancesStats returns the state of the instance pool, zero if streams create their own denoisers
+func GetDenoiserInstancesStats() DenoiserInstancesStats {
+	p := sharedDenoiserInstances.Load()
+	if p == nil {
+		return DenoiserInstancesStats{}
+	}
+	return p.stats()
+}
+
+// PrewarmDenoisers creates the node wide pool of denoisers ahead of the first stream, if it is enabled
+func PrewarmDenoisers(config audio.DenoiserInstancesConfig) {
+	getDenoiserInstances(config)
+}
+
+// getDenoiserInstances returns the node wide pool, nil when disabled. It is created with the configuration of the first caller.
+func getDenoiserInstances(config audio.DenoiserInstancesConfig) *denoiserInstances {
+	if !config.Enabled || config.Size <= 0 {
+		return nil
+	}
+	denoiserInstancesOnce.Do(func() {
+		sharedDenoiserInstances.Store(newDenoiserInstances(config.Size, newRNNoiseDenoiser))
+	})
+	return sharedDenoiserInstances.Load()
+}
+
+func newRNNoiseDenoiser() (*rnnoise.NoiseFilter, error) {
+	return rnnoise.NewNoiseFilter("")
+}
+
+// ------------------------------------------------
+
+// denoiserInstances keeps initialized denoisers ready for streams to check out. A background worker
+// replenishes the pool and clears the history of returned instances before they are handed out again.
+type denoiserInstances struct {
+	size   int
+	create func() (*rnnoise.NoiseFilter, error)
+
+	lock sync.Mutex
+	idle []*rnnoise.NoiseFilter
+
+	returned chan *rnnoise.NoiseFilter
+	refill   chan struct{}
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func newDenoiserInstances(size int, create func() (*rnnoise.NoiseFilter, error)) *denoiserInstances {
+	p := &denoiserInstances{
+		size:     size,
+		create:   create,
+		idle:     make([]*rnnoise.NoiseFilter, 0, size),
+		returned: make(chan *rnnoise.NoiseFilter, size),
+		refill:   make(chan struct{}, 1),
+	}
+	go p.worker()
+	p.signalRefill()
+	return p
+}
+
+// get checks out a denoiser, creating one if the pool is empty. A nil pool always creates one.
+func (p *denoiserInstances) get() (*rnnoise.NoiseFilter, error) {
+	if p == nil {
+		return newRNNoiseDenoiser()
+	}
+
+	p.lock.Lock()
+	if n := len(p.idle); n > 0 {
+		d := p.idle[n-1]
+		p.idle[n-1] = nil
+		p.idle = p.idle[:n-1]
+		p.lock.Unlock()
+
+		p.hits.Inc()
+		p.signalRefill()
+		return d, nil
+	}
+	p.lock.Unlock()
+
+	p.misses.Inc()
+	p.signalRefill()
+	return p.create()
+}
+
+// put returns a denoiser the stream no longer uses, it is dropped if the pool is full
+func (p *denoiserInstances) put(d *rnnoise.NoiseFilter) {
+	if p == nil || d == nil {
+		return
+	}
+
+	select {
+	case p.returned <- d:
+	default:
+	}
+}
+
+func (p *denoiserInstances) signalRefill() {
+	select {
+	case p.refill <- struct{}{}:
+	default:
+	}
+}
+
+// add makes a denoiser available, returns false if the pool is full
+func (p *denoiserInstances) add(d *rnnoise.NoiseFilter) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if len(p.idle) >= p.size {
+		return false
+	}
+	p.idle = append(p.idle, d)
+	return true
+}
+
+func (p *denoiserInstances) worker() {
+	silence := make([]float32, rnnoiseFrameSize)
+	for {
+		select {
+		case d := <-p.returned:
+			// feed silence until the audio of the previous stream is gone from the history
+			for i := 0; i < rnnoiseHistoryFrames; i++ {
+				clear(silence)
+				_, _, _, _ = d.FilterStream(silence, 0)
+			}
+			p.add(d)
+
+		case <-p.refill:
+			for {
+				p.lock.Lock()
+				full := len(p.idle) >= p.size
+				p.lock.Unlock()
+				if full {
+					break
+				}
+
+				d, err := p.create()
+				if err != nil {
+					// streams create their own until the next check out retries
+					break
+				}
+				if !p.add(d) {
+					break
+				}
+			}
+		}
+	}
+}
+
+func (p *denoiserInstances) stats() DenoiserInstancesStats {
+	p.lock.Lock()
+	idle := len(p.idle)
+	p.lock.Unlock()
+
+	return DenoiserInstancesStats{
+		Idle:   idle,
+		Hits:   p.hits.Load(),
+		Misses: p.misses.Load(),
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/zhangzhao-gg/go-rnnoise/rnnoise"
+	"go.uber.org/atomic"
+)
+
+func skipWithoutRNNoise(t *testing.T) {
+	if _, err := newRNNoiseDenoiser(); err != nil {
+		t.Skip("rnnoise unavailable")
+	}
+}
+
+func TestDenoiserInstances(t *testing.T) {
+	skipWithoutRNNoise(t)
+	pool := newDenoiserInstances(2, newRNNoiseDenoiser)
+	require.Eventually(t, func() bool { return pool.stats().Idle == 2 }, time.Second, time.Millisecond)
+
+	first, err := pool.get()
+	require.NoError(t, err)
+	second, err := pool.get()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), pool.stats().Hits)
+
+	// checked out instances are replaced in the background
+	require.Eventually(t, func() bool { return pool.stats().Idle == 2 }, time.Second, time.Millisecond)
+
+	// returned instances beyond the size of the pool are dropped
+	pool.put(first)
+	pool.put(second)
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, 2, pool.stats().Idle)
+}
+
+func TestDenoiserInstances_Empty(t *testing.T) {
+	skipWithoutRNNoise(t)
+	var created atomic.Int32
+	pool := newDenoiserInstances(1, func() (*rnnoise.NoiseFilter, error) {
+		if created.Inc() == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return newRNNoiseDenoiser()
+	})
+	time.Sleep(50 * time.Millisecond)
+	require.Zero(t, pool.stats().Idle)
+
+	// an empty pool creates the instance on demand
+	d, err := pool.get()
+	require.NoError(t, err)
+	require.NotNil(t, d)
+	require.Equal(t, uint64(1), pool.stats().Misses)
+}
+
+func TestDenoiserInstances_Nil(t *testing.T) {
+	skipWithoutRNNoise(t)
+	var pool *denoiserInstances
+	d, err := pool.get()
+	require.NoError(t, err)
+	pool.put(d)
+}
@@ -0,0 +1,170 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/pion/interceptor"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+// large enough for any packet pion reads with its default receive MTU
+const denoiseJobBufferSize = 1500
+
+var (
+	denoiserPoolOnce   sync.Once
+	sharedDenoiserPool atomic.Pointer[denoiserPool]
+)
+
+// DenoiserPoolStats describes the node wide denoiser worker pool
+type DenoiserPoolStats struct {
+	Workers int   `json:"workers"`
+	Pending int64 `json:"pending"`
+	// packets passed through unfiltered as the pool was saturated
+	PassedThrough uint64 `json:"passed_through"`
+}
+
+// GetDenoiserPoolStats returns the state of the worker pool, zero if denoising runs on the read path
+func GetDenoiserPoolStats() DenoiserPoolStats {
+	p := sharedDenoiserPool.Load()
+	if p == nil {
+		return DenoiserPoolStats{}
+	}
+	return p.stats()
+}
+
+// getDenoiserPool returns the node wide pool, it is created with the configuration of the first caller
+func getDenoiserPool(config audio.DenoiserWorkersConfig) *denoiserPool {
+	denoiserPoolOnce.Do(func() {
+		sharedDenoiserPool.Store(newDenoiserPool(config))
+	})
+	return sharedDenoiserPool.Load()
+}
+
+// ------------------------------------------------
+
+// denoiseJob is a packet read from a stream, processed by a worker and then returned to the stream's reader
+type denoiseJob struct {
+	buf   []byte
+	n     int
+	attrs interceptor.Attributes
+	err   error
+	done  chan struct{}
+}
+
+var denoiseJobPool = sync.Pool{
+	New: func() interface{} {
+		return &denoiseJob{
+			buf:  make([]byte, denoiseJobBufferSize),
+			done: make(chan struct{}, 1),
+		}
+	},
+}
+
+// denoiseStream is the ordered queue of a stream in the pool, at most one worker processes a stream at a time
+type denoiseStream struct {
+	process func(job *denoiseJob)
+
+	lock      sync.Mutex
+	pending   []*denoiseJob
+	scheduled bool
+}
+
+// denoiserPool runs the processing of streams on a fixed number of goroutines
+type denoiserPool struct {
+	config  audio.DenoiserWorkersConfig
+	workers int
+	// streams with pending jobs, each stream is queued at most once
+	work chan *denoiseStream
+
+	pending       atomic.Int64
+	passedThrough atomic.Uint64
+}
+
+func newDenoiserPool(config audio.DenoiserWorkersConfig) *denoiserPool {
+	if config.MaxPending <= 0 {
+		config.MaxPending = audio.DefaultDenoiserWorkersConfig.MaxPending
+	}
+	workers := config.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	p := &denoiserPool{
+		config:  config,
+		workers: workers,
+		// a queued stream has at least one pending job, so this never blocks
+		work: make(chan *denoiseStream, config.MaxPending),
+	}
+	for i := 0; i < workers; i++ {
+		go p.worker()
+	}
+	return p
+}
+
+// submit queues job for processing by stream. Returns false if the pool is saturated,
+// the job is not queued then and has to pass through.
+func (p *denoiserPool) submit(stream *denoiseStream, job *denoiseJob) bool {
+	if p.pending.Inc() > int64(p.config.MaxPending) {
+		p.pending.Dec()
+		p.passedThrough.Inc()
+		return false
+	}
+
+	stream.lock.Lock()
+	stream.pending = append(stream.pending, job)
+	schedule := !stream.scheduled
+	stream.scheduled = true
+	stream.lock.Unlock()
+
+	if schedule {
+		p.work <- stream
+	}
+	return true
+}
+
+func (p *denoiserPool) worker() {
+	for stream := range p.work {
+		for {
+			stream.lock.Lock()
+			if len(stream.pending) == 0 {
+				stream.scheduled = false
+				stream.lock.Unlock()
+				break
+			}
+			job := stream.pending[0]
+			stream.pending[0] = nil
+			stream.pending = stream.pending[1:]
+			stream.lock.Unlock()
+
+			stream.process(job)
+			p.pending.Dec()
+			job.done <- struct{}{}
+		}
+	}
+}
+
+func (p *denoiserPool) stats() DenoiserPoolStats {
+	return DenoiserPoolStats{
+		Workers:       p.workers,
+		Pending:       p.pending.Load(),
+		PassedThrough: p.passedThrough.Load(),
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+func newTestDenoiseJob(n int) *denoiseJob {
+	return &denoiseJob{
+		n:    n,
+		done: make(chan struct{}, 1),
+	}
+}
+
+func TestDenoiserPool_Ordered(t *testing.T) {
+	pool := newDenoiserPool(audio.DenoiserWorkersConfig{Workers: 4, MaxPending: 100})
+
+	var processed []int
+	stream := &denoiseStream{
+		process: func(job *denoiseJob) {
+			processed = append(processed, job.n)
+			time.Sleep(100 * time.Microsecond)
+		},
+	}
+
+	jobs := make([]*denoiseJob, 50)
+	for i := range jobs {
+		jobs[i] = newTestDenoiseJob(i)
+		require.True(t, pool.submit(stream, jobs[i]))
+	}
+	for _, job := range jobs {
+		<-job.done
+	}
+
+	require.Len(t, processed, len(jobs))
+	for i, n := range processed {
+		require.Equal(t, i, n)
+	}
+	require.Zero(t, pool.stats().Pending)
+}
+
+func TestDenoiserPool_Saturated(t *testing.T) {
+	pool := newDenoiserPool(audio.DenoiserWorkersConfig{Workers: 1, MaxPending: 1})
+
+	unblock := make(chan struct{})
+	stream := &denoiseStream{
+		process: func(job *denoiseJob) {
+			<-unblock
+		},
+	}
+
+	first := newTestDenoiseJob(0)
+	require.True(t, pool.submit(stream, first))
+	// the pool is full, the packet has to pass through
+	require.False(t, pool.submit(stream, newTestDenoiseJob(1)))
+	require.Equal(t, uint64(1), pool.stats().PassedThrough)
+
+	close(unblock)
+	<-first.done
+	require.True(t, pool.submit(stream, newTestDenoiseJob(2)))
+}
@@ -0,0 +1,333 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+const (
+	// stage name of the origin probe, marks the packet leaving SRTP decryption for its buffer
+	FlightRecorderOriginStage = "srtp"
+
+	maxClosedFlightRecorderTracks = 32
+)
+
+// FlightRecorderConfig configures sampled per-packet timing through the receive stages of the buffers
+type FlightRecorderConfig struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// trace one in SampleRate packets of each track
+	SampleRate uint32 `yaml:"sample_rate,omitempty"`
+	// number of packet traces retained per track
+	Capacity int `yaml:"capacity,omitempty"`
+}
+
+var (
+	DefaultFlightRecorderConfig = FlightRecorderConfig{
+		Enabled:    false,
+		SampleRate: 100,
+		Capacity:   256,
+	}
+)
+
+// StageTiming is the time a packet left a receive stage, relative to the origin probe
+type StageTiming struct {
+	Stage   string        `json:"stage"`
+	Elapsed time.Duration `json:"elapsed_ns"`
+}
+
+type PacketTrace struct {
+	SequenceNumber uint16        `json:"sequence_number"`
+	Timestamp      uint32        `json:"timestamp"`
+	PayloadSize    int           `json:"payload_size"`
+	ReceivedAt     time.Time     `json:"received_at"`
+	Stages         []StageTiming `json:"stages"`
+}
+
+type TrackTrace struct {
+	SSRC     uint32        `json:"ssrc"`
+	MimeType string        `json:"mime_type"`
+	Closed   bool          `json:"closed"`
+	Packets  []PacketTrace `json:"packets"`
+}
+
+type flightTraceKey struct{}
+
+// --------------------------------------------------------
+
+type flightRecorderTrack struct {
+	ssrc     uint32
+	mimeType string
+
+	lock    sync.Mutex
+	count   uint32
+	traces  []*PacketTrace
+	next    int
+	wrapped bool
+}
+
+func (t *flightRecorderTrack) shouldSample(sampleRate uint32) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.count++
+	return t.count%sampleRate == 1 || sampleRate == 1
+}
+
+func (t *flightRecorderTrack) add(trace *PacketTrace) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.traces[t.next] = trace
+	t.next++
+	if t.next == len(t.traces) {
+		t.next = 0
+		t.wrapped = true
+	}
+}
+
+func (t *flightRecorderTrack) mark(trace *PacketTrace, stage string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	trace.Stages = append(trace.Stages, StageTiming{Stage: stage, Elapsed: time.Since(trace.ReceivedAt)})
+}
+
+func (t *flightRecorderTrack) snapshot(closed bool) TrackTrace {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tt := TrackTrace{
+		SSRC:     t.ssrc,
+		MimeType: t.mimeType,
+		Closed:   closed,
+	}
+	ordered := t.traces[:t.next]
+	if t.wrapped {
+		ordered = append(append([]*PacketTrace{}, t.traces[t.next:]...), t.traces[:t.next]...)
+	}
+	for _, trace := range ordered {
+		pt := *trace
+		pt.Stages = append([]StageTiming{}, trace.Stages...)
+		tt.Packets = append(tt.Packets, pt)
+	}
+	return tt
+}
+
+// --------------------------------------------------------
+
+// FlightRecorder samples packets of every remote track and records when they leave each
+// probed receive stage into a per-track ring buffer.
+// An origin probe has to be added before the probed stages, stage probes after each of them.
+type FlightRecorder struct {
+	config FlightRecorderConfig
+	logger logger.Logger
+
+	lock   sync.RWMutex
+	tracks map[uint32]*flightRecorderTrack
+	closed []*flightRecorderTrack
+}
+
+func NewFlightRecorder(config FlightRecorderConfig, logger logger.Logger) *FlightRecorder {
+	if config.SampleRate == 0 {
+		config.SampleRate = DefaultFlightRecorderConfig.SampleRate
+	}
+	if config.Capacity <= 0 {
+		config.Capacity = DefaultFlightRecorderConfig.Capacity
+	}
+	return &FlightRecorder{
+		config: config,
+		logger: logger,
+		tracks: make(map[uint32]*flightRecorderTrack),
+	}
+}
+
+// OriginProbe returns the factory of the probe starting packet traces
+func (f *FlightRecorder) OriginProbe() interceptor.Factory {
+	return &flightRecorderProbeFactory{recorder: f, stage: FlightRecorderOriginStage, origin: true}
+}
+
+// StageProbe returns the factory of a probe marking the end of the given stage
+func (f *FlightRecorder) StageProbe(stage string) interceptor.Factory {
+	return &flightRecorderProbeFactory{recorder: f, stage: stage}
+}
+
+// Dump returns the recorded traces of all live and recently closed tracks
+func (f *FlightRecorder) Dump() []TrackTrace {
+	f.lock.RLock()
+	tracks := make([]*flightRecorderTrack, 0, len(f.tracks))
+	for _, t := range f.tracks {
+		tracks = append(tracks, t)
+	}
+	closed := append([]*flightRecorderTrack{}, f.closed...)
+	f.lock.RUnlock()
+
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].ssrc < tracks[j].ssrc
+	})
+
+	dump := make([]TrackTrace, 0, len(tracks)+len(closed))
+	for _, t := range tracks {
+		dump = append(dump, t.snapshot(false))
+	}
+	for _, t := range closed {
+		dump = append(dump, t.snapshot(true))
+	}
+	return dump
+}
+
+// DumpTrack returns the recorded traces of a single track
+func (f *FlightRecorder) DumpTrack(ssrc uint32) (TrackTrace, bool) {
+	f.lock.RLock()
+	t, ok := f.tracks[ssrc]
+	closed := false
+	if !ok {
+		for i := len(f.closed) - 1; i >= 0; i-- {
+			if f.closed[i].ssrc == ssrc {
+				t, ok, closed = f.closed[i], true, true
+				break
+			}
+		}
+	}
+	f.lock.RUnlock()
+
+	if !ok {
+		return TrackTrace{}, false
+	}
+	return t.snapshot(closed), true
+}
+
+func (f *FlightRecorder) bindTrack(info *interceptor.StreamInfo) *flightRecorderTrack {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	t := f.tracks[info.SSRC]
+	if t == nil {
+		t = &flightRecorderTrack{
+			ssrc:     info.SSRC,
+			mimeType: info.MimeType,
+			traces:   make([]*PacketTrace, f.config.Capacity),
+		}
+		f.tracks[info.SSRC] = t
+	}
+	return t
+}
+
+func (f *FlightRecorder) unbindTrack(ssrc uint32) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	t, ok := f.tracks[ssrc]
+	if !ok {
+		return
+	}
+	delete(f.tracks, ssrc)
+
+	// keep the traces of a few closed tracks around for post-hoc inspection
+	f.closed = append(f.closed, t)
+	if len(f.closed) > maxClosedFlightRecorderTracks {
+		f.closed = f.closed[1:]
+	}
+}
+
+// --------------------------------------------------------
+
+type flightRecorderProbeFactory struct {
+	recorder *FlightRecorder
+	stage    string
+	origin   bool
+}
+
+func (p *flightRecorderProbeFactory) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &flightRecorderProbe{
+		recorder: p.recorder,
+		stage:    p.stage,
+		origin:   p.origin,
+	}, nil
+}
+
+type flightRecorderProbe struct {
+	interceptor.NoOp
+
+	recorder *FlightRecorder
+	stage    string
+	origin   bool
+}
+
+func (p *flightRecorderProbe) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	track := p.recorder.bindTrack(info)
+	sampleRate := p.recorder.config.SampleRate
+
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		n, a, err := reader.Read(b, a)
+		if err != nil {
+			return n, a, err
+		}
+
+		if !p.origin {
+			if trace, ok := a[flightTraceKey{}].(*PacketTrace); ok && trace != nil {
+				track.mark(trace, p.stage)
+			}
+			return n, a, nil
+		}
+
+		if a == nil {
+			a = make(interceptor.Attributes)
+		}
+		// attributes may be reused across reads, never leave a stale trace behind
+		delete(a, flightTraceKey{})
+
+		if !track.shouldSample(sampleRate) {
+			return n, a, nil
+		}
+
+		header := rtp.Header{}
+		headerSize, err := header.Unmarshal(b[:n])
+		if err != nil {
+			return n, a, nil
+		}
+		receivedAt := time.Now()
+		// packets wait for the stages in their buffer, time them from the arrival
+		if arrivalTime, ok := buffer.StageArrivalTime(a); ok {
+			receivedAt = time.Unix(0, arrivalTime)
+		}
+		trace := &PacketTrace{
+			SequenceNumber: header.SequenceNumber,
+			Timestamp:      header.Timestamp,
+			PayloadSize:    n - headerSize,
+			ReceivedAt:     receivedAt,
+			Stages:         []StageTiming{{Stage: p.stage}},
+		}
+		track.add(trace)
+		a[flightTraceKey{}] = trace
+		return n, a, nil
+	})
+}
+
+func (p *flightRecorderProbe) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	if p.origin {
+		p.recorder.unbindTrack(info.SSRC)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"testing"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+)
+
+func TestFlightRecorder(t *testing.T) {
+	recorder := NewFlightRecorder(FlightRecorderConfig{Enabled: true, SampleRate: 2, Capacity: 3}, logger.GetLogger())
+
+	info := &interceptor.StreamInfo{SSRC: 1234, MimeType: "audio/opus"}
+
+	origin, err := recorder.OriginProbe().NewInterceptor("")
+	require.NoError(t, err)
+	stage, err := recorder.StageProbe("stage").NewInterceptor("")
+	require.NoError(t, err)
+
+	sn := uint16(0)
+	reader := interceptor.RTPReader(interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		sn++
+		pkt := &rtp.Packet{
+			Header:  rtp.Header{Version: 2, SequenceNumber: sn, SSRC: info.SSRC},
+			Payload: make([]byte, 10),
+		}
+		buf, err := pkt.Marshal()
+		if err != nil {
+			return 0, nil, err
+		}
+		return copy(b, buf), a, nil
+	}))
+	reader = origin.BindRemoteStream(info, reader)
+	reader = stage.BindRemoteStream(info, reader)
+
+	buf := make([]byte, 1500)
+	for i := 0; i < 10; i++ {
+		_, _, err := reader.Read(buf, nil)
+		require.NoError(t, err)
+	}
+
+	track, ok := recorder.DumpTrack(info.SSRC)
+	require.True(t, ok)
+	require.False(t, track.Closed)
+	// one in two of 10 packets sampled, ring keeps the last 3 in order
+	require.Len(t, track.Packets, 3)
+	require.Equal(t, []uint16{5, 7, 9}, []uint16{
+		track.Packets[0].SequenceNumber,
+		track.Packets[1].SequenceNumber,
+		track.Packets[2].SequenceNumber,
+	})
+	for _, p := range track.Packets {
+		require.Equal(t, 10, p.PayloadSize)
+		require.Len(t, p.Stages, 2)
+		require.Equal(t, FlightRecorderOriginStage, p.Stages[0].Stage)
+		require.Equal(t, "stage", p.Stages[1].Stage)
+	}
+
+	origin.UnbindRemoteStream(info)
+	stage.UnbindRemoteStream(info)
+
+	track, ok = recorder.DumpTrack(info.SSRC)
+	require.True(t, ok)
+	require.True(t, track.Closed)
+	require.Len(t, recorder.Dump(), 1)
+}
+
+func TestFlightRecorder_BufferStages(t *testing.T) {
+	recorder := NewFlightRecorder(FlightRecorderConfig{Enabled: true, SampleRate: 1, Capacity: 10}, logger.GetLogger())
+
+	origin, err := recorder.OriginProbe().NewInterceptor("")
+	require.NoError(t, err)
+	stage, err := recorder.StageProbe("stage").NewInterceptor("")
+	require.NoError(t, err)
+
+	pcmu := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "audio/PCMU", ClockRate: 8000},
+		PayloadType:        0,
+	}
+	buff := buffer.NewBuffer(4321, 100, 100)
+	buff.SetStages(interceptor.NewChain([]interceptor.Interceptor{origin, stage}), &interceptor.StreamInfo{
+		SSRC:        4321,
+		PayloadType: 0,
+		MimeType:    pcmu.MimeType,
+		ClockRate:   pcmu.ClockRate,
+	})
+
+	write := func(sn uint16) {
+		pkt := &rtp.Packet{
+			Header:  rtp.Header{Version: 2, PayloadType: 0, SequenceNumber: sn, Timestamp: uint32(sn) * 160, SSRC: 4321},
+			Payload: make([]byte, 160),
+		}
+		raw, err := pkt.Marshal()
+		require.NoError(t, err)
+		_, err = buff.Write(raw)
+		require.NoError(t, err)
+	}
+
+	// packets written ahead of binding the buffer and after are traced alike
+	write(1)
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{pcmu},
+	}, pcmu.RTPCodecCapability, 0))
+	write(2)
+	write(3)
+
+	buf := make([]byte, 1500)
+	for sn := uint16(1); sn <= 3; sn++ {
+		ep, err := buff.ReadExtended(buf)
+		require.NoError(t, err)
+		require.Equal(t, sn, ep.Packet.SequenceNumber)
+	}
+
+	track, ok := recorder.DumpTrack(4321)
+	require.True(t, ok)
+	require.Len(t, track.Packets, 3)
+	for i, p := range track.Packets {
+		require.Equal(t, uint16(i+1), p.SequenceNumber)
+		require.Equal(t, 160, p.PayloadSize)
+		require.Len(t, p.Stages, 2)
+		require.Equal(t, FlightRecorderOriginStage, p.Stages[0].Stage)
+		require.Equal(t, "stage", p.Stages[1].Stage)
+		require.GreaterOrEqual(t, p.Stages[1].Elapsed, p.Stages[0].Elapsed)
+	}
+
+	// closing the buffer unbinds the stream
+	require.NoError(t, buff.Close())
+	track, ok = recorder.DumpTrack(4321)
+	require.True(t, ok)
+	require.True(t, track.Closed)
+}
@@ -16,16 +16,25 @@
 package interceptor
 
 import (
-	"encoding/binary"
+	"io"
+	"reflect"
+	"strconv"
 	"sync"
+	"time"
 
 	"github.com/pion/interceptor"
 	"github.com/pion/rtp"
 	"github.com/pion/webrtc/v4"
 	"github.com/zhangzhao-gg/go-rnnoise/rnnoise"
+	"go.uber.org/atomic"
 
+	"github.com/livekit/livekit-server/pkg/replay"
 	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/memtrack"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/livekit-server/pkg/sfu/utils"
+	"github.com/livekit/livekit-server/pkg/telemetry/prometheus"
+	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 )
 
@@ -35,35 +44,462 @@ const (
 	rnnoiseFrameSize      = 480 // 10ms at 48kHz
 	rnnoiseBytesPerSample = 2
 	rnnoiseFrameBytes     = rnnoiseFrameSize * rnnoiseBytesPerSample
+	rnnoiseFrameDuration  = 10 * time.Millisecond
+	// RNNoise analyzes the pitch over this many past frames
+	rnnoiseHistoryFrames = 4
+	// approximate size of the state of a denoiser as reported by rnnoise_get_size, allocated outside the Go heap
+	rnnoiseStateBytes = 30 << 10
+
+	// audio resuming after a gap of this length, e. g. the publisher was in DTX, flushes the history of the denoisers
+	silenceGapThreshold = 100 * time.Millisecond
+)
+
+const (
+	// VADProbabilityAttribute is the interceptor attribute holding the highest RNNoise speech probability
+	// of the frames of a denoised packet, as float32 in [0, 1]
+	VADProbabilityAttribute = "vad.probability"
+	// VADIsSpeechAttribute is the interceptor attribute holding whether any frame of a denoised packet
+	// was kept as speech with the configured threshold, as bool
+	VADIsSpeechAttribute = "vad.is_speech"
 )
 
+// VADFromAttributes returns the voice activity the noise filter attached to a packet,
+// ok is false if the packet was not denoised, e. g. while the filter is bypassed
+func VADFromAttributes(a interceptor.Attributes) (probability float32, isSpeech bool, ok bool) {
+	probability, ok = a.Get(VADProbabilityAttribute).(float32)
+	if !ok {
+		return 0, false, false
+	}
+	isSpeech, _ = a.Get(VADIsSpeechAttribute).(bool)
+	return probability, isSpeech, true
+}
+
+// number of denoiser instances held by all streams of all participants
+var liveDenoisers atomic.Int64
+
+// LiveDenoisers returns the number of denoiser instances currently allocated across all streams
+func LiveDenoisers() int64 {
+	return liveDenoisers.Load()
+}
+
+// time spent denoising and frames denoised across all streams since the last TakeFrameLatency
+var (
+	frameLatencyTotal  atomic.Int64
+	frameLatencyFrames atomic.Int64
+)
+
+// TakeFrameLatency returns the average time denoising a frame took across all streams since the previous call,
+// 0 if no frame was denoised
+func TakeFrameLatency() time.Duration {
+	frames := frameLatencyFrames.Swap(0)
+	total := frameLatencyTotal.Swap(0)
+	if frames == 0 {
+		return 0
+	}
+	return time.Duration(total / frames)
+}
+
 // NoiseFilterFactory creates noise filter interceptors for audio streams
 type NoiseFilterFactory struct {
-	config audio.NoiseFilterConfig
-	logger logger.Logger
-	mu     sync.RWMutex
+	config     audio.NoiseFilterConfig
+	profile    *audio.NoiseProfile
+	language   string
+	estimators []*audio.NoiseProfileEstimator
+	readers    map[uint32]*noiseFilterReader
+	disabled   map[uint32]struct{}
+	compatible map[uint32]struct{}
+	echoes     map[uint32]*audio.EchoReference
+	extended   map[uint32]struct{}
+	tracks     map[uint32]noiseFilterTrack
+	mem        *memtrack.Scope
+	logger     logger.Logger
+	mu         sync.RWMutex
+
+	// bypassed by the room or excluded by participant, see SetBypass and SetExcluded
+	roomBypass bool
+	excluded   bool
+	bypass     atomic.Bool
+
+	// concealment of lost packets of streams created afterwards, see SetConcealment
+	concealment audio.ConcealmentConfig
+
+	// gain of streams in dB, see SetStreamGain
+	gains map[uint32]float64
 }
 
 // NewNoiseFilterFactory creates a new noise filter factory
 func NewNoiseFilterFactory(config audio.NoiseFilterConfig, logger logger.Logger) *NoiseFilterFactory {
 	return &NoiseFilterFactory{
-		config: config,
-		logger: logger,
+		config:     config,
+		readers:    make(map[uint32]*noiseFilterReader),
+		disabled:   make(map[uint32]struct{}),
+		compatible: make(map[uint32]struct{}),
+		echoes:     make(map[uint32]*audio.EchoReference),
+		extended:   make(map[uint32]struct{}),
+		tracks:     make(map[uint32]noiseFilterTrack),
+		gains:      make(map[uint32]float64),
+		mem:        memtrack.DefaultRegistry.NewScope("noise_filter"),
+		logger:     logger,
 	}
 }
 
-// UpdateConfig updates the noise filter configuration
+// UpdateConfig updates the noise filter configuration, e. g. after the config file changed. Streams already
+// bound switch to the new settings by creating their denoisers again with the next packet. Streams bound while the
+// noise filter was disabled pass through, and the worker settings of a stream apply, until it is bound again.
 func (f *NoiseFilterFactory) UpdateConfig(config audio.NoiseFilterConfig) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
+
+	if reflect.DeepEqual(f.config, config) {
+		return
+	}
 	f.config = config
+	tuned := f.configLocked()
+	f.logger.Infow("noise filter configuration updated", "enabled", tuned.Enabled, "aggressiveness", tuned.Level(), "model", tuned.ModelPath)
+
+	for _, r := range f.readers {
+		r.reconfigure(tuned)
+	}
+}
+
+// SetConcealment sets the concealment of lost packets for streams created afterwards
+func (f *NoiseFilterFactory) SetConcealment(config audio.ConcealmentConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.concealment = config
 }
 
-// GetConfig returns the current configuration
+func (f *NoiseFilterFactory) getConcealment() audio.ConcealmentConfig {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.concealment
+}
+
+// GetConfig returns the current configuration, tuned for the language of the participant and
+// by the noise profile if one is set
 func (f *NoiseFilterFactory) GetConfig() audio.NoiseFilterConfig {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
-	return f.config
+	return f.configLocked()
+}
+
+func (f *NoiseFilterFactory) configLocked() audio.NoiseFilterConfig {
+	return f.profile.Apply(f.config.ForLanguage(f.language))
+}
+
+// SetLanguage tunes the noise filter for speech of the language, a BCP 47 tag. Streams already bound
+// switch to the tuned settings by creating their denoisers again with the next packet.
+func (f *NoiseFilterFactory) SetLanguage(language string) {
+	language = audio.NormalizeLanguage(language)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.language == language {
+		return
+	}
+	f.language = language
+	config := f.configLocked()
+	label := audio.LanguageLabel(language)
+	f.logger.Infow("noise filter language changed", "language", language, "aggressiveness", config.Level(), "model", config.ModelPath)
+
+	for _, r := range f.readers {
+		r.reconfigure(config)
+		r.stats.Load().SetLanguage(label)
+	}
+}
+
+// SetProfile sets the noise profile learned in earlier sessions, it applies to streams bound afterwards
+func (f *NoiseFilterFactory) SetProfile(profile *audio.NoiseProfile) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.profile = profile
+}
+
+// LearnedProfile returns the noise profile learned from the stream with the most observed noise,
+// falls back to the profile set with SetProfile
+func (f *NoiseFilterFactory) LearnedProfile() *audio.NoiseProfile {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	var best *audio.NoiseProfileEstimator
+	for _, e := range f.estimators {
+		if best == nil || e.NoiseFrames() > best.NoiseFrames() {
+			best = e
+		}
+	}
+	if best == nil {
+		return f.profile
+	}
+	return best.Profile()
+}
+
+func (f *NoiseFilterFactory) newEstimator() *audio.NoiseProfileEstimator {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	e := audio.NewNoiseProfileEstimator(f.config, f.profile)
+	f.estimators = append(f.estimators, e)
+	return e
+}
+
+// Release frees the denoiser and codec state of all streams, e. g. when the peer stopped sending,
+// they are created again with the next packet of a stream
+func (f *NoiseFilterFactory) Release() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	for _, r := range f.readers {
+		r.mu.Lock()
+		r.releaseLocked()
+		r.mu.Unlock()
+	}
+}
+
+// Close tears down all streams, the readers pass audio through from here on and hold no denoiser
+func (f *NoiseFilterFactory) Close() {
+	f.mu.Lock()
+	readers := f.readers
+	f.readers = make(map[uint32]*noiseFilterReader)
+	f.mu.Unlock()
+
+	// instances of streams still bound after this are flagged as leaked
+	f.mem.End()
+
+	for _, r := range readers {
+		r.close()
+	}
+}
+
+// SetBypass passes audio through unfiltered while bypass is set, the denoiser state of all streams
+// is freed when the bypass starts
+func (f *NoiseFilterFactory) SetBypass(bypass bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.roomBypass = bypass
+	f.updateBypassLocked()
+}
+
+// SetExcluded excludes the participant from noise filtering, audio passes through unfiltered
+func (f *NoiseFilterFactory) SetExcluded(excluded bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.excluded = excluded
+	f.updateBypassLocked()
+}
+
+func (f *NoiseFilterFactory) updateBypassLocked() {
+	bypass := f.roomBypass || f.excluded
+	if f.bypass.Swap(bypass) == bypass || !bypass {
+		return
+	}
+
+	for _, r := range f.readers {
+		r.mu.Lock()
+		r.releaseLocked()
+		r.mu.Unlock()
+	}
+}
+
+// SetStreamEnabled switches noise filtering of a single stream at runtime, also ahead of the stream being bound.
+// Disabling frees the denoiser state of the stream, it is created again with the next packet once re-enabled.
+func (f *NoiseFilterFactory) SetStreamEnabled(ssrc uint32, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if enabled {
+		delete(f.disabled, ssrc)
+	} else {
+		f.disabled[ssrc] = struct{}{}
+	}
+
+	r := f.readers[ssrc]
+	if r == nil || r.disabled.Swap(!enabled) == !enabled || enabled {
+		return
+	}
+	r.mu.Lock()
+	r.releaseLocked()
+	r.mu.Unlock()
+}
+
+// IsStreamEnabled returns false if noise filtering of the stream was switched off
+func (f *NoiseFilterFactory) IsStreamEnabled(ssrc uint32) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, disabled := f.disabled[ssrc]
+	return !disabled
+}
+
+// SetStreamCompatible switches a stream to compatibility mode at runtime, also ahead of the stream being bound.
+// Denoised packets then keep the frame duration, bitrate and at most the size of the packets they replace.
+func (f *NoiseFilterFactory) SetStreamCompatible(ssrc uint32, compatible bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if compatible {
+		f.compatible[ssrc] = struct{}{}
+	} else {
+		delete(f.compatible, ssrc)
+	}
+	if r := f.readers[ssrc]; r != nil {
+		r.compatible.Store(compatible)
+	}
+}
+
+// IsStreamCompatible returns true if the stream is in compatibility mode
+func (f *NoiseFilterFactory) IsStreamCompatible(ssrc uint32) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	_, compatible := f.compatible[ssrc]
+	return compatible
+}
+
+// SetStreamEchoReference cancels the audio of reference from a stream ahead of denoising, also ahead of the
+// stream being bound. nil stops cancellation.
+func (f *NoiseFilterFactory) SetStreamEchoReference(ssrc uint32, reference *audio.EchoReference) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if reference != nil {
+		f.echoes[ssrc] = reference
+	} else {
+		delete(f.echoes, ssrc)
+	}
+	if r := f.readers[ssrc]; r != nil {
+		r.echoReference.Store(reference)
+	}
+}
+
+// SetStreamBandwidthExtension switches the reconstruction of the upper band of a narrowband stream at runtime,
+// also ahead of the stream being bound. Only Opus streams are extended, G.711 cannot carry the upper band.
+func (f *NoiseFilterFactory) SetStreamBandwidthExtension(ssrc uint32, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if enabled {
+		f.extended[ssrc] = struct{}{}
+	} else {
+		delete(f.extended, ssrc)
+	}
+	if r := f.readers[ssrc]; r != nil {
+		r.bandwidthExtension.Store(enabled)
+	}
+}
+
+// SetStreamGain scales the audio of a stream by gainDB at runtime, also ahead of the stream being bound. The gain is
+// applied after denoising, streams not denoised are decoded and encoded again just for it. 0 stops scaling.
+func (f *NoiseFilterFactory) SetStreamGain(ssrc uint32, gainDB float64) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if gainDB != 0 {
+		f.gains[ssrc] = gainDB
+	} else {
+		delete(f.gains, ssrc)
+	}
+
+	r := f.readers[ssrc]
+	if r == nil || r.gainDB.Swap(gainDB) == gainDB || r.isActive() {
+		return
+	}
+	// the codec state of a stream only scaled is freed with the gain
+	r.mu.Lock()
+	r.releaseLocked()
+	r.mu.Unlock()
+}
+
+// StreamGain returns the gain of the stream in dB, 0 when it is not scaled
+func (f *NoiseFilterFactory) StreamGain(ssrc uint32) float64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.gains[ssrc]
+}
+
+// SetStreamTrack labels the metrics of a stream with the room and the track it belongs to, also ahead
+// of the stream being bound. Metrics of a stream are recorded from then on.
+func (f *NoiseFilterFactory) SetStreamTrack(ssrc uint32, room livekit.RoomName, trackID livekit.TrackID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	track := noiseFilterTrack{room: room, trackID: trackID}
+	if f.tracks[ssrc] == track {
+		return
+	}
+	f.tracks[ssrc] = track
+	if r := f.readers[ssrc]; r != nil {
+		f.acquireStatsLocked(r, track)
+		r.startDebugDump(track)
+	}
+}
+
+// acquireStatsLocked labels the metrics of a stream with its track and the language of the participant.
+// Must be called with the lock held.
+func (f *NoiseFilterFactory) acquireStatsLocked(r *noiseFilterReader, track noiseFilterTrack) {
+	stats := prometheus.AcquireNoiseFilterStreamStats(track.room, track.trackID)
+	stats.SetLanguage(audio.LanguageLabel(f.language))
+	r.setStats(stats)
+}
+
+func (f *NoiseFilterFactory) addReader(ssrc uint32, r *noiseFilterReader) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	_, disabled := f.disabled[ssrc]
+	r.disabled.Store(disabled)
+	_, compatible := f.compatible[ssrc]
+	r.compatible.Store(compatible)
+	r.echoReference.Store(f.echoes[ssrc])
+	_, extended := f.extended[ssrc]
+	r.bandwidthExtension.Store(extended)
+	r.gainDB.Store(f.gains[ssrc])
+	if track, ok := f.tracks[ssrc]; ok {
+		f.acquireStatsLocked(r, track)
+		r.startDebugDump(track)
+	}
+	f.readers[ssrc] = r
+}
+
+func (f *NoiseFilterFactory) removeReader(ssrc uint32) *noiseFilterReader {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.readers[ssrc]
+	delete(f.readers, ssrc)
+	delete(f.disabled, ssrc)
+	delete(f.compatible, ssrc)
+	delete(f.echoes, ssrc)
+	delete(f.extended, ssrc)
+	delete(f.gains, ssrc)
+	delete(f.tracks, ssrc)
+	return r
+}
+
+// EndStream tears down the filter chain of a stream the peer ended with an RTCP BYE,
+// without waiting for the track to be unbound
+func (f *NoiseFilterFactory) EndStream(ssrc uint32, reason string) {
+	if r := f.removeReader(ssrc); r != nil {
+		r.close()
+		f.logger.Debugw("noise filter stream ended by RTCP BYE", "ssrc", ssrc, "reason", reason)
+	}
+}
+
+// DenoiserStats returns the lifetime stats of the denoiser of every bound stream, keyed by SSRC
+func (f *NoiseFilterFactory) DenoiserStats() map[uint32]audio.DenoiserStats {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	stats := make(map[uint32]audio.DenoiserStats, len(f.readers))
+	for ssrc, r := range f.readers {
+		r.mu.Lock()
+		stats[ssrc] = r.reset.Stats()
+		r.mu.Unlock()
+	}
+	return stats
 }
 
 // NewInterceptor creates a new noise filter interceptor instance
@@ -103,159 +539,973 @@ func (n *NoiseFilterInterceptor) BindRemoteStream(info *interceptor.StreamInfo,
 		return reader
 	}
 
-	n.logger.Debugw("applying noise filter to audio stream", "ssrc", info.SSRC, "config", config)
+	n.logger.Debugw("applying noise filter to audio stream", "ssrc", info.SSRC, "codec", noiseFilterCodec(info), "config", config)
 
-	return &noiseFilterReader{
-		reader:   reader,
-		config:   config,
-		denoiser: nil, // Will be initialized on first packet
-		logger:   n.logger,
-		buffer:   make([]byte, 0, rnnoiseFrameBytes*2), // Buffer for incomplete frames
+	r := &noiseFilterReader{
+		reader:    reader,
+		config:    config,
+		channels:  audio.OpusChannels(info.SDPFmtpLine),
+		settings:  audio.OpusEncoderSettingsFromFmtp(info.SDPFmtpLine),
+		concealer: audio.NewLossConcealer(n.factory.getConcealment()),
+		estimator: n.factory.newEstimator(),
+		reset:     audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		mem:       n.factory.mem.Track(strconv.FormatUint(uint64(info.SSRC), 10)),
+		logger:    n.logger.WithValues("ssrc", info.SSRC),
+		bypass:    &n.factory.bypass,
+
+		payloadType: info.PayloadType,
+		codec:       noiseFilterCodec(info),
+	}
+	r.instances = getDenoiserInstances(config.Instances)
+	if config.Workers.Enabled {
+		r.pool = getDenoiserPool(config.Workers)
+		r.stream.process = r.processJob
+		r.queue = make(chan *denoiseJob, max(config.Workers.StreamQueueSize, 1))
+		r.stop = make(chan struct{})
+	}
+	n.factory.addReader(info.SSRC, r)
+	return r
+}
+
+// UnbindRemoteStream forgets the stream's reader and frees its denoiser
+func (n *NoiseFilterInterceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	if r := n.factory.removeReader(info.SSRC); r != nil {
+		stats := r.close()
+		n.logger.Debugw("noise filter stream closed", "ssrc", info.SSRC, "denoiserStats", stats)
 	}
 }
 
-// noiseFilterReader processes RTP packets and applies noise suppression
+type noiseFilterTrack struct {
+	room    livekit.RoomName
+	trackID livekit.TrackID
+}
+
+// channelDenoiser is the RNNoise state of one channel of a stream, secondPass denoises speech frames
+// a second time at the highest aggressiveness and is nil otherwise
+type channelDenoiser struct {
+	denoiser   *rnnoise.NoiseFilter
+	secondPass *rnnoise.NoiseFilter
+	// instances of the built in model are returned to the pool
+	pooled bool
+}
+
+func (d channelDenoiser) instances() int64 {
+	n := int64(0)
+	if d.denoiser != nil {
+		n++
+	}
+	if d.secondPass != nil {
+		n++
+	}
+	return n
+}
+
+// noiseFilterReader processes RTP packets and applies noise suppression.
+// Opus payloads are decoded to PCM, denoised and encoded again. G.711 payloads are expanded and upsampled
+// to the 48 kHz RNNoise works at, denoised, and downsampled and compressed again. Payloads of other codecs pass through.
 type noiseFilterReader struct {
-	reader   interceptor.RTPReader
-	config   audio.NoiseFilterConfig
-	denoiser *rnnoise.NoiseFilter
-	logger   logger.Logger
-	buffer   []byte
-	mu       sync.Mutex
+	reader    interceptor.RTPReader
+	config    audio.NoiseFilterConfig
+	channels  int
+	estimator *audio.NoiseProfileEstimator
+	reset     *audio.DenoiserResetScheduler
+	mem       *memtrack.Instance
+	logger    logger.Logger
+	bypass    *atomic.Bool
+	disabled  atomic.Bool
+	// switched off by the settings of the participant's language, see NoiseFilterFactory.SetLanguage
+	unconfigured atomic.Bool
+	// packets keep the size of the original ones, see NoiseFilterFactory.SetStreamCompatible
+	compatible atomic.Bool
+	// audio cancelled from the stream, see NoiseFilterFactory.SetStreamEchoReference
+	echoReference atomic.Pointer[audio.EchoReference]
+	// packets passed through without being decoded since the last processed one, they were not lost
+	skipped atomic.Bool
+	// upper band reconstructed, see NoiseFilterFactory.SetStreamBandwidthExtension
+	bandwidthExtension atomic.Bool
+	closed             bool
+	mu                 sync.Mutex
+
+	// gain in dB the audio is scaled by after denoising, see NoiseFilterFactory.SetStreamGain
+	gainDB atomic.Float64
+
+	// one per channel, initialized on the first packet
+	denoisers   []channelDenoiser
+	suppression audio.NoiseSuppression
+	// one per channel, nil when noise frames are attenuated instead
+	comfortNoise []*audio.ComfortNoise
+	// one per channel, nil when frames switch between speech and noise without an envelope
+	gates []*audio.NoiseGate
+	// comfort noise mixed in by a closing gate
+	background []float32
+	// nil unless music detection is enabled, classifies the first channel for all of them, isMusic
+	// bypasses suppression while set
+	music   *audio.MusicDetector
+	isMusic bool
+	// one per channel, nil without an echo reference, echoCancelled is the reference they cancel
+	echoCancellers []*audio.EchoCanceller
+	echoCancelled  *audio.EchoReference
+	// one per channel, nil while the stream is not extended, extensionFailed when the model cannot be created
+	extenders       []audio.BandwidthExtender
+	extensionFailed bool
+
+	// nil until the track of the stream is known
+	stats atomic.Pointer[prometheus.NoiseFilterStreamStats]
+	// nil unless debug dumps are enabled, until the track of the stream is known and once recorded
+	debugDump atomic.Pointer[audio.DebugRecording]
+
+	// payload type of the audio codec, anything else on the stream, e. g. RFC 4733 telephone events, is passed through
+	payloadType uint8
+	codec       mime.MimeType
+
+	decoder audio.OpusDecoder
+	encoder audio.OpusEncoder
+	// in-band FEC and DTX of the publisher's encoder, kept when encoding the denoised audio
+	settings audio.OpusEncoderSettings
+	// nil unless concealment is enabled, feeds the decoder and the denoisers audio for lost packets
+	concealer *audio.LossConcealer
+	// bitrate the encoder is held to in compatibility mode, 0 when it chooses itself
+	encoderBitrate int
+	pcm            []int16
+	samples        []float32
+	payload        []byte
+	// RTP timestamp the audio of the last denoised packet ends at, valid if hasNextTimestamp
+	nextTimestamp    uint32
+	hasNextTimestamp bool
+
+	// G.711 streams, converting between 8 kHz and 48 kHz
+	upsampler   *audio.Resampler
+	downsampler *audio.Resampler
+	narrowband  []int16
+
+	// with a worker pool, packets are read ahead into queue and processed by the pool
+	pool *denoiserPool
+	// pre-initialized denoisers, nil when streams create their own
+	instances *denoiserInstances
+	stream    denoiseStream
+	queue     chan *denoiseJob
+	pumpOnce  sync.Once
+	stop      chan struct{}
+}
+
+// noiseFilterCodec returns the codec of the stream, falling back to the payload types
+// this server negotiates when the stream info does not carry the mime type
+func noiseFilterCodec(info *interceptor.StreamInfo) mime.MimeType {
+	if codec := mime.NormalizeMimeType(info.MimeType); codec != mime.MimeTypeUnknown {
+		return codec
+	}
+
+	switch info.PayloadType {
+	case 0:
+		return mime.MimeTypePCMU
+	case 8:
+		return mime.MimeTypePCMA
+	case 111:
+		return mime.MimeTypeOpus
+	}
+	return mime.MimeTypeUnknown
 }
 
 // Read processes an RTP packet and applies noise suppression to audio payload
 func (r *noiseFilterReader) Read(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+	if r.pool != nil {
+		return r.readQueued(b, a)
+	}
+
 	n, a, err := r.reader.Read(b, a)
 	if err != nil {
 		return n, a, err
 	}
-
-	// Initialize denoiser on first packet
-	r.mu.Lock()
-	if r.denoiser == nil {
-		var err error
-		r.denoiser, err = rnnoise.NewNoiseFilter("")
-		if err != nil {
-			r.logger.Errorw("failed to initialize RNNoise denoiser", err)
-			r.mu.Unlock()
-			return n, a, nil // Pass through without processing
-		}
-		r.logger.Debugw("initialized RNNoise denoiser")
+	if !r.isActive() {
+		r.skipped.Store(true)
+		return n, a, err
 	}
-	r.mu.Unlock()
 
 	if a == nil {
 		a = make(interceptor.Attributes)
 	}
+	return r.process(b, n, a), a, nil
+}
+
+func (r *noiseFilterReader) isActive() bool {
+	return !r.bypass.Load() && (r.isDenoising() || r.gainDB.Load() != 0)
+}
+
+// isDenoising returns false while the stream is only scaled by its gain
+func (r *noiseFilterReader) isDenoising() bool {
+	return !r.disabled.Load() && !r.unconfigured.Load()
+}
+
+// reconfigure replaces the settings of the stream, the denoisers are created again with the next packet
+func (r *noiseFilterReader) reconfigure(config audio.NoiseFilterConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.config = config
+	r.unconfigured.Store(!config.Enabled)
+	if !r.closed {
+		r.releaseLocked()
+	}
+}
 
+// process denoises the packet in b[:n] in place and returns its new size,
+// the voice activity of the packet is set on a
+func (r *noiseFilterReader) process(b []byte, n int, a interceptor.Attributes) int {
 	// Parse RTP header
 	packet := &rtp.Packet{}
 	if err := packet.Unmarshal(b[:n]); err != nil {
-		return n, a, nil // Pass through on parse error
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughParse)
+		return n // Pass through on parse error
+	}
+	// other payload types, e. g. comfort noise of G.711 streams in silence, pass through
+	if packet.PayloadType != r.payloadType {
+		return n
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// samples of the packet per channel, in RTP timestamp units, and lost ahead of it
+	var duration, lost int
+	switch r.codec {
+	case mime.MimeTypeOpus:
+		if r.skipped.Swap(false) {
+			r.concealer.Reset()
+		}
+		// every packet counts towards detecting losses, also those passed through
+		lost = r.concealer.Observe(packet.SequenceNumber, packet.Timestamp, packet.Payload)
+		// stereo packets of a stream negotiated as mono are not folded down, DTX packets carry no audio
+		toc, ok := audio.ParseOpusTOC(packet.Payload)
+		if !ok || (toc.Stereo && r.numChannels() == 1) || audio.IsOpusDTX(packet.Payload) {
+			return n
+		}
+		duration = int(toc.Duration() * audio.OpusSampleRate / time.Second)
+	case mime.MimeTypePCMU, mime.MimeTypePCMA:
+		duration = len(packet.Payload)
+	default:
+		return n
+	}
+
+	if r.closed || !r.initLocked() {
+		return n
+	}
+	// reordered and retransmitted packets arrive after the audio following them was denoised, decoding them
+	// would set the decoder and the denoisers back, they are forwarded as received
+	if r.hasNextTimestamp && int32(packet.Timestamp-r.nextTimestamp) < 0 {
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughLate)
+		return n
+	}
+	if lost > 0 {
+		r.concealLocked(lost, packet.Payload)
+	}
+	r.resumeLocked(packet.Timestamp)
+
+	var payload []byte
+	var probability float32
+	var isSpeech, ok bool
+	if r.codec == mime.MimeTypeOpus {
+		payload, probability, isSpeech, ok = r.processOpusLocked(packet.Payload)
+	} else {
+		payload, probability, isSpeech, ok = r.processG711Locked(packet.Payload)
+	}
+	if !ok {
+		return n
+	}
+	r.nextTimestamp, r.hasNextTimestamp = packet.Timestamp+uint32(duration), true
+	packet.Payload = payload
+	// audio only scaled was not classified
+	if r.denoisers != nil {
+		a.Set(VADProbabilityAttribute, probability)
+		a.Set(VADIsSpeechAttribute, isSpeech)
+	}
+
+	// Marshal the new packet
+	newData, err := packet.Marshal()
+	if err != nil {
+		r.logger.Errorw("failed to marshal processed packet", err)
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughMarshal)
+		return n // Return original on error
+	}
+
+	// Copy processed data back to buffer
+	if len(newData) > len(b) {
+		r.logger.Warnw("processed packet too large for buffer", nil)
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughTooLarge)
+		return n // Return original if too large
+	}
+	return copy(b, newData)
+}
+
+// processPCM denoises the whole RNNoise frames of interleaved 48 kHz PCM in place, audio the server
+// produces itself rather than receives. Returns false if the audio passed through unfiltered.
+func (r *noiseFilterReader) processPCM(pcm []int16) bool {
+	samples := len(pcm) / r.numChannels() / rnnoiseFrameSize * rnnoiseFrameSize
+	if samples == 0 || !r.isActive() {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || !r.initDenoisersLocked() || r.denoisers == nil {
+		return false
+	}
+	if r.samples == nil {
+		r.samples = make([]float32, rnnoiseFrameSize)
+	}
+	r.denoisePCMLocked(pcm, samples)
+	return true
+}
+
+// readQueued returns the next packet of the stream once the pool processed it
+func (r *noiseFilterReader) readQueued(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+	r.pumpOnce.Do(func() {
+		go r.pump()
+	})
+
+	job, ok := <-r.queue
+	if !ok {
+		// the pump stopped, after an error or as the reader was closed, read unfiltered
+		return r.reader.Read(b, a)
+	}
+	<-job.done
+
+	n, attrs, err := copy(b, job.buf[:job.n]), job.attrs, job.err
+	if err == nil && n < job.n {
+		err = io.ErrShortBuffer
+	}
+	job.attrs, job.err = nil, nil
+	denoiseJobPool.Put(job)
+	return n, attrs, err
+}
+
+// pump reads packets ahead of the consumer and hands them to the pool, keeping the read loop free
+// of denoising. Packets pass through if the pool is saturated. It ends with the first read error,
+// or when the reader is closed, dropping the packet it holds.
+func (r *noiseFilterReader) pump() {
+	defer close(r.queue)
+
+	for {
+		job := denoiseJobPool.Get().(*denoiseJob)
+		job.n, job.attrs, job.err = r.reader.Read(job.buf, make(interceptor.Attributes))
+		switch {
+		case job.err != nil:
+			job.done <- struct{}{}
+		case !r.isActive():
+			r.skipped.Store(true)
+			job.done <- struct{}{}
+		case !r.pool.submit(&r.stream, job):
+			r.skipped.Store(true)
+			r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughSaturated)
+			job.done <- struct{}{}
+		}
+
+		select {
+		case r.queue <- job:
+		case <-r.stop:
+			return
+		}
+		if job.err != nil {
+			return
+		}
 	}
+}
 
-	// Process audio payload
-	if len(packet.Payload) > 0 {
-		processedPayload := r.processAudioPayload(packet.Payload)
+func (r *noiseFilterReader) processJob(job *denoiseJob) {
+	if job.attrs == nil {
+		job.attrs = make(interceptor.Attributes)
+	}
+	job.n = r.process(job.buf, job.n, job.attrs)
+}
 
-		// Create new packet with processed payload
-		newPacket := &rtp.Packet{
-			Header:  packet.Header,
-			Payload: processedPayload,
+// initLocked creates the codecs and the denoiser on the first packet, or after they were released.
+// Returns false if the stream has to pass through. Must be called with the lock held.
+func (r *noiseFilterReader) initLocked() bool {
+	switch {
+	case r.codec == mime.MimeTypePCMU || r.codec == mime.MimeTypePCMA:
+		if r.upsampler == nil {
+			r.upsampler = audio.NewResampler(audio.G711SampleRate, audio.OpusSampleRate, 1)
+			r.downsampler = audio.NewResampler(audio.OpusSampleRate, audio.G711SampleRate, 1)
+			r.pcm = make([]int16, 0, audio.OpusMaxFrameSize)
+			r.samples = make([]float32, rnnoiseFrameSize)
+			r.payload = make([]byte, 0, audio.OpusMaxFrameSize*audio.G711SampleRate/audio.OpusSampleRate)
+			r.narrowband = make([]int16, 0, cap(r.payload))
 		}
 
-		// Marshal the new packet
-		newData, err := newPacket.Marshal()
+	case r.decoder == nil || r.encoder == nil:
+		decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, r.numChannels())
+		if err == nil {
+			r.encoder, err = audio.NewOpusEncoder(audio.OpusSampleRate, r.numChannels())
+		}
 		if err != nil {
-			r.logger.Errorw("failed to marshal processed packet", err)
-			return n, a, nil // Return original on error
+			// not retried, without a codec the payload cannot be filtered
+			r.logger.Warnw("opus codec unavailable, passing audio through unfiltered", err)
+			r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughCodecUnavailable)
+			r.codec = mime.MimeTypeUnknown
+			return false
+		}
+		r.decoder = decoder
+		r.encoder = audio.DefaultEncoderRegistry.Track(r.encoder, audio.EncoderPriorityDenoised)
+		if err := audio.PreserveOpusSettings(r.encoder, r.settings); err != nil {
+			// the denoised audio is still valid, receivers lose what they relied on the settings for
+			r.logger.Warnw("could not preserve opus encoder settings", err, "fec", r.settings.InBandFEC, "dtx", r.settings.DTX)
 		}
+		r.pcm = make([]int16, audio.OpusMaxFrameSize*r.numChannels())
+		r.samples = make([]float32, rnnoiseFrameSize)
+		r.payload = make([]byte, audio.OpusMaxPacketSize)
+	}
 
-		// Copy processed data back to buffer
-		if len(newData) <= len(b) {
-			copy(b, newData)
-			return len(newData), a, nil
-		} else {
-			r.logger.Warnw("processed packet too large for buffer", nil)
-			return n, a, nil // Return original if too large
+	return r.initDenoisersLocked()
+}
+
+// initDenoisersLocked creates the denoisers on the first frames, or after they were released, streams
+// only scaled by their gain have none. Returns false if the audio has to pass through. Must be called
+// with the lock held.
+func (r *noiseFilterReader) initDenoisersLocked() bool {
+	if r.denoisers == nil && r.isDenoising() {
+		r.suppression = r.config.Suppression()
+		if err := r.newDenoisersLocked(); err != nil {
+			r.logger.Errorw("failed to initialize RNNoise denoiser", err)
+			stats := r.stats.Load()
+			stats.RecordInitFailure()
+			stats.RecordPassthrough(prometheus.NoiseFilterPassthroughInit)
+			return false // Pass through without processing
+		}
+		if r.config.ComfortNoise.Enabled {
+			r.comfortNoise = make([]*audio.ComfortNoise, r.numChannels())
+			for i := range r.comfortNoise {
+				r.comfortNoise[i] = audio.NewComfortNoise(r.config.ComfortNoise, r.suppression.NoiseGain)
+			}
+		}
+		if r.config.NoiseGate.Enabled {
+			r.gates = make([]*audio.NoiseGate, r.numChannels())
+			for i := range r.gates {
+				r.gates[i] = audio.NewNoiseGate(r.config.NoiseGate, r.suppression.Threshold, r.suppression.NoiseGain, audio.OpusSampleRate)
+			}
+			r.background = make([]float32, rnnoiseFrameSize)
+		}
+		if r.config.MusicDetection.Enabled {
+			r.music = audio.NewMusicDetector(r.config.MusicDetection, rnnoiseFrameDuration)
 		}
+		r.logger.Debugw(
+			"initialized RNNoise denoiser",
+			"aggressiveness", r.config.Level(),
+			"channels", r.numChannels(),
+			"comfortNoise", r.comfortNoise != nil,
+			"noiseGate", r.gates != nil,
+			"musicDetection", r.music != nil,
+		)
+	}
+	return true
+}
+
+// processOpusLocked decodes the payload, denoises each channel of the PCM and encodes it again, returning
+// the highest speech probability of its frames and whether any of them was speech.
+// Returns false if the payload is to be forwarded as is. Must be called with the lock held.
+func (r *noiseFilterReader) processOpusLocked(payload []byte) ([]byte, float32, bool, bool) {
+	samples, err := r.decoder.Decode(payload, r.pcm)
+	if err != nil {
+		r.logger.Debugw("failed to decode opus payload", "error", err)
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughDecode)
+		return nil, 0, false, false
+	}
+	// frames shorter than 10 ms cannot be denoised
+	if samples == 0 || samples%rnnoiseFrameSize != 0 {
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughFrameSize)
+		return nil, 0, false, false
+	}
+
+	maxProbability, isSpeech := r.denoisePCMLocked(r.pcm, samples)
+	r.applyGainLocked(r.pcm[:samples*r.numChannels()])
+
+	out := r.payload
+	if r.compatible.Load() {
+		// same frame duration at the bitrate of the original packet, never larger than it
+		r.setEncoderBitrateLocked(len(payload) * 8 * audio.OpusSampleRate / samples)
+		out = r.payload[:min(len(payload), len(r.payload))]
+	} else {
+		r.setEncoderBitrateLocked(0)
 	}
 
-	return n, a, nil
+	channels := r.numChannels()
+	size, err := r.encoder.Encode(r.pcm[:samples*channels], out)
+	if err != nil {
+		r.logger.Warnw("failed to encode denoised audio", err)
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughEncode)
+		return nil, 0, false, false
+	}
+
+	r.observeMemoryLocked()
+	return r.payload[:size], maxProbability, isSpeech, true
 }
 
-// processAudioPayload applies noise suppression to audio data
-func (r *noiseFilterReader) processAudioPayload(payload []byte) []byte {
-	// For now, we'll assume the payload is PCM audio data
-	// In a real implementation, you'd need to handle different codecs
-	// and potentially decode before processing
+// setEncoderBitrateLocked holds the encoder to bitrate, 0 lets it choose again. Must be called with the lock held.
+func (r *noiseFilterReader) setEncoderBitrateLocked(bitrate int) {
+	if bitrate == r.encoderBitrate {
+		return
+	}
+	if err := audio.SetOpusBitrate(r.encoder, bitrate); err != nil {
+		r.logger.Debugw("failed to set encoder bitrate", "error", err, "bitrate", bitrate)
+	}
+	r.encoderBitrate = bitrate
+}
+
+// processG711Locked expands the payload, upsamples it to 48 kHz for denoising and downsamples and compresses
+// it again, returning the highest speech probability of its frames and whether any of them was speech.
+// Returns false if the payload is to be forwarded as is. Must be called with the lock held.
+func (r *noiseFilterReader) processG711Locked(payload []byte) ([]byte, float32, bool, bool) {
+	// one byte per sample, packets that do not fill whole RNNoise frames, e. g. 5 ms, cannot be denoised
+	samples := len(payload) * audio.OpusSampleRate / audio.G711SampleRate
+	if samples == 0 || samples%rnnoiseFrameSize != 0 {
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughFrameSize)
+		return nil, 0, false, false
+	}
+
+	expand, compress := audio.DecodeMuLaw, audio.EncodeMuLaw
+	if r.codec == mime.MimeTypePCMA {
+		expand, compress = audio.DecodeALaw, audio.EncodeALaw
+	}
+
+	r.narrowband = r.narrowband[:0]
+	for _, b := range payload {
+		r.narrowband = append(r.narrowband, expand(b))
+	}
+	r.pcm = r.upsampler.Resample(r.narrowband, r.pcm[:0])
+
+	maxProbability, isSpeech := r.denoisePCMLocked(r.pcm, samples)
+	r.applyGainLocked(r.pcm[:samples])
 
-	if len(payload) < rnnoiseFrameBytes {
-		// Frame too small, pass through
-		return payload
+	r.narrowband = r.downsampler.Resample(r.pcm, r.narrowband[:0])
+	if len(r.narrowband) != len(payload) {
+		r.stats.Load().RecordPassthrough(prometheus.NoiseFilterPassthroughFrameSize)
+		return nil, 0, false, false
+	}
+	r.payload = r.payload[:0]
+	for _, sample := range r.narrowband {
+		r.payload = append(r.payload, compress(sample))
 	}
 
-	// Add to buffer
-	r.buffer = append(r.buffer, payload...)
+	r.observeMemoryLocked()
+	return r.payload, maxProbability, isSpeech, true
+}
 
-	var processedData []byte
+// denoisePCMLocked denoises the first samples per channel of pcm in place, returning the highest speech
+// probability of its frames and whether any of them was speech. Must be called with the lock held.
+func (r *noiseFilterReader) denoisePCMLocked(pcm []int16, samples int) (float32, bool) {
+	if r.denoisers == nil {
+		return 0, false
+	}
 
-	// Process complete frames
-	for len(r.buffer) >= rnnoiseFrameBytes {
-		frame := r.buffer[:rnnoiseFrameBytes]
+	channels := r.numChannels()
+	cancellers := r.echoCancellersLocked()
+	extenders := r.extendersLocked()
+	now := replay.Now()
+	var maxProbability float32
+	var isSpeech bool
+	debugDump := r.debugDump.Load()
+	if debugDump != nil {
+		debugDump.Pre(pcm[:samples*channels])
+	}
+	for i := 0; i < samples; i += rnnoiseFrameSize {
+		frame := pcm[i*channels : (i+rnnoiseFrameSize)*channels]
+		keepAny := false
+		for channel := range r.denoisers {
+			var canceller *audio.EchoCanceller
+			if cancellers != nil {
+				canceller = cancellers[channel]
+			}
+			at := now.Add(time.Duration(i/rnnoiseFrameSize) * rnnoiseFrameDuration)
+			probability, keepFrame := r.denoiseFrameLocked(channel, frame, canceller, at)
+			if extenders != nil {
+				r.extendFrameLocked(channel, frame, extenders[channel])
+			}
+			maxProbability = max(maxProbability, probability)
+			keepAny = keepAny || keepFrame
+		}
+		isSpeech = isSpeech || keepAny
 
-		// Convert bytes to float32 samples (RNNoise expects float32)
-		samples := make([]float32, rnnoiseFrameSize)
-		for i := 0; i < rnnoiseFrameSize; i++ {
-			// Convert int16 to float32 and normalize
-			int16Val := int16(binary.LittleEndian.Uint16(frame[i*2:]))
-			samples[i] = float32(int16Val) / 32768.0
+		if r.reset.ObserveFrame(keepAny) {
+			r.resetDenoiser()
 		}
+	}
+	if debugDump != nil && debugDump.Post(pcm[:samples*channels]) {
+		r.debugDump.CompareAndSwap(debugDump, nil)
+	}
+	return maxProbability, isSpeech
+}
 
-		// Apply noise suppression using FilterStream
-		r.mu.Lock()
-		if r.denoiser != nil {
-			denoisedFrame, _, keepFrame, err := r.denoiser.FilterStream(samples, r.config.Threshold)
-			if err == nil && keepFrame {
-				// Convert back to int16
-				for i, sample := range denoisedFrame {
-					// Clamp and convert back to int16
-					clampedSample := sample * 32768.0
-					if clampedSample > 32767 {
-						clampedSample = 32767
-					} else if clampedSample < -32768 {
-						clampedSample = -32768
-					}
-					samples[i] = clampedSample
-				}
-			} else if !keepFrame {
-				// Apply noise reduction by reducing volume
-				for i := range samples {
-					samples[i] *= 0.1 // Reduce to 10% volume for noise frames
-				}
+// applyGainLocked scales the decoded audio by the gain of the stream. Must be called with the lock held.
+func (r *noiseFilterReader) applyGainLocked(pcm []int16) {
+	if gainDB := r.gainDB.Load(); gainDB != 0 {
+		audio.ApplyGain(pcm, audio.GainFactor(gainDB, audio.MinTrackGainDB, audio.MaxTrackGainDB))
+	}
+}
+
+// denoiseFrameLocked applies noise suppression to one channel of an interleaved RNNoise frame in place and
+// returns the speech probability of the channel and whether it was kept as speech. The echo is cancelled
+// first if canceller is not nil, now being the time the frame was received. Must be called with the lock held.
+func (r *noiseFilterReader) denoiseFrameLocked(channel int, frame []int16, canceller *audio.EchoCanceller, now time.Time) (float32, bool) {
+	channels := len(r.denoisers)
+	d := r.denoisers[channel]
+
+	// RNNoise expects normalized float32 samples of a single channel
+	deinterleave(r.samples, frame, channel, channels)
+	if canceller != nil {
+		canceller.Process(r.samples, now)
+	}
+	if r.musicFrameLocked(channel) {
+		// music is forwarded without suppression, only the echo is cancelled
+		interleave(frame, r.samples, channel, channels)
+		if r.comfortNoise != nil {
+			r.comfortNoise[channel].Pause()
+		}
+		return 0, true
+	}
+
+	start := replay.Now()
+	denoisedFrame, probability, keepFrame, err := d.denoiser.FilterStream(r.samples, r.suppression.Threshold)
+	if err != nil {
+		return 0, false
+	}
+	if keepFrame && d.secondPass != nil {
+		// the first pass decides what is speech, the second only removes the residual noise
+		if secondFrame, _, _, err := d.secondPass.FilterStream(denoisedFrame, r.suppression.Threshold); err == nil {
+			denoisedFrame = secondFrame
+		}
+	}
+	latency := replay.Since(start)
+	frameLatencyTotal.Add(int64(latency))
+	frameLatencyFrames.Inc()
+	r.stats.Load().RecordFrame(latency, !keepFrame)
+	r.estimator.Observe(r.samples, keepFrame)
+
+	if r.gates != nil {
+		r.gateFrameLocked(channel, frame, denoisedFrame, float32(probability), keepFrame)
+	} else if keepFrame {
+		interleave(frame, denoisedFrame, channel, channels)
+		if r.comfortNoise != nil {
+			r.comfortNoise[channel].Pause()
+		}
+	} else if r.comfortNoise != nil {
+		// replace the noise by comfort noise of the same spectral shape, attenuating it
+		// gates the background on and off around speech
+		r.comfortNoise[channel].Fill(r.samples)
+		for i, sample := range r.samples {
+			frame[i*channels+channel] = int16(min(max(sample*32767, -32768), 32767))
+		}
+	} else {
+		// Apply noise reduction by reducing volume, deeper the more aggressive the filter
+		for i, sample := range r.samples {
+			frame[i*channels+channel] = int16(min(max(sample*32768.0*r.suppression.NoiseGain, -32768), 32767))
+		}
+	}
+	return float32(probability), keepFrame
+}
+
+// musicFrameLocked classifies the frame in r.samples if it is of the first channel and returns whether the
+// stream is music, logging when it switches. Must be called with the lock held.
+func (r *noiseFilterReader) musicFrameLocked(channel int) bool {
+	if r.music == nil {
+		return false
+	}
+	if channel == 0 {
+		if isMusic := r.music.Observe(r.samples); isMusic != r.isMusic {
+			r.isMusic = isMusic
+			r.logger.Debugw("music detection changed", "music", isMusic, "score", r.music.Score())
+		}
+	}
+	if r.isMusic {
+		r.stats.Load().RecordMusicFrame()
+	}
+	return r.isMusic
+}
+
+// gateFrameLocked writes a denoised frame of a channel to the interleaved frame through the noise gate of the
+// channel, which ramps between the denoised speech and the attenuated noise, or the comfort noise replacing it,
+// instead of switching between them. Must be called with the lock held.
+func (r *noiseFilterReader) gateFrameLocked(channel int, frame []int16, denoisedFrame []float32, probability float32, keepFrame bool) {
+	channels := len(r.denoisers)
+	if len(denoisedFrame) != len(r.samples) {
+		denoisedFrame = r.samples
+	}
+
+	var background []float32
+	if r.comfortNoise != nil {
+		if keepFrame {
+			r.comfortNoise[channel].Pause()
+		} else {
+			// learned from the noise before denoising, like comfort noise replacing whole frames
+			copy(r.background, r.samples)
+			r.comfortNoise[channel].Fill(r.background)
+			background = r.background
+		}
+	}
+
+	r.gates[channel].Process(denoisedFrame, background, probability)
+	interleave(frame, denoisedFrame, channel, channels)
+}
+
+// echoCancellersLocked returns the echo cancellers of the channels, nil while the stream has no echo reference.
+// Cancellers are created again when the reference changes. Must be called with the lock held.
+func (r *noiseFilterReader) echoCancellersLocked() []*audio.EchoCanceller {
+	reference := r.echoReference.Load()
+	if reference == nil {
+		r.echoCancellers, r.echoCancelled = nil, nil
+		return nil
+	}
+	if r.echoCancelled != reference || len(r.echoCancellers) != len(r.denoisers) {
+		r.echoCancellers = make([]*audio.EchoCanceller, len(r.denoisers))
+		for i := range r.echoCancellers {
+			r.echoCancellers[i] = audio.NewEchoCanceller(r.config.EchoCancellation, reference)
+		}
+		r.echoCancelled = reference
+	}
+	return r.echoCancellers
+}
+
+// extendersLocked returns the bandwidth extenders of the channels, nil while the stream is not extended.
+// Must be called with the lock held.
+func (r *noiseFilterReader) extendersLocked() []audio.BandwidthExtender {
+	if !r.config.BandwidthExtension.Enabled || !r.bandwidthExtension.Load() || r.codec != mime.MimeTypeOpus || r.extensionFailed {
+		r.extenders = nil
+		return nil
+	}
+	if len(r.extenders) != len(r.denoisers) {
+		extenders := make([]audio.BandwidthExtender, len(r.denoisers))
+		for i := range extenders {
+			var err error
+			if extenders[i], err = audio.NewBandwidthExtender(r.config.BandwidthExtension); err != nil {
+				// not retried, the stream is denoised without extension
+				r.logger.Warnw("bandwidth extension unavailable", err, "model", r.config.BandwidthExtension.Model)
+				r.extensionFailed = true
+				return nil
 			}
 		}
-		r.mu.Unlock()
+		r.extenders = extenders
+	}
+	return r.extenders
+}
+
+// extendFrameLocked reconstructs the upper band of one channel of an interleaved RNNoise frame in place.
+// Must be called with the lock held.
+func (r *noiseFilterReader) extendFrameLocked(channel int, frame []int16, extender audio.BandwidthExtender) {
+	channels := len(r.denoisers)
+	deinterleave(r.samples, frame, channel, channels)
+	extender.Extend(r.samples)
+	interleave(frame, r.samples, channel, channels)
+}
+
+// deinterleave converts one channel of an interleaved int16 frame to the normalized samples of dst
+func deinterleave(dst []float32, frame []int16, channel int, channels int) {
+	if channels == 1 {
+		audio.Int16ToFloat32(dst, frame[:len(dst)])
+		return
+	}
+	for i := range dst {
+		dst[i] = float32(frame[i*channels+channel]) / 32768.0
+	}
+}
+
+// interleave converts normalized samples back into one channel of an interleaved int16 frame, clipping them
+func interleave(frame []int16, samples []float32, channel int, channels int) {
+	if channels == 1 {
+		audio.Float32ToInt16(frame, samples)
+		return
+	}
+	for i, sample := range samples {
+		frame[i*channels+channel] = int16(min(max(sample*32768.0, -32768), 32767))
+	}
+}
 
-		// Convert back to bytes
-		processedFrame := make([]byte, rnnoiseFrameBytes)
-		for i, sample := range samples {
-			int16Val := int16(sample)
-			binary.LittleEndian.PutUint16(processedFrame[i*2:], uint16(int16Val))
+// concealLocked runs audio synthesized for the packets lost ahead of payload through the decoder and the denoisers,
+// so that they continue from it rather than from a hole. Nothing is sent for the lost packets, receivers conceal
+// the loss themselves. Must be called with the lock held.
+func (r *noiseFilterReader) concealLocked(lost int, payload []byte) {
+	result, err := r.concealer.Conceal(r.decoder, r.numChannels(), lost, payload, r.pcm)
+	if err != nil {
+		r.logger.Debugw("could not conceal lost audio", "error", err, "lost", lost)
+	}
+	// the denoisers take whole frames, a partial one is left to the decoder
+	samples := result.Samples() - result.Samples()%rnnoiseFrameSize
+	if samples == 0 {
+		return
+	}
+
+	r.denoisePCMLocked(r.pcm, samples)
+	if r.hasNextTimestamp {
+		r.nextTimestamp += uint32(result.Samples())
+	}
+	plc, fec := result.Durations()
+	prometheus.RecordConcealment("noise_filter", plc, fec)
+}
+
+// resumeLocked prepares the denoisers for audio resuming after a gap in the stream, e. g. the publisher was in DTX or
+// the filter was bypassed. The gap counts as silence towards a due reset, without one the history of the audio
+// before the gap is flushed so that it does not bleed into the frames after it. Must be called with the lock held.
+func (r *noiseFilterReader) resumeLocked(timestamp uint32) {
+	if !r.hasNextTimestamp || r.denoisers == nil {
+		return
+	}
+
+	clockRate := audio.OpusSampleRate
+	if r.codec != mime.MimeTypeOpus {
+		clockRate = audio.G711SampleRate
+	}
+	gap := time.Duration(int32(timestamp-r.nextTimestamp)) * time.Second / time.Duration(clockRate)
+	if gap < silenceGapThreshold {
+		return
+	}
+
+	if r.reset.ObserveSilence(gap) {
+		r.resetDenoiser()
+		return
+	}
+	r.flushDenoisersLocked()
+}
+
+// flushDenoisersLocked feeds silence through the denoisers until the audio they analyze is gone from their history.
+// Must be called with the lock held.
+func (r *noiseFilterReader) flushDenoisersLocked() {
+	for _, d := range r.denoisers {
+		for i := 0; i < rnnoiseHistoryFrames; i++ {
+			clear(r.samples)
+			_, _, _, _ = d.denoiser.FilterStream(r.samples, r.suppression.Threshold)
+			if d.secondPass != nil {
+				clear(r.samples)
+				_, _, _, _ = d.secondPass.FilterStream(r.samples, r.suppression.Threshold)
+			}
 		}
+	}
+}
 
-		processedData = append(processedData, processedFrame...)
+// resetDenoiser replaces the denoisers with fresh instances, dropping state accumulated over a long call.
+// Keeps the current denoisers if new ones cannot be created. Must be called with the lock held.
+func (r *noiseFilterReader) resetDenoiser() {
+	if err := r.newDenoisersLocked(); err != nil {
+		r.logger.Warnw("failed to reset RNNoise denoiser", err)
+		return
+	}
+	r.logger.Debugw("reset RNNoise denoiser", "stats", r.reset.Stats())
+}
 
-		// Remove processed frame from buffer
-		r.buffer = r.buffer[rnnoiseFrameBytes:]
+// numChannels returns the number of channels of the stream, streams without codec parameters are mono
+func (r *noiseFilterReader) numChannels() int {
+	return max(r.channels, 1)
+}
+
+// newDenoisersLocked checks out a denoiser per channel, with one for the second pass if the aggressiveness asks for it.
+// Keeps the current ones on error. Must be called with the lock held.
+func (r *noiseFilterReader) newDenoisersLocked() error {
+	denoisers := make([]channelDenoiser, r.numChannels())
+	for i := range denoisers {
+		var err error
+		denoisers[i].pooled = r.config.ModelPath == ""
+		if denoisers[i].denoiser, err = r.newDenoiser(); err == nil && r.suppression.DoublePass {
+			denoisers[i].secondPass, err = r.newDenoiser()
+		}
+		if err != nil {
+			for _, d := range denoisers {
+				r.releaseDenoiser(d)
+			}
+			return err
+		}
 	}
+	r.setDenoisersLocked(denoisers)
+	return nil
+}
 
-	// Add remaining buffer back to processed data
-	if len(r.buffer) > 0 {
-		processedData = append(processedData, r.buffer...)
-		r.buffer = r.buffer[:0] // Clear buffer but keep capacity
+// newDenoiser creates a denoiser of the configured model, instances of the built in one come from the pool
+func (r *noiseFilterReader) newDenoiser() (*rnnoise.NoiseFilter, error) {
+	if r.config.ModelPath != "" {
+		return rnnoise.NewNoiseFilter(r.config.ModelPath)
 	}
+	return r.instances.get()
+}
+
+// releaseDenoiser returns the instances of the built in model to the pool, others are left to the GC
+func (r *noiseFilterReader) releaseDenoiser(d channelDenoiser) {
+	if d.pooled {
+		r.instances.put(d.denoiser)
+		r.instances.put(d.secondPass)
+	}
+}
+
+// setDenoisersLocked swaps the denoisers, keeping the count of live instances and returning the replaced
+// ones to the pool. Must be called with the lock held.
+func (r *noiseFilterReader) setDenoisersLocked(denoisers []channelDenoiser) {
+	for _, d := range r.denoisers {
+		liveDenoisers.Sub(d.instances())
+		r.releaseDenoiser(d)
+	}
+	for _, d := range denoisers {
+		liveDenoisers.Add(d.instances())
+	}
+	r.denoisers = denoisers
+}
+
+// releaseLocked frees the denoiser and the codec state, both are created again with the next packet.
+// Must be called with the lock held.
+func (r *noiseFilterReader) releaseLocked() {
+	r.setDenoisersLocked(nil)
+	r.comfortNoise = nil
+	r.gates, r.background = nil, nil
+	r.music, r.isMusic = nil, false
+	r.echoCancellers, r.echoCancelled = nil, nil
+	r.extenders = nil
+	audio.DefaultEncoderRegistry.Untrack(r.encoder)
+	r.decoder = nil
+	r.encoder = nil
+	r.encoderBitrate = 0
+	r.hasNextTimestamp = false
+	r.concealer.Reset()
+	r.upsampler = nil
+	r.downsampler = nil
+	r.mem.Set(r.memoryLocked())
+}
+
+// memoryLocked returns the bytes held by the buffers of the stream and by the native state of its
+// denoisers and codecs. Must be called with the lock held.
+func (r *noiseFilterReader) memoryLocked() (int, int) {
+	goBytes := (cap(r.pcm)+cap(r.narrowband))*rnnoiseBytesPerSample + cap(r.samples)*4 + cap(r.payload)
 
-	return processedData
+	nativeBytes := 0
+	for _, d := range r.denoisers {
+		nativeBytes += int(d.instances()) * rnnoiseStateBytes
+	}
+	if r.decoder != nil {
+		nativeBytes += audio.OpusDecoderNativeBytes(r.numChannels())
+	}
+	if r.encoder != nil {
+		nativeBytes += audio.OpusEncoderNativeBytes(r.numChannels())
+	}
+	return goBytes, nativeBytes
+}
+
+// observeMemoryLocked records the memory held by the stream. Must be called with the lock held.
+func (r *noiseFilterReader) observeMemoryLocked() {
+	goBytes, nativeBytes := r.memoryLocked()
+	r.reset.ObserveMemory(goBytes)
+	r.mem.Set(goBytes, nativeBytes)
+}
+
+// setStats swaps the metrics of the stream, releasing the previous ones
+func (r *noiseFilterReader) setStats(stats *prometheus.NoiseFilterStreamStats) {
+	r.stats.Swap(stats).Release()
+}
+
+// close frees the denoiser for good, packets still read afterwards pass through unfiltered
+func (r *noiseFilterReader) close() audio.DenoiserStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.closed && r.stop != nil {
+		close(r.stop)
+	}
+	r.closed = true
+	r.setStats(nil)
+	r.stopDebugDump()
+	r.releaseLocked()
+	r.pcm, r.samples, r.payload, r.narrowband = nil, nil, nil, nil
+	r.mem.Release()
+	return r.reset.Stats()
 }
@@ -0,0 +1,62 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/protocol/logger"
+)
+
+var (
+	debugDumperOnce   sync.Once
+	sharedDebugDumper *audio.DebugDumper
+)
+
+// getDebugDumper returns the node wide dumper, it is created with the configuration of the first caller
+func getDebugDumper(config audio.DebugDumpConfig) *audio.DebugDumper {
+	debugDumperOnce.Do(func() {
+		sharedDebugDumper = audio.NewDebugDumper(config, func(err error) {
+			logger.Warnw("failed to write audio debug dump", err)
+		})
+	})
+	return sharedDebugDumper
+}
+
+// startDebugDump records the start of the stream as the audio of track if debug dumps are enabled
+func (r *noiseFilterReader) startDebugDump(track noiseFilterTrack) {
+	r.mu.Lock()
+	config := r.config.DebugDump
+	r.mu.Unlock()
+	if !config.Enabled {
+		return
+	}
+
+	recording := getDebugDumper(config).NewRecording(fmt.Sprintf("%s_%s", track.room, track.trackID), audio.OpusSampleRate, r.numChannels())
+	if prev := r.debugDump.Swap(recording); prev != nil {
+		prev.Close()
+	}
+	r.logger.Infow("recording audio debug dump", "room", track.room, "trackID", track.trackID, "duration", config.Duration)
+}
+
+// stopDebugDump writes what the recording of the stream holds so far
+func (r *noiseFilterReader) stopDebugDump() {
+	if recording := r.debugDump.Swap(nil); recording != nil {
+		recording.Close()
+	}
+}
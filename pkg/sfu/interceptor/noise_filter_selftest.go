@@ -0,0 +1,130 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+
+	"github.com/zhangzhao-gg/go-rnnoise/rnnoise"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+// RNNoiseLoad creates a denoiser, loading the model
+func RNNoiseLoad() error {
+	_, err := rnnoise.NewNoiseFilter("")
+	return err
+}
+
+// LoadRNNoiseModels creates a denoiser with the configured model file and each named alternative model,
+// so that a missing or corrupt model fails at startup rather than when the first stream selects it
+func LoadRNNoiseModels(config audio.NoiseFilterConfig) error {
+	paths := map[string]string{"model_path": config.ModelPath}
+	for name, path := range config.Models {
+		if path == "" {
+			return fmt.Errorf("noise filter model %q: no model file", name)
+		}
+		paths[fmt.Sprintf("models.%s", name)] = path
+	}
+	for key, path := range paths {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			return fmt.Errorf("noise filter %s: %w", key, err)
+		}
+		denoiser, err := rnnoise.NewNoiseFilter(path)
+		if err != nil {
+			return fmt.Errorf("noise filter %s: loading %s: %w", key, path, err)
+		}
+		if denoiser == nil {
+			return fmt.Errorf("noise filter %s: loading %s: no denoiser created", key, path)
+		}
+	}
+	return nil
+}
+
+// RNNoiseSelfTest creates a denoiser and runs a noisy tone through it, checking that
+// every frame comes back whole and without NaN or out of range samples
+func RNNoiseSelfTest() error {
+	denoiser, err := rnnoise.NewNoiseFilter("")
+	if err != nil {
+		return err
+	}
+	if denoiser == nil {
+		return errors.New("no denoiser created")
+	}
+
+	// deterministic noise, the check must not depend on a random source
+	seed := uint32(1)
+	samples := make([]float32, rnnoiseFrameSize)
+	for frame := 0; frame < 50; frame++ {
+		for i := range samples {
+			seed = seed*1664525 + 1013904223
+			noise := float64(seed>>8)/float64(1<<24) - 0.5
+			t := float64(frame*rnnoiseFrameSize+i) / rnnoiseSampleRate
+			samples[i] = float32(0.25*math.Sin(2*math.Pi*440*t) + 0.05*noise)
+		}
+
+		denoised, _, _, err := denoiser.FilterStream(samples, 0.5)
+		if err != nil {
+			return fmt.Errorf("frame %d: %w", frame, err)
+		}
+		if len(denoised) != rnnoiseFrameSize {
+			return fmt.Errorf("frame %d: denoised %d samples, expected %d", frame, len(denoised), rnnoiseFrameSize)
+		}
+		for _, sample := range denoised {
+			if math.IsNaN(float64(sample)) || math.IsInf(float64(sample), 0) || math.Abs(float64(sample)) > 2 {
+				return fmt.Errorf("frame %d: invalid sample %v", frame, sample)
+			}
+		}
+	}
+	return nil
+}
+
+// RNNoiseFrameDuration is the audio denoised per call of the function returned by RNNoiseBenchmark
+const RNNoiseFrameDuration = rnnoiseFrameDuration
+
+// RNNoiseBenchmark creates a denoiser and returns a function denoising a frame of a noisy tone with it,
+// to measure how many streams a core can carry
+func RNNoiseBenchmark() (func() error, error) {
+	denoiser, err := rnnoise.NewNoiseFilter("")
+	if err != nil {
+		return nil, err
+	}
+	if denoiser == nil {
+		return nil, errors.New("no denoiser created")
+	}
+
+	seed := uint32(1)
+	samples := make([]float32, rnnoiseFrameSize)
+	frame := 0
+	return func() error {
+		for i := range samples {
+			seed = seed*1664525 + 1013904223
+			noise := float64(seed>>8)/float64(1<<24) - 0.5
+			t := float64(frame*rnnoiseFrameSize+i) / rnnoiseSampleRate
+			samples[i] = float32(0.25*math.Sin(2*math.Pi*440*t) + 0.05*noise)
+		}
+		frame++
+
+		_, _, _, err := denoiser.FilterStream(samples, 0.5)
+		return err
+	}, nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/protocol/logger"
+)
+
+// SourceNoiseFilter denoises audio the server originates, e. g. the sources of an audio mix, where it is produced
+// rather than in the stream sent to each subscriber, keeping the cost independent of the number of subscribers
+type SourceNoiseFilter struct {
+	reader *noiseFilterReader
+}
+
+// NewSourceNoiseFilter creates a filter of 48 kHz mono PCM, the denoiser is created with the first frames
+func NewSourceNoiseFilter(config audio.NoiseFilterConfig, logger logger.Logger) *SourceNoiseFilter {
+	return &SourceNoiseFilter{
+		reader: &noiseFilterReader{
+			config:    config,
+			channels:  1,
+			estimator: audio.NewNoiseProfileEstimator(config, nil),
+			reset:     audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+			logger:    logger,
+			bypass:    atomic.NewBool(false),
+			instances: getDenoiserInstances(config.Instances),
+		},
+	}
+}
+
+// Process denoises the whole 10 ms frames of pcm in place, a shorter remainder is left as is.
+// Returns false if the audio passed through unfiltered.
+func (f *SourceNoiseFilter) Process(pcm []int16) bool {
+	if f == nil {
+		return false
+	}
+	return f.reader.processPCM(pcm)
+}
+
+// Close frees the denoiser, audio processed afterwards passes through
+func (f *SourceNoiseFilter) Close() {
+	if f == nil {
+		return
+	}
+	f.reader.close()
+}
@@ -16,14 +16,22 @@
 package interceptor
 
 import (
+	"bytes"
+	"io"
+	"math"
+	"math/rand"
 	"testing"
 
 	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
 	"github.com/livekit/protocol/logger"
 	"github.com/pion/interceptor"
 	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
 )
 
 func TestNoiseFilterFactory(t *testing.T) {
@@ -36,17 +44,15 @@ func TestNoiseFilterFactory(t *testing.T) {
 		{
 			name: "enabled noise filter",
 			config: audio.NoiseFilterConfig{
-				Enabled:    true,
-				Threshold:  0.5,
-				Aggressive: false,
+				Enabled:   true,
+				Threshold: 0.5,
 			},
 		},
 		{
 			name: "disabled noise filter",
 			config: audio.NoiseFilterConfig{
-				Enabled:    false,
-				Threshold:  0.5,
-				Aggressive: false,
+				Enabled:   false,
+				Threshold: 0.5,
 			},
 		},
 	}
@@ -70,9 +76,8 @@ func TestNoiseFilterFactory(t *testing.T) {
 func TestNoiseFilterInterceptor_BindRTCPReader(t *testing.T) {
 	testLogger := logger.GetLogger()
 	config := audio.NoiseFilterConfig{
-		Enabled:    true,
-		Threshold:  0.5,
-		Aggressive: false,
+		Enabled:   true,
+		Threshold: 0.5,
 	}
 
 	factory := NewNoiseFilterFactory(config, testLogger)
@@ -100,9 +105,8 @@ func TestNoiseFilterInterceptor_BindRTCPReader(t *testing.T) {
 func TestNoiseFilterInterceptor_BindLocalStream(t *testing.T) {
 	testLogger := logger.GetLogger()
 	config := audio.NoiseFilterConfig{
-		Enabled:    true,
-		Threshold:  0.5,
-		Aggressive: false,
+		Enabled:   true,
+		Threshold: 0.5,
 	}
 
 	factory := NewNoiseFilterFactory(config, testLogger)
@@ -142,9 +146,8 @@ func TestNoiseFilterInterceptor_BindLocalStream(t *testing.T) {
 func TestNoiseFilterInterceptor_BindRemoteStream(t *testing.T) {
 	testLogger := logger.GetLogger()
 	config := audio.NoiseFilterConfig{
-		Enabled:    true,
-		Threshold:  0.5,
-		Aggressive: false,
+		Enabled:   true,
+		Threshold: 0.5,
 	}
 
 	factory := NewNoiseFilterFactory(config, testLogger)
@@ -179,14 +182,22 @@ func TestNoiseFilterInterceptor_BindRemoteStream(t *testing.T) {
 	nfReader := reader.(*noiseFilterReader)
 	assert.NotNil(t, nfReader.reader)
 	assert.Equal(t, config, nfReader.config)
+	assert.Equal(t, 1, nfReader.numChannels())
+
+	// stereo streams are denoised per channel
+	info.SSRC = 12346
+	info.SDPFmtpLine = "minptime=10;useinbandfec=1;stereo=1"
+	stereoReader := nfInterceptor.BindRemoteStream(info, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return len(b), a, nil
+	}))
+	assert.Equal(t, 2, stereoReader.(*noiseFilterReader).numChannels())
 }
 
 func TestNoiseFilterInterceptor_BindRemoteStream_NonAudio(t *testing.T) {
 	testLogger := logger.GetLogger()
 	config := audio.NoiseFilterConfig{
-		Enabled:    true,
-		Threshold:  0.5,
-		Aggressive: false,
+		Enabled:   true,
+		Threshold: 0.5,
 	}
 
 	factory := NewNoiseFilterFactory(config, testLogger)
@@ -221,9 +232,8 @@ func TestNoiseFilterInterceptor_BindRemoteStream_NonAudio(t *testing.T) {
 func TestNoiseFilterReader_Read(t *testing.T) {
 	testLogger := logger.GetLogger()
 	config := audio.NoiseFilterConfig{
-		Enabled:    true,
-		Threshold:  0.5,
-		Aggressive: false,
+		Enabled:   true,
+		Threshold: 0.5,
 	}
 
 	// Create a mock reader that returns test RTP packets
@@ -254,10 +264,14 @@ func TestNoiseFilterReader_Read(t *testing.T) {
 	})
 
 	reader := &noiseFilterReader{
-		reader: mockReader,
-		config: config,
-		logger: testLogger,
-		buffer: make([]byte, 0, rnnoiseFrameBytes*2),
+		reader:      mockReader,
+		config:      config,
+		logger:      testLogger,
+		estimator:   audio.NewNoiseProfileEstimator(config, nil),
+		reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+		codec:       mime.MimeTypeOpus,
 	}
 
 	// Test reading a packet
@@ -273,13 +287,1001 @@ func TestNoiseFilterReader_Read(t *testing.T) {
 	}
 }
 
+func TestNoiseFilterReader_Read_Opus(t *testing.T) {
+	if !audio.IsOpusCodecAvailable() {
+		t.Skip("opus codec unavailable")
+	}
+
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	require.NoError(t, err)
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	require.NoError(t, err)
+
+	config := audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}
+	pcm := make([]int16, audio.OpusFrameSize)
+	var sequenceNumber uint16
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		for i := range pcm {
+			ts := float64(int(sequenceNumber)*audio.OpusFrameSize+i) / audio.OpusSampleRate
+			pcm[i] = int16(8000*math.Sin(2*math.Pi*440*ts)) + int16(rand.Intn(2000)-1000)
+		}
+		payload := make([]byte, audio.OpusMaxPacketSize)
+		size, err := encoder.Encode(pcm, payload)
+		if err != nil {
+			return 0, a, err
+		}
+
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    111,
+				SSRC:           12345,
+				SequenceNumber: sequenceNumber,
+				Timestamp:      uint32(sequenceNumber) * audio.OpusFrameSize,
+			},
+			Payload: payload[:size],
+		}
+		raw, err := packet.Marshal()
+		if err != nil {
+			return 0, a, err
+		}
+		return copy(b, raw), a, nil
+	})
+
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      config,
+		estimator:   audio.NewNoiseProfileEstimator(config, nil),
+		reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		logger:      logger.GetLogger(),
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+		codec:       mime.MimeTypeOpus,
+	}
+
+	buffer := make([]byte, 1500)
+	out := make([]int16, audio.OpusMaxFrameSize)
+	for i := 0; i < 10; i++ {
+		n, attrs, err := reader.Read(buffer, nil)
+		require.NoError(t, err)
+
+		// denoised packets carry the voice activity of their frames
+		if reader.denoisers != nil {
+			probability, _, ok := VADFromAttributes(attrs)
+			require.True(t, ok)
+			require.GreaterOrEqual(t, probability, float32(0))
+			require.LessOrEqual(t, probability, float32(1))
+		}
+
+		// the filtered payload is still a valid Opus packet of the same duration
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(buffer[:n]))
+		require.Equal(t, uint16(i+1), packet.SequenceNumber)
+		samples, err := decoder.Decode(packet.Payload, out)
+		require.NoError(t, err)
+		require.Equal(t, audio.OpusFrameSize, samples)
+	}
+	if reader.denoisers != nil {
+		require.NotZero(t, reader.reset.Stats().Frames)
+	}
+}
+
+func TestNoiseFilterReader_Read_OpusStereo(t *testing.T) {
+	if !audio.IsOpusCodecAvailable() {
+		t.Skip("opus codec unavailable")
+	}
+
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 2)
+	require.NoError(t, err)
+	// the original and the filtered stream are decoded apart, decoders carry state between packets
+	originalDecoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 2)
+	require.NoError(t, err)
+	filteredDecoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 2)
+	require.NoError(t, err)
+
+	config := audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}
+	// noise on both channels, louder on the right one
+	pcm := make([]int16, 2*audio.OpusFrameSize)
+	var sequenceNumber uint16
+	var originals [][]byte
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		for i := 0; i < audio.OpusFrameSize; i++ {
+			pcm[2*i] = int16(rand.Intn(4000) - 2000)
+			pcm[2*i+1] = int16(rand.Intn(8000) - 4000)
+		}
+		payload := make([]byte, audio.OpusMaxPacketSize)
+		size, err := encoder.Encode(pcm, payload)
+		if err != nil {
+			return 0, a, err
+		}
+		originals = append(originals, payload[:size])
+
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    111,
+				SSRC:           12345,
+				SequenceNumber: sequenceNumber,
+				Timestamp:      uint32(sequenceNumber) * audio.OpusFrameSize,
+			},
+			Payload: payload[:size],
+		}
+		raw, err := packet.Marshal()
+		if err != nil {
+			return 0, a, err
+		}
+		return copy(b, raw), a, nil
+	})
+
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      config,
+		channels:    2,
+		estimator:   audio.NewNoiseProfileEstimator(config, nil),
+		reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		logger:      logger.GetLogger(),
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+		codec:       mime.MimeTypeOpus,
+	}
+
+	energy := func(decoder audio.OpusDecoder, payload []byte) [2]float64 {
+		out := make([]int16, 2*audio.OpusMaxFrameSize)
+		samples, err := decoder.Decode(payload, out)
+		require.NoError(t, err)
+		require.Equal(t, audio.OpusFrameSize, samples)
+
+		var e [2]float64
+		for i := 0; i < samples; i++ {
+			e[0] += float64(out[2*i]) * float64(out[2*i])
+			e[1] += float64(out[2*i+1]) * float64(out[2*i+1])
+		}
+		return e
+	}
+
+	buffer := make([]byte, 1500)
+	var original, filtered [2]float64
+	for i := 0; i < 20; i++ {
+		n, _, err := reader.Read(buffer, nil)
+		require.NoError(t, err)
+		if reader.denoisers == nil {
+			t.Skip("rnnoise unavailable")
+		}
+		require.Len(t, reader.denoisers, 2)
+
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(buffer[:n]))
+		o, f := energy(originalDecoder, originals[i]), energy(filteredDecoder, packet.Payload)
+		// the denoiser needs a few frames to recognize the noise
+		if i < 5 {
+			continue
+		}
+		for channel := range original {
+			original[channel] += o[channel]
+			filtered[channel] += f[channel]
+		}
+	}
+
+	// the noise of each channel is suppressed
+	for channel := range original {
+		require.Less(t, filtered[channel], original[channel]/2, "channel %d", channel)
+	}
+}
+
+// concealingCodec decodes every packet to a 20 ms frame and counts the samples synthesized for lost ones
+type concealingCodec struct {
+	concealed int
+}
+
+func (c *concealingCodec) Decode(payload []byte, pcm []int16) (int, error) {
+	for i := range pcm[:audio.OpusFrameSize] {
+		pcm[i] = 1000
+	}
+	return audio.OpusFrameSize, nil
+}
+
+func (c *concealingCodec) DecodePLC(pcm []int16) error {
+	c.concealed += len(pcm)
+	for i := range pcm {
+		pcm[i] = 500
+	}
+	return nil
+}
+
+func (c *concealingCodec) Encode(pcm []int16, out []byte) (int, error) {
+	return copy(out, []byte{0xf8, 0x01, 0x02, 0x03}), nil
+}
+
+func TestNoiseFilterReader_Read_Concealment(t *testing.T) {
+	read := func(t *testing.T, reader *noiseFilterReader, codec *concealingCodec) {
+		// CELT fullband 20 ms, the third packet is lost
+		var sequenceNumbers []uint16
+		for _, sn := range []uint16{1, 2, 4, 5} {
+			raw, err := (&rtp.Packet{
+				Header: rtp.Header{
+					Version:        2,
+					PayloadType:    111,
+					SSRC:           12345,
+					SequenceNumber: sn,
+					Timestamp:      uint32(sn) * audio.OpusFrameSize,
+				},
+				Payload: []byte{0xf8, 0xff, 0xfe, 0xfd},
+			}).Marshal()
+			require.NoError(t, err)
+			reader.reader = interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+				return copy(b, raw), a, nil
+			})
+
+			buffer := make([]byte, 1500)
+			n, _, err := reader.Read(buffer, nil)
+			require.NoError(t, err)
+			packet := &rtp.Packet{}
+			require.NoError(t, packet.Unmarshal(buffer[:n]))
+			sequenceNumbers = append(sequenceNumbers, packet.SequenceNumber)
+		}
+
+		// the lost frame was synthesized once, nothing was sent in its place
+		require.Equal(t, []uint16{1, 2, 4, 5}, sequenceNumbers)
+		require.Equal(t, audio.OpusFrameSize, codec.concealed)
+		require.True(t, reader.hasNextTimestamp)
+		require.Equal(t, uint32(6*audio.OpusFrameSize), reader.nextTimestamp)
+	}
+
+	newReader := func(config audio.NoiseFilterConfig, codec *concealingCodec) *noiseFilterReader {
+		return &noiseFilterReader{
+			config:      config,
+			concealer:   audio.NewLossConcealer(audio.ConcealmentConfig{Enabled: true}),
+			estimator:   audio.NewNoiseProfileEstimator(config, nil),
+			reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+			logger:      logger.GetLogger(),
+			bypass:      atomic.NewBool(false),
+			payloadType: 111,
+			codec:       mime.MimeTypeOpus,
+			decoder:     codec,
+			encoder:     codec,
+			pcm:         make([]int16, audio.OpusMaxFrameSize),
+			samples:     make([]float32, rnnoiseFrameSize),
+			payload:     make([]byte, audio.OpusMaxPacketSize),
+		}
+	}
+
+	t.Run("scaled", func(t *testing.T) {
+		codec := &concealingCodec{}
+		reader := newReader(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, codec)
+		reader.disabled.Store(true)
+		reader.gainDB.Store(6)
+
+		read(t, reader, codec)
+	})
+
+	t.Run("denoised", func(t *testing.T) {
+		codec := &concealingCodec{}
+		reader := newReader(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, codec)
+		reader.mu.Lock()
+		initialized := reader.initLocked()
+		reader.mu.Unlock()
+		if !initialized {
+			t.Skip("rnnoise unavailable")
+		}
+
+		read(t, reader, codec)
+		// the concealed frames went through the denoisers along with the received ones
+		require.Equal(t, uint64(5*audio.OpusFrameSize/rnnoiseFrameSize), reader.reset.Stats().Frames)
+	})
+}
+
+func TestNoiseFilterReader_Read_G711(t *testing.T) {
+	config := audio.NoiseFilterConfig{
+		Enabled:   true,
+		Threshold: 0.5,
+	}
+
+	sequenceNumber := uint16(0)
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		// 20 ms of a 440 Hz tone at 8 kHz
+		payload := make([]byte, 160)
+		for i := range payload {
+			t := float64(int(sequenceNumber)*len(payload)+i) / audio.G711SampleRate
+			payload[i] = audio.EncodeMuLaw(int16(8000 * math.Sin(2*math.Pi*440*t)))
+		}
+
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    0,
+				SSRC:           12345,
+				SequenceNumber: sequenceNumber,
+				Timestamp:      uint32(sequenceNumber) * 160,
+			},
+			Payload: payload,
+		}
+		raw, err := packet.Marshal()
+		if err != nil {
+			return 0, a, err
+		}
+		return copy(b, raw), a, nil
+	})
+
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      config,
+		estimator:   audio.NewNoiseProfileEstimator(config, nil),
+		reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		logger:      logger.GetLogger(),
+		bypass:      atomic.NewBool(false),
+		payloadType: 0,
+		codec:       mime.MimeTypePCMU,
+	}
+
+	buffer := make([]byte, 1500)
+	for i := 0; i < 10; i++ {
+		n, attrs, err := reader.Read(buffer, nil)
+		require.NoError(t, err)
+
+		if reader.denoisers != nil {
+			_, _, ok := VADFromAttributes(attrs)
+			require.True(t, ok)
+		}
+
+		// resampled back to 8 kHz, the packet keeps its duration
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(buffer[:n]))
+		require.Equal(t, uint16(i+1), packet.SequenceNumber)
+		require.Len(t, packet.Payload, 160)
+	}
+}
+
+// the noise filter runs as a stage of the buffer, on every packet forwarded from it
+func TestNoiseFilter_BufferStages(t *testing.T) {
+	config := audio.NoiseFilterConfig{
+		Enabled:   true,
+		Threshold: 0.5,
+	}
+	factory := NewNoiseFilterFactory(config, logger.GetLogger())
+	defer factory.Close()
+	stages, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+
+	pcmu := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "audio/PCMU", ClockRate: 8000},
+		PayloadType:        0,
+	}
+	audioLevel := webrtc.RTPHeaderExtensionParameter{URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level", ID: 1}
+	buff := buffer.NewBuffer(12345, 100, 100)
+	buff.SetStages(stages, &interceptor.StreamInfo{
+		SSRC:                12345,
+		PayloadType:         0,
+		MimeType:            pcmu.MimeType,
+		ClockRate:           pcmu.ClockRate,
+		RTPHeaderExtensions: []interceptor.RTPHeaderExtension{{URI: audioLevel.URI, ID: audioLevel.ID}},
+	})
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		HeaderExtensions: []webrtc.RTPHeaderExtensionParameter{audioLevel},
+		Codecs:           []webrtc.RTPCodecParameters{pcmu},
+	}, pcmu.RTPCodecCapability, 0))
+	defer buff.Close()
+
+	energy := func(payload []byte) float64 {
+		var e float64
+		for _, b := range payload {
+			sample := float64(audio.DecodeMuLaw(b))
+			e += sample * sample
+		}
+		return e
+	}
+
+	buf := make([]byte, 1500)
+	var original, filtered float64
+	changed := false
+	for i := 1; i <= 50; i++ {
+		payload := make([]byte, 160)
+		for j := range payload {
+			payload[j] = audio.EncodeMuLaw(int16(rand.Intn(8000) - 4000))
+		}
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    0,
+				SSRC:           12345,
+				SequenceNumber: uint16(i),
+				Timestamp:      uint32(i) * 160,
+			},
+			Payload: payload,
+		}
+		raw, err := packet.Marshal()
+		require.NoError(t, err)
+		_, err = buff.Write(raw)
+		require.NoError(t, err)
+
+		ep, err := buff.ReadExtended(buf)
+		require.NoError(t, err)
+		require.Equal(t, uint16(i), ep.Packet.SequenceNumber)
+		require.Len(t, ep.Packet.Payload, len(payload))
+		if !bytes.Equal(payload, ep.Packet.Payload) {
+			changed = true
+		}
+		// the denoiser needs a few frames to recognize the noise
+		if i > 10 {
+			original += energy(payload)
+			filtered += energy(ep.Packet.Payload)
+		}
+	}
+
+	factory.mu.RLock()
+	r := factory.readers[12345]
+	factory.mu.RUnlock()
+	require.NotNil(t, r)
+	r.mu.Lock()
+	denoised := r.denoisers != nil
+	r.mu.Unlock()
+	if !denoised {
+		t.Skip("rnnoise unavailable")
+	}
+	require.True(t, changed)
+	require.Less(t, filtered, original/2)
+}
+
+func TestVADFromAttributes(t *testing.T) {
+	_, _, ok := VADFromAttributes(nil)
+	require.False(t, ok)
+
+	a := interceptor.Attributes{}
+	a.Set(VADProbabilityAttribute, float32(0.8))
+	a.Set(VADIsSpeechAttribute, true)
+	probability, isSpeech, ok := VADFromAttributes(a)
+	require.True(t, ok)
+	require.Equal(t, float32(0.8), probability)
+	require.True(t, isSpeech)
+}
+
+func TestNoiseFilterCodec(t *testing.T) {
+	require.Equal(t, mime.MimeTypeOpus, noiseFilterCodec(&interceptor.StreamInfo{MimeType: "audio/opus", PayloadType: 96}))
+	require.Equal(t, mime.MimeTypeOpus, noiseFilterCodec(&interceptor.StreamInfo{PayloadType: 111}))
+	require.Equal(t, mime.MimeTypePCMU, noiseFilterCodec(&interceptor.StreamInfo{PayloadType: 0}))
+	require.Equal(t, mime.MimeTypeRED, noiseFilterCodec(&interceptor.StreamInfo{MimeType: "audio/red", PayloadType: 63}))
+	require.Equal(t, mime.MimeTypeUnknown, noiseFilterCodec(&interceptor.StreamInfo{PayloadType: 63}))
+}
+
+func TestNoiseFilterReader_Read_TelephoneEvent(t *testing.T) {
+	testLogger := logger.GetLogger()
+	config := audio.NoiseFilterConfig{
+		Enabled:   true,
+		Threshold: 0.5,
+	}
+
+	header := &rtp.Header{
+		Version:        2,
+		PayloadType:    110, // telephone-event/48000
+		SSRC:           12345,
+		Timestamp:      48000,
+		SequenceNumber: 1,
+	}
+	hdr, err := header.Marshal()
+	assert.NoError(t, err)
+	// digit 5, end of event, volume 10, 800 samples
+	original := append(hdr, 5, 0x8a, 0x03, 0x20)
+
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, original), a, nil
+	})
+
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      config,
+		logger:      testLogger,
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+	}
+
+	buffer := make([]byte, 1500)
+	n, _, err := reader.Read(buffer, nil)
+	assert.NoError(t, err)
+	// passed through untouched
+	assert.Equal(t, original, buffer[:n])
+}
+
+func TestNoiseFilterReader_Read_Bypass(t *testing.T) {
+	header := &rtp.Header{
+		Version:        2,
+		PayloadType:    111,
+		SSRC:           12345,
+		Timestamp:      1000,
+		SequenceNumber: 1,
+	}
+	hdr, err := header.Marshal()
+	require.NoError(t, err)
+	original := append(hdr, make([]byte, rnnoiseFrameBytes)...)
+	for i := len(hdr); i < len(original); i++ {
+		original[i] = byte(i % 256)
+	}
+
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return copy(b, original), a, nil
+	})
+
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	factory.SetBypass(true)
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      factory.GetConfig(),
+		logger:      logger.GetLogger(),
+		bypass:      &factory.bypass,
+		payloadType: 111,
+	}
+
+	buffer := make([]byte, 1500)
+	n, _, err := reader.Read(buffer, nil)
+	require.NoError(t, err)
+	// passed through untouched, without creating a denoiser
+	require.Equal(t, original, buffer[:n])
+	require.Nil(t, reader.denoisers)
+}
+
+func TestNoiseFilterReader_Read_Workers(t *testing.T) {
+	const numPackets = 20
+	var sequenceNumber uint16
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		if sequenceNumber == numPackets {
+			return 0, a, io.EOF
+		}
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    110,
+				SSRC:           12345,
+				SequenceNumber: sequenceNumber,
+			},
+			Payload: []byte{5, 0x8a, 0x03, 0x20},
+		}
+		data, err := packet.Marshal()
+		return copy(b, data), a, err
+	})
+
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5},
+		logger:      logger.GetLogger(),
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+		pool:        newDenoiserPool(audio.DenoiserWorkersConfig{Workers: 2, MaxPending: 4}),
+		queue:       make(chan *denoiseJob, 4),
+		stop:        make(chan struct{}),
+	}
+	reader.stream.process = reader.processJob
+
+	// packets come out in order, whether processed by the pool or passed through
+	buffer := make([]byte, 1500)
+	for i := 1; i <= numPackets; i++ {
+		n, _, err := reader.Read(buffer, nil)
+		require.NoError(t, err)
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(buffer[:n]))
+		require.Equal(t, uint16(i), packet.SequenceNumber)
+	}
+	_, _, err := reader.Read(buffer, nil)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestNoiseFilterFactory_Excluded(t *testing.T) {
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	require.False(t, factory.bypass.Load())
+
+	factory.SetExcluded(true)
+	require.True(t, factory.bypass.Load())
+
+	// the room bypass ending does not re-enable filtering of an excluded participant
+	factory.SetBypass(true)
+	factory.SetBypass(false)
+	require.True(t, factory.bypass.Load())
+
+	factory.SetExcluded(false)
+	require.False(t, factory.bypass.Load())
+}
+
+func TestNoiseFilterInterceptor_Teardown(t *testing.T) {
+	baseline := LiveDenoisers()
+
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	readRTP := func(ssrc uint32) interceptor.RTPReader {
+		return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			hdr, err := (&rtp.Header{Version: 2, PayloadType: 111, SSRC: ssrc}).Marshal()
+			if err != nil {
+				return 0, a, err
+			}
+			return copy(b, append(hdr, make([]byte, rnnoiseFrameBytes)...)), a, nil
+		})
+	}
+	ssrcs := []uint32{1111, 2222, 3333}
+	readers := make([]interceptor.RTPReader, 0, len(ssrcs))
+	for _, ssrc := range ssrcs {
+		info := &interceptor.StreamInfo{
+			SSRC:        ssrc,
+			PayloadType: 111,
+			RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+				{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+			},
+		}
+		reader := nfInterceptor.BindRemoteStream(info, readRTP(ssrc))
+		_, _, err := reader.Read(make([]byte, 1500), nil)
+		require.NoError(t, err)
+		readers = append(readers, reader)
+	}
+	require.Len(t, factory.DenoiserStats(), len(ssrcs))
+
+	// RTCP BYE ends the first stream along with its settings, an unknown stream is ignored
+	factory.SetStreamCompatible(ssrcs[0], true)
+	factory.SetStreamGain(ssrcs[0], 6)
+	factory.EndStream(ssrcs[0], "done")
+	factory.EndStream(4444, "done")
+	require.NotContains(t, factory.DenoiserStats(), ssrcs[0])
+	require.Len(t, factory.DenoiserStats(), len(ssrcs)-1)
+	require.False(t, factory.IsStreamCompatible(ssrcs[0]))
+	require.Zero(t, factory.StreamGain(ssrcs[0]))
+
+	// packets still in flight pass through without bringing the denoiser back
+	_, _, err = readers[0].Read(make([]byte, 1500), nil)
+	require.NoError(t, err)
+	require.Nil(t, readers[0].(*noiseFilterReader).denoisers)
+
+	// unbinding ends the second stream, closing the factory the rest
+	nfInterceptor.UnbindRemoteStream(&interceptor.StreamInfo{SSRC: ssrcs[1]})
+	require.Nil(t, readers[1].(*noiseFilterReader).denoisers)
+
+	factory.Close()
+	require.Empty(t, factory.DenoiserStats())
+	require.Nil(t, readers[2].(*noiseFilterReader).denoisers)
+	require.Equal(t, baseline, LiveDenoisers())
+}
+
+func TestNoiseFilterFactory_SetStreamEnabled(t *testing.T) {
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	bind := func(ssrc uint32) *noiseFilterReader {
+		reader := nfInterceptor.BindRemoteStream(&interceptor.StreamInfo{
+			SSRC:        ssrc,
+			PayloadType: 111,
+			RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+				{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+			},
+		}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			return len(b), a, nil
+		}))
+		return reader.(*noiseFilterReader)
+	}
+
+	// switched off ahead of the stream being bound
+	factory.SetStreamEnabled(1111, false)
+	require.False(t, factory.IsStreamEnabled(1111))
+	require.True(t, bind(1111).disabled.Load())
+
+	reader := bind(2222)
+	require.False(t, reader.disabled.Load())
+	factory.SetStreamEnabled(2222, false)
+	require.True(t, reader.disabled.Load())
+	require.Nil(t, reader.denoisers)
+	// other streams are unaffected
+	require.True(t, factory.IsStreamEnabled(3333))
+
+	factory.SetStreamEnabled(2222, true)
+	require.False(t, reader.disabled.Load())
+	require.True(t, factory.IsStreamEnabled(2222))
+}
+
+func TestNoiseFilterFactory_SetStreamGain(t *testing.T) {
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	bind := func(ssrc uint32) *noiseFilterReader {
+		reader := nfInterceptor.BindRemoteStream(&interceptor.StreamInfo{
+			SSRC:        ssrc,
+			PayloadType: 111,
+			RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+				{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+			},
+		}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			return len(b), a, nil
+		}))
+		return reader.(*noiseFilterReader)
+	}
+
+	// set ahead of the stream being bound
+	factory.SetStreamGain(1111, -6)
+	require.Equal(t, -6.0, factory.StreamGain(1111))
+	require.Equal(t, -6.0, bind(1111).gainDB.Load())
+
+	// a stream not denoised stays active while it is scaled
+	reader := bind(2222)
+	factory.SetStreamEnabled(2222, false)
+	require.False(t, reader.isActive())
+	factory.SetStreamGain(2222, 3)
+	require.True(t, reader.isActive())
+	require.False(t, reader.isDenoising())
+
+	factory.SetStreamGain(2222, 0)
+	require.False(t, reader.isActive())
+	require.Zero(t, factory.StreamGain(2222))
+}
+
+func TestNoiseFilterFactory_UpdateConfig(t *testing.T) {
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	reader := nfInterceptor.BindRemoteStream(&interceptor.StreamInfo{
+		SSRC:        1111,
+		PayloadType: 111,
+		RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+			{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+		},
+	}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		return len(b), a, nil
+	})).(*noiseFilterReader)
+	require.True(t, reader.isDenoising())
+
+	// the bound stream follows the new settings
+	factory.UpdateConfig(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.8})
+	require.Equal(t, float32(0.8), factory.GetConfig().Threshold)
+	require.Equal(t, float32(0.8), reader.config.Threshold)
+	require.Nil(t, reader.denoisers)
+
+	factory.UpdateConfig(audio.NoiseFilterConfig{Threshold: 0.8})
+	require.False(t, reader.isDenoising())
+
+	factory.UpdateConfig(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.8})
+	require.True(t, reader.isDenoising())
+}
+
+func TestNoiseFilterFactory_SetStreamCompatible(t *testing.T) {
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	bind := func(ssrc uint32) *noiseFilterReader {
+		reader := nfInterceptor.BindRemoteStream(&interceptor.StreamInfo{
+			SSRC:        ssrc,
+			PayloadType: 111,
+			RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+				{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+			},
+		}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			return len(b), a, nil
+		}))
+		return reader.(*noiseFilterReader)
+	}
+
+	// switched on ahead of the stream being bound
+	factory.SetStreamCompatible(1111, true)
+	require.True(t, factory.IsStreamCompatible(1111))
+	require.True(t, bind(1111).compatible.Load())
+
+	reader := bind(2222)
+	require.False(t, reader.compatible.Load())
+	factory.SetStreamCompatible(2222, true)
+	require.True(t, reader.compatible.Load())
+	require.False(t, factory.IsStreamCompatible(3333))
+
+	factory.SetStreamCompatible(2222, false)
+	require.False(t, reader.compatible.Load())
+	require.False(t, factory.IsStreamCompatible(2222))
+}
+
+func TestNoiseFilterFactory_SetStreamEchoReference(t *testing.T) {
+	factory := NewNoiseFilterFactory(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	bind := func(ssrc uint32) *noiseFilterReader {
+		reader := nfInterceptor.BindRemoteStream(&interceptor.StreamInfo{
+			SSRC:        ssrc,
+			PayloadType: 111,
+			RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+				{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+			},
+		}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			return len(b), a, nil
+		}))
+		return reader.(*noiseFilterReader)
+	}
+
+	reference := audio.NewEchoReference(audio.DefaultEchoCancellationConfig)
+
+	// set ahead of the stream being bound
+	factory.SetStreamEchoReference(1111, reference)
+	require.Same(t, reference, bind(1111).echoReference.Load())
+
+	reader := bind(2222)
+	require.Nil(t, reader.echoReference.Load())
+	factory.SetStreamEchoReference(2222, reference)
+	require.Same(t, reference, reader.echoReference.Load())
+
+	// cancellers follow the reference
+	reader.denoisers = make([]channelDenoiser, 1)
+	cancellers := reader.echoCancellersLocked()
+	require.Len(t, cancellers, 1)
+	require.Equal(t, cancellers, reader.echoCancellersLocked())
+
+	factory.SetStreamEchoReference(2222, nil)
+	require.Nil(t, reader.echoReference.Load())
+	require.Nil(t, reader.echoCancellersLocked())
+}
+
+func TestNoiseFilterFactory_SetStreamBandwidthExtension(t *testing.T) {
+	config := audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5, BandwidthExtension: audio.DefaultBandwidthExtensionConfig}
+	config.BandwidthExtension.Enabled = true
+	factory := NewNoiseFilterFactory(config, logger.GetLogger())
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	nfInterceptor := i.(*NoiseFilterInterceptor)
+
+	bind := func(ssrc uint32, payloadType uint8) *noiseFilterReader {
+		reader := nfInterceptor.BindRemoteStream(&interceptor.StreamInfo{
+			SSRC:        ssrc,
+			PayloadType: payloadType,
+			RTPHeaderExtensions: []interceptor.RTPHeaderExtension{
+				{ID: 1, URI: "urn:ietf:params:rtp-hdrext:ssrc-audio-level"},
+			},
+		}, interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			return len(b), a, nil
+		}))
+		return reader.(*noiseFilterReader)
+	}
+
+	// set ahead of the stream being bound
+	factory.SetStreamBandwidthExtension(1111, true)
+	reader := bind(1111, 111)
+	require.True(t, reader.bandwidthExtension.Load())
+	reader.denoisers = make([]channelDenoiser, 1)
+	require.Len(t, reader.extendersLocked(), 1)
+
+	factory.SetStreamBandwidthExtension(1111, false)
+	require.False(t, reader.bandwidthExtension.Load())
+	require.Nil(t, reader.extendersLocked())
+
+	// G.711 cannot carry the upper band
+	factory.SetStreamBandwidthExtension(2222, true)
+	reader = bind(2222, 0)
+	reader.denoisers = make([]channelDenoiser, 1)
+	require.Nil(t, reader.extendersLocked())
+}
+
+func TestNoiseFilterReader_Read_OpusCompatible(t *testing.T) {
+	if !audio.IsOpusCodecAvailable() {
+		t.Skip("opus codec unavailable")
+	}
+
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	require.NoError(t, err)
+	decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+	require.NoError(t, err)
+
+	config := audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}
+	pcm := make([]int16, audio.OpusFrameSize)
+	var sequenceNumber uint16
+	var originalSize int
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		for i := range pcm {
+			pcm[i] = int16(rand.Intn(16000) - 8000)
+		}
+		payload := make([]byte, audio.OpusMaxPacketSize)
+		size, err := encoder.Encode(pcm, payload)
+		if err != nil {
+			return 0, a, err
+		}
+		originalSize = size
+
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    111,
+				SSRC:           12345,
+				SequenceNumber: sequenceNumber,
+				Timestamp:      uint32(sequenceNumber) * audio.OpusFrameSize,
+			},
+			Payload: payload[:size],
+		}
+		raw, err := packet.Marshal()
+		if err != nil {
+			return 0, a, err
+		}
+		return copy(b, raw), a, nil
+	})
+
+	reader := &noiseFilterReader{
+		reader:      mockReader,
+		config:      config,
+		estimator:   audio.NewNoiseProfileEstimator(config, nil),
+		reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		logger:      logger.GetLogger(),
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+		codec:       mime.MimeTypeOpus,
+	}
+	reader.compatible.Store(true)
+
+	buffer := make([]byte, 1500)
+	out := make([]int16, audio.OpusMaxFrameSize)
+	for i := 0; i < 20; i++ {
+		n, _, err := reader.Read(buffer, nil)
+		require.NoError(t, err)
+
+		// same duration, never larger than the packet it replaces
+		packet := &rtp.Packet{}
+		require.NoError(t, packet.Unmarshal(buffer[:n]))
+		require.LessOrEqual(t, len(packet.Payload), originalSize)
+		samples, err := decoder.Decode(packet.Payload, out)
+		require.NoError(t, err)
+		require.Equal(t, audio.OpusFrameSize, samples)
+	}
+	if reader.encoder != nil {
+		require.NotZero(t, reader.encoderBitrate)
+	}
+
+	// the encoder chooses its bitrate again once no subscriber needs compatibility
+	reader.compatible.Store(false)
+	_, _, err = reader.Read(buffer, nil)
+	require.NoError(t, err)
+	require.Zero(t, reader.encoderBitrate)
+}
+
 // Benchmark tests for performance
+func TestSourceNoiseFilter(t *testing.T) {
+	filter := NewSourceNoiseFilter(audio.NoiseFilterConfig{Enabled: true, Threshold: 0.5}, logger.GetLogger())
+	defer filter.Close()
+
+	var original, filtered float64
+	pcm := make([]int16, 2*rnnoiseFrameSize)
+	for i := 0; i < 20; i++ {
+		for j := range pcm {
+			pcm[j] = int16(rand.Intn(8000) - 4000)
+		}
+		energy := 0.0
+		for _, sample := range pcm {
+			energy += float64(sample) * float64(sample)
+		}
+		if !filter.Process(pcm) {
+			t.Skip("rnnoise unavailable")
+		}
+		// the denoiser needs a few frames to recognize the noise
+		if i < 5 {
+			continue
+		}
+		original += energy
+		for _, sample := range pcm {
+			filtered += float64(sample) * float64(sample)
+		}
+	}
+	require.Less(t, filtered, original/2)
+
+	// closed, audio passes through
+	filter.Close()
+	require.False(t, filter.Process(pcm))
+
+	// nothing to denoise short of a frame
+	require.False(t, NewSourceNoiseFilter(audio.NoiseFilterConfig{Enabled: true}, logger.GetLogger()).Process(make([]int16, rnnoiseFrameSize-1)))
+}
+
 func BenchmarkNoiseFilterReader_Read(b *testing.B) {
 	testLogger := logger.GetLogger()
 	config := audio.NoiseFilterConfig{
-		Enabled:    true,
-		Threshold:  0.5,
-		Aggressive: false,
+		Enabled:   true,
+		Threshold: 0.5,
 	}
 
 	// Create a mock reader that returns test RTP packets
@@ -309,10 +1311,14 @@ func BenchmarkNoiseFilterReader_Read(b *testing.B) {
 	})
 
 	reader := &noiseFilterReader{
-		reader: mockReader,
-		config: config,
-		logger: testLogger,
-		buffer: make([]byte, 0, rnnoiseFrameBytes*2),
+		reader:      mockReader,
+		config:      config,
+		logger:      testLogger,
+		estimator:   audio.NewNoiseProfileEstimator(config, nil),
+		reset:       audio.NewDenoiserResetScheduler(config.Reset, rnnoiseFrameDuration),
+		bypass:      atomic.NewBool(false),
+		payloadType: 111,
+		codec:       mime.MimeTypeOpus,
 	}
 
 	buffer := make([]byte, 1500)
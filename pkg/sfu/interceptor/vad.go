@@ -0,0 +1,413 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+
+	"github.com/livekit/livekit-server/pkg/replay"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/memtrack"
+	"github.com/livekit/livekit-server/pkg/sfu/mime"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	// streams that stop sending, e. g. muted or in DTX, are treated as silent after this long without packets
+	vadSilenceInterval = 100 * time.Millisecond
+)
+
+// VADFactory creates interceptors following the voice activity of received audio streams without altering
+// them, and reports tracks starting and stopping to carry speech. Packets the noise filter denoised carry
+// its RNNoise decisions, which are reused; other packets are decoded and classified by their level, so
+// detection works with the noise filter disabled or bypassed. With utterances enabled, speech is also
+// segmented into utterances on the RTP timeline of the stream for agent turn-taking.
+type VADFactory struct {
+	config audio.VADConfig
+	logger logger.Logger
+
+	mu               sync.Mutex
+	readers          map[uint32]*vadReader
+	tracks           map[uint32]livekit.TrackID
+	onSpeakingChange func(trackID livekit.TrackID, transition *audio.SpeakingTransition)
+	onUtterance      func(trackID livekit.TrackID, event *audio.UtteranceEvent)
+	mem              *memtrack.Scope
+	closed           core.Fuse
+}
+
+func NewVADFactory(config audio.VADConfig, logger logger.Logger) *VADFactory {
+	f := &VADFactory{
+		config:  config,
+		logger:  logger,
+		readers: make(map[uint32]*vadReader),
+		tracks:  make(map[uint32]livekit.TrackID),
+		mem:     memtrack.DefaultRegistry.NewScope("vad"),
+	}
+	go f.silenceWorker()
+	return f
+}
+
+// OnSpeakingChange sets the callback invoked when a track starts or stops carrying speech,
+// only for streams whose track is known
+func (f *VADFactory) OnSpeakingChange(fn func(trackID livekit.TrackID, transition *audio.SpeakingTransition)) {
+	f.mu.Lock()
+	f.onSpeakingChange = fn
+	f.mu.Unlock()
+}
+
+// OnUtterance sets the callback invoked when an utterance of a track starts or ends,
+// only for streams whose track is known
+func (f *VADFactory) OnUtterance(fn func(trackID livekit.TrackID, event *audio.UtteranceEvent)) {
+	f.mu.Lock()
+	f.onUtterance = fn
+	f.mu.Unlock()
+}
+
+// SetStreamTrack sets the track a stream belongs to, also ahead of the stream being bound
+func (f *VADFactory) SetStreamTrack(ssrc uint32, trackID livekit.TrackID) {
+	f.mu.Lock()
+	f.tracks[ssrc] = trackID
+	f.mu.Unlock()
+}
+
+// Close stops following all streams, tracks still speaking are reported to have stopped
+func (f *VADFactory) Close() {
+	f.closed.Break()
+	f.mem.End()
+
+	f.mu.Lock()
+	readers := f.readers
+	f.readers = make(map[uint32]*vadReader)
+	f.mu.Unlock()
+
+	for _, r := range readers {
+		transition, utterance := r.close()
+		f.notify(r, transition)
+		f.notifyUtterance(r, utterance)
+	}
+}
+
+func (f *VADFactory) NewInterceptor(id string) (interceptor.Interceptor, error) {
+	return &VADInterceptor{
+		factory: f,
+		logger:  f.logger.WithValues("interceptor", "vad", "id", id),
+	}, nil
+}
+
+func (f *VADFactory) addReader(ssrc uint32, r *vadReader) {
+	f.mu.Lock()
+	f.readers[ssrc] = r
+	f.mu.Unlock()
+}
+
+func (f *VADFactory) removeReader(ssrc uint32) *vadReader {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	r := f.readers[ssrc]
+	delete(f.readers, ssrc)
+	delete(f.tracks, ssrc)
+	return r
+}
+
+// notify reports a transition of the stream of r, starts only once its track is known and stops only
+// of reported starts
+func (f *VADFactory) notify(r *vadReader, transition *audio.SpeakingTransition) {
+	if transition == nil {
+		return
+	}
+
+	f.mu.Lock()
+	trackID := f.tracks[r.ssrc]
+	onSpeakingChange := f.onSpeakingChange
+	f.mu.Unlock()
+
+	if trackID = r.report(transition.Speaking, trackID); trackID != "" && onSpeakingChange != nil {
+		onSpeakingChange(trackID, transition)
+	}
+}
+
+// notifyUtterance reports an utterance of the stream of r, like notify starts only once the track is known
+// and ends only of reported starts
+func (f *VADFactory) notifyUtterance(r *vadReader, event *audio.UtteranceEvent) {
+	if event == nil {
+		return
+	}
+
+	f.mu.Lock()
+	trackID := f.tracks[r.ssrc]
+	onUtterance := f.onUtterance
+	f.mu.Unlock()
+
+	if trackID = r.reportUtterance(event.Started, trackID); trackID != "" && onUtterance != nil {
+		onUtterance(trackID, event)
+	}
+}
+
+// silenceWorker ends the speech of streams that stopped sending packets
+func (f *VADFactory) silenceWorker() {
+	ticker := replay.NewTicker(vadSilenceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.closed.Watch():
+			return
+
+		case now := <-ticker.C():
+			f.mu.Lock()
+			readers := make([]*vadReader, 0, len(f.readers))
+			for _, r := range f.readers {
+				readers = append(readers, r)
+			}
+			f.mu.Unlock()
+
+			for _, r := range readers {
+				transition, utterance := r.observeSilence(now)
+				f.notify(r, transition)
+				f.notifyUtterance(r, utterance)
+			}
+		}
+	}
+}
+
+// --------------------------------------
+
+type VADInterceptor struct {
+	interceptor.NoOp
+	factory *VADFactory
+	logger  logger.Logger
+}
+
+// BindRemoteStream follows the voice activity of audio streams of the codecs the noise filter handles
+func (v *VADInterceptor) BindRemoteStream(info *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	codec := noiseFilterCodec(info)
+	switch codec {
+	case mime.MimeTypeOpus, mime.MimeTypePCMU, mime.MimeTypePCMA:
+	default:
+		return reader
+	}
+
+	r := &vadReader{
+		reader:      reader,
+		factory:     v.factory,
+		ssrc:        info.SSRC,
+		mem:         v.factory.mem.Track(strconv.FormatUint(uint64(info.SSRC), 10)),
+		logger:      v.logger.WithValues("ssrc", info.SSRC),
+		payloadType: info.PayloadType,
+		codec:       codec,
+		vad:         audio.NewEnergyVAD(v.factory.config),
+		detector:    audio.NewSpeakingDetector(v.factory.config),
+	}
+	if config := v.factory.config; config.Utterance.Enabled {
+		r.utterance = audio.NewUtteranceDetector(config.Utterance, config.MinSpeech, info.ClockRate)
+	}
+	v.factory.addReader(info.SSRC, r)
+	return r
+}
+
+func (v *VADInterceptor) UnbindRemoteStream(info *interceptor.StreamInfo) {
+	if r := v.factory.removeReader(info.SSRC); r != nil {
+		transition, utterance := r.close()
+		v.factory.notify(r, transition)
+		v.factory.notifyUtterance(r, utterance)
+	}
+}
+
+// --------------------------------------
+
+type vadReader struct {
+	reader  interceptor.RTPReader
+	factory *VADFactory
+	ssrc    uint32
+	mem     *memtrack.Instance
+	logger  logger.Logger
+
+	payloadType uint8
+	codec       mime.MimeType
+
+	mu         sync.Mutex
+	closed     bool
+	vad        *audio.EnergyVAD
+	detector   *audio.SpeakingDetector
+	lastPacket time.Time
+	// track the current speech was reported for, empty if it was not
+	reportedTrack livekit.TrackID
+	// nil unless utterances are enabled
+	utterance *audio.UtteranceDetector
+	// track the current utterance was reported for, empty if it was not
+	utteranceTrack livekit.TrackID
+
+	// nil until the first Opus packet without voice activity from the noise filter
+	decoder audio.OpusDecoder
+	pcm     []int16
+}
+
+func (r *vadReader) Read(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+	n, a, err := r.reader.Read(b, a)
+	if err != nil || n == 0 {
+		return n, a, err
+	}
+
+	transition, utterances := r.observe(b[:n], a)
+	r.factory.notify(r, transition)
+	for _, utterance := range utterances {
+		r.factory.notifyUtterance(r, utterance)
+	}
+	return n, a, err
+}
+
+func (r *vadReader) observe(b []byte, a interceptor.Attributes) (*audio.SpeakingTransition, []*audio.UtteranceEvent) {
+	packet := &rtp.Packet{}
+	if err := packet.Unmarshal(b); err != nil || packet.PayloadType != r.payloadType {
+		return nil, nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil, nil
+	}
+
+	isSpeech, ok := r.isSpeechLocked(packet.Payload, a)
+	if !ok {
+		return nil, nil
+	}
+	now := replay.Now()
+	r.lastPacket = now
+	return r.detector.Observe(now, isSpeech), r.utterance.Observe(now, packet.Timestamp, r.packetDuration(packet.Payload), isSpeech)
+}
+
+// packetDuration returns the duration of audio in the payload, zero if unknown
+func (r *vadReader) packetDuration(payload []byte) time.Duration {
+	switch r.codec {
+	case mime.MimeTypeOpus:
+		if toc, ok := audio.ParseOpusTOC(payload); ok {
+			return toc.Duration()
+		}
+	case mime.MimeTypePCMU, mime.MimeTypePCMA:
+		return time.Duration(len(payload)) * time.Second / audio.G711SampleRate
+	}
+	return 0
+}
+
+// isSpeechLocked classifies the payload, returns false if it cannot be. Must be called with the lock held.
+func (r *vadReader) isSpeechLocked(payload []byte, a interceptor.Attributes) (bool, bool) {
+	if _, isSpeech, ok := VADFromAttributes(a); ok {
+		return isSpeech, true
+	}
+
+	switch r.codec {
+	case mime.MimeTypeOpus:
+		if audio.IsOpusDTX(payload) {
+			return false, true
+		}
+		if r.decoder == nil {
+			decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+			if err != nil {
+				// not retried, only packets denoised by the noise filter are classified
+				r.logger.Warnw("opus codec unavailable, voice activity of undenoised audio not detected", err)
+				r.codec = mime.MimeTypeUnknown
+				return false, false
+			}
+			r.decoder = decoder
+			r.pcm = make([]int16, audio.OpusMaxFrameSize)
+			r.mem.Set(cap(r.pcm)*2, audio.OpusDecoderNativeBytes(1))
+		}
+		samples, err := r.decoder.Decode(payload, r.pcm)
+		if err != nil {
+			return false, false
+		}
+		return r.vad.IsSpeech(r.pcm[:samples], audio.OpusSampleRate), true
+
+	case mime.MimeTypePCMU, mime.MimeTypePCMA:
+		expand := audio.DecodeMuLaw
+		if r.codec == mime.MimeTypePCMA {
+			expand = audio.DecodeALaw
+		}
+		r.pcm = r.pcm[:0]
+		for _, b := range payload {
+			r.pcm = append(r.pcm, expand(b))
+		}
+		r.mem.Set(cap(r.pcm)*2, 0)
+		return r.vad.IsSpeech(r.pcm, audio.G711SampleRate), true
+	}
+	return false, false
+}
+
+// observeSilence ends speech and the utterance of a stream without packets since the last check
+func (r *vadReader) observeSilence(now time.Time) (*audio.SpeakingTransition, *audio.UtteranceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed || now.Sub(r.lastPacket) < vadSilenceInterval {
+		return nil, nil
+	}
+
+	var transition *audio.SpeakingTransition
+	if r.detector.IsSpeaking() {
+		transition = r.detector.Observe(now, false)
+	}
+	return transition, r.utterance.Expire(now)
+}
+
+// report returns the track a transition is reported for, empty if it is not. Speech starting is reported
+// for trackID, speech ending for the track its start was reported for.
+func (r *vadReader) report(speaking bool, trackID livekit.TrackID) livekit.TrackID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if speaking {
+		r.reportedTrack = trackID
+		return trackID
+	}
+	reported := r.reportedTrack
+	r.reportedTrack = ""
+	return reported
+}
+
+// reportUtterance returns the track an utterance event is reported for, empty if it is not, like report
+func (r *vadReader) reportUtterance(started bool, trackID livekit.TrackID) livekit.TrackID {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if started {
+		r.utteranceTrack = trackID
+		return trackID
+	}
+	reported := r.utteranceTrack
+	r.utteranceTrack = ""
+	return reported
+}
+
+// close ends detection, returns the end of speech and of the utterance still going on
+func (r *vadReader) close() (*audio.SpeakingTransition, *audio.UtteranceEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.closed = true
+	r.decoder = nil
+	r.mem.Release()
+	return r.detector.Close(), r.utterance.Close()
+}
@@ -0,0 +1,188 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package interceptor
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+	"github.com/livekit/livekit-server/pkg/sfu/buffer"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVADInterceptor(t *testing.T) {
+	config := audio.DefaultVADConfig
+	config.Enabled = true
+	config.MinSpeech = 0
+	config.Hangover = time.Millisecond
+	factory := NewVADFactory(config, logger.GetLogger())
+	defer factory.Close()
+
+	type change struct {
+		trackID  livekit.TrackID
+		speaking bool
+	}
+	var changes []change
+	factory.OnSpeakingChange(func(trackID livekit.TrackID, transition *audio.SpeakingTransition) {
+		changes = append(changes, change{trackID, transition.Speaking})
+	})
+
+	// 20 ms of PCMU, a tone or silence
+	var sequenceNumber uint16
+	var loud bool
+	var attrs interceptor.Attributes
+	mockReader := interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		payload := make([]byte, 160)
+		for i := range payload {
+			var sample int16
+			if loud {
+				sample = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/8000))
+			}
+			payload[i] = audio.EncodeMuLaw(sample)
+		}
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header:  rtp.Header{Version: 2, PayloadType: 0, SequenceNumber: sequenceNumber, SSRC: 1111},
+			Payload: payload,
+		}
+		raw, err := packet.Marshal()
+		require.NoError(t, err)
+		return copy(b, raw), attrs, nil
+	})
+
+	i, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+	reader := i.BindRemoteStream(&interceptor.StreamInfo{SSRC: 1111, PayloadType: 0}, mockReader)
+
+	read := func(speech bool) {
+		loud = speech
+		time.Sleep(2 * time.Millisecond)
+		_, _, err := reader.Read(make([]byte, 1500), nil)
+		require.NoError(t, err)
+	}
+
+	// speech before the track is known is not reported, nor is its end
+	read(false)
+	read(true)
+	read(false)
+	require.Empty(t, changes)
+
+	factory.SetStreamTrack(1111, "TR_1")
+	read(true)
+	read(true)
+	read(false)
+	require.Equal(t, []change{{"TR_1", true}, {"TR_1", false}}, changes)
+
+	// the decision of the noise filter wins over the level
+	changes = nil
+	attrs = interceptor.Attributes{VADProbabilityAttribute: float32(0.9), VADIsSpeechAttribute: true}
+	read(false)
+	require.Equal(t, []change{{"TR_1", true}}, changes)
+
+	// unbinding ends the speech
+	attrs = nil
+	i.UnbindRemoteStream(&interceptor.StreamInfo{SSRC: 1111})
+	require.Equal(t, []change{{"TR_1", true}, {"TR_1", false}}, changes)
+}
+
+func TestVAD_BufferStages(t *testing.T) {
+	config := audio.DefaultVADConfig
+	config.Enabled = true
+	config.MinSpeech = 0
+	config.Hangover = time.Millisecond
+	factory := NewVADFactory(config, logger.GetLogger())
+	defer factory.Close()
+	stages, err := factory.NewInterceptor("")
+	require.NoError(t, err)
+
+	var changes []bool
+	factory.OnSpeakingChange(func(trackID livekit.TrackID, transition *audio.SpeakingTransition) {
+		require.Equal(t, livekit.TrackID("TR_1"), trackID)
+		changes = append(changes, transition.Speaking)
+	})
+	factory.SetStreamTrack(2222, "TR_1")
+
+	pcmu := webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: "audio/PCMU", ClockRate: 8000},
+		PayloadType:        0,
+	}
+	buff := buffer.NewBuffer(2222, 100, 100)
+	buff.SetStages(stages, &interceptor.StreamInfo{
+		SSRC:        2222,
+		PayloadType: 0,
+		MimeType:    pcmu.MimeType,
+		ClockRate:   pcmu.ClockRate,
+	})
+	require.NoError(t, buff.Bind(webrtc.RTPParameters{
+		Codecs: []webrtc.RTPCodecParameters{pcmu},
+	}, pcmu.RTPCodecCapability, 0))
+	defer buff.Close()
+
+	// packets written to the buffer are forwarded as they pass the detector
+	buf := make([]byte, 1500)
+	var sequenceNumber uint16
+	write := func(speech bool) {
+		payload := make([]byte, 160)
+		for i := range payload {
+			var sample int16
+			if speech {
+				sample = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/8000))
+			}
+			payload[i] = audio.EncodeMuLaw(sample)
+		}
+		sequenceNumber++
+		packet := &rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				PayloadType:    0,
+				SSRC:           2222,
+				SequenceNumber: sequenceNumber,
+				Timestamp:      uint32(sequenceNumber) * 160,
+			},
+			Payload: payload,
+		}
+		raw, err := packet.Marshal()
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+		_, err = buff.Write(raw)
+		require.NoError(t, err)
+
+		ep, err := buff.ReadExtended(buf)
+		require.NoError(t, err)
+		require.Equal(t, sequenceNumber, ep.Packet.SequenceNumber)
+		require.Equal(t, payload, ep.Packet.Payload)
+	}
+
+	write(false)
+	require.Empty(t, changes)
+	write(true)
+	write(true)
+	write(false)
+	require.Equal(t, []bool{true, false}, changes)
+
+	// closing the buffer unbinds the stream, a speech in progress ends
+	write(true)
+	require.Equal(t, []bool{true, false, true}, changes)
+	buff.Close()
+	require.Equal(t, []bool{true, false, true, false}, changes)
+}
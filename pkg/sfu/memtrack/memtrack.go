@@ -0,0 +1,281 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package memtrack accounts the memory held by the instances of DSP stages, e. g. the denoisers and codecs
+// of the noise filter, per stream. Native state allocated through cgo is invisible to the Go heap profiler,
+// stages report it along with their Go buffers. Instances still registered well after their stream ended
+// are flagged as leaked.
+package memtrack
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+)
+
+// Config exports the memory held per stage and checks for leaked instances
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// time an instance may stay registered after its stream ended before it is flagged as leaked
+	LeakGrace time.Duration `yaml:"leak_grace,omitempty"`
+	// how often usage is exported and instances are checked
+	CheckInterval time.Duration `yaml:"check_interval,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		LeakGrace:     30 * time.Second,
+		CheckInterval: 10 * time.Second,
+	}
+)
+
+// Usage is the memory held by the instances of a stage
+type Usage struct {
+	Instances   int   `json:"instances"`
+	GoBytes     int64 `json:"go_bytes"`
+	NativeBytes int64 `json:"native_bytes"`
+	// instances flagged as leaked, included in the above
+	Leaked int `json:"leaked"`
+}
+
+// Leak is an instance still registered after its stream ended
+type Leak struct {
+	Stage       string        `json:"stage"`
+	Stream      string        `json:"stream"`
+	Age         time.Duration `json:"age"`
+	SinceEnd    time.Duration `json:"since_end"`
+	GoBytes     int64         `json:"go_bytes"`
+	NativeBytes int64         `json:"native_bytes"`
+	// flagged by this check, earlier checks already returned the instance
+	New bool `json:"-"`
+}
+
+// --------------------------------------
+
+// Registry holds the instances of all stages
+type Registry struct {
+	lock      sync.Mutex
+	instances map[*Instance]struct{}
+	scopes    map[string]*Scope
+}
+
+// DefaultRegistry is the registry of the stages of the server
+var DefaultRegistry = NewRegistry()
+
+func NewRegistry() *Registry {
+	return &Registry{
+		instances: make(map[*Instance]struct{}),
+		scopes:    make(map[string]*Scope),
+	}
+}
+
+// NewScope creates a scope for the instances of a stage belonging to one owner, e. g. the streams of a participant
+func (r *Registry) NewScope(stage string) *Scope {
+	return &Scope{
+		registry: r,
+		stage:    stage,
+	}
+}
+
+// StageScope returns the scope shared by all instances of a stage that have no owner, whose
+// instances end their streams themselves. It never ends.
+func (r *Registry) StageScope(stage string) *Scope {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s, ok := r.scopes[stage]
+	if !ok {
+		s = r.NewScope(stage)
+		r.scopes[stage] = s
+	}
+	return s
+}
+
+// Usage returns the memory held per stage
+func (r *Registry) Usage() map[string]Usage {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	usage := make(map[string]Usage)
+	for i := range r.instances {
+		u := usage[i.scope.stage]
+		u.Instances++
+		u.GoBytes += i.goBytes.Load()
+		u.NativeBytes += i.nativeBytes.Load()
+		if i.leaked {
+			u.Leaked++
+		}
+		usage[i.scope.stage] = u
+	}
+	return usage
+}
+
+// CheckLeaks flags the instances whose stream ended more than grace before now and returns all leaked
+// instances, the oldest first
+func (r *Registry) CheckLeaks(now time.Time, grace time.Duration) []Leak {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var leaks []Leak
+	for i := range r.instances {
+		ended := i.endedAt()
+		if ended.IsZero() || now.Sub(ended) < grace {
+			continue
+		}
+		leaks = append(leaks, Leak{
+			Stage:       i.scope.stage,
+			Stream:      i.stream,
+			Age:         now.Sub(i.created),
+			SinceEnd:    now.Sub(ended),
+			GoBytes:     i.goBytes.Load(),
+			NativeBytes: i.nativeBytes.Load(),
+			New:         !i.leaked,
+		})
+		i.leaked = true
+	}
+	sort.Slice(leaks, func(a, b int) bool { return leaks[a].Age > leaks[b].Age })
+	return leaks
+}
+
+func (r *Registry) add(i *Instance) {
+	r.lock.Lock()
+	r.instances[i] = struct{}{}
+	r.lock.Unlock()
+}
+
+func (r *Registry) remove(i *Instance) {
+	r.lock.Lock()
+	delete(r.instances, i)
+	r.lock.Unlock()
+}
+
+// --------------------------------------
+
+// Scope groups the instances of a stage of one owner. Ending the scope ends the streams of its instances,
+// including ones tracked after it ended, which the owner should not create anymore.
+type Scope struct {
+	registry *Registry
+	stage    string
+
+	lock      sync.Mutex
+	instances map[*Instance]struct{}
+	ended     time.Time
+}
+
+// Track registers an instance created for a stream, nil-safe
+func (s *Scope) Track(stream string) *Instance {
+	if s == nil {
+		return nil
+	}
+
+	i := &Instance{
+		scope:   s,
+		stream:  stream,
+		created: time.Now(),
+	}
+
+	s.lock.Lock()
+	if !s.ended.IsZero() {
+		i.ended.Store(s.ended.UnixNano())
+	} else {
+		if s.instances == nil {
+			s.instances = make(map[*Instance]struct{})
+		}
+		s.instances[i] = struct{}{}
+	}
+	s.lock.Unlock()
+
+	s.registry.add(i)
+	return i
+}
+
+// End ends the streams of all instances of the scope, they are expected to be released shortly
+func (s *Scope) End() {
+	if s == nil {
+		return
+	}
+
+	now := time.Now()
+	s.lock.Lock()
+	if s.ended.IsZero() {
+		s.ended = now
+	}
+	instances := s.instances
+	s.instances = nil
+	s.lock.Unlock()
+
+	for i := range instances {
+		i.EndStream()
+	}
+}
+
+func (s *Scope) forget(i *Instance) {
+	s.lock.Lock()
+	delete(s.instances, i)
+	s.lock.Unlock()
+}
+
+// --------------------------------------
+
+// Instance accounts the memory of one instance of a stage. The methods are no-ops on a nil instance.
+type Instance struct {
+	scope   *Scope
+	stream  string
+	created time.Time
+
+	goBytes     atomic.Int64
+	nativeBytes atomic.Int64
+	// unix nanoseconds the stream ended at, 0 while it has not
+	ended    atomic.Int64
+	released atomic.Bool
+
+	// protected by the lock of the registry
+	leaked bool
+}
+
+// Set records the memory currently held, goBytes on the Go heap and nativeBytes allocated by native libraries
+func (i *Instance) Set(goBytes int, nativeBytes int) {
+	if i == nil {
+		return
+	}
+	i.goBytes.Store(int64(goBytes))
+	i.nativeBytes.Store(int64(nativeBytes))
+}
+
+// EndStream marks the stream of the instance as ended, the instance is expected to be released shortly
+func (i *Instance) EndStream() {
+	if i == nil {
+		return
+	}
+	i.ended.CompareAndSwap(0, time.Now().UnixNano())
+}
+
+// Release unregisters the instance once it freed its memory
+func (i *Instance) Release() {
+	if i == nil || i.released.Swap(true) {
+		return
+	}
+	i.scope.forget(i)
+	i.scope.registry.remove(i)
+}
+
+func (i *Instance) endedAt() time.Time {
+	if ended := i.ended.Load(); ended != 0 {
+		return time.Unix(0, ended)
+	}
+	return time.Time{}
+}
@@ -0,0 +1,100 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package memtrack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("usage is summed per stage", func(t *testing.T) {
+		r := NewRegistry()
+		denoisers := r.NewScope("noise_filter")
+		a := denoisers.Track("1")
+		a.Set(100, 1000)
+		b := denoisers.Track("2")
+		b.Set(50, 500)
+		r.StageScope("vad").Track("1").Set(10, 0)
+
+		usage := r.Usage()
+		require.Equal(t, Usage{Instances: 2, GoBytes: 150, NativeBytes: 1500}, usage["noise_filter"])
+		require.Equal(t, Usage{Instances: 1, GoBytes: 10}, usage["vad"])
+
+		a.Release()
+		a.Release()
+		require.Equal(t, Usage{Instances: 1, GoBytes: 50, NativeBytes: 500}, r.Usage()["noise_filter"])
+	})
+
+	t.Run("instances outliving their stream are flagged once", func(t *testing.T) {
+		r := NewRegistry()
+		s := r.StageScope("tap_dtt")
+		i := s.Track("TR_1")
+		i.Set(0, 17000)
+
+		now := time.Now()
+		require.Empty(t, r.CheckLeaks(now, time.Second))
+
+		i.EndStream()
+		require.Empty(t, r.CheckLeaks(now, time.Second), "within grace")
+
+		leaks := r.CheckLeaks(now.Add(2*time.Second), time.Second)
+		require.Len(t, leaks, 1)
+		require.True(t, leaks[0].New)
+		require.Equal(t, "tap_dtt", leaks[0].Stage)
+		require.Equal(t, "TR_1", leaks[0].Stream)
+		require.EqualValues(t, 17000, leaks[0].NativeBytes)
+		require.Equal(t, 1, r.Usage()["tap_dtt"].Leaked)
+
+		leaks = r.CheckLeaks(now.Add(3*time.Second), time.Second)
+		require.Len(t, leaks, 1)
+		require.False(t, leaks[0].New)
+
+		i.Release()
+		require.Empty(t, r.CheckLeaks(now.Add(4*time.Second), time.Second))
+	})
+
+	t.Run("ending a scope ends its streams", func(t *testing.T) {
+		r := NewRegistry()
+		s := r.NewScope("noise_filter")
+		released := s.Track("1")
+		kept := s.Track("2")
+		released.Release()
+		s.End()
+
+		late := s.Track("3")
+		leaks := r.CheckLeaks(time.Now().Add(time.Minute), time.Second)
+		require.Len(t, leaks, 2)
+		streams := []string{leaks[0].Stream, leaks[1].Stream}
+		require.ElementsMatch(t, []string{"2", "3"}, streams)
+
+		kept.Release()
+		late.Release()
+		require.Empty(t, r.Usage())
+	})
+
+	t.Run("nil instances are no-ops", func(t *testing.T) {
+		var s *Scope
+		i := s.Track("1")
+		require.Nil(t, i)
+		i.Set(1, 1)
+		i.EndStream()
+		i.Release()
+		s.End()
+	})
+}
@@ -44,6 +44,7 @@ const (
 	MimeTypeCodecRTX
 	MimeTypeCodecFlexFEC
 	MimeTypeCodecULPFEC
+	MimeTypeCodecTelephoneEvent
 )
 
 func (m MimeTypeCodec) String() string {
@@ -76,6 +77,8 @@ func (m MimeTypeCodec) String() string {
 		return "flexfec"
 	case MimeTypeCodecULPFEC:
 		return "ulpfec"
+	case MimeTypeCodecTelephoneEvent:
+		return "telephone-event"
 	}
 
 	return "MimeTypeCodecUnknown"
@@ -111,6 +114,8 @@ func (m MimeTypeCodec) ToMimeType() MimeType {
 		return MimeTypeFlexFEC
 	case MimeTypeCodecULPFEC:
 		return MimeTypeULPFEC
+	case MimeTypeCodecTelephoneEvent:
+		return MimeTypeTelephoneEvent
 	}
 
 	return MimeTypeUnknown
@@ -144,6 +149,8 @@ func NormalizeMimeTypeCodec(codec string) MimeTypeCodec {
 		return MimeTypeCodecFlexFEC
 	case strings.EqualFold(codec, "ulpfec"):
 		return MimeTypeCodecULPFEC
+	case strings.EqualFold(codec, "telephone-event"):
+		return MimeTypeCodecTelephoneEvent
 	}
 
 	return MimeTypeCodecUnknown
@@ -195,6 +202,7 @@ const (
 	MimeTypeRTX
 	MimeTypeFlexFEC
 	MimeTypeULPFEC
+	MimeTypeTelephoneEvent
 )
 
 func (m MimeType) String() string {
@@ -227,6 +235,8 @@ func (m MimeType) String() string {
 		return webrtc.MimeTypeFlexFEC
 	case MimeTypeULPFEC:
 		return "video/ulpfec"
+	case MimeTypeTelephoneEvent:
+		return "audio/telephone-event"
 	}
 
 	return "MimeTypeUnknown"
@@ -295,6 +305,8 @@ func NormalizeMimeType(mime string) MimeType {
 		return MimeTypeFlexFEC
 	case strings.EqualFold(mime, "video/ulpfec"):
 		return MimeTypeULPFEC
+	case strings.EqualFold(mime, "audio/telephone-event"):
+		return MimeTypeTelephoneEvent
 	}
 
 	return MimeTypeUnknown
@@ -348,6 +360,10 @@ func IsMimeTypeStringPCMU(mime string) bool {
 	return NormalizeMimeType(mime) == MimeTypePCMU
 }
 
+func IsMimeTypeStringTelephoneEvent(mime string) bool {
+	return NormalizeMimeType(mime) == MimeTypeTelephoneEvent
+}
+
 func IsMimeTypeStringRTX(mime string) bool {
 	return NormalizeMimeType(mime) == MimeTypeRTX
 }
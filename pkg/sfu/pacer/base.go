@@ -56,7 +56,9 @@ func (b *Base) TimeSinceLastSentPacket() time.Duration {
 
 func (b *Base) SendPacket(p *Packet) (int, error) {
 	defer func() {
-		if p.Pool != nil && p.PoolEntity != nil {
+		if p.PayloadRef != nil {
+			p.PayloadRef.Release()
+		} else if p.Pool != nil && p.PoolEntity != nil {
 			p.Pool.Put(p.PoolEntity)
 		}
 	}()
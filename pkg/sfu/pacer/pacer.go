@@ -43,6 +43,12 @@ type Packet struct {
 	WriteStream        webrtc.TrackLocalWriter
 	Pool               *sync.Pool
 	PoolEntity         *[]byte
+	// payload shared with other packets, released once sent instead of returning PoolEntity to Pool
+	PayloadRef Releaser
+}
+
+type Releaser interface {
+	Release()
 }
 
 type Pacer interface {
@@ -74,12 +74,69 @@ type AudioConfig struct {
 	EnableLossProxying bool `yaml:"enable_loss_proxying,omitempty"`
 	// noise filter configuration for real-time audio processing
 	NoiseFilter audio.NoiseFilterConfig `yaml:"noise_filter,omitempty"`
+	// noise filter of audio the server originates, e. g. the sources of audio mixes or injected audio,
+	// configured apart from the filter of published audio
+	EgressNoiseFilter audio.NoiseFilterConfig `yaml:"egress_noise_filter,omitempty"`
+	// persistence of learned noise profiles by participant identity
+	NoiseProfile audio.NoiseProfileConfig `yaml:"noise_profile,omitempty"`
+	// detection of clipping, telephone band or heavily compressed microphones
+	MicQuality audio.MicQualityConfig `yaml:"mic_quality,omitempty"`
+	// opt-in server side mixing of audio for subscribers
+	Mixing audio.MixerConfig `yaml:"mixing,omitempty"`
+	// frame duration of server side audio processing
+	Framing audio.FramingConfig `yaml:"framing,omitempty"`
+	// delivery of in-band DTMF (RFC 4733 telephone events) as data
+	TelephoneEvents audio.TelephoneEventConfig `yaml:"telephone_events,omitempty"`
+	// pausing agent STT provider streams during silence
+	STTGate audio.STTGateConfig `yaml:"stt_gate,omitempty"`
+	// speaking events from voice activity detection, independent of the noise filter
+	VAD audio.VADConfig `yaml:"vad,omitempty"`
+	// participants excluded from processing stages
+	StageBypass audio.StageBypassConfig `yaml:"stage_bypass,omitempty"`
+	// retention of the audio delivered to consumers for snapshots
+	Snapshots audio.SnapshotConfig `yaml:"snapshots,omitempty"`
+	// spending idle CPU on the quality of re-encoded streams
+	QualityScavenging audio.QualityScavengingConfig `yaml:"quality_scavenging,omitempty"`
+	// turning noise filtering off on the least important tracks while the node is overloaded
+	LoadShedding audio.LoadSheddingConfig `yaml:"load_shedding,omitempty"`
+	// choosing retransmission or forward error correction per subscriber from round trip time and loss
+	LossResilience audio.LossResilienceConfig `yaml:"loss_resilience,omitempty"`
+	// wrapping Opus in RED for subscribers reporting losses
+	REDGeneration audio.REDGenerationConfig `yaml:"red_generation,omitempty"`
+	// synthesizing the audio of lost packets for the stages decoding audio
+	Concealment audio.ConcealmentConfig `yaml:"concealment,omitempty"`
+	// reordering and pacing packets ahead of the mixer and PCM taps
+	JitterBuffer audio.JitterBufferConfig `yaml:"jitter_buffer,omitempty"`
+	// attenuating the other participants while an agent speaks
+	Ducking audio.DuckingConfig `yaml:"ducking,omitempty"`
+	// spotting phrases summoning agents in published audio
+	WakeWord audio.WakeWordConfig `yaml:"wake_word,omitempty"`
+	// interrupting agents when somebody speaks over them
+	BargeIn audio.BargeInConfig `yaml:"barge_in,omitempty"`
 }
 
 var (
 	DefaultAudioConfig = AudioConfig{
-		AudioLevelConfig: audio.DefaultAudioLevelConfig,
-		NoiseFilter:      audio.DefaultNoiseFilterConfig(),
+		AudioLevelConfig:  audio.DefaultAudioLevelConfig,
+		NoiseFilter:       audio.DefaultNoiseFilterConfig(),
+		EgressNoiseFilter: audio.DefaultNoiseFilterConfig(),
+		NoiseProfile:      audio.DefaultNoiseProfileConfig,
+		MicQuality:        audio.DefaultMicQualityConfig,
+		Mixing:            audio.DefaultMixerConfig,
+		Framing:           audio.DefaultFramingConfig,
+		TelephoneEvents:   audio.DefaultTelephoneEventConfig,
+		STTGate:           audio.DefaultSTTGateConfig,
+		VAD:               audio.DefaultVADConfig,
+		Snapshots:         audio.DefaultSnapshotConfig,
+		QualityScavenging: audio.DefaultQualityScavengingConfig,
+		LoadShedding:      audio.DefaultLoadSheddingConfig,
+		LossResilience:    audio.DefaultLossResilienceConfig,
+		REDGeneration:     audio.DefaultREDGenerationConfig,
+		Concealment:       audio.DefaultConcealmentConfig,
+		JitterBuffer:      audio.DefaultJitterBufferConfig,
+		Ducking:           audio.DefaultDuckingConfig,
+		WakeWord:          audio.DefaultWakeWordConfig,
+		BargeIn:           audio.DefaultBargeInConfig,
 	}
 )
 
@@ -210,6 +267,8 @@ type WebRTCReceiver struct {
 	redTransformer atomic.Value // redTransformer interface
 
 	forwardStats *ForwardStats
+
+	fanOutConfig FanOutConfig
 }
 
 type ReceiverOpts func(w *WebRTCReceiver) *WebRTCReceiver
@@ -249,6 +308,14 @@ func WithForwardStats(forwardStats *ForwardStats) ReceiverOpts {
 	}
 }
 
+// WithFanOutConfig enables sharing payloads between down tracks in large rooms
+func WithFanOutConfig(fanOutConfig FanOutConfig) ReceiverOpts {
+	return func(w *WebRTCReceiver) *WebRTCReceiver {
+		w.fanOutConfig = fanOutConfig
+		return w
+	}
+}
+
 // NewWebRTCReceiver creates a new webrtc track receiver
 func NewWebRTCReceiver(
 	receiver *webrtc.RTPReceiver,
@@ -787,6 +854,11 @@ func (w *WebRTCReceiver) forwardRTP(layer int32, buff *buffer.Buffer) {
 		return
 	}
 
+	var fanOut *fanOutPacket
+	if w.fanOutConfig.Enabled {
+		fanOut = newFanOutPacket(w.fanOutConfig)
+	}
+
 	pktBuf := make([]byte, bucket.MaxPktSize)
 	for {
 		pkt, err := buff.ReadExtended(pktBuf)
@@ -795,6 +867,16 @@ func (w *WebRTCReceiver) forwardRTP(layer int32, buff *buffer.Buffer) {
 		}
 		dequeuedAt := mono.UnixNano()
 
+		if pkt.IsTelephoneEvent {
+			// in-band DTMF bypasses forwarding and audio processing, it would be taken for audio of the track's codec
+			w.downTrackSpreader.Broadcast(func(dt TrackSender) {
+				if tw, ok := dt.(TelephoneEventWriter); ok {
+					tw.WriteTelephoneEvent(pkt)
+				}
+			})
+			continue
+		}
+
 		if pkt.Packet.PayloadType != uint8(w.codec.PayloadType) {
 			// drop packets as we don't support codec fallback directly
 			w.logger.Debugw(
@@ -821,9 +903,21 @@ func (w *WebRTCReceiver) forwardRTP(layer int32, buff *buffer.Buffer) {
 			continue
 		}
 
-		writeCount := w.downTrackSpreader.Broadcast(func(dt TrackSender) {
-			_ = dt.WriteRTP(pkt, spatialLayer)
-		})
+		var writeCount int
+		if fanOut != nil && w.downTrackSpreader.DownTrackCount() >= w.fanOutConfig.MinSubscribers {
+			writeCount = w.downTrackSpreader.Broadcast(func(dt TrackSender) {
+				if fw, ok := dt.(fanOutWriter); ok {
+					_ = fw.writeRTPFanOut(pkt, spatialLayer, fanOut)
+				} else {
+					_ = dt.WriteRTP(pkt, spatialLayer)
+				}
+			})
+			fanOut.release()
+		} else {
+			writeCount = w.downTrackSpreader.Broadcast(func(dt TrackSender) {
+				_ = dt.WriteRTP(pkt, spatialLayer)
+			})
+		}
 
 		if rt := w.redTransformer.Load(); rt != nil {
 			writeCount += rt.(REDTransformer).ForwardRTP(pkt, spatialLayer)
@@ -942,7 +1036,7 @@ func (w *WebRTCReceiver) GetRedReceiver() TrackReceiver {
 
 	rt := w.redTransformer.Load()
 	if rt == nil {
-		pr := NewRedReceiver(w, DownTrackSpreaderParams{
+		pr := NewRedReceiver(w, w.audioConfig.REDGeneration.REDDistance(), DownTrackSpreaderParams{
 			Threshold: w.lbThreshold,
 			Logger:    w.logger,
 		})
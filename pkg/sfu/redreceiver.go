@@ -24,7 +24,6 @@ import (
 	"github.com/pion/webrtc/v4"
 
 	"github.com/livekit/livekit-server/pkg/sfu/buffer"
-	"github.com/livekit/mediatransportutil/pkg/bucket"
 	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 )
@@ -46,15 +45,18 @@ type RedReceiver struct {
 	downTrackSpreader *DownTrackSpreader
 	logger            logger.Logger
 	closed            atomic.Bool
-	pktBuff           [maxRedCount]*rtp.Packet
-	redPayloadBuf     [mtuSize]byte
+	// the last primary packets, as many as are carried redundantly
+	pktBuff       []*rtp.Packet
+	redPayloadBuf [mtuSize]byte
 }
 
-func NewRedReceiver(receiver TrackReceiver, dsp DownTrackSpreaderParams) *RedReceiver {
+// NewRedReceiver creates a RED encoder of an Opus track carrying distance previous packets in each RED packet
+func NewRedReceiver(receiver TrackReceiver, distance int, dsp DownTrackSpreaderParams) *RedReceiver {
 	return &RedReceiver{
 		TrackReceiver:     receiver,
 		downTrackSpreader: NewDownTrackSpreader(dsp),
 		logger:            dsp.Logger,
+		pktBuff:           make([]*rtp.Packet, distance),
 	}
 }
 
@@ -147,8 +149,8 @@ func (r *RedReceiver) Close() {
 }
 
 func (r *RedReceiver) ReadRTP(buf []byte, layer uint8, esn uint64) (int, error) {
-	// red encoding doesn't support nack
-	return 0, bucket.ErrPacketMismatch
+	// red packets keep the sequence numbers of the primary ones, retransmit the primary encoding
+	return r.TrackReceiver.ReadRTP(buf, layer, esn)
 }
 
 func (r *RedReceiver) encodeRedForPrimary(pkt *rtp.Packet, redPayload []byte) (int, error) {
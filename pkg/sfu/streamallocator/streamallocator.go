@@ -200,6 +200,8 @@ type StreamAllocator struct {
 
 	committedChannelCapacity  int64
 	overriddenChannelCapacity int64
+	// committed channel capacity, readable outside the event loop
+	channelCapacity atomic.Int64
 
 	prober *ccutils.Prober
 
@@ -567,6 +569,11 @@ func (s *StreamAllocator) BWEType() bwe.BWEType {
 }
 
 // called to check if track subscription mute can be applied
+// called by down tracks to tier subscribers for sharing payloads, see sfu.FanOutConfig
+func (s *StreamAllocator) ChannelCapacity() int64 {
+	return s.channelCapacity.Load()
+}
+
 func (s *StreamAllocator) IsSubscribeMutable(downTrack *sfu.DownTrack) bool {
 	s.videoTracksMu.Lock()
 	defer s.videoTracksMu.Unlock()
@@ -730,6 +737,7 @@ func (s *StreamAllocator) handleSignalPeriodicPing(Event) {
 			if probeSignal != ccutils.ProbeSignalCongesting {
 				if channelCapacity > s.committedChannelCapacity {
 					s.committedChannelCapacity = channelCapacity
+					s.channelCapacity.Store(channelCapacity)
 				}
 
 				s.maybeBoostDeficientTracks()
@@ -863,6 +871,7 @@ func (s *StreamAllocator) handleSignalCongestionStateChange(event Event) {
 				"expectedUsage(bps)", s.getExpectedBandwidthUsage(),
 			)
 			s.committedChannelCapacity = cscd.estimatedAvailableChannelCapacity
+			s.channelCapacity.Store(s.committedChannelCapacity)
 
 			s.allocateAllTracks()
 		}
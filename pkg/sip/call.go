@@ -0,0 +1,614 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sip
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	maxRTPPacketSize = 1500
+	// how often the media worker looks at telephone events whose end was lost and at the media timeout
+	mediaPollInterval = 100 * time.Millisecond
+	// telephone events the gateway sends are 100 ms long, updated every 50 ms, the end is sent three times
+	dtmfSendInterval = 50 * time.Millisecond
+	dtmfSendUpdates  = 2
+	dtmfSendEnds     = 3
+	dtmfSendVolume   = 10
+)
+
+// EndReason tells why a call ended
+type EndReason string
+
+const (
+	// the caller hung up
+	EndReasonHangup EndReason = "hangup"
+	// the caller gave up before the call was answered
+	EndReasonCancelled EndReason = "cancelled"
+	// no RTP arrived from the caller for the media timeout
+	EndReasonMediaTimeout EndReason = "media_timeout"
+	// the trunk never acknowledged the answer
+	EndReasonNoAck EndReason = "no_ack"
+	// the gateway hung up, e. g. because the room closed
+	EndReasonClosed EndReason = "closed"
+)
+
+// CallHandler receives the media of an answered call
+type CallHandler interface {
+	// mono PCM of the caller at the sample rate of the call, called from the media goroutine of the call
+	OnAudio(pcm []int16, sampleRate int)
+	// start and end of the DTMF digits the caller sends as telephone events
+	OnDTMF(update audio.TelephoneEventUpdate)
+	// called once when the call ended
+	OnEnd(reason EndReason)
+}
+
+// Call is a phone call received by the gateway
+type Call struct {
+	// Call-ID of the dialog
+	ID string
+	// user part of From, the number of the caller
+	From string
+	// display name of the caller, empty when it sent none
+	FromName string
+	// user part of the request URI, the number dialed
+	To string
+	// audio codec negotiated
+	Format Format
+
+	server         *Server
+	invite         *Message
+	signalAddr     netip.AddrPort
+	localTo        string
+	rtpConn        *net.UDPConn
+	telephoneEvent *Format
+	codec          codec
+	answer         []byte
+
+	lock         sync.Mutex
+	handler      CallHandler
+	response     *Message
+	answered     bool
+	mediaStarted bool
+	remoteRTP    netip.AddrPort
+	cseq         uint32
+	reason       EndReason
+	acked        core.Fuse
+	ended        core.Fuse
+
+	// sending, only used by writers
+	writeLock sync.Mutex
+	resampler *audio.Resampler
+	inputRate int
+	pcm       []int16
+	packet    []byte
+	seq       uint16
+	timestamp uint32
+	ssrc      uint32
+	sent      bool
+}
+
+func newCall(
+	s *Server,
+	invite *Message,
+	signalAddr netip.AddrPort,
+	rtpConn *net.UDPConn,
+	format Format,
+	telephoneEvent *Format,
+	remoteRTP netip.AddrPort,
+) (*Call, error) {
+	id := invite.Get("Call-ID")
+	if id == "" || invite.Get("From") == "" || invite.Get("To") == "" {
+		return nil, ErrInvalidMessage
+	}
+	codec, err := newCodec(format)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Call{
+		ID:             id,
+		From:           URIUser(invite.Get("From")),
+		FromName:       DisplayName(invite.Get("From")),
+		To:             URIUser(invite.RequestURI),
+		Format:         format,
+		server:         s,
+		invite:         invite,
+		signalAddr:     signalAddr,
+		localTo:        invite.Get("To") + ";tag=" + randomToken(8),
+		rtpConn:        rtpConn,
+		telephoneEvent: telephoneEvent,
+		codec:          codec,
+		remoteRTP:      remoteRTP,
+	}
+	if c.To == "" {
+		c.To = URIUser(invite.Get("To"))
+	}
+
+	var ids [12]byte
+	_, _ = rand.Read(ids[:])
+	c.seq = binary.BigEndian.Uint16(ids[0:2])
+	c.timestamp = binary.BigEndian.Uint32(ids[2:6])
+	c.ssrc = binary.BigEndian.Uint32(ids[6:10])
+
+	answer := &MediaAnswer{
+		SessionID:      uint64(binary.BigEndian.Uint16(ids[10:12])) + uint64(time.Now().Unix()),
+		Address:        s.externalIP(),
+		Port:           rtpConn.LocalAddr().(*net.UDPAddr).Port,
+		Format:         format,
+		TelephoneEvent: telephoneEvent,
+	}
+	c.answer = answer.Marshal()
+	return c, nil
+}
+
+// SampleRate returns the sample rate of the PCM of the call
+func (c *Call) SampleRate() int {
+	return c.codec.sampleRate()
+}
+
+// CanSendDTMF returns true if the trunk accepts telephone events
+func (c *Call) CanSendDTMF() bool {
+	return c.telephoneEvent != nil
+}
+
+func (c *Call) Done() <-chan struct{} {
+	return c.ended.Watch()
+}
+
+// WriteAudio sends mono PCM at sampleRate to the caller, transcoded into frames of 20 ms.
+// Audio written before the call was answered is dropped.
+func (c *Call) WriteAudio(pcm []int16, sampleRate int) error {
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	if c.ended.IsBroken() {
+		return ErrCallEnded
+	}
+	c.lock.Lock()
+	answered, remote := c.answered, c.remoteRTP
+	c.lock.Unlock()
+	if !answered {
+		return nil
+	}
+
+	if c.resampler == nil || c.inputRate != sampleRate {
+		c.resampler = audio.NewResampler(sampleRate, c.codec.sampleRate(), 1)
+		c.inputRate = sampleRate
+	}
+	c.pcm = c.resampler.Resample(pcm, c.pcm)
+
+	frameSize := c.codec.sampleRate() * int(frameDuration/time.Millisecond) / 1000
+	for len(c.pcm) >= frameSize {
+		h := rtpHeader{
+			Marker:         !c.sent,
+			PayloadType:    c.Format.PayloadType,
+			SequenceNumber: c.seq,
+			Timestamp:      c.timestamp,
+			SSRC:           c.ssrc,
+		}
+		packet, err := c.codec.encode(c.pcm[:frameSize], h.append(c.packet[:0]))
+		if err != nil {
+			return err
+		}
+		c.packet = packet
+		c.pcm = append(c.pcm[:0], c.pcm[frameSize:]...)
+		c.seq++
+		c.timestamp += uint32(frameSize)
+		c.sent = true
+
+		if _, err := c.rtpConn.WriteToUDPAddrPort(packet, remote); err != nil {
+			if c.ended.IsBroken() {
+				return ErrCallEnded
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// SendDTMF sends a digit to the caller as RFC 4733 telephone events, it blocks for the duration of the digit
+// during which no audio is sent
+func (c *Call) SendDTMF(digit string) error {
+	if c.telephoneEvent == nil {
+		return ErrDTMFNotSupported
+	}
+	event, ok := dtmfEvent(digit)
+	if !ok {
+		return ErrInvalidDigit
+	}
+
+	c.writeLock.Lock()
+	defer c.writeLock.Unlock()
+
+	c.lock.Lock()
+	answered, remote := c.answered, c.remoteRTP
+	c.lock.Unlock()
+	if !answered || c.ended.IsBroken() {
+		return ErrCallEnded
+	}
+
+	step := uint16(c.telephoneEvent.ClockRate * int(dtmfSendInterval/time.Millisecond) / 1000)
+	send := func(duration uint16, end bool, marker bool) error {
+		h := rtpHeader{
+			Marker:         marker,
+			PayloadType:    c.telephoneEvent.PayloadType,
+			SequenceNumber: c.seq,
+			Timestamp:      c.timestamp,
+			SSRC:           c.ssrc,
+		}
+		flags := byte(dtmfSendVolume)
+		if end {
+			flags |= 0x80
+		}
+		packet := append(h.append(c.packet[:0]), event, flags)
+		packet = binary.BigEndian.AppendUint16(packet, duration)
+		c.packet = packet
+		c.seq++
+		_, err := c.rtpConn.WriteToUDPAddrPort(packet, remote)
+		return err
+	}
+
+	for i := 1; i <= dtmfSendUpdates; i++ {
+		if err := send(uint16(i)*step, false, i == 1); err != nil {
+			return err
+		}
+		time.Sleep(dtmfSendInterval)
+	}
+	for i := 0; i < dtmfSendEnds; i++ {
+		if err := send(dtmfSendUpdates*step, true, false); err != nil {
+			return err
+		}
+	}
+	// audio continues after the digit
+	c.timestamp += uint32(dtmfSendUpdates) * uint32(step) * uint32(c.codec.sampleRate()) / uint32(c.telephoneEvent.ClockRate)
+	return nil
+}
+
+// Hangup ends the call, a call that was not answered yet is declined
+func (c *Call) Hangup() {
+	c.hangup(EndReasonClosed)
+}
+
+func (c *Call) hangup(reason EndReason) {
+	c.lock.Lock()
+	answered := c.answered
+	c.lock.Unlock()
+
+	if c.ended.IsBroken() {
+		return
+	}
+	if answered {
+		c.sendBye()
+	} else {
+		c.sendFinal(480, "Temporarily Unavailable")
+	}
+	c.end(reason)
+}
+
+// admit hands the call to the handler of the server and answers or rejects it
+func (c *Call) admit() {
+	c.sendProvisional(180, "Ringing")
+
+	handler, err := c.server.params.OnInvite(c)
+	if err != nil {
+		status := &StatusError{Code: 480, Reason: "Temporarily Unavailable"}
+		errors.As(err, &status)
+		c.server.params.Logger.Infow("sip call rejected", "callID", c.ID, "error", err)
+		c.sendFinal(status.Code, status.Reason)
+		c.end(EndReasonClosed)
+		return
+	}
+
+	c.lock.Lock()
+	c.handler = handler
+	if c.ended.IsBroken() {
+		// cancelled while ringing
+		reason := c.reason
+		c.lock.Unlock()
+		handler.OnEnd(reason)
+		return
+	}
+	res := c.newResponse(c.invite, 200, "OK")
+	res.Add("Contact", c.contact())
+	res.Add("Content-Type", "application/sdp")
+	res.Body = c.answer
+	c.response = res
+	c.answered = true
+	c.mediaStarted = true
+	c.lock.Unlock()
+
+	c.server.send(res, c.signalAddr)
+	c.server.params.Logger.Infow("sip call answered", "callID", c.ID, "from", c.From, "to", c.To)
+	go c.retransmitAnswer(res)
+	go c.mediaWorker(handler)
+}
+
+// retransmitAnswer resends the 200 OK until it was acknowledged, UDP may lose it
+func (c *Call) retransmitAnswer(res *Message) {
+	interval := timerT1
+	timeout := time.NewTimer(64 * timerT1)
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-c.acked.Watch():
+			return
+		case <-c.ended.Watch():
+			return
+		case <-timeout.C:
+			c.server.params.Logger.Infow("sip call not acknowledged", "callID", c.ID)
+			c.hangup(EndReasonNoAck)
+			return
+		case <-time.After(interval):
+			c.server.send(res, c.signalAddr)
+			interval = min(2*interval, timerT2)
+		}
+	}
+}
+
+func (c *Call) onInvite(req *Message) {
+	seq, _, err := req.CSeq()
+	if err != nil {
+		return
+	}
+	inviteSeq, _, _ := c.invite.CSeq()
+
+	c.lock.Lock()
+	response, answered := c.response, c.answered
+	c.lock.Unlock()
+
+	if seq == inviteSeq {
+		// retransmission of the INVITE
+		if response != nil {
+			c.server.send(response, c.signalAddr)
+		} else {
+			c.sendProvisional(100, "Trying")
+		}
+		return
+	}
+	if c.ended.IsBroken() {
+		c.server.respond(req, c.signalAddr, 481, "Call/Transaction Does Not Exist")
+		return
+	}
+	if !answered {
+		c.server.respond(req, c.signalAddr, 491, "Request Pending")
+		return
+	}
+
+	// re-INVITE, e. g. of a trunk moving the media, the answer stays the same
+	if offer, err := ParseSDP(req.Body); err == nil && offer.Port != 0 {
+		if addr, err := netip.ParseAddr(offer.Address); err == nil && !addr.IsUnspecified() {
+			c.lock.Lock()
+			c.remoteRTP = netip.AddrPortFrom(addr.Unmap(), uint16(offer.Port))
+			c.lock.Unlock()
+		}
+	}
+	res := c.newResponse(req, 200, "OK")
+	res.Add("Contact", c.contact())
+	res.Add("Content-Type", "application/sdp")
+	res.Body = c.answer
+	c.server.send(res, c.signalAddr)
+}
+
+func (c *Call) onAck(_ *Message) {
+	c.acked.Break()
+}
+
+func (c *Call) onCancel() {
+	c.lock.Lock()
+	answered := c.answered
+	c.lock.Unlock()
+
+	if answered || c.ended.IsBroken() {
+		return
+	}
+	c.sendFinal(487, "Request Terminated")
+	c.end(EndReasonCancelled)
+}
+
+// end releases the call once, the media worker tells the handler
+func (c *Call) end(reason EndReason) {
+	c.lock.Lock()
+	if c.ended.IsBroken() {
+		c.lock.Unlock()
+		return
+	}
+	c.reason = reason
+	c.ended.Break()
+	handler, mediaStarted := c.handler, c.mediaStarted
+	c.lock.Unlock()
+
+	_ = c.rtpConn.Close()
+	c.server.params.Logger.Infow("sip call ended", "callID", c.ID, "reason", reason)
+	// kept for a while to answer retransmissions
+	time.AfterFunc(64*timerT1, func() { c.server.remove(c) })
+	if handler != nil && !mediaStarted {
+		handler.OnEnd(reason)
+	}
+}
+
+func (c *Call) mediaWorker(handler CallHandler) {
+	var tracker *audio.TelephoneEventTracker
+	if c.telephoneEvent != nil {
+		tracker = audio.NewTelephoneEventTracker(uint32(c.telephoneEvent.ClockRate), 0)
+	}
+
+	buf := make([]byte, maxRTPPacketSize)
+	var pcm []int16
+	lastPacket := time.Now()
+	for {
+		_ = c.rtpConn.SetReadDeadline(time.Now().Add(mediaPollInterval))
+		n, addr, err := c.rtpConn.ReadFromUDPAddrPort(buf)
+		now := time.Now()
+		if err != nil {
+			var netErr net.Error
+			if !errors.As(err, &netErr) || !netErr.Timeout() {
+				break
+			}
+			if tracker != nil {
+				if update := tracker.Expire(now); update != nil {
+					handler.OnDTMF(*update)
+				}
+			}
+			if now.Sub(lastPacket) > c.server.params.Config.MediaTimeout {
+				c.server.params.Logger.Infow("sip call media timed out", "callID", c.ID)
+				c.hangup(EndReasonMediaTimeout)
+			}
+			continue
+		}
+		if !c.latch(addr) {
+			continue
+		}
+		h, payload, err := parseRTP(buf[:n])
+		if err != nil {
+			continue
+		}
+		lastPacket = now
+
+		switch {
+		case h.PayloadType == c.Format.PayloadType:
+			if pcm, err = c.codec.decode(payload, pcm[:0]); err != nil {
+				c.server.params.Logger.Debugw("could not decode sip audio", "error", err, "callID", c.ID)
+				continue
+			}
+			if len(pcm) != 0 {
+				handler.OnAudio(pcm, c.codec.sampleRate())
+			}
+
+		case tracker != nil && h.PayloadType == c.telephoneEvent.PayloadType:
+			event, err := audio.ParseTelephoneEvent(payload)
+			if err != nil {
+				continue
+			}
+			for _, update := range tracker.Push(event, h.Timestamp, now) {
+				handler.OnDTMF(update)
+			}
+		}
+	}
+
+	if tracker != nil {
+		if update := tracker.Flush(); update != nil {
+			handler.OnDTMF(*update)
+		}
+	}
+	c.lock.Lock()
+	reason := c.reason
+	c.lock.Unlock()
+	handler.OnEnd(reason)
+}
+
+// latch accepts RTP from the address of the SDP or of the signaling, and sends to the port it came from,
+// for trunks behind NAT
+func (c *Call) latch(addr netip.AddrPort) bool {
+	addr = netip.AddrPortFrom(addr.Addr().Unmap(), addr.Port())
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if addr.Addr() != c.remoteRTP.Addr() && addr.Addr() != c.signalAddr.Addr().Unmap() {
+		return false
+	}
+	c.remoteRTP = addr
+	return true
+}
+
+func (c *Call) sendProvisional(code int, reason string) {
+	c.server.send(c.newResponse(c.invite, code, reason), c.signalAddr)
+}
+
+// sendFinal rejects the INVITE, the response is kept for retransmissions
+func (c *Call) sendFinal(code int, reason string) {
+	c.lock.Lock()
+	res := c.newResponse(c.invite, code, reason)
+	c.response = res
+	c.lock.Unlock()
+
+	c.server.send(res, c.signalAddr)
+}
+
+func (c *Call) sendBye() {
+	c.lock.Lock()
+	c.cseq++
+	cseq := c.cseq
+	c.lock.Unlock()
+
+	target := c.invite.Get("Contact")
+	if target == "" {
+		target = c.invite.Get("From")
+	}
+
+	bye := &Message{
+		Method:     MethodBye,
+		RequestURI: requestURI(target),
+	}
+	bye.Add("Via", fmt.Sprintf("SIP/2.0/UDP %s:%d;branch=%s%s;rport", c.server.externalIP(), c.server.Addr().Port, branchPrefix, randomToken(8)))
+	bye.Add("Max-Forwards", "70")
+	bye.Add("From", c.localTo)
+	bye.Add("To", c.invite.Get("From"))
+	bye.Add("Call-ID", c.ID)
+	bye.Add("CSeq", fmt.Sprintf("%d %s", cseq, MethodBye))
+	// the route set of a UAS is the Record-Route of the request, in order
+	for _, route := range c.invite.Values("Record-Route") {
+		bye.Add("Route", route)
+	}
+	c.server.send(bye, c.signalAddr)
+}
+
+func (c *Call) newResponse(req *Message, code int, reason string) *Message {
+	res := NewResponse(req, code, reason)
+	if code > 100 {
+		res.Set("To", c.localTo)
+	}
+	return res
+}
+
+func (c *Call) contact() string {
+	return fmt.Sprintf("<sip:%s@%s:%d>", c.To, c.server.externalIP(), c.server.Addr().Port)
+}
+
+// requestURI returns the URI of a name-addr like <sip:+1555@10.0.0.1:5060;transport=udp>;expires=60
+func requestURI(value string) string {
+	if start := strings.IndexByte(value, '<'); start >= 0 {
+		if end := strings.IndexByte(value[start:], '>'); end >= 0 {
+			return value[start+1 : start+end]
+		}
+	}
+	uri, _, _ := strings.Cut(value, ";")
+	return strings.TrimSpace(uri)
+}
+
+// dtmfEvent returns the RFC 4733 event code of a digit
+func dtmfEvent(digit string) (uint8, bool) {
+	for event := uint8(0); event <= 15; event++ {
+		if audio.DTMFDigit(event) == strings.ToUpper(digit) {
+			return event, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,308 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	sipVersion = "SIP/2.0"
+
+	MethodInvite  = "INVITE"
+	MethodAck     = "ACK"
+	MethodBye     = "BYE"
+	MethodCancel  = "CANCEL"
+	MethodOptions = "OPTIONS"
+)
+
+var (
+	ErrInvalidMessage = errors.New("invalid sip message")
+)
+
+// compact forms of header names, RFC 3261 section 7.3.3
+var compactHeaders = map[string]string{
+	"v": "Via",
+	"f": "From",
+	"t": "To",
+	"i": "Call-ID",
+	"m": "Contact",
+	"l": "Content-Length",
+	"c": "Content-Type",
+	"k": "Supported",
+}
+
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+// Message is a SIP request or response
+type Message struct {
+	// request line, empty for responses
+	Method     string
+	RequestURI string
+	// status line, zero for requests
+	StatusCode int
+	Reason     string
+
+	// in the order they were received, Via headers are answered in that order
+	Headers []HeaderField
+	Body    []byte
+}
+
+func (m *Message) IsRequest() bool {
+	return m.Method != ""
+}
+
+// Get returns the first value of the header, names are case insensitive
+func (m *Message) Get(name string) string {
+	for _, h := range m.Headers {
+		if strings.EqualFold(h.Name, name) {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// Values returns all values of the header
+func (m *Message) Values(name string) []string {
+	var values []string
+	for _, h := range m.Headers {
+		if strings.EqualFold(h.Name, name) {
+			values = append(values, h.Value)
+		}
+	}
+	return values
+}
+
+func (m *Message) Add(name string, value string) {
+	m.Headers = append(m.Headers, HeaderField{Name: name, Value: value})
+}
+
+// Set replaces all values of the header with value
+func (m *Message) Set(name string, value string) {
+	headers := m.Headers[:0]
+	set := false
+	for _, h := range m.Headers {
+		if !strings.EqualFold(h.Name, name) {
+			headers = append(headers, h)
+		} else if !set {
+			headers = append(headers, HeaderField{Name: h.Name, Value: value})
+			set = true
+		}
+	}
+	m.Headers = headers
+	if !set {
+		m.Add(name, value)
+	}
+}
+
+// CSeq returns the sequence number and method of the CSeq header
+func (m *Message) CSeq() (uint32, string, error) {
+	fields := strings.Fields(m.Get("CSeq"))
+	if len(fields) != 2 {
+		return 0, "", ErrInvalidMessage
+	}
+	seq, err := strconv.ParseUint(fields[0], 10, 32)
+	if err != nil {
+		return 0, "", ErrInvalidMessage
+	}
+	return uint32(seq), fields[1], nil
+}
+
+// NewResponse returns a response to the request, with the headers RFC 3261 requires to be copied
+func NewResponse(req *Message, code int, reason string) *Message {
+	res := &Message{
+		StatusCode: code,
+		Reason:     reason,
+	}
+	for _, h := range req.Headers {
+		switch h.Name {
+		case "Via", "From", "To", "Call-ID", "CSeq", "Record-Route":
+			res.Headers = append(res.Headers, h)
+		}
+	}
+	return res
+}
+
+// ParseMessage parses a message received in a datagram
+func ParseMessage(b []byte) (*Message, error) {
+	head, body, ok := bytes.Cut(b, []byte("\r\n\r\n"))
+	if !ok {
+		head, body, ok = bytes.Cut(b, []byte("\n\n"))
+		if !ok {
+			return nil, ErrInvalidMessage
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(head), "\r\n", "\n"), "\n")
+	m := &Message{}
+	if err := m.parseStartLine(lines[0]); err != nil {
+		return nil, err
+	}
+
+	for _, line := range lines[1:] {
+		if line == "" {
+			continue
+		}
+		// folded continuation of the previous header
+		if line[0] == ' ' || line[0] == '\t' {
+			if len(m.Headers) == 0 {
+				return nil, ErrInvalidMessage
+			}
+			m.Headers[len(m.Headers)-1].Value += " " + strings.TrimSpace(line)
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return nil, ErrInvalidMessage
+		}
+		name = canonicalHeader(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		// Via may carry several comma separated hops, they are kept one per header
+		if name == "Via" {
+			for _, hop := range strings.Split(value, ",") {
+				m.Add(name, strings.TrimSpace(hop))
+			}
+			continue
+		}
+		m.Add(name, value)
+	}
+
+	if length := m.Get("Content-Length"); length != "" {
+		n, err := strconv.Atoi(length)
+		if err != nil || n < 0 || n > len(body) {
+			return nil, ErrInvalidMessage
+		}
+		body = body[:n]
+	}
+	if len(body) > 0 {
+		m.Body = append([]byte(nil), body...)
+	}
+	return m, nil
+}
+
+func (m *Message) parseStartLine(line string) error {
+	if strings.HasPrefix(line, sipVersion+" ") {
+		status, reason, _ := strings.Cut(line[len(sipVersion)+1:], " ")
+		code, err := strconv.Atoi(status)
+		if err != nil || code < 100 || code > 699 {
+			return ErrInvalidMessage
+		}
+		m.StatusCode, m.Reason = code, reason
+		return nil
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) != 3 || fields[2] != sipVersion {
+		return ErrInvalidMessage
+	}
+	m.Method, m.RequestURI = fields[0], fields[1]
+	return nil
+}
+
+// Marshal returns the message as sent, with Content-Length set to the length of the body
+func (m *Message) Marshal() []byte {
+	var b bytes.Buffer
+	if m.IsRequest() {
+		fmt.Fprintf(&b, "%s %s %s\r\n", m.Method, m.RequestURI, sipVersion)
+	} else {
+		fmt.Fprintf(&b, "%s %d %s\r\n", sipVersion, m.StatusCode, m.Reason)
+	}
+	for _, h := range m.Headers {
+		if h.Name == "Content-Length" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s: %s\r\n", h.Name, h.Value)
+	}
+	fmt.Fprintf(&b, "Content-Length: %d\r\n\r\n", len(m.Body))
+	b.Write(m.Body)
+	return b.Bytes()
+}
+
+func canonicalHeader(name string) string {
+	if long, ok := compactHeaders[strings.ToLower(name)]; ok {
+		return long
+	}
+	switch strings.ToLower(name) {
+	case "call-id":
+		return "Call-ID"
+	case "cseq":
+		return "CSeq"
+	case "www-authenticate":
+		return "WWW-Authenticate"
+	}
+	parts := strings.Split(strings.ToLower(name), "-")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// --------------------------------------
+
+// HeaderParam returns a parameter of a header value like From or Via, e. g. tag or branch
+func HeaderParam(value string, name string) string {
+	// parameters of a name-addr follow the closing bracket
+	if i := strings.LastIndexByte(value, '>'); i >= 0 {
+		value = value[i+1:]
+	}
+	for _, param := range strings.Split(value, ";")[1:] {
+		key, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+		if strings.EqualFold(key, name) {
+			return v
+		}
+	}
+	return ""
+}
+
+// URIUser returns the user part of the SIP URI of a header value or request URI, e. g. the number
+// of sip:+15551234@carrier.example
+func URIUser(value string) string {
+	uri := value
+	if start := strings.IndexByte(value, '<'); start >= 0 {
+		if end := strings.IndexByte(value[start:], '>'); end >= 0 {
+			uri = value[start+1 : start+end]
+		}
+	}
+	_, rest, ok := strings.Cut(uri, ":")
+	if !ok {
+		return ""
+	}
+	user, _, ok := strings.Cut(rest, "@")
+	if !ok {
+		return ""
+	}
+	user, _, _ = strings.Cut(user, ";")
+	return user
+}
+
+// DisplayName returns the display name of a header value like From, empty when it has none
+func DisplayName(value string) string {
+	i := strings.IndexByte(value, '<')
+	if i <= 0 {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(value[:i]), `"`)
+}
@@ -0,0 +1,193 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sip
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	rtpVersion    = 2
+	rtpHeaderSize = 12
+
+	// packetization of the audio the gateway sends
+	frameDuration = 20 * time.Millisecond
+)
+
+var (
+	ErrInvalidRTP = errors.New("invalid rtp packet")
+)
+
+type rtpHeader struct {
+	Marker         bool
+	PayloadType    uint8
+	SequenceNumber uint16
+	Timestamp      uint32
+	SSRC           uint32
+}
+
+// parseRTP returns the header and payload of a packet, skipping CSRCs, the header extension and padding
+func parseRTP(b []byte) (rtpHeader, []byte, error) {
+	if len(b) < rtpHeaderSize || b[0]>>6 != rtpVersion {
+		return rtpHeader{}, nil, ErrInvalidRTP
+	}
+
+	h := rtpHeader{
+		Marker:         b[1]&0x80 != 0,
+		PayloadType:    b[1] & 0x7f,
+		SequenceNumber: binary.BigEndian.Uint16(b[2:4]),
+		Timestamp:      binary.BigEndian.Uint32(b[4:8]),
+		SSRC:           binary.BigEndian.Uint32(b[8:12]),
+	}
+
+	offset := rtpHeaderSize + 4*int(b[0]&0x0f)
+	if b[0]&0x10 != 0 {
+		if len(b) < offset+4 {
+			return rtpHeader{}, nil, ErrInvalidRTP
+		}
+		offset += 4 + 4*int(binary.BigEndian.Uint16(b[offset+2:offset+4]))
+	}
+	end := len(b)
+	if b[0]&0x20 != 0 && end > 0 {
+		end -= int(b[end-1])
+	}
+	if offset > end {
+		return rtpHeader{}, nil, ErrInvalidRTP
+	}
+	return h, b[offset:end], nil
+}
+
+func (h rtpHeader) append(b []byte) []byte {
+	marker := byte(0)
+	if h.Marker {
+		marker = 0x80
+	}
+	b = append(b, rtpVersion<<6, marker|h.PayloadType&0x7f)
+	b = binary.BigEndian.AppendUint16(b, h.SequenceNumber)
+	b = binary.BigEndian.AppendUint32(b, h.Timestamp)
+	return binary.BigEndian.AppendUint32(b, h.SSRC)
+}
+
+// --------------------------------------
+
+// codec transcodes between the payload of the format of a call and mono PCM
+type codec interface {
+	// sample rate of the PCM
+	sampleRate() int
+	// decode appends the samples of a payload to pcm
+	decode(payload []byte, pcm []int16) ([]int16, error)
+	// encode appends the payload of a frame to b
+	encode(pcm []int16, b []byte) ([]byte, error)
+}
+
+func newCodec(f Format) (codec, error) {
+	switch f.Name {
+	case CodecPCMU:
+		return g711Codec{}, nil
+	case CodecPCMA:
+		return g711Codec{aLaw: true}, nil
+	case CodecOpus:
+		decoder, err := audio.NewOpusDecoder(audio.OpusSampleRate, 1)
+		if err != nil {
+			return nil, err
+		}
+		encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+		if err != nil {
+			return nil, err
+		}
+		return &opusCodec{
+			decoder: decoder,
+			encoder: encoder,
+			pcm:     make([]int16, audio.OpusMaxFrameSize),
+			payload: make([]byte, audio.OpusMaxPacketSize),
+		}, nil
+	}
+	return nil, ErrNoCommonCodec
+}
+
+type g711Codec struct {
+	aLaw bool
+}
+
+func (g711Codec) sampleRate() int {
+	return 8000
+}
+
+func (c g711Codec) decode(payload []byte, pcm []int16) ([]int16, error) {
+	for _, b := range payload {
+		if c.aLaw {
+			pcm = append(pcm, audio.DecodeALaw(b))
+		} else {
+			pcm = append(pcm, audio.DecodeMuLaw(b))
+		}
+	}
+	return pcm, nil
+}
+
+func (c g711Codec) encode(pcm []int16, b []byte) ([]byte, error) {
+	for _, sample := range pcm {
+		if c.aLaw {
+			b = append(b, audio.EncodeALaw(sample))
+		} else {
+			b = append(b, audio.EncodeMuLaw(sample))
+		}
+	}
+	return b, nil
+}
+
+type opusCodec struct {
+	decoder audio.OpusDecoder
+	encoder audio.OpusEncoder
+	pcm     []int16
+	payload []byte
+}
+
+func (*opusCodec) sampleRate() int {
+	return audio.OpusSampleRate
+}
+
+func (c *opusCodec) decode(payload []byte, pcm []int16) ([]int16, error) {
+	n, err := c.decoder.Decode(payload, c.pcm)
+	if err != nil {
+		return pcm, err
+	}
+	return append(pcm, c.pcm[:n]...), nil
+}
+
+func (c *opusCodec) encode(pcm []int16, b []byte) ([]byte, error) {
+	n, err := c.encoder.Encode(pcm, c.payload)
+	if err != nil {
+		return b, err
+	}
+	return append(b, c.payload[:n]...), nil
+}
+
+// supportedCodecs returns the codecs of the config the build can transcode, Opus needs the opus build tag
+func supportedCodecs(codecs []string) []string {
+	supported := make([]string, 0, len(codecs))
+	for _, codec := range codecs {
+		if strings.EqualFold(codec, CodecOpus) && !audio.IsOpusCodecAvailable() {
+			continue
+		}
+		supported = append(supported, codec)
+	}
+	return supported
+}
@@ -0,0 +1,260 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sip
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	CodecPCMU           = "PCMU"
+	CodecPCMA           = "PCMA"
+	CodecOpus           = "opus"
+	codecTelephoneEvent = "telephone-event"
+)
+
+var (
+	ErrNoAudio           = errors.New("sdp has no audio stream")
+	ErrNoCommonCodec     = errors.New("no codec in common with the offer")
+	ErrInvalidSDP        = errors.New("invalid sdp")
+	ErrUnsupportedMedium = errors.New("unsupported media transport")
+)
+
+// Format is a payload type of an audio stream
+type Format struct {
+	PayloadType uint8
+	Name        string
+	ClockRate   int
+	Channels    int
+}
+
+// static payload types of RFC 3551 used by telephony
+var staticFormats = map[uint8]Format{
+	0: {PayloadType: 0, Name: CodecPCMU, ClockRate: 8000, Channels: 1},
+	8: {PayloadType: 8, Name: CodecPCMA, ClockRate: 8000, Channels: 1},
+}
+
+// MediaOffer is the audio stream of a session description
+type MediaOffer struct {
+	Address string
+	Port    int
+	// in the order of preference of the sender
+	Formats []Format
+	// packetization time the sender asked for, 0 when not given
+	PTime int
+}
+
+// ParseSDP returns the audio stream of a session description
+func ParseSDP(b []byte) (*MediaOffer, error) {
+	var (
+		offer       *MediaOffer
+		sessionAddr string
+		mediaAddr   string
+		rtpmaps     = map[uint8]Format{}
+		payloads    []uint8
+		// past the audio stream, only the first one is answered
+		done bool
+		// in the section of a stream that is not audio
+		skip bool
+	)
+
+	for _, line := range strings.Split(strings.ReplaceAll(string(b), "\r\n", "\n"), "\n") {
+		if len(line) < 2 || line[1] != '=' {
+			continue
+		}
+		value := line[2:]
+		if done || (skip && line[0] != 'm') {
+			continue
+		}
+		switch line[0] {
+		case 'm':
+			if offer != nil {
+				done = true
+				continue
+			}
+			fields := strings.Fields(value)
+			skip = len(fields) < 4 || fields[0] != "audio"
+			if skip {
+				continue
+			}
+			if fields[2] != "RTP/AVP" && fields[2] != "RTP/AVPF" {
+				return nil, ErrUnsupportedMedium
+			}
+			port, err := strconv.Atoi(fields[1])
+			if err != nil {
+				return nil, ErrInvalidSDP
+			}
+			offer = &MediaOffer{Port: port}
+			for _, f := range fields[3:] {
+				pt, err := strconv.ParseUint(f, 10, 7)
+				if err != nil {
+					return nil, ErrInvalidSDP
+				}
+				payloads = append(payloads, uint8(pt))
+			}
+
+		case 'c':
+			fields := strings.Fields(value)
+			if len(fields) != 3 || fields[0] != "IN" {
+				return nil, ErrInvalidSDP
+			}
+			if offer == nil {
+				sessionAddr = fields[2]
+			} else if mediaAddr == "" {
+				mediaAddr = fields[2]
+			}
+
+		case 'a':
+			if offer == nil {
+				continue
+			}
+			attr, attrValue, _ := strings.Cut(value, ":")
+			switch attr {
+			case "rtpmap":
+				if f, ok := parseRTPMap(attrValue); ok {
+					rtpmaps[f.PayloadType] = f
+				}
+			case "ptime":
+				offer.PTime, _ = strconv.Atoi(attrValue)
+			}
+		}
+	}
+
+	if offer == nil {
+		return nil, ErrNoAudio
+	}
+	offer.Address = mediaAddr
+	if offer.Address == "" {
+		offer.Address = sessionAddr
+	}
+	if offer.Address == "" {
+		return nil, ErrInvalidSDP
+	}
+	for _, pt := range payloads {
+		if f, ok := rtpmaps[pt]; ok {
+			offer.Formats = append(offer.Formats, f)
+		} else if f, ok := staticFormats[pt]; ok {
+			offer.Formats = append(offer.Formats, f)
+		}
+	}
+	return offer, nil
+}
+
+// parseRTPMap parses "111 opus/48000/2"
+func parseRTPMap(value string) (Format, bool) {
+	pt, encoding, ok := strings.Cut(value, " ")
+	if !ok {
+		return Format{}, false
+	}
+	payloadType, err := strconv.ParseUint(pt, 10, 7)
+	if err != nil {
+		return Format{}, false
+	}
+	parts := strings.Split(strings.TrimSpace(encoding), "/")
+	if len(parts) < 2 {
+		return Format{}, false
+	}
+	clockRate, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return Format{}, false
+	}
+	f := Format{PayloadType: uint8(payloadType), Name: parts[0], ClockRate: clockRate, Channels: 1}
+	if len(parts) > 2 {
+		if f.Channels, err = strconv.Atoi(parts[2]); err != nil {
+			return Format{}, false
+		}
+	}
+	return f, true
+}
+
+// Negotiate picks the codec of the call, the first of codecs the offer has, and the telephone events
+// at the clock rate of that codec if the offer has them
+func (o *MediaOffer) Negotiate(codecs []string) (Format, *Format, error) {
+	var audio *Format
+	for _, codec := range codecs {
+		for _, f := range o.Formats {
+			if strings.EqualFold(f.Name, codec) && (f.Name != CodecOpus || f.ClockRate == 48000) {
+				audio = &f
+				break
+			}
+		}
+		if audio != nil {
+			break
+		}
+	}
+	if audio == nil {
+		return Format{}, nil, ErrNoCommonCodec
+	}
+
+	for _, f := range o.Formats {
+		if strings.EqualFold(f.Name, codecTelephoneEvent) && f.ClockRate == audio.ClockRate {
+			return *audio, &f, nil
+		}
+	}
+	return *audio, nil, nil
+}
+
+// MediaAnswer describes the audio stream of the gateway in an answer
+type MediaAnswer struct {
+	SessionID uint64
+	Address   string
+	Port      int
+	Format    Format
+	// nil without telephone events
+	TelephoneEvent *Format
+}
+
+func (a *MediaAnswer) Marshal() []byte {
+	addrType := "IP4"
+	if strings.Contains(a.Address, ":") {
+		addrType = "IP6"
+	}
+
+	payloads := strconv.Itoa(int(a.Format.PayloadType))
+	if a.TelephoneEvent != nil {
+		payloads += " " + strconv.Itoa(int(a.TelephoneEvent.PayloadType))
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "v=0\r\n")
+	fmt.Fprintf(&b, "o=agentix %d %d IN %s %s\r\n", a.SessionID, a.SessionID, addrType, a.Address)
+	fmt.Fprintf(&b, "s=agentix\r\n")
+	fmt.Fprintf(&b, "c=IN %s %s\r\n", addrType, a.Address)
+	fmt.Fprintf(&b, "t=0 0\r\n")
+	fmt.Fprintf(&b, "m=audio %d RTP/AVP %s\r\n", a.Port, payloads)
+	fmt.Fprintf(&b, "a=rtpmap:%s\r\n", rtpMap(a.Format))
+	if a.Format.Name == CodecOpus {
+		fmt.Fprintf(&b, "a=fmtp:%d minptime=10;useinbandfec=1\r\n", a.Format.PayloadType)
+	}
+	if a.TelephoneEvent != nil {
+		fmt.Fprintf(&b, "a=rtpmap:%s\r\n", rtpMap(*a.TelephoneEvent))
+		fmt.Fprintf(&b, "a=fmtp:%d 0-16\r\n", a.TelephoneEvent.PayloadType)
+	}
+	fmt.Fprintf(&b, "a=ptime:%d\r\n", frameDuration.Milliseconds())
+	fmt.Fprintf(&b, "a=sendrecv\r\n")
+	return b.Bytes()
+}
+
+func rtpMap(f Format) string {
+	if f.Channels > 1 {
+		return fmt.Sprintf("%d %s/%d/%d", f.PayloadType, f.Name, f.ClockRate, f.Channels)
+	}
+	return fmt.Sprintf("%d %s/%d", f.PayloadType, f.Name, f.ClockRate)
+}
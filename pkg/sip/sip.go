@@ -0,0 +1,411 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sip is a minimal SIP gateway for phone calls from carriers and PBXs, SIP trunks sending their
+// calls over UDP. It answers INVITEs, negotiates G.711 or Opus audio with RFC 4733 telephone events in SDP,
+// and transcodes the RTP of a call from and to mono PCM. Authentication is by source network only, calls are
+// not proxied or redirected and the gateway never places calls.
+package sip
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	maxMessageSize = 65535
+	// RFC 3261 timers, retransmissions of the 200 OK double from T1 up to T2 until the ACK arrives after 64*T1
+	timerT1 = 500 * time.Millisecond
+	timerT2 = 4 * time.Second
+
+	// magic cookie of RFC 3261 branch parameters
+	branchPrefix = "z9hG4bK"
+	userAgent    = "agentix-rtc-server"
+)
+
+var (
+	ErrTooManyCalls     = errors.New("too many sip calls")
+	ErrNoRTPPort        = errors.New("no rtp port available")
+	ErrInvalidNetwork   = errors.New("invalid allowed network, must be in CIDR notation")
+	ErrCallEnded        = errors.New("sip call ended")
+	ErrDTMFNotSupported = errors.New("call did not negotiate telephone events")
+	ErrInvalidDigit     = errors.New("invalid dtmf digit")
+)
+
+// Config enables a SIP gateway that lets phone callers join rooms
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// UDP port SIP is received on
+	Port int `yaml:"port,omitempty"`
+	// address put into SDP and Contact headers, defaults to the IP of the node
+	ExternalIP string `yaml:"external_ip,omitempty"`
+	// UDP ports the RTP of calls is received on, one per call
+	RTPPortStart int `yaml:"rtp_port_start,omitempty"`
+	RTPPortEnd   int `yaml:"rtp_port_end,omitempty"`
+	// a call joins the room named with this prefix followed by the number it dialed
+	RoomPrefix string `yaml:"room_prefix,omitempty"`
+	// networks of the trunks calls are accepted from, in CIDR notation, from anywhere when empty
+	AllowedNetworks []string `yaml:"allowed_networks,omitempty"`
+	// calls of the node at a time
+	MaxCalls int `yaml:"max_calls,omitempty"`
+	// codecs accepted in order of preference, of opus, PCMU and PCMA. Opus needs the opus build tag.
+	Codecs []string `yaml:"codecs,omitempty"`
+	// a call without RTP from the caller for this long is hung up
+	MediaTimeout time.Duration `yaml:"media_timeout,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Port:         5060,
+		RTPPortStart: 30000,
+		RTPPortEnd:   30999,
+		RoomPrefix:   "call-",
+		MaxCalls:     100,
+		Codecs:       []string{CodecOpus, CodecPCMU, CodecPCMA},
+		MediaTimeout: 30 * time.Second,
+	}
+)
+
+// StatusError rejects an INVITE with a SIP status
+type StatusError struct {
+	Code   int
+	Reason string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("sip %d %s", e.Code, e.Reason)
+}
+
+var (
+	ErrNotFound           = &StatusError{Code: 404, Reason: "Not Found"}
+	ErrBusy               = &StatusError{Code: 486, Reason: "Busy Here"}
+	ErrServiceUnavailable = &StatusError{Code: 503, Reason: "Service Unavailable"}
+)
+
+type ServerParams struct {
+	Config Config
+	Logger logger.Logger
+	// decides on an incoming call once its audio is negotiated and returns the handler of the call, an error
+	// rejects it, with the status of a *StatusError. Called from a goroutine of the call, the caller hears
+	// ringing until it returns.
+	OnInvite func(call *Call) (CallHandler, error)
+}
+
+// Server answers the calls SIP trunks send to the node
+type Server struct {
+	params  ServerParams
+	allowed []netip.Prefix
+	codecs  []string
+	conn    *net.UDPConn
+
+	lock     sync.Mutex
+	calls    map[string]*Call
+	nextPort int
+	stopped  core.Fuse
+}
+
+func NewServer(params ServerParams) (*Server, error) {
+	if params.Config.MediaTimeout <= 0 {
+		params.Config.MediaTimeout = DefaultConfig.MediaTimeout
+	}
+	if len(params.Config.Codecs) == 0 {
+		params.Config.Codecs = DefaultConfig.Codecs
+	}
+
+	s := &Server{
+		params:   params,
+		codecs:   supportedCodecs(params.Config.Codecs),
+		calls:    make(map[string]*Call),
+		nextPort: params.Config.RTPPortStart,
+	}
+	if len(s.codecs) == 0 {
+		return nil, ErrNoCommonCodec
+	}
+	for _, network := range params.Config.AllowedNetworks {
+		prefix, err := netip.ParsePrefix(network)
+		if err != nil {
+			return nil, ErrInvalidNetwork
+		}
+		s.allowed = append(s.allowed, prefix.Masked())
+	}
+	return s, nil
+}
+
+func (s *Server) Start() error {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: s.params.Config.Port})
+	if err != nil {
+		return err
+	}
+	s.conn = conn
+
+	s.params.Logger.Infow("sip gateway listening", "port", conn.LocalAddr().(*net.UDPAddr).Port, "codecs", s.codecs)
+	go s.readWorker()
+	return nil
+}
+
+// Stop hangs up all calls
+func (s *Server) Stop() {
+	s.lock.Lock()
+	s.stopped.Break()
+	calls := make([]*Call, 0, len(s.calls))
+	for _, call := range s.calls {
+		calls = append(calls, call)
+	}
+	s.lock.Unlock()
+
+	for _, call := range calls {
+		call.Hangup()
+	}
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+}
+
+// Addr returns the address SIP is received on
+func (s *Server) Addr() *net.UDPAddr {
+	return s.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// NumCalls returns the calls that have not ended
+func (s *Server) NumCalls() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.numCallsLocked()
+}
+
+func (s *Server) numCallsLocked() int {
+	n := 0
+	for _, call := range s.calls {
+		if !call.ended.IsBroken() {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Server) readWorker() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFromUDPAddrPort(buf)
+		if err != nil {
+			if !s.stopped.IsBroken() {
+				s.params.Logger.Warnw("sip gateway stopped reading", err)
+			}
+			return
+		}
+		// keepalives of RFC 5626 are a bare CRLF
+		if n <= 4 {
+			continue
+		}
+
+		m, err := ParseMessage(buf[:n])
+		if err != nil {
+			s.params.Logger.Debugw("dropping invalid sip message", "error", err, "from", addr)
+			continue
+		}
+		if m.IsRequest() {
+			s.handleRequest(m, addr)
+		}
+	}
+}
+
+func (s *Server) isAllowed(addr netip.AddrPort) bool {
+	if len(s.allowed) == 0 {
+		return true
+	}
+	ip := addr.Addr().Unmap()
+	for _, prefix := range s.allowed {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) handleRequest(req *Message, addr netip.AddrPort) {
+	if !s.isAllowed(addr) {
+		if req.Method != MethodAck {
+			s.respond(req, addr, 403, "Forbidden")
+		}
+		return
+	}
+
+	s.lock.Lock()
+	call := s.calls[req.Get("Call-ID")]
+	s.lock.Unlock()
+
+	switch req.Method {
+	case MethodInvite:
+		if call != nil {
+			call.onInvite(req)
+			return
+		}
+		s.handleInvite(req, addr)
+
+	case MethodAck:
+		if call != nil {
+			call.onAck(req)
+		}
+
+	case MethodBye:
+		if call == nil {
+			s.respond(req, addr, 481, "Call/Transaction Does Not Exist")
+			return
+		}
+		s.respond(req, addr, 200, "OK")
+		call.end(EndReasonHangup)
+
+	case MethodCancel:
+		if call == nil {
+			s.respond(req, addr, 481, "Call/Transaction Does Not Exist")
+			return
+		}
+		s.respond(req, addr, 200, "OK")
+		call.onCancel()
+
+	case MethodOptions:
+		res := NewResponse(req, 200, "OK")
+		res.Add("Allow", strings.Join([]string{MethodInvite, MethodAck, MethodBye, MethodCancel, MethodOptions}, ", "))
+		res.Add("Accept", "application/sdp")
+		s.send(res, addr)
+
+	default:
+		s.respond(req, addr, 501, "Not Implemented")
+	}
+}
+
+func (s *Server) handleInvite(req *Message, addr netip.AddrPort) {
+	offer, err := ParseSDP(req.Body)
+	if err != nil {
+		s.params.Logger.Debugw("rejecting sip call without usable sdp", "error", err, "from", addr)
+		s.respond(req, addr, 488, "Not Acceptable Here")
+		return
+	}
+	format, telephoneEvent, err := offer.Negotiate(s.codecs)
+	if err != nil {
+		s.respond(req, addr, 488, "Not Acceptable Here")
+		return
+	}
+	remoteRTP, err := netip.ParseAddr(offer.Address)
+	if err != nil {
+		s.respond(req, addr, 488, "Not Acceptable Here")
+		return
+	}
+
+	s.lock.Lock()
+	if s.stopped.IsBroken() {
+		s.lock.Unlock()
+		s.respond(req, addr, 503, "Service Unavailable")
+		return
+	}
+	if s.params.Config.MaxCalls > 0 && s.numCallsLocked() >= s.params.Config.MaxCalls {
+		s.lock.Unlock()
+		s.params.Logger.Infow("rejecting sip call", "error", ErrTooManyCalls, "from", addr)
+		s.respond(req, addr, 503, "Service Unavailable")
+		return
+	}
+	rtpConn, err := s.listenRTPLocked()
+	if err != nil {
+		s.lock.Unlock()
+		s.params.Logger.Warnw("rejecting sip call", err, "from", addr)
+		s.respond(req, addr, 503, "Service Unavailable")
+		return
+	}
+	call, err := newCall(s, req, addr, rtpConn, format, telephoneEvent, netip.AddrPortFrom(remoteRTP.Unmap(), uint16(offer.Port)))
+	if err != nil {
+		s.lock.Unlock()
+		_ = rtpConn.Close()
+		s.respond(req, addr, 488, "Not Acceptable Here")
+		return
+	}
+	s.calls[call.ID] = call
+	s.lock.Unlock()
+
+	s.params.Logger.Infow("sip call incoming", "callID", call.ID, "from", call.From, "to", call.To, "codec", format.Name, "remote", addr)
+	call.sendProvisional(100, "Trying")
+	go call.admit()
+}
+
+// listenRTPLocked binds the next free even port of the RTP range, RTCP is not received
+func (s *Server) listenRTPLocked() (*net.UDPConn, error) {
+	start, end := s.params.Config.RTPPortStart, s.params.Config.RTPPortEnd
+	if start <= 0 || end < start {
+		return net.ListenUDP("udp", &net.UDPAddr{})
+	}
+
+	for i := 0; i <= (end-start)/2; i++ {
+		port := s.nextPort
+		s.nextPort += 2
+		if s.nextPort > end {
+			s.nextPort = start
+		}
+		if conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port}); err == nil {
+			return conn, nil
+		}
+	}
+	return nil, ErrNoRTPPort
+}
+
+func (s *Server) remove(call *Call) {
+	s.lock.Lock()
+	if s.calls[call.ID] == call {
+		delete(s.calls, call.ID)
+	}
+	s.lock.Unlock()
+}
+
+func (s *Server) respond(req *Message, addr netip.AddrPort, code int, reason string) {
+	res := NewResponse(req, code, reason)
+	if code >= 200 && HeaderParam(res.Get("To"), "tag") == "" {
+		res.Set("To", res.Get("To")+";tag="+randomToken(8))
+	}
+	s.send(res, addr)
+}
+
+func (s *Server) send(m *Message, addr netip.AddrPort) {
+	m.Set("User-Agent", userAgent)
+	if _, err := s.conn.WriteToUDPAddrPort(m.Marshal(), addr); err != nil && !s.stopped.IsBroken() {
+		s.params.Logger.Debugw("could not send sip message", "error", err, "to", addr)
+	}
+}
+
+// externalIP returns the address the gateway advertises to the trunk
+func (s *Server) externalIP() string {
+	if s.params.Config.ExternalIP != "" {
+		return s.params.Config.ExternalIP
+	}
+	if addr, ok := s.conn.LocalAddr().(*net.UDPAddr); ok && !addr.IP.IsUnspecified() {
+		return addr.IP.String()
+	}
+	return "127.0.0.1"
+}
+
+func randomToken(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,346 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sip
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const testOffer = "v=0\r\n" +
+	"o=- 1 1 IN IP4 127.0.0.1\r\n" +
+	"s=-\r\n" +
+	"c=IN IP4 127.0.0.1\r\n" +
+	"t=0 0\r\n" +
+	"m=video 4000 RTP/AVP 96\r\n" +
+	"a=rtpmap:96 VP8/90000\r\n" +
+	"m=audio %d RTP/AVP 8 0 101\r\n" +
+	"a=rtpmap:101 telephone-event/8000\r\n" +
+	"a=fmtp:101 0-16\r\n" +
+	"a=ptime:20\r\n"
+
+func TestParseMessage(t *testing.T) {
+	m, err := ParseMessage([]byte("INVITE sip:+15550100@gw.example SIP/2.0\r\n" +
+		"v: SIP/2.0/UDP 10.0.0.1:5060;branch=z9hG4bK1, SIP/2.0/UDP 10.0.0.2;branch=z9hG4bK2\r\n" +
+		"f: \"Alice\" <sip:+15550199@carrier.example>;tag=abc\r\n" +
+		"t: <sip:+15550100@gw.example>\r\n" +
+		"i: call-1\r\n" +
+		"CSeq: 7\r\n INVITE\r\n" +
+		"l: 4\r\n" +
+		"\r\n" +
+		"body and more"))
+	require.NoError(t, err)
+	require.True(t, m.IsRequest())
+	require.Equal(t, MethodInvite, m.Method)
+	require.Equal(t, []string{"SIP/2.0/UDP 10.0.0.1:5060;branch=z9hG4bK1", "SIP/2.0/UDP 10.0.0.2;branch=z9hG4bK2"}, m.Values("Via"))
+	require.Equal(t, "call-1", m.Get("call-id"))
+	require.Equal(t, []byte("body"), m.Body)
+
+	seq, method, err := m.CSeq()
+	require.NoError(t, err)
+	require.Equal(t, uint32(7), seq)
+	require.Equal(t, MethodInvite, method)
+
+	require.Equal(t, "abc", HeaderParam(m.Get("From"), "tag"))
+	require.Equal(t, "z9hG4bK1", HeaderParam(m.Get("Via"), "branch"))
+	require.Equal(t, "+15550199", URIUser(m.Get("From")))
+	require.Equal(t, "+15550100", URIUser(m.RequestURI))
+	require.Equal(t, "Alice", DisplayName(m.Get("From")))
+
+	res := NewResponse(m, 200, "OK")
+	parsed, err := ParseMessage(res.Marshal())
+	require.NoError(t, err)
+	require.False(t, parsed.IsRequest())
+	require.Equal(t, 200, parsed.StatusCode)
+	require.Equal(t, m.Values("Via"), parsed.Values("Via"))
+	require.Equal(t, "0", parsed.Get("Content-Length"))
+
+	_, err = ParseMessage([]byte("HELLO\r\n\r\n"))
+	require.ErrorIs(t, err, ErrInvalidMessage)
+}
+
+func TestSDP(t *testing.T) {
+	offer, err := ParseSDP([]byte(fmt.Sprintf(testOffer, 5004)))
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", offer.Address)
+	require.Equal(t, 5004, offer.Port)
+	require.Equal(t, 20, offer.PTime)
+	require.Equal(t, []Format{
+		{PayloadType: 8, Name: CodecPCMA, ClockRate: 8000, Channels: 1},
+		{PayloadType: 0, Name: CodecPCMU, ClockRate: 8000, Channels: 1},
+		{PayloadType: 101, Name: codecTelephoneEvent, ClockRate: 8000, Channels: 1},
+	}, offer.Formats)
+
+	// the preference of the gateway wins
+	format, telephoneEvent, err := offer.Negotiate([]string{CodecOpus, CodecPCMU, CodecPCMA})
+	require.NoError(t, err)
+	require.Equal(t, CodecPCMU, format.Name)
+	require.NotNil(t, telephoneEvent)
+	require.Equal(t, uint8(101), telephoneEvent.PayloadType)
+
+	_, _, err = offer.Negotiate([]string{CodecOpus})
+	require.ErrorIs(t, err, ErrNoCommonCodec)
+
+	answer := (&MediaAnswer{SessionID: 1, Address: "10.0.0.5", Port: 30000, Format: format, TelephoneEvent: telephoneEvent}).Marshal()
+	parsed, err := ParseSDP(answer)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.5", parsed.Address)
+	require.Equal(t, 30000, parsed.Port)
+	require.Equal(t, []Format{format, *telephoneEvent}, parsed.Formats)
+
+	_, err = ParseSDP([]byte("v=0\r\nc=IN IP4 127.0.0.1\r\nm=video 4000 RTP/AVP 96\r\n"))
+	require.ErrorIs(t, err, ErrNoAudio)
+}
+
+func TestRTP(t *testing.T) {
+	h := rtpHeader{Marker: true, PayloadType: 101, SequenceNumber: 65535, Timestamp: 1234, SSRC: 42}
+	b := append(h.append(nil), 1, 2, 3)
+	parsed, payload, err := parseRTP(b)
+	require.NoError(t, err)
+	require.Equal(t, h, parsed)
+	require.Equal(t, []byte{1, 2, 3}, payload)
+
+	_, _, err = parseRTP(b[:8])
+	require.ErrorIs(t, err, ErrInvalidRTP)
+
+	for _, name := range []string{CodecPCMU, CodecPCMA} {
+		c, err := newCodec(Format{Name: name})
+		require.NoError(t, err)
+		encoded, err := c.encode([]int16{0, 1000, -1000, 30000}, nil)
+		require.NoError(t, err)
+		require.Len(t, encoded, 4)
+		decoded, err := c.decode(encoded, nil)
+		require.NoError(t, err)
+		for i, sample := range []int16{0, 1000, -1000, 30000} {
+			require.InDelta(t, sample, decoded[i], 1000, name)
+		}
+	}
+}
+
+type testHandler struct {
+	audio chan []int16
+	dtmf  chan audio.TelephoneEventUpdate
+	end   chan EndReason
+}
+
+func (h *testHandler) OnAudio(pcm []int16, _ int) {
+	h.audio <- append([]int16(nil), pcm...)
+}
+
+func (h *testHandler) OnDTMF(update audio.TelephoneEventUpdate) {
+	h.dtmf <- update
+}
+
+func (h *testHandler) OnEnd(reason EndReason) {
+	h.end <- reason
+}
+
+// testTrunk is the carrier side of a call
+type testTrunk struct {
+	t      *testing.T
+	sip    *net.UDPConn
+	rtp    *net.UDPConn
+	addr   *net.UDPAddr
+	callID string
+}
+
+func newTestTrunk(t *testing.T, server *Server) *testTrunk {
+	sip, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	rtp, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = sip.Close()
+		_ = rtp.Close()
+	})
+	return &testTrunk{
+		t:      t,
+		sip:    sip,
+		rtp:    rtp,
+		addr:   &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: server.Addr().Port},
+		callID: "call-1",
+	}
+}
+
+func (tr *testTrunk) request(method string, cseq int, body string) {
+	m := &Message{Method: method, RequestURI: "sip:+15550100@127.0.0.1"}
+	m.Add("Via", "SIP/2.0/UDP "+tr.sip.LocalAddr().String()+";branch=z9hG4bK"+method)
+	m.Add("From", `"Alice" <sip:+15550199@127.0.0.1>;tag=trunk`)
+	m.Add("To", "<sip:+15550100@127.0.0.1>")
+	m.Add("Call-ID", tr.callID)
+	m.Add("CSeq", fmt.Sprintf("%d %s", cseq, method))
+	m.Add("Contact", "<sip:+15550199@"+tr.sip.LocalAddr().String()+">")
+	if body != "" {
+		m.Add("Content-Type", "application/sdp")
+		m.Body = []byte(body)
+	}
+	_, err := tr.sip.WriteToUDP(m.Marshal(), tr.addr)
+	require.NoError(tr.t, err)
+}
+
+func (tr *testTrunk) read() *Message {
+	buf := make([]byte, maxMessageSize)
+	require.NoError(tr.t, tr.sip.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := tr.sip.Read(buf)
+	require.NoError(tr.t, err)
+	m, err := ParseMessage(buf[:n])
+	require.NoError(tr.t, err)
+	return m
+}
+
+// readStatus skips provisional responses and retransmissions of other statuses
+func (tr *testTrunk) readStatus(code int) *Message {
+	for {
+		if m := tr.read(); m.StatusCode == code {
+			return m
+		}
+	}
+}
+
+func (tr *testTrunk) sendRTP(to netip.AddrPort, h rtpHeader, payload []byte) {
+	_, err := tr.rtp.WriteToUDPAddrPort(append(h.append(nil), payload...), to)
+	require.NoError(tr.t, err)
+}
+
+func TestCall(t *testing.T) {
+	handler := &testHandler{
+		audio: make(chan []int16, 10),
+		dtmf:  make(chan audio.TelephoneEventUpdate, 10),
+		end:   make(chan EndReason, 1),
+	}
+	calls := make(chan *Call, 1)
+	config := DefaultConfig
+	config.Port = 0
+	config.RTPPortStart = 0
+	config.ExternalIP = "127.0.0.1"
+	server, err := NewServer(ServerParams{
+		Config: config,
+		Logger: logger.GetLogger(),
+		OnInvite: func(call *Call) (CallHandler, error) {
+			calls <- call
+			return handler, nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	trunk := newTestTrunk(t, server)
+	trunk.request(MethodInvite, 1, fmt.Sprintf(testOffer, trunk.rtp.LocalAddr().(*net.UDPAddr).Port))
+
+	call := <-calls
+	require.Equal(t, "call-1", call.ID)
+	require.Equal(t, "+15550199", call.From)
+	require.Equal(t, "Alice", call.FromName)
+	require.Equal(t, "+15550100", call.To)
+	require.Equal(t, CodecPCMU, call.Format.Name)
+	require.True(t, call.CanSendDTMF())
+
+	ok := trunk.readStatus(200)
+	require.NotEmpty(t, HeaderParam(ok.Get("To"), "tag"))
+	answer, err := ParseSDP(ok.Body)
+	require.NoError(t, err)
+	require.Equal(t, CodecPCMU, answer.Formats[0].Name)
+	trunk.request(MethodAck, 1, "")
+	require.Equal(t, 1, server.NumCalls())
+
+	gateway := netip.AddrPortFrom(netip.MustParseAddr("127.0.0.1"), uint16(answer.Port))
+
+	// audio of the caller
+	payload, _ := g711Codec{}.encode(make([]int16, 160), nil)
+	trunk.sendRTP(gateway, rtpHeader{PayloadType: 0, SequenceNumber: 1, Timestamp: 160, SSRC: 7}, payload)
+	require.Len(t, <-handler.audio, 160)
+
+	// a digit, with its end sent three times
+	trunk.sendRTP(gateway, rtpHeader{Marker: true, PayloadType: 101, SequenceNumber: 2, Timestamp: 320, SSRC: 7}, []byte{5, 10, 0, 160})
+	trunk.sendRTP(gateway, rtpHeader{PayloadType: 101, SequenceNumber: 3, Timestamp: 320, SSRC: 7}, []byte{5, 0x8a, 3, 32})
+	trunk.sendRTP(gateway, rtpHeader{PayloadType: 101, SequenceNumber: 4, Timestamp: 320, SSRC: 7}, []byte{5, 0x8a, 3, 32})
+	start := <-handler.dtmf
+	require.Equal(t, "5", start.Digit)
+	require.Equal(t, audio.TelephoneEventPhaseStart, start.Phase)
+	end := <-handler.dtmf
+	require.Equal(t, audio.TelephoneEventPhaseEnd, end.Phase)
+	require.Equal(t, 100*time.Millisecond, end.Duration)
+
+	// audio of the room, 20 ms at 16 kHz is one frame
+	require.NoError(t, call.WriteAudio(make([]int16, 320), 16000))
+	buf := make([]byte, maxRTPPacketSize)
+	require.NoError(t, trunk.rtp.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, err := trunk.rtp.Read(buf)
+	require.NoError(t, err)
+	h, received, err := parseRTP(buf[:n])
+	require.NoError(t, err)
+	require.Equal(t, uint8(0), h.PayloadType)
+	require.True(t, h.Marker)
+	require.Len(t, received, 160)
+
+	require.NoError(t, call.SendDTMF("#"))
+	n, err = trunk.rtp.Read(buf)
+	require.NoError(t, err)
+	h, received, err = parseRTP(buf[:n])
+	require.NoError(t, err)
+	require.Equal(t, uint8(101), h.PayloadType)
+	require.Equal(t, byte(11), received[0])
+	require.ErrorIs(t, call.SendDTMF("x"), ErrInvalidDigit)
+
+	trunk.request(MethodBye, 2, "")
+	trunk.readStatus(200)
+	require.Equal(t, EndReasonHangup, <-handler.end)
+	require.Equal(t, 0, server.NumCalls())
+	require.ErrorIs(t, call.WriteAudio(make([]int16, 320), 16000), ErrCallEnded)
+}
+
+func TestRejectCall(t *testing.T) {
+	config := DefaultConfig
+	config.Port = 0
+	config.RTPPortStart = 0
+	config.AllowedNetworks = []string{"127.0.0.0/8"}
+	server, err := NewServer(ServerParams{
+		Config: config,
+		Logger: logger.GetLogger(),
+		OnInvite: func(call *Call) (CallHandler, error) {
+			return nil, ErrNotFound
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+	defer server.Stop()
+
+	trunk := newTestTrunk(t, server)
+	trunk.request(MethodOptions, 1, "")
+	options := trunk.readStatus(200)
+	require.True(t, strings.Contains(options.Get("Allow"), MethodInvite))
+
+	trunk.request(MethodInvite, 2, fmt.Sprintf(testOffer, trunk.rtp.LocalAddr().(*net.UDPAddr).Port))
+	trunk.readStatus(404)
+
+	trunk.callID = "call-2"
+	trunk.request(MethodInvite, 3, strings.Replace(fmt.Sprintf(testOffer, 5004), "8 0 101", "9", 1))
+	trunk.readStatus(488)
+
+	_, err = NewServer(ServerParams{Config: Config{AllowedNetworks: []string{"10.0.0.1"}}})
+	require.ErrorIs(t, err, ErrInvalidNetwork)
+}
@@ -0,0 +1,278 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srt
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	handshakeTimeout        = 5 * time.Second
+	handshakeRetransmission = 250 * time.Millisecond
+	minCallBackoff          = time.Second
+	maxCallBackoff          = 30 * time.Second
+)
+
+var ErrHandshakeTimeout = errors.New("srt handshake timed out")
+
+// callWorker receives the stream of a listener, calling it again whenever the connection ends
+func (s *Server) callWorker(caller CallerConfig) {
+	defer s.callers.Done()
+
+	logger := s.params.Logger.WithValues("address", caller.Address, "room", caller.Room, "identity", caller.Identity)
+	backoff := minCallBackoff
+	for {
+		start := time.Now()
+		err := s.call(caller, logger)
+		if s.stopped.IsBroken() {
+			return
+		}
+		if time.Since(start) > maxCallBackoff {
+			backoff = minCallBackoff
+		}
+		logger.Warnw("srt call ended", err, "retry", backoff)
+
+		select {
+		case <-time.After(backoff):
+		case <-s.stopped.Watch():
+			return
+		}
+		backoff = min(2*backoff, maxCallBackoff)
+	}
+}
+
+// call connects to the listener and receives its stream until the connection ends
+func (s *Server) call(caller CallerConfig, logger logger.Logger) error {
+	if err := s.addStream(); err != nil {
+		return err
+	}
+	defer s.removeStream()
+
+	addr, err := net.ResolveUDPAddr("udp", caller.Address)
+	if err != nil {
+		return err
+	}
+	udp, err := net.DialUDP("udp", nil, addr)
+	if err != nil {
+		return err
+	}
+	defer udp.Close()
+
+	// the socket is closed on stop while the handshake is in progress
+	handshaking := make(chan struct{})
+	go func() {
+		select {
+		case <-s.stopped.Watch():
+			_ = udp.Close()
+		case <-handshaking:
+		}
+	}()
+	response, keys, err := s.handshake(udp, caller)
+	close(handshaking)
+	if err != nil {
+		return err
+	}
+	_ = udp.SetReadDeadline(time.Time{})
+
+	publish, err := s.params.OnCall(caller)
+	if err != nil {
+		return err
+	}
+
+	passphrase := caller.Passphrase
+	if passphrase == "" {
+		passphrase = s.params.Config.Passphrase
+	}
+	latency := max(s.params.Config.Latency, time.Duration(response.hsreq.sendDelay)*time.Millisecond)
+	c := newConn(connParams{
+		logger:       logger,
+		socketID:     response.localSocketID,
+		peerSocketID: response.socketID,
+		peerAddr:     addr,
+		peerSeq:      response.initialSeq,
+		latency:      latency,
+		keys:         keys,
+		passphrase:   passphrase,
+		send: func(b []byte) error {
+			_, err := udp.Write(b)
+			return err
+		},
+		publish: publish,
+	})
+
+	s.lock.Lock()
+	if s.stopped.IsBroken() {
+		s.lock.Unlock()
+		return ErrConnClosed
+	}
+	s.calls[c] = struct{}{}
+	s.lock.Unlock()
+	defer func() {
+		s.lock.Lock()
+		delete(s.calls, c)
+		s.lock.Unlock()
+	}()
+
+	go func() {
+		for {
+			b := make([]byte, maxPacketSize)
+			n, err := udp.Read(b)
+			if err != nil {
+				c.failWith(err)
+				return
+			}
+			if n >= headerSize && destSocketID(b[:n]) == c.params.socketID {
+				c.receive(b[:n])
+			}
+		}
+	}()
+
+	logger.Infow("srt stream started", "latency", latency, "encrypted", keys != nil)
+	c.run()
+	<-c.Done()
+	logger.Infow(
+		"srt stream ended",
+		"reason", c.err,
+		"received", c.received,
+		"retransmitted", c.retransmitted,
+		"dropped", c.dropped,
+	)
+	return c.err
+}
+
+// callerHandshake is the conclusion of the listener, with the socket id it was sent to
+type callerHandshake struct {
+	*handshake
+	localSocketID uint32
+}
+
+// handshake runs the induction and conclusion of a caller, returns the conclusion of the listener and the
+// keys the stream is encrypted with
+func (s *Server) handshake(udp *net.UDPConn, caller CallerConfig) (*callerHandshake, *cryptoKeys, error) {
+	socketID := randomSocketID()
+	seq := randomSeq()
+
+	induction, err := exchange(udp, socketID, &handshake{
+		version:    hsVersion4,
+		extension:  hsUDTDgram,
+		initialSeq: seq,
+		mtu:        mtu,
+		flowWindow: flowWindow,
+		typ:        hsTypeInduction,
+		socketID:   socketID,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if induction.version != hsVersion5 || induction.extension != hsMagic {
+		return nil, nil, &RejectError{Reason: RejectVersion, Err: ErrInvalidHandshake}
+	}
+
+	latency := uint16(s.params.Config.Latency.Milliseconds())
+	request := &handshake{
+		version:    hsVersion5,
+		extension:  hsExtHSREQ,
+		initialSeq: seq,
+		mtu:        mtu,
+		flowWindow: flowWindow,
+		typ:        hsTypeConclusion,
+		socketID:   socketID,
+		cookie:     induction.cookie,
+		hsreq: &hsExtension{
+			version:   srtVersion,
+			flags:     srtFlags,
+			recvDelay: latency,
+			sendDelay: latency,
+		},
+		streamID: caller.StreamID,
+	}
+	extensions := []uint16{extHSREQ}
+
+	passphrase := caller.Passphrase
+	if passphrase == "" {
+		passphrase = s.params.Config.Passphrase
+	}
+	var keys *cryptoKeys
+	if passphrase != "" {
+		if keys, request.km, err = newKeyMaterial(passphrase, s.params.Config.PBKeyLen); err != nil {
+			return nil, nil, err
+		}
+		request.encryption = uint16(s.params.Config.PBKeyLen / 8)
+		request.extension |= hsExtKMREQ
+		extensions = append(extensions, extKMREQ)
+	}
+	if caller.StreamID != "" {
+		request.extension |= hsExtConfig
+		extensions = append(extensions, extSID)
+	}
+
+	conclusion, err := exchange(udp, socketID, request, extensions...)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch {
+	case conclusion.typ >= rejectionBase && conclusion.typ != hsTypeConclusion:
+		return nil, nil, &RejectError{Reason: conclusion.typ - rejectionBase}
+	case conclusion.typ != hsTypeConclusion || conclusion.version != hsVersion5 || conclusion.hsreq == nil:
+		return nil, nil, &RejectError{Reason: RejectVersion, Err: ErrInvalidHandshake}
+	case conclusion.kmState == kmStateBadSecret || conclusion.kmState == kmStateNoSecret:
+		return nil, nil, &RejectError{Reason: RejectBadSecret, Err: ErrBadSecret}
+	case keys != nil && conclusion.km == nil:
+		return nil, nil, &RejectError{Reason: RejectUnsecure, Err: ErrUnsecure}
+	}
+	return &callerHandshake{handshake: conclusion, localSocketID: socketID}, keys, nil
+}
+
+// exchange sends a handshake request until a response arrives
+func exchange(udp *net.UDPConn, socketID uint32, request *handshake, extensions ...uint16) (*handshake, error) {
+	packet := (&controlPacket{typ: ctrlHandshake, cif: request.marshal(extensions...)}).marshal(nil)
+	b := make([]byte, maxPacketSize)
+	deadline := time.Now().Add(handshakeTimeout)
+	for time.Now().Before(deadline) {
+		if _, err := udp.Write(packet); err != nil {
+			return nil, err
+		}
+		_ = udp.SetReadDeadline(time.Now().Add(handshakeRetransmission))
+		for {
+			n, err := udp.Read(b)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					break
+				}
+				return nil, err
+			}
+			if n < headerSize || destSocketID(b[:n]) != socketID {
+				continue
+			}
+			cp, err := parseControlPacket(b[:n])
+			if err != nil || cp.typ != ctrlHandshake {
+				continue
+			}
+			h, err := parseHandshake(cp.cif)
+			if err != nil || (h.typ == hsTypeInduction && request.typ != hsTypeInduction) {
+				// a late response to the induction
+				continue
+			}
+			return h, nil
+		}
+	}
+	return nil, ErrHandshakeTimeout
+}
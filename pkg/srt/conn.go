@@ -0,0 +1,478 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srt
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+)
+
+const (
+	ackInterval       = 10 * time.Millisecond
+	minNAKInterval    = 20 * time.Millisecond
+	keepaliveInterval = time.Second
+	// a peer sending nothing, keepalives included, for this long is gone
+	peerTimeout = 5 * time.Second
+
+	// packets received ahead of delivery, beyond it they are dropped
+	maxBufferedPackets = flowWindow
+	// ACKs kept for the RTT of their ACKACK
+	maxPendingACKs = 64
+	// NAKs fit a packet
+	maxNAKRanges = 160
+)
+
+var (
+	ErrPeerTimeout  = errors.New("srt peer timed out")
+	ErrPeerShutdown = errors.New("srt peer shut down")
+	ErrConnClosed   = errors.New("srt connection closed")
+)
+
+// PublishFunc returns the publisher of a program of the MPEG-TS a stream carries, index is the position of the
+// program in the PAT. An error skips the program.
+type PublishFunc func(program uint16, index int) (ingest.Publisher, error)
+
+type connParams struct {
+	logger       logger.Logger
+	socketID     uint32
+	peerSocketID uint32
+	peerAddr     *net.UDPAddr
+	// initial sequence number of the peer
+	peerSeq uint32
+	latency time.Duration
+	// nil when the stream is not encrypted
+	keys       *cryptoKeys
+	passphrase string
+	send       func(b []byte) error
+	publish    PublishFunc
+}
+
+type bufferedPacket struct {
+	// timestamp of the sender, unwrapped
+	timestamp int64
+	payload   []byte
+}
+
+type seqRange struct {
+	from uint32
+	to   uint32
+}
+
+// conn receives a live stream of MPEG-TS, acknowledging packets, asking for lost ones to be sent again and
+// delivering them in order once the latency passed. Packets still missing then are dropped.
+type conn struct {
+	params   connParams
+	start    time.Time
+	incoming chan []byte
+	delivery chan []byte
+	// errors ending the connection, handled by the worker
+	fail   chan error
+	closed core.Fuse
+	done   chan struct{}
+	err    error
+
+	// state of the worker
+	keys         *cryptoKeys
+	lastReceived time.Time
+	lastSent     time.Time
+	maxSeq       uint32
+	nextDeliver  uint32
+	buffer       map[uint32]*bufferedPacket
+	losses       []seqRange
+	ackNumber    uint32
+	lastACKSeq   uint32
+	acks         map[uint32]time.Time
+	rtt          time.Duration
+	rttVar       time.Duration
+	lastNAK      time.Time
+	// local time of timestamp 0 of the sender
+	base      time.Time
+	hasBase   bool
+	lastTS    uint32
+	tsWrap    int64
+	rateStart time.Time
+	rateCount uint32
+	rateBytes uint32
+	pktRate   uint32
+	byteRate  uint32
+
+	received      uint64
+	retransmitted uint64
+	dropped       uint64
+	undecryptable uint64
+}
+
+func newConn(params connParams) *conn {
+	now := time.Now()
+	return &conn{
+		params:       params,
+		start:        now,
+		incoming:     make(chan []byte, 1024),
+		delivery:     make(chan []byte, 1024),
+		fail:         make(chan error, 1),
+		done:         make(chan struct{}),
+		keys:         params.keys,
+		lastReceived: now,
+		lastSent:     now,
+		maxSeq:       seqAdd(params.peerSeq, -1),
+		nextDeliver:  params.peerSeq,
+		lastACKSeq:   params.peerSeq,
+		buffer:       make(map[uint32]*bufferedPacket),
+		acks:         make(map[uint32]time.Time),
+		rtt:          100 * time.Millisecond,
+		rttVar:       50 * time.Millisecond,
+		rateStart:    now,
+	}
+}
+
+// receive queues a packet of the peer, dropped if the worker falls behind
+func (c *conn) receive(b []byte) {
+	select {
+	case c.incoming <- b:
+	default:
+	}
+}
+
+// close ends the connection, telling the peer
+func (c *conn) close() {
+	c.failWith(ErrConnClosed)
+}
+
+func (c *conn) failWith(err error) {
+	select {
+	case c.fail <- err:
+	default:
+	}
+}
+
+// Done is closed once the publishers of the stream were closed
+func (c *conn) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *conn) run() {
+	go c.deliveryWorker()
+
+	ticker := time.NewTicker(ackInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case b := <-c.incoming:
+			c.handlePacket(b, time.Now())
+		case now := <-ticker.C:
+			c.tick(now)
+		case err := <-c.fail:
+			c.closeWith(err, true)
+		case <-c.closed.Watch():
+			close(c.delivery)
+			return
+		}
+	}
+}
+
+// closeWith is called by the worker only
+func (c *conn) closeWith(err error, shutdown bool) {
+	if c.closed.IsBroken() {
+		return
+	}
+	c.err = err
+	if shutdown {
+		c.sendControl(&controlPacket{typ: ctrlShutdown, cif: make([]byte, 4)})
+	}
+	c.closed.Break()
+}
+
+// deliveryWorker demuxes the stream apart from the worker, so that publishers blocking do not delay ACKs
+func (c *conn) deliveryWorker() {
+	defer close(c.done)
+
+	demuxer := ingest.NewTSDemuxer(ingest.TSDemuxerParams{
+		OnProgram: c.params.publish,
+		OnSkipped: func(program uint16, pid uint16, err error) {
+			c.params.logger.Warnw("not publishing mpeg-ts stream", err, "program", program, "pid", pid)
+		},
+	})
+	defer demuxer.Close()
+
+	for payload := range c.delivery {
+		if err := demuxer.Write(payload); err != nil {
+			c.failWith(err)
+			// drained, the worker blocks on a full channel otherwise
+			for range c.delivery {
+			}
+			return
+		}
+	}
+}
+
+func (c *conn) handlePacket(b []byte, now time.Time) {
+	c.lastReceived = now
+	if isControl(b) {
+		cp, err := parseControlPacket(b)
+		if err != nil {
+			return
+		}
+		c.handleControl(cp, now)
+		return
+	}
+
+	p, err := parseDataPacket(b)
+	if err != nil {
+		return
+	}
+	c.handleData(p, now)
+}
+
+func (c *conn) handleControl(cp controlPacket, now time.Time) {
+	switch cp.typ {
+	case ctrlACKACK:
+		if sent, ok := c.acks[cp.info]; ok {
+			delete(c.acks, cp.info)
+			sample := now.Sub(sent)
+			diff := c.rtt - sample
+			if diff < 0 {
+				diff = -diff
+			}
+			c.rttVar = (3*c.rttVar + diff) / 4
+			c.rtt = (7*c.rtt + sample) / 8
+		}
+
+	case ctrlShutdown:
+		c.closeWith(ErrPeerShutdown, false)
+
+	case ctrlDropRequest:
+		// the sender gave up on these, they are not asked for again
+		if len(cp.cif) >= 8 {
+			c.removeLosses(binary.BigEndian.Uint32(cp.cif[0:4])&seqMask, binary.BigEndian.Uint32(cp.cif[4:8])&seqMask)
+		}
+
+	case ctrlUserDefined:
+		if cp.subtype == ctrlSubtypeKMREQ {
+			c.refreshKeys(cp.cif)
+		}
+	}
+}
+
+// refreshKeys takes the keys the sender announces before switching to them
+func (c *conn) refreshKeys(km []byte) {
+	keys, err := parseKeyMaterial(km, c.params.passphrase)
+	if err != nil {
+		c.params.logger.Warnw("could not refresh srt keys", err)
+		return
+	}
+	if c.keys != nil {
+		if keys.even == nil {
+			keys.even = c.keys.even
+		}
+		if keys.odd == nil {
+			keys.odd = c.keys.odd
+		}
+	}
+	c.keys = keys
+	c.sendControl(&controlPacket{typ: ctrlUserDefined, subtype: ctrlSubtypeKMRSP, cif: km})
+}
+
+func (c *conn) handleData(p dataPacket, now time.Time) {
+	c.received++
+	c.rateCount++
+	c.rateBytes += uint32(len(p.payload))
+
+	if seqDiff(p.seq, c.nextDeliver) < 0 {
+		// delivered or dropped already
+		return
+	}
+	if _, ok := c.buffer[p.seq]; ok {
+		return
+	}
+	if len(c.buffer) >= maxBufferedPackets {
+		c.dropped++
+		return
+	}
+
+	if d := seqDiff(p.seq, c.maxSeq); d > 0 {
+		if d > 1 {
+			lost := seqRange{from: seqAdd(c.maxSeq, 1), to: seqAdd(p.seq, -1)}
+			c.losses = append(c.losses, lost)
+			c.sendNAK([]seqRange{lost}, now)
+		}
+		c.maxSeq = p.seq
+	} else {
+		c.retransmitted++
+		c.removeLosses(p.seq, p.seq)
+	}
+
+	if p.keyFlags != 0 {
+		if c.keys == nil || c.keys.xor(p.seq, p.keyFlags, p.payload) != nil {
+			c.undecryptable++
+			return
+		}
+	}
+
+	if !c.hasBase {
+		c.base = now.Add(-time.Duration(p.timestamp) * time.Microsecond)
+		c.lastTS = p.timestamp
+		c.hasBase = true
+	}
+	c.buffer[p.seq] = &bufferedPacket{
+		timestamp: c.unwrapTimestamp(p.timestamp),
+		payload:   p.payload,
+	}
+}
+
+// unwrapTimestamp extends the 32 bit microseconds of the sender, which wrap after about 71 minutes
+func (c *conn) unwrapTimestamp(ts uint32) int64 {
+	const wrap = int64(1) << 32
+	switch {
+	case ts < c.lastTS && c.lastTS-ts > 1<<31:
+		c.tsWrap += wrap
+		c.lastTS = ts
+	case ts > c.lastTS && ts-c.lastTS > 1<<31:
+		// sent again from before the last wrap
+		return c.tsWrap - wrap + int64(ts)
+	case ts > c.lastTS:
+		c.lastTS = ts
+	}
+	return c.tsWrap + int64(ts)
+}
+
+func (c *conn) deadline(p *bufferedPacket) time.Time {
+	return c.base.Add(time.Duration(p.timestamp)*time.Microsecond + c.params.latency)
+}
+
+func (c *conn) tick(now time.Time) {
+	c.deliver(now)
+	c.sendACK(now)
+
+	if len(c.losses) > 0 && now.Sub(c.lastNAK) >= max(minNAKInterval, c.rtt+4*c.rttVar) {
+		c.sendNAK(c.losses, now)
+	}
+	if now.Sub(c.lastSent) >= keepaliveInterval {
+		c.sendControl(&controlPacket{typ: ctrlKeepalive, cif: make([]byte, 4)})
+	}
+	if now.Sub(c.rateStart) >= time.Second {
+		c.pktRate, c.byteRate = c.rateCount, c.rateBytes
+		c.rateCount, c.rateBytes = 0, 0
+		c.rateStart = now
+	}
+	if now.Sub(c.lastReceived) >= peerTimeout {
+		c.closeWith(ErrPeerTimeout, true)
+	}
+}
+
+// deliver hands on the packets whose time came, in order, skipping those still missing when a later one is due
+func (c *conn) deliver(now time.Time) {
+	for len(c.buffer) > 0 {
+		p, ok := c.buffer[c.nextDeliver]
+		if !ok {
+			var next uint32
+			for seq, q := range c.buffer {
+				if p == nil || seqDiff(seq, next) < 0 {
+					next, p = seq, q
+				}
+			}
+			if now.Before(c.deadline(p)) {
+				return
+			}
+			c.dropped += uint64(seqDiff(next, c.nextDeliver))
+			c.removeLosses(c.nextDeliver, seqAdd(next, -1))
+			c.nextDeliver = next
+		} else if now.Before(c.deadline(p)) {
+			return
+		}
+
+		delete(c.buffer, c.nextDeliver)
+		c.nextDeliver = seqAdd(c.nextDeliver, 1)
+		select {
+		case c.delivery <- p.payload:
+		case <-c.closed.Watch():
+			return
+		}
+	}
+}
+
+// removeLosses takes first to last off the packets to be asked for
+func (c *conn) removeLosses(first, last uint32) {
+	var kept []seqRange
+	for _, r := range c.losses {
+		if seqDiff(r.to, first) < 0 || seqDiff(r.from, last) > 0 {
+			kept = append(kept, r)
+			continue
+		}
+		if seqDiff(r.from, first) < 0 {
+			kept = append(kept, seqRange{from: r.from, to: seqAdd(first, -1)})
+		}
+		if seqDiff(r.to, last) > 0 {
+			kept = append(kept, seqRange{from: seqAdd(last, 1), to: r.to})
+		}
+	}
+	c.losses = kept
+}
+
+// sendACK acknowledges the packets up to the first missing one, when that advanced
+func (c *conn) sendACK(now time.Time) {
+	ackSeq := seqAdd(c.maxSeq, 1)
+	if len(c.losses) > 0 {
+		ackSeq = c.losses[0].from
+	}
+	if ackSeq == c.lastACKSeq {
+		return
+	}
+	c.lastACKSeq = ackSeq
+	c.ackNumber++
+	c.acks[c.ackNumber] = now
+	delete(c.acks, c.ackNumber-maxPendingACKs)
+
+	cif := make([]byte, 0, 28)
+	cif = binary.BigEndian.AppendUint32(cif, ackSeq)
+	cif = binary.BigEndian.AppendUint32(cif, uint32(c.rtt.Microseconds()))
+	cif = binary.BigEndian.AppendUint32(cif, uint32(c.rttVar.Microseconds()))
+	cif = binary.BigEndian.AppendUint32(cif, uint32(maxBufferedPackets-len(c.buffer)))
+	cif = binary.BigEndian.AppendUint32(cif, c.pktRate)
+	cif = binary.BigEndian.AppendUint32(cif, c.pktRate)
+	cif = binary.BigEndian.AppendUint32(cif, c.byteRate)
+	c.sendControl(&controlPacket{typ: ctrlACK, info: c.ackNumber, cif: cif})
+}
+
+func (c *conn) sendNAK(losses []seqRange, now time.Time) {
+	c.lastNAK = now
+	var cif []byte
+	for _, r := range losses[:min(len(losses), maxNAKRanges)] {
+		if r.from == r.to {
+			cif = binary.BigEndian.AppendUint32(cif, r.from)
+		} else {
+			cif = binary.BigEndian.AppendUint32(cif, r.from|0x80000000)
+			cif = binary.BigEndian.AppendUint32(cif, r.to)
+		}
+	}
+	c.sendControl(&controlPacket{typ: ctrlNAK, cif: cif})
+}
+
+func (c *conn) sendControl(cp *controlPacket) {
+	cp.timestamp = uint32(time.Since(c.start).Microseconds())
+	cp.socketID = c.params.peerSocketID
+	c.lastSent = time.Now()
+	if err := c.params.send(cp.marshal(nil)); err != nil {
+		c.params.logger.Debugw("could not send srt packet", "error", err)
+	}
+}
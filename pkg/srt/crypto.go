@@ -0,0 +1,218 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	// key material messages, of HSv5 handshakes and key refreshes
+	kmVersionType = 0x12
+	kmSign        = 0x2029
+	kmCipherCTR   = 2
+	kmSE          = 2
+	kmHeaderSize  = 16
+	kmSaltSize    = 16
+
+	// passphrases derive keys with PBKDF2 over the end of the salt
+	pbkdf2SaltSize   = 8
+	pbkdf2Iterations = 2048
+
+	keyWrapOverhead = 8
+
+	keyFlagEven = 0x1
+	keyFlagOdd  = 0x2
+
+	// states of a failed key exchange, sent as a single word KMRSP
+	kmStateNoSecret  = 3
+	kmStateBadSecret = 4
+
+	MinPassphraseLength = 10
+	MaxPassphraseLength = 79
+)
+
+var (
+	ErrBadSecret          = errors.New("srt passphrase does not match")
+	ErrInvalidKeyMaterial = errors.New("invalid srt key material")
+)
+
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// cryptoKeys decrypts the payload of data packets, AES-CTR with the even or odd key the packet names
+type cryptoKeys struct {
+	salt []byte
+	even cipher.Block
+	odd  cipher.Block
+}
+
+// parseKeyMaterial unwraps the keys of key material with the passphrase
+func parseKeyMaterial(km []byte, passphrase string) (*cryptoKeys, error) {
+	if len(km) < kmHeaderSize || km[0] != kmVersionType || binary.BigEndian.Uint16(km[1:3]) != kmSign ||
+		km[8] != kmCipherCTR {
+		return nil, ErrInvalidKeyMaterial
+	}
+	flags := km[3] & 0x03
+	saltSize := 4 * int(km[14])
+	keySize := 4 * int(km[15])
+	keys := 0
+	if flags&keyFlagEven != 0 {
+		keys++
+	}
+	if flags&keyFlagOdd != 0 {
+		keys++
+	}
+	if keys == 0 || saltSize != kmSaltSize || (keySize != 16 && keySize != 24 && keySize != 32) ||
+		len(km) < kmHeaderSize+saltSize+keyWrapOverhead+keys*keySize {
+		return nil, ErrInvalidKeyMaterial
+	}
+	salt := km[kmHeaderSize : kmHeaderSize+saltSize]
+	wrapped := km[kmHeaderSize+saltSize : kmHeaderSize+saltSize+keyWrapOverhead+keys*keySize]
+
+	kek, err := passphraseKey(passphrase, salt, keySize)
+	if err != nil {
+		return nil, err
+	}
+	sek, err := keyUnwrap(kek, wrapped)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &cryptoKeys{salt: append([]byte(nil), salt...)}
+	if flags&keyFlagEven != 0 {
+		if k.even, err = aes.NewCipher(sek[:keySize]); err != nil {
+			return nil, err
+		}
+		sek = sek[keySize:]
+	}
+	if flags&keyFlagOdd != 0 {
+		if k.odd, err = aes.NewCipher(sek[:keySize]); err != nil {
+			return nil, err
+		}
+	}
+	return k, nil
+}
+
+// newKeyMaterial generates an even key of keySize bytes, returns it with its key material
+func newKeyMaterial(passphrase string, keySize int) (*cryptoKeys, []byte, error) {
+	salt := make([]byte, kmSaltSize)
+	sek := make([]byte, keySize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, nil, err
+	}
+	if _, err := rand.Read(sek); err != nil {
+		return nil, nil, err
+	}
+
+	kek, err := passphraseKey(passphrase, salt, keySize)
+	if err != nil {
+		return nil, nil, err
+	}
+	even, err := aes.NewCipher(sek)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	km := []byte{
+		kmVersionType, kmSign >> 8, kmSign & 0xff, keyFlagEven,
+		0, 0, 0, 0,
+		kmCipherCTR, 0, kmSE, 0,
+		0, 0, kmSaltSize / 4, byte(keySize / 4),
+	}
+	km = append(km, salt...)
+	km = append(km, keyWrap(kek, sek)...)
+	return &cryptoKeys{salt: salt, even: even}, km, nil
+}
+
+func passphraseKey(passphrase string, salt []byte, keySize int) (cipher.Block, error) {
+	key, err := pbkdf2.Key(sha1.New, passphrase, salt[len(salt)-pbkdf2SaltSize:], pbkdf2Iterations, keySize)
+	if err != nil {
+		return nil, err
+	}
+	return aes.NewCipher(key)
+}
+
+// xor encrypts or decrypts a payload in place, the counter starts from the salt and the sequence number
+func (k *cryptoKeys) xor(seq uint32, keyFlags byte, payload []byte) error {
+	block := k.even
+	if keyFlags == keyFlagOdd {
+		block = k.odd
+	}
+	if block == nil {
+		return ErrBadSecret
+	}
+
+	var iv [aes.BlockSize]byte
+	binary.BigEndian.PutUint32(iv[10:14], seq)
+	for i := 0; i < 14; i++ {
+		iv[i] ^= k.salt[i]
+	}
+	cipher.NewCTR(block, iv[:]).XORKeyStream(payload, payload)
+	return nil
+}
+
+// keyWrap and keyUnwrap are the AES key wrap of RFC 3394
+func keyWrap(kek cipher.Block, plain []byte) []byte {
+	n := len(plain) / 8
+	out := make([]byte, 8+len(plain))
+	copy(out, keyWrapIV)
+	copy(out[8:], plain)
+
+	var b [16]byte
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b[:8], out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			kek.Encrypt(b[:], b[:])
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[8*i:], b[8:])
+		}
+	}
+	return out
+}
+
+func keyUnwrap(kek cipher.Block, wrapped []byte) ([]byte, error) {
+	if len(wrapped)%8 != 0 || len(wrapped) < 24 {
+		return nil, ErrInvalidKeyMaterial
+	}
+	n := len(wrapped)/8 - 1
+	a := append([]byte(nil), wrapped[:8]...)
+	r := append([]byte(nil), wrapped[8:]...)
+
+	var b [16]byte
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(a)^t)
+			copy(b[8:], r[8*(i-1):8*i])
+			kek.Decrypt(b[:], b[:])
+			copy(a, b[:8])
+			copy(r[8*(i-1):], b[8:])
+		}
+	}
+	if !bytes.Equal(a, keyWrapIV) {
+		return nil, ErrBadSecret
+	}
+	return r, nil
+}
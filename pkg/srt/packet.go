@@ -0,0 +1,343 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srt
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+const (
+	headerSize = 16
+
+	// control packet types
+	ctrlHandshake   = 0x0000
+	ctrlKeepalive   = 0x0001
+	ctrlACK         = 0x0002
+	ctrlNAK         = 0x0003
+	ctrlShutdown    = 0x0005
+	ctrlACKACK      = 0x0006
+	ctrlDropRequest = 0x0007
+	ctrlUserDefined = 0x7fff
+
+	// subtypes of user defined control packets refreshing keys
+	ctrlSubtypeKMREQ = 3
+	ctrlSubtypeKMRSP = 4
+
+	// handshake types, rejections are rejectionBase plus the reason
+	hsTypeConclusion = 0xffffffff
+	hsTypeInduction  = 0x00000001
+	rejectionBase    = 1000
+
+	hsVersion4 = 4
+	hsVersion5 = 5
+	// extension field of the induction response telling HSv5 support
+	hsMagic = 0x4a17
+	// extension field of induction requests, datagram sockets
+	hsUDTDgram = 2
+
+	// flags of the extension field of conclusions
+	hsExtHSREQ  = 0x1
+	hsExtKMREQ  = 0x2
+	hsExtConfig = 0x4
+
+	// handshake extension types
+	extHSREQ = 1
+	extHSRSP = 2
+	extKMREQ = 3
+	extKMRSP = 4
+	extSID   = 5
+
+	// flags of HSREQ and HSRSP
+	flagTSBPDSND    = 0x01
+	flagTSBPDRCV    = 0x02
+	flagCrypt       = 0x04
+	flagTLPKTDrop   = 0x08
+	flagPeriodicNAK = 0x10
+	flagRexmit      = 0x20
+
+	srtVersion = 0x010501
+	srtFlags   = flagTSBPDSND | flagTSBPDRCV | flagCrypt | flagTLPKTDrop | flagPeriodicNAK | flagRexmit
+
+	mtu        = 1500
+	flowWindow = 8192
+
+	// sequence numbers are 31 bits
+	seqMask = 0x7fffffff
+)
+
+// rejection reasons of handshakes, SRT_REJECT_REASON and the extended ones of access control
+const (
+	RejectUnknown      = 0
+	RejectPeer         = 2
+	RejectResource     = 3
+	RejectRogue        = 4
+	RejectVersion      = 8
+	RejectBadSecret    = 10
+	RejectUnsecure     = 11
+	RejectUnauthorized = 1401
+	RejectOverload     = 1402
+	RejectForbidden    = 1403
+	RejectBadMode      = 1405
+)
+
+var (
+	ErrInvalidPacket    = errors.New("invalid srt packet")
+	ErrInvalidHandshake = errors.New("invalid srt handshake")
+)
+
+// RejectError rejects the handshake of a stream with a reason
+type RejectError struct {
+	Reason uint32
+	Err    error
+}
+
+func (e *RejectError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return "srt handshake rejected"
+}
+
+func (e *RejectError) Unwrap() error {
+	return e.Err
+}
+
+// seqDiff returns a - b on the 31 bit sequence number circle
+func seqDiff(a, b uint32) int32 {
+	return int32((a-b)<<1) >> 1
+}
+
+func seqAdd(seq uint32, n int32) uint32 {
+	return (seq + uint32(n)) & seqMask
+}
+
+// --------------------------------------
+
+type dataPacket struct {
+	seq       uint32
+	position  byte
+	keyFlags  byte
+	timestamp uint32
+	socketID  uint32
+	payload   []byte
+}
+
+// isControl returns whether b is a control packet, b is at least a header long
+func isControl(b []byte) bool {
+	return b[0]&0x80 != 0
+}
+
+func destSocketID(b []byte) uint32 {
+	return binary.BigEndian.Uint32(b[12:16])
+}
+
+func parseDataPacket(b []byte) (dataPacket, error) {
+	if len(b) < headerSize || isControl(b) {
+		return dataPacket{}, ErrInvalidPacket
+	}
+	return dataPacket{
+		seq:       binary.BigEndian.Uint32(b[0:4]) & seqMask,
+		position:  b[4] >> 6,
+		keyFlags:  b[4] >> 3 & 0x03,
+		timestamp: binary.BigEndian.Uint32(b[8:12]),
+		socketID:  destSocketID(b),
+		payload:   b[headerSize:],
+	}, nil
+}
+
+func (p *dataPacket) marshal(b []byte) []byte {
+	b = binary.BigEndian.AppendUint32(b, p.seq&seqMask)
+	// a solo message, in order, numbered 1
+	b = binary.BigEndian.AppendUint32(b, uint32(p.position)<<30|1<<29|uint32(p.keyFlags)<<27|1)
+	b = binary.BigEndian.AppendUint32(b, p.timestamp)
+	b = binary.BigEndian.AppendUint32(b, p.socketID)
+	return append(b, p.payload...)
+}
+
+type controlPacket struct {
+	typ       uint16
+	subtype   uint16
+	info      uint32
+	timestamp uint32
+	socketID  uint32
+	cif       []byte
+}
+
+func parseControlPacket(b []byte) (controlPacket, error) {
+	if len(b) < headerSize || !isControl(b) {
+		return controlPacket{}, ErrInvalidPacket
+	}
+	return controlPacket{
+		typ:       binary.BigEndian.Uint16(b[0:2]) & 0x7fff,
+		subtype:   binary.BigEndian.Uint16(b[2:4]),
+		info:      binary.BigEndian.Uint32(b[4:8]),
+		timestamp: binary.BigEndian.Uint32(b[8:12]),
+		socketID:  destSocketID(b),
+		cif:       b[headerSize:],
+	}, nil
+}
+
+func (p *controlPacket) marshal(b []byte) []byte {
+	b = binary.BigEndian.AppendUint16(b, 0x8000|p.typ)
+	b = binary.BigEndian.AppendUint16(b, p.subtype)
+	b = binary.BigEndian.AppendUint32(b, p.info)
+	b = binary.BigEndian.AppendUint32(b, p.timestamp)
+	b = binary.BigEndian.AppendUint32(b, p.socketID)
+	return append(b, p.cif...)
+}
+
+// --------------------------------------
+
+const handshakeCIFSize = 48
+
+type handshake struct {
+	version    uint32
+	encryption uint16
+	extension  uint16
+	initialSeq uint32
+	mtu        uint32
+	flowWindow uint32
+	typ        uint32
+	socketID   uint32
+	cookie     uint32
+
+	hsreq *hsExtension
+	km    []byte
+	// single word KMRSP of a failed key exchange
+	kmState  uint32
+	streamID string
+}
+
+// hsExtension is the content of HSREQ and HSRSP
+type hsExtension struct {
+	version uint32
+	flags   uint32
+	// TSBPD delays in ms, of the side receiving and the side sending
+	recvDelay uint16
+	sendDelay uint16
+}
+
+func parseHandshake(cif []byte) (*handshake, error) {
+	if len(cif) < handshakeCIFSize {
+		return nil, ErrInvalidHandshake
+	}
+	h := &handshake{
+		version:    binary.BigEndian.Uint32(cif[0:4]),
+		encryption: binary.BigEndian.Uint16(cif[4:6]),
+		extension:  binary.BigEndian.Uint16(cif[6:8]),
+		initialSeq: binary.BigEndian.Uint32(cif[8:12]) & seqMask,
+		mtu:        binary.BigEndian.Uint32(cif[12:16]),
+		flowWindow: binary.BigEndian.Uint32(cif[16:20]),
+		typ:        binary.BigEndian.Uint32(cif[20:24]),
+		socketID:   binary.BigEndian.Uint32(cif[24:28]),
+		cookie:     binary.BigEndian.Uint32(cif[28:32]),
+	}
+
+	b := cif[handshakeCIFSize:]
+	for len(b) >= 4 {
+		typ := binary.BigEndian.Uint16(b[0:2])
+		n := 4 * int(binary.BigEndian.Uint16(b[2:4]))
+		if 4+n > len(b) {
+			return nil, ErrInvalidHandshake
+		}
+		content := b[4 : 4+n]
+		b = b[4+n:]
+
+		switch typ {
+		case extHSREQ, extHSRSP:
+			if len(content) < 12 {
+				return nil, ErrInvalidHandshake
+			}
+			h.hsreq = &hsExtension{
+				version:   binary.BigEndian.Uint32(content[0:4]),
+				flags:     binary.BigEndian.Uint32(content[4:8]),
+				recvDelay: binary.BigEndian.Uint16(content[8:10]),
+				sendDelay: binary.BigEndian.Uint16(content[10:12]),
+			}
+		case extKMREQ, extKMRSP:
+			if len(content) == 4 {
+				h.kmState = binary.BigEndian.Uint32(content)
+			} else {
+				h.km = content
+			}
+		case extSID:
+			h.streamID = decodeStreamID(content)
+		}
+	}
+	return h, nil
+}
+
+func (h *handshake) marshal(extensionTypes ...uint16) []byte {
+	b := make([]byte, 0, 256)
+	b = binary.BigEndian.AppendUint32(b, h.version)
+	b = binary.BigEndian.AppendUint16(b, h.encryption)
+	b = binary.BigEndian.AppendUint16(b, h.extension)
+	b = binary.BigEndian.AppendUint32(b, h.initialSeq)
+	b = binary.BigEndian.AppendUint32(b, h.mtu)
+	b = binary.BigEndian.AppendUint32(b, h.flowWindow)
+	b = binary.BigEndian.AppendUint32(b, h.typ)
+	b = binary.BigEndian.AppendUint32(b, h.socketID)
+	b = binary.BigEndian.AppendUint32(b, h.cookie)
+	// peer ip, not used by peers
+	b = append(b, make([]byte, 16)...)
+
+	for _, typ := range extensionTypes {
+		var content []byte
+		switch typ {
+		case extHSREQ, extHSRSP:
+			content = binary.BigEndian.AppendUint32(content, h.hsreq.version)
+			content = binary.BigEndian.AppendUint32(content, h.hsreq.flags)
+			content = binary.BigEndian.AppendUint16(content, h.hsreq.recvDelay)
+			content = binary.BigEndian.AppendUint16(content, h.hsreq.sendDelay)
+		case extKMREQ, extKMRSP:
+			if h.km == nil {
+				content = binary.BigEndian.AppendUint32(content, h.kmState)
+			} else {
+				content = h.km
+			}
+		case extSID:
+			content = encodeStreamID(h.streamID)
+		}
+		b = binary.BigEndian.AppendUint16(b, typ)
+		b = binary.BigEndian.AppendUint16(b, uint16(len(content)/4))
+		b = append(b, content...)
+	}
+	return b
+}
+
+// the stream id is sent in words of 4 bytes in reverse order, padded with zeros
+func encodeStreamID(s string) []byte {
+	b := make([]byte, (len(s)+3)/4*4)
+	copy(b, s)
+	reverseWords(b)
+	return b
+}
+
+func decodeStreamID(b []byte) string {
+	s := append([]byte(nil), b[:len(b)/4*4]...)
+	reverseWords(s)
+	for len(s) > 0 && s[len(s)-1] == 0 {
+		s = s[:len(s)-1]
+	}
+	return string(s)
+}
+
+func reverseWords(b []byte) {
+	for i := 0; i+4 <= len(b); i += 4 {
+		b[i], b[i+1], b[i+2], b[i+3] = b[i+3], b[i+2], b[i+1], b[i]
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package srt receives live streams of broadcast encoders over SRT, the Secure Reliable Transport, carrying
+// MPEG-TS. The server listens for encoders calling it and calls encoders or gateways listening itself. It
+// speaks HSv5 handshakes with stream ids, latency negotiation and AES encryption keyed by a passphrase, and the
+// receiving side of live mode: ACKs, NAKs for lost packets and delivery after the latency, dropping packets
+// still missing then. Streams are received only, never sent.
+package srt
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+
+	"github.com/livekit/protocol/logger"
+)
+
+const (
+	maxPacketSize = 1500
+	// SYN cookies of induction responses are valid for this long, and the one before
+	cookieInterval = time.Minute
+)
+
+var (
+	ErrTooManyStreams    = errors.New("too many srt streams")
+	ErrInvalidPassphrase = errors.New("srt passphrase must be 10 to 79 characters")
+	ErrInvalidKeyLength  = errors.New("srt pbkeylen must be 16, 24 or 32")
+	ErrUnsecure          = errors.New("srt stream encryption does not match the passphrase setting")
+	ErrInvalidCaller     = errors.New("srt caller needs an address, a room and an identity")
+)
+
+// Config enables SRT ingest of encoders, listening for their calls and calling those listening
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// UDP port SRT is received on, 0 disables the listener
+	Port int `yaml:"port,omitempty"`
+	// streams must be encrypted with it when set, and must not be otherwise. 10 to 79 characters.
+	Passphrase string `yaml:"passphrase,omitempty"`
+	// AES key length in bytes of the streams the server calls, 16, 24 or 32. Callers choose their own.
+	PBKeyLen int `yaml:"pbkeylen,omitempty"`
+	// time packets are held for lost ones to be sent again, the larger of it and that of the sender is used
+	Latency time.Duration `yaml:"latency,omitempty"`
+	// the tracks of a stream whose encoder disconnected stay published this long for it to reconnect
+	ReconnectTimeout time.Duration `yaml:"reconnect_timeout,omitempty"`
+	// streams of the node at a time, those called included
+	MaxStreams int `yaml:"max_streams,omitempty"`
+	// listeners the server calls and publishes the streams of, called again when their connection ends
+	Callers []CallerConfig `yaml:"callers,omitempty"`
+}
+
+// CallerConfig is a listener the server calls, an encoder or gateway in SRT listener mode
+type CallerConfig struct {
+	// host:port of the listener
+	Address string `yaml:"address,omitempty"`
+	// stream id sent to the listener, to select a stream or authenticate
+	StreamID string `yaml:"stream_id,omitempty"`
+	// replaces the passphrase of the config for this listener
+	Passphrase string `yaml:"passphrase,omitempty"`
+	// room the stream is published into and the identity it publishes as, these streams carry no token
+	Room     string `yaml:"room,omitempty"`
+	Identity string `yaml:"identity,omitempty"`
+	Name     string `yaml:"name,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Port:             9000,
+		PBKeyLen:         16,
+		Latency:          120 * time.Millisecond,
+		ReconnectTimeout: 10 * time.Second,
+		MaxStreams:       20,
+	}
+)
+
+type ServerParams struct {
+	Config Config
+	Logger logger.Logger
+	// authorizes a stream of an encoder calling by its stream id, returns how its programs are published. A
+	// *RejectError rejects it with its reason, other errors as unauthorized. Called from the goroutine receiving
+	// SRT, it must not block.
+	OnPublish func(streamID string) (PublishFunc, error)
+	// returns how the programs of the stream of a listener called are published, once connected
+	OnCall func(caller CallerConfig) (PublishFunc, error)
+}
+
+// Server receives the streams of encoders
+type Server struct {
+	params   ServerParams
+	conn     *net.UDPConn
+	socketID uint32
+	secret   []byte
+
+	lock  sync.Mutex
+	conns map[uint32]*conn
+	peers map[string]*listenerConn
+	// connections to listeners called
+	calls   map[*conn]struct{}
+	callers sync.WaitGroup
+	streams int
+	stopped core.Fuse
+}
+
+// listenerConn is a connection of an encoder calling, with the conclusion response it is sent again on
+// retransmitted conclusions
+type listenerConn struct {
+	conn     *conn
+	response []byte
+}
+
+func NewServer(params ServerParams) (*Server, error) {
+	if params.Config.Latency <= 0 {
+		params.Config.Latency = DefaultConfig.Latency
+	}
+	if params.Config.PBKeyLen == 0 {
+		params.Config.PBKeyLen = DefaultConfig.PBKeyLen
+	}
+	if err := validatePassphrase(params.Config.Passphrase); err != nil {
+		return nil, err
+	}
+	if k := params.Config.PBKeyLen; k != 16 && k != 24 && k != 32 {
+		return nil, ErrInvalidKeyLength
+	}
+	for _, caller := range params.Config.Callers {
+		if caller.Address == "" || caller.Room == "" || caller.Identity == "" {
+			return nil, ErrInvalidCaller
+		}
+		if err := validatePassphrase(caller.Passphrase); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Server{
+		params:   params,
+		socketID: randomSocketID(),
+		secret:   make([]byte, 32),
+		conns:    make(map[uint32]*conn),
+		peers:    make(map[string]*listenerConn),
+		calls:    make(map[*conn]struct{}),
+	}
+	if _, err := rand.Read(s.secret); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func validatePassphrase(passphrase string) error {
+	if passphrase != "" && (len(passphrase) < MinPassphraseLength || len(passphrase) > MaxPassphraseLength) {
+		return ErrInvalidPassphrase
+	}
+	return nil
+}
+
+func (s *Server) Start() error {
+	if s.params.Config.Port != 0 {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: s.params.Config.Port})
+		if err != nil {
+			return err
+		}
+		s.conn = conn
+
+		s.params.Logger.Infow(
+			"srt ingest listening",
+			"port", conn.LocalAddr().(*net.UDPAddr).Port,
+			"encrypted", s.params.Config.Passphrase != "",
+			"latency", s.params.Config.Latency,
+		)
+		go s.readWorker()
+	}
+
+	for _, caller := range s.params.Config.Callers {
+		s.callers.Add(1)
+		go s.callWorker(caller)
+	}
+	return nil
+}
+
+// Stop disconnects all encoders
+func (s *Server) Stop() {
+	s.lock.Lock()
+	s.stopped.Break()
+	conns := make([]*conn, 0, len(s.conns)+len(s.calls))
+	for _, c := range s.conns {
+		conns = append(conns, c)
+	}
+	for c := range s.calls {
+		conns = append(conns, c)
+	}
+	s.lock.Unlock()
+
+	for _, c := range conns {
+		c.close()
+	}
+	for _, c := range conns {
+		<-c.Done()
+	}
+	s.callers.Wait()
+	if s.conn != nil {
+		_ = s.conn.Close()
+	}
+}
+
+// Addr returns the address SRT is received on, when listening
+func (s *Server) Addr() *net.UDPAddr {
+	return s.conn.LocalAddr().(*net.UDPAddr)
+}
+
+// NumStreams returns the streams being received
+func (s *Server) NumStreams() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.streams
+}
+
+func (s *Server) readWorker() {
+	for {
+		b := make([]byte, maxPacketSize)
+		n, addr, err := s.conn.ReadFromUDP(b)
+		if err != nil {
+			if !s.stopped.IsBroken() {
+				s.params.Logger.Errorw("srt read failed", err)
+			}
+			return
+		}
+		if n < headerSize {
+			continue
+		}
+		b = b[:n]
+
+		if socketID := destSocketID(b); socketID != 0 {
+			s.lock.Lock()
+			c := s.conns[socketID]
+			s.lock.Unlock()
+			if c != nil && c.params.peerAddr.IP.Equal(addr.IP) && c.params.peerAddr.Port == addr.Port {
+				c.receive(b)
+			}
+			continue
+		}
+
+		if cp, err := parseControlPacket(b); err == nil && cp.typ == ctrlHandshake {
+			s.handleHandshake(addr, cp)
+		}
+	}
+}
+
+func (s *Server) handleHandshake(addr *net.UDPAddr, cp controlPacket) {
+	h, err := parseHandshake(cp.cif)
+	if err != nil {
+		return
+	}
+
+	switch h.typ {
+	case hsTypeInduction:
+		s.send(addr, h.socketID, &handshake{
+			version:    hsVersion5,
+			extension:  hsMagic,
+			initialSeq: h.initialSeq,
+			mtu:        mtu,
+			flowWindow: flowWindow,
+			typ:        hsTypeInduction,
+			socketID:   s.socketID,
+			cookie:     s.cookie(addr, time.Now()),
+		})
+
+	case hsTypeConclusion:
+		now := time.Now()
+		if h.cookie != s.cookie(addr, now) && h.cookie != s.cookie(addr, now.Add(-cookieInterval)) {
+			return
+		}
+
+		key := addr.String() + "/" + strconv.FormatUint(uint64(h.socketID), 10)
+		s.lock.Lock()
+		existing := s.peers[key]
+		s.lock.Unlock()
+		if existing != nil {
+			// the response was lost
+			_ = s.write(addr, existing.response)
+			return
+		}
+
+		if err := s.accept(addr, key, h); err != nil {
+			reason := uint32(RejectUnauthorized)
+			var rejectErr *RejectError
+			if errors.As(err, &rejectErr) {
+				reason = rejectErr.Reason
+			}
+			s.params.Logger.Infow("rejected srt stream", "remote", addr.String(), "reason", reason, "error", err)
+			s.send(addr, h.socketID, &handshake{
+				version:  hsVersion5,
+				typ:      rejectionBase + reason,
+				socketID: s.socketID,
+				cookie:   h.cookie,
+			})
+		}
+	}
+}
+
+// accept sets up the connection of a conclusion, returns the error rejecting it
+func (s *Server) accept(addr *net.UDPAddr, key string, h *handshake) error {
+	if h.version != hsVersion5 || h.hsreq == nil {
+		return &RejectError{Reason: RejectVersion, Err: ErrInvalidHandshake}
+	}
+
+	var keys *cryptoKeys
+	switch {
+	case s.params.Config.Passphrase == "" && h.km == nil && h.kmState == 0:
+	case s.params.Config.Passphrase == "" || h.km == nil:
+		return &RejectError{Reason: RejectUnsecure, Err: ErrUnsecure}
+	default:
+		var err error
+		if keys, err = parseKeyMaterial(h.km, s.params.Config.Passphrase); err != nil {
+			return &RejectError{Reason: RejectBadSecret, Err: err}
+		}
+	}
+
+	if err := s.addStream(); err != nil {
+		return &RejectError{Reason: RejectOverload, Err: err}
+	}
+	publish, err := s.params.OnPublish(h.streamID)
+	if err != nil {
+		s.removeStream()
+		return err
+	}
+
+	latency := max(s.params.Config.Latency, time.Duration(h.hsreq.sendDelay)*time.Millisecond)
+	response := &handshake{
+		version:    hsVersion5,
+		extension:  hsExtHSREQ,
+		initialSeq: h.initialSeq,
+		mtu:        mtu,
+		flowWindow: flowWindow,
+		typ:        hsTypeConclusion,
+		socketID:   randomSocketID(),
+		cookie:     h.cookie,
+		hsreq: &hsExtension{
+			version:   srtVersion,
+			flags:     srtFlags,
+			recvDelay: uint16(latency.Milliseconds()),
+			sendDelay: uint16(max(s.params.Config.Latency, time.Duration(h.hsreq.recvDelay)*time.Millisecond).Milliseconds()),
+		},
+		km: h.km,
+	}
+	extensions := []uint16{extHSRSP}
+	if keys != nil {
+		response.extension |= hsExtKMREQ
+		extensions = append(extensions, extKMRSP)
+	}
+	responseBytes := (&controlPacket{
+		typ:      ctrlHandshake,
+		socketID: h.socketID,
+		cif:      response.marshal(extensions...),
+	}).marshal(nil)
+
+	c := newConn(connParams{
+		logger:       s.params.Logger.WithValues("remote", addr.String()),
+		socketID:     response.socketID,
+		peerSocketID: h.socketID,
+		peerAddr:     addr,
+		peerSeq:      h.initialSeq,
+		latency:      latency,
+		keys:         keys,
+		passphrase:   s.params.Config.Passphrase,
+		send: func(b []byte) error {
+			return s.write(addr, b)
+		},
+		publish: publish,
+	})
+
+	s.lock.Lock()
+	s.conns[response.socketID] = c
+	s.peers[key] = &listenerConn{conn: c, response: responseBytes}
+	s.lock.Unlock()
+
+	go func() {
+		c.run()
+		<-c.Done()
+		s.lock.Lock()
+		delete(s.conns, response.socketID)
+		delete(s.peers, key)
+		s.lock.Unlock()
+		s.removeStream()
+		c.params.logger.Infow(
+			"srt stream ended",
+			"reason", c.err,
+			"received", c.received,
+			"retransmitted", c.retransmitted,
+			"dropped", c.dropped,
+		)
+	}()
+
+	c.params.logger.Infow("srt stream started", "latency", latency, "encrypted", keys != nil)
+	return s.write(addr, responseBytes)
+}
+
+func (s *Server) addStream() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.stopped.IsBroken() || (s.params.Config.MaxStreams > 0 && s.streams >= s.params.Config.MaxStreams) {
+		return ErrTooManyStreams
+	}
+	s.streams++
+	return nil
+}
+
+func (s *Server) removeStream() {
+	s.lock.Lock()
+	s.streams--
+	s.lock.Unlock()
+}
+
+// cookie is the SYN cookie of a caller for the interval of now
+func (s *Server) cookie(addr *net.UDPAddr, now time.Time) uint32 {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(addr.String()))
+	mac.Write(binary.BigEndian.AppendUint64(nil, uint64(now.Unix()/int64(cookieInterval/time.Second))))
+	return binary.BigEndian.Uint32(mac.Sum(nil))
+}
+
+func (s *Server) send(addr *net.UDPAddr, socketID uint32, h *handshake) {
+	cp := &controlPacket{typ: ctrlHandshake, socketID: socketID, cif: h.marshal()}
+	_ = s.write(addr, cp.marshal(nil))
+}
+
+func (s *Server) write(addr *net.UDPAddr, b []byte) error {
+	_, err := s.conn.WriteToUDP(b, addr)
+	return err
+}
+
+func randomSocketID() uint32 {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return binary.BigEndian.Uint32(b[:])&0x3fffffff | 1
+}
+
+func randomSeq() uint32 {
+	var b [4]byte
+	_, _ = rand.Read(b[:])
+	return binary.BigEndian.Uint32(b[:]) & seqMask
+}
@@ -0,0 +1,460 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srt
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/ingest"
+)
+
+const testPassphrase = "correct horse battery"
+
+func TestHandshake(t *testing.T) {
+	h := &handshake{
+		version:    hsVersion5,
+		extension:  hsExtHSREQ | hsExtConfig,
+		initialSeq: 1234,
+		mtu:        mtu,
+		flowWindow: flowWindow,
+		typ:        hsTypeConclusion,
+		socketID:   42,
+		cookie:     7,
+		hsreq:      &hsExtension{version: srtVersion, flags: srtFlags, recvDelay: 120, sendDelay: 80},
+		streamID:   "#!::r=room,m=publish",
+	}
+	parsed, err := parseHandshake(h.marshal(extHSREQ, extSID))
+	require.NoError(t, err)
+	require.Equal(t, h, parsed)
+
+	// padded and reversed in words on the wire
+	require.Equal(t, []byte{0, 'c', 'b', 'a'}, encodeStreamID("abc"))
+}
+
+func TestKeyMaterial(t *testing.T) {
+	keys, km, err := newKeyMaterial(testPassphrase, 24)
+	require.NoError(t, err)
+
+	parsed, err := parseKeyMaterial(km, testPassphrase)
+	require.NoError(t, err)
+	payload := []byte("payload of a data packet")
+	require.NoError(t, keys.xor(9, keyFlagEven, payload))
+	require.NotEqual(t, []byte("payload of a data packet"), payload)
+	require.NoError(t, parsed.xor(9, keyFlagEven, payload))
+	require.Equal(t, []byte("payload of a data packet"), payload)
+
+	_, err = parseKeyMaterial(km, "wrong passphrase")
+	require.ErrorIs(t, err, ErrBadSecret)
+}
+
+func TestListener(t *testing.T) {
+	publisher := newTestPublisher()
+	var streamID string
+	s := newTestServer(t, Config{Passphrase: testPassphrase}, func(id string) (PublishFunc, error) {
+		streamID = id
+		return func(program uint16, index int) (ingest.Publisher, error) {
+			require.Equal(t, uint16(1), program)
+			require.Equal(t, 0, index)
+			return publisher, nil
+		}, nil
+	})
+
+	sender := dialTestSender(t, s)
+	require.NoError(t, sender.handshake("token", testPassphrase))
+	require.Equal(t, "token", streamID)
+	require.Equal(t, 1, s.NumStreams())
+
+	payloads := testStream()
+	// the second is lost and sent again when asked for
+	sender.send(t, 0, payloads[0])
+	for i := 2; i < len(payloads); i++ {
+		sender.send(t, i, payloads[i])
+	}
+	select {
+	case losses := <-sender.naks:
+		require.Equal(t, []uint32{sender.seq + 1}, losses)
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "loss not reported")
+	}
+	sender.send(t, 1, payloads[1])
+
+	require.Eventually(t, func() bool {
+		publisher.lock.Lock()
+		defer publisher.lock.Unlock()
+		return len(publisher.video) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	publisher.lock.Lock()
+	require.Equal(t, testFrames, publisher.video)
+	require.Equal(t, []time.Duration{time.Second, time.Second + 40*time.Millisecond}, publisher.dts)
+	publisher.lock.Unlock()
+
+	sender.shutdown(t)
+	select {
+	case <-publisher.closed:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "publisher not closed")
+	}
+	require.Eventually(t, func() bool { return s.NumStreams() == 0 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestListenerRejected(t *testing.T) {
+	s := newTestServer(t, Config{Passphrase: testPassphrase}, func(id string) (PublishFunc, error) {
+		return nil, &RejectError{Reason: RejectForbidden, Err: errors.New("forbidden")}
+	})
+
+	var rejectErr *RejectError
+	require.ErrorAs(t, dialTestSender(t, s).handshake("token", "wrong passphrase"), &rejectErr)
+	require.Equal(t, uint32(RejectBadSecret), rejectErr.Reason)
+	require.ErrorAs(t, dialTestSender(t, s).handshake("token", ""), &rejectErr)
+	require.Equal(t, uint32(RejectUnsecure), rejectErr.Reason)
+	require.ErrorAs(t, dialTestSender(t, s).handshake("token", testPassphrase), &rejectErr)
+	require.Equal(t, uint32(RejectForbidden), rejectErr.Reason)
+	require.Equal(t, 0, s.NumStreams())
+}
+
+func TestCaller(t *testing.T) {
+	listener, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	publisher := newTestPublisher()
+	caller := CallerConfig{
+		Address:    listener.LocalAddr().String(),
+		StreamID:   "feed",
+		Passphrase: testPassphrase,
+		Room:       "room",
+		Identity:   "feed",
+	}
+	s, err := NewServer(ServerParams{
+		Config: Config{Latency: 20 * time.Millisecond, Callers: []CallerConfig{caller}},
+		Logger: logger.GetLogger(),
+		OnCall: func(c CallerConfig) (PublishFunc, error) {
+			require.Equal(t, caller, c)
+			return func(program uint16, index int) (ingest.Publisher, error) {
+				return publisher, nil
+			}, nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+
+	// the listener answers the handshake of the server and sends the stream
+	b := make([]byte, maxPacketSize)
+	var peer *net.UDPAddr
+	var request *handshake
+	for request == nil || request.typ != hsTypeConclusion {
+		n, addr, err := listener.ReadFromUDP(b)
+		require.NoError(t, err)
+		cp, err := parseControlPacket(b[:n])
+		require.NoError(t, err)
+		request, err = parseHandshake(cp.cif)
+		require.NoError(t, err)
+		peer = addr
+
+		response := &handshake{
+			version:    hsVersion5,
+			extension:  hsMagic,
+			initialSeq: request.initialSeq,
+			typ:        request.typ,
+			socketID:   77,
+			cookie:     99,
+		}
+		var extensions []uint16
+		if request.typ == hsTypeConclusion {
+			require.Equal(t, uint32(99), request.cookie)
+			require.Equal(t, "feed", request.streamID)
+			response.hsreq = &hsExtension{version: srtVersion, flags: srtFlags, recvDelay: 20, sendDelay: 20}
+			response.km = request.km
+			extensions = []uint16{extHSRSP, extKMRSP}
+		}
+		cp = controlPacket{typ: ctrlHandshake, socketID: request.socketID, cif: response.marshal(extensions...)}
+		_, err = listener.WriteToUDP(cp.marshal(nil), addr)
+		require.NoError(t, err)
+	}
+	keys, err := parseKeyMaterial(request.km, testPassphrase)
+	require.NoError(t, err)
+
+	sender := &testSender{
+		write: func(b []byte) error {
+			_, err := listener.WriteToUDP(b, peer)
+			return err
+		},
+		keys:     keys,
+		seq:      request.initialSeq,
+		socketID: request.socketID,
+		start:    time.Now(),
+	}
+	for i, payload := range testStream() {
+		sender.send(t, i, payload)
+	}
+	require.Eventually(t, func() bool {
+		publisher.lock.Lock()
+		defer publisher.lock.Unlock()
+		return len(publisher.video) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1, s.NumStreams())
+
+	// the listener is told when the server stops
+	s.Stop()
+	<-publisher.closed
+	for {
+		n, err := listener.Read(b)
+		require.NoError(t, err)
+		if cp, err := parseControlPacket(b[:n]); err == nil && cp.typ == ctrlShutdown {
+			break
+		}
+	}
+	require.Equal(t, 0, s.NumStreams())
+}
+
+// --------------------------------------
+
+var testFrames = [][]byte{
+	append([]byte{0, 0, 0, 1, 0x65}, make([]byte, 1500)...),
+	{0, 0, 0, 1, 0x41, 0x9a},
+}
+
+// testStream returns the payloads of data packets of a program with an h264 stream carrying testFrames, a
+// TS packet each
+func testStream() [][]byte {
+	var ts []byte
+	ts = append(ts, testTSPackets(0, testPSI(0x00, 1, []byte{0, 1, 0xe1, 0x00}))...)
+	ts = append(ts, testTSPackets(0x100, testPSI(0x02, 1, []byte{
+		0xe1, 0x01, 0xf0, 0x00,
+		0x1b, 0xe1, 0x01, 0xf0, 0x00,
+	}))...)
+	for i, frame := range testFrames {
+		ts = append(ts, testTSPackets(0x101, testPES(90000+int64(i)*3600, frame))...)
+	}
+
+	var payloads [][]byte
+	for ; len(ts) > 0; ts = ts[ingest.TSPacketSize:] {
+		payloads = append(payloads, ts[:ingest.TSPacketSize])
+	}
+	return payloads
+}
+
+func testPSI(tableID byte, extension uint16, data []byte) []byte {
+	section := []byte{0, tableID, 0xb0, byte(5 + len(data) + 4)}
+	section = binary.BigEndian.AppendUint16(section, extension)
+	section = append(section, 0xc1, 0, 0)
+	section = append(section, data...)
+	return append(section, 0, 0, 0, 0)
+}
+
+// testPES returns a video PES of known length, delivered as soon as complete
+func testPES(dts int64, data []byte) []byte {
+	pes := []byte{0, 0, 1, 0xe0, 0, 0, 0x80, 0x80, 5}
+	binary.BigEndian.PutUint16(pes[4:], uint16(3+5+len(data)))
+	pes = append(pes,
+		byte(0x21|dts>>29&0x0e), byte(dts>>22), byte(dts>>14|1), byte(dts>>7), byte(dts<<1|1),
+	)
+	return append(pes, data...)
+}
+
+func testTSPackets(pid uint16, payload []byte) []byte {
+	var ts []byte
+	for i := 0; len(payload) > 0; i++ {
+		header := []byte{0x47, byte(pid >> 8), byte(pid), 0x10 | byte(i&0x0f)}
+		if i == 0 {
+			header[1] |= 0x40
+		}
+		n := min(len(payload), ingest.TSPacketSize-4)
+		if n < ingest.TSPacketSize-4 {
+			header[3] |= 0x20
+			stuffing := ingest.TSPacketSize - 4 - n - 1
+			header = append(header, byte(stuffing))
+			for j := 0; j < stuffing; j++ {
+				header = append(header, 0xff)
+			}
+			if stuffing > 0 {
+				header[5] = 0x00
+			}
+		}
+		ts = append(append(ts, header...), payload[:n]...)
+		payload = payload[n:]
+	}
+	return ts
+}
+
+func newTestServer(t *testing.T, config Config, onPublish func(streamID string) (PublishFunc, error)) *Server {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	config.Port = conn.LocalAddr().(*net.UDPAddr).Port
+	require.NoError(t, conn.Close())
+
+	config.Latency = 20 * time.Millisecond
+	s, err := NewServer(ServerParams{
+		Config:    config,
+		Logger:    logger.GetLogger(),
+		OnPublish: onPublish,
+	})
+	require.NoError(t, err)
+	require.NoError(t, s.Start())
+	t.Cleanup(s.Stop)
+	return s
+}
+
+// testSender sends a stream as an encoder does
+type testSender struct {
+	conn     *net.UDPConn
+	write    func(b []byte) error
+	keys     *cryptoKeys
+	seq      uint32
+	socketID uint32
+	start    time.Time
+	// sequence numbers reported lost
+	naks chan []uint32
+}
+
+func dialTestSender(t *testing.T, s *Server) *testSender {
+	conn, err := net.DialUDP("udp", nil, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: s.Addr().Port})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return &testSender{
+		conn: conn,
+		write: func(b []byte) error {
+			_, err := conn.Write(b)
+			return err
+		},
+		start: time.Now(),
+		naks:  make(chan []uint32, 10),
+	}
+}
+
+// handshake calls the server, returns the rejection
+func (s *testSender) handshake(streamID, passphrase string) error {
+	socketID := randomSocketID()
+	s.seq = randomSeq()
+	induction, err := exchange(s.conn, socketID, &handshake{
+		version:    hsVersion4,
+		extension:  hsUDTDgram,
+		initialSeq: s.seq,
+		typ:        hsTypeInduction,
+		socketID:   socketID,
+	})
+	if err != nil {
+		return err
+	}
+
+	request := &handshake{
+		version:    hsVersion5,
+		extension:  hsExtHSREQ | hsExtConfig,
+		initialSeq: s.seq,
+		typ:        hsTypeConclusion,
+		socketID:   socketID,
+		cookie:     induction.cookie,
+		hsreq:      &hsExtension{version: srtVersion, flags: srtFlags, recvDelay: 20, sendDelay: 20},
+		streamID:   streamID,
+	}
+	extensions := []uint16{extHSREQ, extSID}
+	if passphrase != "" {
+		if s.keys, request.km, err = newKeyMaterial(passphrase, 16); err != nil {
+			return err
+		}
+		request.extension |= hsExtKMREQ
+		extensions = append(extensions, extKMREQ)
+	}
+	response, err := exchange(s.conn, socketID, request, extensions...)
+	if err != nil {
+		return err
+	}
+	if response.typ != hsTypeConclusion {
+		return &RejectError{Reason: response.typ - rejectionBase}
+	}
+	s.socketID = response.socketID
+
+	go func() {
+		b := make([]byte, maxPacketSize)
+		for {
+			n, err := s.conn.Read(b)
+			if err != nil {
+				return
+			}
+			cp, err := parseControlPacket(b[:n])
+			if err != nil || cp.typ != ctrlNAK {
+				continue
+			}
+			var losses []uint32
+			for i := 0; i+4 <= len(cp.cif); i += 4 {
+				losses = append(losses, binary.BigEndian.Uint32(cp.cif[i:]))
+			}
+			s.naks <- losses
+		}
+	}()
+	return nil
+}
+
+// send sends the payload as the i-th data packet of the stream
+func (s *testSender) send(t *testing.T, i int, payload []byte) {
+	p := dataPacket{
+		seq:       seqAdd(s.seq, int32(i)),
+		timestamp: uint32(time.Since(s.start).Microseconds()),
+		socketID:  s.socketID,
+		payload:   append([]byte{}, payload...),
+	}
+	if s.keys != nil {
+		p.keyFlags = keyFlagEven
+		require.NoError(t, s.keys.xor(p.seq, p.keyFlags, p.payload))
+	}
+	require.NoError(t, s.write(p.marshal(nil)))
+}
+
+func (s *testSender) shutdown(t *testing.T) {
+	cp := controlPacket{typ: ctrlShutdown, socketID: s.socketID, cif: make([]byte, 4)}
+	require.NoError(t, s.write(cp.marshal(nil)))
+}
+
+type testPublisher struct {
+	lock   sync.Mutex
+	video  [][]byte
+	dts    []time.Duration
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newTestPublisher() *testPublisher {
+	return &testPublisher{closed: make(chan struct{})}
+}
+
+func (p *testPublisher) WriteH264(au []byte, dts time.Duration) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.video = append(p.video, append([]byte{}, au...))
+	p.dts = append(p.dts, dts)
+	return nil
+}
+
+func (p *testPublisher) WriteOpus(packet []byte) error {
+	return nil
+}
+
+func (p *testPublisher) WritePCM(pcm []int16, sampleRate, channels int) error {
+	return nil
+}
+
+func (p *testPublisher) Close() {
+	p.once.Do(func() { close(p.closed) })
+}
@@ -0,0 +1,90 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+type Manifest struct {
+	RoomName      string    `json:"room_name"`
+	RoomID        string    `json:"room_id"`
+	ServerVersion string    `json:"server_version"`
+	NodeID        string    `json:"node_id"`
+	CreatedAt     time.Time `json:"created_at"`
+	LogsSince     time.Time `json:"logs_since"`
+	NumLogEntries int       `json:"num_log_entries"`
+}
+
+// Bundle is everything support needs to look into an issue of a room
+type Bundle struct {
+	Manifest Manifest
+	Logs     []LogEntry
+	// pipeline state of the room and its participants
+	Stats interface{}
+	// ICE candidates of the participants by identity
+	ICECandidates interface{}
+	// yaml snapshot of the configuration the room runs with, secrets excluded
+	Config []byte
+}
+
+// WriteZip writes the bundle as a zip archive
+func (b *Bundle) WriteZip(w io.Writer) error {
+	b.Manifest.NumLogEntries = len(b.Logs)
+
+	zw := zip.NewWriter(w)
+	if err := writeJSON(zw, "manifest.json", b.Manifest); err != nil {
+		return err
+	}
+
+	f, err := zw.Create("logs.jsonl")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, entry := range b.Logs {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+
+	if err := writeJSON(zw, "stats.json", b.Stats); err != nil {
+		return err
+	}
+	if err := writeJSON(zw, "ice_candidates.json", b.ICECandidates); err != nil {
+		return err
+	}
+	if f, err = zw.Create("config.yaml"); err != nil {
+		return err
+	}
+	if _, err = f.Write(b.Config); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func writeJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
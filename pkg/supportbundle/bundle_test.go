@@ -0,0 +1,77 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogRing(t *testing.T) {
+	now := time.Now()
+	r := NewLogRing(Config{Retention: time.Minute, MaxEntries: 3})
+
+	r.Add(LogEntry{Time: now.Add(-2 * time.Minute), Level: LevelInfo, Message: "expired"})
+	r.Add(LogEntry{Time: now, Level: LevelDebug, Message: "below level"})
+	require.Empty(t, r.Since(time.Time{}, now))
+
+	for _, msg := range []string{"a", "b", "c", "d"} {
+		r.Add(LogEntry{Time: now, Level: LevelWarn, Message: msg})
+	}
+	entries := r.Since(time.Time{}, now)
+	require.Len(t, entries, 3)
+	require.Equal(t, "b", entries[0].Message)
+	require.Equal(t, "d", entries[2].Message)
+
+	require.Empty(t, r.Since(now.Add(time.Second), now))
+}
+
+func TestBundle(t *testing.T) {
+	b := &Bundle{
+		Manifest: Manifest{RoomName: "room"},
+		Logs: []LogEntry{
+			{Message: "one", Level: LevelInfo},
+			{Message: "two", Level: LevelError, Error: "failed"},
+		},
+		Stats:  map[string]int{"participants": 1},
+		Config: []byte("room: {}\n"),
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, b.WriteZip(&buf))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		files[f.Name] = string(data)
+	}
+	require.Len(t, files, 5)
+	require.Contains(t, files["manifest.json"], `"num_log_entries": 2`)
+	require.Contains(t, files["logs.jsonl"], `"level":"error"`)
+	require.Equal(t, "room: {}\n", files["config.yaml"])
+	require.Equal(t, "null\n", files["ice_candidates.json"])
+}
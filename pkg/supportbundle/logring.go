@@ -0,0 +1,163 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package supportbundle
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// how long log entries are kept
+	Retention time.Duration `yaml:"retention,omitempty"`
+	// upper bound of log entries kept per room
+	MaxEntries int `yaml:"max_entries,omitempty"`
+	// lowest level captured, debug, info, warn or error
+	Level string `yaml:"level,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Retention:  10 * time.Minute,
+		MaxEntries: 10000,
+		Level:      "info",
+	}
+)
+
+// --------------------------------------
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+func ParseLevel(s string) Level {
+	switch strings.ToLower(s) {
+	case "debug":
+		return LevelDebug
+	case "warn", "warning":
+		return LevelWarn
+	case "error":
+		return LevelError
+	default:
+		return LevelInfo
+	}
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "debug"
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+// --------------------------------------
+
+type LogEntry struct {
+	Time    time.Time         `json:"ts"`
+	Level   Level             `json:"level"`
+	Message string            `json:"msg"`
+	Error   string            `json:"error,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// LogRing keeps the most recent log entries of a room in memory,
+// entries are dropped when the ring is full or they are older than the retention.
+type LogRing struct {
+	config Config
+	level  Level
+
+	lock    sync.Mutex
+	entries []LogEntry
+	next    int
+	full    bool
+}
+
+func NewLogRing(config Config) *LogRing {
+	if config.Retention <= 0 {
+		config.Retention = DefaultConfig.Retention
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultConfig.MaxEntries
+	}
+	return &LogRing{
+		config:  config,
+		level:   ParseLevel(config.Level),
+		entries: make([]LogEntry, config.MaxEntries),
+	}
+}
+
+// Enabled returns true if entries of the level are captured
+func (r *LogRing) Enabled(level Level) bool {
+	return level >= r.level
+}
+
+func (r *LogRing) Add(entry LogEntry) {
+	if !r.Enabled(entry.Level) {
+		return
+	}
+
+	r.lock.Lock()
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next = 0
+		r.full = true
+	}
+	r.lock.Unlock()
+}
+
+// Since returns the entries logged at or after t and within the retention, oldest first
+func (r *LogRing) Since(t time.Time, now time.Time) []LogEntry {
+	if cutoff := now.Add(-r.config.Retention); t.Before(cutoff) {
+		t = cutoff
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var ordered []LogEntry
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+
+	entries := make([]LogEntry, 0, len(ordered))
+	for _, e := range ordered {
+		if !e.Time.Before(t) {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
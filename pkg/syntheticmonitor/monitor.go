@@ -0,0 +1,338 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syntheticmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/frostbyte73/core"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+	"github.com/livekit/protocol/utils/guid"
+
+	"github.com/livekit/livekit-server/pkg/latencyprobe"
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+const (
+	// participant attribute of synthetic monitors, set to "true" so that clients can leave them out of their UI
+	Attribute = "agentix.synthetic_monitor"
+	// monitors join with this prefix and a random suffix as identity
+	IdentityPrefix = "synthetic-monitor-"
+
+	frameDuration = 20 * time.Millisecond
+)
+
+var (
+	ErrTooManyMonitors = errors.New("too many synthetic monitors in the room")
+	ErrMonitorNotFound = errors.New("synthetic monitor not found")
+	ErrMonitorsClosed  = errors.New("synthetic monitors closed")
+)
+
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// monitors of a room at a time
+	MaxMonitors int `yaml:"max_monitors,omitempty"`
+	// a burst of the reference tone starts every interval and lasts the burst duration
+	ToneInterval time.Duration `yaml:"tone_interval,omitempty"`
+	ToneBurst    time.Duration `yaml:"tone_burst,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		MaxMonitors:  1,
+		ToneInterval: time.Second,
+		ToneBurst:    100 * time.Millisecond,
+	}
+)
+
+// Participant is a synthetic participant joined to a room, it publishes an Opus track and subscribes to
+// every track of the room
+type Participant interface {
+	WriteFrame(payload []byte, duration time.Duration) error
+	Close()
+}
+
+// TrackQuality is the quality a monitor receives a track of the room with, measured by the server on the
+// down track sending it to the monitor
+type TrackQuality struct {
+	ParticipantIdentity livekit.ParticipantIdentity `json:"participant_identity"`
+	TrackID             livekit.TrackID             `json:"track_id"`
+	Kind                string                      `json:"kind"`
+	Score               float32                     `json:"score"`
+	Quality             string                      `json:"quality"`
+	Packets             uint32                      `json:"packets"`
+	PacketsLost         uint32                      `json:"packets_lost"`
+	PacketLoss          float32                     `json:"packet_loss_percentage"`
+	JitterMs            float64                     `json:"jitter_ms"`
+	RttMs               uint32                      `json:"rtt_ms"`
+	Bitrate             float64                     `json:"bitrate"`
+}
+
+type Report struct {
+	Room      livekit.RoomName            `json:"room"`
+	Identity  livekit.ParticipantIdentity `json:"identity"`
+	StartedAt time.Time                   `json:"started_at"`
+	// false once the monitor is no longer a participant of the room, e. g. it was removed
+	Connected bool `json:"connected"`
+	// bursts of the reference tone published so far
+	Markers int             `json:"markers"`
+	Tracks  []*TrackQuality `json:"tracks"`
+}
+
+type MonitorsParams struct {
+	Config Config
+	Logger logger.Logger
+	// Connect joins a synthetic participant to a room of the local server
+	Connect func(ctx context.Context, room livekit.RoomName, identity livekit.ParticipantIdentity) (Participant, error)
+	// Measure returns the quality of the tracks the participant is subscribed to, false if it is not in the room
+	Measure func(room livekit.RoomName, identity livekit.ParticipantIdentity) ([]*TrackQuality, bool)
+}
+
+// Monitors are synthetic participants joined to rooms carrying real traffic. Each publishes a reference tone,
+// a burst every tone interval, subscribes to the tracks of the room like any participant and reports the
+// quality it receives them with, for probes to monitor service levels on production rooms.
+type Monitors struct {
+	params MonitorsParams
+
+	lock sync.Mutex
+	// monitors by room, nil while a monitor is connecting
+	rooms   map[livekit.RoomName]map[livekit.ParticipantIdentity]*monitor
+	stopped core.Fuse
+}
+
+func NewMonitors(params MonitorsParams) *Monitors {
+	if params.Config.ToneInterval <= 0 {
+		params.Config.ToneInterval = DefaultConfig.ToneInterval
+	}
+	if params.Config.ToneBurst <= 0 {
+		params.Config.ToneBurst = DefaultConfig.ToneBurst
+	}
+	return &Monitors{
+		params: params,
+		rooms:  make(map[livekit.RoomName]map[livekit.ParticipantIdentity]*monitor),
+	}
+}
+
+// Start joins a monitor to the room, returns the identity it joined with
+func (m *Monitors) Start(ctx context.Context, room livekit.RoomName) (livekit.ParticipantIdentity, error) {
+	encoder, err := audio.NewOpusEncoder(audio.OpusSampleRate, 1)
+	if err != nil {
+		return "", err
+	}
+	identity := livekit.ParticipantIdentity(guid.New(IdentityPrefix))
+
+	// the slot is taken while connecting, so that concurrent starts respect the limit
+	m.lock.Lock()
+	if m.stopped.IsBroken() {
+		m.lock.Unlock()
+		return "", ErrMonitorsClosed
+	}
+	monitors := m.rooms[room]
+	if m.params.Config.MaxMonitors > 0 && len(monitors) >= m.params.Config.MaxMonitors {
+		m.lock.Unlock()
+		return "", ErrTooManyMonitors
+	}
+	if monitors == nil {
+		monitors = make(map[livekit.ParticipantIdentity]*monitor)
+		m.rooms[room] = monitors
+	}
+	monitors[identity] = nil
+	m.lock.Unlock()
+
+	participant, err := m.params.Connect(ctx, room, identity)
+	if err != nil {
+		m.remove(room, identity, nil)
+		return "", fmt.Errorf("could not connect synthetic monitor: %w", err)
+	}
+
+	mon := &monitor{
+		room:        room,
+		identity:    identity,
+		startedAt:   time.Now(),
+		participant: participant,
+		encoder:     encoder,
+	}
+	m.lock.Lock()
+	if m.stopped.IsBroken() {
+		m.lock.Unlock()
+		participant.Close()
+		return "", ErrMonitorsClosed
+	}
+	m.rooms[room][identity] = mon
+	m.lock.Unlock()
+
+	m.params.Logger.Infow("synthetic monitor started", "room", room, "identity", identity)
+	go m.publishTone(mon)
+	return identity, nil
+}
+
+// Stop removes a monitor from its room
+func (m *Monitors) Stop(room livekit.RoomName, identity livekit.ParticipantIdentity) error {
+	mon := m.get(room, identity)
+	if mon == nil {
+		return ErrMonitorNotFound
+	}
+	m.stop(mon)
+	return nil
+}
+
+// Report returns what a monitor measured
+func (m *Monitors) Report(room livekit.RoomName, identity livekit.ParticipantIdentity) (*Report, error) {
+	mon := m.get(room, identity)
+	if mon == nil {
+		return nil, ErrMonitorNotFound
+	}
+
+	report := &Report{
+		Room:      mon.room,
+		Identity:  mon.identity,
+		StartedAt: mon.startedAt,
+		Markers:   int(mon.markers.Load()),
+	}
+	report.Tracks, report.Connected = m.params.Measure(room, identity)
+	if report.Tracks == nil {
+		report.Tracks = make([]*TrackQuality, 0)
+	}
+	return report, nil
+}
+
+// Close removes all monitors, monitors cannot be started afterwards
+func (m *Monitors) Close() {
+	if m == nil {
+		return
+	}
+
+	m.lock.Lock()
+	m.stopped.Break()
+	var monitors []*monitor
+	for _, room := range m.rooms {
+		for _, mon := range room {
+			if mon != nil {
+				monitors = append(monitors, mon)
+			}
+		}
+	}
+	m.lock.Unlock()
+
+	for _, mon := range monitors {
+		m.stop(mon)
+	}
+}
+
+func (m *Monitors) get(room livekit.RoomName, identity livekit.ParticipantIdentity) *monitor {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	return m.rooms[room][identity]
+}
+
+// remove frees the slot of a monitor, returns false if it is not the one taking it
+func (m *Monitors) remove(room livekit.RoomName, identity livekit.ParticipantIdentity, mon *monitor) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	monitors := m.rooms[room]
+	if current, ok := monitors[identity]; !ok || current != mon {
+		return false
+	}
+	delete(monitors, identity)
+	if len(monitors) == 0 {
+		delete(m.rooms, room)
+	}
+	return true
+}
+
+func (m *Monitors) stop(mon *monitor) {
+	if !m.remove(mon.room, mon.identity, mon) {
+		return
+	}
+
+	// no frame is written once the participant is closed
+	mon.lock.Lock()
+	mon.stopped.Break()
+	mon.lock.Unlock()
+	mon.participant.Close()
+	m.params.Logger.Infow("synthetic monitor stopped", "room", mon.room, "identity", mon.identity)
+}
+
+// publishTone publishes the reference tone of a monitor in real time until it is stopped
+func (m *Monitors) publishTone(mon *monitor) {
+	generator := latencyprobe.NewToneGenerator(
+		audio.OpusSampleRate,
+		int(audio.OpusSampleRate*frameDuration/time.Second),
+		int(m.params.Config.ToneInterval/frameDuration),
+		int(m.params.Config.ToneBurst/frameDuration),
+	)
+	out := make([]byte, audio.OpusMaxPacketSize)
+
+	ticker := time.NewTicker(frameDuration)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mon.stopped.Watch():
+			return
+
+		case <-ticker.C:
+			frame, _, start := generator.NextFrame()
+			n, err := mon.encoder.Encode(frame, out)
+			if err != nil {
+				m.params.Logger.Warnw("could not encode synthetic monitor tone", err, "room", mon.room, "identity", mon.identity)
+				m.stop(mon)
+				return
+			}
+			if err := mon.writeFrame(out[:n]); err != nil {
+				m.params.Logger.Warnw("could not publish synthetic monitor tone", err, "room", mon.room, "identity", mon.identity)
+				m.stop(mon)
+				return
+			}
+			if start {
+				mon.markers.Inc()
+			}
+		}
+	}
+}
+
+// --------------------------------------
+
+type monitor struct {
+	room        livekit.RoomName
+	identity    livekit.ParticipantIdentity
+	startedAt   time.Time
+	participant Participant
+	encoder     audio.OpusEncoder
+
+	markers atomic.Int32
+
+	lock    sync.Mutex
+	stopped core.Fuse
+}
+
+func (m *monitor) writeFrame(payload []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if m.stopped.IsBroken() {
+		return nil
+	}
+	return m.participant.WriteFrame(payload, frameDuration)
+}
@@ -0,0 +1,208 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package syntheticmonitor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/logger"
+
+	"github.com/livekit/livekit-server/pkg/sfu/audio"
+)
+
+type testParticipant struct {
+	lock   sync.Mutex
+	frames int
+	closed bool
+}
+
+func (p *testParticipant) WriteFrame(payload []byte, duration time.Duration) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.frames++
+	return nil
+}
+
+func (p *testParticipant) Close() {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closed = true
+}
+
+func (p *testParticipant) numFrames() int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.frames
+}
+
+func (p *testParticipant) isClosed() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.closed
+}
+
+type testRooms struct {
+	lock         sync.Mutex
+	participants map[livekit.ParticipantIdentity]*testParticipant
+	connectErr   error
+}
+
+func (r *testRooms) connect(ctx context.Context, room livekit.RoomName, identity livekit.ParticipantIdentity) (Participant, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.connectErr != nil {
+		return nil, r.connectErr
+	}
+	p := &testParticipant{}
+	r.participants[identity] = p
+	return p, nil
+}
+
+func (r *testRooms) measure(room livekit.RoomName, identity livekit.ParticipantIdentity) ([]*TrackQuality, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if p := r.participants[identity]; p == nil || p.isClosed() {
+		return nil, false
+	}
+	return []*TrackQuality{{ParticipantIdentity: "speaker", TrackID: "TR_speaker", Kind: "audio", Score: 4.5}}, true
+}
+
+func (r *testRooms) participant(identity livekit.ParticipantIdentity) *testParticipant {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.participants[identity]
+}
+
+func newTestMonitors(t *testing.T, maxMonitors int) (*Monitors, *testRooms) {
+	if !audio.IsOpusCodecAvailable() {
+		t.Skip("opus codec unavailable")
+	}
+
+	rooms := &testRooms{participants: make(map[livekit.ParticipantIdentity]*testParticipant)}
+	monitors := NewMonitors(MonitorsParams{
+		Config: Config{
+			Enabled:      true,
+			MaxMonitors:  maxMonitors,
+			ToneInterval: 100 * time.Millisecond,
+			ToneBurst:    40 * time.Millisecond,
+		},
+		Logger:  logger.GetLogger(),
+		Connect: rooms.connect,
+		Measure: rooms.measure,
+	})
+	t.Cleanup(monitors.Close)
+	return monitors, rooms
+}
+
+func TestMonitors(t *testing.T) {
+	monitors, rooms := newTestMonitors(t, 1)
+
+	identity, err := monitors.Start(context.Background(), "room")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(identity), IdentityPrefix))
+	p := rooms.participant(identity)
+	require.NotNil(t, p)
+
+	// the reference tone is published in real time
+	require.Eventually(t, func() bool {
+		report, err := monitors.Report("room", identity)
+		require.NoError(t, err)
+		return report.Markers >= 2
+	}, 2*time.Second, 20*time.Millisecond)
+	require.Greater(t, p.numFrames(), 5)
+
+	report, err := monitors.Report("room", identity)
+	require.NoError(t, err)
+	require.Equal(t, livekit.RoomName("room"), report.Room)
+	require.Equal(t, identity, report.Identity)
+	require.True(t, report.Connected)
+	require.Len(t, report.Tracks, 1)
+	require.Equal(t, livekit.TrackID("TR_speaker"), report.Tracks[0].TrackID)
+
+	// monitors are looked up by room
+	_, err = monitors.Report("other", identity)
+	require.ErrorIs(t, err, ErrMonitorNotFound)
+
+	require.NoError(t, monitors.Stop("room", identity))
+	require.True(t, p.isClosed())
+	frames := p.numFrames()
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, frames, p.numFrames())
+
+	_, err = monitors.Report("room", identity)
+	require.ErrorIs(t, err, ErrMonitorNotFound)
+	require.ErrorIs(t, monitors.Stop("room", identity), ErrMonitorNotFound)
+}
+
+func TestMonitors_MaxMonitors(t *testing.T) {
+	monitors, rooms := newTestMonitors(t, 1)
+
+	identity, err := monitors.Start(context.Background(), "room")
+	require.NoError(t, err)
+
+	// the limit is per room
+	_, err = monitors.Start(context.Background(), "room")
+	require.ErrorIs(t, err, ErrTooManyMonitors)
+	other, err := monitors.Start(context.Background(), "other")
+	require.NoError(t, err)
+
+	// stopping one frees its slot
+	require.NoError(t, monitors.Stop("room", identity))
+	_, err = monitors.Start(context.Background(), "room")
+	require.NoError(t, err)
+
+	// a monitor failing to connect does not hold a slot
+	require.NoError(t, monitors.Stop("other", other))
+	rooms.connectErr = errors.New("room not found")
+	_, err = monitors.Start(context.Background(), "other")
+	require.ErrorIs(t, err, rooms.connectErr)
+	rooms.connectErr = nil
+	_, err = monitors.Start(context.Background(), "other")
+	require.NoError(t, err)
+}
+
+func TestMonitors_Close(t *testing.T) {
+	monitors, rooms := newTestMonitors(t, 0)
+
+	var identities []livekit.ParticipantIdentity
+	for i := 0; i < 3; i++ {
+		identity, err := monitors.Start(context.Background(), "room")
+		require.NoError(t, err)
+		identities = append(identities, identity)
+	}
+
+	monitors.Close()
+	for _, identity := range identities {
+		require.True(t, rooms.participant(identity).isClosed())
+	}
+	_, err := monitors.Start(context.Background(), "room")
+	require.ErrorIs(t, err, ErrMonitorsClosed)
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/livekit/protocol/utils/guid"
 
 	"github.com/livekit/livekit-server/pkg/config"
+	"github.com/livekit/livekit-server/pkg/eventexport"
 	"github.com/livekit/livekit-server/pkg/routing"
 )
 
@@ -46,17 +47,38 @@ type analyticsService struct {
 	events    rpc.AnalyticsRecorderService_IngestEventsClient
 	stats     rpc.AnalyticsRecorderService_IngestStatsClient
 	nodeRooms rpc.AnalyticsRecorderService_IngestNodeRoomStatesClient
+
+	// long-term storage of events in ClickHouse or BigQuery, nil when disabled
+	exporter *eventexport.Exporter
 }
 
-func NewAnalyticsService(_ *config.Config, currentNode routing.LocalNode) AnalyticsService {
-	return &analyticsService{
+func NewAnalyticsService(conf *config.Config, currentNode routing.LocalNode) AnalyticsService {
+	a := &analyticsService{
 		analyticsKey: "", // TODO: conf.AnalyticsKey
 		nodeID:       string(currentNode.NodeID()),
 	}
+	if conf != nil && conf.EventExport.Enabled {
+		exporter, err := eventexport.NewExporter(eventexport.ExporterParams{
+			Config: conf.EventExport,
+			Logger: logger.GetLogger().WithComponent("eventexport"),
+		})
+		if err != nil {
+			logger.Errorw("could not set up event export", err)
+		} else {
+			exporter.Start()
+			a.exporter = exporter
+		}
+	}
+	return a
+}
+
+// Close writes the events still queued for export
+func (a *analyticsService) Close() {
+	a.exporter.Stop()
 }
 
 func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.AnalyticsStat) {
-	if a.stats == nil {
+	if a.stats == nil && a.exporter == nil {
 		return
 	}
 
@@ -64,6 +86,10 @@ func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.Analyti
 		stat.Id = guid.New("AS_")
 		stat.AnalyticsKey = a.analyticsKey
 		stat.Node = a.nodeID
+		a.exporter.Record(analyticsStatRow(stat))
+	}
+	if a.stats == nil {
+		return
 	}
 	if err := a.stats.Send(&livekit.AnalyticsStats{Stats: stats}); err != nil {
 		logger.Errorw("failed to send stats", err)
@@ -71,13 +97,17 @@ func (a *analyticsService) SendStats(_ context.Context, stats []*livekit.Analyti
 }
 
 func (a *analyticsService) SendEvent(_ context.Context, event *livekit.AnalyticsEvent) {
-	if a.events == nil {
+	if a.events == nil && a.exporter == nil {
 		return
 	}
 
 	event.Id = guid.New("AE_")
 	event.NodeId = a.nodeID
 	event.AnalyticsKey = a.analyticsKey
+	a.exporter.Record(analyticsEventRow(event))
+	if a.events == nil {
+		return
+	}
 	if err := a.events.Send(&livekit.AnalyticsEvents{
 		Events: []*livekit.AnalyticsEvent{event},
 	}); err != nil {
@@ -98,6 +128,10 @@ func (a *analyticsService) SendNodeRoomStates(_ context.Context, nodeRooms *live
 	}
 }
 
+func (a *analyticsService) exportWebhookEvent(event *livekit.WebhookEvent) {
+	a.exporter.Record(webhookEventRow(a.nodeID, event))
+}
+
 func (a *analyticsService) RoomProjectReporter(_ context.Context) roomobs.ProjectReporter {
 	return roomobs.NewNoopProjectReporter()
 }
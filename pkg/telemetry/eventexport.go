@@ -0,0 +1,124 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/livekit/protocol/livekit"
+
+	"github.com/livekit/livekit-server/pkg/eventexport"
+)
+
+func eventExportKind(eventType livekit.AnalyticsEventType) eventexport.Kind {
+	name := eventType.String()
+	switch {
+	case strings.HasPrefix(name, "ROOM_"):
+		return eventexport.KindRoom
+	case strings.HasPrefix(name, "PARTICIPANT_"):
+		return eventexport.KindParticipant
+	case strings.HasPrefix(name, "TRACK_"):
+		return eventexport.KindTrack
+	case strings.HasPrefix(name, "EGRESS_"):
+		return eventexport.KindEgress
+	case strings.HasPrefix(name, "INGRESS_"):
+		return eventexport.KindIngress
+	default:
+		return eventexport.KindOther
+	}
+}
+
+func eventExportTime(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Now()
+	}
+	return ts.AsTime()
+}
+
+func eventExportPayload(m proto.Message) string {
+	payload, err := protojson.Marshal(m)
+	if err != nil {
+		return ""
+	}
+	return string(payload)
+}
+
+func analyticsEventRow(event *livekit.AnalyticsEvent) eventexport.Row {
+	return eventexport.Row{
+		ID:                  event.Id,
+		Timestamp:           eventExportTime(event.Timestamp),
+		Kind:                eventExportKind(event.Type),
+		Type:                strings.ToLower(event.Type.String()),
+		NodeID:              event.NodeId,
+		RoomID:              event.RoomId,
+		RoomName:            event.Room.GetName(),
+		ParticipantID:       event.ParticipantId,
+		ParticipantIdentity: event.Participant.GetIdentity(),
+		TrackID:             event.TrackId,
+		Mime:                event.Track.GetMimeType(),
+		Payload:             eventExportPayload(event),
+	}
+}
+
+// analyticsStatRow condenses the streams of a track stat, the payload is left out
+// as stats are sent for every track every few seconds
+func analyticsStatRow(stat *livekit.AnalyticsStat) eventexport.Row {
+	row := eventexport.Row{
+		ID:            stat.Id,
+		Timestamp:     eventExportTime(stat.TimeStamp),
+		Kind:          eventexport.KindQuality,
+		Type:          strings.ToLower(stat.Kind.String()),
+		NodeID:        stat.Node,
+		RoomID:        stat.RoomId,
+		RoomName:      stat.RoomName,
+		ParticipantID: stat.ParticipantId,
+		TrackID:       stat.TrackId,
+		Mime:          stat.Mime,
+		Score:         stat.Score,
+		MinScore:      stat.MinScore,
+	}
+	for _, stream := range stat.Streams {
+		row.Packets += uint64(stream.PrimaryPackets)
+		row.PacketsLost += uint64(stream.PacketsLost)
+		row.Bytes += stream.PrimaryBytes
+		row.RTT = max(row.RTT, float64(stream.Rtt))
+		row.Jitter = max(row.Jitter, float64(stream.Jitter))
+	}
+	return row
+}
+
+// webhookEventRow exports the events raised by processing of the server, e. g. processing bypass
+// or talk analytics, which have no analytics event of their own
+func webhookEventRow(nodeID string, event *livekit.WebhookEvent) eventexport.Row {
+	return eventexport.Row{
+		ID:                  event.Id,
+		Timestamp:           time.Unix(event.CreatedAt, 0),
+		Kind:                eventexport.KindProcessing,
+		Type:                event.Event,
+		NodeID:              nodeID,
+		RoomID:              event.Room.GetSid(),
+		RoomName:            event.Room.GetName(),
+		ParticipantID:       event.Participant.GetSid(),
+		ParticipantIdentity: event.Participant.GetIdentity(),
+		TrackID:             event.Track.GetSid(),
+		Payload:             eventExportPayload(event),
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry
+
+import (
+	"sync"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// events a subscriber may lag behind before events are dropped for it
+const eventFeedBuffer = 256
+
+// eventFeed fans the webhook events raised on this node out to live subscribers,
+// e. g. operators tailing the events of a room during an incident
+type eventFeed struct {
+	mu          sync.RWMutex
+	subscribers map[*eventFeedSubscriber]struct{}
+}
+
+type eventFeedSubscriber struct {
+	roomName livekit.RoomName
+	events   chan *livekit.WebhookEvent
+}
+
+func (f *eventFeed) subscribe(roomName livekit.RoomName) (<-chan *livekit.WebhookEvent, func()) {
+	sub := &eventFeedSubscriber{
+		roomName: roomName,
+		events:   make(chan *livekit.WebhookEvent, eventFeedBuffer),
+	}
+
+	f.mu.Lock()
+	if f.subscribers == nil {
+		f.subscribers = make(map[*eventFeedSubscriber]struct{})
+	}
+	f.subscribers[sub] = struct{}{}
+	f.mu.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.subscribers, sub)
+			f.mu.Unlock()
+			close(sub.events)
+		})
+	}
+}
+
+func (f *eventFeed) publish(event *livekit.WebhookEvent) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	if len(f.subscribers) == 0 {
+		return
+	}
+	roomName := eventRoomName(event)
+	for sub := range f.subscribers {
+		if sub.roomName != "" && sub.roomName != roomName {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			// slow subscriber, an operator tool must not hold up telemetry
+		}
+	}
+}
+
+func eventRoomName(event *livekit.WebhookEvent) livekit.RoomName {
+	switch {
+	case event.Room != nil:
+		return livekit.RoomName(event.Room.Name)
+	case event.EgressInfo != nil:
+		return livekit.RoomName(event.EgressInfo.RoomName)
+	case event.IngressInfo != nil:
+		return livekit.RoomName(event.IngressInfo.RoomName)
+	default:
+		return ""
+	}
+}
+
+// SubscribeEvents streams the webhook events raised on this node, of a room or of all rooms when roomName
+// is empty, whether or not webhooks are configured. Events are dropped when the subscriber does not keep up.
+// The returned function ends the subscription and closes the channel.
+func (t *telemetryService) SubscribeEvents(roomName livekit.RoomName) (<-chan *livekit.WebhookEvent, func()) {
+	return t.feed.subscribe(roomName)
+}
@@ -0,0 +1,73 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package telemetry_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+type eventSubscriber interface {
+	SubscribeEvents(roomName livekit.RoomName) (<-chan *livekit.WebhookEvent, func())
+}
+
+func Test_SubscribeEvents(t *testing.T) {
+	fixture := createFixture()
+	feed, ok := fixture.sut.(eventSubscriber)
+	require.True(t, ok)
+
+	roomEvents, stopRoom := feed.SubscribeEvents("RoomName")
+	defer stopRoom()
+	allEvents, stopAll := feed.SubscribeEvents("")
+
+	fixture.sut.RoomStarted(context.Background(), &livekit.Room{Sid: "RoomSid", Name: "RoomName"})
+	fixture.sut.RoomStarted(context.Background(), &livekit.Room{Sid: "OtherSid", Name: "OtherRoom"})
+
+	receive := func(events <-chan *livekit.WebhookEvent) *livekit.WebhookEvent {
+		select {
+		case event := <-events:
+			return event
+		case <-time.After(time.Second):
+			require.Fail(t, "no event")
+			return nil
+		}
+	}
+
+	event := receive(roomEvents)
+	require.Equal(t, webhook.EventRoomStarted, event.Event)
+	require.Equal(t, "RoomName", event.Room.Name)
+	require.NotEmpty(t, event.Id)
+
+	require.Equal(t, "RoomName", receive(allEvents).Room.Name)
+	require.Equal(t, "OtherRoom", receive(allEvents).Room.Name)
+
+	// events of other rooms are not delivered
+	select {
+	case event := <-roomEvents:
+		require.Fail(t, "unexpected event", event.Event)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	stopAll()
+	_, ok = <-allEvents
+	require.False(t, ok)
+}
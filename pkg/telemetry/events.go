@@ -29,13 +29,30 @@ import (
 	"github.com/livekit/protocol/webhook"
 )
 
+// webhook event sent when a wake word is spotted in a published audio track
+const EventWakeWordDetected = "wake_word_detected"
+
+type webhookEventExporter interface {
+	exportWebhookEvent(event *livekit.WebhookEvent)
+}
+
+// NotifyEvent sends a webhook event raised outside of telemetry, e. g. by processing of a room.
+// These events have no analytics event of their own, so they are also exported.
 func (t *telemetryService) NotifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption) {
-	if t.notifier == nil {
-		return
+	t.notifyEvent(ctx, event, opts...)
+	if exporter, ok := t.AnalyticsService.(webhookEventExporter); ok {
+		exporter.exportWebhookEvent(event)
 	}
+}
 
+func (t *telemetryService) notifyEvent(ctx context.Context, event *livekit.WebhookEvent, opts ...webhook.NotifyOption) {
 	event.CreatedAt = time.Now().Unix()
 	event.Id = guid.New("EV_")
+	t.feed.publish(event)
+
+	if t.notifier == nil {
+		return
+	}
 
 	if err := t.notifier.QueueNotify(ctx, event, opts...); err != nil {
 		logger.Warnw("failed to notify webhook", err, "event", event.Event)
@@ -44,7 +61,7 @@ func (t *telemetryService) NotifyEvent(ctx context.Context, event *livekit.Webho
 
 func (t *telemetryService) RoomStarted(ctx context.Context, room *livekit.Room) {
 	t.enqueue(func() {
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyEvent(ctx, &livekit.WebhookEvent{
 			Event: webhook.EventRoomStarted,
 			Room:  room,
 		})
@@ -59,7 +76,7 @@ func (t *telemetryService) RoomStarted(ctx context.Context, room *livekit.Room)
 
 func (t *telemetryService) RoomEnded(ctx context.Context, room *livekit.Room) {
 	t.enqueue(func() {
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyEvent(ctx, &livekit.WebhookEvent{
 			Event: webhook.EventRoomFinished,
 			Room:  room,
 		})
@@ -116,7 +133,7 @@ func (t *telemetryService) ParticipantActive(
 	t.enqueue(func() {
 		if !isMigration {
 			// a participant is considered "joined" only when they become "active"
-			t.NotifyEvent(ctx, &livekit.WebhookEvent{
+			t.notifyEvent(ctx, &livekit.WebhookEvent{
 				Event:       webhook.EventParticipantJoined,
 				Room:        room,
 				Participant: participant,
@@ -203,7 +220,7 @@ func (t *telemetryService) ParticipantLeft(ctx context.Context,
 				webhookEvent = webhook.EventParticipantConnectionAborted
 				analyticsEvent = livekit.AnalyticsEventType_PARTICIPANT_CONNECTION_ABORTED
 			}
-			t.NotifyEvent(ctx, &livekit.WebhookEvent{
+			t.notifyEvent(ctx, &livekit.WebhookEvent{
 				Event:       webhookEvent,
 				Room:        room,
 				Participant: participant,
@@ -246,7 +263,7 @@ func (t *telemetryService) TrackPublished(
 			Sid:      string(participantID),
 			Identity: string(identity),
 		}
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyEvent(ctx, &livekit.WebhookEvent{
 			Event:       webhook.EventTrackPublished,
 			Room:        room,
 			Participant: participant,
@@ -370,7 +387,7 @@ func (t *telemetryService) TrackUnpublished(
 			Sid:      string(participantID),
 			Identity: string(identity),
 		}
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyEvent(ctx, &livekit.WebhookEvent{
 			Event:       webhook.EventTrackUnpublished,
 			Room:        room,
 			Participant: participant,
@@ -441,10 +458,26 @@ func (t *telemetryService) TrackSubscribeRTPStats(
 	})
 }
 
+func (t *telemetryService) WakeWordDetected(
+	ctx context.Context,
+	room *livekit.Room,
+	participant *livekit.ParticipantInfo,
+	track *livekit.TrackInfo,
+) {
+	t.enqueue(func() {
+		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+			Event:       EventWakeWordDetected,
+			Room:        room,
+			Participant: participant,
+			Track:       track,
+		})
+	})
+}
+
 func (t *telemetryService) NotifyEgressEvent(ctx context.Context, event string, info *livekit.EgressInfo) {
 	opts := egress.GetEgressNotifyOptions(info)
 
-	t.NotifyEvent(ctx, &livekit.WebhookEvent{
+	t.notifyEvent(ctx, &livekit.WebhookEvent{
 		Event:      event,
 		EgressInfo: info,
 	}, opts...)
@@ -489,7 +522,7 @@ func (t *telemetryService) IngressDeleted(ctx context.Context, info *livekit.Ing
 
 func (t *telemetryService) IngressStarted(ctx context.Context, info *livekit.IngressInfo) {
 	t.enqueue(func() {
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyEvent(ctx, &livekit.WebhookEvent{
 			Event:       webhook.EventIngressStarted,
 			IngressInfo: info,
 		})
@@ -506,7 +539,7 @@ func (t *telemetryService) IngressUpdated(ctx context.Context, info *livekit.Ing
 
 func (t *telemetryService) IngressEnded(ctx context.Context, info *livekit.IngressInfo) {
 	t.enqueue(func() {
-		t.NotifyEvent(ctx, &livekit.WebhookEvent{
+		t.notifyEvent(ctx, &livekit.WebhookEvent{
 			Event:       webhook.EventIngressEnded,
 			IngressInfo: info,
 		})
@@ -0,0 +1,54 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promConcealment *prometheus.CounterVec
+)
+
+func initConcealmentStats(nodeID string, nodeType livekit.NodeType) {
+	promConcealment = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "audio",
+		Name:        "concealment_seconds",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Audio synthesized for lost packets before decoding, method plc is packet loss concealment, fec is recovered from in-band FEC.",
+	}, []string{"stage", "method"})
+
+	prometheus.MustRegister(promConcealment)
+}
+
+// RecordConcealment counts the audio synthesized for a loss by a stage decoding a stream, e. g. "noise_filter" or "mixer"
+func RecordConcealment(stage string, plc time.Duration, fec time.Duration) {
+	if promConcealment == nil {
+		return
+	}
+
+	if plc > 0 {
+		promConcealment.WithLabelValues(stage, "plc").Add(plc.Seconds())
+	}
+	if fec > 0 {
+		promConcealment.WithLabelValues(stage, "fec").Add(fec.Seconds())
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promDSPMemory          *prometheus.GaugeVec
+	promDSPInstances       *prometheus.GaugeVec
+	promDSPLeakedInstances *prometheus.GaugeVec
+	promDSPLeaks           *prometheus.CounterVec
+)
+
+func initDSPMemoryStats(nodeID string, nodeType livekit.NodeType) {
+	promDSPMemory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "dsp",
+		Name:        "memory_bytes",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Memory held by the instances of a DSP stage, kind go is on the Go heap, native is allocated by native libraries.",
+	}, []string{"stage", "kind"})
+	promDSPInstances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "dsp",
+		Name:        "instances",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"stage"})
+	promDSPLeakedInstances = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "dsp",
+		Name:        "leaked_instances",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Instances of a DSP stage still held past the grace period after their stream ended.",
+	}, []string{"stage"})
+	promDSPLeaks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "dsp",
+		Name:        "leaks",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"stage"})
+
+	prometheus.MustRegister(promDSPMemory)
+	prometheus.MustRegister(promDSPInstances)
+	prometheus.MustRegister(promDSPLeakedInstances)
+	prometheus.MustRegister(promDSPLeaks)
+}
+
+// RecordDSPMemory sets the memory held by the instances of a stage, stages without instances are recorded as zero
+func RecordDSPMemory(stage string, instances int, goBytes int64, nativeBytes int64, leaked int) {
+	if promDSPMemory == nil {
+		return
+	}
+
+	promDSPMemory.WithLabelValues(stage, "go").Set(float64(goBytes))
+	promDSPMemory.WithLabelValues(stage, "native").Set(float64(nativeBytes))
+	promDSPInstances.WithLabelValues(stage).Set(float64(instances))
+	promDSPLeakedInstances.WithLabelValues(stage).Set(float64(leaked))
+}
+
+// RecordDSPLeak counts an instance of a stage newly flagged as leaked
+func RecordDSPLeak(stage string) {
+	if promDSPLeaks == nil {
+		return
+	}
+
+	promDSPLeaks.WithLabelValues(stage).Inc()
+}
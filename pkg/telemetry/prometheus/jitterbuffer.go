@@ -0,0 +1,55 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promJitterBuffer *prometheus.CounterVec
+)
+
+func initJitterBufferStats(nodeID string, nodeType livekit.NodeType) {
+	promJitterBuffer = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "audio",
+		Name:        "jitter_buffer_packets",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Packets of jitter buffers ahead of decoding, result reordered were put back in order, late arrived after their playout time and dropped were duplicates or overflowed the buffer.",
+	}, []string{"stage", "result"})
+
+	prometheus.MustRegister(promJitterBuffer)
+}
+
+// RecordJitterBuffer counts the packets a jitter buffer of a stage, e. g. "tap_mix", reordered, and dropped as late or otherwise
+func RecordJitterBuffer(stage string, reordered uint64, late uint64, dropped uint64) {
+	if promJitterBuffer == nil {
+		return
+	}
+
+	if reordered > 0 {
+		promJitterBuffer.WithLabelValues(stage, "reordered").Add(float64(reordered))
+	}
+	if late > 0 {
+		promJitterBuffer.WithLabelValues(stage, "late").Add(float64(late))
+	}
+	if dropped > 0 {
+		promJitterBuffer.WithLabelValues(stage, "dropped").Add(float64(dropped))
+	}
+}
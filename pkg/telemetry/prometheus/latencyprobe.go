@@ -0,0 +1,71 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promLatencyProbeRuns    *prometheus.CounterVec
+	promLatencyProbeLatency *prometheus.GaugeVec
+	promLatencyProbeLoss    prometheus.Gauge
+)
+
+func initLatencyProbeStats(nodeID string, nodeType livekit.NodeType) {
+	promLatencyProbeRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "latency_probe",
+		Name:        "runs",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"status"})
+	promLatencyProbeLatency = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "latency_probe",
+		Name:        "latency_seconds",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "End-to-end audio latency through the server measured by the last successful probe.",
+	}, []string{"quantile"})
+	promLatencyProbeLoss = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "latency_probe",
+		Name:        "marker_loss",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Share of tone markers not received back in the last successful probe.",
+	})
+
+	prometheus.MustRegister(promLatencyProbeRuns)
+	prometheus.MustRegister(promLatencyProbeLatency)
+	prometheus.MustRegister(promLatencyProbeLoss)
+}
+
+func RecordLatencyProbe(sent int, received int, p50 time.Duration, p95 time.Duration, failed bool) {
+	if failed || sent == 0 {
+		promLatencyProbeRuns.WithLabelValues("failure").Inc()
+		return
+	}
+
+	promLatencyProbeRuns.WithLabelValues("success").Inc()
+	promLatencyProbeLoss.Set(1 - float64(received)/float64(sent))
+	if received != 0 {
+		promLatencyProbeLatency.WithLabelValues("0.5").Set(p50.Seconds())
+		promLatencyProbeLatency.WithLabelValues("0.95").Set(p95.Seconds())
+	}
+}
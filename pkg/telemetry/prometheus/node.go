@@ -105,6 +105,14 @@ func Init(nodeID string, nodeType livekit.NodeType) error {
 	webhook.InitWebhookStats(prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()})
 	initQualityStats(nodeID, nodeType)
 	initDataPacketStats(nodeID, nodeType)
+	initNUMAStats(nodeID, nodeType)
+	initLatencyProbeStats(nodeID, nodeType)
+	initSTTGateStats(nodeID, nodeType)
+	initNoiseFilterStats(nodeID, nodeType)
+	initDSPMemoryStats(nodeID, nodeType)
+	initConcealmentStats(nodeID, nodeType)
+	initJitterBufferStats(nodeID, nodeType)
+	initReceiveStageStats(nodeID, nodeType)
 
 	var err error
 	cpuStats, err = hwstats.NewCPUStats(nil)
@@ -187,6 +195,14 @@ func GetNodeStats(nodeStartedAt int64, prevStats []*livekit.NodeStats, rateInter
 	return stats, nil
 }
 
+// GetCPULoad returns the CPU load of the node between 0 and 1, ok is false before Init
+func GetCPULoad() (load float64, ok bool) {
+	if cpuStats == nil {
+		return 0, false
+	}
+	return cpuStats.GetCPULoad(), true
+}
+
 func getNodeStatsRate(statsHistory []*livekit.NodeStats) *livekit.NodeStatsRate {
 	if len(statsHistory) == 0 {
 		return nil
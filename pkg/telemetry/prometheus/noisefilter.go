@@ -0,0 +1,300 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// reasons a noise filtered packet is forwarded as received
+const (
+	NoiseFilterPassthroughParse            = "parse"
+	NoiseFilterPassthroughCodecUnavailable = "codec_unavailable"
+	NoiseFilterPassthroughInit             = "init"
+	NoiseFilterPassthroughDecode           = "decode"
+	NoiseFilterPassthroughFrameSize        = "frame_size"
+	NoiseFilterPassthroughEncode           = "encode"
+	NoiseFilterPassthroughMarshal          = "marshal"
+	NoiseFilterPassthroughTooLarge         = "too_large"
+	NoiseFilterPassthroughSaturated        = "saturated"
+	NoiseFilterPassthroughLate             = "late"
+)
+
+var (
+	promNoiseFilterFrames       *prometheus.CounterVec
+	promNoiseFilterSuppressed   *prometheus.CounterVec
+	promNoiseFilterMusic        *prometheus.CounterVec
+	promNoiseFilterLatency      *prometheus.HistogramVec
+	promNoiseFilterInitFailures *prometheus.CounterVec
+	promNoiseFilterPassthrough  *prometheus.CounterVec
+	promNoiseFilterShedTracks   prometheus.Gauge
+	promNoiseFilterShedding     *prometheus.CounterVec
+
+	promNoiseFilterLanguageFrames      *prometheus.CounterVec
+	promNoiseFilterLanguageSuppressed  *prometheus.CounterVec
+	promNoiseFilterLanguagePassthrough *prometheus.CounterVec
+
+	noiseFilterStreamsLock sync.Mutex
+	noiseFilterStreams     = make(map[noiseFilterStreamKey]*NoiseFilterStreamStats)
+)
+
+func initNoiseFilterStats(nodeID string, nodeType livekit.NodeType) {
+	constLabels := prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()}
+	promNoiseFilterFrames = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "frames",
+		ConstLabels: constLabels,
+		Help:        "10 ms frames run through RNNoise.",
+	}, []string{"room", "track"})
+	promNoiseFilterSuppressed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "suppressed_frames",
+		ConstLabels: constLabels,
+		Help:        "Frames below the voice activity threshold, attenuated as noise.",
+	}, []string{"room", "track"})
+	promNoiseFilterMusic = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "music_frames",
+		ConstLabels: constLabels,
+		Help:        "Frames detected as music, forwarded without suppression.",
+	}, []string{"room", "track"})
+	promNoiseFilterLatency = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "frame_latency_seconds",
+		ConstLabels: constLabels,
+		Help:        "Time RNNoise takes to denoise one frame.",
+		Buckets:     []float64{0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01},
+	}, []string{"room", "track"})
+	promNoiseFilterInitFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "init_failures",
+		ConstLabels: constLabels,
+		Help:        "RNNoise denoisers that could not be created.",
+	}, []string{"room", "track"})
+	promNoiseFilterPassthrough = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "passthrough_packets",
+		ConstLabels: constLabels,
+		Help:        "Packets forwarded unfiltered as processing failed or was skipped.",
+	}, []string{"room", "track", "reason"})
+
+	promNoiseFilterShedTracks = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "shed_tracks",
+		ConstLabels: constLabels,
+		Help:        "Tracks noise filtering is turned off on while the node is overloaded.",
+	})
+	promNoiseFilterShedding = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "load_shedding_tracks",
+		ConstLabels: constLabels,
+		Help:        "Tracks noise filtering was turned off on (shed) or back on (restored) by load shedding.",
+	}, []string{"action"})
+
+	promNoiseFilterLanguageFrames = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "language_frames",
+		ConstLabels: constLabels,
+		Help:        "10 ms frames run through RNNoise by language of the speaker.",
+	}, []string{"language"})
+	promNoiseFilterLanguageSuppressed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "language_suppressed_frames",
+		ConstLabels: constLabels,
+		Help:        "Frames attenuated as noise by language of the speaker.",
+	}, []string{"language"})
+	promNoiseFilterLanguagePassthrough = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "noise_filter",
+		Name:        "language_passthrough_packets",
+		ConstLabels: constLabels,
+		Help:        "Packets forwarded unfiltered by language of the speaker.",
+	}, []string{"language"})
+
+	prometheus.MustRegister(promNoiseFilterFrames)
+	prometheus.MustRegister(promNoiseFilterSuppressed)
+	prometheus.MustRegister(promNoiseFilterMusic)
+	prometheus.MustRegister(promNoiseFilterLatency)
+	prometheus.MustRegister(promNoiseFilterInitFailures)
+	prometheus.MustRegister(promNoiseFilterPassthrough)
+	prometheus.MustRegister(promNoiseFilterShedTracks)
+	prometheus.MustRegister(promNoiseFilterShedding)
+	prometheus.MustRegister(promNoiseFilterLanguageFrames)
+	prometheus.MustRegister(promNoiseFilterLanguageSuppressed)
+	prometheus.MustRegister(promNoiseFilterLanguagePassthrough)
+}
+
+type noiseFilterStreamKey struct {
+	room  livekit.RoomName
+	track livekit.TrackID
+}
+
+// NoiseFilterStreamStats records the noise filter metrics of an audio track. The methods are no-ops
+// on a nil instance, which is what AcquireNoiseFilterStreamStats returns before metrics are initialized.
+type NoiseFilterStreamStats struct {
+	key  noiseFilterStreamKey
+	refs int
+
+	frames       prometheus.Counter
+	suppressed   prometheus.Counter
+	music        prometheus.Counter
+	latency      prometheus.Observer
+	initFailures prometheus.Counter
+	passthrough  *prometheus.CounterVec
+
+	language atomic.Pointer[noiseFilterLanguageStats]
+}
+
+// noiseFilterLanguageStats are the series of a language, shared by all streams of speakers of the language
+type noiseFilterLanguageStats struct {
+	frames      prometheus.Counter
+	suppressed  prometheus.Counter
+	passthrough prometheus.Counter
+}
+
+// AcquireNoiseFilterStreamStats returns the stats of a track, shared by all streams of the track.
+// Every call has to be paired with Release, the series of the track are removed with the last release.
+func AcquireNoiseFilterStreamStats(room livekit.RoomName, track livekit.TrackID) *NoiseFilterStreamStats {
+	if promNoiseFilterFrames == nil {
+		return nil
+	}
+
+	noiseFilterStreamsLock.Lock()
+	defer noiseFilterStreamsLock.Unlock()
+
+	key := noiseFilterStreamKey{room: room, track: track}
+	s, ok := noiseFilterStreams[key]
+	if !ok {
+		labels := prometheus.Labels{"room": string(room), "track": string(track)}
+		s = &NoiseFilterStreamStats{
+			key:          key,
+			frames:       promNoiseFilterFrames.With(labels),
+			suppressed:   promNoiseFilterSuppressed.With(labels),
+			music:        promNoiseFilterMusic.With(labels),
+			latency:      promNoiseFilterLatency.With(labels),
+			initFailures: promNoiseFilterInitFailures.With(labels),
+			passthrough:  promNoiseFilterPassthrough.MustCurryWith(labels),
+		}
+		noiseFilterStreams[key] = s
+	}
+	s.refs++
+	return s
+}
+
+func (s *NoiseFilterStreamStats) Release() {
+	if s == nil {
+		return
+	}
+
+	noiseFilterStreamsLock.Lock()
+	defer noiseFilterStreamsLock.Unlock()
+
+	if s.refs--; s.refs > 0 {
+		return
+	}
+	delete(noiseFilterStreams, s.key)
+
+	labels := prometheus.Labels{"room": string(s.key.room), "track": string(s.key.track)}
+	promNoiseFilterFrames.Delete(labels)
+	promNoiseFilterSuppressed.Delete(labels)
+	promNoiseFilterMusic.Delete(labels)
+	promNoiseFilterLatency.Delete(labels)
+	promNoiseFilterInitFailures.Delete(labels)
+	promNoiseFilterPassthrough.DeletePartialMatch(labels)
+}
+
+// RecordFrame records a denoised frame, suppressed if it was attenuated as noise
+func (s *NoiseFilterStreamStats) RecordFrame(latency time.Duration, suppressed bool) {
+	if s == nil {
+		return
+	}
+
+	s.frames.Inc()
+	if suppressed {
+		s.suppressed.Inc()
+	}
+	s.latency.Observe(latency.Seconds())
+
+	if l := s.language.Load(); l != nil {
+		l.frames.Inc()
+		if suppressed {
+			l.suppressed.Inc()
+		}
+	}
+}
+
+// RecordMusicFrame records a frame forwarded without suppression as music
+func (s *NoiseFilterStreamStats) RecordMusicFrame() {
+	if s == nil {
+		return
+	}
+	s.music.Inc()
+}
+
+// SetLanguage segments the frames recorded from now on by the language label, e. g. "vi" or "unknown"
+func (s *NoiseFilterStreamStats) SetLanguage(language string) {
+	if s == nil {
+		return
+	}
+	s.language.Store(&noiseFilterLanguageStats{
+		frames:      promNoiseFilterLanguageFrames.WithLabelValues(language),
+		suppressed:  promNoiseFilterLanguageSuppressed.WithLabelValues(language),
+		passthrough: promNoiseFilterLanguagePassthrough.WithLabelValues(language),
+	})
+}
+
+func (s *NoiseFilterStreamStats) RecordInitFailure() {
+	if s == nil {
+		return
+	}
+	s.initFailures.Inc()
+}
+
+func (s *NoiseFilterStreamStats) RecordPassthrough(reason string) {
+	if s == nil {
+		return
+	}
+	s.passthrough.WithLabelValues(reason).Inc()
+	if l := s.language.Load(); l != nil {
+		l.passthrough.Inc()
+	}
+}
+
+// RecordNoiseFilterShedding records tracks load shedding shed or restored, action is "shed" or "restored",
+// and the number of tracks shed now
+func RecordNoiseFilterShedding(action string, tracks int, shed int) {
+	if promNoiseFilterShedding == nil {
+		return
+	}
+	promNoiseFilterShedding.WithLabelValues(action).Add(float64(tracks))
+	promNoiseFilterShedTracks.Set(float64(shed))
+}
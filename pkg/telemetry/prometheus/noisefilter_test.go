@@ -0,0 +1,56 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestNoiseFilterStreamStats(t *testing.T) {
+	// nil before metrics are initialized, recording is a no-op
+	require.Nil(t, AcquireNoiseFilterStreamStats("room", "TR_a"))
+	var nilStats *NoiseFilterStreamStats
+	nilStats.RecordFrame(time.Millisecond, true)
+	nilStats.Release()
+
+	initNoiseFilterStats("node", livekit.NodeType_SERVER)
+
+	s := AcquireNoiseFilterStreamStats("room", "TR_a")
+	// streams of a track share the stats
+	require.Same(t, s, AcquireNoiseFilterStreamStats("room", "TR_a"))
+
+	s.RecordFrame(100*time.Microsecond, false)
+	s.RecordFrame(100*time.Microsecond, true)
+	s.RecordInitFailure()
+	s.RecordPassthrough(NoiseFilterPassthroughDecode)
+	require.Equal(t, 2.0, testutil.ToFloat64(promNoiseFilterFrames.WithLabelValues("room", "TR_a")))
+	require.Equal(t, 1.0, testutil.ToFloat64(promNoiseFilterSuppressed.WithLabelValues("room", "TR_a")))
+	require.Equal(t, 1, testutil.CollectAndCount(promNoiseFilterLatency))
+	require.Equal(t, 1, testutil.CollectAndCount(promNoiseFilterPassthrough))
+
+	// the series are removed with the last release
+	s.Release()
+	require.Equal(t, 1, testutil.CollectAndCount(promNoiseFilterPassthrough))
+	s.Release()
+	require.Equal(t, 0, testutil.CollectAndCount(promNoiseFilterPassthrough))
+	require.Equal(t, 0, testutil.CollectAndCount(promNoiseFilterInitFailures))
+}
@@ -0,0 +1,91 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promNUMARooms *prometheus.GaugeVec
+	promNUMATasks *prometheus.CounterVec
+	promNUMADrops *prometheus.CounterVec
+	promNUMAPages *prometheus.GaugeVec
+)
+
+func initNUMAStats(nodeID string, nodeType livekit.NodeType) {
+	promNUMARooms = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "numa",
+		Name:        "rooms",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"numa_node"})
+	promNUMATasks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "numa",
+		Name:        "tasks",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Media tasks run on a NUMA node, locality is spill when the task was placed on another node.",
+	}, []string{"numa_node", "locality"})
+	promNUMADrops = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "numa",
+		Name:        "dropped_tasks",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Media tasks dropped because all workers were backed up.",
+	}, []string{"numa_node", "priority"})
+	promNUMAPages = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "numa",
+		Name:        "pages",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Kernel NUMA page allocation counters, other_node counts cross-node allocations.",
+	}, []string{"numa_node", "type"})
+
+	prometheus.MustRegister(promNUMARooms)
+	prometheus.MustRegister(promNUMATasks)
+	prometheus.MustRegister(promNUMADrops)
+	prometheus.MustRegister(promNUMAPages)
+}
+
+func SetNUMARooms(numaNode int, rooms int) {
+	promNUMARooms.WithLabelValues(strconv.Itoa(numaNode)).Set(float64(rooms))
+}
+
+func AddNUMATask(numaNode int, spilled bool) {
+	locality := "local"
+	if spilled {
+		locality = "spill"
+	}
+	promNUMATasks.WithLabelValues(strconv.Itoa(numaNode), locality).Inc()
+}
+
+func AddNUMATaskDropped(numaNode int, priority string) {
+	promNUMADrops.WithLabelValues(strconv.Itoa(numaNode), priority).Inc()
+}
+
+func RecordNUMAStats(numaNode int, hit, miss, foreign, localNode, otherNode uint64) {
+	node := strconv.Itoa(numaNode)
+	promNUMAPages.WithLabelValues(node, "numa_hit").Set(float64(hit))
+	promNUMAPages.WithLabelValues(node, "numa_miss").Set(float64(miss))
+	promNUMAPages.WithLabelValues(node, "numa_foreign").Set(float64(foreign))
+	promNUMAPages.WithLabelValues(node, "local_node").Set(float64(localNode))
+	promNUMAPages.WithLabelValues(node, "other_node").Set(float64(otherNode))
+}
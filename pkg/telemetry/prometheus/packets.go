@@ -68,6 +68,9 @@ var (
 	promForwardLatency        prometheus.Gauge
 	promForwardJitter         prometheus.Gauge
 
+	promLossResilienceSwitches *prometheus.CounterVec
+	promREDGenerationSwitches  *prometheus.CounterVec
+
 	promPacketTotalIncomingInitial    prometheus.Counter
 	promPacketTotalIncomingRetransmit prometheus.Counter
 	promPacketTotalOutgoingInitial    prometheus.Counter
@@ -175,6 +178,18 @@ func initPacketStats(nodeID string, nodeType livekit.NodeType) {
 		Name:        "jitter",
 		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
 	})
+	promLossResilienceSwitches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "loss_resilience",
+		Name:        "switches",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"mode"})
+	promREDGenerationSwitches = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "red_generation",
+		Name:        "switches",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"state"})
 
 	prometheus.MustRegister(promPacketTotal)
 	prometheus.MustRegister(promPacketBytes)
@@ -191,6 +206,8 @@ func initPacketStats(nodeID string, nodeType livekit.NodeType) {
 	prometheus.MustRegister(promConnections)
 	prometheus.MustRegister(promForwardLatency)
 	prometheus.MustRegister(promForwardJitter)
+	prometheus.MustRegister(promLossResilienceSwitches)
+	prometheus.MustRegister(promREDGenerationSwitches)
 }
 
 func IncrementPackets(country string, direction Direction, count uint64, retransmit bool) {
@@ -325,3 +342,23 @@ func RecordForwardJitter(_, jitterAvg uint32) {
 	forwardJitter.Store(jitterAvg)
 	promForwardJitter.Set(float64(jitterAvg))
 }
+
+// RecordLossResilienceSwitch records a subscriber switching to the loss resilience mode, "nack", "fec" or "red"
+func RecordLossResilienceSwitch(mode string) {
+	if promLossResilienceSwitches == nil {
+		return
+	}
+	promLossResilienceSwitches.WithLabelValues(mode).Inc()
+}
+
+// RecordREDGenerationSwitch records a subscriber starting or stopping to be sent RED
+func RecordREDGenerationSwitch(active bool) {
+	if promREDGenerationSwitches == nil {
+		return
+	}
+	state := "off"
+	if active {
+		state = "on"
+	}
+	promREDGenerationSwitches.WithLabelValues(state).Inc()
+}
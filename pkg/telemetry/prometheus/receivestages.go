@@ -0,0 +1,47 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promReceiveStageDrops prometheus.Counter
+)
+
+func initReceiveStageStats(nodeID string, nodeType livekit.NodeType) {
+	promReceiveStageDrops = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "receive_stages",
+		Name:        "dropped_packets",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "Received packets dropped as the stages processing published media fell behind, they are recovered as lost.",
+	})
+
+	prometheus.MustRegister(promReceiveStageDrops)
+}
+
+// RecordReceiveStageDrop counts a packet a buffer dropped as its stages fell behind
+func RecordReceiveStageDrop() {
+	if promReceiveStageDrops == nil {
+		return
+	}
+
+	promReceiveStageDrops.Inc()
+}
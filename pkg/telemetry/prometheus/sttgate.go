@@ -0,0 +1,70 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package prometheus
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+var (
+	promSTTGateTransitions *prometheus.CounterVec
+	promSTTGateSaved       prometheus.Counter
+	promSTTGatePaused      prometheus.Gauge
+)
+
+func initSTTGateStats(nodeID string, nodeType livekit.NodeType) {
+	promSTTGateTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "stt_gate",
+		Name:        "transitions",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	}, []string{"state"})
+	promSTTGateSaved = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "stt_gate",
+		Name:        "saved_seconds",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+		Help:        "STT provider stream time saved by pausing agent STT streams during silence, per agent.",
+	})
+	promSTTGatePaused = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   livekitNamespace,
+		Subsystem:   "stt_gate",
+		Name:        "paused_tracks",
+		ConstLabels: prometheus.Labels{"node_id": nodeID, "node_type": nodeType.String()},
+	})
+
+	prometheus.MustRegister(promSTTGateTransitions)
+	prometheus.MustRegister(promSTTGateSaved)
+	prometheus.MustRegister(promSTTGatePaused)
+}
+
+func RecordSTTGatePause() {
+	promSTTGateTransitions.WithLabelValues("paused").Inc()
+	promSTTGatePaused.Inc()
+}
+
+// RecordSTTGateResume records the end of a pause, saved is the provider stream time saved for all agents
+func RecordSTTGateResume(saved time.Duration, closed bool) {
+	if !closed {
+		promSTTGateTransitions.WithLabelValues("open").Inc()
+	}
+	promSTTGatePaused.Dec()
+	promSTTGateSaved.Add(saved.Seconds())
+}
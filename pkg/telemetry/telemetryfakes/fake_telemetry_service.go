@@ -282,6 +282,14 @@ type FakeTelemetryService struct {
 		arg3 *livekit.TrackInfo
 		arg4 bool
 	}
+	WakeWordDetectedStub        func(context.Context, *livekit.Room, *livekit.ParticipantInfo, *livekit.TrackInfo)
+	wakeWordDetectedMutex       sync.RWMutex
+	wakeWordDetectedArgsForCall []struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+		arg3 *livekit.ParticipantInfo
+		arg4 *livekit.TrackInfo
+	}
 	WebhookStub        func(context.Context, *livekit.WebhookInfo)
 	webhookMutex       sync.RWMutex
 	webhookArgsForCall []struct {
@@ -1582,6 +1590,41 @@ func (fake *FakeTelemetryService) TrackUnsubscribedArgsForCall(i int) (context.C
 	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
+func (fake *FakeTelemetryService) WakeWordDetected(arg1 context.Context, arg2 *livekit.Room, arg3 *livekit.ParticipantInfo, arg4 *livekit.TrackInfo) {
+	fake.wakeWordDetectedMutex.Lock()
+	fake.wakeWordDetectedArgsForCall = append(fake.wakeWordDetectedArgsForCall, struct {
+		arg1 context.Context
+		arg2 *livekit.Room
+		arg3 *livekit.ParticipantInfo
+		arg4 *livekit.TrackInfo
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.WakeWordDetectedStub
+	fake.recordInvocation("WakeWordDetected", []interface{}{arg1, arg2, arg3, arg4})
+	fake.wakeWordDetectedMutex.Unlock()
+	if stub != nil {
+		fake.WakeWordDetectedStub(arg1, arg2, arg3, arg4)
+	}
+}
+
+func (fake *FakeTelemetryService) WakeWordDetectedCallCount() int {
+	fake.wakeWordDetectedMutex.RLock()
+	defer fake.wakeWordDetectedMutex.RUnlock()
+	return len(fake.wakeWordDetectedArgsForCall)
+}
+
+func (fake *FakeTelemetryService) WakeWordDetectedCalls(stub func(context.Context, *livekit.Room, *livekit.ParticipantInfo, *livekit.TrackInfo)) {
+	fake.wakeWordDetectedMutex.Lock()
+	defer fake.wakeWordDetectedMutex.Unlock()
+	fake.WakeWordDetectedStub = stub
+}
+
+func (fake *FakeTelemetryService) WakeWordDetectedArgsForCall(i int) (context.Context, *livekit.Room, *livekit.ParticipantInfo, *livekit.TrackInfo) {
+	fake.wakeWordDetectedMutex.RLock()
+	defer fake.wakeWordDetectedMutex.RUnlock()
+	argsForCall := fake.wakeWordDetectedArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
 func (fake *FakeTelemetryService) Webhook(arg1 context.Context, arg2 *livekit.WebhookInfo) {
 	fake.webhookMutex.Lock()
 	fake.webhookArgsForCall = append(fake.webhookArgsForCall, struct {
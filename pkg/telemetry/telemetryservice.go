@@ -68,6 +68,8 @@ type TelemetryService interface {
 	TrackMaxSubscribedVideoQuality(ctx context.Context, participantID livekit.ParticipantID, track *livekit.TrackInfo, mime mime.MimeType, maxQuality livekit.VideoQuality)
 	TrackPublishRTPStats(ctx context.Context, participantID livekit.ParticipantID, trackID livekit.TrackID, mimeType mime.MimeType, layer int, stats *livekit.RTPStats)
 	TrackSubscribeRTPStats(ctx context.Context, participantID livekit.ParticipantID, trackID livekit.TrackID, mimeType mime.MimeType, stats *livekit.RTPStats)
+	// WakeWordDetected - a wake word was spotted in a published audio track, participant and track are nil once gone
+	WakeWordDetected(ctx context.Context, room *livekit.Room, participant *livekit.ParticipantInfo, track *livekit.TrackInfo)
 	EgressStarted(ctx context.Context, info *livekit.EgressInfo)
 	EgressUpdated(ctx context.Context, info *livekit.EgressInfo)
 	EgressEnded(ctx context.Context, info *livekit.EgressInfo)
@@ -99,6 +101,7 @@ type telemetryService struct {
 
 	notifier  webhook.QueuedNotifier
 	jobsQueue *utils.OpsQueue
+	feed      eventFeed
 
 	workersMu  sync.RWMutex
 	workers    map[livekit.ParticipantID]*StatsWorker
@@ -180,6 +183,14 @@ func (t *telemetryService) FlushStats() {
 	}
 }
 
+// Close runs the queued jobs and writes the events still queued for export, for shutdown
+func (t *telemetryService) Close() {
+	<-t.jobsQueue.Stop()
+	if closer, ok := t.AnalyticsService.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
 func (t *telemetryService) run() {
 	for range time.Tick(telemetryStatsUpdateInterval) {
 		t.FlushStats()
@@ -0,0 +1,253 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Azure Speech counts offsets and durations in ticks of 100ns
+const azureTick = 100 * time.Nanosecond
+
+// azureProvider streams to the speech recognition WebSocket protocol of Azure Speech, one stream
+// recognizes one turn of conversation and ends once the service detected its end
+type azureProvider struct {
+	config AzureConfig
+}
+
+func (p *azureProvider) Name() ProviderName {
+	return ProviderAzure
+}
+
+func (p *azureProvider) NewStream(ctx context.Context, params StreamParams) (Stream, error) {
+	language := params.Language
+	if language == "" {
+		language = DefaultConfig.Language
+	}
+	u := p.config.URL
+	if u == "" {
+		u = fmt.Sprintf("wss://%s.stt.speech.microsoft.com/speech/recognition/conversation/cognitiveservices/v1", p.config.Region)
+	}
+	query := url.Values{}
+	query.Set("language", language)
+	query.Set("format", "simple")
+
+	header := http.Header{}
+	header.Set("Ocp-Apim-Subscription-Key", p.config.Key)
+	header.Set("X-ConnectionId", azureID())
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u+"?"+query.Encode(), header)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &azureStream{
+		conn:           conn,
+		requestID:      azureID(),
+		language:       language,
+		interimResults: params.InterimResults,
+	}
+	config := `{"context":{"system":{"name":"agentix","version":"1.0.0"}}}`
+	if err := s.writeText("speech.config", "application/json", config); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	// the audio starts with a WAV header, of a stream of unknown length
+	if err := s.writeAudio(azureWAVHeader()); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// --------------------------------------
+
+type azureStream struct {
+	conn           *websocket.Conn
+	requestID      string
+	language       string
+	interimResults bool
+
+	writeLock sync.Mutex
+	payload   []byte
+	closed    bool
+}
+
+type azureMessage struct {
+	RecognitionStatus string `json:"RecognitionStatus"`
+	DisplayText       string `json:"DisplayText"`
+	Text              string `json:"Text"`
+	Offset            int64  `json:"Offset"`
+	Duration          int64  `json:"Duration"`
+}
+
+func (s *azureStream) WriteAudio(pcm []int16) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+	s.payload = appendPCM(s.payload[:0], pcm)
+	return s.writeAudioLocked(s.payload)
+}
+
+func (s *azureStream) Recv() (Result, error) {
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return Result{}, io.EOF
+			}
+			return Result{}, err
+		}
+
+		path, body := parseAzureMessage(data)
+		switch path {
+		case "turn.end":
+			return Result{}, io.EOF
+
+		case "speech.hypothesis":
+			if !s.interimResults {
+				continue
+			}
+			msg := azureMessage{}
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return Result{}, err
+			}
+			if msg.Text == "" {
+				continue
+			}
+			return s.result(msg.Text, false, msg), nil
+
+		case "speech.phrase":
+			msg := azureMessage{}
+			if err := json.Unmarshal(body, &msg); err != nil {
+				return Result{}, err
+			}
+			if msg.RecognitionStatus != "Success" || msg.DisplayText == "" {
+				continue
+			}
+			return s.result(msg.DisplayText, true, msg), nil
+		}
+	}
+}
+
+func (s *azureStream) Close() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	// audio without a body ends the audio of the request
+	_ = s.writeAudioLocked(nil)
+	s.closed = true
+	return s.conn.Close()
+}
+
+func (s *azureStream) result(text string, final bool, msg azureMessage) Result {
+	return Result{
+		Text:     text,
+		Final:    final,
+		Language: s.language,
+		Start:    time.Duration(msg.Offset) * azureTick,
+		End:      time.Duration(msg.Offset+msg.Duration) * azureTick,
+	}
+}
+
+func (s *azureStream) writeText(path string, contentType string, body string) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	msg := s.headers(path, contentType) + "\r\n" + body
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return s.conn.WriteMessage(websocket.TextMessage, []byte(msg))
+}
+
+func (s *azureStream) writeAudio(audio []byte) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	return s.writeAudioLocked(audio)
+}
+
+// writeAudioLocked sends a binary message, the length of its headers in two bytes followed by the
+// headers and the audio. Must be called with the write lock held.
+func (s *azureStream) writeAudioLocked(audio []byte) error {
+	headers := s.headers("audio", "audio/x-wav")
+	msg := make([]byte, 0, 2+len(headers)+len(audio))
+	msg = binary.BigEndian.AppendUint16(msg, uint16(len(headers)))
+	msg = append(msg, headers...)
+	msg = append(msg, audio...)
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return s.conn.WriteMessage(websocket.BinaryMessage, msg)
+}
+
+func (s *azureStream) headers(path string, contentType string) string {
+	return "Path: " + path + "\r\n" +
+		"X-RequestId: " + s.requestID + "\r\n" +
+		"X-Timestamp: " + time.Now().UTC().Format("2006-01-02T15:04:05.000Z") + "\r\n" +
+		"Content-Type: " + contentType + "\r\n"
+}
+
+// parseAzureMessage returns the path and body of a text message, headers and body separated by an empty line
+func parseAzureMessage(data []byte) (string, []byte) {
+	headers, body, _ := bytes.Cut(data, []byte("\r\n\r\n"))
+	for _, line := range strings.Split(string(headers), "\r\n") {
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Path") {
+			return strings.TrimSpace(value), body
+		}
+	}
+	return "", body
+}
+
+// azureID returns 32 random hex digits, the format of request and connection IDs
+func azureID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// azureWAVHeader returns the header of mono 16 bit PCM at SampleRate, of unknown length
+func azureWAVHeader() []byte {
+	b := make([]byte, 0, 44)
+	b = append(b, "RIFF"...)
+	b = binary.LittleEndian.AppendUint32(b, 0)
+	b = append(b, "WAVEfmt "...)
+	b = binary.LittleEndian.AppendUint32(b, 16)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint16(b, 1)
+	b = binary.LittleEndian.AppendUint32(b, SampleRate)
+	b = binary.LittleEndian.AppendUint32(b, SampleRate*2)
+	b = binary.LittleEndian.AppendUint16(b, 2)
+	b = binary.LittleEndian.AppendUint16(b, 16)
+	b = append(b, "data"...)
+	return binary.LittleEndian.AppendUint32(b, 0)
+}
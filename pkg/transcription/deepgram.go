@@ -0,0 +1,151 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const deepgramURL = "wss://api.deepgram.com/v1/listen"
+
+// deepgramProvider streams to the live transcription WebSocket API of Deepgram
+type deepgramProvider struct {
+	config DeepgramConfig
+}
+
+func (p *deepgramProvider) Name() ProviderName {
+	return ProviderDeepgram
+}
+
+func (p *deepgramProvider) NewStream(ctx context.Context, params StreamParams) (Stream, error) {
+	u := p.config.URL
+	if u == "" {
+		u = deepgramURL
+	}
+	query := url.Values{}
+	query.Set("encoding", "linear16")
+	query.Set("sample_rate", strconv.Itoa(SampleRate))
+	query.Set("channels", "1")
+	query.Set("punctuate", "true")
+	query.Set("interim_results", strconv.FormatBool(params.InterimResults))
+	if p.config.Model != "" {
+		query.Set("model", p.config.Model)
+	}
+	if params.Language != "" {
+		query.Set("language", params.Language)
+	}
+
+	header := http.Header{}
+	if p.config.APIKey != "" {
+		header.Set("Authorization", "Token "+p.config.APIKey)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, u+"?"+query.Encode(), header)
+	if err != nil {
+		return nil, err
+	}
+	return &deepgramStream{conn: conn, language: params.Language}, nil
+}
+
+// --------------------------------------
+
+type deepgramStream struct {
+	conn     *websocket.Conn
+	language string
+
+	writeLock sync.Mutex
+	payload   []byte
+	closed    bool
+}
+
+type deepgramMessage struct {
+	Type     string  `json:"type"`
+	Start    float64 `json:"start"`
+	Duration float64 `json:"duration"`
+	IsFinal  bool    `json:"is_final"`
+	Channel  struct {
+		Alternatives []struct {
+			Transcript string   `json:"transcript"`
+			Languages  []string `json:"languages"`
+		} `json:"alternatives"`
+	} `json:"channel"`
+}
+
+func (s *deepgramStream) WriteAudio(pcm []int16) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+	s.payload = appendPCM(s.payload[:0], pcm)
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	return s.conn.WriteMessage(websocket.BinaryMessage, s.payload)
+}
+
+func (s *deepgramStream) Recv() (Result, error) {
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				return Result{}, io.EOF
+			}
+			return Result{}, err
+		}
+
+		msg := deepgramMessage{}
+		if err := json.Unmarshal(data, &msg); err != nil {
+			return Result{}, err
+		}
+		if msg.Type != "Results" || len(msg.Channel.Alternatives) == 0 || msg.Channel.Alternatives[0].Transcript == "" {
+			continue
+		}
+
+		alternative := msg.Channel.Alternatives[0]
+		result := Result{
+			Text:     alternative.Transcript,
+			Final:    msg.IsFinal,
+			Language: s.language,
+			Start:    time.Duration(msg.Start * float64(time.Second)),
+			End:      time.Duration((msg.Start + msg.Duration) * float64(time.Second)),
+		}
+		if len(alternative.Languages) != 0 {
+			result.Language = alternative.Languages[0]
+		}
+		return result, nil
+	}
+}
+
+func (s *deepgramStream) Close() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	_ = s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	_ = s.conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"CloseStream"}`))
+	return s.conn.Close()
+}
@@ -0,0 +1,189 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+const (
+	googleAddress = "speech.googleapis.com:443"
+	googleMethod  = "/google.cloud.speech.v1.Speech/StreamingRecognize"
+)
+
+// googleStreamDesc describes StreamingRecognize, the messages of speech.proto are copied without the
+// service, which would be named after the package of the copy
+var googleStreamDesc = grpc.StreamDesc{StreamName: "StreamingRecognize", ClientStreams: true, ServerStreams: true}
+
+// googleProvider streams to StreamingRecognize of the Cloud Speech-to-Text v1 gRPC API, authorized with
+// an API key. Google ends streams after about five minutes.
+type googleProvider struct {
+	config GoogleConfig
+}
+
+func (p *googleProvider) Name() ProviderName {
+	return ProviderGoogle
+}
+
+func (p *googleProvider) NewStream(ctx context.Context, params StreamParams) (Stream, error) {
+	address := p.config.Address
+	if address == "" {
+		address = googleAddress
+	}
+	language := params.Language
+	if language == "" {
+		language = DefaultConfig.Language
+	}
+
+	stream, err := newGRPCStream(ctx, address, credentials.NewTLS(nil), startGoogleStream, "x-goog-api-key", p.config.APIKey)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &googleStream{grpcStream: stream, language: language}
+	if err := stream.stream.Send(&StreamingRecognizeRequest{
+		StreamingRequest: &StreamingRecognizeRequest_StreamingConfig{
+			StreamingConfig: &StreamingRecognitionConfig{
+				Config: &RecognitionConfig{
+					Encoding:                   RecognitionConfig_LINEAR16,
+					SampleRateHertz:            SampleRate,
+					LanguageCode:               language,
+					EnableAutomaticPunctuation: true,
+					Model:                      p.config.Model,
+				},
+				InterimResults: params.InterimResults,
+			},
+		},
+	}); err != nil {
+		_ = stream.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func startGoogleStream(ctx context.Context, conn *grpc.ClientConn) (grpc.BidiStreamingClient[StreamingRecognizeRequest, StreamingRecognizeResponse], error) {
+	stream, err := conn.NewStream(ctx, &googleStreamDesc, googleMethod)
+	if err != nil {
+		return nil, err
+	}
+	return &grpc.GenericClientStream[StreamingRecognizeRequest, StreamingRecognizeResponse]{ClientStream: stream}, nil
+}
+
+// --------------------------------------
+
+type googleStream struct {
+	*grpcStream[StreamingRecognizeRequest, StreamingRecognizeResponse]
+	language string
+
+	writeLock sync.Mutex
+	closed    bool
+
+	// results of a response not returned yet, and the end of the last final one, where the next one starts
+	pending []Result
+	lastEnd time.Duration
+}
+
+func (s *googleStream) WriteAudio(pcm []int16) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+	return s.stream.Send(&StreamingRecognizeRequest{
+		StreamingRequest: &StreamingRecognizeRequest_AudioContent{AudioContent: appendPCM(nil, pcm)},
+	})
+}
+
+func (s *googleStream) Recv() (Result, error) {
+	for len(s.pending) == 0 {
+		res, err := s.stream.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return Result{}, io.EOF
+			}
+			return Result{}, err
+		}
+		if code := res.GetError().GetCode(); code != 0 {
+			return Result{}, fmt.Errorf("speech recognition failed, code %d: %s", code, res.GetError().GetMessage())
+		}
+		s.pending = s.results(res)
+	}
+
+	result := s.pending[0]
+	s.pending = s.pending[1:]
+	return result, nil
+}
+
+func (s *googleStream) Close() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.close()
+}
+
+// results returns the final results of a response one by one, and its interim results, the stable
+// one followed by ones likely to change, as one. Only the first alternative of a result is used.
+func (s *googleStream) results(res *StreamingRecognizeResponse) []Result {
+	var results []Result
+	var interim []string
+	var interimEnd time.Duration
+	for _, r := range res.Results {
+		if len(r.Alternatives) == 0 || r.Alternatives[0].Transcript == "" {
+			continue
+		}
+		transcript := strings.TrimSpace(r.Alternatives[0].Transcript)
+		end := r.ResultEndTime.AsDuration()
+		language := r.LanguageCode
+		if language == "" {
+			language = s.language
+		}
+		if !r.IsFinal {
+			interim = append(interim, transcript)
+			interimEnd = max(interimEnd, end)
+			continue
+		}
+		results = append(results, Result{
+			Text:     transcript,
+			Final:    true,
+			Language: language,
+			Start:    s.lastEnd,
+			End:      end,
+		})
+		s.lastEnd = end
+	}
+	if len(interim) != 0 {
+		results = append(results, Result{
+			Text:     strings.Join(interim, " "),
+			Language: s.language,
+			Start:    s.lastEnd,
+			End:      interimEnd,
+		})
+	}
+	return results
+}
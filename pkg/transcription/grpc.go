@@ -0,0 +1,70 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcription
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative whisper.proto speech.proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// grpcStream is a bidirectional streaming call on a connection of its own
+type grpcStream[Req, Res any] struct {
+	stream grpc.BidiStreamingClient[Req, Res]
+	conn   *grpc.ClientConn
+	cancel context.CancelFunc
+}
+
+// newGRPCStream starts the call of the server at address with call, with the key value pairs of md as
+// metadata
+func newGRPCStream[Req, Res any](
+	ctx context.Context,
+	address string,
+	creds credentials.TransportCredentials,
+	call func(ctx context.Context, conn *grpc.ClientConn) (grpc.BidiStreamingClient[Req, Res], error),
+	md ...string,
+) (*grpcStream[Req, Res], error) {
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+
+	// the call outlives ctx, which only bounds setting it up
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	if len(md) != 0 {
+		callCtx = metadata.AppendToOutgoingContext(callCtx, md...)
+	}
+	stop := context.AfterFunc(ctx, cancel)
+	defer stop()
+
+	stream, err := call(callCtx, conn)
+	if err != nil {
+		cancel()
+		_ = conn.Close()
+		return nil, err
+	}
+	return &grpcStream[Req, Res]{stream: stream, conn: conn, cancel: cancel}, nil
+}
+
+func (s *grpcStream[Req, Res]) close() error {
+	_ = s.stream.CloseSend()
+	s.cancel()
+	return s.conn.Close()
+}
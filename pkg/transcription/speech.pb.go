@@ -0,0 +1,620 @@
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: speech.proto
+
+package transcription
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RecognitionConfig_AudioEncoding int32
+
+const (
+	RecognitionConfig_ENCODING_UNSPECIFIED RecognitionConfig_AudioEncoding = 0
+	// signed 16 bit little endian PCM
+	RecognitionConfig_LINEAR16 RecognitionConfig_AudioEncoding = 1
+)
+
+// Enum value maps for RecognitionConfig_AudioEncoding.
+var (
+	RecognitionConfig_AudioEncoding_name = map[int32]string{
+		0: "ENCODING_UNSPECIFIED",
+		1: "LINEAR16",
+	}
+	RecognitionConfig_AudioEncoding_value = map[string]int32{
+		"ENCODING_UNSPECIFIED": 0,
+		"LINEAR16":             1,
+	}
+)
+
+func (x RecognitionConfig_AudioEncoding) Enum() *RecognitionConfig_AudioEncoding {
+	p := new(RecognitionConfig_AudioEncoding)
+	*p = x
+	return p
+}
+
+func (x RecognitionConfig_AudioEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RecognitionConfig_AudioEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_speech_proto_enumTypes[0].Descriptor()
+}
+
+func (RecognitionConfig_AudioEncoding) Type() protoreflect.EnumType {
+	return &file_speech_proto_enumTypes[0]
+}
+
+func (x RecognitionConfig_AudioEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RecognitionConfig_AudioEncoding.Descriptor instead.
+func (RecognitionConfig_AudioEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{2, 0}
+}
+
+type StreamingRecognizeRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to StreamingRequest:
+	//
+	//	*StreamingRecognizeRequest_StreamingConfig
+	//	*StreamingRecognizeRequest_AudioContent
+	StreamingRequest isStreamingRecognizeRequest_StreamingRequest `protobuf_oneof:"streaming_request"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StreamingRecognizeRequest) Reset() {
+	*x = StreamingRecognizeRequest{}
+	mi := &file_speech_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamingRecognizeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamingRecognizeRequest) ProtoMessage() {}
+
+func (x *StreamingRecognizeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamingRecognizeRequest.ProtoReflect.Descriptor instead.
+func (*StreamingRecognizeRequest) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StreamingRecognizeRequest) GetStreamingRequest() isStreamingRecognizeRequest_StreamingRequest {
+	if x != nil {
+		return x.StreamingRequest
+	}
+	return nil
+}
+
+func (x *StreamingRecognizeRequest) GetStreamingConfig() *StreamingRecognitionConfig {
+	if x != nil {
+		if x, ok := x.StreamingRequest.(*StreamingRecognizeRequest_StreamingConfig); ok {
+			return x.StreamingConfig
+		}
+	}
+	return nil
+}
+
+func (x *StreamingRecognizeRequest) GetAudioContent() []byte {
+	if x != nil {
+		if x, ok := x.StreamingRequest.(*StreamingRecognizeRequest_AudioContent); ok {
+			return x.AudioContent
+		}
+	}
+	return nil
+}
+
+type isStreamingRecognizeRequest_StreamingRequest interface {
+	isStreamingRecognizeRequest_StreamingRequest()
+}
+
+type StreamingRecognizeRequest_StreamingConfig struct {
+	// sent first, alone
+	StreamingConfig *StreamingRecognitionConfig `protobuf:"bytes,1,opt,name=streaming_config,json=streamingConfig,proto3,oneof"`
+}
+
+type StreamingRecognizeRequest_AudioContent struct {
+	AudioContent []byte `protobuf:"bytes,2,opt,name=audio_content,json=audioContent,proto3,oneof"`
+}
+
+func (*StreamingRecognizeRequest_StreamingConfig) isStreamingRecognizeRequest_StreamingRequest() {}
+
+func (*StreamingRecognizeRequest_AudioContent) isStreamingRecognizeRequest_StreamingRequest() {}
+
+type StreamingRecognitionConfig struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Config         *RecognitionConfig     `protobuf:"bytes,1,opt,name=config,proto3" json:"config,omitempty"`
+	InterimResults bool                   `protobuf:"varint,3,opt,name=interim_results,json=interimResults,proto3" json:"interim_results,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *StreamingRecognitionConfig) Reset() {
+	*x = StreamingRecognitionConfig{}
+	mi := &file_speech_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamingRecognitionConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamingRecognitionConfig) ProtoMessage() {}
+
+func (x *StreamingRecognitionConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamingRecognitionConfig.ProtoReflect.Descriptor instead.
+func (*StreamingRecognitionConfig) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StreamingRecognitionConfig) GetConfig() *RecognitionConfig {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+func (x *StreamingRecognitionConfig) GetInterimResults() bool {
+	if x != nil {
+		return x.InterimResults
+	}
+	return false
+}
+
+type RecognitionConfig struct {
+	state                      protoimpl.MessageState          `protogen:"open.v1"`
+	Encoding                   RecognitionConfig_AudioEncoding `protobuf:"varint,1,opt,name=encoding,proto3,enum=agentix.transcription.RecognitionConfig_AudioEncoding" json:"encoding,omitempty"`
+	SampleRateHertz            int32                           `protobuf:"varint,2,opt,name=sample_rate_hertz,json=sampleRateHertz,proto3" json:"sample_rate_hertz,omitempty"`
+	LanguageCode               string                          `protobuf:"bytes,3,opt,name=language_code,json=languageCode,proto3" json:"language_code,omitempty"`
+	EnableAutomaticPunctuation bool                            `protobuf:"varint,11,opt,name=enable_automatic_punctuation,json=enableAutomaticPunctuation,proto3" json:"enable_automatic_punctuation,omitempty"`
+	Model                      string                          `protobuf:"bytes,13,opt,name=model,proto3" json:"model,omitempty"`
+	unknownFields              protoimpl.UnknownFields
+	sizeCache                  protoimpl.SizeCache
+}
+
+func (x *RecognitionConfig) Reset() {
+	*x = RecognitionConfig{}
+	mi := &file_speech_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecognitionConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecognitionConfig) ProtoMessage() {}
+
+func (x *RecognitionConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecognitionConfig.ProtoReflect.Descriptor instead.
+func (*RecognitionConfig) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RecognitionConfig) GetEncoding() RecognitionConfig_AudioEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return RecognitionConfig_ENCODING_UNSPECIFIED
+}
+
+func (x *RecognitionConfig) GetSampleRateHertz() int32 {
+	if x != nil {
+		return x.SampleRateHertz
+	}
+	return 0
+}
+
+func (x *RecognitionConfig) GetLanguageCode() string {
+	if x != nil {
+		return x.LanguageCode
+	}
+	return ""
+}
+
+func (x *RecognitionConfig) GetEnableAutomaticPunctuation() bool {
+	if x != nil {
+		return x.EnableAutomaticPunctuation
+	}
+	return false
+}
+
+func (x *RecognitionConfig) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+type StreamingRecognizeResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// a google.rpc.Status
+	Error         *Status                       `protobuf:"bytes,1,opt,name=error,proto3" json:"error,omitempty"`
+	Results       []*StreamingRecognitionResult `protobuf:"bytes,2,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamingRecognizeResponse) Reset() {
+	*x = StreamingRecognizeResponse{}
+	mi := &file_speech_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamingRecognizeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamingRecognizeResponse) ProtoMessage() {}
+
+func (x *StreamingRecognizeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamingRecognizeResponse.ProtoReflect.Descriptor instead.
+func (*StreamingRecognizeResponse) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *StreamingRecognizeResponse) GetError() *Status {
+	if x != nil {
+		return x.Error
+	}
+	return nil
+}
+
+func (x *StreamingRecognizeResponse) GetResults() []*StreamingRecognitionResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type StreamingRecognitionResult struct {
+	state        protoimpl.MessageState          `protogen:"open.v1"`
+	Alternatives []*SpeechRecognitionAlternative `protobuf:"bytes,1,rep,name=alternatives,proto3" json:"alternatives,omitempty"`
+	IsFinal      bool                            `protobuf:"varint,2,opt,name=is_final,json=isFinal,proto3" json:"is_final,omitempty"`
+	// position of the end of the result in the audio of the stream
+	ResultEndTime *durationpb.Duration `protobuf:"bytes,4,opt,name=result_end_time,json=resultEndTime,proto3" json:"result_end_time,omitempty"`
+	LanguageCode  string               `protobuf:"bytes,6,opt,name=language_code,json=languageCode,proto3" json:"language_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamingRecognitionResult) Reset() {
+	*x = StreamingRecognitionResult{}
+	mi := &file_speech_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamingRecognitionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamingRecognitionResult) ProtoMessage() {}
+
+func (x *StreamingRecognitionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamingRecognitionResult.ProtoReflect.Descriptor instead.
+func (*StreamingRecognitionResult) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *StreamingRecognitionResult) GetAlternatives() []*SpeechRecognitionAlternative {
+	if x != nil {
+		return x.Alternatives
+	}
+	return nil
+}
+
+func (x *StreamingRecognitionResult) GetIsFinal() bool {
+	if x != nil {
+		return x.IsFinal
+	}
+	return false
+}
+
+func (x *StreamingRecognitionResult) GetResultEndTime() *durationpb.Duration {
+	if x != nil {
+		return x.ResultEndTime
+	}
+	return nil
+}
+
+func (x *StreamingRecognitionResult) GetLanguageCode() string {
+	if x != nil {
+		return x.LanguageCode
+	}
+	return ""
+}
+
+type SpeechRecognitionAlternative struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Transcript    string                 `protobuf:"bytes,1,opt,name=transcript,proto3" json:"transcript,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpeechRecognitionAlternative) Reset() {
+	*x = SpeechRecognitionAlternative{}
+	mi := &file_speech_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeechRecognitionAlternative) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeechRecognitionAlternative) ProtoMessage() {}
+
+func (x *SpeechRecognitionAlternative) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeechRecognitionAlternative.ProtoReflect.Descriptor instead.
+func (*SpeechRecognitionAlternative) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *SpeechRecognitionAlternative) GetTranscript() string {
+	if x != nil {
+		return x.Transcript
+	}
+	return ""
+}
+
+// Status is google.rpc.Status without details
+type Status struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Status) Reset() {
+	*x = Status{}
+	mi := &file_speech_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Status) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Status) ProtoMessage() {}
+
+func (x *Status) ProtoReflect() protoreflect.Message {
+	mi := &file_speech_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Status.ProtoReflect.Descriptor instead.
+func (*Status) Descriptor() ([]byte, []int) {
+	return file_speech_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *Status) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *Status) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+var File_speech_proto protoreflect.FileDescriptor
+
+const file_speech_proto_rawDesc = "" +
+	"\n" +
+	"\fspeech.proto\x12\x15agentix.transcription\x1a\x1egoogle/protobuf/duration.proto\"\xb7\x01\n" +
+	"\x19StreamingRecognizeRequest\x12^\n" +
+	"\x10streaming_config\x18\x01 \x01(\v21.agentix.transcription.StreamingRecognitionConfigH\x00R\x0fstreamingConfig\x12%\n" +
+	"\raudio_content\x18\x02 \x01(\fH\x00R\faudioContentB\x13\n" +
+	"\x11streaming_request\"\x87\x01\n" +
+	"\x1aStreamingRecognitionConfig\x12@\n" +
+	"\x06config\x18\x01 \x01(\v2(.agentix.transcription.RecognitionConfigR\x06config\x12'\n" +
+	"\x0finterim_results\x18\x03 \x01(\bR\x0einterimResults\"\xc9\x02\n" +
+	"\x11RecognitionConfig\x12R\n" +
+	"\bencoding\x18\x01 \x01(\x0e26.agentix.transcription.RecognitionConfig.AudioEncodingR\bencoding\x12*\n" +
+	"\x11sample_rate_hertz\x18\x02 \x01(\x05R\x0fsampleRateHertz\x12#\n" +
+	"\rlanguage_code\x18\x03 \x01(\tR\flanguageCode\x12@\n" +
+	"\x1cenable_automatic_punctuation\x18\v \x01(\bR\x1aenableAutomaticPunctuation\x12\x14\n" +
+	"\x05model\x18\r \x01(\tR\x05model\"7\n" +
+	"\rAudioEncoding\x12\x18\n" +
+	"\x14ENCODING_UNSPECIFIED\x10\x00\x12\f\n" +
+	"\bLINEAR16\x10\x01\"\x9e\x01\n" +
+	"\x1aStreamingRecognizeResponse\x123\n" +
+	"\x05error\x18\x01 \x01(\v2\x1d.agentix.transcription.StatusR\x05error\x12K\n" +
+	"\aresults\x18\x02 \x03(\v21.agentix.transcription.StreamingRecognitionResultR\aresults\"\xf8\x01\n" +
+	"\x1aStreamingRecognitionResult\x12W\n" +
+	"\falternatives\x18\x01 \x03(\v23.agentix.transcription.SpeechRecognitionAlternativeR\falternatives\x12\x19\n" +
+	"\bis_final\x18\x02 \x01(\bR\aisFinal\x12A\n" +
+	"\x0fresult_end_time\x18\x04 \x01(\v2\x19.google.protobuf.DurationR\rresultEndTime\x12#\n" +
+	"\rlanguage_code\x18\x06 \x01(\tR\flanguageCode\">\n" +
+	"\x1cSpeechRecognitionAlternative\x12\x1e\n" +
+	"\n" +
+	"transcript\x18\x01 \x01(\tR\n" +
+	"transcript\"6\n" +
+	"\x06Status\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessageB5Z3github.com/livekit/livekit-server/pkg/transcriptionb\x06proto3"
+
+var (
+	file_speech_proto_rawDescOnce sync.Once
+	file_speech_proto_rawDescData []byte
+)
+
+func file_speech_proto_rawDescGZIP() []byte {
+	file_speech_proto_rawDescOnce.Do(func() {
+		file_speech_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_speech_proto_rawDesc), len(file_speech_proto_rawDesc)))
+	})
+	return file_speech_proto_rawDescData
+}
+
+var file_speech_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_speech_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_speech_proto_goTypes = []any{
+	(RecognitionConfig_AudioEncoding)(0), // 0: agentix.transcription.RecognitionConfig.AudioEncoding
+	(*StreamingRecognizeRequest)(nil),    // 1: agentix.transcription.StreamingRecognizeRequest
+	(*StreamingRecognitionConfig)(nil),   // 2: agentix.transcription.StreamingRecognitionConfig
+	(*RecognitionConfig)(nil),            // 3: agentix.transcription.RecognitionConfig
+	(*StreamingRecognizeResponse)(nil),   // 4: agentix.transcription.StreamingRecognizeResponse
+	(*StreamingRecognitionResult)(nil),   // 5: agentix.transcription.StreamingRecognitionResult
+	(*SpeechRecognitionAlternative)(nil), // 6: agentix.transcription.SpeechRecognitionAlternative
+	(*Status)(nil),                       // 7: agentix.transcription.Status
+	(*durationpb.Duration)(nil),          // 8: google.protobuf.Duration
+}
+var file_speech_proto_depIdxs = []int32{
+	2, // 0: agentix.transcription.StreamingRecognizeRequest.streaming_config:type_name -> agentix.transcription.StreamingRecognitionConfig
+	3, // 1: agentix.transcription.StreamingRecognitionConfig.config:type_name -> agentix.transcription.RecognitionConfig
+	0, // 2: agentix.transcription.RecognitionConfig.encoding:type_name -> agentix.transcription.RecognitionConfig.AudioEncoding
+	7, // 3: agentix.transcription.StreamingRecognizeResponse.error:type_name -> agentix.transcription.Status
+	5, // 4: agentix.transcription.StreamingRecognizeResponse.results:type_name -> agentix.transcription.StreamingRecognitionResult
+	6, // 5: agentix.transcription.StreamingRecognitionResult.alternatives:type_name -> agentix.transcription.SpeechRecognitionAlternative
+	8, // 6: agentix.transcription.StreamingRecognitionResult.result_end_time:type_name -> google.protobuf.Duration
+	7, // [7:7] is the sub-list for method output_type
+	7, // [7:7] is the sub-list for method input_type
+	7, // [7:7] is the sub-list for extension type_name
+	7, // [7:7] is the sub-list for extension extendee
+	0, // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_speech_proto_init() }
+func file_speech_proto_init() {
+	if File_speech_proto != nil {
+		return
+	}
+	file_speech_proto_msgTypes[0].OneofWrappers = []any{
+		(*StreamingRecognizeRequest_StreamingConfig)(nil),
+		(*StreamingRecognizeRequest_AudioContent)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_speech_proto_rawDesc), len(file_speech_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_speech_proto_goTypes,
+		DependencyIndexes: file_speech_proto_depIdxs,
+		EnumInfos:         file_speech_proto_enumTypes,
+		MessageInfos:      file_speech_proto_msgTypes,
+	}.Build()
+	File_speech_proto = out.File
+	file_speech_proto_goTypes = nil
+	file_speech_proto_depIdxs = nil
+}
@@ -0,0 +1,230 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package transcription runs streaming speech to text of audio tracks with a TranscriptionProvider:
+// Deepgram, Google Cloud Speech-to-Text, Azure Speech, or a local Whisper server implementing the gRPC
+// service of whisper.proto.
+package transcription
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ProviderName selects a TranscriptionProvider
+type ProviderName string
+
+const (
+	ProviderDeepgram ProviderName = "deepgram"
+	ProviderGoogle   ProviderName = "google"
+	ProviderAzure    ProviderName = "azure"
+	ProviderWhisper  ProviderName = "whisper"
+)
+
+// sample rate of the audio written to streams
+const SampleRate = 16000
+
+// RoomKey is the key of the JSON object of room metadata configuring transcription of the room
+const RoomKey = "agentix.transcription"
+
+const writeTimeout = 5 * time.Second
+
+var (
+	ErrUnknownProvider = errors.New("unknown transcription provider, must be deepgram, google, azure or whisper")
+	ErrNotConfigured   = errors.New("transcription provider credentials or address missing")
+	ErrStreamClosed    = errors.New("transcription stream closed")
+)
+
+// TranscriptionProvider recognizes the speech of audio streams
+type TranscriptionProvider interface {
+	Name() ProviderName
+	// NewStream starts recognizing mono 16 bit PCM at SampleRate
+	NewStream(ctx context.Context, params StreamParams) (Stream, error)
+}
+
+type StreamParams struct {
+	// BCP-47 code of the language spoken, e. g. en-US
+	Language string
+	// results are also returned while speech is recognized, not only once it is final
+	InterimResults bool
+}
+
+// Stream is the recognition of the audio of a track. Audio is written and results are received from
+// different goroutines, providers may end streams after a while, they are to be started again.
+type Stream interface {
+	// WriteAudio sends mono 16 bit PCM at SampleRate
+	WriteAudio(pcm []int16) error
+	// Recv returns the next result, waiting for it, and io.EOF once the provider ended the stream
+	Recv() (Result, error)
+	// Close ends the stream, results not received yet are dropped
+	Close() error
+}
+
+// Result is the text of an utterance, interim results are replaced by the following ones until one is final
+type Result struct {
+	Text  string
+	Final bool
+	// language recognized, that of the stream if the provider does not tell
+	Language string
+	// position of the utterance in the audio of the stream
+	Start time.Duration
+	End   time.Duration
+}
+
+// Config enables transcription of the published audio tracks of rooms, with the results sent to the
+// room as transcription data packets and final ones as webhook events. Rooms override the settings
+// with the RoomKey object of their metadata.
+type Config struct {
+	Enabled bool `yaml:"enabled,omitempty"`
+	// transcribe rooms whose metadata does not enable it
+	AllRooms bool `yaml:"all_rooms,omitempty"`
+	// provider, language and interim results of rooms that do not set their own
+	Provider       ProviderName `yaml:"provider,omitempty"`
+	Language       string       `yaml:"language,omitempty"`
+	InterimResults bool         `yaml:"interim_results,omitempty"`
+	// tracks of a room transcribed at a time
+	MaxStreams int `yaml:"max_streams,omitempty"`
+
+	Deepgram DeepgramConfig `yaml:"deepgram,omitempty"`
+	Google   GoogleConfig   `yaml:"google,omitempty"`
+	Azure    AzureConfig    `yaml:"azure,omitempty"`
+	Whisper  WhisperConfig  `yaml:"whisper,omitempty"`
+}
+
+type DeepgramConfig struct {
+	APIKey string `yaml:"api_key,omitempty"`
+	Model  string `yaml:"model,omitempty"`
+	// WebSocket URL of the listen API, defaults to that of Deepgram
+	URL string `yaml:"url,omitempty"`
+}
+
+type GoogleConfig struct {
+	APIKey string `yaml:"api_key,omitempty"`
+	Model  string `yaml:"model,omitempty"`
+	// host:port of the gRPC API, defaults to that of Google
+	Address string `yaml:"address,omitempty"`
+}
+
+type AzureConfig struct {
+	Key    string `yaml:"key,omitempty"`
+	Region string `yaml:"region,omitempty"`
+	// WebSocket URL of the speech service, defaults to that of the region
+	URL string `yaml:"url,omitempty"`
+}
+
+type WhisperConfig struct {
+	// host:port of the plaintext gRPC server
+	Address string `yaml:"address,omitempty"`
+}
+
+var (
+	DefaultConfig = Config{
+		Provider:       ProviderDeepgram,
+		Language:       "en-US",
+		InterimResults: true,
+		MaxStreams:     8,
+	}
+)
+
+// RoomSettings is the RoomKey object of room metadata, unset fields keep the settings of the config
+type RoomSettings struct {
+	Enabled        *bool        `json:"enabled,omitempty"`
+	Provider       ProviderName `json:"provider,omitempty"`
+	Language       string       `json:"language,omitempty"`
+	InterimResults *bool        `json:"interim_results,omitempty"`
+}
+
+// Settings is how the tracks of a room are transcribed
+type Settings struct {
+	Enabled        bool
+	Provider       ProviderName
+	Language       string
+	InterimResults bool
+}
+
+// RoomSettings returns the settings of a room with the JSON object metadata, those of the config
+// if the metadata does not configure transcription
+func (c Config) RoomSettings(metadata string) Settings {
+	settings := Settings{
+		Enabled:        c.AllRooms,
+		Provider:       c.Provider,
+		Language:       c.Language,
+		InterimResults: c.InterimResults,
+	}
+
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &doc); err != nil {
+		return settings
+	}
+	raw, ok := doc[RoomKey]
+	if !ok {
+		return settings
+	}
+	var room RoomSettings
+	if err := json.Unmarshal(raw, &room); err != nil {
+		return settings
+	}
+
+	// an object without enabled turns transcription on
+	settings.Enabled = room.Enabled == nil || *room.Enabled
+	if room.Provider != "" {
+		settings.Provider = room.Provider
+	}
+	if room.Language != "" {
+		settings.Language = room.Language
+	}
+	if room.InterimResults != nil {
+		settings.InterimResults = *room.InterimResults
+	}
+	return settings
+}
+
+// NewProvider returns the provider named name with its settings of the config
+func NewProvider(config Config, name ProviderName) (TranscriptionProvider, error) {
+	switch name {
+	case ProviderDeepgram:
+		if config.Deepgram.APIKey == "" && config.Deepgram.URL == "" {
+			return nil, ErrNotConfigured
+		}
+		return &deepgramProvider{config: config.Deepgram}, nil
+	case ProviderGoogle:
+		if config.Google.APIKey == "" {
+			return nil, ErrNotConfigured
+		}
+		return &googleProvider{config: config.Google}, nil
+	case ProviderAzure:
+		if config.Azure.Key == "" || (config.Azure.Region == "" && config.Azure.URL == "") {
+			return nil, ErrNotConfigured
+		}
+		return &azureProvider{config: config.Azure}, nil
+	case ProviderWhisper:
+		if config.Whisper.Address == "" {
+			return nil, ErrNotConfigured
+		}
+		return &whisperProvider{config: config.Whisper}, nil
+	}
+	return nil, ErrUnknownProvider
+}
+
+// appendPCM appends the samples to b as signed 16 bit little endian PCM
+func appendPCM(b []byte, pcm []int16) []byte {
+	for _, sample := range pcm {
+		b = binary.LittleEndian.AppendUint16(b, uint16(sample))
+	}
+	return b
+}
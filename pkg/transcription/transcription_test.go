@@ -0,0 +1,194 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcription
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRoomSettings(t *testing.T) {
+	config := DefaultConfig
+
+	settings := config.RoomSettings("")
+	require.False(t, settings.Enabled)
+	require.Equal(t, ProviderDeepgram, settings.Provider)
+
+	settings = config.RoomSettings(`{"agentix.transcription":{"provider":"azure","language":"de-DE","interim_results":false}}`)
+	require.Equal(t, Settings{Enabled: true, Provider: ProviderAzure, Language: "de-DE"}, settings)
+
+	config.AllRooms = true
+	require.True(t, config.RoomSettings(`{"topic":"support"}`).Enabled)
+	require.False(t, config.RoomSettings(`{"agentix.transcription":{"enabled":false}}`).Enabled)
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(DefaultConfig, "other")
+	require.ErrorIs(t, err, ErrUnknownProvider)
+	_, err = NewProvider(DefaultConfig, ProviderGoogle)
+	require.ErrorIs(t, err, ErrNotConfigured)
+
+	config := DefaultConfig
+	config.Azure = AzureConfig{Key: "key", Region: "westeurope"}
+	p, err := NewProvider(config, ProviderAzure)
+	require.NoError(t, err)
+	require.Equal(t, ProviderAzure, p.Name())
+}
+
+func TestDeepgram(t *testing.T) {
+	audio := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "Token key", r.Header.Get("Authorization"))
+		require.Equal(t, "16000", r.URL.Query().Get("sample_rate"))
+		require.Equal(t, "de", r.URL.Query().Get("language"))
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, data, err := conn.ReadMessage()
+		require.NoError(t, err)
+		audio <- data
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"Metadata"}`)))
+		require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(
+			`{"type":"Results","start":1.5,"duration":0.5,"is_final":true,"channel":{"alternatives":[{"transcript":"hallo"}]}}`,
+		)))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	p, err := NewProvider(Config{Deepgram: DeepgramConfig{APIKey: "key", URL: "ws" + strings.TrimPrefix(server.URL, "http")}}, ProviderDeepgram)
+	require.NoError(t, err)
+	s, err := p.NewStream(context.Background(), StreamParams{Language: "de"})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.WriteAudio([]int16{1, -1}))
+	require.Equal(t, []byte{1, 0, 0xff, 0xff}, <-audio)
+
+	result, err := s.Recv()
+	require.NoError(t, err)
+	require.Equal(t, Result{Text: "hallo", Final: true, Language: "de", Start: 1500 * time.Millisecond, End: 2 * time.Second}, result)
+	_, err = s.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestAzureMessages(t *testing.T) {
+	path, body := parseAzureMessage([]byte("X-RequestId: 1\r\nPath: speech.phrase\r\nContent-Type: application/json\r\n\r\n{\"DisplayText\":\"Hi.\"}"))
+	require.Equal(t, "speech.phrase", path)
+	require.Equal(t, `{"DisplayText":"Hi."}`, string(body))
+
+	header := azureWAVHeader()
+	require.Len(t, header, 44)
+	require.Equal(t, uint32(SampleRate), binary.LittleEndian.Uint32(header[24:]))
+	require.Len(t, azureID(), 32)
+}
+
+func TestGoogleMessages(t *testing.T) {
+	// the field numbers of speech.proto are those of the Speech API
+	config, err := proto.Marshal(&StreamingRecognizeRequest{
+		StreamingRequest: &StreamingRecognizeRequest_StreamingConfig{StreamingConfig: &StreamingRecognitionConfig{}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, protowire.Number(1), tagNumber(t, config))
+	audio, err := proto.Marshal(&StreamingRecognizeRequest{
+		StreamingRequest: &StreamingRecognizeRequest_AudioContent{AudioContent: []byte{1, 2}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, protowire.Number(2), tagNumber(t, audio))
+
+	result := func(transcript string, final bool, seconds int64) *StreamingRecognitionResult {
+		return &StreamingRecognitionResult{
+			Alternatives:  []*SpeechRecognitionAlternative{{Transcript: transcript}},
+			IsFinal:       final,
+			ResultEndTime: durationpb.New(time.Duration(seconds) * time.Second),
+		}
+	}
+	res := &StreamingRecognizeResponse{
+		Results: []*StreamingRecognitionResult{
+			result("hello there", true, 2),
+			result("how", false, 3),
+			result("are", false, 3),
+		},
+	}
+	s := &googleStream{language: "en-US"}
+	require.Equal(t, []Result{
+		{Text: "hello there", Final: true, Language: "en-US", End: 2 * time.Second},
+		{Text: "how are", Language: "en-US", Start: 2 * time.Second, End: 3 * time.Second},
+	}, s.results(res))
+}
+
+type testWhisperServer struct {
+	UnimplementedWhisperServer
+	requests chan *TranscribeRequest
+}
+
+func (s *testWhisperServer) Transcribe(stream grpc.BidiStreamingServer[TranscribeRequest, TranscribeResponse]) error {
+	for i := 0; i < 2; i++ {
+		req, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		s.requests <- req
+	}
+	return stream.Send(&TranscribeResponse{Text: "hello", Final: true, StartUs: 1000, EndUs: 2000})
+}
+
+func TestWhisper(t *testing.T) {
+	requests := make(chan *TranscribeRequest, 2)
+	server := grpc.NewServer()
+	RegisterWhisperServer(server, &testWhisperServer{requests: requests})
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(l) }()
+	defer server.Stop()
+
+	p, err := NewProvider(Config{Whisper: WhisperConfig{Address: l.Addr().String()}}, ProviderWhisper)
+	require.NoError(t, err)
+	s, err := p.NewStream(context.Background(), StreamParams{Language: "en", InterimResults: true})
+	require.NoError(t, err)
+	defer s.Close()
+
+	require.NoError(t, s.WriteAudio([]int16{2}))
+	require.True(t, proto.Equal(&TranscribeRequest{Language: "en", InterimResults: true}, <-requests))
+	require.True(t, proto.Equal(&TranscribeRequest{Pcm: []byte{2, 0}}, <-requests))
+
+	result, err := s.Recv()
+	require.NoError(t, err)
+	require.Equal(t, Result{Text: "hello", Final: true, Language: "en", Start: time.Millisecond, End: 2 * time.Millisecond}, result)
+	_, err = s.Recv()
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func tagNumber(t *testing.T, b []byte) protowire.Number {
+	num, _, n := protowire.ConsumeTag(b)
+	require.Greater(t, n, 0)
+	return num
+}
@@ -0,0 +1,110 @@
+// Copyright 2024 LiveKit, Inc.
+// Copyright 2024 FidoX.io - AgentIX RTC Server modifications
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transcription
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// whisperProvider streams to a local server implementing the Whisper service of whisper.proto
+type whisperProvider struct {
+	config WhisperConfig
+}
+
+func (p *whisperProvider) Name() ProviderName {
+	return ProviderWhisper
+}
+
+func (p *whisperProvider) NewStream(ctx context.Context, params StreamParams) (Stream, error) {
+	stream, err := newGRPCStream(ctx, p.config.Address, insecure.NewCredentials(), func(ctx context.Context, conn *grpc.ClientConn) (Whisper_TranscribeClient, error) {
+		return NewWhisperClient(conn).Transcribe(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &whisperStream{grpcStream: stream, language: params.Language}
+	if err := stream.stream.Send(&TranscribeRequest{Language: params.Language, InterimResults: params.InterimResults}); err != nil {
+		_ = stream.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// --------------------------------------
+
+type whisperStream struct {
+	*grpcStream[TranscribeRequest, TranscribeResponse]
+	language string
+
+	writeLock sync.Mutex
+	closed    bool
+}
+
+func (s *whisperStream) WriteAudio(pcm []int16) error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return ErrStreamClosed
+	}
+	return s.stream.Send(&TranscribeRequest{Pcm: appendPCM(nil, pcm)})
+}
+
+func (s *whisperStream) Recv() (Result, error) {
+	for {
+		res, err := s.stream.Recv()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return Result{}, io.EOF
+			}
+			return Result{}, err
+		}
+		if res.Text == "" {
+			continue
+		}
+
+		result := Result{
+			Text:     res.Text,
+			Final:    res.Final,
+			Language: res.Language,
+			Start:    time.Duration(res.StartUs) * time.Microsecond,
+			End:      time.Duration(res.EndUs) * time.Microsecond,
+		}
+		if result.Language == "" {
+			result.Language = s.language
+		}
+		return result, nil
+	}
+}
+
+func (s *whisperStream) Close() error {
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.close()
+}
@@ -92,6 +92,7 @@ type RTCClient struct {
 	OnConnected             func()
 	OnDataReceived          func(data []byte, sid string)
 	OnDataUnlabeledReceived func(data []byte)
+	OnRTPReceived           func(track *webrtc.TrackRemote, pkt *rtp.Packet)
 	refreshToken            string
 
 	// map of livekit.ParticipantID and last packet
@@ -1070,6 +1071,9 @@ func (c *RTCClient) processRemoteTrack(track *webrtc.TrackRemote) {
 		c.bytesReceived[publisherID] += uint64(pkt.MarshalSize())
 		c.lock.Unlock()
 		numBytes += pkt.MarshalSize()
+		if c.OnRTPReceived != nil {
+			c.OnRTPReceived(track, pkt)
+		}
 		if time.Since(lastUpdate) > 30*time.Second {
 			logger.Infow(
 				"consumed from participant",
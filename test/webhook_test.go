@@ -143,7 +143,7 @@ func setupServerWithWebhook() (server *service.LivekitServer, testServer *webhoo
 	}
 	currentNode.SetNodeID(livekit.NodeID(guid.New(nodeID1)))
 
-	server, err = service.InitializeServer(conf, currentNode)
+	server, err = service.InitializeServer(conf, currentNode, service.ServerOptions{})
 	if err != nil {
 		return
 	}